@@ -5,6 +5,7 @@ package v1beta1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Destination provides the configuration that will be applied to the
@@ -20,13 +21,110 @@ type Destination struct {
 	// useful when migrating to VSO from a previous secret deployment strategy.
 	// +kubebuilder:default=false
 	Overwrite bool `json:"overwrite,omitempty"`
+	// Adopt the destination Secret if it exists and Create is true, and it is not
+	// owned by another VSO resource. This is useful when migrating to VSO from
+	// other tools, e.g. Helm or External Secrets Operator, without deleting the
+	// live Secret. The Secret's data is synced before its owner labels and
+	// references are applied. The labels and annotations that the Secret had
+	// before its adoption are retained, while the owner references of other
+	// tools are removed.
+	// +kubebuilder:default=false
+	Adopt bool `json:"adopt,omitempty"`
+	// Enforce the destination Secret's data. Out-of-band changes to the Secret's
+	// data, or its deletion, are detected as soon as they happen, and the Secret is
+	// resynced. Requires Create to be set to true, and the HMAC of the Secret's
+	// data to be computed, see HMACSecretData. Supported by VaultStaticSecret,
+	// VaultPKISecret, and HCPVaultSecretsApp, it is not supported for the
+	// additional Destinations of a VaultPKISecret.
+	// +kubebuilder:default=false
+	Enforce bool `json:"enforce,omitempty"`
+	// Immutable syncs the data to an immutable Secret, that is named after the
+	// destination Secret with a suffix derived from the data. A new immutable
+	// Secret is created whenever the data changes, and the destination Secret is
+	// updated to point to it with the 'vso.secrets.hashicorp.com/immutable-secret'
+	// annotation, it does not hold any data itself. Immutable Secrets are not
+	// watched by the kubelet, which reduces the load on the API server in large
+	// clusters. Requires Create to be set to true.
+	// +kubebuilder:default=false
+	Immutable bool `json:"immutable,omitempty"`
+	// ImmutableHistoryLimit is the number of previous immutable Secrets to retain
+	// when Immutable is set, the older ones are deleted. If set to 0, only the
+	// current immutable Secret is retained.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	ImmutableHistoryLimit *int `json:"immutableHistoryLimit,omitempty"`
+	// Chunking splits the data across multiple Secrets when it exceeds the 1MiB
+	// Secret size limit. The chunks are named after the destination Secret with
+	// their index as a suffix, e.g. 'name-0', 'name-1', and are listed in order by
+	// the destination Secret's 'vso.secrets.hashicorp.com/chunks' annotation, it
+	// does not hold any data itself. A value that does not fit in a single chunk is
+	// split across consecutive chunks, it is restored by concatenating its parts in
+	// the chunks' order. If not set, the sync fails when the data exceeds the limit.
+	// Requires Create to be set to true, and is not supported along with Immutable.
+	// +kubebuilder:default=false
+	Chunking bool `json:"chunking,omitempty"`
+	// ContentMAC annotates the destination Secret with the HMAC-SHA256 of its data,
+	// computed with the operator's HMAC key, in the
+	// 'vso.secrets.hashicorp.com/content-mac' annotation. It only changes when the
+	// data changes, or when the data is synced after the HMAC key was rotated, which
+	// allows other tools to react to a rotation. Requires Create to be set to true.
+	// +kubebuilder:default=false
+	ContentMAC bool `json:"contentMAC,omitempty"`
+	// DeletionPolicy of the destination Secret, applied when the resource is
+	// deleted. Choices are `Retain` or `Delete`.
+	//
+	// If `Retain` is set, the Secret is kept, its owner labels and references are
+	// removed so that it is no longer garbage collected along with the resource.
+	//
+	// If `Delete` is set, the Secret is deleted along with the resource.
+	//
+	// If not set, the Secret is garbage collected along with the resource by way
+	// of its owner reference. Only applies to Secrets that were created by the
+	// operator, i.e. Create is true.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 	// Labels to apply to the Secret. Requires Create to be set to true.
+	// The values may contain templates, that are rendered with the metadata of
+	// the synced secret, e.g. '{{ .Metadata.version }}' of a KV v2 secret,
+	// '{{ .Metadata.lease_id }}' of a dynamic secret, or
+	// '{{ .Metadata.serial_number }}' of a certificate, and with the resource's
+	// Annotations and Labels. The secret data is not available to the templates.
 	Labels map[string]string `json:"labels,omitempty"`
 	// Annotations to apply to the Secret. Requires Create to be set to true.
+	// The values may contain templates, that are rendered with the metadata of
+	// the synced secret, e.g. '{{ .Metadata.version }}' of a KV v2 secret,
+	// '{{ .Metadata.lease_id }}' of a dynamic secret, or
+	// '{{ .Metadata.serial_number }}' of a certificate, and with the resource's
+	// Annotations and Labels. The secret data is not available to the templates.
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// Type of Kubernetes Secret. Requires Create to be set to true.
 	// Defaults to Opaque.
 	Type v1.SecretType `json:"type,omitempty"`
+	// ChainOrder controls how the certificate chain is laid out in a
+	// "kubernetes.io/tls" Secret. Only supported by VaultPKISecret.
+	// Choices are `leaf-chain`, `leaf`, or `root-ca`.
+	//
+	// If `leaf-chain` is set, "tls.crt" contains the certificate followed by the
+	// CA chain, and "ca.crt" contains the issuing CA.
+	//
+	// If `leaf` is set, "tls.crt" contains only the certificate, and "ca.crt"
+	// contains the CA chain.
+	//
+	// If `root-ca` is set, "tls.crt" contains the certificate followed by the
+	// intermediate CAs, and "ca.crt" contains the root CA. This requires the
+	// VaultPKISecret's IncludeRootCA to be set, otherwise the issuing CA is used.
+	//
+	// If not set, "tls.crt" contains the certificate followed by the CA chain,
+	// and "ca.crt" is only set when Vault does not return a CA chain.
+	// +kubebuilder:validation:Enum=leaf-chain;leaf;root-ca
+	ChainOrder string `json:"chainOrder,omitempty"`
+	// KeyMap renames the keys of the secret data, it maps a source key, e.g.
+	// 'username', to its key in the Secret, e.g. 'DB_USER'. It is a simpler
+	// alternative to Transformation templates for plain renames. The
+	// Transformation's Includes and Excludes apply to the source keys, and a
+	// template rendered for the same key takes precedence over the renamed key.
+	// Supported by VaultStaticSecret, VaultDynamicSecret, and HCPVaultSecretsApp.
+	KeyMap map[string]string `json:"keyMap,omitempty"`
 	// Transformation provides configuration for transforming the secret data before
 	// it is stored in the Destination.
 	Transformation Transformation `json:"transformation,omitempty"`
@@ -40,12 +138,116 @@ type Destination struct {
 // E.g. vso.secrets.hashicorp.com/restartedAt: "2023-03-23T13:39:31Z"
 //
 // Supported resources: Deployment, DaemonSet, StatefulSet, argo.Rollout
+//
+// An argo.Rollout, or Rollout, is restarted by patching its 'spec.restartAt',
+// and a Flux Kustomization is reconciled by patching its
+// 'reconcile.fluxcd.io/requestedAt' annotation. Neither modifies a pod
+// template, which avoids drift in GitOps tools like ArgoCD.
+//
+// Arbitrary resources, e.g. CRD based workloads, are supported by setting
+// Version, and optionally Group, along with a Strategy. The Operator must be
+// granted the RBAC permissions to get and patch such resources.
+//
+// Applications that support reloading their secrets can be notified instead of
+// being restarted by setting the Strategy to `notify`, see RolloutRestartNotify
+// for more details.
 type RolloutRestartTarget struct {
-	// Kind of the resource
-	// +kubebuilder:validation:Enum={Deployment,DaemonSet,StatefulSet,argo.Rollout}
+	// Kind of the resource. If Version is not set, Kind must be one of:
+	// Deployment, DaemonSet, StatefulSet, argo.Rollout, Rollout, Kustomization.
+	// Rollout is an alias of argo.Rollout, and Kustomization refers to a Flux
+	// Kustomization.
 	Kind string `json:"kind"`
 	// Name of the resource
 	Name string `json:"name"`
+	// Group of the resource, only applies when Version is set.
+	// Leave empty for resources in the core API group.
+	Group string `json:"group,omitempty"`
+	// Version of the resource. Setting Version enables the rollout-restart of
+	// any resource identified by Group, Version, and Kind.
+	Version string `json:"version,omitempty"`
+	// Strategy used to trigger the rollout-restart of a resource identified by
+	// Group, Version, and Kind. Only applies when Version is set, except for
+	// `notify` which applies to all targets.
+	// Choices are `annotation`, `scale`, `restartAt`, or `notify`.
+	//
+	// If `annotation` is set, the 'vso.secrets.hashicorp.com/restartedAt'
+	// annotation is patched into the resource's pod template found at
+	// AnnotationsPath.
+	//
+	// If `scale` is set, the resource's 'spec.replicas' is scaled down to zero,
+	// and then back to its original value.
+	//
+	// If `restartAt` is set, the resource's 'spec.restartAt' is patched with the
+	// current time, as is done for an argo.Rollout.
+	//
+	// If `notify` is set, the resource is not restarted. Instead, its Pods are
+	// notified of the secret rotation as configured in Notify.
+	// +kubebuilder:validation:Enum=annotation;scale;restartAt;notify
+	// +kubebuilder:default=annotation
+	Strategy string `json:"strategy,omitempty"`
+	// AnnotationsPath is the dot separated path to the pod template annotations
+	// of the resource, only applies to the `annotation` Strategy.
+	// E.g. 'spec.template.pod.metadata.annotations' for a Strimzi KafkaConnect.
+	// +kubebuilder:default="spec.template.metadata.annotations"
+	AnnotationsPath string `json:"annotationsPath,omitempty"`
+	// Notify configures the `notify` Strategy.
+	Notify *RolloutRestartNotify `json:"notify,omitempty"`
+	// Trigger sets the value of the 'vso.secrets.hashicorp.com/restartedAt'
+	// annotation. Choices are `timestamp` or `content-hash`.
+	//
+	// If `timestamp` is set, the value is the time of the rollout-restart.
+	//
+	// If `content-hash` is set, the value is an HMAC of the destination Secret's
+	// data, so that it only changes when the data does. Repeated rollout-restarts
+	// for the same data are then no-ops, which avoids perpetual drift in GitOps
+	// tools like ArgoCD and Flux. An argo.Rollout is restarted by patching its
+	// pod template annotations rather than its 'spec.restartAt'.
+	//
+	// Only applies to rollout-restarts that patch the annotation.
+	// +kubebuilder:validation:Enum=timestamp;content-hash
+	// +kubebuilder:default=timestamp
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// RolloutRestartNotify configures how the Pods of a RolloutRestartTarget are
+// notified of a Vault Secret rotation, rather than being restarted.
+//
+// By default, each of the target's Pods is patched to include the
+// 'vso.secrets.hashicorp.com/notifiedAt' annotation with a timestamp value of
+// when the notification was sent. The annotation can be projected into the
+// Pod with a downwardAPI volume, whose file is updated in place by the kubelet.
+// A sidecar watching that file can then signal the application, e.g. with a
+// SIGHUP, or run a reload command.
+//
+// If URL is set, an HTTP POST request is sent to it instead, whose JSON body
+// contains the target's 'namespace', 'kind', and 'name', along with the
+// 'notifiedAt' timestamp.
+type RolloutRestartNotify struct {
+	// URL of an in-cluster endpoint, e.g. 'http://app.ns.svc:8080/-/reload',
+	// that is sent an HTTP POST request upon rotation. The Pods are not
+	// annotated if it is set.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url,omitempty"`
+	// PodSelector selects the Pods to annotate, it defaults to the target's
+	// 'spec.selector'. Required for resources that do not have a
+	// 'spec.selector', e.g. a Strimzi KafkaConnect.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+// RolloutRestartWindow is a recurring window during which the rollout-restarts
+// of a syncable secret resource are allowed, e.g. outside of peak hours.
+type RolloutRestartWindow struct {
+	// Schedule of the start of each window, as a cron expression with the five
+	// fields: minute, hour, day of month, month, and day of week. The expression
+	// is evaluated in UTC, unless it is prefixed by CRON_TZ=<zone>, e.g.
+	// "CRON_TZ=Europe/Berlin 0 22 * * 1-5".
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// Duration of each window, in duration notation e.g. 30m, 2h. Must be at
+	// least 1m.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	Duration string `json:"duration"`
 }
 
 type Transformation struct {
@@ -67,11 +269,34 @@ type Transformation struct {
 	// applied before any inclusion patterns. To exclude all source secret data
 	// fields, you can configure the single pattern ".*".
 	Excludes []string `json:"excludes,omitempty"`
-	// ExcludeRaw data from the destination Secret. Exclusion policy can be set
-	// globally by including 'exclude-raw` in the '--global-transformation-options'
-	// command line flag. If set, the command line flag always takes precedence over
-	// this configuration.
-	ExcludeRaw bool `json:"excludeRaw,omitempty"`
+	// ExcludeRaw data from the destination Secret. The default exclusion policy
+	// can be set globally by including 'exclude-raw` in the
+	// '--global-transformation-options' command line flag. If not set, the global
+	// default is inherited, otherwise this configuration always takes precedence
+	// over it.
+	ExcludeRaw *bool `json:"excludeRaw,omitempty"`
+	// Encodings maps a K8s Secret data key to the encoding of its value. They
+	// apply to the source secret data fields, after they are renamed by the
+	// Destination's KeyMap, and never to templated fields.
+	// Choices are `base64decode`, `json`, or `int-string`.
+	//
+	// If `base64decode` is set, the base64 encoded string value is decoded, and
+	// its raw bytes are stored, e.g. a binary payload that is stored in KV.
+	//
+	// If `json` is set, the value is always JSON encoded, including a string value
+	// that is otherwise stored as is.
+	//
+	// If `int-string` is set, the numeric value is stored as an integer, without an
+	// exponent, e.g. 1e+21 is stored as 1000000000000000000000. A fraction fails the
+	// sync.
+	Encodings map[string]string `json:"encodings,omitempty"`
+	// IsolateTemplateErrors renders each template independently. A template that
+	// fails to render only affects its own key, which retains its value from the
+	// destination Secret, while all other keys and the raw data are still synced.
+	// The keys that failed to render are listed in the resource's
+	// TemplatesRendered status condition. If not set, any template rendering error
+	// fails the entire sync.
+	IsolateTemplateErrors bool `json:"isolateTemplateErrors,omitempty"`
 }
 
 // TransformationRef contains the configuration for accessing templates from an
@@ -123,4 +348,55 @@ type VaultClientMeta struct {
 	// ID is the Vault ID of the authenticated client. The ID should never contain
 	// any sensitive information.
 	ID string `json:"id,omitempty"`
+	// Accessor of the client's Vault token, it can be used to look up the token
+	// in Vault and to correlate the client with the Vault audit log.
+	Accessor string `json:"accessor,omitempty"`
+	// TokenTTL is the TTL of the client's Vault token in seconds, as of its last
+	// login or renewal.
+	TokenTTL int64 `json:"tokenTTL,omitempty"`
+	// TokenRenewals is the number of times the client's Vault token was renewed
+	// since its login.
+	TokenRenewals int `json:"tokenRenewals,omitempty"`
+	// TokenPolicies are the Vault policies granted to the client's Vault token.
+	TokenPolicies []string `json:"tokenPolicies,omitempty"`
+}
+
+// BackoffConfig overrides the operator's back-off of the failed sync attempts
+// of a syncable secret resource, which is configured by the operator's
+// --backoff-* flags. Unset fields default to the operator's configuration. The
+// override applies from the next failed sync attempt after a successful sync.
+type BackoffConfig struct {
+	// InitialInterval is the interval before the first retry, in duration
+	// notation e.g. 5s, 1m.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ms|s|m|h))$`
+	InitialInterval string `json:"initialInterval,omitempty"`
+	// MaxInterval is the maximum interval between retries, in duration notation
+	// e.g. 30s, 5m.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ms|s|m|h))$`
+	MaxInterval string `json:"maxInterval,omitempty"`
+	// Multiplier by which the interval is increased after each retry, e.g. 1.5.
+	// It must be greater than zero.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?)$`
+	Multiplier string `json:"multiplier,omitempty"`
+	// MaxElapsedTime is the maximum elapsed time after which the retries stop,
+	// in duration notation e.g. 1h. Setting it to 0s retries forever.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ms|s|m|h))$`
+	MaxElapsedTime string `json:"maxElapsedTime,omitempty"`
+}
+
+// BackOffStatus reports the back-off of the failing sync attempts of a
+// syncable secret resource.
+type BackOffStatus struct {
+	// Failures is the number of consecutive failed sync attempts.
+	Failures int `json:"failures"`
+	// Interval is the current back-off interval, e.g. 30s. It is empty once the
+	// maximum elapsed back-off time has been exceeded.
+	Interval string `json:"interval"`
+	// NextRetryTime is the time, in Unix seconds, of the next sync attempt. It
+	// is zero once the maximum elapsed back-off time has been exceeded.
+	NextRetryTime int64 `json:"nextRetryTime"`
 }
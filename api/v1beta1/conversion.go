@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// v1beta1 is the conversion Hub for all of the API's kinds. Future API
+// versions implement conversion.Convertible by converting to and from the
+// v1beta1 types, which are served by the operator's conversion webhook.
+var (
+	_ conversion.Hub = (*HCPAuth)(nil)
+	_ conversion.Hub = (*HCPVaultSecretsApp)(nil)
+	_ conversion.Hub = (*HCPVaultSecretsProject)(nil)
+	_ conversion.Hub = (*OperatorStatus)(nil)
+	_ conversion.Hub = (*SecretTransformation)(nil)
+	_ conversion.Hub = (*VaultAuth)(nil)
+	_ conversion.Hub = (*VaultAuthGlobal)(nil)
+	_ conversion.Hub = (*VaultConnection)(nil)
+	_ conversion.Hub = (*VaultDynamicSecret)(nil)
+	_ conversion.Hub = (*VaultPKISecret)(nil)
+	_ conversion.Hub = (*VaultSecretExport)(nil)
+	_ conversion.Hub = (*VaultSSHCertificate)(nil)
+	_ conversion.Hub = (*VaultStaticSecret)(nil)
+	_ conversion.Hub = (*VaultTransitKey)(nil)
+)
+
+// Hub marks HCPAuth as a conversion hub.
+func (*HCPAuth) Hub() {}
+
+// Hub marks HCPVaultSecretsApp as a conversion hub.
+func (*HCPVaultSecretsApp) Hub() {}
+
+// Hub marks HCPVaultSecretsProject as a conversion hub.
+func (*HCPVaultSecretsProject) Hub() {}
+
+// Hub marks OperatorStatus as a conversion hub.
+func (*OperatorStatus) Hub() {}
+
+// Hub marks SecretTransformation as a conversion hub.
+func (*SecretTransformation) Hub() {}
+
+// Hub marks VaultAuth as a conversion hub.
+func (*VaultAuth) Hub() {}
+
+// Hub marks VaultAuthGlobal as a conversion hub.
+func (*VaultAuthGlobal) Hub() {}
+
+// Hub marks VaultConnection as a conversion hub.
+func (*VaultConnection) Hub() {}
+
+// Hub marks VaultDynamicSecret as a conversion hub.
+func (*VaultDynamicSecret) Hub() {}
+
+// Hub marks VaultPKISecret as a conversion hub.
+func (*VaultPKISecret) Hub() {}
+
+// Hub marks VaultSecretExport as a conversion hub.
+func (*VaultSecretExport) Hub() {}
+
+// Hub marks VaultSSHCertificate as a conversion hub.
+func (*VaultSSHCertificate) Hub() {}
+
+// Hub marks VaultStaticSecret as a conversion hub.
+func (*VaultStaticSecret) Hub() {}
+
+// Hub marks VaultTransitKey as a conversion hub.
+func (*VaultTransitKey) Hub() {}
@@ -24,13 +24,18 @@ type HCPAuthSpec struct {
 	// is the default behavior.
 	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
 	// Method to use when authenticating to Vault.
-	// +kubebuilder:validation:Enum=servicePrincipal
+	// +kubebuilder:validation:Enum=servicePrincipal;workloadIdentity
 	// +kubebuilder:default="servicePrincipal"
 	Method string `json:"method,omitempty"`
 	// ServicePrincipal provides the necessary configuration for authenticating to
 	// HCP using a service principal. For security reasons, only project-level
 	// service principals should ever be used.
 	ServicePrincipal *HCPAuthServicePrincipal `json:"servicePrincipal,omitempty"`
+	// WorkloadIdentity provides the necessary configuration for authenticating to
+	// HCP using workload identity federation. A ServiceAccount token is exchanged
+	// for an HCP service principal access token, so no long-lived HCP credentials
+	// are stored in the cluster.
+	WorkloadIdentity *HCPAuthWorkloadIdentity `json:"workloadIdentity,omitempty"`
 }
 
 // HCPAuthServicePrincipal provides HCPAuth configuration options needed for
@@ -46,6 +51,27 @@ type HCPAuthServicePrincipal struct {
 	SecretRef string `json:"secretRef"`
 }
 
+// HCPAuthWorkloadIdentity provides HCPAuth configuration options needed for
+// authenticating to HCP using workload identity federation, with the token of
+// a Kubernetes ServiceAccount as the OIDC subject credential.
+type HCPAuthWorkloadIdentity struct {
+	// ProviderResourceName is the resource name of the HCP workload identity
+	// provider that the ServiceAccount token is exchanged with, e.g.
+	// iam/project/<project-id>/service-principal/<name>/workload-identity-provider/<name>
+	ProviderResourceName string `json:"providerResourceName"`
+	// ServiceAccount whose token is exchanged for an HCP access token. This must
+	// reside in the consumer's (HCP) namespace.
+	ServiceAccount string `json:"serviceAccount"`
+	// TokenAudiences to include in the ServiceAccount token, they must match the
+	// workload identity provider's allowed audiences. Defaults to the
+	// ProviderResourceName.
+	TokenAudiences []string `json:"audiences,omitempty"`
+	// TokenExpirationSeconds to set the ServiceAccount token.
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=600
+	TokenExpirationSeconds int64 `json:"tokenExpirationSeconds,omitempty"`
+}
+
 // HCPAuthStatus defines the observed state of HCPAuth
 type HCPAuthStatus struct {
 	// Valid auth mechanism.
@@ -29,17 +29,36 @@ type HCPVaultSecretsAppSpec struct {
 	// whenever the Vault secret changes between reconciliation events. See
 	// RolloutRestartTarget for more details.
 	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows restricts the rollout-restarts of the
+	// RolloutRestartTargets to recurring windows. The secret is still synced on
+	// schedule, but its rollout-restarts are deferred until the start of the next
+	// window, and the resource has a RolloutRestartDeferred condition meanwhile.
+	// The rollout-restarts are not restricted if no windows are set.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
 	// Destination provides configuration necessary for syncing the HCP Vault
 	// Application secrets to Kubernetes.
 	Destination Destination `json:"destination"`
 	// SyncConfig configures sync behavior from HVS to VSO
 	SyncConfig *HVSSyncConfig `json:"syncConfig,omitempty"`
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the secrets are neither synced nor their dynamic secrets renewed,
+	// and the resource has a Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
 }
 
 // HVSSyncConfig configures sync behavior from HVS to VSO
 type HVSSyncConfig struct {
 	// Dynamic configures sync behavior for dynamic secrets.
 	Dynamic *HVSDynamicSyncConfig `json:"dynamic,omitempty"`
+	// InstantUpdates is a flag to indicate that the App is synced as soon as the
+	// operator's HVS webhook receiver is notified of a change to it, rather than
+	// waiting for RefreshAfter. Requires the operator's HVS webhook receiver to be
+	// enabled.
+	InstantUpdates bool `json:"instantUpdates,omitempty"`
+	// Backoff overrides the operator's back-off of the failed sync attempts.
+	Backoff *BackoffConfig `json:"backoff,omitempty"`
+	// Rotating configures sync behavior for rotating secrets.
+	Rotating *HVSRotatingSyncConfig `json:"rotating,omitempty"`
 }
 
 // HVSDynamicSyncConfig configures sync behavior for HVS dynamic secrets.
@@ -52,6 +71,18 @@ type HVSDynamicSyncConfig struct {
 	RenewalPercent int `json:"renewalPercent,omitempty"`
 }
 
+// HVSRotatingSyncConfig configures sync behavior for HVS rotating secrets.
+type HVSRotatingSyncConfig struct {
+	// RenewalPercent is the percent out of 100 of a rotating secret version's
+	// lifetime when the App is synced again, rather than waiting for RefreshAfter.
+	// If the version has not been rotated by then, the App is synced again once
+	// it expires. Defaults to 67 percent plus up to 10% jitter.
+	// +kubebuilder:default=67
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=90
+	RenewalPercent int `json:"renewalPercent,omitempty"`
+}
+
 // HVSDynamicStatus defines the observed state of a dynamic secret within an HCP
 // Vault Secrets App
 type HVSDynamicStatus struct {
@@ -65,6 +96,19 @@ type HVSDynamicStatus struct {
 	TTL string `json:"ttl,omitempty"`
 }
 
+// HVSRotatingStatus defines the observed state of a rotating secret within an
+// HCP Vault Secrets App
+type HVSRotatingStatus struct {
+	// Name of the rotating secret
+	Name string `json:"name,omitempty"`
+	// Version of the rotating secret that was synced
+	Version int64 `json:"version,omitempty"`
+	// CreatedAt is the timestamp string of when the version was created
+	CreatedAt string `json:"createdAt,omitempty"`
+	// ExpiresAt is the timestamp string of when the version will expire
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
 // HCPVaultSecretsAppStatus defines the observed state of HCPVaultSecretsApp
 type HCPVaultSecretsAppStatus struct {
 	// LastGeneration is the Generation of the last reconciled resource.
@@ -81,6 +125,15 @@ type HCPVaultSecretsAppStatus struct {
 	// DynamicSecrets lists the last observed state of any dynamic secrets
 	// within the HCP Vault Secrets App
 	DynamicSecrets []HVSDynamicStatus `json:"dynamicSecrets,omitempty"`
+	// RotatingSecrets lists the last observed state of any rotating secrets
+	// within the HCP Vault Secrets App
+	RotatingSecrets []HVSRotatingStatus `json:"rotatingSecrets,omitempty"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// the outcome of rendering its templates.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HCPVaultSecretsProjectSpec defines the desired state of HCPVaultSecretsProject
+type HCPVaultSecretsProjectSpec struct {
+	// AppNames are the glob patterns used to select the HCP Vault Secrets Apps
+	// that are to be synced, e.g. "team-a-*". Each matching App is synced into its
+	// own destination Secret by an HCPVaultSecretsApp that is owned by this
+	// resource.
+	// +kubebuilder:validation:MinItems=1
+	AppNames []string `json:"appNames"`
+	// HCPAuthRef to the HCPAuth resource, can be prefixed with a namespace, eg:
+	// `namespaceA/vaultAuthRefB`. If no namespace prefix is provided it will default
+	// to the namespace of the HCPAuth CR. If no value is specified for HCPAuthRef the
+	// Operator will default to the `default` HCPAuth, configured in the operator's
+	// namespace.
+	HCPAuthRef string `json:"hcpAuthRef,omitempty"`
+	// RefreshAfter a period of time, in duration notation e.g. 30s, 1m, 24h, after
+	// which the HCP Vault Secrets Project's Apps are listed again.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	// +kubebuilder:default="600s"
+	RefreshAfter string `json:"refreshAfter,omitempty"`
+	// AppTemplate is applied to the HCPVaultSecretsApp of each matching App.
+	AppTemplate HCPVaultSecretsProjectAppTemplate `json:"appTemplate"`
+}
+
+// HCPVaultSecretsProjectAppTemplate configures the HCPVaultSecretsApp of each
+// App that is synced by an HCPVaultSecretsProject.
+type HCPVaultSecretsProjectAppTemplate struct {
+	// RefreshAfter a period of time, in duration notation e.g. 30s, 1m, 24h
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	// +kubebuilder:default="600s"
+	RefreshAfter string `json:"refreshAfter,omitempty"`
+	// RolloutRestartTargets are configured on each HCPVaultSecretsApp. See
+	// RolloutRestartTarget for more details.
+	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows are configured on each HCPVaultSecretsApp. See
+	// RolloutRestartWindow for more details.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
+	// Destination provides configuration necessary for syncing the HCP Vault
+	// Application secrets to Kubernetes. The destination's name is a template that
+	// is rendered for each App, e.g. "{{ .AppName }}-secrets". The App's name is
+	// available as .AppName.
+	Destination Destination `json:"destination"`
+	// SyncConfig configures sync behavior from HVS to VSO
+	SyncConfig *HVSSyncConfig `json:"syncConfig,omitempty"`
+}
+
+// HCPVaultSecretsProjectApp is an App that is synced by an
+// HCPVaultSecretsProject.
+type HCPVaultSecretsProjectApp struct {
+	// AppName of the HCP Vault Secrets App.
+	AppName string `json:"appName"`
+	// Name of the HCPVaultSecretsApp that syncs the App.
+	Name string `json:"name"`
+	// Destination is the name of the App's destination Secret.
+	Destination string `json:"destination"`
+}
+
+// HCPVaultSecretsProjectStatus defines the observed state of HCPVaultSecretsProject
+type HCPVaultSecretsProjectStatus struct {
+	// LastGeneration is the Generation of the last reconciled resource.
+	LastGeneration int64 `json:"lastGeneration"`
+	// Apps are the HCP Vault Secrets Apps that matched AppNames during the last
+	// reconciliation.
+	Apps []HCPVaultSecretsProjectApp `json:"apps,omitempty"`
+	// Error is the last error encountered while syncing the project's Apps.
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// HCPVaultSecretsProject is the Schema for the hcpvaultsecretsprojects API
+type HCPVaultSecretsProject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HCPVaultSecretsProjectSpec   `json:"spec,omitempty"`
+	Status HCPVaultSecretsProjectStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HCPVaultSecretsProjectList contains a list of HCPVaultSecretsProject
+type HCPVaultSecretsProjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HCPVaultSecretsProject `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HCPVaultSecretsProject{}, &HCPVaultSecretsProjectList{})
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorStatusStatus summarizes the capacity and health of the operator. It
+// is maintained by the leader.
+type OperatorStatusStatus struct {
+	// Leader is the identity of the operator instance that is the current leader.
+	Leader string `json:"leader,omitempty"`
+	// Healthy is true when there are no stale resources and the Vault client
+	// cache has no tainted clients.
+	Healthy bool `json:"healthy"`
+	// Backlog of pending reconcile requests per resource kind.
+	Backlog []OperatorStatusBacklog `json:"backlog,omitempty"`
+	// StaleResources is the number of syncable secret resources that are either
+	// unhealthy, or that are overdue for their next sync.
+	StaleResources int `json:"staleResources"`
+	// ClientCache summarizes the state of the Vault client cache.
+	ClientCache OperatorStatusClientCache `json:"clientCache"`
+	// EventWatchers is the number of running Vault event watchers.
+	EventWatchers int `json:"eventWatchers"`
+	// LastUpdated is the time the status was last updated.
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// OperatorStatusBacklog is the reconcile backlog of a single resource kind.
+type OperatorStatusBacklog struct {
+	// Kind of the resource.
+	Kind string `json:"kind"`
+	// Depth is the number of reconcile requests waiting to be processed.
+	Depth int `json:"depth"`
+}
+
+// OperatorStatusClientCache summarizes the state of the Vault client cache.
+type OperatorStatusClientCache struct {
+	// Length is the number of cached clients.
+	Length int `json:"length"`
+	// Size is the maximum number of cached clients.
+	Size int `json:"size"`
+	// TaintedClients is the number of cached clients that are tainted.
+	TaintedClients int `json:"taintedClients"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// OperatorStatus is the Schema for the operatorstatuses API. There is a single
+// OperatorStatus named "default" in the operator's namespace.
+type OperatorStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status OperatorStatusStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OperatorStatusList contains a list of OperatorStatus
+type OperatorStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorStatus{}, &OperatorStatusList{})
+}
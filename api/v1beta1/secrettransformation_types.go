@@ -11,6 +11,10 @@ import (
 type SecretTransformationStatus struct {
 	Valid *bool  `json:"valid"`
 	Error string `json:"error"`
+	// SourceTemplatesDigest is the digest of the source templates that are
+	// sourced from ConfigMaps. A change of the digest triggers a re-render of
+	// all destinations that refer to the SecretTransformation.
+	SourceTemplatesDigest string `json:"sourceTemplatesDigest,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -46,13 +50,26 @@ type SecretTransformationSpec struct {
 	Excludes []string `json:"excludes,omitempty"`
 }
 
-// SourceTemplate provides source templating configuration.
+// SourceTemplate provides source templating configuration. Exactly one of Text
+// or ConfigMapRef must be set.
 type SourceTemplate struct {
 	Name string `json:"name,omitempty"`
 	// Text contains the Go text template format. The template
 	// references attributes from the data structure of the source secret.
 	// Refer to https://pkg.go.dev/text/template for more information.
-	Text string `json:"text"`
+	Text string `json:"text,omitempty"`
+	// ConfigMapRef sources the template text from a key of a ConfigMap in the
+	// SecretTransformation's namespace. Changes to the ConfigMap trigger a
+	// re-render of all destinations that refer to the SecretTransformation.
+	ConfigMapRef *ConfigMapKeyRef `json:"configMapRef,omitempty"`
+}
+
+// ConfigMapKeyRef selects a key of a ConfigMap.
+type ConfigMapKeyRef struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+	// Key in the ConfigMap's data.
+	Key string `json:"key"`
 }
 
 // +kubebuilder:object:root=true
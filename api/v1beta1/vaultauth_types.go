@@ -23,6 +23,18 @@ type VaultAuthConfigKubernetes struct {
 	// +kubebuilder:default=600
 	// +kubebuilder:validation:Minimum=600
 	TokenExpirationSeconds int64 `json:"tokenExpirationSeconds,omitempty"`
+	// ReuseToken enables the caching of the ServiceAccount token by the operator.
+	// The token is reused for all logins with the same ServiceAccount, audiences,
+	// and expiration, until half of its lifetime has elapsed. This reduces the
+	// load on the Kubernetes TokenRequest API.
+	ReuseToken bool `json:"reuseToken,omitempty"`
+	// LoginDiagnostics enables the diagnosis of failed logins. When Vault denies
+	// a login, the operator reviews the ServiceAccount token with the Kubernetes
+	// TokenReview API, and reports the most likely misconfiguration of Vault's
+	// kubernetes auth method along with the Vault error.
+	// The operator must be allowed to create TokenReviews and SubjectAccessReviews,
+	// the Helm chart grants it when controller.manager.loginDiagnostics.enabled is set.
+	LoginDiagnostics bool `json:"loginDiagnostics,omitempty"`
 }
 
 // Merge merges the other VaultAuthConfigKubernetes into a copy of the current.
@@ -42,6 +54,12 @@ func (a *VaultAuthConfigKubernetes) Merge(other *VaultAuthConfigKubernetes) (*Va
 	if c.TokenExpirationSeconds == 0 {
 		c.TokenExpirationSeconds = other.TokenExpirationSeconds
 	}
+	if !c.ReuseToken {
+		c.ReuseToken = other.ReuseToken
+	}
+	if !c.LoginDiagnostics {
+		c.LoginDiagnostics = other.LoginDiagnostics
+	}
 
 	if err := c.Validate(); err != nil {
 		return nil, err
@@ -377,6 +395,26 @@ type MergeStrategy struct {
 	Params string `json:"params,omitempty"`
 }
 
+// VaultAuthFallback provides an auth method that is used when logging in with
+// the VaultAuth's primary auth method fails.
+type VaultAuthFallback struct {
+	// Method to use when authenticating to Vault.
+	// +kubebuilder:validation:Enum=kubernetes;jwt;appRole;aws;gcp
+	Method string `json:"method"`
+	// Mount to use when authenticating to auth method.
+	Mount string `json:"mount"`
+	// Kubernetes specific auth configuration, requires that the Method be set to `kubernetes`.
+	Kubernetes *VaultAuthConfigKubernetes `json:"kubernetes,omitempty"`
+	// AppRole specific auth configuration, requires that the Method be set to `appRole`.
+	AppRole *VaultAuthConfigAppRole `json:"appRole,omitempty"`
+	// JWT specific auth configuration, requires that the Method be set to `jwt`.
+	JWT *VaultAuthConfigJWT `json:"jwt,omitempty"`
+	// AWS specific auth configuration, requires that Method be set to `aws`.
+	AWS *VaultAuthConfigAWS `json:"aws,omitempty"`
+	// GCP specific auth configuration, requires that Method be set to `gcp`.
+	GCP *VaultAuthConfigGCP `json:"gcp,omitempty"`
+}
+
 // VaultAuthSpec defines the desired state of VaultAuth
 type VaultAuthSpec struct {
 	// VaultConnectionRef to the VaultConnection resource, can be prefixed with a namespace,
@@ -417,6 +455,13 @@ type VaultAuthSpec struct {
 	AWS *VaultAuthConfigAWS `json:"aws,omitempty"`
 	// GCP specific auth configuration, requires that Method be set to `gcp`.
 	GCP *VaultAuthConfigGCP `json:"gcp,omitempty"`
+	// Fallbacks are the auth methods to try, in order, when logging in with the
+	// primary auth method fails with an auth specific error, e.g. a permission
+	// denied response from Vault, or the credentials for the method could not be
+	// obtained. Errors that are not specific to the auth method, like Vault being
+	// unreachable, do not trigger a fallback. Every new login starts with the
+	// primary auth method. The Headers of the VaultAuth apply to all fallbacks.
+	Fallbacks []VaultAuthFallback `json:"fallbacks,omitempty"`
 	// StorageEncryption provides the necessary configuration to encrypt the client storage cache.
 	// This should only be configured when client cache persistence with encryption is enabled.
 	// This is done by passing setting the manager's commandline argument
@@ -433,6 +478,13 @@ type VaultAuthStatus struct {
 	Error      string             `json:"error,omitempty"`
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	SpecHash   string             `json:"specHash,omitempty"`
+	// ActiveMethod is the auth method that was used for the last successful
+	// login, it is only set when Fallbacks are configured. It is formatted as
+	// <method>/<mount>.
+	ActiveMethod string `json:"activeMethod,omitempty"`
+	// VaultClientMeta contains the status of the Vault client that most recently
+	// logged in with this VaultAuth.
+	VaultClientMeta VaultClientMeta `json:"vaultClientMeta,omitempty"`
 }
 
 // +kubebuilder:object:root=true
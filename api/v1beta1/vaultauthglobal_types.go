@@ -47,6 +47,13 @@ type VaultAuthGlobalSpec struct {
 	AWS *VaultAuthGlobalConfigAWS `json:"aws,omitempty"`
 	// GCP specific auth configuration, requires that Method be set to `gcp`.
 	GCP *VaultAuthGlobalConfigGCP `json:"gcp,omitempty"`
+	// NamespaceOverrides of the auth method configuration, for the VaultAuths in
+	// the matching namespaces. The keys are namespace patterns, e.g. `team-*`,
+	// with the syntax of Go's path.Match. An exact match takes precedence,
+	// followed by the longest matching pattern. This allows a single
+	// VaultAuthGlobal to serve many namespaces with different roles. The values
+	// that are set on the VaultAuth itself still take precedence.
+	NamespaceOverrides map[string]VaultAuthGlobalNamespaceOverride `json:"namespaceOverrides,omitempty"`
 }
 
 // VaultAuthGlobalStatus defines the observed state of VaultAuthGlobal
@@ -137,6 +144,21 @@ type VaultAuthGlobalConfigGCP struct {
 	Headers map[string]string `json:"headers,omitempty"`
 }
 
+// VaultAuthGlobalNamespaceOverride overrides the auth method configuration of a
+// VaultAuthGlobal for the VaultAuths in the matching namespaces.
+type VaultAuthGlobalNamespaceOverride struct {
+	// Role to use for authenticating to Vault. Does not apply to the `appRole`
+	// auth method.
+	Role string `json:"role,omitempty"`
+	// Mount to use when authenticating to auth method.
+	Mount string `json:"mount,omitempty"`
+	// ServiceAccount to use when authenticating to Vault. It is the
+	// IRSAServiceAccount of the `aws` auth method, and the
+	// WorkloadIdentityServiceAccount of the `gcp` auth method. Does not apply to
+	// the `appRole` auth method.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&VaultAuthGlobal{}, &VaultAuthGlobalList{})
 }
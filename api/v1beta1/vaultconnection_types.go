@@ -17,6 +17,18 @@ type VaultConnectionSpec struct {
 	TLSServerName string `json:"tlsServerName,omitempty"`
 	// CACertSecretRef is the name of a Kubernetes secret containing the trusted PEM encoded CA certificate chain as `ca.crt`.
 	CACertSecretRef string `json:"caCertSecretRef,omitempty"`
+	// CABundleRef references the trusted PEM encoded CA certificate chain in a
+	// ConfigMap, or a ClusterTrustBundle, it is mutually exclusive with
+	// CACertSecretRef. The referenced object is watched, all cached Vault
+	// clients of the connection are rebuilt whenever the CA bundle changes.
+	CABundleRef *VaultConnectionCABundleRef `json:"caBundleRef,omitempty"`
+	// ClientCertSecretRef is the name of a Kubernetes secret of type
+	// `kubernetes.io/tls` containing the PEM encoded client certificate as
+	// `tls.crt`, and its private key as `tls.key`, e.g. one managed by
+	// cert-manager. The certificate is presented to the Vault server for mutual
+	// TLS. The secret is watched, all cached Vault clients of the connection are
+	// rebuilt whenever the certificate is rotated.
+	ClientCertSecretRef string `json:"clientCertSecretRef,omitempty"`
 	// SkipTLSVerify for TLS connections.
 	// +kubebuilder:default=false
 	SkipTLSVerify bool `json:"skipTLSVerify"`
@@ -25,12 +37,138 @@ type VaultConnectionSpec struct {
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
 	Timeout string `json:"timeout,omitempty"`
+	// ProxyURL is the URL of the proxy used for all Vault requests of this
+	// connection. If not set, the proxy is taken from the environment, e.g.
+	// HTTPS_PROXY, which applies to all connections of the operator.
+	// +kubebuilder:validation:Pattern=`^(http|https|socks5)://.+`
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// DialTimeout applied when establishing a connection to the Vault server,
+	// or to the proxy. If not set, the default dial timeout of the Vault API
+	// client is used.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	DialTimeout string `json:"dialTimeout,omitempty"`
+	// DNS configures the resolution of the Vault server's, or the proxy's,
+	// host name. If not set, the system's resolver is used.
+	DNS *VaultConnectionDNS `json:"dns,omitempty"`
+	// Websocket configures the websocket client used for streaming events from
+	// Vault. If not set, the websocket client uses the same settings as the HTTP
+	// client.
+	Websocket *VaultConnectionWebsocket `json:"websocket,omitempty"`
+	// RateLimit configures the client-side rate limit of all Vault requests for
+	// this connection. The limit is shared by all Vault clients of the
+	// connection, in each operator instance. If not set, requests are not rate
+	// limited.
+	RateLimit *VaultConnectionRateLimit `json:"rateLimit,omitempty"`
+	// CircuitBreaker stops sending Vault requests for this connection after
+	// consecutive failures, until a probe request succeeds. If not set, requests
+	// are always sent to Vault.
+	CircuitBreaker *VaultConnectionCircuitBreaker `json:"circuitBreaker,omitempty"`
+}
+
+// VaultConnectionCABundleRef references a CA bundle that is not stored in a
+// Kubernetes secret.
+type VaultConnectionCABundleRef struct {
+	// Kind of the referenced object, one of ConfigMap or ClusterTrustBundle.
+	// +kubebuilder:validation:Enum=ConfigMap;ClusterTrustBundle
+	Kind string `json:"kind"`
+	// Name of the ConfigMap, in the VaultConnection's namespace, or of the
+	// ClusterTrustBundle.
+	Name string `json:"name"`
+	// Key of the ConfigMap containing the CA bundle. If not set, it defaults to
+	// `ca.crt`. It is ignored for ClusterTrustBundles.
+	Key string `json:"key,omitempty"`
+}
+
+// VaultConnectionDNS configures a custom DNS resolver for a connection.
+type VaultConnectionDNS struct {
+	// Nameservers are the addresses of the DNS servers used for resolving host
+	// names, in the form host:port, e.g. 10.0.0.10:53. They are tried in order,
+	// until one of them can be reached.
+	// +kubebuilder:validation:MinItems=1
+	Nameservers []string `json:"nameservers"`
+}
+
+// VaultConnectionRateLimit configures the client-side rate limit of all Vault
+// requests for a connection.
+type VaultConnectionRateLimit struct {
+	// RequestsPerSecond is the sustained rate of Vault requests. Requests in
+	// excess of the rate are delayed.
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int `json:"requestsPerSecond"`
+	// Burst is the maximum number of Vault requests that may be sent at once.
+	// If not set, it defaults to RequestsPerSecond.
+	// +kubebuilder:validation:Minimum=1
+	Burst int `json:"burst,omitempty"`
+}
+
+// VaultConnectionCircuitBreaker configures the circuit breaker of all Vault
+// requests for a connection. A request fails if Vault is unreachable, or
+// responds with a server error or 429 Too Many Requests.
+type VaultConnectionCircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed requests after which
+	// the circuit breaker opens, all requests are rejected while it is open.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	// OpenDuration is the duration the circuit breaker stays open, before a
+	// single probe request is sent. The circuit breaker closes if the probe
+	// request succeeds, otherwise it opens again.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	// +kubebuilder:default="30s"
+	OpenDuration string `json:"openDuration,omitempty"`
+}
+
+// VaultConnectionWebsocket configures the websocket client used for streaming
+// events from Vault, independently of the HTTP client.
+type VaultConnectionWebsocket struct {
+	// ProxyURL is the URL of the proxy used for all websocket connections. If not
+	// set, the VaultConnection's ProxyURL is used.
+	// +kubebuilder:validation:Pattern=`^(http|https|socks5)://.+`
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// CACertSecretRef is the name of a Kubernetes secret containing the trusted
+	// PEM encoded CA certificate chain as `ca.crt`, used for websocket connections.
+	// If not set, the VaultConnection's CACertSecretRef is used.
+	CACertSecretRef string `json:"caCertSecretRef,omitempty"`
+	// DialTimeout applied when establishing a websocket connection. If not set,
+	// the VaultConnection's Timeout is used.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	DialTimeout string `json:"dialTimeout,omitempty"`
+	// PingInterval is the interval at which pings are sent on an open websocket
+	// connection to verify that it is still healthy. If not set, no pings are
+	// sent.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	PingInterval string `json:"pingInterval,omitempty"`
 }
 
 // VaultConnectionStatus defines the observed state of VaultConnection
 type VaultConnectionStatus struct {
 	// Valid auth mechanism.
 	Valid *bool `json:"valid"`
+	// CircuitBreaker is the observed state of the connection's circuit breaker,
+	// it is only set if a circuit breaker is configured.
+	CircuitBreaker *VaultConnectionCircuitBreakerStatus `json:"circuitBreaker,omitempty"`
+	// ClientCertificateDigest is the SHA-256 digest of the client certificate
+	// that the connection's Vault clients were last built with, it is only set
+	// if a ClientCertSecretRef is configured.
+	ClientCertificateDigest string `json:"clientCertificateDigest,omitempty"`
+	// CABundleDigest is the SHA-256 digest of the CA bundle that the
+	// connection's Vault clients were last built with, it is only set if a
+	// CABundleRef is configured.
+	CABundleDigest string `json:"caBundleDigest,omitempty"`
+}
+
+// VaultConnectionCircuitBreakerStatus is the observed state of a connection's
+// circuit breaker.
+type VaultConnectionCircuitBreakerStatus struct {
+	// State of the circuit breaker, one of Closed, Open, or HalfOpen.
+	State string `json:"state"`
+	// LastTransitionTime is the time of the circuit breaker's last state
+	// change.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
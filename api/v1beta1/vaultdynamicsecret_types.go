@@ -15,8 +15,15 @@ type VaultDynamicSecretSpec struct {
 	// the namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator
 	// will default to the `default` VaultAuth, configured in the operator's namespace.
 	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+	// VaultConnectionRef to the VaultConnection resource, overrides the
+	// VaultConnection of the VaultAuth, can be prefixed with a namespace, eg:
+	// `namespaceA/vaultConnectionRefB`. If no namespace prefix is provided it will
+	// default to the namespace of this resource. Only VaultConnections in this
+	// resource's namespace or the operator's namespace can be referenced.
+	VaultConnectionRef string `json:"vaultConnectionRef,omitempty"`
 	// Namespace of the secrets engine mount in Vault. If not set, the namespace that's
-	// part of VaultAuth resource will be inferred.
+	// part of VaultAuth resource will be inferred. A namespace prefixed with "+/" is
+	// relative to the VaultAuth's namespace, e.g. "+/team-a".
 	Namespace string `json:"namespace,omitempty"`
 	// Mount path of the secret's engine in Vault.
 	Mount string `json:"mount"`
@@ -40,6 +47,53 @@ type VaultDynamicSecretSpec struct {
 	// Please consult https://developer.hashicorp.com/vault/docs/secrets if you are
 	// uncertain about what 'params' should/can be set to.
 	Params map[string]string `json:"params,omitempty"`
+	// ParamsFrom sets params from the values of Secret or ConfigMap keys in the
+	// VaultDynamicSecret's namespace. They are merged with Params when requesting
+	// credentials/secrets, taking precedence over Params. Use it for sensitive
+	// params, e.g. CSR contents or wrapped token IDs, that should not be set in
+	// plaintext in the spec. See Params for more details.
+	ParamsFrom []ParamFromSource `json:"paramsFrom,omitempty"`
+	// Engine enables the handling of a specific secrets engine's responses.
+	// Choices are `aws`, `consul`, `kubernetes`, `ldap-library`, or `nomad`.
+	//
+	// If `aws` is set, STS credentials are requested from the AWS secrets
+	// engine's `sts` endpoint, with Path being the name of the Vault role, see
+	// AWS. The STS credentials' lease is never renewed, new credentials are
+	// requested based on the expiration of the session token instead, see
+	// RenewalPercent.
+	//
+	// If `kubernetes` is set, a service account token is requested from the
+	// Kubernetes secrets engine's `creds` endpoint, with Path being the name of
+	// the Vault role, see Kubernetes. The token's lease is never renewed, a new
+	// token is requested before it expires instead, see RenewalPercent.
+	//
+	// If `consul` or `nomad` is set, a token is requested from the Consul or
+	// Nomad secrets engine's `creds` endpoint, with Path being the name of the
+	// Vault role, see Consul and Nomad. The token's lease is renewed, and it is
+	// revoked on VDS resource deletion.
+	//
+	// If `ldap-library` is set, a service account is checked out from the LDAP
+	// secrets engine's library set, with Path being the name of the library set,
+	// see LDAPLibrary. The check-out's lease is renewed, and the service account
+	// is checked back in once a new one is checked out, and on VDS resource
+	// deletion.
+	// +kubebuilder:validation:Enum=aws;consul;kubernetes;ldap-library;nomad
+	Engine string `json:"engine,omitempty"`
+	// AWS configures the STS credentials request, it is only used when Engine is
+	// set to `aws`.
+	AWS *VaultDynamicSecretAWS `json:"aws,omitempty"`
+	// Kubernetes configures the service account token request, it is only used
+	// when Engine is set to `kubernetes`.
+	Kubernetes *VaultDynamicSecretKubernetes `json:"kubernetes,omitempty"`
+	// Consul configures the Vault role of the Consul token, it is only used when
+	// Engine is set to `consul`.
+	Consul *VaultDynamicSecretConsul `json:"consul,omitempty"`
+	// Nomad configures the Vault role of the Nomad token, it is only used when
+	// Engine is set to `nomad`.
+	Nomad *VaultDynamicSecretNomad `json:"nomad,omitempty"`
+	// LDAPLibrary configures the service account check-out, it is only used when
+	// Engine is set to `ldap-library`.
+	LDAPLibrary *VaultDynamicSecretLDAPLibrary `json:"ldapLibrary,omitempty"`
 	// RenewalPercent is the percent out of 100 of the lease duration when the
 	// lease is renewed. Defaults to 67 percent plus jitter.
 	// +kubebuilder:default=67
@@ -53,14 +107,46 @@ type VaultDynamicSecretSpec struct {
 	// are sometimes referred to as "static roles", or "static credentials", with a
 	// request path that contains "static-creds".
 	AllowStaticCreds bool `json:"allowStaticCreds,omitempty"`
+	// RefreshMode controls how the secret is kept up to date.
+	// Choices are `lease`, `poll`, or `static-creds`.
+	//
+	// If `lease` is set, the secret's lease is renewed, and new credentials are
+	// requested once it can no longer be renewed.
+	//
+	// If `poll` is set, new credentials are requested every RefreshAfter, and
+	// the secret's lease is never renewed. This is useful for endpoints that do
+	// not return a lease, e.g. `transit/datakey`, or for one-time credentials.
+	// If RefreshAfter is not set, the secret's lease duration is used instead.
+	//
+	// If `static-creds` is set, the credentials are synced after every rotation
+	// by the Vault server, see AllowStaticCreds.
+	//
+	// If not set, `static-creds` is used when AllowStaticCreds is true,
+	// otherwise `lease` is used. RefreshMode takes precedence over
+	// AllowStaticCreds.
+	// +kubebuilder:validation:Enum=lease;poll;static-creds
+	RefreshMode string `json:"refreshMode,omitempty"`
 	// RolloutRestartTargets should be configured whenever the application(s) consuming the Vault secret does
 	// not support dynamically reloading a rotated secret.
 	// In that case one, or more RolloutRestartTarget(s) can be configured here. The Operator will
 	// trigger a "rollout-restart" for each target whenever the Vault secret changes between reconciliation events.
 	// See RolloutRestartTarget for more details.
 	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows restricts the rollout-restarts of the
+	// RolloutRestartTargets to recurring windows. The secret is still synced on
+	// schedule, but its rollout-restarts are deferred until the start of the next
+	// window, and the resource has a RolloutRestartDeferred condition meanwhile.
+	// The rollout-restarts are not restricted if no windows are set.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
 	// Destination provides configuration necessary for syncing the Vault secret to Kubernetes.
 	Destination Destination `json:"destination"`
+	// UsernameKey is the destination Secret key that the `username` of the
+	// Vault secret data is synced to, instead of `username`. Use it to expose
+	// consistent key names regardless of the secrets engine, without templates.
+	UsernameKey string `json:"usernameKey,omitempty"`
+	// PasswordKey is the destination Secret key that the `password` of the
+	// Vault secret data is synced to, instead of `password`. See UsernameKey.
+	PasswordKey string `json:"passwordKey,omitempty"`
 	// RefreshAfter a period of time for VSO to sync the source secret data, in
 	// duration notation e.g. 30s, 1m, 24h. This value only needs to be set when
 	// syncing from a secret's engine that does not provide a lease TTL in its
@@ -70,6 +156,139 @@ type VaultDynamicSecretSpec struct {
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
 	RefreshAfter string `json:"refreshAfter,omitempty"`
+	// ExpiryFieldPath is a JSONPath expression into the Vault response data, e.g.
+	// `.expires_on`, that holds the expiry time of the credentials. This value only
+	// needs to be set when syncing from a secret's engine that returns the expiry
+	// in its response data rather than in the lease duration. The expiry must be
+	// an RFC 3339 timestamp or a Unix timestamp in seconds. When set, the refresh
+	// horizon is computed from the expiry time minus a clock skew tolerance, and
+	// the lease is never renewed, new credentials are requested instead. This
+	// value is ignored when AllowStaticCreds is true.
+	ExpiryFieldPath string `json:"expiryFieldPath,omitempty"`
+	// WrapTTL enables Vault response wrapping, in duration notation e.g. 30s, 1m,
+	// 24h. When set, only the response wrapping token is synced to the
+	// destination Secret's `token` key, and the workload must unwrap the secret
+	// itself before the token expires. The unwrap instructions are set in the
+	// destination Secret's `vso.hashicorp.com/unwrap` annotation. New credentials
+	// are requested before the token expires, or every RefreshAfter if it is sooner.
+	// The lease of the wrapped secret is never renewed nor revoked, and
+	// transformations, AllowStaticCreds, and ExpiryFieldPath are ignored.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	WrapTTL string `json:"wrapTTL,omitempty"`
+	// ShareLease with all VaultDynamicSecrets that request the same credentials,
+	// i.e. the same Mount, Path, and Params with the same VaultAuth. A single
+	// Vault lease is then requested and renewed on behalf of all of them, and
+	// its credentials are synced to each destination. The lease is revoked once
+	// the last resource sharing it is deleted. Ignored for static creds, polled
+	// and wrapped secrets, and the `ldap-library` Engine.
+	ShareLease bool `json:"shareLease,omitempty"`
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the secret is neither synced nor its lease renewed, and the
+	// resource has a Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// VaultDynamicSecretAWS configures the request of STS credentials from the AWS
+// secrets engine.
+type VaultDynamicSecretAWS struct {
+	// RoleARN of the AWS role to assume, it must be one of the role ARNs
+	// allowed by the Vault role. Required if the Vault role allows more than one.
+	RoleARN string `json:"roleARN,omitempty"`
+	// TTL of the STS credentials, in duration notation e.g. 15m, 1h.
+	// If not set, the Vault role's default TTL is used.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	TTL string `json:"ttl,omitempty"`
+	// SessionTags are passed through to the AWS session, the Vault role must be
+	// an `assumed_role` or `federation_token` role.
+	SessionTags map[string]string `json:"sessionTags,omitempty"`
+}
+
+// VaultDynamicSecretKubernetes configures the request of a service account
+// token from the Kubernetes secrets engine. Along with the data returned by
+// Vault, the token is synced to the "token" key, its namespace to the
+// "namespace" key, and the CA certificate and host of the Kubernetes API
+// server configured on the Mount to the "ca.crt" and "kubernetes_host" keys.
+// The kubeconfig template function can be used to render a kubeconfig from
+// these keys.
+type VaultDynamicSecretKubernetes struct {
+	// KubernetesNamespace in which the service account token is generated.
+	KubernetesNamespace string `json:"kubernetesNamespace"`
+	// ClusterRoleBinding binds the Vault role's Kubernetes role with a
+	// ClusterRoleBinding, rather than a RoleBinding in KubernetesNamespace.
+	ClusterRoleBinding bool `json:"clusterRoleBinding,omitempty"`
+	// TTL of the service account token, in duration notation e.g. 15m, 1h.
+	// If not set, the Vault role's default TTL is used.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	TTL string `json:"ttl,omitempty"`
+	// Audiences of the service account token. If not set, the Vault role's
+	// audiences are used.
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// VaultDynamicSecretConsul configures the Vault role of the tokens requested
+// from the Consul secrets engine. If Policies is set, the Vault role named by
+// Path is created, or updated, before requesting a token, which requires the
+// VaultAuth's policy to allow writing to the Mount's `roles` endpoint.
+// Otherwise, the Vault role must already exist.
+type VaultDynamicSecretConsul struct {
+	// Policies are the Consul ACL policies attached to the token.
+	Policies []string `json:"policies,omitempty"`
+	// TTL of the token's lease, in duration notation e.g. 15m, 1h.
+	// If not set, the Mount's default TTL is used.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	TTL string `json:"ttl,omitempty"`
+}
+
+// VaultDynamicSecretNomad configures the Vault role of the tokens requested
+// from the Nomad secrets engine. If Policies is set, or Type is `management`,
+// the Vault role named by Path is created, or updated, before requesting a
+// token, which requires the VaultAuth's policy to allow writing to the Mount's
+// `role` endpoint. Otherwise, the Vault role must already exist.
+type VaultDynamicSecretNomad struct {
+	// Policies are the Nomad ACL policies attached to the token, only used
+	// with the `client` Type.
+	Policies []string `json:"policies,omitempty"`
+	// Type of the token, either `client` or `management`.
+	// +kubebuilder:validation:Enum=client;management
+	// +kubebuilder:default=client
+	Type string `json:"type,omitempty"`
+	// Global replicates the token to all regions of the Nomad cluster.
+	Global bool `json:"global,omitempty"`
+}
+
+// VaultDynamicSecretLDAPLibrary configures the check-out of a service account
+// from an LDAP secrets engine library set. Checking the service account back
+// in requires the VaultAuth's policy to allow writing to the library set's
+// `check-in` endpoint.
+type VaultDynamicSecretLDAPLibrary struct {
+	// TTL of the check-out, in duration notation e.g. 15m, 1h.
+	// If not set, the library set's default TTL is used.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	TTL string `json:"ttl,omitempty"`
+}
+
+// ParamFromSource sets a request param from the value of a Secret or ConfigMap
+// key. Exactly one of SecretKeyRef or ConfigMapKeyRef must be set.
+type ParamFromSource struct {
+	// Name of the request param.
+	Name string `json:"name"`
+	// SecretKeyRef selects the Secret key that holds the param's value.
+	SecretKeyRef *SecretKeyRef `json:"secretKeyRef,omitempty"`
+	// ConfigMapKeyRef selects the ConfigMap key that holds the param's value.
+	ConfigMapKeyRef *ConfigMapKeyRef `json:"configMapKeyRef,omitempty"`
+}
+
+// SecretKeyRef selects a key of a Secret.
+type SecretKeyRef struct {
+	// Name of the Secret.
+	Name string `json:"name"`
+	// Key in the Secret's data.
+	Key string `json:"key"`
 }
 
 // VaultDynamicSecretStatus defines the observed state of VaultDynamicSecret
@@ -80,8 +299,19 @@ type VaultDynamicSecretStatus struct {
 	LastGeneration int64 `json:"lastGeneration"`
 	// SecretLease for the Vault secret.
 	SecretLease VaultSecretLease `json:"secretLease"`
+	// ExpiryTime of the Vault secret in Unix seconds, as extracted from the
+	// response data with VaultDynamicSecretSpec.ExpiryFieldPath, or the
+	// expiration of the credentials of the VaultDynamicSecretSpec.Engine.
+	ExpiryTime int64 `json:"expiryTime,omitempty"`
 	// StaticCredsMetaData contains the static creds response meta-data
 	StaticCredsMetaData VaultStaticCredsMetaData `json:"staticCredsMetaData,omitempty"`
+	// Credentials contains the meta-data of the synced credentials, it is only
+	// set for secrets engines that return a `username`, e.g. the database and
+	// RabbitMQ secrets engines.
+	Credentials VaultDynamicSecretCredentials `json:"credentials,omitempty"`
+	// CheckedOutAccount is the service account that is checked out from the
+	// LDAP library set, see VaultDynamicSecretSpec.LDAPLibrary.
+	CheckedOutAccount string `json:"checkedOutAccount,omitempty"`
 	// LastRuntimePodUID used for tracking the transition from one Pod to the next.
 	// It is used to mitigate the effects of a Vault lease renewal storm.
 	LastRuntimePodUID types.UID `json:"lastRuntimePodUID,omitempty"`
@@ -97,6 +327,12 @@ type VaultDynamicSecretStatus struct {
 	// VaultClientMeta contains the status of the Vault client and is used during
 	// resource reconciliation.
 	VaultClientMeta VaultClientMeta `json:"vaultClientMeta,omitempty"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// the outcome of rendering its templates.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
 }
 
 type VaultSecretLease struct {
@@ -126,6 +362,18 @@ type VaultStaticCredsMetaData struct {
 	TTL int64 `json:"ttl"`
 }
 
+// VaultDynamicSecretCredentials contains the meta-data of database-style
+// credentials.
+type VaultDynamicSecretCredentials struct {
+	// Username of the credentials.
+	Username string `json:"username,omitempty"`
+	// LastVaultRotation of the static credentials' password in Unix seconds.
+	LastVaultRotation int64 `json:"lastVaultRotation,omitempty"`
+	// Expiration of the credentials in Unix seconds, computed from the lease
+	// duration of dynamic credentials, or the TTL of static credentials.
+	Expiration int64 `json:"expiration,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultEventSubscriptionSpec defines the desired state of VaultEventSubscription
+type VaultEventSubscriptionSpec struct {
+	// VaultAuthRef to the VaultAuth resource, can be prefixed with a namespace,
+	// eg: `namespaceA/vaultAuthRefB`. If no namespace prefix is provided it will default to
+	// the namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator
+	// will default to the `default` VaultAuth, configured in the operator's namespace.
+	// The VaultAuth is used for streaming the events from Vault, on behalf of all
+	// the resources that reference the subscription.
+	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+
+	// Namespace in Vault to subscribe to the events of. If not set, the namespace
+	// that's part of VaultAuth resource will be inferred.
+	Namespace string `json:"namespace,omitempty"`
+
+	// EventTypes are the Vault event types to subscribe to, e.g.
+	// kv-v2/data-write. A "*" matches any sequence of characters, e.g. kv*
+	// matches all KV events. Defaults to kv*, i.e. the KV events of all mounts.
+	// Subscribing to more than one event type requires a Vault policy that
+	// allows subscribing to all event types, the events are filtered by Vault.
+	EventTypes []string `json:"eventTypes,omitempty"`
+
+	// PathFilters limit the events that are delivered to the subscribed
+	// resources to the matching Vault event paths, e.g. kv/data/team-a/*. A "*"
+	// matches any sequence of characters. Defaults to all paths.
+	PathFilters []string `json:"pathFilters,omitempty"`
+
+	// DebounceWindow is the period of time, in duration notation e.g. 5s, 1m,
+	// that the sync of a subscribed resource is delayed after an event. All
+	// events received during the window are coalesced into a single sync. It can
+	// be overridden by the subscribed resource.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	DebounceWindow string `json:"debounceWindow,omitempty"`
+
+	// AllowedNamespaces Kubernetes Namespaces which are allow-listed for use with
+	// this VaultEventSubscription. This field allows administrators to control
+	// which Kubernetes namespaces are authorized to receive Vault events through
+	// the subscription.
+	// Accepted values:
+	// []{"*"} - wildcard, all namespaces.
+	// []{"a", "b"} - list of namespaces.
+	// unset - disallow all namespaces except the VaultEventSubscription's
+	// namespace, this is the default behavior.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// VaultEventSubscriptionStatus defines the observed state of VaultEventSubscription
+type VaultEventSubscriptionStatus struct {
+	// Valid subscription configuration.
+	Valid *bool  `json:"valid"`
+	Error string `json:"error"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// VaultEventSubscription is the Schema for the vaulteventsubscriptions API. It
+// defines a Vault event subscription that is shared by all the resources that
+// reference it, e.g. VaultStaticSecrets with instant updates.
+type VaultEventSubscription struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VaultEventSubscriptionSpec   `json:"spec,omitempty"`
+	Status VaultEventSubscriptionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VaultEventSubscriptionList contains a list of VaultEventSubscription
+type VaultEventSubscriptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VaultEventSubscription `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VaultEventSubscription{}, &VaultEventSubscriptionList{})
+}
@@ -19,9 +19,16 @@ type VaultPKISecretSpec struct {
 	// the namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator
 	// will default to the `default` VaultAuth, configured in the operator's namespace.
 	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+	// VaultConnectionRef to the VaultConnection resource, overrides the
+	// VaultConnection of the VaultAuth, can be prefixed with a namespace, eg:
+	// `namespaceA/vaultConnectionRefB`. If no namespace prefix is provided it will
+	// default to the namespace of this resource. Only VaultConnections in this
+	// resource's namespace or the operator's namespace can be referenced.
+	VaultConnectionRef string `json:"vaultConnectionRef,omitempty"`
 
 	// Namespace of the secrets engine mount in Vault. If not set, the namespace that's
-	// part of VaultAuth resource will be inferred.
+	// part of VaultAuth resource will be inferred. A namespace prefixed with "+/" is
+	// relative to the VaultAuth's namespace, e.g. "+/team-a".
 	Namespace string `json:"namespace,omitempty"`
 
 	// Mount for the secret in Vault
@@ -43,6 +50,16 @@ type VaultPKISecretSpec struct {
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
 	ExpiryOffset string `json:"expiryOffset,omitempty"`
 
+	// RenewBefore is the duration before the certificate's NotAfter time at which
+	// it should be renewed. When set, it takes precedence over ExpiryOffset.
+	// The certificate is always renewed based on the earlier of its NotAfter
+	// time and the expiration reported by Vault, since the latter may not
+	// reflect TTL capping done by Vault.
+	// Should be in duration notation e.g. 30s, 120s, etc.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	RenewBefore string `json:"renewBefore,omitempty"`
+
 	// IssuerRef reference to an existing PKI issuer, either by Vault-generated
 	// identifier, the literal string default to refer to the currently
 	// configured default issuer, or the name assigned to an issuer.
@@ -55,15 +72,35 @@ type VaultPKISecretSpec struct {
 	// trigger a "rollout-restart" for each target whenever the Vault secret changes between reconciliation events.
 	// See RolloutRestartTarget for more details.
 	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows restricts the rollout-restarts of the
+	// RolloutRestartTargets to recurring windows. The secret is still synced on
+	// schedule, but its rollout-restarts are deferred until the start of the next
+	// window, and the resource has a RolloutRestartDeferred condition meanwhile.
+	// The rollout-restarts are not restricted if no windows are set.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
 
 	// Destination provides configuration necessary for syncing the Vault secret
 	// to Kubernetes. If the type is set to "kubernetes.io/tls", "tls.key" will
 	// be set to the "private_key" response from Vault, and "tls.crt" will be
 	// set to "certificate" + "ca_chain" from the Vault response ("issuing_ca"
 	// is used when "ca_chain" is empty). The "remove_roots_from_chain=true"
-	// option is used with Vault to exclude the root CA from the Vault response.
+	// option is used with Vault to exclude the root CA from the Vault response,
+	// unless IncludeRootCA is set. Destination.ChainOrder can be used to control
+	// the layout of the certificate chain.
 	Destination Destination `json:"destination"`
 
+	// Destinations are additional Kubernetes Secrets that the issued certificate
+	// is synced to, e.g. a "kubernetes.io/tls" Secret for an Ingress in
+	// Destination, and an Opaque Secret holding a JKS keystore rendered by its
+	// own Transformation. Each Destination is configured independently of the
+	// others, but the certificate is only issued once. The Name of each
+	// Destination must be unique, and differ from Destination's.
+	// Drift detection only applies to Destination.
+	Destinations []Destination `json:"destinations,omitempty"`
+
+	// IncludeRootCA in the CA chain returned by Vault.
+	IncludeRootCA bool `json:"includeRootCA,omitempty"`
+
 	// CommonName to include in the request.
 	CommonName string `json:"commonName,omitempty"`
 
@@ -118,12 +155,102 @@ type VaultPKISecretSpec struct {
 	// ExcludeCNFromSans from DNS or Email Subject Alternate Names.
 	// Default: false
 	ExcludeCNFromSans bool `json:"excludeCNFromSans,omitempty"`
+
+	// CSR configures the certificate to be signed by Vault from a certificate
+	// signing request, rather than being issued by Vault along with its private key.
+	// This ensures that the private key never leaves the cluster.
+	CSR *VaultPKISecretCSR `json:"csr,omitempty"`
+
+	// ACME configures the certificate to be obtained from the ACME server of the
+	// PKI Mount, rather than from its issue or sign endpoints. The operator
+	// completes the ACME flow, and syncs the locally generated private key along
+	// with the issued certificate. ACME must be enabled on the PKI Mount. Cannot
+	// be combined with CSR.
+	ACME *VaultPKISecretACME `json:"acme,omitempty"`
+
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the certificate is neither issued nor renewed, and the resource
+	// has a Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// VaultPKISecretACME configures how the operator obtains a certificate from
+// Vault's ACME server.
+type VaultPKISecretACME struct {
+	// DirectoryPath of the ACME server, relative to the Mount.
+	// If not set, "roles/<Role>/acme/directory" is used.
+	DirectoryPath string `json:"directoryPath,omitempty"`
+
+	// AccountKeySecretRef is the name of the Secret storing the ACME account's
+	// private key. The Secret must be in the same namespace as the VaultPKISecret.
+	// If it does not exist, the operator generates a new account key and creates
+	// the Secret. The Secret may be shared by other VaultPKISecrets.
+	AccountKeySecretRef string `json:"accountKeySecretRef"`
+
+	// ExternalAccountBinding requests new External Account Binding credentials
+	// from Vault when the ACME account is registered. Required when the PKI
+	// Mount's eab_policy enforces account binding.
+	ExternalAccountBinding bool `json:"externalAccountBinding,omitempty"`
+
+	// Solver for the ACME challenges, either "http-01" or "dns-01". The "http-01"
+	// solver requires the operator's HTTP-01 solver to be enabled, and reachable
+	// on port 80 of every domain. The "dns-01" solver publishes the challenge
+	// TXT records as external-dns DNSEndpoints.
+	// +kubebuilder:validation:Enum=http-01;dns-01
+	// +kubebuilder:default=http-01
+	Solver string `json:"solver,omitempty"`
+
+	// KeyType of the private key generated by the operator, either "rsa" or
+	// "ec".
+	// +kubebuilder:validation:Enum=rsa;ec
+	// +kubebuilder:default=ec
+	KeyType string `json:"keyType,omitempty"`
+
+	// KeyBits of the private key generated by the operator. If not set, 2048 is
+	// used for "rsa", and 256 is used for "ec".
+	KeyBits int `json:"keyBits,omitempty"`
+}
+
+// VaultPKISecretCSR configures how the certificate signing request is obtained,
+// and which Vault endpoint is used to sign it.
+type VaultPKISecretCSR struct {
+	// Mode of signing, either "sign" or "sign-verbatim". With "sign", the values
+	// from the request (CommonName, AltNames, etc.) take precedence over those in
+	// the CSR. With "sign-verbatim", the certificate is signed with the values
+	// from the CSR.
+	// +kubebuilder:validation:Enum=sign;sign-verbatim
+	// +kubebuilder:default=sign
+	Mode string `json:"mode,omitempty"`
+
+	// SecretRef is the name of the Secret containing a PEM encoded CSR. The Secret
+	// must be in the same namespace as the VaultPKISecret. The private key must be
+	// managed externally, e.g. by cert-manager, since it is not synced to the
+	// Destination. If not set, the operator generates the private key and CSR,
+	// syncing the private key along with the signed certificate.
+	SecretRef string `json:"secretRef,omitempty"`
+
+	// SecretKey in SecretRef containing the CSR.
+	// +kubebuilder:default=tls.csr
+	SecretKey string `json:"secretKey,omitempty"`
+
+	// KeyType of the private key generated by the operator, either "rsa", "ec",
+	// or "ed25519". Not used when SecretRef is set.
+	// +kubebuilder:validation:Enum=rsa;ec;ed25519
+	// +kubebuilder:default=ec
+	KeyType string `json:"keyType,omitempty"`
+
+	// KeyBits of the private key generated by the operator. If not set, 2048 is
+	// used for "rsa", and 256 is used for "ec". Not used for "ed25519", or when
+	// SecretRef is set.
+	KeyBits int `json:"keyBits,omitempty"`
 }
 
 // VaultPKISecretStatus defines the observed state of VaultPKISecret
 type VaultPKISecretStatus struct {
 	SerialNumber string `json:"serialNumber,omitempty"`
 	Expiration   int64  `json:"expiration,omitempty"`
+	// NotAfter is the expiry of the issued certificate, as a Unix timestamp.
+	NotAfter int64 `json:"notAfter,omitempty"`
 	// LastGeneration is the Generation of the last reconciled resource.
 	LastGeneration int64 `json:"lastGeneration"`
 	// LastLastRotation of the certificate.
@@ -138,6 +265,12 @@ type VaultPKISecretStatus struct {
 	SecretMAC string `json:"secretMAC,omitempty"`
 	Valid     *bool  `json:"valid"`
 	Error     string `json:"error"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// the outcome of rendering its templates.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -163,7 +296,7 @@ func (v *VaultPKISecret) GetIssuerAPIData() map[string]interface{} {
 		"ttl":                     v.Spec.TTL,
 		"not_after":               v.Spec.NotAfter,
 		"exclude_cn_from_sans":    v.Spec.ExcludeCNFromSans,
-		"remove_roots_from_chain": true,
+		"remove_roots_from_chain": !v.Spec.IncludeRootCA,
 	}
 
 	if v.Spec.Format != "" {
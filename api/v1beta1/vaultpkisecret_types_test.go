@@ -96,6 +96,25 @@ func TestVaultPKISecret_GetIssuerAPIData(t *testing.T) {
 				"remove_roots_from_chain": true,
 			},
 		},
+		{
+			name: "include-root-ca",
+			spec: VaultPKISecretSpec{
+				CommonName:    "qux",
+				IncludeRootCA: true,
+			},
+			want: map[string]interface{}{
+				"common_name":             "qux",
+				"alt_names":               "",
+				"ip_sans":                 "",
+				"uri_sans":                "",
+				"other_sans":              "",
+				"user_ids":                "",
+				"ttl":                     "",
+				"not_after":               "",
+				"exclude_cn_from_sans":    false,
+				"remove_roots_from_chain": false,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultSecretExportSpec defines the desired state of VaultSecretExport
+type VaultSecretExportSpec struct {
+	// VaultAuthRef to the VaultAuth resource, can be prefixed with a namespace,
+	// eg: `namespaceA/vaultAuthRefB`. If no namespace prefix is provided it will default to
+	// the namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator
+	// will default to the `default` VaultAuth, configured in the operator's namespace.
+	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+
+	// Namespace of the secrets engine mount in Vault. If not set, the namespace that's
+	// part of VaultAuth resource will be inferred. A namespace prefixed with "+/" is
+	// relative to the VaultAuth's namespace, e.g. "+/team-a".
+	Namespace string `json:"namespace,omitempty"`
+
+	// Mount of the KV-v2 secrets engine in Vault.
+	Mount string `json:"mount"`
+
+	// Path of the secret in Vault, relative to the Mount.
+	Path string `json:"path"`
+
+	// Source is the Kubernetes Secret whose data is exported to Vault.
+	Source VaultSecretExportSource `json:"source"`
+
+	// ConflictPolicy decides how a conflicting write to the Vault secret is
+	// handled. A conflict is detected when the current version of the Vault
+	// secret was not written by the operator, e.g. it was modified in Vault, or
+	// it already existed on the initial export. With "Fail" the secret is not
+	// exported until the conflict is resolved, either by deleting the Vault
+	// secret or by changing the ConflictPolicy. With "Overwrite" a new version of
+	// the Vault secret is always written.
+	// +kubebuilder:validation:Enum=Fail;Overwrite
+	// +kubebuilder:default=Fail
+	ConflictPolicy string `json:"conflictPolicy,omitempty"`
+
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the secret is not exported to Vault, and the resource has a
+	// Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// VaultSecretExportSource is the Kubernetes Secret that is exported to Vault.
+type VaultSecretExportSource struct {
+	// Name of the Secret, in the namespace of the VaultSecretExport.
+	Name string `json:"name"`
+
+	// Keys of the Secret's data to export. If not set, all keys are exported.
+	// The values are written to Vault as strings.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// VaultSecretExportStatus defines the observed state of VaultSecretExport
+type VaultSecretExportStatus struct {
+	// LastGeneration is the Generation of the last reconciled resource.
+	LastGeneration int64 `json:"lastGeneration"`
+	// Version of the Vault secret that was last written by the operator. It is
+	// used as the check-and-set version of the next write.
+	Version int `json:"version,omitempty"`
+	// LastExportTime of the Source's data, in Unix seconds.
+	LastExportTime int64 `json:"lastExportTime,omitempty"`
+	// SecretMAC of the last exported Source data, it is used to decide whether
+	// the Source's data has changed and must be exported again.
+	SecretMAC string `json:"secretMAC,omitempty"`
+	Valid     *bool  `json:"valid"`
+	Error     string `json:"error"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// a conflicting write to the Vault secret.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// VaultSecretExport is the Schema for the vaultsecretexports API
+type VaultSecretExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VaultSecretExportSpec   `json:"spec,omitempty"`
+	Status VaultSecretExportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VaultSecretExportList contains a list of VaultSecretExport
+type VaultSecretExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VaultSecretExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VaultSecretExport{}, &VaultSecretExportList{})
+}
@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultSSHCertificateSpec defines the desired state of VaultSSHCertificate
+type VaultSSHCertificateSpec struct {
+	// VaultAuthRef to the VaultAuth resource, can be prefixed with a namespace,
+	// eg: `namespaceA/vaultAuthRefB`. If no namespace prefix is provided it will default to
+	// the namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator
+	// will default to the `default` VaultAuth, configured in the operator's namespace.
+	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+
+	// Namespace of the secrets engine mount in Vault. If not set, the namespace that's
+	// part of VaultAuth resource will be inferred. A namespace prefixed with "+/" is
+	// relative to the VaultAuth's namespace, e.g. "+/team-a".
+	Namespace string `json:"namespace,omitempty"`
+
+	// Mount of the SSH secrets engine in Vault.
+	Mount string `json:"mount"`
+
+	// Role in Vault to use when signing the public key.
+	Role string `json:"role"`
+
+	// CertType of the certificate, either "user" or "host".
+	// +kubebuilder:validation:Enum=user;host
+	// +kubebuilder:default=user
+	CertType string `json:"certType,omitempty"`
+
+	// ValidPrincipals to include in the certificate, i.e. the usernames for a
+	// user certificate, or the hostnames for a host certificate.
+	// If not set, the Vault role's default is used.
+	ValidPrincipals []string `json:"validPrincipals,omitempty"`
+
+	// KeyID of the certificate. If not set, the Vault role's key ID format is
+	// used.
+	KeyID string `json:"keyID,omitempty"`
+
+	// TTL for the certificate; sets its valid_before time.
+	// If not specified the Vault role's default,
+	// backend default, or system default TTL is used, in that order.
+	// Cannot be larger than the role's max TTL.
+	// Should be in duration notation e.g. 120s, 2h, etc.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h|d))$`
+	TTL string `json:"ttl,omitempty"`
+
+	// CriticalOptions to include in the certificate, e.g. force-command.
+	// They must be allowed by the Vault role.
+	CriticalOptions map[string]string `json:"criticalOptions,omitempty"`
+
+	// Extensions to include in the certificate, e.g. permit-pty.
+	// They must be allowed by the Vault role.
+	Extensions map[string]string `json:"extensions,omitempty"`
+
+	// RenewBefore is the duration before the certificate's valid_before time at
+	// which it should be renewed. If not set, the certificate is renewed after
+	// two thirds of its validity period.
+	// Should be in duration notation e.g. 30s, 120s, etc.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	RenewBefore string `json:"renewBefore,omitempty"`
+
+	// KeyPair configures the key pair whose public key is signed by Vault.
+	// If not set, the operator generates an ed25519 key pair.
+	KeyPair *VaultSSHCertificateKeyPair `json:"keyPair,omitempty"`
+
+	// RolloutRestartTargets should be configured whenever the application(s) consuming the Vault secret does
+	// not support dynamically reloading a rotated secret.
+	// In that case one, or more RolloutRestartTarget(s) can be configured here. The Operator will
+	// trigger a "rollout-restart" for each target whenever the Vault secret changes between reconciliation events.
+	// See RolloutRestartTarget for more details.
+	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows restricts the rollout-restarts of the
+	// RolloutRestartTargets to recurring windows. The secret is still synced on
+	// schedule, but its rollout-restarts are deferred until the start of the next
+	// window, and the resource has a RolloutRestartDeferred condition meanwhile.
+	// The rollout-restarts are not restricted if no windows are set.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
+
+	// Destination provides configuration necessary for syncing the signed
+	// certificate to Kubernetes. The Secret holds the "ssh-certificate", the
+	// signed "ssh-publickey", the "ca.pub" of the Mount's CA, and a
+	// "known_hosts" entry trusting that CA. The "ssh-privatekey" is included
+	// if the key pair is generated by the operator.
+	Destination Destination `json:"destination"`
+
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the certificate is neither signed nor renewed, and the resource
+	// has a Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// VaultSSHCertificateKeyPair configures how the public key to be signed is
+// obtained.
+type VaultSSHCertificateKeyPair struct {
+	// SecretRef is the name of the Secret containing the public key, in the
+	// authorized_keys format. The Secret must be in the same namespace as the
+	// VaultSSHCertificate. The private key must be managed externally, since it
+	// is not synced to the Destination. If not set, the operator generates the
+	// key pair, syncing the private key along with the signed certificate.
+	SecretRef string `json:"secretRef,omitempty"`
+
+	// SecretKey in SecretRef containing the public key.
+	// +kubebuilder:default=ssh-publickey
+	SecretKey string `json:"secretKey,omitempty"`
+
+	// KeyType of the private key generated by the operator, either "rsa", "ec",
+	// or "ed25519". Not used when SecretRef is set.
+	// +kubebuilder:validation:Enum=rsa;ec;ed25519
+	// +kubebuilder:default=ed25519
+	KeyType string `json:"keyType,omitempty"`
+
+	// KeyBits of the private key generated by the operator. If not set, 2048 is
+	// used for "rsa", and 256 is used for "ec". Not used for "ed25519", or when
+	// SecretRef is set.
+	KeyBits int `json:"keyBits,omitempty"`
+}
+
+// VaultSSHCertificateStatus defines the observed state of VaultSSHCertificate
+type VaultSSHCertificateStatus struct {
+	// SerialNumber of the signed certificate.
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// ValidAfter is the start of the certificate's validity, as a Unix timestamp.
+	ValidAfter int64 `json:"validAfter,omitempty"`
+	// ValidBefore is the expiry of the certificate, as a Unix timestamp.
+	ValidBefore int64 `json:"validBefore,omitempty"`
+	// LastGeneration is the Generation of the last reconciled resource.
+	LastGeneration int64 `json:"lastGeneration"`
+	// LastRotation of the certificate.
+	LastRotation int64 `json:"lastRotation"`
+	// SecretMAC used when deciding whether new Vault secret data should be synced.
+	//
+	// The controller will compare the "new" Vault secret data to this value using HMAC,
+	// if they are different, then the data will be synced to the Destination.
+	//
+	// The SecretMac is also used to detect drift in the Destination Secret's Data.
+	// If drift is detected the data will be synced to the Destination.
+	SecretMAC string `json:"secretMAC,omitempty"`
+	Valid     *bool  `json:"valid"`
+	Error     string `json:"error"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// the outcome of rendering its templates.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// VaultSSHCertificate is the Schema for the vaultsshcertificates API
+type VaultSSHCertificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VaultSSHCertificateSpec   `json:"spec,omitempty"`
+	Status VaultSSHCertificateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VaultSSHCertificateList contains a list of VaultSSHCertificate
+type VaultSSHCertificateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VaultSSHCertificate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VaultSSHCertificate{}, &VaultSSHCertificateList{})
+}
@@ -17,8 +17,15 @@ type VaultStaticSecretSpec struct {
 	// namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator will
 	// default to the `default` VaultAuth, configured in the operator's namespace.
 	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+	// VaultConnectionRef to the VaultConnection resource, overrides the
+	// VaultConnection of the VaultAuth, can be prefixed with a namespace, eg:
+	// `namespaceA/vaultConnectionRefB`. If no namespace prefix is provided it will
+	// default to the namespace of this resource. Only VaultConnections in this
+	// resource's namespace or the operator's namespace can be referenced.
+	VaultConnectionRef string `json:"vaultConnectionRef,omitempty"`
 	// Namespace of the secrets engine mount in Vault. If not set, the namespace that's
-	// part of VaultAuth resource will be inferred.
+	// part of VaultAuth resource will be inferred. A namespace prefixed with "+/" is
+	// relative to the VaultAuth's namespace, e.g. "+/team-a".
 	Namespace string `json:"namespace,omitempty"`
 	// Mount for the secret in Vault
 	Mount string `json:"mount"`
@@ -28,6 +35,8 @@ type VaultStaticSecretSpec struct {
 	Path string `json:"path"`
 	// Version of the secret to fetch. Only valid for type kv-v2. Corresponds to version query parameter:
 	// https://developer.hashicorp.com/vault/api-docs/secret/kv/kv-v2#version
+	// When set, the secret's current version is read from its metadata, and the
+	// SecretVersionCurrent condition reports whether the pinned version is behind it.
 	// +kubebuilder:validation:Minimum=0
 	Version int `json:"version,omitempty"`
 	// Type of the Vault static secret
@@ -51,10 +60,66 @@ type VaultStaticSecretSpec struct {
 	// All configured targets will be ignored if HMACSecretData is set to false.
 	// See RolloutRestartTarget for more details.
 	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows restricts the rollout-restarts of the
+	// RolloutRestartTargets to recurring windows. The secret is still synced on
+	// schedule, but its rollout-restarts are deferred until the start of the next
+	// window, and the resource has a RolloutRestartDeferred condition meanwhile.
+	// The rollout-restarts are not restricted if no windows are set.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
 	// Destination provides configuration necessary for syncing the Vault secret to Kubernetes.
 	Destination Destination `json:"destination"`
 	// SyncConfig configures sync behavior from Vault to VSO
 	SyncConfig *SyncConfig `json:"syncConfig,omitempty"`
+	// TransitDecrypt decrypts the secret data fields that hold Vault Transit
+	// ciphertext before they are synced to the Destination.
+	TransitDecrypt *TransitDecrypt `json:"transitDecrypt,omitempty"`
+	// WrapTTL enables Vault response wrapping, in duration notation e.g. 30s, 1m,
+	// 24h. When set, only the response wrapping token is synced to the
+	// destination Secret's `token` key, and the workload must unwrap the secret
+	// itself before the token expires. The unwrap instructions are set in the
+	// destination Secret's `vso.hashicorp.com/unwrap` annotation. A new token is
+	// synced before the token expires, or every RefreshAfter if it is sooner.
+	// Transformations and TransitDecrypt are ignored, and RolloutRestartTargets
+	// are never restarted.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	WrapTTL string `json:"wrapTTL,omitempty"`
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the secret is neither read from Vault nor synced, and the
+	// resource has a Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
+	// OnSourceDelete sets how the destination Secret is handled when the secret
+	// is deleted in Vault, or the KV-v2 secret's version is deleted or destroyed.
+	// Choices are `retain`, `empty`, or `delete`. If `retain` is set, the
+	// destination Secret is left unchanged. If `empty` is set, all of its data is
+	// removed. If `delete` is set, the destination Secret is deleted if it was
+	// created by the operator, otherwise all of its data is removed. The resource
+	// has a SourceDeleted condition until the secret is recreated in Vault.
+	// +kubebuilder:validation:Enum=retain;empty;delete
+	// +kubebuilder:default=retain
+	OnSourceDelete string `json:"onSourceDelete,omitempty"`
+}
+
+// TransitDecrypt configures the decryption of the secret data fields that hold
+// Vault Transit ciphertext, e.g. `vault:v1:...`.
+type TransitDecrypt struct {
+	// Mount of the transit secrets engine in Vault, it resides in the same Vault
+	// namespace as the secret.
+	Mount string `json:"mount"`
+	// Key is the name of the transit key that encrypted the ciphertext.
+	Key string `json:"key"`
+	// Fields contains regex patterns used to select the top-level secret data
+	// fields that hold ciphertext. The values of the selected fields must be
+	// strings. Templates are rendered with the decrypted values.
+	// +kubebuilder:validation:MinItems=1
+	Fields []string `json:"fields"`
+	// Operation to perform on the selected fields. Choices are `decrypt` or
+	// `rewrap`. If `decrypt` is set, the plaintext is synced. If `rewrap` is
+	// set, the ciphertext is rewrapped with the latest version of Key, and the
+	// rewrapped ciphertext is synced.
+	// +kubebuilder:validation:Enum=decrypt;rewrap
+	// +kubebuilder:default=decrypt
+	Operation string `json:"operation,omitempty"`
 }
 
 // SyncConfig configures sync behavior from Vault to VSO
@@ -62,6 +127,40 @@ type SyncConfig struct {
 	// InstantUpdates is a flag to indicate that event-driven updates are
 	// enabled for this VaultStaticSecret
 	InstantUpdates bool `json:"instantUpdates,omitempty"`
+	// InstantUpdatesConfig configures the Vault events that trigger instant
+	// updates. It is only used when InstantUpdates is enabled.
+	InstantUpdatesConfig *InstantUpdatesConfig `json:"instantUpdatesConfig,omitempty"`
+	// EventSubscriptionRef to the VaultEventSubscription resource that the
+	// instant updates are received from, can be prefixed with a namespace, eg:
+	// `namespaceA/vaultEventSubscriptionB`. If no namespace prefix is provided
+	// it will default to the namespace of the VaultStaticSecret CR. The event
+	// types and the VaultAuth of the subscription are used, the EventTypes of
+	// the InstantUpdatesConfig must not be set. It is only used when
+	// InstantUpdates is enabled.
+	EventSubscriptionRef string `json:"eventSubscriptionRef,omitempty"`
+	// Backoff overrides the operator's back-off of the failed sync attempts.
+	Backoff *BackoffConfig `json:"backoff,omitempty"`
+}
+
+// InstantUpdatesConfig configures the Vault events that trigger the sync of a
+// VaultStaticSecret with instant updates.
+type InstantUpdatesConfig struct {
+	// EventTypes are the Vault event types to subscribe to, e.g.
+	// kv-v2/data-write, or database/rotate. A "*" matches any sequence of
+	// characters, e.g. kv* matches all KV events. Defaults to kv*.
+	// Subscribing to more than one event type requires a Vault policy that
+	// allows subscribing to all event types, the events are filtered by Vault.
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// PathFilters are the Vault event paths that trigger a sync, e.g.
+	// database/static-roles/app. A "*" matches any sequence of characters.
+	// Defaults to the path of the secret.
+	PathFilters []string `json:"pathFilters,omitempty"`
+	// DebounceWindow is the period of time, in duration notation e.g. 5s, 1m,
+	// that the sync is delayed after an event. All events received during the
+	// window are coalesced into a single sync.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	DebounceWindow string `json:"debounceWindow,omitempty"`
 }
 
 // VaultStaticSecretStatus defines the observed state of VaultStaticSecret
@@ -76,6 +175,33 @@ type VaultStaticSecretStatus struct {
 	// The SecretMac is also used to detect drift in the Destination Secret's Data.
 	// If drift is detected the data will be synced to the Destination.
 	SecretMAC string `json:"secretMAC,omitempty"`
+	// SecretVersion is the version of the KV-v2 secret that was last synced.
+	SecretVersion int `json:"secretVersion,omitempty"`
+	// CurrentSecretVersion is the current version of the KV-v2 secret in Vault.
+	// It is greater than SecretVersion when a pinned Version falls behind.
+	CurrentSecretVersion int `json:"currentSecretVersion,omitempty"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// the outcome of rendering its templates.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// EventWatcher reports the health of the Vault event watcher, it is only
+	// set when InstantUpdates is enabled.
+	EventWatcher *VaultStaticSecretEventWatcher `json:"eventWatcher,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
+}
+
+// VaultStaticSecretEventWatcher reports the health of the Vault event watcher
+// of a VaultStaticSecret.
+type VaultStaticSecretEventWatcher struct {
+	// Connected is true when the event watcher is connected to Vault.
+	Connected bool `json:"connected"`
+	// LastEventTime is the time, in Unix seconds, of the last Vault event that
+	// matched the secret.
+	LastEventTime int64 `json:"lastEventTime,omitempty"`
+	// ReconnectCount is the number of times the event watcher reconnected to
+	// Vault.
+	ReconnectCount int `json:"reconnectCount,omitempty"`
 }
 
 // +kubebuilder:object:root=true
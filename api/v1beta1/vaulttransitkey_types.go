@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultTransitKeySpec defines the desired state of VaultTransitKey
+type VaultTransitKeySpec struct {
+	// VaultAuthRef to the VaultAuth resource, can be prefixed with a namespace,
+	// eg: `namespaceA/vaultAuthRefB`. If no namespace prefix is provided it will default to
+	// the namespace of the VaultAuth CR. If no value is specified for VaultAuthRef the Operator
+	// will default to the `default` VaultAuth, configured in the operator's namespace.
+	VaultAuthRef string `json:"vaultAuthRef,omitempty"`
+
+	// Namespace of the secrets engine mount in Vault. If not set, the namespace that's
+	// part of VaultAuth resource will be inferred. A namespace prefixed with "+/" is
+	// relative to the VaultAuth's namespace, e.g. "+/team-a".
+	Namespace string `json:"namespace,omitempty"`
+
+	// Mount of the transit secrets engine in Vault.
+	Mount string `json:"mount"`
+
+	// Key is the name of the transit key that encrypts the data keys.
+	Key string `json:"key"`
+
+	// DataKeyType of the data keys requested from Vault, either "plaintext" or
+	// "wrapped". With "plaintext", both the data key and its ciphertext are
+	// synced. With "wrapped", only the ciphertext of the data key, wrapped by
+	// Key, is synced, the application must decrypt it with Vault.
+	// +kubebuilder:validation:Enum=plaintext;wrapped
+	// +kubebuilder:default=plaintext
+	DataKeyType string `json:"dataKeyType,omitempty"`
+
+	// Bits of the data keys.
+	// +kubebuilder:validation:Enum=128;256;512
+	// +kubebuilder:default=256
+	Bits int `json:"bits,omitempty"`
+
+	// RotationPeriod after which a new data key is requested from Vault.
+	// Should be in duration notation e.g. 30m, 24h, etc.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(s|m|h))$`
+	// +kubebuilder:default="24h"
+	RotationPeriod string `json:"rotationPeriod,omitempty"`
+
+	// RetainVersions is the number of previous data keys that are retained in
+	// the Destination, so that the data encrypted by them can still be
+	// decrypted.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default=2
+	RetainVersions int `json:"retainVersions,omitempty"`
+
+	// RolloutRestartTargets should be configured whenever the application(s) consuming the Vault secret does
+	// not support dynamically reloading a rotated secret.
+	// In that case one, or more RolloutRestartTarget(s) can be configured here. The Operator will
+	// trigger a "rollout-restart" for each target whenever the Vault secret changes between reconciliation events.
+	// See RolloutRestartTarget for more details.
+	RolloutRestartTargets []RolloutRestartTarget `json:"rolloutRestartTargets,omitempty"`
+	// RolloutRestartWindows restricts the rollout-restarts of the
+	// RolloutRestartTargets to recurring windows. The secret is still synced on
+	// schedule, but its rollout-restarts are deferred until the start of the next
+	// window, and the resource has a RolloutRestartDeferred condition meanwhile.
+	// The rollout-restarts are not restricted if no windows are set.
+	RolloutRestartWindows []RolloutRestartWindow `json:"rolloutRestartWindows,omitempty"`
+
+	// Destination provides configuration necessary for syncing the data keys to
+	// Kubernetes. The current data key is synced as "plaintext" and "ciphertext",
+	// along with its "version". Every retained data key, including the current
+	// one, is also synced as "plaintext-<version>" and "ciphertext-<version>".
+	// The plaintext keys are base64 encoded, and omitted for the "wrapped"
+	// DataKeyType.
+	Destination Destination `json:"destination"`
+
+	// Suspend the sync of the resource, e.g. during a maintenance window. While
+	// suspended, the data keys are neither generated nor rotated, and the resource
+	// has a Paused condition. Resuming the resource syncs it.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// VaultTransitDataKey is a data key that was generated by Vault.
+type VaultTransitDataKey struct {
+	// Version of the data key, incremented on every rotation.
+	Version int64 `json:"version"`
+	// KeyVersion of the transit key that encrypted the data key.
+	KeyVersion int `json:"keyVersion,omitempty"`
+	// Ciphertext of the data key.
+	Ciphertext string `json:"ciphertext"`
+}
+
+// VaultTransitKeyStatus defines the observed state of VaultTransitKey
+type VaultTransitKeyStatus struct {
+	// DataKeys are the current and retained data keys, oldest first. Only the
+	// ciphertext of the data keys is stored.
+	DataKeys []VaultTransitDataKey `json:"dataKeys,omitempty"`
+	// LastGeneration is the Generation of the last reconciled resource.
+	LastGeneration int64 `json:"lastGeneration"`
+	// LastRotation of the data key.
+	LastRotation int64 `json:"lastRotation"`
+	// SecretMAC used when deciding whether new Vault secret data should be synced.
+	//
+	// The controller will compare the "new" Vault secret data to this value using HMAC,
+	// if they are different, then the data will be synced to the Destination.
+	//
+	// The SecretMac is also used to detect drift in the Destination Secret's Data.
+	// If drift is detected the data will be synced to the Destination.
+	SecretMAC string `json:"secretMAC,omitempty"`
+	Valid     *bool  `json:"valid"`
+	Error     string `json:"error"`
+	// Conditions hold the latest observations of the resource's state, such as
+	// the outcome of rendering its templates.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// BackOff reports the back-off of the failing sync attempts, it is only set
+	// while the sync is failing.
+	BackOff *BackOffStatus `json:"backOff,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// VaultTransitKey is the Schema for the vaulttransitkeys API
+type VaultTransitKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VaultTransitKeySpec   `json:"spec,omitempty"`
+	Status VaultTransitKeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VaultTransitKeyList contains a list of VaultTransitKey
+type VaultTransitKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VaultTransitKey `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VaultTransitKey{}, &VaultTransitKeyList{})
+}
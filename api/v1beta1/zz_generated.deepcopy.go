@@ -12,6 +12,51 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackOffStatus) DeepCopyInto(out *BackOffStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackOffStatus.
+func (in *BackOffStatus) DeepCopy() *BackOffStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackOffStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackoffConfig) DeepCopyInto(out *BackoffConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackoffConfig.
+func (in *BackoffConfig) DeepCopy() *BackoffConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BackoffConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyRef) DeepCopyInto(out *ConfigMapKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyRef.
+func (in *ConfigMapKeyRef) DeepCopy() *ConfigMapKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Destination) DeepCopyInto(out *Destination) {
 	*out = *in
@@ -29,6 +74,18 @@ func (in *Destination) DeepCopyInto(out *Destination) {
 			(*out)[key] = val
 		}
 	}
+	if in.ImmutableHistoryLimit != nil {
+		in, out := &in.ImmutableHistoryLimit, &out.ImmutableHistoryLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.KeyMap != nil {
+		in, out := &in.KeyMap, &out.KeyMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	in.Transformation.DeepCopyInto(&out.Transformation)
 }
 
@@ -129,6 +186,11 @@ func (in *HCPAuthSpec) DeepCopyInto(out *HCPAuthSpec) {
 		*out = new(HCPAuthServicePrincipal)
 		**out = **in
 	}
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(HCPAuthWorkloadIdentity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPAuthSpec.
@@ -161,6 +223,26 @@ func (in *HCPAuthStatus) DeepCopy() *HCPAuthStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HCPAuthWorkloadIdentity) DeepCopyInto(out *HCPAuthWorkloadIdentity) {
+	*out = *in
+	if in.TokenAudiences != nil {
+		in, out := &in.TokenAudiences, &out.TokenAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPAuthWorkloadIdentity.
+func (in *HCPAuthWorkloadIdentity) DeepCopy() *HCPAuthWorkloadIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(HCPAuthWorkloadIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HCPVaultSecretsApp) DeepCopyInto(out *HCPVaultSecretsApp) {
 	*out = *in
@@ -226,6 +308,13 @@ func (in *HCPVaultSecretsAppSpec) DeepCopyInto(out *HCPVaultSecretsAppSpec) {
 	if in.RolloutRestartTargets != nil {
 		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
 		*out = make([]RolloutRestartTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
 		copy(*out, *in)
 	}
 	in.Destination.DeepCopyInto(&out.Destination)
@@ -254,6 +343,23 @@ func (in *HCPVaultSecretsAppStatus) DeepCopyInto(out *HCPVaultSecretsAppStatus)
 		*out = make([]HVSDynamicStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.RotatingSecrets != nil {
+		in, out := &in.RotatingSecrets, &out.RotatingSecrets
+		*out = make([]HVSRotatingStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsAppStatus.
@@ -267,138 +373,106 @@ func (in *HCPVaultSecretsAppStatus) DeepCopy() *HCPVaultSecretsAppStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HVSDynamicStatus) DeepCopyInto(out *HVSDynamicStatus) {
+func (in *HCPVaultSecretsProject) DeepCopyInto(out *HCPVaultSecretsProject) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSDynamicStatus.
-func (in *HVSDynamicStatus) DeepCopy() *HVSDynamicStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsProject.
+func (in *HCPVaultSecretsProject) DeepCopy() *HCPVaultSecretsProject {
 	if in == nil {
 		return nil
 	}
-	out := new(HVSDynamicStatus)
+	out := new(HCPVaultSecretsProject)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HVSDynamicSyncConfig) DeepCopyInto(out *HVSDynamicSyncConfig) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSDynamicSyncConfig.
-func (in *HVSDynamicSyncConfig) DeepCopy() *HVSDynamicSyncConfig {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HCPVaultSecretsProject) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(HVSDynamicSyncConfig)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HVSSyncConfig) DeepCopyInto(out *HVSSyncConfig) {
+func (in *HCPVaultSecretsProjectApp) DeepCopyInto(out *HCPVaultSecretsProjectApp) {
 	*out = *in
-	if in.Dynamic != nil {
-		in, out := &in.Dynamic, &out.Dynamic
-		*out = new(HVSDynamicSyncConfig)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSSyncConfig.
-func (in *HVSSyncConfig) DeepCopy() *HVSSyncConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsProjectApp.
+func (in *HCPVaultSecretsProjectApp) DeepCopy() *HCPVaultSecretsProjectApp {
 	if in == nil {
 		return nil
 	}
-	out := new(HVSSyncConfig)
+	out := new(HCPVaultSecretsProjectApp)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MergeStrategy) DeepCopyInto(out *MergeStrategy) {
+func (in *HCPVaultSecretsProjectAppTemplate) DeepCopyInto(out *HCPVaultSecretsProjectAppTemplate) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MergeStrategy.
-func (in *MergeStrategy) DeepCopy() *MergeStrategy {
-	if in == nil {
-		return nil
+	if in.RolloutRestartTargets != nil {
+		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
+		*out = make([]RolloutRestartTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(MergeStrategy)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutRestartTarget) DeepCopyInto(out *RolloutRestartTarget) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutRestartTarget.
-func (in *RolloutRestartTarget) DeepCopy() *RolloutRestartTarget {
-	if in == nil {
-		return nil
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
+		copy(*out, *in)
+	}
+	in.Destination.DeepCopyInto(&out.Destination)
+	if in.SyncConfig != nil {
+		in, out := &in.SyncConfig, &out.SyncConfig
+		*out = new(HVSSyncConfig)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(RolloutRestartTarget)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretTransformation) DeepCopyInto(out *SecretTransformation) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformation.
-func (in *SecretTransformation) DeepCopy() *SecretTransformation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsProjectAppTemplate.
+func (in *HCPVaultSecretsProjectAppTemplate) DeepCopy() *HCPVaultSecretsProjectAppTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretTransformation)
+	out := new(HCPVaultSecretsProjectAppTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SecretTransformation) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretTransformationList) DeepCopyInto(out *SecretTransformationList) {
+func (in *HCPVaultSecretsProjectList) DeepCopyInto(out *HCPVaultSecretsProjectList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SecretTransformation, len(*in))
+		*out = make([]HCPVaultSecretsProject, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformationList.
-func (in *SecretTransformationList) DeepCopy() *SecretTransformationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsProjectList.
+func (in *HCPVaultSecretsProjectList) DeepCopy() *HCPVaultSecretsProjectList {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretTransformationList)
+	out := new(HCPVaultSecretsProjectList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SecretTransformationList) DeepCopyObject() runtime.Object {
+func (in *HCPVaultSecretsProjectList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -406,217 +480,196 @@ func (in *SecretTransformationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretTransformationSpec) DeepCopyInto(out *SecretTransformationSpec) {
+func (in *HCPVaultSecretsProjectSpec) DeepCopyInto(out *HCPVaultSecretsProjectSpec) {
 	*out = *in
-	if in.Templates != nil {
-		in, out := &in.Templates, &out.Templates
-		*out = make(map[string]Template, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.SourceTemplates != nil {
-		in, out := &in.SourceTemplates, &out.SourceTemplates
-		*out = make([]SourceTemplate, len(*in))
-		copy(*out, *in)
-	}
-	if in.Includes != nil {
-		in, out := &in.Includes, &out.Includes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Excludes != nil {
-		in, out := &in.Excludes, &out.Excludes
+	if in.AppNames != nil {
+		in, out := &in.AppNames, &out.AppNames
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.AppTemplate.DeepCopyInto(&out.AppTemplate)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformationSpec.
-func (in *SecretTransformationSpec) DeepCopy() *SecretTransformationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsProjectSpec.
+func (in *HCPVaultSecretsProjectSpec) DeepCopy() *HCPVaultSecretsProjectSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretTransformationSpec)
+	out := new(HCPVaultSecretsProjectSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretTransformationStatus) DeepCopyInto(out *SecretTransformationStatus) {
+func (in *HCPVaultSecretsProjectStatus) DeepCopyInto(out *HCPVaultSecretsProjectStatus) {
 	*out = *in
-	if in.Valid != nil {
-		in, out := &in.Valid, &out.Valid
-		*out = new(bool)
-		**out = **in
+	if in.Apps != nil {
+		in, out := &in.Apps, &out.Apps
+		*out = make([]HCPVaultSecretsProjectApp, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformationStatus.
-func (in *SecretTransformationStatus) DeepCopy() *SecretTransformationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCPVaultSecretsProjectStatus.
+func (in *HCPVaultSecretsProjectStatus) DeepCopy() *HCPVaultSecretsProjectStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretTransformationStatus)
+	out := new(HCPVaultSecretsProjectStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SourceTemplate) DeepCopyInto(out *SourceTemplate) {
+func (in *HVSDynamicStatus) DeepCopyInto(out *HVSDynamicStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceTemplate.
-func (in *SourceTemplate) DeepCopy() *SourceTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSDynamicStatus.
+func (in *HVSDynamicStatus) DeepCopy() *HVSDynamicStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SourceTemplate)
+	out := new(HVSDynamicStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageEncryption) DeepCopyInto(out *StorageEncryption) {
+func (in *HVSDynamicSyncConfig) DeepCopyInto(out *HVSDynamicSyncConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEncryption.
-func (in *StorageEncryption) DeepCopy() *StorageEncryption {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSDynamicSyncConfig.
+func (in *HVSDynamicSyncConfig) DeepCopy() *HVSDynamicSyncConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageEncryption)
+	out := new(HVSDynamicSyncConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SyncConfig) DeepCopyInto(out *SyncConfig) {
+func (in *HVSRotatingStatus) DeepCopyInto(out *HVSRotatingStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncConfig.
-func (in *SyncConfig) DeepCopy() *SyncConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSRotatingStatus.
+func (in *HVSRotatingStatus) DeepCopy() *HVSRotatingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SyncConfig)
+	out := new(HVSRotatingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Template) DeepCopyInto(out *Template) {
+func (in *HVSRotatingSyncConfig) DeepCopyInto(out *HVSRotatingSyncConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Template.
-func (in *Template) DeepCopy() *Template {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSRotatingSyncConfig.
+func (in *HVSRotatingSyncConfig) DeepCopy() *HVSRotatingSyncConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(Template)
+	out := new(HVSRotatingSyncConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TemplateRef) DeepCopyInto(out *TemplateRef) {
+func (in *HVSSyncConfig) DeepCopyInto(out *HVSSyncConfig) {
 	*out = *in
+	if in.Dynamic != nil {
+		in, out := &in.Dynamic, &out.Dynamic
+		*out = new(HVSDynamicSyncConfig)
+		**out = **in
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(BackoffConfig)
+		**out = **in
+	}
+	if in.Rotating != nil {
+		in, out := &in.Rotating, &out.Rotating
+		*out = new(HVSRotatingSyncConfig)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRef.
-func (in *TemplateRef) DeepCopy() *TemplateRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HVSSyncConfig.
+func (in *HVSSyncConfig) DeepCopy() *HVSSyncConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(TemplateRef)
+	out := new(HVSSyncConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Transformation) DeepCopyInto(out *Transformation) {
+func (in *InstantUpdatesConfig) DeepCopyInto(out *InstantUpdatesConfig) {
 	*out = *in
-	if in.Templates != nil {
-		in, out := &in.Templates, &out.Templates
-		*out = make(map[string]Template, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.EventTypes != nil {
+		in, out := &in.EventTypes, &out.EventTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.TransformationRefs != nil {
-		in, out := &in.TransformationRefs, &out.TransformationRefs
-		*out = make([]TransformationRef, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Includes != nil {
-		in, out := &in.Includes, &out.Includes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Excludes != nil {
-		in, out := &in.Excludes, &out.Excludes
+	if in.PathFilters != nil {
+		in, out := &in.PathFilters, &out.PathFilters
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Transformation.
-func (in *Transformation) DeepCopy() *Transformation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstantUpdatesConfig.
+func (in *InstantUpdatesConfig) DeepCopy() *InstantUpdatesConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(Transformation)
+	out := new(InstantUpdatesConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TransformationRef) DeepCopyInto(out *TransformationRef) {
+func (in *MergeStrategy) DeepCopyInto(out *MergeStrategy) {
 	*out = *in
-	if in.TemplateRefs != nil {
-		in, out := &in.TemplateRefs, &out.TemplateRefs
-		*out = make([]TemplateRef, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationRef.
-func (in *TransformationRef) DeepCopy() *TransformationRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MergeStrategy.
+func (in *MergeStrategy) DeepCopy() *MergeStrategy {
 	if in == nil {
 		return nil
 	}
-	out := new(TransformationRef)
+	out := new(MergeStrategy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuth) DeepCopyInto(out *VaultAuth) {
+func (in *OperatorStatus) DeepCopyInto(out *OperatorStatus) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuth.
-func (in *VaultAuth) DeepCopy() *VaultAuth {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatus.
+func (in *OperatorStatus) DeepCopy() *OperatorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuth)
+	out := new(OperatorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultAuth) DeepCopyObject() runtime.Object {
+func (in *OperatorStatus) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -624,293 +677,237 @@ func (in *VaultAuth) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthConfigAWS) DeepCopyInto(out *VaultAuthConfigAWS) {
+func (in *OperatorStatusBacklog) DeepCopyInto(out *OperatorStatusBacklog) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigAWS.
-func (in *VaultAuthConfigAWS) DeepCopy() *VaultAuthConfigAWS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatusBacklog.
+func (in *OperatorStatusBacklog) DeepCopy() *OperatorStatusBacklog {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthConfigAWS)
+	out := new(OperatorStatusBacklog)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthConfigAppRole) DeepCopyInto(out *VaultAuthConfigAppRole) {
+func (in *OperatorStatusClientCache) DeepCopyInto(out *OperatorStatusClientCache) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigAppRole.
-func (in *VaultAuthConfigAppRole) DeepCopy() *VaultAuthConfigAppRole {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatusClientCache.
+func (in *OperatorStatusClientCache) DeepCopy() *OperatorStatusClientCache {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthConfigAppRole)
+	out := new(OperatorStatusClientCache)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthConfigGCP) DeepCopyInto(out *VaultAuthConfigGCP) {
+func (in *OperatorStatusList) DeepCopyInto(out *OperatorStatusList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OperatorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigGCP.
-func (in *VaultAuthConfigGCP) DeepCopy() *VaultAuthConfigGCP {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatusList.
+func (in *OperatorStatusList) DeepCopy() *OperatorStatusList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthConfigGCP)
+	out := new(OperatorStatusList)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthConfigJWT) DeepCopyInto(out *VaultAuthConfigJWT) {
-	*out = *in
-	if in.TokenAudiences != nil {
-		in, out := &in.TokenAudiences, &out.TokenAudiences
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigJWT.
-func (in *VaultAuthConfigJWT) DeepCopy() *VaultAuthConfigJWT {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OperatorStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(VaultAuthConfigJWT)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthConfigKubernetes) DeepCopyInto(out *VaultAuthConfigKubernetes) {
+func (in *OperatorStatusStatus) DeepCopyInto(out *OperatorStatusStatus) {
 	*out = *in
-	if in.TokenAudiences != nil {
-		in, out := &in.TokenAudiences, &out.TokenAudiences
-		*out = make([]string, len(*in))
+	if in.Backlog != nil {
+		in, out := &in.Backlog, &out.Backlog
+		*out = make([]OperatorStatusBacklog, len(*in))
 		copy(*out, *in)
 	}
+	out.ClientCache = in.ClientCache
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigKubernetes.
-func (in *VaultAuthConfigKubernetes) DeepCopy() *VaultAuthConfigKubernetes {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatusStatus.
+func (in *OperatorStatusStatus) DeepCopy() *OperatorStatusStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthConfigKubernetes)
+	out := new(OperatorStatusStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobal) DeepCopyInto(out *VaultAuthGlobal) {
+func (in *ParamFromSource) DeepCopyInto(out *ParamFromSource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(ConfigMapKeyRef)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobal.
-func (in *VaultAuthGlobal) DeepCopy() *VaultAuthGlobal {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParamFromSource.
+func (in *ParamFromSource) DeepCopy() *ParamFromSource {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobal)
+	out := new(ParamFromSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultAuthGlobal) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalConfigAWS) DeepCopyInto(out *VaultAuthGlobalConfigAWS) {
+func (in *RolloutRestartNotify) DeepCopyInto(out *RolloutRestartNotify) {
 	*out = *in
-	out.VaultAuthConfigAWS = in.VaultAuthConfigAWS
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigAWS.
-func (in *VaultAuthGlobalConfigAWS) DeepCopy() *VaultAuthGlobalConfigAWS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutRestartNotify.
+func (in *RolloutRestartNotify) DeepCopy() *RolloutRestartNotify {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalConfigAWS)
+	out := new(RolloutRestartNotify)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalConfigAppRole) DeepCopyInto(out *VaultAuthGlobalConfigAppRole) {
+func (in *RolloutRestartTarget) DeepCopyInto(out *RolloutRestartTarget) {
 	*out = *in
-	out.VaultAuthConfigAppRole = in.VaultAuthConfigAppRole
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Notify != nil {
+		in, out := &in.Notify, &out.Notify
+		*out = new(RolloutRestartNotify)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigAppRole.
-func (in *VaultAuthGlobalConfigAppRole) DeepCopy() *VaultAuthGlobalConfigAppRole {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutRestartTarget.
+func (in *RolloutRestartTarget) DeepCopy() *RolloutRestartTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalConfigAppRole)
+	out := new(RolloutRestartTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalConfigGCP) DeepCopyInto(out *VaultAuthGlobalConfigGCP) {
+func (in *RolloutRestartWindow) DeepCopyInto(out *RolloutRestartWindow) {
 	*out = *in
-	out.VaultAuthConfigGCP = in.VaultAuthConfigGCP
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigGCP.
-func (in *VaultAuthGlobalConfigGCP) DeepCopy() *VaultAuthGlobalConfigGCP {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutRestartWindow.
+func (in *RolloutRestartWindow) DeepCopy() *RolloutRestartWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalConfigGCP)
+	out := new(RolloutRestartWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalConfigJWT) DeepCopyInto(out *VaultAuthGlobalConfigJWT) {
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
 	*out = *in
-	in.VaultAuthConfigJWT.DeepCopyInto(&out.VaultAuthConfigJWT)
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigJWT.
-func (in *VaultAuthGlobalConfigJWT) DeepCopy() *VaultAuthGlobalConfigJWT {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalConfigJWT)
+	out := new(SecretKeyRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalConfigKubernetes) DeepCopyInto(out *VaultAuthGlobalConfigKubernetes) {
+func (in *SecretTransformation) DeepCopyInto(out *SecretTransformation) {
 	*out = *in
-	in.VaultAuthConfigKubernetes.DeepCopyInto(&out.VaultAuthConfigKubernetes)
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigKubernetes.
-func (in *VaultAuthGlobalConfigKubernetes) DeepCopy() *VaultAuthGlobalConfigKubernetes {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformation.
+func (in *SecretTransformation) DeepCopy() *SecretTransformation {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalConfigKubernetes)
+	out := new(SecretTransformation)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretTransformation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalList) DeepCopyInto(out *VaultAuthGlobalList) {
+func (in *SecretTransformationList) DeepCopyInto(out *SecretTransformationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VaultAuthGlobal, len(*in))
+		*out = make([]SecretTransformation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalList.
-func (in *VaultAuthGlobalList) DeepCopy() *VaultAuthGlobalList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformationList.
+func (in *SecretTransformationList) DeepCopy() *SecretTransformationList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalList)
+	out := new(SecretTransformationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultAuthGlobalList) DeepCopyObject() runtime.Object {
+func (in *SecretTransformationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -918,130 +915,1571 @@ func (in *VaultAuthGlobalList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalRef) DeepCopyInto(out *VaultAuthGlobalRef) {
+func (in *SecretTransformationSpec) DeepCopyInto(out *SecretTransformationSpec) {
 	*out = *in
-	if in.MergeStrategy != nil {
-		in, out := &in.MergeStrategy, &out.MergeStrategy
-		*out = new(MergeStrategy)
-		**out = **in
-	}
-	if in.AllowDefault != nil {
-		in, out := &in.AllowDefault, &out.AllowDefault
-		*out = new(bool)
-		**out = **in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]Template, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalRef.
-func (in *VaultAuthGlobalRef) DeepCopy() *VaultAuthGlobalRef {
-	if in == nil {
-		return nil
+	if in.SourceTemplates != nil {
+		in, out := &in.SourceTemplates, &out.SourceTemplates
+		*out = make([]SourceTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(VaultAuthGlobalRef)
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Excludes != nil {
+		in, out := &in.Excludes, &out.Excludes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformationSpec.
+func (in *SecretTransformationSpec) DeepCopy() *SecretTransformationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretTransformationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretTransformationStatus) DeepCopyInto(out *SecretTransformationStatus) {
+	*out = *in
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTransformationStatus.
+func (in *SecretTransformationStatus) DeepCopy() *SecretTransformationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretTransformationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceTemplate) DeepCopyInto(out *SourceTemplate) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ConfigMapKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceTemplate.
+func (in *SourceTemplate) DeepCopy() *SourceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageEncryption) DeepCopyInto(out *StorageEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEncryption.
+func (in *StorageEncryption) DeepCopy() *StorageEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncConfig) DeepCopyInto(out *SyncConfig) {
+	*out = *in
+	if in.InstantUpdatesConfig != nil {
+		in, out := &in.InstantUpdatesConfig, &out.InstantUpdatesConfig
+		*out = new(InstantUpdatesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(BackoffConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncConfig.
+func (in *SyncConfig) DeepCopy() *SyncConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Template) DeepCopyInto(out *Template) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Template.
+func (in *Template) DeepCopy() *Template {
+	if in == nil {
+		return nil
+	}
+	out := new(Template)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRef) DeepCopyInto(out *TemplateRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRef.
+func (in *TemplateRef) DeepCopy() *TemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Transformation) DeepCopyInto(out *Transformation) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]Template, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TransformationRefs != nil {
+		in, out := &in.TransformationRefs, &out.TransformationRefs
+		*out = make([]TransformationRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Excludes != nil {
+		in, out := &in.Excludes, &out.Excludes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeRaw != nil {
+		in, out := &in.ExcludeRaw, &out.ExcludeRaw
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Encodings != nil {
+		in, out := &in.Encodings, &out.Encodings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Transformation.
+func (in *Transformation) DeepCopy() *Transformation {
+	if in == nil {
+		return nil
+	}
+	out := new(Transformation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationRef) DeepCopyInto(out *TransformationRef) {
+	*out = *in
+	if in.TemplateRefs != nil {
+		in, out := &in.TemplateRefs, &out.TemplateRefs
+		*out = make([]TemplateRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationRef.
+func (in *TransformationRef) DeepCopy() *TransformationRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitDecrypt) DeepCopyInto(out *TransitDecrypt) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitDecrypt.
+func (in *TransitDecrypt) DeepCopy() *TransitDecrypt {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitDecrypt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuth) DeepCopyInto(out *VaultAuth) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuth.
+func (in *VaultAuth) DeepCopy() *VaultAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultAuth) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthConfigAWS) DeepCopyInto(out *VaultAuthConfigAWS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigAWS.
+func (in *VaultAuthConfigAWS) DeepCopy() *VaultAuthConfigAWS {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthConfigAWS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthConfigAppRole) DeepCopyInto(out *VaultAuthConfigAppRole) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigAppRole.
+func (in *VaultAuthConfigAppRole) DeepCopy() *VaultAuthConfigAppRole {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthConfigAppRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthConfigGCP) DeepCopyInto(out *VaultAuthConfigGCP) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigGCP.
+func (in *VaultAuthConfigGCP) DeepCopy() *VaultAuthConfigGCP {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthConfigGCP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthConfigJWT) DeepCopyInto(out *VaultAuthConfigJWT) {
+	*out = *in
+	if in.TokenAudiences != nil {
+		in, out := &in.TokenAudiences, &out.TokenAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigJWT.
+func (in *VaultAuthConfigJWT) DeepCopy() *VaultAuthConfigJWT {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthConfigJWT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthConfigKubernetes) DeepCopyInto(out *VaultAuthConfigKubernetes) {
+	*out = *in
+	if in.TokenAudiences != nil {
+		in, out := &in.TokenAudiences, &out.TokenAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfigKubernetes.
+func (in *VaultAuthConfigKubernetes) DeepCopy() *VaultAuthConfigKubernetes {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthConfigKubernetes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthFallback) DeepCopyInto(out *VaultAuthFallback) {
+	*out = *in
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VaultAuthConfigKubernetes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppRole != nil {
+		in, out := &in.AppRole, &out.AppRole
+		*out = new(VaultAuthConfigAppRole)
+		**out = **in
+	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(VaultAuthConfigJWT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWS != nil {
+		in, out := &in.AWS, &out.AWS
+		*out = new(VaultAuthConfigAWS)
+		**out = **in
+	}
+	if in.GCP != nil {
+		in, out := &in.GCP, &out.GCP
+		*out = new(VaultAuthConfigGCP)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthFallback.
+func (in *VaultAuthFallback) DeepCopy() *VaultAuthFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobal) DeepCopyInto(out *VaultAuthGlobal) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobal.
+func (in *VaultAuthGlobal) DeepCopy() *VaultAuthGlobal {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultAuthGlobal) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalConfigAWS) DeepCopyInto(out *VaultAuthGlobalConfigAWS) {
+	*out = *in
+	out.VaultAuthConfigAWS = in.VaultAuthConfigAWS
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigAWS.
+func (in *VaultAuthGlobalConfigAWS) DeepCopy() *VaultAuthGlobalConfigAWS {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalConfigAWS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalConfigAppRole) DeepCopyInto(out *VaultAuthGlobalConfigAppRole) {
+	*out = *in
+	out.VaultAuthConfigAppRole = in.VaultAuthConfigAppRole
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigAppRole.
+func (in *VaultAuthGlobalConfigAppRole) DeepCopy() *VaultAuthGlobalConfigAppRole {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalConfigAppRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalConfigGCP) DeepCopyInto(out *VaultAuthGlobalConfigGCP) {
+	*out = *in
+	out.VaultAuthConfigGCP = in.VaultAuthConfigGCP
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigGCP.
+func (in *VaultAuthGlobalConfigGCP) DeepCopy() *VaultAuthGlobalConfigGCP {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalConfigGCP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalConfigJWT) DeepCopyInto(out *VaultAuthGlobalConfigJWT) {
+	*out = *in
+	in.VaultAuthConfigJWT.DeepCopyInto(&out.VaultAuthConfigJWT)
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigJWT.
+func (in *VaultAuthGlobalConfigJWT) DeepCopy() *VaultAuthGlobalConfigJWT {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalConfigJWT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalConfigKubernetes) DeepCopyInto(out *VaultAuthGlobalConfigKubernetes) {
+	*out = *in
+	in.VaultAuthConfigKubernetes.DeepCopyInto(&out.VaultAuthConfigKubernetes)
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalConfigKubernetes.
+func (in *VaultAuthGlobalConfigKubernetes) DeepCopy() *VaultAuthGlobalConfigKubernetes {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalConfigKubernetes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalList) DeepCopyInto(out *VaultAuthGlobalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultAuthGlobal, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalList.
+func (in *VaultAuthGlobalList) DeepCopy() *VaultAuthGlobalList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultAuthGlobalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalNamespaceOverride) DeepCopyInto(out *VaultAuthGlobalNamespaceOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalNamespaceOverride.
+func (in *VaultAuthGlobalNamespaceOverride) DeepCopy() *VaultAuthGlobalNamespaceOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalNamespaceOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalRef) DeepCopyInto(out *VaultAuthGlobalRef) {
+	*out = *in
+	if in.MergeStrategy != nil {
+		in, out := &in.MergeStrategy, &out.MergeStrategy
+		*out = new(MergeStrategy)
+		**out = **in
+	}
+	if in.AllowDefault != nil {
+		in, out := &in.AllowDefault, &out.AllowDefault
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalRef.
+func (in *VaultAuthGlobalRef) DeepCopy() *VaultAuthGlobalRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalSpec) DeepCopyInto(out *VaultAuthGlobalSpec) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultParams != nil {
+		in, out := &in.DefaultParams, &out.DefaultParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DefaultHeaders != nil {
+		in, out := &in.DefaultHeaders, &out.DefaultHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VaultAuthGlobalConfigKubernetes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppRole != nil {
+		in, out := &in.AppRole, &out.AppRole
+		*out = new(VaultAuthGlobalConfigAppRole)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(VaultAuthGlobalConfigJWT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWS != nil {
+		in, out := &in.AWS, &out.AWS
+		*out = new(VaultAuthGlobalConfigAWS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCP != nil {
+		in, out := &in.GCP, &out.GCP
+		*out = new(VaultAuthGlobalConfigGCP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceOverrides != nil {
+		in, out := &in.NamespaceOverrides, &out.NamespaceOverrides
+		*out = make(map[string]VaultAuthGlobalNamespaceOverride, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalSpec.
+func (in *VaultAuthGlobalSpec) DeepCopy() *VaultAuthGlobalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthGlobalStatus) DeepCopyInto(out *VaultAuthGlobalStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalStatus.
+func (in *VaultAuthGlobalStatus) DeepCopy() *VaultAuthGlobalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthGlobalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthList) DeepCopyInto(out *VaultAuthList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultAuth, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthList.
+func (in *VaultAuthList) DeepCopy() *VaultAuthList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultAuthList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthSpec) DeepCopyInto(out *VaultAuthSpec) {
+	*out = *in
+	if in.VaultAuthGlobalRef != nil {
+		in, out := &in.VaultAuthGlobalRef, &out.VaultAuthGlobalRef
+		*out = new(VaultAuthGlobalRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VaultAuthConfigKubernetes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppRole != nil {
+		in, out := &in.AppRole, &out.AppRole
+		*out = new(VaultAuthConfigAppRole)
+		**out = **in
+	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(VaultAuthConfigJWT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWS != nil {
+		in, out := &in.AWS, &out.AWS
+		*out = new(VaultAuthConfigAWS)
+		**out = **in
+	}
+	if in.GCP != nil {
+		in, out := &in.GCP, &out.GCP
+		*out = new(VaultAuthConfigGCP)
+		**out = **in
+	}
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]VaultAuthFallback, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageEncryption != nil {
+		in, out := &in.StorageEncryption, &out.StorageEncryption
+		*out = new(StorageEncryption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthSpec.
+func (in *VaultAuthSpec) DeepCopy() *VaultAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthStatus) DeepCopyInto(out *VaultAuthStatus) {
+	*out = *in
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.VaultClientMeta.DeepCopyInto(&out.VaultClientMeta)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthStatus.
+func (in *VaultAuthStatus) DeepCopy() *VaultAuthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultClientMeta) DeepCopyInto(out *VaultClientMeta) {
+	*out = *in
+	if in.TokenPolicies != nil {
+		in, out := &in.TokenPolicies, &out.TokenPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultClientMeta.
+func (in *VaultClientMeta) DeepCopy() *VaultClientMeta {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultClientMeta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnection) DeepCopyInto(out *VaultConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnection.
+func (in *VaultConnection) DeepCopy() *VaultConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionCABundleRef) DeepCopyInto(out *VaultConnectionCABundleRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionCABundleRef.
+func (in *VaultConnectionCABundleRef) DeepCopy() *VaultConnectionCABundleRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionCABundleRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionCircuitBreaker) DeepCopyInto(out *VaultConnectionCircuitBreaker) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionCircuitBreaker.
+func (in *VaultConnectionCircuitBreaker) DeepCopy() *VaultConnectionCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionCircuitBreakerStatus) DeepCopyInto(out *VaultConnectionCircuitBreakerStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionCircuitBreakerStatus.
+func (in *VaultConnectionCircuitBreakerStatus) DeepCopy() *VaultConnectionCircuitBreakerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionCircuitBreakerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionDNS) DeepCopyInto(out *VaultConnectionDNS) {
+	*out = *in
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionDNS.
+func (in *VaultConnectionDNS) DeepCopy() *VaultConnectionDNS {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionDNS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionList) DeepCopyInto(out *VaultConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionList.
+func (in *VaultConnectionList) DeepCopy() *VaultConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionRateLimit) DeepCopyInto(out *VaultConnectionRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionRateLimit.
+func (in *VaultConnectionRateLimit) DeepCopy() *VaultConnectionRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionSpec) DeepCopyInto(out *VaultConnectionSpec) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CABundleRef != nil {
+		in, out := &in.CABundleRef, &out.CABundleRef
+		*out = new(VaultConnectionCABundleRef)
+		**out = **in
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(VaultConnectionDNS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Websocket != nil {
+		in, out := &in.Websocket, &out.Websocket
+		*out = new(VaultConnectionWebsocket)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(VaultConnectionRateLimit)
+		**out = **in
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(VaultConnectionCircuitBreaker)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionSpec.
+func (in *VaultConnectionSpec) DeepCopy() *VaultConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionStatus) DeepCopyInto(out *VaultConnectionStatus) {
+	*out = *in
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(VaultConnectionCircuitBreakerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionStatus.
+func (in *VaultConnectionStatus) DeepCopy() *VaultConnectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultConnectionWebsocket) DeepCopyInto(out *VaultConnectionWebsocket) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionWebsocket.
+func (in *VaultConnectionWebsocket) DeepCopy() *VaultConnectionWebsocket {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultConnectionWebsocket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecret) DeepCopyInto(out *VaultDynamicSecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecret.
+func (in *VaultDynamicSecret) DeepCopy() *VaultDynamicSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultDynamicSecret) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretAWS) DeepCopyInto(out *VaultDynamicSecretAWS) {
+	*out = *in
+	if in.SessionTags != nil {
+		in, out := &in.SessionTags, &out.SessionTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretAWS.
+func (in *VaultDynamicSecretAWS) DeepCopy() *VaultDynamicSecretAWS {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretAWS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretConsul) DeepCopyInto(out *VaultDynamicSecretConsul) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretConsul.
+func (in *VaultDynamicSecretConsul) DeepCopy() *VaultDynamicSecretConsul {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretConsul)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretCredentials) DeepCopyInto(out *VaultDynamicSecretCredentials) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretCredentials.
+func (in *VaultDynamicSecretCredentials) DeepCopy() *VaultDynamicSecretCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretKubernetes) DeepCopyInto(out *VaultDynamicSecretKubernetes) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretKubernetes.
+func (in *VaultDynamicSecretKubernetes) DeepCopy() *VaultDynamicSecretKubernetes {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretKubernetes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretLDAPLibrary) DeepCopyInto(out *VaultDynamicSecretLDAPLibrary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretLDAPLibrary.
+func (in *VaultDynamicSecretLDAPLibrary) DeepCopy() *VaultDynamicSecretLDAPLibrary {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretLDAPLibrary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretList) DeepCopyInto(out *VaultDynamicSecretList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultDynamicSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretList.
+func (in *VaultDynamicSecretList) DeepCopy() *VaultDynamicSecretList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultDynamicSecretList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretNomad) DeepCopyInto(out *VaultDynamicSecretNomad) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretNomad.
+func (in *VaultDynamicSecretNomad) DeepCopy() *VaultDynamicSecretNomad {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretNomad)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretSpec) DeepCopyInto(out *VaultDynamicSecretSpec) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ParamsFrom != nil {
+		in, out := &in.ParamsFrom, &out.ParamsFrom
+		*out = make([]ParamFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AWS != nil {
+		in, out := &in.AWS, &out.AWS
+		*out = new(VaultDynamicSecretAWS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VaultDynamicSecretKubernetes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(VaultDynamicSecretConsul)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nomad != nil {
+		in, out := &in.Nomad, &out.Nomad
+		*out = new(VaultDynamicSecretNomad)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAPLibrary != nil {
+		in, out := &in.LDAPLibrary, &out.LDAPLibrary
+		*out = new(VaultDynamicSecretLDAPLibrary)
+		**out = **in
+	}
+	if in.RolloutRestartTargets != nil {
+		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
+		*out = make([]RolloutRestartTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
+		copy(*out, *in)
+	}
+	in.Destination.DeepCopyInto(&out.Destination)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretSpec.
+func (in *VaultDynamicSecretSpec) DeepCopy() *VaultDynamicSecretSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultDynamicSecretStatus) DeepCopyInto(out *VaultDynamicSecretStatus) {
+	*out = *in
+	out.SecretLease = in.SecretLease
+	out.StaticCredsMetaData = in.StaticCredsMetaData
+	out.Credentials = in.Credentials
+	in.VaultClientMeta.DeepCopyInto(&out.VaultClientMeta)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretStatus.
+func (in *VaultDynamicSecretStatus) DeepCopy() *VaultDynamicSecretStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultDynamicSecretStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultEventSubscription) DeepCopyInto(out *VaultEventSubscription) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultEventSubscription.
+func (in *VaultEventSubscription) DeepCopy() *VaultEventSubscription {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultEventSubscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultEventSubscription) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultEventSubscriptionList) DeepCopyInto(out *VaultEventSubscriptionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultEventSubscription, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultEventSubscriptionList.
+func (in *VaultEventSubscriptionList) DeepCopy() *VaultEventSubscriptionList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultEventSubscriptionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultEventSubscriptionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultEventSubscriptionSpec) DeepCopyInto(out *VaultEventSubscriptionSpec) {
+	*out = *in
+	if in.EventTypes != nil {
+		in, out := &in.EventTypes, &out.EventTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PathFilters != nil {
+		in, out := &in.PathFilters, &out.PathFilters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultEventSubscriptionSpec.
+func (in *VaultEventSubscriptionSpec) DeepCopy() *VaultEventSubscriptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultEventSubscriptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultEventSubscriptionStatus) DeepCopyInto(out *VaultEventSubscriptionStatus) {
+	*out = *in
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultEventSubscriptionStatus.
+func (in *VaultEventSubscriptionStatus) DeepCopy() *VaultEventSubscriptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultEventSubscriptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultPKISecret) DeepCopyInto(out *VaultPKISecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecret.
+func (in *VaultPKISecret) DeepCopy() *VaultPKISecret {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultPKISecret)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultPKISecret) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalSpec) DeepCopyInto(out *VaultAuthGlobalSpec) {
+func (in *VaultPKISecretACME) DeepCopyInto(out *VaultPKISecretACME) {
 	*out = *in
-	if in.AllowedNamespaces != nil {
-		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.DefaultParams != nil {
-		in, out := &in.DefaultParams, &out.DefaultParams
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.DefaultHeaders != nil {
-		in, out := &in.DefaultHeaders, &out.DefaultHeaders
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Kubernetes != nil {
-		in, out := &in.Kubernetes, &out.Kubernetes
-		*out = new(VaultAuthGlobalConfigKubernetes)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AppRole != nil {
-		in, out := &in.AppRole, &out.AppRole
-		*out = new(VaultAuthGlobalConfigAppRole)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.JWT != nil {
-		in, out := &in.JWT, &out.JWT
-		*out = new(VaultAuthGlobalConfigJWT)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AWS != nil {
-		in, out := &in.AWS, &out.AWS
-		*out = new(VaultAuthGlobalConfigAWS)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.GCP != nil {
-		in, out := &in.GCP, &out.GCP
-		*out = new(VaultAuthGlobalConfigGCP)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalSpec.
-func (in *VaultAuthGlobalSpec) DeepCopy() *VaultAuthGlobalSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretACME.
+func (in *VaultPKISecretACME) DeepCopy() *VaultPKISecretACME {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalSpec)
+	out := new(VaultPKISecretACME)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthGlobalStatus) DeepCopyInto(out *VaultAuthGlobalStatus) {
+func (in *VaultPKISecretCSR) DeepCopyInto(out *VaultPKISecretCSR) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthGlobalStatus.
-func (in *VaultAuthGlobalStatus) DeepCopy() *VaultAuthGlobalStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretCSR.
+func (in *VaultPKISecretCSR) DeepCopy() *VaultPKISecretCSR {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthGlobalStatus)
+	out := new(VaultPKISecretCSR)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthList) DeepCopyInto(out *VaultAuthList) {
+func (in *VaultPKISecretList) DeepCopyInto(out *VaultPKISecretList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VaultAuth, len(*in))
+		*out = make([]VaultPKISecret, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthList.
-func (in *VaultAuthList) DeepCopy() *VaultAuthList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretList.
+func (in *VaultPKISecretList) DeepCopy() *VaultPKISecretList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthList)
+	out := new(VaultPKISecretList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultAuthList) DeepCopyObject() runtime.Object {
+func (in *VaultPKISecretList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1049,76 +2487,77 @@ func (in *VaultAuthList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthSpec) DeepCopyInto(out *VaultAuthSpec) {
+func (in *VaultPKISecretSpec) DeepCopyInto(out *VaultPKISecretSpec) {
 	*out = *in
-	if in.VaultAuthGlobalRef != nil {
-		in, out := &in.VaultAuthGlobalRef, &out.VaultAuthGlobalRef
-		*out = new(VaultAuthGlobalRef)
-		(*in).DeepCopyInto(*out)
+	if in.RolloutRestartTargets != nil {
+		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
+		*out = make([]RolloutRestartTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.AllowedNamespaces != nil {
-		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
-		*out = make([]string, len(*in))
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
 		copy(*out, *in)
 	}
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	in.Destination.DeepCopyInto(&out.Destination)
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]Destination, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.AltNames != nil {
+		in, out := &in.AltNames, &out.AltNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Kubernetes != nil {
-		in, out := &in.Kubernetes, &out.Kubernetes
-		*out = new(VaultAuthConfigKubernetes)
-		(*in).DeepCopyInto(*out)
+	if in.IPSans != nil {
+		in, out := &in.IPSans, &out.IPSans
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.AppRole != nil {
-		in, out := &in.AppRole, &out.AppRole
-		*out = new(VaultAuthConfigAppRole)
-		**out = **in
+	if in.URISans != nil {
+		in, out := &in.URISans, &out.URISans
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.JWT != nil {
-		in, out := &in.JWT, &out.JWT
-		*out = new(VaultAuthConfigJWT)
-		(*in).DeepCopyInto(*out)
+	if in.OtherSans != nil {
+		in, out := &in.OtherSans, &out.OtherSans
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.AWS != nil {
-		in, out := &in.AWS, &out.AWS
-		*out = new(VaultAuthConfigAWS)
-		**out = **in
+	if in.UserIDs != nil {
+		in, out := &in.UserIDs, &out.UserIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.GCP != nil {
-		in, out := &in.GCP, &out.GCP
-		*out = new(VaultAuthConfigGCP)
+	if in.CSR != nil {
+		in, out := &in.CSR, &out.CSR
+		*out = new(VaultPKISecretCSR)
 		**out = **in
 	}
-	if in.StorageEncryption != nil {
-		in, out := &in.StorageEncryption, &out.StorageEncryption
-		*out = new(StorageEncryption)
+	if in.ACME != nil {
+		in, out := &in.ACME, &out.ACME
+		*out = new(VaultPKISecretACME)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthSpec.
-func (in *VaultAuthSpec) DeepCopy() *VaultAuthSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretSpec.
+func (in *VaultPKISecretSpec) DeepCopy() *VaultPKISecretSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultAuthSpec)
+	out := new(VaultPKISecretSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultAuthStatus) DeepCopyInto(out *VaultAuthStatus) {
+func (in *VaultPKISecretStatus) DeepCopyInto(out *VaultPKISecretStatus) {
 	*out = *in
 	if in.Valid != nil {
 		in, out := &in.Valid, &out.Valid
@@ -1132,35 +2571,25 @@ func (in *VaultAuthStatus) DeepCopyInto(out *VaultAuthStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthStatus.
-func (in *VaultAuthStatus) DeepCopy() *VaultAuthStatus {
-	if in == nil {
-		return nil
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
 	}
-	out := new(VaultAuthStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultClientMeta) DeepCopyInto(out *VaultClientMeta) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultClientMeta.
-func (in *VaultClientMeta) DeepCopy() *VaultClientMeta {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretStatus.
+func (in *VaultPKISecretStatus) DeepCopy() *VaultPKISecretStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultClientMeta)
+	out := new(VaultPKISecretStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultConnection) DeepCopyInto(out *VaultConnection) {
+func (in *VaultSSHCertificate) DeepCopyInto(out *VaultSSHCertificate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1168,18 +2597,18 @@ func (in *VaultConnection) DeepCopyInto(out *VaultConnection) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnection.
-func (in *VaultConnection) DeepCopy() *VaultConnection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSSHCertificate.
+func (in *VaultSSHCertificate) DeepCopy() *VaultSSHCertificate {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultConnection)
+	out := new(VaultSSHCertificate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultConnection) DeepCopyObject() runtime.Object {
+func (in *VaultSSHCertificate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1187,31 +2616,46 @@ func (in *VaultConnection) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultConnectionList) DeepCopyInto(out *VaultConnectionList) {
+func (in *VaultSSHCertificateKeyPair) DeepCopyInto(out *VaultSSHCertificateKeyPair) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSSHCertificateKeyPair.
+func (in *VaultSSHCertificateKeyPair) DeepCopy() *VaultSSHCertificateKeyPair {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSSHCertificateKeyPair)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSSHCertificateList) DeepCopyInto(out *VaultSSHCertificateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VaultConnection, len(*in))
+		*out = make([]VaultSSHCertificate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionList.
-func (in *VaultConnectionList) DeepCopy() *VaultConnectionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSSHCertificateList.
+func (in *VaultSSHCertificateList) DeepCopy() *VaultSSHCertificateList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultConnectionList)
+	out := new(VaultSSHCertificateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultConnectionList) DeepCopyObject() runtime.Object {
+func (in *VaultSSHCertificateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1219,68 +2663,140 @@ func (in *VaultConnectionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultConnectionSpec) DeepCopyInto(out *VaultConnectionSpec) {
+func (in *VaultSSHCertificateSpec) DeepCopyInto(out *VaultSSHCertificateSpec) {
+	*out = *in
+	if in.ValidPrincipals != nil {
+		in, out := &in.ValidPrincipals, &out.ValidPrincipals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CriticalOptions != nil {
+		in, out := &in.CriticalOptions, &out.CriticalOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KeyPair != nil {
+		in, out := &in.KeyPair, &out.KeyPair
+		*out = new(VaultSSHCertificateKeyPair)
+		**out = **in
+	}
+	if in.RolloutRestartTargets != nil {
+		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
+		*out = make([]RolloutRestartTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
+		copy(*out, *in)
+	}
+	in.Destination.DeepCopyInto(&out.Destination)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSSHCertificateSpec.
+func (in *VaultSSHCertificateSpec) DeepCopy() *VaultSSHCertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSSHCertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSSHCertificateStatus) DeepCopyInto(out *VaultSSHCertificateStatus) {
+	*out = *in
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSSHCertificateStatus.
+func (in *VaultSSHCertificateStatus) DeepCopy() *VaultSSHCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSSHCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretLease) DeepCopyInto(out *VaultSecretLease) {
 	*out = *in
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionSpec.
-func (in *VaultConnectionSpec) DeepCopy() *VaultConnectionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretLease.
+func (in *VaultSecretLease) DeepCopy() *VaultSecretLease {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultConnectionSpec)
+	out := new(VaultSecretLease)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultConnectionStatus) DeepCopyInto(out *VaultConnectionStatus) {
+func (in *VaultStaticCredsMetaData) DeepCopyInto(out *VaultStaticCredsMetaData) {
 	*out = *in
-	if in.Valid != nil {
-		in, out := &in.Valid, &out.Valid
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultConnectionStatus.
-func (in *VaultConnectionStatus) DeepCopy() *VaultConnectionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticCredsMetaData.
+func (in *VaultStaticCredsMetaData) DeepCopy() *VaultStaticCredsMetaData {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultConnectionStatus)
+	out := new(VaultStaticCredsMetaData)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultDynamicSecret) DeepCopyInto(out *VaultDynamicSecret) {
+func (in *VaultSecretExport) DeepCopyInto(out *VaultSecretExport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecret.
-func (in *VaultDynamicSecret) DeepCopy() *VaultDynamicSecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretExport.
+func (in *VaultSecretExport) DeepCopy() *VaultSecretExport {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultDynamicSecret)
+	out := new(VaultSecretExport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultDynamicSecret) DeepCopyObject() runtime.Object {
+func (in *VaultSecretExport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1288,31 +2804,31 @@ func (in *VaultDynamicSecret) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultDynamicSecretList) DeepCopyInto(out *VaultDynamicSecretList) {
+func (in *VaultSecretExportList) DeepCopyInto(out *VaultSecretExportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VaultDynamicSecret, len(*in))
+		*out = make([]VaultSecretExport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretList.
-func (in *VaultDynamicSecretList) DeepCopy() *VaultDynamicSecretList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretExportList.
+func (in *VaultSecretExportList) DeepCopy() *VaultSecretExportList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultDynamicSecretList)
+	out := new(VaultSecretExportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultDynamicSecretList) DeepCopyObject() runtime.Object {
+func (in *VaultSecretExportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1320,53 +2836,75 @@ func (in *VaultDynamicSecretList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultDynamicSecretSpec) DeepCopyInto(out *VaultDynamicSecretSpec) {
+func (in *VaultSecretExportSource) DeepCopyInto(out *VaultSecretExportSource) {
 	*out = *in
-	if in.Params != nil {
-		in, out := &in.Params, &out.Params
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.RolloutRestartTargets != nil {
-		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
-		*out = make([]RolloutRestartTarget, len(*in))
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.Destination.DeepCopyInto(&out.Destination)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretSpec.
-func (in *VaultDynamicSecretSpec) DeepCopy() *VaultDynamicSecretSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretExportSource.
+func (in *VaultSecretExportSource) DeepCopy() *VaultSecretExportSource {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultDynamicSecretSpec)
+	out := new(VaultSecretExportSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultDynamicSecretStatus) DeepCopyInto(out *VaultDynamicSecretStatus) {
+func (in *VaultSecretExportSpec) DeepCopyInto(out *VaultSecretExportSpec) {
 	*out = *in
-	out.SecretLease = in.SecretLease
-	out.StaticCredsMetaData = in.StaticCredsMetaData
-	out.VaultClientMeta = in.VaultClientMeta
+	in.Source.DeepCopyInto(&out.Source)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultDynamicSecretStatus.
-func (in *VaultDynamicSecretStatus) DeepCopy() *VaultDynamicSecretStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretExportSpec.
+func (in *VaultSecretExportSpec) DeepCopy() *VaultSecretExportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultDynamicSecretStatus)
+	out := new(VaultSecretExportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultPKISecret) DeepCopyInto(out *VaultPKISecret) {
+func (in *VaultSecretExportStatus) DeepCopyInto(out *VaultSecretExportStatus) {
+	*out = *in
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretExportStatus.
+func (in *VaultSecretExportStatus) DeepCopy() *VaultSecretExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultStaticSecret) DeepCopyInto(out *VaultStaticSecret) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1374,18 +2912,18 @@ func (in *VaultPKISecret) DeepCopyInto(out *VaultPKISecret) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecret.
-func (in *VaultPKISecret) DeepCopy() *VaultPKISecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecret.
+func (in *VaultStaticSecret) DeepCopy() *VaultStaticSecret {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultPKISecret)
+	out := new(VaultStaticSecret)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultPKISecret) DeepCopyObject() runtime.Object {
+func (in *VaultStaticSecret) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1393,31 +2931,46 @@ func (in *VaultPKISecret) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultPKISecretList) DeepCopyInto(out *VaultPKISecretList) {
+func (in *VaultStaticSecretEventWatcher) DeepCopyInto(out *VaultStaticSecretEventWatcher) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretEventWatcher.
+func (in *VaultStaticSecretEventWatcher) DeepCopy() *VaultStaticSecretEventWatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultStaticSecretEventWatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultStaticSecretList) DeepCopyInto(out *VaultStaticSecretList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VaultPKISecret, len(*in))
+		*out = make([]VaultStaticSecret, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretList.
-func (in *VaultPKISecretList) DeepCopy() *VaultPKISecretList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretList.
+func (in *VaultStaticSecretList) DeepCopy() *VaultStaticSecretList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultPKISecretList)
+	out := new(VaultStaticSecretList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultPKISecretList) DeepCopyObject() runtime.Object {
+func (in *VaultStaticSecretList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1425,122 +2978,116 @@ func (in *VaultPKISecretList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultPKISecretSpec) DeepCopyInto(out *VaultPKISecretSpec) {
+func (in *VaultStaticSecretSpec) DeepCopyInto(out *VaultStaticSecretSpec) {
 	*out = *in
+	if in.HMACSecretData != nil {
+		in, out := &in.HMACSecretData, &out.HMACSecretData
+		*out = new(bool)
+		**out = **in
+	}
 	if in.RolloutRestartTargets != nil {
 		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
 		*out = make([]RolloutRestartTarget, len(*in))
-		copy(*out, *in)
-	}
-	in.Destination.DeepCopyInto(&out.Destination)
-	if in.AltNames != nil {
-		in, out := &in.AltNames, &out.AltNames
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.IPSans != nil {
-		in, out := &in.IPSans, &out.IPSans
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.URISans != nil {
-		in, out := &in.URISans, &out.URISans
-		*out = make([]string, len(*in))
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
 		copy(*out, *in)
 	}
-	if in.OtherSans != nil {
-		in, out := &in.OtherSans, &out.OtherSans
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	in.Destination.DeepCopyInto(&out.Destination)
+	if in.SyncConfig != nil {
+		in, out := &in.SyncConfig, &out.SyncConfig
+		*out = new(SyncConfig)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.UserIDs != nil {
-		in, out := &in.UserIDs, &out.UserIDs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.TransitDecrypt != nil {
+		in, out := &in.TransitDecrypt, &out.TransitDecrypt
+		*out = new(TransitDecrypt)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretSpec.
-func (in *VaultPKISecretSpec) DeepCopy() *VaultPKISecretSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretSpec.
+func (in *VaultStaticSecretSpec) DeepCopy() *VaultStaticSecretSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultPKISecretSpec)
+	out := new(VaultStaticSecretSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultPKISecretStatus) DeepCopyInto(out *VaultPKISecretStatus) {
+func (in *VaultStaticSecretStatus) DeepCopyInto(out *VaultStaticSecretStatus) {
 	*out = *in
-	if in.Valid != nil {
-		in, out := &in.Valid, &out.Valid
-		*out = new(bool)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EventWatcher != nil {
+		in, out := &in.EventWatcher, &out.EventWatcher
+		*out = new(VaultStaticSecretEventWatcher)
 		**out = **in
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKISecretStatus.
-func (in *VaultPKISecretStatus) DeepCopy() *VaultPKISecretStatus {
-	if in == nil {
-		return nil
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
 	}
-	out := new(VaultPKISecretStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultSecretLease) DeepCopyInto(out *VaultSecretLease) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretLease.
-func (in *VaultSecretLease) DeepCopy() *VaultSecretLease {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretStatus.
+func (in *VaultStaticSecretStatus) DeepCopy() *VaultStaticSecretStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultSecretLease)
+	out := new(VaultStaticSecretStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultStaticCredsMetaData) DeepCopyInto(out *VaultStaticCredsMetaData) {
+func (in *VaultTransitDataKey) DeepCopyInto(out *VaultTransitDataKey) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticCredsMetaData.
-func (in *VaultStaticCredsMetaData) DeepCopy() *VaultStaticCredsMetaData {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTransitDataKey.
+func (in *VaultTransitDataKey) DeepCopy() *VaultTransitDataKey {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultStaticCredsMetaData)
+	out := new(VaultTransitDataKey)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultStaticSecret) DeepCopyInto(out *VaultStaticSecret) {
+func (in *VaultTransitKey) DeepCopyInto(out *VaultTransitKey) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecret.
-func (in *VaultStaticSecret) DeepCopy() *VaultStaticSecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTransitKey.
+func (in *VaultTransitKey) DeepCopy() *VaultTransitKey {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultStaticSecret)
+	out := new(VaultTransitKey)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultStaticSecret) DeepCopyObject() runtime.Object {
+func (in *VaultTransitKey) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1548,31 +3095,31 @@ func (in *VaultStaticSecret) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultStaticSecretList) DeepCopyInto(out *VaultStaticSecretList) {
+func (in *VaultTransitKeyList) DeepCopyInto(out *VaultTransitKeyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VaultStaticSecret, len(*in))
+		*out = make([]VaultTransitKey, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretList.
-func (in *VaultStaticSecretList) DeepCopy() *VaultStaticSecretList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTransitKeyList.
+func (in *VaultTransitKeyList) DeepCopy() *VaultTransitKeyList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultStaticSecretList)
+	out := new(VaultTransitKeyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VaultStaticSecretList) DeepCopyObject() runtime.Object {
+func (in *VaultTransitKeyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1580,47 +3127,66 @@ func (in *VaultStaticSecretList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultStaticSecretSpec) DeepCopyInto(out *VaultStaticSecretSpec) {
+func (in *VaultTransitKeySpec) DeepCopyInto(out *VaultTransitKeySpec) {
 	*out = *in
-	if in.HMACSecretData != nil {
-		in, out := &in.HMACSecretData, &out.HMACSecretData
-		*out = new(bool)
-		**out = **in
-	}
 	if in.RolloutRestartTargets != nil {
 		in, out := &in.RolloutRestartTargets, &out.RolloutRestartTargets
 		*out = make([]RolloutRestartTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RolloutRestartWindows != nil {
+		in, out := &in.RolloutRestartWindows, &out.RolloutRestartWindows
+		*out = make([]RolloutRestartWindow, len(*in))
 		copy(*out, *in)
 	}
 	in.Destination.DeepCopyInto(&out.Destination)
-	if in.SyncConfig != nil {
-		in, out := &in.SyncConfig, &out.SyncConfig
-		*out = new(SyncConfig)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretSpec.
-func (in *VaultStaticSecretSpec) DeepCopy() *VaultStaticSecretSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTransitKeySpec.
+func (in *VaultTransitKeySpec) DeepCopy() *VaultTransitKeySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultStaticSecretSpec)
+	out := new(VaultTransitKeySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultStaticSecretStatus) DeepCopyInto(out *VaultStaticSecretStatus) {
+func (in *VaultTransitKeyStatus) DeepCopyInto(out *VaultTransitKeyStatus) {
 	*out = *in
+	if in.DataKeys != nil {
+		in, out := &in.DataKeys, &out.DataKeys
+		*out = make([]VaultTransitDataKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackOff != nil {
+		in, out := &in.BackOff, &out.BackOff
+		*out = new(BackOffStatus)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultStaticSecretStatus.
-func (in *VaultStaticSecretStatus) DeepCopy() *VaultStaticSecretStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTransitKeyStatus.
+func (in *VaultTransitKeyStatus) DeepCopy() *VaultTransitKeyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultStaticSecretStatus)
+	out := new(VaultTransitKeyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
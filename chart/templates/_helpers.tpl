@@ -189,6 +189,47 @@ globalVaultAuthOptions configures the manager's --global-vault-auth-options flag
 {{- end -}}
 {{- end -}}
 
+{{/*
+vaultNamespaceRemap configures the manager's --vault-namespace-remap flag.
+*/}}
+{{- define "vso.vaultNamespaceRemap" -}}
+{{- $opts := list -}}
+{{- range $old, $new := .Values.controller.manager.vaultNamespaceRemap -}}
+{{- $opts = mustAppend $opts (printf "%s=%s" $old $new) -}}
+{{- end -}}
+{{- if $opts -}}
+{{- $opts | join "," -}}
+{{- end -}}
+{{- end -}}
+
+{{/*
+vaultLoginMaxConcurrency configures the manager's --vault-login-max-concurrency flag.
+*/}}
+{{- define "vso.vaultLoginMaxConcurrency" -}}
+{{- $opts := list -}}
+{{- range $k, $v := .Values.controller.manager.vaultLoginMaxConcurrency -}}
+{{- $opts = mustAppend $opts (printf "%s=%v" $k $v) -}}
+{{- end -}}
+{{- if $opts -}}
+{{- $opts | join "," -}}
+{{- end -}}
+{{- end -}}
+
+{{/*
+allowedVaultNamespaces configures the manager's --allowed-vault-namespaces flag.
+*/}}
+{{- define "vso.allowedVaultNamespaces" -}}
+{{- $opts := list -}}
+{{- range $k8sNS, $vaultNamespaces := .Values.controller.manager.allowedVaultNamespaces -}}
+{{- range $vaultNS := $vaultNamespaces -}}
+{{- $opts = mustAppend $opts (printf "%s=%s" $k8sNS $vaultNS) -}}
+{{- end -}}
+{{- end -}}
+{{- if $opts -}}
+{{- $opts | join "," -}}
+{{- end -}}
+{{- end -}}
+
 {{/*
 backoffOnSecretSourceError provides the backoff options for the manager when a
 secret source error occurs.
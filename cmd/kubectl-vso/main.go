@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// kubectl-vso is a kubectl plugin for troubleshooting the resources that are
+// managed by the Vault Secrets Operator. It is invoked as "kubectl vso" once
+// the binary is found on the PATH.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+const usage = `Usage: kubectl vso <command> [flags]
+
+Commands:
+  sync <type>/<name>    Force the sync of a resource, e.g. "kubectl vso sync vds/my-db-creds".
+  status                Show the sync status of all syncable resources.
+  render                Render the templates of a SecretTransformation with the given secret data.
+
+Resource types:
+%s
+Run "kubectl vso <command> -h" for the command's flags.
+`
+
+var errUsage = errors.New("invalid usage")
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(secretsv1beta1.AddToScheme(scheme))
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, newClient); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// clientFunc returns the client.Client for kubeconfig and its context, along
+// with the context's default namespace.
+type clientFunc func(kubeconfig, kubeContext string) (client.Client, string, error)
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, newClientFunc clientFunc) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(stderr, usage, resourceTypesUsage())
+		return errUsage
+	}
+
+	cmd := args[0]
+	fs := flag.NewFlagSet("kubectl vso "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var kubeconfig, kubeContext, namespace string
+	fs.StringVar(&kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file, defaults to the kubectl's kubeconfig.")
+	fs.StringVar(&kubeContext, "context", "",
+		"The name of the kubeconfig context to use.")
+	fs.StringVar(&namespace, "namespace", "",
+		"The namespace of the resources, defaults to the context's namespace.")
+	fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+
+	var allNamespaces bool
+	var transformation, dataFile string
+	data := make(map[string]any)
+	switch cmd {
+	case "sync":
+	case "status":
+		fs.BoolVar(&allNamespaces, "all-namespaces", false,
+			"Show the resources of all namespaces.")
+		fs.BoolVar(&allNamespaces, "A", false, "Shorthand for --all-namespaces.")
+	case "render":
+		fs.StringVar(&transformation, "transformation", "",
+			"The SecretTransformation to render, can be prefixed with a namespace, e.g. ns1/name.")
+		fs.Func("data", "The secret data as key=value, can be repeated.", func(s string) error {
+			k, v, ok := cutKeyValue(s)
+			if !ok {
+				return fmt.Errorf("invalid data %q, must be key=value", s)
+			}
+			data[k] = v
+			return nil
+		})
+		fs.StringVar(&dataFile, "data-file", "",
+			"Path to a JSON file with the secret data, e.g. the data of a Vault KV secret.")
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", cmd)
+		fmt.Fprintf(stderr, usage, resourceTypesUsage())
+		return errUsage
+	}
+
+	posArgs, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	var wantArgs int
+	if cmd == "sync" {
+		wantArgs = 1
+	}
+	if len(posArgs) != wantArgs {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, defaultNamespace, err := newClientFunc(kubeconfig, kubeContext)
+	if err != nil {
+		return err
+	}
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	switch cmd {
+	case "sync":
+		rt, name, err := parseResourceRef(posArgs[0])
+		if err != nil {
+			return err
+		}
+		if err := syncResource(ctx, c, rt, client.ObjectKey{Namespace: namespace, Name: name}, time.Now()); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s/%s sync requested\n", rt.name(), name)
+	case "status":
+		if allNamespaces {
+			namespace = ""
+		}
+		statuses, err := listResourceStatus(ctx, c, namespace)
+		if err != nil {
+			return err
+		}
+		return printResourceStatus(stdout, statuses)
+	case "render":
+		if transformation == "" {
+			fs.Usage()
+			return errUsage
+		}
+		if dataFile != "" {
+			if err := readDataFile(dataFile, data); err != nil {
+				return err
+			}
+		}
+		rendered, err := renderTransformation(ctx, c, namespace, transformation, data)
+		if err != nil {
+			return err
+		}
+		return printRendered(stdout, rendered)
+	}
+
+	return nil
+}
+
+// parseInterspersed parses the flags in args, allowing them to follow the
+// positional arguments, as kubectl does. It returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var posArgs []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return posArgs, nil
+		}
+		posArgs = append(posArgs, args[0])
+		args = args[1:]
+	}
+}
+
+func newClient(kubeconfig, kubeContext string) (client.Client, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules,
+		&clientcmd.ConfigOverrides{
+			CurrentContext: kubeContext,
+		})
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	c, err := client.New(config, client.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return c, namespace, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_run(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantErr    error
+		wantSynced client.ObjectKey
+	}{
+		{
+			name:       "sync",
+			args:       []string{"sync", "vss/app"},
+			wantStdout: "vaultstaticsecret/app sync requested\n",
+			wantSynced: client.ObjectKey{Namespace: "default", Name: "app"},
+		},
+		{
+			name:       "sync-namespace-after-ref",
+			args:       []string{"sync", "vss/app", "-n", "tenant-1"},
+			wantStdout: "vaultstaticsecret/app sync requested\n",
+			wantSynced: client.ObjectKey{Namespace: "tenant-1", Name: "app"},
+		},
+		{
+			name:    "sync-no-ref",
+			args:    []string{"sync"},
+			wantErr: errUsage,
+		},
+		{
+			name: "status",
+			args: []string{"status", "-A"},
+			wantStdout: "NAMESPACE  KIND               NAME  SYNCED  VALID  ERROR\n" +
+				"default    VaultStaticSecret  app   true    -      \n" +
+				"tenant-1   VaultStaticSecret  app   true    -      \n",
+		},
+		{
+			name:    "render-no-transformation",
+			args:    []string{"render"},
+			wantErr: errUsage,
+		},
+		{
+			name:    "unknown",
+			args:    []string{"foo"},
+			wantErr: errUsage,
+		},
+		{
+			name:    "none",
+			wantErr: errUsage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			c := testutils.NewFakeClientBuilder().WithObjects(
+				&secretsv1beta1.VaultStaticSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "app",
+					},
+				},
+				&secretsv1beta1.VaultStaticSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "tenant-1",
+						Name:      "app",
+					},
+				},
+			).Build()
+			newClientFunc := func(_, _ string) (client.Client, string, error) {
+				return c, "default", nil
+			}
+
+			var stdout, stderr bytes.Buffer
+			err := run(ctx, tt.args, &stdout, &stderr, newClientFunc)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStdout, stdout.String())
+
+			if tt.wantSynced.Name != "" {
+				got := &secretsv1beta1.VaultStaticSecret{}
+				require.NoError(t, c.Get(ctx, tt.wantSynced, got))
+				assert.Contains(t, got.GetAnnotations(), consts.AnnotationForceSync)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+)
+
+// renderObjName is the name of the syncable secret resource that refers to
+// the rendered SecretTransformation, it is never created.
+const renderObjName = "kubectl-vso-render"
+
+// renderTransformation renders the SecretTransformation ref with data, the
+// same way that the operator renders it for a syncable secret resource in
+// namespace. The ref can be prefixed with a namespace, e.g. ns1/name.
+func renderTransformation(ctx context.Context, c client.Client, namespace, ref string, data map[string]any) (map[string][]byte, error) {
+	transformationRef := secretsv1beta1.TransformationRef{
+		Name: ref,
+	}
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		transformationRef.Namespace = ns
+		transformationRef.Name = name
+	}
+
+	obj := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      renderObjName,
+		},
+	}
+	dest := &secretsv1beta1.Destination{
+		Name: renderObjName,
+		Transformation: secretsv1beta1.Transformation{
+			TransformationRefs: []secretsv1beta1.TransformationRef{
+				transformationRef,
+			},
+		},
+	}
+
+	opt, err := helpers.NewDestinationTransformationOption(ctx, c, obj, dest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.NewSecretsDataBuilder().WithVaultData(data, data, opt)
+}
+
+// readDataFile reads the JSON object in path into data. The keys that are
+// already in data take precedence.
+func readDataFile(path string, data map[string]any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var fileData map[string]any
+	if err := json.Unmarshal(b, &fileData); err != nil {
+		return fmt.Errorf("invalid data file %s: %w", path, err)
+	}
+
+	for k, v := range fileData {
+		if _, ok := data[k]; !ok {
+			data[k] = v
+		}
+	}
+
+	return nil
+}
+
+// printRendered writes the rendered secret data as a JSON object of strings.
+func printRendered(w io.Writer, rendered map[string][]byte) error {
+	out := make(map[string]string, len(rendered))
+	for k, v := range rendered {
+		out[k] = string(v)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func cutKeyValue(s string) (string, string, bool) {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return "", "", false
+	}
+	return k, v, true
+}
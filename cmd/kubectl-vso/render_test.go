@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_renderTransformation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st := &secretsv1beta1.SecretTransformation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "tenant-1",
+			Name:      "db",
+		},
+		Spec: secretsv1beta1.SecretTransformationSpec{
+			Templates: map[string]secretsv1beta1.Template{
+				"url": {
+					Text: `{{- get .Secrets "username" -}}@db`,
+				},
+			},
+			Excludes: []string{".*"},
+		},
+		Status: secretsv1beta1.SecretTransformationStatus{
+			Valid: ptr.To(true),
+		},
+	}
+	invalid := st.DeepCopy()
+	invalid.Name = "invalid"
+	invalid.Status.Valid = ptr.To(false)
+	c := testutils.NewFakeClientBuilder().WithObjects(st, invalid).Build()
+
+	data := map[string]any{
+		"username": "alice",
+	}
+
+	got, err := renderTransformation(ctx, c, "tenant-1", "db", data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("alice@db"), got["url"])
+	assert.NotContains(t, got, "username")
+
+	got, err = renderTransformation(ctx, c, "default", "tenant-1/db", data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("alice@db"), got["url"])
+
+	_, err = renderTransformation(ctx, c, "default", "db", data)
+	assert.Error(t, err)
+
+	_, err = renderTransformation(ctx, c, "tenant-1", "invalid", data)
+	assert.Error(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, printRendered(&b, map[string][]byte{"url": []byte("alice@db")}))
+	assert.Equal(t, "{\n  \"url\": \"alice@db\"\n}\n", b.String())
+}
+
+func Test_readDataFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"username": "alice", "password": "s3cr3t"}`), 0o600))
+
+	data := map[string]any{
+		"username": "bob",
+	}
+	require.NoError(t, readDataFile(path, data))
+	assert.Equal(t, map[string]any{
+		"username": "bob",
+		"password": "s3cr3t",
+	}, data)
+
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+	assert.Error(t, readDataFile(path, data))
+}
@@ -0,0 +1,175 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+// resourceType is a syncable secret resource type, that can be referred to
+// by its kind, its plural, or its short name.
+type resourceType struct {
+	kind      string
+	shortName string
+}
+
+// syncableResourceTypes are the resource types that can be synced with
+// "kubectl vso sync". Changing the annotations of any of these resources
+// triggers their reconciliation.
+var syncableResourceTypes = []resourceType{
+	{kind: "HCPVaultSecretsApp", shortName: "hvsa"},
+	{kind: "VaultDynamicSecret", shortName: "vds"},
+	{kind: "VaultPKISecret", shortName: "vps"},
+	{kind: "VaultSSHCertificate", shortName: "vssh"},
+	{kind: "VaultSecretExport", shortName: "vse"},
+	{kind: "VaultStaticSecret", shortName: "vss"},
+	{kind: "VaultTransitKey", shortName: "vtk"},
+}
+
+func (t resourceType) name() string {
+	return strings.ToLower(t.kind)
+}
+
+func (t resourceType) gvk() schema.GroupVersionKind {
+	return secretsv1beta1.GroupVersion.WithKind(t.kind)
+}
+
+func (t resourceType) listGVK() schema.GroupVersionKind {
+	return secretsv1beta1.GroupVersion.WithKind(t.kind + "List")
+}
+
+func (t resourceType) matches(s string) bool {
+	s = strings.ToLower(s)
+	return s == t.shortName || s == t.name() || s == t.name()+"s"
+}
+
+func resourceTypesUsage() string {
+	var b strings.Builder
+	for _, t := range syncableResourceTypes {
+		fmt.Fprintf(&b, "  %-6s %s\n", t.shortName, t.name())
+	}
+	return b.String()
+}
+
+// parseResourceRef parses a resource reference of the form <type>/<name>,
+// e.g. "vds/my-db-creds".
+func parseResourceRef(ref string) (resourceType, string, error) {
+	typ, name, ok := strings.Cut(ref, "/")
+	if !ok || typ == "" || name == "" {
+		return resourceType{}, "", fmt.Errorf("invalid resource %q, must be <type>/<name>", ref)
+	}
+
+	for _, t := range syncableResourceTypes {
+		if t.matches(typ) {
+			return t, name, nil
+		}
+	}
+
+	return resourceType{}, "", fmt.Errorf("unsupported resource type %q", typ)
+}
+
+// syncResource forces the sync of the resource at objKey, by setting its
+// force-sync annotation to now.
+func syncResource(ctx context.Context, c client.Client, t resourceType, objKey client.ObjectKey, now time.Time) error {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(t.gvk())
+	if err := c.Get(ctx, objKey, u); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(u.DeepCopy())
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[consts.AnnotationForceSync] = now.UTC().Format(time.RFC3339Nano)
+	u.SetAnnotations(annotations)
+
+	return c.Patch(ctx, u, patch)
+}
+
+// resourceStatus is the sync status of a single syncable resource.
+type resourceStatus struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Synced is true if the resource's current generation has been reconciled.
+	Synced bool
+	// Valid is empty if the resource's status does not report its validity.
+	Valid string
+	Error string
+}
+
+// listResourceStatus returns the status of every syncable resource in
+// namespace, or in all namespaces if namespace is empty. The resource types
+// whose CRDs are not installed are skipped.
+func listResourceStatus(ctx context.Context, c client.Client, namespace string) ([]resourceStatus, error) {
+	var result []resourceStatus
+	for _, t := range syncableResourceTypes {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(t.listGVK())
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", t.name(), err)
+		}
+
+		for _, item := range list.Items {
+			s := resourceStatus{
+				Kind:      t.kind,
+				Namespace: item.GetNamespace(),
+				Name:      item.GetName(),
+			}
+			lastGeneration, _, _ := unstructured.NestedInt64(item.Object, "status", "lastGeneration")
+			s.Synced = lastGeneration == item.GetGeneration()
+			if valid, ok, _ := unstructured.NestedBool(item.Object, "status", "valid"); ok {
+				s.Valid = strconv.FormatBool(valid)
+			}
+			s.Error, _, _ = unstructured.NestedString(item.Object, "status", "error")
+			result = append(result, s)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func printResourceStatus(w io.Writer, statuses []resourceStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tKIND\tNAME\tSYNCED\tVALID\tERROR")
+	for _, s := range statuses {
+		valid := s.Valid
+		if valid == "" {
+			valid = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			s.Namespace, s.Kind, s.Name, s.Synced, valid, s.Error)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_parseResourceRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		ref      string
+		wantKind string
+		wantName string
+		wantErr  string
+	}{
+		{
+			name:     "short-name",
+			ref:      "vds/my-db-creds",
+			wantKind: "VaultDynamicSecret",
+			wantName: "my-db-creds",
+		},
+		{
+			name:     "kind",
+			ref:      "VaultStaticSecret/app",
+			wantKind: "VaultStaticSecret",
+			wantName: "app",
+		},
+		{
+			name:     "plural",
+			ref:      "vaultpkisecrets/cert",
+			wantKind: "VaultPKISecret",
+			wantName: "cert",
+		},
+		{
+			name:    "no-name",
+			ref:     "vds/",
+			wantErr: `invalid resource "vds/", must be <type>/<name>`,
+		},
+		{
+			name:    "no-type",
+			ref:     "my-db-creds",
+			wantErr: `invalid resource "my-db-creds", must be <type>/<name>`,
+		},
+		{
+			name:    "unsupported",
+			ref:     "vaultauth/default",
+			wantErr: `unsupported resource type "vaultauth"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, name, err := parseResourceRef(tt.ref)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKind, got.kind)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
+
+func Test_syncResource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultDynamicSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-db-creds",
+			Annotations: map[string]string{
+				"foo": "bar",
+			},
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(o).Build()
+	rt, name, err := parseResourceRef("vds/my-db-creds")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	objKey := client.ObjectKey{Namespace: "default", Name: name}
+	require.NoError(t, syncResource(ctx, c, rt, objKey, now))
+
+	got := &secretsv1beta1.VaultDynamicSecret{}
+	require.NoError(t, c.Get(ctx, objKey, got))
+	assert.Equal(t, map[string]string{
+		"foo":                      "bar",
+		consts.AnnotationForceSync: "2024-01-02T03:04:05Z",
+	}, got.GetAnnotations())
+
+	assert.Error(t, syncResource(ctx, c, rt, client.ObjectKey{Namespace: "other", Name: name}, now))
+}
+
+func Test_listResourceStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := testutils.NewFakeClientBuilder().WithObjects(
+		&secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       "app",
+				Generation: 2,
+			},
+			Status: secretsv1beta1.VaultStaticSecretStatus{
+				LastGeneration: 2,
+			},
+		},
+		&secretsv1beta1.VaultPKISecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       "cert",
+				Generation: 3,
+			},
+			Status: secretsv1beta1.VaultPKISecretStatus{
+				LastGeneration: 2,
+				Valid:          ptr.To(false),
+				Error:          consts.ReasonVaultClientError,
+			},
+		},
+		&secretsv1beta1.VaultTransitKey{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "other",
+				Name:       "key",
+				Generation: 1,
+			},
+			Status: secretsv1beta1.VaultTransitKeyStatus{
+				LastGeneration: 1,
+				Valid:          ptr.To(true),
+			},
+		},
+	).Build()
+
+	got, err := listResourceStatus(ctx, c, "default")
+	require.NoError(t, err)
+	assert.Equal(t, []resourceStatus{
+		{
+			Kind:      "VaultPKISecret",
+			Namespace: "default",
+			Name:      "cert",
+			Synced:    false,
+			Valid:     "false",
+			Error:     consts.ReasonVaultClientError,
+		},
+		{
+			Kind:      "VaultStaticSecret",
+			Namespace: "default",
+			Name:      "app",
+			Synced:    true,
+		},
+	}, got)
+
+	got, err = listResourceStatus(ctx, c, "")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, resourceStatus{
+		Kind:      "VaultTransitKey",
+		Namespace: "other",
+		Name:      "key",
+		Synced:    true,
+		Valid:     "true",
+	}, got[2])
+
+	var b bytes.Buffer
+	require.NoError(t, printResourceStatus(&b, got[2:]))
+	assert.Equal(t, "NAMESPACE  KIND             NAME  SYNCED  VALID  ERROR\n"+
+		"other      VaultTransitKey  key   true    true   \n", b.String())
+}
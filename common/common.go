@@ -7,7 +7,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -101,6 +104,16 @@ func init() {
 }
 
 func getAuthRefNamespacedName(obj client.Object) (types.NamespacedName, error) {
+	switch o := obj.(type) {
+	case *secretsv1beta1.HCPVaultSecretsProject:
+		return ParseResourceRef(o.Spec.HCPAuthRef, o.GetNamespace())
+	case *secretsv1beta1.VaultSecretExport:
+		// VaultSecretExport has no destination, so it is not a syncable secret.
+		return ParseResourceRef(o.Spec.VaultAuthRef, o.GetNamespace())
+	case *secretsv1beta1.VaultEventSubscription:
+		return ParseResourceRef(o.Spec.VaultAuthRef, o.GetNamespace())
+	}
+
 	m, err := NewSyncableSecretMetaData(obj)
 	if err != nil {
 		return types.NamespacedName{}, err
@@ -219,7 +232,43 @@ func GetVaultAuthNamespaced(ctx context.Context, c ctrlclient.Client, obj ctrlcl
 		return nil, err
 	}
 
-	return authObj, nil
+	return withVaultConnectionOverride(authObj, obj)
+}
+
+// withVaultConnectionOverride returns a copy of the VaultAuth with its
+// VaultConnectionRef set to obj's spec.vaultConnectionRef, if any. The
+// VaultConnection must be in obj's namespace or the operator's namespace.
+func withVaultConnectionOverride(authObj *secretsv1beta1.VaultAuth, obj ctrlclient.Object) (*secretsv1beta1.VaultAuth, error) {
+	var connRef string
+	switch obj.(type) {
+	case *secretsv1beta1.VaultDynamicSecret, *secretsv1beta1.VaultStaticSecret, *secretsv1beta1.VaultPKISecret:
+		m, err := NewSyncableSecretMetaData(obj)
+		if err != nil {
+			return nil, err
+		}
+		connRef = m.VaultConnectionRef
+	}
+
+	if connRef == "" {
+		return authObj, nil
+	}
+
+	ref, err := ParseResourceRef(connRef, obj.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.Namespace != obj.GetNamespace() && ref.Namespace != OperatorNamespace {
+		return nil, &NamespaceNotAllowedError{
+			TargetNS: obj.GetNamespace(),
+			ObjRef:   ref,
+			RefKind:  "VaultConnection",
+		}
+	}
+
+	cObj := authObj.DeepCopy()
+	cObj.Spec.VaultConnectionRef = ref.String()
+	return cObj, nil
 }
 
 // MergeInVaultAuthGlobal merges the VaultAuthGlobal object into the VaultAuth
@@ -295,18 +344,25 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 		}
 	}
 
+	// the auth method configuration is merged from the VaultAuthGlobal's spec with
+	// the override for the VaultAuth's namespace applied, if any.
+	globalSpec, err := namespacedVaultAuthGlobalSpec(&gObj, cObj.GetNamespace())
+	if err != nil {
+		return nil, nil, &InvalidMergeError{Err: err}
+	}
+
 	// authMethod is the method to be used in the VaultAuth object. If the method is
 	// not set in the VaultAuth object, the default method from the VaultAuthGlobal
 	// object is used.
 	if cObj.Spec.Method == "" {
-		if gObj.Spec.DefaultAuthMethod == "" {
+		if globalSpec.DefaultAuthMethod == "" {
 			return nil, nil, &InvalidMergeError{
 				Err: fmt.Errorf(
 					"no auth method set in VaultAuth %s and no default method set in VaultAuthGlobal %s",
 					client.ObjectKeyFromObject(cObj), authGlobalRef),
 			}
 		}
-		cObj.Spec.Method = gObj.Spec.DefaultAuthMethod
+		cObj.Spec.Method = globalSpec.DefaultAuthMethod
 	}
 
 	var globalAuthMount string
@@ -315,7 +371,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 	var globalAuthHeaders map[string]string
 	switch cObj.Spec.Method {
 	case vaultcredsconsts.ProviderMethodKubernetes:
-		globalAuthMethod := gObj.Spec.Kubernetes
+		globalAuthMethod := globalSpec.Kubernetes
 		mergeTargetAuthMethod := cObj.Spec.Kubernetes
 		if mergeTargetAuthMethod == nil && globalAuthMethod == nil {
 			return nil, nil, &InvalidMergeError{
@@ -344,7 +400,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 			globalAuthHeaders = globalAuthMethod.Headers
 		}
 	case vaultcredsconsts.ProviderMethodJWT:
-		globalAuthMethod := gObj.Spec.JWT
+		globalAuthMethod := globalSpec.JWT
 		mergeTargetAuthMethod := cObj.Spec.JWT
 		if mergeTargetAuthMethod == nil && globalAuthMethod == nil {
 			return nil, nil, &InvalidMergeError{
@@ -373,7 +429,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 			globalAuthHeaders = globalAuthMethod.Headers
 		}
 	case vaultcredsconsts.ProviderMethodAppRole:
-		globalAuthMethod := gObj.Spec.AppRole
+		globalAuthMethod := globalSpec.AppRole
 		mergeTargetAuthMethod := cObj.Spec.AppRole
 		if mergeTargetAuthMethod == nil && globalAuthMethod == nil {
 			return nil, nil, &InvalidMergeError{
@@ -402,7 +458,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 			globalAuthHeaders = globalAuthMethod.Headers
 		}
 	case vaultcredsconsts.ProviderMethodAWS:
-		globalAuthMethod := gObj.Spec.AWS
+		globalAuthMethod := globalSpec.AWS
 		mergeTargetAuthMethod := cObj.Spec.AWS
 		if mergeTargetAuthMethod == nil && globalAuthMethod == nil {
 			return nil, nil, fmt.Errorf("global auth method %s is not configured "+
@@ -429,7 +485,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 			globalAuthHeaders = globalAuthMethod.Headers
 		}
 	case vaultcredsconsts.ProviderMethodGCP:
-		globalAuthMethod := gObj.Spec.GCP
+		globalAuthMethod := globalSpec.GCP
 		mergeTargetAuthMethod := cObj.Spec.GCP
 		if mergeTargetAuthMethod == nil && globalAuthMethod == nil {
 			return nil, nil, &InvalidMergeError{
@@ -467,7 +523,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 	}
 
 	cObj.Spec.Mount = firstNonZeroLen(strLenFunc,
-		cObj.Spec.Mount, globalAuthMount, gObj.Spec.DefaultMount)
+		cObj.Spec.Mount, globalAuthMount, globalSpec.DefaultMount)
 	if cObj.Spec.Mount == "" {
 		return nil, nil, &InvalidMergeError{
 			Err: fmt.Errorf(
@@ -478,7 +534,7 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 	}
 
 	cObj.Spec.Namespace = firstNonZeroLen(strLenFunc,
-		cObj.Spec.Namespace, globalAuthNamespace, gObj.Spec.DefaultVaultNamespace)
+		cObj.Spec.Namespace, globalAuthNamespace, globalSpec.DefaultVaultNamespace)
 
 	paramsMergeStrategy := "none"
 	headersMergeStrategy := "none"
@@ -493,10 +549,10 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 
 	switch paramsMergeStrategy {
 	case "union":
-		cObj.Spec.Params = mergeMaps(gObj.Spec.DefaultParams, globalAuthParams, cObj.Spec.Params)
+		cObj.Spec.Params = mergeMaps(globalSpec.DefaultParams, globalAuthParams, cObj.Spec.Params)
 	case "replace":
 		cObj.Spec.Params = firstNonZeroLen(mapLenFunc[string, string],
-			cObj.Spec.Params, globalAuthParams, gObj.Spec.DefaultParams)
+			cObj.Spec.Params, globalAuthParams, globalSpec.DefaultParams)
 	case "none":
 	default:
 		return nil, nil, &InvalidMergeError{Err: fmt.Errorf("unsupported params merge strategy %q", paramsMergeStrategy)}
@@ -504,21 +560,98 @@ func MergeInVaultAuthGlobal(ctx context.Context, c ctrlclient.Client, o *secrets
 
 	switch headersMergeStrategy {
 	case "union":
-		cObj.Spec.Headers = mergeMaps(gObj.Spec.DefaultHeaders, globalAuthHeaders, cObj.Spec.Headers)
+		cObj.Spec.Headers = mergeMaps(globalSpec.DefaultHeaders, globalAuthHeaders, cObj.Spec.Headers)
 	case "replace":
 		cObj.Spec.Headers = firstNonZeroLen(mapLenFunc[string, string],
-			cObj.Spec.Headers, globalAuthHeaders, gObj.Spec.DefaultHeaders)
+			cObj.Spec.Headers, globalAuthHeaders, globalSpec.DefaultHeaders)
 	case "none":
 	default:
 		return nil, nil, &InvalidMergeError{Err: fmt.Errorf("unsupported headers merge strategy %q", headersMergeStrategy)}
 	}
 
 	cObj.Spec.VaultConnectionRef = firstNonZeroLen(strLenFunc,
-		cObj.Spec.VaultConnectionRef, gObj.Spec.VaultConnectionRef)
+		cObj.Spec.VaultConnectionRef, globalSpec.VaultConnectionRef)
 
 	return cObj, &gObj, nil
 }
 
+// namespacedVaultAuthGlobalSpec returns the spec of gObj with the override of
+// its NamespaceOverrides for namespace applied. The spec of gObj is returned
+// as-is when there is no matching override.
+func namespacedVaultAuthGlobalSpec(gObj *secretsv1beta1.VaultAuthGlobal, namespace string) (*secretsv1beta1.VaultAuthGlobalSpec, error) {
+	override, err := namespaceOverride(gObj, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if override == nil {
+		return &gObj.Spec, nil
+	}
+
+	spec := gObj.Spec.DeepCopy()
+	setIfNotEmpty := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+
+	setIfNotEmpty(&spec.DefaultMount, override.Mount)
+	if spec.Kubernetes != nil {
+		setIfNotEmpty(&spec.Kubernetes.Mount, override.Mount)
+		setIfNotEmpty(&spec.Kubernetes.Role, override.Role)
+		setIfNotEmpty(&spec.Kubernetes.ServiceAccount, override.ServiceAccount)
+	}
+	if spec.JWT != nil {
+		setIfNotEmpty(&spec.JWT.Mount, override.Mount)
+		setIfNotEmpty(&spec.JWT.Role, override.Role)
+		setIfNotEmpty(&spec.JWT.ServiceAccount, override.ServiceAccount)
+	}
+	if spec.AppRole != nil {
+		setIfNotEmpty(&spec.AppRole.Mount, override.Mount)
+	}
+	if spec.AWS != nil {
+		setIfNotEmpty(&spec.AWS.Mount, override.Mount)
+		setIfNotEmpty(&spec.AWS.Role, override.Role)
+		setIfNotEmpty(&spec.AWS.IRSAServiceAccount, override.ServiceAccount)
+	}
+	if spec.GCP != nil {
+		setIfNotEmpty(&spec.GCP.Mount, override.Mount)
+		setIfNotEmpty(&spec.GCP.Role, override.Role)
+		setIfNotEmpty(&spec.GCP.WorkloadIdentityServiceAccount, override.ServiceAccount)
+	}
+
+	return spec, nil
+}
+
+// namespaceOverride returns the override of gObj's NamespaceOverrides that
+// matches namespace, or nil if there is none. An exact match takes precedence,
+// followed by the longest matching pattern. Patterns of the same length are
+// considered in lexical order.
+func namespaceOverride(gObj *secretsv1beta1.VaultAuthGlobal, namespace string) (*secretsv1beta1.VaultAuthGlobalNamespaceOverride, error) {
+	if override, ok := gObj.Spec.NamespaceOverrides[namespace]; ok {
+		return &override, nil
+	}
+
+	var matched string
+	var found bool
+	for _, pattern := range slices.Sorted(maps.Keys(gObj.Spec.NamespaceOverrides)) {
+		ok, err := path.Match(pattern, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q in VaultAuthGlobal %s: %w",
+				pattern, client.ObjectKeyFromObject(gObj), err)
+		}
+		if ok && (!found || len(pattern) > len(matched)) {
+			matched = pattern
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	override := gObj.Spec.NamespaceOverrides[matched]
+	return &override, nil
+}
+
 func mergeMaps[K comparable, V any](maps ...map[K]V) map[K]V {
 	ret := make(map[K]V)
 	for _, m := range maps {
@@ -585,7 +718,8 @@ func GetVaultAuthWithRetry(ctx context.Context, c client.Client, key types.Names
 }
 
 // GetHCPAuthForObj returns the corresponding secretsv1beta1.HCPAuth for obj.
-// Supported client.Object: secretsv1beta1.HCPVaultSecretsApp
+// Supported client.Object: secretsv1beta1.HCPVaultSecretsApp,
+// secretsv1beta1.HCPVaultSecretsProject
 func GetHCPAuthForObj(ctx context.Context, c client.Client, obj client.Object) (*secretsv1beta1.HCPAuth, error) {
 	authRef, err := getAuthRefNamespacedName(obj)
 	if err != nil {
@@ -608,6 +742,36 @@ func GetHCPAuthForObj(ctx context.Context, c client.Client, obj client.Object) (
 	return authObj, nil
 }
 
+// GetVaultEventSubscriptionForObj returns the VaultEventSubscription that is
+// referenced by ref, it can be prefixed with a namespace, otherwise it defaults
+// to obj's namespace. The VaultEventSubscription must allow obj's namespace.
+func GetVaultEventSubscriptionForObj(ctx context.Context, c client.Client, obj client.Object, ref string) (*secretsv1beta1.VaultEventSubscription, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("empty VaultEventSubscription reference for %s", client.ObjectKeyFromObject(obj))
+	}
+
+	subRef, err := ParseResourceRef(ref, obj.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	var subObj secretsv1beta1.VaultEventSubscription
+	if err := c.Get(ctx, subRef, &subObj); err != nil {
+		return nil, err
+	}
+
+	if !isAllowedNamespace(&subObj, obj.GetNamespace(), subObj.Spec.AllowedNamespaces...) {
+		return nil, &NamespaceNotAllowedError{
+			TargetNS:  obj.GetNamespace(),
+			ObjRef:    subRef,
+			RefKind:   "VaultEventSubscription",
+			AllowedNS: subObj.Spec.AllowedNamespaces,
+		}
+	}
+
+	return &subObj, nil
+}
+
 func GetHCPAuthWithRetry(ctx context.Context, c client.Client, key types.NamespacedName,
 	delay time.Duration, max uint64,
 ) (*secretsv1beta1.HCPAuth, error) {
@@ -732,12 +896,21 @@ func FindVaultAuthForStorageEncryption(ctx context.Context, c client.Client) (*s
 
 // GetVaultNamespace for the Syncable Secret type object.
 //
-// Supported types for obj are: VaultDynamicSecret, VaultStaticSecret. VaultPKISecret
+// Supported types for obj are: VaultDynamicSecret, VaultStaticSecret. VaultPKISecret, VaultSSHCertificate, VaultTransitKey,
+// VaultSecretExport, VaultEventSubscription
 func GetVaultNamespace(obj client.Object) (string, error) {
 	var ns string
 	switch o := obj.(type) {
 	case *secretsv1beta1.VaultPKISecret:
 		ns = o.Spec.Namespace
+	case *secretsv1beta1.VaultSSHCertificate:
+		ns = o.Spec.Namespace
+	case *secretsv1beta1.VaultTransitKey:
+		ns = o.Spec.Namespace
+	case *secretsv1beta1.VaultSecretExport:
+		ns = o.Spec.Namespace
+	case *secretsv1beta1.VaultEventSubscription:
+		ns = o.Spec.Namespace
 	case *secretsv1beta1.VaultStaticSecret:
 		ns = o.Spec.Namespace
 	case *secretsv1beta1.VaultDynamicSecret:
@@ -773,13 +946,20 @@ type SyncableSecretMetaData struct {
 	Namespace string
 	// Destination of the syncable-secret object. Maps to obj.Spec.Destination.
 	Destination *secretsv1beta1.Destination
-	AuthRef     string
+	// Destinations are the additional destinations of the syncable-secret
+	// object. Maps to obj.Spec.Destinations, only supported by VaultPKISecret.
+	Destinations []secretsv1beta1.Destination
+	AuthRef      string
+	// VaultConnectionRef overrides the VaultConnection of the VaultAuth. Maps to
+	// obj.Spec.VaultConnectionRef, only supported by VaultDynamicSecret,
+	// VaultStaticSecret, and VaultPKISecret.
+	VaultConnectionRef string
 }
 
 // NewSyncableSecretMetaData returns SyncableSecretMetaData if obj is a supported type.
 // An error will be returned of obj is not a supported type.
 //
-// Supported types for obj are: VaultDynamicSecret, VaultStaticSecret. VaultPKISecret
+// Supported types for obj are: VaultDynamicSecret, VaultStaticSecret. VaultPKISecret, VaultSSHCertificate, VaultTransitKey
 func NewSyncableSecretMetaData(obj ctrlclient.Object) (*SyncableSecretMetaData, error) {
 	meta := &SyncableSecretMetaData{
 		Name:      obj.GetName(),
@@ -792,12 +972,28 @@ func NewSyncableSecretMetaData(obj ctrlclient.Object) (*SyncableSecretMetaData,
 		meta.APIVersion = t.APIVersion
 		meta.Kind = t.Kind
 		meta.AuthRef = t.Spec.VaultAuthRef
+		meta.VaultConnectionRef = t.Spec.VaultConnectionRef
 	case *secretsv1beta1.VaultStaticSecret:
 		meta.Destination = t.Spec.Destination.DeepCopy()
 		meta.APIVersion = t.APIVersion
 		meta.Kind = t.Kind
 		meta.AuthRef = t.Spec.VaultAuthRef
+		meta.VaultConnectionRef = t.Spec.VaultConnectionRef
 	case *secretsv1beta1.VaultPKISecret:
+		meta.Destination = t.Spec.Destination.DeepCopy()
+		for _, d := range t.Spec.Destinations {
+			meta.Destinations = append(meta.Destinations, *d.DeepCopy())
+		}
+		meta.APIVersion = t.APIVersion
+		meta.Kind = t.Kind
+		meta.AuthRef = t.Spec.VaultAuthRef
+		meta.VaultConnectionRef = t.Spec.VaultConnectionRef
+	case *secretsv1beta1.VaultSSHCertificate:
+		meta.Destination = t.Spec.Destination.DeepCopy()
+		meta.APIVersion = t.APIVersion
+		meta.Kind = t.Kind
+		meta.AuthRef = t.Spec.VaultAuthRef
+	case *secretsv1beta1.VaultTransitKey:
 		meta.Destination = t.Spec.Destination.DeepCopy()
 		meta.APIVersion = t.APIVersion
 		meta.Kind = t.Kind
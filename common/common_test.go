@@ -205,6 +205,110 @@ func Test_getAuthRefNamespacedName(t *testing.T) {
 	}
 }
 
+func Test_withVaultConnectionOverride(t *testing.T) {
+	authObj := &secretsv1beta1.VaultAuth{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "baz",
+			Name:      "qux",
+		},
+		Spec: secretsv1beta1.VaultAuthSpec{
+			VaultConnectionRef: "conn",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		obj     client.Object
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "no-override",
+			obj: &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+			},
+			want:    "conn",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unsupported-type",
+			obj: &secretsv1beta1.VaultSSHCertificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+			},
+			want:    "conn",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "vss-without-ns",
+			obj: &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					VaultConnectionRef: "other",
+				},
+			},
+			want:    "foo/other",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "vds-with-ns",
+			obj: &secretsv1beta1.VaultDynamicSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					VaultConnectionRef: "foo/other",
+				},
+			},
+			want:    "foo/other",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "vps-operator-ns",
+			obj: &secretsv1beta1.VaultPKISecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+				Spec: secretsv1beta1.VaultPKISecretSpec{
+					VaultConnectionRef: OperatorNamespace + "/other",
+				},
+			},
+			want:    OperatorNamespace + "/other",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "ns-not-allowed",
+			obj: &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					VaultConnectionRef: "baz/other",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorAs(t, err, new(*NamespaceNotAllowedError), i...)
+			},
+		},
+		{
+			name: "invalid-ref",
+			obj: &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo"},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					VaultConnectionRef: "foo/bar/other",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "invalid name: foo/bar/other", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := withVaultConnectionOverride(authObj, tt.obj)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got.Spec.VaultConnectionRef)
+			// the VaultAuth is never modified.
+			assert.Equal(t, "conn", authObj.Spec.VaultConnectionRef)
+		})
+	}
+}
+
 func Test_isAllowedNamespace(t *testing.T) {
 	t.Parallel()
 
@@ -578,6 +682,104 @@ func TestGetHCPAuthForObj(t *testing.T) {
 	}
 }
 
+func TestGetVaultEventSubscriptionForObj(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	subs := []client.Object{
+		&secretsv1beta1.VaultEventSubscription{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "local"},
+		},
+		&secretsv1beta1.VaultEventSubscription{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "admin", Name: "allowed"},
+			Spec: secretsv1beta1.VaultEventSubscriptionSpec{
+				AllowedNamespaces: []string{"foo"},
+			},
+		},
+		&secretsv1beta1.VaultEventSubscription{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "admin", Name: "wildcard"},
+			Spec: secretsv1beta1.VaultEventSubscriptionSpec{
+				AllowedNamespaces: []string{"*"},
+			},
+		},
+		&secretsv1beta1.VaultEventSubscription{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "admin", Name: "disallowed"},
+			Spec: secretsv1beta1.VaultEventSubscriptionSpec{
+				AllowedNamespaces: []string{"bar"},
+			},
+		},
+		&secretsv1beta1.VaultEventSubscription{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "admin", Name: "unset"},
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(subs...).Build()
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    types.NamespacedName
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "local",
+			ref:     "local",
+			want:    types.NamespacedName{Namespace: "foo", Name: "local"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "external-namespace-allowed",
+			ref:     "admin/allowed",
+			want:    types.NamespacedName{Namespace: "admin", Name: "allowed"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "external-namespace-allowed-wildcard",
+			ref:     "admin/wildcard",
+			want:    types.NamespacedName{Namespace: "admin", Name: "wildcard"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "external-namespace-disallowed",
+			ref:  "admin/disallowed",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				var wantErr *NamespaceNotAllowedError
+				return assert.ErrorAs(t, err, &wantErr, i...)
+			},
+		},
+		{
+			name: "external-namespace-unset",
+			ref:  "admin/unset",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				var wantErr *NamespaceNotAllowedError
+				return assert.ErrorAs(t, err, &wantErr, i...)
+			},
+		},
+		{
+			name: "not-found",
+			ref:  "other",
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return errors.IsNotFound(err)
+			},
+		},
+		{
+			name:    "empty-ref",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "app"},
+			}
+			got, err := GetVaultEventSubscriptionForObj(ctx, c, obj, tt.ref)
+			if !tt.wantErr(t, err, fmt.Sprintf("GetVaultEventSubscriptionForObj(%q)", tt.ref)) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, client.ObjectKeyFromObject(got))
+		})
+	}
+}
+
 func TestNewSyncableSecretMetaData(t *testing.T) {
 	t.Parallel()
 
@@ -686,6 +888,47 @@ func TestNewSyncableSecretMetaData(t *testing.T) {
 	}
 }
 
+func Test_namespaceOverride(t *testing.T) {
+	t.Parallel()
+
+	gObj := &secretsv1beta1.VaultAuthGlobal{
+		Spec: secretsv1beta1.VaultAuthGlobalSpec{
+			NamespaceOverrides: map[string]secretsv1beta1.VaultAuthGlobalNamespaceOverride{
+				"team-a":   {Role: "exact"},
+				"team-*":   {Role: "team"},
+				"team-a*":  {Role: "longest"},
+				"*":        {Role: "any"},
+				"team-?-x": {Role: "single"},
+			},
+		},
+	}
+
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{namespace: "team-a", want: "exact"},
+		{namespace: "team-ab", want: "longest"},
+		{namespace: "team-b", want: "team"},
+		{namespace: "team-b-x", want: "single"},
+		{namespace: "other", want: "any"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := namespaceOverride(gObj, tt.namespace)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.Role)
+		})
+	}
+
+	got, err := namespaceOverride(&secretsv1beta1.VaultAuthGlobal{}, "team-a")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
 func Test_MergeInVaultAuthGlobal(t *testing.T) {
 	t.Parallel()
 
@@ -713,6 +956,7 @@ func Test_MergeInVaultAuthGlobal(t *testing.T) {
 					ServiceAccount:         "sa1",
 					TokenExpirationSeconds: 200,
 					TokenAudiences:         []string{"baz"},
+					ReuseToken:             true,
 				},
 			},
 			AppRole: &secretsv1beta1.VaultAuthGlobalConfigAppRole{
@@ -783,6 +1027,7 @@ func Test_MergeInVaultAuthGlobal(t *testing.T) {
 				ServiceAccount:         "sa1",
 				TokenExpirationSeconds: 200,
 				TokenAudiences:         []string{"baz"},
+				ReuseToken:             true,
 			},
 		},
 	}
@@ -867,6 +1112,36 @@ func Test_MergeInVaultAuthGlobal(t *testing.T) {
 	wantK8sReplaceParamsGlobalDefaults.Spec.VaultAuthGlobalRef.MergeStrategy.Params = "replace"
 	wantK8sReplaceParamsGlobalDefaults.Spec.Params = maps.Clone(gObjDefaultParams.Spec.DefaultParams)
 
+	gObjWithNamespaceOverrides := gObj.DeepCopy()
+	gObjWithNamespaceOverrides.Spec.NamespaceOverrides = map[string]secretsv1beta1.VaultAuthGlobalNamespaceOverride{
+		"b*": {
+			Role: "bee",
+		},
+		"ba*": {
+			Role:           "ant",
+			Mount:          "qux-ba",
+			ServiceAccount: "sa-ba",
+		},
+		"other": {
+			Role: "wasp",
+		},
+	}
+
+	wantK8sNamespaceOverride := wantK8sBase.DeepCopy()
+	wantK8sNamespaceOverride.Spec.Mount = "qux-ba"
+	wantK8sNamespaceOverride.Spec.Kubernetes.Role = "ant"
+	wantK8sNamespaceOverride.Spec.Kubernetes.ServiceAccount = "sa-ba"
+
+	wantK8sNamespaceOverrideLocalRole := wantK8sNamespaceOverride.DeepCopy()
+	wantK8sNamespaceOverrideLocalRole.Spec.Kubernetes.Role = "local"
+
+	gObjWithInvalidNamespaceOverride := gObj.DeepCopy()
+	gObjWithInvalidNamespaceOverride.Spec.NamespaceOverrides = map[string]secretsv1beta1.VaultAuthGlobalNamespaceOverride{
+		"[": {
+			Role: "bee",
+		},
+	}
+
 	tests := []struct {
 		name    string
 		c       client.Client
@@ -876,6 +1151,71 @@ func Test_MergeInVaultAuthGlobal(t *testing.T) {
 		want    *secretsv1beta1.VaultAuth
 		wantErr assert.ErrorAssertionFunc
 	}{
+		{
+			name: "set-kubernetes-namespace-override",
+			c:    builder.Build(),
+			o: &secretsv1beta1.VaultAuth{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "baz",
+				},
+				Spec: secretsv1beta1.VaultAuthSpec{
+					VaultAuthGlobalRef: &secretsv1beta1.VaultAuthGlobalRef{
+						Name:          "buz",
+						MergeStrategy: &secretsv1beta1.MergeStrategy{},
+					},
+					Method: "kubernetes",
+				},
+			},
+			gObj:    gObjWithNamespaceOverrides.DeepCopy(),
+			want:    wantK8sNamespaceOverride,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "set-kubernetes-namespace-override-local-role",
+			c:    builder.Build(),
+			o: &secretsv1beta1.VaultAuth{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "baz",
+				},
+				Spec: secretsv1beta1.VaultAuthSpec{
+					VaultAuthGlobalRef: &secretsv1beta1.VaultAuthGlobalRef{
+						Name:          "buz",
+						MergeStrategy: &secretsv1beta1.MergeStrategy{},
+					},
+					Method: "kubernetes",
+					Kubernetes: &secretsv1beta1.VaultAuthConfigKubernetes{
+						Role: "local",
+					},
+				},
+			},
+			gObj:    gObjWithNamespaceOverrides.DeepCopy(),
+			want:    wantK8sNamespaceOverrideLocalRole,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid-namespace-override-pattern",
+			c:    builder.Build(),
+			o: &secretsv1beta1.VaultAuth{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "baz",
+				},
+				Spec: secretsv1beta1.VaultAuthSpec{
+					VaultAuthGlobalRef: &secretsv1beta1.VaultAuthGlobalRef{
+						Name:          "buz",
+						MergeStrategy: &secretsv1beta1.MergeStrategy{},
+					},
+					Method: "kubernetes",
+				},
+			},
+			gObj: gObjWithInvalidNamespaceOverride.DeepCopy(),
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				var e *InvalidMergeError
+				return assert.ErrorAs(t, err, &e)
+			},
+		},
 		{
 			name: "set-kubernetes",
 			c:    builder.Build(),
@@ -956,6 +1296,7 @@ func Test_MergeInVaultAuthGlobal(t *testing.T) {
 						ServiceAccount:         "sa1",
 						TokenExpirationSeconds: 200,
 						TokenAudiences:         []string{"qux"},
+						ReuseToken:             true,
 					},
 				},
 			},
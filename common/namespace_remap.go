@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamespaceRemap maps renamed Vault namespaces from their old name to their new
+// name. It allows the operator to continue using resources that reference a
+// Vault namespace by its old name, without requiring any updates to those
+// resources. Updating the resources would otherwise result in new Vault clients
+// being created, along with the rotation of all of their secrets.
+type NamespaceRemap map[string]string
+
+// ParseNamespaceRemap parses a NamespaceRemap from a slice of "old=new" Vault
+// namespace pairs.
+func ParseNamespaceRemap(pairs []string) (NamespaceRemap, error) {
+	remap := NamespaceRemap{}
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+
+		oldNS, newNS, ok := strings.Cut(pair, "=")
+		oldNS = normalizeVaultNamespace(oldNS)
+		newNS = normalizeVaultNamespace(newNS)
+		if !ok || oldNS == "" || newNS == "" {
+			return nil, fmt.Errorf("invalid namespace remap %q, must be in the form old=new", pair)
+		}
+
+		if oldNS == newNS {
+			return nil, fmt.Errorf("invalid namespace remap %q, old and new namespaces are the same", pair)
+		}
+
+		if _, ok := remap[oldNS]; ok {
+			return nil, fmt.Errorf("duplicate namespace remap for %q", oldNS)
+		}
+
+		remap[oldNS] = newNS
+	}
+
+	return remap, nil
+}
+
+// Remap returns the new name for the Vault namespace ns. Child namespaces of a
+// renamed namespace are remapped as well. The longest matching old namespace
+// takes precedence. If ns has not been renamed, it is returned unchanged.
+func (r NamespaceRemap) Remap(ns string) string {
+	if len(r) == 0 || ns == "" {
+		return ns
+	}
+
+	normalized := normalizeVaultNamespace(ns)
+	var match string
+	for oldNS := range r {
+		if len(oldNS) <= len(match) {
+			continue
+		}
+		if normalized == oldNS || strings.HasPrefix(normalized, oldNS+"/") {
+			match = oldNS
+		}
+	}
+
+	if match == "" {
+		return ns
+	}
+
+	return r[match] + strings.TrimPrefix(normalized, match)
+}
+
+func normalizeVaultNamespace(ns string) string {
+	return strings.Trim(strings.TrimSpace(ns), "/")
+}
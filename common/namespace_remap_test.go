@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNamespaceRemap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    NamespaceRemap
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "empty",
+			pairs:   nil,
+			want:    NamespaceRemap{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "valid",
+			pairs: []string{"ns1=ns2", "/tenant/ns3/=tenant/ns4", ""},
+			want: NamespaceRemap{
+				"ns1":        "ns2",
+				"tenant/ns3": "tenant/ns4",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "missing-separator",
+			pairs:   []string{"ns1"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty-new",
+			pairs:   []string{"ns1="},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "same",
+			pairs:   []string{"ns1=ns1/"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "duplicate",
+			pairs:   []string{"ns1=ns2", "ns1=ns3"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNamespaceRemap(tt.pairs)
+			if !tt.wantErr(t, err, "ParseNamespaceRemap(%v)", tt.pairs) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNamespaceRemap_Remap(t *testing.T) {
+	t.Parallel()
+
+	remap := NamespaceRemap{
+		"ns1":     "ns2",
+		"ns1/foo": "bar",
+	}
+
+	tests := []struct {
+		name  string
+		remap NamespaceRemap
+		ns    string
+		want  string
+	}{
+		{
+			name:  "nil-remap",
+			remap: nil,
+			ns:    "ns1",
+			want:  "ns1",
+		},
+		{
+			name:  "empty-namespace",
+			remap: remap,
+			ns:    "",
+			want:  "",
+		},
+		{
+			name:  "renamed",
+			remap: remap,
+			ns:    "ns1",
+			want:  "ns2",
+		},
+		{
+			name:  "renamed-with-slashes",
+			remap: remap,
+			ns:    "/ns1/",
+			want:  "ns2",
+		},
+		{
+			name:  "child-of-renamed",
+			remap: remap,
+			ns:    "ns1/baz",
+			want:  "ns2/baz",
+		},
+		{
+			name:  "longest-match",
+			remap: remap,
+			ns:    "ns1/foo/qux",
+			want:  "bar/qux",
+		},
+		{
+			name:  "not-renamed",
+			remap: remap,
+			ns:    "ns10",
+			want:  "ns10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.remap.Remap(tt.ns))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// relativeVaultNamespacePrefix denotes a Vault namespace that is relative to
+// the namespace of the VaultAuth, e.g. "+/team-a".
+const relativeVaultNamespacePrefix = "+"
+
+// allK8sNamespaces is the AllowedVaultNamespaces key that applies to every
+// Kubernetes namespace.
+const allK8sNamespaces = "*"
+
+// VaultNamespaceNotAllowedError is returned when a Kubernetes namespace targets
+// a Vault namespace that it is not allowed to by the operator's
+// AllowedVaultNamespaces.
+type VaultNamespaceNotAllowedError struct {
+	K8sNamespace   string
+	VaultNamespace string
+	Allowed        []string
+}
+
+func (e *VaultNamespaceNotAllowedError) Error() string {
+	return fmt.Sprintf(
+		"vault namespace %q is not allowed for kubernetes namespace %q, allowedVaultNamespaces=%v",
+		e.VaultNamespace, e.K8sNamespace, e.Allowed)
+}
+
+// IsRelativeVaultNamespace returns true if ns is relative to the namespace of
+// the VaultAuth, see ResolveVaultNamespace.
+func IsRelativeVaultNamespace(ns string) bool {
+	ns = strings.TrimSpace(ns)
+	return ns == relativeVaultNamespacePrefix || strings.HasPrefix(ns, relativeVaultNamespacePrefix+"/")
+}
+
+// ResolveVaultNamespace returns the Vault namespace ns resolved against parent.
+// A relative namespace of "+" resolves to parent, and "+/child" resolves to the
+// child namespace of parent. Any other ns is returned unchanged.
+func ResolveVaultNamespace(parent, ns string) (string, error) {
+	if !IsRelativeVaultNamespace(ns) {
+		return ns, nil
+	}
+
+	child := strings.TrimPrefix(strings.TrimSpace(ns), relativeVaultNamespacePrefix)
+	child = normalizeVaultNamespace(child)
+	for _, s := range strings.Split(child, "/") {
+		if child != "" && (s == "" || s == "." || s == "..") {
+			return "", fmt.Errorf("invalid relative vault namespace %q", ns)
+		}
+	}
+
+	parent = normalizeVaultNamespace(parent)
+	switch {
+	case child == "":
+		return parent, nil
+	case parent == "":
+		return child, nil
+	default:
+		return parent + "/" + child, nil
+	}
+}
+
+// AllowedVaultNamespaces maps a Kubernetes namespace to the Vault namespaces
+// that its resources may target. Child namespaces of an allowed Vault namespace
+// are allowed as well. The "*" key applies to all Kubernetes namespaces.
+// Kubernetes namespaces without any entries are not restricted.
+type AllowedVaultNamespaces map[string][]string
+
+// ParseAllowedVaultNamespaces parses AllowedVaultNamespaces from a slice of
+// "k8s-namespace=vault-namespace" pairs. A Kubernetes namespace may be listed
+// more than once.
+func ParseAllowedVaultNamespaces(pairs []string) (AllowedVaultNamespaces, error) {
+	allowed := AllowedVaultNamespaces{}
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+
+		k8sNS, vaultNS, ok := strings.Cut(pair, "=")
+		k8sNS = strings.TrimSpace(k8sNS)
+		vaultNS = normalizeVaultNamespace(vaultNS)
+		if !ok || k8sNS == "" || vaultNS == "" {
+			return nil, fmt.Errorf(
+				"invalid allowed vault namespace %q, must be in the form k8s-namespace=vault-namespace", pair)
+		}
+
+		if IsRelativeVaultNamespace(vaultNS) {
+			return nil, fmt.Errorf(
+				"invalid allowed vault namespace %q, relative vault namespaces are not supported", pair)
+		}
+
+		if !slices.Contains(allowed[k8sNS], vaultNS) {
+			allowed[k8sNS] = append(allowed[k8sNS], vaultNS)
+		}
+	}
+
+	return allowed, nil
+}
+
+// Allowed returns an error if the Kubernetes namespace k8sNS is not allowed to
+// target the Vault namespace vaultNS.
+func (a AllowedVaultNamespaces) Allowed(k8sNS, vaultNS string) error {
+	allowed := append(slices.Clone(a[allK8sNamespaces]), a[k8sNS]...)
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	normalized := normalizeVaultNamespace(vaultNS)
+	for _, ns := range allowed {
+		if normalized == ns || strings.HasPrefix(normalized, ns+"/") {
+			return nil
+		}
+	}
+
+	return &VaultNamespaceNotAllowedError{
+		K8sNamespace:   k8sNS,
+		VaultNamespace: vaultNS,
+		Allowed:        allowed,
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVaultNamespace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		parent  string
+		ns      string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "absolute",
+			parent:  "org",
+			ns:      "other/team-a",
+			want:    "other/team-a",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "empty",
+			parent:  "org",
+			ns:      "",
+			want:    "",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "relative-child",
+			parent:  "org/",
+			ns:      "+/team-a",
+			want:    "org/team-a",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "relative-grandchild",
+			parent:  "org",
+			ns:      "+/team-a/app/",
+			want:    "org/team-a/app",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "relative-to-root",
+			parent:  "",
+			ns:      "+/team-a",
+			want:    "team-a",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "relative-self",
+			parent:  "org",
+			ns:      "+",
+			want:    "org",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "not-relative",
+			parent:  "org",
+			ns:      "+team-a",
+			want:    "+team-a",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid-parent-ref",
+			parent:  "org/team-a",
+			ns:      "+/../team-b",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid-empty-segment",
+			parent:  "org",
+			ns:      "+/team-a//app",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveVaultNamespace(tt.parent, tt.ns)
+			if !tt.wantErr(t, err, "ResolveVaultNamespace(%q, %q)", tt.parent, tt.ns) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseAllowedVaultNamespaces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    AllowedVaultNamespaces
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "empty",
+			pairs:   nil,
+			want:    AllowedVaultNamespaces{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "valid",
+			pairs: []string{"team-a=/org/team-a/", "team-a=org/shared", "team-a=org/shared", "*=org/common", ""},
+			want: AllowedVaultNamespaces{
+				"team-a": {"org/team-a", "org/shared"},
+				"*":      {"org/common"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "missing-separator",
+			pairs:   []string{"team-a"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty-vault-namespace",
+			pairs:   []string{"team-a=/"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty-k8s-namespace",
+			pairs:   []string{"=org"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "relative",
+			pairs:   []string{"team-a=+/team-a"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAllowedVaultNamespaces(tt.pairs)
+			if !tt.wantErr(t, err, "ParseAllowedVaultNamespaces(%v)", tt.pairs) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAllowedVaultNamespaces_Allowed(t *testing.T) {
+	t.Parallel()
+
+	allowed := AllowedVaultNamespaces{
+		"team-a": {"org/team-a"},
+		"team-b": {"org/team-b"},
+		"*":      {"org/shared"},
+	}
+
+	tests := []struct {
+		name    string
+		allowed AllowedVaultNamespaces
+		k8sNS   string
+		vaultNS string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "nil-allowed",
+			allowed: nil,
+			k8sNS:   "team-a",
+			vaultNS: "org/team-b",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "allowed",
+			allowed: allowed,
+			k8sNS:   "team-a",
+			vaultNS: "/org/team-a/",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "allowed-child",
+			allowed: allowed,
+			k8sNS:   "team-a",
+			vaultNS: "org/team-a/app",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "allowed-all-k8s-namespaces",
+			allowed: allowed,
+			k8sNS:   "team-a",
+			vaultNS: "org/shared",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "denied-other-team",
+			allowed: allowed,
+			k8sNS:   "team-a",
+			vaultNS: "org/team-b",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorAs(t, err, new(*VaultNamespaceNotAllowedError), i...)
+			},
+		},
+		{
+			name:    "denied-prefix",
+			allowed: allowed,
+			k8sNS:   "team-a",
+			vaultNS: "org/team-ab",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "denied-root",
+			allowed: allowed,
+			k8sNS:   "team-a",
+			vaultNS: "",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "restricted-by-all-k8s-namespaces",
+			allowed: allowed,
+			k8sNS:   "team-c",
+			vaultNS: "org/team-a",
+			wantErr: assert.Error,
+		},
+		{
+			name: "unrestricted",
+			allowed: AllowedVaultNamespaces{
+				"team-a": {"org/team-a"},
+			},
+			k8sNS:   "team-c",
+			vaultNS: "org/team-a",
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.wantErr(t, tt.allowed.Allowed(tt.k8sNS, tt.vaultNS),
+				"Allowed(%q, %q)", tt.k8sNS, tt.vaultNS)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// VaultPathNotAllowedError is returned when a resource references a Vault path
+// that it is not allowed to by the operator's VaultPathPolicy.
+type VaultPathNotAllowedError struct {
+	Path     string
+	DeniedBy string
+	Allowed  []string
+}
+
+func (e *VaultPathNotAllowedError) Error() string {
+	if e.DeniedBy != "" {
+		return fmt.Sprintf("vault path %q is denied by %q", e.Path, e.DeniedBy)
+	}
+	return fmt.Sprintf("vault path %q is not allowed, allowedVaultPaths=%v", e.Path, e.Allowed)
+}
+
+type vaultPathPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// VaultPathPolicy restricts the Vault paths that the resources may reference.
+// A path is not allowed if it matches any of the denied patterns, or if there
+// are allowed patterns and it matches none of them. A nil VaultPathPolicy
+// allows every path.
+type VaultPathPolicy struct {
+	allowed []vaultPathPattern
+	denied  []vaultPathPattern
+}
+
+// NewVaultPathPolicy returns a VaultPathPolicy for the allowed and denied path
+// patterns. The patterns are globs where "*" matches any sequence of
+// characters, including "/", e.g. "kv/apps/*". The returned VaultPathPolicy is
+// nil if there are no patterns.
+func NewVaultPathPolicy(allowed, denied []string) *VaultPathPolicy {
+	var p VaultPathPolicy
+	for _, v := range []struct {
+		patterns []string
+		compiled *[]vaultPathPattern
+	}{
+		{patterns: allowed, compiled: &p.allowed},
+		{patterns: denied, compiled: &p.denied},
+	} {
+		for _, pattern := range v.patterns {
+			pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+			if pattern == "" {
+				continue
+			}
+
+			*v.compiled = append(*v.compiled, vaultPathPattern{
+				pattern: pattern,
+				re: regexp.MustCompile(
+					"^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"),
+			})
+		}
+	}
+
+	if len(p.allowed) == 0 && len(p.denied) == 0 {
+		return nil
+	}
+
+	return &p
+}
+
+// Allowed returns an error if vaultPath is not allowed.
+func (p *VaultPathPolicy) Allowed(vaultPath string) error {
+	if p == nil {
+		return nil
+	}
+
+	normalized := strings.TrimPrefix(path.Clean("/"+vaultPath), "/")
+	for _, d := range p.denied {
+		if d.re.MatchString(normalized) {
+			return &VaultPathNotAllowedError{
+				Path:     vaultPath,
+				DeniedBy: d.pattern,
+			}
+		}
+	}
+
+	if len(p.allowed) == 0 {
+		return nil
+	}
+
+	allowed := make([]string, 0, len(p.allowed))
+	for _, a := range p.allowed {
+		if a.re.MatchString(normalized) {
+			return nil
+		}
+		allowed = append(allowed, a.pattern)
+	}
+
+	return &VaultPathNotAllowedError{
+		Path:    vaultPath,
+		Allowed: allowed,
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultPathPolicy_Allowed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		allowed []string
+		denied  []string
+		path    string
+		wantErr error
+	}{
+		{
+			name: "unrestricted",
+			path: "sys/policies/acl/admin",
+		},
+		{
+			name:    "allowed",
+			allowed: []string{"kv/apps/*", "db/creds/*"},
+			path:    "kv/apps/team-a/config",
+		},
+		{
+			name:    "not-allowed",
+			allowed: []string{"kv/apps/*", "db/creds/*"},
+			path:    "kv/infra/config",
+			wantErr: &VaultPathNotAllowedError{
+				Path:    "kv/infra/config",
+				Allowed: []string{"kv/apps/*", "db/creds/*"},
+			},
+		},
+		{
+			name:   "denied",
+			denied: []string{"sys/*"},
+			path:   "sys/policies/acl/admin",
+			wantErr: &VaultPathNotAllowedError{
+				Path:     "sys/policies/acl/admin",
+				DeniedBy: "sys/*",
+			},
+		},
+		{
+			name:    "denied-takes-precedence",
+			allowed: []string{"kv/*"},
+			denied:  []string{"kv/*/admin"},
+			path:    "kv/apps/admin",
+			wantErr: &VaultPathNotAllowedError{
+				Path:     "kv/apps/admin",
+				DeniedBy: "kv/*/admin",
+			},
+		},
+		{
+			name:    "normalized",
+			allowed: []string{"/kv/apps/*/"},
+			denied:  []string{"sys/*"},
+			path:    "/kv//apps/team-a",
+		},
+		{
+			name:   "normalized-denied",
+			denied: []string{"sys/*"},
+			path:   "kv/../sys/raw",
+			wantErr: &VaultPathNotAllowedError{
+				Path:     "kv/../sys/raw",
+				DeniedBy: "sys/*",
+			},
+		},
+		{
+			name:    "literal",
+			allowed: []string{"kv.apps/*"},
+			path:    "kv-apps/config",
+			wantErr: &VaultPathNotAllowedError{
+				Path:    "kv-apps/config",
+				Allowed: []string{"kv.apps/*"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewVaultPathPolicy(tt.allowed, tt.denied).Allowed(tt.path)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewVaultPathPolicy(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NewVaultPathPolicy(nil, nil))
+	assert.Nil(t, NewVaultPathPolicy([]string{""}, []string{" / "}))
+	assert.NotNil(t, NewVaultPathPolicy(nil, []string{"sys/*"}))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// WatchNamespaces returns the sorted set of Kubernetes namespaces that an
+// operator instance restricted to namespaces must watch, it always includes
+// operatorNamespace, since the operator's own resources reside there. It is
+// nil if namespaces is empty, i.e. all namespaces are watched.
+func WatchNamespaces(operatorNamespace string, namespaces []string) []string {
+	var ret []string
+	for _, ns := range namespaces {
+		if ns = strings.TrimSpace(ns); ns != "" && !slices.Contains(ret, ns) {
+			ret = append(ret, ns)
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil
+	}
+
+	if !slices.Contains(ret, operatorNamespace) {
+		ret = append(ret, operatorNamespace)
+	}
+	slices.Sort(ret)
+
+	return ret
+}
+
+// ScopedLeaderElectionID returns the leader election ID of an operator
+// instance that watches namespaces, so that multiple instances that are
+// restricted to different namespaces never compete for the same leader lease.
+// The id is returned unchanged if all namespaces are watched.
+func ScopedLeaderElectionID(id string, namespaces []string) string {
+	if len(namespaces) == 0 {
+		return id
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(namespaces, ",")))
+	return id + "-" + hex.EncodeToString(sum[:])[:10]
+}
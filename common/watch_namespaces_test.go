@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchNamespaces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       []string
+	}{
+		{
+			name: "all",
+		},
+		{
+			name:       "empty",
+			namespaces: []string{"", " "},
+		},
+		{
+			name:       "restricted",
+			namespaces: []string{"team-b", " team-a", "team-b"},
+			want:       []string{"team-a", "team-b", "vso"},
+		},
+		{
+			name:       "operator-namespace",
+			namespaces: []string{"vso", "team-a"},
+			want:       []string{"team-a", "vso"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, WatchNamespaces("vso", tt.namespaces))
+		})
+	}
+}
+
+func TestScopedLeaderElectionID(t *testing.T) {
+	t.Parallel()
+
+	id := "b0d477c0.hashicorp.com"
+	assert.Equal(t, id, ScopedLeaderElectionID(id, nil))
+
+	teamA := ScopedLeaderElectionID(id, []string{"team-a", "vso"})
+	assert.Regexp(t, `^b0d477c0\.hashicorp\.com-[0-9a-f]{10}$`, teamA)
+	assert.Equal(t, teamA, ScopedLeaderElectionID(id, []string{"team-a", "vso"}))
+	assert.NotEqual(t, teamA, ScopedLeaderElectionID(id, []string{"team-b", "vso"}))
+}
@@ -22,5 +22,26 @@ const (
 	AWSSessionName     = "session_name"
 	AWSSessionToken    = "session_token"
 
-	AnnotationResync = "vso.hashicorp.com/resync"
+	// AnnotationForceSync forces a full sync of a syncable secret resource
+	// whenever its value changes, regardless of whether the source data has
+	// changed or its refresh horizon has elapsed. The value is typically a
+	// timestamp, it is set by "kubectl vso sync".
+	AnnotationForceSync = "vso.secrets.hashicorp.com/force-sync"
+	// AnnotationHVSWebhookEvent is set on an HCPVaultSecretsApp by an operator
+	// replica that is not the leader, when its HVS webhook receiver is notified of
+	// a change to the App. Changing it requeues the HCPVaultSecretsApp on the
+	// leader. The value is the time the event was received.
+	AnnotationHVSWebhookEvent = "vso.secrets.hashicorp.com/hvs-webhook-event"
+	// AnnotationVaultAuthRef sets the VaultAuth used to service an
+	// external-secrets.io ExternalSecret, or all ExternalSecrets of a SecretStore.
+	AnnotationVaultAuthRef = "vso.hashicorp.com/vault-auth-ref"
+	// AnnotationUnwrap describes how to unwrap the Vault response wrapping token
+	// that is synced to a destination Secret.
+	AnnotationUnwrap = "vso.hashicorp.com/unwrap"
+	// WrappingTokenKey is the destination Secret data key of the Vault response
+	// wrapping token.
+	WrappingTokenKey = "token"
+	// LabelOrphaned is set on the destination Secrets that are retained when
+	// the operator is uninstalled with the orphan destination Secrets policy.
+	LabelOrphaned = "vso.secrets.hashicorp.com/orphaned"
 )
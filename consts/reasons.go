@@ -8,6 +8,7 @@ const (
 	ReasonInvalidConfiguration       = "InvalidConfiguration"
 	ReasonInvalidResourceRef         = "InvalidResourceRef"
 	ReasonK8sClientError             = "K8sClientError"
+	ReasonRolloutRestartDeferred     = "RolloutRestartDeferred"
 	ReasonRolloutRestartFailed       = "RolloutRestartFailed"
 	ReasonRolloutRestartTriggered    = "RolloutRestartTriggered"
 	ReasonRolloutRestartUnsupported  = "RolloutRestartUnsupported"
@@ -25,6 +26,8 @@ const (
 	ReasonVaultClientError           = "VaultClientError"
 	ReasonVaultStaticSecret          = "VaultStaticSecretError"
 	ReasonHVSSecret                  = "HVSSecretError"
+	ReasonHVSProjectError            = "HVSProjectError"
+	ReasonHVSProjectAppsSynced       = "HVSProjectAppsSynced"
 	ReasonSecretDataDrift            = "SecretDataDrift"
 	ReasonInexistentDestination      = "InexistentDestination"
 	ReasonResourceUpdated            = "ResourceUpdated"
@@ -39,4 +42,26 @@ const (
 	ReasonVaultClientConfigChanged   = "VaultClientConfigChanged"
 	ReasonEventWatcherError          = "EventWatcherError"
 	ReasonEventWatcherStarted        = "EventWatcherStarted"
+	ReasonCertificateRequestError    = "CertificateRequestError"
+	ReasonTemplateRenderError        = "TemplateRenderError"
+	ReasonRotationDeferred           = "RotationDeferred"
+	ReasonDeletionPolicyError        = "DeletionPolicyError"
+	ReasonTransitDecryptError        = "TransitDecryptError"
+	ReasonTransitKeyVersionStranded  = "TransitKeyVersionStranded"
+	ReasonAccountCheckIn             = "AccountCheckIn"
+	ReasonSecretExported             = "SecretExported"
+	ReasonSecretExportError          = "SecretExportError"
+	ReasonSecretExportConflict       = "SecretExportConflict"
+	ReasonSyncSuspended              = "SyncSuspended"
+	ReasonSyncResumed                = "SyncResumed"
+	ReasonVaultPathNotAllowed        = "VaultPathNotAllowed"
+	ReasonSourceDeleted              = "SourceDeleted"
+	ReasonSyncDegraded               = "SyncDegraded"
+	ReasonSyncRecovered              = "SyncRecovered"
+	ReasonSecretDataTooLarge         = "SecretDataTooLarge"
+	ReasonSecretTypeIncompatible     = "SecretTypeIncompatible"
+	ReasonClientCertificateRotated   = "ClientCertificateRotated"
+	ReasonCABundleRotated            = "CABundleRotated"
+	ReasonVaultThrottled             = "VaultThrottled"
+	ReasonVaultLoginDenied           = "VaultLoginDenied"
 )
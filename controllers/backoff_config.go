@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+// syncBackoffOpts returns the ExponentialBackOffOpts that override the
+// operator's backoff with o's spec.syncConfig.backoff. Invalid values are
+// logged and ignored.
+func syncBackoffOpts(ctx context.Context, o client.Object) []backoff.ExponentialBackOffOpts {
+	var cfg *secretsv1beta1.BackoffConfig
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		if t.Spec.SyncConfig != nil {
+			cfg = t.Spec.SyncConfig.Backoff
+		}
+	case *secretsv1beta1.HCPVaultSecretsApp:
+		if t.Spec.SyncConfig != nil {
+			cfg = t.Spec.SyncConfig.Backoff
+		}
+	}
+
+	opts, err := backoffConfigOpts(cfg)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Ignoring invalid spec.syncConfig.backoff values")
+	}
+	return opts
+}
+
+// backoffConfigOpts returns the ExponentialBackOffOpts of cfg. The invalid
+// values of cfg are skipped, and returned in the error.
+func backoffConfigOpts(cfg *secretsv1beta1.BackoffConfig) ([]backoff.ExponentialBackOffOpts, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var opts []backoff.ExponentialBackOffOpts
+	var errs error
+	parseDuration := func(name, v string, f func(time.Duration) backoff.ExponentialBackOffOpts) {
+		if v == "" {
+			return
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			errs = errors.Join(errs, fmt.Errorf("invalid %s %q", name, v))
+			return
+		}
+		opts = append(opts, f(d))
+	}
+
+	parseDuration("initialInterval", cfg.InitialInterval, backoff.WithInitialInterval)
+	parseDuration("maxInterval", cfg.MaxInterval, backoff.WithMaxInterval)
+	parseDuration("maxElapsedTime", cfg.MaxElapsedTime, backoff.WithMaxElapsedTime)
+	if cfg.Multiplier != "" {
+		m, err := strconv.ParseFloat(cfg.Multiplier, 64)
+		if err != nil || m <= 0 {
+			errs = errors.Join(errs, fmt.Errorf("invalid multiplier %q, must be greater than 0", cfg.Multiplier))
+		} else {
+			opts = append(opts, backoff.WithMultiplier(m))
+		}
+	}
+
+	return opts, errs
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+func Test_backoffConfigOpts(t *testing.T) {
+	t.Parallel()
+
+	defaults := DefaultExponentialBackOffOpts()
+	tests := []struct {
+		name    string
+		cfg     *secretsv1beta1.BackoffConfig
+		want    *backoff.ExponentialBackOff
+		wantErr string
+	}{
+		{
+			name: "nil",
+			want: backoff.NewExponentialBackOff(defaults...),
+		},
+		{
+			name: "all",
+			cfg: &secretsv1beta1.BackoffConfig{
+				InitialInterval: "500ms",
+				MaxInterval:     "10s",
+				Multiplier:      "2",
+				MaxElapsedTime:  "1h",
+			},
+			want: backoff.NewExponentialBackOff(append(defaults,
+				backoff.WithInitialInterval(time.Millisecond*500),
+				backoff.WithMaxInterval(time.Second*10),
+				backoff.WithMultiplier(2),
+				backoff.WithMaxElapsedTime(time.Hour),
+			)...),
+		},
+		{
+			name: "invalid",
+			cfg: &secretsv1beta1.BackoffConfig{
+				InitialInterval: "1s",
+				MaxInterval:     "10",
+				Multiplier:      "0",
+			},
+			want: backoff.NewExponentialBackOff(append(defaults,
+				backoff.WithInitialInterval(time.Second),
+			)...),
+			wantErr: "invalid maxInterval \"10\"\ninvalid multiplier \"0\", must be greater than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := backoffConfigOpts(tt.cfg)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			r := NewBackOffRegistry(defaults...)
+			entry, created := r.Get(client.ObjectKey{Namespace: "foo", Name: "bar"}, opts...)
+			require.True(t, created)
+			got, ok := entry.bo.(*backoff.ExponentialBackOff)
+			require.True(t, ok)
+			assert.Equal(t, tt.want.InitialInterval, got.InitialInterval)
+			assert.Equal(t, tt.want.MaxInterval, got.MaxInterval)
+			assert.Equal(t, tt.want.Multiplier, got.Multiplier)
+			assert.Equal(t, tt.want.MaxElapsedTime, got.MaxElapsedTime)
+		})
+	}
+}
+
+func Test_syncBackoffOpts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	assert.Empty(t, syncBackoffOpts(ctx, &secretsv1beta1.VaultStaticSecret{}))
+	assert.Empty(t, syncBackoffOpts(ctx, &secretsv1beta1.VaultDynamicSecret{}))
+	assert.Len(t, syncBackoffOpts(ctx, &secretsv1beta1.VaultStaticSecret{
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			SyncConfig: &secretsv1beta1.SyncConfig{
+				Backoff: &secretsv1beta1.BackoffConfig{
+					InitialInterval: "1s",
+					MaxInterval:     "5s",
+				},
+			},
+		},
+	}), 2)
+	assert.Len(t, syncBackoffOpts(ctx, &secretsv1beta1.HCPVaultSecretsApp{
+		Spec: secretsv1beta1.HCPVaultSecretsAppSpec{
+			SyncConfig: &secretsv1beta1.HVSSyncConfig{
+				Backoff: &secretsv1beta1.BackoffConfig{
+					Multiplier: "1.5",
+				},
+			},
+		},
+	}), 1)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+// Status returns the BackOffStatus as of the last call to NextBackOff, or nil
+// if NextBackOff was never called.
+func (s *BackOff) Status() *secretsv1beta1.BackOffStatus {
+	if s.failures == 0 {
+		return nil
+	}
+
+	status := &secretsv1beta1.BackOffStatus{
+		Failures: s.failures,
+	}
+	if !s.next.IsZero() {
+		status.Interval = s.last.String()
+		status.NextRetryTime = s.next.Unix()
+	}
+	return status
+}
+
+// nextBackOff returns the next backoff duration of o's entry, and publishes
+// the backoff state in o's status. The status is updated right away, since
+// the failed reconciliation may not update it otherwise. Errors updating the
+// status are logged, they never fail the reconciliation.
+func nextBackOff(ctx context.Context, c client.Client, o client.Object, entry *BackOff) time.Duration {
+	d := entry.NextBackOff()
+	status := backOffStatus(o)
+	if status == nil {
+		return d
+	}
+
+	*status = entry.Status()
+	if err := c.Status().Update(ctx, o); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update the back-off status")
+	}
+	return d
+}
+
+// resetBackOff deletes o's entry from the BackOffRegistry, and clears the
+// backoff state, and the Throttled condition, from o's status. The status is
+// updated by the caller, along with the outcome of the successful sync.
+func resetBackOff(r *BackOffRegistry, o client.Object) bool {
+	if status := backOffStatus(o); status != nil {
+		*status = nil
+	}
+	if conditions := statusConditions(o); conditions != nil {
+		*conditions = removeConditions(*conditions, conditionTypeThrottled)
+	}
+	return r.Delete(client.ObjectKeyFromObject(o))
+}
+
+func backOffStatus(o client.Object) **secretsv1beta1.BackOffStatus {
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		return &t.Status.BackOff
+	case *secretsv1beta1.VaultDynamicSecret:
+		return &t.Status.BackOff
+	case *secretsv1beta1.VaultPKISecret:
+		return &t.Status.BackOff
+	case *secretsv1beta1.VaultSSHCertificate:
+		return &t.Status.BackOff
+	case *secretsv1beta1.VaultTransitKey:
+		return &t.Status.BackOff
+	case *secretsv1beta1.VaultSecretExport:
+		return &t.Status.BackOff
+	case *secretsv1beta1.HCPVaultSecretsApp:
+		return &t.Status.BackOff
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestBackOff_Status(t *testing.T) {
+	t.Parallel()
+
+	entry := &BackOff{
+		bo: backoff.NewConstantBackOff(time.Second * 30),
+	}
+	assert.Nil(t, entry.Status())
+
+	entry.NextBackOff()
+	got := entry.Status()
+	require.NotNil(t, got)
+	assert.Equal(t, 1, got.Failures)
+	assert.Equal(t, "30s", got.Interval)
+	assert.NotZero(t, got.NextRetryTime)
+
+	entry.NextBackOff()
+	assert.Equal(t, 2, entry.Status().Failures)
+
+	// the retries are exhausted.
+	entry.bo = &backoff.StopBackOff{}
+	entry.NextBackOff()
+	assert.Equal(t, &secretsv1beta1.BackOffStatus{
+		Failures: 3,
+	}, entry.Status())
+}
+
+func Test_nextBackOff(t *testing.T) {
+	ctx := context.Background()
+
+	o := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+	}
+	objKey := client.ObjectKeyFromObject(o)
+	c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+	r := NewBackOffRegistry(backoff.WithInitialInterval(time.Second*5), backoff.WithRandomizationFactor(0))
+
+	for i := 1; i <= 2; i++ {
+		entry, _ := r.Get(objKey)
+		assert.Greater(t, nextBackOff(ctx, c, o, entry), time.Duration(0))
+
+		var got secretsv1beta1.VaultStaticSecret
+		require.NoError(t, c.Get(ctx, objKey, &got))
+		require.NotNil(t, got.Status.BackOff)
+		assert.Equal(t, i, got.Status.BackOff.Failures)
+		assert.NotEmpty(t, got.Status.BackOff.Interval)
+		assert.NotZero(t, got.Status.BackOff.NextRetryTime)
+	}
+
+	assert.True(t, resetBackOff(r, o))
+	assert.Nil(t, o.Status.BackOff)
+	assert.False(t, resetBackOff(r, o))
+
+	// resources without a back-off status are never updated.
+	entry, _ := r.Get(objKey)
+	auth := &secretsv1beta1.VaultAuth{}
+	assert.Greater(t, nextBackOff(ctx, c, auth, entry), time.Duration(0))
+}
@@ -5,12 +5,17 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/hashicorp/vault/api"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -18,6 +23,8 @@ import (
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/vault"
 )
 
 var (
@@ -30,7 +37,47 @@ var (
 	nowFunc = time.Now
 )
 
-const renewalPercentCap = 90
+const (
+	renewalPercentCap = 90
+
+	// conditionTypeTemplatesRendered is the condition type that reports the
+	// outcome of rendering a resource's templates.
+	conditionTypeTemplatesRendered = "TemplatesRendered"
+	reasonIsolateTemplateErrors    = "IsolateTemplateErrors"
+
+	// conditionTypeSecretVersionCurrent is the condition type that reports
+	// whether the pinned version of a KV-v2 secret is its current version.
+	conditionTypeSecretVersionCurrent = "SecretVersionCurrent"
+	reasonVersionPinned               = "VersionPinned"
+
+	// conditionTypeEventWatcherHealthy is the condition type that reports
+	// whether the event watcher of a resource with instant updates is
+	// connected to Vault.
+	conditionTypeEventWatcherHealthy = "EventWatcherHealthy"
+	reasonEventWatcherConnected      = "Connected"
+	reasonEventWatcherConnecting     = "Connecting"
+	reasonEventWatcherFailing        = "Failing"
+	// eventWatcherFailureThreshold is the number of consecutive failures after
+	// which the event watcher is reported as unhealthy.
+	eventWatcherFailureThreshold = 3
+
+	// conditionTypeSecretDataTooLarge is the condition type that reports that
+	// the data to sync exceeds the Secret size limit.
+	conditionTypeSecretDataTooLarge = "SecretDataTooLarge"
+	// conditionTypeSecretTypeIncompatible is the condition type that reports that
+	// the data to sync does not satisfy the constraints of the Secret's type.
+	conditionTypeSecretTypeIncompatible = "SecretTypeIncompatible"
+
+	// DestinationSecretsPolicyRetain retains the destination Secrets when the
+	// controller is being deleted.
+	DestinationSecretsPolicyRetain = "retain"
+	// DestinationSecretsPolicyDelete deletes the destination Secrets when the
+	// controller is being deleted.
+	DestinationSecretsPolicyDelete = "delete"
+	// DestinationSecretsPolicyOrphan retains the destination Secrets when the
+	// controller is being deleted, and labels them with consts.LabelOrphaned.
+	DestinationSecretsPolicyOrphan = "orphan"
+)
 
 type empty struct{}
 
@@ -135,6 +182,8 @@ func RemoveAllFinalizers(ctx context.Context, c client.Client, log logr.Logger)
 	// * VaultDynamicSecret
 	// * VaultStaticSecret <- not currently implemented
 	// * VaultPKISecret
+	// * VaultSSHCertificate
+	// * VaultTransitKey
 
 	vamList := &secretsv1beta1.VaultAuthList{}
 	err := c.List(ctx, vamList, opts...)
@@ -163,6 +212,20 @@ func RemoveAllFinalizers(ctx context.Context, c client.Client, log logr.Logger)
 		log.Error(err, "Unable to list VaultPKISecret resources")
 	}
 	removeFinalizers(ctx, c, log, vpkiList)
+
+	vsshList := &secretsv1beta1.VaultSSHCertificateList{}
+	err = c.List(ctx, vsshList, opts...)
+	if err != nil {
+		log.Error(err, "Unable to list VaultSSHCertificate resources")
+	}
+	removeFinalizers(ctx, c, log, vsshList)
+
+	vtkList := &secretsv1beta1.VaultTransitKeyList{}
+	err = c.List(ctx, vtkList, opts...)
+	if err != nil {
+		log.Error(err, "Unable to list VaultTransitKey resources")
+	}
+	removeFinalizers(ctx, c, log, vtkList)
 	return nil
 }
 
@@ -192,6 +255,26 @@ func removeFinalizers(ctx context.Context, c client.Client, log logr.Logger, obj
 				}
 			}
 		}
+	case *secretsv1beta1.VaultSSHCertificateList:
+		for _, x := range t.Items {
+			cnt++
+			if controllerutil.RemoveFinalizer(&x, vaultSSHCertificateFinalizer) {
+				log.Info(fmt.Sprintf("Updating finalizer for SSHCertificate %s", x.Name))
+				if err := c.Update(ctx, &x, &client.UpdateOptions{}); err != nil {
+					log.Error(err, fmt.Sprintf("Unable to update finalizer for %s: %s", vaultSSHCertificateFinalizer, x.Name))
+				}
+			}
+		}
+	case *secretsv1beta1.VaultTransitKeyList:
+		for _, x := range t.Items {
+			cnt++
+			if controllerutil.RemoveFinalizer(&x, vaultTransitKeyFinalizer) {
+				log.Info(fmt.Sprintf("Updating finalizer for TransitKey %s", x.Name))
+				if err := c.Update(ctx, &x, &client.UpdateOptions{}); err != nil {
+					log.Error(err, fmt.Sprintf("Unable to update finalizer for %s: %s", vaultTransitKeyFinalizer, x.Name))
+				}
+			}
+		}
 	case *secretsv1beta1.VaultConnectionList:
 		for _, x := range t.Items {
 			cnt++
@@ -216,6 +299,70 @@ func removeFinalizers(ctx context.Context, c client.Client, log logr.Logger, obj
 	log.Info(fmt.Sprintf("Removed %d finalizers", cnt))
 }
 
+// CleanupDestinationSecrets applies policy to all destination Secrets that were
+// created by the controller, when the controller is being deleted. The
+// destination Secrets are either retained as is, deleted, or labeled with
+// consts.LabelOrphaned. Errors are logged so that we can do the best effort
+// attempt to clean up *all* destination Secrets, only an error listing them is
+// returned.
+func CleanupDestinationSecrets(ctx context.Context, c client.Client, log logr.Logger, policy string) error {
+	switch policy {
+	case DestinationSecretsPolicyRetain:
+		return nil
+	case DestinationSecretsPolicyDelete, DestinationSecretsPolicyOrphan:
+	default:
+		return fmt.Errorf("unsupported destination secrets policy %q", policy)
+	}
+
+	secrets, err := helpers.FindManagedSecrets(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	cnt := 0
+	for _, s := range secrets {
+		objKey := client.ObjectKeyFromObject(&s)
+		if policy == DestinationSecretsPolicyDelete {
+			if err := c.Delete(ctx, &s); client.IgnoreNotFound(err) != nil {
+				log.Error(err, fmt.Sprintf("Unable to delete destination secret %s", objKey))
+				continue
+			}
+		} else {
+			if s.Labels[consts.LabelOrphaned] == "true" {
+				continue
+			}
+			patch := client.MergeFrom(s.DeepCopy())
+			s.Labels[consts.LabelOrphaned] = "true"
+			if err := c.Patch(ctx, &s, patch); client.IgnoreNotFound(err) != nil {
+				log.Error(err, fmt.Sprintf("Unable to label destination secret %s", objKey))
+				continue
+			}
+		}
+		cnt++
+	}
+	log.Info(fmt.Sprintf("Applied the %s policy to %d destination secrets", policy, cnt))
+
+	return nil
+}
+
+// finalizeDestinationSecrets applies the DeletionPolicy of o's destinations to
+// the Secrets that were created for them. It is a no-op if o's finalizer has
+// already been removed.
+func finalizeDestinationSecrets(ctx context.Context, c client.Client, recorder record.EventRecorder, o client.Object, finalizer string) error {
+	if !controllerutil.ContainsFinalizer(o, finalizer) {
+		return nil
+	}
+
+	if err := helpers.FinalizeDestinationSecrets(ctx, c, o); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to apply the deletion policy of the destination secrets")
+		recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonDeletionPolicyError,
+			"Failed to apply the deletion policy of the destination secrets: %s", err)
+		return err
+	}
+
+	return nil
+}
+
 func parseDurationString(duration, path string, min time.Duration) (time.Duration, error) {
 	var err error
 	var d time.Duration
@@ -235,6 +382,53 @@ func parseDurationString(duration, path string, min time.Duration) (time.Duratio
 	return d, nil
 }
 
+// withWrapTTL returns a context that requests a wrapped Vault response if
+// wrapTTL is set, see vault.WithWrapTTL.
+func withWrapTTL(ctx context.Context, wrapTTL string) (context.Context, error) {
+	if wrapTTL == "" {
+		return ctx, nil
+	}
+
+	d, err := parseDurationString(wrapTTL, ".spec.wrapTTL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return vault.WithWrapTTL(ctx, d), nil
+}
+
+// wrappedSecretData returns the destination Secret data and annotations for a
+// wrapped Vault response. Only the response wrapping token is synced, the
+// annotations describe how to unwrap it.
+func wrappedSecretData(resp vault.Response) (map[string][]byte, map[string]string, error) {
+	var wrapInfo *api.SecretWrapInfo
+	if resp.Secret() != nil {
+		wrapInfo = resp.Secret().WrapInfo
+	}
+	if wrapInfo == nil || wrapInfo.Token == "" {
+		return nil, nil, errors.New("the Vault response is not wrapped")
+	}
+
+	created := wrapInfo.CreationTime
+	if created.IsZero() {
+		created = nowFunc()
+	}
+	expires := created.Add(time.Duration(wrapInfo.TTL) * time.Second)
+
+	data := map[string][]byte{
+		consts.WrappingTokenKey: []byte(wrapInfo.Token),
+	}
+	annotations := map[string]string{
+		consts.AnnotationUnwrap: fmt.Sprintf(
+			"The %s key holds a single-use Vault response wrapping token that expires at %s. "+
+				"Unwrap it with 'vault unwrap', or the sys/wrapping/unwrap API, "+
+				"to get the secret from %s.",
+			consts.WrappingTokenKey, expires.UTC().Format(time.RFC3339), wrapInfo.CreationPath),
+	}
+
+	return data, annotations, nil
+}
+
 func isInWindow(t1, t2 time.Time) bool {
 	return t1.After(t2) || t1.Equal(t2)
 }
@@ -316,3 +510,223 @@ func updateConditions(current []metav1.Condition, updates ...metav1.Condition) [
 	}
 	return ret
 }
+
+// handleTemplateRenderError returns the helpers.TemplateRenderError contained in
+// err, after restoring the keys that failed to render in data, see
+// preserveFailedTemplateKeys. Any other error is returned as is.
+func handleTemplateRenderError(ctx context.Context, c client.Client, o client.Object,
+	data map[string][]byte, err error,
+) (*helpers.TemplateRenderError, error) {
+	var renderErr *helpers.TemplateRenderError
+	if !errors.As(err, &renderErr) {
+		return nil, err
+	}
+
+	if err := preserveFailedTemplateKeys(ctx, c, o, data, renderErr); err != nil {
+		return nil, err
+	}
+
+	return renderErr, nil
+}
+
+// preserveFailedTemplateKeys sets the data of each key in renderErr to its
+// value from the object's current destination Secret. Keys that are not present
+// in the current destination Secret are omitted from data.
+func preserveFailedTemplateKeys(ctx context.Context, c client.Client, o client.Object,
+	data map[string][]byte, renderErr *helpers.TemplateRenderError,
+) error {
+	dest, exists, err := helpers.GetSyncableSecret(ctx, c, o)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		dest = nil
+	}
+
+	retainSecretData(dest, data, renderErr.Keys())
+
+	return nil
+}
+
+// retainSecretData sets the data of each key in keys to its value from the
+// current Secret. Keys that are not present in the current Secret, or if it is
+// nil, are omitted from data.
+func retainSecretData(current *corev1.Secret, data map[string][]byte, keys []string) {
+	for _, k := range keys {
+		if current != nil {
+			if v, ok := current.Data[k]; ok {
+				data[k] = v
+				continue
+			}
+		}
+		delete(data, k)
+	}
+}
+
+// templatesRenderedConditions returns the object's conditions updated with the
+// outcome of rendering its templates. The TemplatesRendered condition is only
+// set when IsolateTemplateErrors is enabled.
+func templatesRenderedConditions(current []metav1.Condition, generation int64,
+	opt *helpers.SecretTransformationOption, renderErr *helpers.TemplateRenderError,
+) []metav1.Condition {
+	var conditions []metav1.Condition
+	for _, cond := range current {
+		if cond.Type != conditionTypeTemplatesRendered {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	if opt == nil || !opt.IsolateTemplateErrors {
+		return conditions
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionTypeTemplatesRendered,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reasonIsolateTemplateErrors,
+		Message:            "All templates rendered successfully",
+	}
+	if renderErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = fmt.Sprintf(
+			"Retained the previous values for keys that failed to render: %s",
+			strings.Join(renderErr.Keys(), ", "))
+	}
+
+	return updateConditions(current, append(conditions, condition)...)
+}
+
+// secretVersionConditions returns the conditions with the SecretVersionCurrent
+// condition updated for the pinned version. The condition is removed if no
+// version is pinned.
+func secretVersionConditions(current []metav1.Condition, generation int64, pinned, currentVersion int, err error) []metav1.Condition {
+	var conditions []metav1.Condition
+	for _, cond := range current {
+		if cond.Type != conditionTypeSecretVersionCurrent {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	if pinned <= 0 {
+		return conditions
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionTypeSecretVersionCurrent,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reasonVersionPinned,
+		Message:            fmt.Sprintf("Pinned version %d is the current version", pinned),
+	}
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Message = fmt.Sprintf("Failed to read the current version: %s", err)
+	case pinned < currentVersion:
+		condition.Status = metav1.ConditionFalse
+		condition.Message = fmt.Sprintf("Pinned version %d is behind the current version %d",
+			pinned, currentVersion)
+	}
+
+	return updateConditions(current, append(conditions, condition)...)
+}
+
+// handleSecretDataError sets the SecretDataTooLarge condition of o when err is
+// a helpers.SecretDataTooLargeError, or the SecretTypeIncompatible condition
+// when err is a helpers.SecretTypeDataError, it should be called with the error
+// returned by helpers.SyncSecret. The status is updated right away, since the
+// failed sync may not update it otherwise. The conditions are removed when err
+// is nil, the status is then updated by the caller along with the outcome of
+// the successful sync.
+func handleSecretDataError(ctx context.Context, c client.Client, o client.Object, err error) {
+	conditions := statusConditions(o)
+	if conditions == nil {
+		return
+	}
+
+	if err == nil {
+		*conditions = removeConditions(*conditions,
+			conditionTypeSecretDataTooLarge, conditionTypeSecretTypeIncompatible)
+		return
+	}
+
+	var sizeErr *helpers.SecretDataTooLargeError
+	var typeErr *helpers.SecretTypeDataError
+	var condition metav1.Condition
+	var staleType string
+	switch {
+	case errors.As(err, &sizeErr):
+		condition = metav1.Condition{
+			Type:    conditionTypeSecretDataTooLarge,
+			Reason:  consts.ReasonSecretDataTooLarge,
+			Message: sizeErr.Error(),
+		}
+		staleType = conditionTypeSecretTypeIncompatible
+	case errors.As(err, &typeErr):
+		condition = metav1.Condition{
+			Type:    conditionTypeSecretTypeIncompatible,
+			Reason:  consts.ReasonSecretTypeIncompatible,
+			Message: typeErr.Error(),
+		}
+		staleType = conditionTypeSecretDataTooLarge
+	default:
+		return
+	}
+
+	// only the condition of the last failed sync is relevant.
+	changed := hasCondition(*conditions, staleType)
+	if changed {
+		*conditions = removeConditions(*conditions, staleType)
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.ObservedGeneration = o.GetGeneration()
+	if replaceCondition(conditions, condition) || changed {
+		if err := c.Status().Update(ctx, o); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to update the status",
+				"conditionType", condition.Type)
+		}
+	}
+}
+
+// eventWatcherConditions returns the conditions with the EventWatcherHealthy
+// condition updated from the event watcher's health. The err is the error
+// returned when starting the event watcher. The condition is removed if
+// instant updates are not enabled.
+func eventWatcherConditions(current []metav1.Condition, generation int64, enabled bool, health eventWatcherHealth, err error) []metav1.Condition {
+	var conditions []metav1.Condition
+	for _, cond := range current {
+		if cond.Type != conditionTypeEventWatcherHealthy {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	if !enabled {
+		return conditions
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionTypeEventWatcherHealthy,
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: generation,
+		Reason:             reasonEventWatcherConnecting,
+		Message:            "Connecting to Vault",
+	}
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonEventWatcherFailing
+		condition.Message = fmt.Sprintf("Failed to watch events: %s", err)
+	case health.Connected:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonEventWatcherConnected
+		condition.Message = "Connected to Vault"
+	case health.Failures >= eventWatcherFailureThreshold:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonEventWatcherFailing
+		condition.Message = fmt.Sprintf("Failed to watch events %d times in a row", health.Failures)
+	}
+
+	return updateConditions(current, append(conditions, condition)...)
+}
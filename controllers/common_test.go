@@ -5,18 +5,25 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
 	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
 )
 
 func Test_dynamicHorizon(t *testing.T) {
@@ -547,3 +554,533 @@ func TestVaultAuthReconciler_updateConditions(t *testing.T) {
 		})
 	}
 }
+
+func Test_preserveFailedTemplateKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	renderErr := &helpers.TemplateRenderError{
+		Errs: map[string]error{
+			"foo": errors.New("foo failed"),
+			"qux": errors.New("qux failed"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		existing *corev1.Secret
+		data     map[string][]byte
+		want     map[string][]byte
+	}{
+		{
+			name: "no-destination",
+			data: map[string][]byte{
+				"bar": []byte("bar"),
+				"foo": []byte("raw"),
+			},
+			want: map[string][]byte{
+				"bar": []byte("bar"),
+			},
+		},
+		{
+			name: "retain-previous",
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dest",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"bar": []byte("old-bar"),
+					"foo": []byte("old-foo"),
+				},
+			},
+			data: map[string][]byte{
+				"bar": []byte("bar"),
+				"qux": []byte("raw"),
+			},
+			want: map[string][]byte{
+				"bar": []byte("bar"),
+				"foo": []byte("old-foo"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := testutils.NewFakeClientBuilder()
+			if tt.existing != nil {
+				builder = builder.WithObjects(tt.existing)
+			}
+			o := &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vss",
+					Namespace: "default",
+				},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Destination: secretsv1beta1.Destination{
+						Name: "dest",
+					},
+				},
+			}
+
+			require.NoError(t, preserveFailedTemplateKeys(ctx, builder.Build(), o, tt.data, renderErr))
+			assert.Equal(t, tt.want, tt.data)
+		})
+	}
+}
+
+func Test_templatesRenderedConditions(t *testing.T) {
+	t.Parallel()
+
+	other := metav1.Condition{
+		Type:   "Other",
+		Status: metav1.ConditionTrue,
+		Reason: "Other",
+	}
+	renderErr := &helpers.TemplateRenderError{
+		Errs: map[string]error{
+			"foo": errors.New("foo failed"),
+			"bar": errors.New("bar failed"),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		current     []metav1.Condition
+		opt         *helpers.SecretTransformationOption
+		renderErr   *helpers.TemplateRenderError
+		wantStatus  metav1.ConditionStatus
+		wantMessage string
+	}{
+		{
+			name:    "not-isolated",
+			current: []metav1.Condition{other},
+			opt:     &helpers.SecretTransformationOption{},
+		},
+		{
+			name:        "rendered",
+			current:     []metav1.Condition{other},
+			opt:         &helpers.SecretTransformationOption{IsolateTemplateErrors: true},
+			wantStatus:  metav1.ConditionTrue,
+			wantMessage: "All templates rendered successfully",
+		},
+		{
+			name:        "failed",
+			current:     []metav1.Condition{other},
+			opt:         &helpers.SecretTransformationOption{IsolateTemplateErrors: true},
+			renderErr:   renderErr,
+			wantStatus:  metav1.ConditionFalse,
+			wantMessage: "Retained the previous values for keys that failed to render: bar, foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := templatesRenderedConditions(tt.current, 1, tt.opt, tt.renderErr)
+			if tt.wantStatus == "" {
+				assert.Equal(t, []metav1.Condition{other}, got)
+				return
+			}
+
+			require.Len(t, got, 2)
+			assert.Equal(t, other.Type, got[0].Type)
+			assert.Equal(t, conditionTypeTemplatesRendered, got[1].Type)
+			assert.Equal(t, tt.wantStatus, got[1].Status)
+			assert.Equal(t, tt.wantMessage, got[1].Message)
+			assert.Equal(t, int64(1), got[1].ObservedGeneration)
+		})
+	}
+}
+
+func Test_secretVersionConditions(t *testing.T) {
+	t.Parallel()
+
+	other := metav1.Condition{
+		Type:   "Other",
+		Status: metav1.ConditionTrue,
+		Reason: "Other",
+	}
+
+	tests := []struct {
+		name           string
+		current        []metav1.Condition
+		pinned         int
+		currentVersion int
+		err            error
+		wantStatus     metav1.ConditionStatus
+		wantMessage    string
+	}{
+		{
+			name: "not-pinned",
+			current: []metav1.Condition{
+				other,
+				{
+					Type:   conditionTypeSecretVersionCurrent,
+					Status: metav1.ConditionFalse,
+					Reason: reasonVersionPinned,
+				},
+			},
+			currentVersion: 2,
+		},
+		{
+			name:           "current",
+			current:        []metav1.Condition{other},
+			pinned:         2,
+			currentVersion: 2,
+			wantStatus:     metav1.ConditionTrue,
+			wantMessage:    "Pinned version 2 is the current version",
+		},
+		{
+			name:           "behind",
+			current:        []metav1.Condition{other},
+			pinned:         1,
+			currentVersion: 3,
+			wantStatus:     metav1.ConditionFalse,
+			wantMessage:    "Pinned version 1 is behind the current version 3",
+		},
+		{
+			name:        "error",
+			current:     []metav1.Condition{other},
+			pinned:      1,
+			err:         errors.New("permission denied"),
+			wantStatus:  metav1.ConditionUnknown,
+			wantMessage: "Failed to read the current version: permission denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := secretVersionConditions(tt.current, 1, tt.pinned, tt.currentVersion, tt.err)
+			if tt.wantStatus == "" {
+				assert.Equal(t, []metav1.Condition{other}, got)
+				return
+			}
+
+			require.Len(t, got, 2)
+			assert.Equal(t, other.Type, got[0].Type)
+			assert.Equal(t, conditionTypeSecretVersionCurrent, got[1].Type)
+			assert.Equal(t, reasonVersionPinned, got[1].Reason)
+			assert.Equal(t, tt.wantStatus, got[1].Status)
+			assert.Equal(t, tt.wantMessage, got[1].Message)
+			assert.Equal(t, int64(1), got[1].ObservedGeneration)
+		})
+	}
+}
+
+func Test_eventWatcherConditions(t *testing.T) {
+	t.Parallel()
+
+	other := metav1.Condition{
+		Type:   "Other",
+		Status: metav1.ConditionTrue,
+		Reason: "Other",
+	}
+
+	tests := []struct {
+		name        string
+		current     []metav1.Condition
+		enabled     bool
+		health      eventWatcherHealth
+		err         error
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name: "disabled",
+			current: []metav1.Condition{
+				other,
+				{
+					Type:   conditionTypeEventWatcherHealthy,
+					Status: metav1.ConditionTrue,
+					Reason: reasonEventWatcherConnected,
+				},
+			},
+			health: eventWatcherHealth{Connected: true},
+		},
+		{
+			name:        "connected",
+			current:     []metav1.Condition{other},
+			enabled:     true,
+			health:      eventWatcherHealth{Connected: true, ReconnectCount: 2},
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  reasonEventWatcherConnected,
+			wantMessage: "Connected to Vault",
+		},
+		{
+			name:        "connecting",
+			current:     []metav1.Condition{other},
+			enabled:     true,
+			health:      eventWatcherHealth{Failures: eventWatcherFailureThreshold - 1},
+			wantStatus:  metav1.ConditionUnknown,
+			wantReason:  reasonEventWatcherConnecting,
+			wantMessage: "Connecting to Vault",
+		},
+		{
+			name:        "failing",
+			current:     []metav1.Condition{other},
+			enabled:     true,
+			health:      eventWatcherHealth{Failures: 5},
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  reasonEventWatcherFailing,
+			wantMessage: "Failed to watch events 5 times in a row",
+		},
+		{
+			name:        "error",
+			current:     []metav1.Condition{other},
+			enabled:     true,
+			err:         errors.New("permission denied"),
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  reasonEventWatcherFailing,
+			wantMessage: "Failed to watch events: permission denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := eventWatcherConditions(tt.current, 1, tt.enabled, tt.health, tt.err)
+			if tt.wantStatus == "" {
+				assert.Equal(t, []metav1.Condition{other}, got)
+				return
+			}
+
+			require.Len(t, got, 2)
+			assert.Equal(t, other.Type, got[0].Type)
+			assert.Equal(t, conditionTypeEventWatcherHealthy, got[1].Type)
+			assert.Equal(t, tt.wantReason, got[1].Reason)
+			assert.Equal(t, tt.wantStatus, got[1].Status)
+			assert.Equal(t, tt.wantMessage, got[1].Message)
+			assert.Equal(t, int64(1), got[1].ObservedGeneration)
+		})
+	}
+}
+
+func Test_handleSecretDataError(t *testing.T) {
+	ctx := context.Background()
+
+	o := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "foo",
+			Generation: 2,
+		},
+	}
+	objKey := client.ObjectKeyFromObject(o)
+	c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+
+	// other errors are ignored.
+	handleSecretDataError(ctx, c, o, errors.New("permission denied"))
+	assert.Empty(t, o.Status.Conditions)
+
+	sizeErr := &helpers.SecretDataTooLargeError{
+		Secret: "dest",
+		Size:   2097152,
+		Limit:  1048576,
+	}
+	handleSecretDataError(ctx, c, o, fmt.Errorf("sync failed: %w", sizeErr))
+
+	var got secretsv1beta1.VaultStaticSecret
+	require.NoError(t, c.Get(ctx, objKey, &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeSecretDataTooLarge, got.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, consts.ReasonSecretDataTooLarge, got.Status.Conditions[0].Reason)
+	assert.Equal(t, sizeErr.Error(), got.Status.Conditions[0].Message)
+	assert.Equal(t, int64(2), got.Status.Conditions[0].ObservedGeneration)
+
+	// the condition of the previous failure is replaced.
+	typeErr := &helpers.SecretTypeDataError{
+		Secret: "dest",
+		Type:   corev1.SecretTypeDockerConfigJson,
+		Err:    errors.New(`missing key ".dockerconfigjson"`),
+	}
+	handleSecretDataError(ctx, c, o, fmt.Errorf("sync failed: %w", typeErr))
+
+	require.NoError(t, c.Get(ctx, objKey, &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeSecretTypeIncompatible, got.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, consts.ReasonSecretTypeIncompatible, got.Status.Conditions[0].Reason)
+	assert.Equal(t, typeErr.Error(), got.Status.Conditions[0].Message)
+
+	// the conditions are removed by the next successful sync.
+	handleSecretDataError(ctx, c, o, nil)
+	assert.Empty(t, o.Status.Conditions)
+}
+
+func TestCleanupDestinationSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	owner := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "vss",
+			UID:       "5e9a6e4f-0b8c-4b57-a3d1-2c4c7a5d1f3e",
+		},
+	}
+	ownerLabels, err := helpers.OwnerLabelsForObj(owner)
+	require.NoError(t, err)
+
+	newSecrets := func() []client.Object {
+		return []client.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "managed",
+					Labels:    ownerLabels,
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "other",
+					Name:      "managed",
+					Labels:    ownerLabels,
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "unmanaged",
+					Labels: map[string]string{
+						"app.kubernetes.io/name": "other",
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      string
+		wantSecrets []string
+		wantLabeled []string
+		wantErr     string
+	}{
+		{
+			name:        "retain",
+			policy:      DestinationSecretsPolicyRetain,
+			wantSecrets: []string{"default/managed", "default/unmanaged", "other/managed"},
+		},
+		{
+			name:        "delete",
+			policy:      DestinationSecretsPolicyDelete,
+			wantSecrets: []string{"default/unmanaged"},
+		},
+		{
+			name:        "orphan",
+			policy:      DestinationSecretsPolicyOrphan,
+			wantSecrets: []string{"default/managed", "default/unmanaged", "other/managed"},
+			wantLabeled: []string{"default/managed", "other/managed"},
+		},
+		{
+			name:    "invalid",
+			policy:  "purge",
+			wantErr: `unsupported destination secrets policy "purge"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := testutils.NewFakeClientBuilder().WithObjects(newSecrets()...).Build()
+			err := CleanupDestinationSecrets(ctx, c, logr.Discard(), tt.policy)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var secrets corev1.SecretList
+			require.NoError(t, c.List(ctx, &secrets))
+			var gotSecrets, gotLabeled []string
+			for _, s := range secrets.Items {
+				objKey := client.ObjectKeyFromObject(&s).String()
+				gotSecrets = append(gotSecrets, objKey)
+				if s.Labels[consts.LabelOrphaned] == "true" {
+					gotLabeled = append(gotLabeled, objKey)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantSecrets, gotSecrets)
+			assert.ElementsMatch(t, tt.wantLabeled, gotLabeled)
+		})
+	}
+}
+
+func Test_wrappedSecretData(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		secret          *api.Secret
+		wantData        map[string][]byte
+		wantAnnotations map[string]string
+		wantErr         string
+	}{
+		{
+			name: "wrapped",
+			secret: &api.Secret{
+				WrapInfo: &api.SecretWrapInfo{
+					Token:        "wrapping-token",
+					TTL:          300,
+					CreationTime: created,
+					CreationPath: "kv/data/app",
+				},
+			},
+			wantData: map[string][]byte{
+				consts.WrappingTokenKey: []byte("wrapping-token"),
+			},
+			wantAnnotations: map[string]string{
+				consts.AnnotationUnwrap: "The token key holds a single-use Vault response wrapping token " +
+					"that expires at 2024-01-01T00:05:00Z. Unwrap it with 'vault unwrap', " +
+					"or the sys/wrapping/unwrap API, to get the secret from kv/data/app.",
+			},
+		},
+		{
+			name: "not-wrapped",
+			secret: &api.Secret{
+				Data: map[string]any{
+					"foo": "bar",
+				},
+			},
+			wantErr: "the Vault response is not wrapped",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, annotations, err := wrappedSecretData(vault.NewDefaultResponse(tt.secret))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, data)
+			assert.Equal(t, tt.wantAnnotations, annotations)
+		})
+	}
+}
+
+func Test_withWrapTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	got, err := withWrapTTL(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, ctx, got)
+
+	got, err = withWrapTTL(ctx, "5m")
+	require.NoError(t, err)
+	assert.NotEqual(t, ctx, got)
+
+	_, err = withWrapTTL(ctx, "500ms")
+	assert.ErrorContains(t, err, "below the minimum allowed value")
+}
@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+var (
+	_ manager.Runnable               = (*VaultConnectionHealthChecker)(nil)
+	_ manager.LeaderElectionRunnable = (*VaultConnectionHealthChecker)(nil)
+)
+
+const (
+	// VaultConnectionHealthCheckHealthz adds the Vault connectivity check to
+	// the manager's /healthz endpoint.
+	VaultConnectionHealthCheckHealthz = "healthz"
+	// VaultConnectionHealthCheckReadyz adds the Vault connectivity check to the
+	// manager's /readyz endpoint.
+	VaultConnectionHealthCheckReadyz = "readyz"
+)
+
+// VaultConnectionHealthChecks are all the supported health endpoints for the
+// Vault connectivity check.
+var VaultConnectionHealthChecks = []string{
+	VaultConnectionHealthCheckHealthz,
+	VaultConnectionHealthCheckReadyz,
+}
+
+// ValidateVaultConnectionHealthChecks returns an error if any of checks is not
+// one of VaultConnectionHealthChecks.
+func ValidateVaultConnectionHealthChecks(checks []string) error {
+	var errs error
+	for _, check := range checks {
+		if !slices.Contains(VaultConnectionHealthChecks, check) {
+			errs = errors.Join(errs, fmt.Errorf(
+				"unsupported Vault connection health check %q, must be one of %v",
+				check, VaultConnectionHealthChecks))
+		}
+	}
+
+	return errs
+}
+
+// vaultConnectionProbeFunc returns an error if the Vault server of the
+// VaultConnection is either unreachable or sealed.
+type vaultConnectionProbeFunc func(context.Context, client.Client, *secretsv1beta1.VaultConnection) error
+
+// VaultConnectionHealthChecker periodically checks that the Vault server of
+// every VaultConnection is reachable and unsealed. The outcome of each check is
+// exposed by a per-connection health gauge, and cached until the next check, so
+// that Check can be added to the manager's health endpoints without probing
+// Vault on every request.
+type VaultConnectionHealthChecker struct {
+	Client client.Client
+	// Interval between checks, it is also the TTL of the cached outcome.
+	Interval time.Duration
+	// Timeout of the probe of a single VaultConnection, it defaults to the
+	// Interval.
+	Timeout time.Duration
+	// probe is used by tests to fake the Vault server.
+	probe vaultConnectionProbeFunc
+
+	mu      sync.RWMutex
+	results map[client.ObjectKey]error
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every operator
+// instance must report its own connectivity to Vault.
+func (h *VaultConnectionHealthChecker) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (h *VaultConnectionHealthChecker) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("vaultConnectionHealthChecker")
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+	for {
+		if err := h.check(ctx); err != nil {
+			logger.Error(err, "Vault connection health check failed")
+		} else {
+			logger.V(consts.LogLevelDebug).Info("Vault connection health check succeeded")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check returns the joined errors of all the VaultConnections that were either
+// unreachable or sealed during the last check, it implements healthz.Checker.
+// It never fails before the first check has completed, so that the operator's
+// startup is not held up by Vault.
+func (h *VaultConnectionHealthChecker) Check(_ *http.Request) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var errs error
+	for objKey, err := range h.results {
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("VaultConnection %s: %w", objKey, err))
+		}
+	}
+
+	return errs
+}
+
+// check probes every VaultConnection concurrently, caches the outcomes, and
+// updates their health gauges.
+func (h *VaultConnectionHealthChecker) check(ctx context.Context) error {
+	var list secretsv1beta1.VaultConnectionList
+	if err := h.Client.List(ctx, &list); err != nil {
+		return err
+	}
+
+	probe := h.probe
+	if probe == nil {
+		probe = probeVaultConnection
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = h.Interval
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[client.ObjectKey]error, len(list.Items))
+	for i := range list.Items {
+		o := &list.Items[i]
+		if o.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			err := probe(probeCtx, h.Client, o)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[client.ObjectKeyFromObject(o)] = err
+		}()
+	}
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for objKey := range h.results {
+		if _, ok := results[objKey]; !ok {
+			metrics.DeleteVaultConnectionHealthy(objKey)
+		}
+	}
+
+	var errs error
+	for objKey, err := range results {
+		metrics.SetVaultConnectionHealthy(objKey, err == nil)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("VaultConnection %s: %w", objKey, err))
+		}
+	}
+	h.results = results
+
+	return errs
+}
+
+// probeVaultConnection checks the health of the VaultConnection's Vault server
+// with sys/health.
+func probeVaultConnection(ctx context.Context, c client.Client, o *secretsv1beta1.VaultConnection) error {
+	cfg, err := vault.NewClientConfigFromConnObj(o, "")
+	if err != nil {
+		return err
+	}
+
+	vaultClient, err := vault.MakeVaultClient(ctx, cfg, c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := vaultClient.Sys().HealthWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !resp.Initialized:
+		return errors.New("vault is not initialized")
+	case resp.Sealed:
+		return errors.New("vault is sealed")
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestVaultConnectionHealthChecker_check(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	healthy := &secretsv1beta1.VaultConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "connection-health"},
+	}
+	sealed := &secretsv1beta1.VaultConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "sealed", Namespace: "connection-health"},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(healthy, sealed).Build()
+
+	h := &VaultConnectionHealthChecker{
+		Client:   c,
+		Interval: time.Minute,
+		probe: func(_ context.Context, _ client.Client, o *secretsv1beta1.VaultConnection) error {
+			if o.Name == "sealed" {
+				return errors.New("vault is sealed")
+			}
+			return nil
+		},
+	}
+
+	// the check never fails before the first check.
+	assert.NoError(t, h.Check(nil))
+
+	err := h.check(ctx)
+	assert.EqualError(t, err, "VaultConnection connection-health/sealed: vault is sealed")
+	assert.EqualError(t, h.Check(nil), "VaultConnection connection-health/sealed: vault is sealed")
+	assert.Equal(t, float64(1), metricValue(t,
+		metrics.VaultConnectionHealthy.WithLabelValues("healthy", "connection-health")))
+	assert.Equal(t, float64(0), metricValue(t,
+		metrics.VaultConnectionHealthy.WithLabelValues("sealed", "connection-health")))
+
+	// the gauges of deleted VaultConnections are removed.
+	require.NoError(t, c.Delete(ctx, sealed))
+	assert.NoError(t, h.check(ctx))
+	assert.NoError(t, h.Check(nil))
+	assert.False(t, metrics.VaultConnectionHealthy.DeleteLabelValues("sealed", "connection-health"))
+	assert.True(t, metrics.VaultConnectionHealthy.DeleteLabelValues("healthy", "connection-health"))
+}
+
+func Test_probeVaultConnection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "healthy",
+			body:    `{"initialized": true, "sealed": false}`,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "sealed",
+			body: `{"initialized": true, "sealed": true}`,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "vault is sealed", i...)
+			},
+		},
+		{
+			name: "uninitialized",
+			body: `{"initialized": false, "sealed": true}`,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "vault is not initialized", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/v1/sys/health" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			o := &secretsv1beta1.VaultConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+				Spec: secretsv1beta1.VaultConnectionSpec{
+					Address: srv.URL,
+				},
+			}
+			err := probeVaultConnection(context.Background(), testutils.NewFakeClientBuilder().Build(), o)
+			tt.wantErr(t, err)
+		})
+	}
+
+	// an unreachable Vault server.
+	o := &secretsv1beta1.VaultConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+		Spec: secretsv1beta1.VaultConnectionSpec{
+			Address: "http://127.0.0.1:1",
+		},
+	}
+	assert.Error(t, probeVaultConnection(context.Background(), testutils.NewFakeClientBuilder().Build(), o))
+}
+
+func TestValidateVaultConnectionHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidateVaultConnectionHealthChecks(nil))
+	assert.NoError(t, ValidateVaultConnectionHealthChecks([]string{"healthz", "readyz"}))
+	assert.Error(t, ValidateVaultConnectionHealthChecks([]string{"readyz", "livez"}))
+}
@@ -0,0 +1,404 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// eventSubscriber is a resource that is notified of the Vault events that
+// match it.
+type eventSubscriber struct {
+	// obj is the subscribed resource.
+	obj client.Object
+	// match returns true if the event for the Vault namespace, event type and
+	// path concerns the subscribed resource.
+	match func(namespace, eventType, path string) bool
+	// debounce is the period of time that the resource's sync is delayed after
+	// an event, all events received in the meantime are coalesced.
+	debounce time.Duration
+}
+
+// eventSubscription multiplexes the events of a single websocket subscription
+// to Vault to all of its subscribers. All subscribers share the same Vault
+// client and namespace.
+type eventSubscription struct {
+	// key of the subscription, see eventSubscriptionKey.
+	key string
+	// clientID of the Vault client the subscription was created with.
+	clientID string
+	// clientObj is the object that the Vault client is reloaded for, either a
+	// VaultEventSubscription or one of the subscribers.
+	clientObj client.Object
+	// eventPath is the websocket path that subscribes to the Vault events.
+	eventPath string
+	// filter is the expression that Vault uses to filter the events, see
+	// eventSubscribePath.
+	filter string
+	// wsClient used to connect to Vault, it is replaced when the Vault client is
+	// reloaded.
+	wsClient *vault.WebsocketClient
+	// cancel closes the subscription's context, and stops its goroutine.
+	cancel      func()
+	mu          sync.RWMutex
+	subscribers map[types.NamespacedName]*eventSubscriber
+	connected   bool
+	// connects is the number of times the subscription connected to Vault.
+	connects int
+	// failures is the number of consecutive failed attempts to watch events
+	// since the subscription was last connected.
+	failures int
+	// lastEventTimes holds the time of the last event that matched each
+	// subscriber.
+	lastEventTimes map[types.NamespacedName]time.Time
+	// pending holds the subscribers with a debounced sync pending.
+	pending map[types.NamespacedName]bool
+}
+
+// eventWatcherHealth reports the health of a subscriber's event watcher.
+type eventWatcherHealth struct {
+	Connected      bool
+	LastEventTime  time.Time
+	ReconnectCount int
+	Failures       int
+}
+
+// eventSubscriptionKey returns the key of the subscription that is shared by
+// all resources that have the same Vault client, Vault namespace, and event
+// types. The eventTypes must be sorted.
+func eventSubscriptionKey(clientID, namespace string, eventTypes []string) string {
+	return clientID + "/" + namespace + "/" + strings.Join(eventTypes, ",")
+}
+
+// eventSubscribePath returns the websocket path that subscribes to the Vault
+// events of eventTypes, along with the expression that Vault uses to filter
+// them. Vault subscribes to a single event type pattern per connection, so
+// more than one event type is subscribed with a wildcard, and the events are
+// filtered by Vault.
+func eventSubscribePath(eventTypes []string) (string, string) {
+	if len(eventTypes) == 1 {
+		return eventSubscribePathPrefix + eventTypes[0], ""
+	}
+
+	exprs := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		exprs = append(exprs, fmt.Sprintf("event_type matches %q", eventGlobPattern(eventType)))
+	}
+	return eventSubscribePathPrefix + "*", strings.Join(exprs, " or ")
+}
+
+// sortedEventTypes returns a sorted copy of eventTypes without duplicates.
+func sortedEventTypes(eventTypes []string) []string {
+	result := make([]string, 0, len(eventTypes))
+	seen := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !seen[eventType] {
+			seen[eventType] = true
+			result = append(result, eventType)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// eventGlobPattern returns the regular expression of the glob, a "*" matches
+// any sequence of characters.
+func eventGlobPattern(glob string) string {
+	return "^" + strings.ReplaceAll(regexp.QuoteMeta(glob), `\*`, ".*") + "$"
+}
+
+// compileEventGlobs returns the regular expressions of the globs.
+func compileEventGlobs(globs []string) []*regexp.Regexp {
+	result := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		result = append(result, regexp.MustCompile(eventGlobPattern(glob)))
+	}
+	return result
+}
+
+// matchAny returns true if s matches any of the regular expressions.
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// add the subscriber, replacing any existing subscriber for key. Returns true
+// if the subscription is connected to Vault.
+func (s *eventSubscription) add(key types.NamespacedName, sub *eventSubscriber) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[key] = sub
+	return s.connected
+}
+
+// remove the subscriber for key, returning the number of remaining subscribers.
+func (s *eventSubscription) remove(key types.NamespacedName) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, key)
+	delete(s.lastEventTimes, key)
+	return len(s.subscribers)
+}
+
+// setConnected records whether the subscription is connected to Vault, and
+// returns all of its subscribers.
+func (s *eventSubscription) setConnected(connected bool) []*eventSubscriber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = connected
+	if connected {
+		s.connects++
+		s.failures = 0
+	}
+	return s.subscribersLocked()
+}
+
+// reconnectCount returns the number of times the subscription reconnected to
+// Vault after its first connection.
+func (s *eventSubscription) reconnectCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reconnectCountLocked()
+}
+
+func (s *eventSubscription) reconnectCountLocked() int {
+	if s.connects == 0 {
+		return 0
+	}
+	return s.connects - 1
+}
+
+// failed records a failed attempt to watch events, returning the number of
+// consecutive failures.
+func (s *eventSubscription) failed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+	return s.failures
+}
+
+// health returns the event watcher health of the subscriber for key.
+func (s *eventSubscription) health(key types.NamespacedName) (eventWatcherHealth, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.subscribers[key]; !ok {
+		return eventWatcherHealth{}, false
+	}
+	return eventWatcherHealth{
+		Connected:      s.connected,
+		LastEventTime:  s.lastEventTimes[key],
+		ReconnectCount: s.reconnectCountLocked(),
+		Failures:       s.failures,
+	}, true
+}
+
+// all returns all subscribers.
+func (s *eventSubscription) all() []*eventSubscriber {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subscribersLocked()
+}
+
+// matching returns the subscribers that match the event for the Vault
+// namespace, event type and path, and records the event's time for each of
+// them.
+func (s *eventSubscription) matching(namespace, eventType, path string) []*eventSubscriber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []*eventSubscriber
+	now := nowFunc()
+	for key, sub := range s.subscribers {
+		if sub.match(namespace, eventType, path) {
+			if s.lastEventTimes == nil {
+				s.lastEventTimes = make(map[types.NamespacedName]time.Time)
+			}
+			s.lastEventTimes[key] = now
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// debounce calls fn once the subscriber for key's debounce window has elapsed.
+// It returns false if a call is already pending, the event is then coalesced
+// into it. The fn is called immediately if the subscriber has no debounce
+// window.
+func (s *eventSubscription) debounce(key types.NamespacedName, sub *eventSubscriber, fn func()) bool {
+	if sub.debounce <= 0 {
+		fn()
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[key] {
+		return false
+	}
+	if s.pending == nil {
+		s.pending = make(map[types.NamespacedName]bool)
+	}
+	s.pending[key] = true
+
+	time.AfterFunc(sub.debounce, func() {
+		s.mu.Lock()
+		delete(s.pending, key)
+		s.mu.Unlock()
+		fn()
+	})
+
+	return true
+}
+
+func (s *eventSubscription) subscribersLocked() []*eventSubscriber {
+	result := make([]*eventSubscriber, 0, len(s.subscribers))
+	for _, sub := range s.subscribers {
+		result = append(result, sub)
+	}
+	return result
+}
+
+// eventSubscriptionRegistry keeps track of the running event subscriptions,
+// keyed by eventSubscriptionKey.
+type eventSubscriptionRegistry struct {
+	mu            sync.Mutex
+	subscriptions map[string]*eventSubscription
+	// failed holds the subscriptions that stopped after failing to watch
+	// events, their health is carried over to the next subscription for the
+	// same key.
+	failed map[string]*eventSubscription
+}
+
+func newEventSubscriptionRegistry() *eventSubscriptionRegistry {
+	return &eventSubscriptionRegistry{
+		subscriptions: make(map[string]*eventSubscription),
+		failed:        make(map[string]*eventSubscription),
+	}
+}
+
+// Subscribe adds the subscriber for objKey to the subscription for key. If no
+// subscription is running for key, one is created with newSubscription, and
+// started with start. Returns true if the subscription is already connected
+// to Vault.
+func (r *eventSubscriptionRegistry) Subscribe(key string, objKey types.NamespacedName, sub *eventSubscriber,
+	newSubscription func() *eventSubscription, start func(*eventSubscription),
+) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.subscriptions[key]; ok {
+		return s.add(objKey, sub)
+	}
+
+	s := newSubscription()
+	s.key = key
+	s.subscribers = map[types.NamespacedName]*eventSubscriber{
+		objKey: sub,
+	}
+	if prev, ok := r.failed[key]; ok {
+		prev.mu.RLock()
+		s.connects = prev.connects
+		s.failures = prev.failures
+		s.lastEventTimes = prev.lastEventTimes
+		prev.mu.RUnlock()
+		delete(r.failed, key)
+	}
+	r.subscriptions[key] = s
+	start(s)
+
+	return false
+}
+
+// Subscribed returns true if objKey is subscribed to the running subscription
+// for key.
+func (r *eventSubscriptionRegistry) Subscribed(key string, objKey types.NamespacedName) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.subscriptions[key]
+	if !ok {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok = s.subscribers[objKey]
+	return ok
+}
+
+// Unsubscribe removes the subscriber for objKey from the subscription for key.
+// The subscription is stopped once it has no subscribers left.
+func (r *eventSubscriptionRegistry) Unsubscribe(key string, objKey types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.subscriptions[key]
+	if !ok {
+		if prev, ok := r.failed[key]; ok && prev.remove(objKey) == 0 {
+			delete(r.failed, key)
+		}
+		return
+	}
+	if s.remove(objKey) == 0 {
+		delete(r.subscriptions, key)
+		if s.cancel != nil {
+			s.cancel()
+		}
+	}
+}
+
+// Remove the subscription from the registry, it is called by the subscription's
+// goroutine once it stops.
+func (r *eventSubscriptionRegistry) Remove(s *eventSubscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.subscriptions[s.key] == s {
+		delete(r.subscriptions, s.key)
+		s.mu.RLock()
+		failed := s.failures > 0
+		s.mu.RUnlock()
+		if failed {
+			r.failed[s.key] = s
+		}
+	}
+}
+
+// Health returns the event watcher health of the subscriber for objKey. The
+// health of a subscription that stopped after failing is reported until the
+// next subscription for key is started.
+func (r *eventSubscriptionRegistry) Health(key string, objKey types.NamespacedName) (eventWatcherHealth, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.subscriptions[key]; ok {
+		return s.health(objKey)
+	}
+	if s, ok := r.failed[key]; ok {
+		health, ok := s.health(objKey)
+		health.Connected = false
+		return health, ok
+	}
+	return eventWatcherHealth{}, false
+}
+
+// Len returns the number of running subscriptions.
+func (r *eventSubscriptionRegistry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.subscriptions)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+func TestEventSubscriptionRegistry(t *testing.T) {
+	registry := newEventSubscriptionRegistry()
+
+	newSubscriber := func(name, path string) (types.NamespacedName, *eventSubscriber) {
+		o := &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+		}
+		return types.NamespacedName{Namespace: "default", Name: name}, &eventSubscriber{
+			obj: o,
+			match: func(namespace, _, p string) bool {
+				return namespace == "ns1" && p == path
+			},
+		}
+	}
+
+	var started []*eventSubscription
+	var canceled int
+	newSubscription := func() *eventSubscription {
+		return &eventSubscription{
+			clientID: "client-id",
+			cancel: func() {
+				canceled++
+			},
+		}
+	}
+	start := func(s *eventSubscription) {
+		started = append(started, s)
+	}
+
+	key := eventSubscriptionKey("client-id", "ns1", []string{defaultEventType})
+	fooKey, foo := newSubscriber("foo", "kv/data/foo")
+	barKey, bar := newSubscriber("bar", "kv/data/bar")
+	bazKey, baz := newSubscriber("baz", "kv/data/foo")
+
+	// the first subscriber starts the subscription
+	assert.False(t, registry.Subscribe(key, fooKey, foo, newSubscription, start))
+	require.Len(t, started, 1)
+	s := started[0]
+	assert.Equal(t, key, s.key)
+	assert.Equal(t, 1, registry.Len())
+
+	// the other subscribers share the subscription
+	s.setConnected(true)
+	assert.True(t, registry.Subscribe(key, barKey, bar, newSubscription, start))
+	assert.True(t, registry.Subscribe(key, bazKey, baz, newSubscription, start))
+	assert.Len(t, started, 1)
+	assert.Equal(t, 1, registry.Len())
+	assert.Len(t, s.all(), 3)
+	assert.True(t, registry.Subscribed(key, barKey))
+	assert.False(t, registry.Subscribed(eventSubscriptionKey("other-id", "ns1", []string{defaultEventType}), barKey))
+
+	// events are dispatched to the matching subscribers
+	assert.ElementsMatch(t, []*eventSubscriber{foo, baz}, s.matching("ns1", "kv-v2/data-write", "kv/data/foo"))
+	assert.ElementsMatch(t, []*eventSubscriber{bar}, s.matching("ns1", "kv-v2/data-write", "kv/data/bar"))
+	assert.Empty(t, s.matching("ns2", "kv-v2/data-write", "kv/data/foo"))
+
+	// the health of each subscriber is tracked
+	health, ok := registry.Health(key, fooKey)
+	require.True(t, ok)
+	assert.True(t, health.Connected)
+	assert.False(t, health.LastEventTime.IsZero())
+	assert.Equal(t, 0, health.ReconnectCount)
+	_, ok = registry.Health(key, types.NamespacedName{Namespace: "default", Name: "qux"})
+	assert.False(t, ok)
+
+	// the subscription is stopped once it has no subscribers left
+	registry.Unsubscribe(key, fooKey)
+	registry.Unsubscribe(key, barKey)
+	assert.Equal(t, 0, canceled)
+	assert.False(t, registry.Subscribed(key, barKey))
+	registry.Unsubscribe(key, bazKey)
+	assert.Equal(t, 1, canceled)
+	assert.Equal(t, 0, registry.Len())
+
+	// a stopped subscription is removed, and a new one is started on the next
+	// subscribe
+	assert.False(t, registry.Subscribe(key, fooKey, foo, newSubscription, start))
+	require.Len(t, started, 2)
+	registry.Remove(s)
+	assert.Equal(t, 1, registry.Len())
+	registry.Remove(started[1])
+	assert.Equal(t, 0, registry.Len())
+	assert.False(t, registry.Subscribed(key, fooKey))
+}
+
+func TestEventSubscriptionRegistry_Health(t *testing.T) {
+	registry := newEventSubscriptionRegistry()
+
+	objKey := types.NamespacedName{Namespace: "default", Name: "foo"}
+	sub := &eventSubscriber{
+		obj: &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: objKey.Namespace,
+				Name:      objKey.Name,
+			},
+		},
+		match: func(_, _, _ string) bool {
+			return true
+		},
+	}
+
+	var started []*eventSubscription
+	newSubscription := func() *eventSubscription {
+		return &eventSubscription{
+			clientID: "client-id",
+		}
+	}
+	start := func(s *eventSubscription) {
+		started = append(started, s)
+	}
+
+	key := eventSubscriptionKey("client-id", "ns1", []string{defaultEventType})
+	registry.Subscribe(key, objKey, sub, newSubscription, start)
+	require.Len(t, started, 1)
+	s := started[0]
+
+	health, ok := registry.Health(key, objKey)
+	require.True(t, ok)
+	assert.Equal(t, eventWatcherHealth{}, health)
+
+	// reconnects are counted after the first connection
+	s.setConnected(true)
+	s.matching("ns1", "kv-v2/data-write", "kv/data/foo")
+	s.setConnected(false)
+	assert.Equal(t, 1, s.failed())
+	s.setConnected(true)
+	health, ok = registry.Health(key, objKey)
+	require.True(t, ok)
+	assert.True(t, health.Connected)
+	assert.Equal(t, 1, health.ReconnectCount)
+	assert.Equal(t, 0, health.Failures)
+	lastEventTime := health.LastEventTime
+	assert.False(t, lastEventTime.IsZero())
+
+	// the health of a failed subscription is reported until it is replaced
+	s.setConnected(false)
+	for i := 0; i < eventWatcherFailureThreshold; i++ {
+		s.failed()
+	}
+	registry.Remove(s)
+	assert.Equal(t, 0, registry.Len())
+	health, ok = registry.Health(key, objKey)
+	require.True(t, ok)
+	assert.Equal(t, eventWatcherHealth{
+		LastEventTime:  lastEventTime,
+		ReconnectCount: 1,
+		Failures:       eventWatcherFailureThreshold,
+	}, health)
+
+	// and is carried over to the next subscription
+	registry.Subscribe(key, objKey, sub, newSubscription, start)
+	require.Len(t, started, 2)
+	health, ok = registry.Health(key, objKey)
+	require.True(t, ok)
+	assert.Equal(t, eventWatcherFailureThreshold, health.Failures)
+	started[1].setConnected(true)
+	health, ok = registry.Health(key, objKey)
+	require.True(t, ok)
+	assert.Equal(t, 2, health.ReconnectCount)
+	assert.Equal(t, 0, health.Failures)
+	assert.Equal(t, lastEventTime, health.LastEventTime)
+}
+
+func Test_eventSubscribePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		wantPath   string
+		wantFilter string
+	}{
+		{
+			name:       "default",
+			eventTypes: []string{defaultEventType},
+			wantPath:   "/v1/sys/events/subscribe/kv*",
+		},
+		{
+			name:       "single",
+			eventTypes: []string{"kv-v2/data-write"},
+			wantPath:   "/v1/sys/events/subscribe/kv-v2/data-write",
+		},
+		{
+			name:       "multiple",
+			eventTypes: []string{"database/rotate", "kv-v2/data-*"},
+			wantPath:   "/v1/sys/events/subscribe/*",
+			wantFilter: `event_type matches "^database/rotate$" or event_type matches "^kv-v2/data-.*$"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path, filter := eventSubscribePath(tt.eventTypes)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, tt.wantFilter, filter)
+		})
+	}
+}
+
+func Test_sortedEventTypes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"database/rotate", "kv*"},
+		sortedEventTypes([]string{"kv*", "database/rotate", "kv*"}))
+}
+
+func TestEventSubscription_debounce(t *testing.T) {
+	t.Parallel()
+
+	key := types.NamespacedName{Namespace: "default", Name: "foo"}
+	s := &eventSubscription{}
+	calls := make(chan struct{}, 10)
+	fn := func() {
+		calls <- struct{}{}
+	}
+
+	// no debounce window
+	assert.True(t, s.debounce(key, &eventSubscriber{}, fn))
+	assert.Len(t, calls, 1)
+	<-calls
+
+	// events are coalesced while a call is pending
+	sub := &eventSubscriber{debounce: 50 * time.Millisecond}
+	assert.True(t, s.debounce(key, sub, fn))
+	assert.False(t, s.debounce(key, sub, fn))
+	assert.False(t, s.debounce(key, sub, fn))
+	assert.Len(t, calls, 0)
+	select {
+	case <-calls:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for the debounced call")
+	}
+	assert.Eventually(t, func() bool {
+		return s.debounce(key, sub, fn)
+	}, 5*time.Second, 10*time.Millisecond)
+}
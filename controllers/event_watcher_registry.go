@@ -4,30 +4,31 @@
 package controllers
 
 import (
-	"context"
-
 	gocache "github.com/patrickmn/go-cache"
 	"k8s.io/apimachinery/pkg/types"
 )
 
-// eventWatcherMeta - metadata for managing an event watcher goroutine
+// eventWatcherMeta - metadata for managing an object's subscription to the
+// events of a shared event watcher
 type eventWatcherMeta struct {
-	// Cancel will close the watcher's context (and stop the watcher goroutine)
-	Cancel context.CancelFunc `json:"-"`
-	// StoppedCh lets the watcher goroutine signal the caller that it has
-	// stopped (and removed itself from the registry)
-	StoppedCh chan struct{} `json:"-"`
+	// SubscriptionKey is the key of the eventSubscription that the object is
+	// subscribed to
+	SubscriptionKey string
 	// LastGeneration is the generation of the VaultStaticSecret resource, used
 	// to detect if the event watcher needs to be recreated
 	LastGeneration int64
 	// LastClientID - vault client ID for the last successful connection, used
 	// to detect if the Vault client has changed since the event watcher started
 	LastClientID string
+	// SubscriptionGeneration is the generation of the referenced
+	// VaultEventSubscription resource, if any, used to detect if the event
+	// watcher needs to be recreated
+	SubscriptionGeneration int64
 }
 
-// eventWatcherRegistry - registry for keeping track of running event watcher
-// goroutines keyed by object name, along with associated metadata for
-// rebuilding and killing the watchers
+// eventWatcherRegistry - registry for keeping track of the objects that are
+// subscribed to event watchers keyed by object name, along with associated
+// metadata for resubscribing and unsubscribing the objects
 type eventWatcherRegistry struct {
 	registry *gocache.Cache
 }
@@ -57,3 +58,8 @@ func (r *eventWatcherRegistry) Get(key types.NamespacedName) (*eventWatcherMeta,
 func (r *eventWatcherRegistry) Delete(key types.NamespacedName) {
 	r.registry.Delete(key.String())
 }
+
+// Len - return the number of objects with registered event watchers
+func (r *eventWatcherRegistry) Len() int {
+	return r.registry.ItemCount()
+}
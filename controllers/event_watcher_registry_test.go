@@ -4,7 +4,6 @@
 package controllers
 
 import (
-	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,15 +17,11 @@ func TestEventWatcherRegistry(t *testing.T) {
 	registry := newEventWatcherRegistry()
 	assert.Equal(t, 0, registry.registry.ItemCount())
 
-	ctx, cancel := context.WithCancel(context.Background())
-	stoppedCh := make(chan struct{}, 1)
-
 	// Create a new event watcher metadata
 	meta := &eventWatcherMeta{
-		LastGeneration: 123,
-		LastClientID:   "client-id",
-		Cancel:         cancel,
-		StoppedCh:      stoppedCh,
+		LastGeneration:  123,
+		LastClientID:    "client-id",
+		SubscriptionKey: "client-id/ns1",
 	}
 
 	// Register the event watcher
@@ -34,9 +29,6 @@ func TestEventWatcherRegistry(t *testing.T) {
 	registry.Register(itemName, meta)
 	assert.Equal(t, 1, registry.registry.ItemCount())
 
-	// close the channel
-	close(stoppedCh)
-
 	// Get the event watcher
 	got, ok := registry.Get(itemName)
 	require.True(t, ok, "expected to get event watcher, got none")
@@ -58,12 +50,7 @@ func TestEventWatcherRegistry(t *testing.T) {
 	assert.Equal(t, int64(456), gotAgain.LastGeneration)
 	assert.Equal(t, "client-id", gotAgain.LastClientID)
 
-	// Cancel context received from the registry, check the original
-	gotAgain.Cancel()
-	assert.Equal(t, ctx.Err(), context.Canceled)
-
-	_, isOpen := <-gotAgain.StoppedCh
-	assert.False(t, isOpen, "expected stoppedCh from registry item to be closed")
+	assert.Equal(t, "client-id/ns1", gotAgain.SubscriptionKey)
 
 	// Delete the event watcher
 	registry.Delete(itemName)
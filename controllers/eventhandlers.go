@@ -5,10 +5,16 @@ package controllers
 
 import (
 	"context"
+	"reflect"
+	"sync"
 	"time"
 
+	certificatesv1alpha1 "k8s.io/api/certificates/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,7 +23,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
 )
 
 var maxRequeueAfter = time.Second * 1
@@ -33,6 +43,19 @@ func NewEnqueueRefRequestsHandlerST(refCache ResourceReferenceCache, syncReg *Sy
 	)
 }
 
+// NewEnqueueRefRequestsHandlerCM returns a handler.EventHandler suitable for
+// triggering the reconciliation of a SecretTransformation based on changes to
+// the ConfigMaps that its source templates refer to.
+func NewEnqueueRefRequestsHandlerCM(refCache ResourceReferenceCache) handler.EventHandler {
+	return &enqueueRefRequestsHandler{
+		kind:     ConfigMap,
+		refCache: refCache,
+		// the references are maintained by the referring SecretTransformation,
+		// which must be reconciled when the ConfigMap is deleted.
+		enqueueOnDelete: true,
+	}
+}
+
 func NewEnqueueRefRequestsHandler(kind ResourceKind, refCache ResourceReferenceCache, syncReg *SyncRegistry, validator ValidatorFunc) handler.EventHandler {
 	return &enqueueRefRequestsHandler{
 		kind:      kind,
@@ -50,6 +73,9 @@ type enqueueRefRequestsHandler struct {
 	syncReg         *SyncRegistry
 	validator       ValidatorFunc
 	maxRequeueAfter time.Duration
+	// enqueueOnDelete enqueues the referrers on deletion of the referenced
+	// object, rather than pruning the references.
+	enqueueOnDelete bool
 }
 
 func (e *enqueueRefRequestsHandler) Create(ctx context.Context,
@@ -68,17 +94,51 @@ func (e *enqueueRefRequestsHandler) Update(ctx context.Context,
 		return
 	}
 
-	if evt.ObjectNew.GetGeneration() != evt.ObjectOld.GetGeneration() {
+	if evt.ObjectNew.GetGeneration() != evt.ObjectOld.GetGeneration() ||
+		refObjectChanged(evt.ObjectOld, evt.ObjectNew) {
 		e.enqueue(ctx, q, evt.ObjectNew)
 	}
 }
 
-func (e *enqueueRefRequestsHandler) Delete(_ context.Context,
-	evt event.DeleteEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request],
+func (e *enqueueRefRequestsHandler) Delete(ctx context.Context,
+	evt event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request],
 ) {
+	if e.enqueueOnDelete {
+		e.enqueue(ctx, q, evt.Object)
+		return
+	}
 	e.refCache.Prune(e.kind, client.ObjectKeyFromObject(evt.Object))
 }
 
+// refObjectChanged returns true if the referenced object has changed in a way
+// that is not reflected by its generation.
+func refObjectChanged(oldObj, newObj client.Object) bool {
+	switch n := newObj.(type) {
+	case *corev1.ConfigMap:
+		// ConfigMaps have no generation.
+		o, ok := oldObj.(*corev1.ConfigMap)
+		return ok && !reflect.DeepEqual(o.Data, n.Data)
+	case *certificatesv1alpha1.ClusterTrustBundle:
+		// ClusterTrustBundles have no generation.
+		o, ok := oldObj.(*certificatesv1alpha1.ClusterTrustBundle)
+		return ok && o.Spec.TrustBundle != n.Spec.TrustBundle
+	case *secretsv1beta1.SecretTransformation:
+		// the source templates that are sourced from ConfigMaps have changed.
+		o, ok := oldObj.(*secretsv1beta1.SecretTransformation)
+		return ok && o.Status.SourceTemplatesDigest != n.Status.SourceTemplatesDigest
+	case *secretsv1beta1.VaultEventSubscription:
+		// the subscribers resubscribe once the subscription becomes valid.
+		o, ok := oldObj.(*secretsv1beta1.VaultEventSubscription)
+		return ok && !reflect.DeepEqual(o.Status.Valid, n.Status.Valid)
+	case *metav1.PartialObjectMetadata:
+		// only the metadata is watched, e.g. for Secrets, so any change to the
+		// object's data is only reflected by its resource version.
+		return oldObj.GetResourceVersion() != n.GetResourceVersion()
+	default:
+		return false
+	}
+}
+
 func (e *enqueueRefRequestsHandler) Generic(_ context.Context,
 	_ event.GenericEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request],
 ) {
@@ -183,10 +243,106 @@ func (e *enqueueOnDeletionRequestHandler) Generic(ctx context.Context,
 ) {
 }
 
+var _ handler.EventHandler = (*enqueueOnDriftRequestHandler)(nil)
+
+// enqueueOnDriftRequestHandler immediately enqueues the owner of a destination
+// Secret whenever the Secret's data has drifted from the data that was last
+// synced to it, or the Secret has been deleted. Only the owners of kind that
+// enforce their Destination are enqueued.
+type enqueueOnDriftRequestHandler struct {
+	client    client.Client
+	validator helpers.HMACValidator
+	recorder  record.EventRecorder
+	kind      ResourceKind
+}
+
+func (e *enqueueOnDriftRequestHandler) Create(_ context.Context,
+	_ event.CreateEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request],
+) {
+}
+
+func (e *enqueueOnDriftRequestHandler) Update(ctx context.Context,
+	evt event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request],
+) {
+	e.enqueue(ctx, evt.ObjectNew, false, q)
+}
+
+func (e *enqueueOnDriftRequestHandler) Delete(ctx context.Context,
+	evt event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request],
+) {
+	helpers.ForgetSyncedSecretVersion(client.ObjectKeyFromObject(evt.Object))
+	e.enqueue(ctx, evt.Object, true, q)
+}
+
+func (e *enqueueOnDriftRequestHandler) Generic(_ context.Context,
+	_ event.GenericEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request],
+) {
+}
+
+func (e *enqueueOnDriftRequestHandler) enqueue(ctx context.Context, secret client.Object,
+	deleted bool, q workqueue.TypedRateLimitingInterface[reconcile.Request],
+) {
+	gvk := secretsv1beta1.GroupVersion.WithKind(e.kind.String())
+	logger := log.FromContext(ctx).WithName("enqueueOnDriftRequestHandler").
+		WithValues("ownerGVK", gvk, "secret", client.ObjectKeyFromObject(secret))
+	for _, ref := range secret.GetOwnerReferences() {
+		if ref.APIVersion != gvk.GroupVersion().String() || ref.Kind != gvk.Kind {
+			continue
+		}
+
+		objKey := client.ObjectKey{Namespace: secret.GetNamespace(), Name: ref.Name}
+		o, err := e.client.Scheme().New(gvk)
+		if err != nil {
+			logger.Error(err, "Failed to create the owner object")
+			return
+		}
+		obj, ok := o.(client.Object)
+		if !ok {
+			return
+		}
+		if err := e.client.Get(ctx, objKey, obj); err != nil {
+			logger.V(consts.LogLevelDebug).Info("Failed to get the owner", "owner", objKey, "err", err)
+			continue
+		}
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		meta, err := common.NewSyncableSecretMetaData(obj)
+		if err != nil || !meta.Destination.Enforce || meta.Destination.Name != secret.GetName() {
+			continue
+		}
+
+		message := "Destination secret was deleted, resyncing"
+		if !deleted {
+			drifted, err := helpers.DetectDestinationSecretDrift(ctx, e.client, e.validator, obj)
+			if err != nil {
+				logger.Error(err, "Failed to detect the destination secret's drift", "owner", objKey)
+				continue
+			}
+			if !drifted {
+				continue
+			}
+			message = "Destination secret data drift detected, resyncing"
+		}
+
+		logger.V(consts.LogLevelDebug).Info("Enqueuing", "owner", objKey, "deleted", deleted)
+		e.recorder.Event(obj, corev1.EventTypeWarning, consts.ReasonSecretDataDrift, message)
+		metrics.IncSecretDriftDetected(metricsController(e.kind), objKey)
+		q.Add(reconcile.Request{NamespacedName: objKey})
+	}
+}
+
 // enqueueDelayingSyncEventHandler enqueues objects with a delay to avoid
 // thundering herd issues. It is meant to be used with GenericEvents only.
 type enqueueDelayingSyncEventHandler struct {
 	enqueueDurationForJitter time.Duration
+	// kind of the reconciler's resource, used for metrics.
+	kind ResourceKind
+	mu   sync.Mutex
+	// pending holds the time at which each delayed request becomes ready, an
+	// event for a pending request is deduplicated by the workqueue.
+	pending map[reconcile.Request]time.Time
 }
 
 func (e *enqueueDelayingSyncEventHandler) Create(_ context.Context, _ event.CreateEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
@@ -214,6 +370,13 @@ func (e *enqueueDelayingSyncEventHandler) Generic(ctx context.Context, evt event
 	}
 
 	_, horizon := computeMaxJitterDuration(e.enqueueDurationForJitter)
+	deduplicated := e.trackPending(req, horizon)
+	metrics.ObserveSourceChannelEvent(metricsController(e.kind), horizon, deduplicated)
+	if deduplicated {
+		logger.V(consts.LogLevelTrace).Info("GenericEvent deduplicated, request already pending",
+			"req", req)
+	}
+
 	logger.V(consts.LogLevelTrace).Info("Enqueuing GenericEvent",
 		"req", req, "horizon", horizon)
 	if horizon > 0 {
@@ -222,3 +385,30 @@ func (e *enqueueDelayingSyncEventHandler) Generic(ctx context.Context, evt event
 		q.Add(req)
 	}
 }
+
+// trackPending records that req becomes ready after horizon, and returns true
+// if req was already pending. Requests that are no longer pending are pruned.
+func (e *enqueueDelayingSyncEventHandler) trackPending(req reconcile.Request, horizon time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := nowFunc()
+	for r, readyAt := range e.pending {
+		if !now.Before(readyAt) {
+			delete(e.pending, r)
+		}
+	}
+
+	if e.pending == nil {
+		e.pending = make(map[reconcile.Request]time.Time)
+	}
+
+	readyAt := now.Add(horizon)
+	cur, deduplicated := e.pending[req]
+	// the workqueue keeps the earliest ready time of a pending request.
+	if !deduplicated || readyAt.Before(cur) {
+		e.pending[req] = readyAt
+	}
+
+	return deduplicated
+}
@@ -5,6 +5,8 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"sync"
 	"testing"
@@ -13,14 +15,20 @@ import (
 	"github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
 )
 
 type testCaseEnqueueRefRequestHandler struct {
@@ -41,6 +49,7 @@ type testCaseEnqueueRefRequestHandler struct {
 	wantInvalidObjects []client.Object
 	wantRefCache       *resourceReferenceCache
 	maxRequeueAfter    time.Duration
+	enqueueOnDelete    bool
 }
 
 type validatorFunc struct {
@@ -306,6 +315,93 @@ func Test_enqueueRefRequestsHandler_Update(t *testing.T) {
 				TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil),
 			},
 		},
+		{
+			name:     "enqueued-source-templates-digest-changed",
+			kind:     SecretTransformation,
+			refCache: cache,
+			updateEvents: []event.UpdateEvent{
+				{
+					ObjectOld: objectOld,
+					ObjectNew: &secretsv1beta1.SecretTransformation{
+						ObjectMeta: objectOld.ObjectMeta,
+						Status: secretsv1beta1.SecretTransformationStatus{
+							SourceTemplatesDigest: "digest",
+						},
+					},
+				},
+			},
+			q: &DelegatingQueue{
+				TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil),
+			},
+			wantAddedAfter: wantAddedAfterValid,
+			wantRefCache:   cache,
+		},
+		{
+			name: "enqueued-configmap-data-changed",
+			kind: ConfigMap,
+			refCache: &resourceReferenceCache{
+				m: refCacheMap{
+					ConfigMap: {
+						{
+							Namespace: "foo",
+							Name:      "baz",
+						}: map[client.ObjectKey]empty{
+							{Namespace: "default", Name: "templates"}: {},
+						},
+					},
+				},
+			},
+			updateEvents: []event.UpdateEvent{
+				{
+					ObjectOld: &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "templates"},
+						Data:       map[string]string{"tmpl": "foo"},
+					},
+					ObjectNew: &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "templates"},
+						Data:       map[string]string{"tmpl": "bar"},
+					},
+				},
+			},
+			q: &DelegatingQueue{
+				TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil),
+			},
+			wantAddedAfter: wantAddedAfterValid,
+		},
+		{
+			name: "no-enqueue-configmap-data-unchanged",
+			kind: ConfigMap,
+			refCache: &resourceReferenceCache{
+				m: refCacheMap{
+					ConfigMap: {
+						{
+							Namespace: "foo",
+							Name:      "baz",
+						}: map[client.ObjectKey]empty{
+							{Namespace: "default", Name: "templates"}: {},
+						},
+					},
+				},
+			},
+			updateEvents: []event.UpdateEvent{
+				{
+					ObjectOld: &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "templates"},
+						Data:       map[string]string{"tmpl": "foo"},
+					},
+					ObjectNew: &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default", Name: "templates",
+							Labels: map[string]string{"foo": "bar"},
+						},
+						Data: map[string]string{"tmpl": "foo"},
+					},
+				},
+			},
+			q: &DelegatingQueue{
+				TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -378,6 +474,53 @@ func Test_enqueueRefRequestsHandler_Delete(t *testing.T) {
 			},
 			wantRefCache: cache,
 		},
+		{
+			name: "enqueued-on-delete",
+			kind: ConfigMap,
+			refCache: &resourceReferenceCache{
+				m: refCacheMap{
+					ConfigMap: {
+						{
+							Namespace: "foo",
+							Name:      "baz",
+						}: map[client.ObjectKey]empty{
+							{Namespace: "default", Name: "templates"}: {},
+						},
+					},
+				},
+			},
+			enqueueOnDelete: true,
+			q: &DelegatingQueue{
+				TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil),
+			},
+			deleteEvents: []event.DeleteEvent{
+				{
+					Object: &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "templates"},
+					},
+				},
+			},
+			wantAddedAfter: []any{
+				reconcile.Request{
+					NamespacedName: client.ObjectKey{
+						Namespace: "foo",
+						Name:      "baz",
+					},
+				},
+			},
+			wantRefCache: &resourceReferenceCache{
+				m: refCacheMap{
+					ConfigMap: {
+						{
+							Namespace: "foo",
+							Name:      "baz",
+						}: map[client.ObjectKey]empty{
+							{Namespace: "default", Name: "templates"}: {},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:     "not-enqueued-cache-update-combined",
 			kind:     SecretTransformation,
@@ -414,6 +557,7 @@ func assertEnqueueRefRequestHandler(t *testing.T, ctx context.Context, tt testCa
 		refCache:        tt.refCache,
 		syncReg:         tt.syncReg,
 		maxRequeueAfter: tt.maxRequeueAfter,
+		enqueueOnDelete: tt.enqueueOnDelete,
 	}
 
 	if len(tt.createEvents) > 0 && len(tt.updateEvents) > 0 {
@@ -842,3 +986,184 @@ func assertEnqueueOnDeletionRequestHandler(t *testing.T, ctx context.Context,
 		}
 	}
 }
+
+func Test_enqueueOnDriftRequestHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := testutils.NewFakeClientBuilder().Build()
+	hmacObjKey := client.ObjectKey{Namespace: "vso", Name: "hmac"}
+	_, err := helpers.CreateHMACKeySecret(ctx, c, hmacObjKey)
+	require.NoError(t, err)
+	validator := helpers.NewHMACValidator(hmacObjKey)
+
+	data := map[string][]byte{"foo": []byte("bar")}
+	message, err := json.Marshal(data)
+	require.NoError(t, err)
+	mac, err := validator.HMAC(ctx, c, message)
+	require.NoError(t, err)
+
+	newOwner := func(name string, enforce bool) *secretsv1beta1.VaultStaticSecret {
+		o := &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+			Spec: secretsv1beta1.VaultStaticSecretSpec{
+				Destination: secretsv1beta1.Destination{
+					Name:    name + "-dest",
+					Create:  true,
+					Enforce: enforce,
+				},
+			},
+			Status: secretsv1beta1.VaultStaticSecretStatus{
+				SecretMAC: base64.StdEncoding.EncodeToString(mac),
+			},
+		}
+		require.NoError(t, c.Create(ctx, o))
+		return o
+	}
+	newSecret := func(owner client.Object, data map[string][]byte) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: owner.GetNamespace(),
+				Name:      owner.GetName() + "-dest",
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: secretsv1beta1.GroupVersion.String(),
+						Kind:       VaultStaticSecret.String(),
+						Name:       owner.GetName(),
+					},
+				},
+			},
+			Data: data,
+		}
+		require.NoError(t, c.Create(ctx, s))
+		return s
+	}
+
+	drifted := newSecret(newOwner("drifted", true), map[string][]byte{"foo": []byte("qux")})
+	matched := newSecret(newOwner("matched", true), data)
+	notEnforced := newSecret(newOwner("not-enforced", false), map[string][]byte{"foo": []byte("qux")})
+
+	tests := []struct {
+		name   string
+		secret *corev1.Secret
+		delete bool
+		want   []reconcile.Request
+	}{
+		{
+			name:   "drifted",
+			secret: drifted,
+			want: []reconcile.Request{
+				{NamespacedName: client.ObjectKey{Namespace: "default", Name: "drifted"}},
+			},
+		},
+		{
+			name:   "matched",
+			secret: matched,
+		},
+		{
+			name:   "matched-deleted",
+			secret: matched,
+			delete: true,
+			want: []reconcile.Request{
+				{NamespacedName: client.ObjectKey{Namespace: "default", Name: "matched"}},
+			},
+		},
+		{
+			name:   "not-enforced",
+			secret: notEnforced,
+		},
+		{
+			name:   "not-enforced-deleted",
+			secret: notEnforced,
+			delete: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := record.NewFakeRecorder(10)
+			h := &enqueueOnDriftRequestHandler{
+				client:    c,
+				validator: validator,
+				recorder:  recorder,
+				kind:      VaultStaticSecret,
+			}
+			q := workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil)
+			t.Cleanup(q.ShutDown)
+			if tt.delete {
+				h.Delete(ctx, event.DeleteEvent{Object: tt.secret}, q)
+			} else {
+				h.Update(ctx, event.UpdateEvent{ObjectOld: tt.secret, ObjectNew: tt.secret}, q)
+			}
+
+			var got []reconcile.Request
+			for q.Len() > 0 {
+				req, _ := q.Get()
+				got = append(got, req)
+				q.Done(req)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Len(t, recorder.Events, len(tt.want))
+		})
+	}
+}
+
+func Test_enqueueDelayingSyncEventHandler_Generic(t *testing.T) {
+	t.Parallel()
+
+	// SecretTransformation has no SourceCh, so its metrics are not updated
+	// elsewhere.
+	controller := metricsController(SecretTransformation)
+	received := func() float64 {
+		return metricValue(t, metrics.SourceChannelEventsReceived.WithLabelValues(controller))
+	}
+	deduplicated := func() float64 {
+		return metricValue(t, metrics.SourceChannelEventsDeduplicated.WithLabelValues(controller))
+	}
+
+	ctx := context.Background()
+	h := &enqueueDelayingSyncEventHandler{
+		enqueueDurationForJitter: time.Minute,
+		kind:                     SecretTransformation,
+	}
+	q := &DelegatingQueue{
+		TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue[reconcile.Request](nil),
+	}
+	t.Cleanup(q.ShutDown)
+
+	wantReceived, wantDeduplicated := received(), deduplicated()
+	h.Generic(ctx, newTestSourceEvent("foo"), q)
+	h.Generic(ctx, newTestSourceEvent("foo"), q)
+	h.Generic(ctx, newTestSourceEvent("bar"), q)
+	// events without an object are ignored.
+	h.Generic(ctx, event.GenericEvent{}, q)
+
+	assert.Equal(t, wantReceived+3, received())
+	assert.Equal(t, wantDeduplicated+1, deduplicated())
+	assert.Len(t, q.AddedAfter, 3)
+	assert.Len(t, h.pending, 2)
+}
+
+func Test_enqueueDelayingSyncEventHandler_trackPending(t *testing.T) {
+	t.Parallel()
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "foo"}}
+	other := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "bar"}}
+
+	h := &enqueueDelayingSyncEventHandler{}
+	assert.False(t, h.trackPending(req, time.Minute))
+	assert.True(t, h.trackPending(req, time.Hour))
+	// the earliest ready time is kept.
+	assert.WithinDuration(t, time.Now().Add(time.Minute), h.pending[req], time.Second)
+
+	// an expired request is pruned, and is no longer deduplicated.
+	h.pending[req] = time.Now().Add(-time.Second)
+	assert.False(t, h.trackPending(other, 0))
+	assert.NotContains(t, h.pending, req)
+	assert.False(t, h.trackPending(req, time.Minute))
+}
@@ -0,0 +1,309 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+const (
+	externalSecretsGroup   = "external-secrets.io"
+	externalSecretsVersion = "v1beta1"
+
+	kindSecretStore        = "SecretStore"
+	kindClusterSecretStore = "ClusterSecretStore"
+
+	externalSecretCreationPolicyOwner = "Owner"
+	externalSecretCreationPolicyMerge = "Merge"
+
+	// externalSecretDefaultRefreshInterval is the default refresh interval of an
+	// ExternalSecret, it matches the one of the external-secrets operator.
+	externalSecretDefaultRefreshInterval = time.Hour
+
+	// external-secrets.io ExternalSecret status condition and reasons.
+	externalSecretConditionReady                 = "Ready"
+	externalSecretReasonSecretSynced             = "SecretSynced"
+	externalSecretReasonSecretSyncedError        = "SecretSyncedError"
+	externalSecretReasonUnsupportedConfiguration = "UnsupportedConfiguration"
+)
+
+var (
+	externalSecretGVK = schema.GroupVersionKind{
+		Group:   externalSecretsGroup,
+		Version: externalSecretsVersion,
+		Kind:    "ExternalSecret",
+	}
+	secretStoreGVK = schema.GroupVersionKind{
+		Group:   externalSecretsGroup,
+		Version: externalSecretsVersion,
+		Kind:    kindSecretStore,
+	}
+	clusterSecretStoreGVK = schema.GroupVersionKind{
+		Group:   externalSecretsGroup,
+		Version: externalSecretsVersion,
+		Kind:    kindClusterSecretStore,
+	}
+)
+
+// ExternalSecretCRDInstalled returns true if the external-secrets.io
+// ExternalSecret CRD is installed in the cluster.
+func ExternalSecretCRDInstalled(mapper meta.RESTMapper) (bool, error) {
+	if _, err := mapper.RESTMapping(externalSecretGVK.GroupKind(), externalSecretGVK.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// externalSecret is the subset of the external-secrets.io ExternalSecret that
+// is serviced by the ExternalSecretReconciler.
+type externalSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              externalSecretSpec `json:"spec,omitempty"`
+}
+
+type externalSecretSpec struct {
+	SecretStoreRef  externalSecretStoreRef   `json:"secretStoreRef,omitempty"`
+	Target          externalSecretTarget     `json:"target,omitempty"`
+	RefreshInterval *metav1.Duration         `json:"refreshInterval,omitempty"`
+	Data            []externalSecretData     `json:"data,omitempty"`
+	DataFrom        []externalSecretDataFrom `json:"dataFrom,omitempty"`
+}
+
+type externalSecretStoreRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+type externalSecretTarget struct {
+	Name           string                  `json:"name,omitempty"`
+	CreationPolicy string                  `json:"creationPolicy,omitempty"`
+	Template       *externalSecretTemplate `json:"template,omitempty"`
+}
+
+type externalSecretTemplate struct {
+	Type     corev1.SecretType              `json:"type,omitempty"`
+	Metadata externalSecretTemplateMetadata `json:"metadata,omitempty"`
+}
+
+type externalSecretTemplateMetadata struct {
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+type externalSecretData struct {
+	SecretKey string                  `json:"secretKey"`
+	RemoteRef externalSecretRemoteRef `json:"remoteRef"`
+}
+
+type externalSecretDataFrom struct {
+	Extract *externalSecretRemoteRef `json:"extract,omitempty"`
+}
+
+type externalSecretRemoteRef struct {
+	Key      string `json:"key"`
+	Property string `json:"property,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+// secretStore is the subset of the external-secrets.io SecretStore and
+// ClusterSecretStore that is serviced by the ExternalSecretReconciler and
+// ImportExternalSecrets.
+type secretStore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              secretStoreSpec `json:"spec,omitempty"`
+}
+
+type secretStoreSpec struct {
+	Provider secretStoreProvider `json:"provider"`
+}
+
+type secretStoreProvider struct {
+	Vault *secretStoreVaultProvider `json:"vault,omitempty"`
+}
+
+type secretStoreVaultProvider struct {
+	Server     string                      `json:"server"`
+	Path       string                      `json:"path,omitempty"`
+	Version    string                      `json:"version,omitempty"`
+	Namespace  string                      `json:"namespace,omitempty"`
+	CAProvider *secretStoreVaultCAProvider `json:"caProvider,omitempty"`
+	Auth       secretStoreVaultAuth        `json:"auth,omitempty"`
+}
+
+type secretStoreVaultCAProvider struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Key       string `json:"key,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type secretStoreVaultAuth struct {
+	Kubernetes *secretStoreVaultKubernetesAuth `json:"kubernetes,omitempty"`
+}
+
+type secretStoreVaultKubernetesAuth struct {
+	Path              string                        `json:"mountPath,omitempty"`
+	Role              string                        `json:"role"`
+	ServiceAccountRef *secretStoreServiceAccountRef `json:"serviceAccountRef,omitempty"`
+}
+
+type secretStoreServiceAccountRef struct {
+	Name      string   `json:"name"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// refreshInterval returns the ExternalSecret's refresh interval, zero disables
+// refreshing.
+func (e *externalSecret) refreshInterval() time.Duration {
+	if e.Spec.RefreshInterval == nil {
+		return externalSecretDefaultRefreshInterval
+	}
+	return e.Spec.RefreshInterval.Duration
+}
+
+// validate returns an error if the ExternalSecret uses a feature that is not
+// supported by VSO.
+func (e *externalSecret) validate() error {
+	switch e.Spec.Target.CreationPolicy {
+	case "", externalSecretCreationPolicyOwner, externalSecretCreationPolicyMerge:
+	default:
+		return fmt.Errorf("unsupported target creationPolicy %q", e.Spec.Target.CreationPolicy)
+	}
+
+	if len(e.Spec.Data) == 0 && len(e.Spec.DataFrom) == 0 {
+		return fmt.Errorf("one of data or dataFrom is required")
+	}
+
+	for idx, d := range e.Spec.Data {
+		if d.SecretKey == "" || d.RemoteRef.Key == "" {
+			return fmt.Errorf("data[%d]: secretKey and remoteRef.key are required", idx)
+		}
+	}
+
+	for idx, d := range e.Spec.DataFrom {
+		if d.Extract == nil {
+			return fmt.Errorf("dataFrom[%d]: only extract is supported", idx)
+		}
+		if d.Extract.Key == "" {
+			return fmt.Errorf("dataFrom[%d]: extract.key is required", idx)
+		}
+	}
+
+	return nil
+}
+
+// vaultStaticSecret translates the ExternalSecret and its Vault backed store
+// into an equivalent VaultStaticSecret. The returned object is never persisted,
+// it carries the ExternalSecret's TypeMeta and ObjectMeta, so that any
+// synced Secret is owned by the ExternalSecret. The VaultStaticSecret's Path
+// is unset, since every remote key is read individually.
+func (e *externalSecret) vaultStaticSecret(store *secretStore) (*secretsv1beta1.VaultStaticSecret, error) {
+	provider := store.Spec.Provider.Vault
+	if provider == nil {
+		return nil, fmt.Errorf("%s %s is not backed by Vault", store.Kind, store.Name)
+	}
+
+	kvType := consts.KVSecretTypeV2
+	switch provider.Version {
+	case "", "v2":
+	case "v1":
+		kvType = consts.KVSecretTypeV1
+	default:
+		return nil, fmt.Errorf("unsupported Vault KV version %q", provider.Version)
+	}
+
+	mount := strings.Trim(provider.Path, "/")
+	if mount == "" {
+		return nil, fmt.Errorf("%s %s: the Vault provider's path is required", store.Kind, store.Name)
+	}
+
+	vaultAuthRef := e.Annotations[consts.AnnotationVaultAuthRef]
+	if vaultAuthRef == "" {
+		vaultAuthRef = store.Annotations[consts.AnnotationVaultAuthRef]
+	}
+
+	dest := secretsv1beta1.Destination{
+		Name:   e.Spec.Target.Name,
+		Create: e.Spec.Target.CreationPolicy != externalSecretCreationPolicyMerge,
+	}
+	if dest.Name == "" {
+		dest.Name = e.Name
+	}
+	if tmpl := e.Spec.Target.Template; tmpl != nil {
+		dest.Type = tmpl.Type
+		dest.Labels = tmpl.Metadata.Labels
+		dest.Annotations = tmpl.Metadata.Annotations
+	}
+
+	return &secretsv1beta1.VaultStaticSecret{
+		TypeMeta:   e.TypeMeta,
+		ObjectMeta: *e.ObjectMeta.DeepCopy(),
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			VaultAuthRef: vaultAuthRef,
+			Namespace:    provider.Namespace,
+			Mount:        mount,
+			Type:         kvType,
+			Destination:  dest,
+		},
+	}, nil
+}
+
+// remoteRefSpec returns a copy of spec that reads the remote key of ref.
+func remoteRefSpec(spec secretsv1beta1.VaultStaticSecretSpec, ref externalSecretRemoteRef) (secretsv1beta1.VaultStaticSecretSpec, error) {
+	// the external-secrets operator accepts keys that are prefixed with the mount.
+	spec.Path = strings.TrimPrefix(strings.Trim(ref.Key, "/"), spec.Mount+"/")
+	if ref.Version != "" {
+		if spec.Type != consts.KVSecretTypeV2 {
+			return spec, fmt.Errorf("remote key %q: version is only supported by KV v2", ref.Key)
+		}
+		v, err := strconv.Atoi(ref.Version)
+		if err != nil {
+			return spec, fmt.Errorf("remote key %q: invalid version %q", ref.Key, ref.Version)
+		}
+		spec.Version = v
+	}
+	return spec, nil
+}
+
+// externalSecretValue returns the value of a Vault secret's property, or the
+// JSON encoding of the whole secret if property is empty.
+func externalSecretValue(data map[string]any, property string) ([]byte, error) {
+	if property == "" {
+		return json.Marshal(data)
+	}
+
+	v, ok := data[property]
+	if !ok {
+		return nil, fmt.Errorf("property %q not found", property)
+	}
+	return externalSecretValueBytes(v)
+}
+
+func externalSecretValueBytes(v any) ([]byte, error) {
+	switch t := v.(type) {
+	case string:
+		return []byte(t), nil
+	case []byte:
+		return t, nil
+	default:
+		return json.Marshal(t)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// ExternalSecretReconciler services the external-secrets.io ExternalSecrets
+// whose SecretStore or ClusterSecretStore is backed by a Vault KV secrets
+// engine. It eases the migration from the external-secrets operator, since the
+// ExternalSecrets can be serviced by VSO before their manifests are rewritten.
+// All other ExternalSecrets are ignored.
+//
+// The Vault client is obtained from the VaultAuth set by the
+// consts.AnnotationVaultAuthRef annotation on the ExternalSecret or its store,
+// falling back to the default VaultAuth.
+type ExternalSecretReconciler struct {
+	client.Client
+	Scheme          *runtime.Scheme
+	Recorder        record.EventRecorder
+	ClientFactory   vault.ClientFactory
+	BackOffRegistry *BackOffRegistry
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+}
+
+// +kubebuilder:rbac:groups=external-secrets.io,resources=externalsecrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=external-secrets.io,resources=externalsecrets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=external-secrets.io,resources=secretstores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=external-secrets.io,resources=clustersecretstores,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+
+func (r *ExternalSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		return ctrl.Result{}, nil
+	}
+
+	u := newUnstructured(externalSecretGVK)
+	if err := r.Client.Get(ctx, req.NamespacedName, u); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.BackOffRegistry.Delete(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "error getting resource from k8s", "externalSecret", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if u.GetDeletionTimestamp() != nil {
+		// any synced Secret is garbage collected with its owning ExternalSecret.
+		r.BackOffRegistry.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	var es externalSecret
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &es); err != nil {
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonInvalidConfiguration,
+			"Failed to decode ExternalSecret: %s", err)
+		return ctrl.Result{}, nil
+	}
+
+	store, err := getSecretStore(ctx, r.Client, &es)
+	if err != nil {
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonInvalidResourceRef,
+			"Failed to get the secret store: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	if store.Spec.Provider.Vault == nil {
+		logger.V(consts.LogLevelDebug).Info("Ignoring ExternalSecret, its store is not backed by Vault",
+			"storeKind", store.Kind, "storeName", store.Name)
+		return ctrl.Result{}, nil
+	}
+
+	vss, err := es.vaultStaticSecret(store)
+	if err == nil {
+		err = es.validate()
+	}
+	if err != nil {
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonInvalidConfiguration,
+			"Unsupported ExternalSecret configuration: %s", err)
+		return ctrl.Result{}, r.updateStatus(ctx, u, metav1.ConditionFalse,
+			externalSecretReasonUnsupportedConfiguration, err.Error())
+	}
+
+	c, err := r.ClientFactory.Get(ctx, r.Client, vss)
+	if err != nil {
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonVaultClientConfigError,
+			"Failed to get Vault auth login: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	data, err := r.secretData(ctx, c, &es, vss.Spec)
+	if err != nil {
+		if vault.IsForbiddenError(err) {
+			c.Taint()
+		}
+
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonVaultClientError,
+			"Failed to read Vault secret: %s", err)
+		if err := r.updateStatus(ctx, u, metav1.ConditionFalse,
+			externalSecretReasonSecretSyncedError, err.Error()); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: entry.NextBackOff()}, nil
+	} else {
+		r.BackOffRegistry.Delete(req.NamespacedName)
+	}
+
+	if !vss.Spec.Destination.Create {
+		// the Merge creation policy retains the Secret's other keys.
+		if dest, err := helpers.GetSecret(ctx, r.Client, client.ObjectKey{
+			Namespace: vss.Namespace,
+			Name:      vss.Spec.Destination.Name,
+		}); err == nil {
+			for k, v := range dest.Data {
+				if _, ok := data[k]; !ok {
+					data[k] = v
+				}
+			}
+		}
+	}
+
+	if err := helpers.SyncSecret(ctx, r.Client, vss, data); err != nil {
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
+			"Failed to update k8s secret: %s", err)
+		if err := r.updateStatus(ctx, u, metav1.ConditionFalse,
+			externalSecretReasonSecretSyncedError, err.Error()); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+	r.Recorder.Event(u, corev1.EventTypeNormal, consts.ReasonSecretSynced, "Secret synced")
+
+	if err := r.updateStatus(ctx, u, metav1.ConditionTrue,
+		externalSecretReasonSecretSynced, "Secret was synced"); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var requeueAfter time.Duration
+	if d := es.refreshInterval(); d > 0 {
+		requeueAfter = computeHorizonWithJitter(d)
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// getSecretStore returns the SecretStore or ClusterSecretStore that the
+// ExternalSecret refers to.
+func getSecretStore(ctx context.Context, c client.Reader, es *externalSecret) (*secretStore, error) {
+	var gvk schema.GroupVersionKind
+	key := client.ObjectKey{
+		Name: es.Spec.SecretStoreRef.Name,
+	}
+	switch es.Spec.SecretStoreRef.Kind {
+	case "", kindSecretStore:
+		gvk = secretStoreGVK
+		key.Namespace = es.Namespace
+	case kindClusterSecretStore:
+		gvk = clusterSecretStoreGVK
+	default:
+		return nil, fmt.Errorf("unsupported secretStoreRef kind %q", es.Spec.SecretStoreRef.Kind)
+	}
+
+	u := newUnstructured(gvk)
+	if err := c.Get(ctx, key, u); err != nil {
+		return nil, err
+	}
+
+	var store secretStore
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &store); err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// secretData reads all remote keys of the ExternalSecret from Vault, and
+// returns the resulting K8s Secret data.
+func (r *ExternalSecretReconciler) secretData(ctx context.Context, c vault.Client,
+	es *externalSecret, spec secretsv1beta1.VaultStaticSecretSpec,
+) (map[string][]byte, error) {
+	// every Vault secret is only read once, even if it is referenced multiple times.
+	secrets := make(map[string]map[string]any)
+	read := func(ref externalSecretRemoteRef) (map[string]any, error) {
+		s, err := remoteRefSpec(spec, ref)
+		if err != nil {
+			return nil, err
+		}
+		kvReq, err := newKVRequest(s)
+		if err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s?%s", kvReq.Path(), kvReq.Values().Encode())
+		if d, ok := secrets[key]; ok {
+			return d, nil
+		}
+		resp, err := c.Read(ctx, kvReq)
+		if err != nil {
+			return nil, err
+		}
+		secrets[key] = resp.Data()
+		return secrets[key], nil
+	}
+
+	data := make(map[string][]byte)
+	for _, d := range es.Spec.DataFrom {
+		secret, err := read(*d.Extract)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range secret {
+			b, err := externalSecretValueBytes(v)
+			if err != nil {
+				return nil, fmt.Errorf("remote key %q: %w", d.Extract.Key, err)
+			}
+			data[k] = b
+		}
+	}
+
+	for _, d := range es.Spec.Data {
+		secret, err := read(d.RemoteRef)
+		if err != nil {
+			return nil, err
+		}
+		b, err := externalSecretValue(secret, d.RemoteRef.Property)
+		if err != nil {
+			return nil, fmt.Errorf("remote key %q: %w", d.RemoteRef.Key, err)
+		}
+		data[d.SecretKey] = b
+	}
+
+	return data, nil
+}
+
+// updateStatus sets the ExternalSecret's Ready condition, the way the
+// external-secrets operator does.
+func (r *ExternalSecretReconciler) updateStatus(ctx context.Context, u *unstructured.Unstructured,
+	status metav1.ConditionStatus, reason, message string,
+) error {
+	logger := log.FromContext(ctx)
+	now := metav1.Now()
+	condition := map[string]any{
+		"type":               externalSecretConditionReady,
+		"status":             string(status),
+		"reason":             reason,
+		"message":            message,
+		"lastTransitionTime": now.UTC().Format(time.RFC3339),
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	var updated []any
+	for _, c := range conditions {
+		m, ok := c.(map[string]any)
+		if !ok || m["type"] != externalSecretConditionReady {
+			updated = append(updated, c)
+			continue
+		}
+		// retain the transition time if the condition's status has not changed.
+		if m["status"] == string(status) {
+			if ts, ok := m["lastTransitionTime"]; ok {
+				condition["lastTransitionTime"] = ts
+			}
+		}
+	}
+	updated = append(updated, condition)
+
+	errs := unstructured.SetNestedSlice(u.Object, updated, "status", "conditions")
+	if status == metav1.ConditionTrue {
+		errs = errors.Join(errs,
+			unstructured.SetNestedField(u.Object, now.UTC().Format(time.RFC3339), "status", "refreshTime"),
+			unstructured.SetNestedField(u.Object, u.GetResourceVersion(), "status", "syncedResourceVersion"),
+		)
+	}
+	if errs != nil {
+		return errs
+	}
+
+	if err := r.Status().Update(ctx, u); err != nil {
+		logger.Error(err, "Failed to update the resource's status")
+		r.Recorder.Eventf(u, corev1.EventTypeWarning, consts.ReasonStatusUpdateError,
+			"Failed to update the resource's status, err=%s", err)
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. It should only be
+// called if ExternalSecretCRDInstalled returns true.
+func (r *ExternalSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	if r.BackOffRegistry == nil {
+		r.BackOffRegistry = NewBackOffRegistry()
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(newUnstructured(externalSecretGVK)).
+		WithEventFilter(syncableSecretPredicate(nil)).
+		WithOptions(opts).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDeletionRequestHandler{
+				gvk: externalSecretGVK,
+			},
+			builder.WithPredicates(&secretsPredicate{}),
+		).
+		Complete(r)
+}
+
+func newUnstructured(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
@@ -0,0 +1,310 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+var (
+	_ vault.ClientFactory = (*stubKVClientFactory)(nil)
+	_ vault.Client        = (*stubKVClient)(nil)
+)
+
+// stubKVClient returns the KV v2 secrets in secrets from Read.
+type stubKVClient struct {
+	vault.Client
+	secrets map[string]map[string]any
+	reads   []string
+}
+
+func (c *stubKVClient) Read(_ context.Context, req vault.ReadRequest) (vault.Response, error) {
+	c.reads = append(c.reads, req.Path())
+	data, ok := c.secrets[req.Path()]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", req.Path())
+	}
+	return vault.NewKVV2Response(&api.Secret{
+		Data: map[string]any{
+			"data": data,
+		},
+	}), nil
+}
+
+func (c *stubKVClient) Taint() {}
+
+// stubKVClientFactory always returns its stubKVClient from Get.
+type stubKVClientFactory struct {
+	vault.ClientFactory
+	client *stubKVClient
+	objs   []client.Object
+}
+
+func (f *stubKVClientFactory) Get(_ context.Context, _ client.Client, obj client.Object) (vault.Client, error) {
+	f.objs = append(f.objs, obj)
+	return f.client, nil
+}
+
+func newTestUnstructured(gvk schema.GroupVersionKind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+	u := newUnstructured(gvk)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetUID("c2f5e1bd-6b50-4a33-8d1f-9d1e4b6c1e2a")
+	u.Object["spec"] = spec
+	return u
+}
+
+func TestExternalSecretReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	vaultStore := newTestUnstructured(secretStoreGVK, "default", "vault", map[string]any{
+		"provider": map[string]any{
+			"vault": map[string]any{
+				"server":  "https://vault:8200",
+				"path":    "secret",
+				"version": "v2",
+			},
+		},
+	})
+	vaultStore.SetAnnotations(map[string]string{
+		consts.AnnotationVaultAuthRef: "vso/auth",
+	})
+	awsStore := newTestUnstructured(secretStoreGVK, "default", "aws", map[string]any{
+		"provider": map[string]any{
+			"aws": map[string]any{
+				"service": "SecretsManager",
+			},
+		},
+	})
+	secrets := map[string]map[string]any{
+		"secret/data/app/db": {
+			"username": "admin",
+			"password": "s3cr3t",
+		},
+		"secret/data/app/config": {
+			"port":  8080,
+			"debug": "true",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		store      *unstructured.Unstructured
+		spec       map[string]any
+		existing   *corev1.Secret
+		wantData   map[string][]byte
+		wantReads  []string
+		wantReady  string
+		wantReason string
+	}{
+		{
+			name:  "synced",
+			store: vaultStore,
+			spec: map[string]any{
+				"refreshInterval": "15m",
+				"secretStoreRef": map[string]any{
+					"name": "vault",
+					"kind": "SecretStore",
+				},
+				"target": map[string]any{
+					"name": "app",
+				},
+				"data": []any{
+					map[string]any{
+						"secretKey": "db-password",
+						"remoteRef": map[string]any{
+							"key":      "app/db",
+							"property": "password",
+						},
+					},
+					map[string]any{
+						"secretKey": "db-username",
+						"remoteRef": map[string]any{
+							"key":      "secret/app/db",
+							"property": "username",
+						},
+					},
+				},
+				"dataFrom": []any{
+					map[string]any{
+						"extract": map[string]any{
+							"key": "app/config",
+						},
+					},
+				},
+			},
+			wantData: map[string][]byte{
+				"db-password": []byte("s3cr3t"),
+				"db-username": []byte("admin"),
+				"port":        []byte("8080"),
+				"debug":       []byte("true"),
+			},
+			wantReads:  []string{"secret/data/app/config", "secret/data/app/db"},
+			wantReady:  "True",
+			wantReason: externalSecretReasonSecretSynced,
+		},
+		{
+			name:  "merge",
+			store: vaultStore,
+			spec: map[string]any{
+				"secretStoreRef": map[string]any{
+					"name": "vault",
+				},
+				"target": map[string]any{
+					"creationPolicy": "Merge",
+				},
+				"data": []any{
+					map[string]any{
+						"secretKey": "password",
+						"remoteRef": map[string]any{
+							"key":      "app/db",
+							"property": "password",
+						},
+					},
+				},
+			},
+			existing: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "es",
+				},
+				Data: map[string][]byte{
+					"password": []byte("old"),
+					"other":    []byte("retained"),
+				},
+			},
+			wantData: map[string][]byte{
+				"password": []byte("s3cr3t"),
+				"other":    []byte("retained"),
+			},
+			wantReads:  []string{"secret/data/app/db"},
+			wantReady:  "True",
+			wantReason: externalSecretReasonSecretSynced,
+		},
+		{
+			name:  "unsupported-creation-policy",
+			store: vaultStore,
+			spec: map[string]any{
+				"secretStoreRef": map[string]any{
+					"name": "vault",
+				},
+				"target": map[string]any{
+					"creationPolicy": "Orphan",
+				},
+				"data": []any{
+					map[string]any{
+						"secretKey": "password",
+						"remoteRef": map[string]any{
+							"key": "app/db",
+						},
+					},
+				},
+			},
+			wantReady:  "False",
+			wantReason: externalSecretReasonUnsupportedConfiguration,
+		},
+		{
+			name:  "not-backed-by-vault",
+			store: awsStore,
+			spec: map[string]any{
+				"secretStoreRef": map[string]any{
+					"name": "aws",
+				},
+				"data": []any{
+					map[string]any{
+						"secretKey": "password",
+						"remoteRef": map[string]any{
+							"key": "app/db",
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			es := newTestUnstructured(externalSecretGVK, "default", "es", tt.spec)
+			objs := []client.Object{es, tt.store.DeepCopy()}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing)
+			}
+			c := testutils.NewFakeClientBuilder().
+				WithObjects(objs...).
+				WithStatusSubresource(es).
+				Build()
+			vaultClient := &stubKVClient{secrets: secrets}
+			factory := &stubKVClientFactory{client: vaultClient}
+			r := &ExternalSecretReconciler{
+				Client:          c,
+				Recorder:        record.NewFakeRecorder(10),
+				ClientFactory:   factory,
+				BackOffRegistry: NewBackOffRegistry(),
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(es)})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.wantReads, vaultClient.reads)
+
+			got := newUnstructured(externalSecretGVK)
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(es), got))
+			conditions, _, err := unstructured.NestedSlice(got.Object, "status", "conditions")
+			require.NoError(t, err)
+			if tt.wantReady == "" {
+				assert.Empty(t, conditions)
+				assert.Empty(t, factory.objs)
+				return
+			}
+
+			require.Len(t, conditions, 1)
+			condition := conditions[0].(map[string]any)
+			assert.Equal(t, externalSecretConditionReady, condition["type"])
+			assert.Equal(t, tt.wantReady, condition["status"])
+			assert.Equal(t, tt.wantReason, condition["reason"])
+			if tt.wantData == nil {
+				return
+			}
+
+			require.Len(t, factory.objs, 1)
+			if assert.IsType(t, &secretsv1beta1.VaultStaticSecret{}, factory.objs[0]) {
+				assert.Equal(t, "vso/auth", factory.objs[0].(*secretsv1beta1.VaultStaticSecret).Spec.VaultAuthRef)
+			}
+
+			name, _, _ := unstructured.NestedString(tt.spec, "target", "name")
+			if name == "" {
+				name = es.GetName()
+			}
+			secret, err := helpers.GetSecret(ctx, c, client.ObjectKey{Namespace: "default", Name: name})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, secret.Data)
+			if tt.existing == nil {
+				require.Len(t, secret.OwnerReferences, 1)
+				assert.Equal(t, "ExternalSecret", secret.OwnerReferences[0].Kind)
+				assert.Equal(t, "external-secrets.io/v1beta1", secret.OwnerReferences[0].APIVersion)
+				assert.Equal(t, es.GetUID(), secret.OwnerReferences[0].UID)
+			}
+		})
+	}
+}
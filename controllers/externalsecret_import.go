@@ -0,0 +1,272 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	vaultcredsconsts "github.com/hashicorp/vault-secrets-operator/credentials/vault/consts"
+)
+
+// ExternalSecretImport holds the VSO resources that are equivalent to the
+// external-secrets.io resources of a cluster.
+type ExternalSecretImport struct {
+	// Objects are the imported VaultConnections, VaultAuths, and
+	// VaultStaticSecrets, in that order for each ExternalSecret.
+	Objects []client.Object
+	// Skipped holds the reason why an ExternalSecret could not be imported,
+	// keyed by its namespace/name.
+	Skipped map[string]error
+}
+
+// ImportExternalSecrets generates the VaultConnections, VaultAuths, and
+// VaultStaticSecrets that are equivalent to the external-secrets.io
+// ExternalSecrets in namespace, or in all namespaces if it is empty. The
+// ExternalSecrets whose store is not backed by Vault are ignored, the ones that
+// cannot be expressed as VSO resources are skipped. The generated resources
+// are not persisted.
+func ImportExternalSecrets(ctx context.Context, c client.Reader, namespace string) (*ExternalSecretImport, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(externalSecretGVK.GroupVersion().WithKind(externalSecretGVK.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	result := &ExternalSecretImport{
+		Skipped: make(map[string]error),
+	}
+	seen := make(map[string]bool)
+	for _, u := range list.Items {
+		objKey := client.ObjectKeyFromObject(&u).String()
+
+		var es externalSecret
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &es); err != nil {
+			result.Skipped[objKey] = err
+			continue
+		}
+
+		store, err := getSecretStore(ctx, c, &es)
+		if err != nil {
+			result.Skipped[objKey] = fmt.Errorf("failed to get the secret store: %w", err)
+			continue
+		}
+
+		if store.Spec.Provider.Vault == nil {
+			continue
+		}
+
+		objs, err := importExternalSecret(&es, store)
+		if err != nil {
+			result.Skipped[objKey] = err
+			continue
+		}
+
+		for _, o := range objs {
+			// the VaultConnection and VaultAuth of a store are shared by all of
+			// its ExternalSecrets in a namespace.
+			k := o.GetObjectKind().GroupVersionKind().Kind + "/" + client.ObjectKeyFromObject(o).String()
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			result.Objects = append(result.Objects, o)
+		}
+	}
+
+	return result, nil
+}
+
+// importExternalSecret returns the VaultConnection, VaultAuth, and
+// VaultStaticSecret that are equivalent to the ExternalSecret and its Vault
+// backed store. The VaultConnection and VaultAuth are named after the store and
+// reside in the ExternalSecret's namespace. They are omitted if the
+// consts.AnnotationVaultAuthRef annotation is set on the ExternalSecret or its
+// store, in which case the referenced VaultAuth is used instead.
+func importExternalSecret(es *externalSecret, store *secretStore) ([]client.Object, error) {
+	if err := es.validate(); err != nil {
+		return nil, err
+	}
+
+	vss, err := es.vaultStaticSecret(store)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := es.importSpec(vss.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	if spec.VaultAuthRef == "" {
+		conn, err := importVaultConnection(es.Namespace, store)
+		if err != nil {
+			return nil, err
+		}
+
+		auth, err := importVaultAuth(es.Namespace, store)
+		if err != nil {
+			return nil, err
+		}
+		auth.Spec.VaultConnectionRef = conn.Name
+		spec.VaultAuthRef = auth.Name
+		objs = append(objs, conn, auth)
+	}
+
+	return append(objs, &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       VaultStaticSecret.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      es.Name,
+		},
+		Spec: spec,
+	}), nil
+}
+
+// importSpec returns a copy of spec that reads the ExternalSecret's remote key.
+// A VaultStaticSecret reads a single Vault secret, so all of the ExternalSecret's
+// data and dataFrom must refer to the same remote key. Every data entry is
+// rendered by a template of the Destination's Transformation.
+func (e *externalSecret) importSpec(spec secretsv1beta1.VaultStaticSecretSpec) (secretsv1beta1.VaultStaticSecretSpec, error) {
+	var refs []externalSecretRemoteRef
+	for _, d := range e.Spec.DataFrom {
+		refs = append(refs, *d.Extract)
+	}
+
+	templates := make(map[string]secretsv1beta1.Template)
+	for _, d := range e.Spec.Data {
+		refs = append(refs, d.RemoteRef)
+		text := `{{- .Secrets | mustToJson -}}`
+		if d.RemoteRef.Property != "" {
+			text = fmt.Sprintf(`{{- get .Secrets %q -}}`, d.RemoteRef.Property)
+		}
+		templates[d.SecretKey] = secretsv1beta1.Template{
+			Text: text,
+		}
+	}
+
+	result, err := remoteRefSpec(spec, refs[0])
+	if err != nil {
+		return spec, err
+	}
+
+	for _, ref := range refs[1:] {
+		s, err := remoteRefSpec(spec, ref)
+		if err != nil {
+			return spec, err
+		}
+		if s.Path != result.Path || s.Version != result.Version {
+			return spec, fmt.Errorf("remote keys %q and %q differ, "+
+				"a VaultStaticSecret can only read a single remote key", refs[0].Key, ref.Key)
+		}
+	}
+
+	// the external-secrets operator never includes the raw secret.
+	result.Destination.Transformation.ExcludeRaw = ptr.To(true)
+	if len(templates) > 0 {
+		result.Destination.Transformation.Templates = templates
+		if len(e.Spec.DataFrom) == 0 {
+			// only the data entries are synced.
+			result.Destination.Transformation.Excludes = []string{".*"}
+		}
+	}
+
+	if d := e.refreshInterval(); d > 0 {
+		result.RefreshAfter = d.String()
+	}
+
+	return result, nil
+}
+
+// importVaultConnection returns the VaultConnection that is equivalent to the
+// Vault provider of store.
+func importVaultConnection(namespace string, store *secretStore) (*secretsv1beta1.VaultConnection, error) {
+	provider := store.Spec.Provider.Vault
+	conn := &secretsv1beta1.VaultConnection{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       VaultConnection.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      store.Name,
+		},
+		Spec: secretsv1beta1.VaultConnectionSpec{
+			Address: provider.Server,
+		},
+	}
+
+	if ca := provider.CAProvider; ca != nil {
+		if ca.Type != "Secret" {
+			return nil, fmt.Errorf("%s %s: unsupported caProvider type %q, only Secret is supported",
+				store.Kind, store.Name, ca.Type)
+		}
+		if ca.Key != "" && ca.Key != "ca.crt" {
+			return nil, fmt.Errorf("%s %s: unsupported caProvider key %q, the CA certificate must be stored as ca.crt",
+				store.Kind, store.Name, ca.Key)
+		}
+		if ca.Namespace != "" && ca.Namespace != namespace {
+			return nil, fmt.Errorf("%s %s: the caProvider Secret must reside in namespace %s",
+				store.Kind, store.Name, namespace)
+		}
+		conn.Spec.CACertSecretRef = ca.Name
+	}
+
+	return conn, nil
+}
+
+// importVaultAuth returns the VaultAuth that is equivalent to the Vault
+// provider's auth of store. Only the kubernetes auth method is supported.
+func importVaultAuth(namespace string, store *secretStore) (*secretsv1beta1.VaultAuth, error) {
+	provider := store.Spec.Provider.Vault
+	k8sAuth := provider.Auth.Kubernetes
+	if k8sAuth == nil {
+		return nil, fmt.Errorf("%s %s: unsupported Vault auth method, only kubernetes is supported, "+
+			"the %s annotation can be set to use an existing VaultAuth",
+			store.Kind, store.Name, consts.AnnotationVaultAuthRef)
+	}
+
+	if k8sAuth.ServiceAccountRef == nil || k8sAuth.ServiceAccountRef.Name == "" {
+		return nil, fmt.Errorf("%s %s: the Vault kubernetes auth serviceAccountRef is required",
+			store.Kind, store.Name)
+	}
+
+	mount := k8sAuth.Path
+	if mount == "" {
+		mount = "kubernetes"
+	}
+
+	return &secretsv1beta1.VaultAuth{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       VaultAuth.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      store.Name,
+		},
+		Spec: secretsv1beta1.VaultAuthSpec{
+			Namespace: provider.Namespace,
+			Method:    vaultcredsconsts.ProviderMethodKubernetes,
+			Mount:     mount,
+			Kubernetes: &secretsv1beta1.VaultAuthConfigKubernetes{
+				Role:           k8sAuth.Role,
+				ServiceAccount: k8sAuth.ServiceAccountRef.Name,
+				TokenAudiences: k8sAuth.ServiceAccountRef.Audiences,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,400 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestImportExternalSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	vaultStore := newTestUnstructured(secretStoreGVK, "default", "vault", map[string]any{
+		"provider": map[string]any{
+			"vault": map[string]any{
+				"server":    "https://vault:8200",
+				"path":      "secret",
+				"version":   "v2",
+				"namespace": "tenant-1",
+				"caProvider": map[string]any{
+					"type": "Secret",
+					"name": "vault-ca",
+					"key":  "ca.crt",
+				},
+				"auth": map[string]any{
+					"kubernetes": map[string]any{
+						"mountPath": "k8s",
+						"role":      "app",
+						"serviceAccountRef": map[string]any{
+							"name": "app",
+						},
+					},
+				},
+			},
+		},
+	})
+	awsStore := newTestUnstructured(secretStoreGVK, "default", "aws", map[string]any{
+		"provider": map[string]any{
+			"aws": map[string]any{
+				"service": "SecretsManager",
+			},
+		},
+	})
+
+	objs := []client.Object{
+		vaultStore,
+		awsStore,
+		newTestUnstructured(externalSecretGVK, "default", "db", map[string]any{
+			"secretStoreRef": map[string]any{
+				"name": "vault",
+			},
+			"target": map[string]any{
+				"name": "db-creds",
+			},
+			"data": []any{
+				map[string]any{
+					"secretKey": "user",
+					"remoteRef": map[string]any{
+						"key":      "app/db",
+						"property": "username",
+					},
+				},
+				map[string]any{
+					"secretKey": "all",
+					"remoteRef": map[string]any{
+						"key": "secret/app/db",
+					},
+				},
+			},
+		}),
+		newTestUnstructured(externalSecretGVK, "default", "config", map[string]any{
+			"secretStoreRef": map[string]any{
+				"name": "vault",
+			},
+			"refreshInterval": "0s",
+			"target": map[string]any{
+				"creationPolicy": "Merge",
+			},
+			"dataFrom": []any{
+				map[string]any{
+					"extract": map[string]any{
+						"key":     "app/config",
+						"version": "2",
+					},
+				},
+			},
+		}),
+		newTestUnstructured(externalSecretGVK, "default", "multi", map[string]any{
+			"secretStoreRef": map[string]any{
+				"name": "vault",
+			},
+			"dataFrom": []any{
+				map[string]any{
+					"extract": map[string]any{
+						"key": "app/db",
+					},
+				},
+				map[string]any{
+					"extract": map[string]any{
+						"key": "app/config",
+					},
+				},
+			},
+		}),
+		newTestUnstructured(externalSecretGVK, "default", "aws", map[string]any{
+			"secretStoreRef": map[string]any{
+				"name": "aws",
+			},
+			"dataFrom": []any{
+				map[string]any{
+					"extract": map[string]any{
+						"key": "app",
+					},
+				},
+			},
+		}),
+		newTestUnstructured(externalSecretGVK, "default", "no-store", map[string]any{
+			"secretStoreRef": map[string]any{
+				"name": "missing",
+			},
+			"dataFrom": []any{
+				map[string]any{
+					"extract": map[string]any{
+						"key": "app",
+					},
+				},
+			},
+		}),
+	}
+
+	c := testutils.NewFakeClientBuilder().WithObjects(objs...).Build()
+	got, err := ImportExternalSecrets(ctx, c, "default")
+	require.NoError(t, err)
+
+	wantConn := &secretsv1beta1.VaultConnection{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       "VaultConnection",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "vault",
+		},
+		Spec: secretsv1beta1.VaultConnectionSpec{
+			Address:         "https://vault:8200",
+			CACertSecretRef: "vault-ca",
+		},
+	}
+	wantAuth := &secretsv1beta1.VaultAuth{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       "VaultAuth",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "vault",
+		},
+		Spec: secretsv1beta1.VaultAuthSpec{
+			VaultConnectionRef: "vault",
+			Namespace:          "tenant-1",
+			Method:             "kubernetes",
+			Mount:              "k8s",
+			Kubernetes: &secretsv1beta1.VaultAuthConfigKubernetes{
+				Role:           "app",
+				ServiceAccount: "app",
+			},
+		},
+	}
+	wantDB := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       "VaultStaticSecret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "db",
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			VaultAuthRef: "vault",
+			Namespace:    "tenant-1",
+			Mount:        "secret",
+			Path:         "app/db",
+			Type:         consts.KVSecretTypeV2,
+			RefreshAfter: "1h0m0s",
+			Destination: secretsv1beta1.Destination{
+				Name:   "db-creds",
+				Create: true,
+				Transformation: secretsv1beta1.Transformation{
+					Templates: map[string]secretsv1beta1.Template{
+						"user": {Text: `{{- get .Secrets "username" -}}`},
+						"all":  {Text: `{{- .Secrets | mustToJson -}}`},
+					},
+					Excludes:   []string{".*"},
+					ExcludeRaw: ptr.To(true),
+				},
+			},
+		},
+	}
+	wantConfig := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: secretsv1beta1.GroupVersion.String(),
+			Kind:       "VaultStaticSecret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "config",
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			VaultAuthRef: "vault",
+			Namespace:    "tenant-1",
+			Mount:        "secret",
+			Path:         "app/config",
+			Version:      2,
+			Type:         consts.KVSecretTypeV2,
+			Destination: secretsv1beta1.Destination{
+				Name: "config",
+				Transformation: secretsv1beta1.Transformation{
+					ExcludeRaw: ptr.To(true),
+				},
+			},
+		},
+	}
+
+	// the VaultConnection and VaultAuth are shared by both ExternalSecrets.
+	assert.ElementsMatch(t, []client.Object{wantConn, wantAuth, wantDB, wantConfig}, got.Objects)
+	require.Len(t, got.Skipped, 2)
+	assert.ErrorContains(t, got.Skipped["default/multi"], "a VaultStaticSecret can only read a single remote key")
+	assert.ErrorContains(t, got.Skipped["default/no-store"], "failed to get the secret store")
+}
+
+func Test_importExternalSecret(t *testing.T) {
+	t.Parallel()
+
+	newStore := func(auth map[string]any, ca map[string]any) *secretStore {
+		vault := map[string]any{
+			"server": "https://vault:8200",
+			"path":   "secret",
+			"auth":   auth,
+		}
+		if ca != nil {
+			vault["caProvider"] = ca
+		}
+		return &secretStore{
+			TypeMeta: metav1.TypeMeta{
+				Kind: kindClusterSecretStore,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vault",
+			},
+			Spec: secretStoreSpec{
+				Provider: secretStoreProvider{
+					Vault: mustSecretStoreVaultProvider(t, vault),
+				},
+			},
+		}
+	}
+	k8sAuth := map[string]any{
+		"kubernetes": map[string]any{
+			"role": "app",
+			"serviceAccountRef": map[string]any{
+				"name": "app",
+			},
+		},
+	}
+	es := &externalSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "tenant",
+			Name:      "app",
+		},
+		Spec: externalSecretSpec{
+			DataFrom: []externalSecretDataFrom{
+				{Extract: &externalSecretRemoteRef{Key: "app"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		es          *externalSecret
+		store       *secretStore
+		wantKinds   []string
+		wantErr     string
+		wantMount   string
+		wantAuthRef string
+	}{
+		{
+			name:        "kubernetes-auth",
+			es:          es,
+			store:       newStore(k8sAuth, nil),
+			wantKinds:   []string{"VaultConnection", "VaultAuth", "VaultStaticSecret"},
+			wantMount:   "kubernetes",
+			wantAuthRef: "vault",
+		},
+		{
+			name: "vault-auth-ref-annotation",
+			es: func() *externalSecret {
+				o := *es
+				o.Annotations = map[string]string{
+					consts.AnnotationVaultAuthRef: "vso/auth",
+				}
+				return &o
+			}(),
+			store: newStore(map[string]any{
+				"appRole": map[string]any{
+					"path": "approle",
+				},
+			}, nil),
+			wantKinds:   []string{"VaultStaticSecret"},
+			wantAuthRef: "vso/auth",
+		},
+		{
+			name: "unsupported-auth",
+			es:   es,
+			store: newStore(map[string]any{
+				"appRole": map[string]any{
+					"path": "approle",
+				},
+			}, nil),
+			wantErr: "unsupported Vault auth method",
+		},
+		{
+			name: "no-service-account",
+			es:   es,
+			store: newStore(map[string]any{
+				"kubernetes": map[string]any{
+					"role": "app",
+				},
+			}, nil),
+			wantErr: "serviceAccountRef is required",
+		},
+		{
+			name: "unsupported-ca-key",
+			es:   es,
+			store: newStore(k8sAuth, map[string]any{
+				"type": "Secret",
+				"name": "ca",
+				"key":  "ca.pem",
+			}),
+			wantErr: `unsupported caProvider key "ca.pem"`,
+		},
+		{
+			name: "ca-other-namespace",
+			es:   es,
+			store: newStore(k8sAuth, map[string]any{
+				"type":      "Secret",
+				"name":      "ca",
+				"namespace": "vault",
+			}),
+			wantErr: "the caProvider Secret must reside in namespace tenant",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := importExternalSecret(tt.es, tt.store)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var kinds []string
+			for _, o := range got {
+				kinds = append(kinds, o.GetObjectKind().GroupVersionKind().Kind)
+				assert.Equal(t, tt.es.Namespace, o.GetNamespace())
+				if auth, ok := o.(*secretsv1beta1.VaultAuth); ok {
+					assert.Equal(t, tt.wantMount, auth.Spec.Mount)
+				}
+			}
+			assert.Equal(t, tt.wantKinds, kinds)
+
+			vss := got[len(got)-1].(*secretsv1beta1.VaultStaticSecret)
+			assert.Equal(t, tt.wantAuthRef, vss.Spec.VaultAuthRef)
+			assert.Equal(t, "app", vss.Spec.Path)
+		})
+	}
+}
+
+func mustSecretStoreVaultProvider(t *testing.T, m map[string]any) *secretStoreVaultProvider {
+	t.Helper()
+
+	var p secretStoreVaultProvider
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(m, &p))
+	return &p
+}
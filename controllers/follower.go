@@ -0,0 +1,200 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+var (
+	_ manager.Runnable               = (*FollowerValidator)(nil)
+	_ manager.LeaderElectionRunnable = (*FollowerValidator)(nil)
+)
+
+// errFollowerNotValidated is returned by FollowerValidator.Ready until the
+// first validation has completed.
+var errFollowerNotValidated = errors.New("follower has not been validated yet")
+
+// FollowerValidator periodically validates that an operator instance running in
+// follower mode could take over syncing the secret resources from the active
+// instance. For every syncable resource it obtains a Vault, or HVS, client,
+// which exercises the resource's auth method, and keeps the Vault client cache
+// warm. It never modifies any resources. The outcome of the last validation is
+// reported by Ready, which is meant to be the manager's readiness check.
+type FollowerValidator struct {
+	Client        client.Client
+	ClientFactory vault.ClientFactory
+	// Interval between validations.
+	Interval time.Duration
+	// newHVSClient is used by tests to fake the HVS API.
+	newHVSClient hvsClientFunc
+
+	mu        sync.RWMutex
+	validated bool
+	lastErr   error
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. A follower
+// must not take part in leader election, since it never becomes active.
+func (v *FollowerValidator) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (v *FollowerValidator) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("followerValidator")
+	ticker := time.NewTicker(v.Interval)
+	defer ticker.Stop()
+	for {
+		err := v.validate(ctx)
+		if err != nil {
+			logger.Error(err, "Follower validation failed")
+		} else {
+			logger.V(consts.LogLevelDebug).Info("Follower validation succeeded")
+		}
+
+		v.mu.Lock()
+		v.validated = true
+		v.lastErr = err
+		v.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ready returns an error if the follower could not take over, it implements
+// healthz.Checker.
+func (v *FollowerValidator) Ready(_ *http.Request) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if !v.validated {
+		return errFollowerNotValidated
+	}
+
+	return v.lastErr
+}
+
+// validate obtains a client for every syncable resource, and returns the
+// joined errors of all the resources that failed.
+func (v *FollowerValidator) validate(ctx context.Context) error {
+	var errs error
+	var objs []client.Object
+	for _, list := range []client.ObjectList{
+		&secretsv1beta1.VaultStaticSecretList{},
+		&secretsv1beta1.VaultDynamicSecretList{},
+		&secretsv1beta1.VaultPKISecretList{},
+		&secretsv1beta1.VaultSSHCertificateList{},
+		&secretsv1beta1.VaultTransitKeyList{},
+	} {
+		items, err := v.list(ctx, list)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		objs = append(objs, items...)
+	}
+
+	for _, o := range objs {
+		if _, err := v.ClientFactory.Get(ctx, v.Client, o); err != nil {
+			errs = errors.Join(errs, followerObjError(o, err))
+		}
+	}
+
+	newClient := v.newHVSClient
+	if newClient == nil {
+		newClient = newHVSClient
+	}
+
+	objs = nil
+	for _, list := range []client.ObjectList{
+		&secretsv1beta1.HCPVaultSecretsAppList{},
+		&secretsv1beta1.HCPVaultSecretsProjectList{},
+	} {
+		items, err := v.list(ctx, list)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		objs = append(objs, items...)
+	}
+
+	for _, o := range objs {
+		if _, err := newClient(ctx, v.Client, o); err != nil {
+			errs = errors.Join(errs, followerObjError(o, err))
+		}
+	}
+
+	return errs
+}
+
+// list returns all the objects of list that are not being deleted.
+func (v *FollowerValidator) list(ctx context.Context, list client.ObjectList) ([]client.Object, error) {
+	if err := v.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	switch t := list.(type) {
+	case *secretsv1beta1.VaultStaticSecretList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	case *secretsv1beta1.VaultDynamicSecretList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	case *secretsv1beta1.VaultPKISecretList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	case *secretsv1beta1.VaultSSHCertificateList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	case *secretsv1beta1.VaultTransitKeyList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	case *secretsv1beta1.HCPVaultSecretsAppList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	case *secretsv1beta1.HCPVaultSecretsProjectList:
+		for i := range t.Items {
+			objs = append(objs, &t.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported list type %T", list)
+	}
+
+	var ret []client.Object
+	for _, o := range objs {
+		if o.GetDeletionTimestamp() == nil {
+			ret = append(ret, o)
+		}
+	}
+
+	return ret, nil
+}
+
+func followerObjError(o client.Object, err error) error {
+	return fmt.Errorf("%T %s: %w", o, client.ObjectKeyFromObject(o), err)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	hvsclient "github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/client/secret_service"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+var _ vault.ClientFactory = (*stubClientFactory)(nil)
+
+// stubClientFactory returns an error from Get for the objects in errs.
+type stubClientFactory struct {
+	vault.ClientFactory
+	errs map[client.ObjectKey]error
+	gets []client.ObjectKey
+}
+
+func (f *stubClientFactory) Get(_ context.Context, _ client.Client, obj client.Object) (vault.Client, error) {
+	key := client.ObjectKeyFromObject(obj)
+	f.gets = append(f.gets, key)
+	return nil, f.errs[key]
+}
+
+func TestFollowerValidator_validate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default"}
+	}
+	objs := []client.Object{
+		&secretsv1beta1.VaultStaticSecret{ObjectMeta: meta("vss")},
+		&secretsv1beta1.VaultDynamicSecret{ObjectMeta: meta("vds")},
+		&secretsv1beta1.VaultPKISecret{ObjectMeta: meta("pki")},
+		&secretsv1beta1.HCPVaultSecretsApp{ObjectMeta: meta("hvsa")},
+	}
+	vssKey := client.ObjectKey{Namespace: "default", Name: "vss"}
+	hvsaKey := client.ObjectKey{Namespace: "default", Name: "hvsa"}
+
+	tests := []struct {
+		name      string
+		vaultErrs map[client.ObjectKey]error
+		hvsErrs   map[client.ObjectKey]error
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "vault-auth-failed",
+			vaultErrs: map[client.ObjectKey]error{
+				vssKey: errors.New("permission denied"),
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "VaultStaticSecret default/vss: permission denied", i...)
+			},
+		},
+		{
+			name: "hcp-auth-failed",
+			hvsErrs: map[client.ObjectKey]error{
+				hvsaKey: errors.New("invalid credentials"),
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "HCPVaultSecretsApp default/hvsa: invalid credentials", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testutils.NewFakeClientBuilder().WithObjects(objs...).Build()
+			f := &stubClientFactory{errs: tt.vaultErrs}
+			var hvsGets []client.ObjectKey
+			v := &FollowerValidator{
+				Client:        c,
+				ClientFactory: f,
+				newHVSClient: func(_ context.Context, _ client.Client, o client.Object) (hvsclient.ClientService, error) {
+					key := client.ObjectKeyFromObject(o)
+					hvsGets = append(hvsGets, key)
+					return nil, tt.hvsErrs[key]
+				},
+			}
+
+			tt.wantErr(t, v.validate(ctx))
+			assert.ElementsMatch(t, []client.ObjectKey{
+				vssKey,
+				{Namespace: "default", Name: "vds"},
+				{Namespace: "default", Name: "pki"},
+			}, f.gets)
+			assert.Equal(t, []client.ObjectKey{hvsaKey}, hvsGets)
+		})
+	}
+}
+
+func TestFollowerValidator_Ready(t *testing.T) {
+	t.Parallel()
+
+	v := &FollowerValidator{}
+	assert.ErrorIs(t, v.Ready(nil), errFollowerNotValidated)
+
+	v.validated = true
+	assert.NoError(t, v.Ready(nil))
+
+	v.lastErr = errors.New("validation failed")
+	assert.EqualError(t, v.Ready(nil), "validation failed")
+}
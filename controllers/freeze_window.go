@@ -0,0 +1,392 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/cron"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+const (
+	// defaultFreezeWindowInterval is the default interval between updates of
+	// the freeze window's active metric.
+	defaultFreezeWindowInterval = time.Second * 30
+	// freezeWindowMaxJitter is the maximum jitter added to the end of the freeze
+	// window, it spreads out the deferred work once the window ends.
+	freezeWindowMaxJitter = time.Minute
+
+	// conditionTypeRotationDeferred is the condition type that reports a secret
+	// rotation that is deferred until the end of the freeze window.
+	conditionTypeRotationDeferred = "RotationDeferred"
+	// conditionTypeRolloutRestartDeferred is the condition type that reports
+	// the rollout-restarts that are deferred until the end of the freeze window.
+	conditionTypeRolloutRestartDeferred = "RolloutRestartDeferred"
+	reasonFreezeWindow                  = "FreezeWindow"
+)
+
+var (
+	_ manager.Runnable               = (*FreezeWindow)(nil)
+	_ manager.LeaderElectionRunnable = (*FreezeWindow)(nil)
+)
+
+// FreezeWindow provides a recurring maintenance window during which the
+// syncable secret controllers defer all non-critical secret rotations and all
+// rollout-restarts. The deferred work is executed once the window ends. Syncs
+// of new and updated resources, of missing destination Secrets, and of secrets
+// that would expire before the window ends are never deferred. The deferred
+// work is reported in the resource's status conditions. It is meant to be added
+// to the manager, and runs on all replicas.
+type FreezeWindow struct {
+	// Schedule of the start of each window.
+	Schedule *cron.Schedule
+	// Duration of each window.
+	Duration time.Duration
+	// ExemptSelector selects the resources that are never frozen, it is
+	// ignored if empty.
+	ExemptSelector labels.Selector
+	// Interval between updates of the freeze window's active metric.
+	Interval time.Duration
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (w *FreezeWindow) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (w *FreezeWindow) Start(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultFreezeWindowInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, active := w.End(nowFunc())
+		metrics.SetFreezeWindowActive(active)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// End returns true along with the end of the freeze window, if a window is
+// active at now. It is safe to call on a nil FreezeWindow.
+func (w *FreezeWindow) End(now time.Time) (time.Time, bool) {
+	if w == nil || w.Schedule == nil || w.Duration <= 0 {
+		return time.Time{}, false
+	}
+
+	start, ok := w.Schedule.Prev(now, w.Duration)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	end := start.Add(w.Duration)
+	if !end.After(now) {
+		return time.Time{}, false
+	}
+
+	return end, true
+}
+
+// Frozen returns true along with the end of the freeze window, if o is frozen.
+// It is safe to call on a nil FreezeWindow.
+func (w *FreezeWindow) Frozen(o client.Object) (time.Time, bool) {
+	end, ok := w.End(nowFunc())
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if w.ExemptSelector != nil && !w.ExemptSelector.Empty() &&
+		w.ExemptSelector.Matches(labels.Set(o.GetLabels())) {
+		return time.Time{}, false
+	}
+
+	return end, true
+}
+
+// HandlePending should be called at the start of each reconciliation of o. Once
+// o is no longer frozen, it clears the conditions of the deferred work, and
+// triggers the rollout-restarts that were deferred, unless they remain deferred
+// until o's next rollout-restart window. If o still has deferred
+// rollout-restarts, it returns the duration after which o should be requeued.
+// It is safe to call on a nil FreezeWindow.
+func (w *FreezeWindow) HandlePending(ctx context.Context, c client.Client, validator helpers.HMACValidator,
+	o client.Object, recorder record.EventRecorder,
+) (time.Duration, error) {
+	conditions := statusConditions(o)
+	if conditions == nil {
+		return 0, nil
+	}
+
+	restartDeferred := hasCondition(*conditions, conditionTypeRolloutRestartDeferred)
+	if end, ok := w.Frozen(o); ok {
+		if restartDeferred {
+			return deferUntil(end), nil
+		}
+		return 0, nil
+	}
+
+	if !restartDeferred && !hasCondition(*conditions, conditionTypeRotationDeferred) {
+		return 0, nil
+	}
+
+	var deferAfter time.Duration
+	updated := hasCondition(*conditions, conditionTypeRotationDeferred)
+	*conditions = removeConditions(*conditions, conditionTypeRotationDeferred)
+	if restartDeferred {
+		if start, ok := nextRolloutRestartWindow(ctx, o, nowFunc()); ok {
+			deferAfter = deferUntil(start)
+			if replaceCondition(conditions, newDeferredCondition(o,
+				conditionTypeRolloutRestartDeferred, reasonRolloutRestartWindow, start)) {
+				updated = true
+			}
+		} else {
+			log.FromContext(ctx).Info("Triggering the deferred rollout-restarts")
+			// rollout-restart errors are not retryable
+			// all error reporting is handled by helpers.HandleRolloutRestarts
+			_ = helpers.HandleRolloutRestarts(ctx, c, validator, o, recorder)
+			*conditions = removeConditions(*conditions, conditionTypeRolloutRestartDeferred)
+			updated = true
+		}
+	}
+
+	if !updated {
+		return deferAfter, nil
+	}
+
+	return deferAfter, c.Status().Update(ctx, o)
+}
+
+// DeferRotation returns true along with the duration after which o should be
+// requeued, if the non-critical rotation of o's secret should be deferred. The
+// rotation is not deferred if expiry, the expiry of the current secret, is set
+// and is before the end of the freeze window. It is safe to call on a nil
+// FreezeWindow.
+func (w *FreezeWindow) DeferRotation(ctx context.Context, c client.Client, kind ResourceKind,
+	o client.Object, expiry time.Time, recorder record.EventRecorder,
+) (time.Duration, bool) {
+	end, ok := w.Frozen(o)
+	if !ok {
+		return 0, false
+	}
+
+	logger := log.FromContext(ctx)
+	if !expiry.IsZero() && expiry.Before(end) {
+		logger.V(consts.LogLevelDebug).Info(
+			"Not deferring the rotation, the secret expires during the freeze window",
+			"expiry", expiry, "end", end)
+		return 0, false
+	}
+
+	metrics.IncFreezeWindowDeferred(metricsController(kind), metrics.FreezeWindowActionRotation)
+	deferAfter := deferUntil(end)
+	logger.V(consts.LogLevelDebug).Info("Deferring the rotation until the end of the freeze window",
+		"end", end, "deferAfter", deferAfter)
+
+	if w.setDeferredCondition(ctx, c, o,
+		newDeferredCondition(o, conditionTypeRotationDeferred, reasonFreezeWindow, end)) {
+		recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonRotationDeferred,
+			"Secret rotation deferred until the end of the freeze window at %s",
+			end.UTC().Format(time.RFC3339))
+	}
+
+	return deferAfter, true
+}
+
+// HandleRolloutRestarts triggers the rollout-restarts of o, see
+// helpers.HandleRolloutRestarts. If o is frozen, the rollout-restarts are
+// deferred until the end of the freeze window. Otherwise, if o is outside of
+// its rollout-restart windows, they are deferred until the start of its next
+// window. The duration after which o should be requeued is returned for
+// deferred rollout-restarts. It is safe to call on a nil FreezeWindow.
+func (w *FreezeWindow) HandleRolloutRestarts(ctx context.Context, c client.Client, validator helpers.HMACValidator,
+	kind ResourceKind, o client.Object, recorder record.EventRecorder,
+) time.Duration {
+	if hasRolloutRestartTargets(o) {
+		if end, ok := w.Frozen(o); ok {
+			metrics.IncFreezeWindowDeferred(metricsController(kind), metrics.FreezeWindowActionRolloutRestart)
+			if w.setDeferredCondition(ctx, c, o,
+				newDeferredCondition(o, conditionTypeRolloutRestartDeferred, reasonFreezeWindow, end)) {
+				recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonRolloutRestartDeferred,
+					"Rollout restart deferred until the end of the freeze window at %s",
+					end.UTC().Format(time.RFC3339))
+			}
+			return deferUntil(end)
+		}
+
+		if start, ok := nextRolloutRestartWindow(ctx, o, nowFunc()); ok {
+			if w.setDeferredCondition(ctx, c, o,
+				newDeferredCondition(o, conditionTypeRolloutRestartDeferred, reasonRolloutRestartWindow, start)) {
+				recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonRolloutRestartDeferred,
+					"Rollout restart deferred until the next rollout-restart window at %s",
+					start.UTC().Format(time.RFC3339))
+			}
+			return deferUntil(start)
+		}
+	}
+
+	// rollout-restart errors are not retryable
+	// all error reporting is handled by helpers.HandleRolloutRestarts
+	_ = helpers.HandleRolloutRestarts(ctx, c, validator, o, recorder)
+	return 0
+}
+
+// setDeferredCondition sets the deferred work condition cond on o, and updates
+// o's status. Returns true if the condition was not already set.
+func (w *FreezeWindow) setDeferredCondition(ctx context.Context, c client.Client, o client.Object,
+	cond metav1.Condition,
+) bool {
+	conditions := statusConditions(o)
+	if conditions == nil {
+		return false
+	}
+
+	if !replaceCondition(conditions, cond) {
+		return false
+	}
+
+	if err := c.Status().Update(ctx, o); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update the status", "conditionType", cond.Type)
+	}
+
+	return true
+}
+
+// newDeferredCondition returns the condition of the work of conditionType on o
+// that is deferred until end, for reason.
+func newDeferredCondition(o client.Object, conditionType, reason string, end time.Time) metav1.Condition {
+	var what string
+	switch conditionType {
+	case conditionTypeRotationDeferred:
+		what = "Secret rotation"
+	case conditionTypeRolloutRestartDeferred:
+		what = "Rollout restart"
+	}
+
+	until := "the end of the freeze window"
+	if reason == reasonRolloutRestartWindow {
+		until = "the next rollout-restart window"
+	}
+
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: o.GetGeneration(),
+		Reason:             reason,
+		Message:            fmt.Sprintf("%s deferred until %s at %s", what, until, end.UTC().Format(time.RFC3339)),
+	}
+}
+
+// replaceCondition replaces the condition of cond's type in conditions with
+// cond. Returns false if an identical condition was already set.
+func replaceCondition(conditions *[]metav1.Condition, cond metav1.Condition) bool {
+	for _, cur := range *conditions {
+		if cur.Type == cond.Type && cur.Reason == cond.Reason && cur.Message == cond.Message {
+			return false
+		}
+	}
+
+	*conditions = updateConditions(*conditions, append(removeConditions(*conditions, cond.Type), cond)...)
+	return true
+}
+
+// deferUntil returns the duration until end plus some jitter.
+func deferUntil(end time.Time) time.Duration {
+	_, jitter := computeMaxJitterWithPercent(freezeWindowMaxJitter, 1)
+	return end.Sub(nowFunc()) + time.Duration(jitter)
+}
+
+// minRequeueAfter returns the shortest of the requeue durations a and b, where
+// zero denotes no requeue.
+func minRequeueAfter(a, b time.Duration) time.Duration {
+	if a <= 0 {
+		return b
+	}
+	if b <= 0 {
+		return a
+	}
+	return min(a, b)
+}
+
+func statusConditions(o client.Object) *[]metav1.Condition {
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		return &t.Status.Conditions
+	case *secretsv1beta1.VaultDynamicSecret:
+		return &t.Status.Conditions
+	case *secretsv1beta1.VaultPKISecret:
+		return &t.Status.Conditions
+	case *secretsv1beta1.VaultSSHCertificate:
+		return &t.Status.Conditions
+	case *secretsv1beta1.VaultTransitKey:
+		return &t.Status.Conditions
+	case *secretsv1beta1.VaultSecretExport:
+		return &t.Status.Conditions
+	case *secretsv1beta1.HCPVaultSecretsApp:
+		return &t.Status.Conditions
+	default:
+		return nil
+	}
+}
+
+func hasRolloutRestartTargets(o client.Object) bool {
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		return len(t.Spec.RolloutRestartTargets) > 0
+	case *secretsv1beta1.VaultDynamicSecret:
+		return len(t.Spec.RolloutRestartTargets) > 0
+	case *secretsv1beta1.VaultPKISecret:
+		return len(t.Spec.RolloutRestartTargets) > 0
+	case *secretsv1beta1.VaultSSHCertificate:
+		return len(t.Spec.RolloutRestartTargets) > 0
+	case *secretsv1beta1.VaultTransitKey:
+		return len(t.Spec.RolloutRestartTargets) > 0
+	case *secretsv1beta1.HCPVaultSecretsApp:
+		return len(t.Spec.RolloutRestartTargets) > 0
+	default:
+		return false
+	}
+}
+
+func hasCondition(conditions []metav1.Condition, conditionType string) bool {
+	for _, cond := range conditions {
+		if cond.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// removeConditions returns conditions without those of conditionTypes.
+func removeConditions(conditions []metav1.Condition, conditionTypes ...string) []metav1.Condition {
+	var ret []metav1.Condition
+	for _, cond := range conditions {
+		if !slices.Contains(conditionTypes, cond.Type) {
+			ret = append(ret, cond)
+		}
+	}
+	return ret
+}
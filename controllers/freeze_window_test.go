@@ -0,0 +1,256 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/cron"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func newTestFreezeWindow(t *testing.T, expr string, d time.Duration, exempt string) *FreezeWindow {
+	t.Helper()
+
+	schedule, err := cron.Parse(expr)
+	require.NoError(t, err)
+	selector, err := labels.Parse(exempt)
+	require.NoError(t, err)
+	return &FreezeWindow{
+		Schedule:       schedule,
+		Duration:       d,
+		ExemptSelector: selector,
+	}
+}
+
+func TestFreezeWindow_End(t *testing.T) {
+	t.Parallel()
+
+	w := newTestFreezeWindow(t, "0 22 * * 5", 60*time.Hour, "")
+	start := time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		w      *FreezeWindow
+		now    time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "nil",
+			now:    start,
+			wantOK: false,
+		},
+		{
+			name:   "at-start",
+			w:      w,
+			now:    start,
+			want:   start.Add(60 * time.Hour),
+			wantOK: true,
+		},
+		{
+			name:   "within",
+			w:      w,
+			now:    start.Add(48 * time.Hour),
+			want:   start.Add(60 * time.Hour),
+			wantOK: true,
+		},
+		{
+			name:   "at-end",
+			w:      w,
+			now:    start.Add(60 * time.Hour),
+			wantOK: false,
+		},
+		{
+			name:   "before",
+			w:      w,
+			now:    start.Add(-time.Minute),
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tt.w.End(tt.now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "End() = %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func TestFreezeWindow_Frozen(t *testing.T) {
+	t.Parallel()
+
+	newObj := func(l map[string]string) *secretsv1beta1.VaultStaticSecret {
+		return &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "foo",
+				Labels:    l,
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		w    *FreezeWindow
+		o    client.Object
+		want bool
+	}{
+		{
+			name: "nil",
+			o:    newObj(nil),
+		},
+		{
+			name: "inactive",
+			w:    newTestFreezeWindow(t, "0 0 30 2 *", time.Hour, ""),
+			o:    newObj(nil),
+		},
+		{
+			name: "active",
+			w:    newTestFreezeWindow(t, "* * * * *", 2*time.Minute, ""),
+			o:    newObj(nil),
+			want: true,
+		},
+		{
+			name: "active-not-exempt",
+			w:    newTestFreezeWindow(t, "* * * * *", 2*time.Minute, "tier=critical"),
+			o:    newObj(map[string]string{"tier": "low"}),
+			want: true,
+		},
+		{
+			name: "active-exempt",
+			w:    newTestFreezeWindow(t, "* * * * *", 2*time.Minute, "tier=critical"),
+			o:    newObj(map[string]string{"tier": "critical"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			end, got := tt.w.Frozen(tt.o)
+			assert.Equal(t, tt.want, got)
+			if got {
+				assert.True(t, end.After(time.Now()))
+			} else {
+				assert.True(t, end.IsZero())
+			}
+		})
+	}
+}
+
+func TestFreezeWindow_DeferRotation(t *testing.T) {
+	ctx := context.Background()
+
+	w := newTestFreezeWindow(t, "* * * * *", 2*time.Minute, "")
+	o := &secretsv1beta1.VaultDynamicSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+	recorder := record.NewFakeRecorder(10)
+	counter := metrics.FreezeWindowDeferred.WithLabelValues(
+		metricsController(VaultDynamicSecret), metrics.FreezeWindowActionRotation)
+	before := metricValue(t, counter)
+
+	_, ok := w.DeferRotation(ctx, c, VaultDynamicSecret, o, time.Now().Add(time.Second), recorder)
+	assert.False(t, ok, "expected no deferral of a secret that expires during the window")
+	assert.Empty(t, o.Status.Conditions)
+
+	for i := 0; i < 2; i++ {
+		deferAfter, ok := w.DeferRotation(ctx, c, VaultDynamicSecret, o, time.Time{}, recorder)
+		require.True(t, ok)
+		assert.Greater(t, deferAfter, time.Duration(0))
+		assert.LessOrEqual(t, deferAfter, 2*time.Minute+freezeWindowMaxJitter)
+	}
+	assert.Equal(t, before+2, metricValue(t, counter))
+	assert.Len(t, recorder.Events, 1)
+
+	var got secretsv1beta1.VaultDynamicSecret
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeRotationDeferred, got.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, reasonFreezeWindow, got.Status.Conditions[0].Reason)
+
+	// the window has ended, the condition is cleared.
+	pendingAfter, err := (*FreezeWindow)(nil).HandlePending(ctx, c, nil, &got, recorder)
+	require.NoError(t, err)
+	assert.Zero(t, pendingAfter)
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	assert.Empty(t, got.Status.Conditions)
+}
+
+func TestFreezeWindow_HandleRolloutRestarts(t *testing.T) {
+	ctx := context.Background()
+
+	w := newTestFreezeWindow(t, "* * * * *", 2*time.Minute, "")
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "app",
+		},
+	}
+	o := &secretsv1beta1.VaultPKISecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+		Spec: secretsv1beta1.VaultPKISecretSpec{
+			RolloutRestartTargets: []secretsv1beta1.RolloutRestartTarget{
+				{
+					Kind: "Deployment",
+					Name: deployment.Name,
+				},
+			},
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(o, deployment).WithStatusSubresource(o).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	restartedAt := func() string {
+		t.Helper()
+		var d appsv1.Deployment
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(deployment), &d))
+		return d.Spec.Template.Annotations[helpers.AnnotationRestartedAt]
+	}
+
+	deferAfter := w.HandleRolloutRestarts(ctx, c, nil, VaultPKISecret, o, recorder)
+	assert.Greater(t, deferAfter, time.Duration(0))
+	assert.Empty(t, restartedAt())
+
+	var got secretsv1beta1.VaultPKISecret
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeRolloutRestartDeferred, got.Status.Conditions[0].Type)
+
+	// still frozen, the rollout-restart remains pending.
+	pendingAfter, err := w.HandlePending(ctx, c, nil, &got, recorder)
+	require.NoError(t, err)
+	assert.Greater(t, pendingAfter, time.Duration(0))
+	assert.Empty(t, restartedAt())
+
+	// the window has ended, the deferred rollout-restart is triggered.
+	pendingAfter, err = (*FreezeWindow)(nil).HandlePending(ctx, c, nil, &got, recorder)
+	require.NoError(t, err)
+	assert.Zero(t, pendingAfter)
+	assert.NotEmpty(t, restartedAt())
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	assert.Empty(t, got.Status.Conditions)
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/hashicorp/hcp-sdk-go/auth/workload"
 	hvsclient "github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/client/secret_service"
 	"github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/models"
 	hcpconfig "github.com/hashicorp/hcp-sdk-go/config"
@@ -31,7 +32,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/hashicorp/vault-secrets-operator/credentials"
 	"github.com/hashicorp/vault-secrets-operator/credentials/hcp"
@@ -88,6 +91,27 @@ type HCPVaultSecretsAppReconciler struct {
 	referenceCache              ResourceReferenceCache
 	GlobalTransformationOptions *helpers.GlobalTransformationOptions
 	BackOffRegistry             *BackOffRegistry
+	// Shedder defers the reconciliation of low priority resources under a large
+	// backlog, it is nil if load shedding is not enabled.
+	Shedder *ReconcileShedder
+	// FreezeWindow defers non-critical secret rotations and rollout-restarts
+	// during the freeze window, it is nil if no freeze window is configured.
+	FreezeWindow *FreezeWindow
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// StartupSyncSmear spreads the initial reconciliation of the resources over
+	// a window after the operator starts, it is nil if smearing is not enabled.
+	StartupSyncSmear *StartupSyncSmear
+	// SyncRegistry holds the resources whose consts.AnnotationForceSync
+	// annotation has changed, and that must be fully synced.
+	SyncRegistry *SyncRegistry
+	// SyncStatusRegistry maintains the aggregated sync status of all resources.
+	SyncStatusRegistry *SyncStatusRegistry
+	// SourceCh is used to trigger a requeue of resource instances from an
+	// external source, e.g. the HVSWebhookReceiver. Should be set on a
+	// source.Channel in SetupWithManager.
+	SourceCh chan event.GenericEvent
 }
 
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=hcpvaultsecretsapps,verbs=get;list;watch;create;update;patch;delete
@@ -100,6 +124,7 @@ type HCPVaultSecretsAppReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;patch
 //
 
 // Reconcile a secretsv1beta1.HCPVaultSecretsApp Custom Resource instance. Each
@@ -108,9 +133,16 @@ type HCPVaultSecretsAppReconciler struct {
 func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(HCPVaultSecretsApp, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
 	o := &secretsv1beta1.HCPVaultSecretsApp{}
 	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(HCPVaultSecretsApp, req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
@@ -123,6 +155,36 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, r.handleDeletion(ctx, o)
 	}
 
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil || suspended {
+		return ctrl.Result{}, err
+	}
+
+	if deferAfter, ok := r.Shedder.Shed(ctx, HCPVaultSecretsApp, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	if deferAfter, ok := r.StartupSyncSmear.Defer(ctx, HCPVaultSecretsApp, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	pendingAfter, err := r.FreezeWindow.HandlePending(ctx, r.Client, r.HMACValidator, o, r.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// forced syncs bypass the freeze window, the shadow secret cache, and the
+	// secret MAC check.
+	forceSync := r.SyncRegistry.Has(req.NamespacedName)
+	if _, frozen := r.FreezeWindow.Frozen(o); frozen && !forceSync && o.Status.LastGeneration == o.GetGeneration() {
+		// only the periodic syncs of existing secrets are deferred.
+		if exists, _ := helpers.CheckSecretExists(ctx, r.Client, o); exists {
+			if deferAfter, ok := r.FreezeWindow.DeferRotation(
+				ctx, r.Client, HCPVaultSecretsApp, o, hvsSecretsExpiry(o), r.Recorder); ok {
+				return ctrl.Result{RequeueAfter: deferAfter}, nil
+			}
+		}
+	}
+
 	var requeueAfter time.Duration
 	if o.Spec.RefreshAfter != "" {
 		d, err := parseDurationString(o.Spec.RefreshAfter, ".spec.refreshAfter", r.MinRefreshAfter)
@@ -144,7 +206,7 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
 	}
 
-	c, err := r.hvsClient(ctx, o)
+	c, err := newHVSClient(ctx, r.Client, o)
 	if err != nil {
 		logger.Error(err, "Get HCP Vault Secrets Client")
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSClientConfigError,
@@ -168,21 +230,25 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		logger.Error(err, "Get App Secrets", "appName", o.Spec.AppName)
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSSecret,
 			"Failed to get HVS App secrets: %s", err)
-		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName, syncBackoffOpts(ctx, o)...)
 		return ctrl.Result{
-			RequeueAfter: entry.NextBackOff(),
+			RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
 		}, nil
 	}
 
-	// Get shadowed dynamic secret data (if any)
-	shadowSecrets, err := r.getShadowSecretData(ctx, o)
-	if err != nil {
-		// If we can't get the shadow secret data, log a warning and proceed
-		// without retrying since user intervention would be required to fix it
-		// and the shadow secret will just be recreated at the end of
-		// Reconcile().
-		logger.V(consts.LogLevelWarning).Info("Failed to get shadow secret data, proceeding without shadow cache",
-			"appName", o.Spec.AppName, "err", err)
+	// Get shadowed dynamic secret data (if any), forced syncs always fetch fresh
+	// dynamic secrets.
+	var shadowSecrets map[string]*models.Secrets20231128OpenSecret
+	if !forceSync {
+		shadowSecrets, err = r.getShadowSecretData(ctx, o)
+		if err != nil {
+			// If we can't get the shadow secret data, log a warning and proceed
+			// without retrying since user intervention would be required to fix it
+			// and the shadow secret will just be recreated at the end of
+			// Reconcile().
+			logger.V(consts.LogLevelWarning).Info("Failed to get shadow secret data, proceeding without shadow cache",
+				"appName", o.Spec.AppName, "err", err)
+		}
 	}
 
 	renewPercent := getDynamicRenewPercent(o.Spec.SyncConfig)
@@ -191,9 +257,9 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		logger.Error(err, "Get Dynamic Secrets", "appName", o.Spec.AppName)
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSSecret,
 			"Failed to get HVS dynamic secrets: %s", err)
-		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName, syncBackoffOpts(ctx, o)...)
 		return ctrl.Result{
-			RequeueAfter: entry.NextBackOff(),
+			RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
 		}, nil
 	}
 	// Add the dynamic secrets to the OpenAppSecrets response to be processed
@@ -204,7 +270,7 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	// Remove this app from the backoff registry now that we're done with HVS
 	// API calls
-	r.BackOffRegistry.Delete(req.NamespacedName)
+	resetBackOff(r.BackOffRegistry, o)
 
 	o.Status.DynamicSecrets = dynamicSecrets.statuses
 
@@ -222,11 +288,29 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
+	// Refresh the rotating secrets before their current version expires, rather
+	// than waiting for the next `requeueAfter`.
+	rotatingSecrets := getHVSRotatingSecrets(resp.Payload.Secrets,
+		getRotatingRenewPercent(o.Spec.SyncConfig), time.Now())
+	o.Status.RotatingSecrets = rotatingSecrets.statuses
+	if rotatingSecrets.nextRenewal.timeToNextRenewal > 0 {
+		_, j := computeMaxJitter(rotatingSecrets.nextRenewal.ttl)
+		nextRotatingRequeue := rotatingSecrets.nextRenewal.timeToNextRenewal + time.Duration(j)
+
+		if requeueAfter == 0 || nextRotatingRequeue < requeueAfter {
+			logger.V(consts.LogLevelTrace).Info("Setting requeueAfter to the next rotating secret refresh time",
+				"appName", o.Spec.AppName, "requeueAfter", requeueAfter,
+				"nextRotatingRequeue", nextRotatingRequeue)
+			requeueAfter = nextRotatingRequeue
+		}
+	}
+
 	r.referenceCache.Set(SecretTransformation, req.NamespacedName,
 		helpers.GetTransformationRefObjKeys(
-			o.Spec.Destination.Transformation, o.Namespace)...)
+			o.Spec.Destination.Transformation, o.Namespace, r.GlobalTransformationOptions)...)
 
 	data, err := r.SecretDataBuilder.WithHVSAppSecrets(resp, transOption)
+	renderErr, err := handleTemplateRenderError(ctx, r.Client, o, data, err)
 	if err != nil {
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretDataBuilderError,
 			"Failed to build K8s secret data: %s", err)
@@ -235,10 +319,13 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
 		}, nil
 	}
+	if renderErr != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values for keys that failed to render: %s", renderErr)
+	}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), transOption, renderErr)
 
 	doSync := true
-	// doRolloutRestart only if this is not the first time this secret has been synced
-	doRolloutRestart := o.Status.SecretMAC != ""
 	macsEqual, messageMAC, err := helpers.HandleSecretHMAC(ctx, r.Client, r.HMACValidator, o, data)
 	if err != nil {
 		return ctrl.Result{
@@ -246,17 +333,25 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}, nil
 	}
 
+	// doRolloutRestart only if this is not the first time this secret has been
+	// synced, and the sync was not only forced.
+	doRolloutRestart := o.Status.SecretMAC != "" && (!macsEqual || o.Status.LastGeneration != o.GetGeneration())
+
 	// skip the next sync if the data has not changed since the last sync, and the
-	// resource has not been updated.
+	// resource has not been updated, nor forced to sync.
 	// Note: spec.status.lastGeneration was added later, so we don't want to force a
 	// sync until we've updated it.
-	if o.Status.LastGeneration == 0 || o.Status.LastGeneration == o.GetGeneration() {
+	if (o.Status.LastGeneration == 0 || o.Status.LastGeneration == o.GetGeneration()) && !forceSync {
 		doSync = !macsEqual
 	}
 
 	o.Status.SecretMAC = base64.StdEncoding.EncodeToString(messageMAC)
 	if doSync {
-		if err := helpers.SyncSecret(ctx, r.Client, o, data); err != nil {
+		opts := helpers.DefaultSyncOptions()
+		opts.HMACValidator = r.HMACValidator
+		err := helpers.SyncSecret(ctx, r.Client, o, data, opts)
+		handleSecretDataError(ctx, r.Client, o, err)
+		if err != nil {
 			r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
 				"Failed to update k8s secret: %s", err)
 			return ctrl.Result{}, err
@@ -264,9 +359,8 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 		reason := consts.ReasonSecretSynced
 		if doRolloutRestart {
 			reason = consts.ReasonSecretRotated
-			// rollout-restart errors are not retryable
-			// all error reporting is handled by helpers.HandleRolloutRestarts
-			_ = helpers.HandleRolloutRestarts(ctx, r.Client, o, r.Recorder)
+			pendingAfter = minRequeueAfter(pendingAfter,
+				r.FreezeWindow.HandleRolloutRestarts(ctx, r.Client, r.HMACValidator, HCPVaultSecretsApp, o, r.Recorder))
 		}
 		if err := r.storeShadowSecretData(ctx, o, dynamicSecrets.secrets); err != nil {
 			r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
@@ -274,20 +368,52 @@ func (r *HCPVaultSecretsAppReconciler) Reconcile(ctx context.Context, req ctrl.R
 				o.Spec.AppName, err)
 			return ctrl.Result{}, nil
 		}
+		if forceSync {
+			reason = consts.ReasonForceSync
+		}
 		r.Recorder.Event(o, corev1.EventTypeNormal, reason, "Secret synced")
 	} else {
 		r.Recorder.Event(o, corev1.EventTypeNormal, consts.ReasonSecretSync, "Secret sync not required")
 	}
 
+	if ok := r.SyncRegistry.Delete(req.NamespacedName); ok {
+		logger.V(consts.LogLevelDebug).Info("Deleted object from SyncRegistry",
+			"obj", req.NamespacedName)
+	}
+
 	if err := r.updateStatus(ctx, o); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{
-		RequeueAfter: requeueAfter,
+		RequeueAfter: minRequeueAfter(requeueAfter, pendingAfter),
 	}, nil
 }
 
+// hvsSecretsExpiry returns the earliest expiry of o's dynamic and rotating
+// secrets, it is zero if o has neither.
+func hvsSecretsExpiry(o *secretsv1beta1.HCPVaultSecretsApp) time.Time {
+	expiresAt := make([]string, 0, len(o.Status.DynamicSecrets)+len(o.Status.RotatingSecrets))
+	for _, s := range o.Status.DynamicSecrets {
+		expiresAt = append(expiresAt, s.ExpiresAt)
+	}
+	for _, s := range o.Status.RotatingSecrets {
+		expiresAt = append(expiresAt, s.ExpiresAt)
+	}
+
+	var expiry time.Time
+	for _, v := range expiresAt {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			continue
+		}
+		if expiry.IsZero() || t.Before(expiry) {
+			expiry = t
+		}
+	}
+	return expiry
+}
+
 func (r *HCPVaultSecretsAppReconciler) updateStatus(ctx context.Context, o *secretsv1beta1.HCPVaultSecretsApp) error {
 	o.Status.LastGeneration = o.GetGeneration()
 	if err := r.Status().Update(ctx, o); err != nil {
@@ -301,14 +427,19 @@ func (r *HCPVaultSecretsAppReconciler) updateStatus(ctx context.Context, o *secr
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *HCPVaultSecretsAppReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(HCPVaultSecretsApp, r.Recorder)
 	r.referenceCache = newResourceReferenceCache()
 	if r.BackOffRegistry == nil {
 		r.BackOffRegistry = NewBackOffRegistry()
 	}
+	if r.SyncRegistry == nil {
+		r.SyncRegistry = NewSyncRegistry()
+	}
+	r.SourceCh = newSourceChannel()
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&secretsv1beta1.HCPVaultSecretsApp{}).
-		WithEventFilter(syncableSecretPredicate(nil)).
+		WithEventFilter(forceSyncableSecretPredicate(r.SyncRegistry)).
 		WithOptions(opts).
 		Watches(
 			&secretsv1beta1.SecretTransformation{},
@@ -326,34 +457,58 @@ func (r *HCPVaultSecretsAppReconciler) SetupWithManager(mgr ctrl.Manager, opts c
 			},
 			builder.WithPredicates(&secretsPredicate{}),
 		).
-		Complete(r)
+		// Enforced destination Secrets are resynced as soon as their data drifts, the
+		// data is only fetched for the Secrets that were not updated by the operator.
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDriftRequestHandler{
+				client:    r.Client,
+				validator: r.HMACValidator,
+				recorder:  r.Recorder,
+				kind:      HCPVaultSecretsApp,
+			},
+			builder.WithPredicates(&secretsDriftPredicate{}),
+		).
+		WatchesRawSource(
+			source.Channel(r.SourceCh,
+				&enqueueDelayingSyncEventHandler{
+					enqueueDurationForJitter: time.Second * 2,
+					kind:                     HCPVaultSecretsApp,
+				},
+			),
+		).
+		Complete(r.SyncStatusRegistry.Reconciler(HCPVaultSecretsApp, r))
 }
 
-func (r *HCPVaultSecretsAppReconciler) hvsClient(ctx context.Context, o *secretsv1beta1.HCPVaultSecretsApp) (hvsclient.ClientService, error) {
-	authObj, err := common.GetHCPAuthForObj(ctx, r.Client, o)
+// newHVSClient returns an HVS client authenticated with the HCPAuth that is
+// referenced by o.
+func newHVSClient(ctx context.Context, c client.Client, o client.Object) (hvsclient.ClientService, error) {
+	authObj, err := common.GetHCPAuthForObj(ctx, c, o)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get HCPAuth, err=%w", err)
 	}
 
-	p, err := credentials.NewCredentialProvider(ctx, r.Client, authObj, o.Namespace)
+	p, err := credentials.NewCredentialProvider(ctx, c, authObj, o.GetNamespace())
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup CredentialProvider, err=%w", err)
 	}
 
-	creds, err := p.GetCreds(ctx, r.Client)
+	creds, err := p.GetCreds(ctx, c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get creds from CredentialProvider, err=%w", err)
 	}
 
+	credsOption, err := hcpCredentialsOption(authObj.Spec.Method, creds)
+	if err != nil {
+		return nil, err
+	}
+
 	hcpConfig, err := hcpconfig.NewHCPConfig(
 		hcpconfig.WithProfile(&profile.UserProfile{
 			OrganizationID: authObj.Spec.OrganizationID,
 			ProjectID:      authObj.Spec.ProjectID,
 		}),
-		hcpconfig.WithClientCredentials(
-			creds[hcp.ProviderSecretClientID].(string),
-			creds[hcp.ProviderSecretClientSecret].(string),
-		),
+		credsOption,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to instantiate HCP Config, err=%w", err)
@@ -371,11 +526,37 @@ func (r *HCPVaultSecretsAppReconciler) hvsClient(ctx context.Context, o *secrets
 	return hvsclient.New(cl, nil), nil
 }
 
+// hcpCredentialsOption returns the HCP config option that authenticates with
+// the creds of the HCPAuth method.
+func hcpCredentialsOption(method string, creds map[string]any) (hcpconfig.HCPConfigOption, error) {
+	switch method {
+	case hcp.ProviderMethodServicePrincipal:
+		return hcpconfig.WithClientCredentials(
+			creds[hcp.ProviderSecretClientID].(string),
+			creds[hcp.ProviderSecretClientSecret].(string),
+		), nil
+	case hcp.ProviderMethodWorkloadIdentity:
+		return hcpconfig.WithWorkloadIdentity(&workload.IdentityProviderConfig{
+			ProviderResourceName: creds[hcp.ProviderCredsProviderResourceName].(string),
+			Token: &workload.CredentialTokenSource{
+				Token: creds[hcp.ProviderCredsToken].(string),
+			},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported HCP authentication method %s", method)
+	}
+}
+
 func (r *HCPVaultSecretsAppReconciler) handleDeletion(ctx context.Context, o client.Object) error {
 	logger := log.FromContext(ctx)
+	if err := finalizeDestinationSecrets(ctx, r.Client, r.Recorder, o, hcpVaultSecretsAppFinalizer); err != nil {
+		return err
+	}
+
 	objKey := client.ObjectKeyFromObject(o)
 	r.referenceCache.Remove(SecretTransformation, objKey)
 	r.BackOffRegistry.Delete(objKey)
+	r.SyncRegistry.Delete(objKey)
 	shadowObjKey := makeShadowObjKey(o)
 	if err := helpers.DeleteSecret(ctx, r.Client, shadowObjKey); err != nil {
 		logger.Error(err, "Failed to delete shadow secret", "shadow secret", shadowObjKey)
@@ -712,6 +893,66 @@ func getDynamicRenewPercent(syncConfig *secretsv1beta1.HVSSyncConfig) int {
 	return capRenewalPercent(renewPercent)
 }
 
+// getRotatingRenewPercent returns the HVSSyncConfig rotating renewal percent or
+// the default renewal percent in that order of precendence
+func getRotatingRenewPercent(syncConfig *secretsv1beta1.HVSSyncConfig) int {
+	renewPercent := defaultDynamicRenewPercent
+	if syncConfig != nil && syncConfig.Rotating != nil && syncConfig.Rotating.RenewalPercent != 0 {
+		renewPercent = syncConfig.Rotating.RenewalPercent
+	}
+	return capRenewalPercent(renewPercent)
+}
+
+type hvsRotatingSecretResult struct {
+	nextRenewal nextRenewalDetails
+	statuses    []secretsv1beta1.HVSRotatingStatus
+}
+
+// getHVSRotatingSecrets returns the statuses of the rotating secrets among
+// secrets, and the details of their next refresh. A rotating secret is
+// refreshed once renewPercent of its current version's lifetime has elapsed,
+// or when the version expires if it has not been rotated by then. Expired
+// versions are left to the regular refresh.
+func getHVSRotatingSecrets(secrets []*models.Secrets20231128OpenSecret, renewPercent int, now time.Time) hvsRotatingSecretResult {
+	var result hvsRotatingSecretResult
+	for _, s := range secrets {
+		if s == nil || s.Type != helpers.HVSSecretTypeRotating || s.RotatingVersion == nil {
+			continue
+		}
+
+		v := s.RotatingVersion
+		status := secretsv1beta1.HVSRotatingStatus{
+			Name:    s.Name,
+			Version: v.Version,
+		}
+		if !time.Time(v.CreatedAt).IsZero() {
+			status.CreatedAt = v.CreatedAt.String()
+		}
+		if !time.Time(v.ExpiresAt).IsZero() {
+			status.ExpiresAt = v.ExpiresAt.String()
+		}
+		result.statuses = append(result.statuses, status)
+
+		createdAt, expiresAt := time.Time(v.CreatedAt), time.Time(v.ExpiresAt)
+		if createdAt.IsZero() || !expiresAt.After(createdAt) || !now.Before(expiresAt) {
+			continue
+		}
+
+		ttl := expiresAt.Sub(createdAt)
+		timeToNextRenewal := createdAt.Add(ttl * time.Duration(renewPercent) / 100).Sub(now)
+		if timeToNextRenewal <= 0 {
+			timeToNextRenewal = expiresAt.Sub(now)
+		}
+		if result.nextRenewal.timeToNextRenewal == 0 || timeToNextRenewal < result.nextRenewal.timeToNextRenewal {
+			result.nextRenewal = nextRenewalDetails{
+				timeToNextRenewal: timeToNextRenewal,
+				ttl:               ttl,
+			}
+		}
+	}
+	return result
+}
+
 func makeHVSDynamicStatus(secret *models.Secrets20231128OpenSecret) secretsv1beta1.HVSDynamicStatus {
 	status := secretsv1beta1.HVSDynamicStatus{
 		Name: secret.Name,
@@ -32,6 +32,7 @@ type fakeHVSTransportOpts struct {
 	openSecretResponses  []*hvsclient.OpenAppSecretOK
 	openSecretsResponses []*hvsclient.OpenAppSecretsOK
 	listSecretsResponses []*hvsclient.ListAppSecretsOK
+	listAppsResponses    []*hvsclient.ListAppsOK
 }
 
 // fakeHVSTransport is used to fake responses from HVS in tests.
@@ -40,8 +41,10 @@ type fakeHVSTransport struct {
 	openSecretResponses  []*hvsclient.OpenAppSecretOK
 	openSecretsResponses []*hvsclient.OpenAppSecretsOK
 	listSecretsResponses []*hvsclient.ListAppSecretsOK
+	listAppsResponses    []*hvsclient.ListAppsOK
 	lastOpenSecretsIdx   int
 	lastListSecretsIdx   int
+	lastListAppsIdx      int
 	numRequests          int
 }
 
@@ -61,6 +64,17 @@ func (f *fakeHVSTransport) Submit(operation *runtime.ClientOperation) (any, erro
 		resp := f.listSecretsResponses[f.lastListSecretsIdx]
 		f.lastListSecretsIdx++
 		return resp, nil
+	case "ListApps":
+		if f.lastListAppsIdx >= len(f.listAppsResponses) {
+			return &hvsclient.ListAppsOK{
+				Payload: &models.Secrets20231128ListAppsResponse{
+					Pagination: nil,
+				},
+			}, nil
+		}
+		resp := f.listAppsResponses[f.lastListAppsIdx]
+		f.lastListAppsIdx++
+		return resp, nil
 	case "OpenAppSecrets":
 		if f.lastOpenSecretsIdx >= len(f.openSecretsResponses) {
 			return &hvsclient.OpenAppSecretsOK{
@@ -96,6 +110,7 @@ func newFakeHVSTransportWithOpts(t *testing.T, opt *fakeHVSTransportOpts) *fakeH
 		p.openSecretResponses = opt.openSecretResponses
 		p.openSecretsResponses = opt.openSecretsResponses
 		p.listSecretsResponses = opt.listSecretsResponses
+		p.listAppsResponses = opt.listAppsResponses
 	}
 	return p
 }
@@ -708,6 +723,193 @@ func Test_makeDynamicRenewPercent(t *testing.T) {
 	}
 }
 
+func Test_getHVSRotatingSecrets(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Truncate(time.Second)
+	rotating := func(name string, version int64, createdAt, expiresAt time.Time) *models.Secrets20231128OpenSecret {
+		return &models.Secrets20231128OpenSecret{
+			Name: name,
+			Type: helpers.HVSSecretTypeRotating,
+			RotatingVersion: &models.Secrets20231128OpenSecretRotatingVersion{
+				CreatedAt: strfmt.DateTime(createdAt),
+				ExpiresAt: strfmt.DateTime(expiresAt),
+				Version:   version,
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		secrets      []*models.Secrets20231128OpenSecret
+		renewPercent int
+		expected     hvsRotatingSecretResult
+	}{
+		"no rotating secrets": {
+			secrets: []*models.Secrets20231128OpenSecret{
+				{Name: "kv", Type: helpers.HVSSecretTypeKV},
+			},
+			renewPercent: defaultDynamicRenewPercent,
+			expected:     hvsRotatingSecretResult{},
+		},
+		"before renewal point": {
+			secrets: []*models.Secrets20231128OpenSecret{
+				{Name: "kv", Type: helpers.HVSSecretTypeKV},
+				rotating("rotating", 2, now.Add(-10*time.Minute), now.Add(90*time.Minute)),
+			},
+			renewPercent: 50,
+			expected: hvsRotatingSecretResult{
+				nextRenewal: nextRenewalDetails{
+					timeToNextRenewal: 40 * time.Minute,
+					ttl:               100 * time.Minute,
+				},
+				statuses: []secretsv1beta1.HVSRotatingStatus{
+					{
+						Name:      "rotating",
+						Version:   2,
+						CreatedAt: strfmt.DateTime(now.Add(-10 * time.Minute)).String(),
+						ExpiresAt: strfmt.DateTime(now.Add(90 * time.Minute)).String(),
+					},
+				},
+			},
+		},
+		"past renewal point": {
+			secrets: []*models.Secrets20231128OpenSecret{
+				rotating("rotating", 3, now.Add(-80*time.Minute), now.Add(20*time.Minute)),
+			},
+			renewPercent: 50,
+			expected: hvsRotatingSecretResult{
+				nextRenewal: nextRenewalDetails{
+					timeToNextRenewal: 20 * time.Minute,
+					ttl:               100 * time.Minute,
+				},
+				statuses: []secretsv1beta1.HVSRotatingStatus{
+					{
+						Name:      "rotating",
+						Version:   3,
+						CreatedAt: strfmt.DateTime(now.Add(-80 * time.Minute)).String(),
+						ExpiresAt: strfmt.DateTime(now.Add(20 * time.Minute)).String(),
+					},
+				},
+			},
+		},
+		"earliest renewal": {
+			secrets: []*models.Secrets20231128OpenSecret{
+				rotating("hourly", 1, now, now.Add(time.Hour)),
+				rotating("daily", 1, now, now.Add(24*time.Hour)),
+			},
+			renewPercent: 50,
+			expected: hvsRotatingSecretResult{
+				nextRenewal: nextRenewalDetails{
+					timeToNextRenewal: 30 * time.Minute,
+					ttl:               time.Hour,
+				},
+				statuses: []secretsv1beta1.HVSRotatingStatus{
+					{
+						Name:      "hourly",
+						Version:   1,
+						CreatedAt: strfmt.DateTime(now).String(),
+						ExpiresAt: strfmt.DateTime(now.Add(time.Hour)).String(),
+					},
+					{
+						Name:      "daily",
+						Version:   1,
+						CreatedAt: strfmt.DateTime(now).String(),
+						ExpiresAt: strfmt.DateTime(now.Add(24 * time.Hour)).String(),
+					},
+				},
+			},
+		},
+		"expired": {
+			secrets: []*models.Secrets20231128OpenSecret{
+				rotating("rotating", 4, now.Add(-2*time.Hour), now.Add(-time.Hour)),
+			},
+			renewPercent: defaultDynamicRenewPercent,
+			expected: hvsRotatingSecretResult{
+				statuses: []secretsv1beta1.HVSRotatingStatus{
+					{
+						Name:      "rotating",
+						Version:   4,
+						CreatedAt: strfmt.DateTime(now.Add(-2 * time.Hour)).String(),
+						ExpiresAt: strfmt.DateTime(now.Add(-time.Hour)).String(),
+					},
+				},
+			},
+		},
+		"no expiry": {
+			secrets: []*models.Secrets20231128OpenSecret{
+				{
+					Name: "rotating",
+					Type: helpers.HVSSecretTypeRotating,
+					RotatingVersion: &models.Secrets20231128OpenSecretRotatingVersion{
+						Version: 5,
+					},
+				},
+			},
+			renewPercent: defaultDynamicRenewPercent,
+			expected: hvsRotatingSecretResult{
+				statuses: []secretsv1beta1.HVSRotatingStatus{
+					{
+						Name:    "rotating",
+						Version: 5,
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := getHVSRotatingSecrets(tc.secrets, tc.renewPercent, now)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_getRotatingRenewPercent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		syncConfig *secretsv1beta1.HVSSyncConfig
+		expected   int
+	}{
+		"syncConfig is nil": {
+			syncConfig: nil,
+			expected:   defaultDynamicRenewPercent,
+		},
+		"syncConfig.Rotating is nil": {
+			syncConfig: &secretsv1beta1.HVSSyncConfig{
+				Dynamic: &secretsv1beta1.HVSDynamicSyncConfig{
+					RenewalPercent: 42,
+				},
+			},
+			expected: defaultDynamicRenewPercent,
+		},
+		"syncConfig.Rotating not nil": {
+			syncConfig: &secretsv1beta1.HVSSyncConfig{
+				Rotating: &secretsv1beta1.HVSRotatingSyncConfig{
+					RenewalPercent: 42,
+				},
+			},
+			expected: 42,
+		},
+		"syncConfig.Rotating.RenewalPercent is over 90": {
+			syncConfig: &secretsv1beta1.HVSSyncConfig{
+				Rotating: &secretsv1beta1.HVSRotatingSyncConfig{
+					RenewalPercent: 91,
+				},
+			},
+			expected: 90,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := getRotatingRenewPercent(tc.syncConfig)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
 func Test_fetchOpenSecretsPaginated(t *testing.T) {
 	t.Parallel()
 
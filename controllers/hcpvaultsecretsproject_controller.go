@@ -0,0 +1,399 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	hvsclient "github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/client/secret_service"
+	"github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/models"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+// hcpVaultSecretsProjectLabel is set on each HCPVaultSecretsApp that is
+// managed by an HCPVaultSecretsProject, its value is the project's name.
+var hcpVaultSecretsProjectLabel = fmt.Sprintf("%s.%s/name", "hcpvaultsecretsprojects",
+	secretsv1beta1.GroupVersion.Group)
+
+// hvsClientFunc returns an HVS client for the HCPAuth referenced by o.
+type hvsClientFunc func(context.Context, client.Client, client.Object) (hvsclient.ClientService, error)
+
+// HCPVaultSecretsProjectReconciler reconciles a HCPVaultSecretsProject object
+type HCPVaultSecretsProjectReconciler struct {
+	client.Client
+	Scheme          *runtime.Scheme
+	Recorder        record.EventRecorder
+	MinRefreshAfter time.Duration
+	BackOffRegistry *BackOffRegistry
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// newHVSClient is used by tests to fake the HVS API.
+	newHVSClient hvsClientFunc
+}
+
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=hcpvaultsecretsprojects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=hcpvaultsecretsprojects/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=hcpvaultsecretsapps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//
+
+// Reconcile a secretsv1beta1.HCPVaultSecretsProject Custom Resource instance.
+// Each invocation lists the HCP Vault Secrets Apps in the project, and ensures
+// that every App matching the resource's AppNames is synced by an
+// HCPVaultSecretsApp that is owned by the resource. HCPVaultSecretsApps of Apps
+// that no longer match are deleted.
+func (r *HCPVaultSecretsProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		return ctrl.Result{}, nil
+	}
+
+	o := &secretsv1beta1.HCPVaultSecretsProject{}
+	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.BackOffRegistry.Delete(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "error getting resource from k8s", "obj", o)
+		return ctrl.Result{}, err
+	}
+
+	if o.GetDeletionTimestamp() != nil {
+		// the owned HCPVaultSecretsApps are garbage collected by K8s.
+		r.BackOffRegistry.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	var requeueAfter time.Duration
+	if o.Spec.RefreshAfter != "" {
+		d, err := parseDurationString(o.Spec.RefreshAfter, ".spec.refreshAfter", r.MinRefreshAfter)
+		if err != nil {
+			logger.Error(err, "Field validation failed")
+			r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSProjectError,
+				"Field validation failed, err=%s", err)
+			return ctrl.Result{}, err
+		}
+		if d.Seconds() > 0 {
+			requeueAfter = computeHorizonWithJitter(d)
+		}
+	}
+
+	newClient := r.newHVSClient
+	if newClient == nil {
+		newClient = newHVSClient
+	}
+
+	c, err := newClient(ctx, r.Client, o)
+	if err != nil {
+		logger.Error(err, "Get HCP Vault Secrets Client")
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSClientConfigError,
+			"Failed to instantiate HVS client: %s", err)
+		return r.updateStatusOnError(ctx, o, err, computeHorizonWithJitter(requeueDurationOnError))
+	}
+
+	resp, err := listAppsPaginated(ctx, c, &hvsclient.ListAppsParams{
+		Context: ctx,
+	}, func(app *models.Secrets20231128App) bool {
+		return matchAppName(o.Spec.AppNames, app.Name)
+	})
+	if err != nil {
+		logger.Error(err, "List HCP Vault Secrets Apps")
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSProjectError,
+			"Failed to list HVS apps: %s", err)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		return r.updateStatusOnError(ctx, o, err, entry.NextBackOff())
+	}
+	r.BackOffRegistry.Delete(req.NamespacedName)
+
+	var appNames []string
+	for _, app := range resp.Payload.Apps {
+		appNames = append(appNames, app.Name)
+	}
+
+	apps, err := r.syncApps(ctx, o, appNames)
+	o.Status.Apps = apps
+	if err != nil {
+		logger.Error(err, "Sync HCP Vault Secrets Apps")
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonHVSProjectError,
+			"Failed to sync HVS apps: %s", err)
+		return r.updateStatusOnError(ctx, o, err, computeHorizonWithJitter(requeueDurationOnError))
+	}
+
+	r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonHVSProjectAppsSynced,
+		"Synced %d HVS apps", len(apps))
+
+	o.Status.Error = ""
+	if err := r.updateStatus(ctx, o); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{
+		RequeueAfter: requeueAfter,
+	}, nil
+}
+
+// syncApps ensures that an HCPVaultSecretsApp exists for each of the
+// appNames, and deletes those that are no longer needed. It returns the Apps
+// that are synced.
+func (r *HCPVaultSecretsProjectReconciler) syncApps(ctx context.Context,
+	o *secretsv1beta1.HCPVaultSecretsProject, appNames []string,
+) ([]secretsv1beta1.HCPVaultSecretsProjectApp, error) {
+	logger := log.FromContext(ctx).WithName("syncApps")
+
+	slices.Sort(appNames)
+	var errs error
+	var apps []secretsv1beta1.HCPVaultSecretsProjectApp
+	want := make(map[string]bool)
+	for _, appName := range appNames {
+		desired, err := makeProjectHCPVaultSecretsApp(o, appName)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		want[desired.Name] = true
+		obj := &secretsv1beta1.HCPVaultSecretsApp{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      desired.Name,
+				Namespace: desired.Namespace,
+			},
+		}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+			if obj.ResourceVersion == "" {
+				obj.Labels = desired.Labels
+			} else if !metav1.IsControlledBy(obj, o) {
+				return fmt.Errorf("HCPVaultSecretsApp %s is not owned by HCPVaultSecretsProject %s",
+					client.ObjectKeyFromObject(obj), client.ObjectKeyFromObject(o))
+			}
+			if obj.Labels == nil {
+				obj.Labels = map[string]string{}
+			}
+			obj.Labels[hcpVaultSecretsProjectLabel] = o.Name
+			obj.Spec = desired.Spec
+			return controllerutil.SetControllerReference(o, obj, r.Scheme)
+		})
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		logger.V(consts.LogLevelDebug).Info("Synced HCPVaultSecretsApp",
+			"appName", appName, "name", obj.Name, "operation", op)
+		apps = append(apps, secretsv1beta1.HCPVaultSecretsProjectApp{
+			AppName:     appName,
+			Name:        obj.Name,
+			Destination: obj.Spec.Destination.Name,
+		})
+	}
+
+	var owned secretsv1beta1.HCPVaultSecretsAppList
+	if err := r.Client.List(ctx, &owned, client.InNamespace(o.Namespace),
+		client.MatchingLabels{hcpVaultSecretsProjectLabel: o.Name},
+	); err != nil {
+		return apps, errors.Join(errs, err)
+	}
+
+	for _, item := range owned.Items {
+		if want[item.Name] || !metav1.IsControlledBy(&item, o) {
+			continue
+		}
+
+		logger.V(consts.LogLevelDebug).Info("Deleting HCPVaultSecretsApp",
+			"appName", item.Spec.AppName, "name", item.Name)
+		if err := r.Client.Delete(ctx, &item); client.IgnoreNotFound(err) != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return apps, errs
+}
+
+func (r *HCPVaultSecretsProjectReconciler) updateStatusOnError(ctx context.Context,
+	o *secretsv1beta1.HCPVaultSecretsProject, err error, requeueAfter time.Duration,
+) (ctrl.Result, error) {
+	o.Status.Error = err.Error()
+	if err := r.updateStatus(ctx, o); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *HCPVaultSecretsProjectReconciler) updateStatus(ctx context.Context, o *secretsv1beta1.HCPVaultSecretsProject) error {
+	o.Status.LastGeneration = o.GetGeneration()
+	if err := r.Status().Update(ctx, o); err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonStatusUpdateError,
+			"Failed to update the resource's status, err=%s", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HCPVaultSecretsProjectReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	if r.BackOffRegistry == nil {
+		r.BackOffRegistry = NewBackOffRegistry()
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&secretsv1beta1.HCPVaultSecretsProject{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(opts).
+		// ensure that a deleted HCPVaultSecretsApp is recreated.
+		Owns(&secretsv1beta1.HCPVaultSecretsApp{},
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(event.CreateEvent) bool { return false },
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+			})).
+		Complete(r)
+}
+
+// makeProjectHCPVaultSecretsApp returns the desired HCPVaultSecretsApp that
+// syncs the App appName of the HCPVaultSecretsProject o.
+func makeProjectHCPVaultSecretsApp(o *secretsv1beta1.HCPVaultSecretsProject, appName string) (*secretsv1beta1.HCPVaultSecretsApp, error) {
+	name := fmt.Sprintf("%s-%s", o.Name, appName)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid HCPVaultSecretsApp name %q for app %q: %s",
+			name, appName, strings.Join(errs, ", "))
+	}
+
+	destName, err := renderProjectDestinationName(o.Spec.AppTemplate.Destination.Name, appName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination name for app %q: %w", appName, err)
+	}
+
+	tmpl := o.Spec.AppTemplate.DeepCopy()
+	dest := tmpl.Destination
+	dest.Name = destName
+
+	return &secretsv1beta1.HCPVaultSecretsApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: o.Namespace,
+			Labels: map[string]string{
+				hcpVaultSecretsProjectLabel: o.Name,
+			},
+		},
+		Spec: secretsv1beta1.HCPVaultSecretsAppSpec{
+			AppName:               appName,
+			HCPAuthRef:            o.Spec.HCPAuthRef,
+			RefreshAfter:          tmpl.RefreshAfter,
+			RolloutRestartTargets: tmpl.RolloutRestartTargets,
+			RolloutRestartWindows: tmpl.RolloutRestartWindows,
+			Destination:           dest,
+			SyncConfig:            tmpl.SyncConfig,
+		},
+	}, nil
+}
+
+// renderProjectDestinationName renders the destination name template text for
+// the App appName.
+func renderProjectDestinationName(text, appName string) (string, error) {
+	t, err := template.New("destination").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, struct{ AppName string }{AppName: appName}); err != nil {
+		return "", err
+	}
+
+	name := b.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("%q is not a valid Secret name: %s", name, strings.Join(errs, ", "))
+	}
+
+	return name, nil
+}
+
+// matchAppName returns true if name matches any of the glob patterns.
+// Malformed patterns never match.
+func matchAppName(patterns []string, name string) bool {
+	for _, pat := range patterns {
+		if ok, err := path.Match(pat, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// appFilter is a function that filters out apps from the ListApps API response.
+// The function should return true to keep the app.
+type appFilter func(*models.Secrets20231128App) bool
+
+// listAppsPaginated fetches all pages of the ListApps API call and returns a slice of responses.
+// Note: Some attributes of the params will be modified in the process of fetching the apps.
+func listAppsPaginated(ctx context.Context, c hvsclient.ClientService, params *hvsclient.ListAppsParams, filter appFilter) (*hvsclient.ListAppsOK, error) {
+	if params == nil {
+		return nil, fmt.Errorf("params is nil")
+	}
+
+	logger := log.FromContext(ctx).WithName("listAppsPaginated")
+	logger.V(consts.LogLevelDebug).Info("Listing Apps")
+
+	var resp *hvsclient.ListAppsOK
+	var apps []*models.Secrets20231128App
+	var err error
+	for {
+		resp, err = c.ListApps(params, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list apps: %w", err)
+		}
+
+		if resp == nil {
+			return nil, fmt.Errorf("failed to list apps: response is nil")
+		}
+
+		for _, app := range resp.Payload.Apps {
+			if filter != nil && !filter(app) {
+				continue
+			}
+			apps = append(apps, app)
+		}
+
+		if resp.Payload.Pagination == nil || resp.Payload.Pagination.NextPageToken == "" {
+			break
+		}
+
+		params.PaginationNextPageToken = ptr.To(resp.Payload.Pagination.NextPageToken)
+	}
+
+	return &hvsclient.ListAppsOK{
+		Payload: &models.Secrets20231128ListAppsResponse{
+			Apps:       apps,
+			Pagination: resp.Payload.Pagination,
+		},
+	}, nil
+}
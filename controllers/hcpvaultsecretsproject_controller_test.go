@@ -0,0 +1,472 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hvsclient "github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/client/secret_service"
+	"github.com/hashicorp/hcp-sdk-go/clients/cloud-vault-secrets/preview/2023-11-28/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_listAppsPaginated(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fooApp := &models.Secrets20231128App{Name: "team-a-foo"}
+	barApp := &models.Secrets20231128App{Name: "team-a-bar"}
+	bazApp := &models.Secrets20231128App{Name: "team-b-baz"}
+
+	responses := []*hvsclient.ListAppsOK{
+		{
+			Payload: &models.Secrets20231128ListAppsResponse{
+				Pagination: &models.CommonPaginationResponse{
+					NextPageToken: "page1",
+				},
+				Apps: []*models.Secrets20231128App{fooApp, bazApp},
+			},
+		},
+		{
+			Payload: &models.Secrets20231128ListAppsResponse{
+				Pagination: &models.CommonPaginationResponse{},
+				Apps:       []*models.Secrets20231128App{barApp},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		responses []*hvsclient.ListAppsOK
+		filter    appFilter
+		params    *hvsclient.ListAppsParams
+		want      []*models.Secrets20231128App
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "all-pages",
+			responses: responses,
+			params:    &hvsclient.ListAppsParams{},
+			want:      []*models.Secrets20231128App{fooApp, bazApp, barApp},
+			wantErr:   assert.NoError,
+		},
+		{
+			name:      "filtered",
+			responses: responses,
+			params:    &hvsclient.ListAppsParams{},
+			filter: func(app *models.Secrets20231128App) bool {
+				return matchAppName([]string{"team-a-*"}, app.Name)
+			},
+			want:    []*models.Secrets20231128App{fooApp, barApp},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "nil-params",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newFakeHVSTransportWithOpts(t, &fakeHVSTransportOpts{
+				listAppsResponses: tt.responses,
+			})
+			got, err := listAppsPaginated(ctx, hvsclient.New(p, nil), tt.params, tt.filter)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got.Payload.Apps)
+			assert.Equal(t, len(tt.responses), p.numRequests)
+		})
+	}
+}
+
+func Test_matchAppName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		appName  string
+		want     bool
+	}{
+		{
+			name:     "exact",
+			patterns: []string{"foo"},
+			appName:  "foo",
+			want:     true,
+		},
+		{
+			name:     "glob",
+			patterns: []string{"bar", "team-a-*"},
+			appName:  "team-a-foo",
+			want:     true,
+		},
+		{
+			name:     "no-match",
+			patterns: []string{"team-a-*"},
+			appName:  "team-b-foo",
+			want:     false,
+		},
+		{
+			name:     "malformed-pattern",
+			patterns: []string{"team-[a"},
+			appName:  "team-a",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, matchAppName(tt.patterns, tt.appName),
+				"matchAppName(%v, %v)", tt.patterns, tt.appName)
+		})
+	}
+}
+
+func Test_renderProjectDestinationName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		text    string
+		appName string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "static",
+			text:    "secrets",
+			appName: "foo",
+			want:    "secrets",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "templated",
+			text:    "{{ .AppName }}-secrets",
+			appName: "foo",
+			want:    "foo-secrets",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid-template",
+			text:    "{{ .AppName ",
+			appName: "foo",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown-field",
+			text:    "{{ .Foo }}",
+			appName: "foo",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid-name",
+			text:    "{{ .AppName }}_secrets",
+			appName: "foo",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderProjectDestinationName(tt.text, tt.appName)
+			if !tt.wantErr(t, err, "renderProjectDestinationName(%q, %q)", tt.text, tt.appName) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHCPVaultSecretsProjectReconciler_syncApps(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	newProject := func() *secretsv1beta1.HCPVaultSecretsProject {
+		return &secretsv1beta1.HCPVaultSecretsProject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "proj",
+				Namespace: "default",
+				UID:       "proj-uid",
+			},
+			Spec: secretsv1beta1.HCPVaultSecretsProjectSpec{
+				AppNames:   []string{"*"},
+				HCPAuthRef: "hcp-auth",
+				AppTemplate: secretsv1beta1.HCPVaultSecretsProjectAppTemplate{
+					RefreshAfter: "60s",
+					Destination: secretsv1beta1.Destination{
+						Name:   "{{ .AppName }}-secrets",
+						Create: true,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing func(*secretsv1beta1.HCPVaultSecretsProject) []client.Object
+		appNames []string
+		want     []secretsv1beta1.HCPVaultSecretsProjectApp
+		wantObjs []string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "create",
+			appNames: []string{"foo", "bar"},
+			want: []secretsv1beta1.HCPVaultSecretsProjectApp{
+				{AppName: "bar", Name: "proj-bar", Destination: "bar-secrets"},
+				{AppName: "foo", Name: "proj-foo", Destination: "foo-secrets"},
+			},
+			wantObjs: []string{"proj-bar", "proj-foo"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name: "delete-stale",
+			existing: func(o *secretsv1beta1.HCPVaultSecretsProject) []client.Object {
+				app, err := makeProjectHCPVaultSecretsApp(o, "stale")
+				require.NoError(t, err)
+				require.NoError(t, ctrl.SetControllerReference(o, app,
+					testutils.NewFakeClientBuilder().Build().Scheme()))
+				return []client.Object{app}
+			},
+			appNames: []string{"foo"},
+			want: []secretsv1beta1.HCPVaultSecretsProjectApp{
+				{AppName: "foo", Name: "proj-foo", Destination: "foo-secrets"},
+			},
+			wantObjs: []string{"proj-foo"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name: "not-owned",
+			existing: func(o *secretsv1beta1.HCPVaultSecretsProject) []client.Object {
+				return []client.Object{
+					&secretsv1beta1.HCPVaultSecretsApp{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "proj-foo",
+							Namespace: o.Namespace,
+						},
+					},
+				}
+			},
+			appNames: []string{"foo", "bar"},
+			want: []secretsv1beta1.HCPVaultSecretsProjectApp{
+				{AppName: "bar", Name: "proj-bar", Destination: "bar-secrets"},
+			},
+			wantObjs: []string{"proj-bar", "proj-foo"},
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "invalid-name",
+			appNames: []string{"Foo_Bar", "foo"},
+			want: []secretsv1beta1.HCPVaultSecretsProjectApp{
+				{AppName: "foo", Name: "proj-foo", Destination: "foo-secrets"},
+			},
+			wantObjs: []string{"proj-foo"},
+			wantErr:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newProject()
+			builder := testutils.NewFakeClientBuilder()
+			if tt.existing != nil {
+				builder = builder.WithObjects(tt.existing(o)...)
+			}
+			c := builder.Build()
+			r := &HCPVaultSecretsProjectReconciler{
+				Client: c,
+				Scheme: c.Scheme(),
+			}
+
+			got, err := r.syncApps(ctx, o, tt.appNames)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+
+			var apps secretsv1beta1.HCPVaultSecretsAppList
+			require.NoError(t, c.List(ctx, &apps, client.InNamespace(o.Namespace)))
+			var names []string
+			for _, app := range apps.Items {
+				names = append(names, app.Name)
+			}
+			assert.ElementsMatch(t, tt.wantObjs, names)
+
+			for _, want := range tt.want {
+				var app secretsv1beta1.HCPVaultSecretsApp
+				require.NoError(t, c.Get(ctx, client.ObjectKey{
+					Namespace: o.Namespace,
+					Name:      want.Name,
+				}, &app))
+				assert.True(t, metav1.IsControlledBy(&app, o))
+				assert.Equal(t, o.Name, app.Labels[hcpVaultSecretsProjectLabel])
+				assert.Equal(t, want.AppName, app.Spec.AppName)
+				assert.Equal(t, o.Spec.HCPAuthRef, app.Spec.HCPAuthRef)
+				assert.Equal(t, o.Spec.AppTemplate.RefreshAfter, app.Spec.RefreshAfter)
+				assert.Equal(t, want.Destination, app.Spec.Destination.Name)
+				assert.True(t, app.Spec.Destination.Create)
+			}
+		})
+	}
+}
+
+func TestHCPVaultSecretsProjectReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.HCPVaultSecretsProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "proj",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: secretsv1beta1.HCPVaultSecretsProjectSpec{
+			AppNames:     []string{"team-a-*"},
+			RefreshAfter: "60s",
+			AppTemplate: secretsv1beta1.HCPVaultSecretsProjectAppTemplate{
+				Destination: secretsv1beta1.Destination{
+					Name: "{{ .AppName }}",
+				},
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().
+		WithObjects(o).
+		WithStatusSubresource(o).
+		Build()
+	p := newFakeHVSTransportWithOpts(t, &fakeHVSTransportOpts{
+		listAppsResponses: []*hvsclient.ListAppsOK{
+			{
+				Payload: &models.Secrets20231128ListAppsResponse{
+					Apps: []*models.Secrets20231128App{
+						{Name: "team-a-foo"},
+						{Name: "team-b-bar"},
+					},
+				},
+			},
+		},
+	})
+	r := &HCPVaultSecretsProjectReconciler{
+		Client:          c,
+		Scheme:          c.Scheme(),
+		Recorder:        record.NewFakeRecorder(10),
+		BackOffRegistry: NewBackOffRegistry(),
+		newHVSClient: func(context.Context, client.Client, client.Object) (hvsclient.ClientService, error) {
+			return hvsclient.New(p, nil), nil
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(o)}
+	got, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Greater(t, got.RequeueAfter.Seconds(), float64(0))
+
+	var updated secretsv1beta1.HCPVaultSecretsProject
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &updated))
+	assert.Equal(t, secretsv1beta1.HCPVaultSecretsProjectStatus{
+		LastGeneration: 1,
+		Apps: []secretsv1beta1.HCPVaultSecretsProjectApp{
+			{AppName: "team-a-foo", Name: "proj-team-a-foo", Destination: "team-a-foo"},
+		},
+	}, updated.Status)
+}
+
+func TestHCPVaultSecretsProjectReconciler_Reconcile_shard(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.HCPVaultSecretsProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "proj",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: secretsv1beta1.HCPVaultSecretsProjectSpec{
+			AppNames: []string{"*"},
+			AppTemplate: secretsv1beta1.HCPVaultSecretsProjectAppTemplate{
+				Destination: secretsv1beta1.Destination{
+					Name: "{{ .AppName }}",
+				},
+			},
+		},
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(o)}
+	owner := shardFor(req.NamespacedName, 2)
+
+	tests := []struct {
+		name     string
+		shard    *Shard
+		wantApps []string
+	}{
+		{
+			name:     "not-sharded",
+			wantApps: []string{"proj-foo"},
+		},
+		{
+			name:     "owned",
+			shard:    &Shard{Index: owner, Count: 2},
+			wantApps: []string{"proj-foo"},
+		},
+		{
+			name:  "not-owned",
+			shard: &Shard{Index: (owner + 1) % 2, Count: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := testutils.NewFakeClientBuilder().
+				WithObjects(o.DeepCopy()).
+				WithStatusSubresource(o).
+				Build()
+			p := newFakeHVSTransportWithOpts(t, &fakeHVSTransportOpts{
+				listAppsResponses: []*hvsclient.ListAppsOK{
+					{
+						Payload: &models.Secrets20231128ListAppsResponse{
+							Apps: []*models.Secrets20231128App{
+								{Name: "foo"},
+							},
+						},
+					},
+				},
+			})
+			var hvsClients int
+			r := &HCPVaultSecretsProjectReconciler{
+				Client:          c,
+				Scheme:          c.Scheme(),
+				Recorder:        record.NewFakeRecorder(10),
+				BackOffRegistry: NewBackOffRegistry(),
+				Shard:           tt.shard,
+				newHVSClient: func(context.Context, client.Client, client.Object) (hvsclient.ClientService, error) {
+					hvsClients++
+					return hvsclient.New(p, nil), nil
+				},
+			}
+
+			_, err := r.Reconcile(ctx, req)
+			require.NoError(t, err)
+
+			// only the operator instance that owns the resource's shard manages its
+			// HCPVaultSecretsApps.
+			var apps secretsv1beta1.HCPVaultSecretsAppList
+			require.NoError(t, c.List(ctx, &apps))
+			var got []string
+			for _, app := range apps.Items {
+				got = append(got, app.Name)
+			}
+			assert.Equal(t, tt.wantApps, got)
+			if tt.wantApps == nil {
+				assert.Zero(t, hvsClients)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+)
+
+// hmacKeyRotationRetryInterval is the interval between attempts to rotate the
+// HMAC key after an error.
+const hmacKeyRotationRetryInterval = time.Minute
+
+var _ manager.LeaderElectionRunnable = (*HMACKeyRotator)(nil)
+
+// HMACKeyRotator rotates the HMAC key that is used by the operator's
+// helpers.HMACValidator on an interval. The last helpers.HMACKeyHistoryLimit
+// replaced keys are kept, so that the MACs of the synced secrets are still
+// valid after a rotation, they are recomputed with the new key on their next
+// sync.
+type HMACKeyRotator struct {
+	Client client.Client
+	// ObjKey of the Secret that holds the HMAC key.
+	ObjKey client.ObjectKey
+	// Interval between rotations of the HMAC key, the time of the last
+	// rotation is the creation time of the Secret that holds the current key.
+	Interval time.Duration
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *HMACKeyRotator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (r *HMACKeyRotator) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("hmacKeyRotator")
+	for {
+		next, err := r.rotateIfDue(ctx)
+		if err != nil {
+			logger.Error(err, "Failed to rotate the HMAC key", "secret", r.ObjKey)
+			next = hmacKeyRotationRetryInterval
+		}
+
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// rotateIfDue rotates the HMAC key if its Interval has elapsed since the last
+// rotation. It returns the duration until the next rotation is due.
+func (r *HMACKeyRotator) rotateIfDue(ctx context.Context) (time.Duration, error) {
+	s, err := helpers.GetCurrentHMACKeySecret(ctx, r.Client, r.ObjKey)
+	if err != nil {
+		return 0, err
+	}
+
+	now := nowFunc()
+	due := s.CreationTimestamp.Add(r.Interval)
+	if now.Before(due) {
+		return due.Sub(now), nil
+	}
+
+	if _, err := helpers.RotateHMACKeySecret(ctx, r.Client, r.ObjKey); err != nil {
+		return 0, err
+	}
+	log.FromContext(ctx).WithName("hmacKeyRotator").Info("Rotated the HMAC key",
+		"secret", r.ObjKey)
+
+	return r.Interval, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestHMACKeyRotator_rotateIfDue(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	origNowFunc := nowFunc
+	t.Cleanup(func() {
+		nowFunc = origNowFunc
+	})
+	nowFunc = func() time.Time {
+		return now
+	}
+
+	objKey := client.ObjectKey{Namespace: "vso", Name: "hmac"}
+	key := []byte("0123456789abcdef")
+
+	tests := []struct {
+		name        string
+		created     time.Time
+		noSecret    bool
+		wantNext    time.Duration
+		wantRotated bool
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "not-due",
+			created:  now.Add(-time.Hour),
+			wantNext: 23 * time.Hour,
+			wantErr:  assert.NoError,
+		},
+		{
+			name:        "due",
+			created:     now.Add(-25 * time.Hour),
+			wantNext:    24 * time.Hour,
+			wantRotated: true,
+			wantErr:     assert.NoError,
+		},
+		{
+			name:     "no-secret",
+			noSecret: true,
+			wantErr:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := testutils.NewFakeClientBuilder().Build()
+			if !tt.noSecret {
+				require.NoError(t, c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:         objKey.Namespace,
+						Name:              objKey.Name,
+						CreationTimestamp: metav1.NewTime(tt.created),
+					},
+					Data: map[string][]byte{
+						helpers.HMACKeyName: key,
+					},
+				}))
+			}
+
+			r := &HMACKeyRotator{
+				Client:   c,
+				ObjKey:   objKey,
+				Interval: 24 * time.Hour,
+			}
+			next, err := r.rotateIfDue(ctx)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.wantNext, next)
+
+			keys, err := helpers.GetHMACKeys(ctx, c, objKey)
+			require.NoError(t, err)
+			if tt.wantRotated {
+				require.Len(t, keys, 2)
+				assert.NotEqual(t, key, keys[0])
+				assert.Equal(t, key, keys[1])
+			} else {
+				assert.Equal(t, [][]byte{key}, keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+const (
+	// headerHCPWebhookSignature holds the hex encoded HMAC-SHA512 signature of an
+	// HCP webhook request's body.
+	headerHCPWebhookSignature = "X-HCP-Webhook-Signature"
+	// hvsWebhookMaxBodySize is the maximum size of an accepted HCP webhook
+	// request body.
+	hvsWebhookMaxBodySize = 1 << 20
+)
+
+var (
+	_ manager.Runnable               = (*HVSWebhookReceiver)(nil)
+	_ manager.LeaderElectionRunnable = (*HVSWebhookReceiver)(nil)
+	_ http.Handler                   = (*HVSWebhookReceiver)(nil)
+)
+
+// hvsWebhookEvent is the subset of an HCP webhook event that is needed to
+// determine the HCP Vault Secrets App that was changed.
+type hvsWebhookEvent struct {
+	EventID      string `json:"event_id"`
+	EventAction  string `json:"event_action"`
+	EventSource  string `json:"event_source"`
+	EventPayload struct {
+		OrganizationID string `json:"organization_id"`
+		ProjectID      string `json:"project_id"`
+		AppName        string `json:"app_name"`
+	} `json:"event_payload"`
+}
+
+// HVSWebhookReceiver receives HCP webhook events for changes to HCP Vault
+// Secrets Apps. Each HCPVaultSecretsApp that has instant updates enabled, and
+// that syncs the changed App, is immediately requeued. It is meant to be added
+// to the manager, and runs on every replica, since its Service selects all of
+// them. Only the leader reconciles HCPVaultSecretsApps, so the other replicas
+// requeue them by setting their consts.AnnotationHVSWebhookEvent annotation.
+type HVSWebhookReceiver struct {
+	Client client.Client
+	// BindAddress the receiver listens on.
+	BindAddress string
+	// HMACKey of the HCP webhook, it is used to verify the signature of every
+	// request.
+	HMACKey []byte
+	// SourceCh is the HCPVaultSecretsAppReconciler's SourceCh.
+	SourceCh chan event.GenericEvent
+	// Elected is closed once this replica is the leader, e.g. the manager's
+	// Elected(). If nil, the replica is always the leader.
+	Elected <-chan struct{}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *HVSWebhookReceiver) NeedLeaderElection() bool {
+	return false
+}
+
+// isLeader returns true if this replica is the leader.
+func (r *HVSWebhookReceiver) isLeader() bool {
+	if r.Elected == nil {
+		return true
+	}
+
+	select {
+	case <-r.Elected:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (r *HVSWebhookReceiver) Start(ctx context.Context) error {
+	if len(r.HMACKey) == 0 {
+		return errors.New("an HMAC key is required for the HVS webhook receiver")
+	}
+
+	logger := log.FromContext(ctx).WithName("hvsWebhookReceiver")
+	srv := &http.Server{
+		Addr:              r.BindAddress,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return log.IntoContext(ctx, logger)
+		},
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("Starting the HVS webhook receiver", "addr", r.BindAddress)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// ServeHTTP handles a single HCP webhook event.
+func (r *HVSWebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.FromContext(ctx).WithName("hvsWebhookReceiver")
+	if req.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, hvsWebhookMaxBodySize+1))
+	if err != nil || len(body) > hvsWebhookMaxBodySize {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !r.validSignature(body, req.Header.Get(headerHCPWebhookSignature)) {
+		logger.V(consts.LogLevelWarning).Info("Rejected HCP webhook event with an invalid signature")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var ev hvsWebhookEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	logger.V(consts.LogLevelDebug).Info("Received HCP webhook event",
+		"eventID", ev.EventID, "eventSource", ev.EventSource, "eventAction", ev.EventAction,
+		"appName", ev.EventPayload.AppName)
+
+	// e.g. the verification event that HCP sends when a webhook is created.
+	if ev.EventPayload.AppName == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	objs, err := r.matchingApps(ctx, ev)
+	if err != nil {
+		logger.Error(err, "Failed to list HCPVaultSecretsApps")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	leader := r.isLeader()
+	for _, o := range objs {
+		if !leader {
+			if err := r.requeueOnLeader(ctx, o); err != nil {
+				logger.Error(err, "Failed to requeue HCPVaultSecretsApp on the leader",
+					"obj", client.ObjectKeyFromObject(o))
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			logger.V(consts.LogLevelDebug).Info("Requeued HCPVaultSecretsApp on the leader",
+				"obj", client.ObjectKeyFromObject(o), "appName", ev.EventPayload.AppName)
+			continue
+		}
+
+		if !sendSourceEvent(ctx, HCPVaultSecretsApp, r.SourceCh, event.GenericEvent{
+			Object: &secretsv1beta1.HCPVaultSecretsApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: o.Namespace,
+					Name:      o.Name,
+				},
+			},
+		}) {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		logger.V(consts.LogLevelDebug).Info("Enqueued HCPVaultSecretsApp",
+			"obj", client.ObjectKeyFromObject(o), "appName", ev.EventPayload.AppName)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requeueOnLeader requeues o on the leader by setting its
+// consts.AnnotationHVSWebhookEvent annotation to now.
+func (r *HVSWebhookReceiver) requeueOnLeader(ctx context.Context, o *secretsv1beta1.HCPVaultSecretsApp) error {
+	patch := client.MergeFrom(o.DeepCopy())
+	annotations := o.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[consts.AnnotationHVSWebhookEvent] = time.Now().UTC().Format(time.RFC3339Nano)
+	o.SetAnnotations(annotations)
+
+	return r.Client.Patch(ctx, o, patch)
+}
+
+// matchingApps returns the HCPVaultSecretsApps with instant updates enabled that
+// sync the App of ev. When ev includes the App's organization and project, they
+// must match those of the HCPVaultSecretsApp's HCPAuth.
+func (r *HVSWebhookReceiver) matchingApps(ctx context.Context, ev hvsWebhookEvent) ([]*secretsv1beta1.HCPVaultSecretsApp, error) {
+	logger := log.FromContext(ctx).WithName("hvsWebhookReceiver")
+
+	var list secretsv1beta1.HCPVaultSecretsAppList
+	if err := r.Client.List(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	var objs []*secretsv1beta1.HCPVaultSecretsApp
+	for i := range list.Items {
+		o := &list.Items[i]
+		if o.Spec.SyncConfig == nil || !o.Spec.SyncConfig.InstantUpdates ||
+			o.Spec.AppName != ev.EventPayload.AppName {
+			continue
+		}
+
+		if ev.EventPayload.OrganizationID != "" || ev.EventPayload.ProjectID != "" {
+			authObj, err := common.GetHCPAuthForObj(ctx, r.Client, o)
+			if err != nil {
+				logger.Error(err, "Failed to get HCPAuth", "obj", client.ObjectKeyFromObject(o))
+				continue
+			}
+			if ev.EventPayload.OrganizationID != "" && ev.EventPayload.OrganizationID != authObj.Spec.OrganizationID {
+				continue
+			}
+			if ev.EventPayload.ProjectID != "" && ev.EventPayload.ProjectID != authObj.Spec.ProjectID {
+				continue
+			}
+		}
+
+		objs = append(objs, o)
+	}
+
+	return objs, nil
+}
+
+func (r *HVSWebhookReceiver) validSignature(body []byte, signature string) bool {
+	if len(r.HMACKey) == 0 || signature == "" {
+		return false
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha512.New, r.HMACKey)
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestHVSWebhookReceiver_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	hmacKey := []byte("hmac-key")
+	sign := func(body string) string {
+		mac := hmac.New(sha512.New, hmacKey)
+		mac.Write([]byte(body))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	newApp := func(name, appName string, instantUpdates bool) *secretsv1beta1.HCPVaultSecretsApp {
+		return &secretsv1beta1.HCPVaultSecretsApp{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: secretsv1beta1.HCPVaultSecretsAppSpec{
+				AppName:    appName,
+				HCPAuthRef: "hcp-auth",
+				SyncConfig: &secretsv1beta1.HVSSyncConfig{
+					InstantUpdates: instantUpdates,
+				},
+			},
+		}
+	}
+	objs := []client.Object{
+		&secretsv1beta1.HCPAuth{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hcp-auth",
+				Namespace: "default",
+			},
+			Spec: secretsv1beta1.HCPAuthSpec{
+				OrganizationID: "org",
+				ProjectID:      "proj",
+			},
+		},
+		newApp("foo", "app-foo", true),
+		newApp("foo-polling", "app-foo", false),
+		newApp("bar", "app-bar", true),
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		signature  string
+		follower   bool
+		wantStatus int
+		want       []client.ObjectKey
+	}{
+		{
+			name:       "matched",
+			method:     http.MethodPost,
+			body:       `{"event_action":"update","event_payload":{"app_name":"app-foo"}}`,
+			wantStatus: http.StatusNoContent,
+			want:       []client.ObjectKey{{Namespace: "default", Name: "foo"}},
+		},
+		{
+			name:       "matched-org-project",
+			method:     http.MethodPost,
+			body:       `{"event_payload":{"app_name":"app-bar","organization_id":"org","project_id":"proj"}}`,
+			wantStatus: http.StatusNoContent,
+			want:       []client.ObjectKey{{Namespace: "default", Name: "bar"}},
+		},
+		{
+			name:       "matched-follower",
+			method:     http.MethodPost,
+			body:       `{"event_action":"update","event_payload":{"app_name":"app-foo"}}`,
+			follower:   true,
+			wantStatus: http.StatusNoContent,
+			want:       []client.ObjectKey{{Namespace: "default", Name: "foo"}},
+		},
+		{
+			name:       "other-project-follower",
+			method:     http.MethodPost,
+			body:       `{"event_payload":{"app_name":"app-bar","organization_id":"org","project_id":"other"}}`,
+			follower:   true,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "other-project",
+			method:     http.MethodPost,
+			body:       `{"event_payload":{"app_name":"app-bar","organization_id":"org","project_id":"other"}}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "no-app",
+			method:     http.MethodPost,
+			body:       `{"event_action":"test","event_payload":{}}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid-signature",
+			method:     http.MethodPost,
+			body:       `{"event_payload":{"app_name":"app-foo"}}`,
+			signature:  "deadbeef",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid-body",
+			method:     http.MethodPost,
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid-method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceCh := make(chan event.GenericEvent, len(objs))
+			c := testutils.NewFakeClientBuilder().WithObjects(objs...).Build()
+			r := &HVSWebhookReceiver{
+				Client:   c,
+				HMACKey:  hmacKey,
+				SourceCh: sourceCh,
+			}
+			if tt.follower {
+				r.Elected = make(chan struct{})
+			}
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			signature := tt.signature
+			if signature == "" {
+				signature = sign(tt.body)
+			}
+			req.Header.Set(headerHCPWebhookSignature, signature)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			close(sourceCh)
+			var got []client.ObjectKey
+			for evt := range sourceCh {
+				got = append(got, client.ObjectKeyFromObject(evt.Object))
+			}
+
+			var list secretsv1beta1.HCPVaultSecretsAppList
+			require.NoError(t, c.List(context.Background(), &list))
+			var requeued []client.ObjectKey
+			for _, o := range list.Items {
+				if _, ok := o.GetAnnotations()[consts.AnnotationHVSWebhookEvent]; ok {
+					requeued = append(requeued, client.ObjectKeyFromObject(&o))
+				}
+			}
+
+			if tt.follower {
+				assert.Empty(t, got)
+				assert.Equal(t, tt.want, requeued)
+			} else {
+				assert.Equal(t, tt.want, got)
+				assert.Empty(t, requeued)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// defaultKVReadBatchMaxConcurrency is the default number of reads of a batch
+// that are sent to Vault concurrently.
+const defaultKVReadBatchMaxConcurrency = 8
+
+// KVReadBatcher coalesces the KV reads of the VaultStaticSecrets that share a
+// Vault client and a KV mount. All reads that are requested within Window of
+// the first read of a batch are sent together once the window has elapsed.
+// Identical reads of a batch, e.g. of the same path and version, are only sent
+// once and share their response. Since Vault has no bulk read for KV, the
+// distinct reads of a batch are sent with at most MaxConcurrency at a time.
+// This reduces the number of requests to Vault, and smooths them out, when
+// many VaultStaticSecrets are reconciled at once, e.g. after an operator
+// restart.
+type KVReadBatcher struct {
+	// Window is the duration a batch collects reads before they are sent.
+	Window time.Duration
+	// MaxConcurrency is the maximum number of reads of a batch that are sent to
+	// Vault concurrently.
+	MaxConcurrency int
+
+	mu      sync.Mutex
+	batches map[kvReadBatchKey]*kvReadBatch
+}
+
+type kvReadBatchKey struct {
+	cacheKey vault.ClientCacheKey
+	mount    string
+}
+
+type kvReadBatch struct {
+	client vault.Client
+	reads  map[string]*kvRead
+}
+
+type kvRead struct {
+	req  vault.ReadRequest
+	done chan struct{}
+	resp vault.Response
+	err  error
+}
+
+// Read the KV secret for req from the mount with the Vault client c, as part of
+// the current batch of c and mount. It is safe to call on a nil KVReadBatcher,
+// or one with a Window of 0, in which case the secret is read immediately. The
+// returned Response may be shared with other callers, and must not be
+// modified.
+func (b *KVReadBatcher) Read(ctx context.Context, c vault.Client, mount string, req vault.ReadRequest) (vault.Response, error) {
+	if b == nil || b.Window <= 0 {
+		return c.Read(ctx, req)
+	}
+
+	cacheKey, err := c.GetCacheKey()
+	if err != nil {
+		return c.Read(ctx, req)
+	}
+
+	read := b.add(kvReadBatchKey{cacheKey: cacheKey, mount: mount}, c, req)
+	select {
+	case <-read.done:
+		return read.resp, read.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// add req to the current batch for key, a new batch is started if there is
+// none. It returns the batch's read for req.
+func (b *KVReadBatcher) add(key kvReadBatchKey, c vault.Client, req vault.ReadRequest) *kvRead {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.batches == nil {
+		b.batches = make(map[kvReadBatchKey]*kvReadBatch)
+	}
+
+	batch, ok := b.batches[key]
+	if !ok {
+		batch = &kvReadBatch{
+			client: c,
+			reads:  make(map[string]*kvRead),
+		}
+		b.batches[key] = batch
+		time.AfterFunc(b.Window, func() {
+			b.flush(key, batch)
+		})
+	}
+
+	readKey := req.Path() + "?" + req.Values().Encode()
+	read, ok := batch.reads[readKey]
+	if ok {
+		metrics.IncKVReadBatchCoalesced()
+		return read
+	}
+
+	read = &kvRead{
+		req:  req,
+		done: make(chan struct{}),
+	}
+	batch.reads[readKey] = read
+
+	return read
+}
+
+// flush sends all reads of the batch for key to Vault. No reads can be added to
+// the batch once it is flushed.
+func (b *KVReadBatcher) flush(key kvReadBatchKey, batch *kvReadBatch) {
+	b.mu.Lock()
+	if b.batches[key] == batch {
+		delete(b.batches, key)
+	}
+	b.mu.Unlock()
+
+	maxConcurrency := b.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultKVReadBatchMaxConcurrency
+	}
+
+	// the reads are not bound to any of the callers' contexts, since they are
+	// shared by all of them. The Vault client's timeout still applies.
+	ctx := context.Background()
+	sem := make(chan struct{}, maxConcurrency)
+	for _, read := range batch.reads {
+		sem <- struct{}{}
+		go func(read *kvRead) {
+			defer func() { <-sem }()
+			metrics.IncKVReadBatchReads()
+			read.resp, read.err = batch.client.Read(ctx, read.req)
+			close(read.done)
+		}(read)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+var _ vault.Client = (*fakeKVReadClient)(nil)
+
+// fakeKVReadClient records the reads of a KVReadBatcher, all other
+// vault.Client methods are not implemented.
+type fakeKVReadClient struct {
+	vault.Client
+	cacheKey vault.ClientCacheKey
+	mu       sync.Mutex
+	reads    map[string]int
+}
+
+func (c *fakeKVReadClient) GetCacheKey() (vault.ClientCacheKey, error) {
+	return c.cacheKey, nil
+}
+
+func (c *fakeKVReadClient) Read(_ context.Context, req vault.ReadRequest) (vault.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reads == nil {
+		c.reads = make(map[string]int)
+	}
+	c.reads[req.Path()+"?"+req.Values().Encode()]++
+	return nil, nil
+}
+
+func (c *fakeKVReadClient) readCounts() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[string]int, len(c.reads))
+	for k, v := range c.reads {
+		counts[k] = v
+	}
+	return counts
+}
+
+func TestKVReadBatcher_Read(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b := &KVReadBatcher{
+		Window:         50 * time.Millisecond,
+		MaxConcurrency: 2,
+	}
+	c := &fakeKVReadClient{cacheKey: "client-1"}
+	other := &fakeKVReadClient{cacheKey: "client-2"}
+
+	reqs := []struct {
+		c     *fakeKVReadClient
+		mount string
+		req   vault.ReadRequest
+	}{
+		{c: c, mount: "kv", req: vault.NewKVReadRequestV2("kv", "foo", 0)},
+		{c: c, mount: "kv", req: vault.NewKVReadRequestV2("kv", "foo", 0)},
+		{c: c, mount: "kv", req: vault.NewKVReadRequestV2("kv", "foo", 0)},
+		{c: c, mount: "kv", req: vault.NewKVReadRequestV2("kv", "foo", 1)},
+		{c: c, mount: "kv", req: vault.NewKVReadRequestV2("kv", "bar", 0)},
+		{c: c, mount: "kv-v1", req: vault.NewKVReadRequestV1("kv-v1", "foo")},
+		{c: other, mount: "kv", req: vault.NewKVReadRequestV2("kv", "foo", 0)},
+	}
+
+	var wg sync.WaitGroup
+	for _, r := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.Read(ctx, r.c, r.mount, r.req)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// identical reads of the same client and mount are coalesced.
+	assert.Equal(t, map[string]int{
+		"kv/data/foo?":          1,
+		"kv/data/foo?version=1": 1,
+		"kv/data/bar?":          1,
+		"kv-v1/foo?":            1,
+	}, c.readCounts())
+	assert.Equal(t, map[string]int{
+		"kv/data/foo?": 1,
+	}, other.readCounts())
+
+	// a new batch is started once the previous batch has been flushed.
+	_, err := b.Read(ctx, c, "kv", vault.NewKVReadRequestV2("kv", "foo", 0))
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.readCounts()["kv/data/foo?"])
+}
+
+func TestKVReadBatcher_Read_disabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &fakeKVReadClient{cacheKey: "client-1"}
+	req := vault.NewKVReadRequestV2("kv", "foo", 0)
+
+	var nilBatcher *KVReadBatcher
+	_, err := nilBatcher.Read(ctx, c, "kv", req)
+	require.NoError(t, err)
+	_, err = (&KVReadBatcher{}).Read(ctx, c, "kv", req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.readCounts()["kv/data/foo?"])
+}
+
+func TestKVReadBatcher_Read_canceled(t *testing.T) {
+	t.Parallel()
+
+	b := &KVReadBatcher{
+		Window: time.Minute,
+	}
+	c := &fakeKVReadClient{cacheKey: "client-1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := b.Read(ctx, c, "kv", vault.NewKVReadRequestV2("kv", "foo", 0))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,386 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// defaultLeaseManagerMaxConcurrency is the default number of lease renewals of
+// a batch that are sent to Vault concurrently.
+const defaultLeaseManagerMaxConcurrency = 8
+
+var (
+	_ manager.Runnable               = (*LeaseManager)(nil)
+	_ manager.LeaderElectionRunnable = (*LeaseManager)(nil)
+)
+
+// leaseRenewFunc renews the lease of o with the Vault client c.
+type leaseRenewFunc func(ctx context.Context, c vault.ClientBase, o *secretsv1beta1.VaultDynamicSecret) (*secretsv1beta1.VaultSecretLease, error)
+
+// LeaseManager renews the leases of the VaultDynamicSecrets out of the
+// reconcile loop, similar to the api.LifetimeWatcher. It runs a renewer per
+// cached Vault client, which renews all of the client's leases that are due
+// within BatchWindow of each other together, and publishes the renewed leases
+// in the resources' status. Since Vault has no bulk lease renewal, the renewals
+// of a batch are sent with at most MaxConcurrency at a time. A resource is only
+// reconciled again once the renewal of its lease fails or is truncated, in
+// which case new credentials are requested. This reduces the number of
+// reconciliations by an order of magnitude when there are many
+// VaultDynamicSecrets with renewable leases. It is meant to be added to the
+// manager, and only runs on the leader.
+type LeaseManager struct {
+	// BatchWindow is the duration after the earliest due lease of a Vault client
+	// within which the client's other due leases are renewed along with it.
+	BatchWindow time.Duration
+	// MaxConcurrency is the maximum number of lease renewals of a batch that are
+	// sent to Vault concurrently.
+	MaxConcurrency int
+
+	client   client.Client
+	recorder record.EventRecorder
+	renew    leaseRenewFunc
+	enqueue  func(context.Context, client.ObjectKey)
+
+	mu       sync.Mutex
+	ctx      context.Context
+	renewers map[string]*leaseRenewer
+	index    map[client.ObjectKey]string
+}
+
+type leaseRenewer struct {
+	client vault.ClientBase
+	leases map[client.ObjectKey]*managedLease
+	wake   chan struct{}
+	cancel context.CancelFunc
+}
+
+type managedLease struct {
+	id    string
+	dueAt time.Time
+}
+
+// setup the LeaseManager for the VaultDynamicSecret controller. The leases are
+// renewed with renew, and the resources whose lease can no longer be renewed
+// are handed back to the controller with enqueue.
+func (m *LeaseManager) setup(c client.Client, recorder record.EventRecorder,
+	renew leaseRenewFunc, enqueue func(context.Context, client.ObjectKey),
+) {
+	if m == nil {
+		return
+	}
+
+	m.client = c
+	m.recorder = recorder
+	m.renew = renew
+	m.enqueue = enqueue
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (m *LeaseManager) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done, after which
+// all renewers are stopped.
+func (m *LeaseManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.renewers = make(map[string]*leaseRenewer)
+	m.index = make(map[client.ObjectKey]string)
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.renewers {
+		r.cancel()
+	}
+	m.ctx = nil
+	m.renewers = nil
+	m.index = nil
+
+	return nil
+}
+
+// Track o's lease for renewal with the Vault client c, replacing any lease that
+// was previously tracked for o. It returns false if o's lease cannot be renewed
+// by the LeaseManager, in which case o is no longer tracked, and its lease must
+// be renewed by the reconciliation. It is safe to call on a nil LeaseManager.
+func (m *LeaseManager) Track(o *secretsv1beta1.VaultDynamicSecret, c vault.ClientBase) bool {
+	if m == nil {
+		return false
+	}
+
+	objKey := client.ObjectKeyFromObject(o)
+	lease := o.Status.SecretLease
+	leaseDuration := time.Duration(lease.LeaseDuration) * time.Second
+	cacheKey := o.Status.VaultClientMeta.CacheKey
+	// shared leases are renewed by the reconciliation, since their renewal is
+	// coordinated across all resources that share them.
+	if lease.ID == "" || !lease.Renewable || leaseDuration <= 0 || cacheKey == "" ||
+		useStaticCreds(o) || useDataExpiry(o) || usePolling(o) || shareLease(o) {
+		m.Untrack(objKey)
+		return false
+	}
+
+	dueAt := time.Unix(o.Status.LastRenewalTime, 0).Add(
+		computeDynamicHorizonWithJitter(leaseDuration, o.Spec.RenewalPercent))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ctx == nil || m.renew == nil {
+		m.untrack(objKey)
+		return false
+	}
+
+	if cur, ok := m.index[objKey]; ok && cur != cacheKey {
+		m.untrack(objKey)
+	}
+
+	r, ok := m.renewers[cacheKey]
+	if !ok {
+		ctx, cancel := context.WithCancel(m.ctx)
+		r = &leaseRenewer{
+			leases: make(map[client.ObjectKey]*managedLease),
+			wake:   make(chan struct{}, 1),
+			cancel: cancel,
+		}
+		m.renewers[cacheKey] = r
+		go m.run(ctx, r)
+	}
+
+	r.client = c
+	r.leases[objKey] = &managedLease{
+		id:    lease.ID,
+		dueAt: dueAt,
+	}
+	m.index[objKey] = cacheKey
+	r.notify()
+
+	return true
+}
+
+// Untrack the lease of the resource objKey. It is safe to call on a nil
+// LeaseManager.
+func (m *LeaseManager) Untrack(objKey client.ObjectKey) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.untrack(objKey)
+}
+
+// RemoveClient stops the renewers of the Vault client cacheKey, and of its
+// clones, and untracks all of their leases, e.g. once the client is removed
+// from the cache. It is safe to call on a nil LeaseManager.
+func (m *LeaseManager) RemoveClient(cacheKey vault.ClientCacheKey) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, r := range m.renewers {
+		if ok, _ := vault.ClientCacheKey(k).SameParent(cacheKey); !ok {
+			continue
+		}
+
+		r.cancel()
+		delete(m.renewers, k)
+		for objKey := range r.leases {
+			delete(m.index, objKey)
+		}
+	}
+}
+
+// untrack must be called with the lock held.
+func (m *LeaseManager) untrack(objKey client.ObjectKey) {
+	cacheKey, ok := m.index[objKey]
+	if !ok {
+		return
+	}
+
+	delete(m.index, objKey)
+	if r, ok := m.renewers[cacheKey]; ok {
+		delete(r.leases, objKey)
+		if len(r.leases) == 0 {
+			r.cancel()
+			delete(m.renewers, cacheKey)
+		}
+	}
+}
+
+// untrackLease untracks the lease of the resource objKey, unless it was
+// replaced since l was tracked.
+func (m *LeaseManager) untrackLease(r *leaseRenewer, objKey client.ObjectKey, l *managedLease) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tracked(r, objKey, l) {
+		m.untrack(objKey)
+	}
+}
+
+// tracked returns true if l is still the lease that r renews for the resource
+// objKey. It must be called with the lock held.
+func (m *LeaseManager) tracked(r *leaseRenewer, objKey client.ObjectKey, l *managedLease) bool {
+	cacheKey, ok := m.index[objKey]
+	return ok && m.renewers[cacheKey] == r && r.leases[objKey] == l
+}
+
+// run renews the leases of r as they come due, until ctx is done.
+func (m *LeaseManager) run(ctx context.Context, r *leaseRenewer) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for ctx.Err() == nil {
+		c, due, next := m.dueLeases(r)
+		if len(due) > 0 {
+			m.renewBatch(ctx, r, c, due)
+			continue
+		}
+
+		var timerC <-chan time.Time
+		if !next.IsZero() {
+			timer.Reset(max(next.Sub(nowFunc()), 0))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-timerC:
+		}
+	}
+}
+
+// dueLeases returns the leases of r that are due within the BatchWindow, along
+// with the time at which the next of r's other leases is due.
+func (m *LeaseManager) dueLeases(r *leaseRenewer) (vault.ClientBase, map[client.ObjectKey]*managedLease, time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := nowFunc().Add(m.BatchWindow)
+	due := make(map[client.ObjectKey]*managedLease)
+	var next time.Time
+	for objKey, l := range r.leases {
+		if !l.dueAt.After(cutoff) {
+			due[objKey] = l
+		} else if next.IsZero() || l.dueAt.Before(next) {
+			next = l.dueAt
+		}
+	}
+
+	return r.client, due, next
+}
+
+// renewBatch renews the due leases of r with the Vault client c.
+func (m *LeaseManager) renewBatch(ctx context.Context, r *leaseRenewer, c vault.ClientBase, due map[client.ObjectKey]*managedLease) {
+	maxConcurrency := m.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultLeaseManagerMaxConcurrency
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrency)
+	for objKey, l := range due {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(objKey client.ObjectKey, l *managedLease) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			m.renewOne(ctx, r, c, objKey, l)
+		}(objKey, l)
+	}
+	wg.Wait()
+}
+
+// renewOne renews the lease l of the resource objKey, and publishes the renewed
+// lease in the resource's status. The resource is untracked and handed back to
+// the controller if the renewal fails.
+func (m *LeaseManager) renewOne(ctx context.Context, r *leaseRenewer, c vault.ClientBase, objKey client.ObjectKey, l *managedLease) {
+	logger := log.FromContext(ctx).WithName("leaseManager").WithValues("obj", objKey, "leaseID", l.id)
+
+	o := &secretsv1beta1.VaultDynamicSecret{}
+	if err := m.client.Get(ctx, objKey, o); err != nil {
+		m.untrackLease(r, objKey, l)
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get the resource, handing the renewal back to the controller")
+			m.enqueue(ctx, objKey)
+		}
+		return
+	}
+
+	if o.Status.SecretLease.ID != l.id || o.GetDeletionTimestamp() != nil {
+		// the resource was reconciled since its lease was tracked.
+		m.untrackLease(r, objKey, l)
+		return
+	}
+
+	lease, err := m.renew(ctx, c, o)
+	if err != nil {
+		logger.V(consts.LogLevelDebug).Info(
+			"Failed to renew the lease, handing the renewal back to the controller", "err", err)
+		m.untrackLease(r, objKey, l)
+		m.enqueue(ctx, objKey)
+		return
+	}
+
+	renewedAt := nowFunc()
+	o.Status.StaticCredsMetaData = secretsv1beta1.VaultStaticCredsMetaData{}
+	o.Status.SecretLease = *lease
+	o.Status.LastRenewalTime = renewedAt.Unix()
+	if o.Status.Credentials.Username != "" {
+		o.Status.Credentials.Expiration = dynamicSecretExpiry(o).Unix()
+	}
+	if err := m.client.Status().Update(ctx, o); err != nil {
+		logger.Error(err, "Failed to update the resource's status after renewing its lease")
+	}
+
+	horizon := computeDynamicHorizonWithJitter(
+		time.Duration(lease.LeaseDuration)*time.Second, o.Spec.RenewalPercent)
+	m.recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonSecretLeaseRenewal,
+		"Renewed lease, lease_id=%s, horizon=%s", l.id, horizon)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.tracked(r, objKey, l) {
+		return
+	}
+	if !lease.Renewable || lease.LeaseDuration <= 0 {
+		// the lease can no longer be renewed, it is left to expire.
+		m.untrack(objKey)
+		return
+	}
+	l.dueAt = renewedAt.Add(horizon)
+}
+
+// notify wakes the renewer, so that it picks up a newly tracked lease.
+func (r *leaseRenewer) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+const leaseManagerCacheKey = "kubernetes-2a8108711ae49ac0faa724"
+
+func newLeaseManagerVDS(name, leaseID string) *secretsv1beta1.VaultDynamicSecret {
+	return &secretsv1beta1.VaultDynamicSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: secretsv1beta1.VaultDynamicSecretSpec{
+			Mount:          "db",
+			Path:           "creds/app",
+			RenewalPercent: 67,
+		},
+		Status: secretsv1beta1.VaultDynamicSecretStatus{
+			VaultClientMeta: secretsv1beta1.VaultClientMeta{
+				CacheKey: leaseManagerCacheKey,
+			},
+			SecretLease: secretsv1beta1.VaultSecretLease{
+				ID:            leaseID,
+				LeaseDuration: 600,
+				Renewable:     true,
+			},
+			// the lease is due immediately.
+			LastRenewalTime: time.Now().Add(-time.Hour).Unix(),
+		},
+	}
+}
+
+func TestLeaseManager_Track(t *testing.T) {
+	t.Parallel()
+
+	var nilManager *LeaseManager
+	assert.False(t, nilManager.Track(newLeaseManagerVDS("foo", "lease-1"), nil))
+	nilManager.Untrack(client.ObjectKey{Namespace: "default", Name: "foo"})
+	nilManager.RemoveClient(leaseManagerCacheKey)
+
+	tests := []struct {
+		name   string
+		modify func(o *secretsv1beta1.VaultDynamicSecret)
+		want   bool
+	}{
+		{
+			name: "renewable",
+			want: true,
+		},
+		{
+			name: "not-renewable",
+			modify: func(o *secretsv1beta1.VaultDynamicSecret) {
+				o.Status.SecretLease.Renewable = false
+			},
+		},
+		{
+			name: "no-lease",
+			modify: func(o *secretsv1beta1.VaultDynamicSecret) {
+				o.Status.SecretLease.ID = ""
+			},
+		},
+		{
+			name: "no-cache-key",
+			modify: func(o *secretsv1beta1.VaultDynamicSecret) {
+				o.Status.VaultClientMeta.CacheKey = ""
+			},
+		},
+		{
+			name: "shared-lease",
+			modify: func(o *secretsv1beta1.VaultDynamicSecret) {
+				o.Spec.ShareLease = true
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			m := &LeaseManager{BatchWindow: time.Minute}
+			m.setup(testutils.NewFakeClientBuilder().Build(), record.NewFakeRecorder(10),
+				func(context.Context, vault.ClientBase, *secretsv1beta1.VaultDynamicSecret) (*secretsv1beta1.VaultSecretLease, error) {
+					return nil, errors.New("not implemented")
+				},
+				func(context.Context, client.ObjectKey) {},
+			)
+			// the LeaseManager does not track leases until it is started.
+			assert.False(t, m.Track(newLeaseManagerVDS("foo", "lease-1"), nil))
+
+			startLeaseManager(t, ctx, m)
+
+			o := newLeaseManagerVDS("foo", "lease-1")
+			// the lease is not due, so that it is never renewed by the test.
+			o.Status.LastRenewalTime = time.Now().Unix()
+			if tt.modify != nil {
+				tt.modify(o)
+			}
+			assert.Equal(t, tt.want, m.Track(o, nil))
+
+			m.mu.Lock()
+			_, ok := m.index[client.ObjectKeyFromObject(o)]
+			m.mu.Unlock()
+			assert.Equal(t, tt.want, ok)
+
+			m.Untrack(client.ObjectKeyFromObject(o))
+			m.mu.Lock()
+			assert.Empty(t, m.index)
+			assert.Empty(t, m.renewers)
+			m.mu.Unlock()
+		})
+	}
+}
+
+func TestLeaseManager_renew(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	foo := newLeaseManagerVDS("foo", "lease-1")
+	bar := newLeaseManagerVDS("bar", "lease-2")
+	c := testutils.NewFakeClientBuilder().
+		WithObjects(foo.DeepCopy(), bar.DeepCopy()).
+		WithStatusSubresource(foo, bar).
+		Build()
+
+	var mu sync.Mutex
+	renewed := make(map[string]int)
+	var enqueued []client.ObjectKey
+	m := &LeaseManager{BatchWindow: time.Minute}
+	m.setup(c, record.NewFakeRecorder(10),
+		func(_ context.Context, _ vault.ClientBase, o *secretsv1beta1.VaultDynamicSecret) (*secretsv1beta1.VaultSecretLease, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			renewed[o.Status.SecretLease.ID]++
+			if o.Name == "bar" {
+				return nil, errors.New("lease not found")
+			}
+			lease := o.Status.SecretLease
+			return &lease, nil
+		},
+		func(_ context.Context, objKey client.ObjectKey) {
+			mu.Lock()
+			defer mu.Unlock()
+			enqueued = append(enqueued, objKey)
+		},
+	)
+	startLeaseManager(t, ctx, m)
+
+	require.True(t, m.Track(foo, nil))
+	require.True(t, m.Track(bar, nil))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return renewed["lease-1"] == 1 && len(enqueued) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, map[string]int{"lease-1": 1, "lease-2": 1}, renewed)
+	assert.Equal(t, []client.ObjectKey{client.ObjectKeyFromObject(bar)}, enqueued)
+	mu.Unlock()
+
+	// the renewed lease is published in foo's status.
+	var got secretsv1beta1.VaultDynamicSecret
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(foo), &got))
+	assert.Greater(t, got.Status.LastRenewalTime, foo.Status.LastRenewalTime)
+
+	// foo remains tracked for its next renewal, bar is handed back to the
+	// controller.
+	m.mu.Lock()
+	assert.Contains(t, m.index, client.ObjectKeyFromObject(foo))
+	assert.NotContains(t, m.index, client.ObjectKeyFromObject(bar))
+	m.mu.Unlock()
+
+	m.RemoveClient(leaseManagerCacheKey)
+	m.mu.Lock()
+	assert.Empty(t, m.index)
+	assert.Empty(t, m.renewers)
+	m.mu.Unlock()
+}
+
+// startLeaseManager starts m, and waits until it accepts leases.
+func startLeaseManager(t *testing.T, ctx context.Context, m *LeaseManager) {
+	t.Helper()
+
+	go func() {
+		_ = m.Start(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.ctx != nil
+	}, time.Second, time.Millisecond)
+}
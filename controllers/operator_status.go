@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// staleResourceGracePeriod is the time after a resource's next scheduled sync,
+// after which it is considered to be stale.
+const staleResourceGracePeriod = time.Minute
+
+var (
+	_ manager.Runnable               = (*OperatorStatusReporter)(nil)
+	_ manager.LeaderElectionRunnable = (*OperatorStatusReporter)(nil)
+)
+
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=operatorstatuses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=operatorstatuses/status,verbs=get;update;patch
+
+// OperatorStatusReporter periodically updates the singleton OperatorStatus
+// resource, and the vso_up metric, with a summary of the operator's capacity
+// and health. It is meant to be added to the manager, and only runs on the
+// leader.
+type OperatorStatusReporter struct {
+	Client             client.Client
+	ClientFactory      vault.CachingClientFactory
+	SyncStatusRegistry *SyncStatusRegistry
+	// Gatherer provides the controller-runtime workqueue metrics, from which the
+	// reconcile backlog is computed.
+	Gatherer prometheus.Gatherer
+	// EventWatcherCountFunc returns the number of running Vault event watchers.
+	EventWatcherCountFunc func() int
+	// Identity of the operator instance.
+	Identity string
+	// Interval between status updates.
+	Interval time.Duration
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *OperatorStatusReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (r *OperatorStatusReporter) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("operatorStatusReporter")
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.report(ctx); err != nil {
+			logger.Error(err, "Failed to report the operator status")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *OperatorStatusReporter) report(ctx context.Context) error {
+	status, err := r.status()
+	if err != nil {
+		return err
+	}
+
+	metrics.SetOperatorUp(status.Healthy)
+
+	o := &secretsv1beta1.OperatorStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: common.OperatorNamespace,
+			Name:      consts.NameDefault,
+		},
+	}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(o), o); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err := r.Client.Create(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	o.Status = status
+	return r.Client.Status().Update(ctx, o)
+}
+
+func (r *OperatorStatusReporter) status() (secretsv1beta1.OperatorStatusStatus, error) {
+	now := nowFunc()
+	status := secretsv1beta1.OperatorStatusStatus{
+		Leader:      r.Identity,
+		LastUpdated: metav1.NewTime(now),
+	}
+
+	backlog, err := r.backlog()
+	if err != nil {
+		return status, err
+	}
+	status.Backlog = backlog
+
+	for _, s := range r.SyncStatusRegistry.List() {
+		if !s.Healthy || (s.NextSyncTime != nil &&
+			now.After(s.NextSyncTime.Add(staleResourceGracePeriod))) {
+			status.StaleResources++
+		}
+	}
+
+	if r.ClientFactory != nil {
+		stats := r.ClientFactory.Stats()
+		status.ClientCache = secretsv1beta1.OperatorStatusClientCache{
+			Length:         stats.Length,
+			Size:           stats.Size,
+			TaintedClients: stats.Tainted,
+		}
+	}
+
+	if r.EventWatcherCountFunc != nil {
+		status.EventWatchers = r.EventWatcherCountFunc()
+	}
+
+	status.Healthy = status.StaleResources == 0 && status.ClientCache.TaintedClients == 0
+
+	return status, nil
+}
+
+// backlog returns the depth of each controller's workqueue. Controllers are
+// named after the lowercase kind of the resource they reconcile, which is
+// mapped back to the kind using the client's scheme.
+func (r *OperatorStatusReporter) backlog() ([]secretsv1beta1.OperatorStatusBacklog, error) {
+	families, err := r.Gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := map[string]string{}
+	for gvk := range r.Client.Scheme().AllKnownTypes() {
+		if gvk.GroupVersion() == secretsv1beta1.GroupVersion {
+			kinds[strings.ToLower(gvk.Kind)] = gvk.Kind
+		}
+	}
+
+	var backlog []secretsv1beta1.OperatorStatusBacklog
+	for controller, depth := range workqueueDepths(families) {
+		kind, ok := kinds[controller]
+		if !ok {
+			continue
+		}
+		backlog = append(backlog, secretsv1beta1.OperatorStatusBacklog{
+			Kind:  kind,
+			Depth: depth,
+		})
+	}
+
+	sort.Slice(backlog, func(i, j int) bool {
+		return backlog[i].Kind < backlog[j].Kind
+	})
+
+	return backlog, nil
+}
+
+// workqueueDepths returns the depth of each controller's workqueue, keyed by
+// the controller name, from the gathered controller-runtime workqueue metrics.
+func workqueueDepths(families []*io_prometheus_client.MetricFamily) map[string]int {
+	name := prometheus.BuildFQName("", ctrlmetrics.WorkQueueSubsystem, ctrlmetrics.DepthKey)
+	depths := map[string]int{}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "name" {
+					depths[l.GetValue()] = int(m.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+
+	return depths
+}
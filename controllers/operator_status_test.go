@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+type stubCachingClientFactory struct {
+	vault.CachingClientFactory
+	stats vault.ClientCacheStats
+}
+
+func (f *stubCachingClientFactory) Stats() vault.ClientCacheStats {
+	return f.stats
+}
+
+func TestOperatorStatusReporter_report(t *testing.T) {
+	ctx := context.Background()
+
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workqueue_depth",
+	}, []string{"name", "controller"})
+	depth.WithLabelValues("vaultstaticsecret", "vaultstaticsecret").Set(3)
+	depth.WithLabelValues("vaultpkisecret", "vaultpkisecret").Set(1)
+	depth.WithLabelValues("unknown", "unknown").Set(5)
+	gatherer := prometheus.NewRegistry()
+	gatherer.MustRegister(depth)
+
+	overdue := nowFunc().Add(-2 * staleResourceGracePeriod)
+	upcoming := nowFunc().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		statuses    map[client.ObjectKey]SyncStatus
+		stats       vault.ClientCacheStats
+		wantHealthy bool
+		wantStale   int
+	}{
+		{
+			name: "healthy",
+			statuses: map[client.ObjectKey]SyncStatus{
+				{Namespace: "foo", Name: "a"}: {Healthy: true, NextSyncTime: &upcoming},
+				{Namespace: "foo", Name: "b"}: {Healthy: true},
+			},
+			stats: vault.ClientCacheStats{
+				Length: 2,
+				Size:   10,
+			},
+			wantHealthy: true,
+		},
+		{
+			name: "stale",
+			statuses: map[client.ObjectKey]SyncStatus{
+				{Namespace: "foo", Name: "a"}: {Healthy: true, NextSyncTime: &overdue},
+				{Namespace: "foo", Name: "b"}: {Healthy: false},
+				{Namespace: "foo", Name: "c"}: {Healthy: true, NextSyncTime: &upcoming},
+			},
+			wantStale: 2,
+		},
+		{
+			name: "tainted-clients",
+			stats: vault.ClientCacheStats{
+				Length:  2,
+				Size:    10,
+				Tainted: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testutils.NewFakeClientBuilder().
+				WithStatusSubresource(&secretsv1beta1.OperatorStatus{}).
+				Build()
+
+			registry := NewSyncStatusRegistry()
+			for objKey, s := range tt.statuses {
+				registry.update(VaultStaticSecret, objKey, true, func(e *SyncStatus) {
+					e.Healthy = s.Healthy
+					e.NextSyncTime = s.NextSyncTime
+				})
+			}
+
+			r := &OperatorStatusReporter{
+				Client:             c,
+				ClientFactory:      &stubCachingClientFactory{stats: tt.stats},
+				SyncStatusRegistry: registry,
+				Gatherer:           gatherer,
+				EventWatcherCountFunc: func() int {
+					return 4
+				},
+				Identity: "vso-0",
+				Interval: time.Minute,
+			}
+
+			// report twice to cover both the creation and the update of the resource.
+			for i := 0; i < 2; i++ {
+				require.NoError(t, r.report(ctx))
+			}
+
+			var got secretsv1beta1.OperatorStatus
+			require.NoError(t, c.Get(ctx, client.ObjectKey{
+				Namespace: common.OperatorNamespace,
+				Name:      consts.NameDefault,
+			}, &got))
+
+			assert.Equal(t, "vso-0", got.Status.Leader)
+			assert.Equal(t, tt.wantHealthy, got.Status.Healthy)
+			assert.Equal(t, tt.wantStale, got.Status.StaleResources)
+			assert.Equal(t, 4, got.Status.EventWatchers)
+			assert.Equal(t, secretsv1beta1.OperatorStatusClientCache{
+				Length:         tt.stats.Length,
+				Size:           tt.stats.Size,
+				TaintedClients: tt.stats.Tainted,
+			}, got.Status.ClientCache)
+			assert.Equal(t, []secretsv1beta1.OperatorStatusBacklog{
+				{Kind: "VaultPKISecret", Depth: 1},
+				{Kind: "VaultStaticSecret", Depth: 3},
+			}, got.Status.Backlog)
+			assert.False(t, got.Status.LastUpdated.IsZero())
+
+			wantUp := float64(0)
+			if tt.wantHealthy {
+				wantUp = 1
+			}
+			var up io_prometheus_client.Metric
+			require.NoError(t, metrics.OperatorUp.Write(&up))
+			assert.Equal(t, wantUp, up.GetGauge().GetValue())
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
 )
 
@@ -23,6 +24,53 @@ func syncableSecretPredicate(syncReg *SyncRegistry) predicate.Predicate {
 	)
 }
 
+// forceSyncableSecretPredicate is the syncableSecretPredicate of the resources
+// whose annotation and label changes do not require a full sync. Only a change
+// to their consts.AnnotationForceSync annotation updates the SyncRegistry.
+func forceSyncableSecretPredicate(syncReg *SyncRegistry) predicate.Predicate {
+	return predicate.Or(
+		// must come first, since predicate.Or returns on the first match.
+		&forceSyncAnnotationPredicate{syncReg: syncReg},
+		syncableSecretPredicate(nil),
+	)
+}
+
+// forceSyncAnnotationPredicate filters the update events of the resources
+// whose consts.AnnotationForceSync annotation has changed. On change update
+// the SyncRegistry if set.
+type forceSyncAnnotationPredicate struct {
+	syncReg *SyncRegistry
+}
+
+func (p *forceSyncAnnotationPredicate) Create(_ event.CreateEvent) bool {
+	return false
+}
+
+func (p *forceSyncAnnotationPredicate) Delete(_ event.DeleteEvent) bool {
+	return false
+}
+
+func (p *forceSyncAnnotationPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	newValue, ok := e.ObjectNew.GetAnnotations()[consts.AnnotationForceSync]
+	if !ok || newValue == e.ObjectOld.GetAnnotations()[consts.AnnotationForceSync] {
+		return false
+	}
+
+	if p.syncReg != nil {
+		p.syncReg.Add(client.ObjectKeyFromObject(e.ObjectNew))
+	}
+
+	return true
+}
+
+func (p *forceSyncAnnotationPredicate) Generic(_ event.GenericEvent) bool {
+	return false
+}
+
 type annotationChangedPredicate struct {
 	syncReg *SyncRegistry
 	predicate.AnnotationChangedPredicate
@@ -90,3 +138,30 @@ func (s *secretsPredicate) Update(_ event.UpdateEvent) bool {
 func (s *secretsPredicate) Generic(_ event.GenericEvent) bool {
 	return false
 }
+
+// secretsDriftPredicate filters the events of the Secrets that are owned by a
+// syncable secret resource down to their deletion, and to any changes that were
+// not made by the operator itself.
+type secretsDriftPredicate struct{}
+
+func (s *secretsDriftPredicate) Create(_ event.CreateEvent) bool {
+	return false
+}
+
+func (s *secretsDriftPredicate) Delete(evt event.DeleteEvent) bool {
+	return helpers.HasOwnerLabels(evt.Object)
+}
+
+func (s *secretsDriftPredicate) Update(evt event.UpdateEvent) bool {
+	if evt.ObjectOld == nil || evt.ObjectNew == nil {
+		return false
+	}
+
+	return helpers.HasOwnerLabels(evt.ObjectNew) &&
+		evt.ObjectOld.GetResourceVersion() != evt.ObjectNew.GetResourceVersion() &&
+		!helpers.IsSyncedSecretVersion(evt.ObjectNew)
+}
+
+func (s *secretsDriftPredicate) Generic(_ event.GenericEvent) bool {
+	return false
+}
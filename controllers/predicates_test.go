@@ -16,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
 )
 
@@ -239,6 +240,104 @@ func Test_labelChangedPredicate_Update(t *testing.T) {
 	}
 }
 
+func Test_forceSyncableSecretPredicate_Update(t *testing.T) {
+	t.Parallel()
+
+	objectOldDefault := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+			Annotations: map[string]string{
+				"foo": "baz",
+			},
+		},
+	}
+	objectNewForceSync := objectOldDefault.DeepCopy()
+	objectNewForceSync.Annotations[consts.AnnotationForceSync] = "2024-01-02T03:04:05Z"
+	objectNewForceSyncAgain := objectOldDefault.DeepCopy()
+	objectNewForceSyncAgain.Annotations[consts.AnnotationForceSync] = "2024-01-02T03:04:06Z"
+	objectNewAnnotations := objectOldDefault.DeepCopy()
+	objectNewAnnotations.Annotations["buz"] = "baz"
+
+	tests := []testCaseAnnoLabelChanged{
+		{
+			name:    "force-sync-added",
+			syncReg: NewSyncRegistry(),
+			evt: event.UpdateEvent{
+				ObjectOld: objectOldDefault,
+				ObjectNew: objectNewForceSync,
+			},
+			want: true,
+			wantRegistryObjectKeys: []client.ObjectKey{
+				{
+					Namespace: "default",
+					Name:      "foo",
+				},
+			},
+		},
+		{
+			name:    "force-sync-changed",
+			syncReg: NewSyncRegistry(),
+			evt: event.UpdateEvent{
+				ObjectOld: objectNewForceSync,
+				ObjectNew: objectNewForceSyncAgain,
+			},
+			want: true,
+			wantRegistryObjectKeys: []client.ObjectKey{
+				{
+					Namespace: "default",
+					Name:      "foo",
+				},
+			},
+		},
+		{
+			name:    "force-sync-removed",
+			syncReg: NewSyncRegistry(),
+			evt: event.UpdateEvent{
+				ObjectOld: objectNewForceSync,
+				ObjectNew: objectOldDefault,
+			},
+			want: true,
+		},
+		{
+			name:    "other-annotation-changed",
+			syncReg: NewSyncRegistry(),
+			evt: event.UpdateEvent{
+				ObjectOld: objectOldDefault,
+				ObjectNew: objectNewAnnotations,
+			},
+			want: true,
+		},
+		{
+			name:    "no-update",
+			syncReg: NewSyncRegistry(),
+			evt: event.UpdateEvent{
+				ObjectOld: objectNewForceSync,
+				ObjectNew: objectNewForceSync,
+			},
+			want: false,
+		},
+		{
+			name: "force-sync-added-without-sync-registry",
+			evt: event.UpdateEvent{
+				ObjectOld: objectOldDefault,
+				ObjectNew: objectNewForceSync,
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			tt.newPredicateFunc = forceSyncableSecretPredicate
+
+			t.Parallel()
+
+			assertAnnoLabelChangedOnUpdate(t, tt)
+		})
+	}
+}
+
 func assertAnnoLabelChangedOnUpdate(t *testing.T, tt testCaseAnnoLabelChanged) {
 	t.Helper()
 
@@ -299,3 +398,55 @@ func Test_secretsPredicate_Delete(t *testing.T) {
 		})
 	}
 }
+
+func Test_secretsDriftPredicate_Update(t *testing.T) {
+	t.Parallel()
+
+	newSecret := func(labels map[string]string, resourceVersion string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            "drift",
+				Labels:          labels,
+				ResourceVersion: resourceVersion,
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		evt  event.UpdateEvent
+		want bool
+	}{
+		{
+			name: "changed",
+			evt: event.UpdateEvent{
+				ObjectOld: newSecret(helpers.OwnerLabels, "1"),
+				ObjectNew: newSecret(helpers.OwnerLabels, "2"),
+			},
+			want: true,
+		},
+		{
+			name: "unchanged",
+			evt: event.UpdateEvent{
+				ObjectOld: newSecret(helpers.OwnerLabels, "1"),
+				ObjectNew: newSecret(helpers.OwnerLabels, "1"),
+			},
+			want: false,
+		},
+		{
+			name: "not-owned",
+			evt: event.UpdateEvent{
+				ObjectOld: newSecret(nil, "1"),
+				ObjectNew: newSecret(nil, "2"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &secretsDriftPredicate{}
+			assert.Equalf(t, tt.want, s.Update(tt.evt), "Update(%v)", tt.evt)
+		})
+	}
+}
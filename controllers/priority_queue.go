@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"container/heap"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// defaultPriorityQueueMaxWait is the default duration after which a routine
+// request is served ahead of any expedited requests.
+const defaultPriorityQueueMaxWait = time.Minute * 2
+
+var _ workqueue.Queue[reconcile.Request] = (*priorityQueue)(nil)
+
+// PriorityFunc returns the deadline of the request, and true if the request
+// should be expedited. Expedited requests are served in the order of their
+// deadlines, ahead of all routine requests.
+type PriorityFunc func(req reconcile.Request) (time.Time, bool)
+
+// NewPriorityQueueFunc returns a constructor for a controller's workqueue, to
+// be set on controller.Options.NewQueue. The workqueue serves the requests
+// that priority expedites ahead of the routine requests, which are served in
+// FIFO order. A routine request that has waited for longer than maxWait is
+// served ahead of the expedited requests, so that the routine requests are
+// never starved. The workqueue metrics of the default controller workqueue are
+// preserved.
+func NewPriorityQueueFunc(kind ResourceKind, priority PriorityFunc, maxWait time.Duration) func(string, workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	if maxWait <= 0 {
+		maxWait = defaultPriorityQueueMaxWait
+	}
+
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		q := newPriorityQueue(metricsController(kind), priority, maxWait)
+		return workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter,
+			workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+				Name: controllerName,
+				DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(
+					workqueue.TypedDelayingQueueConfig[reconcile.Request]{
+						Name: controllerName,
+						Queue: workqueue.NewTypedWithConfig(
+							workqueue.TypedQueueConfig[reconcile.Request]{
+								Name:  controllerName,
+								Queue: q,
+							},
+						),
+					},
+				),
+			},
+		)
+	}
+}
+
+// priorityQueueEntry is an entry of one of the priorityQueue's classes. An
+// entry is stale if its seq no longer matches the seq of its queued request, in
+// which case it is skipped.
+type priorityQueueEntry struct {
+	req reconcile.Request
+	seq uint64
+	// deadline of an expedited request.
+	deadline time.Time
+	// addedAt is the time a routine request was added.
+	addedAt time.Time
+}
+
+// priorityQueueItem tracks the current entry of a queued request.
+type priorityQueueItem struct {
+	seq       uint64
+	expedited bool
+}
+
+// priorityQueue implements the storage of a workqueue.Typed. Its functions are
+// always called with the workqueue's lock held.
+type priorityQueue struct {
+	controller string
+	priority   PriorityFunc
+	maxWait    time.Duration
+	items      map[reconcile.Request]priorityQueueItem
+	expedited  expeditedHeap
+	routine    []priorityQueueEntry
+	seq        uint64
+	now        func() time.Time
+}
+
+func newPriorityQueue(controller string, priority PriorityFunc, maxWait time.Duration) *priorityQueue {
+	return &priorityQueue{
+		controller: controller,
+		priority:   priority,
+		maxWait:    maxWait,
+		items:      make(map[reconcile.Request]priorityQueueItem),
+		now:        nowFunc,
+	}
+}
+
+// Push implements workqueue.Queue.
+func (q *priorityQueue) Push(req reconcile.Request) {
+	q.seq++
+	if deadline, ok := q.priority(req); ok {
+		q.items[req] = priorityQueueItem{seq: q.seq, expedited: true}
+		heap.Push(&q.expedited, priorityQueueEntry{req: req, seq: q.seq, deadline: deadline})
+		return
+	}
+
+	q.items[req] = priorityQueueItem{seq: q.seq}
+	q.routine = append(q.routine, priorityQueueEntry{req: req, seq: q.seq, addedAt: q.now()})
+}
+
+// Touch implements workqueue.Queue. It is called when a request that is
+// already queued is added again, a routine request may be expedited.
+func (q *priorityQueue) Touch(req reconcile.Request) {
+	if item, ok := q.items[req]; !ok || item.expedited {
+		return
+	}
+
+	deadline, ok := q.priority(req)
+	if !ok {
+		return
+	}
+
+	// the request's routine entry becomes stale.
+	q.seq++
+	q.items[req] = priorityQueueItem{seq: q.seq, expedited: true}
+	heap.Push(&q.expedited, priorityQueueEntry{req: req, seq: q.seq, deadline: deadline})
+}
+
+// Len implements workqueue.Queue.
+func (q *priorityQueue) Len() int {
+	return len(q.items)
+}
+
+// Pop implements workqueue.Queue. It is only called when Len() > 0.
+func (q *priorityQueue) Pop() reconcile.Request {
+	q.dropStale()
+
+	var e priorityQueueEntry
+	switch {
+	case len(q.expedited) == 0:
+		e = q.popRoutine()
+	case len(q.routine) > 0 && q.now().Sub(q.routine[0].addedAt) >= q.maxWait:
+		// the routine request has waited for too long, it is served ahead of the
+		// expedited requests.
+		metrics.IncReconcileQueueStarved(q.controller)
+		e = q.popRoutine()
+	default:
+		metrics.IncReconcileQueueExpedited(q.controller)
+		e = heap.Pop(&q.expedited).(priorityQueueEntry)
+	}
+
+	delete(q.items, e.req)
+	return e.req
+}
+
+func (q *priorityQueue) popRoutine() priorityQueueEntry {
+	e := q.routine[0]
+	// allow the entry to be garbage collected.
+	q.routine[0] = priorityQueueEntry{}
+	q.routine = q.routine[1:]
+	return e
+}
+
+// dropStale removes the stale entries from the heads of both classes.
+func (q *priorityQueue) dropStale() {
+	for len(q.routine) > 0 && q.items[q.routine[0].req].seq != q.routine[0].seq {
+		q.popRoutine()
+	}
+	for len(q.expedited) > 0 && q.items[q.expedited[0].req].seq != q.expedited[0].seq {
+		heap.Pop(&q.expedited)
+	}
+}
+
+// expeditedHeap is a min-heap of expedited requests, ordered by deadline.
+type expeditedHeap []priorityQueueEntry
+
+func (h expeditedHeap) Len() int { return len(h) }
+
+func (h expeditedHeap) Less(i, j int) bool {
+	if h[i].deadline.Equal(h[j].deadline) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h expeditedHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *expeditedHeap) Push(x any) {
+	*h = append(*h, x.(priorityQueueEntry))
+}
+
+func (h *expeditedHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestRequest(name string) reconcile.Request {
+	return reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "default",
+			Name:      name,
+		},
+	}
+}
+
+func Test_priorityQueue(t *testing.T) {
+	t.Parallel()
+
+	then := time.Unix(nowFunc().Unix(), 0)
+	now := then
+	deadlines := map[string]time.Time{}
+	priority := func(req reconcile.Request) (time.Time, bool) {
+		d, ok := deadlines[req.Name]
+		return d, ok
+	}
+
+	q := newPriorityQueue("test", priority, time.Minute)
+	q.now = func() time.Time {
+		return now
+	}
+
+	popAll := func() []string {
+		var result []string
+		for q.Len() > 0 {
+			result = append(result, q.Pop().Name)
+		}
+		return result
+	}
+
+	// routine requests are served in FIFO order, after the expedited requests,
+	// which are served in the order of their deadlines.
+	deadlines["short-ttl"] = then.Add(time.Minute)
+	deadlines["long-ttl"] = then.Add(time.Hour)
+	for _, name := range []string{"routine-1", "long-ttl", "routine-2", "short-ttl"} {
+		q.Push(newTestRequest(name))
+	}
+	require.Equal(t, 4, q.Len())
+	assert.Equal(t, []string{"short-ttl", "long-ttl", "routine-1", "routine-2"}, popAll())
+
+	// a routine request is expedited when it is touched.
+	delete(deadlines, "short-ttl")
+	q.Push(newTestRequest("short-ttl"))
+	q.Push(newTestRequest("routine-1"))
+	q.Push(newTestRequest("long-ttl"))
+	deadlines["short-ttl"] = then.Add(time.Minute)
+	q.Touch(newTestRequest("short-ttl"))
+	q.Touch(newTestRequest("routine-1"))
+	require.Equal(t, 3, q.Len())
+	assert.Equal(t, []string{"short-ttl", "long-ttl", "routine-1"}, popAll())
+
+	// a starved routine request is served ahead of the expedited requests.
+	q.Push(newTestRequest("routine-1"))
+	q.Push(newTestRequest("long-ttl"))
+	now = now.Add(time.Minute)
+	q.Push(newTestRequest("short-ttl"))
+	assert.Equal(t, []string{"routine-1", "short-ttl", "long-ttl"}, popAll())
+}
+
+func TestNewPriorityQueueFunc(t *testing.T) {
+	t.Parallel()
+
+	deadline := nowFunc().Add(time.Minute)
+	priority := func(req reconcile.Request) (time.Time, bool) {
+		return deadline, req.Name == "expedited"
+	}
+
+	q := NewPriorityQueueFunc(VaultDynamicSecret, priority, 0)("test-priority-queue",
+		workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	t.Cleanup(q.ShutDown)
+
+	q.Add(newTestRequest("routine"))
+	q.Add(newTestRequest("expedited"))
+	// duplicates are ignored.
+	q.Add(newTestRequest("routine"))
+	require.Equal(t, 2, q.Len())
+
+	var got []string
+	for q.Len() > 0 {
+		req, shutdown := q.Get()
+		require.False(t, shutdown)
+		got = append(got, req.Name)
+		q.Done(req)
+	}
+	assert.Equal(t, []string{"expedited", "routine"}, got)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// defaultReconcileShedderInterval is the default interval between samples of
+// the controllers' workqueue depth.
+const defaultReconcileShedderInterval = time.Second * 5
+
+var (
+	_ manager.Runnable               = (*ReconcileShedder)(nil)
+	_ manager.LeaderElectionRunnable = (*ReconcileShedder)(nil)
+)
+
+// ReconcileShedder provides a load shedding policy for the syncable secret
+// controllers. Once the backlog of a controller's workqueue reaches the
+// Threshold, the reconcile requests for low priority kinds and namespaces are
+// deferred, rather than reconciled. This keeps the most critical secrets within
+// their sync horizons while the operator recovers from a storm of requests. It
+// is meant to be added to the manager, and only runs on the leader.
+type ReconcileShedder struct {
+	// Gatherer provides the controller-runtime workqueue metrics, from which the
+	// backlog of each controller is sampled.
+	Gatherer prometheus.Gatherer
+	// Threshold is the workqueue depth at which a controller starts shedding its
+	// low priority requests. Shedding is disabled if it is not positive.
+	Threshold int
+	// Kinds whose reconcile requests are low priority.
+	Kinds []ResourceKind
+	// Namespaces whose reconcile requests are low priority.
+	Namespaces []string
+	// DeferAfter is the duration after which a shed request is requeued.
+	DeferAfter time.Duration
+	// Interval between samples of the workqueue depths.
+	Interval time.Duration
+
+	mu     sync.RWMutex
+	depths map[string]int
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *ReconcileShedder) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (s *ReconcileShedder) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("reconcileShedder")
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultReconcileShedderInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sample(); err != nil {
+			logger.Error(err, "Failed to sample the workqueue depths")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shed returns true along with the duration after which the request for o
+// should be requeued, if the reconciliation of o should be deferred. It is
+// safe to call on a nil ReconcileShedder.
+func (s *ReconcileShedder) Shed(ctx context.Context, kind ResourceKind, o client.Object) (time.Duration, bool) {
+	if s == nil || s.Threshold <= 0 {
+		return 0, false
+	}
+
+	controller := metricsController(kind)
+	if !s.shedding(controller) {
+		return 0, false
+	}
+
+	var reason string
+	switch {
+	case slices.Contains(s.Kinds, kind):
+		reason = metrics.ReconcileShedReasonKind
+	case slices.Contains(s.Namespaces, o.GetNamespace()):
+		reason = metrics.ReconcileShedReasonNamespace
+	default:
+		return 0, false
+	}
+
+	metrics.IncReconcileShed(controller, reason)
+	deferAfter := computeHorizonWithJitter(s.DeferAfter)
+	log.FromContext(ctx).V(consts.LogLevelDebug).Info("Shedding low priority reconcile request",
+		"reason", reason, "deferAfter", deferAfter)
+
+	return deferAfter, true
+}
+
+// shedding returns true if the backlog of controller is at, or above, the
+// Threshold.
+func (s *ReconcileShedder) shedding(controller string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.depths[controller] >= s.Threshold
+}
+
+func (s *ReconcileShedder) sample() error {
+	families, err := s.Gatherer.Gather()
+	if err != nil {
+		return err
+	}
+
+	depths := workqueueDepths(families)
+	for _, kind := range []ResourceKind{
+		VaultStaticSecret,
+		VaultDynamicSecret,
+		VaultPKISecret,
+		VaultSSHCertificate,
+		VaultTransitKey,
+		HCPVaultSecretsApp,
+	} {
+		controller := metricsController(kind)
+		metrics.SetReconcileShedding(controller, depths[controller] >= s.Threshold)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depths = depths
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+func TestReconcileShedder_Shed(t *testing.T) {
+	ctx := context.Background()
+
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workqueue_depth",
+	}, []string{"name", "controller"})
+	depth.WithLabelValues("vaultstaticsecret", "vaultstaticsecret").Set(100)
+	depth.WithLabelValues("vaultdynamicsecret", "vaultdynamicsecret").Set(10)
+	depth.WithLabelValues("vaultpkisecret", "vaultpkisecret").Set(100)
+	gatherer := prometheus.NewRegistry()
+	gatherer.MustRegister(depth)
+
+	newObj := func(namespace string) *secretsv1beta1.VaultStaticSecret {
+		return &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "foo",
+			},
+		}
+	}
+
+	shedder := &ReconcileShedder{
+		Gatherer:   gatherer,
+		Threshold:  50,
+		Kinds:      []ResourceKind{VaultPKISecret},
+		Namespaces: []string{"dev"},
+		DeferAfter: time.Minute,
+	}
+	require.NoError(t, shedder.sample())
+
+	tests := []struct {
+		name       string
+		shedder    *ReconcileShedder
+		kind       ResourceKind
+		namespace  string
+		want       bool
+		wantReason string
+	}{
+		{
+			name:      "nil-shedder",
+			kind:      VaultStaticSecret,
+			namespace: "dev",
+		},
+		{
+			name:    "disabled",
+			shedder: &ReconcileShedder{Namespaces: []string{"dev"}},
+			kind:    VaultStaticSecret,
+		},
+		{
+			name:       "low-priority-kind",
+			shedder:    shedder,
+			kind:       VaultPKISecret,
+			namespace:  "prod",
+			want:       true,
+			wantReason: metrics.ReconcileShedReasonKind,
+		},
+		{
+			name:       "low-priority-namespace",
+			shedder:    shedder,
+			kind:       VaultStaticSecret,
+			namespace:  "dev",
+			want:       true,
+			wantReason: metrics.ReconcileShedReasonNamespace,
+		},
+		{
+			name:      "high-priority",
+			shedder:   shedder,
+			kind:      VaultStaticSecret,
+			namespace: "prod",
+		},
+		{
+			name:      "below-threshold",
+			shedder:   shedder,
+			kind:      VaultDynamicSecret,
+			namespace: "dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shed prometheus.Counter
+			var before float64
+			if tt.wantReason != "" {
+				shed = metrics.ReconcileShed.WithLabelValues(metricsController(tt.kind), tt.wantReason)
+				before = metricValue(t, shed)
+			}
+
+			got, ok := tt.shedder.Shed(ctx, tt.kind, newObj(tt.namespace))
+			assert.Equal(t, tt.want, ok)
+			if !tt.want {
+				assert.Zero(t, got)
+				return
+			}
+
+			assert.Greater(t, got, time.Duration(0))
+			assert.LessOrEqual(t, got, time.Minute)
+			assert.Equal(t, before+1, metricValue(t, shed))
+		})
+	}
+
+	assert.Equal(t, float64(1), metricValue(t,
+		metrics.ReconcileShedding.WithLabelValues(metricsController(VaultStaticSecret))))
+	assert.Equal(t, float64(0), metricValue(t,
+		metrics.ReconcileShedding.WithLabelValues(metricsController(VaultDynamicSecret))))
+}
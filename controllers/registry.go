@@ -4,6 +4,8 @@
 package controllers
 
 import (
+	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -21,6 +23,14 @@ const (
 	HCPVaultSecretsApp
 	VaultAuth
 	VaultAuthGlobal
+	ConfigMap
+	VaultConnection
+	VaultSSHCertificate
+	VaultTransitKey
+	VaultSecretExport
+	Secret
+	ClusterTrustBundle
+	VaultEventSubscription
 )
 
 func (k ResourceKind) String() string {
@@ -39,11 +49,54 @@ func (k ResourceKind) String() string {
 		return "VaultAuth"
 	case VaultAuthGlobal:
 		return "VaultAuthGlobal"
+	case ConfigMap:
+		return "ConfigMap"
+	case VaultConnection:
+		return "VaultConnection"
+	case VaultSSHCertificate:
+		return "VaultSSHCertificate"
+	case VaultTransitKey:
+		return "VaultTransitKey"
+	case VaultSecretExport:
+		return "VaultSecretExport"
+	case Secret:
+		return "Secret"
+	case ClusterTrustBundle:
+		return "ClusterTrustBundle"
+	case VaultEventSubscription:
+		return "VaultEventSubscription"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseResourceKind returns the ResourceKind for its name, e.g. VaultStaticSecret.
+func ParseResourceKind(s string) (ResourceKind, error) {
+	for _, k := range []ResourceKind{
+		SecretTransformation,
+		VaultDynamicSecret,
+		VaultStaticSecret,
+		VaultPKISecret,
+		HCPVaultSecretsApp,
+		VaultAuth,
+		VaultAuthGlobal,
+		ConfigMap,
+		VaultConnection,
+		VaultSSHCertificate,
+		VaultTransitKey,
+		VaultSecretExport,
+		Secret,
+		ClusterTrustBundle,
+		VaultEventSubscription,
+	} {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported resource kind %q", s)
+}
+
 type ResourceReferenceCache interface {
 	Set(ResourceKind, client.ObjectKey, ...client.ObjectKey)
 	Get(ResourceKind, client.ObjectKey) []client.ObjectKey
@@ -254,15 +307,16 @@ func (r *BackOffRegistry) Delete(objKey client.ObjectKey) bool {
 }
 
 // Get is a getter/setter that returns the BackOff for objKey.
-// If objKey is not in the set of registered objects, it will be added. Return
-// true if the sync backoff entry was created.
-func (r *BackOffRegistry) Get(objKey client.ObjectKey) (*BackOff, bool) {
+// If objKey is not in the set of registered objects, it will be added, opts
+// override the registry's options of the new entry. Return true if the sync
+// backoff entry was created.
+func (r *BackOffRegistry) Get(objKey client.ObjectKey, opts ...backoff.ExponentialBackOffOpts) (*BackOff, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	entry, ok := r.m[objKey]
 	if !ok {
-		bo := backoff.NewExponentialBackOff(r.opts...)
+		bo := backoff.NewExponentialBackOff(append(slices.Clone(r.opts), opts...)...)
 		// call Reset() to ensure that the initial interval is honoured.
 		bo.Reset()
 		entry = &BackOff{
@@ -278,11 +332,25 @@ func (r *BackOffRegistry) Get(objKey client.ObjectKey) (*BackOff, bool) {
 // BackOff.Reset, since elements in BackOffRegistry are meant to be ephemeral.
 type BackOff struct {
 	bo backoff.BackOff
+	// failures is the number of calls to NextBackOff.
+	failures int
+	// last is the duration returned by the last call to NextBackOff.
+	last time.Duration
+	// next is the time of the next retry, it is zero when the backoff has
+	// stopped.
+	next time.Time
 }
 
 // NextBackOff returns the next backoff duration.
 func (s *BackOff) NextBackOff() time.Duration {
-	return s.bo.NextBackOff()
+	d := s.bo.NextBackOff()
+	s.failures++
+	s.last = d
+	s.next = time.Time{}
+	if d != backoff.Stop {
+		s.next = nowFunc().Add(d)
+	}
+	return d
 }
 
 // DefaultExponentialBackOffOpts returns the default exponential options for the
@@ -615,3 +615,14 @@ func TestBackOffRegistry_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestParseResourceKind(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseResourceKind("VaultPKISecret")
+	require.NoError(t, err)
+	assert.Equal(t, VaultPKISecret, got)
+
+	_, err = ParseResourceKind("vaultpkisecret")
+	assert.EqualError(t, err, `unsupported resource kind "vaultpkisecret"`)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/cron"
+)
+
+const (
+	// rolloutRestartWindowHorizon is how far ahead the start of the next
+	// rollout-restart window is searched for.
+	rolloutRestartWindowHorizon = 31 * 24 * time.Hour
+	// minRolloutRestartWindowDuration is the minimum duration of a
+	// rollout-restart window, the windows start on the minute.
+	minRolloutRestartWindowDuration = time.Minute
+	reasonRolloutRestartWindow      = "RolloutRestartWindow"
+)
+
+func rolloutRestartWindows(o client.Object) []secretsv1beta1.RolloutRestartWindow {
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		return t.Spec.RolloutRestartWindows
+	case *secretsv1beta1.VaultDynamicSecret:
+		return t.Spec.RolloutRestartWindows
+	case *secretsv1beta1.VaultPKISecret:
+		return t.Spec.RolloutRestartWindows
+	case *secretsv1beta1.VaultSSHCertificate:
+		return t.Spec.RolloutRestartWindows
+	case *secretsv1beta1.VaultTransitKey:
+		return t.Spec.RolloutRestartWindows
+	case *secretsv1beta1.HCPVaultSecretsApp:
+		return t.Spec.RolloutRestartWindows
+	default:
+		return nil
+	}
+}
+
+// nextRolloutRestartWindow returns true along with the start of the next
+// rollout-restart window of o, if o has rollout-restart windows and none of
+// them is active at now. If no window starts within the
+// rolloutRestartWindowHorizon, the end of the horizon is returned instead.
+// Invalid windows are logged and ignored.
+func nextRolloutRestartWindow(ctx context.Context, o client.Object, now time.Time) (time.Time, bool) {
+	logger := log.FromContext(ctx)
+
+	var next time.Time
+	var restricted bool
+	for i, win := range rolloutRestartWindows(o) {
+		path := fmt.Sprintf(".spec.rolloutRestartWindows[%d]", i)
+		schedule, err := cron.Parse(win.Schedule)
+		if err != nil {
+			logger.Error(err, "Ignoring invalid rollout-restart window", "path", path+".schedule")
+			continue
+		}
+		d, err := parseDurationString(win.Duration, path+".duration", minRolloutRestartWindowDuration)
+		if err != nil {
+			logger.Error(err, "Ignoring invalid rollout-restart window")
+			continue
+		}
+
+		restricted = true
+		if start, ok := schedule.Prev(now, d); ok && start.Add(d).After(now) {
+			// the window is active.
+			return time.Time{}, false
+		}
+		if start, ok := schedule.Next(now, rolloutRestartWindowHorizon); ok {
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+		}
+	}
+
+	if !restricted {
+		return time.Time{}, false
+	}
+
+	if next.IsZero() {
+		next = now.Add(rolloutRestartWindowHorizon)
+	}
+
+	return next, true
+}
@@ -0,0 +1,197 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_nextRolloutRestartWindow(t *testing.T) {
+	t.Parallel()
+
+	// a Friday
+	now := time.Date(2024, 5, 3, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		windows []secretsv1beta1.RolloutRestartWindow
+		want    time.Time
+		wantOK  bool
+	}{
+		{
+			name: "no-windows",
+		},
+		{
+			name: "active",
+			windows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: "0 13 * * *",
+					Duration: "2h",
+				},
+			},
+		},
+		{
+			name: "ended",
+			windows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: "0 12 * * *",
+					Duration: "2h",
+				},
+			},
+			want:   time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name: "earliest",
+			windows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: "0 2 * * 1-5",
+					Duration: "3h",
+				},
+				{
+					Schedule: "0 22 * * *",
+					Duration: "1h",
+				},
+			},
+			want:   time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name: "time-zone",
+			windows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: "CRON_TZ=Europe/Berlin 0 22 * * *",
+					Duration: "1h",
+				},
+			},
+			want:   time.Date(2024, 5, 3, 20, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name: "beyond-horizon",
+			windows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: "0 0 30 2 *",
+					Duration: "1h",
+				},
+			},
+			want:   now.Add(rolloutRestartWindowHorizon),
+			wantOK: true,
+		},
+		{
+			name: "invalid-ignored",
+			windows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: "0 25 * * *",
+					Duration: "1h",
+				},
+				{
+					Schedule: "0 22 * * *",
+					Duration: "30s",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RolloutRestartWindows: tt.windows,
+				},
+			}
+			got, ok := nextRolloutRestartWindow(context.Background(), o, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "nextRolloutRestartWindow() = %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func TestFreezeWindow_HandleRolloutRestarts_rolloutRestartWindows(t *testing.T) {
+	ctx := context.Background()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "app",
+		},
+	}
+	// a daily window that starts in two hours.
+	start := time.Now().UTC().Add(2 * time.Hour)
+	o := &secretsv1beta1.VaultDynamicSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+		Spec: secretsv1beta1.VaultDynamicSecretSpec{
+			RolloutRestartTargets: []secretsv1beta1.RolloutRestartTarget{
+				{
+					Kind: "Deployment",
+					Name: deployment.Name,
+				},
+			},
+			RolloutRestartWindows: []secretsv1beta1.RolloutRestartWindow{
+				{
+					Schedule: fmt.Sprintf("%d %d * * *", start.Minute(), start.Hour()),
+					Duration: "1m",
+				},
+			},
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(o, deployment).WithStatusSubresource(o).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	restartedAt := func() string {
+		t.Helper()
+		var d appsv1.Deployment
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(deployment), &d))
+		return d.Spec.Template.Annotations[helpers.AnnotationRestartedAt]
+	}
+
+	var w *FreezeWindow
+	deferAfter := w.HandleRolloutRestarts(ctx, c, nil, VaultDynamicSecret, o, recorder)
+	assert.Greater(t, deferAfter, time.Hour)
+	assert.Empty(t, restartedAt())
+	assert.Len(t, recorder.Events, 1)
+
+	var got secretsv1beta1.VaultDynamicSecret
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeRolloutRestartDeferred, got.Status.Conditions[0].Type)
+	assert.Equal(t, reasonRolloutRestartWindow, got.Status.Conditions[0].Reason)
+
+	// outside the window, the rollout-restart remains pending.
+	pendingAfter, err := w.HandlePending(ctx, c, nil, &got, recorder)
+	require.NoError(t, err)
+	assert.Greater(t, pendingAfter, time.Hour)
+	assert.Empty(t, restartedAt())
+
+	// within the window, the deferred rollout-restart is triggered.
+	got.Spec.RolloutRestartWindows = []secretsv1beta1.RolloutRestartWindow{
+		{
+			Schedule: "* * * * *",
+			Duration: "2m",
+		},
+	}
+	pendingAfter, err = w.HandlePending(ctx, c, nil, &got, recorder)
+	require.NoError(t, err)
+	assert.Zero(t, pendingAfter)
+	assert.NotEmpty(t, restartedAt())
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	assert.Empty(t, got.Status.Conditions)
+}
@@ -5,6 +5,10 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -12,6 +16,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -19,20 +24,24 @@ import (
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
 	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/template"
 )
 
 // SecretTransformationReconciler reconciles a SecretTransformation object
 type SecretTransformationReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	Recorder record.EventRecorder
+	Scheme         *runtime.Scheme
+	Recorder       record.EventRecorder
+	referenceCache ResourceReferenceCache
 }
 
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=secrettransformations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=secrettransformations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=secrettransformations/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -47,6 +56,7 @@ func (r *SecretTransformationReconciler) Reconcile(ctx context.Context, req ctrl
 	o, err := common.GetSecretTransformation(ctx, r.Client, req.NamespacedName)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
+			r.referenceCache.Remove(ConfigMap, req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
@@ -57,12 +67,21 @@ func (r *SecretTransformationReconciler) Reconcile(ctx context.Context, req ctrl
 	if o.GetDeletionTimestamp() != nil {
 		logger.Info("Got deletion timestamp", "obj", o)
 		metrics.DeleteResourceStatus("secrettransformation", o)
+		r.referenceCache.Remove(ConfigMap, req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
 	o.Status.Valid = ptr.To(true)
 	o.Status.Error = ""
 	errs := ValidateSecretTransformation(ctx, o)
+
+	r.referenceCache.Set(ConfigMap, req.NamespacedName, sourceTemplateConfigMapKeys(o)...)
+	digest, err := r.validateConfigMapSourceTemplates(ctx, o)
+	if err != nil {
+		errs = errors.Join(errs, err)
+	}
+	o.Status.SourceTemplatesDigest = digest
+
 	if errs != nil {
 		o.Status.Valid = ptr.To(false)
 		o.Status.Error = errs.Error()
@@ -88,10 +107,71 @@ func (r *SecretTransformationReconciler) updateStatus(ctx context.Context, o *se
 	return nil
 }
 
+// validateConfigMapSourceTemplates reads and validates the source templates
+// that are sourced from ConfigMaps. It returns the digest of their text, it is
+// empty if there are no such templates.
+func (r *SecretTransformationReconciler) validateConfigMapSourceTemplates(ctx context.Context, o *secretsv1beta1.SecretTransformation) (string, error) {
+	var errs error
+	var found bool
+	h := sha256.New()
+	stmpl := template.NewSecretTemplate(o.Name)
+	objKey := client.ObjectKeyFromObject(o)
+	for idx, tmpl := range o.Spec.SourceTemplates {
+		if tmpl.ConfigMapRef == nil {
+			continue
+		}
+
+		found = true
+		name := tmpl.Name
+		if name == "" {
+			name = fmt.Sprintf("%s/%d", objKey, idx)
+		}
+
+		text, err := helpers.GetSourceTemplateText(ctx, r.Client, o.Namespace, tmpl)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		if err := stmpl.Parse(name, text); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", name, text)
+	}
+
+	if !found {
+		return "", errs
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), errs
+}
+
+// sourceTemplateConfigMapKeys returns the keys of all ConfigMaps that the
+// source templates of o refer to.
+func sourceTemplateConfigMapKeys(o *secretsv1beta1.SecretTransformation) []client.ObjectKey {
+	var keys []client.ObjectKey
+	for _, tmpl := range o.Spec.SourceTemplates {
+		if tmpl.ConfigMapRef != nil {
+			keys = append(keys, client.ObjectKey{
+				Namespace: o.Namespace,
+				Name:      tmpl.ConfigMapRef.Name,
+			})
+		}
+	}
+	return keys
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SecretTransformationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.referenceCache = newResourceReferenceCache()
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&secretsv1beta1.SecretTransformation{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&secretsv1beta1.SecretTransformation{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&corev1.ConfigMap{},
+			NewEnqueueRefRequestsHandlerCM(r.referenceCache),
+		).
 		Complete(r)
 }
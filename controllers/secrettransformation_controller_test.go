@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestSecretTransformationReconciler_validateConfigMapSourceTemplates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	newCM := func(text string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "templates",
+			},
+			Data: map[string]string{
+				"helpers.tmpl": text,
+			},
+		}
+	}
+	newObj := func(sourceTemplates ...secretsv1beta1.SourceTemplate) *secretsv1beta1.SecretTransformation {
+		return &secretsv1beta1.SecretTransformation{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "st",
+			},
+			Spec: secretsv1beta1.SecretTransformationSpec{
+				SourceTemplates: sourceTemplates,
+			},
+		}
+	}
+	cmRef := secretsv1beta1.SourceTemplate{
+		ConfigMapRef: &secretsv1beta1.ConfigMapKeyRef{
+			Name: "templates",
+			Key:  "helpers.tmpl",
+		},
+	}
+	inline := secretsv1beta1.SourceTemplate{
+		Text: `{{- define "inline" -}}foo{{- end -}}`,
+	}
+
+	validCM := newCM(`{{- define "helper" -}}foo{{- end -}}`)
+	tests := []struct {
+		name       string
+		cm         *corev1.ConfigMap
+		o          *secretsv1beta1.SecretTransformation
+		wantDigest bool
+		wantErr    string
+	}{
+		{
+			name: "inline-only",
+			o:    newObj(inline),
+		},
+		{
+			name:       "valid",
+			cm:         validCM,
+			o:          newObj(inline, cmRef),
+			wantDigest: true,
+		},
+		{
+			name:       "configmap-not-found",
+			o:          newObj(cmRef),
+			wantDigest: true,
+			wantErr:    `configmaps "templates" not found`,
+		},
+		{
+			name:       "invalid-template",
+			cm:         newCM(`{{- define "helper" -}}`),
+			o:          newObj(cmRef),
+			wantDigest: true,
+			wantErr:    "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := testutils.NewFakeClientBuilder()
+			if tt.cm != nil {
+				builder = builder.WithObjects(tt.cm)
+			}
+			r := &SecretTransformationReconciler{
+				Client: builder.Build(),
+			}
+
+			got, err := r.validateConfigMapSourceTemplates(ctx, tt.o)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantDigest {
+				assert.Len(t, got, 64)
+			} else {
+				assert.Empty(t, got)
+			}
+		})
+	}
+
+	// the digest changes with the ConfigMap's template text.
+	r := &SecretTransformationReconciler{
+		Client: testutils.NewFakeClientBuilder().WithObjects(validCM).Build(),
+	}
+	digest1, err := r.validateConfigMapSourceTemplates(ctx, newObj(cmRef))
+	require.NoError(t, err)
+	cm := validCM.DeepCopy()
+	cm.Data["helpers.tmpl"] = `{{- define "helper" -}}bar{{- end -}}`
+	require.NoError(t, r.Client.Update(ctx, cm))
+	digest2, err := r.validateConfigMapSourceTemplates(ctx, newObj(cmRef))
+	require.NoError(t, err)
+	assert.NotEqual(t, digest1, digest2)
+
+	assert.Equal(t, []client.ObjectKey{{Namespace: "default", Name: "templates"}},
+		sourceTemplateConfigMapKeys(newObj(inline, cmRef)))
+}
+
+func TestValidateSecretTransformation_configMapRef(t *testing.T) {
+	t.Parallel()
+
+	o := &secretsv1beta1.SecretTransformation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "st",
+		},
+		Spec: secretsv1beta1.SecretTransformationSpec{
+			SourceTemplates: []secretsv1beta1.SourceTemplate{
+				{
+					ConfigMapRef: &secretsv1beta1.ConfigMapKeyRef{
+						Name: "templates",
+						Key:  "helpers.tmpl",
+					},
+				},
+			},
+		},
+	}
+	assert.NoError(t, ValidateSecretTransformation(context.Background(), o))
+
+	o.Spec.SourceTemplates[0].Text = "{{- foo -}}"
+	assert.EqualError(t, ValidateSecretTransformation(context.Background(), o),
+		"source template default/st/0: text and configMapRef are mutually exclusive")
+}
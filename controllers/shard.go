@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultShardLeaseDuration = 15 * time.Second
+	defaultShardRenewDeadline = 10 * time.Second
+	defaultShardRetryPeriod   = 2 * time.Second
+)
+
+// Shard partitions the syncable secret resources between multiple active
+// operator instances. Every resource is owned by exactly one of the Count
+// shards, which is selected by a consistent hash of the resource's
+// namespace/name. Only the owning instance reconciles a resource.
+type Shard struct {
+	// Index of the shard that is owned by this operator instance, in the range
+	// [0, Count).
+	Index int
+	// Count is the total number of shards.
+	Count int
+}
+
+// Validate the Shard's configuration.
+func (s *Shard) Validate() error {
+	if s.Count < 1 {
+		return fmt.Errorf("invalid shard count %d, must be greater than 0", s.Count)
+	}
+	if s.Index < 0 || s.Index >= s.Count {
+		return fmt.Errorf("invalid shard index %d, must be in the range [0, %d)", s.Index, s.Count)
+	}
+	return nil
+}
+
+// Owns returns true if the resource for objKey belongs to the Shard. It is safe
+// to call on a nil Shard, which owns all resources.
+func (s *Shard) Owns(objKey client.ObjectKey) bool {
+	if s == nil || s.Count <= 1 {
+		return true
+	}
+
+	return shardFor(objKey, s.Count) == s.Index
+}
+
+// String returns the Shard as index/count.
+func (s *Shard) String() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// shardFor returns the shard in the range [0, count) of the resource for
+// objKey.
+func shardFor(objKey client.ObjectKey, count int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(objKey.String()))
+	return jumpHash(h.Sum64(), count)
+}
+
+// jumpHash implements the jump consistent hash algorithm by Lamping and Veach.
+// When the number of buckets grows from n to n+1, only 1/(n+1) of the keys
+// move to another bucket, so scaling out the shards only causes a small
+// fraction of the resources to change owner.
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// ShardAssigner assigns a Shard to an operator instance by acquiring the lease
+// of the first available shard. This allows all replicas of an operator
+// Deployment to share the same configuration. Replicas in excess of the shard
+// count wait as hot standbys until any shard lease becomes available.
+type ShardAssigner struct {
+	// Config is used to access the shard leases.
+	Config *rest.Config
+	// Namespace of the shard leases.
+	Namespace string
+	// LeasePrefix is the name prefix of the shard leases, the shard index is
+	// appended to it.
+	LeasePrefix string
+	// Identity of the operator instance.
+	Identity string
+	// Count is the total number of shards.
+	Count int
+	// OnLost is called when the lease of the assigned shard is lost. The
+	// operator instance must stop reconciling its resources immediately.
+	OnLost        func()
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// Assign blocks until the lease of a shard has been acquired, or ctx is done.
+// The lease is held until ctx is done.
+func (a *ShardAssigner) Assign(ctx context.Context) (*Shard, error) {
+	if a.Count < 1 {
+		return nil, fmt.Errorf("invalid shard count %d, must be greater than 0", a.Count)
+	}
+	if a.Identity == "" {
+		return nil, errors.New("an identity is required")
+	}
+
+	leaseDuration := a.LeaseDuration
+	if leaseDuration <= 0 {
+		leaseDuration = defaultShardLeaseDuration
+	}
+	renewDeadline := a.RenewDeadline
+	if renewDeadline <= 0 {
+		renewDeadline = defaultShardRenewDeadline
+	}
+	retryPeriod := a.RetryPeriod
+	if retryPeriod <= 0 {
+		retryPeriod = defaultShardRetryPeriod
+	}
+
+	logger := log.FromContext(ctx).WithName("shardAssigner")
+	var assigned atomic.Int64
+	assigned.Store(-1)
+	acquired := make(chan int, a.Count)
+	cancels := make([]context.CancelFunc, 0, a.Count)
+	cancelAll := func(except int) {
+		for i, cancel := range cancels {
+			if i != except {
+				cancel()
+			}
+		}
+	}
+
+	for i := 0; i < a.Count; i++ {
+		lock, err := resourcelock.NewFromKubeconfig(resourcelock.LeasesResourceLock,
+			a.Namespace, fmt.Sprintf("%s-%d", a.LeasePrefix, i),
+			resourcelock.ResourceLockConfig{Identity: a.Identity}, a.Config, renewDeadline)
+		if err != nil {
+			cancelAll(-1)
+			return nil, err
+		}
+
+		elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Name:            lock.Describe(),
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) {
+					acquired <- i
+				},
+				OnStoppedLeading: func() {
+					// only the lease of the assigned shard must be held until ctx is done.
+					if assigned.Load() == int64(i) && ctx.Err() == nil && a.OnLost != nil {
+						a.OnLost()
+					}
+				},
+			},
+		})
+		if err != nil {
+			cancelAll(-1)
+			return nil, err
+		}
+
+		leCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		go elector.Run(leCtx)
+	}
+
+	select {
+	case <-ctx.Done():
+		cancelAll(-1)
+		return nil, ctx.Err()
+	case i := <-acquired:
+		assigned.Store(int64(i))
+		// release the leases of any other shards that were acquired concurrently.
+		cancelAll(i)
+		logger.Info("Acquired the shard lease", "shard", i, "count", a.Count)
+		return &Shard{Index: i, Count: a.Count}, nil
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestShard_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		shard   *Shard
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			shard: &Shard{Index: 2, Count: 3},
+		},
+		{
+			name:    "invalid-count",
+			shard:   &Shard{Index: 0, Count: 0},
+			wantErr: "invalid shard count 0",
+		},
+		{
+			name:    "negative-index",
+			shard:   &Shard{Index: -1, Count: 3},
+			wantErr: "invalid shard index -1",
+		},
+		{
+			name:    "index-out-of-range",
+			shard:   &Shard{Index: 3, Count: 3},
+			wantErr: "invalid shard index 3, must be in the range [0, 3)",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.shard.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestShard_Owns(t *testing.T) {
+	t.Parallel()
+
+	objKeys := make([]client.ObjectKey, 1000)
+	for i := range objKeys {
+		objKeys[i] = client.ObjectKey{
+			Namespace: fmt.Sprintf("ns-%d", i%10),
+			Name:      fmt.Sprintf("secret-%d", i),
+		}
+	}
+
+	var nilShard *Shard
+	for _, objKey := range objKeys {
+		assert.True(t, nilShard.Owns(objKey))
+		assert.True(t, (&Shard{Index: 0, Count: 1}).Owns(objKey))
+	}
+
+	count := 4
+	owned := make([]int, count)
+	for _, objKey := range objKeys {
+		var owners int
+		for i := 0; i < count; i++ {
+			if (&Shard{Index: i, Count: count}).Owns(objKey) {
+				owners++
+				owned[i]++
+			}
+		}
+		require.Equal(t, 1, owners, "resource %s must be owned by exactly one shard", objKey)
+	}
+
+	for i, n := range owned {
+		// the resources should be spread roughly evenly across the shards.
+		assert.InDelta(t, len(objKeys)/count, n, float64(len(objKeys))/10, "shard %d", i)
+	}
+}
+
+func Test_jumpHash(t *testing.T) {
+	t.Parallel()
+
+	for key := uint64(0); key < 1000; key++ {
+		assert.Equal(t, 0, jumpHash(key, 1))
+		for buckets := 1; buckets < 10; buckets++ {
+			before := jumpHash(key*7919, buckets)
+			after := jumpHash(key*7919, buckets+1)
+			require.True(t, after >= 0 && after <= buckets)
+			// a key either keeps its bucket, or moves to the new bucket.
+			if after != before {
+				assert.Equal(t, buckets, after)
+			}
+		}
+	}
+}
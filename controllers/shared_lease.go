@@ -0,0 +1,345 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// errSharedLeaseSuperseded is returned when renewing a shared lease that was
+// replaced by new credentials requested by another resource.
+var errSharedLeaseSuperseded = errors.New("shared lease superseded")
+
+// sharedLease holds the Vault response of the credentials that are shared by
+// all VaultDynamicSecrets that request the same credentials with
+// spec.shareLease set.
+type sharedLease struct {
+	resp      vault.Response
+	lease     secretsv1beta1.VaultSecretLease
+	renewedAt time.Time
+	// holders are the resources that use the lease.
+	holders map[client.ObjectKey]struct{}
+}
+
+// usable returns true if the lease can be adopted at now by a resource with
+// renewalPercent, i.e. the lease is not past its renewal time.
+func (l sharedLease) usable(now time.Time, renewalPercent int) bool {
+	d := time.Duration(l.lease.LeaseDuration) * time.Second
+	return now.Before(l.renewedAt.Add(computeStartRenewingAt(d, renewalPercent)))
+}
+
+// expired returns true if the lease is past its TTL at now.
+func (l sharedLease) expired(now time.Time) bool {
+	return !now.Before(l.renewedAt.Add(time.Duration(l.lease.LeaseDuration) * time.Second))
+}
+
+// sharedLeaseRegistry holds the shared leases keyed by sharedLeaseKey. A shared
+// lease is evicted once it is replaced, once it is past its TTL, or once the
+// last resource that holds it releases it. All of its methods are safe to call
+// on a nil registry.
+type sharedLeaseRegistry struct {
+	m  map[string]sharedLease
+	mu sync.RWMutex
+}
+
+func newSharedLeaseRegistry() *sharedLeaseRegistry {
+	return &sharedLeaseRegistry{
+		m: map[string]sharedLease{},
+	}
+}
+
+// Get the shared lease for key.
+func (r *sharedLeaseRegistry) Get(key string) (sharedLease, bool) {
+	if r == nil {
+		return sharedLease{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	l, ok := r.m[key]
+	return l, ok
+}
+
+// Lookup the shared lease by its lease ID.
+func (r *sharedLeaseRegistry) Lookup(leaseID string) (sharedLease, bool) {
+	if r == nil || leaseID == "" {
+		return sharedLease{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, l := range r.m {
+		if l.lease.ID == leaseID {
+			return l, true
+		}
+	}
+
+	return sharedLease{}, false
+}
+
+// Set the shared lease for key, replacing any previous lease, and hold it for
+// objKey. Any other lease held by objKey is released. Responses without a lease
+// are never shared.
+func (r *sharedLeaseRegistry) Set(key string, objKey client.ObjectKey, resp vault.Response, lease secretsv1beta1.VaultSecretLease, now time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.release(objKey, "")
+	if lease.ID != "" {
+		r.m[key] = sharedLease{
+			resp:      resp,
+			lease:     lease,
+			renewedAt: now,
+			holders: map[client.ObjectKey]struct{}{
+				objKey: {},
+			},
+		}
+	}
+	r.prune(now)
+}
+
+// Hold the shared lease for key for objKey, e.g. when adopting it. Any other
+// lease held by objKey is released.
+func (r *sharedLeaseRegistry) Hold(key string, objKey client.ObjectKey, now time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.release(objKey, key)
+	if l, ok := r.m[key]; ok {
+		l.holders[objKey] = struct{}{}
+	}
+	r.prune(now)
+}
+
+// Release the shared lease held by objKey, e.g. when it is deleted or it stops
+// sharing its lease.
+func (r *sharedLeaseRegistry) Release(objKey client.ObjectKey, now time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.release(objKey, "")
+	r.prune(now)
+}
+
+// release objKey from all shared leases other than the one for key. A lease
+// without any holders is evicted. The caller must hold the lock.
+func (r *sharedLeaseRegistry) release(objKey client.ObjectKey, key string) {
+	for k, l := range r.m {
+		if k == key {
+			continue
+		}
+		delete(l.holders, objKey)
+		if len(l.holders) == 0 {
+			delete(r.m, k)
+		}
+	}
+}
+
+// prune evicts the shared leases that are past their TTL at now. The caller
+// must hold the lock.
+func (r *sharedLeaseRegistry) prune(now time.Time) {
+	for k, l := range r.m {
+		if l.expired(now) {
+			delete(r.m, k)
+		}
+	}
+}
+
+// Renewed records the renewal of lease at now.
+func (r *sharedLeaseRegistry) Renewed(lease secretsv1beta1.VaultSecretLease, now time.Time) {
+	if r == nil || lease.ID == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, l := range r.m {
+		if l.lease.ID == lease.ID {
+			l.lease = lease
+			l.renewedAt = now
+			r.m[k] = l
+		}
+	}
+	r.prune(now)
+}
+
+// DeleteLease removes the shared lease with leaseID.
+func (r *sharedLeaseRegistry) DeleteLease(leaseID string) {
+	if r == nil || leaseID == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, l := range r.m {
+		if l.lease.ID == leaseID {
+			delete(r.m, k)
+		}
+	}
+}
+
+// shareLease returns true if o's lease should be shared with the other
+// resources that request the same credentials. Static credentials, polled
+// and wrapped secrets, and LDAP library check-outs are never shared.
+func shareLease(o *secretsv1beta1.VaultDynamicSecret) bool {
+	return o.Spec.ShareLease && !useStaticCreds(o) && !usePolling(o) && o.Spec.Engine != engineLDAPLibrary
+}
+
+// sharedLeaseKey returns the key of o's shared lease. Resources share a lease
+// if they request the same credentials from the same Vault namespace, with
+// the same Vault client.
+func (r *VaultDynamicSecretReconciler) sharedLeaseKey(ctx context.Context, o *secretsv1beta1.VaultDynamicSecret) (string, error) {
+	params, err := r.requestParams(ctx, o)
+	if err != nil {
+		return "", err
+	}
+
+	rolePath, roleParams := engineRole(o)
+	b, err := json.Marshal([]map[string]any{params, roleParams})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		o.Status.VaultClientMeta.CacheKey, o.Status.VaultClientMeta.ID, o.Spec.Namespace,
+		o.Spec.Engine, o.Spec.Mount, o.Spec.Path, o.Spec.RequestHTTPMethod, rolePath, string(b),
+	}, "\x00"), nil
+}
+
+// doSharedVault returns the response of the shared lease for o's credentials,
+// if another resource holds a usable shared lease. Otherwise, new credentials
+// are requested from Vault and shared with the other resources.
+func (r *VaultDynamicSecretReconciler) doSharedVault(ctx context.Context, c vault.ClientBase, o *secretsv1beta1.VaultDynamicSecret) (vault.Response, error) {
+	objKey := client.ObjectKeyFromObject(o)
+	if !shareLease(o) {
+		// o may have stopped sharing its lease.
+		r.sharedLeases.Release(objKey, nowFunc())
+		return r.doVault(ctx, c, o)
+	}
+
+	key, err := r.sharedLeaseKey(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// the current shared lease is never re-adopted, new credentials are requested
+	// instead, e.g. after its renewal failed.
+	if l, ok := r.sharedLeases.Get(key); ok && l.lease.ID != o.Status.SecretLease.ID &&
+		l.usable(nowFunc(), o.Spec.RenewalPercent) {
+		log.FromContext(ctx).V(consts.LogLevelDebug).Info("Adopting shared lease", "id", l.lease.ID)
+		r.sharedLeases.Hold(key, objKey, nowFunc())
+		return l.resp, nil
+	}
+
+	resp, err := r.doVault(ctx, c, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var lease secretsv1beta1.VaultSecretLease
+	if resp.Secret() != nil {
+		lease = *r.getVaultSecretLease(resp.Secret())
+	}
+	r.sharedLeases.Set(key, objKey, resp, lease, nowFunc())
+
+	return resp, nil
+}
+
+// renewSharedLease renews o's lease and returns it along with the time it was
+// renewed. A shared lease is only renewed if no other resource has renewed it
+// since o's last renewal. errSharedLeaseSuperseded is returned if another
+// resource has replaced the shared lease with new credentials.
+func (r *VaultDynamicSecretReconciler) renewSharedLease(ctx context.Context, c vault.ClientBase, o *secretsv1beta1.VaultDynamicSecret) (*secretsv1beta1.VaultSecretLease, time.Time, error) {
+	if !shareLease(o) {
+		lease, err := r.renewLease(ctx, c, o)
+		return lease, nowFunc(), err
+	}
+
+	key, err := r.sharedLeaseKey(ctx, o)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if l, ok := r.sharedLeases.Get(key); ok && l.usable(nowFunc(), o.Spec.RenewalPercent) {
+		if l.lease.ID != o.Status.SecretLease.ID {
+			return nil, time.Time{}, errSharedLeaseSuperseded
+		}
+		if l.renewedAt.Unix() > o.Status.LastRenewalTime {
+			lease := l.lease
+			return &lease, l.renewedAt, nil
+		}
+	}
+
+	lease, err := r.renewLease(ctx, c, o)
+	now := nowFunc()
+	if err == nil {
+		r.sharedLeases.Renewed(*lease, now)
+	}
+
+	return lease, now, err
+}
+
+// sharedLeaseRenewedAt returns the time at which the shared lease with leaseID
+// was last renewed, it is now if o's lease is not shared.
+func (r *VaultDynamicSecretReconciler) sharedLeaseRenewedAt(o *secretsv1beta1.VaultDynamicSecret, leaseID string) time.Time {
+	if shareLease(o) {
+		if l, ok := r.sharedLeases.Lookup(leaseID); ok {
+			return l.renewedAt
+		}
+	}
+
+	return nowFunc()
+}
+
+// isLeaseShared returns true if o's lease is held by any other
+// VaultDynamicSecret, in which case it must not be revoked. The lease is
+// assumed to be shared if the resources cannot be listed.
+func (r *VaultDynamicSecretReconciler) isLeaseShared(ctx context.Context, o *secretsv1beta1.VaultDynamicSecret) bool {
+	if !o.Spec.ShareLease || o.Status.SecretLease.ID == "" {
+		return false
+	}
+
+	var list secretsv1beta1.VaultDynamicSecretList
+	if err := r.Client.List(ctx, &list); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list the resources sharing the lease")
+		return true
+	}
+
+	for _, item := range list.Items {
+		if item.UID != o.UID && item.GetDeletionTimestamp() == nil &&
+			item.Status.SecretLease.ID == o.Status.SecretLease.ID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,227 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+func newSharedLeaseVDS(name string, params map[string]string) *secretsv1beta1.VaultDynamicSecret {
+	return &secretsv1beta1.VaultDynamicSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+		},
+		Spec: secretsv1beta1.VaultDynamicSecretSpec{
+			Mount:          "db",
+			Path:           "creds/app",
+			Params:         params,
+			RenewalPercent: 67,
+			ShareLease:     true,
+		},
+		Status: secretsv1beta1.VaultDynamicSecretStatus{
+			VaultClientMeta: secretsv1beta1.VaultClientMeta{
+				CacheKey: "kubernetes-123",
+				ID:       "client-1",
+			},
+		},
+	}
+}
+
+func TestVaultDynamicSecretReconciler_doSharedVault(t *testing.T) {
+	ctx := context.Background()
+
+	newResp := func(id string) vault.Response {
+		return vault.NewDefaultResponse(&api.Secret{
+			LeaseID:       id,
+			LeaseDuration: 600,
+			Renewable:     true,
+			Data: map[string]any{
+				"username": id,
+			},
+		})
+	}
+	vClient := &vault.MockRecordingVaultClient{
+		ReadResponses: map[string][]vault.Response{
+			"db/creds/app": {newResp("lease-1"), newResp("lease-2")},
+		},
+		// requests with params are always written.
+		WriteResponses: map[string][]vault.Response{
+			"db/creds/app": {newResp("lease-3")},
+		},
+	}
+	r := &VaultDynamicSecretReconciler{
+		Client:       testutils.NewFakeClientBuilder().Build(),
+		sharedLeases: newSharedLeaseRegistry(),
+	}
+
+	foo := newSharedLeaseVDS("foo", nil)
+	bar := newSharedLeaseVDS("bar", nil)
+
+	resp, err := r.doSharedVault(ctx, vClient, foo)
+	require.NoError(t, err)
+	assert.Equal(t, "lease-1", resp.Secret().LeaseID)
+	foo.Status.SecretLease.ID = resp.Secret().LeaseID
+
+	// bar adopts the lease requested for foo.
+	resp, err = r.doSharedVault(ctx, vClient, bar)
+	require.NoError(t, err)
+	assert.Equal(t, "lease-1", resp.Secret().LeaseID)
+	assert.Len(t, vClient.Requests, 1)
+	bar.Status.SecretLease.ID = resp.Secret().LeaseID
+
+	// the current shared lease is never re-adopted, e.g. after its renewal failed.
+	resp, err = r.doSharedVault(ctx, vClient, foo)
+	require.NoError(t, err)
+	assert.Equal(t, "lease-2", resp.Secret().LeaseID)
+	assert.Len(t, vClient.Requests, 2)
+
+	// bar's renewal reports that its lease was superseded.
+	_, _, err = r.renewSharedLease(ctx, vClient, bar)
+	assert.ErrorIs(t, err, errSharedLeaseSuperseded)
+	assert.Len(t, vClient.Requests, 2)
+
+	// resources that request other credentials never share the lease.
+	resp, err = r.doSharedVault(ctx, vClient, newSharedLeaseVDS("baz", map[string]string{"ttl": "1h"}))
+	require.NoError(t, err)
+	assert.Equal(t, "lease-3", resp.Secret().LeaseID)
+	assert.Len(t, vClient.Requests, 3)
+}
+
+func TestVaultDynamicSecretReconciler_renewSharedLease(t *testing.T) {
+	ctx := context.Background()
+
+	vClient := &vault.MockRecordingVaultClient{
+		WriteResponses: map[string][]vault.Response{
+			"/sys/leases/renew": {
+				vault.NewDefaultResponse(&api.Secret{
+					LeaseID:       "lease-1",
+					LeaseDuration: 600,
+					Renewable:     true,
+				}),
+			},
+		},
+	}
+	r := &VaultDynamicSecretReconciler{
+		Client:       testutils.NewFakeClientBuilder().Build(),
+		sharedLeases: newSharedLeaseRegistry(),
+	}
+
+	lastRenewal := time.Now().Add(-time.Minute)
+	lease := secretsv1beta1.VaultSecretLease{
+		ID:            "lease-1",
+		LeaseDuration: 600,
+		Renewable:     true,
+	}
+	foo := newSharedLeaseVDS("foo", nil)
+	bar := newSharedLeaseVDS("bar", nil)
+	for _, o := range []*secretsv1beta1.VaultDynamicSecret{foo, bar} {
+		o.Status.SecretLease = lease
+		o.Status.LastRenewalTime = lastRenewal.Unix()
+	}
+
+	key, err := r.sharedLeaseKey(ctx, foo)
+	require.NoError(t, err)
+	r.sharedLeases.Set(key, client.ObjectKeyFromObject(foo), nil, lease, lastRenewal)
+
+	got, renewedAt, err := r.renewSharedLease(ctx, vClient, foo)
+	require.NoError(t, err)
+	assert.Equal(t, &lease, got)
+	assert.True(t, renewedAt.After(lastRenewal))
+	assert.Len(t, vClient.Requests, 1)
+
+	// bar adopts the renewal made for foo.
+	got, barRenewedAt, err := r.renewSharedLease(ctx, vClient, bar)
+	require.NoError(t, err)
+	assert.Equal(t, &lease, got)
+	assert.Equal(t, renewedAt, barRenewedAt)
+	assert.Len(t, vClient.Requests, 1)
+}
+
+func TestSharedLeaseRegistry(t *testing.T) {
+	now := time.Now()
+	newLease := func(id string) secretsv1beta1.VaultSecretLease {
+		return secretsv1beta1.VaultSecretLease{
+			ID:            id,
+			LeaseDuration: 600,
+			Renewable:     true,
+		}
+	}
+	foo := client.ObjectKey{Namespace: "default", Name: "foo"}
+	bar := client.ObjectKey{Namespace: "default", Name: "bar"}
+	baz := client.ObjectKey{Namespace: "default", Name: "baz"}
+
+	r := newSharedLeaseRegistry()
+	r.Set("key-1", foo, nil, newLease("lease-1"), now)
+	r.Hold("key-1", bar, now)
+
+	// the lease is kept until its last holder releases it.
+	r.Release(foo, now)
+	_, ok := r.Get("key-1")
+	assert.True(t, ok)
+	r.Release(bar, now)
+	_, ok = r.Get("key-1")
+	assert.False(t, ok)
+
+	// a replaced lease is evicted.
+	r.Set("key-1", foo, nil, newLease("lease-1"), now)
+	r.Set("key-1", bar, nil, newLease("lease-2"), now)
+	_, ok = r.Lookup("lease-1")
+	assert.False(t, ok)
+
+	// holding another lease releases the previous one.
+	r.Set("key-2", baz, nil, newLease("lease-3"), now)
+	r.Hold("key-1", baz, now)
+	_, ok = r.Get("key-2")
+	assert.False(t, ok)
+
+	// requesting credentials without a lease releases the previous one.
+	r.Set("key-3", bar, nil, secretsv1beta1.VaultSecretLease{}, now)
+	l, ok := r.Get("key-1")
+	require.True(t, ok)
+	assert.Equal(t, map[client.ObjectKey]struct{}{baz: {}}, l.holders)
+	_, ok = r.Get("key-3")
+	assert.False(t, ok)
+
+	// leases that are past their TTL are evicted.
+	r.Set("key-4", foo, nil, newLease("lease-4"), now.Add(10*time.Minute))
+	assert.Len(t, r.m, 1)
+	_, ok = r.Get("key-4")
+	assert.True(t, ok)
+}
+
+func TestVaultDynamicSecretReconciler_isLeaseShared(t *testing.T) {
+	ctx := context.Background()
+
+	foo := newSharedLeaseVDS("foo", nil)
+	foo.Status.SecretLease.ID = "lease-1"
+	bar := newSharedLeaseVDS("bar", nil)
+	bar.Status.SecretLease.ID = "lease-1"
+	baz := newSharedLeaseVDS("baz", nil)
+	baz.Status.SecretLease.ID = "lease-2"
+
+	r := &VaultDynamicSecretReconciler{
+		Client: testutils.NewFakeClientBuilder().WithObjects(foo, bar, baz).Build(),
+	}
+	assert.True(t, r.isLeaseShared(ctx, foo))
+	assert.True(t, r.isLeaseShared(ctx, bar))
+	assert.False(t, r.isLeaseShared(ctx, baz))
+
+	foo.Spec.ShareLease = false
+	assert.False(t, r.isLeaseShared(ctx, foo))
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// sourceChannelBufferSize is the capacity of a reconciler's SourceCh. It
+// absorbs bursts of events, e.g. from the Vault event watchers, while the
+// controller drains the channel into its workqueue.
+const sourceChannelBufferSize = 256
+
+// newSourceChannel returns a new buffered SourceCh.
+func newSourceChannel() chan event.GenericEvent {
+	return make(chan event.GenericEvent, sourceChannelBufferSize)
+}
+
+// sendSourceEvent sends evt to the SourceCh of the kind's reconciler, blocking
+// until there is room in its buffer. The event is dropped if ctx is done first,
+// or if ch is closed. Returns true if the event was sent.
+func sendSourceEvent(ctx context.Context, kind ResourceKind, ch chan<- event.GenericEvent, evt event.GenericEvent) (sent bool) {
+	controller := metricsController(kind)
+	defer recoverClosedSourceChannel(ctx, controller, evt, &sent)
+
+	select {
+	case ch <- evt:
+		metrics.SetSourceChannelLength(controller, len(ch))
+		return true
+	case <-ctx.Done():
+		dropSourceEvent(ctx, controller, evt, metrics.SourceChannelDropReasonCanceled)
+		return false
+	}
+}
+
+// trySendSourceEvent sends evt to the SourceCh of the kind's reconciler without
+// blocking. It is meant for senders that must never stall, like the Vault event
+// watchers. The event is dropped if ch's buffer is full, or if ch is closed.
+// Returns true if the event was sent.
+func trySendSourceEvent(ctx context.Context, kind ResourceKind, ch chan<- event.GenericEvent, evt event.GenericEvent) (sent bool) {
+	controller := metricsController(kind)
+	defer recoverClosedSourceChannel(ctx, controller, evt, &sent)
+
+	select {
+	case ch <- evt:
+		metrics.SetSourceChannelLength(controller, len(ch))
+		return true
+	default:
+		dropSourceEvent(ctx, controller, evt, metrics.SourceChannelDropReasonFull)
+		return false
+	}
+}
+
+// recoverClosedSourceChannel recovers from sending on a closed SourceCh, which
+// only happens while the operator is shutting down.
+func recoverClosedSourceChannel(ctx context.Context, controller string, evt event.GenericEvent, sent *bool) {
+	if r := recover(); r != nil {
+		*sent = false
+		dropSourceEvent(ctx, controller, evt, metrics.SourceChannelDropReasonClosed)
+	}
+}
+
+func dropSourceEvent(ctx context.Context, controller string, evt event.GenericEvent, reason string) {
+	metrics.IncSourceChannelEventsDropped(controller, reason)
+
+	var objKey client.ObjectKey
+	if evt.Object != nil {
+		objKey = client.ObjectKeyFromObject(evt.Object)
+	}
+	log.FromContext(ctx).V(consts.LogLevelWarning).Info("Dropped SourceCh event",
+		"controller", controller, "obj", objKey, "reason", reason)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+func metricValue(t *testing.T, m prometheus.Metric) float64 {
+	t.Helper()
+
+	var pb io_prometheus_client.Metric
+	require.NoError(t, m.Write(&pb))
+	switch {
+	case pb.Gauge != nil:
+		return pb.GetGauge().GetValue()
+	case pb.Counter != nil:
+		return pb.GetCounter().GetValue()
+	default:
+		t.Fatalf("unsupported metric type %v", m.Desc())
+		return 0
+	}
+}
+
+func newTestSourceEvent(name string) event.GenericEvent {
+	return event.GenericEvent{
+		Object: &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+		},
+	}
+}
+
+func Test_sendSourceEvent(t *testing.T) {
+	t.Parallel()
+
+	// VaultAuth has no SourceCh, so its metrics are not updated elsewhere.
+	controller := metricsController(VaultAuth)
+	dropped := func(reason string) float64 {
+		return metricValue(t, metrics.SourceChannelEventsDropped.WithLabelValues(controller, reason))
+	}
+
+	ctx := context.Background()
+	ch := make(chan event.GenericEvent, 1)
+	assert.True(t, sendSourceEvent(ctx, VaultAuth, ch, newTestSourceEvent("foo")))
+	assert.Len(t, ch, 1)
+	assert.Equal(t, float64(1), metricValue(t, metrics.SourceChannelLength.WithLabelValues(controller)))
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	canceled := dropped(metrics.SourceChannelDropReasonCanceled)
+	assert.False(t, sendSourceEvent(canceledCtx, VaultAuth, ch, newTestSourceEvent("bar")))
+	assert.Equal(t, canceled+1, dropped(metrics.SourceChannelDropReasonCanceled))
+
+	<-ch
+	close(ch)
+	closed := dropped(metrics.SourceChannelDropReasonClosed)
+	assert.NotPanics(t, func() {
+		assert.False(t, sendSourceEvent(ctx, VaultAuth, ch, newTestSourceEvent("foo")))
+	})
+	assert.Equal(t, closed+1, dropped(metrics.SourceChannelDropReasonClosed))
+}
+
+func Test_trySendSourceEvent(t *testing.T) {
+	t.Parallel()
+
+	// VaultAuthGlobal has no SourceCh, so its metrics are not updated elsewhere.
+	controller := metricsController(VaultAuthGlobal)
+	dropped := func(reason string) float64 {
+		return metricValue(t, metrics.SourceChannelEventsDropped.WithLabelValues(controller, reason))
+	}
+
+	ctx := context.Background()
+	ch := make(chan event.GenericEvent, 1)
+	assert.True(t, trySendSourceEvent(ctx, VaultAuthGlobal, ch, newTestSourceEvent("foo")))
+
+	// the buffer is full, so the event is dropped rather than blocking.
+	full := dropped(metrics.SourceChannelDropReasonFull)
+	assert.False(t, trySendSourceEvent(ctx, VaultAuthGlobal, ch, newTestSourceEvent("bar")))
+	assert.Equal(t, full+1, dropped(metrics.SourceChannelDropReasonFull))
+	assert.Equal(t, "foo", (<-ch).Object.GetName())
+
+	close(ch)
+	closed := dropped(metrics.SourceChannelDropReasonClosed)
+	assert.NotPanics(t, func() {
+		assert.False(t, trySendSourceEvent(ctx, VaultAuthGlobal, ch, newTestSourceEvent("foo")))
+	})
+	assert.Equal(t, closed+1, dropped(metrics.SourceChannelDropReasonClosed))
+}
+
+func Test_newSourceChannel(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, sourceChannelBufferSize, cap(newSourceChannel()))
+}
@@ -0,0 +1,170 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// defaultStartupSyncInterval is the default average interval between the
+// initial reconciliations of a kind's resources.
+const defaultStartupSyncInterval = time.Millisecond * 100
+
+// StartupSyncSmear spreads the initial reconciliation of the resources that
+// existed when the operator started over a window, rather than reconciling all
+// of them at once. Each resource is assigned a stable offset within its kind's
+// window by a hash of its namespace/name. The window of a kind is proportional
+// to its number of resources, at Interval per resource, and capped at the
+// configured window, so that small installs are not delayed needlessly.
+type StartupSyncSmear struct {
+	// Reader is used to count the resources of each kind, it should not be
+	// backed by the manager's cache.
+	Reader client.Reader
+	// StartTime of the operator, only the resources created before it are
+	// deferred.
+	StartTime time.Time
+	// Window is the maximum duration over which the initial reconciliations of
+	// each kind are spread.
+	Window time.Duration
+	// KindWindows overrides the Window of specific kinds. A window of 0 disables
+	// the smearing of the kind.
+	KindWindows map[ResourceKind]time.Duration
+	// Interval is the average interval between the initial reconciliations of a
+	// kind's resources.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	windows map[ResourceKind]time.Duration
+}
+
+// Defer returns true along with the duration after which the request for o
+// should be requeued, if its initial reconciliation should be deferred. It is
+// safe to call on a nil StartupSyncSmear.
+func (s *StartupSyncSmear) Defer(ctx context.Context, kind ResourceKind, o client.Object) (time.Duration, bool) {
+	if s == nil {
+		return 0, false
+	}
+
+	maxWindow := s.maxWindow(kind)
+	now := nowFunc()
+	if maxWindow <= 0 || !now.Before(s.StartTime.Add(maxWindow)) ||
+		!o.GetCreationTimestamp().Time.Before(s.StartTime) {
+		return 0, false
+	}
+
+	window := s.window(ctx, kind, maxWindow)
+	offset := time.Duration(float64(window) * smearFraction(client.ObjectKeyFromObject(o)))
+	deferAfter := s.StartTime.Add(offset).Sub(now)
+	if deferAfter <= 0 {
+		return 0, false
+	}
+
+	metrics.IncReconcileStartupDeferred(metricsController(kind))
+	log.FromContext(ctx).V(consts.LogLevelDebug).Info("Deferring the initial reconciliation",
+		"deferAfter", deferAfter, "window", window)
+
+	return deferAfter, true
+}
+
+func (s *StartupSyncSmear) maxWindow(kind ResourceKind) time.Duration {
+	if d, ok := s.KindWindows[kind]; ok {
+		return d
+	}
+	return s.Window
+}
+
+// window returns the kind's window, which is computed from its number of
+// resources on first use. maxWindow is returned if the resources cannot be
+// counted.
+func (s *StartupSyncSmear) window(ctx context.Context, kind ResourceKind, maxWindow time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d, ok := s.windows[kind]; ok {
+		return d
+	}
+
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(secretsv1beta1.GroupVersion.WithKind(kind.String() + "List"))
+	if err := s.Reader.List(ctx, list); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to count the resources, using the maximum startup sync window",
+			"kind", kind.String(), "window", maxWindow)
+		return maxWindow
+	}
+
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultStartupSyncInterval
+	}
+
+	window := min(interval*time.Duration(len(list.Items)), maxWindow)
+	if s.windows == nil {
+		s.windows = make(map[ResourceKind]time.Duration)
+	}
+	s.windows[kind] = window
+
+	return window
+}
+
+// smearFraction returns the stable offset of the resource for objKey within a
+// window, as a fraction in the range [0, 1).
+func smearFraction(objKey client.ObjectKey) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(objKey.String()))
+	// the high bits of FNV are poorly distributed for similar keys, e.g.
+	// secret-1, secret-2, so they are mixed by the MurmurHash3 finalizer.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return float64(x>>11) / float64(uint64(1)<<53)
+}
+
+// ParseKindWindows parses the kind windows from a list of kind=duration pairs,
+// e.g. VaultDynamicSecret=10m.
+func ParseKindWindows(vals []string) (map[ResourceKind]time.Duration, error) {
+	windows := make(map[ResourceKind]time.Duration, len(vals))
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		k, d, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid kind window %q, must be of the form kind=duration", v)
+		}
+
+		kind, err := ParseResourceKind(strings.TrimSpace(k))
+		if err != nil {
+			return nil, err
+		}
+
+		window, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return nil, fmt.Errorf("invalid window for kind %s: %w", kind, err)
+		}
+		if window < 0 {
+			return nil, fmt.Errorf("invalid window for kind %s, must not be negative", kind)
+		}
+
+		windows[kind] = window
+	}
+
+	return windows, nil
+}
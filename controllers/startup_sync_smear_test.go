@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+func TestStartupSyncSmear_Defer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, secretsv1beta1.AddToScheme(scheme))
+
+	startTime := nowFunc()
+	created := metav1.NewTime(startTime.Add(-time.Hour))
+	var objs []client.Object
+	for i := 0; i < 100; i++ {
+		objs = append(objs, &secretsv1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              fmt.Sprintf("vss-%d", i),
+				CreationTimestamp: created,
+			},
+		})
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	s := &StartupSyncSmear{
+		Reader:    c,
+		StartTime: startTime,
+		Window:    time.Hour,
+		KindWindows: map[ResourceKind]time.Duration{
+			VaultPKISecret: 0,
+		},
+		Interval: time.Minute,
+	}
+
+	// the window of 100 resources at 1m per resource is capped at 1h.
+	var deferred int
+	for _, o := range objs {
+		d, ok := s.Defer(ctx, VaultStaticSecret, o)
+		if ok {
+			deferred++
+			assert.Greater(t, d, time.Duration(0))
+			assert.LessOrEqual(t, d, time.Hour)
+		}
+
+		// the offset of a resource is stable.
+		d2, ok2 := s.Defer(ctx, VaultStaticSecret, o)
+		assert.Equal(t, ok, ok2)
+		assert.InDelta(t, d, d2, float64(time.Second))
+	}
+	assert.Greater(t, deferred, 90)
+
+	// resources created after the start are not deferred.
+	o := objs[0].DeepCopyObject().(client.Object)
+	o.SetCreationTimestamp(metav1.NewTime(startTime.Add(time.Second)))
+	_, ok := s.Defer(ctx, VaultStaticSecret, o)
+	assert.False(t, ok)
+
+	// kinds with a window of 0 are not deferred.
+	_, ok = s.Defer(ctx, VaultPKISecret, objs[0])
+	assert.False(t, ok)
+
+	// the smearing ends with the window.
+	s.StartTime = startTime.Add(-time.Hour)
+	_, ok = s.Defer(ctx, VaultStaticSecret, objs[0])
+	assert.False(t, ok)
+
+	var nilSmear *StartupSyncSmear
+	_, ok = nilSmear.Defer(ctx, VaultStaticSecret, objs[0])
+	assert.False(t, ok)
+}
+
+func TestStartupSyncSmear_window(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, secretsv1beta1.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&secretsv1beta1.VaultDynamicSecret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "vds-1"},
+		},
+		&secretsv1beta1.VaultDynamicSecret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "vds-2"},
+		},
+	).Build()
+
+	s := &StartupSyncSmear{Reader: c}
+	// the window is proportional to the number of resources.
+	assert.Equal(t, 2*defaultStartupSyncInterval, s.window(ctx, VaultDynamicSecret, time.Hour))
+	assert.Equal(t, time.Duration(0), s.window(ctx, VaultPKISecret, time.Hour))
+
+	s = &StartupSyncSmear{Reader: c}
+	assert.Equal(t, defaultStartupSyncInterval, s.window(ctx, VaultDynamicSecret, defaultStartupSyncInterval))
+}
+
+func Test_smearFraction(t *testing.T) {
+	t.Parallel()
+
+	buckets := make([]int, 4)
+	for i := 0; i < 1000; i++ {
+		f := smearFraction(client.ObjectKey{Namespace: "default", Name: fmt.Sprintf("secret-%d", i)})
+		require.True(t, f >= 0 && f < 1)
+		buckets[int(f*float64(len(buckets)))]++
+	}
+	for _, n := range buckets {
+		// the offsets should be spread roughly evenly across the window.
+		assert.InDelta(t, 250, n, 75)
+	}
+}
+
+func TestParseKindWindows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		vals    []string
+		want    map[ResourceKind]time.Duration
+		wantErr string
+	}{
+		{
+			name: "valid",
+			vals: []string{"VaultDynamicSecret=10m", " VaultPKISecret = 0s ", ""},
+			want: map[ResourceKind]time.Duration{
+				VaultDynamicSecret: 10 * time.Minute,
+				VaultPKISecret:     0,
+			},
+		},
+		{
+			name:    "missing-duration",
+			vals:    []string{"VaultDynamicSecret"},
+			wantErr: `invalid kind window "VaultDynamicSecret"`,
+		},
+		{
+			name:    "invalid-kind",
+			vals:    []string{"Foo=10m"},
+			wantErr: `unsupported resource kind "Foo"`,
+		},
+		{
+			name:    "invalid-duration",
+			vals:    []string{"VaultStaticSecret=10"},
+			wantErr: "invalid window for kind VaultStaticSecret",
+		},
+		{
+			name:    "negative-duration",
+			vals:    []string{"VaultStaticSecret=-1m"},
+			wantErr: "must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseKindWindows(tt.vals)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+const (
+	// conditionTypePaused is the condition type that reports that the sync of
+	// a resource is suspended by its spec.suspend field.
+	conditionTypePaused = "Paused"
+	reasonSuspended     = "Suspended"
+)
+
+// isSuspended returns true if the sync of the syncable secret resource o is
+// suspended.
+func isSuspended(o client.Object) bool {
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		return t.Spec.Suspend
+	case *secretsv1beta1.VaultDynamicSecret:
+		return t.Spec.Suspend
+	case *secretsv1beta1.VaultPKISecret:
+		return t.Spec.Suspend
+	case *secretsv1beta1.VaultSSHCertificate:
+		return t.Spec.Suspend
+	case *secretsv1beta1.VaultTransitKey:
+		return t.Spec.Suspend
+	case *secretsv1beta1.VaultSecretExport:
+		return t.Spec.Suspend
+	case *secretsv1beta1.HCPVaultSecretsApp:
+		return t.Spec.Suspend
+	default:
+		return false
+	}
+}
+
+// handleSuspend should be called at the start of each reconciliation of o,
+// once its deletion has been handled. It returns true if o is suspended, in
+// which case the reconciliation should end without a requeue, the resource is
+// reconciled again once it is resumed. The Paused condition of o is set while
+// it is suspended, and cleared once it is resumed.
+func handleSuspend(ctx context.Context, c client.Client, o client.Object, recorder record.EventRecorder) (bool, error) {
+	conditions := statusConditions(o)
+	if conditions == nil {
+		return false, nil
+	}
+
+	suspended := isSuspended(o)
+	paused := hasCondition(*conditions, conditionTypePaused)
+	if suspended == paused {
+		return suspended, nil
+	}
+
+	logger := log.FromContext(ctx)
+	if suspended {
+		logger.Info("Suspending the sync")
+		*conditions = updateConditions(*conditions, append(removeConditions(*conditions, conditionTypePaused),
+			metav1.Condition{
+				Type:               conditionTypePaused,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: o.GetGeneration(),
+				Reason:             reasonSuspended,
+				Message:            "Sync suspended by spec.suspend",
+			})...)
+		recorder.Event(o, corev1.EventTypeNormal, consts.ReasonSyncSuspended, "Sync suspended")
+	} else {
+		logger.Info("Resuming the sync")
+		*conditions = removeConditions(*conditions, conditionTypePaused)
+		recorder.Event(o, corev1.EventTypeNormal, consts.ReasonSyncResumed, "Sync resumed")
+	}
+
+	return suspended, c.Status().Update(ctx, o)
+}
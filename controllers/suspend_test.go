@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_handleSuspend(t *testing.T) {
+	ctx := context.Background()
+
+	o := &secretsv1beta1.VaultDynamicSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "foo",
+			Generation: 2,
+		},
+		Spec: secretsv1beta1.VaultDynamicSecretSpec{
+			Suspend: true,
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	for i := 0; i < 2; i++ {
+		suspended, err := handleSuspend(ctx, c, o, recorder)
+		require.NoError(t, err)
+		assert.True(t, suspended)
+	}
+	assert.Len(t, recorder.Events, 1)
+
+	var got secretsv1beta1.VaultDynamicSecret
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypePaused, got.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, reasonSuspended, got.Status.Conditions[0].Reason)
+	assert.Equal(t, int64(2), got.Status.Conditions[0].ObservedGeneration)
+
+	// the resource is resumed, the condition is cleared.
+	got.Spec.Suspend = false
+	require.NoError(t, c.Update(ctx, &got))
+	for i := 0; i < 2; i++ {
+		suspended, err := handleSuspend(ctx, c, &got, recorder)
+		require.NoError(t, err)
+		assert.False(t, suspended)
+	}
+	assert.Len(t, recorder.Events, 2)
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	assert.Empty(t, got.Status.Conditions)
+
+	// resources without status conditions are never suspended.
+	suspended, err := handleSuspend(ctx, c, &secretsv1beta1.VaultAuth{}, recorder)
+	require.NoError(t, err)
+	assert.False(t, suspended)
+}
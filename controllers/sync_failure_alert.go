@@ -0,0 +1,232 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+const (
+	// conditionTypeSyncDegraded is the condition type that reports a resource
+	// whose sync has failed at least SyncFailureAlert.Threshold consecutive
+	// times.
+	conditionTypeSyncDegraded = "SyncDegraded"
+
+	// SyncFailureWebhookFormatGeneric is the webhook format that delivers the
+	// JSON encoded SyncFailureNotification.
+	SyncFailureWebhookFormatGeneric = "generic"
+	// SyncFailureWebhookFormatSlack is the webhook format that delivers a Slack
+	// incoming webhook message.
+	SyncFailureWebhookFormatSlack = "slack"
+
+	defaultSyncFailureWebhookTimeout = time.Second * 10
+)
+
+// SyncFailureNotification is the payload delivered to the generic sync failure
+// webhook.
+type SyncFailureNotification struct {
+	// Kind of the syncable secret resource.
+	Kind string `json:"kind"`
+	// Namespace of the syncable secret resource.
+	Namespace string `json:"namespace"`
+	// Name of the syncable secret resource.
+	Name string `json:"name"`
+	// Degraded is true when the resource became degraded, and false when it
+	// recovered.
+	Degraded bool `json:"degraded"`
+	// ConsecutiveFailures is the number of consecutive sync failures.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// Reason of the last sync failure.
+	Reason string `json:"reason,omitempty"`
+	// Message of the last sync failure.
+	Message string `json:"message,omitempty"`
+	// Time of the notification.
+	Time time.Time `json:"time"`
+}
+
+// SyncFailureAlert raises an alert once the sync of a syncable secret resource
+// has failed Threshold consecutive times. Every failure is reported by a
+// warning event of the resource's controller. When the threshold is reached the
+// resource is marked degraded: its SyncDegraded condition is set, a
+// SyncDegraded warning event is emitted, and the webhook is notified, if
+// configured. The alert is cleared by the next successful sync. It is meant to
+// be set on the SyncStatusRegistry.
+//
+// All methods are safe to call on a nil SyncFailureAlert.
+type SyncFailureAlert struct {
+	// Client used to update the resource's status conditions.
+	Client client.Client
+	// Threshold of consecutive sync failures after which a resource is
+	// degraded. The alert is disabled when it is less than 1.
+	Threshold int
+	// WebhookURL is notified whenever a resource becomes degraded, or recovers.
+	// The webhook is disabled when it is empty.
+	WebhookURL string
+	// WebhookFormat of the notification, one of generic or slack.
+	WebhookFormat string
+	// HTTPClient used to deliver the webhook notifications, defaults to a client
+	// with a 10s timeout.
+	HTTPClient *http.Client
+}
+
+// Validate the SyncFailureAlert's configuration.
+func (a *SyncFailureAlert) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	switch a.WebhookFormat {
+	case "", SyncFailureWebhookFormatGeneric, SyncFailureWebhookFormatSlack:
+	default:
+		return fmt.Errorf("unsupported webhook format %q, valid values are: %v", a.WebhookFormat,
+			[]string{SyncFailureWebhookFormatGeneric, SyncFailureWebhookFormatSlack})
+	}
+
+	return nil
+}
+
+func (a *SyncFailureAlert) threshold() int {
+	if a == nil {
+		return 0
+	}
+	return a.Threshold
+}
+
+// syncHealthTransition is a change of the degraded state of a resource.
+type syncHealthTransition struct {
+	status   SyncStatus
+	degraded bool
+	failures int
+}
+
+// handle the transition t of o. The events are emitted with recorder, which
+// must not be wrapped by the SyncStatusRegistry.
+func (a *SyncFailureAlert) handle(o client.Object, recorder record.EventRecorder, t *syncHealthTransition) {
+	if a == nil || t == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSyncFailureWebhookTimeout)
+	defer cancel()
+
+	logger := ctrl.Log.WithName("syncFailureAlert").WithValues(
+		"kind", t.status.Kind, "namespace", t.status.Namespace, "name", t.status.Name)
+
+	conditions := statusConditions(o)
+	if t.degraded {
+		message := fmt.Sprintf("Sync failed %d consecutive times, last error: %s", t.failures, t.status.Message)
+		logger.Info("Sync degraded", "consecutiveFailures", t.failures)
+		recorder.Event(o, corev1.EventTypeWarning, consts.ReasonSyncDegraded, message)
+		if conditions != nil && replaceCondition(conditions, metav1.Condition{
+			Type:               conditionTypeSyncDegraded,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: o.GetGeneration(),
+			Reason:             consts.ReasonSyncDegraded,
+			Message:            message,
+		}) {
+			a.updateStatus(ctx, o)
+		}
+	} else {
+		logger.Info("Sync recovered", "consecutiveFailures", t.failures)
+		recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonSyncRecovered,
+			"Sync recovered after %d consecutive failures", t.failures)
+		if conditions != nil && hasCondition(*conditions, conditionTypeSyncDegraded) {
+			*conditions = removeConditions(*conditions, conditionTypeSyncDegraded)
+			a.updateStatus(ctx, o)
+		}
+	}
+
+	if a.WebhookURL != "" {
+		n := SyncFailureNotification{
+			Kind:                t.status.Kind,
+			Namespace:           t.status.Namespace,
+			Name:                t.status.Name,
+			Degraded:            t.degraded,
+			ConsecutiveFailures: t.failures,
+			Reason:              t.status.Reason,
+			Message:             t.status.Message,
+			Time:                nowFunc().UTC(),
+		}
+		// deliver the notification in the background, so that a slow webhook
+		// never blocks the reconciliation.
+		go func() {
+			if err := a.notify(n); err != nil {
+				logger.Error(err, "Failed to deliver the sync failure webhook notification")
+			}
+		}()
+	}
+}
+
+func (a *SyncFailureAlert) updateStatus(ctx context.Context, o client.Object) {
+	if a.Client == nil {
+		return
+	}
+	if err := a.Client.Status().Update(ctx, o); err != nil {
+		ctrl.Log.WithName("syncFailureAlert").Error(err, "Failed to update the status",
+			"conditionType", conditionTypeSyncDegraded, "object", client.ObjectKeyFromObject(o))
+	}
+}
+
+// notify delivers n to the webhook.
+func (a *SyncFailureAlert) notify(n SyncFailureNotification) error {
+	var payload any = n
+	if a.WebhookFormat == SyncFailureWebhookFormatSlack {
+		payload = map[string]string{
+			"text": slackSyncFailureText(n),
+		}
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSyncFailureWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.WebhookURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultSyncFailureWebhookTimeout}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected webhook response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func slackSyncFailureText(n SyncFailureNotification) string {
+	if n.Degraded {
+		return fmt.Sprintf(":warning: %s %s/%s sync degraded after %d consecutive failures: %s",
+			n.Kind, n.Namespace, n.Name, n.ConsecutiveFailures, n.Message)
+	}
+	return fmt.Sprintf(":white_check_mark: %s %s/%s sync recovered after %d consecutive failures",
+		n.Kind, n.Namespace, n.Name, n.ConsecutiveFailures)
+}
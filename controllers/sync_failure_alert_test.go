@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestSyncFailureAlert(t *testing.T) {
+	ctx := context.Background()
+
+	notifications := make(chan SyncFailureNotification, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var n SyncFailureNotification
+		if err := json.NewDecoder(req.Body).Decode(&n); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		notifications <- n
+	}))
+	t.Cleanup(srv.Close)
+
+	o := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "foo",
+			Generation: 3,
+		},
+	}
+	objKey := client.ObjectKeyFromObject(o)
+	c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+
+	r := NewSyncStatusRegistry()
+	r.FailureAlert = &SyncFailureAlert{
+		Client:     c,
+		Threshold:  3,
+		WebhookURL: srv.URL,
+	}
+	require.NoError(t, r.FailureAlert.Validate())
+
+	fake := record.NewFakeRecorder(10)
+	recorder := r.EventRecorder(VaultStaticSecret, fake)
+
+	// the resource is not degraded until the threshold is reached.
+	for i := 0; i < 2; i++ {
+		recorder.Event(o, corev1.EventTypeWarning, consts.ReasonVaultClientError, "permission denied")
+	}
+	got, ok := r.Get(VaultStaticSecret, objKey)
+	require.True(t, ok)
+	assert.Equal(t, 2, got.ConsecutiveFailures)
+	assert.False(t, got.Degraded)
+	assert.Len(t, fake.Events, 2)
+	assert.Empty(t, o.Status.Conditions)
+
+	for i := 0; i < 2; i++ {
+		recorder.Event(o, corev1.EventTypeWarning, consts.ReasonVaultClientError, "permission denied")
+	}
+	got, ok = r.Get(VaultStaticSecret, objKey)
+	require.True(t, ok)
+	assert.Equal(t, 4, got.ConsecutiveFailures)
+	assert.True(t, got.Degraded)
+	// the alert is only raised once.
+	require.Len(t, fake.Events, 5)
+	for i := 0; i < 3; i++ {
+		<-fake.Events
+	}
+	assert.Equal(t, "Warning SyncDegraded Sync failed 3 consecutive times, last error: permission denied",
+		<-fake.Events)
+
+	var stored secretsv1beta1.VaultStaticSecret
+	require.NoError(t, c.Get(ctx, objKey, &stored))
+	require.Len(t, stored.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeSyncDegraded, stored.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, stored.Status.Conditions[0].Status)
+	assert.Equal(t, consts.ReasonSyncDegraded, stored.Status.Conditions[0].Reason)
+	assert.Equal(t, int64(3), stored.Status.Conditions[0].ObservedGeneration)
+
+	select {
+	case n := <-notifications:
+		assert.Equal(t, VaultStaticSecret.String(), n.Kind)
+		assert.Equal(t, objKey.Namespace, n.Namespace)
+		assert.Equal(t, objKey.Name, n.Name)
+		assert.True(t, n.Degraded)
+		assert.Equal(t, 3, n.ConsecutiveFailures)
+		assert.Equal(t, consts.ReasonVaultClientError, n.Reason)
+		assert.Equal(t, "permission denied", n.Message)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for the degraded notification")
+	}
+
+	// the next successful sync clears the alert.
+	<-fake.Events
+	recorder.Event(o, corev1.EventTypeNormal, consts.ReasonSecretSynced, "synced")
+	got, ok = r.Get(VaultStaticSecret, objKey)
+	require.True(t, ok)
+	assert.Equal(t, 0, got.ConsecutiveFailures)
+	assert.False(t, got.Degraded)
+	require.Len(t, fake.Events, 2)
+	<-fake.Events
+	assert.Equal(t, "Normal SyncRecovered Sync recovered after 4 consecutive failures", <-fake.Events)
+
+	require.NoError(t, c.Get(ctx, objKey, &stored))
+	assert.Empty(t, stored.Status.Conditions)
+
+	select {
+	case n := <-notifications:
+		assert.False(t, n.Degraded)
+		assert.Equal(t, 4, n.ConsecutiveFailures)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for the recovered notification")
+	}
+}
+
+func TestSyncFailureAlert_Validate(t *testing.T) {
+	t.Parallel()
+
+	var a *SyncFailureAlert
+	assert.NoError(t, a.Validate())
+	for _, format := range []string{"", SyncFailureWebhookFormatGeneric, SyncFailureWebhookFormatSlack} {
+		assert.NoError(t, (&SyncFailureAlert{WebhookFormat: format}).Validate())
+	}
+	assert.EqualError(t, (&SyncFailureAlert{WebhookFormat: "teams"}).Validate(),
+		`unsupported webhook format "teams", valid values are: [generic slack]`)
+}
+
+func TestSyncFailureAlert_notifySlack(t *testing.T) {
+	t.Parallel()
+
+	bodies := make(chan map[string]string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		bodies <- body
+	}))
+	t.Cleanup(srv.Close)
+
+	a := &SyncFailureAlert{
+		WebhookURL:    srv.URL,
+		WebhookFormat: SyncFailureWebhookFormatSlack,
+	}
+	require.NoError(t, a.notify(SyncFailureNotification{
+		Kind:                VaultDynamicSecret.String(),
+		Namespace:           "default",
+		Name:                "db",
+		Degraded:            true,
+		ConsecutiveFailures: 5,
+		Message:             "permission denied",
+	}))
+	assert.Equal(t, map[string]string{
+		"text": ":warning: VaultDynamicSecret default/db sync degraded after 5 consecutive failures: permission denied",
+	}, <-bodies)
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(notFound.Close)
+	a.WebhookURL = notFound.URL
+	assert.EqualError(t, a.notify(SyncFailureNotification{}), "unexpected webhook response status 404")
+}
@@ -0,0 +1,356 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// syncSuccessReasons are the event reasons that denote a successful sync from
+// the secret source.
+var syncSuccessReasons = map[string]bool{
+	consts.ReasonSecretSynced:       true,
+	consts.ReasonSecretRotated:      true,
+	consts.ReasonSecretSync:         true,
+	consts.ReasonSecretLeaseRenewal: true,
+}
+
+// SyncStatus is the operator's view of the sync state of a single syncable
+// secret resource.
+type SyncStatus struct {
+	// Kind of the syncable secret resource.
+	Kind string `json:"kind"`
+	// Namespace of the syncable secret resource.
+	Namespace string `json:"namespace"`
+	// Name of the syncable secret resource.
+	Name string `json:"name"`
+	// Healthy is true if the last sync attempt was successful.
+	Healthy bool `json:"healthy"`
+	// Reason for the last observed sync condition.
+	Reason string `json:"reason,omitempty"`
+	// Message for the last observed sync condition.
+	Message string `json:"message,omitempty"`
+	// LastSyncTime is the time of the last successful sync.
+	LastSyncTime *time.Time `json:"lastSyncTime,omitempty"`
+	// LastErrorTime is the time of the last failed sync.
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+	// NextSyncTime is the time of the next scheduled sync. It is empty when no
+	// sync is scheduled.
+	NextSyncTime *time.Time `json:"nextSyncTime,omitempty"`
+	// ConsecutiveFailures is the number of sync failures since the last
+	// successful sync.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+	// Degraded is true once ConsecutiveFailures has reached the
+	// SyncFailureAlert's threshold.
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// SyncStatusList is the aggregated view of all SyncStatus entries. It is the
+// response body of the SyncStatusRegistry's HTTP handler.
+type SyncStatusList struct {
+	Items []SyncStatus `json:"items"`
+}
+
+type syncStatusKey struct {
+	kind   ResourceKind
+	objKey client.ObjectKey
+}
+
+var _ http.Handler = (*SyncStatusRegistry)(nil)
+
+// SyncStatusRegistry maintains an aggregated, in-memory view of the sync status
+// of every syncable secret resource managed by the operator. Entries are
+// populated from the events emitted by the secret controllers, along with the
+// results of each reconciliation. The registry can be served as a
+// machine-readable status endpoint.
+//
+// All methods are safe to call on a nil SyncStatusRegistry.
+type SyncStatusRegistry struct {
+	// FailureAlert is raised after consecutive sync failures, it is optional.
+	FailureAlert *SyncFailureAlert
+	m            map[syncStatusKey]*SyncStatus
+	mu           sync.RWMutex
+}
+
+// NewSyncStatusRegistry returns a SyncStatusRegistry.
+func NewSyncStatusRegistry() *SyncStatusRegistry {
+	return &SyncStatusRegistry{
+		m: map[syncStatusKey]*SyncStatus{},
+	}
+}
+
+// Get the SyncStatus for objKey of kind.
+func (r *SyncStatusRegistry) Get(kind ResourceKind, objKey client.ObjectKey) (SyncStatus, bool) {
+	if r == nil {
+		return SyncStatus{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.m[syncStatusKey{kind: kind, objKey: objKey}]
+	if !ok {
+		return SyncStatus{}, false
+	}
+	return *s, true
+}
+
+// Delete the SyncStatus for objKey of kind, along with all of its metrics.
+// Should be called whenever the resource has been deleted.
+func (r *SyncStatusRegistry) Delete(kind ResourceKind, objKey client.ObjectKey) bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics.DeleteSecretMetrics(metricsController(kind), objKey)
+
+	key := syncStatusKey{kind: kind, objKey: objKey}
+	_, ok := r.m[key]
+	delete(r.m, key)
+	return ok
+}
+
+// List returns all SyncStatus entries sorted by kind, namespace, and name.
+func (r *SyncStatusRegistry) List() []SyncStatus {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]SyncStatus, 0, len(r.m))
+	for _, s := range r.m {
+		result = append(result, *s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// ServeHTTP writes the JSON encoded SyncStatusList.
+func (r *SyncStatusRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := json.Marshal(&SyncStatusList{Items: r.List()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// update the SyncStatus for objKey of kind with f. If create is false, then
+// only existing entries will be updated.
+func (r *SyncStatusRegistry) update(kind ResourceKind, objKey client.ObjectKey, create bool, f func(s *SyncStatus)) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := syncStatusKey{kind: kind, objKey: objKey}
+	s, ok := r.m[key]
+	if !ok {
+		if !create {
+			return
+		}
+		s = &SyncStatus{
+			Kind:      kind.String(),
+			Namespace: objKey.Namespace,
+			Name:      objKey.Name,
+		}
+		r.m[key] = s
+	}
+
+	f(s)
+}
+
+// observeEvent updates the SyncStatus from an event emitted by a secret
+// controller. Warning events always mark the resource as unhealthy, and count
+// as a sync failure. Returns the transition of the resource's degraded state,
+// if any.
+func (r *SyncStatusRegistry) observeEvent(kind ResourceKind, obj runtime.Object, eventType, reason, message string) *syncHealthTransition {
+	o, ok := obj.(client.Object)
+	if !ok || r == nil {
+		return nil
+	}
+
+	var t *syncHealthTransition
+	now := nowFunc()
+	objKey := client.ObjectKeyFromObject(o)
+	switch {
+	case eventType == corev1.EventTypeWarning:
+		metrics.IncSecretSyncErrors(metricsController(kind), objKey)
+		threshold := r.FailureAlert.threshold()
+		r.update(kind, objKey, true, func(s *SyncStatus) {
+			s.Healthy = false
+			s.Reason = reason
+			s.Message = message
+			s.LastErrorTime = &now
+			s.ConsecutiveFailures++
+			if threshold > 0 && !s.Degraded && s.ConsecutiveFailures >= threshold {
+				s.Degraded = true
+				t = &syncHealthTransition{status: *s, degraded: true, failures: s.ConsecutiveFailures}
+			}
+		})
+	case syncSuccessReasons[reason]:
+		metrics.SetSecretLastSyncTimestamp(metricsController(kind), objKey, now)
+		r.update(kind, objKey, true, func(s *SyncStatus) {
+			s.Healthy = true
+			s.Reason = reason
+			s.Message = message
+			s.LastSyncTime = &now
+			if s.Degraded {
+				t = &syncHealthTransition{status: *s, degraded: false, failures: s.ConsecutiveFailures}
+			}
+			s.ConsecutiveFailures = 0
+			s.Degraded = false
+		})
+	}
+
+	return t
+}
+
+// observeResult updates the next scheduled sync time from the result of a
+// reconciliation.
+func (r *SyncStatusRegistry) observeResult(kind ResourceKind, objKey client.ObjectKey, result ctrl.Result, err error) {
+	controller := metricsController(kind)
+	if err != nil {
+		metrics.IncSecretSyncErrors(controller, objKey)
+		metrics.DeleteSecretNextRotationTimestamp(controller, objKey)
+		r.update(kind, objKey, false, func(s *SyncStatus) {
+			now := nowFunc()
+			s.Healthy = false
+			s.Message = err.Error()
+			s.LastErrorTime = &now
+			s.NextSyncTime = nil
+		})
+		return
+	}
+
+	if result.RequeueAfter > 0 {
+		next := nowFunc().Add(result.RequeueAfter)
+		metrics.SetSecretNextRotationTimestamp(controller, objKey, next)
+		r.update(kind, objKey, true, func(s *SyncStatus) {
+			s.NextSyncTime = &next
+		})
+		return
+	}
+
+	metrics.DeleteSecretNextRotationTimestamp(controller, objKey)
+	r.update(kind, objKey, false, func(s *SyncStatus) {
+		s.NextSyncTime = nil
+	})
+}
+
+// EventRecorder returns a record.EventRecorder that records the sync status of
+// all events for kind, before passing them on to recorder.
+func (r *SyncStatusRegistry) EventRecorder(kind ResourceKind, recorder record.EventRecorder) record.EventRecorder {
+	if r == nil {
+		return recorder
+	}
+
+	return &syncStatusEventRecorder{
+		EventRecorder: recorder,
+		kind:          kind,
+		registry:      r,
+	}
+}
+
+// Reconciler returns a reconcile.Reconciler that records the result of every
+// reconciliation of kind performed by reconciler.
+func (r *SyncStatusRegistry) Reconciler(kind ResourceKind, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	if r == nil {
+		return reconciler
+	}
+
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		start := nowFunc()
+		result, err := reconciler.Reconcile(ctx, req)
+		r.observeResult(kind, req.NamespacedName, result, err)
+		// only observe the duration for resources that have not been deleted.
+		if _, ok := r.Get(kind, req.NamespacedName); ok {
+			metrics.ObserveSecretSyncDuration(metricsController(kind), req.NamespacedName, nowFunc().Sub(start))
+		}
+		return result, err
+	})
+}
+
+// metricsController returns the controller label value for the metrics of kind.
+func metricsController(kind ResourceKind) string {
+	return strings.ToLower(kind.String())
+}
+
+var _ record.EventRecorder = (*syncStatusEventRecorder)(nil)
+
+type syncStatusEventRecorder struct {
+	record.EventRecorder
+	kind     ResourceKind
+	registry *SyncStatusRegistry
+}
+
+func (e *syncStatusEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	t := e.registry.observeEvent(e.kind, object, eventtype, reason, message)
+	e.EventRecorder.Event(object, eventtype, reason, message)
+	e.alert(object, t)
+}
+
+func (e *syncStatusEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	t := e.registry.observeEvent(e.kind, object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+	e.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	e.alert(object, t)
+}
+
+func (e *syncStatusEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	t := e.registry.observeEvent(e.kind, object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+	e.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	e.alert(object, t)
+}
+
+// alert raises the SyncFailureAlert for the transition t, once the event that
+// caused it has been recorded.
+func (e *syncStatusEventRecorder) alert(object runtime.Object, t *syncHealthTransition) {
+	if t == nil {
+		return
+	}
+	if o, ok := object.(client.Object); ok {
+		e.registry.FailureAlert.handle(o, e.EventRecorder, t)
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+func TestSyncStatusRegistry_EventRecorder(t *testing.T) {
+	t.Parallel()
+
+	obj := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+	}
+	objKey := client.ObjectKeyFromObject(obj)
+
+	tests := []struct {
+		name        string
+		events      [][]string
+		wantOk      bool
+		wantHealthy bool
+		wantReason  string
+		wantSync    bool
+		wantError   bool
+	}{
+		{
+			name: "synced",
+			events: [][]string{
+				{corev1.EventTypeNormal, consts.ReasonSecretSynced, "synced"},
+			},
+			wantOk:      true,
+			wantHealthy: true,
+			wantReason:  consts.ReasonSecretSynced,
+			wantSync:    true,
+		},
+		{
+			name: "warning",
+			events: [][]string{
+				{corev1.EventTypeWarning, consts.ReasonVaultClientError, "failed"},
+			},
+			wantOk:      true,
+			wantHealthy: false,
+			wantReason:  consts.ReasonVaultClientError,
+			wantError:   true,
+		},
+		{
+			name: "warning-then-synced",
+			events: [][]string{
+				{corev1.EventTypeWarning, consts.ReasonVaultClientError, "failed"},
+				{corev1.EventTypeNormal, consts.ReasonSecretRotated, "rotated"},
+			},
+			wantOk:      true,
+			wantHealthy: true,
+			wantReason:  consts.ReasonSecretRotated,
+			wantSync:    true,
+			wantError:   true,
+		},
+		{
+			name: "ignored",
+			events: [][]string{
+				{corev1.EventTypeNormal, consts.ReasonAccepted, "accepted"},
+			},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSyncStatusRegistry()
+			fake := record.NewFakeRecorder(len(tt.events))
+			recorder := r.EventRecorder(VaultStaticSecret, fake)
+			for _, e := range tt.events {
+				recorder.Event(obj, e[0], e[1], e[2])
+			}
+			assert.Len(t, fake.Events, len(tt.events))
+
+			got, ok := r.Get(VaultStaticSecret, objKey)
+			require.Equal(t, tt.wantOk, ok)
+			if !tt.wantOk {
+				return
+			}
+
+			assert.Equal(t, VaultStaticSecret.String(), got.Kind)
+			assert.Equal(t, objKey.Namespace, got.Namespace)
+			assert.Equal(t, objKey.Name, got.Name)
+			assert.Equal(t, tt.wantHealthy, got.Healthy)
+			assert.Equal(t, tt.wantReason, got.Reason)
+			assert.Equal(t, tt.wantSync, got.LastSyncTime != nil)
+			assert.Equal(t, tt.wantError, got.LastErrorTime != nil)
+		})
+	}
+}
+
+func TestSyncStatusRegistry_Reconciler(t *testing.T) {
+	t.Parallel()
+
+	objKey := client.ObjectKey{
+		Namespace: "foo",
+		Name:      "bar",
+	}
+
+	tests := []struct {
+		name        string
+		exists      bool
+		result      ctrl.Result
+		err         error
+		wantOk      bool
+		wantHealthy bool
+		wantNext    bool
+	}{
+		{
+			name:     "requeue-after",
+			result:   ctrl.Result{RequeueAfter: time.Minute},
+			wantOk:   true,
+			wantNext: true,
+		},
+		{
+			name:        "no-requeue-existing",
+			exists:      true,
+			result:      ctrl.Result{},
+			wantOk:      true,
+			wantHealthy: true,
+		},
+		{
+			name:   "no-requeue-not-existing",
+			result: ctrl.Result{},
+			wantOk: false,
+		},
+		{
+			name:        "error-existing",
+			exists:      true,
+			err:         errors.New("reconcile error"),
+			wantOk:      true,
+			wantHealthy: false,
+		},
+		{
+			name:   "error-not-existing",
+			err:    errors.New("reconcile error"),
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSyncStatusRegistry()
+			if tt.exists {
+				next := nowFunc().Add(time.Minute)
+				r.update(VaultDynamicSecret, objKey, true, func(s *SyncStatus) {
+					s.Healthy = true
+					s.NextSyncTime = &next
+				})
+			}
+
+			reconciler := r.Reconciler(VaultDynamicSecret,
+				reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+					return tt.result, tt.err
+				}))
+
+			result, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: objKey})
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.err, err)
+
+			got, ok := r.Get(VaultDynamicSecret, objKey)
+			require.Equal(t, tt.wantOk, ok)
+			if !tt.wantOk {
+				return
+			}
+
+			assert.Equal(t, tt.wantHealthy, got.Healthy)
+			assert.Equal(t, tt.wantNext, got.NextSyncTime != nil)
+			if tt.err != nil {
+				assert.Equal(t, tt.err.Error(), got.Message)
+				assert.NotNil(t, got.LastErrorTime)
+			}
+		})
+	}
+}
+
+func TestSyncStatusRegistry_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	r := NewSyncStatusRegistry()
+	keys := []struct {
+		kind   ResourceKind
+		objKey client.ObjectKey
+	}{
+		{kind: VaultStaticSecret, objKey: client.ObjectKey{Namespace: "foo", Name: "b"}},
+		{kind: VaultDynamicSecret, objKey: client.ObjectKey{Namespace: "foo", Name: "a"}},
+		{kind: VaultStaticSecret, objKey: client.ObjectKey{Namespace: "bar", Name: "c"}},
+		{kind: VaultStaticSecret, objKey: client.ObjectKey{Namespace: "foo", Name: "a"}},
+	}
+	for _, k := range keys {
+		r.update(k.kind, k.objKey, true, func(s *SyncStatus) {
+			s.Healthy = true
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var got SyncStatusList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []SyncStatus{
+		{Kind: VaultDynamicSecret.String(), Namespace: "foo", Name: "a", Healthy: true},
+		{Kind: VaultStaticSecret.String(), Namespace: "bar", Name: "c", Healthy: true},
+		{Kind: VaultStaticSecret.String(), Namespace: "foo", Name: "a", Healthy: true},
+		{Kind: VaultStaticSecret.String(), Namespace: "foo", Name: "b", Healthy: true},
+	}, got.Items)
+
+	assert.True(t, r.Delete(VaultStaticSecret, client.ObjectKey{Namespace: "foo", Name: "a"}))
+	assert.False(t, r.Delete(VaultStaticSecret, client.ObjectKey{Namespace: "foo", Name: "a"}))
+	assert.Len(t, r.List(), 3)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/status", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestSyncStatusRegistry_metrics(t *testing.T) {
+	t.Parallel()
+
+	obj := &secretsv1beta1.VaultPKISecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "metrics",
+			Name:      "pki",
+		},
+	}
+	objKey := client.ObjectKeyFromObject(obj)
+	controller := "vaultpkisecret"
+
+	getValue := func(t *testing.T, c prometheus.Collector) (float64, bool) {
+		t.Helper()
+		ch := make(chan prometheus.Metric)
+		go func() {
+			c.Collect(ch)
+			close(ch)
+		}()
+		var value float64
+		var found bool
+		for m := range ch {
+			var pb io_prometheus_client.Metric
+			require.NoError(t, m.Write(&pb))
+			labels := map[string]string{}
+			for _, l := range pb.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["controller"] != controller ||
+				labels["name"] != objKey.Name || labels["namespace"] != objKey.Namespace {
+				continue
+			}
+			found = true
+			switch {
+			case pb.Gauge != nil:
+				value = pb.GetGauge().GetValue()
+			case pb.Counter != nil:
+				value = pb.GetCounter().GetValue()
+			case pb.Histogram != nil:
+				value = float64(pb.GetHistogram().GetSampleCount())
+			}
+		}
+		return value, found
+	}
+
+	r := NewSyncStatusRegistry()
+	recorder := r.EventRecorder(VaultPKISecret, record.NewFakeRecorder(10))
+	reconciler := r.Reconciler(VaultPKISecret,
+		reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			recorder.Event(obj, corev1.EventTypeNormal, consts.ReasonSecretRotated, "rotated")
+			return ctrl.Result{RequeueAfter: time.Hour}, nil
+		}))
+
+	_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: objKey})
+	require.NoError(t, err)
+
+	lastSync, ok := getValue(t, metrics.SecretLastSyncTimestamp)
+	require.True(t, ok)
+	assert.InDelta(t, float64(nowFunc().Unix()), lastSync, 5)
+
+	nextRotation, ok := getValue(t, metrics.SecretNextRotationTimestamp)
+	require.True(t, ok)
+	assert.InDelta(t, float64(nowFunc().Add(time.Hour).Unix()), nextRotation, 5)
+
+	count, ok := getValue(t, metrics.SecretSyncDuration)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), count)
+
+	recorder.Event(obj, corev1.EventTypeWarning, consts.ReasonVaultClientError, "failed")
+	errs, ok := getValue(t, metrics.SecretSyncErrors)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), errs)
+
+	assert.True(t, r.Delete(VaultPKISecret, objKey))
+	for _, c := range []prometheus.Collector{
+		metrics.SecretLastSyncTimestamp,
+		metrics.SecretNextRotationTimestamp,
+		metrics.SecretSyncDuration,
+		metrics.SecretSyncErrors,
+	} {
+		_, ok := getValue(t, c)
+		assert.False(t, ok)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// conditionTypeThrottled is the condition type that reports that the last
+// request to Vault was throttled, e.g. it was rate limited, or the Vault server
+// was unavailable. It is cleared once the resource is synced again.
+const conditionTypeThrottled = "Throttled"
+
+// handleThrottled should be called when syncing o from Vault failed with err. If
+// Vault throttled the request, the Throttled condition of o is set, and the
+// duration after which the sync should be retried is returned, along with true.
+// The retry honors the delay requested by Vault, if any, rather than advancing
+// the resource's exponential backoff. The event is of type Normal, a throttled
+// request is not counted as a sync failure.
+func handleThrottled(ctx context.Context, c client.Client, o client.Object,
+	recorder record.EventRecorder, err error,
+) (time.Duration, bool) {
+	var throttledErr *vault.ThrottledError
+	if !errors.As(err, &throttledErr) {
+		return 0, false
+	}
+
+	horizon := computeHorizonWithJitter(requeueDurationOnError)
+	if throttledErr.RetryAfter > 0 {
+		// never retry before Vault asked for it.
+		_, jitter := computeMaxJitterDuration(throttledErr.RetryAfter)
+		horizon = throttledErr.RetryAfter + jitter
+	}
+
+	msg := fmt.Sprintf("Vault throttled the request, status=%d, retrying in %s",
+		throttledErr.StatusCode, horizon.Truncate(time.Second))
+	log.FromContext(ctx).V(consts.LogLevelWarning).Info(msg, "err", err)
+	recorder.Event(o, corev1.EventTypeNormal, consts.ReasonVaultThrottled, msg)
+
+	if conditions := statusConditions(o); conditions != nil {
+		if replaceCondition(conditions, metav1.Condition{
+			Type:               conditionTypeThrottled,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: o.GetGeneration(),
+			Reason:             consts.ReasonVaultThrottled,
+			Message:            fmt.Sprintf("Vault throttled the request, status=%d", throttledErr.StatusCode),
+		}) {
+			if err := c.Status().Update(ctx, o); err != nil {
+				log.FromContext(ctx).Error(err, "Failed to update the throttled status")
+			}
+		}
+	}
+
+	return horizon, true
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+func Test_handleThrottled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		err         error
+		wantOK      bool
+		wantMin     time.Duration
+		wantMax     time.Duration
+		wantMessage string
+	}{
+		{
+			name: "not-throttled",
+			err:  fmt.Errorf("permission denied"),
+		},
+		{
+			name: "retry-after",
+			err: fmt.Errorf("read failed: %w", &vault.ThrottledError{
+				StatusCode: http.StatusTooManyRequests,
+				RetryAfter: 30 * time.Second,
+			}),
+			wantOK:      true,
+			wantMin:     30 * time.Second,
+			wantMax:     33 * time.Second,
+			wantMessage: "Vault throttled the request, status=429",
+		},
+		{
+			name: "without-retry-after",
+			err: &vault.ThrottledError{
+				StatusCode: http.StatusServiceUnavailable,
+			},
+			wantOK:      true,
+			wantMin:     requeueDurationOnError * 8 / 10,
+			wantMax:     requeueDurationOnError,
+			wantMessage: "Vault throttled the request, status=503",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			o := &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  "default",
+					Name:       "foo",
+					Generation: 2,
+				},
+			}
+			c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+			recorder := record.NewFakeRecorder(10)
+
+			got, ok := handleThrottled(ctx, c, o, recorder, tt.err)
+			assert.Equal(t, tt.wantOK, ok)
+
+			var obj secretsv1beta1.VaultStaticSecret
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &obj))
+			if !tt.wantOK {
+				assert.Zero(t, got)
+				assert.Empty(t, recorder.Events)
+				assert.Empty(t, obj.Status.Conditions)
+				return
+			}
+
+			assert.GreaterOrEqual(t, got, tt.wantMin)
+			assert.LessOrEqual(t, got, tt.wantMax)
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, "Normal "+consts.ReasonVaultThrottled)
+			require.Len(t, obj.Status.Conditions, 1)
+			assert.Equal(t, conditionTypeThrottled, obj.Status.Conditions[0].Type)
+			assert.Equal(t, metav1.ConditionTrue, obj.Status.Conditions[0].Status)
+			assert.Equal(t, consts.ReasonVaultThrottled, obj.Status.Conditions[0].Reason)
+			assert.Equal(t, tt.wantMessage, obj.Status.Conditions[0].Message)
+			assert.Equal(t, int64(2), obj.Status.Conditions[0].ObservedGeneration)
+			assert.Nil(t, obj.Status.BackOff)
+
+			// the condition is cleared along with the backoff.
+			resetBackOff(NewBackOffRegistry(), &obj)
+			assert.Empty(t, obj.Status.Conditions)
+		})
+	}
+}
@@ -29,6 +29,14 @@ func ValidateSecretTransformation(_ context.Context, o client.Object) error {
 			} else {
 				name = fmt.Sprintf("%s/%s", objKey, name)
 			}
+			if tmpl.ConfigMapRef != nil {
+				if tmpl.Text != "" {
+					errs = errors.Join(errs, fmt.Errorf(
+						"source template %s: text and configMapRef are mutually exclusive", name))
+				}
+				// the template text is validated once it is read from the ConfigMap.
+				continue
+			}
 			if err := stmpl.Parse(name, tmpl.Text); err != nil {
 				errs = errors.Join(errs, err)
 			}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// conditionTypeVaultPathDenied is the condition type that reports that a
+// resource references a Vault path that is not allowed by the operator's
+// VaultPathPolicy.
+const conditionTypeVaultPathDenied = "VaultPathDenied"
+
+// vaultPaths returns the Vault paths referenced by the syncable secret
+// resource o, i.e. its mount joined with its path, role, or key.
+func vaultPaths(o client.Object) []string {
+	switch t := o.(type) {
+	case *secretsv1beta1.VaultStaticSecret:
+		paths := []string{vault.JoinPath(t.Spec.Mount, t.Spec.Path)}
+		if t.Spec.TransitDecrypt != nil {
+			paths = append(paths, vault.JoinPath(t.Spec.TransitDecrypt.Mount, t.Spec.TransitDecrypt.Key))
+		}
+		return paths
+	case *secretsv1beta1.VaultDynamicSecret:
+		return []string{vault.JoinPath(t.Spec.Mount, t.Spec.Path)}
+	case *secretsv1beta1.VaultPKISecret:
+		return []string{vault.JoinPath(t.Spec.Mount, t.Spec.Role)}
+	case *secretsv1beta1.VaultSSHCertificate:
+		return []string{vault.JoinPath(t.Spec.Mount, t.Spec.Role)}
+	case *secretsv1beta1.VaultTransitKey:
+		return []string{vault.JoinPath(t.Spec.Mount, t.Spec.Key)}
+	case *secretsv1beta1.VaultSecretExport:
+		return []string{vault.JoinPath(t.Spec.Mount, t.Spec.Path)}
+	default:
+		return nil
+	}
+}
+
+// handleVaultPathPolicy should be called at the start of each reconciliation
+// of o, once its suspension has been handled. It returns true if o references
+// a Vault path that is not allowed by policy, in which case the
+// reconciliation should end without a requeue, the resource is reconciled
+// again once it is updated. The VaultPathDenied condition of o is set while
+// its paths are not allowed, and cleared once they are.
+func handleVaultPathPolicy(ctx context.Context, c client.Client, o client.Object,
+	policy *common.VaultPathPolicy, recorder record.EventRecorder,
+) (bool, error) {
+	conditions := statusConditions(o)
+	if conditions == nil {
+		return false, nil
+	}
+
+	var err error
+	for _, p := range vaultPaths(o) {
+		if err = policy.Allowed(p); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		if !hasCondition(*conditions, conditionTypeVaultPathDenied) {
+			return false, nil
+		}
+		*conditions = removeConditions(*conditions, conditionTypeVaultPathDenied)
+		return false, c.Status().Update(ctx, o)
+	}
+
+	if !replaceCondition(conditions, metav1.Condition{
+		Type:               conditionTypeVaultPathDenied,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: o.GetGeneration(),
+		Reason:             consts.ReasonVaultPathNotAllowed,
+		Message:            err.Error(),
+	}) {
+		return true, nil
+	}
+
+	log.FromContext(ctx).Error(err, "Refusing to sync the resource")
+	recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonVaultPathNotAllowed,
+		"Refusing to sync the resource: %s", err)
+
+	return true, c.Status().Update(ctx, o)
+}
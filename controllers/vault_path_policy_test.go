@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_handleVaultPathPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	o := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "foo",
+			Generation: 2,
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Mount: "kv",
+			Path:  "apps/foo",
+			TransitDecrypt: &secretsv1beta1.TransitDecrypt{
+				Mount: "sys",
+				Key:   "foo",
+			},
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+	recorder := record.NewFakeRecorder(10)
+	policy := common.NewVaultPathPolicy([]string{"kv/apps/*"}, []string{"sys/*"})
+
+	for i := 0; i < 2; i++ {
+		denied, err := handleVaultPathPolicy(ctx, c, o, policy, recorder)
+		require.NoError(t, err)
+		assert.True(t, denied)
+	}
+	assert.Len(t, recorder.Events, 1)
+
+	var got secretsv1beta1.VaultStaticSecret
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, conditionTypeVaultPathDenied, got.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, consts.ReasonVaultPathNotAllowed, got.Status.Conditions[0].Reason)
+	assert.Equal(t, `vault path "sys/foo" is denied by "sys/*"`, got.Status.Conditions[0].Message)
+	assert.Equal(t, int64(2), got.Status.Conditions[0].ObservedGeneration)
+
+	// a path that is not allowed updates the condition.
+	got.Spec.TransitDecrypt = nil
+	got.Spec.Path = "infra/foo"
+	require.NoError(t, c.Update(ctx, &got))
+	denied, err := handleVaultPathPolicy(ctx, c, &got, policy, recorder)
+	require.NoError(t, err)
+	assert.True(t, denied)
+	assert.Len(t, recorder.Events, 2)
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	require.Len(t, got.Status.Conditions, 1)
+	assert.Equal(t, `vault path "kv/infra/foo" is not allowed, allowedVaultPaths=[kv/apps/*]`,
+		got.Status.Conditions[0].Message)
+
+	// the paths are allowed, the condition is cleared.
+	got.Spec.Path = "apps/foo"
+	require.NoError(t, c.Update(ctx, &got))
+	for i := 0; i < 2; i++ {
+		denied, err := handleVaultPathPolicy(ctx, c, &got, policy, recorder)
+		require.NoError(t, err)
+		assert.False(t, denied)
+	}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+	assert.Empty(t, got.Status.Conditions)
+
+	// every path is allowed without a policy.
+	got.Spec.Path = "infra/foo"
+	denied, err = handleVaultPathPolicy(ctx, c, &got, nil, recorder)
+	require.NoError(t, err)
+	assert.False(t, denied)
+}
@@ -169,6 +169,11 @@ func (r *VaultAuthReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	o.Status.SpecHash = specHash
+	// the active auth method is recorded by the ClientFactory on login, it is
+	// only relevant when fallbacks are configured.
+	if len(o.Spec.Fallbacks) == 0 {
+		o.Status.ActiveMethod = ""
+	}
 
 	var horizon time.Duration
 	if errs != nil {
@@ -5,18 +5,27 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 
+	certificatesv1alpha1 "k8s.io/api/certificates/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/consts"
@@ -32,6 +41,13 @@ type VaultConnectionReconciler struct {
 	Scheme        *runtime.Scheme
 	Recorder      record.EventRecorder
 	ClientFactory vault.CachingClientFactory
+	// SourceCh is used to trigger a reconciliation when the state of a
+	// VaultConnection's circuit breaker changes. It is set up in SetupWithManager.
+	SourceCh chan event.GenericEvent
+	// referenceCache tracks the client certificate Secret of each
+	// VaultConnection, so that its Vault clients are rebuilt when the
+	// certificate is rotated.
+	referenceCache ResourceReferenceCache
 }
 
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultconnections,verbs=get;list;watch;create;update;patch;delete
@@ -39,6 +55,8 @@ type VaultConnectionReconciler struct {
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultconnections/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=certificates.k8s.io,resources=clustertrustbundles,verbs=get;list;watch
 // needed for managing cached Clients, duplicated in vaultauth_controller.go
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;delete;update;patch;deletecollection
 
@@ -62,9 +80,15 @@ func (r *VaultConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if o.GetDeletionTimestamp() != nil {
 		logger.Info("Got deletion timestamp", "obj", o)
 		metrics.DeleteResourceStatus("vaultconnection", o)
+		vault.DeleteConnectionGuard(req.NamespacedName)
+		r.referenceCache.Remove(Secret, req.NamespacedName)
+		r.referenceCache.Remove(ConfigMap, req.NamespacedName)
+		r.referenceCache.Remove(ClusterTrustBundle, req.NamespacedName)
 		return r.handleFinalizer(ctx, o)
 	}
 
+	r.setTLSReferences(o)
+
 	// assume that status is always invalid
 	o.Status.Valid = ptr.To(false)
 
@@ -108,6 +132,19 @@ func (r *VaultConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		errs = errors.Join(errs, err)
 	}
 
+	if err := r.handleTLSRotation(ctx, o, vaultConfig); err != nil {
+		logger.Error(err, "Failed to handle the TLS rotation")
+		errs = errors.Join(errs, err)
+	}
+
+	o.Status.CircuitBreaker = nil
+	if status, ok := vault.GetCircuitBreakerStatus(req.NamespacedName); ok {
+		o.Status.CircuitBreaker = &secretsv1beta1.VaultConnectionCircuitBreakerStatus{
+			State:              string(status.State),
+			LastTransitionTime: metav1.NewTime(status.LastTransitionTime),
+		}
+	}
+
 	if err := r.updateStatus(ctx, o); err != nil {
 		errs = errors.Join(errs, err)
 	}
@@ -132,6 +169,105 @@ func (r *VaultConnectionReconciler) updateStatus(ctx context.Context, o *secrets
 	return err
 }
 
+// handleTLSRotation prunes all the cached Clients of o, if either its client
+// certificate, or its CA bundle from cfg's CABundleRef, has changed since they
+// were built, so that they are rebuilt with the rotated TLS configuration. The
+// digests of both are recorded in o's status.
+func (r *VaultConnectionReconciler) handleTLSRotation(ctx context.Context, o *secretsv1beta1.VaultConnection, cfg *vault.ClientConfig) error {
+	var certDigest, caDigest string
+	if o.Spec.ClientCertSecretRef != "" {
+		s := &corev1.Secret{}
+		if err := r.Client.Get(ctx, client.ObjectKey{
+			Namespace: o.Namespace,
+			Name:      o.Spec.ClientCertSecretRef,
+		}, s); err != nil {
+			return err
+		}
+		certDigest = tlsDigest(s.Data[corev1.TLSCertKey])
+	}
+
+	if cfg.CABundleRef != nil {
+		b, err := vault.GetCABundleBytes(ctx, r.Client, cfg.K8sNamespace, cfg.CABundleRef, cfg.SkipTLSVerify)
+		if err != nil {
+			return err
+		}
+		caDigest = tlsDigest(b)
+	}
+
+	certRotated := digestChanged(o.Status.ClientCertificateDigest, certDigest)
+	caRotated := digestChanged(o.Status.CABundleDigest, caDigest)
+	if certRotated || caRotated {
+		count, err := r.ClientFactory.Prune(ctx, r.Client, o, vault.CachingClientFactoryPruneRequest{
+			FilterFunc:   filterAllCacheRefs,
+			PruneStorage: true,
+		})
+		if err != nil {
+			return err
+		}
+		if certRotated {
+			r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonClientCertificateRotated,
+				"Client certificate rotated, rebuilding %d cached Vault clients", count)
+		}
+		if caRotated {
+			r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonCABundleRotated,
+				"CA bundle %s %q changed, rebuilding %d cached Vault clients",
+				cfg.CABundleRef.Kind, cfg.CABundleRef.Name, count)
+		}
+	}
+	o.Status.ClientCertificateDigest = certDigest
+	o.Status.CABundleDigest = caDigest
+
+	return nil
+}
+
+// setTLSReferences records the Secret, ConfigMap, or ClusterTrustBundle that
+// the TLS configuration of o refers to, so that o is reconciled whenever they
+// change.
+func (r *VaultConnectionReconciler) setTLSReferences(o *secretsv1beta1.VaultConnection) {
+	refs := map[ResourceKind][]client.ObjectKey{
+		Secret:             nil,
+		ConfigMap:          nil,
+		ClusterTrustBundle: nil,
+	}
+	if o.Spec.ClientCertSecretRef != "" {
+		refs[Secret] = append(refs[Secret], client.ObjectKey{
+			Namespace: o.Namespace,
+			Name:      o.Spec.ClientCertSecretRef,
+		})
+	}
+	if ref := o.Spec.CABundleRef; ref != nil {
+		switch ref.Kind {
+		case vault.CABundleRefKindConfigMap:
+			refs[ConfigMap] = append(refs[ConfigMap], client.ObjectKey{
+				Namespace: o.Namespace,
+				Name:      ref.Name,
+			})
+		case vault.CABundleRefKindClusterTrustBundle:
+			refs[ClusterTrustBundle] = append(refs[ClusterTrustBundle], client.ObjectKey{
+				Name: ref.Name,
+			})
+		}
+	}
+
+	objKey := client.ObjectKeyFromObject(o)
+	for kind, references := range refs {
+		r.referenceCache.Set(kind, objKey, references...)
+	}
+}
+
+// digestChanged returns true if both the recorded and the current digest are
+// set, and differ. Adding or removing a TLS reference changes the
+// VaultConnection's generation, which already causes its Clients to be pruned.
+func digestChanged(recorded, current string) bool {
+	return recorded != "" && current != "" && recorded != current
+}
+
+// tlsDigest returns the hex encoded SHA-256 digest of b.
+func tlsDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
 func (r *VaultConnectionReconciler) handleFinalizer(ctx context.Context, o *secretsv1beta1.VaultConnection) (ctrl.Result, error) {
 	if controllerutil.ContainsFinalizer(o, vaultConnectionFinalizer) {
 		if _, err := r.ClientFactory.Prune(ctx, r.Client, o, vault.CachingClientFactoryPruneRequest{
@@ -153,8 +289,81 @@ func (r *VaultConnectionReconciler) handleFinalizer(ctx context.Context, o *secr
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *VaultConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&secretsv1beta1.VaultConnection{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		Complete(r)
+	if r.SourceCh == nil {
+		r.SourceCh = newSourceChannel()
+	}
+	r.referenceCache = newResourceReferenceCache()
+
+	ctx := log.IntoContext(context.Background(), mgr.GetLogger())
+	vault.OnCircuitBreakerStateChange(func(objKey client.ObjectKey, _ vault.CircuitBreakerState) {
+		// the state change is recorded in the VaultConnection's status.
+		trySendSourceEvent(ctx, VaultConnection, r.SourceCh, event.GenericEvent{
+			Object: &secretsv1beta1.VaultConnection{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: objKey.Namespace,
+					Name:      objKey.Name,
+				},
+			},
+		})
+	})
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		// the predicates must not filter the events of the referenced TLS
+		// objects, since their generation never changes.
+		For(&secretsv1beta1.VaultConnection{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WatchesRawSource(
+			source.Channel(r.SourceCh, &handler.EnqueueRequestForObject{}),
+		).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueRefRequestsHandler{
+				kind:     Secret,
+				refCache: r.referenceCache,
+				// the connection becomes invalid once its client certificate
+				// Secret is deleted.
+				enqueueOnDelete: true,
+			},
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			&enqueueRefRequestsHandler{
+				kind:            ConfigMap,
+				refCache:        r.referenceCache,
+				enqueueOnDelete: true,
+			},
+		)
+
+	// ClusterTrustBundles are only served by clusters that have the API enabled.
+	installed, err := clusterTrustBundleAPIInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		b = b.Watches(
+			&certificatesv1alpha1.ClusterTrustBundle{},
+			&enqueueRefRequestsHandler{
+				kind:            ClusterTrustBundle,
+				refCache:        r.referenceCache,
+				enqueueOnDelete: true,
+			},
+		)
+	} else {
+		mgr.GetLogger().Info("ClusterTrustBundle API not installed, CA bundle changes will not be watched")
+	}
+
+	return b.Complete(r)
+}
+
+// clusterTrustBundleAPIInstalled returns true if the certificates.k8s.io
+// ClusterTrustBundle API is served by the cluster.
+func clusterTrustBundleAPIInstalled(mapper meta.RESTMapper) (bool, error) {
+	gvk := certificatesv1alpha1.SchemeGroupVersion.WithKind("ClusterTrustBundle")
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+type pruneRecordingClientFactory struct {
+	vault.CachingClientFactory
+	requests []vault.CachingClientFactoryPruneRequest
+}
+
+func (f *pruneRecordingClientFactory) Prune(_ context.Context, _ client.Client, _ client.Object, req vault.CachingClientFactoryPruneRequest) (int, error) {
+	f.requests = append(f.requests, req)
+	return 2, nil
+}
+
+func TestVaultConnectionReconciler_handleTLSRotation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-cert", Namespace: "vso"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert-1"),
+			corev1.TLSPrivateKeyKey: []byte("key-1"),
+		},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "vso"},
+		Data: map[string]string{
+			"bundle.pem": "ca-1",
+		},
+	}
+	o := &secretsv1beta1.VaultConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "vso"},
+		Spec: secretsv1beta1.VaultConnectionSpec{
+			ClientCertSecretRef: s.Name,
+			CABundleRef: &secretsv1beta1.VaultConnectionCABundleRef{
+				Kind: vault.CABundleRefKindConfigMap,
+				Name: cm.Name,
+				Key:  "bundle.pem",
+			},
+			SkipTLSVerify: true,
+		},
+	}
+	c := testutils.NewFakeClientBuilder().WithObjects(s, cm).Build()
+	factory := &pruneRecordingClientFactory{}
+	recorder := record.NewFakeRecorder(10)
+	r := &VaultConnectionReconciler{
+		Client:        c,
+		Recorder:      recorder,
+		ClientFactory: factory,
+	}
+	handle := func() error {
+		cfg, err := vault.NewClientConfigFromConnObj(o, "")
+		require.NoError(t, err)
+		return r.handleTLSRotation(ctx, o, cfg)
+	}
+
+	// the Clients are not pruned until the digests have been recorded.
+	require.NoError(t, handle())
+	certDigest := o.Status.ClientCertificateDigest
+	caDigest := o.Status.CABundleDigest
+	assert.Equal(t, tlsDigest([]byte("cert-1")), certDigest)
+	assert.Equal(t, tlsDigest([]byte("ca-1")), caDigest)
+	require.NoError(t, handle())
+	assert.Equal(t, certDigest, o.Status.ClientCertificateDigest)
+	assert.Equal(t, caDigest, o.Status.CABundleDigest)
+	assert.Empty(t, factory.requests)
+
+	// the Clients are pruned once the certificate is rotated.
+	s.Data[corev1.TLSCertKey] = []byte("cert-2")
+	require.NoError(t, c.Update(ctx, s))
+	require.NoError(t, handle())
+	assert.NotEqual(t, certDigest, o.Status.ClientCertificateDigest)
+	require.Len(t, factory.requests, 1)
+	assert.True(t, factory.requests[0].PruneStorage)
+	assert.False(t, factory.requests[0].SkipClientCallbacks)
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t,
+		"Normal ClientCertificateRotated Client certificate rotated, rebuilding 2 cached Vault clients",
+		<-recorder.Events)
+
+	// the Clients are pruned once the CA bundle changes.
+	cm.Data["bundle.pem"] = "ca-2"
+	require.NoError(t, c.Update(ctx, cm))
+	require.NoError(t, handle())
+	assert.NotEqual(t, caDigest, o.Status.CABundleDigest)
+	require.Len(t, factory.requests, 2)
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t,
+		`Normal CABundleRotated CA bundle ConfigMap "ca-bundle" changed, rebuilding 2 cached Vault clients`,
+		<-recorder.Events)
+
+	// the digests are cleared once the references are removed.
+	o.Spec.ClientCertSecretRef = ""
+	o.Spec.CABundleRef = nil
+	require.NoError(t, handle())
+	assert.Empty(t, o.Status.ClientCertificateDigest)
+	assert.Empty(t, o.Status.CABundleDigest)
+	assert.Len(t, factory.requests, 2)
+
+	// the client certificate Secret must exist.
+	o.Spec.ClientCertSecretRef = "other"
+	assert.Error(t, handle())
+}
+
+func TestVaultConnectionReconciler_setTLSReferences(t *testing.T) {
+	t.Parallel()
+
+	r := &VaultConnectionReconciler{
+		referenceCache: newResourceReferenceCache(),
+	}
+	o := &secretsv1beta1.VaultConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "vso"},
+		Spec: secretsv1beta1.VaultConnectionSpec{
+			ClientCertSecretRef: "client-cert",
+			CABundleRef: &secretsv1beta1.VaultConnectionCABundleRef{
+				Kind: vault.CABundleRefKindClusterTrustBundle,
+				Name: "vault-ca",
+			},
+		},
+	}
+	objKey := client.ObjectKeyFromObject(o)
+
+	r.setTLSReferences(o)
+	assert.Equal(t, []client.ObjectKey{objKey},
+		r.referenceCache.Get(Secret, client.ObjectKey{Namespace: "vso", Name: "client-cert"}))
+	assert.Equal(t, []client.ObjectKey{objKey},
+		r.referenceCache.Get(ClusterTrustBundle, client.ObjectKey{Name: "vault-ca"}))
+
+	o.Spec.ClientCertSecretRef = ""
+	o.Spec.CABundleRef = &secretsv1beta1.VaultConnectionCABundleRef{
+		Kind: vault.CABundleRefKindConfigMap,
+		Name: "vault-ca",
+	}
+	r.setTLSReferences(o)
+	assert.Empty(t,
+		r.referenceCache.Get(Secret, client.ObjectKey{Namespace: "vso", Name: "client-cert"}))
+	assert.Empty(t,
+		r.referenceCache.Get(ClusterTrustBundle, client.ObjectKey{Name: "vault-ca"}))
+	assert.Equal(t, []client.ObjectKey{objKey},
+		r.referenceCache.Get(ConfigMap, client.ObjectKey{Namespace: "vso", Name: "vault-ca"}))
+}
@@ -12,6 +12,9 @@ import (
 	"maps"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -22,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/jsonpath"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -34,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
 
@@ -42,6 +47,17 @@ import (
 
 const (
 	vaultDynamicSecretFinalizer = "vaultdynamicsecret.secrets.hashicorp.com/finalizer"
+
+	// VaultDynamicSecretSpec.RefreshMode choices, lease is the default.
+	refreshModePoll        = "poll"
+	refreshModeStaticCreds = "static-creds"
+
+	// VaultDynamicSecretSpec.Engine choices.
+	engineAWS         = "aws"
+	engineConsul      = "consul"
+	engineKubernetes  = "kubernetes"
+	engineLDAPLibrary = "ldap-library"
+	engineNomad       = "nomad"
 )
 
 // staticCredsJitterHorizon should be used when computing the jitter
@@ -49,6 +65,10 @@ const (
 var (
 	staticCredsJitterHorizon = time.Second * 3
 	vdsJitterFactor          = 0.05
+	// expiryClockSkewTolerance is the maximum duration subtracted from the
+	// expiry time extracted with VaultDynamicSecretSpec.ExpiryFieldPath, to
+	// account for the clock skew between VSO and the credential's issuer.
+	expiryClockSkewTolerance = time.Second * 30
 )
 
 var _ reconcile.Reconciler = &VaultDynamicSecretReconciler{}
@@ -62,8 +82,33 @@ type VaultDynamicSecretReconciler struct {
 	HMACValidator               helpers.HMACValidator
 	SyncRegistry                *SyncRegistry
 	BackOffRegistry             *BackOffRegistry
+	SyncStatusRegistry          *SyncStatusRegistry
 	referenceCache              ResourceReferenceCache
 	GlobalTransformationOptions *helpers.GlobalTransformationOptions
+	// Shedder defers the reconciliation of low priority resources under a large
+	// backlog, it is nil if load shedding is not enabled.
+	Shedder *ReconcileShedder
+	// FreezeWindow defers non-critical secret rotations and rollout-restarts
+	// during the freeze window, it is nil if no freeze window is configured.
+	FreezeWindow *FreezeWindow
+	// VaultPathPolicy restricts the Vault paths that the resources may
+	// reference, it is nil if no paths are restricted.
+	VaultPathPolicy *common.VaultPathPolicy
+	// sharedLeases holds the leases that are shared by the resources with
+	// spec.shareLease set.
+	sharedLeases *sharedLeaseRegistry
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// StartupSyncSmear spreads the initial reconciliation of the resources over
+	// a window after the operator starts, it is nil if smearing is not enabled.
+	StartupSyncSmear *StartupSyncSmear
+	// LeaseManager renews the resources' leases out of the reconcile loop, it is
+	// nil if the leases are renewed by the reconciliation.
+	LeaseManager *LeaseManager
+	// NamespaceRemap maps renamed Vault namespaces to their new name, it is used
+	// to remap the cache key found in the instance's VaultClientMeta.
+	NamespaceRemap common.NamespaceRemap
 	// sourceCh is used to trigger a requeue of resource instances from an
 	// external source. Should be set on a source.Channel in SetupWithManager.
 	// This channel should be closed when the controller is stopped.
@@ -85,6 +130,7 @@ type VaultDynamicSecretReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;patch
 //
 // needed for managing cached Clients, duplicated in vaultconnection_controller.go
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;delete;update;patch
@@ -107,10 +153,19 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(VaultDynamicSecret, req.NamespacedName)
+		r.LeaseManager.Untrack(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
 	logger := log.FromContext(ctx).WithValues("podUID", r.runtimePodUID)
 	o := &secretsv1beta1.VaultDynamicSecret{}
 	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(VaultDynamicSecret, req.NamespacedName)
+			r.LeaseManager.Untrack(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "error getting resource from k8s", "obj", o)
@@ -122,9 +177,35 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, r.handleDeletion(ctx, o)
 	}
 
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil || suspended {
+		r.LeaseManager.Untrack(req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if denied, err := handleVaultPathPolicy(ctx, r.Client, o, r.VaultPathPolicy, r.Recorder); err != nil || denied {
+		r.LeaseManager.Untrack(req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if deferAfter, ok := r.Shedder.Shed(ctx, VaultDynamicSecret, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	// secrets that are past their renewal or rotation time are never deferred.
+	if _, expedite := computeExpiryDeadline(o, nowFunc()); !expedite {
+		if deferAfter, ok := r.StartupSyncSmear.Defer(ctx, VaultDynamicSecret, o); ok {
+			return ctrl.Result{RequeueAfter: deferAfter}, nil
+		}
+	}
+
+	pendingAfter, err := r.FreezeWindow.HandlePending(ctx, r.Client, r.HMACValidator, o, r.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	r.referenceCache.Set(SecretTransformation, req.NamespacedName,
 		helpers.GetTransformationRefObjKeys(
-			o.Spec.Destination.Transformation, o.Namespace)...)
+			o.Spec.Destination.Transformation, o.Namespace, r.GlobalTransformationOptions)...)
 
 	destExists, _ := helpers.CheckSecretExists(ctx, r.Client, o)
 	if !o.Spec.Destination.Create && !destExists {
@@ -142,12 +223,16 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	// we can ignore the error here, since it was handled above in the Get() call.
 	clientCacheKey, _ := vClient.GetCacheKey()
-	lastClientCacheKey := o.Status.VaultClientMeta.CacheKey
+	// remap the last cache key in the case where its Vault namespace has been
+	// renamed, this prevents a needless secret rotation.
+	lastClientCacheKey := vault.RemapClientCacheKey(
+		vault.ClientCacheKey(o.Status.VaultClientMeta.CacheKey), r.NamespaceRemap).String()
 	lastClientID := o.Status.VaultClientMeta.ID
 
 	// update the VaultClientMeta in the resource's status.
 	o.Status.VaultClientMeta.CacheKey = clientCacheKey.String()
 	o.Status.VaultClientMeta.ID = vClient.ID()
+	vault.SetVaultClientMetaToken(&o.Status.VaultClientMeta, vClient)
 
 	var syncReason string
 	// doSync indicates that the controller should perform the secret sync,
@@ -186,8 +271,8 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 		logger.Info("Restart check",
 			"inWindow", inWindow,
 			"horizon", horizon,
-			"allowStaticCreds", o.Spec.AllowStaticCreds)
-		if !o.Spec.AllowStaticCreds {
+			"staticCreds", useStaticCreds(o))
+		if !useStaticCreds(o) {
 			if !inWindow {
 				// means that we are not in the lease renewal window.
 				r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonSecretLeaseRenewal,
@@ -196,6 +281,9 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 				if err := r.updateStatus(ctx, o); err != nil {
 					return ctrl.Result{}, err
 				}
+				if r.LeaseManager.Track(o, vClient) {
+					return ctrl.Result{}, nil
+				}
 				return ctrl.Result{RequeueAfter: horizon}, nil
 			}
 		} else if inWindow {
@@ -211,9 +299,20 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
-	if !doSync && r.isRenewableLease(&o.Status.SecretLease, o, true) && !o.Spec.AllowStaticCreds && leaseID != "" {
+	if !doSync && ((useDataExpiry(o) && o.Status.ExpiryTime > 0) || (usePolling(o) && o.Status.LastRenewalTime > 0)) {
+		// the credentials are only ever refreshed, never renewed, since renewing the
+		// lease does not extend the expiry in the secret data, or the secret is
+		// polled.
+		if horizon, inWindow := computeRelativeHorizonWithJitter(o, time.Second*1); !inWindow {
+			logger.V(consts.LogLevelDebug).Info("Not in refresh window",
+				"horizon", horizon, "expiryTime", o.Status.ExpiryTime)
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
+	}
+
+	if !doSync && r.isRenewableLease(&o.Status.SecretLease, o, true) && !useStaticCreds(o) && !useDataExpiry(o) && !usePolling(o) && leaseID != "" {
 		// Renew the lease and return from Reconcile if the lease is successfully renewed.
-		if secretLease, err := r.renewLease(ctx, vClient, o); err == nil {
+		if secretLease, renewedAt, err := r.renewSharedLease(ctx, vClient, o); err == nil {
 			if !r.isRenewableLease(secretLease, o, false) {
 				return ctrl.Result{}, nil
 			}
@@ -227,7 +326,10 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 			o.Status.StaticCredsMetaData = secretsv1beta1.VaultStaticCredsMetaData{}
 			o.Status.SecretLease = *secretLease
-			o.Status.LastRenewalTime = nowFunc().Unix()
+			o.Status.LastRenewalTime = renewedAt.Unix()
+			if o.Status.Credentials.Username != "" {
+				o.Status.Credentials.Expiration = dynamicSecretExpiry(o).Unix()
+			}
 			if err := r.updateStatus(ctx, o); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -238,13 +340,22 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 				// compatible with computeHorizonWithJitter()
 				leaseDuration = time.Second * 5
 			}
-			horizon := computeDynamicHorizonWithJitter(leaseDuration, o.Spec.RenewalPercent)
+			// a shared lease may have been renewed by another resource.
+			horizon := max(computeDynamicHorizonWithJitter(leaseDuration, o.Spec.RenewalPercent)-
+				nowFunc().Sub(renewedAt), time.Second)
 			r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonSecretLeaseRenewal,
 				"Renewed lease, lease_id=%s, horizon=%s", leaseID, horizon)
+			if r.LeaseManager.Track(o, vClient) {
+				// the lease manager renews the lease from now on.
+				return ctrl.Result{}, nil
+			}
 			return ctrl.Result{RequeueAfter: horizon}, nil
 		} else {
 			var e *LeaseTruncatedError
-			if errors.As(err, &e) {
+			if errors.Is(err, errSharedLeaseSuperseded) {
+				r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonSecretLeaseRenewal,
+					"Shared lease was superseded by new credentials, lease_id=%s", leaseID)
+			} else if errors.As(err, &e) {
 				r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonSecretLeaseRenewal,
 					"Lease renewal duration was truncated from %ds to %ds, "+
 						"requesting new credentials", e.Expected, e.Actual)
@@ -259,6 +370,13 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
+	if syncReason == "" && !useStaticCreds(o) {
+		if deferAfter, ok := r.FreezeWindow.DeferRotation(
+			ctx, r.Client, VaultDynamicSecret, o, dynamicSecretExpiry(o), r.Recorder); ok {
+			return ctrl.Result{RequeueAfter: minRequeueAfter(deferAfter, pendingAfter)}, nil
+		}
+	}
+
 	reason := consts.ReasonSecretSynced
 	if o.Status.LastGeneration > 0 {
 		reason = consts.ReasonSecretRotated
@@ -275,37 +393,43 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 	secretLease, staticCredsUpdated, err := r.syncSecret(ctx, vClient, o, transOption)
 	if err != nil {
 		r.SyncRegistry.Add(req.NamespacedName)
+		if horizon, ok := handleThrottled(ctx, r.Client, o, r.Recorder, err); ok {
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
 		if vault.IsForbiddenError(err) {
 			logger.V(consts.LogLevelWarning).Info("Tainting client", "err", err)
 			vClient.Taint()
 		}
 		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
-		horizon := entry.NextBackOff()
+		horizon := nextBackOff(ctx, r.Client, o, entry)
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
 			"Failed to sync the secret, horizon=%s, err=%s", horizon, err)
 		return ctrl.Result{
 			RequeueAfter: horizon,
 		}, nil
 	} else {
-		r.BackOffRegistry.Delete(req.NamespacedName)
+		resetBackOff(r.BackOffRegistry, o)
 	}
 
 	doRolloutRestart := (doSync && o.Status.LastGeneration > 1) || staticCredsUpdated
 	o.Status.SecretLease = *secretLease
-	o.Status.LastRenewalTime = nowFunc().Unix()
+	o.Status.LastRenewalTime = r.sharedLeaseRenewedAt(o, secretLease.ID).Unix()
 	if err := r.updateStatus(ctx, o); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	horizon := r.computePostSyncHorizon(ctx, o)
+	if horizon > 0 && shareLease(o) {
+		// an adopted shared lease may have been renewed by another resource.
+		horizon = max(horizon-nowFunc().Sub(time.Unix(o.Status.LastRenewalTime, 0)), time.Second)
+	}
 	r.Recorder.Eventf(o, corev1.EventTypeNormal, reason,
 		"Secret synced, lease_id=%q, horizon=%s, sync_reason=%q",
 		secretLease.ID, horizon, syncReason)
 
 	if doRolloutRestart {
-		// rollout-restart errors are not retryable
-		// all error reporting is handled by helpers.HandleRolloutRestarts
-		_ = helpers.HandleRolloutRestarts(ctx, r.Client, o, r.Recorder)
+		pendingAfter = minRequeueAfter(pendingAfter,
+			r.FreezeWindow.HandleRolloutRestarts(ctx, r.Client, r.HMACValidator, VaultDynamicSecret, o, r.Recorder))
 	}
 
 	if ok := r.SyncRegistry.Delete(req.NamespacedName); ok {
@@ -317,18 +441,42 @@ func (r *VaultDynamicSecretReconciler) Reconcile(ctx context.Context, req ctrl.R
 		// no need to requeue
 		logger.Info("Vault secret does not support periodic renewal/refresh via reconciliation",
 			"requeue", false, "horizon", horizon)
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: pendingAfter}, nil
+	}
+
+	if r.LeaseManager.Track(o, vClient) {
+		// the lease manager renews the lease, the resource is only reconciled
+		// again once the renewal fails.
+		return ctrl.Result{RequeueAfter: pendingAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: minRequeueAfter(horizon, pendingAfter)}, nil
+}
+
+// dynamicSecretExpiry returns the expiry of o's current secret, it is zero if
+// the expiry is unknown.
+func dynamicSecretExpiry(o *secretsv1beta1.VaultDynamicSecret) time.Time {
+	if useDataExpiry(o) {
+		if o.Status.ExpiryTime > 0 {
+			return time.Unix(o.Status.ExpiryTime, 0)
+		}
+		return time.Time{}
 	}
 
-	return ctrl.Result{RequeueAfter: horizon}, nil
+	if o.Status.LastRenewalTime > 0 && o.Status.SecretLease.LeaseDuration > 0 {
+		return time.Unix(o.Status.LastRenewalTime, 0).Add(
+			time.Duration(o.Status.SecretLease.LeaseDuration) * time.Second)
+	}
+
+	return time.Time{}
 }
 
 func (r *VaultDynamicSecretReconciler) isRenewableLease(secretLease *secretsv1beta1.VaultSecretLease, o *secretsv1beta1.VaultDynamicSecret, skipEventRecording bool) bool {
 	renewable := secretLease.Renewable
-	if !renewable && !skipEventRecording && !o.Spec.AllowStaticCreds {
+	if !renewable && !skipEventRecording && !useStaticCreds(o) && !usePolling(o) {
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretLeaseRenewal,
 			"Lease is not renewable, staticCreds=%t, info=%#v",
-			o.Spec.AllowStaticCreds, secretLease)
+			useStaticCreds(o), secretLease)
 	}
 
 	return renewable
@@ -343,19 +491,33 @@ func (r *VaultDynamicSecretReconciler) isStaticCreds(meta *secretsv1beta1.VaultS
 // doVault performs a Vault request based on the VaultDynamicSecret's spec.
 func (r *VaultDynamicSecretReconciler) doVault(ctx context.Context, c vault.ClientBase, o *secretsv1beta1.VaultDynamicSecret) (vault.Response, error) {
 	path := vault.JoinPath(o.Spec.Mount, o.Spec.Path)
-	var err error
+	switch o.Spec.Engine {
+	case engineAWS:
+		path = vault.JoinPath(o.Spec.Mount, "sts", o.Spec.Path)
+	case engineConsul, engineKubernetes, engineNomad:
+		path = vault.JoinPath(o.Spec.Mount, "creds", o.Spec.Path)
+	case engineLDAPLibrary:
+		path = vault.JoinPath(o.Spec.Mount, "library", o.Spec.Path, "check-out")
+	}
 	var resp vault.Response
-	var params map[string]any
-	paramsLen := len(o.Spec.Params)
-	if paramsLen > 0 {
-		params = make(map[string]any, paramsLen)
-		for k, v := range o.Spec.Params {
-			params[k] = v
-		}
+	params, err := r.requestParams(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil && o.Spec.Engine == engineLDAPLibrary {
+		// checking out a service account always requires a write request.
+		params = map[string]any{}
 	}
 
 	method := o.Spec.RequestHTTPMethod
 	logger := log.FromContext(ctx).WithName("doVault")
+	if rolePath, roleParams := engineRole(o); rolePath != "" {
+		logger.V(consts.LogLevelDebug).Info("Writing Vault role", "path", rolePath)
+		if _, err := c.Write(ctx, vault.NewWriteRequest(rolePath, roleParams)); err != nil {
+			logger.Error(err, "Vault role write failed", "path", rolePath)
+			return nil, fmt.Errorf("failed to write the Vault role %q: %w", rolePath, err)
+		}
+	}
 	if params != nil {
 		if !(method == http.MethodPost || method == http.MethodPut) {
 			logger.V(consts.LogLevelWarning).Info(
@@ -390,12 +552,172 @@ func (r *VaultDynamicSecretReconciler) doVault(ctx context.Context, c vault.Clie
 	return resp, nil
 }
 
+// requestParams returns the params of the Vault request, the values sourced
+// from ParamsFrom take precedence over Params, and the Engine's params take
+// precedence over both.
+func (r *VaultDynamicSecretReconciler) requestParams(ctx context.Context, o *secretsv1beta1.VaultDynamicSecret) (map[string]any, error) {
+	var engineParams map[string]any
+	switch o.Spec.Engine {
+	case engineAWS:
+		engineParams = awsSTSParams(o)
+	case engineKubernetes:
+		engineParams = kubernetesCredsParams(o)
+	case engineLDAPLibrary:
+		if o.Spec.LDAPLibrary != nil && o.Spec.LDAPLibrary.TTL != "" {
+			engineParams = map[string]any{
+				"ttl": o.Spec.LDAPLibrary.TTL,
+			}
+		}
+	}
+	paramsLen := len(o.Spec.Params) + len(o.Spec.ParamsFrom) + len(engineParams)
+	if paramsLen == 0 {
+		return nil, nil
+	}
+
+	params := make(map[string]any, paramsLen)
+	for k, v := range o.Spec.Params {
+		params[k] = v
+	}
+	for _, p := range o.Spec.ParamsFrom {
+		v, err := helpers.GetParamFromSourceValue(ctx, r.Client, o.Namespace, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get param %q from its source: %w", p.Name, err)
+		}
+		params[p.Name] = v
+	}
+	for k, v := range engineParams {
+		params[k] = v
+	}
+
+	return params, nil
+}
+
+// awsSTSParams returns the params of the AWS STS credentials request for o, it
+// is nil if the Engine is not `aws`.
+func awsSTSParams(o *secretsv1beta1.VaultDynamicSecret) map[string]any {
+	if o.Spec.Engine != engineAWS || o.Spec.AWS == nil {
+		return nil
+	}
+
+	params := make(map[string]any)
+	if o.Spec.AWS.RoleARN != "" {
+		params["role_arn"] = o.Spec.AWS.RoleARN
+	}
+	if o.Spec.AWS.TTL != "" {
+		params["ttl"] = o.Spec.AWS.TTL
+	}
+	if len(o.Spec.AWS.SessionTags) > 0 {
+		tags := make([]string, 0, len(o.Spec.AWS.SessionTags))
+		for k, v := range o.Spec.AWS.SessionTags {
+			tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(tags)
+		params["session_tags"] = tags
+	}
+
+	return params
+}
+
+// kubernetesCredsParams returns the params of the Kubernetes service account
+// token request for o, it is nil if the Engine is not `kubernetes`.
+func kubernetesCredsParams(o *secretsv1beta1.VaultDynamicSecret) map[string]any {
+	if o.Spec.Engine != engineKubernetes || o.Spec.Kubernetes == nil {
+		return nil
+	}
+
+	params := map[string]any{
+		"kubernetes_namespace": o.Spec.Kubernetes.KubernetesNamespace,
+	}
+	if o.Spec.Kubernetes.ClusterRoleBinding {
+		params["cluster_role_binding"] = true
+	}
+	if o.Spec.Kubernetes.TTL != "" {
+		params["ttl"] = o.Spec.Kubernetes.TTL
+	}
+	if len(o.Spec.Kubernetes.Audiences) > 0 {
+		params["audiences"] = strings.Join(o.Spec.Kubernetes.Audiences, ",")
+	}
+
+	return params
+}
+
+// engineRole returns the path and params of the Vault role of the Consul or
+// Nomad token request for o, the path is empty if the role should not be
+// written.
+func engineRole(o *secretsv1beta1.VaultDynamicSecret) (string, map[string]any) {
+	switch o.Spec.Engine {
+	case engineConsul:
+		if o.Spec.Consul == nil || len(o.Spec.Consul.Policies) == 0 {
+			return "", nil
+		}
+		params := map[string]any{
+			"consul_policies": strings.Join(o.Spec.Consul.Policies, ","),
+		}
+		if o.Spec.Consul.TTL != "" {
+			params["ttl"] = o.Spec.Consul.TTL
+		}
+		return vault.JoinPath(o.Spec.Mount, "roles", o.Spec.Path), params
+	case engineNomad:
+		if o.Spec.Nomad == nil {
+			return "", nil
+		}
+		tokenType := o.Spec.Nomad.Type
+		if tokenType == "" {
+			tokenType = "client"
+		}
+		if len(o.Spec.Nomad.Policies) == 0 && tokenType != "management" {
+			return "", nil
+		}
+		params := map[string]any{
+			"type":   tokenType,
+			"global": o.Spec.Nomad.Global,
+		}
+		if tokenType == "client" {
+			params["policies"] = strings.Join(o.Spec.Nomad.Policies, ",")
+		}
+		return vault.JoinPath(o.Spec.Mount, "role", o.Spec.Path), params
+	}
+
+	return "", nil
+}
+
+// kubernetesCredsResponse returns a copy of the Kubernetes service account
+// token resp, with its data augmented by kubernetesCredsData from the config
+// of the Kubernetes secrets engine mount.
+func (r *VaultDynamicSecretReconciler) kubernetesCredsResponse(ctx context.Context, c vault.ClientBase,
+	o *secretsv1beta1.VaultDynamicSecret, resp vault.Response,
+) (vault.Response, error) {
+	configResp, err := c.Read(ctx, vault.NewReadRequest(vault.JoinPath(o.Spec.Mount, "config"), nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Kubernetes secrets engine config: %w", err)
+	}
+	if configResp == nil {
+		return nil, errors.New("nil response from vault for the Kubernetes secrets engine config")
+	}
+
+	var secret api.Secret
+	if resp.Secret() != nil {
+		secret = *resp.Secret()
+	}
+	secret.Data = kubernetesCredsData(resp.Data(), configResp.Data())
+	return vault.NewDefaultResponse(&secret), nil
+}
+
 func (r *VaultDynamicSecretReconciler) syncSecret(ctx context.Context, c vault.ClientBase,
 	o *secretsv1beta1.VaultDynamicSecret, opt *helpers.SecretTransformationOption,
 ) (*secretsv1beta1.VaultSecretLease, bool, error) {
 	logger := log.FromContext(ctx).WithName("syncSecret")
 
-	resp, err := r.doVault(ctx, c, o)
+	// all requests made during the sync must be handled by a Vault node that has
+	// observed the effects of the prior requests, otherwise we may get stale
+	// reads from performance standbys/secondaries.
+	ctx = vault.WithReplicationState(ctx)
+	wrapCtx, err := withWrapTTL(ctx, o.Spec.WrapTTL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := r.doSharedVault(wrapCtx, c, o)
 	if err != nil {
 		return nil, false, err
 	}
@@ -404,16 +726,20 @@ func (r *VaultDynamicSecretReconciler) syncSecret(ctx context.Context, c vault.C
 		return nil, false, errors.New("nil response")
 	}
 
+	if o.Spec.WrapTTL != "" {
+		return r.syncWrappedSecret(ctx, o, resp)
+	}
+
 	var data map[string][]byte
 	secretLease := r.getVaultSecretLease(resp.Secret())
-	if !r.isRenewableLease(secretLease, o, true) && o.Spec.AllowStaticCreds {
+	if !r.isRenewableLease(secretLease, o, true) && useStaticCreds(o) {
 		staticCredsMeta, rotatedResponse, err := r.awaitVaultSecretRotation(ctx, o, c, resp)
 		if err != nil {
 			return nil, false, err
 		}
 
 		resp = rotatedResponse
-		data, err = resp.SecretK8sData(opt)
+		data, err = r.secretK8sData(ctx, o, resp, opt)
 		if err != nil {
 			return nil, false, err
 		}
@@ -438,20 +764,176 @@ func (r *VaultDynamicSecretReconciler) syncSecret(ctx context.Context, c vault.C
 		o.Status.StaticCredsMetaData = *staticCredsMeta
 		logger.V(consts.LogLevelDebug).Info("Static creds", "status", o.Status)
 	} else {
-		data, err = resp.SecretK8sData(opt)
+		o.Status.ExpiryTime = 0
+		if useDataExpiry(o) {
+			var expiry time.Time
+			switch {
+			case o.Spec.ExpiryFieldPath != "":
+				expiry, err = expiryTimeFromData(resp.Data(), o.Spec.ExpiryFieldPath)
+			case o.Spec.Engine == engineAWS:
+				expiry, err = awsSTSExpiry(resp)
+			default:
+				expiry, err = leaseExpiry(resp, "Kubernetes service account token")
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			if !expiry.After(nowFunc()) {
+				return nil, false, fmt.Errorf("expiry time %s is not in the future",
+					expiry.Format(time.RFC3339))
+			}
+			o.Status.ExpiryTime = expiry.Unix()
+		}
+
+		if o.Spec.Engine == engineKubernetes {
+			resp, err = r.kubernetesCredsResponse(ctx, c, o, resp)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		data, err = r.secretK8sData(ctx, o, resp, opt)
 		if err != nil {
 			return nil, false, err
 		}
 	}
 
-	if err := helpers.SyncSecret(ctx, r.Client, o, data); err != nil {
+	opts := helpers.DefaultSyncOptions()
+	opts.Metadata = dynamicSecretMetadata(secretLease)
+	opts.HMACValidator = r.HMACValidator
+	err = helpers.SyncSecret(ctx, r.Client, o, data, opts)
+	handleSecretDataError(ctx, r.Client, o, err)
+	if err != nil {
 		logger.Error(err, "Destination sync failed")
 		return nil, false, err
 	}
 
+	o.Status.Credentials = credentialsStatus(o, resp, secretLease)
+	if o.Spec.Engine == engineLDAPLibrary {
+		account, _ := resp.Data()["service_account_name"].(string)
+		if o.Status.CheckedOutAccount != "" && o.Status.CheckedOutAccount != account {
+			r.checkInAccount(ctx, c, o, o.Status.CheckedOutAccount)
+		}
+		o.Status.CheckedOutAccount = account
+	}
+
 	return secretLease, true, nil
 }
 
+// dynamicSecretMetadata returns the metadata of the secret's lease, that the
+// destination's label and annotation templates are rendered with.
+func dynamicSecretMetadata(lease *secretsv1beta1.VaultSecretLease) map[string]any {
+	return map[string]any{
+		"lease_id":       lease.ID,
+		"lease_duration": lease.LeaseDuration,
+		"renewable":      lease.Renewable,
+		"request_id":     lease.RequestID,
+	}
+}
+
+// syncWrappedSecret syncs the response wrapping token of the wrapped resp to
+// the destination Secret. The wrapped secret's lease is never renewed, so the
+// returned lease has the duration of the wrapping token, ensuring that new
+// credentials are requested before the token expires.
+func (r *VaultDynamicSecretReconciler) syncWrappedSecret(ctx context.Context,
+	o *secretsv1beta1.VaultDynamicSecret, resp vault.Response,
+) (*secretsv1beta1.VaultSecretLease, bool, error) {
+	data, annotations, err := wrappedSecretData(resp)
+	if err != nil {
+		return nil, false, err
+	}
+
+	o.Status.ExpiryTime = 0
+	o.Status.StaticCredsMetaData = secretsv1beta1.VaultStaticCredsMetaData{}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), nil, nil)
+
+	opts := helpers.DefaultSyncOptions()
+	opts.Annotations = annotations
+	opts.HMACValidator = r.HMACValidator
+	err = helpers.SyncSecret(ctx, r.Client, o, data, opts)
+	handleSecretDataError(ctx, r.Client, o, err)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Destination sync failed")
+		return nil, false, err
+	}
+
+	return &secretsv1beta1.VaultSecretLease{
+		LeaseDuration: resp.Secret().WrapInfo.TTL,
+		RequestID:     resp.Secret().RequestID,
+	}, true, nil
+}
+
+// secretK8sData returns the K8s Secret data for resp. Keys whose templates
+// failed to render retain their previous values, see handleTemplateRenderError.
+func (r *VaultDynamicSecretReconciler) secretK8sData(ctx context.Context, o *secretsv1beta1.VaultDynamicSecret,
+	resp vault.Response, opt *helpers.SecretTransformationOption,
+) (map[string][]byte, error) {
+	data, err := resp.SecretK8sData(opt)
+	renderErr, err := handleTemplateRenderError(ctx, r.Client, o, data, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if renderErr != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values for keys that failed to render: %s", renderErr)
+	}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), opt, renderErr)
+
+	return remapCredentialKeys(o, data), nil
+}
+
+// remapCredentialKeys moves the `username` and `password` keys of data to the
+// VaultDynamicSecretSpec.UsernameKey and VaultDynamicSecretSpec.PasswordKey.
+func remapCredentialKeys(o *secretsv1beta1.VaultDynamicSecret, data map[string][]byte) map[string][]byte {
+	moved := make(map[string][]byte)
+	for from, to := range map[string]string{
+		"username": o.Spec.UsernameKey,
+		"password": o.Spec.PasswordKey,
+	} {
+		if to == "" || to == from {
+			continue
+		}
+		if v, ok := data[from]; ok {
+			moved[to] = v
+			delete(data, from)
+		}
+	}
+	for k, v := range moved {
+		data[k] = v
+	}
+
+	return data
+}
+
+// credentialsStatus returns the meta-data of the database-style credentials in
+// resp, it is empty if resp does not include a `username`.
+func credentialsStatus(o *secretsv1beta1.VaultDynamicSecret, resp vault.Response,
+	secretLease *secretsv1beta1.VaultSecretLease,
+) secretsv1beta1.VaultDynamicSecretCredentials {
+	username, _ := resp.Data()["username"].(string)
+	if username == "" {
+		return secretsv1beta1.VaultDynamicSecretCredentials{}
+	}
+
+	creds := secretsv1beta1.VaultDynamicSecretCredentials{
+		Username: username,
+	}
+	switch {
+	case useStaticCreds(o):
+		creds.LastVaultRotation = o.Status.StaticCredsMetaData.LastVaultRotation
+		if ttl := o.Status.StaticCredsMetaData.TTL; ttl > 0 {
+			creds.Expiration = nowFunc().Unix() + ttl
+		}
+	case o.Status.ExpiryTime > 0:
+		creds.Expiration = o.Status.ExpiryTime
+	case secretLease.LeaseDuration > 0:
+		creds.Expiration = nowFunc().Unix() + int64(secretLease.LeaseDuration)
+	}
+
+	return creds
+}
+
 // awaitVaultSecretRotation waits for the Vault secret to be rotated. This is
 // necessary for the case where the Vault secret is a static-creds secret and includes
 // a rotation schedule.
@@ -595,7 +1077,9 @@ func (r *VaultDynamicSecretReconciler) renewLease(
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *VaultDynamicSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(VaultDynamicSecret, r.Recorder)
 	r.referenceCache = newResourceReferenceCache()
+	r.sharedLeases = newSharedLeaseRegistry()
 	if r.BackOffRegistry == nil {
 		r.BackOffRegistry = NewBackOffRegistry()
 	}
@@ -607,8 +1091,18 @@ func (r *VaultDynamicSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts c
 		},
 	)
 
+	// expedite the syncs of the secrets that are closest to their expiry, e.g.
+	// while working through a backlog after an operator restart.
+	opts.NewQueue = NewPriorityQueueFunc(VaultDynamicSecret, r.expedite, 0)
+
 	// TODO: close this channel when the controller is stopped.
-	r.SourceCh = make(chan event.GenericEvent)
+	r.SourceCh = newSourceChannel()
+	if r.LeaseManager != nil {
+		r.LeaseManager.setup(r.Client, r.Recorder, r.renewLease, r.enqueueLeaseRenewal)
+		if err := mgr.Add(r.LeaseManager); err != nil {
+			return err
+		}
+	}
 	m := ctrl.NewControllerManagedBy(mgr).
 		For(&secretsv1beta1.VaultDynamicSecret{}).
 		WithOptions(opts).
@@ -633,10 +1127,11 @@ func (r *VaultDynamicSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts c
 			source.Channel(r.SourceCh,
 				&enqueueDelayingSyncEventHandler{
 					enqueueDurationForJitter: time.Second * 2,
+					kind:                     VaultDynamicSecret,
 				}),
 		)
 
-	if err := m.Complete(r); err != nil {
+	if err := m.Complete(r.SyncStatusRegistry.Reconciler(VaultDynamicSecret, r)); err != nil {
 		return err
 	}
 
@@ -644,16 +1139,37 @@ func (r *VaultDynamicSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts c
 }
 
 // handleDeletion will handle the deletion path of the VDS secret:
-// * revoking any associated outstanding leases
+// * applying the destination secret's deletion policy
+// * revoking any associated outstanding leases, unless they are shared
 // * removing our finalizer
 func (r *VaultDynamicSecretReconciler) handleDeletion(ctx context.Context, o *secretsv1beta1.VaultDynamicSecret) error {
 	logger := log.FromContext(ctx)
+	if err := finalizeDestinationSecrets(ctx, r.Client, r.Recorder, o, vaultDynamicSecretFinalizer); err != nil {
+		return err
+	}
+
 	// We are ignoring errors inside `revokeLease`, otherwise we may fail to remove the finalizer.
 	// Worst case at this point we will leave a dangling lease instead of a secret which
 	// cannot be deleted. Events are emitted in these cases.
-	r.revokeLease(ctx, o, "")
+	if o.Spec.Engine == engineLDAPLibrary && o.Status.CheckedOutAccount != "" {
+		if c, err := r.ClientFactory.Get(ctx, r.Client, o); err != nil {
+			logger.Error(err, "Failed to get client when checking in account",
+				"account", o.Status.CheckedOutAccount)
+		} else {
+			r.checkInAccount(ctx, c, o, o.Status.CheckedOutAccount)
+		}
+	}
+	r.LeaseManager.Untrack(client.ObjectKeyFromObject(o))
+	if r.isLeaseShared(ctx, o) {
+		logger.Info("Not revoking the lease, it is shared with other resources",
+			"id", o.Status.SecretLease.ID)
+	} else {
+		r.revokeLease(ctx, o, "")
+		r.sharedLeases.DeleteLease(o.Status.SecretLease.ID)
+	}
 
 	objKey := client.ObjectKeyFromObject(o)
+	r.sharedLeases.Release(objKey, nowFunc())
 	r.SyncRegistry.Delete(objKey)
 	r.BackOffRegistry.Delete(objKey)
 	r.referenceCache.Remove(SecretTransformation, objKey)
@@ -700,20 +1216,48 @@ func (r *VaultDynamicSecretReconciler) revokeLease(ctx context.Context, o *secre
 	}
 }
 
+// checkInAccount checks the service account back in to the VDS secret's LDAP
+// library set. Errors are only logged and emitted as events, since the
+// account is checked in by Vault once its check-out lease expires.
+func (r *VaultDynamicSecretReconciler) checkInAccount(ctx context.Context, c vault.ClientBase, o *secretsv1beta1.VaultDynamicSecret, account string) {
+	logger := log.FromContext(ctx).WithValues("account", account)
+	path := vault.JoinPath(o.Spec.Mount, "library", o.Spec.Path, "check-in")
+	if _, err := c.Write(ctx, vault.NewWriteRequest(path, map[string]any{
+		"service_account_names": []string{account},
+	})); err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonAccountCheckIn,
+			"Failed to check in account %s: %s", account, err)
+		logger.Error(err, "Failed to check in account")
+	} else {
+		r.Recorder.Eventf(o, corev1.EventTypeNormal, consts.ReasonAccountCheckIn,
+			"Account checked in: %s", account)
+		logger.Info("Account checked in")
+	}
+}
+
 // computePostSyncHorizon for a secretsv1beta1.VaultDynamicSecret. The duration
 // computed varies depending on the "type" of Vault secret being synced. In the
 // case the secret is from a "static-creds" role, the computed horizon will be
 // greater than the secret rotation period/TTL. For all other types, the horizon
 // is computed from the secret's lease duration, the o.Spec.RenewalPercent, minus
 // some jitter offset. In the case where the secret has no lease duration, the
-// horizon will be computed from o.Spec.RefreshAfter.
+// horizon will be computed from o.Spec.RefreshAfter. When o.Spec.ExpiryFieldPath
+// is set, the horizon is computed from the expiry time in the secret data
+// instead.
 func (r *VaultDynamicSecretReconciler) computePostSyncHorizon(ctx context.Context, o *secretsv1beta1.VaultDynamicSecret) time.Duration {
 	logger := log.FromContext(ctx).WithName("computePostSyncHorizon")
 	var horizon time.Duration
 
 	secretLease := o.Status.SecretLease
 	d := getRotationDuration(o)
-	if !o.Spec.AllowStaticCreds {
+	if usePolling(o) {
+		// the secret is refreshed at the end of the period, since its lease is never
+		// renewed.
+		horizon = computeHorizonWithJitter(d)
+		logger.V(consts.LogLevelDebug).Info("Polled",
+			"secretLease", secretLease, "horizon", horizon,
+			"refreshAfter", o.Spec.RefreshAfter)
+	} else if !useStaticCreds(o) {
 		horizon = computeDynamicHorizonWithJitter(d, o.Spec.RenewalPercent)
 		logger.V(consts.LogLevelDebug).Info("Leased",
 			"secretLease", secretLease, "horizon", horizon,
@@ -727,7 +1271,7 @@ func (r *VaultDynamicSecretReconciler) computePostSyncHorizon(ctx context.Contex
 		if !r.isStaticCreds(&staticCredsMeta) {
 			horizon = 0
 			logger.Info("Vault response data does not support static-creds semantics",
-				"allowStaticCreds", o.Spec.AllowStaticCreds,
+				"staticCreds", useStaticCreds(o),
 				"horizon", horizon,
 				"status", o.Status,
 			)
@@ -754,8 +1298,17 @@ func (r *VaultDynamicSecretReconciler) computePostSyncHorizon(ctx context.Contex
 
 func getRotationDuration(o *secretsv1beta1.VaultDynamicSecret) time.Duration {
 	var d time.Duration
-	if o.Spec.AllowStaticCreds {
+	if useStaticCreds(o) {
 		d = time.Duration(o.Status.StaticCredsMetaData.TTL) * time.Second
+	} else if useDataExpiry(o) && o.Status.ExpiryTime > 0 {
+		d = time.Unix(o.Status.ExpiryTime, 0).Sub(time.Unix(o.Status.LastRenewalTime, 0))
+		d -= min(expiryClockSkewTolerance, d/10)
+	} else if usePolling(o) && o.Spec.RefreshAfter != "" {
+		d, _ = parseDurationString(o.Spec.RefreshAfter, ".spec.refreshAfter", 0)
+		if wrapDuration := time.Duration(o.Status.SecretLease.LeaseDuration) * time.Second; o.Spec.WrapTTL != "" && wrapDuration > 0 {
+			// new credentials must be requested before the wrapping token expires.
+			d = min(d, wrapDuration)
+		}
 	} else {
 		d = time.Duration(o.Status.SecretLease.LeaseDuration) * time.Second
 		if d <= 0 && o.Spec.RefreshAfter != "" {
@@ -769,6 +1322,41 @@ func getRotationDuration(o *secretsv1beta1.VaultDynamicSecret) time.Duration {
 	return d
 }
 
+// expedite implements PriorityFunc. A request is expedited if its secret is
+// past its renewal or rotation time, the secret's expiry is the deadline.
+func (r *VaultDynamicSecretReconciler) expedite(req reconcile.Request) (time.Time, bool) {
+	o := &secretsv1beta1.VaultDynamicSecret{}
+	if err := r.Client.Get(context.Background(), req.NamespacedName, o); err != nil {
+		return time.Time{}, false
+	}
+
+	return computeExpiryDeadline(o, nowFunc())
+}
+
+// computeExpiryDeadline returns the expiry time of o's secret, and true if the
+// secret is past its renewal or rotation time at now. Secrets that are only
+// refreshed periodically, i.e. secrets without a lease or expiry, are never
+// past their renewal time.
+func computeExpiryDeadline(o *secretsv1beta1.VaultDynamicSecret, now time.Time) (time.Time, bool) {
+	if o.GetDeletionTimestamp() != nil {
+		return time.Time{}, false
+	}
+
+	var ts int64
+	if useStaticCreds(o) {
+		ts = o.Status.StaticCredsMetaData.LastVaultRotation
+	} else if o.Status.SecretLease.LeaseDuration > 0 || (useDataExpiry(o) && o.Status.ExpiryTime > 0) {
+		ts = o.Status.LastRenewalTime
+	}
+
+	d := getRotationDuration(o)
+	if ts <= 0 || d <= 0 || now.Before(computeRotationTime(o)) {
+		return time.Time{}, false
+	}
+
+	return time.Unix(ts, 0).Add(d), true
+}
+
 // vaultClientCallback requests reconciliation of all VaultDynamicSecret
 // instances that were synced with Client
 func (r *VaultDynamicSecretReconciler) vaultClientCallback(ctx context.Context, c vault.Client) {
@@ -782,6 +1370,7 @@ func (r *VaultDynamicSecretReconciler) vaultClientCallback(ctx context.Context,
 	}
 
 	logger = logger.WithValues("cacheKey", cacheKey, "controller", "vds")
+	r.LeaseManager.RemoveClient(cacheKey)
 	var l secretsv1beta1.VaultDynamicSecretList
 	if err := r.Client.List(ctx, &l, client.InNamespace(
 		c.GetCredentialProvider().GetNamespace()),
@@ -822,7 +1411,7 @@ func (r *VaultDynamicSecretReconciler) vaultClientCallback(ctx context.Context,
 				r.SyncRegistry.Add(objKey)
 				logger.V(consts.LogLevelDebug).Info(
 					"Sending GenericEvent to the SourceCh", "evt", evt)
-				r.SourceCh <- evt
+				sendSourceEvent(ctx, VaultDynamicSecret, r.SourceCh, evt)
 			}
 		} else if err != nil {
 			logger.V(consts.LogLevelWarning).Info(
@@ -831,13 +1420,30 @@ func (r *VaultDynamicSecretReconciler) vaultClientCallback(ctx context.Context,
 	}
 }
 
+// enqueueLeaseRenewal requests the sync of the VaultDynamicSecret objKey, once
+// the LeaseManager can no longer renew its lease.
+func (r *VaultDynamicSecretReconciler) enqueueLeaseRenewal(ctx context.Context, objKey client.ObjectKey) {
+	r.SyncRegistry.Add(objKey)
+	sendSourceEvent(ctx, VaultDynamicSecret, r.SourceCh, event.GenericEvent{
+		Object: &secretsv1beta1.VaultDynamicSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: objKey.Namespace,
+				Name:      objKey.Name,
+			},
+		},
+	})
+}
+
 func computeRotationTime(o *secretsv1beta1.VaultDynamicSecret) time.Time {
 	var ts int64
 	var horizon time.Duration
 	d := getRotationDuration(o)
-	if o.Spec.AllowStaticCreds {
+	if useStaticCreds(o) {
 		ts = o.Status.StaticCredsMetaData.LastVaultRotation
 		horizon = d
+	} else if usePolling(o) {
+		ts = o.Status.LastRenewalTime
+		horizon = d
 	} else {
 		ts = o.Status.LastRenewalTime
 		horizon = computeStartRenewingAt(d, o.Spec.RenewalPercent)
@@ -855,7 +1461,7 @@ func computeRotationTime(o *secretsv1beta1.VaultDynamicSecret) time.Time {
 func computeRelativeHorizon(o *secretsv1beta1.VaultDynamicSecret) (time.Duration, bool) {
 	ts := computeRotationTime(o)
 	now := nowFunc()
-	if o.Spec.AllowStaticCreds {
+	if useStaticCreds(o) {
 		return ts.Sub(now), now.Before(ts)
 	} else {
 		return ts.Sub(now), now.After(ts)
@@ -875,7 +1481,7 @@ func computeRelativeHorizonWithJitter(o *secretsv1beta1.VaultDynamicSecret, minH
 	if horizon < minHorizon {
 		horizon = minHorizon
 	}
-	if o.Spec.AllowStaticCreds {
+	if useStaticCreds(o) {
 		_, jitter := computeMaxJitterWithPercent(staticCredsJitterHorizon, vdsJitterFactor)
 		horizon += time.Duration(jitter)
 	} else {
@@ -885,6 +1491,129 @@ func computeRelativeHorizonWithJitter(o *secretsv1beta1.VaultDynamicSecret, minH
 	return horizon, inWindow
 }
 
+// useDataExpiry returns true if the refresh horizon of o should be computed
+// from the expiry time in the Vault secret data, or from the expiration of the
+// AWS or Kubernetes Engine's credentials.
+func useDataExpiry(o *secretsv1beta1.VaultDynamicSecret) bool {
+	return (o.Spec.ExpiryFieldPath != "" || o.Spec.Engine == engineAWS || o.Spec.Engine == engineKubernetes) &&
+		!useStaticCreds(o) && o.Spec.WrapTTL == ""
+}
+
+// awsSTSExpiry returns the expiration of the STS credentials in resp. Newer
+// Vault versions return the session token's expiration in the response data,
+// otherwise it is computed from the lease duration, since STS leases are never
+// renewable.
+func awsSTSExpiry(resp vault.Response) (time.Time, error) {
+	if v, ok := resp.Data()["expiration"]; ok && v != nil {
+		return expiryTimeFromData(resp.Data(), ".expiration")
+	}
+
+	return leaseExpiry(resp, "AWS STS")
+}
+
+// leaseExpiry returns the expiration of the non-renewable lease of resp, kind
+// describes the response in the returned error.
+func leaseExpiry(resp vault.Response, kind string) (time.Time, error) {
+	if secret := resp.Secret(); secret != nil && secret.LeaseDuration > 0 {
+		return nowFunc().Add(time.Duration(secret.LeaseDuration) * time.Second), nil
+	}
+
+	return time.Time{}, fmt.Errorf("no expiration found in the %s response", kind)
+}
+
+// kubernetesCredsData returns the data of the Kubernetes service account token
+// response, augmented with the keys of a service account token Secret: "token",
+// "namespace", and "ca.crt" if the config of the Kubernetes secrets engine
+// includes a CA certificate. The "kubernetes_host" from the config is included
+// as well, so that a kubeconfig can be rendered from the data.
+func kubernetesCredsData(data, config map[string]any) map[string]any {
+	result := make(map[string]any, len(data)+4)
+	for k, v := range data {
+		result[k] = v
+	}
+	if v, ok := data["service_account_token"]; ok {
+		result["token"] = v
+	}
+	if v, ok := data["service_account_namespace"]; ok {
+		result["namespace"] = v
+	}
+	if v, ok := config["kubernetes_ca_cert"].(string); ok && v != "" {
+		result["ca.crt"] = v
+	}
+	if v, ok := config["kubernetes_host"].(string); ok && v != "" {
+		result["kubernetes_host"] = v
+	}
+
+	return result
+}
+
+// useStaticCreds returns true if o syncs static credentials that are rotated by
+// Vault. An explicit RefreshMode takes precedence over AllowStaticCreds. Wrapped
+// responses never use static-creds semantics.
+func useStaticCreds(o *secretsv1beta1.VaultDynamicSecret) bool {
+	if o.Spec.WrapTTL != "" {
+		return false
+	}
+	if o.Spec.RefreshMode != "" {
+		return o.Spec.RefreshMode == refreshModeStaticCreds
+	}
+	return o.Spec.AllowStaticCreds
+}
+
+// usePolling returns true if o's secret should be refreshed by requesting new
+// credentials every RefreshAfter, without ever renewing its lease. Wrapped
+// responses are always polled, since their lease is unknown.
+func usePolling(o *secretsv1beta1.VaultDynamicSecret) bool {
+	return o.Spec.RefreshMode == refreshModePoll || o.Spec.WrapTTL != ""
+}
+
+// expiryTimeFromData returns the expiry time found in data at the JSONPath
+// fieldPath. The expiry must be either an RFC 3339 timestamp, or a Unix
+// timestamp in seconds.
+func expiryTimeFromData(data map[string]any, fieldPath string) (time.Time, error) {
+	if !strings.HasPrefix(fieldPath, "{") {
+		fieldPath = fmt.Sprintf("{%s}", fieldPath)
+	}
+
+	jp := jsonpath.New("expiryFieldPath")
+	if err := jp.Parse(fieldPath); err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiryFieldPath %q: %w", fieldPath, err)
+	}
+
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expiry not found at %q: %w", fieldPath, err)
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		return time.Time{}, fmt.Errorf("expected a single expiry at %q", fieldPath)
+	}
+
+	var ts int64
+	switch v := results[0][0].Interface().(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+		if ts, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid expiry %q at %q", v, fieldPath)
+		}
+	case json.Number:
+		if ts, err = v.Int64(); err != nil {
+			return time.Time{}, fmt.Errorf("invalid expiry %q at %q", v, fieldPath)
+		}
+	case int:
+		ts = int64(v)
+	case int64:
+		ts = v
+	case float64:
+		ts = int64(v)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported expiry type %T at %q", v, fieldPath)
+	}
+
+	return time.Unix(ts, 0), nil
+}
+
 func vaultStaticCredsMetaDataFromData(data map[string]any) (*secretsv1beta1.VaultStaticCredsMetaData, error) {
 	var ret secretsv1beta1.VaultStaticCredsMetaData
 	if v, ok := data["last_vault_rotation"]; ok && v != nil {
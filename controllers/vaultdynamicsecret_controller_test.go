@@ -5,17 +5,21 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -189,7 +193,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -228,7 +232,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -271,7 +275,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -315,7 +319,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -360,7 +364,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -404,7 +408,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -444,7 +448,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -484,7 +488,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -525,7 +529,7 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 				runtimePodUID: "",
 			},
 			args: args{
-				ctx:     nil,
+				ctx:     context.Background(),
 				vClient: &vault.MockRecordingVaultClient{},
 				o: &secretsv1beta1.VaultDynamicSecret{
 					ObjectMeta: metav1.ObjectMeta{
@@ -552,11 +556,396 @@ func TestVaultDynamicSecretReconciler_syncSecret(t *testing.T) {
 					"unsupported HTTP method %q for sync", http.MethodOptions), i...)
 			},
 		},
+		{
+			name: "with-params-from",
+			fields: fields{
+				Client: fake.NewClientBuilder().WithObjects(
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "params",
+							Namespace: "default",
+						},
+						Data: map[string][]byte{
+							"csr": []byte("-----BEGIN CERTIFICATE REQUEST-----"),
+						},
+					},
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "params",
+							Namespace: "default",
+						},
+						Data: map[string]string{
+							"ttl": "1h",
+						},
+					},
+				).Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx:     context.Background(),
+				vClient: &vault.MockRecordingVaultClient{},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount: "baz",
+						Path:  "foo",
+						Params: map[string]string{
+							"qux": "bar",
+							"csr": "overridden",
+						},
+						ParamsFrom: []secretsv1beta1.ParamFromSource{
+							{
+								Name: "csr",
+								SecretKeyRef: &secretsv1beta1.SecretKeyRef{
+									Name: "params",
+									Key:  "csr",
+								},
+							},
+							{
+								Name: "ttl",
+								ConfigMapKeyRef: &secretsv1beta1.ConfigMapKeyRef{
+									Name: "params",
+									Key:  "ttl",
+								},
+							},
+						},
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{},
+				},
+			},
+			want: &secretsv1beta1.VaultSecretLease{
+				LeaseDuration: 0,
+				Renewable:     false,
+			},
+			expectRequests: []*vault.MockRequest{
+				{
+					Method: http.MethodPut,
+					Path:   "baz/foo",
+					Params: map[string]any{
+						"qux": "bar",
+						"csr": "-----BEGIN CERTIFICATE REQUEST-----",
+						"ttl": "1h",
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "with-params-from-secret-not-found",
+			fields: fields{
+				Client:        fake.NewClientBuilder().Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx:     context.Background(),
+				vClient: &vault.MockRecordingVaultClient{},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount: "baz",
+						Path:  "foo",
+						ParamsFrom: []secretsv1beta1.ParamFromSource{
+							{
+								Name: "csr",
+								SecretKeyRef: &secretsv1beta1.SecretKeyRef{
+									Name: "params",
+									Key:  "csr",
+								},
+							},
+						},
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{},
+				},
+			},
+			want:           nil,
+			expectRequests: nil,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`failed to get param "csr" from its source: secrets "params" not found`, i...)
+			},
+		},
+		{
+			name: "expiry-field-path-not-found",
+			fields: fields{
+				Client:        fake.NewClientBuilder().Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx:     context.Background(),
+				vClient: &vault.MockRecordingVaultClient{},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount:           "baz",
+						Path:            "foo",
+						ExpiryFieldPath: ".expires_on",
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{},
+				},
+			},
+			want: nil,
+			expectRequests: []*vault.MockRequest{
+				{
+					Method: http.MethodGet,
+					Path:   "baz/foo",
+					Params: nil,
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`expiry not found at "{.expires_on}": expires_on is not found`, i...)
+			},
+		},
+		{
+			name: "aws-sts-no-expiration",
+			fields: fields{
+				Client:        fake.NewClientBuilder().Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx:     context.Background(),
+				vClient: &vault.MockRecordingVaultClient{},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount:  "aws",
+						Path:   "deploy",
+						Engine: "aws",
+						Params: map[string]string{
+							"ttl": "1h",
+						},
+						AWS: &secretsv1beta1.VaultDynamicSecretAWS{
+							RoleARN: "arn:aws:iam::123456789012:role/deploy",
+							TTL:     "15m",
+							SessionTags: map[string]string{
+								"team": "a",
+								"env":  "prod",
+							},
+						},
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{},
+				},
+			},
+			want: nil,
+			expectRequests: []*vault.MockRequest{
+				{
+					Method: http.MethodPut,
+					Path:   "aws/sts/deploy",
+					Params: map[string]any{
+						"role_arn":     "arn:aws:iam::123456789012:role/deploy",
+						"ttl":          "15m",
+						"session_tags": []string{"env=prod", "team=a"},
+					},
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`no expiration found in the AWS STS response`, i...)
+			},
+		},
+		{
+			name: "kubernetes-creds-no-lease",
+			fields: fields{
+				Client:        fake.NewClientBuilder().Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx:     context.Background(),
+				vClient: &vault.MockRecordingVaultClient{},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount:  "kubernetes",
+						Path:   "deploy",
+						Engine: "kubernetes",
+						Kubernetes: &secretsv1beta1.VaultDynamicSecretKubernetes{
+							KubernetesNamespace: "apps",
+							ClusterRoleBinding:  true,
+							TTL:                 "30m",
+							Audiences:           []string{"api", "vault"},
+						},
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{},
+				},
+			},
+			want: nil,
+			expectRequests: []*vault.MockRequest{
+				{
+					Method: http.MethodPut,
+					Path:   "kubernetes/creds/deploy",
+					Params: map[string]any{
+						"kubernetes_namespace": "apps",
+						"cluster_role_binding": true,
+						"ttl":                  "30m",
+						"audiences":            "api,vault",
+					},
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`no expiration found in the Kubernetes service account token response`, i...)
+			},
+		},
+		{
+			name: "consul-with-role",
+			fields: fields{
+				Client:        fake.NewClientBuilder().Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx:     context.Background(),
+				vClient: &vault.MockRecordingVaultClient{},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount:  "consul",
+						Path:   "deploy",
+						Engine: "consul",
+						Consul: &secretsv1beta1.VaultDynamicSecretConsul{
+							Policies: []string{"read-kv", "write-kv"},
+							TTL:      "1h",
+						},
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{},
+				},
+			},
+			want: &secretsv1beta1.VaultSecretLease{
+				LeaseDuration: 0,
+				Renewable:     false,
+			},
+			expectRequests: []*vault.MockRequest{
+				{
+					Method: http.MethodPut,
+					Path:   "consul/roles/deploy",
+					Params: map[string]any{
+						"consul_policies": "read-kv,write-kv",
+						"ttl":             "1h",
+					},
+				},
+				{
+					Method: http.MethodGet,
+					Path:   "consul/creds/deploy",
+					Params: nil,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "ldap-library-check-in",
+			fields: fields{
+				Client:        fake.NewClientBuilder().Build(),
+				runtimePodUID: "",
+			},
+			args: args{
+				ctx: context.Background(),
+				vClient: &vault.MockRecordingVaultClient{
+					WriteResponses: map[string][]vault.Response{
+						"ldap/library/deploy/check-out": {
+							vault.NewDefaultResponse(&api.Secret{
+								LeaseID:       "ldap/library/deploy/check-out/abc",
+								LeaseDuration: 3600,
+								Renewable:     true,
+								Data: map[string]any{
+									"service_account_name": "svc-new",
+									"password":             "secret",
+								},
+							}),
+						},
+					},
+				},
+				o: &secretsv1beta1.VaultDynamicSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "default",
+					},
+					Spec: secretsv1beta1.VaultDynamicSecretSpec{
+						Mount:  "ldap",
+						Path:   "deploy",
+						Engine: "ldap-library",
+						LDAPLibrary: &secretsv1beta1.VaultDynamicSecretLDAPLibrary{
+							TTL: "1h",
+						},
+						Destination: secretsv1beta1.Destination{
+							Name:   "baz",
+							Create: true,
+						},
+					},
+					Status: secretsv1beta1.VaultDynamicSecretStatus{
+						CheckedOutAccount: "svc-old",
+					},
+				},
+			},
+			want: &secretsv1beta1.VaultSecretLease{
+				ID:            "ldap/library/deploy/check-out/abc",
+				LeaseDuration: 3600,
+				Renewable:     true,
+			},
+			expectRequests: []*vault.MockRequest{
+				{
+					Method: http.MethodPut,
+					Path:   "ldap/library/deploy/check-out",
+					Params: map[string]any{
+						"ttl": "1h",
+					},
+				},
+				{
+					Method: http.MethodPut,
+					Path:   "ldap/library/deploy/check-in",
+					Params: map[string]any{
+						"service_account_names": []string{"svc-old"},
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &VaultDynamicSecretReconciler{
-				Client: tt.fields.Client,
+				Client:   tt.fields.Client,
+				Recorder: record.NewFakeRecorder(10),
 			}
 			got, _, err := r.syncSecret(tt.args.ctx, tt.args.vClient, tt.args.o, nil)
 			if !tt.wantErr(t, err, fmt.Sprintf("syncSecret(%v, %v, %v, %v)", tt.args.ctx, tt.args.vClient, tt.args.o, nil)) {
@@ -717,6 +1106,74 @@ func Test_computeRotationTime(t *testing.T) {
 			},
 			want: then.Add(180 * time.Second),
 		},
+		{
+			name: "sixty-percent-expiry",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 60,
+					},
+					ExpiryTime:      then.Add(time.Hour).Unix(),
+					LastRenewalTime: then.Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent:  60,
+					ExpiryFieldPath: ".expires_on",
+				},
+			},
+			// the clock skew tolerance is subtracted from the expiry.
+			want: then.Add((time.Hour - expiryClockSkewTolerance) * 6 / 10),
+		},
+		{
+			name: "sixty-percent-short-expiry",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					ExpiryTime:      then.Add(100 * time.Second).Unix(),
+					LastRenewalTime: then.Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent:  60,
+					ExpiryFieldPath: ".expires_on",
+				},
+			},
+			// the clock skew tolerance is capped at 10 percent of the expiry.
+			want: then.Add(54 * time.Second),
+		},
+		{
+			name: "sixty-percent-expiry-static-creds",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					StaticCredsMetaData: secretsv1beta1.VaultStaticCredsMetaData{
+						LastVaultRotation: then.Unix(),
+						TTL:               30,
+					},
+					ExpiryTime: then.Add(time.Hour).Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent:   60,
+					AllowStaticCreds: true,
+					ExpiryFieldPath:  ".expires_on",
+				},
+			},
+			want: then.Add(30 * time.Second),
+		},
+		{
+			name: "poll-refreshAfter",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 300,
+					},
+					LastRenewalTime: then.Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 60,
+					RefreshAfter:   "100s",
+					RefreshMode:    refreshModePoll,
+				},
+			},
+			want: then.Add(100 * time.Second),
+		},
 		{
 			name: "invalid-refreshAfter-value",
 			vds: &secretsv1beta1.VaultDynamicSecret{
@@ -739,6 +1196,112 @@ func Test_computeRotationTime(t *testing.T) {
 	}
 }
 
+func Test_computeExpiryDeadline(t *testing.T) {
+	// time without nanos, for ease of comparison
+	then := time.Unix(nowFunc().Unix(), 0)
+	tests := []struct {
+		name         string
+		vds          *secretsv1beta1.VaultDynamicSecret
+		now          time.Time
+		wantDeadline time.Time
+		wantExpedite bool
+	}{
+		{
+			name: "before-renewal",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 300,
+					},
+					LastRenewalTime: then.Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 50,
+				},
+			},
+			now: then.Add(149 * time.Second),
+		},
+		{
+			name: "past-renewal",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 300,
+					},
+					LastRenewalTime: then.Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 50,
+				},
+			},
+			now:          then.Add(150 * time.Second),
+			wantDeadline: then.Add(300 * time.Second),
+			wantExpedite: true,
+		},
+		{
+			name: "static-creds-past-rotation",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					StaticCredsMetaData: secretsv1beta1.VaultStaticCredsMetaData{
+						LastVaultRotation: then.Unix(),
+						TTL:               60,
+					},
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					AllowStaticCreds: true,
+				},
+			},
+			now:          then.Add(61 * time.Second),
+			wantDeadline: then.Add(60 * time.Second),
+			wantExpedite: true,
+		},
+		{
+			name: "refresh-after-only",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					LastRenewalTime: then.Unix(),
+				},
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 50,
+					RefreshAfter:   "30s",
+				},
+			},
+			now: then.Add(time.Hour),
+		},
+		{
+			name: "never-synced",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 50,
+				},
+			},
+			now: then,
+		},
+		{
+			name: "deleted",
+			vds: &secretsv1beta1.VaultDynamicSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &metav1.Time{Time: then},
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 300,
+					},
+					LastRenewalTime: then.Unix(),
+				},
+			},
+			now: then.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, expedite := computeExpiryDeadline(tt.vds, tt.now)
+			assert.Equal(t, tt.wantExpedite, expedite)
+			assert.Equal(t, tt.wantDeadline, deadline)
+		})
+	}
+}
+
 func Test_computeRelativeHorizonWithJitter(t *testing.T) {
 	staticNow := time.Unix(nowFunc().Unix(), 0)
 	defaultNowFunc := func() time.Time { return staticNow }
@@ -892,44 +1455,126 @@ func TestVaultDynamicSecretReconciler_computePostSyncHorizon(t *testing.T) {
 					},
 				},
 			},
-			wantMinHorizon: time.Duration(30 * float64(time.Second)),
-			// max jitter 150000000
-			wantMaxHorizon: time.Duration(30.65 * float64(time.Second)),
+			wantMinHorizon: time.Duration(30 * float64(time.Second)),
+			// max jitter 150000000
+			wantMaxHorizon: time.Duration(30.65 * float64(time.Second)),
+		},
+		{
+			name: "static-creds-ttl-0",
+			o: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					AllowStaticCreds: true,
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					StaticCredsMetaData: secretsv1beta1.VaultStaticCredsMetaData{
+						LastVaultRotation: nowFunc().Unix() - 30,
+						RotationPeriod:    60,
+						TTL:               0,
+					},
+				},
+			},
+			wantMinHorizon: time.Duration(1 * float64(time.Second)),
+			// max jitter 150000000
+			wantMaxHorizon: time.Duration(1.15 * float64(time.Second)),
+		},
+		{
+			name: "allowed-but-not-static-creds-response",
+			o: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					AllowStaticCreds: true,
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{},
+			},
+			wantMinHorizon: 0,
+			wantMaxHorizon: 0,
+		},
+		{
+			name: "leased",
+			o: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 60,
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 100,
+					},
+				},
+			},
+			wantMaxHorizon: time.Second * 70,
+			wantMinHorizon: time.Second * 60,
+		},
+		{
+			name: "leased-with-refreshAfter",
+			o: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 60,
+					RefreshAfter:   "200s",
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 100,
+					},
+				},
+			},
+			wantMaxHorizon: time.Second * 70,
+			wantMinHorizon: time.Second * 60,
+		},
+		{
+			name: "not-leased-with-refreshAfter",
+			o: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					RenewalPercent: 60,
+					RefreshAfter:   "100s",
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{},
+				},
+			},
+			wantMaxHorizon: time.Second * 70,
+			wantMinHorizon: time.Second * 60,
 		},
 		{
-			name: "static-creds-ttl-0",
+			name: "poll-with-refreshAfter",
 			o: &secretsv1beta1.VaultDynamicSecret{
 				Spec: secretsv1beta1.VaultDynamicSecretSpec{
-					AllowStaticCreds: true,
+					RenewalPercent: 60,
+					RefreshAfter:   "100s",
+					RefreshMode:    refreshModePoll,
 				},
 				Status: secretsv1beta1.VaultDynamicSecretStatus{
-					StaticCredsMetaData: secretsv1beta1.VaultStaticCredsMetaData{
-						LastVaultRotation: nowFunc().Unix() - 30,
-						RotationPeriod:    60,
-						TTL:               0,
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 300,
+						Renewable:     true,
 					},
 				},
 			},
-			wantMinHorizon: time.Duration(1 * float64(time.Second)),
-			// max jitter 150000000
-			wantMaxHorizon: time.Duration(1.15 * float64(time.Second)),
+			wantMaxHorizon: time.Second * 90,
+			wantMinHorizon: time.Second * 80,
 		},
 		{
-			name: "allowed-but-not-static-creds-response",
+			name: "poll-without-refreshAfter",
 			o: &secretsv1beta1.VaultDynamicSecret{
 				Spec: secretsv1beta1.VaultDynamicSecretSpec{
-					AllowStaticCreds: true,
+					RenewalPercent: 60,
+					RefreshMode:    refreshModePoll,
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 100,
+					},
 				},
-				Status: secretsv1beta1.VaultDynamicSecretStatus{},
 			},
-			wantMinHorizon: 0,
-			wantMaxHorizon: 0,
+			wantMaxHorizon: time.Second * 90,
+			wantMinHorizon: time.Second * 80,
 		},
 		{
-			name: "leased",
+			name: "wrapped",
 			o: &secretsv1beta1.VaultDynamicSecret{
 				Spec: secretsv1beta1.VaultDynamicSecretSpec{
-					RenewalPercent: 60,
+					RenewalPercent:   60,
+					RefreshAfter:     "200s",
+					WrapTTL:          "100s",
+					AllowStaticCreds: true,
 				},
 				Status: secretsv1beta1.VaultDynamicSecretStatus{
 					SecretLease: secretsv1beta1.VaultSecretLease{
@@ -937,34 +1582,39 @@ func TestVaultDynamicSecretReconciler_computePostSyncHorizon(t *testing.T) {
 					},
 				},
 			},
-			wantMaxHorizon: time.Second * 70,
-			wantMinHorizon: time.Second * 60,
+			wantMaxHorizon: time.Second * 90,
+			wantMinHorizon: time.Second * 80,
 		},
 		{
-			name: "leased-with-refreshAfter",
+			name: "refreshMode-static-creds",
 			o: &secretsv1beta1.VaultDynamicSecret{
 				Spec: secretsv1beta1.VaultDynamicSecretSpec{
-					RenewalPercent: 60,
-					RefreshAfter:   "200s",
+					RefreshMode: refreshModeStaticCreds,
 				},
 				Status: secretsv1beta1.VaultDynamicSecretStatus{
-					SecretLease: secretsv1beta1.VaultSecretLease{
-						LeaseDuration: 100,
+					StaticCredsMetaData: secretsv1beta1.VaultStaticCredsMetaData{
+						LastVaultRotation: nowFunc().Unix() - 30,
+						RotationPeriod:    60,
+						TTL:               30,
 					},
 				},
 			},
-			wantMaxHorizon: time.Second * 70,
-			wantMinHorizon: time.Second * 60,
+			wantMinHorizon: time.Duration(30 * float64(time.Second)),
+			// max jitter 150000000
+			wantMaxHorizon: time.Duration(30.65 * float64(time.Second)),
 		},
 		{
-			name: "not-leased-with-refreshAfter",
+			name: "refreshMode-lease-overrides-allowStaticCreds",
 			o: &secretsv1beta1.VaultDynamicSecret{
 				Spec: secretsv1beta1.VaultDynamicSecretSpec{
-					RenewalPercent: 60,
-					RefreshAfter:   "100s",
+					RenewalPercent:   60,
+					AllowStaticCreds: true,
+					RefreshMode:      "lease",
 				},
 				Status: secretsv1beta1.VaultDynamicSecretStatus{
-					SecretLease: secretsv1beta1.VaultSecretLease{},
+					SecretLease: secretsv1beta1.VaultSecretLease{
+						LeaseDuration: 100,
+					},
 				},
 			},
 			wantMaxHorizon: time.Second * 70,
@@ -1243,6 +1893,111 @@ func Test_vaultStaticCredsMetaDataFromData(t *testing.T) {
 	}
 }
 
+func Test_expiryTimeFromData(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 23, 18, 1, 0, time.UTC)
+	tests := []struct {
+		name      string
+		data      map[string]any
+		fieldPath string
+		want      time.Time
+		wantErr   string
+	}{
+		{
+			name: "rfc3339",
+			data: map[string]any{
+				"expires_on": "2024-05-01T23:18:01Z",
+			},
+			fieldPath: ".expires_on",
+			want:      ts,
+		},
+		{
+			name: "braced-nested",
+			data: map[string]any{
+				"token": map[string]any{
+					"expiry": "2024-05-01T23:18:01Z",
+				},
+			},
+			fieldPath: "{.token.expiry}",
+			want:      ts,
+		},
+		{
+			name: "unix-json-number",
+			data: map[string]any{
+				"expires_on": json.Number(strconv.FormatInt(ts.Unix(), 10)),
+			},
+			fieldPath: ".expires_on",
+			want:      time.Unix(ts.Unix(), 0),
+		},
+		{
+			name: "unix-string",
+			data: map[string]any{
+				"expires_on": strconv.FormatInt(ts.Unix(), 10),
+			},
+			fieldPath: ".expires_on",
+			want:      time.Unix(ts.Unix(), 0),
+		},
+		{
+			name: "unix-int",
+			data: map[string]any{
+				"expires_on": int(ts.Unix()),
+			},
+			fieldPath: ".expires_on",
+			want:      time.Unix(ts.Unix(), 0),
+		},
+		{
+			name: "not-found",
+			data: map[string]any{
+				"expires": "2024-05-01T23:18:01Z",
+			},
+			fieldPath: ".expires_on",
+			wantErr:   `expiry not found at "{.expires_on}": expires_on is not found`,
+		},
+		{
+			name: "invalid-path",
+			data: map[string]any{
+				"expires_on": "2024-05-01T23:18:01Z",
+			},
+			fieldPath: ".expires_on[",
+			wantErr:   `invalid expiryFieldPath "{.expires_on[}"`,
+		},
+		{
+			name: "multiple",
+			data: map[string]any{
+				"expires_on": []any{"2024-05-01T23:18:01Z", "2024-05-01T23:18:01Z"},
+			},
+			fieldPath: ".expires_on[*]",
+			wantErr:   `expected a single expiry at "{.expires_on[*]}"`,
+		},
+		{
+			name: "invalid-string",
+			data: map[string]any{
+				"expires_on": "tomorrow",
+			},
+			fieldPath: ".expires_on",
+			wantErr:   `invalid expiry "tomorrow" at "{.expires_on}"`,
+		},
+		{
+			name: "unsupported-type",
+			data: map[string]any{
+				"expires_on": true,
+			},
+			fieldPath: ".expires_on",
+			wantErr:   `unsupported expiry type bool at "{.expires_on}"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expiryTimeFromData(tt.data, tt.fieldPath)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "expiryTimeFromData() = %s, want %s", got, tt.want)
+		})
+	}
+}
+
 type vaultResponse struct {
 	data map[string]any
 }
@@ -1259,6 +2014,230 @@ func (s *vaultResponse) SecretK8sData(_ *helpers.SecretTransformationOption) (ma
 	return nil, nil
 }
 
+func Test_awsSTSExpiry(t *testing.T) {
+	staticNow := time.Unix(time.Now().Unix(), 0)
+	nowFuncOrig := nowFunc
+	t.Cleanup(func() {
+		nowFunc = nowFuncOrig
+	})
+	nowFunc = func() time.Time { return staticNow }
+
+	tests := []struct {
+		name    string
+		secret  *api.Secret
+		want    time.Time
+		wantErr string
+	}{
+		{
+			name: "expiration",
+			secret: &api.Secret{
+				LeaseDuration: 3600,
+				Data: map[string]any{
+					"access_key": "ASIA",
+					"expiration": staticNow.Add(15 * time.Minute).UTC().Format(time.RFC3339),
+				},
+			},
+			want: staticNow.Add(15 * time.Minute),
+		},
+		{
+			name: "lease-duration",
+			secret: &api.Secret{
+				LeaseDuration: 3600,
+				Data: map[string]any{
+					"access_key": "ASIA",
+				},
+			},
+			want: staticNow.Add(time.Hour),
+		},
+		{
+			name: "invalid-expiration",
+			secret: &api.Secret{
+				Data: map[string]any{
+					"expiration": "tomorrow",
+				},
+			},
+			wantErr: `invalid expiry "tomorrow" at "{.expiration}"`,
+		},
+		{
+			name: "no-expiration",
+			secret: &api.Secret{
+				Data: map[string]any{
+					"access_key": "ASIA",
+				},
+			},
+			wantErr: "no expiration found in the AWS STS response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := awsSTSExpiry(vault.NewDefaultResponse(tt.secret))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "awsSTSExpiry() = %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func Test_engineRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		spec       secretsv1beta1.VaultDynamicSecretSpec
+		wantPath   string
+		wantParams map[string]any
+	}{
+		{
+			name: "no-engine",
+			spec: secretsv1beta1.VaultDynamicSecretSpec{
+				Mount: "consul",
+				Path:  "deploy",
+				Consul: &secretsv1beta1.VaultDynamicSecretConsul{
+					Policies: []string{"read-kv"},
+				},
+			},
+		},
+		{
+			name: "consul-without-policies",
+			spec: secretsv1beta1.VaultDynamicSecretSpec{
+				Mount:  "consul",
+				Path:   "deploy",
+				Engine: engineConsul,
+				Consul: &secretsv1beta1.VaultDynamicSecretConsul{
+					TTL: "1h",
+				},
+			},
+		},
+		{
+			name: "consul",
+			spec: secretsv1beta1.VaultDynamicSecretSpec{
+				Mount:  "consul",
+				Path:   "deploy",
+				Engine: engineConsul,
+				Consul: &secretsv1beta1.VaultDynamicSecretConsul{
+					Policies: []string{"read-kv"},
+				},
+			},
+			wantPath: "consul/roles/deploy",
+			wantParams: map[string]any{
+				"consul_policies": "read-kv",
+			},
+		},
+		{
+			name: "nomad-without-policies",
+			spec: secretsv1beta1.VaultDynamicSecretSpec{
+				Mount:  "nomad",
+				Path:   "deploy",
+				Engine: engineNomad,
+				Nomad:  &secretsv1beta1.VaultDynamicSecretNomad{},
+			},
+		},
+		{
+			name: "nomad-client",
+			spec: secretsv1beta1.VaultDynamicSecretSpec{
+				Mount:  "nomad",
+				Path:   "deploy",
+				Engine: engineNomad,
+				Nomad: &secretsv1beta1.VaultDynamicSecretNomad{
+					Policies: []string{"readonly", "submit-job"},
+					Global:   true,
+				},
+			},
+			wantPath: "nomad/role/deploy",
+			wantParams: map[string]any{
+				"type":     "client",
+				"global":   true,
+				"policies": "readonly,submit-job",
+			},
+		},
+		{
+			name: "nomad-management",
+			spec: secretsv1beta1.VaultDynamicSecretSpec{
+				Mount:  "nomad",
+				Path:   "admin",
+				Engine: engineNomad,
+				Nomad: &secretsv1beta1.VaultDynamicSecretNomad{
+					Type: "management",
+				},
+			},
+			wantPath: "nomad/role/admin",
+			wantParams: map[string]any{
+				"type":   "management",
+				"global": false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotPath, gotParams := engineRole(&secretsv1beta1.VaultDynamicSecret{Spec: tt.spec})
+			assert.Equal(t, tt.wantPath, gotPath)
+			assert.Equal(t, tt.wantParams, gotParams)
+		})
+	}
+}
+
+func Test_kubernetesCredsData(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"service_account_name":      "v-token-deploy",
+		"service_account_namespace": "apps",
+		"service_account_token":     "eyJhbGciOi",
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   map[string]any
+	}{
+		{
+			name: "with-config",
+			config: map[string]any{
+				"kubernetes_ca_cert":   "-----BEGIN CERTIFICATE-----",
+				"kubernetes_host":      "https://k8s.example.com:6443",
+				"disable_local_ca_jwt": false,
+			},
+			want: map[string]any{
+				"service_account_name":      "v-token-deploy",
+				"service_account_namespace": "apps",
+				"service_account_token":     "eyJhbGciOi",
+				"token":                     "eyJhbGciOi",
+				"namespace":                 "apps",
+				"ca.crt":                    "-----BEGIN CERTIFICATE-----",
+				"kubernetes_host":           "https://k8s.example.com:6443",
+			},
+		},
+		{
+			name: "empty-config",
+			config: map[string]any{
+				"kubernetes_ca_cert": "",
+				"kubernetes_host":    "",
+			},
+			want: map[string]any{
+				"service_account_name":      "v-token-deploy",
+				"service_account_namespace": "apps",
+				"service_account_token":     "eyJhbGciOi",
+				"token":                     "eyJhbGciOi",
+				"namespace":                 "apps",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, kubernetesCredsData(data, tt.config))
+		})
+	}
+}
+
 func TestVaultDynamicSecretReconciler_awaitRotation(t *testing.T) {
 	ts, err := time.Parse(time.RFC3339Nano, "2024-05-02T19:48:01.328261545Z")
 	if err != nil {
@@ -1476,3 +2455,133 @@ func TestVaultDynamicSecretReconciler_awaitRotation(t *testing.T) {
 		})
 	}
 }
+
+func Test_remapCredentialKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		usernameKey string
+		passwordKey string
+		want        map[string][]byte
+	}{
+		{
+			name: "unset",
+			want: map[string][]byte{
+				"username": []byte("v-app"),
+				"password": []byte("secret"),
+				"_raw":     []byte("{}"),
+			},
+		},
+		{
+			name:        "remapped",
+			usernameKey: "DB_USER",
+			passwordKey: "DB_PASSWORD",
+			want: map[string][]byte{
+				"DB_USER":     []byte("v-app"),
+				"DB_PASSWORD": []byte("secret"),
+				"_raw":        []byte("{}"),
+			},
+		},
+		{
+			name:        "swapped",
+			usernameKey: "password",
+			passwordKey: "username",
+			want: map[string][]byte{
+				"password": []byte("v-app"),
+				"username": []byte("secret"),
+				"_raw":     []byte("{}"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					UsernameKey: tt.usernameKey,
+					PasswordKey: tt.passwordKey,
+				},
+			}
+			data := map[string][]byte{
+				"username": []byte("v-app"),
+				"password": []byte("secret"),
+				"_raw":     []byte("{}"),
+			}
+			assert.Equal(t, tt.want, remapCredentialKeys(o, data))
+		})
+	}
+}
+
+func Test_credentialsStatus(t *testing.T) {
+	staticNow := time.Unix(time.Now().Unix(), 0)
+	nowFuncOrig := nowFunc
+	t.Cleanup(func() {
+		nowFunc = nowFuncOrig
+	})
+	nowFunc = func() time.Time { return staticNow }
+
+	tests := []struct {
+		name  string
+		o     *secretsv1beta1.VaultDynamicSecret
+		data  map[string]any
+		lease *secretsv1beta1.VaultSecretLease
+		want  secretsv1beta1.VaultDynamicSecretCredentials
+	}{
+		{
+			name: "no-username",
+			o:    &secretsv1beta1.VaultDynamicSecret{},
+			data: map[string]any{
+				"access_key": "ASIA",
+			},
+			lease: &secretsv1beta1.VaultSecretLease{LeaseDuration: 3600},
+			want:  secretsv1beta1.VaultDynamicSecretCredentials{},
+		},
+		{
+			name: "dynamic",
+			o:    &secretsv1beta1.VaultDynamicSecret{},
+			data: map[string]any{
+				"username": "v-app",
+				"password": "secret",
+			},
+			lease: &secretsv1beta1.VaultSecretLease{LeaseDuration: 3600},
+			want: secretsv1beta1.VaultDynamicSecretCredentials{
+				Username:   "v-app",
+				Expiration: staticNow.Unix() + 3600,
+			},
+		},
+		{
+			name: "static",
+			o: &secretsv1beta1.VaultDynamicSecret{
+				Spec: secretsv1beta1.VaultDynamicSecretSpec{
+					AllowStaticCreds: true,
+				},
+				Status: secretsv1beta1.VaultDynamicSecretStatus{
+					StaticCredsMetaData: secretsv1beta1.VaultStaticCredsMetaData{
+						LastVaultRotation: staticNow.Unix() - 600,
+						RotationPeriod:    3600,
+						TTL:               3000,
+					},
+				},
+			},
+			data: map[string]any{
+				"username": "app",
+				"password": "secret",
+			},
+			lease: &secretsv1beta1.VaultSecretLease{},
+			want: secretsv1beta1.VaultDynamicSecretCredentials{
+				Username:          "app",
+				LastVaultRotation: staticNow.Unix() - 600,
+				Expiration:        staticNow.Unix() + 3000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := credentialsStatus(tt.o, vault.NewDefaultResponse(&api.Secret{Data: tt.data}), tt.lease)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// VaultEventSubscriptionReconciler reconciles a VaultEventSubscription object.
+// The Vault event watchers are run by the controllers of the subscribed
+// resources, the reconciler only validates the subscription.
+type VaultEventSubscriptionReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// GlobalVaultAuthOptions is a struct that contains global VaultAuth options.
+	GlobalVaultAuthOptions *common.GlobalVaultAuthOptions
+}
+
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaulteventsubscriptions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaulteventsubscriptions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile validates the VaultEventSubscription CR instance, updating the
+// instance's status with the result.
+func (r *VaultEventSubscriptionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	o := &secretsv1beta1.VaultEventSubscription{}
+	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "Failed to get VaultEventSubscription resource", "resource", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if o.GetDeletionTimestamp() != nil {
+		logger.Info("Got deletion timestamp", "obj", o)
+		metrics.DeleteResourceStatus("vaulteventsubscription", o)
+		return ctrl.Result{}, nil
+	}
+
+	var errs error
+	if _, err := parseDurationString(o.Spec.DebounceWindow, ".spec.debounceWindow", 0); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	if _, err := common.GetVaultAuthNamespaced(ctx, r.Client, o, r.GlobalVaultAuthOptions); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	var horizon time.Duration
+	if errs != nil {
+		o.Status.Valid = ptr.To(false)
+		o.Status.Error = errs.Error()
+		horizon = computeHorizonWithJitter(requeueDurationOnError)
+	} else {
+		o.Status.Valid = ptr.To(true)
+		o.Status.Error = ""
+	}
+
+	if err := r.updateStatus(ctx, o); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if errs == nil {
+		r.Recorder.Event(o, corev1.EventTypeNormal, consts.ReasonAccepted,
+			"Successfully handled VaultEventSubscription resource request")
+	} else {
+		logger.Error(errs, "Failed to handle VaultEventSubscription resource request", "horizon", horizon)
+		r.Recorder.Event(o, corev1.EventTypeWarning, consts.ReasonAccepted,
+			fmt.Sprintf("Failed to handle VaultEventSubscription resource request: err=%s", errs))
+	}
+
+	return ctrl.Result{
+		RequeueAfter: horizon,
+	}, nil
+}
+
+func (r *VaultEventSubscriptionReconciler) updateStatus(ctx context.Context, o *secretsv1beta1.VaultEventSubscription) error {
+	logger := log.FromContext(ctx)
+	metrics.SetResourceStatus("vaulteventsubscription", o, ptr.Deref(o.Status.Valid, false))
+	if err := r.Status().Update(ctx, o); err != nil {
+		logger.Error(err, "Failed to update the resource's status")
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VaultEventSubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&secretsv1beta1.VaultEventSubscription{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestVaultEventSubscriptionReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	authObj := &secretsv1beta1.VaultAuth{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vso", Name: "auth"},
+		Spec: secretsv1beta1.VaultAuthSpec{
+			Method: "kubernetes",
+			Mount:  "kubernetes",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		o         *secretsv1beta1.VaultEventSubscription
+		wantValid bool
+		wantError string
+	}{
+		{
+			name: "valid",
+			o: &secretsv1beta1.VaultEventSubscription{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "vso", Name: "kv"},
+				Spec: secretsv1beta1.VaultEventSubscriptionSpec{
+					VaultAuthRef:   "auth",
+					EventTypes:     []string{"kv*"},
+					DebounceWindow: "5s",
+				},
+			},
+			wantValid: true,
+		},
+		{
+			name: "invalid-debounce-window",
+			o: &secretsv1beta1.VaultEventSubscription{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "vso", Name: "kv"},
+				Spec: secretsv1beta1.VaultEventSubscriptionSpec{
+					VaultAuthRef:   "auth",
+					DebounceWindow: "5x",
+				},
+			},
+			wantError: `invalid value "5x" for .spec.debounceWindow`,
+		},
+		{
+			name: "vault-auth-namespace-not-allowed",
+			o: &secretsv1beta1.VaultEventSubscription{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "kv"},
+				Spec: secretsv1beta1.VaultEventSubscriptionSpec{
+					VaultAuthRef: "vso/auth",
+				},
+			},
+			wantError: `target namespace "tenant" is not allowed by kind=VaultAuth`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			c := testutils.NewFakeClientBuilder().
+				WithObjects(authObj.DeepCopy(), tt.o).
+				WithStatusSubresource(tt.o).
+				Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &VaultEventSubscriptionReconciler{
+				Client:   c,
+				Recorder: recorder,
+			}
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tt.o)})
+			require.NoError(t, err)
+
+			var got secretsv1beta1.VaultEventSubscription
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt.o), &got))
+			assert.Equal(t, ptr.To(tt.wantValid), got.Status.Valid)
+			require.Len(t, recorder.Events, 1)
+			if tt.wantValid {
+				assert.Empty(t, got.Status.Error)
+				assert.Zero(t, res.RequeueAfter)
+				assert.Contains(t, <-recorder.Events, "Normal Accepted")
+			} else {
+				assert.Contains(t, got.Status.Error, tt.wantError)
+				assert.NotZero(t, res.RequeueAfter)
+				assert.Contains(t, <-recorder.Events, "Warning Accepted")
+			}
+		})
+	}
+}
+
+func Test_eventSubscriptionObjKeys(t *testing.T) {
+	t.Parallel()
+
+	o := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "app"},
+	}
+	assert.Empty(t, eventSubscriptionObjKeys(o))
+
+	o.Spec.SyncConfig = &secretsv1beta1.SyncConfig{
+		EventSubscriptionRef: "kv",
+	}
+	// the subscription is only referenced with instant updates.
+	assert.Empty(t, eventSubscriptionObjKeys(o))
+
+	o.Spec.SyncConfig.InstantUpdates = true
+	assert.Equal(t, []client.ObjectKey{{Namespace: "tenant", Name: "kv"}}, eventSubscriptionObjKeys(o))
+
+	o.Spec.SyncConfig.EventSubscriptionRef = "vso/kv"
+	assert.Equal(t, []client.ObjectKey{{Namespace: "vso", Name: "kv"}}, eventSubscriptionObjKeys(o))
+}
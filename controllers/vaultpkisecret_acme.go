@@ -0,0 +1,616 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"golang.org/x/crypto/acme"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+const (
+	acmeSolverHTTP01 = "http-01"
+	acmeSolverDNS01  = "dns-01"
+	// acmeAccountKeySecretKey holds the PEM encoded ACME account key in the
+	// account key Secret.
+	acmeAccountKeySecretKey = "account.key"
+	// acmeOrderTimeout bounds the time spent completing a single ACME order,
+	// including waiting for the challenges to be validated.
+	acmeOrderTimeout = 5 * time.Minute
+	// acmeHTTP01ChallengePath is the path prefix of HTTP-01 challenge requests.
+	acmeHTTP01ChallengePath = "/.well-known/acme-challenge/"
+	// acmeDNS01RecordTTL is the TTL of the published DNS-01 TXT records.
+	acmeDNS01RecordTTL = 60
+)
+
+var (
+	_ manager.Runnable               = (*ACMEHTTP01Solver)(nil)
+	_ manager.LeaderElectionRunnable = (*ACMEHTTP01Solver)(nil)
+	_ http.Handler                   = (*ACMEHTTP01Solver)(nil)
+	_ acmeChallengeSolver            = (*ACMEHTTP01Solver)(nil)
+	_ acmeChallengeSolver            = (*acmeDNS01Solver)(nil)
+
+	errACMEHTTP01SolverDisabled = errors.New("the ACME HTTP-01 solver is not enabled")
+
+	dnsEndpointGVK = schema.GroupVersionKind{
+		Group:   "externaldns.k8s.io",
+		Version: "v1alpha1",
+		Kind:    "DNSEndpoint",
+	}
+)
+
+// acmeChallengeSolver fulfills ACME challenges of a single type.
+type acmeChallengeSolver interface {
+	// Present the challenge for domain, so that it can be validated by the ACME
+	// server.
+	Present(ctx context.Context, o *secretsv1beta1.VaultPKISecret, ac *acme.Client, domain string, chal *acme.Challenge) error
+	// CleanUp anything created by Present.
+	CleanUp(ctx context.Context, o *secretsv1beta1.VaultPKISecret, domain string, chal *acme.Challenge) error
+}
+
+// ACMEHTTP01Solver serves the key authorizations of pending ACME HTTP-01
+// challenges. Requests for http://<domain>/.well-known/acme-challenge/ must be
+// routed to it. It is meant to be added to the manager, and only runs on the
+// leader.
+type ACMEHTTP01Solver struct {
+	// BindAddress the solver listens on.
+	BindAddress string
+	mu          sync.RWMutex
+	tokens      map[string]string
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *ACMEHTTP01Solver) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (s *ACMEHTTP01Solver) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("acmeHTTP01Solver")
+	srv := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return log.IntoContext(ctx, logger)
+		},
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("Starting the ACME HTTP-01 solver", "addr", s.BindAddress)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// ServeHTTP responds to a single HTTP-01 challenge request.
+func (s *ACMEHTTP01Solver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := strings.CutPrefix(req.URL.Path, acmeHTTP01ChallengePath)
+	if !ok || token == "" {
+		http.NotFound(w, req)
+		return
+	}
+
+	s.mu.RLock()
+	keyAuth, ok := s.tokens[token]
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	log.FromContext(req.Context()).V(consts.LogLevelDebug).Info(
+		"Serving ACME HTTP-01 challenge", "host", req.Host)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(keyAuth))
+}
+
+// Present implements acmeChallengeSolver.
+func (s *ACMEHTTP01Solver) Present(_ context.Context, _ *secretsv1beta1.VaultPKISecret, ac *acme.Client, _ string, chal *acme.Challenge) error {
+	keyAuth, err := ac.HTTP01ChallengeResponse(chal.Token)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = make(map[string]string)
+	}
+	s.tokens[chal.Token] = keyAuth
+
+	return nil
+}
+
+// CleanUp implements acmeChallengeSolver.
+func (s *ACMEHTTP01Solver) CleanUp(_ context.Context, _ *secretsv1beta1.VaultPKISecret, _ string, chal *acme.Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, chal.Token)
+
+	return nil
+}
+
+// acmeDNS01Solver publishes the TXT records of ACME DNS-01 challenges as
+// external-dns DNSEndpoints, owned by the VaultPKISecret.
+type acmeDNS01Solver struct {
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Present implements acmeChallengeSolver.
+func (s *acmeDNS01Solver) Present(ctx context.Context, o *secretsv1beta1.VaultPKISecret, ac *acme.Client, domain string, chal *acme.Challenge) error {
+	record, err := ac.DNS01ChallengeRecord(chal.Token)
+	if err != nil {
+		return err
+	}
+
+	obj := newDNSEndpoint(o, domain)
+	_, err = controllerutil.CreateOrUpdate(ctx, s.client, obj, func() error {
+		obj.SetLabels(map[string]string{
+			"app.kubernetes.io/component":  "acme-challenge",
+			"app.kubernetes.io/managed-by": "hashicorp-vso",
+		})
+		if err := unstructured.SetNestedSlice(obj.Object, []any{
+			map[string]any{
+				"dnsName":    "_acme-challenge." + domain,
+				"recordType": "TXT",
+				"recordTTL":  int64(acmeDNS01RecordTTL),
+				"targets":    []any{record},
+			},
+		}, "spec", "endpoints"); err != nil {
+			return err
+		}
+		return controllerutil.SetControllerReference(o, obj, s.scheme)
+	})
+
+	return err
+}
+
+// CleanUp implements acmeChallengeSolver.
+func (s *acmeDNS01Solver) CleanUp(ctx context.Context, o *secretsv1beta1.VaultPKISecret, domain string, _ *acme.Challenge) error {
+	return client.IgnoreNotFound(s.client.Delete(ctx, newDNSEndpoint(o, domain)))
+}
+
+// newDNSEndpoint returns the DNSEndpoint for the DNS-01 challenge of domain. Its
+// name is derived from the VaultPKISecret's name and domain.
+func newDNSEndpoint(o *secretsv1beta1.VaultPKISecret, domain string) *unstructured.Unstructured {
+	sum := sha256.Sum256([]byte(domain))
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(dnsEndpointGVK)
+	obj.SetNamespace(o.Namespace)
+	obj.SetName(fmt.Sprintf("%s-acme-%s", o.Name, hex.EncodeToString(sum[:])[:10]))
+	return obj
+}
+
+// issueACMECertificate obtains a certificate for o from Vault's ACME server. It
+// returns a Response equivalent to that of the PKI issue endpoint, along with
+// the locally generated private key.
+func (r *VaultPKISecretReconciler) issueACMECertificate(ctx context.Context, c vault.Client, o *secretsv1beta1.VaultPKISecret) (vault.Response, *vault.PKIPrivateKey, error) {
+	spec := o.Spec.ACME
+	solver, err := r.acmeSolver(spec.Solver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template, err := newACMECSRTemplate(o.Spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accountKey, err := r.getACMEAccountKey(ctx, o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directoryPath := acmeDirectoryPath(o.Spec)
+	ac, err := c.ACMEClient(directoryPath, accountKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, acmeOrderTimeout)
+	defer cancel()
+
+	if err := registerACMEAccount(ctx, c, ac, directoryPath, spec.ExternalAccountBinding); err != nil {
+		return nil, nil, fmt.Errorf("failed to register the ACME account: %w", err)
+	}
+
+	ids := acme.DomainIDs(template.DNSNames...)
+	for _, ip := range template.IPAddresses {
+		ids = append(ids, acme.IPIDs(ip.String())...)
+	}
+
+	order, err := ac.AuthorizeOrder(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the ACME order: %w", err)
+	}
+
+	for _, u := range order.AuthzURLs {
+		if err := authorizeACME(ctx, ac, solver, spec.Solver, o, u); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	order, err = ac.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed waiting for the ACME order: %w", err)
+	}
+
+	privateKey, err := vault.GeneratePKIPrivateKey(spec.KeyType, spec.KeyBits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	csr, err := privateKey.CSR(template)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode([]byte(csr))
+	chain, _, err := ac.CreateOrderCert(ctx, order.FinalizeURL, block.Bytes, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to finalize the ACME order: %w", err)
+	}
+
+	secret, err := newACMEPKISecret(chain, o.Spec.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vault.NewDefaultResponse(secret), privateKey, nil
+}
+
+func (r *VaultPKISecretReconciler) acmeSolver(solverType string) (acmeChallengeSolver, error) {
+	switch solverType {
+	case acmeSolverHTTP01, "":
+		if r.ACMEHTTP01Solver == nil {
+			return nil, errACMEHTTP01SolverDisabled
+		}
+		return r.ACMEHTTP01Solver, nil
+	case acmeSolverDNS01:
+		return &acmeDNS01Solver{
+			client: r.Client,
+			scheme: r.Scheme,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ACME solver %q", solverType)
+	}
+}
+
+// getACMEAccountKey returns the ACME account key from the account key Secret. A
+// new key is generated, and the Secret created, if the Secret does not exist.
+func (r *VaultPKISecretReconciler) getACMEAccountKey(ctx context.Context, o *secretsv1beta1.VaultPKISecret) (crypto.Signer, error) {
+	objKey := client.ObjectKey{
+		Namespace: o.Namespace,
+		Name:      o.Spec.ACME.AccountKeySecretRef,
+	}
+
+	s, err := helpers.GetSecret(ctx, r.Client, objKey)
+	if err == nil {
+		return parseACMEAccountKey(s)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: objKey.Namespace,
+			Name:      objKey.Name,
+			Labels: map[string]string{
+				"app.kubernetes.io/component":  "acme-account",
+				"app.kubernetes.io/managed-by": "hashicorp-vso",
+			},
+		},
+		Data: map[string][]byte{
+			acmeAccountKeySecretKey: pem.EncodeToMemory(&pem.Block{
+				Type:  "EC PRIVATE KEY",
+				Bytes: der,
+			}),
+		},
+	}
+	if err := r.Client.Create(ctx, s); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			// another VaultPKISecret sharing the account created it first.
+			if s, err = helpers.GetSecret(ctx, r.Client, objKey); err == nil {
+				return parseACMEAccountKey(s)
+			}
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// parseACMEAccountKey returns the PEM encoded ACME account key from s. The key
+// may be an EC, RSA, or PKCS #8 private key.
+func parseACMEAccountKey(s *corev1.Secret) (crypto.Signer, error) {
+	block, _ := pem.Decode(s.Data[acmeAccountKeySecretKey])
+	if block == nil {
+		return nil, fmt.Errorf("no ACME account key found in secret %s/%s, key=%q",
+			s.Namespace, s.Name, acmeAccountKeySecretKey)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACME account key in secret %s/%s: %w",
+			s.Namespace, s.Name, err)
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported ACME account key type %T in secret %s/%s",
+			key, s.Namespace, s.Name)
+	}
+}
+
+// registerACMEAccount registers the ACME account of ac, if it is not already
+// registered. When eab is true, new External Account Binding credentials are
+// requested from Vault for the registration.
+func registerACMEAccount(ctx context.Context, c vault.Client, ac *acme.Client, directoryPath string, eab bool) error {
+	if _, err := ac.GetReg(ctx, ""); err == nil {
+		return nil
+	} else if !errors.Is(err, acme.ErrNoAccount) {
+		return err
+	}
+
+	account := &acme.Account{}
+	if eab {
+		binding, err := newACMEExternalAccountBinding(ctx, c, directoryPath)
+		if err != nil {
+			return err
+		}
+		account.ExternalAccountBinding = binding
+	}
+
+	if _, err := ac.Register(ctx, account, acme.AcceptTOS); err != nil &&
+		!errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return err
+	}
+
+	return nil
+}
+
+// newACMEExternalAccountBinding requests new External Account Binding
+// credentials from the new-eab endpoint alongside the ACME directory.
+func newACMEExternalAccountBinding(ctx context.Context, c vault.Client, directoryPath string) (*acme.ExternalAccountBinding, error) {
+	resp, err := c.Write(ctx, vault.NewWriteRequest(path.Join(path.Dir(directoryPath), "new-eab"), nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to request ACME external account binding from Vault: %w", err)
+	}
+
+	data := resp.Data()
+	kid, _ := data["id"].(string)
+	encodedKey, _ := data["key"].(string)
+	if kid == "" || encodedKey == "" {
+		return nil, errors.New("invalid ACME external account binding response from Vault")
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encodedKey, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACME external account binding key from Vault: %w", err)
+	}
+
+	return &acme.ExternalAccountBinding{
+		KID: kid,
+		Key: key,
+	}, nil
+}
+
+// authorizeACME completes the authorization at authzURL with solver, unless it
+// is already valid.
+func authorizeACME(ctx context.Context, ac *acme.Client, solver acmeChallengeSolver, solverType string, o *secretsv1beta1.VaultPKISecret, authzURL string) error {
+	logger := log.FromContext(ctx).WithName("authorizeACME")
+
+	z, err := ac.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("failed to get the ACME authorization: %w", err)
+	}
+	if z.Status == acme.StatusValid {
+		return nil
+	}
+
+	if solverType == "" {
+		solverType = acmeSolverHTTP01
+	}
+
+	var chal *acme.Challenge
+	for _, c := range z.Challenges {
+		if c.Type == solverType {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("no %s ACME challenge offered for %q", solverType, z.Identifier.Value)
+	}
+
+	domain := z.Identifier.Value
+	if err := solver.Present(ctx, o, ac, domain, chal); err != nil {
+		return fmt.Errorf("failed to present the %s ACME challenge for %q: %w", solverType, domain, err)
+	}
+	defer func() {
+		if err := solver.CleanUp(ctx, o, domain, chal); err != nil {
+			logger.Error(err, "Failed to clean up the ACME challenge", "domain", domain)
+		}
+	}()
+
+	if _, err := ac.Accept(ctx, chal); err != nil {
+		return fmt.Errorf("failed to accept the %s ACME challenge for %q: %w", solverType, domain, err)
+	}
+
+	if _, err := ac.WaitAuthorization(ctx, z.URI); err != nil {
+		return fmt.Errorf("failed ACME authorization for %q: %w", domain, err)
+	}
+
+	return nil
+}
+
+// acmeDirectoryPath returns the path of the ACME directory, including the PKI
+// Mount.
+func acmeDirectoryPath(spec secretsv1beta1.VaultPKISecretSpec) string {
+	directoryPath := spec.ACME.DirectoryPath
+	if directoryPath == "" {
+		directoryPath = path.Join("roles", spec.Role, "acme", "directory")
+	}
+
+	return path.Join(spec.Mount, directoryPath)
+}
+
+// newACMECSRTemplate returns a CSR template for the ACME order. ACME only
+// supports DNS and IP identifiers, and the CommonName must be one of them.
+func newACMECSRTemplate(spec secretsv1beta1.VaultPKISecretSpec) (*x509.CertificateRequest, error) {
+	template, err := newPKICSRTemplate(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(template.EmailAddresses) > 0 || len(template.URIs) > 0 {
+		return nil, errors.New("email and URI SANs are not supported with ACME")
+	}
+
+	if cn := spec.CommonName; cn != "" {
+		if ip := net.ParseIP(cn); ip != nil {
+			if !slices.ContainsFunc(template.IPAddresses, ip.Equal) {
+				template.IPAddresses = append([]net.IP{ip}, template.IPAddresses...)
+			}
+		} else if !slices.Contains(template.DNSNames, cn) {
+			template.DNSNames = append([]string{cn}, template.DNSNames...)
+		}
+	}
+
+	if len(template.DNSNames) == 0 && len(template.IPAddresses) == 0 {
+		return nil, errors.New("at least one of CommonName, AltNames, or IPSans is required with ACME")
+	}
+
+	return template, nil
+}
+
+// newACMEPKISecret returns a Secret with the same data as a PKI issue response
+// for the DER encoded certificate chain. The certificate is base64 encoded DER
+// if format is "der", otherwise it is PEM encoded.
+func newACMEPKISecret(chain [][]byte, format string) (*api.Secret, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("empty certificate chain from the ACME server")
+	}
+
+	cert, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, err
+	}
+
+	encode := func(der []byte) string {
+		if format == "der" {
+			return base64.StdEncoding.EncodeToString(der)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: der,
+		}))
+	}
+
+	data := map[string]any{
+		"certificate":   encode(chain[0]),
+		"serial_number": formatCertSerialNumber(cert.SerialNumber),
+		"expiration":    cert.NotAfter.Unix(),
+	}
+	if len(chain) > 1 {
+		var caChain []string
+		for _, der := range chain[1:] {
+			caChain = append(caChain, encode(der))
+		}
+		data["issuing_ca"] = caChain[0]
+		data["ca_chain"] = caChain
+	}
+
+	return &api.Secret{
+		Data: data,
+	}, nil
+}
+
+// formatCertSerialNumber returns the serial number in the colon separated hex
+// format used by Vault.
+func formatCertSerialNumber(n *big.Int) string {
+	b := n.Bytes()
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%02x", v)
+	}
+
+	return strings.Join(parts, ":")
+}
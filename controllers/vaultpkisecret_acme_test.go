@@ -0,0 +1,458 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+func newTestACMEClient(t *testing.T) *acme.Client {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &acme.Client{Key: key}
+}
+
+func TestACMEHTTP01Solver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ac := newTestACMEClient(t)
+	chal := &acme.Challenge{Type: acmeSolverHTTP01, Token: "token"}
+	keyAuth, err := ac.HTTP01ChallengeResponse(chal.Token)
+	require.NoError(t, err)
+
+	s := &ACMEHTTP01Solver{}
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/.well-known/acme-challenge/token").Code)
+
+	require.NoError(t, s.Present(ctx, nil, ac, "example.com", chal))
+	w := serve(http.MethodGet, "/.well-known/acme-challenge/token")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, keyAuth, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/.well-known/acme-challenge/other").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/token").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/.well-known/acme-challenge/token").Code)
+
+	require.NoError(t, s.CleanUp(ctx, nil, "example.com", chal))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/.well-known/acme-challenge/token").Code)
+}
+
+func Test_acmeDNS01Solver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ac := newTestACMEClient(t)
+	chal := &acme.Challenge{Type: acmeSolverDNS01, Token: "token"}
+	record, err := ac.DNS01ChallengeRecord(chal.Token)
+	require.NoError(t, err)
+
+	o := &secretsv1beta1.VaultPKISecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pki",
+			Namespace: "default",
+			UID:       "uid",
+		},
+	}
+	c := testutils.NewFakeClientBuilder().Build()
+	s := &acmeDNS01Solver{
+		client: c,
+		scheme: c.Scheme(),
+	}
+
+	require.NoError(t, s.Present(ctx, o, ac, "example.com", chal))
+	// presenting again updates the existing DNSEndpoint
+	require.NoError(t, s.Present(ctx, o, ac, "example.com", chal))
+
+	got := newDNSEndpoint(o, "example.com")
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(got), got))
+	endpoints, _, err := unstructured.NestedSlice(got.Object, "spec", "endpoints")
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{
+			"dnsName":    "_acme-challenge.example.com",
+			"recordType": "TXT",
+			"recordTTL":  int64(acmeDNS01RecordTTL),
+			"targets":    []any{record},
+		},
+	}, endpoints)
+	if assert.Len(t, got.GetOwnerReferences(), 1) {
+		assert.Equal(t, o.Name, got.GetOwnerReferences()[0].Name)
+	}
+
+	require.NoError(t, s.CleanUp(ctx, o, "example.com", chal))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(got), got)))
+	// cleaning up again is a no-op
+	require.NoError(t, s.CleanUp(ctx, o, "example.com", chal))
+}
+
+func Test_newDNSEndpoint(t *testing.T) {
+	t.Parallel()
+
+	o := &secretsv1beta1.VaultPKISecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pki",
+			Namespace: "default",
+		},
+	}
+
+	got := newDNSEndpoint(o, "example.com")
+	assert.Equal(t, dnsEndpointGVK, got.GroupVersionKind())
+	assert.Equal(t, "default", got.GetNamespace())
+	assert.Regexp(t, `^pki-acme-[0-9a-f]{10}$`, got.GetName())
+	assert.Equal(t, got.GetName(), newDNSEndpoint(o, "example.com").GetName())
+	assert.NotEqual(t, got.GetName(), newDNSEndpoint(o, "www.example.com").GetName())
+}
+
+func TestVaultPKISecretReconciler_acmeSolver(t *testing.T) {
+	t.Parallel()
+
+	r := &VaultPKISecretReconciler{}
+	_, err := r.acmeSolver(acmeSolverHTTP01)
+	assert.ErrorIs(t, err, errACMEHTTP01SolverDisabled)
+
+	r.ACMEHTTP01Solver = &ACMEHTTP01Solver{}
+	for _, solverType := range []string{"", acmeSolverHTTP01} {
+		got, err := r.acmeSolver(solverType)
+		require.NoError(t, err)
+		assert.Equal(t, r.ACMEHTTP01Solver, got)
+	}
+
+	got, err := r.acmeSolver(acmeSolverDNS01)
+	require.NoError(t, err)
+	assert.IsType(t, &acmeDNS01Solver{}, got)
+
+	_, err = r.acmeSolver("tls-alpn-01")
+	assert.EqualError(t, err, `unsupported ACME solver "tls-alpn-01"`)
+}
+
+func TestVaultPKISecretReconciler_getACMEAccountKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultPKISecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pki",
+			Namespace: "default",
+		},
+		Spec: secretsv1beta1.VaultPKISecretSpec{
+			ACME: &secretsv1beta1.VaultPKISecretACME{
+				AccountKeySecretRef: "acme-account",
+			},
+		},
+	}
+	secretKey := client.ObjectKey{Namespace: "default", Name: "acme-account"}
+
+	t.Run("create", func(t *testing.T) {
+		r := &VaultPKISecretReconciler{
+			Client: testutils.NewFakeClientBuilder().Build(),
+		}
+
+		key, err := r.getACMEAccountKey(ctx, o)
+		require.NoError(t, err)
+
+		var s corev1.Secret
+		require.NoError(t, r.Client.Get(ctx, secretKey, &s))
+		assert.Contains(t, s.Data, acmeAccountKeySecretKey)
+
+		// the stored key is reused
+		got, err := r.getACMEAccountKey(ctx, o)
+		require.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
+
+	t.Run("existing-pkcs8", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+
+		r := &VaultPKISecretReconciler{
+			Client: testutils.NewFakeClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: secretKey.Namespace,
+					Name:      secretKey.Name,
+				},
+				Data: map[string][]byte{
+					acmeAccountKeySecretKey: pem.EncodeToMemory(&pem.Block{
+						Type:  "PRIVATE KEY",
+						Bytes: der,
+					}),
+				},
+			}).Build(),
+		}
+
+		got, err := r.getACMEAccountKey(ctx, o)
+		require.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := &VaultPKISecretReconciler{
+			Client: testutils.NewFakeClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: secretKey.Namespace,
+					Name:      secretKey.Name,
+				},
+				Data: map[string][]byte{
+					acmeAccountKeySecretKey: []byte("invalid"),
+				},
+			}).Build(),
+		}
+
+		_, err := r.getACMEAccountKey(ctx, o)
+		assert.EqualError(t, err,
+			`no ACME account key found in secret default/acme-account, key="account.key"`)
+	})
+}
+
+func Test_acmeDirectoryPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		spec secretsv1beta1.VaultPKISecretSpec
+		want string
+	}{
+		{
+			name: "default",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount: "pki",
+				Role:  "web",
+				ACME:  &secretsv1beta1.VaultPKISecretACME{},
+			},
+			want: "pki/roles/web/acme/directory",
+		},
+		{
+			name: "directory-path",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount: "pki",
+				Role:  "web",
+				ACME: &secretsv1beta1.VaultPKISecretACME{
+					DirectoryPath: "issuer/default/acme/directory",
+				},
+			},
+			want: "pki/issuer/default/acme/directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, acmeDirectoryPath(tt.spec))
+		})
+	}
+}
+
+func Test_newACMECSRTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    secretsv1beta1.VaultPKISecretSpec
+		wantDNS []string
+		wantIPs []net.IP
+		wantErr string
+	}{
+		{
+			name: "common-name-added",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CommonName: "example.com",
+				AltNames:   []string{"www.example.com"},
+				IPSans:     []string{"192.0.2.1"},
+			},
+			wantDNS: []string{"example.com", "www.example.com"},
+			wantIPs: []net.IP{net.ParseIP("192.0.2.1")},
+		},
+		{
+			name: "common-name-in-alt-names",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CommonName: "www.example.com",
+				AltNames:   []string{"example.com", "www.example.com"},
+			},
+			wantDNS: []string{"example.com", "www.example.com"},
+		},
+		{
+			name: "ip-common-name",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CommonName: "192.0.2.1",
+			},
+			wantIPs: []net.IP{net.ParseIP("192.0.2.1")},
+		},
+		{
+			name: "email-alt-name",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CommonName: "example.com",
+				AltNames:   []string{"admin@example.com"},
+			},
+			wantErr: "email and URI SANs are not supported with ACME",
+		},
+		{
+			name:    "no-identifiers",
+			spec:    secretsv1beta1.VaultPKISecretSpec{},
+			wantErr: "at least one of CommonName, AltNames, or IPSans is required with ACME",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newACMECSRTemplate(tt.spec)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDNS, got.DNSNames)
+			assert.Equal(t, len(tt.wantIPs), len(got.IPAddresses))
+			for i, ip := range tt.wantIPs {
+				assert.True(t, ip.Equal(got.IPAddresses[i]))
+			}
+		})
+	}
+}
+
+func Test_newACMEPKISecret(t *testing.T) {
+	t.Parallel()
+
+	root, rootKey := newTestCACertificate(t, "root", nil, nil)
+	intermediate, intermediateKey := newTestCACertificate(t, "intermediate", root, rootKey)
+	leaf, _ := newTestCACertificate(t, "leaf", intermediate, intermediateKey)
+	chain := [][]byte{leaf.Raw, intermediate.Raw}
+
+	t.Run("pem", func(t *testing.T) {
+		secret, err := newACMEPKISecret(chain, "")
+		require.NoError(t, err)
+
+		got, err := vault.UnmarshalPKIIssueResponse(secret)
+		require.NoError(t, err)
+		assert.Equal(t, encodeTestCertificate(leaf), got.Certificate)
+		assert.Equal(t, encodeTestCertificate(intermediate), got.IssuingCa)
+		assert.Equal(t, []string{encodeTestCertificate(intermediate)}, got.CAChain)
+		assert.Equal(t, "01", got.SerialNumber)
+		assert.Equal(t, leaf.NotAfter.Unix(), got.Expiration)
+		notAfter, err := got.NotAfter()
+		require.NoError(t, err)
+		assert.Equal(t, leaf.NotAfter.Unix(), notAfter.Unix())
+	})
+
+	t.Run("der", func(t *testing.T) {
+		secret, err := newACMEPKISecret(chain[:1], "der")
+		require.NoError(t, err)
+
+		got, err := vault.UnmarshalPKIIssueResponse(secret)
+		require.NoError(t, err)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(leaf.Raw), got.Certificate)
+		assert.Empty(t, got.IssuingCa)
+		assert.Empty(t, got.CAChain)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := newACMEPKISecret(nil, "")
+		assert.EqualError(t, err, "empty certificate chain from the ACME server")
+	})
+}
+
+func Test_formatCertSerialNumber(t *testing.T) {
+	t.Parallel()
+
+	n, ok := new(big.Int).SetString("3a0f0001ff", 16)
+	require.True(t, ok)
+	assert.Equal(t, "3a:0f:00:01:ff", formatCertSerialNumber(n))
+	assert.Equal(t, "01", formatCertSerialNumber(big.NewInt(1)))
+}
+
+// stubWriteVaultClient records the path of every write, and returns data.
+type stubWriteVaultClient struct {
+	vault.Client
+	data   map[string]any
+	writes []string
+}
+
+func (c *stubWriteVaultClient) Write(_ context.Context, req vault.WriteRequest) (vault.Response, error) {
+	c.writes = append(c.writes, req.Path())
+	return vault.NewDefaultResponse(&api.Secret{Data: c.data}), nil
+}
+
+func Test_newACMEExternalAccountBinding(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    *acme.ExternalAccountBinding
+		wantErr string
+	}{
+		{
+			name: "valid",
+			data: map[string]any{
+				"id":       "kid",
+				"key":      base64.RawURLEncoding.EncodeToString([]byte("hmac-key")),
+				"key_type": "hs",
+			},
+			want: &acme.ExternalAccountBinding{
+				KID: "kid",
+				Key: []byte("hmac-key"),
+			},
+		},
+		{
+			name: "padded-key",
+			data: map[string]any{
+				"id":  "kid",
+				"key": base64.URLEncoding.EncodeToString([]byte("hmac-key")),
+			},
+			want: &acme.ExternalAccountBinding{
+				KID: "kid",
+				Key: []byte("hmac-key"),
+			},
+		},
+		{
+			name:    "missing-id",
+			data:    map[string]any{"key": "a2V5"},
+			wantErr: "invalid ACME external account binding response from Vault",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &stubWriteVaultClient{data: tt.data}
+			got, err := newACMEExternalAccountBinding(ctx, c, "pki/roles/web/acme/directory")
+			assert.Equal(t, []string{"pki/roles/web/acme/new-eab"}, c.writes)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
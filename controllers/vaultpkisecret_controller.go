@@ -6,11 +6,18 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"maps"
+	"net"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
 	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
@@ -36,6 +44,17 @@ import (
 
 const vaultPKIFinalizer = "vaultpkisecrets.secrets.hashicorp.com/finalizer"
 
+const (
+	pkiCSRModeSign  = "sign"
+	pkiCSRSecretKey = "tls.csr"
+)
+
+const (
+	pkiChainOrderLeafChain = "leaf-chain"
+	pkiChainOrderLeaf      = "leaf"
+	pkiChainOrderRootCA    = "root-ca"
+)
+
 var minHorizon = time.Second * 1
 
 // VaultPKISecretReconciler reconciles a VaultPKISecret object
@@ -47,8 +66,69 @@ type VaultPKISecretReconciler struct {
 	Recorder                    record.EventRecorder
 	SyncRegistry                *SyncRegistry
 	BackOffRegistry             *BackOffRegistry
+	SyncStatusRegistry          *SyncStatusRegistry
 	referenceCache              ResourceReferenceCache
 	GlobalTransformationOptions *helpers.GlobalTransformationOptions
+	// Shedder defers the reconciliation of low priority resources under a large
+	// backlog, it is nil if load shedding is not enabled.
+	Shedder *ReconcileShedder
+	// FreezeWindow defers non-critical secret rotations and rollout-restarts
+	// during the freeze window, it is nil if no freeze window is configured.
+	FreezeWindow *FreezeWindow
+	// VaultPathPolicy restricts the Vault paths that the resources may
+	// reference, it is nil if no paths are restricted.
+	VaultPathPolicy *common.VaultPathPolicy
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// StartupSyncSmear spreads the initial reconciliation of the resources over
+	// a window after the operator starts, it is nil if smearing is not enabled.
+	StartupSyncSmear *StartupSyncSmear
+	// ACMEHTTP01Solver serves the HTTP-01 challenges of ACME orders, it is nil if
+	// the solver is not enabled.
+	ACMEHTTP01Solver *ACMEHTTP01Solver
+	// pendingDestinations holds the certificates that are yet to be synced to
+	// all additional destinations.
+	pendingDestinations pkiPendingDestinations
+}
+
+// pkiIssuedCertificate is a certificate issued for a VaultPKISecret.
+type pkiIssuedCertificate struct {
+	resp     vault.Response
+	certResp *vault.PKICertResponse
+}
+
+// pkiPendingDestinations maps a VaultPKISecret to the certificate that failed
+// to sync to some of its additional destinations. The zero value is ready to
+// use.
+type pkiPendingDestinations struct {
+	m  map[client.ObjectKey]*pkiIssuedCertificate
+	mu sync.Mutex
+}
+
+func (p *pkiPendingDestinations) get(objKey client.ObjectKey) (*pkiIssuedCertificate, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cert, ok := p.m[objKey]
+	return cert, ok
+}
+
+func (p *pkiPendingDestinations) set(objKey client.ObjectKey, cert *pkiIssuedCertificate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.m == nil {
+		p.m = make(map[client.ObjectKey]*pkiIssuedCertificate)
+	}
+	p.m[objKey] = cert
+}
+
+func (p *pkiPendingDestinations) delete(objKey client.ObjectKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.m, objKey)
 }
 
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultpkisecrets,verbs=get;list;watch;create;update;patch;delete
@@ -62,6 +142,11 @@ type VaultPKISecretReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;patch
+//
+// required for ACME DNS-01 challenges
+// +kubebuilder:rbac:groups=externaldns.k8s.io,resources=dnsendpoints,verbs=get;create;update;delete
 //
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -72,9 +157,16 @@ type VaultPKISecretReconciler struct {
 func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(VaultPKISecret, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
 	o := &secretsv1beta1.VaultPKISecret{}
 	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(VaultPKISecret, req.NamespacedName)
 			logger.V(consts.LogLevelDebug).Info("VaultPKISecret resource not found", "req", req)
 			return ctrl.Result{}, nil
 		}
@@ -88,6 +180,49 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, r.handleDeletion(ctx, o)
 	}
 
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil || suspended {
+		return ctrl.Result{}, err
+	}
+
+	if denied, err := handleVaultPathPolicy(ctx, r.Client, o, r.VaultPathPolicy, r.Recorder); err != nil || denied {
+		return ctrl.Result{}, err
+	}
+
+	if deferAfter, ok := r.Shedder.Shed(ctx, VaultPKISecret, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	if deferAfter, ok := r.StartupSyncSmear.Defer(ctx, VaultPKISecret, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	pendingAfter, err := r.FreezeWindow.HandlePending(ctx, r.Client, r.HMACValidator, o, r.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := validatePKIDestinations(o); err != nil {
+		o.Status.Error = consts.ReasonInvalidConfiguration
+		msg := "Invalid destinations"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// the additional destinations of the current certificate failed to sync,
+	// they are retried without issuing a new certificate, unless o was updated
+	// since.
+	if cert, ok := r.pendingDestinations.get(req.NamespacedName); ok {
+		if cert.certResp.SerialNumber == o.Status.SerialNumber && o.GetGeneration() == o.Status.LastGeneration {
+			logger.Info("Retrying the sync of the additional destinations")
+			return r.syncIssuedCertificate(ctx, o, cert, consts.ReasonSecretSynced, pendingAfter)
+		}
+		r.pendingDestinations.delete(req.NamespacedName)
+	}
+
 	path := r.getPath(o.Spec)
 	destinationExists, _ := helpers.CheckSecretExists(ctx, r.Client, o)
 	// In the case where the secret should exist already, check that it does
@@ -132,6 +267,9 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			"create", o.Spec.Clear,
 			"destination", o.Spec.Destination.Name)
 		syncReason = consts.ReasonInexistentDestination
+	case hasInexistentPKIDestinations(ctx, r.Client, o):
+		logger.Info("Additional destination secret does not exist")
+		syncReason = consts.ReasonInexistentDestination
 	case destinationExists:
 		if schemaEpoch > 0 {
 			if matched, err := helpers.HMACDestinationSecret(ctx, r.Client,
@@ -143,9 +281,12 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	r.referenceCache.Set(SecretTransformation, req.NamespacedName,
-		helpers.GetTransformationRefObjKeys(
-			o.Spec.Destination.Transformation, o.Namespace)...)
+	var transformationRefs []client.ObjectKey
+	for _, dest := range append([]secretsv1beta1.Destination{o.Spec.Destination}, o.Spec.Destinations...) {
+		transformationRefs = append(transformationRefs, helpers.GetTransformationRefObjKeys(
+			dest.Transformation, o.Namespace, r.GlobalTransformationOptions)...)
+	}
+	r.referenceCache.Set(SecretTransformation, req.NamespacedName, transformationRefs...)
 
 	transOption, err := helpers.NewSecretTransformationOption(ctx, r.Client, o, r.GlobalTransformationOptions)
 	if err != nil {
@@ -160,16 +301,53 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		if !inWindow {
 			logger.Info("Not in renewal window", "horizon", horizon)
 			return ctrl.Result{
-				RequeueAfter: horizon,
+				RequeueAfter: minRequeueAfter(horizon, pendingAfter),
 			}, nil
 		} else {
 			syncReason = consts.ReasonInRenewalWindow
 		}
+
+		if deferAfter, ok := r.FreezeWindow.DeferRotation(
+			ctx, r.Client, VaultPKISecret, o, pkiCertificateExpiry(o), r.Recorder); ok {
+			return ctrl.Result{RequeueAfter: minRequeueAfter(deferAfter, pendingAfter)}, nil
+		}
 	}
 
 	// assume that status is always invalid
 	o.Status.Valid = ptr.To(false)
 	logger.Info("Must sync", "reason", syncReason)
+
+	params := o.GetIssuerAPIData()
+	var privateKey *vault.PKIPrivateKey
+	if o.Spec.CSR != nil && o.Spec.ACME != nil {
+		o.Status.Error = consts.ReasonCertificateRequestError
+		msg := "CSR and ACME are mutually exclusive"
+		logger.Error(nil, msg)
+		r.recordEvent(o, o.Status.Error, msg)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	} else if o.Spec.CSR != nil {
+		var csr string
+		csr, privateKey, err = r.getCSR(ctx, o)
+		if err != nil {
+			o.Status.Error = consts.ReasonCertificateRequestError
+			msg := "Failed to get the certificate signing request"
+			logger.Error(err, msg)
+			r.recordEvent(o, o.Status.Error, msg+": %s", err)
+			if err := r.updateStatus(ctx, o); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{
+				RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+			}, nil
+		}
+		params["csr"] = csr
+		// the private key is never returned when signing
+		delete(params, "private_key_format")
+	}
+
 	c, err := r.ClientFactory.Get(ctx, r.Client, o)
 	if err != nil {
 		o.Status.Error = consts.ReasonK8sClientError
@@ -179,8 +357,17 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}, nil
 	}
 
-	resp, err := c.Write(ctx, vault.NewWriteRequest(path, o.GetIssuerAPIData()))
+	var resp vault.Response
+	if o.Spec.ACME != nil {
+		resp, privateKey, err = r.issueACMECertificate(ctx, c, o)
+	} else {
+		resp, err = c.Write(ctx, vault.NewWriteRequest(path, params))
+	}
 	if err != nil {
+		if horizon, ok := handleThrottled(ctx, r.Client, o, r.Recorder, err); ok {
+			r.SyncRegistry.Add(req.NamespacedName)
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
 		if vault.IsForbiddenError(err) {
 			c.Taint()
 		}
@@ -195,10 +382,10 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		r.SyncRegistry.Add(req.NamespacedName)
 		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
 		return ctrl.Result{
-			RequeueAfter: entry.NextBackOff(),
+			RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
 		}, nil
 	} else {
-		r.BackOffRegistry.Delete(req.NamespacedName)
+		resetBackOff(r.BackOffRegistry, o)
 	}
 
 	certResp, err := vault.UnmarshalPKIIssueResponse(resp.Secret())
@@ -228,7 +415,27 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}, nil
 	}
 
+	if privateKey != nil {
+		// include the locally generated private key, as if it had been issued by Vault.
+		pk, err := privateKey.Marshal(o.Spec.Format, o.Spec.PrivateKeyFormat)
+		if err != nil {
+			o.Status.Error = consts.ReasonCertificateRequestError
+			msg := "Failed to marshal the private key"
+			logger.Error(err, msg)
+			r.recordEvent(o, o.Status.Error, msg+": %s", err)
+			if err := r.updateStatus(ctx, o); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{
+				RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+			}, nil
+		}
+		resp.Secret().Data["private_key"] = pk
+		resp.Secret().Data["private_key_type"] = privateKey.Type()
+	}
+
 	data, err := resp.SecretK8sData(transOption)
+	renderErr, err := handleTemplateRenderError(ctx, r.Client, o, data, err)
 	if err != nil {
 		o.Status.Error = consts.ReasonK8sClientError
 		msg := "Failed to marshal Vault secret data"
@@ -241,15 +448,13 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
 		}, nil
 	}
-
-	// Fix ca_chain formatting since it's a slice
-	if len(data["ca_chain"]) > 0 {
-		data["ca_chain"] = []byte(strings.Join(certResp.CAChain, "\n"))
-	}
-	// If using data transformation (templates), avoid generating tls.key and tls.crt.
-	if o.Spec.Destination.Type == corev1.SecretTypeTLS && len(transOption.KeyedTemplates) == 0 {
-		data = convertToK8sTLSSecretData(data)
+	if renderErr != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values for keys that failed to render: %s", renderErr)
 	}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), transOption, renderErr)
+
+	data = pkiSecretData(data, certResp, o.Spec.Destination, transOption)
 
 	if b, err := json.Marshal(data); err == nil {
 		newMAC, err := r.HMACValidator.HMAC(ctx, r.Client, b)
@@ -266,7 +471,12 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		o.Status.SecretMAC = base64.StdEncoding.EncodeToString(newMAC)
 	}
 
-	if err := helpers.SyncSecret(ctx, r.Client, o, data); err != nil {
+	opts := helpers.DefaultSyncOptions()
+	opts.Metadata = pkiSecretMetadata(certResp)
+	opts.HMACValidator = r.HMACValidator
+	err = helpers.SyncSecret(ctx, r.Client, o, data, opts)
+	handleSecretDataError(ctx, r.Client, o, err)
+	if err != nil {
 		logger.Error(err, "Sync secret")
 		o.Status.Error = consts.ReasonSecretSyncError
 		if err := r.updateStatus(ctx, o); err != nil {
@@ -280,9 +490,8 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	reason := consts.ReasonSecretSynced
 	if o.Status.SerialNumber != "" {
 		reason = consts.ReasonSecretRotated
-		// rollout-restart errors are not retryable
-		// all error reporting is handled by helpers.HandleRolloutRestarts
-		_ = helpers.HandleRolloutRestarts(ctx, r.Client, o, r.Recorder)
+		pendingAfter = minRequeueAfter(pendingAfter,
+			r.FreezeWindow.HandleRolloutRestarts(ctx, r.Client, r.HMACValidator, VaultPKISecret, o, r.Recorder))
 	}
 
 	// revoke the certificate on renewal
@@ -296,29 +505,201 @@ func (r *VaultPKISecretReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	o.Status.Valid = ptr.To(true)
-	o.Status.Error = ""
+	// the certificate is recorded before it is synced to the additional
+	// destinations, so that it is never issued again if that fails.
 	o.Status.SerialNumber = certResp.SerialNumber
 	o.Status.Expiration = certResp.Expiration
+	o.Status.NotAfter = 0
+	if notAfter, err := certResp.NotAfter(); err != nil {
+		logger.Info("Warning: failed to parse the certificate's NotAfter, "+
+			"renewal will be based on the expiration reported by Vault", "err", err)
+	} else {
+		o.Status.NotAfter = notAfter.Unix()
+	}
 	o.Status.LastRotation = time.Now().Unix()
+	r.SyncRegistry.Delete(req.NamespacedName)
+
+	return r.syncIssuedCertificate(ctx, o, &pkiIssuedCertificate{
+		resp:     resp,
+		certResp: certResp,
+	}, reason, pendingAfter)
+}
+
+// syncIssuedCertificate syncs cert to the additional destinations of o, and
+// updates o's status. The certificate must already be synced to o's
+// Spec.Destination, and recorded in its status. If any additional destination
+// fails to sync, cert is retained, so that the next reconciliation only retries
+// the additional destinations.
+func (r *VaultPKISecretReconciler) syncIssuedCertificate(ctx context.Context, o *secretsv1beta1.VaultPKISecret,
+	cert *pkiIssuedCertificate, reason string, pendingAfter time.Duration,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	objKey := client.ObjectKeyFromObject(o)
+	if err := r.syncDestinations(ctx, o, cert.resp, cert.certResp); err != nil {
+		r.pendingDestinations.set(objKey, cert)
+		logger.Error(err, "Sync additional destinations")
+		o.Status.Valid = ptr.To(false)
+		o.Status.Error = consts.ReasonSecretSyncError
+		r.recordEvent(o, o.Status.Error, "Failed to sync additional destinations: %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+	r.pendingDestinations.delete(objKey)
+
+	o.Status.Valid = ptr.To(true)
+	o.Status.Error = ""
 	if err := r.updateStatus(ctx, o); err != nil {
 		logger.Error(err, "Failed to update the status")
 		return ctrl.Result{}, err
 	}
 
-	r.SyncRegistry.Delete(req.NamespacedName)
-
 	horizon, _ := computePKIRenewalWindow(ctx, o, .05)
 	r.recordEvent(o, reason, fmt.Sprintf("Secret synced, horizon=%s", horizon))
 	logger.Info("Successfully updated the secret", "horizon", horizon)
 	return ctrl.Result{
-		RequeueAfter: horizon,
+		RequeueAfter: minRequeueAfter(horizon, pendingAfter),
 	}, nil
 }
 
+// syncDestinations syncs the certificate issued for o to each of its
+// additional destinations. Returns all errors encountered.
+func (r *VaultPKISecretReconciler) syncDestinations(ctx context.Context, o *secretsv1beta1.VaultPKISecret,
+	resp vault.Response, certResp *vault.PKICertResponse,
+) error {
+	var errs error
+	for i := range o.Spec.Destinations {
+		dest := &o.Spec.Destinations[i]
+		if err := r.syncDestination(ctx, o, dest, resp, certResp); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("destination %q: %w", dest.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// syncDestination syncs the certificate issued for o to dest, applying dest's
+// own transformation. Keys that failed to render retain their previous values,
+// as they do for o's Spec.Destination.
+func (r *VaultPKISecretReconciler) syncDestination(ctx context.Context, o *secretsv1beta1.VaultPKISecret,
+	dest *secretsv1beta1.Destination, resp vault.Response, certResp *vault.PKICertResponse,
+) error {
+	opt, err := helpers.NewDestinationTransformationOption(ctx, r.Client, o, dest, r.GlobalTransformationOptions)
+	if err != nil {
+		return err
+	}
+
+	data, err := resp.SecretK8sData(opt)
+	var renderErr *helpers.TemplateRenderError
+	if errors.As(err, &renderErr) {
+		current, err := helpers.GetSecret(ctx, r.Client,
+			client.ObjectKey{Namespace: o.Namespace, Name: dest.Name})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			current = nil
+		}
+		retainSecretData(current, data, renderErr.Keys())
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values of destination %q for keys that failed to render: %s",
+			dest.Name, renderErr)
+	} else if err != nil {
+		return err
+	}
+
+	return helpers.SyncSecret(ctx, r.Client, o, pkiSecretData(data, certResp, *dest, opt),
+		helpers.SyncOptions{Destination: dest, Metadata: pkiSecretMetadata(certResp), HMACValidator: r.HMACValidator})
+}
+
+// pkiSecretMetadata returns the metadata of the issued certificate, that the
+// destination's label and annotation templates are rendered with.
+func pkiSecretMetadata(certResp *vault.PKICertResponse) map[string]any {
+	return map[string]any{
+		"serial_number": certResp.SerialNumber,
+		"expiration":    certResp.Expiration,
+	}
+}
+
+// validatePKIDestinations ensures that the names of all of o's destinations
+// are unique.
+func validatePKIDestinations(o *secretsv1beta1.VaultPKISecret) error {
+	seen := map[string]bool{
+		o.Spec.Destination.Name: true,
+	}
+	for _, dest := range o.Spec.Destinations {
+		if dest.Name == "" {
+			return fmt.Errorf("destination name cannot be empty")
+		}
+		if seen[dest.Name] {
+			return fmt.Errorf("duplicate destination name %q", dest.Name)
+		}
+		seen[dest.Name] = true
+	}
+
+	return nil
+}
+
+// hasInexistentPKIDestinations returns true if any of o's additional
+// destinations that should be created does not exist.
+func hasInexistentPKIDestinations(ctx context.Context, c client.Client, o *secretsv1beta1.VaultPKISecret) bool {
+	for _, dest := range o.Spec.Destinations {
+		if !dest.Create {
+			continue
+		}
+		if _, err := helpers.GetSecret(ctx, c,
+			client.ObjectKey{Namespace: o.Namespace, Name: dest.Name}); apierrors.IsNotFound(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pkiSecretData returns the K8s Secret data for dest from the data of the
+// Vault PKI response.
+func pkiSecretData(data map[string][]byte, certResp *vault.PKICertResponse,
+	dest secretsv1beta1.Destination, opt *helpers.SecretTransformationOption,
+) map[string][]byte {
+	// Fix ca_chain formatting since it's a slice
+	if len(data["ca_chain"]) > 0 {
+		data["ca_chain"] = []byte(strings.Join(certResp.CAChain, "\n"))
+	}
+	// If using data transformation (templates), avoid generating tls.key and tls.crt.
+	if dest.Type == corev1.SecretTypeTLS && len(opt.KeyedTemplates) == 0 {
+		if dest.ChainOrder == "" {
+			data = convertToK8sTLSSecretData(data)
+		} else {
+			data = convertToK8sTLSSecretDataWithChainOrder(data, certResp, dest.ChainOrder)
+		}
+	}
+
+	return data
+}
+
+// pkiCertificateExpiry returns the expiry of o's current certificate, it is
+// zero if the expiry is unknown.
+func pkiCertificateExpiry(o *secretsv1beta1.VaultPKISecret) time.Time {
+	if o.Status.NotAfter > 0 {
+		return time.Unix(o.Status.NotAfter, 0)
+	}
+	if o.Status.Expiration > 0 {
+		return time.Unix(o.Status.Expiration, 0)
+	}
+	return time.Time{}
+}
+
 func (r *VaultPKISecretReconciler) handleDeletion(ctx context.Context, o *secretsv1beta1.VaultPKISecret) error {
+	if err := finalizeDestinationSecrets(ctx, r.Client, r.Recorder, o, vaultPKIFinalizer); err != nil {
+		return err
+	}
+
 	objKey := client.ObjectKeyFromObject(o)
 	r.SyncRegistry.Delete(objKey)
+	r.pendingDestinations.delete(objKey)
 	r.BackOffRegistry.Delete(objKey)
 
 	r.referenceCache.Remove(SecretTransformation, objKey)
@@ -346,6 +727,7 @@ func (r *VaultPKISecretReconciler) handleDeletion(ctx context.Context, o *secret
 }
 
 func (r *VaultPKISecretReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(VaultPKISecret, r.Recorder)
 	r.referenceCache = newResourceReferenceCache()
 	if r.BackOffRegistry == nil {
 		r.BackOffRegistry = NewBackOffRegistry()
@@ -370,7 +752,19 @@ func (r *VaultPKISecretReconciler) SetupWithManager(mgr ctrl.Manager, opts contr
 			},
 			builder.WithPredicates(&secretsPredicate{}),
 		).
-		Complete(r)
+		// Enforced destination Secrets are resynced as soon as their data drifts, the
+		// data is only fetched for the Secrets that were not updated by the operator.
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDriftRequestHandler{
+				client:    r.Client,
+				validator: r.HMACValidator,
+				recorder:  r.Recorder,
+				kind:      VaultPKISecret,
+			},
+			builder.WithPredicates(&secretsDriftPredicate{}),
+		).
+		Complete(r.SyncStatusRegistry.Reconciler(VaultPKISecret, r))
 }
 
 func (r *VaultPKISecretReconciler) finalizePKI(ctx context.Context, l logr.Logger, s *secretsv1beta1.VaultPKISecret) error {
@@ -413,7 +807,16 @@ func (r *VaultPKISecretReconciler) revokeCertificate(ctx context.Context, l logr
 
 func (r *VaultPKISecretReconciler) getPath(spec secretsv1beta1.VaultPKISecretSpec) string {
 	parts := []string{spec.Mount}
-	if spec.IssuerRef != "" {
+	if spec.CSR != nil {
+		if spec.IssuerRef != "" {
+			parts = append(parts, "issuer", spec.IssuerRef)
+		}
+		mode := spec.CSR.Mode
+		if mode == "" {
+			mode = pkiCSRModeSign
+		}
+		parts = append(parts, mode)
+	} else if spec.IssuerRef != "" {
 		parts = append(parts, "issuer", spec.IssuerRef)
 	} else {
 		parts = append(parts, "issue")
@@ -423,6 +826,57 @@ func (r *VaultPKISecretReconciler) getPath(spec secretsv1beta1.VaultPKISecretSpe
 	return strings.Join(parts, "/")
 }
 
+// getCSR returns the PEM encoded CSR to be signed by Vault. If the CSR is not
+// provided by a Secret, a new private key is generated, and returned along with
+// its CSR.
+func (r *VaultPKISecretReconciler) getCSR(ctx context.Context, o *secretsv1beta1.VaultPKISecret) (string, *vault.PKIPrivateKey, error) {
+	spec := o.Spec.CSR
+	if spec.SecretRef != "" {
+		key := spec.SecretKey
+		if key == "" {
+			key = pkiCSRSecretKey
+		}
+
+		s, err := helpers.GetSecret(ctx, r.Client, client.ObjectKey{
+			Namespace: o.Namespace,
+			Name:      spec.SecretRef,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+
+		b, ok := s.Data[key]
+		if !ok || len(b) == 0 {
+			return "", nil, fmt.Errorf("no CSR found in secret %s/%s, key=%q",
+				s.Namespace, s.Name, key)
+		}
+
+		if block, _ := pem.Decode(b); block == nil || block.Type != "CERTIFICATE REQUEST" {
+			return "", nil, fmt.Errorf("invalid CSR in secret %s/%s, key=%q",
+				s.Namespace, s.Name, key)
+		}
+
+		return string(b), nil, nil
+	}
+
+	template, err := newPKICSRTemplate(o.Spec)
+	if err != nil {
+		return "", nil, err
+	}
+
+	privateKey, err := vault.GeneratePKIPrivateKey(spec.KeyType, spec.KeyBits)
+	if err != nil {
+		return "", nil, err
+	}
+
+	csr, err := privateKey.CSR(template)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return csr, privateKey, nil
+}
+
 func (r *VaultPKISecretReconciler) recordEvent(o *secretsv1beta1.VaultPKISecret, reason, msg string, i ...interface{}) {
 	eventType := corev1.EventTypeNormal
 	if !ptr.Deref(o.Status.Valid, false) {
@@ -450,22 +904,76 @@ func (r *VaultPKISecretReconciler) updateStatus(ctx context.Context, o *secretsv
 	return err
 }
 
+// newPKICSRTemplate returns a CSR template for the subject and SANs from spec.
+// The template is only relevant for the "sign-verbatim" mode, since the request
+// parameters otherwise take precedence.
+func newPKICSRTemplate(spec secretsv1beta1.VaultPKISecretSpec) (*x509.CertificateRequest, error) {
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName: spec.CommonName,
+		},
+	}
+
+	for _, name := range spec.AltNames {
+		if strings.Contains(name, "@") {
+			template.EmailAddresses = append(template.EmailAddresses, name)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	for _, v := range spec.IPSans {
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP SAN %q", v)
+		}
+		template.IPAddresses = append(template.IPAddresses, ip)
+	}
+
+	for _, v := range spec.URISans {
+		u, err := url.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI SAN %q: %w", v, err)
+		}
+		template.URIs = append(template.URIs, u)
+	}
+
+	return template, nil
+}
+
+// computeExpirationTimePKI returns the time at which the certificate should be
+// renewed. The earlier of the certificate's NotAfter and the expiration reported
+// by Vault is used, since the latter may not account for clock drift or Vault
+// side TTL capping.
 func computeExpirationTimePKI(o *secretsv1beta1.VaultPKISecret, offset int64) time.Time {
-	return time.Unix(o.Status.Expiration-offset, 0)
+	expiration := o.Status.Expiration
+	if o.Status.NotAfter > 0 && (expiration <= 0 || o.Status.NotAfter < expiration) {
+		expiration = o.Status.NotAfter
+	}
+	return time.Unix(expiration-offset, 0)
 }
 
 func computePKIRenewalWindow(ctx context.Context, o *secretsv1beta1.VaultPKISecret,
 	jitterPercent float64,
 ) (time.Duration, bool) {
-	logger := log.FromContext(ctx).WithValues("expiryOffset", o.Spec.ExpiryOffset)
+	logger := log.FromContext(ctx).WithValues(
+		"expiryOffset", o.Spec.ExpiryOffset, "renewBefore", o.Spec.RenewBefore)
 	if o.Status.LastRotation > 0 {
 		// TODO: factor out lastRotation when we add support for spec.renewalPercent
 		logger = logger.WithValues("lastRotation", time.Unix(o.Status.LastRotation, 0))
 	}
+	if o.Status.NotAfter > 0 {
+		logger = logger.WithValues("notAfter", time.Unix(o.Status.NotAfter, 0))
+	}
 
-	offset, err := parseDurationString(o.Spec.ExpiryOffset, ".spec.expiryOffset", 0)
+	// spec.renewBefore takes precedence over spec.expiryOffset
+	duration, path := o.Spec.ExpiryOffset, ".spec.expiryOffset"
+	if o.Spec.RenewBefore != "" {
+		duration, path = o.Spec.RenewBefore, ".spec.renewBefore"
+	}
+	offset, err := parseDurationString(duration, path, 0)
 	if err != nil {
-		logger.Info("Warning: tolerating invalid expiryOffset",
+		logger.Info("Warning: tolerating invalid offset",
 			"err", err, "effectiveOffset", offset)
 	}
 
@@ -490,7 +998,7 @@ func computePKIRenewalWindow(ctx context.Context, o *secretsv1beta1.VaultPKISecr
 	logger.V(consts.LogLevelDebug).WithValues(
 		"expiresWhen", rotationTime, "now", now,
 		"serialNumber", o.Status.SerialNumber,
-		"horizon", horizon).Info("Computed certificate renewal window with spec.expiryOffset")
+		"horizon", horizon).Info("Computed certificate renewal window")
 
 	return horizon, inWindow
 }
@@ -522,3 +1030,75 @@ func convertToK8sTLSSecretData(data map[string][]byte) map[string][]byte {
 
 	return ret
 }
+
+// convertToK8sTLSSecretDataWithChainOrder sets "tls.key", "tls.crt", and
+// "ca.crt" in data, laying out the certificate chain from certResp according to
+// chainOrder. See Destination.ChainOrder for the supported choices.
+func convertToK8sTLSSecretDataWithChainOrder(data map[string][]byte,
+	certResp *vault.PKICertResponse, chainOrder string,
+) map[string][]byte {
+	ret := maps.Clone(data)
+	if v, ok := ret["private_key"]; ok {
+		ret[corev1.TLSPrivateKeyKey] = v
+	}
+
+	chain := certResp.CAChain
+	if len(chain) == 0 && certResp.IssuingCa != "" {
+		chain = []string{certResp.IssuingCa}
+	}
+
+	issuingCA := certResp.IssuingCa
+	if issuingCA == "" && len(chain) > 0 {
+		issuingCA = chain[0]
+	}
+
+	var certs, caCerts []string
+	if v, ok := ret["certificate"]; ok {
+		certs = append(certs, string(v))
+	}
+	switch chainOrder {
+	case pkiChainOrderLeaf:
+		caCerts = chain
+	case pkiChainOrderRootCA:
+		for _, c := range chain {
+			if isSelfSignedCertificate(c) {
+				caCerts = append(caCerts, c)
+			} else {
+				certs = append(certs, c)
+			}
+		}
+		if len(caCerts) == 0 && issuingCA != "" {
+			caCerts = []string{issuingCA}
+		}
+	default:
+		certs = append(certs, chain...)
+		if issuingCA != "" {
+			caCerts = []string{issuingCA}
+		}
+	}
+
+	if len(certs) > 0 {
+		ret[corev1.TLSCertKey] = []byte(strings.Join(certs, "\n"))
+	}
+	if len(caCerts) > 0 {
+		ret[corev1.ServiceAccountRootCAKey] = []byte(strings.Join(caCerts, "\n"))
+	}
+
+	return ret
+}
+
+// isSelfSignedCertificate returns true if the PEM encoded certificate is a self
+// signed, root, CA certificate.
+func isSelfSignedCertificate(certificate string) bool {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return false
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
@@ -5,12 +5,33 @@ package controllers
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
 )
 
 func Test_computePKIRenewalWindow(t *testing.T) {
@@ -53,6 +74,7 @@ func Test_computePKIRenewalWindow(t *testing.T) {
 		name            string
 		o               *secretsv1beta1.VaultPKISecret
 		expirationDelta int64
+		notAfterDelta   *int64
 		jitterPercent   float64
 		wantInWindow    bool
 		assertFunc      assertFunc
@@ -154,6 +176,72 @@ func Test_computePKIRenewalWindow(t *testing.T) {
 			assertFunc:      newNotInWindowAssertFunc(time.Second*60, time.Second*57, false),
 			wantInWindow:    false,
 		},
+		{
+			name: "in-window-not-after-before-expiration",
+			o: &secretsv1beta1.VaultPKISecret{
+				Spec:   secretsv1beta1.VaultPKISecretSpec{},
+				Status: secretsv1beta1.VaultPKISecretStatus{},
+			},
+			expirationDelta: 60,
+			notAfterDelta:   ptr.To[int64](0),
+			jitterPercent:   0.05,
+			assertFunc:      newInWindowAssertFunc(time.Second*1, time.Duration(1.05*float64(time.Second))),
+			wantInWindow:    true,
+		},
+		{
+			name: "not-in-window-not-after-after-expiration",
+			o: &secretsv1beta1.VaultPKISecret{
+				Spec:   secretsv1beta1.VaultPKISecretSpec{},
+				Status: secretsv1beta1.VaultPKISecretStatus{},
+			},
+			expirationDelta: 60,
+			notAfterDelta:   ptr.To[int64](3600),
+			jitterPercent:   0.05,
+			assertFunc:      newNotInWindowAssertFunc(time.Second*60, time.Second*57, false),
+			wantInWindow:    false,
+		},
+		{
+			name: "not-in-window-with-renew-before",
+			o: &secretsv1beta1.VaultPKISecret{
+				Spec: secretsv1beta1.VaultPKISecretSpec{
+					ExpiryOffset: "30s",
+					RenewBefore:  "10s",
+				},
+				Status: secretsv1beta1.VaultPKISecretStatus{},
+			},
+			expirationDelta: 60,
+			jitterPercent:   0.05,
+			assertFunc:      newNotInWindowAssertFunc(time.Second*50, time.Second*60, true),
+			wantInWindow:    false,
+		},
+		{
+			name: "not-in-window-not-after-with-renew-before",
+			o: &secretsv1beta1.VaultPKISecret{
+				Spec: secretsv1beta1.VaultPKISecretSpec{
+					RenewBefore: "10s",
+				},
+				Status: secretsv1beta1.VaultPKISecretStatus{},
+			},
+			expirationDelta: 3600,
+			notAfterDelta:   ptr.To[int64](60),
+			jitterPercent:   0.05,
+			assertFunc:      newNotInWindowAssertFunc(time.Second*50, time.Second*60, true),
+			wantInWindow:    false,
+		},
+		{
+			name: "in-window-with-renew-before",
+			o: &secretsv1beta1.VaultPKISecret{
+				Spec: secretsv1beta1.VaultPKISecretSpec{
+					RenewBefore: "2m",
+				},
+				Status: secretsv1beta1.VaultPKISecretStatus{},
+			},
+			expirationDelta: 3600,
+			notAfterDelta:   ptr.To[int64](60),
+			jitterPercent:   0.05,
+			assertFunc:      newInWindowAssertFunc(time.Second*1, time.Duration(1.05*float64(time.Second))),
+			wantInWindow:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -171,6 +259,9 @@ func Test_computePKIRenewalWindow(t *testing.T) {
 			nowFunc = defaultNowFunc
 			now := nowFunc()
 			tt.o.Status.Expiration = now.Unix() + tt.expirationDelta
+			if tt.notAfterDelta != nil {
+				tt.o.Status.NotAfter = now.Unix() + *tt.notAfterDelta
+			}
 			gotHorizon, gotInWindow := computePKIRenewalWindow(ctx, tt.o, tt.jitterPercent)
 			tt.assertFunc(t, gotHorizon, "computePKIRenewalWindow(%v, %v, %v)", ctx, tt.o, tt.jitterPercent)
 			assert.Equalf(t, tt.wantInWindow, gotInWindow, "computePKIRenewalWindow(%v, %v, %v)", ctx, tt.o, tt.jitterPercent)
@@ -241,3 +332,488 @@ func Test_convertToK8sTLSSecretData(t *testing.T) {
 		})
 	}
 }
+
+func Test_validatePKIDestinations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		destinations []secretsv1beta1.Destination
+		wantErr      assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "none",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unique",
+			destinations: []secretsv1beta1.Destination{
+				{Name: "keystore"},
+				{Name: "truststore"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "duplicate-primary",
+			destinations: []secretsv1beta1.Destination{
+				{Name: "tls"},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, `duplicate destination name "tls"`, i...)
+			},
+		},
+		{
+			name: "duplicate",
+			destinations: []secretsv1beta1.Destination{
+				{Name: "keystore"},
+				{Name: "keystore"},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, `duplicate destination name "keystore"`, i...)
+			},
+		},
+		{
+			name: "empty-name",
+			destinations: []secretsv1beta1.Destination{
+				{},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "destination name cannot be empty", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &secretsv1beta1.VaultPKISecret{
+				Spec: secretsv1beta1.VaultPKISecretSpec{
+					Destination: secretsv1beta1.Destination{
+						Name: "tls",
+					},
+					Destinations: tt.destinations,
+				},
+			}
+			tt.wantErr(t, validatePKIDestinations(o))
+		})
+	}
+}
+
+func Test_convertToK8sTLSSecretDataWithChainOrder(t *testing.T) {
+	t.Parallel()
+
+	rootCA, rootKey := newTestCACertificate(t, "root", nil, nil)
+	intCA, _ := newTestCACertificate(t, "intermediate", rootCA, rootKey)
+	root := encodeTestCertificate(rootCA)
+	intermediate := encodeTestCertificate(intCA)
+
+	data := map[string][]byte{
+		"private_key": []byte("v_private_key"),
+		"certificate": []byte("v_certificate"),
+	}
+	tests := []struct {
+		name       string
+		certResp   *vault.PKICertResponse
+		chainOrder string
+		wantCrt    string
+		wantCA     string
+	}{
+		{
+			name: "leaf-chain",
+			certResp: &vault.PKICertResponse{
+				CAChain:   []string{intermediate, root},
+				IssuingCa: intermediate,
+			},
+			chainOrder: pkiChainOrderLeafChain,
+			wantCrt:    "v_certificate\n" + intermediate + "\n" + root,
+			wantCA:     intermediate,
+		},
+		{
+			name: "leaf",
+			certResp: &vault.PKICertResponse{
+				CAChain:   []string{intermediate, root},
+				IssuingCa: intermediate,
+			},
+			chainOrder: pkiChainOrderLeaf,
+			wantCrt:    "v_certificate",
+			wantCA:     intermediate + "\n" + root,
+		},
+		{
+			name: "root-ca",
+			certResp: &vault.PKICertResponse{
+				CAChain:   []string{intermediate, root},
+				IssuingCa: intermediate,
+			},
+			chainOrder: pkiChainOrderRootCA,
+			wantCrt:    "v_certificate\n" + intermediate,
+			wantCA:     root,
+		},
+		{
+			name: "root-ca-without-root",
+			certResp: &vault.PKICertResponse{
+				CAChain:   []string{intermediate},
+				IssuingCa: intermediate,
+			},
+			chainOrder: pkiChainOrderRootCA,
+			wantCrt:    "v_certificate\n" + intermediate,
+			wantCA:     intermediate,
+		},
+		{
+			name: "leaf-chain-issuing-ca-only",
+			certResp: &vault.PKICertResponse{
+				IssuingCa: root,
+			},
+			chainOrder: pkiChainOrderLeafChain,
+			wantCrt:    "v_certificate\n" + root,
+			wantCA:     root,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertToK8sTLSSecretDataWithChainOrder(data, tt.certResp, tt.chainOrder)
+			assert.Equal(t, map[string][]byte{
+				"private_key": []byte("v_private_key"),
+				"certificate": []byte("v_certificate"),
+				"tls.key":     []byte("v_private_key"),
+				"tls.crt":     []byte(tt.wantCrt),
+				"ca.crt":      []byte(tt.wantCA),
+			}, got)
+		})
+	}
+}
+
+func newTestCACertificate(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func encodeTestCertificate(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Raw,
+	}))
+}
+
+func TestVaultPKISecretReconciler_getPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		spec secretsv1beta1.VaultPKISecretSpec
+		want string
+	}{
+		{
+			name: "issue",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount: "pki",
+				Role:  "role",
+			},
+			want: "pki/issue/role",
+		},
+		{
+			name: "issue-with-issuer",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount:     "pki",
+				Role:      "role",
+				IssuerRef: "issuer",
+			},
+			want: "pki/issuer/issuer/role",
+		},
+		{
+			name: "sign-default",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount: "pki",
+				Role:  "role",
+				CSR:   &secretsv1beta1.VaultPKISecretCSR{},
+			},
+			want: "pki/sign/role",
+		},
+		{
+			name: "sign-with-issuer",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount:     "pki",
+				Role:      "role",
+				IssuerRef: "issuer",
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					Mode: "sign",
+				},
+			},
+			want: "pki/issuer/issuer/sign/role",
+		},
+		{
+			name: "sign-verbatim",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				Mount: "pki",
+				Role:  "role",
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					Mode: "sign-verbatim",
+				},
+			},
+			want: "pki/sign-verbatim/role",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &VaultPKISecretReconciler{}
+			assert.Equal(t, tt.want, r.getPath(tt.spec))
+		})
+	}
+}
+
+func TestVaultPKISecretReconciler_getCSR(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	key, err := vault.GeneratePKIPrivateKey(vault.PKIKeyTypeEC, 0)
+	require.NoError(t, err)
+	csr, err := key.CSR(&x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "external.example.com"},
+	})
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "csr",
+		},
+		Data: map[string][]byte{
+			"tls.csr": []byte(csr),
+			"other":   []byte("not-a-csr"),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		spec           secretsv1beta1.VaultPKISecretSpec
+		wantCSR        string
+		wantPrivateKey bool
+		wantCN         string
+		wantErr        assert.ErrorAssertionFunc
+	}{
+		{
+			name: "from-secret",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					SecretRef: "csr",
+				},
+			},
+			wantCSR: csr,
+			wantCN:  "external.example.com",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "from-secret-invalid",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					SecretRef: "csr",
+					SecretKey: "other",
+				},
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "from-secret-missing-key",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					SecretRef: "csr",
+					SecretKey: "missing",
+				},
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "from-secret-not-found",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					SecretRef: "missing",
+				},
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "generated",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				CommonName: "generated.example.com",
+				AltNames:   []string{"foo.example.com", "admin@example.com"},
+				IPSans:     []string{"127.0.0.1"},
+				URISans:    []string{"spiffe://example.com/foo"},
+				CSR: &secretsv1beta1.VaultPKISecretCSR{
+					KeyType: vault.PKIKeyTypeRSA,
+				},
+			},
+			wantPrivateKey: true,
+			wantCN:         "generated.example.com",
+			wantErr:        assert.NoError,
+		},
+		{
+			name: "generated-invalid-ip-san",
+			spec: secretsv1beta1.VaultPKISecretSpec{
+				IPSans: []string{"invalid"},
+				CSR:    &secretsv1beta1.VaultPKISecretCSR{},
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &VaultPKISecretReconciler{
+				Client: fake.NewClientBuilder().WithObjects(secret.DeepCopy()).Build(),
+			}
+			o := &secretsv1beta1.VaultPKISecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "pki",
+				},
+				Spec: tt.spec,
+			}
+			gotCSR, gotPrivateKey, err := r.getCSR(ctx, o)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			if tt.wantCSR != "" {
+				assert.Equal(t, tt.wantCSR, gotCSR)
+			}
+			assert.Equal(t, tt.wantPrivateKey, gotPrivateKey != nil)
+
+			block, _ := pem.Decode([]byte(gotCSR))
+			require.NotNil(t, block)
+			req, err := x509.ParseCertificateRequest(block.Bytes)
+			require.NoError(t, err)
+			require.NoError(t, req.CheckSignature())
+			assert.Equal(t, tt.wantCN, req.Subject.CommonName)
+			if tt.wantPrivateKey {
+				assert.Equal(t, tt.spec.AltNames[:1], req.DNSNames)
+				assert.Equal(t, tt.spec.AltNames[1:], req.EmailAddresses)
+				require.Len(t, req.IPAddresses, 1)
+				assert.Equal(t, tt.spec.IPSans[0], req.IPAddresses[0].String())
+				require.Len(t, req.URIs, 1)
+				assert.Equal(t, tt.spec.URISans[0], req.URIs[0].String())
+			}
+		})
+	}
+}
+
+// stubPKIClientFactory always returns its Client from Get.
+type stubPKIClientFactory struct {
+	vault.ClientFactory
+	client vault.Client
+}
+
+func (f *stubPKIClientFactory) Get(_ context.Context, _ client.Client, _ client.Object) (vault.Client, error) {
+	return f.client, nil
+}
+
+func TestVaultPKISecretReconciler_Reconcile_destinationsFailed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	failDestination := true
+	c := testutils.NewFakeClientBuilder().
+		WithStatusSubresource(&secretsv1beta1.VaultPKISecret{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*corev1.Secret); ok && obj.GetName() == "extra" && failDestination {
+					return errors.New("create denied")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	hmacObjKey := client.ObjectKey{Namespace: "vso", Name: "hmac"}
+	_, err := helpers.CreateHMACKeySecret(ctx, c, hmacObjKey)
+	require.NoError(t, err)
+
+	o := &secretsv1beta1.VaultPKISecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pki",
+		},
+		Spec: secretsv1beta1.VaultPKISecretSpec{
+			Mount:  "pki",
+			Role:   "role",
+			Revoke: true,
+			Destination: secretsv1beta1.Destination{
+				Name:   "primary",
+				Create: true,
+			},
+			Destinations: []secretsv1beta1.Destination{
+				{
+					Name:   "extra",
+					Create: true,
+				},
+			},
+		},
+	}
+	require.NoError(t, c.Create(ctx, o))
+
+	vaultClient := &stubWriteVaultClient{
+		data: map[string]any{
+			"certificate":   "cert",
+			"private_key":   "key",
+			"serial_number": "01:02",
+			"expiration":    time.Now().Add(time.Hour).Unix(),
+		},
+	}
+	r := &VaultPKISecretReconciler{
+		Client:          c,
+		ClientFactory:   &stubPKIClientFactory{client: vaultClient},
+		HMACValidator:   helpers.NewHMACValidator(hmacObjKey),
+		Recorder:        record.NewFakeRecorder(100),
+		SyncRegistry:    NewSyncRegistry(),
+		BackOffRegistry: NewBackOffRegistry(),
+		referenceCache:  newResourceReferenceCache(),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(o)}
+
+	var got secretsv1beta1.VaultPKISecret
+	for i := 0; i < 2; i++ {
+		_, err = r.Reconcile(ctx, req)
+		require.NoError(t, err)
+
+		require.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+		assert.Equal(t, "01:02", got.Status.SerialNumber)
+		assert.Equal(t, consts.ReasonSecretSyncError, got.Status.Error)
+		assert.False(t, ptr.Deref(got.Status.Valid, true))
+		_, err = helpers.GetSecret(ctx, c, client.ObjectKey{Namespace: "default", Name: "primary"})
+		assert.NoError(t, err)
+		// the certificate is issued once, no matter how often the additional
+		// destinations fail to sync.
+		assert.Equal(t, []string{"pki/issue/role"}, vaultClient.writes)
+	}
+
+	failDestination = false
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+	assert.Equal(t, "01:02", got.Status.SerialNumber)
+	assert.Empty(t, got.Status.Error)
+	assert.True(t, ptr.Deref(got.Status.Valid, false))
+	extra, err := helpers.GetSecret(ctx, c, client.ObjectKey{Namespace: "default", Name: "extra"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), extra.Data["certificate"])
+	assert.Equal(t, []string{"pki/issue/role"}, vaultClient.writes)
+	_, ok := r.pendingDestinations.get(req.NamespacedName)
+	assert.False(t, ok)
+}
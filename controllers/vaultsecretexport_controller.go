@@ -0,0 +1,394 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+const (
+	exportConflictPolicyOverwrite = "Overwrite"
+
+	// conditionTypeSecretExported is the condition type that reports whether
+	// the source Secret of a VaultSecretExport was exported to Vault.
+	conditionTypeSecretExported = "SecretExported"
+	reasonExported              = "Exported"
+	reasonExportConflict        = "Conflict"
+	reasonExportFailed          = "Failed"
+)
+
+// VaultSecretExportReconciler reconciles a VaultSecretExport object
+type VaultSecretExportReconciler struct {
+	client.Client
+	Scheme             *runtime.Scheme
+	ClientFactory      vault.ClientFactory
+	HMACValidator      helpers.HMACValidator
+	Recorder           record.EventRecorder
+	SyncRegistry       *SyncRegistry
+	BackOffRegistry    *BackOffRegistry
+	SyncStatusRegistry *SyncStatusRegistry
+	referenceCache     ResourceReferenceCache
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// VaultPathPolicy restricts the Vault paths that the resources may
+	// reference, it is nil if no paths are restricted.
+	VaultPathPolicy *common.VaultPathPolicy
+}
+
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultsecretexports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultsecretexports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//
+
+// Reconcile exports the data of a VaultSecretExport's source Secret to its
+// KV-v2 secret in Vault, whenever the source Secret's data changes. Every write
+// is a check-and-set write against the version of the Vault secret that was
+// last written by the operator, so that changes made to the Vault secret by
+// others are detected as conflicts, and handled according to the
+// ConflictPolicy.
+func (r *VaultSecretExportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(VaultSecretExport, req.NamespacedName)
+		r.referenceCache.Remove(Secret, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	o := &secretsv1beta1.VaultSecretExport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(VaultSecretExport, req.NamespacedName)
+			r.SyncRegistry.Delete(req.NamespacedName)
+			r.BackOffRegistry.Delete(req.NamespacedName)
+			r.referenceCache.Remove(Secret, req.NamespacedName)
+			logger.V(consts.LogLevelDebug).Info("VaultSecretExport resource not found", "req", req)
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "Failed to get VaultSecretExport resource", "resource", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if o.GetDeletionTimestamp() != nil {
+		// the exported secret is retained in Vault.
+		return ctrl.Result{}, nil
+	}
+
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil || suspended {
+		return ctrl.Result{}, err
+	}
+
+	if denied, err := handleVaultPathPolicy(ctx, r.Client, o, r.VaultPathPolicy, r.Recorder); err != nil || denied {
+		return ctrl.Result{}, err
+	}
+
+	sourceObjKey := client.ObjectKey{
+		Namespace: o.Namespace,
+		Name:      o.Spec.Source.Name,
+	}
+	r.referenceCache.Set(Secret, req.NamespacedName, sourceObjKey)
+
+	// assume that status is always invalid
+	o.Status.Valid = ptr.To(false)
+
+	source := &corev1.Secret{}
+	if err := r.Client.Get(ctx, sourceObjKey, source); err != nil {
+		horizon := computeHorizonWithJitter(requeueDurationOnError)
+		o.Status.Error = consts.ReasonK8sClientError
+		msg := fmt.Sprintf("Failed to get the source Secret %q, horizon=%s", sourceObjKey.Name, horizon)
+		logger.Error(err, msg)
+		r.recordEvent(o, consts.ReasonSecretExportError, msg+": %s", err)
+		o.Status.Conditions = secretExportedConditions(o.Status.Conditions, o.GetGeneration(),
+			reasonExportFailed, msg)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: horizon,
+		}, nil
+	}
+
+	data, err := secretExportData(source, o.Spec.Source.Keys)
+	if err != nil {
+		o.Status.Error = consts.ReasonInvalidConfiguration
+		msg := "Invalid source Secret data"
+		logger.Error(err, msg)
+		r.recordEvent(o, consts.ReasonSecretExportError, msg+": %s", err)
+		o.Status.Conditions = secretExportedConditions(o.Status.Conditions, o.GetGeneration(),
+			reasonExportFailed, fmt.Sprintf("%s: %s", msg, err))
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	newMAC, err := r.HMACValidator.HMAC(ctx, r.Client, b)
+	if err != nil {
+		logger.Error(err, "HMAC data")
+		o.Status.Error = consts.ReasonHMACDataError
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+	secretMAC := base64.StdEncoding.EncodeToString(newMAC)
+
+	var syncReason string
+	switch {
+	case o.Status.Version == 0:
+		syncReason = consts.ReasonInitialSync
+	case r.SyncRegistry.Has(req.NamespacedName):
+		syncReason = consts.ReasonForceSync
+	case o.GetGeneration() != o.Status.LastGeneration:
+		syncReason = consts.ReasonResourceUpdated
+	case secretMAC != o.Status.SecretMAC:
+		syncReason = consts.ReasonSecretDataDrift
+	default:
+		logger.V(consts.LogLevelDebug).Info("Source Secret data unchanged, skipping export")
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Must export", "reason", syncReason)
+
+	c, err := r.ClientFactory.Get(ctx, r.Client, o)
+	if err != nil {
+		o.Status.Error = consts.ReasonK8sClientError
+		logger.Error(err, "Get Vault client")
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	currentVersion, err := vault.ReadKVV2CurrentVersion(ctx, c, o.Spec.Mount, o.Spec.Path)
+	if err == nil && isSecretExportConflict(o, currentVersion) {
+		return r.handleConflict(ctx, o, fmt.Sprintf(
+			"The current version %d of the Vault secret was not written by the operator, "+
+				"the last exported version is %d", currentVersion, o.Status.Version))
+	}
+
+	var version int
+	if err == nil {
+		version, err = vault.WriteKVV2WithCAS(ctx, c, o.Spec.Mount, o.Spec.Path, data, currentVersion)
+	}
+	if err != nil {
+		if horizon, ok := handleThrottled(ctx, r.Client, o, r.Recorder, err); ok {
+			r.SyncRegistry.Add(req.NamespacedName)
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
+		if vault.IsCheckAndSetError(err) {
+			// the Vault secret was written to after its current version was read.
+			return r.handleConflict(ctx, o, fmt.Sprintf(
+				"The Vault secret was modified during the export: %s", err))
+		}
+		if vault.IsForbiddenError(err) {
+			c.Taint()
+		}
+		o.Status.Error = consts.ReasonVaultClientError
+		msg := "Failed to export the secret to Vault"
+		logger.Error(err, msg)
+		r.recordEvent(o, consts.ReasonSecretExportError, msg+": %s", err)
+		o.Status.Conditions = secretExportedConditions(o.Status.Conditions, o.GetGeneration(),
+			reasonExportFailed, fmt.Sprintf("%s: %s", msg, err))
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		r.SyncRegistry.Add(req.NamespacedName)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		return ctrl.Result{
+			RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
+		}, nil
+	}
+
+	resetBackOff(r.BackOffRegistry, o)
+
+	o.Status.Valid = ptr.To(true)
+	o.Status.Error = ""
+	o.Status.Version = version
+	o.Status.SecretMAC = secretMAC
+	o.Status.LastExportTime = nowFunc().Unix()
+	o.Status.Conditions = secretExportedConditions(o.Status.Conditions, o.GetGeneration(),
+		reasonExported, fmt.Sprintf("Exported version %d of the Vault secret", version))
+	if err := r.updateStatus(ctx, o); err != nil {
+		logger.Error(err, "Failed to update the status")
+		return ctrl.Result{}, err
+	}
+
+	r.SyncRegistry.Delete(req.NamespacedName)
+
+	r.recordEvent(o, consts.ReasonSecretExported, "Secret exported to Vault, version=%d", version)
+	logger.Info("Successfully exported the secret", "version", version)
+	return ctrl.Result{}, nil
+}
+
+// handleConflict records a conflicting write to the Vault secret of o. The
+// export is retried with back-off, since the conflict can be resolved outside
+// the cluster, e.g. by deleting the Vault secret.
+func (r *VaultSecretExportReconciler) handleConflict(ctx context.Context, o *secretsv1beta1.VaultSecretExport, msg string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	objKey := client.ObjectKeyFromObject(o)
+
+	o.Status.Error = consts.ReasonSecretExportConflict
+	logger.Info("Conflicting write to the Vault secret", "msg", msg)
+	r.recordEvent(o, consts.ReasonSecretExportConflict, "%s", msg)
+	o.Status.Conditions = secretExportedConditions(o.Status.Conditions, o.GetGeneration(),
+		reasonExportConflict, msg)
+	if err := r.updateStatus(ctx, o); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.SyncRegistry.Add(objKey)
+	entry, _ := r.BackOffRegistry.Get(objKey)
+	return ctrl.Result{
+		RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
+	}, nil
+}
+
+func (r *VaultSecretExportReconciler) recordEvent(o *secretsv1beta1.VaultSecretExport, reason, msg string, i ...interface{}) {
+	eventType := corev1.EventTypeNormal
+	if !ptr.Deref(o.Status.Valid, false) {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.Recorder.Eventf(o, eventType, reason, msg, i...)
+}
+
+func (r *VaultSecretExportReconciler) updateStatus(ctx context.Context, o *secretsv1beta1.VaultSecretExport) error {
+	logger := log.FromContext(ctx)
+	logger.V(consts.LogLevelTrace).Info("Update status called")
+
+	metrics.SetResourceStatus("vaultsecretexport", o, ptr.Deref(o.Status.Valid, false))
+
+	o.Status.LastGeneration = o.GetGeneration()
+	if err := r.Status().Update(ctx, o); err != nil {
+		msg := "Failed to update the resource's status"
+		r.recordEvent(o, consts.ReasonStatusUpdateError, "%s: %s", msg, err)
+		logger.Error(err, msg)
+		return err
+	}
+
+	return nil
+}
+
+func (r *VaultSecretExportReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(VaultSecretExport, r.Recorder)
+	r.referenceCache = newResourceReferenceCache()
+	if r.BackOffRegistry == nil {
+		r.BackOffRegistry = NewBackOffRegistry()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		// the predicates must not filter the source Secret's events, since its
+		// generation never changes.
+		For(&secretsv1beta1.VaultSecretExport{},
+			builder.WithPredicates(syncableSecretPredicate(r.SyncRegistry))).
+		WithOptions(opts).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueRefRequestsHandler{
+				kind:     Secret,
+				refCache: r.referenceCache,
+				// the references are maintained by the referring
+				// VaultSecretExport, which must be reconciled when the source
+				// Secret is deleted.
+				enqueueOnDelete: true,
+			},
+		).
+		Complete(r.SyncStatusRegistry.Reconciler(VaultSecretExport, r))
+}
+
+// isSecretExportConflict returns true if the currentVersion of the Vault secret
+// was not written by the operator, and must not be overwritten according to the
+// ConflictPolicy of o. A Vault secret that does not exist is never a conflict.
+func isSecretExportConflict(o *secretsv1beta1.VaultSecretExport, currentVersion int) bool {
+	if currentVersion == 0 || currentVersion == o.Status.Version {
+		return false
+	}
+
+	return o.Spec.ConflictPolicy != exportConflictPolicyOverwrite
+}
+
+// secretExportData returns the data of the source Secret to export to Vault. If
+// keys is empty, all keys of the Secret are exported. An error is returned if
+// any of the keys is not found in the Secret.
+func secretExportData(source *corev1.Secret, keys []string) (map[string]any, error) {
+	data := make(map[string]any)
+	if len(keys) == 0 {
+		for k, v := range source.Data {
+			data[k] = string(v)
+		}
+		return data, nil
+	}
+
+	for _, k := range keys {
+		v, ok := source.Data[k]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found in Secret %s/%s", k, source.Namespace, source.Name)
+		}
+		data[k] = string(v)
+	}
+
+	return data, nil
+}
+
+// secretExportedConditions returns the conditions with the SecretExported
+// condition set to reason and msg. The condition is only true for a successful
+// export.
+func secretExportedConditions(current []metav1.Condition, generation int64, reason, msg string) []metav1.Condition {
+	var conditions []metav1.Condition
+	for _, cond := range current {
+		if cond.Type != conditionTypeSecretExported {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	status := metav1.ConditionFalse
+	if reason == reasonExported {
+		status = metav1.ConditionTrue
+	}
+
+	return updateConditions(current, append(conditions, metav1.Condition{
+		Type:               conditionTypeSecretExported,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            msg,
+	})...)
+}
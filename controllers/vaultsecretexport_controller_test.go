@@ -0,0 +1,366 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// stubExportVaultClient is a KV-v2 secret at version, it only accepts writes
+// whose check-and-set version matches the current version.
+type stubExportVaultClient struct {
+	vault.Client
+	version int
+	writes  []map[string]any
+}
+
+func (c *stubExportVaultClient) Read(_ context.Context, req vault.ReadRequest) (vault.Response, error) {
+	if c.version == 0 {
+		return nil, &vault.EmptyResponseError{Path: req.Path()}
+	}
+	return vault.NewDefaultResponse(&api.Secret{
+		Data: map[string]any{
+			"current_version": c.version,
+		},
+	}), nil
+}
+
+func (c *stubExportVaultClient) Write(_ context.Context, req vault.WriteRequest) (vault.Response, error) {
+	params := req.Params()
+	if cas := params["options"].(map[string]any)["cas"].(int); cas != c.version {
+		return nil, &api.ResponseError{
+			StatusCode: http.StatusBadRequest,
+			Errors:     []string{"check-and-set parameter did not match the current version"},
+		}
+	}
+
+	c.version++
+	c.writes = append(c.writes, params["data"].(map[string]any))
+	return vault.NewDefaultResponse(&api.Secret{
+		Data: map[string]any{
+			"version": c.version,
+		},
+	}), nil
+}
+
+func (c *stubExportVaultClient) Taint() {}
+
+// stubExportClientFactory always returns its stubExportVaultClient from Get.
+type stubExportClientFactory struct {
+	vault.ClientFactory
+	client *stubExportVaultClient
+	gets   int
+}
+
+func (f *stubExportClientFactory) Get(_ context.Context, _ client.Client, _ client.Object) (vault.Client, error) {
+	f.gets++
+	return f.client, nil
+}
+
+func TestVaultSecretExportReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sourceData := map[string][]byte{
+		"tls.crt": []byte("cert"),
+		"tls.key": []byte("key"),
+	}
+	exportData := map[string]any{
+		"tls.crt": "cert",
+		"tls.key": "key",
+	}
+
+	tests := []struct {
+		name           string
+		noSource       bool
+		keys           []string
+		conflictPolicy string
+		status         secretsv1beta1.VaultSecretExportStatus
+		currentMAC     bool
+		vaultVersion   int
+		wantWrites     []map[string]any
+		wantVersion    int
+		wantReason     string
+		wantError      string
+	}{
+		{
+			name:        "initial",
+			wantWrites:  []map[string]any{exportData},
+			wantVersion: 1,
+			wantReason:  reasonExported,
+		},
+		{
+			name: "keys",
+			keys: []string{"tls.crt"},
+			wantWrites: []map[string]any{
+				{"tls.crt": "cert"},
+			},
+			wantVersion: 1,
+			wantReason:  reasonExported,
+		},
+		{
+			name:         "initial-conflict",
+			vaultVersion: 3,
+			wantVersion:  0,
+			wantReason:   reasonExportConflict,
+			wantError:    consts.ReasonSecretExportConflict,
+		},
+		{
+			name:           "initial-overwrite",
+			conflictPolicy: exportConflictPolicyOverwrite,
+			vaultVersion:   3,
+			wantWrites:     []map[string]any{exportData},
+			wantVersion:    4,
+			wantReason:     reasonExported,
+		},
+		{
+			name: "source-updated",
+			status: secretsv1beta1.VaultSecretExportStatus{
+				LastGeneration: 1,
+				Version:        2,
+				SecretMAC:      "stale",
+			},
+			vaultVersion: 2,
+			wantWrites:   []map[string]any{exportData},
+			wantVersion:  3,
+			wantReason:   reasonExported,
+		},
+		{
+			name: "modified-in-vault",
+			status: secretsv1beta1.VaultSecretExportStatus{
+				LastGeneration: 1,
+				Version:        2,
+				SecretMAC:      "stale",
+			},
+			vaultVersion: 3,
+			wantVersion:  2,
+			wantReason:   reasonExportConflict,
+			wantError:    consts.ReasonSecretExportConflict,
+		},
+		{
+			name:           "modified-in-vault-overwrite",
+			conflictPolicy: exportConflictPolicyOverwrite,
+			status: secretsv1beta1.VaultSecretExportStatus{
+				LastGeneration: 1,
+				Version:        2,
+				SecretMAC:      "stale",
+			},
+			vaultVersion: 3,
+			wantWrites:   []map[string]any{exportData},
+			wantVersion:  4,
+			wantReason:   reasonExported,
+		},
+		{
+			name: "deleted-in-vault",
+			status: secretsv1beta1.VaultSecretExportStatus{
+				LastGeneration: 1,
+				Version:        2,
+				SecretMAC:      "stale",
+			},
+			wantWrites:  []map[string]any{exportData},
+			wantVersion: 1,
+			wantReason:  reasonExported,
+		},
+		{
+			name: "unchanged",
+			status: secretsv1beta1.VaultSecretExportStatus{
+				LastGeneration: 1,
+				Version:        2,
+				Valid:          ptr.To(true),
+			},
+			currentMAC:   true,
+			vaultVersion: 2,
+			wantVersion:  2,
+		},
+		{
+			name:        "missing-key",
+			keys:        []string{"ca.crt"},
+			wantVersion: 0,
+			wantReason:  reasonExportFailed,
+			wantError:   consts.ReasonInvalidConfiguration,
+		},
+		{
+			name:        "missing-source",
+			noSource:    true,
+			wantVersion: 0,
+			wantReason:  reasonExportFailed,
+			wantError:   consts.ReasonK8sClientError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := testutils.NewFakeClientBuilder().
+				WithStatusSubresource(&secretsv1beta1.VaultSecretExport{}).
+				Build()
+			hmacObjKey := client.ObjectKey{Namespace: "vso", Name: "hmac"}
+			_, err := helpers.CreateHMACKeySecret(ctx, c, hmacObjKey)
+			require.NoError(t, err)
+			validator := helpers.NewHMACValidator(hmacObjKey)
+
+			status := tt.status
+			if tt.currentMAC {
+				data, err := secretExportData(&corev1.Secret{Data: sourceData}, tt.keys)
+				require.NoError(t, err)
+				b, err := json.Marshal(data)
+				require.NoError(t, err)
+				mac, err := validator.HMAC(ctx, c, b)
+				require.NoError(t, err)
+				status.SecretMAC = base64.StdEncoding.EncodeToString(mac)
+			}
+
+			o := &secretsv1beta1.VaultSecretExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  "default",
+					Name:       "export",
+					Generation: 1,
+				},
+				Spec: secretsv1beta1.VaultSecretExportSpec{
+					Mount: "kv",
+					Path:  "app/tls",
+					Source: secretsv1beta1.VaultSecretExportSource{
+						Name: "tls",
+						Keys: tt.keys,
+					},
+					ConflictPolicy: tt.conflictPolicy,
+				},
+			}
+			require.NoError(t, c.Create(ctx, o))
+			o.Status = status
+			require.NoError(t, c.Status().Update(ctx, o))
+			if !tt.noSource {
+				require.NoError(t, c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "tls",
+					},
+					Data: sourceData,
+				}))
+			}
+
+			vaultClient := &stubExportVaultClient{version: tt.vaultVersion}
+			factory := &stubExportClientFactory{client: vaultClient}
+			r := &VaultSecretExportReconciler{
+				Client:          c,
+				Recorder:        record.NewFakeRecorder(10),
+				ClientFactory:   factory,
+				HMACValidator:   validator,
+				SyncRegistry:    NewSyncRegistry(),
+				BackOffRegistry: NewBackOffRegistry(),
+				referenceCache:  newResourceReferenceCache(),
+			}
+
+			objKey := client.ObjectKeyFromObject(o)
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: objKey})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWrites, vaultClient.writes)
+			assert.Equal(t, []client.ObjectKey{objKey},
+				r.referenceCache.Get(Secret, client.ObjectKey{Namespace: "default", Name: "tls"}))
+
+			got := &secretsv1beta1.VaultSecretExport{}
+			require.NoError(t, c.Get(ctx, objKey, got))
+			assert.Equal(t, tt.wantVersion, got.Status.Version)
+			assert.Equal(t, tt.wantError, got.Status.Error)
+			if tt.wantReason == "" {
+				assert.Zero(t, factory.gets)
+				assert.Equal(t, tt.status.Conditions, got.Status.Conditions)
+				return
+			}
+
+			assert.Equal(t, tt.wantReason == reasonExported, ptr.Deref(got.Status.Valid, false))
+			require.Len(t, got.Status.Conditions, 1)
+			assert.Equal(t, conditionTypeSecretExported, got.Status.Conditions[0].Type)
+			assert.Equal(t, tt.wantReason, got.Status.Conditions[0].Reason)
+			if tt.wantReason == reasonExported {
+				assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+				assert.NotZero(t, got.Status.LastExportTime)
+			} else {
+				assert.Equal(t, metav1.ConditionFalse, got.Status.Conditions[0].Status)
+			}
+			if tt.wantReason == reasonExportConflict {
+				assert.True(t, r.SyncRegistry.Has(objKey))
+				require.NotNil(t, got.Status.BackOff)
+				assert.Equal(t, 1, got.Status.BackOff.Failures)
+			} else {
+				assert.Nil(t, got.Status.BackOff)
+			}
+		})
+	}
+}
+
+func Test_secretExportData(t *testing.T) {
+	t.Parallel()
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "tls",
+		},
+		Data: map[string][]byte{
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		keys    []string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "all",
+			want: map[string]any{
+				"tls.crt": "cert",
+				"tls.key": "key",
+			},
+		},
+		{
+			name: "keys",
+			keys: []string{"tls.key"},
+			want: map[string]any{
+				"tls.key": "key",
+			},
+		},
+		{
+			name:    "missing-key",
+			keys:    []string{"tls.key", "ca.crt"},
+			wantErr: `key "ca.crt" not found in Secret default/tls`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := secretExportData(source, tt.keys)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,636 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+const vaultSSHCertificateFinalizer = "vaultsshcertificates.secrets.hashicorp.com/finalizer"
+
+const (
+	sshCertTypeHost     = "host"
+	sshPublicKeySecret  = "ssh-publickey"
+	sshPrivateKeySecret = "ssh-privatekey"
+	sshCertificateKey   = "ssh-certificate"
+	sshCAKey            = "ca.pub"
+	sshKnownHostsKey    = "known_hosts"
+)
+
+// VaultSSHCertificateReconciler reconciles a VaultSSHCertificate object
+type VaultSSHCertificateReconciler struct {
+	client.Client
+	Scheme                      *runtime.Scheme
+	ClientFactory               vault.ClientFactory
+	HMACValidator               helpers.HMACValidator
+	Recorder                    record.EventRecorder
+	SyncRegistry                *SyncRegistry
+	BackOffRegistry             *BackOffRegistry
+	SyncStatusRegistry          *SyncStatusRegistry
+	referenceCache              ResourceReferenceCache
+	GlobalTransformationOptions *helpers.GlobalTransformationOptions
+	// Shedder defers the reconciliation of low priority resources under a large
+	// backlog, it is nil if load shedding is not enabled.
+	Shedder *ReconcileShedder
+	// FreezeWindow defers non-critical secret rotations and rollout-restarts
+	// during the freeze window, it is nil if no freeze window is configured.
+	FreezeWindow *FreezeWindow
+	// VaultPathPolicy restricts the Vault paths that the resources may
+	// reference, it is nil if no paths are restricted.
+	VaultPathPolicy *common.VaultPathPolicy
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// StartupSyncSmear spreads the initial reconciliation of the resources over
+	// a window after the operator starts, it is nil if smearing is not enabled.
+	StartupSyncSmear *StartupSyncSmear
+}
+
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultsshcertificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultsshcertificates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultsshcertificates/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//
+// required for rollout-restart
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;patch
+//
+
+// Reconcile signs the public key of a VaultSSHCertificate with the Vault SSH
+// secrets engine, and syncs the signed certificate to its Destination. The
+// certificate is renewed before its valid_before time.
+func (r *VaultSSHCertificateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(VaultSSHCertificate, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	o := &secretsv1beta1.VaultSSHCertificate{}
+	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(VaultSSHCertificate, req.NamespacedName)
+			logger.V(consts.LogLevelDebug).Info("VaultSSHCertificate resource not found", "req", req)
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "Failed to get VaultSSHCertificate resource", "resource", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if o.GetDeletionTimestamp() != nil {
+		logger.Info("Got deletion timestamp", "obj", o)
+		return ctrl.Result{}, r.handleDeletion(ctx, o)
+	}
+
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil || suspended {
+		return ctrl.Result{}, err
+	}
+
+	if denied, err := handleVaultPathPolicy(ctx, r.Client, o, r.VaultPathPolicy, r.Recorder); err != nil || denied {
+		return ctrl.Result{}, err
+	}
+
+	if deferAfter, ok := r.Shedder.Shed(ctx, VaultSSHCertificate, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	if deferAfter, ok := r.StartupSyncSmear.Defer(ctx, VaultSSHCertificate, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	pendingAfter, err := r.FreezeWindow.HandlePending(ctx, r.Client, r.HMACValidator, o, r.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	destinationExists, _ := helpers.CheckSecretExists(ctx, r.Client, o)
+	if !o.Spec.Destination.Create && !destinationExists {
+		horizon := computeHorizonWithJitter(requeueDurationOnError)
+		msg := fmt.Sprintf("Kubernetes secret %q does not exist yet, horizon=%s",
+			o.Spec.Destination.Name, horizon)
+		logger.Info(msg)
+		o.Status.Error = consts.ReasonK8sClientError
+		r.recordEvent(o, o.Status.Error, msg)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{
+			RequeueAfter: horizon,
+		}, nil
+	}
+
+	var syncReason string
+	switch {
+	case o.Status.SerialNumber == "":
+		syncReason = consts.ReasonInitialSync
+	case r.SyncRegistry.Has(req.NamespacedName):
+		syncReason = consts.ReasonForceSync
+	case o.GetGeneration() != o.Status.LastGeneration:
+		syncReason = consts.ReasonResourceUpdated
+	case o.Spec.Destination.Create && !destinationExists:
+		logger.Info("Destination secret does not exist",
+			"destination", o.Spec.Destination.Name)
+		syncReason = consts.ReasonInexistentDestination
+	case destinationExists:
+		if matched, err := helpers.HMACDestinationSecret(ctx, r.Client,
+			r.HMACValidator, o); err == nil && !matched {
+			syncReason = consts.ReasonSecretDataDrift
+		} else if err != nil {
+			logger.Error(err, "Failed to HMAC destination secret")
+		}
+	}
+
+	r.referenceCache.Set(SecretTransformation, req.NamespacedName,
+		helpers.GetTransformationRefObjKeys(
+			o.Spec.Destination.Transformation, o.Namespace, r.GlobalTransformationOptions)...)
+
+	transOption, err := helpers.NewSecretTransformationOption(ctx, r.Client, o, r.GlobalTransformationOptions)
+	if err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTransformationError,
+			"Failed setting up SecretTransformationOption: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	if syncReason == "" {
+		horizon, inWindow := computeSSHRenewalWindow(ctx, o, 0.05)
+		if !inWindow {
+			logger.Info("Not in renewal window", "horizon", horizon)
+			return ctrl.Result{
+				RequeueAfter: minRequeueAfter(horizon, pendingAfter),
+			}, nil
+		}
+		syncReason = consts.ReasonInRenewalWindow
+
+		if deferAfter, ok := r.FreezeWindow.DeferRotation(
+			ctx, r.Client, VaultSSHCertificate, o, time.Unix(o.Status.ValidBefore, 0), r.Recorder); ok {
+			return ctrl.Result{RequeueAfter: minRequeueAfter(deferAfter, pendingAfter)}, nil
+		}
+	}
+
+	// assume that status is always invalid
+	o.Status.Valid = ptr.To(false)
+	logger.Info("Must sync", "reason", syncReason)
+
+	publicKey, privateKey, err := r.getPublicKey(ctx, o)
+	if err != nil {
+		o.Status.Error = consts.ReasonCertificateRequestError
+		msg := "Failed to get the public key"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	c, err := r.ClientFactory.Get(ctx, r.Client, o)
+	if err != nil {
+		o.Status.Error = consts.ReasonK8sClientError
+		logger.Error(err, "Get Vault client")
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	certResp, caPublicKey, err := r.signPublicKey(ctx, c, o, publicKey)
+	if err != nil {
+		if horizon, ok := handleThrottled(ctx, r.Client, o, r.Recorder, err); ok {
+			r.SyncRegistry.Add(req.NamespacedName)
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
+		if vault.IsForbiddenError(err) {
+			c.Taint()
+		}
+		o.Status.Error = consts.ReasonK8sClientError
+		msg := "Failed to sign the public key with Vault"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		r.SyncRegistry.Add(req.NamespacedName)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		return ctrl.Result{
+			RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
+		}, nil
+	} else {
+		resetBackOff(r.BackOffRegistry, o)
+	}
+
+	cert, err := certResp.Certificate()
+	if err != nil {
+		o.Status.Error = consts.ReasonK8sClientError
+		msg := "Invalid certificate returned by Vault"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	resp := vault.NewDefaultResponse(&api.Secret{
+		Data: sshCertificateData(o, certResp, caPublicKey, publicKey, privateKey),
+	})
+	data, err := resp.SecretK8sData(transOption)
+	renderErr, err := handleTemplateRenderError(ctx, r.Client, o, data, err)
+	if err != nil {
+		o.Status.Error = consts.ReasonK8sClientError
+		msg := "Failed to marshal Vault secret data"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+	if renderErr != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values for keys that failed to render: %s", renderErr)
+	}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), transOption, renderErr)
+
+	if b, err := json.Marshal(data); err == nil {
+		newMAC, err := r.HMACValidator.HMAC(ctx, r.Client, b)
+		if err != nil {
+			logger.Error(err, "HMAC data")
+			o.Status.Error = consts.ReasonHMACDataError
+			if err := r.updateStatus(ctx, o); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{
+				RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+			}, nil
+		}
+		o.Status.SecretMAC = base64.StdEncoding.EncodeToString(newMAC)
+	}
+
+	opts := helpers.DefaultSyncOptions()
+	opts.HMACValidator = r.HMACValidator
+	err = helpers.SyncSecret(ctx, r.Client, o, data, opts)
+	handleSecretDataError(ctx, r.Client, o, err)
+	if err != nil {
+		logger.Error(err, "Sync secret")
+		o.Status.Error = consts.ReasonSecretSyncError
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	reason := consts.ReasonSecretSynced
+	if o.Status.SerialNumber != "" {
+		reason = consts.ReasonSecretRotated
+		pendingAfter = minRequeueAfter(pendingAfter,
+			r.FreezeWindow.HandleRolloutRestarts(ctx, r.Client, r.HMACValidator, VaultSSHCertificate, o, r.Recorder))
+	}
+
+	o.Status.Valid = ptr.To(true)
+	o.Status.Error = ""
+	o.Status.SerialNumber = certResp.SerialNumber
+	o.Status.ValidAfter = sshCertTime(cert.ValidAfter)
+	o.Status.ValidBefore = sshCertTime(cert.ValidBefore)
+	o.Status.LastRotation = time.Now().Unix()
+	if err := r.updateStatus(ctx, o); err != nil {
+		logger.Error(err, "Failed to update the status")
+		return ctrl.Result{}, err
+	}
+
+	r.SyncRegistry.Delete(req.NamespacedName)
+
+	horizon, _ := computeSSHRenewalWindow(ctx, o, .05)
+	r.recordEvent(o, reason, fmt.Sprintf("Secret synced, horizon=%s", horizon))
+	logger.Info("Successfully updated the secret", "horizon", horizon)
+	return ctrl.Result{
+		RequeueAfter: minRequeueAfter(horizon, pendingAfter),
+	}, nil
+}
+
+// signPublicKey signs publicKey with the Vault SSH secrets engine, returning
+// the signed certificate along with the public key of the Mount's CA.
+func (r *VaultSSHCertificateReconciler) signPublicKey(ctx context.Context, c vault.Client,
+	o *secretsv1beta1.VaultSSHCertificate, publicKey string,
+) (*vault.SSHCertResponse, string, error) {
+	resp, err := c.Write(ctx, vault.NewWriteRequest(
+		fmt.Sprintf("%s/sign/%s", o.Spec.Mount, o.Spec.Role), sshSignParams(o, publicKey)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	certResp, err := vault.UnmarshalSSHSignResponse(resp.Secret())
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err = c.Read(ctx, vault.NewReadRequest(fmt.Sprintf("%s/config/ca", o.Spec.Mount), nil))
+	if err != nil {
+		return nil, "", err
+	}
+
+	caPublicKey, _ := resp.Data()["public_key"].(string)
+	if caPublicKey == "" {
+		return nil, "", fmt.Errorf("no CA public key configured on mount %q", o.Spec.Mount)
+	}
+
+	return certResp, strings.TrimSpace(caPublicKey), nil
+}
+
+// getPublicKey returns the public key to be signed by Vault, in the
+// authorized_keys format. If the public key is not provided by a Secret, a new
+// private key is generated, and returned along with its public key.
+func (r *VaultSSHCertificateReconciler) getPublicKey(ctx context.Context,
+	o *secretsv1beta1.VaultSSHCertificate,
+) (string, *vault.SSHPrivateKey, error) {
+	spec := o.Spec.KeyPair
+	if spec == nil {
+		spec = &secretsv1beta1.VaultSSHCertificateKeyPair{}
+	}
+
+	if spec.SecretRef != "" {
+		key := spec.SecretKey
+		if key == "" {
+			key = sshPublicKeySecret
+		}
+
+		s, err := helpers.GetSecret(ctx, r.Client, client.ObjectKey{
+			Namespace: o.Namespace,
+			Name:      spec.SecretRef,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+
+		b, ok := s.Data[key]
+		if !ok || len(b) == 0 {
+			return "", nil, fmt.Errorf("no public key found in secret %s/%s, key=%q",
+				s.Namespace, s.Name, key)
+		}
+
+		if _, _, _, _, err := ssh.ParseAuthorizedKey(b); err != nil {
+			return "", nil, fmt.Errorf("invalid public key in secret %s/%s, key=%q: %w",
+				s.Namespace, s.Name, key, err)
+		}
+
+		return strings.TrimSpace(string(b)), nil, nil
+	}
+
+	keyType := spec.KeyType
+	if keyType == "" {
+		keyType = vault.PKIKeyTypeEd25519
+	}
+
+	privateKey, err := vault.GenerateSSHPrivateKey(keyType, spec.KeyBits)
+	if err != nil {
+		return "", nil, err
+	}
+
+	publicKey, err := privateKey.PublicKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return publicKey, privateKey, nil
+}
+
+func (r *VaultSSHCertificateReconciler) recordEvent(o *secretsv1beta1.VaultSSHCertificate, reason, msg string, i ...interface{}) {
+	eventType := corev1.EventTypeNormal
+	if !ptr.Deref(o.Status.Valid, false) {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.Recorder.Eventf(o, eventType, reason, msg, i...)
+}
+
+func (r *VaultSSHCertificateReconciler) updateStatus(ctx context.Context, o *secretsv1beta1.VaultSSHCertificate) error {
+	logger := log.FromContext(ctx)
+	logger.V(consts.LogLevelTrace).Info("Update status called")
+
+	metrics.SetResourceStatus("vaultsshcertificate", o, ptr.Deref(o.Status.Valid, false))
+
+	o.Status.LastGeneration = o.GetGeneration()
+	if err := r.Status().Update(ctx, o); err != nil {
+		msg := "Failed to update the resource's status"
+		r.recordEvent(o, consts.ReasonStatusUpdateError, "%s: %s", msg, err)
+		logger.Error(err, msg)
+		return err
+	}
+
+	_, err := maybeAddFinalizer(ctx, r.Client, o, vaultSSHCertificateFinalizer)
+	return err
+}
+
+func (r *VaultSSHCertificateReconciler) handleDeletion(ctx context.Context, o *secretsv1beta1.VaultSSHCertificate) error {
+	if err := finalizeDestinationSecrets(ctx, r.Client, r.Recorder, o, vaultSSHCertificateFinalizer); err != nil {
+		return err
+	}
+
+	objKey := client.ObjectKeyFromObject(o)
+	r.SyncRegistry.Delete(objKey)
+	r.BackOffRegistry.Delete(objKey)
+	r.referenceCache.Remove(SecretTransformation, objKey)
+
+	logger := log.FromContext(ctx).WithName("handleDeletion")
+	if controllerutil.RemoveFinalizer(o, vaultSSHCertificateFinalizer) {
+		if err := r.Update(ctx, o); err != nil {
+			logger.Error(err, "Failed to remove the finalizer")
+			return err
+		}
+		logger.V(consts.LogLevelDebug).Info("Finalizers successfully removed")
+	}
+
+	return nil
+}
+
+func (r *VaultSSHCertificateReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(VaultSSHCertificate, r.Recorder)
+	r.referenceCache = newResourceReferenceCache()
+	if r.BackOffRegistry == nil {
+		r.BackOffRegistry = NewBackOffRegistry()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&secretsv1beta1.VaultSSHCertificate{}).
+		WithEventFilter(syncableSecretPredicate(r.SyncRegistry)).
+		WithOptions(opts).
+		Watches(
+			&secretsv1beta1.SecretTransformation{},
+			NewEnqueueRefRequestsHandlerST(r.referenceCache, r.SyncRegistry),
+		).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDeletionRequestHandler{
+				gvk: secretsv1beta1.GroupVersion.WithKind(VaultSSHCertificate.String()),
+			},
+			builder.WithPredicates(&secretsPredicate{}),
+		).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDriftRequestHandler{
+				client:    r.Client,
+				validator: r.HMACValidator,
+				recorder:  r.Recorder,
+				kind:      VaultSSHCertificate,
+			},
+			builder.WithPredicates(&secretsDriftPredicate{}),
+		).
+		Complete(r.SyncStatusRegistry.Reconciler(VaultSSHCertificate, r))
+}
+
+// sshSignParams returns the parameters of the Vault SSH sign request for o.
+func sshSignParams(o *secretsv1beta1.VaultSSHCertificate, publicKey string) map[string]any {
+	params := map[string]any{
+		"public_key": publicKey,
+	}
+	if o.Spec.CertType != "" {
+		params["cert_type"] = o.Spec.CertType
+	}
+	if len(o.Spec.ValidPrincipals) > 0 {
+		params["valid_principals"] = strings.Join(o.Spec.ValidPrincipals, ",")
+	}
+	if o.Spec.KeyID != "" {
+		params["key_id"] = o.Spec.KeyID
+	}
+	if o.Spec.TTL != "" {
+		params["ttl"] = o.Spec.TTL
+	}
+	if len(o.Spec.CriticalOptions) > 0 {
+		params["critical_options"] = o.Spec.CriticalOptions
+	}
+	if len(o.Spec.Extensions) > 0 {
+		params["extensions"] = o.Spec.Extensions
+	}
+
+	return params
+}
+
+// sshCertificateData returns the secret data for the certificate signed for o.
+// The known_hosts entry trusts the CA for the ValidPrincipals of a host
+// certificate, and for all hosts otherwise.
+func sshCertificateData(o *secretsv1beta1.VaultSSHCertificate, certResp *vault.SSHCertResponse,
+	caPublicKey, publicKey string, privateKey *vault.SSHPrivateKey,
+) map[string]any {
+	hosts := "*"
+	if o.Spec.CertType == sshCertTypeHost && len(o.Spec.ValidPrincipals) > 0 {
+		principals := append([]string{}, o.Spec.ValidPrincipals...)
+		sort.Strings(principals)
+		hosts = strings.Join(principals, ",")
+	}
+
+	data := map[string]any{
+		"serial_number":    certResp.SerialNumber,
+		sshCertificateKey:  strings.TrimSpace(certResp.SignedKey) + "\n",
+		sshPublicKeySecret: publicKey + "\n",
+		sshCAKey:           caPublicKey + "\n",
+		sshKnownHostsKey:   fmt.Sprintf("@cert-authority %s %s\n", hosts, caPublicKey),
+	}
+	if privateKey != nil {
+		if pk, err := privateKey.Marshal(); err == nil {
+			data[sshPrivateKeySecret] = pk
+		}
+	}
+
+	return data
+}
+
+// computeSSHRenewalWindow returns the horizon until the certificate of o should
+// be renewed, and whether it is in its renewal window. The certificate is
+// renewed RenewBefore its valid_before time, or after two thirds of its
+// validity period if RenewBefore is not set.
+func computeSSHRenewalWindow(ctx context.Context, o *secretsv1beta1.VaultSSHCertificate,
+	jitterPercent float64,
+) (time.Duration, bool) {
+	logger := log.FromContext(ctx).WithValues(
+		"renewBefore", o.Spec.RenewBefore,
+		"validBefore", time.Unix(o.Status.ValidBefore, 0))
+
+	offset, err := parseDurationString(o.Spec.RenewBefore, ".spec.renewBefore", 0)
+	if err != nil {
+		logger.Info("Warning: tolerating invalid offset",
+			"err", err, "effectiveOffset", offset)
+	}
+
+	validBefore := time.Unix(o.Status.ValidBefore, 0)
+	rotationTime := validBefore.Add(-offset)
+	if offset <= 0 {
+		validAfter := time.Unix(o.Status.ValidAfter, 0)
+		if o.Status.ValidAfter <= 0 {
+			validAfter = time.Unix(o.Status.LastRotation, 0)
+		}
+		rotationTime = validAfter.Add(validBefore.Sub(validAfter) * 2 / 3)
+	}
+
+	now := nowFunc()
+	horizon := rotationTime.Sub(now)
+	var inWindow bool
+	if isInWindow(now, rotationTime) || horizon < minHorizon {
+		horizon = minHorizon
+		inWindow = true
+	}
+
+	_, jitter := computeMaxJitterDurationWithPercent(horizon, jitterPercent)
+	if inWindow {
+		horizon += jitter
+	} else {
+		horizon -= jitter
+	}
+
+	logger.V(consts.LogLevelDebug).WithValues(
+		"renewWhen", rotationTime, "now", now,
+		"serialNumber", o.Status.SerialNumber,
+		"horizon", horizon).Info("Computed certificate renewal window")
+
+	return horizon, inWindow
+}
+
+// sshCertTime converts an SSH certificate timestamp to a Unix timestamp.
+func sshCertTime(t uint64) int64 {
+	if t >= uint64(1<<63-1) {
+		return 1<<63 - 1
+	}
+	return int64(t)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+func Test_computeSSHRenewalWindow(t *testing.T) {
+	ctx := context.Background()
+	staticNow := time.Unix(time.Now().Unix(), 0)
+
+	tests := []struct {
+		name             string
+		renewBefore      string
+		validAfterDelta  int64
+		validBeforeDelta int64
+		wantInWindow     bool
+		wantMin          time.Duration
+		wantMax          time.Duration
+	}{
+		{
+			name:             "default-not-in-window",
+			validAfterDelta:  -60,
+			validBeforeDelta: 3540,
+			// renewed at 2/3 of the 1h validity, i.e. in 39m.
+			wantMin: time.Duration(0.95 * float64(39*time.Minute)),
+			wantMax: 39 * time.Minute,
+		},
+		{
+			name:             "default-in-window",
+			validAfterDelta:  -2460,
+			validBeforeDelta: 1140,
+			wantInWindow:     true,
+			wantMin:          time.Second,
+			wantMax:          time.Duration(1.05 * float64(time.Second)),
+		},
+		{
+			name:             "renew-before-not-in-window",
+			renewBefore:      "10m",
+			validAfterDelta:  -60,
+			validBeforeDelta: 3540,
+			wantMin:          time.Duration(0.95 * float64(49*time.Minute)),
+			wantMax:          49 * time.Minute,
+		},
+		{
+			name:             "renew-before-in-window",
+			renewBefore:      "10m",
+			validAfterDelta:  -3000,
+			validBeforeDelta: 540,
+			wantInWindow:     true,
+			wantMin:          time.Second,
+			wantMax:          time.Duration(1.05 * float64(time.Second)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nowFuncOrig := nowFunc
+			t.Cleanup(func() {
+				nowFunc = nowFuncOrig
+			})
+			nowFunc = func() time.Time { return staticNow }
+
+			o := &secretsv1beta1.VaultSSHCertificate{
+				Spec: secretsv1beta1.VaultSSHCertificateSpec{
+					RenewBefore: tt.renewBefore,
+				},
+				Status: secretsv1beta1.VaultSSHCertificateStatus{
+					ValidAfter:  staticNow.Unix() + tt.validAfterDelta,
+					ValidBefore: staticNow.Unix() + tt.validBeforeDelta,
+				},
+			}
+			gotHorizon, gotInWindow := computeSSHRenewalWindow(ctx, o, 0.05)
+			assert.Equal(t, tt.wantInWindow, gotInWindow)
+			assert.GreaterOrEqual(t, gotHorizon, tt.wantMin)
+			assert.LessOrEqual(t, gotHorizon, tt.wantMax)
+		})
+	}
+}
+
+func TestVaultSSHCertificateReconciler_getPublicKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	key, err := vault.GenerateSSHPrivateKey(vault.PKIKeyTypeEd25519, 0)
+	require.NoError(t, err)
+	publicKey, err := key.PublicKey()
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "ssh",
+		},
+		Data: map[string][]byte{
+			"ssh-publickey": []byte(publicKey + "\n"),
+			"other":         []byte("not-a-public-key"),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		keyPair        *secretsv1beta1.VaultSSHCertificateKeyPair
+		wantPublicKey  string
+		wantPrivateKey bool
+		wantType       string
+		wantErr        string
+	}{
+		{
+			name: "from-secret",
+			keyPair: &secretsv1beta1.VaultSSHCertificateKeyPair{
+				SecretRef: "ssh",
+			},
+			wantPublicKey: publicKey,
+			wantType:      ssh.KeyAlgoED25519,
+		},
+		{
+			name: "from-secret-invalid",
+			keyPair: &secretsv1beta1.VaultSSHCertificateKeyPair{
+				SecretRef: "ssh",
+				SecretKey: "other",
+			},
+			wantErr: `invalid public key in secret default/ssh, key="other"`,
+		},
+		{
+			name: "from-secret-missing-key",
+			keyPair: &secretsv1beta1.VaultSSHCertificateKeyPair{
+				SecretRef: "ssh",
+				SecretKey: "missing",
+			},
+			wantErr: `no public key found in secret default/ssh, key="missing"`,
+		},
+		{
+			name: "from-secret-not-found",
+			keyPair: &secretsv1beta1.VaultSSHCertificateKeyPair{
+				SecretRef: "missing",
+			},
+			wantErr: "not found",
+		},
+		{
+			name:           "generated-default",
+			wantPrivateKey: true,
+			wantType:       ssh.KeyAlgoED25519,
+		},
+		{
+			name: "generated-ec",
+			keyPair: &secretsv1beta1.VaultSSHCertificateKeyPair{
+				KeyType: vault.PKIKeyTypeEC,
+			},
+			wantPrivateKey: true,
+			wantType:       ssh.KeyAlgoECDSA256,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &VaultSSHCertificateReconciler{
+				Client: fake.NewClientBuilder().WithObjects(secret.DeepCopy()).Build(),
+			}
+			o := &secretsv1beta1.VaultSSHCertificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "ssh-cert",
+				},
+				Spec: secretsv1beta1.VaultSSHCertificateSpec{
+					KeyPair: tt.keyPair,
+				},
+			}
+			gotPublicKey, gotPrivateKey, err := r.getPublicKey(ctx, o)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantPublicKey != "" {
+				assert.Equal(t, tt.wantPublicKey, gotPublicKey)
+			}
+			assert.Equal(t, tt.wantPrivateKey, gotPrivateKey != nil)
+
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(gotPublicKey))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, pub.Type())
+		})
+	}
+}
+
+func Test_sshSignParams(t *testing.T) {
+	t.Parallel()
+
+	o := &secretsv1beta1.VaultSSHCertificate{
+		Spec: secretsv1beta1.VaultSSHCertificateSpec{
+			CertType:        "host",
+			ValidPrincipals: []string{"web-0.example.com", "web-1.example.com"},
+			KeyID:           "web",
+			TTL:             "24h",
+			Extensions: map[string]string{
+				"permit-pty": "",
+			},
+		},
+	}
+	assert.Equal(t, map[string]any{
+		"public_key":       "ssh-ed25519 AAAA",
+		"cert_type":        "host",
+		"valid_principals": "web-0.example.com,web-1.example.com",
+		"key_id":           "web",
+		"ttl":              "24h",
+		"extensions": map[string]string{
+			"permit-pty": "",
+		},
+	}, sshSignParams(o, "ssh-ed25519 AAAA"))
+
+	assert.Equal(t, map[string]any{
+		"public_key": "ssh-ed25519 AAAA",
+	}, sshSignParams(&secretsv1beta1.VaultSSHCertificate{}, "ssh-ed25519 AAAA"))
+}
+
+func Test_sshCertificateData(t *testing.T) {
+	t.Parallel()
+
+	certResp := &vault.SSHCertResponse{
+		SerialNumber: "c73f26d2340276aa",
+		SignedKey:    "ssh-ed25519-cert-v01@openssh.com AAAA\n",
+	}
+
+	tests := []struct {
+		name           string
+		spec           secretsv1beta1.VaultSSHCertificateSpec
+		wantKnownHosts string
+	}{
+		{
+			name: "user",
+			spec: secretsv1beta1.VaultSSHCertificateSpec{
+				ValidPrincipals: []string{"alice"},
+			},
+			wantKnownHosts: "@cert-authority * ssh-rsa CA\n",
+		},
+		{
+			name: "host",
+			spec: secretsv1beta1.VaultSSHCertificateSpec{
+				CertType:        "host",
+				ValidPrincipals: []string{"web-1.example.com", "web-0.example.com"},
+			},
+			wantKnownHosts: "@cert-authority web-0.example.com,web-1.example.com ssh-rsa CA\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := &secretsv1beta1.VaultSSHCertificate{
+				Spec: tt.spec,
+			}
+			assert.Equal(t, map[string]any{
+				"serial_number":   "c73f26d2340276aa",
+				"ssh-certificate": "ssh-ed25519-cert-v01@openssh.com AAAA\n",
+				"ssh-publickey":   "ssh-ed25519 AAAA\n",
+				"ca.pub":          "ssh-rsa CA\n",
+				"known_hosts":     tt.wantKnownHosts,
+			}, sshCertificateData(o, certResp, "ssh-rsa CA", "ssh-ed25519 AAAA", nil))
+		})
+	}
+}
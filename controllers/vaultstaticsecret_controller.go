@@ -7,7 +7,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -28,17 +31,24 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/vault/api"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
-
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
 	"github.com/hashicorp/vault-secrets-operator/vault"
 )
 
 const (
 	vaultStaticSecretFinalizer = "vaultstaticsecret.secrets.hashicorp.com/finalizer"
-	kvEventPath                = "/v1/sys/events/subscribe/kv*"
+	eventSubscribePathPrefix   = "/v1/sys/events/subscribe/"
+	// defaultEventType subscribes to all KV events.
+	defaultEventType = "kv*"
+	// conditionTypeSourceDeleted is the condition type that reports that the
+	// secret was deleted in Vault.
+	conditionTypeSourceDeleted = "SourceDeleted"
 )
 
 // VaultStaticSecretReconciler reconciles a VaultStaticSecret object
@@ -52,16 +62,44 @@ type VaultStaticSecretReconciler struct {
 	referenceCache              ResourceReferenceCache
 	GlobalTransformationOptions *helpers.GlobalTransformationOptions
 	BackOffRegistry             *BackOffRegistry
+	// Shedder defers the reconciliation of low priority resources under a large
+	// backlog, it is nil if load shedding is not enabled.
+	Shedder *ReconcileShedder
+	// FreezeWindow defers non-critical secret rotations and rollout-restarts
+	// during the freeze window, it is nil if no freeze window is configured.
+	FreezeWindow *FreezeWindow
+	// VaultPathPolicy restricts the Vault paths that the resources may
+	// reference, it is nil if no paths are restricted.
+	VaultPathPolicy *common.VaultPathPolicy
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// StartupSyncSmear spreads the initial reconciliation of the resources over
+	// a window after the operator starts, it is nil if smearing is not enabled.
+	StartupSyncSmear *StartupSyncSmear
+	// KVReadBatcher coalesces the KV reads of resources that share a Vault
+	// client and a KV mount, it is nil if batching is not enabled.
+	KVReadBatcher *KVReadBatcher
+	// SyncRegistry holds the resources whose consts.AnnotationForceSync
+	// annotation has changed, and that must be fully synced.
+	SyncRegistry *SyncRegistry
+	// SyncStatusRegistry maintains the aggregated sync status of all resources.
+	SyncStatusRegistry *SyncStatusRegistry
+	// NamespaceRemap maps renamed Vault namespaces to their new name, it is used
+	// when matching Vault events to instances.
+	NamespaceRemap common.NamespaceRemap
 	// SourceCh is used to trigger a requeue of resource instances from an
 	// external source. Should be set on a source.Channel in SetupWithManager.
 	// This channel should be closed when the controller is stopped.
 	SourceCh             chan event.GenericEvent
 	eventWatcherRegistry *eventWatcherRegistry
+	eventSubscriptions   *eventSubscriptionRegistry
 }
 
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultstaticsecrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultstaticsecrets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaultstaticsecrets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaulteventsubscriptions,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
 //
@@ -70,14 +108,23 @@ type VaultStaticSecretReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;patch
 //
 
 func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(VaultStaticSecret, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
 	o := &secretsv1beta1.VaultStaticSecret{}
 	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(VaultStaticSecret, req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
@@ -90,6 +137,49 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, r.handleDeletion(ctx, o)
 	}
 
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil {
+		return ctrl.Result{}, err
+	} else if suspended {
+		// the event watcher is restarted once the resource is resumed.
+		r.unWatchEvents(o)
+		return ctrl.Result{}, nil
+	}
+
+	if denied, err := handleVaultPathPolicy(ctx, r.Client, o, r.VaultPathPolicy, r.Recorder); err != nil {
+		return ctrl.Result{}, err
+	} else if denied {
+		// the event watcher is restarted once the resource's paths are allowed.
+		r.unWatchEvents(o)
+		return ctrl.Result{}, nil
+	}
+
+	if deferAfter, ok := r.Shedder.Shed(ctx, VaultStaticSecret, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	if deferAfter, ok := r.StartupSyncSmear.Defer(ctx, VaultStaticSecret, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	pendingAfter, err := r.FreezeWindow.HandlePending(ctx, r.Client, r.HMACValidator, o, r.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// forced syncs bypass the freeze window, the read cache, and the secret MAC
+	// check.
+	forceSync := r.SyncRegistry.Has(req.NamespacedName)
+	if _, frozen := r.FreezeWindow.Frozen(o); frozen && !forceSync && o.Status.LastGeneration == o.GetGeneration() {
+		// only the periodic syncs of existing secrets are deferred, the secret has
+		// no known expiry.
+		if exists, _ := helpers.CheckSecretExists(ctx, r.Client, o); exists {
+			if deferAfter, ok := r.FreezeWindow.DeferRotation(
+				ctx, r.Client, VaultStaticSecret, o, time.Time{}, r.Recorder); ok {
+				return ctrl.Result{RequeueAfter: deferAfter}, nil
+			}
+		}
+	}
+
 	c, err := r.ClientFactory.Get(ctx, r.Client, o)
 	if err != nil {
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonVaultClientConfigError,
@@ -111,7 +201,9 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	r.referenceCache.Set(SecretTransformation, req.NamespacedName,
 		helpers.GetTransformationRefObjKeys(
-			o.Spec.Destination.Transformation, o.Namespace)...)
+			o.Spec.Destination.Transformation, o.Namespace, r.GlobalTransformationOptions)...)
+	r.referenceCache.Set(VaultEventSubscription, req.NamespacedName,
+		eventSubscriptionObjKeys(o)...)
 
 	transOption, err := helpers.NewSecretTransformationOption(ctx, r.Client, o, r.GlobalTransformationOptions)
 	if err != nil {
@@ -126,26 +218,75 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
 	}
 
-	resp, err := c.Read(ctx, kvReq)
+	wrapCtx, err := withWrapTTL(ctx, o.Spec.WrapTTL)
+	if err != nil {
+		logger.Error(err, "Field validation failed")
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonVaultStaticSecret,
+			"Field validation failed, err=%s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	var resp vault.Response
+	if o.Spec.WrapTTL != "" {
+		// every wrapped response holds a distinct wrapping token, so they are never
+		// batched.
+		resp, err = c.Read(wrapCtx, kvReq)
+	} else if forceSync {
+		resp, err = c.Read(vault.WithReadCacheBypass(ctx), kvReq)
+	} else {
+		resp, err = r.KVReadBatcher.Read(ctx, c, o.Spec.Mount, kvReq)
+	}
+	if isSourceDeleted(o, resp, err) {
+		return r.handleSourceDeleted(ctx, o, requeueAfter, pendingAfter)
+	}
 	if err != nil {
+		if horizon, ok := handleThrottled(ctx, r.Client, o, r.Recorder, err); ok {
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
 		if vault.IsForbiddenError(err) {
 			c.Taint()
 		}
 
-		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName, syncBackoffOpts(ctx, o)...)
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonVaultClientError,
 			"Failed to read Vault secret: %s", err)
-		return ctrl.Result{RequeueAfter: entry.NextBackOff()}, nil
+		return ctrl.Result{RequeueAfter: nextBackOff(ctx, r.Client, o, entry)}, nil
 	} else {
-		r.BackOffRegistry.Delete(req.NamespacedName)
+		resetBackOff(r.BackOffRegistry, o)
+	}
+	o.Status.Conditions = removeConditions(o.Status.Conditions, conditionTypeSourceDeleted)
+
+	if o.Spec.WrapTTL != "" {
+		// wrapped secrets are synced on every reconciliation.
+		r.SyncRegistry.Delete(req.NamespacedName)
+		return r.syncWrappedSecret(ctx, c, o, resp, requeueAfter, pendingAfter)
 	}
 
-	data, err := r.SecretDataBuilder.WithVaultData(resp.Data(), resp.Secret().Data, transOption)
+	vaultData := resp.Data()
+	if o.Spec.TransitDecrypt != nil {
+		vaultData, err = transitDecryptData(ctx, c, o.Spec.TransitDecrypt, vaultData)
+		if err != nil {
+			if vault.IsForbiddenError(err) {
+				c.Taint()
+			}
+			r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTransitDecryptError,
+				"Failed to decrypt Vault secret data: %s", err)
+			return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+		}
+	}
+
+	data, err := r.SecretDataBuilder.WithVaultData(vaultData, resp.Secret().Data, transOption)
+	renderErr, err := handleTemplateRenderError(ctx, r.Client, o, data, err)
 	if err != nil {
 		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretDataBuilderError,
 			"Failed to build K8s secret data: %s", err)
 		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
 	}
+	if renderErr != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values for keys that failed to render: %s", renderErr)
+	}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), transOption, renderErr)
 
 	var doRolloutRestart bool
 	doSync := true
@@ -157,17 +298,19 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			requeueAfter = computeHorizonWithJitter(time.Second * 60)
 		}
 
-		// doRolloutRestart only if this is not the first time this secret has been synced
-		doRolloutRestart = o.Status.SecretMAC != ""
-
 		macsEqual, messageMAC, err := helpers.HandleSecretHMAC(ctx, r.Client, r.HMACValidator, o, data)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
 		}
 
+		// doRolloutRestart only if this is not the first time this secret has been
+		// synced, and the sync was not only forced.
+		doRolloutRestart = o.Status.SecretMAC != "" &&
+			(!macsEqual || o.Status.LastGeneration != o.GetGeneration())
+
 		// skip the next sync if the data has not changed since the last sync, and the
-		// resource has not been updated.
-		if o.Status.LastGeneration == o.GetGeneration() {
+		// resource has not been updated, nor forced to sync.
+		if o.Status.LastGeneration == o.GetGeneration() && !forceSync {
 			doSync = !macsEqual
 		}
 
@@ -179,7 +322,12 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	if doSync {
-		if err := helpers.SyncSecret(ctx, r.Client, o, data); err != nil {
+		opts := helpers.DefaultSyncOptions()
+		opts.Metadata = staticSecretMetadata(resp)
+		opts.HMACValidator = r.HMACValidator
+		err := helpers.SyncSecret(ctx, r.Client, o, data, opts)
+		handleSecretDataError(ctx, r.Client, o, err)
+		if err != nil {
 			r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
 				"Failed to update k8s secret: %s", err)
 			return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
@@ -187,21 +335,32 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		reason := consts.ReasonSecretSynced
 		if doRolloutRestart {
 			reason = consts.ReasonSecretRotated
-			// rollout-restart errors are not retryable
-			// all error reporting is handled by helpers.HandleRolloutRestarts
-			_ = helpers.HandleRolloutRestarts(ctx, r.Client, o, r.Recorder)
+			pendingAfter = minRequeueAfter(pendingAfter,
+				r.FreezeWindow.HandleRolloutRestarts(ctx, r.Client, r.HMACValidator, VaultStaticSecret, o, r.Recorder))
+		}
+		if forceSync {
+			reason = consts.ReasonForceSync
 		}
 		r.Recorder.Event(o, corev1.EventTypeNormal, reason, "Secret synced")
 	} else {
 		logger.V(consts.LogLevelDebug).Info("Secret sync not required")
 	}
 
+	if ok := r.SyncRegistry.Delete(req.NamespacedName); ok {
+		logger.V(consts.LogLevelDebug).Info("Deleted object from SyncRegistry",
+			"obj", req.NamespacedName)
+	}
+
+	r.updateSecretVersionStatus(ctx, c, o, resp)
+
 	if o.Spec.SyncConfig != nil && o.Spec.SyncConfig.InstantUpdates {
 		logger.V(consts.LogLevelDebug).Info("Event watcher enabled")
 		// ensure event watcher is running
-		if err := r.ensureEventWatcher(ctx, o, c); err != nil {
+		err := r.ensureEventWatcher(ctx, o, c)
+		if err != nil {
 			r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonEventWatcherError, "Failed to watch events: %s", err)
 		}
+		r.updateEventWatcherStatus(o, err)
 	} else {
 		// ensure event watcher is not running
 		r.unWatchEvents(o)
@@ -212,7 +371,146 @@ func (r *VaultStaticSecretReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	return ctrl.Result{
-		RequeueAfter: requeueAfter,
+		RequeueAfter: minRequeueAfter(requeueAfter, pendingAfter),
+	}, nil
+}
+
+// staticSecretMetadata returns the metadata of the KV v2 secret in resp, e.g.
+// its version and created_time, that the destination's label and annotation
+// templates are rendered with. It is nil for KV v1 secrets.
+func staticSecretMetadata(resp vault.Response) map[string]any {
+	if s := resp.Secret(); s != nil {
+		if m, ok := s.Data["metadata"].(map[string]any); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+// syncWrappedSecret syncs the response wrapping token of the wrapped resp to
+// the destination Secret. The token is synced on every reconciliation, and the
+// next reconciliation is scheduled before the token expires.
+func (r *VaultStaticSecretReconciler) syncWrappedSecret(ctx context.Context, c vault.Client,
+	o *secretsv1beta1.VaultStaticSecret, resp vault.Response, requeueAfter, pendingAfter time.Duration,
+) (ctrl.Result, error) {
+	data, annotations, err := wrappedSecretData(resp)
+	if err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretDataBuilderError,
+			"Failed to build K8s secret data: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	opts := helpers.DefaultSyncOptions()
+	opts.Annotations = annotations
+	opts.HMACValidator = r.HMACValidator
+	err = helpers.SyncSecret(ctx, r.Client, o, data, opts)
+	handleSecretDataError(ctx, r.Client, o, err)
+	if err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
+			"Failed to update k8s secret: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+	r.Recorder.Event(o, corev1.EventTypeNormal, consts.ReasonSecretSynced, "Secret synced")
+
+	// the secret MAC is meaningless, since every wrapping token is distinct.
+	o.Status.SecretMAC = ""
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), nil, nil)
+	r.updateSecretVersionStatus(ctx, c, o, resp)
+	r.unWatchEvents(o)
+	if err := r.updateStatus(ctx, o); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	wrapHorizon := computeHorizonWithJitter(time.Duration(resp.Secret().WrapInfo.TTL) * time.Second)
+	return ctrl.Result{
+		RequeueAfter: minRequeueAfter(minRequeueAfter(requeueAfter, wrapHorizon), pendingAfter),
+	}, nil
+}
+
+// isSourceDeleted returns true if the read of o's secret from Vault found it
+// deleted, and the deletion should be handled by handleSourceDeleted. A missing
+// secret is only handled as deleted once it was synced, or the
+// onSourceDelete policy requires the destination Secret to be emptied or
+// deleted. Otherwise, e.g. for a new resource that points at a path that does
+// not exist, it is handled like any other read error.
+func isSourceDeleted(o *secretsv1beta1.VaultStaticSecret, resp vault.Response, err error) bool {
+	if !vault.IsEmptyResponseError(err) && (err != nil || !vault.IsKVV2SecretDeleted(resp)) {
+		return false
+	}
+
+	return o.Status.SecretMAC != "" ||
+		hasCondition(o.Status.Conditions, conditionTypeSourceDeleted) ||
+		(o.Spec.OnSourceDelete != "" && o.Spec.OnSourceDelete != "retain")
+}
+
+// handleSourceDeleted handles the deletion of o's secret in Vault, as set by
+// o.Spec.OnSourceDelete. The SourceDeleted condition is set on o until the
+// secret is recreated, which is detected by the next reconciliation.
+func (r *VaultStaticSecretReconciler) handleSourceDeleted(ctx context.Context,
+	o *secretsv1beta1.VaultStaticSecret, requeueAfter, pendingAfter time.Duration,
+) (ctrl.Result, error) {
+	objKey := client.ObjectKeyFromObject(o)
+	var err error
+	var message string
+	switch o.Spec.OnSourceDelete {
+	case "", "retain":
+		message = "The Vault secret was deleted, retaining the destination Secret"
+	case "delete":
+		if o.Spec.Destination.Create {
+			message = "The Vault secret was deleted, deleted the destination Secret"
+			err = helpers.DeleteSecret(ctx, r.Client, client.ObjectKey{
+				Namespace: o.Namespace,
+				Name:      o.Spec.Destination.Name,
+			})
+			break
+		}
+		// the destination Secret was not created by the operator, so it is only
+		// emptied.
+		fallthrough
+	case "empty":
+		message = "The Vault secret was deleted, emptied the destination Secret"
+		opts := helpers.DefaultSyncOptions()
+		opts.HMACValidator = r.HMACValidator
+		err = helpers.SyncSecret(ctx, r.Client, o, map[string][]byte{}, opts)
+	default:
+		err = fmt.Errorf("unsupported onSourceDelete %q", o.Spec.OnSourceDelete)
+	}
+	if err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonSecretSyncError,
+			"Failed to handle the deletion of the Vault secret: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	if o.Spec.OnSourceDelete != "" && o.Spec.OnSourceDelete != "retain" {
+		// the secret is synced once it is recreated in Vault.
+		o.Status.SecretMAC = ""
+	}
+
+	if replaceCondition(&o.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeSourceDeleted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: o.GetGeneration(),
+		Reason:             consts.ReasonSourceDeleted,
+		Message:            message,
+	}) {
+		r.Recorder.Event(o, corev1.EventTypeWarning, consts.ReasonSourceDeleted, message)
+	}
+
+	r.SyncRegistry.Delete(objKey)
+	o.Status.SecretVersion = 0
+	if err := r.updateStatus(ctx, o); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the secret is read again to detect its recreation, the event watcher is kept
+	// running when instant updates are enabled.
+	if requeueAfter == 0 {
+		entry, _ := r.BackOffRegistry.Get(objKey, syncBackoffOpts(ctx, o)...)
+		requeueAfter = nextBackOff(ctx, r.Client, o, entry)
+	}
+
+	return ctrl.Result{
+		RequeueAfter: minRequeueAfter(requeueAfter, pendingAfter),
 	}, nil
 }
 
@@ -231,9 +529,15 @@ func (r *VaultStaticSecretReconciler) updateStatus(ctx context.Context, o *secre
 
 func (r *VaultStaticSecretReconciler) handleDeletion(ctx context.Context, o client.Object) error {
 	logger := log.FromContext(ctx)
+	if err := finalizeDestinationSecrets(ctx, r.Client, r.Recorder, o, vaultStaticSecretFinalizer); err != nil {
+		return err
+	}
+
 	objKey := client.ObjectKeyFromObject(o)
 	r.referenceCache.Remove(SecretTransformation, objKey)
+	r.referenceCache.Remove(VaultEventSubscription, objKey)
 	r.BackOffRegistry.Delete(objKey)
+	r.SyncRegistry.Delete(objKey)
 	r.unWatchEvents(o.(*secretsv1beta1.VaultStaticSecret))
 	if controllerutil.ContainsFinalizer(o, vaultStaticSecretFinalizer) {
 		logger.Info("Removing finalizer")
@@ -248,81 +552,272 @@ func (r *VaultStaticSecretReconciler) handleDeletion(ctx context.Context, o clie
 	return nil
 }
 
+// ensureEventWatcher subscribes o to the event watcher that is shared by all
+// VaultStaticSecrets with the same Vault client and namespace. The event
+// watcher is started if it is not already running. If o references a
+// VaultEventSubscription, the event watcher is shared by all the
+// VaultStaticSecrets that reference it, and it uses the subscription's Vault
+// client.
 func (r *VaultStaticSecretReconciler) ensureEventWatcher(ctx context.Context, o *secretsv1beta1.VaultStaticSecret, c vault.Client) error {
 	logger := log.FromContext(ctx).WithName("ensureEventWatcher")
 	name := client.ObjectKeyFromObject(o)
 
+	var debounce time.Duration
+	eventTypes := []string{defaultEventType}
+	if cfg := o.Spec.SyncConfig.InstantUpdatesConfig; cfg != nil {
+		var err error
+		debounce, err = parseDurationString(cfg.DebounceWindow,
+			".spec.syncConfig.instantUpdatesConfig.debounceWindow", 0)
+		if err != nil {
+			return err
+		}
+		if len(cfg.EventTypes) > 0 {
+			eventTypes = sortedEventTypes(cfg.EventTypes)
+		}
+	}
+
+	// the Vault namespace of o's own client, the events are matched against
+	// it.
+	vssNamespace := strings.Trim(c.Namespace(), "/")
+	var clientObj client.Object = o
+	var subObj *secretsv1beta1.VaultEventSubscription
+	if ref := o.Spec.SyncConfig.EventSubscriptionRef; ref != "" {
+		var err error
+		subObj, eventTypes, err = r.getEventSubscription(ctx, o, ref)
+		if err != nil {
+			r.unWatchEvents(o)
+			return err
+		}
+		if debounce == 0 {
+			// the subscription's pattern is validated by its own controller.
+			debounce, _ = parseDurationString(subObj.Spec.DebounceWindow,
+				".spec.debounceWindow", 0)
+		}
+
+		c, err = r.ClientFactory.Get(ctx, r.Client, subObj)
+		if err != nil {
+			r.unWatchEvents(o)
+			return fmt.Errorf("failed to get the Vault client of VaultEventSubscription %s: %w",
+				client.ObjectKeyFromObject(subObj), err)
+		}
+		clientObj = subObj
+	}
+
+	eventPath, filter := eventSubscribePath(eventTypes)
+	wsClient, err := newEventWebsocketClient(c, eventPath, filter)
+	if err != nil {
+		return err
+	}
+	namespace := strings.Trim(wsClient.Headers.Get(api.NamespaceHeaderName), "/")
+	if subObj == nil {
+		vssNamespace = namespace
+	}
+	key := eventSubscriptionKey(c.ID(), namespace, eventTypes)
+
+	var subGeneration int64
+	if subObj != nil {
+		subGeneration = subObj.GetGeneration()
+	}
+
 	meta, ok := r.eventWatcherRegistry.Get(name)
 	if ok {
-		// The watcher is running, and if the VSS object has not been updated,
+		// The object is subscribed, and if the VSS object has not been updated,
 		// and the client ID is the same, just return
-		if meta.LastGeneration == o.GetGeneration() && meta.LastClientID == c.ID() {
+		if meta.LastGeneration == o.GetGeneration() && meta.LastClientID == c.ID() &&
+			meta.SubscriptionGeneration == subGeneration &&
+			meta.SubscriptionKey == key && r.eventSubscriptions.Subscribed(key, name) {
 			logger.V(consts.LogLevelDebug).Info("Event watcher already running",
 				"namespace", o.Namespace, "name", o.Name)
 			return nil
 		}
+		if meta.SubscriptionKey != key {
+			// The vault client has changed, so unsubscribe from the previous
+			// event watcher, it is stopped once it has no subscribers left.
+			r.eventSubscriptions.Unsubscribe(meta.SubscriptionKey, name)
+		}
+	}
+
+	var subPathFilters []string
+	if subObj != nil {
+		subPathFilters = subObj.Spec.PathFilters
 	}
-	if meta != nil {
-		// The watcher is running, but the metadata or vault client has changed,
-		// so kill it
-		if meta.Cancel != nil {
-			meta.Cancel()
-			// Wait for the goroutine to stop and remove itself from the event registry
-			waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
-			defer cancel()
-			if err := waitForStoppedCh(waitCtx, meta.StoppedCh); err != nil {
-				logger.Error(err, "Failed to stop event watcher for VSS", "name", name)
+	sub := &eventSubscriber{
+		obj:      o.DeepCopy(),
+		match:    r.staticSecretEventMatcher(o, vssNamespace, eventTypes, subPathFilters),
+		debounce: debounce,
+	}
+	var watchCtx context.Context
+	connected := r.eventSubscriptions.Subscribe(key, name, sub,
+		func() *eventSubscription {
+			var cancel context.CancelFunc
+			watchCtx, cancel = context.WithCancel(context.Background())
+			return &eventSubscription{
+				clientID:  c.ID(),
+				clientObj: clientObj.DeepCopyObject().(client.Object),
+				eventPath: eventPath,
+				filter:    filter,
+				wsClient:  wsClient,
+				cancel:    cancel,
 			}
-		} else {
-			logger.Error(fmt.Errorf("nil cancel function"), "event watcher has nil cancel function", "VSS", name, "meta", meta)
-		}
+		},
+		func(s *eventSubscription) {
+			// launch the goroutine to watch events
+			logger.V(consts.LogLevelDebug).Info("Starting event watcher", "key", key)
+			go r.getEvents(watchCtx, s)
+		},
+	)
+
+	r.eventWatcherRegistry.Register(name, &eventWatcherMeta{
+		SubscriptionKey:        key,
+		LastClientID:           c.ID(),
+		LastGeneration:         o.GetGeneration(),
+		SubscriptionGeneration: subGeneration,
+	})
+	if connected {
+		r.Recorder.Event(o, corev1.EventTypeNormal, consts.ReasonEventWatcherStarted, "Started watching events")
 	}
-	wsClient, err := c.WebsocketClient(kvEventPath)
+
+	return nil
+}
+
+// getEventSubscription returns the VaultEventSubscription referenced by o,
+// along with its sorted event types. The event types of o's
+// InstantUpdatesConfig must not be set, since they are defined by the
+// subscription.
+func (r *VaultStaticSecretReconciler) getEventSubscription(ctx context.Context, o *secretsv1beta1.VaultStaticSecret, ref string) (*secretsv1beta1.VaultEventSubscription, []string, error) {
+	if cfg := o.Spec.SyncConfig.InstantUpdatesConfig; cfg != nil && len(cfg.EventTypes) > 0 {
+		return nil, nil, errors.New(
+			".spec.syncConfig.instantUpdatesConfig.eventTypes must not be set along with .spec.syncConfig.eventSubscriptionRef")
+	}
+
+	subObj, err := common.GetVaultEventSubscriptionForObj(ctx, r.Client, o, ref)
 	if err != nil {
-		return fmt.Errorf("failed to create websocket client: %w", err)
+		return nil, nil, err
+	}
+	if subObj.Status.Valid != nil && !*subObj.Status.Valid {
+		return nil, nil, fmt.Errorf("VaultEventSubscription %s is invalid: %s",
+			client.ObjectKeyFromObject(subObj), subObj.Status.Error)
 	}
 
-	watchCtx, cancel := context.WithCancel(context.Background())
-	stoppedCh := make(chan struct{}, 1)
-	updatedMeta := &eventWatcherMeta{
-		Cancel:         cancel,
-		LastClientID:   c.ID(),
-		LastGeneration: o.GetGeneration(),
-		StoppedCh:      stoppedCh,
+	eventTypes := []string{defaultEventType}
+	if len(subObj.Spec.EventTypes) > 0 {
+		eventTypes = sortedEventTypes(subObj.Spec.EventTypes)
 	}
-	// launch the goroutine to watch events
-	logger.V(consts.LogLevelDebug).Info("Starting event watcher", "meta", updatedMeta)
-	r.eventWatcherRegistry.Register(name, updatedMeta)
-	// Pass a dereferenced VSS object here because it seems to avoid an issue
-	// where the EventWatcherStarted event is occasionally emitted without a
-	// name or namespace attached.
-	go r.getEvents(watchCtx, *o, wsClient, stoppedCh)
 
-	return nil
+	return subObj, eventTypes, nil
 }
 
-// unWatchEvents - If the VSS is in the registry, cancel its event watcher
-// context to close the goroutine, and remove the VSS from the registry
+// eventSubscriptionObjKeys returns the object key of the VaultEventSubscription
+// that o receives its instant updates from, if any.
+func eventSubscriptionObjKeys(o *secretsv1beta1.VaultStaticSecret) []client.ObjectKey {
+	cfg := o.Spec.SyncConfig
+	if cfg == nil || !cfg.InstantUpdates || cfg.EventSubscriptionRef == "" {
+		return nil
+	}
+
+	ref, err := common.ParseResourceRef(cfg.EventSubscriptionRef, o.Namespace)
+	if err != nil {
+		return nil
+	}
+
+	return []client.ObjectKey{ref}
+}
+
+// newEventWebsocketClient returns a websocket client that subscribes to the
+// Vault events of eventPath, filtered by Vault with filter.
+func newEventWebsocketClient(c vault.Client, eventPath, filter string) (*vault.WebsocketClient, error) {
+	wsClient, err := c.WebsocketClient(eventPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create websocket client: %w", err)
+	}
+	if err := wsClient.SetFilter(filter); err != nil {
+		return nil, err
+	}
+	return wsClient, nil
+}
+
+// staticSecretEventMatcher returns a function that matches the Vault events of
+// eventTypes that trigger the sync of o. The events must be for the KV secret
+// that is synced by o, or match one of the PathFilters of its
+// InstantUpdatesConfig. The namespace is the Vault namespace of o's client.
+// The subPathFilters of o's VaultEventSubscription, if any, further restrict
+// the events.
+func (r *VaultStaticSecretReconciler) staticSecretEventMatcher(o *secretsv1beta1.VaultStaticSecret, namespace string, eventTypes, subPathFilters []string) func(string, string, string) bool {
+	specPath := strings.Join([]string{o.Spec.Mount, o.Spec.Path}, "/")
+	if o.Spec.Type == consts.KVSecretTypeV2 {
+		specPath = strings.Join([]string{o.Spec.Mount, "data", o.Spec.Path}, "/")
+	}
+	specNamespace := r.NamespaceRemap.Remap(o.Spec.Namespace)
+	if common.IsRelativeVaultNamespace(o.Spec.Namespace) {
+		// relative namespaces are resolved by the client factory, the
+		// websocket client is bound to the resolved namespace.
+		specNamespace = namespace
+	}
+
+	matchPath := func(path string) bool {
+		return path == specPath
+	}
+	if cfg := o.Spec.SyncConfig; cfg != nil && cfg.InstantUpdatesConfig != nil &&
+		len(cfg.InstantUpdatesConfig.PathFilters) > 0 {
+		pathFilters := compileEventGlobs(cfg.InstantUpdatesConfig.PathFilters)
+		matchPath = func(path string) bool {
+			return matchAny(pathFilters, path)
+		}
+	}
+	if len(subPathFilters) > 0 {
+		subFilters := compileEventGlobs(subPathFilters)
+		matchSpecPath := matchPath
+		matchPath = func(path string) bool {
+			return matchAny(subFilters, path) && matchSpecPath(path)
+		}
+	}
+	typeGlobs := compileEventGlobs(eventTypes)
+
+	return func(namespace, eventType, path string) bool {
+		return namespace == specNamespace && matchAny(typeGlobs, eventType) && matchPath(path)
+	}
+}
+
+// unWatchEvents - If the VSS is in the registry, unsubscribe it from its event
+// watcher, and remove the VSS from the registry. The event watcher is stopped
+// once it has no subscribers left.
 func (r *VaultStaticSecretReconciler) unWatchEvents(o *secretsv1beta1.VaultStaticSecret) {
 	name := client.ObjectKeyFromObject(o)
 	meta, ok := r.eventWatcherRegistry.Get(name)
 	if ok {
-		if meta.Cancel != nil {
-			meta.Cancel()
-		}
+		r.eventSubscriptions.Unsubscribe(meta.SubscriptionKey, name)
 		r.eventWatcherRegistry.Delete(name)
 	}
+	o.Status.EventWatcher = nil
+	o.Status.Conditions = eventWatcherConditions(o.Status.Conditions, o.GetGeneration(), false, eventWatcherHealth{}, nil)
+}
+
+// updateEventWatcherStatus sets the EventWatcher status and the
+// EventWatcherHealthy condition from the health of o's event watcher. The err
+// is the error returned by ensureEventWatcher.
+func (r *VaultStaticSecretReconciler) updateEventWatcherStatus(o *secretsv1beta1.VaultStaticSecret, err error) {
+	var health eventWatcherHealth
+	name := client.ObjectKeyFromObject(o)
+	if meta, ok := r.eventWatcherRegistry.Get(name); ok {
+		health, _ = r.eventSubscriptions.Health(meta.SubscriptionKey, name)
+	}
+
+	status := &secretsv1beta1.VaultStaticSecretEventWatcher{
+		Connected:      health.Connected,
+		ReconnectCount: health.ReconnectCount,
+	}
+	if !health.LastEventTime.IsZero() {
+		status.LastEventTime = health.LastEventTime.Unix()
+	}
+	o.Status.EventWatcher = status
+	o.Status.Conditions = eventWatcherConditions(o.Status.Conditions, o.GetGeneration(), true, health, err)
 }
 
 // getEvents calls streamStaticSecretEvents in a loop, collecting and responding
 // to any errors returned.
-func (r *VaultStaticSecretReconciler) getEvents(ctx context.Context, o secretsv1beta1.VaultStaticSecret, wsClient *vault.WebsocketClient, stoppedCh chan struct{}) {
-	logger := log.FromContext(ctx).WithName("getEvents")
-	name := client.ObjectKeyFromObject(&o)
-	defer func() {
-		r.eventWatcherRegistry.Delete(name)
-		close(stoppedCh)
-	}()
+func (r *VaultStaticSecretReconciler) getEvents(ctx context.Context, s *eventSubscription) {
+	logger := log.FromContext(ctx).WithName("getEvents").WithValues("key", s.key)
+	defer r.eventSubscriptions.Remove(s)
 
 	// Use the same backoff options used for Vault reads in Reconcile()
 	retryBackoff := backoff.NewExponentialBackOff(r.BackOffRegistry.opts...)
@@ -335,8 +830,7 @@ eventLoop:
 	for {
 		select {
 		case <-ctx.Done():
-			logger.V(consts.LogLevelDebug).Info("Context done, stopping getEvents",
-				"namespace", o.Namespace, "name", o.Name)
+			logger.V(consts.LogLevelDebug).Info("Context done, stopping getEvents")
 			return
 		default:
 			if shouldBackoff {
@@ -347,7 +841,7 @@ eventLoop:
 				}
 				time.Sleep(retryBackoff.NextBackOff())
 			}
-			err := r.streamStaticSecretEvents(ctx, &o, wsClient)
+			err := r.streamStaticSecretEvents(ctx, s)
 			if err != nil {
 				if strings.Contains(err.Error(), "use of closed network connection") ||
 					strings.Contains(err.Error(), "context canceled") {
@@ -355,61 +849,67 @@ eventLoop:
 					// exit the goroutine (and the defer will remove this from
 					// the registry)
 					logger.V(consts.LogLevelDebug).Info(
-						"Websocket client closed, stopping GetEvents for",
-						"namespace", o.Namespace, "name", o.Name)
+						"Websocket client closed, stopping GetEvents")
 					return
 				}
 
 				errorCount++
+				s.failed()
 				shouldBackoff = true
 
 				// For any other errors, we emit the error as an event on the
-				// VaultStaticSecret, reload the client and try connecting
-				// again.
-				r.Recorder.Eventf(&o, corev1.EventTypeWarning, consts.ReasonEventWatcherError,
-					"Error while watching events: %s", err)
+				// subscribed VaultStaticSecrets, reload the client and try
+				// connecting again.
+				subs := s.all()
+				for _, sub := range subs {
+					r.Recorder.Eventf(sub.obj, corev1.EventTypeWarning, consts.ReasonEventWatcherError,
+						"Error while watching events: %s", err)
+				}
 
 				if errorCount >= errorThreshold {
 					logger.Error(err, "Too many errors while watching events, requeuing")
 					break eventLoop
 				}
+				if len(subs) == 0 {
+					return
+				}
 
-				newVaultClient, err := r.ClientFactory.Get(ctx, r.Client, &o)
+				clientObj := s.clientObj
+				if clientObj == nil {
+					clientObj = subs[0].obj
+				}
+				newVaultClient, err := r.ClientFactory.Get(ctx, r.Client, clientObj)
 				if err != nil {
 					logger.Error(err, "Failed to retrieve Vault client")
 					break eventLoop
-				} else {
-					wsClient, err = newVaultClient.WebsocketClient(kvEventPath)
-					if err != nil {
-						logger.Error(err, "Failed to create new websocket client")
-						break eventLoop
-					}
 				}
-
-				// Update the LastClientID in the event registry
-				key := client.ObjectKeyFromObject(&o)
-				meta, ok := r.eventWatcherRegistry.Get(key)
-				if !ok {
-					logger.Error(
-						fmt.Errorf("failed to get event watcher metadata for VaultStaticSecret"),
-						"key", key.String())
+				if newVaultClient.ID() != s.clientID {
+					// The subscribers must resubscribe to the event watcher of
+					// the new Vault client.
+					logger.V(consts.LogLevelDebug).Info("Vault client changed, requeuing")
+					break eventLoop
+				}
+				s.wsClient, err = newEventWebsocketClient(newVaultClient, s.eventPath, s.filter)
+				if err != nil {
+					logger.Error(err, "Failed to create new websocket client")
 					break eventLoop
 				}
-				meta.LastClientID = newVaultClient.ID()
-				r.eventWatcherRegistry.Register(key, meta)
 			}
 		}
 	}
 
 	// If we've reached this point, we've encountered too many errors and need
-	// to close this watcher and requeue the resource
-	r.SourceCh <- event.GenericEvent{
-		Object: &secretsv1beta1.VaultStaticSecret{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: o.Namespace,
-				Name:      o.Name,
+	// to close this watcher and requeue its subscribers
+	r.eventSubscriptions.Remove(s)
+	for _, sub := range s.all() {
+		sendSourceEvent(ctx, VaultStaticSecret, r.SourceCh, event.GenericEvent{
+			Object: &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: sub.obj.GetNamespace(),
+					Name:      sub.obj.GetName(),
+				},
 			},
-		},
+		})
 	}
 }
 
@@ -423,27 +923,51 @@ type eventMsg struct {
 				Modified string `json:"modified"`
 			} `json:"metadata"`
 		} `json:"event"`
+		EventType string `json:"event_type"`
 		Namespace string `json:"namespace"`
 	} `json:"data"`
 }
 
-func (r *VaultStaticSecretReconciler) streamStaticSecretEvents(ctx context.Context, o *secretsv1beta1.VaultStaticSecret, wsClient *vault.WebsocketClient) error {
-	logger := log.FromContext(ctx).WithName("streamStaticSecretEvents")
-	conn, err := wsClient.Connect(ctx)
+// streamStaticSecretEvents reads the events of the subscription's websocket
+// connection, and requeues the subscribed VaultStaticSecrets that match the
+// modified secrets.
+func (r *VaultStaticSecretReconciler) streamStaticSecretEvents(ctx context.Context, s *eventSubscription) error {
+	logger := log.FromContext(ctx).WithName("streamStaticSecretEvents").WithValues("key", s.key)
+	conn, err := s.wsClient.Connect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to vault websocket: %w", err)
 	}
-	defer conn.Close(websocket.StatusNormalClosure, "closing event watcher")
+	defer func() {
+		s.setConnected(false)
+		metrics.DecEventWatchersActive(metricsController(VaultStaticSecret))
+		conn.Close(websocket.StatusNormalClosure, "closing event watcher")
+	}()
 
 	// We made it past the initial websocket connection, so emit a "good" event
 	// status
-	r.Recorder.Event(o, corev1.EventTypeNormal, consts.ReasonEventWatcherStarted, "Started watching events")
+	subs := s.setConnected(true)
+	metrics.IncEventWatchersActive(metricsController(VaultStaticSecret))
+	if s.reconnectCount() > 0 {
+		metrics.IncEventWatcherReconnects(metricsController(VaultStaticSecret))
+	}
+	for _, sub := range subs {
+		r.Recorder.Event(sub.obj, corev1.EventTypeNormal, consts.ReasonEventWatcherStarted, "Started watching events")
+		// requeue the subscribers to report the connection in their status,
+		// and to sync any changes that were missed while disconnected.
+		trySendSourceEvent(ctx, VaultStaticSecret, r.SourceCh, event.GenericEvent{
+			Object: &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: sub.obj.GetNamespace(),
+					Name:      sub.obj.GetName(),
+				},
+			},
+		})
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			logger.V(consts.LogLevelDebug).Info("Context done, closing websocket",
-				"namespace", o.Namespace, "name", o.Name)
+			logger.V(consts.LogLevelDebug).Info("Context done, closing websocket")
 			return nil
 		default:
 			msgType, message, err := conn.Read(ctx)
@@ -459,33 +983,39 @@ func (r *VaultStaticSecretReconciler) streamStaticSecretEvents(ctx context.Conte
 			logger.V(consts.LogLevelTrace).Info("Received message",
 				"message type", msgType, "message", messageMap)
 
-			modified, err := parseutil.ParseBool(messageMap.Data.Event.Metadata.Modified)
-			if err != nil {
-				return fmt.Errorf("failed to parse modified field: %w", err)
+			// Only the KV events have a modified field, the events of the
+			// other secrets engines, e.g. database/rotate, always modify
+			// their secret.
+			modified := true
+			if v := messageMap.Data.Event.Metadata.Modified; v != "" {
+				modified, err = parseutil.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("failed to parse modified field: %w", err)
+				}
 			}
 
 			if modified {
 				namespace := strings.Trim(messageMap.Data.Namespace, "/")
+				eventType := messageMap.Data.EventType
 				path := messageMap.Data.Event.Metadata.Path
-				specPath := strings.Join([]string{o.Spec.Mount, o.Spec.Path}, "/")
-
-				if o.Spec.Type == consts.KVSecretTypeV2 {
-					specPath = strings.Join([]string{o.Spec.Mount, "data", o.Spec.Path}, "/")
-				}
 				logger.V(consts.LogLevelTrace).Info("modified Event received from Vault",
-					"namespace", namespace, "path", path, "spec.namespace", o.Spec.Namespace,
-					"spec path", specPath)
-				if namespace == o.Spec.Namespace && path == specPath {
+					"namespace", namespace, "eventType", eventType, "path", path)
+				for _, sub := range s.matching(namespace, eventType, path) {
+					objKey := client.ObjectKeyFromObject(sub.obj)
 					logger.V(consts.LogLevelDebug).Info("Event matches, sending requeue",
-						"namespace", namespace, "path", path)
-					r.SourceCh <- event.GenericEvent{
-						Object: &secretsv1beta1.VaultStaticSecret{
-							ObjectMeta: metav1.ObjectMeta{
-								Namespace: o.Namespace,
-								Name:      o.Name,
+						"namespace", namespace, "eventType", eventType, "path", path,
+						"vss", objKey, "debounce", sub.debounce)
+					s.debounce(objKey, sub, func() {
+						// never block the websocket reader on a full SourceCh
+						trySendSourceEvent(ctx, VaultStaticSecret, r.SourceCh, event.GenericEvent{
+							Object: &secretsv1beta1.VaultStaticSecret{
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace: objKey.Namespace,
+									Name:      objKey.Name,
+								},
 							},
-						},
-					}
+						})
+					})
 				}
 			} else {
 				// This is an event we're not interested in, ignore it and
@@ -499,21 +1029,37 @@ func (r *VaultStaticSecretReconciler) streamStaticSecretEvents(ctx context.Conte
 }
 
 func (r *VaultStaticSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(VaultStaticSecret, r.Recorder)
 	r.referenceCache = newResourceReferenceCache()
 	if r.BackOffRegistry == nil {
 		r.BackOffRegistry = NewBackOffRegistry()
 	}
-	r.SourceCh = make(chan event.GenericEvent)
+	if r.SyncRegistry == nil {
+		r.SyncRegistry = NewSyncRegistry()
+	}
+	r.SourceCh = newSourceChannel()
 	r.eventWatcherRegistry = newEventWatcherRegistry()
+	r.eventSubscriptions = newEventSubscriptionRegistry()
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&secretsv1beta1.VaultStaticSecret{}).
-		WithEventFilter(syncableSecretPredicate(nil)).
+		WithEventFilter(forceSyncableSecretPredicate(r.SyncRegistry)).
 		WithOptions(opts).
 		Watches(
 			&secretsv1beta1.SecretTransformation{},
 			NewEnqueueRefRequestsHandlerST(r.referenceCache, nil),
 		).
+		Watches(
+			&secretsv1beta1.VaultEventSubscription{},
+			&enqueueRefRequestsHandler{
+				kind:     VaultEventSubscription,
+				refCache: r.referenceCache,
+				// the references are maintained by the referring
+				// VaultStaticSecret, which must unsubscribe when the
+				// VaultEventSubscription is deleted.
+				enqueueOnDelete: true,
+			},
+		).
 		// In order to reduce the operator's memory usage, we only watch for the
 		// Secret's metadata. That is sufficient for us to know when a Secret is
 		// deleted. If we ever need to access to the Secret's data, we can always fetch
@@ -526,14 +1072,74 @@ func (r *VaultStaticSecretReconciler) SetupWithManager(mgr ctrl.Manager, opts co
 			},
 			builder.WithPredicates(&secretsPredicate{}),
 		).
+		// Enforced destination Secrets are resynced as soon as their data drifts, the
+		// data is only fetched for the Secrets that were not updated by the operator.
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDriftRequestHandler{
+				client:    r.Client,
+				validator: r.HMACValidator,
+				recorder:  r.Recorder,
+				kind:      VaultStaticSecret,
+			},
+			builder.WithPredicates(&secretsDriftPredicate{}),
+		).
 		WatchesRawSource(
 			source.Channel(r.SourceCh,
 				&enqueueDelayingSyncEventHandler{
 					enqueueDurationForJitter: time.Second * 2,
+					kind:                     VaultStaticSecret,
 				},
 			),
 		).
-		Complete(r)
+		Complete(r.SyncStatusRegistry.Reconciler(VaultStaticSecret, r))
+}
+
+// EventWatcherCount returns the number of running Vault event watchers, each
+// watcher is shared by all VaultStaticSecrets with the same Vault client and
+// namespace.
+func (r *VaultStaticSecretReconciler) EventWatcherCount() int {
+	if r.eventSubscriptions == nil {
+		return 0
+	}
+	return r.eventSubscriptions.Len()
+}
+
+// updateSecretVersionStatus records the synced and current versions of the
+// KV-v2 secret in the status of o. When o is pinned to a version, the current
+// version is read from the secret's metadata.
+func (r *VaultStaticSecretReconciler) updateSecretVersionStatus(ctx context.Context, c vault.Client,
+	o *secretsv1beta1.VaultStaticSecret, resp vault.Response,
+) {
+	o.Status.SecretVersion, o.Status.CurrentSecretVersion = 0, 0
+	if o.Spec.Type != consts.KVSecretTypeV2 {
+		o.Status.Conditions = secretVersionConditions(o.Status.Conditions, o.GetGeneration(), 0, 0, nil)
+		return
+	}
+
+	o.Status.SecretVersion, _ = vault.KVV2SecretVersion(resp)
+	if o.Spec.Version <= 0 {
+		// the latest version was read.
+		o.Status.CurrentSecretVersion = o.Status.SecretVersion
+		o.Status.Conditions = secretVersionConditions(o.Status.Conditions, o.GetGeneration(), 0, 0, nil)
+		return
+	}
+
+	metaResp, err := r.KVReadBatcher.Read(ctx, c, o.Spec.Mount,
+		vault.NewKVReadMetadataRequestV2(o.Spec.Mount, o.Spec.Path))
+	if err == nil {
+		var ok bool
+		if o.Status.CurrentSecretVersion, ok = vault.KVV2CurrentVersion(metaResp); !ok {
+			err = errors.New("current_version not found in the secret's metadata")
+		}
+	}
+	if err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonVaultClientError,
+			"Failed to read the current version of the Vault secret: %s", err)
+	}
+
+	o.Status.Conditions = secretVersionConditions(o.Status.Conditions, o.GetGeneration(),
+		o.Spec.Version, o.Status.CurrentSecretVersion, err)
 }
 
 func newKVRequest(s secretsv1beta1.VaultStaticSecretSpec) (vault.ReadRequest, error) {
@@ -548,3 +1154,52 @@ func newKVRequest(s secretsv1beta1.VaultStaticSecretSpec) (vault.ReadRequest, er
 	}
 	return kvReq, nil
 }
+
+// transitDecryptData returns a copy of data, with the values of the fields
+// selected by t decrypted, or rewrapped, with Vault Transit. The raw secret data
+// is never decrypted.
+func transitDecryptData(ctx context.Context, c vault.Client, t *secretsv1beta1.TransitDecrypt, data map[string]any) (map[string]any, error) {
+	pats := make([]*regexp.Regexp, 0, len(t.Fields))
+	for _, f := range t.Fields {
+		pat, err := regexp.Compile(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field pattern %q: %w", f, err)
+		}
+		pats = append(pats, pat)
+	}
+
+	// data may be shared with other readers of the same secret.
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		result[k] = v
+		if !slices.ContainsFunc(pats, func(pat *regexp.Regexp) bool {
+			return pat.MatchString(k)
+		}) {
+			continue
+		}
+
+		ciphertext, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("field %q is not a string", k)
+		}
+
+		switch t.Operation {
+		case "", "decrypt":
+			b, err := vault.DecryptCiphertextWithTransit(ctx, c, t.Mount, t.Key, ciphertext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt field %q: %w", k, err)
+			}
+			result[k] = string(b)
+		case "rewrap":
+			s, err := vault.RewrapWithTransit(ctx, c, t.Mount, t.Key, ciphertext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewrap field %q: %w", k, err)
+			}
+			result[k] = s
+		default:
+			return nil, fmt.Errorf("unsupported transit operation %q", t.Operation)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,464 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+// stubTransitVaultClient "decrypts" ciphertext of the form vault:v1:<plaintext>,
+// and rewraps it as vault:v2:<plaintext>.
+type stubTransitVaultClient struct {
+	vault.Client
+	writes []string
+}
+
+func (c *stubTransitVaultClient) Write(_ context.Context, req vault.WriteRequest) (vault.Response, error) {
+	c.writes = append(c.writes, req.Path())
+	plaintext := strings.TrimPrefix(req.Params()["ciphertext"].(string), "vault:v1:")
+	data := map[string]any{
+		"plaintext":  base64.StdEncoding.EncodeToString([]byte(plaintext)),
+		"ciphertext": "vault:v2:" + plaintext,
+	}
+	return vault.NewDefaultResponse(&api.Secret{Data: data}), nil
+}
+
+func Test_transitDecryptData(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	data := map[string]any{
+		"password":     "vault:v1:secret",
+		"api_password": "vault:v1:api-secret",
+		"username":     "alice",
+		"port":         5432,
+	}
+
+	tests := []struct {
+		name       string
+		t          *secretsv1beta1.TransitDecrypt
+		want       map[string]any
+		wantWrites []string
+		wantErr    string
+	}{
+		{
+			name: "decrypt",
+			t: &secretsv1beta1.TransitDecrypt{
+				Mount:  "transit",
+				Key:    "app",
+				Fields: []string{"password$"},
+			},
+			want: map[string]any{
+				"password":     "secret",
+				"api_password": "api-secret",
+				"username":     "alice",
+				"port":         5432,
+			},
+			wantWrites: []string{"transit/decrypt/app", "transit/decrypt/app"},
+		},
+		{
+			name: "rewrap",
+			t: &secretsv1beta1.TransitDecrypt{
+				Mount:     "transit",
+				Key:       "app",
+				Fields:    []string{"^password$"},
+				Operation: "rewrap",
+			},
+			want: map[string]any{
+				"password":     "vault:v2:secret",
+				"api_password": "vault:v1:api-secret",
+				"username":     "alice",
+				"port":         5432,
+			},
+			wantWrites: []string{"transit/rewrap/app"},
+		},
+		{
+			name: "non-string-field",
+			t: &secretsv1beta1.TransitDecrypt{
+				Mount:  "transit",
+				Key:    "app",
+				Fields: []string{"port"},
+			},
+			wantErr: `field "port" is not a string`,
+		},
+		{
+			name: "invalid-pattern",
+			t: &secretsv1beta1.TransitDecrypt{
+				Mount:  "transit",
+				Key:    "app",
+				Fields: []string{"("},
+			},
+			wantErr: `invalid field pattern "("`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &stubTransitVaultClient{}
+			got, err := transitDecryptData(ctx, c, tt.t, data)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantWrites, c.writes)
+			// the source data is never modified.
+			assert.Equal(t, "vault:v1:secret", data["password"])
+		})
+	}
+}
+
+func Test_staticSecretEventMatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		syncConfig     *secretsv1beta1.SyncConfig
+		eventTypes     []string
+		subPathFilters []string
+		namespace      string
+		eventType      string
+		path           string
+		want           bool
+	}{
+		{
+			name:       "secret-path",
+			syncConfig: &secretsv1beta1.SyncConfig{InstantUpdates: true},
+			eventTypes: []string{defaultEventType},
+			namespace:  "ns1",
+			eventType:  "kv-v2/data-write",
+			path:       "kv/data/app",
+			want:       true,
+		},
+		{
+			name:       "other-path",
+			syncConfig: &secretsv1beta1.SyncConfig{InstantUpdates: true},
+			eventTypes: []string{defaultEventType},
+			namespace:  "ns1",
+			eventType:  "kv-v2/data-write",
+			path:       "kv/data/other",
+		},
+		{
+			name:       "other-namespace",
+			syncConfig: &secretsv1beta1.SyncConfig{InstantUpdates: true},
+			eventTypes: []string{defaultEventType},
+			namespace:  "ns2",
+			eventType:  "kv-v2/data-write",
+			path:       "kv/data/app",
+		},
+		{
+			name:       "other-event-type",
+			syncConfig: &secretsv1beta1.SyncConfig{InstantUpdates: true},
+			eventTypes: []string{"kv-v2/data-write"},
+			namespace:  "ns1",
+			eventType:  "kv-v2/data-delete",
+			path:       "kv/data/app",
+		},
+		{
+			name: "path-filters",
+			syncConfig: &secretsv1beta1.SyncConfig{
+				InstantUpdates: true,
+				InstantUpdatesConfig: &secretsv1beta1.InstantUpdatesConfig{
+					PathFilters: []string{"database/static-roles/*"},
+				},
+			},
+			eventTypes: []string{"database/rotate", defaultEventType},
+			namespace:  "ns1",
+			eventType:  "database/rotate",
+			path:       "database/static-roles/app",
+			want:       true,
+		},
+		{
+			name: "path-filters-replace-secret-path",
+			syncConfig: &secretsv1beta1.SyncConfig{
+				InstantUpdates: true,
+				InstantUpdatesConfig: &secretsv1beta1.InstantUpdatesConfig{
+					PathFilters: []string{"database/static-roles/*"},
+				},
+			},
+			eventTypes: []string{"database/rotate", defaultEventType},
+			namespace:  "ns1",
+			eventType:  "kv-v2/data-write",
+			path:       "kv/data/app",
+		},
+		{
+			name:           "subscription-path-filters",
+			syncConfig:     &secretsv1beta1.SyncConfig{InstantUpdates: true},
+			eventTypes:     []string{defaultEventType},
+			subPathFilters: []string{"kv/data/*"},
+			namespace:      "ns1",
+			eventType:      "kv-v2/data-write",
+			path:           "kv/data/app",
+			want:           true,
+		},
+		{
+			name:           "subscription-path-filters-restrict",
+			syncConfig:     &secretsv1beta1.SyncConfig{InstantUpdates: true},
+			eventTypes:     []string{defaultEventType},
+			subPathFilters: []string{"kv/data/team-a/*"},
+			namespace:      "ns1",
+			eventType:      "kv-v2/data-write",
+			path:           "kv/data/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := &secretsv1beta1.VaultStaticSecret{
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Namespace:  "ns1",
+					Mount:      "kv",
+					Path:       "app",
+					Type:       consts.KVSecretTypeV2,
+					SyncConfig: tt.syncConfig,
+				},
+			}
+			r := &VaultStaticSecretReconciler{}
+			match := r.staticSecretEventMatcher(o, "ns1", tt.eventTypes, tt.subPathFilters)
+			assert.Equal(t, tt.want, match(tt.namespace, tt.eventType, tt.path))
+		})
+	}
+}
+
+func TestVaultStaticSecretReconciler_handleSourceDeleted(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		onSourceDelete string
+		create         bool
+		wantData       map[string][]byte
+		wantDeleted    bool
+		wantSecretMAC  string
+		wantMessage    string
+	}{
+		{
+			name:          "retain-default",
+			create:        true,
+			wantData:      map[string][]byte{"foo": []byte("bar")},
+			wantSecretMAC: "mac",
+			wantMessage:   "The Vault secret was deleted, retaining the destination Secret",
+		},
+		{
+			name:           "empty",
+			onSourceDelete: "empty",
+			create:         true,
+			wantData:       nil,
+			wantMessage:    "The Vault secret was deleted, emptied the destination Secret",
+		},
+		{
+			name:           "delete",
+			onSourceDelete: "delete",
+			create:         true,
+			wantDeleted:    true,
+			wantMessage:    "The Vault secret was deleted, deleted the destination Secret",
+		},
+		{
+			name:           "delete-not-created",
+			onSourceDelete: "delete",
+			wantData:       nil,
+			wantMessage:    "The Vault secret was deleted, emptied the destination Secret",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  "default",
+					Name:       "foo",
+					UID:        "uid",
+					Generation: 1,
+				},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Mount:          "kv",
+					Path:           "app",
+					Type:           consts.KVSecretTypeV2,
+					OnSourceDelete: tt.onSourceDelete,
+					Destination: secretsv1beta1.Destination{
+						Name:   "dest",
+						Create: tt.create,
+					},
+				},
+				Status: secretsv1beta1.VaultStaticSecretStatus{
+					SecretMAC:     "mac",
+					SecretVersion: 2,
+				},
+			}
+			dest := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "dest",
+				},
+				Data: map[string][]byte{"foo": []byte("bar")},
+			}
+			gvk := secretsv1beta1.GroupVersion.WithKind("VaultStaticSecret")
+			if tt.create {
+				labels, err := helpers.OwnerLabelsForObj(o)
+				require.NoError(t, err)
+				dest.Labels = labels
+				dest.OwnerReferences = []metav1.OwnerReference{
+					{
+						APIVersion: gvk.GroupVersion().String(),
+						Kind:       gvk.Kind,
+						Name:       o.Name,
+						UID:        o.UID,
+					},
+				}
+			}
+			c := testutils.NewFakeClientBuilder().WithObjects(o, dest).WithStatusSubresource(o).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &VaultStaticSecretReconciler{
+				Client:          c,
+				Recorder:        recorder,
+				SyncRegistry:    NewSyncRegistry(),
+				BackOffRegistry: NewBackOffRegistry(),
+			}
+
+			for i := 0; i < 2; i++ {
+				o.SetGroupVersionKind(gvk)
+				result, err := r.handleSourceDeleted(ctx, o, 0, 0)
+				require.NoError(t, err)
+				assert.NotZero(t, result.RequeueAfter)
+			}
+			// the event is only recorded once.
+			assert.Len(t, recorder.Events, 1)
+
+			var got secretsv1beta1.VaultStaticSecret
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+			require.Len(t, got.Status.Conditions, 1)
+			assert.Equal(t, conditionTypeSourceDeleted, got.Status.Conditions[0].Type)
+			assert.Equal(t, metav1.ConditionTrue, got.Status.Conditions[0].Status)
+			assert.Equal(t, consts.ReasonSourceDeleted, got.Status.Conditions[0].Reason)
+			assert.Equal(t, tt.wantMessage, got.Status.Conditions[0].Message)
+			assert.Equal(t, tt.wantSecretMAC, got.Status.SecretMAC)
+			assert.Zero(t, got.Status.SecretVersion)
+
+			var gotDest corev1.Secret
+			err := c.Get(ctx, client.ObjectKeyFromObject(dest), &gotDest)
+			if tt.wantDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, gotDest.Data)
+		})
+	}
+}
+
+// stubMissingKVClient responds to every Read as if the secret does not exist.
+type stubMissingKVClient struct {
+	vault.Client
+	reads []string
+}
+
+func (c *stubMissingKVClient) Read(_ context.Context, req vault.ReadRequest) (vault.Response, error) {
+	c.reads = append(c.reads, req.Path())
+	return nil, &vault.EmptyResponseError{Path: req.Path()}
+}
+
+func (c *stubMissingKVClient) Taint() {}
+
+func TestVaultStaticSecretReconciler_Reconcile_sourceMissing(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name             string
+		onSourceDelete   string
+		secretMAC        string
+		wantDeleted      bool
+		wantEventMessage string
+	}{
+		{
+			name:             "new-resource",
+			wantEventMessage: "Failed to read Vault secret",
+		},
+		{
+			name:             "new-resource-retain",
+			onSourceDelete:   "retain",
+			wantEventMessage: "Failed to read Vault secret",
+		},
+		{
+			name:             "synced",
+			secretMAC:        "mac",
+			wantDeleted:      true,
+			wantEventMessage: "The Vault secret was deleted, retaining the destination Secret",
+		},
+		{
+			name:             "new-resource-empty",
+			onSourceDelete:   "empty",
+			wantDeleted:      true,
+			wantEventMessage: "The Vault secret was deleted, emptied the destination Secret",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  "default",
+					Name:       "foo",
+					Generation: 1,
+				},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Mount:          "kv",
+					Path:           "missing",
+					Type:           consts.KVSecretTypeV2,
+					OnSourceDelete: tt.onSourceDelete,
+					Destination: secretsv1beta1.Destination{
+						Name:   "dest",
+						Create: true,
+					},
+				},
+				Status: secretsv1beta1.VaultStaticSecretStatus{
+					SecretMAC: tt.secretMAC,
+				},
+			}
+			c := testutils.NewFakeClientBuilder().WithObjects(o).WithStatusSubresource(o).Build()
+			vaultClient := &stubMissingKVClient{}
+			recorder := record.NewFakeRecorder(10)
+			r := &VaultStaticSecretReconciler{
+				Client:          c,
+				ClientFactory:   &stubPKIClientFactory{client: vaultClient},
+				Recorder:        recorder,
+				SyncRegistry:    NewSyncRegistry(),
+				BackOffRegistry: NewBackOffRegistry(),
+				referenceCache:  newResourceReferenceCache(),
+			}
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+			require.NoError(t, err)
+			assert.NotZero(t, result.RequeueAfter)
+			assert.Equal(t, []string{"kv/data/missing"}, vaultClient.reads)
+
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, tt.wantEventMessage)
+
+			var got secretsv1beta1.VaultStaticSecret
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), &got))
+			assert.Equal(t, tt.wantDeleted, hasCondition(got.Status.Conditions, conditionTypeSourceDeleted))
+		})
+	}
+}
@@ -0,0 +1,562 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+const vaultTransitKeyFinalizer = "vaulttransitkeys.secrets.hashicorp.com/finalizer"
+
+const (
+	transitDataKeyTypePlaintext = "plaintext"
+	transitDataKeyTypeWrapped   = "wrapped"
+	transitPlaintextKey         = "plaintext"
+	transitCiphertextKey        = "ciphertext"
+	transitVersionKey           = "version"
+	// defaultTransitRotationPeriod is used when the RotationPeriod is not set.
+	defaultTransitRotationPeriod = 24 * time.Hour
+)
+
+// VaultTransitKeyReconciler reconciles a VaultTransitKey object
+type VaultTransitKeyReconciler struct {
+	client.Client
+	Scheme                      *runtime.Scheme
+	ClientFactory               vault.ClientFactory
+	HMACValidator               helpers.HMACValidator
+	Recorder                    record.EventRecorder
+	SyncRegistry                *SyncRegistry
+	BackOffRegistry             *BackOffRegistry
+	SyncStatusRegistry          *SyncStatusRegistry
+	referenceCache              ResourceReferenceCache
+	GlobalTransformationOptions *helpers.GlobalTransformationOptions
+	// Shedder defers the reconciliation of low priority resources under a large
+	// backlog, it is nil if load shedding is not enabled.
+	Shedder *ReconcileShedder
+	// FreezeWindow defers non-critical secret rotations and rollout-restarts
+	// during the freeze window, it is nil if no freeze window is configured.
+	FreezeWindow *FreezeWindow
+	// VaultPathPolicy restricts the Vault paths that the resources may
+	// reference, it is nil if no paths are restricted.
+	VaultPathPolicy *common.VaultPathPolicy
+	// Shard limits the reconciliation to the resources that are owned by this
+	// operator instance, it is nil if sharding is not enabled.
+	Shard *Shard
+	// StartupSyncSmear spreads the initial reconciliation of the resources over
+	// a window after the operator starts, it is nil if smearing is not enabled.
+	StartupSyncSmear *StartupSyncSmear
+}
+
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaulttransitkeys,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaulttransitkeys/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=secrets.hashicorp.com,resources=vaulttransitkeys/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//
+// required for rollout-restart
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;patch
+//
+
+// Reconcile requests a data key from the Vault Transit secrets engine for a
+// VaultTransitKey, and syncs it to its Destination, along with the retained
+// data keys. A new data key is requested every RotationPeriod.
+func (r *VaultTransitKeyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.Shard.Owns(req.NamespacedName) {
+		// the resource is reconciled by the operator instance that owns its shard.
+		r.SyncStatusRegistry.Delete(VaultTransitKey, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	o := &secretsv1beta1.VaultTransitKey{}
+	if err := r.Client.Get(ctx, req.NamespacedName, o); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.SyncStatusRegistry.Delete(VaultTransitKey, req.NamespacedName)
+			logger.V(consts.LogLevelDebug).Info("VaultTransitKey resource not found", "req", req)
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, "Failed to get VaultTransitKey resource", "resource", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if o.GetDeletionTimestamp() != nil {
+		logger.Info("Got deletion timestamp", "obj", o)
+		return ctrl.Result{}, r.handleDeletion(ctx, o)
+	}
+
+	if suspended, err := handleSuspend(ctx, r.Client, o, r.Recorder); err != nil || suspended {
+		return ctrl.Result{}, err
+	}
+
+	if denied, err := handleVaultPathPolicy(ctx, r.Client, o, r.VaultPathPolicy, r.Recorder); err != nil || denied {
+		return ctrl.Result{}, err
+	}
+
+	if deferAfter, ok := r.Shedder.Shed(ctx, VaultTransitKey, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	if deferAfter, ok := r.StartupSyncSmear.Defer(ctx, VaultTransitKey, o); ok {
+		return ctrl.Result{RequeueAfter: deferAfter}, nil
+	}
+
+	pendingAfter, err := r.FreezeWindow.HandlePending(ctx, r.Client, r.HMACValidator, o, r.Recorder)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	destinationExists, _ := helpers.CheckSecretExists(ctx, r.Client, o)
+	if !o.Spec.Destination.Create && !destinationExists {
+		horizon := computeHorizonWithJitter(requeueDurationOnError)
+		msg := fmt.Sprintf("Kubernetes secret %q does not exist yet, horizon=%s",
+			o.Spec.Destination.Name, horizon)
+		logger.Info(msg)
+		o.Status.Error = consts.ReasonK8sClientError
+		r.recordEvent(o, o.Status.Error, msg)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{
+			RequeueAfter: horizon,
+		}, nil
+	}
+
+	var syncReason string
+	switch {
+	case len(o.Status.DataKeys) == 0:
+		syncReason = consts.ReasonInitialSync
+	case r.SyncRegistry.Has(req.NamespacedName):
+		syncReason = consts.ReasonForceSync
+	case o.GetGeneration() != o.Status.LastGeneration:
+		syncReason = consts.ReasonResourceUpdated
+	case o.Spec.Destination.Create && !destinationExists:
+		logger.Info("Destination secret does not exist",
+			"destination", o.Spec.Destination.Name)
+		syncReason = consts.ReasonInexistentDestination
+	case destinationExists:
+		if matched, err := helpers.HMACDestinationSecret(ctx, r.Client,
+			r.HMACValidator, o); err == nil && !matched {
+			syncReason = consts.ReasonSecretDataDrift
+		} else if err != nil {
+			logger.Error(err, "Failed to HMAC destination secret")
+		}
+	}
+
+	r.referenceCache.Set(SecretTransformation, req.NamespacedName,
+		helpers.GetTransformationRefObjKeys(
+			o.Spec.Destination.Transformation, o.Namespace, r.GlobalTransformationOptions)...)
+
+	transOption, err := helpers.NewSecretTransformationOption(ctx, r.Client, o, r.GlobalTransformationOptions)
+	if err != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTransformationError,
+			"Failed setting up SecretTransformationOption: %s", err)
+		return ctrl.Result{RequeueAfter: computeHorizonWithJitter(requeueDurationOnError)}, nil
+	}
+
+	// the data key is only rotated on the initial sync, when the resource is
+	// updated, or when its rotation period has elapsed. Otherwise, the existing
+	// data keys are synced again.
+	var rotate bool
+	switch syncReason {
+	case consts.ReasonInitialSync, consts.ReasonResourceUpdated:
+		rotate = true
+	case "":
+		horizon, inWindow := computeTransitKeyRotationWindow(ctx, o, 0.05)
+		if !inWindow {
+			logger.Info("Not in rotation window", "horizon", horizon)
+			return ctrl.Result{
+				RequeueAfter: minRequeueAfter(horizon, pendingAfter),
+			}, nil
+		}
+		syncReason = consts.ReasonInRenewalWindow
+		rotate = true
+
+		// data keys do not expire, so the rotation is always deferred during the
+		// freeze window.
+		if deferAfter, ok := r.FreezeWindow.DeferRotation(
+			ctx, r.Client, VaultTransitKey, o, time.Time{}, r.Recorder); ok {
+			return ctrl.Result{RequeueAfter: minRequeueAfter(deferAfter, pendingAfter)}, nil
+		}
+	}
+
+	// assume that status is always invalid
+	o.Status.Valid = ptr.To(false)
+	logger.Info("Must sync", "reason", syncReason, "rotate", rotate)
+
+	c, err := r.ClientFactory.Get(ctx, r.Client, o)
+	if err != nil {
+		o.Status.Error = consts.ReasonK8sClientError
+		logger.Error(err, "Get Vault client")
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	var dataKey *vault.TransitDataKey
+	if rotate {
+		dataKey, err = vault.GenerateDataKeyWithTransit(ctx, c, o.Spec.Mount, o.Spec.Key,
+			transitDataKeyType(o), o.Spec.Bits)
+	}
+	var plaintexts map[int64]string
+	if err == nil {
+		plaintexts, err = r.decryptDataKeys(ctx, c, o, dataKey)
+	}
+	if err != nil {
+		if horizon, ok := handleThrottled(ctx, r.Client, o, r.Recorder, err); ok {
+			r.SyncRegistry.Add(req.NamespacedName)
+			return ctrl.Result{RequeueAfter: horizon}, nil
+		}
+		if vault.IsForbiddenError(err) {
+			c.Taint()
+		}
+		o.Status.Error = consts.ReasonVaultClientError
+		msg := "Failed to get the data key from Vault"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		r.SyncRegistry.Add(req.NamespacedName)
+		entry, _ := r.BackOffRegistry.Get(req.NamespacedName)
+		return ctrl.Result{
+			RequeueAfter: nextBackOff(ctx, r.Client, o, entry),
+		}, nil
+	} else {
+		resetBackOff(r.BackOffRegistry, o)
+	}
+
+	dataKeys := o.Status.DataKeys
+	if dataKey != nil {
+		dataKeys = appendTransitDataKey(dataKeys, dataKey, o.Spec.RetainVersions)
+		plaintexts[dataKeys[len(dataKeys)-1].Version] = dataKey.Plaintext
+	}
+
+	resp := vault.NewDefaultResponse(&api.Secret{
+		Data: transitDataKeyData(dataKeys, plaintexts),
+	})
+	data, err := resp.SecretK8sData(transOption)
+	renderErr, err := handleTemplateRenderError(ctx, r.Client, o, data, err)
+	if err != nil {
+		o.Status.Error = consts.ReasonK8sClientError
+		msg := "Failed to marshal Vault secret data"
+		logger.Error(err, msg)
+		r.recordEvent(o, o.Status.Error, msg+": %s", err)
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+	if renderErr != nil {
+		r.Recorder.Eventf(o, corev1.EventTypeWarning, consts.ReasonTemplateRenderError,
+			"Retaining previous values for keys that failed to render: %s", renderErr)
+	}
+	o.Status.Conditions = templatesRenderedConditions(o.Status.Conditions, o.GetGeneration(), transOption, renderErr)
+
+	if b, err := json.Marshal(data); err == nil {
+		newMAC, err := r.HMACValidator.HMAC(ctx, r.Client, b)
+		if err != nil {
+			logger.Error(err, "HMAC data")
+			o.Status.Error = consts.ReasonHMACDataError
+			if err := r.updateStatus(ctx, o); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{
+				RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+			}, nil
+		}
+		o.Status.SecretMAC = base64.StdEncoding.EncodeToString(newMAC)
+	}
+
+	opts := helpers.DefaultSyncOptions()
+	opts.HMACValidator = r.HMACValidator
+	err = helpers.SyncSecret(ctx, r.Client, o, data, opts)
+	handleSecretDataError(ctx, r.Client, o, err)
+	if err != nil {
+		logger.Error(err, "Sync secret")
+		o.Status.Error = consts.ReasonSecretSyncError
+		if err := r.updateStatus(ctx, o); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: computeHorizonWithJitter(requeueDurationOnError),
+		}, nil
+	}
+
+	reason := consts.ReasonSecretSynced
+	if dataKey != nil && len(o.Status.DataKeys) > 0 {
+		reason = consts.ReasonSecretRotated
+		pendingAfter = minRequeueAfter(pendingAfter,
+			r.FreezeWindow.HandleRolloutRestarts(ctx, r.Client, r.HMACValidator, VaultTransitKey, o, r.Recorder))
+	}
+
+	o.Status.Valid = ptr.To(true)
+	o.Status.Error = ""
+	o.Status.DataKeys = dataKeys
+	if dataKey != nil {
+		o.Status.LastRotation = nowFunc().Unix()
+	}
+	if err := r.updateStatus(ctx, o); err != nil {
+		logger.Error(err, "Failed to update the status")
+		return ctrl.Result{}, err
+	}
+
+	r.SyncRegistry.Delete(req.NamespacedName)
+
+	horizon, _ := computeTransitKeyRotationWindow(ctx, o, .05)
+	r.recordEvent(o, reason, fmt.Sprintf("Secret synced, horizon=%s", horizon))
+	logger.Info("Successfully updated the secret", "horizon", horizon)
+	return ctrl.Result{
+		RequeueAfter: minRequeueAfter(horizon, pendingAfter),
+	}, nil
+}
+
+// decryptDataKeys returns the base64 encoded plaintext of every data key of o,
+// by version. The plaintext is recovered by decrypting the data key's
+// ciphertext with Vault. Data keys that are dropped by the rotation to dataKey
+// are skipped, and nothing is decrypted for the "wrapped" DataKeyType.
+func (r *VaultTransitKeyReconciler) decryptDataKeys(ctx context.Context, c vault.Client,
+	o *secretsv1beta1.VaultTransitKey, dataKey *vault.TransitDataKey,
+) (map[int64]string, error) {
+	plaintexts := make(map[int64]string)
+	if transitDataKeyType(o) == transitDataKeyTypeWrapped {
+		return plaintexts, nil
+	}
+
+	dataKeys := o.Status.DataKeys
+	if dataKey != nil {
+		dataKeys = appendTransitDataKey(dataKeys, dataKey, o.Spec.RetainVersions)
+		dataKeys = dataKeys[:len(dataKeys)-1]
+	}
+
+	for _, k := range dataKeys {
+		b, err := vault.DecryptCiphertextWithTransit(ctx, c, o.Spec.Mount, o.Spec.Key, k.Ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt data key version %d: %w", k.Version, err)
+		}
+		plaintexts[k.Version] = base64.StdEncoding.EncodeToString(b)
+	}
+
+	return plaintexts, nil
+}
+
+func (r *VaultTransitKeyReconciler) recordEvent(o *secretsv1beta1.VaultTransitKey, reason, msg string, i ...interface{}) {
+	eventType := corev1.EventTypeNormal
+	if !ptr.Deref(o.Status.Valid, false) {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.Recorder.Eventf(o, eventType, reason, msg, i...)
+}
+
+func (r *VaultTransitKeyReconciler) updateStatus(ctx context.Context, o *secretsv1beta1.VaultTransitKey) error {
+	logger := log.FromContext(ctx)
+	logger.V(consts.LogLevelTrace).Info("Update status called")
+
+	metrics.SetResourceStatus("vaulttransitkey", o, ptr.Deref(o.Status.Valid, false))
+
+	o.Status.LastGeneration = o.GetGeneration()
+	if err := r.Status().Update(ctx, o); err != nil {
+		msg := "Failed to update the resource's status"
+		r.recordEvent(o, consts.ReasonStatusUpdateError, "%s: %s", msg, err)
+		logger.Error(err, msg)
+		return err
+	}
+
+	_, err := maybeAddFinalizer(ctx, r.Client, o, vaultTransitKeyFinalizer)
+	return err
+}
+
+func (r *VaultTransitKeyReconciler) handleDeletion(ctx context.Context, o *secretsv1beta1.VaultTransitKey) error {
+	if err := finalizeDestinationSecrets(ctx, r.Client, r.Recorder, o, vaultTransitKeyFinalizer); err != nil {
+		return err
+	}
+
+	objKey := client.ObjectKeyFromObject(o)
+	r.SyncRegistry.Delete(objKey)
+	r.BackOffRegistry.Delete(objKey)
+	r.referenceCache.Remove(SecretTransformation, objKey)
+
+	logger := log.FromContext(ctx).WithName("handleDeletion")
+	if controllerutil.RemoveFinalizer(o, vaultTransitKeyFinalizer) {
+		if err := r.Update(ctx, o); err != nil {
+			logger.Error(err, "Failed to remove the finalizer")
+			return err
+		}
+		logger.V(consts.LogLevelDebug).Info("Finalizers successfully removed")
+	}
+
+	return nil
+}
+
+func (r *VaultTransitKeyReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.Recorder = r.SyncStatusRegistry.EventRecorder(VaultTransitKey, r.Recorder)
+	r.referenceCache = newResourceReferenceCache()
+	if r.BackOffRegistry == nil {
+		r.BackOffRegistry = NewBackOffRegistry()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&secretsv1beta1.VaultTransitKey{}).
+		WithEventFilter(syncableSecretPredicate(r.SyncRegistry)).
+		WithOptions(opts).
+		Watches(
+			&secretsv1beta1.SecretTransformation{},
+			NewEnqueueRefRequestsHandlerST(r.referenceCache, r.SyncRegistry),
+		).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDeletionRequestHandler{
+				gvk: secretsv1beta1.GroupVersion.WithKind(VaultTransitKey.String()),
+			},
+			builder.WithPredicates(&secretsPredicate{}),
+		).
+		WatchesMetadata(
+			&corev1.Secret{},
+			&enqueueOnDriftRequestHandler{
+				client:    r.Client,
+				validator: r.HMACValidator,
+				recorder:  r.Recorder,
+				kind:      VaultTransitKey,
+			},
+			builder.WithPredicates(&secretsDriftPredicate{}),
+		).
+		Complete(r.SyncStatusRegistry.Reconciler(VaultTransitKey, r))
+}
+
+// transitDataKeyType returns the DataKeyType of o, defaulting to "plaintext".
+func transitDataKeyType(o *secretsv1beta1.VaultTransitKey) string {
+	if o.Spec.DataKeyType == "" {
+		return transitDataKeyTypePlaintext
+	}
+	return o.Spec.DataKeyType
+}
+
+// appendTransitDataKey appends dataKey to dataKeys as the next version, and
+// drops the oldest data keys beyond the retainVersions previous ones.
+func appendTransitDataKey(dataKeys []secretsv1beta1.VaultTransitDataKey,
+	dataKey *vault.TransitDataKey, retainVersions int,
+) []secretsv1beta1.VaultTransitDataKey {
+	var version int64 = 1
+	if len(dataKeys) > 0 {
+		version = dataKeys[len(dataKeys)-1].Version + 1
+	}
+
+	result := append(append([]secretsv1beta1.VaultTransitDataKey{}, dataKeys...),
+		secretsv1beta1.VaultTransitDataKey{
+			Version:    version,
+			KeyVersion: dataKey.KeyVersion,
+			Ciphertext: dataKey.Ciphertext,
+		})
+	if retainVersions < 0 {
+		retainVersions = 0
+	}
+	if len(result) > retainVersions+1 {
+		result = result[len(result)-(retainVersions+1):]
+	}
+
+	return result
+}
+
+// transitDataKeyData returns the secret data for dataKeys, the last one being
+// the current data key. The plaintexts are only included for the versions
+// found in plaintexts.
+func transitDataKeyData(dataKeys []secretsv1beta1.VaultTransitDataKey, plaintexts map[int64]string) map[string]any {
+	data := make(map[string]any)
+	for _, k := range dataKeys {
+		v := strconv.FormatInt(k.Version, 10)
+		data[transitCiphertextKey+"-"+v] = k.Ciphertext
+		if p, ok := plaintexts[k.Version]; ok && p != "" {
+			data[transitPlaintextKey+"-"+v] = p
+		}
+	}
+
+	if len(dataKeys) > 0 {
+		current := dataKeys[len(dataKeys)-1]
+		v := strconv.FormatInt(current.Version, 10)
+		data[transitVersionKey] = v
+		data[transitCiphertextKey] = current.Ciphertext
+		if p, ok := plaintexts[current.Version]; ok && p != "" {
+			data[transitPlaintextKey] = p
+		}
+	}
+
+	return data
+}
+
+// computeTransitKeyRotationWindow returns the horizon until the data key of o
+// should be rotated, and whether it is in its rotation window. The data key is
+// rotated RotationPeriod after its last rotation.
+func computeTransitKeyRotationWindow(ctx context.Context, o *secretsv1beta1.VaultTransitKey,
+	jitterPercent float64,
+) (time.Duration, bool) {
+	logger := log.FromContext(ctx).WithValues(
+		"rotationPeriod", o.Spec.RotationPeriod,
+		"lastRotation", time.Unix(o.Status.LastRotation, 0))
+
+	period, err := parseDurationString(o.Spec.RotationPeriod, ".spec.rotationPeriod", 0)
+	if err != nil || period <= 0 {
+		period = defaultTransitRotationPeriod
+		if err != nil {
+			logger.Info("Warning: tolerating invalid rotation period",
+				"err", err, "effectivePeriod", period)
+		}
+	}
+
+	rotationTime := time.Unix(o.Status.LastRotation, 0).Add(period)
+	now := nowFunc()
+	horizon := rotationTime.Sub(now)
+	var inWindow bool
+	if isInWindow(now, rotationTime) || horizon < minHorizon {
+		horizon = minHorizon
+		inWindow = true
+	}
+
+	_, jitter := computeMaxJitterDurationWithPercent(horizon, jitterPercent)
+	if inWindow {
+		horizon += jitter
+	} else {
+		horizon -= jitter
+	}
+
+	logger.V(consts.LogLevelDebug).WithValues(
+		"rotateWhen", rotationTime, "now", now,
+		"horizon", horizon).Info("Computed data key rotation window")
+
+	return horizon, inWindow
+}
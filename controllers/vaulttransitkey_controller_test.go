@@ -0,0 +1,273 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/vault"
+)
+
+func Test_computeTransitKeyRotationWindow(t *testing.T) {
+	ctx := context.Background()
+	staticNow := time.Unix(time.Now().Unix(), 0)
+
+	tests := []struct {
+		name              string
+		rotationPeriod    string
+		lastRotationDelta int64
+		wantInWindow      bool
+		wantMin           time.Duration
+		wantMax           time.Duration
+	}{
+		{
+			name:              "default-not-in-window",
+			lastRotationDelta: -3600,
+			wantMin:           time.Duration(0.95 * float64(23*time.Hour)),
+			wantMax:           23 * time.Hour,
+		},
+		{
+			name:              "default-in-window",
+			lastRotationDelta: -86400,
+			wantInWindow:      true,
+			wantMin:           time.Second,
+			wantMax:           time.Duration(1.05 * float64(time.Second)),
+		},
+		{
+			name:              "period-not-in-window",
+			rotationPeriod:    "1h",
+			lastRotationDelta: -600,
+			wantMin:           time.Duration(0.95 * float64(50*time.Minute)),
+			wantMax:           50 * time.Minute,
+		},
+		{
+			name:              "period-in-window",
+			rotationPeriod:    "1h",
+			lastRotationDelta: -3700,
+			wantInWindow:      true,
+			wantMin:           time.Second,
+			wantMax:           time.Duration(1.05 * float64(time.Second)),
+		},
+		{
+			name:              "invalid-period",
+			rotationPeriod:    "1y",
+			lastRotationDelta: -3600,
+			wantMin:           time.Duration(0.95 * float64(23*time.Hour)),
+			wantMax:           23 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nowFuncOrig := nowFunc
+			t.Cleanup(func() {
+				nowFunc = nowFuncOrig
+			})
+			nowFunc = func() time.Time { return staticNow }
+
+			o := &secretsv1beta1.VaultTransitKey{
+				Spec: secretsv1beta1.VaultTransitKeySpec{
+					RotationPeriod: tt.rotationPeriod,
+				},
+				Status: secretsv1beta1.VaultTransitKeyStatus{
+					LastRotation: staticNow.Unix() + tt.lastRotationDelta,
+				},
+			}
+			gotHorizon, gotInWindow := computeTransitKeyRotationWindow(ctx, o, 0.05)
+			assert.Equal(t, tt.wantInWindow, gotInWindow)
+			assert.GreaterOrEqual(t, gotHorizon, tt.wantMin)
+			assert.LessOrEqual(t, gotHorizon, tt.wantMax)
+		})
+	}
+}
+
+func Test_appendTransitDataKey(t *testing.T) {
+	t.Parallel()
+
+	dataKey := &vault.TransitDataKey{
+		Ciphertext: "vault:v2:new",
+		KeyVersion: 2,
+	}
+
+	tests := []struct {
+		name           string
+		dataKeys       []secretsv1beta1.VaultTransitDataKey
+		retainVersions int
+		want           []secretsv1beta1.VaultTransitDataKey
+	}{
+		{
+			name: "initial",
+			want: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 1, KeyVersion: 2, Ciphertext: "vault:v2:new"},
+			},
+		},
+		{
+			name: "retained",
+			dataKeys: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 1, KeyVersion: 1, Ciphertext: "vault:v1:one"},
+			},
+			retainVersions: 2,
+			want: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 1, KeyVersion: 1, Ciphertext: "vault:v1:one"},
+				{Version: 2, KeyVersion: 2, Ciphertext: "vault:v2:new"},
+			},
+		},
+		{
+			name: "dropped",
+			dataKeys: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 3, KeyVersion: 1, Ciphertext: "vault:v1:three"},
+				{Version: 4, KeyVersion: 1, Ciphertext: "vault:v1:four"},
+				{Version: 5, KeyVersion: 1, Ciphertext: "vault:v1:five"},
+			},
+			retainVersions: 1,
+			want: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 5, KeyVersion: 1, Ciphertext: "vault:v1:five"},
+				{Version: 6, KeyVersion: 2, Ciphertext: "vault:v2:new"},
+			},
+		},
+		{
+			name: "none-retained",
+			dataKeys: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 1, KeyVersion: 1, Ciphertext: "vault:v1:one"},
+			},
+			want: []secretsv1beta1.VaultTransitDataKey{
+				{Version: 2, KeyVersion: 2, Ciphertext: "vault:v2:new"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			orig := append([]secretsv1beta1.VaultTransitDataKey{}, tt.dataKeys...)
+			assert.Equal(t, tt.want, appendTransitDataKey(tt.dataKeys, dataKey, tt.retainVersions))
+			assert.Equal(t, orig, append([]secretsv1beta1.VaultTransitDataKey{}, tt.dataKeys...))
+		})
+	}
+}
+
+func Test_transitDataKeyData(t *testing.T) {
+	t.Parallel()
+
+	dataKeys := []secretsv1beta1.VaultTransitDataKey{
+		{Version: 1, Ciphertext: "vault:v1:one"},
+		{Version: 2, Ciphertext: "vault:v1:two"},
+	}
+
+	assert.Equal(t, map[string]any{
+		"version":      "2",
+		"ciphertext":   "vault:v1:two",
+		"plaintext":    "dHdv",
+		"ciphertext-1": "vault:v1:one",
+		"plaintext-1":  "b25l",
+		"ciphertext-2": "vault:v1:two",
+		"plaintext-2":  "dHdv",
+	}, transitDataKeyData(dataKeys, map[int64]string{1: "b25l", 2: "dHdv"}))
+
+	assert.Equal(t, map[string]any{
+		"version":      "2",
+		"ciphertext":   "vault:v1:two",
+		"ciphertext-1": "vault:v1:one",
+		"ciphertext-2": "vault:v1:two",
+	}, transitDataKeyData(dataKeys, nil))
+
+	assert.Equal(t, map[string]any{}, transitDataKeyData(nil, nil))
+}
+
+// stubTransitDataKeyClient decrypts every ciphertext to its plaintext in
+// plaintexts, recording the decrypted ciphertexts.
+type stubTransitDataKeyClient struct {
+	vault.Client
+	plaintexts map[string]string
+	decrypted  []string
+}
+
+func (c *stubTransitDataKeyClient) Write(_ context.Context, req vault.WriteRequest) (vault.Response, error) {
+	ciphertext, _ := req.Params()["ciphertext"].(string)
+	c.decrypted = append(c.decrypted, ciphertext)
+	return vault.NewDefaultResponse(&api.Secret{
+		Data: map[string]any{
+			"plaintext": base64.StdEncoding.EncodeToString([]byte(c.plaintexts[ciphertext])),
+		},
+	}), nil
+}
+
+func TestVaultTransitKeyReconciler_decryptDataKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dataKeys := []secretsv1beta1.VaultTransitDataKey{
+		{Version: 1, Ciphertext: "vault:v1:one"},
+		{Version: 2, Ciphertext: "vault:v1:two"},
+	}
+	plaintexts := map[string]string{
+		"vault:v1:one": "one",
+		"vault:v1:two": "two",
+	}
+
+	tests := []struct {
+		name          string
+		dataKeyType   string
+		dataKey       *vault.TransitDataKey
+		want          map[int64]string
+		wantDecrypted []string
+	}{
+		{
+			name: "sync",
+			want: map[int64]string{
+				1: base64.StdEncoding.EncodeToString([]byte("one")),
+				2: base64.StdEncoding.EncodeToString([]byte("two")),
+			},
+			wantDecrypted: []string{"vault:v1:one", "vault:v1:two"},
+		},
+		{
+			name: "rotate",
+			dataKey: &vault.TransitDataKey{
+				Plaintext:  "dGhyZWU=",
+				Ciphertext: "vault:v1:three",
+			},
+			want: map[int64]string{
+				2: base64.StdEncoding.EncodeToString([]byte("two")),
+			},
+			wantDecrypted: []string{"vault:v1:two"},
+		},
+		{
+			name:        "wrapped",
+			dataKeyType: transitDataKeyTypeWrapped,
+			want:        map[int64]string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &stubTransitDataKeyClient{plaintexts: plaintexts}
+			o := &secretsv1beta1.VaultTransitKey{
+				Spec: secretsv1beta1.VaultTransitKeySpec{
+					Mount:          "transit",
+					Key:            "app",
+					DataKeyType:    tt.dataKeyType,
+					RetainVersions: 1,
+				},
+				Status: secretsv1beta1.VaultTransitKeyStatus{
+					DataKeys: dataKeys,
+				},
+			}
+			r := &VaultTransitKeyReconciler{}
+			got, err := r.decryptDataKeys(ctx, c, o, tt.dataKey)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantDecrypted, c.decrypted)
+		})
+	}
+}
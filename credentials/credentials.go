@@ -25,6 +25,7 @@ var ProviderMethodsSupported = []string{
 	consts.ProviderMethodAWS,
 	consts.ProviderMethodGCP,
 	hcp.ProviderMethodServicePrincipal,
+	hcp.ProviderMethodWorkloadIdentity,
 }
 
 // NewCredentialProvider returns a new provider.CredentialProviderBase instance
@@ -59,6 +60,8 @@ func NewCredentialProvider(ctx context.Context, client client.Client, obj client
 		switch authObj.Spec.Method {
 		case hcp.ProviderMethodServicePrincipal:
 			prov = &hcp.ServicePrincipleCredentialProvider{}
+		case hcp.ProviderMethodWorkloadIdentity:
+			prov = &hcp.WorkloadIdentityCredentialProvider{}
 		default:
 			return nil, fmt.Errorf("unsupported authentication method %s", authObj.Spec.Method)
 		}
@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hcp
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+)
+
+const (
+	ProviderMethodWorkloadIdentity    = "workloadIdentity"
+	ProviderCredsToken                = "token"
+	ProviderCredsProviderResourceName = "providerResourceName"
+)
+
+var _ CredentialProviderHCP = (*WorkloadIdentityCredentialProvider)(nil)
+
+// WorkloadIdentityCredentialProvider provides credentials for authenticating to
+// HCP using workload identity federation. The credentials are a ServiceAccount
+// token that HCP exchanges for a service principal access token.
+type WorkloadIdentityCredentialProvider struct {
+	authObj           *secretsv1beta1.HCPAuth
+	providerNamespace string
+	uid               types.UID
+}
+
+// GetNamespace returns the K8s Namespace of the credential source.
+func (l *WorkloadIdentityCredentialProvider) GetNamespace() string {
+	return l.providerNamespace
+}
+
+// GetUID returns the K8s UID of the credential source.
+func (l *WorkloadIdentityCredentialProvider) GetUID() types.UID {
+	return l.uid
+}
+
+func (l *WorkloadIdentityCredentialProvider) Init(ctx context.Context, client ctrlclient.Client,
+	authObj *secretsv1beta1.HCPAuth, providerNamespace string,
+) error {
+	spec := authObj.Spec.WorkloadIdentity
+	if spec == nil {
+		return fmt.Errorf("workload identity auth method not configured")
+	}
+	if spec.ProviderResourceName == "" {
+		return fmt.Errorf("invalid workload identity auth configuration: empty providerResourceName")
+	}
+
+	l.authObj = authObj
+	l.providerNamespace = providerNamespace
+
+	// We use the UID of the ServiceAccount whose token is exchanged.
+	sa, err := l.getServiceAccount(ctx, client)
+	if err != nil {
+		log.FromContext(ctx).Error(err,
+			"Init() failed to get service account", "serviceAccount", spec.ServiceAccount)
+		return err
+	}
+	l.uid = sa.UID
+	return nil
+}
+
+func (l *WorkloadIdentityCredentialProvider) getServiceAccount(ctx context.Context, client ctrlclient.Client) (*corev1.ServiceAccount, error) {
+	return helpers.GetServiceAccount(ctx, client, ctrlclient.ObjectKey{
+		Namespace: l.providerNamespace,
+		Name:      l.authObj.Spec.WorkloadIdentity.ServiceAccount,
+	})
+}
+
+// GetCreds returns a new ServiceAccount token along with the resource name of
+// the workload identity provider it is exchanged with.
+func (l *WorkloadIdentityCredentialProvider) GetCreds(ctx context.Context,
+	client ctrlclient.Client,
+) (map[string]any, error) {
+	logger := log.FromContext(ctx)
+	spec := l.authObj.Spec.WorkloadIdentity
+
+	sa, err := l.getServiceAccount(ctx, client)
+	if err != nil {
+		logger.Error(err, "Failed to get service account")
+		return nil, err
+	}
+
+	audiences := spec.TokenAudiences
+	if len(audiences) == 0 {
+		audiences = []string{spec.ProviderResourceName}
+	}
+	expirationSeconds := spec.TokenExpirationSeconds
+	if expirationSeconds == 0 {
+		expirationSeconds = 600
+	}
+
+	tr, err := helpers.RequestSAToken(ctx, client, sa, expirationSeconds, audiences)
+	if err != nil {
+		logger.Error(err, "Failed to get service account token")
+		return nil, err
+	}
+
+	return map[string]any{
+		ProviderCredsToken:                tr.Status.Token,
+		ProviderCredsProviderResourceName: spec.ProviderResourceName,
+	}, nil
+}
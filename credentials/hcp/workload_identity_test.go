@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package hcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+func TestWorkloadIdentityCredentialProvider_GetCreds(t *testing.T) {
+	ctx := context.Background()
+	providerResourceName := "iam/project/p1/service-principal/sp1/workload-identity-provider/k8s"
+
+	tests := []struct {
+		name          string
+		spec          *secretsv1beta1.HCPAuthWorkloadIdentity
+		createSA      bool
+		want          map[string]any
+		wantAudiences []string
+		wantExp       int64
+		wantInitErr   string
+	}{
+		{
+			name: "default-audiences",
+			spec: &secretsv1beta1.HCPAuthWorkloadIdentity{
+				ProviderResourceName: providerResourceName,
+				ServiceAccount:       "app",
+			},
+			createSA: true,
+			want: map[string]any{
+				ProviderCredsToken:                "token-1",
+				ProviderCredsProviderResourceName: providerResourceName,
+			},
+			wantAudiences: []string{providerResourceName},
+			wantExp:       600,
+		},
+		{
+			name: "audiences",
+			spec: &secretsv1beta1.HCPAuthWorkloadIdentity{
+				ProviderResourceName:   providerResourceName,
+				ServiceAccount:         "app",
+				TokenAudiences:         []string{"hcp"},
+				TokenExpirationSeconds: 1200,
+			},
+			createSA: true,
+			want: map[string]any{
+				ProviderCredsToken:                "token-1",
+				ProviderCredsProviderResourceName: providerResourceName,
+			},
+			wantAudiences: []string{"hcp"},
+			wantExp:       1200,
+		},
+		{
+			name: "service-account-not-found",
+			spec: &secretsv1beta1.HCPAuthWorkloadIdentity{
+				ProviderResourceName: providerResourceName,
+				ServiceAccount:       "app",
+			},
+			wantInitErr: `serviceaccounts "app" not found`,
+		},
+		{
+			name:        "not-configured",
+			wantInitErr: "workload identity auth method not configured",
+		},
+		{
+			name: "empty-provider-resource-name",
+			spec: &secretsv1beta1.HCPAuthWorkloadIdentity{
+				ServiceAccount: "app",
+			},
+			wantInitErr: "invalid workload identity auth configuration: empty providerResourceName",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRequest *authv1.TokenRequest
+			client := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceCreate: func(ctx context.Context, client ctrlclient.Client, subResourceName string,
+					obj ctrlclient.Object, subResource ctrlclient.Object, opts ...ctrlclient.SubResourceCreateOption,
+				) error {
+					gotRequest = subResource.(*authv1.TokenRequest)
+					gotRequest.Status.Token = "token-1"
+					return nil
+				},
+			}).Build()
+			if tt.createSA {
+				require.NoError(t, client.Create(ctx, &corev1.ServiceAccount{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "app",
+						Namespace: "tenant-ns",
+						UID:       "uid-1",
+					},
+				}))
+			}
+
+			l := &WorkloadIdentityCredentialProvider{}
+			err := l.Init(ctx, client, &secretsv1beta1.HCPAuth{
+				Spec: secretsv1beta1.HCPAuthSpec{
+					Method:           ProviderMethodWorkloadIdentity,
+					WorkloadIdentity: tt.spec,
+				},
+			}, "tenant-ns")
+			if tt.wantInitErr != "" {
+				assert.EqualError(t, err, tt.wantInitErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, types.UID("uid-1"), l.GetUID())
+			assert.Equal(t, "tenant-ns", l.GetNamespace())
+
+			got, err := l.GetCreds(ctx, client)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			require.NotNil(t, gotRequest)
+			assert.Equal(t, tt.wantAudiences, gotRequest.Spec.Audiences)
+			assert.Equal(t, tt.wantExp, *gotRequest.Spec.ExpirationSeconds)
+		})
+	}
+}
@@ -15,3 +15,12 @@ type CredentialProviderBase interface {
 	GetNamespace() string
 	GetCreds(context.Context, ctrlclient.Client) (map[string]interface{}, error)
 }
+
+// LoginDiagnoser is implemented by the credential providers that can diagnose
+// a login that was denied by Vault.
+type LoginDiagnoser interface {
+	// DiagnoseLogin returns the most likely causes of the denied login with
+	// creds, in order of likelihood. It returns nil if the diagnosis is not
+	// enabled.
+	DiagnoseLogin(ctx context.Context, client ctrlclient.Client, creds map[string]any) []string
+}
@@ -84,7 +84,7 @@ func (l *KubernetesCredentialProvider) GetCreds(ctx context.Context, client ctrl
 		return nil, err
 	}
 
-	tr, err := helpers.RequestSAToken(ctx, client, sa, l.authObj.Spec.Kubernetes.TokenExpirationSeconds, l.authObj.Spec.Kubernetes.TokenAudiences)
+	token, err := l.requestToken(ctx, client, sa)
 	if err != nil {
 		logger.Error(err, "Failed to get service account token")
 		return nil, err
@@ -93,6 +93,21 @@ func (l *KubernetesCredentialProvider) GetCreds(ctx context.Context, client ctrl
 	// credentials needed for Kubernetes auth
 	return map[string]interface{}{
 		"role": l.authObj.Spec.Kubernetes.Role,
-		"jwt":  tr.Status.Token,
+		"jwt":  token,
 	}, nil
 }
+
+// requestToken returns a new token for sa, or a cached token if the
+// VaultAuth's ReuseToken is set.
+func (l *KubernetesCredentialProvider) requestToken(ctx context.Context, client ctrlclient.Client, sa *corev1.ServiceAccount) (string, error) {
+	spec := l.authObj.Spec.Kubernetes
+	if spec.ReuseToken {
+		return serviceAccountTokens.getOrRequest(ctx, client, sa, spec.TokenExpirationSeconds, spec.TokenAudiences)
+	}
+
+	tr, err := helpers.RequestSAToken(ctx, client, sa, spec.TokenExpirationSeconds, spec.TokenAudiences)
+	if err != nil {
+		return "", err
+	}
+	return tr.Status.Token, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/hashicorp/vault-secrets-operator/credentials/provider"
+)
+
+var _ provider.LoginDiagnoser = (*KubernetesCredentialProvider)(nil)
+
+// serviceAccountTokenClaims are the claims of a ServiceAccount token that are
+// relevant to the diagnosis of a denied login.
+type serviceAccountTokenClaims struct {
+	Audiences  []string `json:"aud"`
+	Expiration int64    `json:"exp"`
+	Kubernetes struct {
+		ServiceAccount struct {
+			UID types.UID `json:"uid"`
+		} `json:"serviceaccount"`
+	} `json:"kubernetes.io"`
+}
+
+// DiagnoseLogin reviews the ServiceAccount token of a login that was denied by
+// Vault's kubernetes auth method, when the VaultAuth's LoginDiagnostics is set.
+// The token's claims are decoded without verifying its signature, it is
+// verified with the Kubernetes TokenReview API.
+func (l *KubernetesCredentialProvider) DiagnoseLogin(ctx context.Context, client ctrlclient.Client, creds map[string]any) []string {
+	spec := l.authObj.Spec.Kubernetes
+	if spec == nil || !spec.LoginDiagnostics {
+		return nil
+	}
+
+	logger := log.FromContext(ctx).WithName("DiagnoseLogin")
+	saRef := fmt.Sprintf("%s/%s", l.providerNamespace, spec.ServiceAccount)
+	sa, err := l.getServiceAccount(ctx, client)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return []string{fmt.Sprintf("the ServiceAccount %s does not exist", saRef)}
+		}
+		logger.Error(err, "Failed to get the ServiceAccount")
+	}
+
+	var diagnostics []string
+	var audiences []string
+	token, _ := creds["jwt"].(string)
+	claims, err := decodeServiceAccountTokenClaims(token)
+	if err != nil {
+		diagnostics = append(diagnostics, fmt.Sprintf("the ServiceAccount token is malformed: %s", err))
+	} else {
+		audiences = claims.Audiences
+		if sa != nil && claims.Kubernetes.ServiceAccount.UID != "" && claims.Kubernetes.ServiceAccount.UID != sa.UID {
+			diagnostics = append(diagnostics, fmt.Sprintf(
+				"the ServiceAccount token was issued for a previous ServiceAccount %s, it was recreated since", saRef))
+		}
+		if exp := time.Unix(claims.Expiration, 0); claims.Expiration > 0 && time.Now().After(exp) {
+			diagnostics = append(diagnostics, fmt.Sprintf(
+				"the ServiceAccount token expired at %s", exp.UTC().Format(time.RFC3339)))
+		}
+		diagnostics = append(diagnostics, reviewServiceAccountToken(ctx, client, token, claims.Audiences)...)
+	}
+
+	if allowed, err := canReviewTokens(ctx, client, l.providerNamespace, spec.ServiceAccount); err != nil {
+		logger.Error(err, "Failed to review the ServiceAccount's access")
+	} else if !allowed {
+		diagnostics = append(diagnostics, fmt.Sprintf(
+			"the ServiceAccount %s is not allowed to create TokenReviews, it must be bound to the "+
+				"system:auth-delegator ClusterRole if Vault uses the client's token as the reviewer JWT", saRef))
+	}
+
+	diagnostics = append(diagnostics, fmt.Sprintf(
+		"the Vault role %q must be bound to the ServiceAccount name %q and namespace %q, "+
+			"and its audience, if set, must be one of the token's audiences %v",
+		spec.Role, spec.ServiceAccount, l.providerNamespace, audiences))
+
+	return diagnostics
+}
+
+// reviewServiceAccountToken verifies token with the Kubernetes TokenReview API,
+// first with its own audiences, then with the audiences of the Kubernetes API
+// server, which are required when Vault uses the client's token as the
+// reviewer JWT.
+func reviewServiceAccountToken(ctx context.Context, client ctrlclient.Client, token string, audiences []string) []string {
+	review := func(audiences []string) (*authv1.TokenReview, error) {
+		tr := &authv1.TokenReview{
+			Spec: authv1.TokenReviewSpec{
+				Token:     token,
+				Audiences: audiences,
+			},
+		}
+		if err := client.Create(ctx, tr); err != nil {
+			return nil, err
+		}
+		return tr, nil
+	}
+
+	tr, err := review(audiences)
+	if err != nil {
+		return []string{fmt.Sprintf("the ServiceAccount token could not be reviewed: %s", err)}
+	}
+	if !tr.Status.Authenticated {
+		return []string{fmt.Sprintf("the ServiceAccount token is not valid: %s", tr.Status.Error)}
+	}
+
+	if len(audiences) == 0 {
+		return nil
+	}
+
+	tr, err = review(nil)
+	if err != nil {
+		return []string{fmt.Sprintf("the ServiceAccount token could not be reviewed: %s", err)}
+	}
+	if !tr.Status.Authenticated {
+		return []string{fmt.Sprintf(
+			"the ServiceAccount token's audiences %v do not include the Kubernetes API server's audiences, "+
+				"they must be added to the VaultAuth's audiences if Vault uses the client's token as the reviewer JWT",
+			audiences)}
+	}
+
+	return nil
+}
+
+// canReviewTokens returns true if the ServiceAccount is allowed to create
+// TokenReviews.
+func canReviewTokens(ctx context.Context, client ctrlclient.Client, namespace, name string) (bool, error) {
+	sar := &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+			Groups: []string{
+				"system:serviceaccounts",
+				"system:serviceaccounts:" + namespace,
+				"system:authenticated",
+			},
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Group:    authv1.GroupName,
+				Resource: "tokenreviews",
+				Verb:     "create",
+			},
+		},
+	}
+	if err := client.Create(ctx, sar); err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// decodeServiceAccountTokenClaims decodes the claims of the JWT token, without
+// verifying its signature.
+func decodeServiceAccountTokenClaims(token string) (*serviceAccountTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected 3 JWT segments, got %d", len(parts))
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the JWT claims: %w", err)
+	}
+
+	var claims serviceAccountTokenClaims
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the JWT claims: %w", err)
+	}
+	return &claims, nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func testServiceAccountToken(t *testing.T, audiences []string, uid string, exp time.Time) string {
+	t.Helper()
+
+	claims := map[string]any{
+		"aud": audiences,
+		"exp": exp.Unix(),
+		"kubernetes.io": map[string]any{
+			"serviceaccount": map[string]any{
+				"uid": uid,
+			},
+		},
+	}
+	b, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return "header." + base64.RawURLEncoding.EncodeToString(b) + ".signature"
+}
+
+func TestKubernetesCredentialProvider_DiagnoseLogin(t *testing.T) {
+	t.Parallel()
+
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "tenant",
+			Name:      "app",
+			UID:       "uid-1",
+		},
+	}
+	roleHint := `the Vault role "app" must be bound to the ServiceAccount name "app" and namespace "tenant", ` +
+		`and its audience, if set, must be one of the token's audiences [vault]`
+
+	tests := []struct {
+		name            string
+		disabled        bool
+		sa              *corev1.ServiceAccount
+		token           string
+		apiAudiences    []string
+		canReviewTokens bool
+		wantDiagnostics []string
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			sa:       sa,
+			token:    testServiceAccountToken(t, []string{"vault"}, "uid-1", time.Now().Add(time.Hour)),
+		},
+		{
+			name:            "service-account-not-found",
+			token:           testServiceAccountToken(t, []string{"vault"}, "uid-1", time.Now().Add(time.Hour)),
+			wantDiagnostics: []string{"the ServiceAccount tenant/app does not exist"},
+		},
+		{
+			name:            "role-binding",
+			sa:              sa,
+			token:           testServiceAccountToken(t, []string{"vault"}, "uid-1", time.Now().Add(time.Hour)),
+			apiAudiences:    []string{"vault"},
+			canReviewTokens: true,
+			wantDiagnostics: []string{roleHint},
+		},
+		{
+			name:            "missing-api-server-audience",
+			sa:              sa,
+			token:           testServiceAccountToken(t, []string{"vault"}, "uid-1", time.Now().Add(time.Hour)),
+			apiAudiences:    []string{"https://kubernetes.default.svc"},
+			canReviewTokens: true,
+			wantDiagnostics: []string{
+				"the ServiceAccount token's audiences [vault] do not include the Kubernetes API server's audiences, " +
+					"they must be added to the VaultAuth's audiences if Vault uses the client's token as the reviewer JWT",
+				roleHint,
+			},
+		},
+		{
+			name:  "recreated-service-account-and-no-auth-delegator",
+			sa:    sa,
+			token: testServiceAccountToken(t, []string{"vault"}, "uid-0", time.Now().Add(time.Hour)),
+			wantDiagnostics: []string{
+				"the ServiceAccount token was issued for a previous ServiceAccount tenant/app, it was recreated since",
+				"the ServiceAccount token is not valid: invalid token",
+				"the ServiceAccount tenant/app is not allowed to create TokenReviews, it must be bound to the " +
+					"system:auth-delegator ClusterRole if Vault uses the client's token as the reviewer JWT",
+				roleHint,
+			},
+		},
+		{
+			name:            "malformed-token",
+			sa:              sa,
+			token:           "foo",
+			canReviewTokens: true,
+			wantDiagnostics: []string{
+				"the ServiceAccount token is malformed: expected 3 JWT segments, got 1",
+				`the Vault role "app" must be bound to the ServiceAccount name "app" and namespace "tenant", ` +
+					`and its audience, if set, must be one of the token's audiences []`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := testutils.NewFakeClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, client ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.CreateOption) error {
+					switch o := obj.(type) {
+					case *authv1.TokenReview:
+						audiences := o.Spec.Audiences
+						if len(audiences) == 0 {
+							audiences = tt.apiAudiences
+						}
+						claims, err := decodeServiceAccountTokenClaims(o.Spec.Token)
+						require.NoError(t, err)
+						if claims.Kubernetes.ServiceAccount.UID != sa.UID {
+							o.Status.Error = "invalid token"
+							return nil
+						}
+						for _, aud := range claims.Audiences {
+							if slices.Contains(audiences, aud) {
+								o.Status.Authenticated = true
+							}
+						}
+						return nil
+					case *authzv1.SubjectAccessReview:
+						assert.Equal(t, "system:serviceaccount:tenant:app", o.Spec.User)
+						o.Status.Allowed = tt.canReviewTokens
+						return nil
+					}
+					return client.Create(ctx, obj, opts...)
+				},
+			})
+			if tt.sa != nil {
+				builder = builder.WithObjects(tt.sa.DeepCopy())
+			}
+
+			p := &KubernetesCredentialProvider{
+				authObj: &secretsv1beta1.VaultAuth{
+					Spec: secretsv1beta1.VaultAuthSpec{
+						Kubernetes: &secretsv1beta1.VaultAuthConfigKubernetes{
+							Role:             "app",
+							ServiceAccount:   "app",
+							LoginDiagnostics: !tt.disabled,
+						},
+					},
+				},
+				providerNamespace: "tenant",
+			}
+
+			got := p.DiagnoseLogin(context.Background(), builder.Build(), map[string]any{
+				"role": "app",
+				"jwt":  tt.token,
+			})
+			assert.Equal(t, tt.wantDiagnostics, got)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/helpers"
+)
+
+// serviceAccountTokens caches the ServiceAccount tokens that are reused across
+// logins, it is shared by all KubernetesCredentialProviders.
+var serviceAccountTokens = newServiceAccountTokenCache()
+
+type serviceAccountTokenCacheKey struct {
+	uid               types.UID
+	audiences         string
+	expirationSeconds int64
+}
+
+type cachedServiceAccountToken struct {
+	token string
+	// reuseUntil is the time after which the token is no longer reused, it is
+	// half way through its lifetime.
+	reuseUntil time.Time
+	expiration time.Time
+}
+
+// serviceAccountTokenCache holds the ServiceAccount tokens by ServiceAccount UID,
+// audiences, and expiration. A token is only reused until half of its lifetime
+// has elapsed, so that it remains valid for any Vault login that uses it.
+type serviceAccountTokenCache struct {
+	mu      sync.Mutex
+	tokens  map[serviceAccountTokenCacheKey]*cachedServiceAccountToken
+	nowFunc func() time.Time
+}
+
+func newServiceAccountTokenCache() *serviceAccountTokenCache {
+	return &serviceAccountTokenCache{
+		tokens:  make(map[serviceAccountTokenCacheKey]*cachedServiceAccountToken),
+		nowFunc: time.Now,
+	}
+}
+
+// getOrRequest returns the cached token for sa, requesting a new token if none
+// can be reused.
+func (c *serviceAccountTokenCache) getOrRequest(ctx context.Context, client ctrlclient.Client,
+	sa *corev1.ServiceAccount, expirationSeconds int64, audiences []string,
+) (string, error) {
+	sorted := slices.Clone(audiences)
+	slices.Sort(sorted)
+	key := serviceAccountTokenCacheKey{
+		uid:               sa.UID,
+		audiences:         strings.Join(sorted, ","),
+		expirationSeconds: expirationSeconds,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.nowFunc()
+	if t, ok := c.tokens[key]; ok && now.Before(t.reuseUntil) {
+		return t.token, nil
+	}
+
+	tr, err := helpers.RequestSAToken(ctx, client, sa, expirationSeconds, audiences)
+	if err != nil {
+		return "", err
+	}
+
+	c.purge(now)
+	c.tokens[key] = newCachedServiceAccountToken(tr, now)
+	return tr.Status.Token, nil
+}
+
+// purge removes all expired tokens, it must be called with the lock held.
+func (c *serviceAccountTokenCache) purge(now time.Time) {
+	for k, t := range c.tokens {
+		if !now.Before(t.expiration) {
+			delete(c.tokens, k)
+		}
+	}
+}
+
+func newCachedServiceAccountToken(tr *authv1.TokenRequest, now time.Time) *cachedServiceAccountToken {
+	expiration := tr.Status.ExpirationTimestamp.Time
+	return &cachedServiceAccountToken{
+		token:      tr.Status.Token,
+		reuseUntil: now.Add(expiration.Sub(now) / 2),
+		expiration: expiration,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_serviceAccountTokenCache_getOrRequest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "default",
+			UID:       "uid-1",
+		},
+	}
+	var requests int
+	client := testutils.NewFakeClientBuilder().WithObjects(sa).WithInterceptorFuncs(
+		interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, client ctrlclient.Client, subResourceName string,
+				obj ctrlclient.Object, subResource ctrlclient.Object, opts ...ctrlclient.SubResourceCreateOption,
+			) error {
+				requests++
+				tr := subResource.(*authv1.TokenRequest)
+				tr.Status.Token = fmt.Sprintf("token-%d", requests)
+				tr.Status.ExpirationTimestamp = metav1.NewTime(
+					time.Now().Add(time.Duration(*tr.Spec.ExpirationSeconds) * time.Second))
+				return nil
+			},
+		},
+	).Build()
+
+	now := time.Now()
+	c := newServiceAccountTokenCache()
+	c.nowFunc = func() time.Time {
+		return now
+	}
+
+	token, err := c.getOrRequest(ctx, client, sa, 600, []string{"vault", "https://kubernetes.default.svc"})
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	require.Len(t, c.tokens, 1)
+
+	// the audiences are not ordered.
+	got, err := c.getOrRequest(ctx, client, sa, 600, []string{"https://kubernetes.default.svc", "vault"})
+	require.NoError(t, err)
+	assert.Equal(t, token, got)
+	assert.Len(t, c.tokens, 1)
+	assert.Equal(t, 1, requests)
+
+	// distinct audiences or expiration require their own token.
+	_, err = c.getOrRequest(ctx, client, sa, 600, []string{"vault"})
+	require.NoError(t, err)
+	_, err = c.getOrRequest(ctx, client, sa, 1200, []string{"vault"})
+	require.NoError(t, err)
+	assert.Len(t, c.tokens, 3)
+	assert.Equal(t, 3, requests)
+
+	// the token is not reused past half of its lifetime.
+	for _, tok := range c.tokens {
+		tok.reuseUntil = now
+		tok.expiration = now.Add(time.Minute)
+	}
+	_, err = c.getOrRequest(ctx, client, sa, 600, []string{"vault", "https://kubernetes.default.svc"})
+	require.NoError(t, err)
+	assert.Len(t, c.tokens, 3)
+	assert.Equal(t, 4, requests)
+	for k, tok := range c.tokens {
+		if k.audiences == "https://kubernetes.default.svc,vault" {
+			assert.True(t, tok.reuseUntil.After(now))
+		}
+	}
+
+	// expired tokens are purged once a new token is requested.
+	now = now.Add(2 * time.Minute)
+	_, err = c.getOrRequest(ctx, client, sa, 1200, []string{"vault"})
+	require.NoError(t, err)
+	assert.Len(t, c.tokens, 2)
+	assert.Equal(t, 5, requests)
+}
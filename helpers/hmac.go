@@ -12,8 +12,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -68,6 +70,14 @@ func HandleSecretHMAC(ctx context.Context, client ctrlclient.Client,
 	}
 
 	macsEqual := EqualMACS(lastMAC, newMAC)
+	if !macsEqual {
+		// the last MAC may have been computed with the previous HMAC key, in
+		// which case the data has not changed since the key was rotated.
+		macsEqual, _, err = validator.Validate(ctx, client, message, lastMAC)
+		if err != nil {
+			return false, nil, err
+		}
+	}
 	if macsEqual {
 		macsEqual, err = HMACDestinationSecret(ctx, client, validator, obj)
 		if err != nil {
@@ -132,6 +142,28 @@ func HMACDestinationSecret(ctx context.Context, client ctrlclient.Client,
 	return macsEqual, nil
 }
 
+// DetectDestinationSecretDrift returns true if the data of obj's destination
+// Secret has drifted from the data that was last synced to it, or if the Secret
+// no longer exists. It always returns false if the HMAC of obj's data has never
+// been computed.
+// Supported types for obj are:
+// VaultDynamicSecret, VaultStaticSecret, VaultPKISecret, HCPVaultSecretsApp
+func DetectDestinationSecretDrift(ctx context.Context, client ctrlclient.Client,
+	validator HMACValidator, obj ctrlclient.Object,
+) (bool, error) {
+	cur, err := getSecretMac(obj)
+	if err != nil || cur == "" {
+		return false, err
+	}
+
+	matched, err := HMACDestinationSecret(ctx, client, validator, obj)
+	if err != nil {
+		return false, err
+	}
+
+	return !matched, nil
+}
+
 func getSecretMac(obj ctrlclient.Object) (string, error) {
 	var cur string
 	switch t := obj.(type) {
@@ -141,6 +173,10 @@ func getSecretMac(obj ctrlclient.Object) (string, error) {
 		cur = t.Status.SecretMAC
 	case *v1beta1.VaultPKISecret:
 		cur = t.Status.SecretMAC
+	case *v1beta1.VaultSSHCertificate:
+		cur = t.Status.SecretMAC
+	case *v1beta1.VaultTransitKey:
+		cur = t.Status.SecretMAC
 	case *v1beta1.HCPVaultSecretsApp:
 		cur = t.Status.SecretMAC
 	default:
@@ -150,8 +186,15 @@ func getSecretMac(obj ctrlclient.Object) (string, error) {
 }
 
 const (
-	HMACKeyName   = "key"
-	hmacKeyLength = 16
+	HMACKeyName = "key"
+	// HMACKeyHistoryLimit is the number of previous HMAC keys that are kept
+	// after a rotation, they are only used to validate MACs.
+	HMACKeyHistoryLimit = 3
+	hmacKeyLength       = 16
+	// annotationHMACKeyVersion is set on the HMAC key Secret, its value is the
+	// version of the current HMAC key. Version 0 is the key of the HMAC key
+	// Secret itself, every rotation creates a new Secret for the next version.
+	annotationHMACKeyVersion = "vso.secrets.hashicorp.com/hmac-key-version"
 )
 
 type (
@@ -211,7 +254,6 @@ func createHMACKeySecret(ctx context.Context, client ctrlclient.Client, objKey c
 			HMACKeyName: key,
 		},
 	}
-
 	if err := client.Create(ctx, s); err != nil {
 		return nil, err
 	}
@@ -219,6 +261,136 @@ func createHMACKeySecret(ctx context.Context, client ctrlclient.Client, objKey c
 	return s, nil
 }
 
+// RotateHMACKeySecret replaces the HMAC key of the Secret for objKey with a
+// newly generated key, it returns the Secret that holds the new key. The new
+// key is stored in a new immutable Secret for the next key version, after which
+// the HMAC key Secret is updated to point to it. The update fails with a
+// conflict if the HMAC key Secret was changed since it was read, so the switch
+// to the new key is atomic. The previous keys are kept up to
+// HMACKeyHistoryLimit, so that the MACs computed before the rotation remain
+// valid.
+func RotateHMACKeySecret(ctx context.Context, client ctrlclient.Client, objKey ctrlclient.ObjectKey) (*corev1.Secret, error) {
+	cur, err := GetHMACKeySecret(ctx, client, objKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := generateHMACKey()
+	if err != nil {
+		return nil, err
+	}
+
+	version := hmacKeyVersion(cur) + 1
+	versionObjKey := hmacKeyVersionObjKey(objKey, version)
+	s, err := createHMACKeySecret(ctx, client, versionObjKey, key)
+	if apierrors.IsAlreadyExists(err) {
+		// the Secret was left over by a rotation that did not complete, its key
+		// has never been used.
+		s, err = GetHMACKeySecret(ctx, client, versionObjKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HMAC key secret %q: %w", versionObjKey, err)
+	}
+
+	if cur.Annotations == nil {
+		cur.Annotations = make(map[string]string)
+	}
+	cur.Annotations[annotationHMACKeyVersion] = strconv.Itoa(version)
+	if err := client.Update(ctx, cur); err != nil {
+		return nil, fmt.Errorf("failed to update HMAC key secret %q: %w", objKey, err)
+	}
+
+	// the HMAC key Secret itself is never deleted, since it holds the current
+	// key version.
+	for v := version - HMACKeyHistoryLimit - 1; v > 0; v-- {
+		if err := client.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: objKey.Namespace,
+				Name:      hmacKeyVersionObjKey(objKey, v).Name,
+			},
+		}); apierrors.IsNotFound(err) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to delete HMAC key secret %q: %w",
+				hmacKeyVersionObjKey(objKey, v), err)
+		}
+	}
+
+	return s, nil
+}
+
+// GetCurrentHMACKeySecret returns the Secret that holds the current HMAC key
+// of the HMAC key Secret for objKey. It is the HMAC key Secret itself until the
+// key is rotated.
+func GetCurrentHMACKeySecret(ctx context.Context, client ctrlclient.Client, objKey ctrlclient.ObjectKey) (*corev1.Secret, error) {
+	s, err := GetHMACKeySecret(ctx, client, objKey)
+	if err != nil {
+		return nil, err
+	}
+
+	version := hmacKeyVersion(s)
+	if version == 0 {
+		return s, nil
+	}
+
+	return GetHMACKeySecret(ctx, client, hmacKeyVersionObjKey(objKey, version))
+}
+
+// GetHMACKeys returns the current HMAC key of the HMAC key Secret for objKey,
+// followed by the previous keys that are still valid, newest first.
+func GetHMACKeys(ctx context.Context, client ctrlclient.Client, objKey ctrlclient.ObjectKey) ([][]byte, error) {
+	s, err := GetHMACKeySecret(ctx, client, objKey)
+	if err != nil {
+		return nil, err
+	}
+
+	version := hmacKeyVersion(s)
+	var keys [][]byte
+	for v := version; v >= 0 && v >= version-HMACKeyHistoryLimit; v-- {
+		vs := s
+		if v > 0 {
+			vs, err = GetSecret(ctx, client, hmacKeyVersionObjKey(objKey, v))
+			if apierrors.IsNotFound(err) && v < version {
+				// the previous key was pruned.
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("encountered an error getting %q: %w",
+					hmacKeyVersionObjKey(objKey, v), err)
+			}
+		}
+
+		key, err := validateHMACKeySecret(vs)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// hmacKeyVersion returns the version of the current HMAC key of the HMAC key
+// Secret s.
+func hmacKeyVersion(s *corev1.Secret) int {
+	v, err := strconv.Atoi(s.Annotations[annotationHMACKeyVersion])
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// hmacKeyVersionObjKey returns the ObjectKey of the Secret that holds the
+// version of the HMAC key Secret for objKey.
+func hmacKeyVersionObjKey(objKey ctrlclient.ObjectKey, version int) ctrlclient.ObjectKey {
+	if version == 0 {
+		return objKey
+	}
+	return ctrlclient.ObjectKey{
+		Namespace: objKey.Namespace,
+		Name:      fmt.Sprintf("%s-v%d", objKey.Name, version),
+	}
+}
+
 // GetHMACKeySecret returns the Secret for objKey. The Secret.Data must contain a valid HMAC key for HMACKeyName.
 func GetHMACKeySecret(ctx context.Context, client ctrlclient.Client, objKey ctrlclient.ObjectKey) (*corev1.Secret, error) {
 	if err := common.ValidateObjectKey(objKey); err != nil {
@@ -238,9 +410,9 @@ func GetHMACKeySecret(ctx context.Context, client ctrlclient.Client, objKey ctrl
 	return s, nil
 }
 
-// getHMACKeyFromSecret returns the HMAC key from Secret for objKey.
+// getHMACKeyFromSecret returns the current HMAC key from Secret for objKey.
 func getHMACKeyFromSecret(ctx context.Context, client ctrlclient.Client, objKey ctrlclient.ObjectKey) ([]byte, error) {
-	s, err := GetHMACKeySecret(ctx, client, objKey)
+	s, err := GetCurrentHMACKeySecret(ctx, client, objKey)
 	if err != nil {
 		return nil, err
 	}
@@ -285,17 +457,18 @@ func hmacFromSecret(ctx context.Context, client ctrlclient.Client, objKey ctrlcl
 }
 
 // validateMACFromSecret returns true if the messageMAC matches the HMAC of message.
-// The HMAC key is stored in the v1.Secret for objKey.
+// The HMAC key is stored in the v1.Secret for objKey. The messageMAC is also
+// validated with the previous HMAC keys, if the key was rotated.
 // Typically, the messageMAC would come from hmacFromSecret.
 // Returns false on any error.
 func validateMACFromSecret(ctx context.Context, client ctrlclient.Client, objKey ctrlclient.ObjectKey,
 	message, messageMAC []byte,
 ) (bool, []byte, error) {
-	key, err := getHMACKeyFromSecret(ctx, client, objKey)
+	keys, err := GetHMACKeys(ctx, client, objKey)
 	if err != nil {
 		return false, nil, err
 	}
-	return ValidateMAC(message, messageMAC, key)
+	return ValidateMACWithKeys(message, messageMAC, keys...)
 }
 
 // validateHMACKeySecret returns the validated key from the Secret.
@@ -332,6 +505,32 @@ func ValidateMAC(message, messageMAC, key []byte) (bool, []byte, error) {
 	return EqualMACS(messageMAC, expectedMAC), expectedMAC, nil
 }
 
+// ValidateMACWithKeys computes the MAC of message with each of the keys, and
+// compares the result to messageMAC. Returns true if the MAC of any of the
+// keys is equal to messageMAC. The returned MAC is always the MAC computed with
+// the first key. Empty keys are ignored.
+func ValidateMACWithKeys(message, messageMAC []byte, keys ...[]byte) (bool, []byte, error) {
+	var expectedMAC []byte
+	for i, key := range keys {
+		if len(key) == 0 {
+			continue
+		}
+
+		ok, mac, err := ValidateMAC(message, messageMAC, key)
+		if err != nil {
+			return false, nil, err
+		}
+		if i == 0 {
+			expectedMAC = mac
+		}
+		if ok {
+			return true, expectedMAC, nil
+		}
+	}
+
+	return false, expectedMAC, nil
+}
+
 // MACMessage computes the MAC of data with key.
 func MACMessage(key, data []byte) ([]byte, error) {
 	if err := validateKeyLength(key); err != nil {
@@ -16,7 +16,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
@@ -156,6 +158,7 @@ type hmacSecretTestCase struct {
 	want               bool
 	data               map[string][]byte
 	hmacKey            []byte
+	previousHMACKey    []byte
 	secretMAC          string
 	invalidObjKind     bool
 	noCreateHMACSecret bool
@@ -364,6 +367,143 @@ func TestHMACDestinationSecret(t *testing.T) {
 	}
 }
 
+func TestRotateHMACKeySecret(t *testing.T) {
+	ctx := context.Background()
+	c := clientBuilder.Build()
+
+	_, err := RotateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.Error(t, err)
+	assert.True(t, errors.IsNotFound(err))
+
+	orig, err := CreateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	keys, err := GetHMACKeys(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{orig.Data[HMACKeyName]}, keys)
+
+	want := [][]byte{orig.Data[HMACKeyName]}
+	for i := 1; i <= HMACKeyHistoryLimit+2; i++ {
+		got, err := RotateHMACKeySecret(ctx, c, defaultHMACObjKey)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%s-v%d", defaultHMACObjKey.Name, i), got.Name)
+		assert.NoError(t, validateKeyLength(got.Data[HMACKeyName]))
+		assert.Equal(t, ptr.To(true), got.Immutable)
+		assert.Equal(t, hmacSecretLabels, got.Labels)
+
+		cur, err := GetCurrentHMACKeySecret(ctx, c, defaultHMACObjKey)
+		require.NoError(t, err)
+		assert.Equal(t, got.Data, cur.Data)
+
+		// the current key comes first, followed by the previous keys up to the
+		// history limit.
+		want = append([][]byte{got.Data[HMACKeyName]}, want...)
+		if len(want) > HMACKeyHistoryLimit+1 {
+			want = want[:HMACKeyHistoryLimit+1]
+		}
+		keys, err := GetHMACKeys(ctx, c, defaultHMACObjKey)
+		require.NoError(t, err)
+		assert.Equal(t, want, keys)
+	}
+
+	// the HMAC key Secret is kept, while the Secrets of the pruned keys are
+	// deleted.
+	secrets := &corev1.SecretList{}
+	require.NoError(t, c.List(ctx, secrets, client.InNamespace(defaultHMACObjKey.Namespace)))
+	assert.Len(t, secrets.Items, HMACKeyHistoryLimit+2)
+	s, err := GetHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	assert.Equal(t, orig.Data, s.Data)
+}
+
+func TestRotateHMACKeySecret_incomplete(t *testing.T) {
+	ctx := context.Background()
+	c := clientBuilder.Build()
+
+	orig, err := CreateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+
+	// a rotation that did not complete left its Secret behind, the current key
+	// is unchanged.
+	rotatedHMACKey, err := generateHMACKey()
+	require.NoError(t, err)
+	leftover, err := createHMACKeySecret(ctx, c, hmacKeyVersionObjKey(defaultHMACObjKey, 1), rotatedHMACKey)
+	require.NoError(t, err)
+	keys, err := GetHMACKeys(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{orig.Data[HMACKeyName]}, keys)
+
+	got, err := RotateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	assert.Equal(t, leftover.Data, got.Data)
+	keys, err = GetHMACKeys(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{rotatedHMACKey, orig.Data[HMACKeyName]}, keys)
+
+	// the switch to the new key fails if the HMAC key Secret was changed
+	// since it was read.
+	c = testutils.NewFakeClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			return errors.NewConflict(corev1.Resource("secrets"), obj.GetName(), nil)
+		},
+	}).Build()
+	_, err = CreateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	_, err = RotateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.Error(t, err)
+	assert.True(t, errors.IsConflict(err))
+	cur, err := GetCurrentHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	assert.Equal(t, defaultHMACObjKey.Name, cur.Name)
+}
+
+func TestValidateMACWithKeys(t *testing.T) {
+	message := []byte(`{"foo":"bar"}`)
+	previousKey, err := generateHMACKey()
+	require.NoError(t, err)
+	previousMAC, err := MACMessage(previousKey, message)
+	require.NoError(t, err)
+	currentMAC, err := MACMessage(defaultHMACKey, message)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		messageMAC []byte
+		keys       [][]byte
+		want       bool
+	}{
+		{
+			name:       "current-key",
+			messageMAC: currentMAC,
+			keys:       [][]byte{defaultHMACKey, previousKey},
+			want:       true,
+		},
+		{
+			name:       "previous-key",
+			messageMAC: previousMAC,
+			keys:       [][]byte{defaultHMACKey, previousKey},
+			want:       true,
+		},
+		{
+			name:       "no-previous-key",
+			messageMAC: previousMAC,
+			keys:       [][]byte{defaultHMACKey, nil},
+		},
+		{
+			name:       "mismatch",
+			messageMAC: []byte(`invalid`),
+			keys:       [][]byte{defaultHMACKey, previousKey},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotMAC, err := ValidateMACWithKeys(message, tt.messageMAC, tt.keys...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, currentMAC, gotMAC)
+		})
+	}
+}
+
 func TestHandleDestinationSecret(t *testing.T) {
 	defaultData := map[string][]byte{
 		"foo": []byte(`baz`),
@@ -381,6 +521,11 @@ func TestHandleDestinationSecret(t *testing.T) {
 	newDataHMAC, err := MACMessage(defaultHMACKey, marshalRaw(t, newData))
 	require.NoError(t, err)
 
+	rotatedHMACKey, err := generateHMACKey()
+	require.NoError(t, err)
+	rotatedHMAC, err := MACMessage(rotatedHMACKey, b)
+	require.NoError(t, err)
+
 	objMeta := metav1.ObjectMeta{
 		Namespace: "foo",
 		Name:      "bar",
@@ -406,6 +551,23 @@ func TestHandleDestinationSecret(t *testing.T) {
 			wantErr: assert.NoError,
 			want:    true,
 		},
+		{
+			name:            "matched-previous-key",
+			secretMAC:       defaultSecretMAC,
+			objMeta:         objMeta,
+			hmacKey:         rotatedHMACKey,
+			previousHMACKey: defaultHMACKey,
+			destination: secretsv1beta1.Destination{
+				Name: "baz",
+			},
+			data: defaultData,
+			handleSecretHMAC: handleSecretHMACTest{
+				data:    defaultData,
+				wantMAC: rotatedHMAC,
+			},
+			wantErr: assert.NoError,
+			want:    true,
+		},
 		{
 			name:      "mis-matched-new-data",
 			secretMAC: defaultSecretMAC,
@@ -543,6 +705,90 @@ func TestHandleDestinationSecret(t *testing.T) {
 
 // assertSecretHMAC is used to test either HandleSecretHMAC() or
 // HMACDestinationSecret().
+func TestDetectDestinationSecretDrift(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	data := map[string][]byte{
+		"foo": []byte(`baz`),
+	}
+	mac, err := MACMessage(defaultHMACKey, marshalRaw(t, data))
+	require.NoError(t, err)
+	secretMAC := base64.StdEncoding.EncodeToString(mac)
+
+	tests := []struct {
+		name       string
+		secretMAC  string
+		secretData map[string][]byte
+		want       bool
+	}{
+		{
+			name:       "matched",
+			secretMAC:  secretMAC,
+			secretData: data,
+			want:       false,
+		},
+		{
+			name:      "drifted",
+			secretMAC: secretMAC,
+			secretData: map[string][]byte{
+				"foo": []byte(`qux`),
+			},
+			want: true,
+		},
+		{
+			name:      "destination-inexistent",
+			secretMAC: secretMAC,
+			want:      true,
+		},
+		{
+			name: "empty-secretMAC",
+			secretData: map[string][]byte{
+				"foo": []byte(`qux`),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := clientBuilder.Build()
+			_, err := createHMACKeySecret(ctx, c, defaultHMACObjKey, defaultHMACKey)
+			require.NoError(t, err)
+
+			obj := &secretsv1beta1.VaultStaticSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Destination: secretsv1beta1.Destination{
+						Name: "baz",
+					},
+				},
+				Status: secretsv1beta1.VaultStaticSecretStatus{
+					SecretMAC: tt.secretMAC,
+				},
+			}
+			if tt.secretData != nil {
+				require.NoError(t, c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: obj.Namespace,
+						Name:      obj.Spec.Destination.Name,
+					},
+					Data: tt.secretData,
+				}))
+			}
+
+			got, err := DetectDestinationSecretDrift(ctx, c, NewHMACValidator(defaultHMACObjKey), obj)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func assertSecretHMAC(t *testing.T, tt hmacSecretTestCase, c client.Client) {
 	t.Helper()
 
@@ -552,8 +798,12 @@ func assertSecretHMAC(t *testing.T, tt hmacSecretTestCase, c client.Client) {
 		if len(hmacKey) == 0 {
 			hmacKey = defaultHMACKey
 		}
-		_, err := createHMACKeySecret(ctx, c, defaultHMACObjKey, hmacKey)
-		require.NoError(t, err)
+		if len(tt.previousHMACKey) > 0 {
+			createRotatedHMACKeySecret(t, c, tt.previousHMACKey, hmacKey)
+		} else {
+			_, err := createHMACKeySecret(ctx, c, defaultHMACObjKey, hmacKey)
+			require.NoError(t, err)
+		}
 	}
 
 	for objKind, obj := range getHMACObjsMap(t, tt) {
@@ -586,3 +836,19 @@ func assertSecretHMAC(t *testing.T, tt hmacSecretTestCase, c client.Client) {
 		})
 	}
 }
+
+// createRotatedHMACKeySecret creates the HMAC key Secret for defaultHMACObjKey
+// with previous as its key, and rotates it to key.
+func createRotatedHMACKeySecret(t *testing.T, c client.Client, previous, key []byte) {
+	t.Helper()
+
+	ctx := context.Background()
+	s, err := createHMACKeySecret(ctx, c, defaultHMACObjKey, previous)
+	require.NoError(t, err)
+	_, err = createHMACKeySecret(ctx, c, hmacKeyVersionObjKey(defaultHMACObjKey, 1), key)
+	require.NoError(t, err)
+	s.Annotations = map[string]string{
+		annotationHMACKeyVersion: "1",
+	}
+	require.NoError(t, c.Update(ctx, s))
+}
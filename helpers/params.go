@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+type SecretKeyNotFoundError struct {
+	key    string
+	objKey ctrlclient.ObjectKey
+}
+
+func (e *SecretKeyNotFoundError) Error() string {
+	return fmt.Sprintf("key %q not found in Secret %s", e.key, e.objKey)
+}
+
+// GetParamFromSourceValue returns the value of the Secret or ConfigMap key that
+// is referenced by p. The Secret or ConfigMap is expected to be in namespace.
+func GetParamFromSourceValue(ctx context.Context, client ctrlclient.Client,
+	namespace string, p secretsv1beta1.ParamFromSource,
+) (string, error) {
+	switch {
+	case p.SecretKeyRef != nil && p.ConfigMapKeyRef != nil:
+		return "", fmt.Errorf("param %q: secretKeyRef and configMapKeyRef are mutually exclusive", p.Name)
+	case p.SecretKeyRef != nil:
+		objKey := ctrlclient.ObjectKey{Namespace: namespace, Name: p.SecretKeyRef.Name}
+		s, err := GetSecret(ctx, client, objKey)
+		if err != nil {
+			return "", err
+		}
+
+		v, ok := s.Data[p.SecretKeyRef.Key]
+		if !ok {
+			return "", &SecretKeyNotFoundError{
+				key:    p.SecretKeyRef.Key,
+				objKey: objKey,
+			}
+		}
+		return string(v), nil
+	case p.ConfigMapKeyRef != nil:
+		objKey := ctrlclient.ObjectKey{Namespace: namespace, Name: p.ConfigMapKeyRef.Name}
+		cm, err := GetConfigMap(ctx, client, objKey)
+		if err != nil {
+			return "", err
+		}
+
+		if v, ok := cm.Data[p.ConfigMapKeyRef.Key]; ok {
+			return v, nil
+		}
+		if v, ok := cm.BinaryData[p.ConfigMapKeyRef.Key]; ok {
+			return string(v), nil
+		}
+		return "", &ConfigMapKeyNotFoundError{
+			key:    p.ConfigMapKeyRef.Key,
+			objKey: objKey,
+		}
+	default:
+		return "", fmt.Errorf("param %q: one of secretKeyRef or configMapKeyRef is required", p.Name)
+	}
+}
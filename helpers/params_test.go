@@ -0,0 +1,178 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestGetParamFromSourceValue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "params",
+		},
+		Data: map[string][]byte{
+			"csr": []byte("-----BEGIN CERTIFICATE REQUEST-----"),
+		},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "params",
+		},
+		Data: map[string]string{
+			"ttl": "1h",
+		},
+		BinaryData: map[string][]byte{
+			"common_name": []byte("example.com"),
+		},
+	}
+	client := testutils.NewFakeClientBuilder().WithObjects(secret, cm).Build()
+
+	tests := []struct {
+		name      string
+		namespace string
+		p         secretsv1beta1.ParamFromSource
+		want      string
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "secret",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "csr",
+				SecretKeyRef: &secretsv1beta1.SecretKeyRef{
+					Name: "params",
+					Key:  "csr",
+				},
+			},
+			want:    "-----BEGIN CERTIFICATE REQUEST-----",
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "configmap",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "ttl",
+				ConfigMapKeyRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "params",
+					Key:  "ttl",
+				},
+			},
+			want:    "1h",
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "configmap-binary-data",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "common_name",
+				ConfigMapKeyRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "params",
+					Key:  "common_name",
+				},
+			},
+			want:    "example.com",
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "secret-key-not-found",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "csr",
+				SecretKeyRef: &secretsv1beta1.SecretKeyRef{
+					Name: "params",
+					Key:  "other",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`key "other" not found in Secret default/params`, i...)
+			},
+		},
+		{
+			name:      "configmap-key-not-found",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "ttl",
+				ConfigMapKeyRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "params",
+					Key:  "other",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`key "other" not found in ConfigMap default/params`, i...)
+			},
+		},
+		{
+			name:      "secret-not-found",
+			namespace: "other",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "csr",
+				SecretKeyRef: &secretsv1beta1.SecretKeyRef{
+					Name: "params",
+					Key:  "csr",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t, apierrors.IsNotFound(err), i...)
+			},
+		},
+		{
+			name:      "both",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "csr",
+				SecretKeyRef: &secretsv1beta1.SecretKeyRef{
+					Name: "params",
+					Key:  "csr",
+				},
+				ConfigMapKeyRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "params",
+					Key:  "ttl",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`param "csr": secretKeyRef and configMapKeyRef are mutually exclusive`, i...)
+			},
+		},
+		{
+			name:      "none",
+			namespace: "default",
+			p: secretsv1beta1.ParamFromSource{
+				Name: "csr",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`param "csr": one of secretKeyRef or configMapKeyRef is required`, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := GetParamFromSourceValue(ctx, client, tt.namespace, tt.p)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
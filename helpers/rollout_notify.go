@@ -0,0 +1,187 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	argorolloutsv1alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+)
+
+// AnnotationNotifiedAt is updated to notify a Pod of a secret rotation.
+const AnnotationNotifiedAt = "vso.secrets.hashicorp.com/notifiedAt"
+
+// notifyHTTPClient is used to send the notifications to a
+// v1beta1.RolloutRestartNotify URL.
+var notifyHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// rolloutRestartKinds maps the supported RolloutRestartTarget Kinds to their
+// GroupVersionKind.
+var rolloutRestartKinds = map[string]schema.GroupVersionKind{
+	"DaemonSet":    appsv1.SchemeGroupVersion.WithKind("DaemonSet"),
+	"Deployment":   appsv1.SchemeGroupVersion.WithKind("Deployment"),
+	"StatefulSet":  appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+	"argo.Rollout": argorolloutsv1alpha1.SchemeGroupVersion.WithKind("Rollout"),
+}
+
+// rolloutRestartNotification is the body of the HTTP POST request sent to a
+// v1beta1.RolloutRestartNotify URL.
+type rolloutRestartNotification struct {
+	Namespace  string `json:"namespace"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	NotifiedAt string `json:"notifiedAt"`
+}
+
+// notifyRolloutRestartTarget notifies the target in namespace of a secret
+// rotation, rather than restarting it. See v1beta1.RolloutRestartNotify for
+// more details.
+func notifyRolloutRestartTarget(ctx context.Context, namespace string, target v1beta1.RolloutRestartTarget, client ctrlclient.Client) error {
+	notifiedAt := time.Now().Format(time.RFC3339)
+	if target.Notify != nil && target.Notify.URL != "" {
+		return notifyURL(ctx, target.Notify.URL, rolloutRestartNotification{
+			Namespace:  namespace,
+			Kind:       target.Kind,
+			Name:       target.Name,
+			NotifiedAt: notifiedAt,
+		})
+	}
+
+	selector, err := notifyPodSelector(ctx, namespace, target, client)
+	if err != nil {
+		return err
+	}
+
+	pods := &corev1.PodList{}
+	if err := client.List(ctx, pods,
+		ctrlclient.InNamespace(namespace),
+		ctrlclient.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return fmt.Errorf("failed to list Pods for selector %q, err=%w", selector, err)
+	}
+
+	var errs error
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		patch := ctrlclient.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[AnnotationNotifiedAt] = notifiedAt
+		if err := client.Patch(ctx, pod, patch); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to annotate Pod %s, err=%w",
+				ctrlclient.ObjectKeyFromObject(pod), err))
+		}
+	}
+
+	return errs
+}
+
+// notifyPodSelector returns the selector of the Pods to notify for target. It
+// defaults to the target resource's 'spec.selector'.
+func notifyPodSelector(ctx context.Context, namespace string, target v1beta1.RolloutRestartTarget, client ctrlclient.Client) (labels.Selector, error) {
+	labelSelector := &metav1.LabelSelector{}
+	if target.Notify != nil && target.Notify.PodSelector != nil {
+		labelSelector = target.Notify.PodSelector
+	} else {
+		gvk := schema.GroupVersionKind{
+			Group:   target.Group,
+			Version: target.Version,
+			Kind:    target.Kind,
+		}
+		if target.Version == "" {
+			var ok bool
+			if gvk, ok = rolloutRestartKinds[target.Kind]; !ok {
+				return nil, fmt.Errorf("unsupported Kind %q for %T", target.Kind, target)
+			}
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespace)
+		obj.SetName(target.Name)
+		objKey := ctrlclient.ObjectKeyFromObject(obj)
+		if err := client.Get(ctx, objKey, obj); err != nil {
+			return nil, fmt.Errorf("failed to Get %s for objKey %s, err=%w", gvk, objKey, err)
+		}
+
+		selector, found, err := unstructured.NestedFieldNoCopy(obj.Object, "spec", "selector")
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.selector for %s %s, err=%w", target.Kind, objKey, err)
+		}
+		// a typed object without a selector may store it as null.
+		if !found || selector == nil {
+			return nil, fmt.Errorf(
+				"%s %s has no spec.selector, a pod selector must be configured", target.Kind, objKey)
+		}
+		m, ok := selector.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid spec.selector for %s %s, unexpected type %T", target.Kind, objKey, selector)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, labelSelector); err != nil {
+			return nil, fmt.Errorf("invalid spec.selector for %s %s, err=%w", target.Kind, objKey, err)
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod selector, err=%w", err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("pod selector cannot be empty")
+	}
+
+	return selector, nil
+}
+
+// notifyURL sends the notification n to url in an HTTP POST request.
+func notifyURL(ctx context.Context, url string, n rolloutRestartNotification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := notifyHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification to %s, err=%w", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("failed to send notification to %s, unexpected status code %d",
+			url, resp.StatusCode)
+	}
+
+	return nil
+}
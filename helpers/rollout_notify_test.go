@@ -0,0 +1,227 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func TestRolloutRestart_notifyPods(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	beforeNotify := time.Now().Add(-1 * time.Second)
+
+	newPod := func(name string, l map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels:    l,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		objs       []ctrlclient.Object
+		target     v1beta1.RolloutRestartTarget
+		wantPods   []string
+		wantIgnore []string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "Deployment-selector",
+			objs: []ctrlclient.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "foo",
+					},
+					Spec: appsv1.DeploymentSpec{
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "foo"},
+						},
+					},
+				},
+				newPod("foo-1", map[string]string{"app": "foo"}),
+				newPod("foo-2", map[string]string{"app": "foo"}),
+				newPod("bar-1", map[string]string{"app": "bar"}),
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Kind:     "Deployment",
+				Name:     "foo",
+				Strategy: "notify",
+			},
+			wantPods:   []string{"foo-1", "foo-2"},
+			wantIgnore: []string{"bar-1"},
+			wantErr:    assert.NoError,
+		},
+		{
+			name: "PodSelector",
+			objs: []ctrlclient.Object{
+				newPod("connect-1", map[string]string{"strimzi.io/cluster": "connect"}),
+				newPod("bar-1", map[string]string{"app": "bar"}),
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Group:    "kafka.strimzi.io",
+				Version:  "v1beta2",
+				Kind:     "KafkaConnect",
+				Name:     "connect",
+				Strategy: "notify",
+				Notify: &v1beta1.RolloutRestartNotify{
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"strimzi.io/cluster": "connect"},
+					},
+				},
+			},
+			wantPods:   []string{"connect-1"},
+			wantIgnore: []string{"bar-1"},
+			wantErr:    assert.NoError,
+		},
+		{
+			name: "no-spec.selector",
+			objs: []ctrlclient.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "foo",
+					},
+				},
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Kind:     "Deployment",
+				Name:     "foo",
+				Strategy: "notify",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "has no spec.selector", i...)
+			},
+		},
+		{
+			name: "empty-PodSelector",
+			target: v1beta1.RolloutRestartTarget{
+				Kind:     "Deployment",
+				Name:     "foo",
+				Strategy: "notify",
+				Notify: &v1beta1.RolloutRestartNotify{
+					PodSelector: &metav1.LabelSelector{},
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "pod selector cannot be empty", i...)
+			},
+		},
+		{
+			name: "invalid-Kind",
+			target: v1beta1.RolloutRestartTarget{
+				Kind:     "invalid",
+				Name:     "foo",
+				Strategy: "notify",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, `unsupported Kind "invalid"`, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			c := testutils.NewFakeClientBuilder().WithObjects(tt.objs...).Build()
+			err := RolloutRestart(ctx, "default", tt.target, c)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			for _, name := range tt.wantPods {
+				pod := &corev1.Pod{}
+				require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "default", Name: name}, pod))
+				notifiedAt, err := time.Parse(time.RFC3339, pod.Annotations[AnnotationNotifiedAt])
+				require.NoError(t, err)
+				assert.True(t, notifiedAt.After(beforeNotify))
+			}
+			for _, name := range tt.wantIgnore {
+				pod := &corev1.Pod{}
+				require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "default", Name: name}, pod))
+				assert.NotContains(t, pod.Annotations, AnnotationNotifiedAt)
+			}
+		})
+	}
+}
+
+func TestRolloutRestart_notifyURL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "ok",
+			statusCode: http.StatusNoContent,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "server-error",
+			statusCode: http.StatusInternalServerError,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "unexpected status code 500", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			var got rolloutRestartNotification
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(tt.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			target := v1beta1.RolloutRestartTarget{
+				Kind:     "Deployment",
+				Name:     "foo",
+				Strategy: "notify",
+				Notify: &v1beta1.RolloutRestartNotify{
+					URL: server.URL + "/-/reload",
+				},
+			}
+			err := RolloutRestart(ctx, "default", target, testutils.NewFakeClientBuilder().Build())
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, "default", got.Namespace)
+			assert.Equal(t, "Deployment", got.Kind)
+			assert.Equal(t, "foo", got.Name)
+			_, err = time.Parse(time.RFC3339, got.NotifiedAt)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -5,14 +5,19 @@ package helpers
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	argorolloutsv1alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -24,15 +29,41 @@ import (
 // AnnotationRestartedAt is updated to trigger a rollout-restart
 const AnnotationRestartedAt = "vso.secrets.hashicorp.com/restartedAt"
 
+// AnnotationFluxReconcileRequestedAt is updated to request the reconciliation
+// of a Flux Kustomization.
+const AnnotationFluxReconcileRequestedAt = "reconcile.fluxcd.io/requestedAt"
+
+// fluxKustomizationGVK is the GroupVersionKind of the Flux Kustomization.
+var fluxKustomizationGVK = schema.GroupVersionKind{
+	Group:   "kustomize.toolkit.fluxcd.io",
+	Version: "v1",
+	Kind:    "Kustomization",
+}
+
+const (
+	rolloutRestartStrategyAnnotation = "annotation"
+	rolloutRestartStrategyScale      = "scale"
+	rolloutRestartStrategyRestartAt  = "restartAt"
+	rolloutRestartStrategyNotify     = "notify"
+
+	defaultRolloutRestartAnnotationsPath = "spec.template.metadata.annotations"
+
+	rolloutRestartTriggerTimestamp   = "timestamp"
+	rolloutRestartTriggerContentHash = "content-hash"
+)
+
 // HandleRolloutRestarts for all v1beta1.RolloutRestartTarget(s) configured for obj.
 // Supported objs are: v1beta1.VaultDynamicSecret, v1beta1.VaultStaticSecret, v1beta1.VaultPKISecret
 // Please note the following:
 // - a rollout-restart will be triggered for each configured v1beta1.RolloutRestartTarget
 // - the rollout-restart action has no support for roll-back
 // - does not wait for the action to complete
+// - the validator is only required for targets with the content-hash Trigger
 //
 // Returns all errors encountered.
-func HandleRolloutRestarts(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object, recorder record.EventRecorder) error {
+func HandleRolloutRestarts(ctx context.Context, client ctrlclient.Client, validator HMACValidator,
+	obj ctrlclient.Object, recorder record.EventRecorder,
+) error {
 	logger := log.FromContext(ctx)
 
 	var targets []v1beta1.RolloutRestartTarget
@@ -43,6 +74,10 @@ func HandleRolloutRestarts(ctx context.Context, client ctrlclient.Client, obj ct
 		targets = t.Spec.RolloutRestartTargets
 	case *v1beta1.VaultPKISecret:
 		targets = t.Spec.RolloutRestartTargets
+	case *v1beta1.VaultSSHCertificate:
+		targets = t.Spec.RolloutRestartTargets
+	case *v1beta1.VaultTransitKey:
+		targets = t.Spec.RolloutRestartTargets
 	case *v1beta1.HCPVaultSecretsApp:
 		targets = t.Spec.RolloutRestartTargets
 	default:
@@ -57,8 +92,16 @@ func HandleRolloutRestarts(ctx context.Context, client ctrlclient.Client, obj ct
 	}
 
 	var errs error
+	var contentHash string
 	for _, target := range targets {
-		if err := RolloutRestart(ctx, obj.GetNamespace(), target, client); err != nil {
+		var err error
+		if target.Trigger == rolloutRestartTriggerContentHash && contentHash == "" {
+			contentHash, err = destinationContentHash(ctx, client, validator, obj)
+		}
+		if err == nil {
+			err = rolloutRestart(ctx, obj.GetNamespace(), target, client, contentHash)
+		}
+		if err != nil {
 			errs = errors.Join(err)
 			recorder.Eventf(obj, corev1.EventTypeWarning, consts.ReasonRolloutRestartFailed,
 				"Rollout restart failed for target %#v: err=%s", target, err)
@@ -78,12 +121,41 @@ func HandleRolloutRestarts(ctx context.Context, client ctrlclient.Client, obj ct
 }
 
 // RolloutRestart patches the target in namespace for rollout-restart.
-// Supported target Kinds are: DaemonSet, Deployment, StatefulSet
+// Supported target Kinds are: DaemonSet, Deployment, StatefulSet, argo.Rollout,
+// Rollout, Kustomization. Any other resource is supported when the target's Version is set, see
+// rolloutRestartGVK for more details. Targets with the notify Strategy are
+// notified instead, see notifyRolloutRestartTarget for more details.
+//
+// The rollout-restart is always triggered with a timestamp, regardless of the
+// target's Trigger, see HandleRolloutRestarts for content-hash support.
 func RolloutRestart(ctx context.Context, namespace string, target v1beta1.RolloutRestartTarget, client ctrlclient.Client) error {
+	return rolloutRestart(ctx, namespace, target, client, "")
+}
+
+// rolloutRestart patches the target in namespace for rollout-restart. If the
+// target's Trigger is content-hash, then contentHash is used as the value of
+// the AnnotationRestartedAt annotation, otherwise the current time is.
+func rolloutRestart(ctx context.Context, namespace string, target v1beta1.RolloutRestartTarget, client ctrlclient.Client, contentHash string) error {
 	if namespace == "" {
 		return fmt.Errorf("namespace cannot be empty")
 	}
 
+	restartedAt := time.Now().Format(time.RFC3339)
+	if target.Trigger == rolloutRestartTriggerContentHash {
+		if contentHash == "" {
+			return fmt.Errorf("content hash cannot be empty for trigger %q", target.Trigger)
+		}
+		restartedAt = contentHash
+	}
+
+	if target.Strategy == rolloutRestartStrategyNotify {
+		return notifyRolloutRestartTarget(ctx, namespace, target, client)
+	}
+
+	if target.Version != "" {
+		return rolloutRestartGVK(ctx, namespace, target, client, restartedAt)
+	}
+
 	objectMeta := metav1.ObjectMeta{
 		Namespace: namespace,
 		Name:      target.Name,
@@ -103,18 +175,24 @@ func RolloutRestart(ctx context.Context, namespace string, target v1beta1.Rollou
 		obj = &appsv1.StatefulSet{
 			ObjectMeta: objectMeta,
 		}
-	case "argo.Rollout":
+	case "argo.Rollout", "Rollout":
 		obj = &argorolloutsv1alpha1.Rollout{
 			ObjectMeta: objectMeta,
 		}
+	case "Kustomization":
+		return requestFluxReconcile(ctx, namespace, target, client, restartedAt)
 	default:
 		return fmt.Errorf("unsupported Kind %q for %T", target.Kind, target)
 	}
 
-	return patchForRolloutRestart(ctx, obj, client)
+	return patchForRolloutRestart(ctx, obj, client, restartedAt,
+		target.Trigger == rolloutRestartTriggerContentHash)
 }
 
-func patchForRolloutRestart(ctx context.Context, obj ctrlclient.Object, client ctrlclient.Client) error {
+// patchForRolloutRestart patches obj's pod template to include the
+// AnnotationRestartedAt annotation with the restartedAt value. An argo.Rollout
+// has its 'spec.restartAt' patched instead, unless useTemplate is true.
+func patchForRolloutRestart(ctx context.Context, obj ctrlclient.Object, client ctrlclient.Client, restartedAt string, useTemplate bool) error {
 	objKey := ctrlclient.ObjectKeyFromObject(obj)
 	if err := client.Get(ctx, objKey, obj); err != nil {
 		return fmt.Errorf("failed to Get object for objKey %s, err=%w", objKey, err)
@@ -129,28 +207,169 @@ func patchForRolloutRestart(ctx context.Context, obj ctrlclient.Object, client c
 		if t.Spec.Template.ObjectMeta.Annotations == nil {
 			t.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
 		}
-		t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = time.Now().Format(time.RFC3339)
+		t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = restartedAt
 		return client.Patch(ctx, t, patch)
 	case *appsv1.StatefulSet:
 		patch := ctrlclient.StrategicMergeFrom(t.DeepCopy())
 		if t.Spec.Template.ObjectMeta.Annotations == nil {
 			t.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
 		}
-		t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = time.Now().Format(time.RFC3339)
+		t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = restartedAt
 		return client.Patch(ctx, t, patch)
 	case *appsv1.DaemonSet:
 		patch := ctrlclient.StrategicMergeFrom(t.DeepCopy())
 		if t.Spec.Template.ObjectMeta.Annotations == nil {
 			t.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
 		}
-		t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = time.Now().Format(time.RFC3339)
+		t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = restartedAt
 		return client.Patch(ctx, t, patch)
 	case *argorolloutsv1alpha1.Rollout:
 		// use MergeFrom() since it supports CRDs whereas StrategicMergeFrom() does not.
 		patch := ctrlclient.MergeFrom(t.DeepCopy())
-		t.Spec.RestartAt = &metav1.Time{Time: time.Now()}
+		if useTemplate {
+			// patching the pod template results in a new revision of the Rollout,
+			// which is only created if the restartedAt value has changed.
+			if t.Spec.Template.ObjectMeta.Annotations == nil {
+				t.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
+			}
+			t.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt] = restartedAt
+		} else {
+			t.Spec.RestartAt = &metav1.Time{Time: time.Now()}
+		}
 		return client.Patch(ctx, t, patch)
 	default:
 		return fmt.Errorf("unsupported type %T for rollout-restart patching", t)
 	}
 }
+
+// rolloutRestartGVK triggers the rollout-restart of the resource identified by
+// the target's Group, Version, and Kind according to the target's Strategy.
+// The restartedAt value only applies to the annotation Strategy.
+func rolloutRestartGVK(ctx context.Context, namespace string, target v1beta1.RolloutRestartTarget, client ctrlclient.Client, restartedAt string) error {
+	if target.Kind == "" {
+		return fmt.Errorf("kind cannot be empty")
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   target.Group,
+		Version: target.Version,
+		Kind:    target.Kind,
+	})
+	obj.SetNamespace(namespace)
+	obj.SetName(target.Name)
+
+	objKey := ctrlclient.ObjectKeyFromObject(obj)
+	if err := client.Get(ctx, objKey, obj); err != nil {
+		return fmt.Errorf("failed to Get %s for objKey %s, err=%w", obj.GroupVersionKind(), objKey, err)
+	}
+
+	// use MergeFrom() since it supports CRDs whereas StrategicMergeFrom() does not.
+	patch := ctrlclient.MergeFrom(obj.DeepCopy())
+	switch target.Strategy {
+	case rolloutRestartStrategyAnnotation, "":
+		path := target.AnnotationsPath
+		if path == "" {
+			path = defaultRolloutRestartAnnotationsPath
+		}
+		fields := append(strings.Split(path, "."), AnnotationRestartedAt)
+		if err := unstructured.SetNestedField(obj.Object, restartedAt, fields...); err != nil {
+			return fmt.Errorf("failed to set annotation at %q, err=%w", path, err)
+		}
+		return client.Patch(ctx, obj, patch)
+	case rolloutRestartStrategyRestartAt:
+		if err := unstructured.SetNestedField(
+			obj.Object, time.Now().UTC().Format(time.RFC3339), "spec", "restartAt"); err != nil {
+			return fmt.Errorf("failed to set spec.restartAt, err=%w", err)
+		}
+		return client.Patch(ctx, obj, patch)
+	case rolloutRestartStrategyScale:
+		replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if err != nil {
+			return fmt.Errorf("failed to get spec.replicas, err=%w", err)
+		}
+		if !found || replicas == 0 {
+			return fmt.Errorf("%s %s has no replicas, cannot restart it", obj.GetKind(), objKey)
+		}
+
+		if err := unstructured.SetNestedField(obj.Object, int64(0), "spec", "replicas"); err != nil {
+			return err
+		}
+		if err := client.Patch(ctx, obj, patch); err != nil {
+			return fmt.Errorf("failed to scale down %s %s, err=%w", obj.GetKind(), objKey, err)
+		}
+
+		patch = ctrlclient.MergeFrom(obj.DeepCopy())
+		if err := unstructured.SetNestedField(obj.Object, replicas, "spec", "replicas"); err != nil {
+			return err
+		}
+		if err := client.Patch(ctx, obj, patch); err != nil {
+			return fmt.Errorf("failed to scale up %s %s to %d replicas, err=%w",
+				obj.GetKind(), objKey, replicas, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported Strategy %q for %T", target.Strategy, target)
+	}
+}
+
+// requestFluxReconcile requests the reconciliation of the Flux Kustomization
+// target by setting its AnnotationFluxReconcileRequestedAt annotation to the
+// restartedAt value. The Kustomization's workloads are left untouched, so that
+// any restart is driven by Flux, and never drifts from the source of truth.
+func requestFluxReconcile(ctx context.Context, namespace string, target v1beta1.RolloutRestartTarget, client ctrlclient.Client, restartedAt string) error {
+	switch target.Strategy {
+	case rolloutRestartStrategyAnnotation, "":
+	default:
+		return fmt.Errorf("unsupported Strategy %q for Kind %q", target.Strategy, target.Kind)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(fluxKustomizationGVK)
+	obj.SetNamespace(namespace)
+	obj.SetName(target.Name)
+
+	objKey := ctrlclient.ObjectKeyFromObject(obj)
+	if err := client.Get(ctx, objKey, obj); err != nil {
+		return fmt.Errorf("failed to Get %s for objKey %s, err=%w", fluxKustomizationGVK, objKey, err)
+	}
+
+	// use MergeFrom() since it supports CRDs whereas StrategicMergeFrom() does not.
+	patch := ctrlclient.MergeFrom(obj.DeepCopy())
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationFluxReconcileRequestedAt] = restartedAt
+	obj.SetAnnotations(annotations)
+
+	return client.Patch(ctx, obj, patch)
+}
+
+// destinationContentHash returns the hex encoded HMAC of the data of obj's
+// destination Secret.
+func destinationContentHash(ctx context.Context, client ctrlclient.Client, validator HMACValidator, obj ctrlclient.Object) (string, error) {
+	if validator == nil {
+		return "", fmt.Errorf("an HMAC validator is required to compute the content hash")
+	}
+
+	s, ok, err := GetSyncableSecret(ctx, client, obj)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("destination Secret not found, cannot compute the content hash")
+	}
+
+	message, err := json.Marshal(s.Data)
+	if err != nil {
+		return "", err
+	}
+
+	mac, err := validator.HMAC(ctx, client, message)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(mac), nil
+}
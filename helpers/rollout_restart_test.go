@@ -5,6 +5,7 @@ package helpers
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"testing"
 	"time"
@@ -13,7 +14,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/hashicorp/vault-secrets-operator/api/v1beta1"
@@ -129,6 +134,74 @@ func TestRolloutRestart(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "Rollout",
+			obj: &argorolloutsv1alpha1.Rollout{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "fred",
+				},
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Kind: "Rollout",
+				Name: "fred",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "GVK-Deployment-annotation",
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "foo",
+				},
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Group:    "apps",
+				Version:  "v1",
+				Kind:     "Deployment",
+				Name:     "foo",
+				Strategy: "annotation",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "GVK-argo.Rollout-restartAt",
+			obj: &argorolloutsv1alpha1.Rollout{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "fred",
+				},
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Group:    "argoproj.io",
+				Version:  "v1alpha1",
+				Kind:     "Rollout",
+				Name:     "fred",
+				Strategy: "restartAt",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "GVK-invalid-Strategy",
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "foo",
+				},
+			},
+			target: v1beta1.RolloutRestartTarget{
+				Group:    "apps",
+				Version:  "v1",
+				Kind:     "Deployment",
+				Name:     "foo",
+				Strategy: "invalid",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err,
+					fmt.Sprintf("unsupported Strategy %q", "invalid"), i...)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -152,6 +225,235 @@ func TestRolloutRestart(t *testing.T) {
 	}
 }
 
+func TestRolloutRestart_GVK(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	builder := testutils.NewFakeClientBuilder()
+	beforeRolloutRestart := time.Now().Add(-1 * time.Second)
+
+	newKafkaConnect := func(name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "kafka.strimzi.io",
+			Version: "v1beta2",
+			Kind:    "KafkaConnect",
+		})
+		obj.SetNamespace("default")
+		obj.SetName(name)
+		require.NoError(t, unstructured.SetNestedField(obj.Object, int64(3), "spec", "replicas"))
+		return obj
+	}
+
+	tests := []struct {
+		name         string
+		obj          *unstructured.Unstructured
+		target       v1beta1.RolloutRestartTarget
+		wantReplicas int64
+		wantPath     []string
+		wantErr      assert.ErrorAssertionFunc
+	}{
+		{
+			name: "annotation-custom-path",
+			obj:  newKafkaConnect("connect"),
+			target: v1beta1.RolloutRestartTarget{
+				Group:           "kafka.strimzi.io",
+				Version:         "v1beta2",
+				Kind:            "KafkaConnect",
+				Name:            "connect",
+				Strategy:        "annotation",
+				AnnotationsPath: "spec.template.pod.metadata.annotations",
+			},
+			wantReplicas: 3,
+			wantPath:     []string{"spec", "template", "pod", "metadata", "annotations"},
+			wantErr:      assert.NoError,
+		},
+		{
+			name: "annotation-default-path",
+			obj:  newKafkaConnect("connect"),
+			target: v1beta1.RolloutRestartTarget{
+				Group:   "kafka.strimzi.io",
+				Version: "v1beta2",
+				Kind:    "KafkaConnect",
+				Name:    "connect",
+			},
+			wantReplicas: 3,
+			wantPath:     []string{"spec", "template", "metadata", "annotations"},
+			wantErr:      assert.NoError,
+		},
+		{
+			name: "scale",
+			obj:  newKafkaConnect("connect"),
+			target: v1beta1.RolloutRestartTarget{
+				Group:    "kafka.strimzi.io",
+				Version:  "v1beta2",
+				Kind:     "KafkaConnect",
+				Name:     "connect",
+				Strategy: "scale",
+			},
+			wantReplicas: 3,
+			wantErr:      assert.NoError,
+		},
+		{
+			name: "scale-no-replicas",
+			obj: func() *unstructured.Unstructured {
+				obj := newKafkaConnect("connect")
+				unstructured.RemoveNestedField(obj.Object, "spec", "replicas")
+				return obj
+			}(),
+			target: v1beta1.RolloutRestartTarget{
+				Group:    "kafka.strimzi.io",
+				Version:  "v1beta2",
+				Kind:     "KafkaConnect",
+				Name:     "connect",
+				Strategy: "scale",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "has no replicas, cannot restart it", i...)
+			},
+		},
+		{
+			name: "not-found",
+			obj:  newKafkaConnect("connect"),
+			target: v1beta1.RolloutRestartTarget{
+				Group:   "kafka.strimzi.io",
+				Version: "v1beta2",
+				Kind:    "KafkaConnect",
+				Name:    "other",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "failed to Get", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			c := builder.Build()
+			require.NoError(t, c.Create(ctx, tt.obj))
+
+			err := RolloutRestart(ctx, tt.obj.GetNamespace(), tt.target, c)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(tt.obj.GroupVersionKind())
+			require.NoError(t, c.Get(ctx, ctrlclient.ObjectKeyFromObject(tt.obj), got))
+
+			replicas, _, err := unstructured.NestedInt64(got.Object, "spec", "replicas")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReplicas, replicas)
+
+			if len(tt.wantPath) > 0 {
+				restartAt, found, err := unstructured.NestedString(
+					got.Object, append(tt.wantPath, AnnotationRestartedAt)...)
+				require.NoError(t, err)
+				require.True(t, found)
+				restartAtTime, err := time.Parse(time.RFC3339, restartAt)
+				require.NoError(t, err)
+				assert.True(t, restartAtTime.After(beforeRolloutRestart))
+			}
+		})
+	}
+}
+
+func TestRolloutRestart_fluxKustomization(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	builder := testutils.NewFakeClientBuilder()
+	beforeRolloutRestart := time.Now().Add(-1 * time.Second)
+
+	newKustomization := func() *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(fluxKustomizationGVK)
+		obj.SetNamespace("default")
+		obj.SetName("apps")
+		obj.SetAnnotations(map[string]string{
+			"foo": "bar",
+		})
+		require.NoError(t, unstructured.SetNestedField(obj.Object, "./apps", "spec", "path"))
+		return obj
+	}
+
+	tests := []struct {
+		name    string
+		target  v1beta1.RolloutRestartTarget
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "default-strategy",
+			target: v1beta1.RolloutRestartTarget{
+				Kind: "Kustomization",
+				Name: "apps",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "annotation",
+			target: v1beta1.RolloutRestartTarget{
+				Kind:     "Kustomization",
+				Name:     "apps",
+				Strategy: "annotation",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid-strategy",
+			target: v1beta1.RolloutRestartTarget{
+				Kind:     "Kustomization",
+				Name:     "apps",
+				Strategy: "scale",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`unsupported Strategy "scale" for Kind "Kustomization"`, i...)
+			},
+		},
+		{
+			name: "not-found",
+			target: v1beta1.RolloutRestartTarget{
+				Kind: "Kustomization",
+				Name: "other",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "failed to Get", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			obj := newKustomization()
+			c := builder.Build()
+			require.NoError(t, c.Create(ctx, obj))
+
+			err := RolloutRestart(ctx, obj.GetNamespace(), tt.target, c)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(fluxKustomizationGVK)
+			require.NoError(t, c.Get(ctx, ctrlclient.ObjectKeyFromObject(obj), got))
+
+			annotations := got.GetAnnotations()
+			assert.Equal(t, "bar", annotations["foo"])
+			requestedAt, err := time.Parse(time.RFC3339, annotations[AnnotationFluxReconcileRequestedAt])
+			require.NoError(t, err)
+			assert.True(t, requestedAt.After(beforeRolloutRestart))
+
+			// the spec is never modified.
+			assert.Equal(t, map[string]any{"path": "./apps"}, got.Object["spec"])
+		})
+	}
+}
+
 func assertPatchedRolloutRestartObj(t *testing.T, ctx context.Context, obj ctrlclient.Object, beforeRolloutRestart time.Time, client ctrlclient.WithWatch) {
 	t.Helper()
 
@@ -185,3 +487,166 @@ func assertPatchedRolloutRestartObj(t *testing.T, ctx context.Context, obj ctrlc
 		"restartAt should be after beforeRolloutRestart",
 		attr, restartAtTime, "beforeRolloutRestart", beforeRolloutRestart)
 }
+
+func TestHandleRolloutRestarts_contentHash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	validator := NewHMACValidator(defaultHMACObjKey)
+	data := map[string][]byte{
+		"foo": []byte(`baz`),
+	}
+	mac, err := MACMessage(defaultHMACKey, marshalRaw(t, data))
+	require.NoError(t, err)
+	wantHash := hex.EncodeToString(mac)
+
+	newVSS := func(targets ...v1beta1.RolloutRestartTarget) *v1beta1.VaultStaticSecret {
+		return &v1beta1.VaultStaticSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "vss",
+			},
+			Spec: v1beta1.VaultStaticSecretSpec{
+				Destination: v1beta1.Destination{
+					Name: "app",
+				},
+				RolloutRestartTargets: targets,
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		obj             *v1beta1.VaultStaticSecret
+		destination     bool
+		validator       HMACValidator
+		wantDeployment  string
+		wantRollout     string
+		wantRolloutSpec bool
+		wantErr         assert.ErrorAssertionFunc
+	}{
+		{
+			name: "content-hash",
+			obj: newVSS(
+				v1beta1.RolloutRestartTarget{
+					Kind:    "Deployment",
+					Name:    "foo",
+					Trigger: "content-hash",
+				},
+				v1beta1.RolloutRestartTarget{
+					Kind:    "argo.Rollout",
+					Name:    "bar",
+					Trigger: "content-hash",
+				},
+			),
+			destination:    true,
+			validator:      validator,
+			wantDeployment: wantHash,
+			wantRollout:    wantHash,
+			wantErr:        assert.NoError,
+		},
+		{
+			name: "mixed-triggers",
+			obj: newVSS(
+				v1beta1.RolloutRestartTarget{
+					Kind:    "Deployment",
+					Name:    "foo",
+					Trigger: "content-hash",
+				},
+				v1beta1.RolloutRestartTarget{
+					Kind:    "argo.Rollout",
+					Name:    "bar",
+					Trigger: "timestamp",
+				},
+			),
+			destination:     true,
+			validator:       validator,
+			wantDeployment:  wantHash,
+			wantRolloutSpec: true,
+			wantErr:         assert.NoError,
+		},
+		{
+			name: "no-destination",
+			obj: newVSS(
+				v1beta1.RolloutRestartTarget{
+					Kind:    "Deployment",
+					Name:    "foo",
+					Trigger: "content-hash",
+				},
+			),
+			validator: validator,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "destination Secret not found", i...)
+			},
+		},
+		{
+			name: "no-validator",
+			obj: newVSS(
+				v1beta1.RolloutRestartTarget{
+					Kind:    "Deployment",
+					Name:    "foo",
+					Trigger: "content-hash",
+				},
+			),
+			destination: true,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "an HMAC validator is required", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			objs := []ctrlclient.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "foo",
+					},
+				},
+				&argorolloutsv1alpha1.Rollout{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "bar",
+					},
+				},
+			}
+			if tt.destination {
+				objs = append(objs, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      "app",
+					},
+					Data: data,
+				})
+			}
+			c := testutils.NewFakeClientBuilder().WithObjects(objs...).Build()
+			_, err := createHMACKeySecret(ctx, c, defaultHMACObjKey, defaultHMACKey)
+			require.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(10)
+			err = HandleRolloutRestarts(ctx, c, tt.validator, tt.obj, recorder)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			// the annotation values are stable across repeated rollout-restarts.
+			for i := 0; i < 2; i++ {
+				require.NoError(t, HandleRolloutRestarts(ctx, c, tt.validator, tt.obj, recorder))
+
+				var deployment appsv1.Deployment
+				require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "default", Name: "foo"}, &deployment))
+				assert.Equal(t, tt.wantDeployment,
+					deployment.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt])
+
+				var rollout argorolloutsv1alpha1.Rollout
+				require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "default", Name: "bar"}, &rollout))
+				assert.Equal(t, tt.wantRollout,
+					rollout.Spec.Template.ObjectMeta.Annotations[AnnotationRestartedAt])
+				assert.Equal(t, tt.wantRolloutSpec, rollout.Spec.RestartAt != nil)
+			}
+		})
+	}
+}
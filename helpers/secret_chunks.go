@@ -0,0 +1,243 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/consts"
+)
+
+const (
+	// AnnotationSecretChunks is set on the destination Secrets that are
+	// configured for Chunking and whose data exceeds the Secret size limit, its
+	// value is the comma separated list of the Secrets that hold the data, in
+	// order.
+	AnnotationSecretChunks = "vso.secrets.hashicorp.com/chunks"
+
+	// AnnotationSecretChunkIndex is set on the Secrets that hold a chunk of a
+	// destination Secret's data, its value is the index of the chunk.
+	AnnotationSecretChunkIndex = "vso.secrets.hashicorp.com/chunk-index"
+
+	// annotationChunkDestination is set on the Secrets that hold a chunk of a
+	// destination Secret's data, its value is the name of the destination Secret.
+	annotationChunkDestination = "vso.secrets.hashicorp.com/chunk-destination"
+)
+
+var _ error = (*SecretDataTooLargeError)(nil)
+
+// SecretDataTooLargeError is returned when the data to sync to a destination
+// Secret exceeds the Secret size limit, and the destination is not configured
+// for Chunking.
+type SecretDataTooLargeError struct {
+	// Secret is the name of the destination Secret.
+	Secret string
+	// Size of the data in bytes.
+	Size int
+	// Limit is the maximum size of a Secret's data in bytes.
+	Limit int
+}
+
+func (e *SecretDataTooLargeError) Error() string {
+	return fmt.Sprintf("data for secret %q is %d bytes, which exceeds the Secret size limit "+
+		"of %d bytes, consider excluding _raw, or enabling chunking on the destination",
+		e.Secret, e.Size, e.Limit)
+}
+
+// secretDataSize returns the size of data, as it is accounted for by the
+// Secret size limit.
+func secretDataSize(data map[string][]byte) int {
+	var size int
+	for _, v := range data {
+		size += len(v)
+	}
+	return size
+}
+
+// checkSecretDataSize returns a SecretDataTooLargeError if data exceeds the
+// Secret size limit.
+func checkSecretDataSize(name string, data map[string][]byte) error {
+	if size := secretDataSize(data); size > corev1.MaxSecretSize {
+		return &SecretDataTooLargeError{
+			Secret: name,
+			Size:   size,
+			Limit:  corev1.MaxSecretSize,
+		}
+	}
+	return nil
+}
+
+// splitSecretData splits data into chunks that do not exceed limit. The keys
+// are assigned to the chunks in order, a key is moved to the next chunk if it
+// does not fit in the current one. Values that exceed limit are split across
+// consecutive chunks.
+func splitSecretData(data map[string][]byte, limit int) []map[string][]byte {
+	var chunks []map[string][]byte
+	cur := make(map[string][]byte)
+	var size int
+	next := func() {
+		chunks = append(chunks, cur)
+		cur = make(map[string][]byte)
+		size = 0
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		v := data[k]
+		// start a new chunk, unless the value has to be split anyway.
+		if size > 0 && size+len(v) > limit && (len(v) <= limit || size == limit) {
+			next()
+		}
+		for {
+			n := min(len(v), limit-size)
+			cur[k] = append(cur[k], v[:n]...)
+			if cur[k] == nil {
+				cur[k] = []byte{}
+			}
+			size += n
+			v = v[n:]
+			if len(v) == 0 {
+				break
+			}
+			next()
+		}
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+
+	return chunks
+}
+
+// secretChunkName returns the name of the Secret that holds the chunk at
+// index of the destination Secret name.
+func secretChunkName(name string, index int) string {
+	return fmt.Sprintf("%s-%d", name, index)
+}
+
+// syncSecretChunks splits data across the chunk Secrets of the destination d.
+// Each chunk is annotated with its own content MAC if macFunc is set. Returns
+// the names of the chunk Secrets in order.
+func syncSecretChunks(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object,
+	d *secretsv1beta1.Destination, data map[string][]byte,
+	labels, annotations map[string]string, references []metav1.OwnerReference,
+	macFunc contentMACFunc,
+) ([]string, error) {
+	logger := log.FromContext(ctx).WithName("syncSecret")
+
+	var names []string
+	for i, chunk := range splitSecretData(data, corev1.MaxSecretSize) {
+		key := ctrlclient.ObjectKey{
+			Namespace: obj.GetNamespace(),
+			Name:      secretChunkName(d.Name, i),
+		}
+
+		chunkAnnotations := maps.Clone(annotations)
+		if macFunc != nil {
+			mac, err := macFunc(chunk)
+			if err != nil {
+				return nil, err
+			}
+			chunkAnnotations[AnnotationContentMAC] = mac
+		}
+		chunkAnnotations[AnnotationSecretChunkIndex] = strconv.Itoa(i)
+		chunkAnnotations[annotationChunkDestination] = d.Name
+
+		s, exists, err := getSecretExists(ctx, client, key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			if err := checkSecretIsOwnedByObj(s, references); err != nil {
+				return nil, err
+			}
+			if s.Type != corev1.SecretTypeOpaque {
+				return nil, fmt.Errorf("cannot sync the chunk secret %s, its type %s is not %s",
+					key, s.Type, corev1.SecretTypeOpaque)
+			}
+		} else {
+			s = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		}
+
+		s.Data = chunk
+		s.SetLabels(labels)
+		s.SetAnnotations(chunkAnnotations)
+		s.SetOwnerReferences(references)
+		if exists {
+			logger.V(consts.LogLevelDebug).Info("Updating chunk secret", "secret", key)
+			err = client.Update(ctx, s)
+		} else {
+			logger.V(consts.LogLevelDebug).Info("Creating chunk secret", "secret", key)
+			err = client.Create(ctx, s)
+		}
+		if err != nil {
+			return nil, err
+		}
+		recordSyncedSecretVersion(s)
+
+		names = append(names, key.Name)
+	}
+
+	return names, nil
+}
+
+// joinSecretChunks returns the data held by the chunk Secrets names in
+// namespace, the values that were split across chunks are concatenated in
+// order.
+func joinSecretChunks(ctx context.Context, client ctrlclient.Client, namespace string, names []string) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	for _, name := range names {
+		s, err := GetSecret(ctx, client, ctrlclient.ObjectKey{Namespace: namespace, Name: name})
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range s.Data {
+			data[k] = append(data[k], v...)
+			if data[k] == nil {
+				data[k] = []byte{}
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// pruneSecretChunks deletes the chunk Secrets of the destination d that are
+// not in current, e.g. once the data shrinks.
+func pruneSecretChunks(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object,
+	d *secretsv1beta1.Destination, current []string,
+) error {
+	owned, err := FindSecretsOwnedByObj(ctx, client, obj)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, s := range owned {
+		if s.Annotations[annotationChunkDestination] != d.Name || slices.Contains(current, s.Name) {
+			continue
+		}
+		if err := client.Delete(ctx, &s); ctrlclient.IgnoreNotFound(err) != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
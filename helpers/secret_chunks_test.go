@@ -0,0 +1,223 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+)
+
+func Test_splitSecretData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		data  map[string][]byte
+		limit int
+		want  []map[string][]byte
+	}{
+		{
+			name:  "empty",
+			data:  map[string][]byte{},
+			limit: 10,
+		},
+		{
+			name: "single-chunk",
+			data: map[string][]byte{
+				"a": []byte("12345"),
+				"b": []byte("123"),
+				"c": {},
+			},
+			limit: 10,
+			want: []map[string][]byte{
+				{
+					"a": []byte("12345"),
+					"b": []byte("123"),
+					"c": {},
+				},
+			},
+		},
+		{
+			name: "keys-moved-to-next-chunk",
+			data: map[string][]byte{
+				"a": []byte("123456"),
+				"b": []byte("123456"),
+				"c": []byte("1234"),
+			},
+			limit: 10,
+			want: []map[string][]byte{
+				{
+					"a": []byte("123456"),
+				},
+				{
+					"b": []byte("123456"),
+					"c": []byte("1234"),
+				},
+			},
+		},
+		{
+			name: "value-split-across-chunks",
+			data: map[string][]byte{
+				"_raw": []byte("1234567890abcdefghijklmnop"),
+				"a":    []byte("12"),
+			},
+			limit: 10,
+			want: []map[string][]byte{
+				{
+					"_raw": []byte("1234567890"),
+				},
+				{
+					"_raw": []byte("abcdefghij"),
+				},
+				{
+					"_raw": []byte("klmnop"),
+					"a":    []byte("12"),
+				},
+			},
+		},
+		{
+			name: "value-split-after-partial-chunk",
+			data: map[string][]byte{
+				"a": []byte("1234"),
+				"b": []byte("abcdefghijklmn"),
+			},
+			limit: 10,
+			want: []map[string][]byte{
+				{
+					"a": []byte("1234"),
+					"b": []byte("abcdef"),
+				},
+				{
+					"b": []byte("ghijklmn"),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := splitSecretData(tt.data, tt.limit)
+			assert.Equal(t, tt.want, got)
+			for _, chunk := range got {
+				assert.LessOrEqual(t, secretDataSize(chunk), tt.limit)
+			}
+		})
+	}
+}
+
+func TestSyncSecret_chunking(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:   "dest",
+				Create: true,
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	hmacSecret, err := CreateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	opts := DefaultSyncOptions()
+	opts.HMACValidator = NewHMACValidator(defaultHMACObjKey)
+
+	objKey := ctrlclient.ObjectKey{Namespace: o.Namespace, Name: o.Spec.Destination.Name}
+	listSecrets := func() []corev1.Secret {
+		t.Helper()
+		secrets := &corev1.SecretList{}
+		require.NoError(t, c.List(ctx, secrets, ctrlclient.InNamespace(o.Namespace)))
+		return secrets.Items
+	}
+	wantMAC := func(data map[string][]byte) string {
+		t.Helper()
+
+		mac, err := MACMessage(hmacSecret.Data[HMACKeyName], contentMessage(data))
+		require.NoError(t, err)
+		return hex.EncodeToString(mac)
+	}
+
+	large := map[string][]byte{
+		"_raw": bytes.Repeat([]byte("a"), corev1.MaxSecretSize),
+		"foo":  bytes.Repeat([]byte("b"), corev1.MaxSecretSize/2),
+	}
+
+	// oversized data is refused unless chunking is enabled.
+	err = SyncSecret(ctx, c, o, large)
+	var sizeErr *SecretDataTooLargeError
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, &SecretDataTooLargeError{
+		Secret: objKey.Name,
+		Size:   corev1.MaxSecretSize + corev1.MaxSecretSize/2,
+		Limit:  corev1.MaxSecretSize,
+	}, sizeErr)
+	assert.Empty(t, listSecrets())
+
+	o.Spec.Destination.Chunking = true
+	o.Spec.Destination.ContentMAC = true
+	require.NoError(t, SyncSecret(ctx, c, o, large, opts))
+
+	var dest corev1.Secret
+	require.NoError(t, c.Get(ctx, objKey, &dest))
+	assert.Empty(t, dest.Data)
+	assert.Equal(t, corev1.SecretTypeOpaque, dest.Type)
+	assert.Equal(t, "dest-0,dest-1", dest.Annotations[AnnotationSecretChunks])
+	assert.Equal(t, wantMAC(large), dest.Annotations[AnnotationContentMAC])
+	assert.Len(t, listSecrets(), 3)
+
+	for i, name := range strings.Split(dest.Annotations[AnnotationSecretChunks], ",") {
+		var chunk corev1.Secret
+		require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: o.Namespace, Name: name}, &chunk))
+		assert.Equal(t, []string{"0", "1"}[i], chunk.Annotations[AnnotationSecretChunkIndex])
+		assert.Equal(t, objKey.Name, destinationSecretName(&chunk))
+		assert.LessOrEqual(t, secretDataSize(chunk.Data), corev1.MaxSecretSize)
+		// each chunk has its own content MAC.
+		assert.Equal(t, wantMAC(chunk.Data), chunk.Annotations[AnnotationContentMAC])
+		require.NoError(t, checkSecretIsOwnedByObj(&chunk, dest.OwnerReferences))
+	}
+
+	// the chunks are joined for the syncable secret.
+	s, ok, err := GetSyncableSecret(ctx, c, o)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, large, s.Data)
+
+	// the chunks are pruned once the data fits in a single Secret.
+	small := map[string][]byte{"foo": []byte("bar")}
+	require.NoError(t, SyncSecret(ctx, c, o, small, opts))
+	require.NoError(t, c.Get(ctx, objKey, &dest))
+	assert.Equal(t, small, dest.Data)
+	assert.NotContains(t, dest.Annotations, AnnotationSecretChunks)
+	assert.Equal(t, wantMAC(small), dest.Annotations[AnnotationContentMAC])
+	assert.Len(t, listSecrets(), 1)
+
+	// chunking is not supported for immutable destinations.
+	o.Spec.Destination.Immutable = true
+	require.ErrorAs(t, SyncSecret(ctx, c, o, large, opts), &sizeErr)
+}
@@ -4,12 +4,23 @@
 package helpers
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -19,6 +30,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -26,6 +39,7 @@ import (
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/template"
 	"github.com/hashicorp/vault-secrets-operator/utils"
 )
 
@@ -34,6 +48,47 @@ const (
 	HVSSecretTypeKV       = "kv"
 	HVSSecretTypeRotating = "rotating"
 	HVSSecretTypeDynamic  = "dynamic"
+
+	// DeletionPolicyRetain retains the destination Secret when its owner is
+	// deleted.
+	DeletionPolicyRetain = "Retain"
+	// DeletionPolicyDelete deletes the destination Secret when its owner is
+	// deleted.
+	DeletionPolicyDelete = "Delete"
+
+	// AnnotationContentMAC is set on the destination Secrets that are owned by VSO
+	// and configured with ContentMAC, its value is the hex encoded HMAC-SHA256 of
+	// the Secret's data, computed with the operator's HMAC key.
+	AnnotationContentMAC = "vso.secrets.hashicorp.com/content-mac"
+
+	// AnnotationImmutableSecret is set on the destination Secrets that are
+	// configured as Immutable, its value is the name of the immutable Secret that
+	// holds the current data.
+	AnnotationImmutableSecret = "vso.secrets.hashicorp.com/immutable-secret"
+
+	// annotationImmutableDestination is set on the immutable Secrets, its value
+	// is the name of the destination Secret they were created for.
+	annotationImmutableDestination = "vso.secrets.hashicorp.com/immutable-destination"
+
+	// annotationAdoptedLabels is set on the adopted destination Secrets, its value
+	// is the comma separated list of the label keys that the Secret had before
+	// its adoption.
+	annotationAdoptedLabels = "vso.secrets.hashicorp.com/adopted-labels"
+
+	// annotationAdoptedAnnotations is set on the adopted destination Secrets, its
+	// value is the comma separated list of the annotation keys that the Secret
+	// had before its adoption.
+	annotationAdoptedAnnotations = "vso.secrets.hashicorp.com/adopted-annotations"
+
+	// annotationImmutableGeneration is set on the immutable Secrets, it is
+	// incremented whenever an immutable Secret becomes the current one of its
+	// destination. The previous immutable Secrets are pruned in its order, since
+	// their creation timestamps only have a resolution of one second.
+	annotationImmutableGeneration = "vso.secrets.hashicorp.com/immutable-generation"
+
+	// defaultImmutableHistoryLimit is the number of previous immutable Secrets
+	// that are retained when the destination's ImmutableHistoryLimit is not set.
+	defaultImmutableHistoryLimit = 3
 )
 
 var SecretDataErrorContainsRaw = fmt.Errorf("key '%s' not permitted in Secret data", SecretDataKeyRaw)
@@ -119,6 +174,19 @@ func FindSecretsOwnedByObj(ctx context.Context, client ctrlclient.Client, obj ct
 	return result, nil
 }
 
+// FindManagedSecrets returns all corev1.Secrets in the cluster that were
+// created by VSO. Those are secrets that have a copy of OwnerLabels, along with
+// the owner's UID label.
+func FindManagedSecrets(ctx context.Context, client ctrlclient.Client) ([]corev1.Secret, error) {
+	secrets := &corev1.SecretList{}
+	if err := client.List(ctx, secrets,
+		ctrlclient.MatchingLabels(OwnerLabels), ctrlclient.HasLabels{labelOwnerRefUID}); err != nil {
+		return nil, err
+	}
+
+	return secrets.Items, nil
+}
+
 func DefaultSyncOptions() SyncOptions {
 	return SyncOptions{
 		PruneOrphans: true,
@@ -129,6 +197,20 @@ func DefaultSyncOptions() SyncOptions {
 type SyncOptions struct {
 	// PruneOrphans controls whether to delete any previously synced k8s Secrets.
 	PruneOrphans bool
+	// Destination to sync the data to instead of the object's
+	// Spec.Destination. It must be one of the object's Spec.Destinations.
+	Destination *secretsv1beta1.Destination
+	// Annotations to set on the Secret in addition to the Destination's
+	// Annotations, taking precedence over them. They are only set when the
+	// Secret is owned by the object.
+	Annotations map[string]string
+	// Metadata of the synced secret, e.g. the KV secret version, the lease ID,
+	// or the certificate serial number. The templates in the Destination's
+	// Labels and Annotations are rendered with it as SecretInput.Metadata.
+	Metadata map[string]any
+	// HMACValidator computes the content MAC of the Destinations configured with
+	// ContentMAC.
+	HMACValidator HMACValidator
 }
 
 // SyncSecret writes data to a Kubernetes Secret for obj. All configuring is
@@ -150,6 +232,12 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 		return err
 	}
 
+	// the orphans are pruned based on all the destinations of obj.
+	destinations := append([]secretsv1beta1.Destination{*meta.Destination}, meta.Destinations...)
+	if options.Destination != nil {
+		meta.Destination = options.Destination
+	}
+
 	logger := log.FromContext(ctx).WithName("syncSecret").WithValues(
 		"secretName", meta.Destination.Name, "create", meta.Destination.Create)
 	key := ctrlclient.ObjectKey{
@@ -169,7 +257,7 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 	pruneOrphans := func() {
 		if options.PruneOrphans {
 			// for now we treat orphan pruning errors as being non-fatal.
-			if err := pruneOrphanSecrets(ctx, client, obj, meta.Destination); err != nil {
+			if err := pruneOrphanSecrets(ctx, client, obj, destinations...); err != nil {
 				logger.V(consts.LogLevelWarning).Error(err, "Failed to prune orphan secrets",
 					"owner", ctrlclient.ObjectKeyFromObject(obj).String())
 			} else {
@@ -190,11 +278,19 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 		// It will make cleaning up previous labels/annotation additions difficult,  since we don't know
 		// what we set previously. It is possible to keep the previous labels/annotations in the
 		// syncable-secret's Status, but...
+		if err := validateSecretTypeData(key.Name, dest.Type, data); err != nil {
+			return err
+		}
+		if err := checkSecretDataSize(key.Name, data); err != nil {
+			return err
+		}
+
 		dest.Data = data
 		logger.V(consts.LogLevelDebug).Info("Updating secret")
 		if err := client.Update(ctx, dest); err != nil {
 			return err
 		}
+		recordSyncedSecretVersion(dest)
 
 		pruneOrphans()
 
@@ -207,6 +303,20 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 		secretType = meta.Destination.Type
 	}
 
+	if err := validateSecretTypeData(key.Name, secretType, data); err != nil {
+		return err
+	}
+
+	// data that exceeds the Secret size limit is only synced when the
+	// destination is configured for chunking.
+	var chunked bool
+	if err := checkSecretDataSize(key.Name, data); err != nil {
+		if !meta.Destination.Chunking || meta.Destination.Immutable {
+			return err
+		}
+		chunked = true
+	}
+
 	// these are the OwnerReferences that should be included in any Secret that is created/owned by
 	// the syncable-secret
 	references := []metav1.OwnerReference{
@@ -222,7 +332,7 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 			"secret", ctrlclient.ObjectKeyFromObject(dest))
 
 		checkOwnerShip := true
-		if meta.Destination.Overwrite {
+		if meta.Destination.Overwrite || meta.Destination.Adopt {
 			checkOwnerShip = HasOwnerLabels(dest)
 		}
 
@@ -230,6 +340,20 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 			if err := checkSecretIsOwnedByObj(dest, references); err != nil {
 				return err
 			}
+		} else if meta.Destination.Adopt {
+			// the Secret is not owned by any VSO resource, so we sync its data before
+			// taking ownership of it.
+			if dest.Type != secretType {
+				return fmt.Errorf("cannot adopt the destination secret %s, its type %s does not match %s",
+					key, dest.Type, secretType)
+			}
+
+			logger.Info("Adopting secret", "secret", ctrlclient.ObjectKeyFromObject(dest))
+			dest.Data = data
+			recordAdoptedMetadata(dest)
+			if err := client.Update(ctx, dest); err != nil {
+				return err
+			}
 		}
 	} else {
 		// secret does not exist, so we are going to create it.
@@ -243,17 +367,67 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 			"secret", ctrlclient.ObjectKeyFromObject(dest))
 	}
 
+	destLabels, err := renderDestinationMetadata(obj, meta.Destination.Labels, options.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to render the destination labels: %w", err)
+	}
+	for k, v := range destLabels {
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("invalid value for the destination label %q: %s",
+				k, strings.Join(errs, "; "))
+		}
+	}
+	destAnnotations, err := renderDestinationMetadata(obj, meta.Destination.Annotations, options.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to render the destination annotations: %w", err)
+	}
+
 	// common setup/updates
-	// set any labels configured in meta.Destination.Labels
+	// the labels and annotations that an adopted Secret had before its adoption
+	// are retained.
 	labels := make(map[string]string)
-	for k, v := range meta.Destination.Labels {
+	annotations := destAnnotations
+	if meta.Destination.Adopt {
+		labels, annotations = adoptedMetadata(dest)
+		maps.Copy(annotations, destAnnotations)
+	}
+	if len(options.Annotations) > 0 {
+		annotations = maps.Clone(annotations)
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		maps.Copy(annotations, options.Annotations)
+	}
+	// the content MAC always takes precedence over the configured annotations.
+	annotations = maps.Clone(annotations)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	delete(annotations, AnnotationContentMAC)
+	var macFunc contentMACFunc
+	if meta.Destination.ContentMAC {
+		if options.HMACValidator == nil {
+			return errors.New("the content MAC requires an HMACValidator")
+		}
+		macFunc = func(data map[string][]byte) (string, error) {
+			return contentMAC(ctx, client, options.HMACValidator, data)
+		}
+		mac, err := macFunc(data)
+		if err != nil {
+			return fmt.Errorf("failed to compute the content MAC: %w", err)
+		}
+		annotations[AnnotationContentMAC] = mac
+	}
+
+	// set any labels configured in meta.Destination.Labels
+	for k, v := range destLabels {
 		labels[k] = v
 	}
 
 	ownerLabels, err := OwnerLabelsForObj(obj)
 	// always add the "owner" labels last to guard against intersections with meta.Destination.Labels
 	for k, v := range ownerLabels {
-		_, ok := labels[k]
+		_, ok := destLabels[k]
 		if ok {
 			logger.V(consts.LogLevelWarning).Info(
 				"Label conflicts with a default owner label, owner label takes precedence",
@@ -262,10 +436,39 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 		labels[k] = v
 	}
 
+	if meta.Destination.Immutable {
+		name, err := syncImmutableSecret(ctx, client, obj, meta.Destination, secretType, data,
+			labels, annotations, references)
+		if err != nil {
+			return err
+		}
+		// the destination Secret only points to the immutable Secret that holds the data.
+		annotations[AnnotationImmutableSecret] = name
+		secretType = corev1.SecretTypeOpaque
+		data = nil
+	} else {
+		delete(annotations, AnnotationImmutableSecret)
+	}
+
+	var chunks []string
+	if chunked {
+		chunks, err = syncSecretChunks(ctx, client, obj, meta.Destination, data,
+			labels, annotations, references, macFunc)
+		if err != nil {
+			return err
+		}
+		// the destination Secret only lists the chunk Secrets that hold the data.
+		annotations[AnnotationSecretChunks] = strings.Join(chunks, ",")
+		secretType = corev1.SecretTypeOpaque
+		data = nil
+	} else {
+		delete(annotations, AnnotationSecretChunks)
+	}
+
 	lastType := dest.Type
 	dest.Data = data
 	dest.Type = secretType
-	dest.SetAnnotations(meta.Destination.Annotations)
+	dest.SetAnnotations(annotations)
 	dest.SetLabels(labels)
 	dest.SetOwnerReferences(references)
 	logger.V(consts.LogLevelTrace).Info("ObjectMeta", "objectMeta", dest.ObjectMeta)
@@ -306,13 +509,310 @@ func SyncSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Ob
 			return err
 		}
 	}
+	recordSyncedSecretVersion(dest)
+
+	if meta.Destination.Immutable {
+		// for now we treat immutable Secret pruning errors as being non-fatal.
+		if err := pruneImmutableSecrets(ctx, client, obj, meta.Destination,
+			annotations[AnnotationImmutableSecret]); err != nil {
+			logger.V(consts.LogLevelWarning).Error(err, "Failed to prune previous immutable secrets")
+		}
+	}
+
+	if meta.Destination.Chunking {
+		// for now we treat chunk Secret pruning errors as being non-fatal.
+		if err := pruneSecretChunks(ctx, client, obj, meta.Destination, chunks); err != nil {
+			logger.V(consts.LogLevelWarning).Error(err, "Failed to prune previous chunk secrets")
+		}
+	}
 
 	pruneOrphans()
 
 	return nil
 }
 
-func pruneOrphanSecrets(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object, dest *secretsv1beta1.Destination) error {
+// renderDestinationMetadata returns m with each of its values that contains a
+// template rendered with the secret metadata, and the annotations and labels of
+// obj. The secret data is never included in the template input, since labels
+// and annotations are not confidential.
+func renderDestinationMetadata(obj ctrlclient.Object, m map[string]string, metadata map[string]any) (map[string]string, error) {
+	if !slices.ContainsFunc(slices.Collect(maps.Values(m)), isTemplateText) {
+		return m, nil
+	}
+
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	input := NewSecretInput(nil, metadata, obj.GetAnnotations(), obj.GetLabels())
+	rendered := make(map[string]string, len(m))
+	for k, v := range m {
+		if !isTemplateText(v) {
+			rendered[k] = v
+			continue
+		}
+
+		tmpl := template.NewSecretTemplate("")
+		if err := tmpl.Parse(k, v); err != nil {
+			return nil, fmt.Errorf("%q: %w", k, err)
+		}
+		b, err := tmpl.ExecuteTemplate(k, input)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", k, err)
+		}
+		rendered[k] = strings.TrimSpace(string(b))
+	}
+
+	return rendered, nil
+}
+
+// isTemplateText returns true if s contains a template action.
+func isTemplateText(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// syncImmutableSecret creates the immutable Secret that holds data for the
+// destination d, unless it already exists. The Secret's name is derived from
+// data, so a new Secret is only created when the data changes. Returns the name
+// of the immutable Secret.
+func syncImmutableSecret(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object,
+	d *secretsv1beta1.Destination, secretType corev1.SecretType, data map[string][]byte,
+	labels, annotations map[string]string, references []metav1.OwnerReference,
+) (string, error) {
+	key := ctrlclient.ObjectKey{
+		Namespace: obj.GetNamespace(),
+		Name:      immutableSecretName(d.Name, data),
+	}
+
+	owned, err := FindSecretsOwnedByObj(ctx, client, obj)
+	if err != nil {
+		return "", err
+	}
+	var generation int64
+	for _, s := range owned {
+		if s.Name != key.Name && s.Annotations[annotationImmutableDestination] == d.Name {
+			generation = max(generation, immutableSecretGeneration(&s))
+		}
+	}
+	generation++
+
+	cur, exists, err := getSecretExists(ctx, client, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		// the Secret's data cannot have changed, since it is immutable.
+		if err := checkSecretIsOwnedByObj(cur, references); err != nil {
+			return "", err
+		}
+		// a previous immutable Secret is current again, e.g. after the data was
+		// reverted, only its metadata can be updated.
+		if immutableSecretGeneration(cur) < generation {
+			if cur.Annotations == nil {
+				cur.Annotations = make(map[string]string)
+			}
+			cur.Annotations[annotationImmutableGeneration] = strconv.FormatInt(generation, 10)
+			if err := client.Update(ctx, cur); err != nil {
+				return "", err
+			}
+		}
+		return key.Name, nil
+	}
+
+	annotations = maps.Clone(annotations)
+	annotations[annotationImmutableDestination] = d.Name
+	annotations[annotationImmutableGeneration] = strconv.FormatInt(generation, 10)
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            key.Name,
+			Namespace:       key.Namespace,
+			Labels:          labels,
+			Annotations:     annotations,
+			OwnerReferences: references,
+		},
+		Immutable: ptr.To(true),
+		Type:      secretType,
+		Data:      data,
+	}
+
+	log.FromContext(ctx).WithName("syncSecret").V(consts.LogLevelDebug).Info(
+		"Creating immutable secret", "secret", key)
+	if err := client.Create(ctx, s); err != nil {
+		return "", err
+	}
+	recordSyncedSecretVersion(s)
+
+	return key.Name, nil
+}
+
+// immutableSecretName returns the name of the immutable Secret that holds data
+// for the destination Secret name.
+func immutableSecretName(name string, data map[string][]byte) string {
+	return fmt.Sprintf("%s-%s", name, contentSHA256(data)[:10])
+}
+
+// immutableSecretGeneration returns the generation of the immutable Secret s,
+// it is 0 if s has none.
+func immutableSecretGeneration(s *corev1.Secret) int64 {
+	generation, err := strconv.ParseInt(s.Annotations[annotationImmutableGeneration], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return generation
+}
+
+// pruneImmutableSecrets deletes the previous immutable Secrets of the
+// destination d that exceed its ImmutableHistoryLimit, the oldest are deleted
+// first. They are ordered by their generation, then by their creation
+// timestamp and name. The current immutable Secret is never deleted.
+func pruneImmutableSecrets(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object,
+	d *secretsv1beta1.Destination, current string,
+) error {
+	limit := ptr.Deref(d.ImmutableHistoryLimit, defaultImmutableHistoryLimit)
+	owned, err := FindSecretsOwnedByObj(ctx, client, obj)
+	if err != nil {
+		return err
+	}
+
+	var previous []corev1.Secret
+	for _, s := range owned {
+		if s.Name != current && s.Annotations[annotationImmutableDestination] == d.Name {
+			previous = append(previous, s)
+		}
+	}
+	if len(previous) <= limit {
+		return nil
+	}
+
+	// newest first
+	slices.SortFunc(previous, func(a, b corev1.Secret) int {
+		return cmp.Or(
+			cmp.Compare(immutableSecretGeneration(&b), immutableSecretGeneration(&a)),
+			b.CreationTimestamp.Compare(a.CreationTimestamp.Time),
+			strings.Compare(b.Name, a.Name),
+		)
+	})
+
+	var errs error
+	for _, s := range previous[limit:] {
+		if err := client.Delete(ctx, &s); ctrlclient.IgnoreNotFound(err) != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// recordAdoptedMetadata records the label and annotation keys of s before it
+// is adopted, see adoptedMetadata.
+func recordAdoptedMetadata(s *corev1.Secret) {
+	labelKeys := slices.Sorted(maps.Keys(s.Labels))
+	annotationKeys := slices.Sorted(maps.Keys(s.Annotations))
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string)
+	}
+	s.Annotations[annotationAdoptedLabels] = strings.Join(labelKeys, ",")
+	s.Annotations[annotationAdoptedAnnotations] = strings.Join(annotationKeys, ",")
+}
+
+// adoptedMetadata returns the labels and annotations of the adopted Secret s
+// that it had before its adoption, along with the annotations that record
+// them. Both are empty if s was not adopted.
+func adoptedMetadata(s *corev1.Secret) (map[string]string, map[string]string) {
+	labels := make(map[string]string)
+	annotations := make(map[string]string)
+	for _, k := range []string{annotationAdoptedLabels, annotationAdoptedAnnotations} {
+		if v, ok := s.Annotations[k]; ok {
+			annotations[k] = v
+		}
+	}
+
+	for _, k := range strings.Split(s.Annotations[annotationAdoptedLabels], ",") {
+		if v, ok := s.Labels[k]; ok && k != "" {
+			labels[k] = v
+		}
+	}
+	for _, k := range strings.Split(s.Annotations[annotationAdoptedAnnotations], ",") {
+		if v, ok := s.Annotations[k]; ok && k != "" {
+			annotations[k] = v
+		}
+	}
+
+	return labels, annotations
+}
+
+// destinationSecretName returns the name of the destination Secret that s was
+// synced for, it is the name of s unless s is an immutable, or a chunk Secret.
+func destinationSecretName(s *corev1.Secret) string {
+	if name := s.Annotations[annotationImmutableDestination]; name != "" {
+		return name
+	}
+	if name := s.Annotations[annotationChunkDestination]; name != "" {
+		return name
+	}
+	return s.Name
+}
+
+// FinalizeDestinationSecrets applies the DeletionPolicy of each of obj's
+// destinations to the Secret that was created for it. It must be called from
+// obj's finalizer path, before the finalizer is removed. The Secrets of a
+// Retain policy have their owner labels and obj's owner reference removed, so
+// that they are not garbage collected along with obj. The Secrets of a Delete
+// policy are deleted. The Secrets of destinations without a DeletionPolicy are
+// left to the garbage collector.
+func FinalizeDestinationSecrets(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object) error {
+	meta, err := common.NewSyncableSecretMetaData(obj)
+	if err != nil {
+		return err
+	}
+
+	destinations := append([]secretsv1beta1.Destination{*meta.Destination}, meta.Destinations...)
+	if !slices.ContainsFunc(destinations, func(d secretsv1beta1.Destination) bool {
+		return d.DeletionPolicy != ""
+	}) {
+		return nil
+	}
+
+	owned, err := FindSecretsOwnedByObj(ctx, client, obj)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx).WithName("finalizeDestinationSecrets")
+	var errs error
+	for _, s := range owned {
+		idx := slices.IndexFunc(destinations, func(d secretsv1beta1.Destination) bool {
+			return d.Name == destinationSecretName(&s)
+		})
+		if idx < 0 {
+			continue
+		}
+
+		switch destinations[idx].DeletionPolicy {
+		case DeletionPolicyDelete:
+			logger.V(consts.LogLevelDebug).Info("Deleting secret", "secretName", s.Name)
+			if err := client.Delete(ctx, &s); ctrlclient.IgnoreNotFound(err) != nil {
+				errs = errors.Join(errs, err)
+			}
+		case DeletionPolicyRetain:
+			logger.V(consts.LogLevelDebug).Info("Retaining secret", "secretName", s.Name)
+			patch := ctrlclient.MergeFrom(s.DeepCopy())
+			for k := range OwnerLabels {
+				delete(s.Labels, k)
+			}
+			delete(s.Labels, labelOwnerRefUID)
+			s.OwnerReferences = slices.DeleteFunc(s.OwnerReferences, func(ref metav1.OwnerReference) bool {
+				return ref.UID == obj.GetUID()
+			})
+			if err := client.Patch(ctx, &s, patch); ctrlclient.IgnoreNotFound(err) != nil {
+				errs = errors.Join(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func pruneOrphanSecrets(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object, destinations ...secretsv1beta1.Destination) error {
 	owned, err := FindSecretsOwnedByObj(ctx, client, obj)
 	if err != nil {
 		return err
@@ -320,7 +820,13 @@ func pruneOrphanSecrets(ctx context.Context, client ctrlclient.Client, obj ctrlc
 
 	var errs error
 	for _, s := range owned {
-		if s.Name == dest.Name {
+		if slices.ContainsFunc(destinations, func(d secretsv1beta1.Destination) bool {
+			// the immutable, and chunk Secrets of a destination are pruned once it is
+			// no longer immutable, or chunked.
+			return d.Name == s.Name ||
+				(d.Immutable && d.Name == s.Annotations[annotationImmutableDestination]) ||
+				(d.Chunking && d.Name == s.Annotations[annotationChunkDestination])
+		}) {
 			continue
 		}
 		if err := client.Delete(ctx, &s); err != nil {
@@ -331,6 +837,106 @@ func pruneOrphanSecrets(ctx context.Context, client ctrlclient.Client, obj ctrlc
 	return errs
 }
 
+var _ error = (*SecretTypeDataError)(nil)
+
+// SecretTypeDataError is returned when the data to sync to a destination Secret
+// does not satisfy the constraints of the Secret's type, see ValidateSecretData.
+type SecretTypeDataError struct {
+	// Secret is the name of the destination Secret.
+	Secret string
+	// Type of the destination Secret.
+	Type corev1.SecretType
+	// Err is the error returned by ValidateSecretData.
+	Err error
+}
+
+func (e *SecretTypeDataError) Error() string {
+	return fmt.Sprintf("data for secret %q is not compatible with its type %s: %s",
+		e.Secret, e.Type, e.Err)
+}
+
+func (e *SecretTypeDataError) Unwrap() error {
+	return e.Err
+}
+
+// validateSecretTypeData wraps the ValidateSecretData error in a
+// SecretTypeDataError.
+func validateSecretTypeData(name string, secretType corev1.SecretType, data map[string][]byte) error {
+	if err := ValidateSecretData(secretType, data); err != nil {
+		return &SecretTypeDataError{
+			Secret: name,
+			Type:   secretType,
+			Err:    err,
+		}
+	}
+	return nil
+}
+
+// ValidateSecretData checks that data satisfies the constraints of the
+// Kubernetes Secret type, e.g. that the .dockerconfigjson key of a
+// kubernetes.io/dockerconfigjson Secret holds valid JSON with an auths object,
+// or that the tls.crt and tls.key of a kubernetes.io/tls Secret are a matching
+// PEM encoded certificate and private key. This surfaces transformation
+// template errors before the Secret is written, rather than writing a Secret
+// that its consumers fail to load. Types without any data constraints are
+// always valid.
+func ValidateSecretData(secretType corev1.SecretType, data map[string][]byte) error {
+	var required []string
+	var jsonKey string
+	switch secretType {
+	case corev1.SecretTypeDockerConfigJson:
+		required = []string{corev1.DockerConfigJsonKey}
+		jsonKey = corev1.DockerConfigJsonKey
+	case corev1.SecretTypeDockercfg:
+		required = []string{corev1.DockerConfigKey}
+		jsonKey = corev1.DockerConfigKey
+	case corev1.SecretTypeSSHAuth:
+		required = []string{corev1.SSHAuthPrivateKey}
+	case corev1.SecretTypeTLS:
+		required = []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}
+	case corev1.SecretTypeBasicAuth:
+		_, hasUsername := data[corev1.BasicAuthUsernameKey]
+		_, hasPassword := data[corev1.BasicAuthPasswordKey]
+		if !hasUsername && !hasPassword {
+			return fmt.Errorf("secret type %s requires one of the keys %q or %q",
+				secretType, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+		}
+	}
+
+	var errs error
+	for _, k := range required {
+		if _, ok := data[k]; !ok {
+			errs = errors.Join(errs, fmt.Errorf("secret type %s requires the key %q", secretType, k))
+		}
+	}
+	if errs != nil {
+		return errs
+	}
+
+	if jsonKey != "" {
+		var m map[string]any
+		if err := json.Unmarshal(data[jsonKey], &m); err != nil {
+			return fmt.Errorf("secret type %s requires valid JSON for the key %q: %w",
+				secretType, jsonKey, err)
+		}
+		if secretType == corev1.SecretTypeDockerConfigJson {
+			if _, ok := m["auths"].(map[string]any); !ok {
+				return fmt.Errorf("secret type %s requires an \"auths\" object in the JSON of the key %q",
+					secretType, jsonKey)
+			}
+		}
+	}
+
+	if secretType == corev1.SecretTypeTLS {
+		if _, err := tls.X509KeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey]); err != nil {
+			return fmt.Errorf("secret type %s requires a PEM encoded certificate and its private key "+
+				"for the keys %q and %q: %w", secretType, corev1.TLSCertKey, corev1.TLSPrivateKeyKey, err)
+		}
+	}
+
+	return nil
+}
+
 // CheckSecretExists checks if the Secret configured on obj exists.
 // Returns true if the secret exists, false if the secret was not found.
 // If any error, other than apierrors.IsNotFound, is encountered,
@@ -362,6 +968,29 @@ func getSecretExistsForObj(ctx context.Context, client ctrlclient.Client, obj ct
 		return nil, false, err
 	}
 
+	// the data of an immutable destination is held by the immutable Secret that
+	// the destination Secret points to.
+	if exists && meta.Destination.Create && meta.Destination.Immutable {
+		if name := s.Annotations[AnnotationImmutableSecret]; name != "" {
+			objKey.Name = name
+			s, exists, err = getSecretExists(ctx, client, objKey)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	// the data of a chunked destination is held by the chunk Secrets that the
+	// destination Secret lists.
+	if exists && meta.Destination.Create && meta.Destination.Chunking {
+		if names := s.Annotations[AnnotationSecretChunks]; names != "" {
+			s.Data, err = joinSecretChunks(ctx, client, obj.GetNamespace(), strings.Split(names, ","))
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
 	logger.V(consts.LogLevelDebug).Info("Secret exists")
 	return s, exists, nil
 }
@@ -386,6 +1015,28 @@ func getSecretExists(ctx context.Context, client ctrlclient.Client, objKey ctrlc
 	return s, exists, err
 }
 
+// syncedSecretVersions holds the resourceVersion of each Secret as of its last
+// write by SyncSecret. It is used to tell the operator's own writes apart from
+// out-of-band changes to the Secret.
+var syncedSecretVersions sync.Map
+
+func recordSyncedSecretVersion(s *corev1.Secret) {
+	syncedSecretVersions.Store(ctrlclient.ObjectKeyFromObject(s), s.GetResourceVersion())
+}
+
+// IsSyncedSecretVersion returns true if o is the version of the Secret that was
+// last written by SyncSecret.
+func IsSyncedSecretVersion(o ctrlclient.Object) bool {
+	v, ok := syncedSecretVersions.Load(ctrlclient.ObjectKeyFromObject(o))
+	return ok && v == o.GetResourceVersion()
+}
+
+// ForgetSyncedSecretVersion removes the last written version of the Secret for
+// objKey. Should be called when the Secret is deleted.
+func ForgetSyncedSecretVersion(objKey ctrlclient.ObjectKey) {
+	syncedSecretVersions.Delete(objKey)
+}
+
 // HasOwnerLabels returns true if all owner labels are present and valid, if not
 // it returns false.
 // Note: this may cause issues if we ever add new "owner"
@@ -504,12 +1155,12 @@ func (s *SecretDataBuilder) WithVaultData(d, secretData map[string]any, opt *Sec
 
 		input := NewSecretInput(d, metadata, opt.Annotations, opt.Labels)
 		data, err = renderTemplates(opt, input)
-		if err != nil {
+		if err != nil && !isTemplateRenderError(err) {
 			return nil, err
 		}
 	}
 
-	return makeK8sData(d, data, raw, opt)
+	return makeK8sDataWithRenderError(d, data, raw, opt, err)
 }
 
 func marshalJSON(value any) ([]byte, error) {
@@ -527,6 +1178,73 @@ func marshalJSON(value any) ([]byte, error) {
 	return b, nil
 }
 
+const (
+	// KeyEncodingBase64Decode decodes a base64 encoded string value.
+	KeyEncodingBase64Decode = "base64decode"
+	// KeyEncodingJSON always JSON encodes a value.
+	KeyEncodingJSON = "json"
+	// KeyEncodingIntString stores a numeric value as an integer.
+	KeyEncodingIntString = "int-string"
+)
+
+var supportedKeyEncodings = []string{
+	KeyEncodingBase64Decode,
+	KeyEncodingJSON,
+	KeyEncodingIntString,
+}
+
+// encodeValue returns the K8s Secret data bytes of the value of key, encoded
+// with encoding. The value is marshaled with marshalJSON if encoding is empty.
+func encodeValue(key string, value any, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return marshalJSON(value)
+	case KeyEncodingJSON:
+		return json.Marshal(value)
+	case KeyEncodingBase64Decode:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of key %q is not a base64 encoded string", key)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to base64 decode the value of key %q: %w", key, err)
+		}
+		return b, nil
+	case KeyEncodingIntString:
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		case int:
+			return []byte(strconv.Itoa(v)), nil
+		case int64:
+			return []byte(strconv.FormatInt(v, 10)), nil
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				return []byte(strconv.FormatInt(i, 10)), nil
+			}
+			var err error
+			if f, err = v.Float64(); err != nil {
+				return nil, fmt.Errorf("value of key %q is not a number", key)
+			}
+		case string:
+			var err error
+			if f, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("value of key %q is not a number", key)
+			}
+		default:
+			return nil, fmt.Errorf("value of key %q is not a number", key)
+		}
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("value of key %q is not an integer", key)
+		}
+		return []byte(strconv.FormatFloat(f, 'f', -1, 64)), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q for key %q", encoding, key)
+	}
+}
+
 // WithHVSAppSecrets returns the K8s Secret data from HCP Vault Secrets App.
 func (s *SecretDataBuilder) WithHVSAppSecrets(resp *hvsclient.OpenAppSecretsOK, opt *SecretTransformationOption) (map[string][]byte, error) {
 	if opt == nil {
@@ -604,12 +1322,12 @@ func (s *SecretDataBuilder) WithHVSAppSecrets(resp *hvsclient.OpenAppSecretsOK,
 
 	if hasTemplates {
 		data, err = renderTemplates(opt, NewSecretInput(secrets, metadata, opt.Annotations, opt.Labels))
-		if err != nil {
+		if err != nil && !isTemplateRenderError(err) {
 			return nil, err
 		}
 	}
 
-	return makeK8sData(secrets, data, raw, opt)
+	return makeK8sDataWithRenderError(secrets, data, raw, opt, err)
 }
 
 func (s *SecretDataBuilder) makeHVSMetadata(v *models.Secrets20231128OpenSecret) (map[string]any, error) {
@@ -682,10 +1400,15 @@ func makeK8sData[V any](secretData map[string]V, extraData map[string][]byte,
 		return nil, err
 	}
 
+	filtered, err = renameKeys(opt, filtered)
+	if err != nil {
+		return nil, err
+	}
+
 	// include the filtered fields that are not already in data
 	for k, v := range filtered {
 		if _, ok := data[k]; !ok {
-			bv, err := marshalJSON(v)
+			bv, err := encodeValue(k, v, opt.Encodings[k])
 			if err != nil {
 				return nil, err
 			}
@@ -696,6 +1419,64 @@ func makeK8sData[V any](secretData map[string]V, extraData map[string][]byte,
 	return data, nil
 }
 
+// renameKeys returns data with its keys renamed by the SecretTransformationOption's
+// KeyMap. A renamed key takes precedence over a key of the same name that is not
+// renamed. Returns a SecretDataErrorContainsRaw error if a key is renamed to
+// SecretDataKeyRaw, while it is not excluded.
+func renameKeys[V any](opt *SecretTransformationOption, data map[string]V) (map[string]V, error) {
+	if len(opt.KeyMap) == 0 {
+		return data, nil
+	}
+
+	renamed := make(map[string]V, len(data))
+	for k, v := range data {
+		if to, ok := opt.KeyMap[k]; ok {
+			if to == SecretDataKeyRaw && !opt.ExcludeRaw {
+				return nil, SecretDataErrorContainsRaw
+			}
+			renamed[to] = v
+		}
+	}
+	for k, v := range data {
+		if _, ok := opt.KeyMap[k]; ok {
+			continue
+		}
+		if _, ok := renamed[k]; !ok {
+			renamed[k] = v
+		}
+	}
+
+	return renamed, nil
+}
+
+// makeK8sDataWithRenderError wraps makeK8sData, returning the resulting data
+// along with renderErr, when renderErr is a TemplateRenderError. The keys that
+// failed to render are never set from the secret data.
+func makeK8sDataWithRenderError[V any](secretData map[string]V, extraData map[string][]byte,
+	raw []byte, opt *SecretTransformationOption, renderErr error,
+) (map[string][]byte, error) {
+	data, err := makeK8sData(secretData, extraData, raw, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var tmplErr *TemplateRenderError
+	if !errors.As(renderErr, &tmplErr) {
+		return data, nil
+	}
+
+	for k := range tmplErr.Errs {
+		delete(data, k)
+	}
+
+	return data, tmplErr
+}
+
+func isTemplateRenderError(err error) bool {
+	var tmplErr *TemplateRenderError
+	return errors.As(err, &tmplErr)
+}
+
 func NewSecretsDataBuilder() *SecretDataBuilder {
 	return &SecretDataBuilder{}
 }
@@ -728,6 +1509,41 @@ func FromHVSShadowSecret(data []byte) (*models.Secrets20231128OpenSecret, error)
 	return secret, nil
 }
 
+// contentMACFunc returns the content MAC of data.
+type contentMACFunc func(data map[string][]byte) (string, error)
+
+// contentMessage returns the message that is digested for the content of data.
+// The keys are sorted, and each key and value is length prefixed, so that the
+// message is stable and unambiguous.
+func contentMessage(data map[string][]byte) []byte {
+	var b bytes.Buffer
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		v := data[k]
+		_ = binary.Write(&b, binary.BigEndian, uint64(len(k)))
+		b.WriteString(k)
+		_ = binary.Write(&b, binary.BigEndian, uint64(len(v)))
+		b.Write(v)
+	}
+	return b.Bytes()
+}
+
+// contentSHA256 returns the hex encoded sha256 digest of data's content
+// message.
+func contentSHA256(data map[string][]byte) string {
+	sum := sha256.Sum256(contentMessage(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentMAC returns the hex encoded HMAC-SHA256 of data's content message,
+// computed by v with the operator's HMAC key.
+func contentMAC(ctx context.Context, client ctrlclient.Client, v HMACValidator, data map[string][]byte) (string, error) {
+	mac, err := v.HMAC(ctx, client, contentMessage(data))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(mac), nil
+}
+
 // HashString returns the first eight + last four characters of the sha256 sum
 // of the input string.
 func HashString(s string) string {
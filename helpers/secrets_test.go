@@ -5,9 +5,18 @@ package helpers
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"maps"
+	"math/big"
 	"testing"
 	"time"
 
@@ -21,13 +30,37 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/internal/testutils"
+	"github.com/hashicorp/vault-secrets-operator/utils"
 )
 
+// testTLSKeyPair returns a PEM encoded self-signed certificate and its private
+// key.
+func testTLSKeyPair(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
 func TestFindSecretsOwnedByObj(t *testing.T) {
 	t.Parallel()
 
@@ -232,6 +265,10 @@ func TestSyncSecret(t *testing.T) {
 	ownerWithDest.DeepCopyInto(ownerWithDestOverwrite)
 	ownerWithDestOverwrite.Spec.Destination.Overwrite = true
 
+	ownerWithDestAdopt := &secretsv1beta1.VaultDynamicSecret{}
+	ownerWithDest.DeepCopyInto(ownerWithDestAdopt)
+	ownerWithDestAdopt.Spec.Destination.Adopt = true
+
 	invalidNoDest := &secretsv1beta1.VaultDynamicSecret{
 		TypeMeta:   defaultOwner.TypeMeta,
 		ObjectMeta: defaultOwner.ObjectMeta,
@@ -329,6 +366,7 @@ func TestSyncSecret(t *testing.T) {
 				},
 			},
 			obj:        ownerWithCreateAndType,
+			data:       map[string][]byte{corev1.DockerConfigKey: []byte(`{}`)},
 			destLabels: maps.Clone(OwnerLabels),
 			destOwnerReferences: []metav1.OwnerReference{
 				{
@@ -342,6 +380,17 @@ func TestSyncSecret(t *testing.T) {
 			expectSecretsCount: 1,
 			wantErr:            assert.NoError,
 		},
+		{
+			name:               "invalid-data-for-type",
+			client:             clientBuilder.Build(),
+			obj:                ownerWithCreateAndType,
+			data:               map[string][]byte{corev1.DockerConfigKey: []byte(`{`)},
+			expectSecretsCount: 0,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err,
+					`secret type kubernetes.io/dockercfg requires valid JSON for the key ".dockercfg"`)
+			},
+		},
 		{
 			name:    "valid-dest-prune-orphans",
 			client:  clientBuilder.Build(),
@@ -450,6 +499,32 @@ func TestSyncSecret(t *testing.T) {
 			expectSecretsCount: 1,
 			wantErr:            assert.NoError,
 		},
+		{
+			name:       "dest-exists-not-owned-adopt-true",
+			client:     clientBuilder.Build(),
+			obj:        ownerWithDestAdopt,
+			createDest: true,
+			destLabels: map[string]string{
+				"app.kubernetes.io/managed-by": "Helm",
+			},
+			data: map[string][]byte{
+				"foo": []byte(`bar`),
+			},
+			expectSecretsCount: 1,
+			wantErr:            assert.NoError,
+		},
+		{
+			name:               "dest-exists-owned-adopt-true",
+			client:             clientBuilder.Build(),
+			obj:                ownerWithDestAdopt,
+			createDest:         true,
+			destLabels:         OwnerLabels,
+			expectSecretsCount: 1,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err,
+					"not the owner of the destination Secret foo/baz")
+			},
+		},
 		{
 			name:               "dest-exists-owned-overwrite-true",
 			client:             clientBuilder.Build(),
@@ -543,6 +618,8 @@ func TestSyncSecret(t *testing.T) {
 					wantType = corev1.SecretTypeOpaque
 				}
 				assert.Equal(t, wantType, destSecret.Type)
+				// the content MAC is opt-in.
+				assert.NotContains(t, destSecret.Annotations, AnnotationContentMAC)
 			}
 
 			for _, objKey := range orphans {
@@ -566,6 +643,753 @@ func TestSyncSecret(t *testing.T) {
 	}
 }
 
+func TestSyncSecret_destinations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultPKISecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultPKISecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultPKISecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:   "tls",
+				Create: true,
+				Type:   corev1.SecretTypeTLS,
+			},
+			Destinations: []secretsv1beta1.Destination{
+				{
+					Name:   "keystore",
+					Create: true,
+				},
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	cert, key := testTLSKeyPair(t)
+	tlsData := map[string][]byte{
+		corev1.TLSCertKey:       cert,
+		corev1.TLSPrivateKeyKey: key,
+	}
+	keystoreData := map[string][]byte{
+		"keystore.jks": []byte("jks"),
+	}
+
+	require.NoError(t, SyncSecret(ctx, c, o, tlsData))
+	require.NoError(t, SyncSecret(ctx, c, o, keystoreData, SyncOptions{
+		PruneOrphans: true,
+		Destination:  &o.Spec.Destinations[0],
+	}))
+
+	var tlsSecret, keystoreSecret corev1.Secret
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "foo", Name: "tls"}, &tlsSecret))
+	assert.Equal(t, corev1.SecretTypeTLS, tlsSecret.Type)
+	assert.Equal(t, tlsData, tlsSecret.Data)
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "foo", Name: "keystore"}, &keystoreSecret))
+	assert.Equal(t, corev1.SecretTypeOpaque, keystoreSecret.Type)
+	assert.Equal(t, keystoreData, keystoreSecret.Data)
+
+	// syncing the primary destination does not prune the additional destinations.
+	require.NoError(t, SyncSecret(ctx, c, o, tlsData))
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "foo", Name: "keystore"}, &keystoreSecret))
+
+	// removed destinations are pruned.
+	o.Spec.Destinations = nil
+	require.NoError(t, SyncSecret(ctx, c, o, tlsData))
+	assert.True(t, apierrors.IsNotFound(
+		c.Get(ctx, ctrlclient.ObjectKey{Namespace: "foo", Name: "keystore"}, &keystoreSecret)))
+}
+
+func TestSyncSecret_adopt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:   "dest",
+				Create: true,
+				Adopt:  true,
+				Labels: map[string]string{
+					"team": "qux",
+				},
+				Annotations: map[string]string{
+					"note": "synced",
+				},
+			},
+		},
+	}
+
+	objKey := ctrlclient.ObjectKey{Namespace: o.Namespace, Name: o.Spec.Destination.Name}
+	c := testutils.NewFakeClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: objKey.Namespace,
+			Name:      objKey.Name,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "Helm",
+			},
+			Annotations: map[string]string{
+				"meta.helm.sh/release-name": "app",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "external-secrets.io/v1beta1",
+					Kind:       "ExternalSecret",
+					Name:       "dest",
+					UID:        types.UID("other"),
+				},
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"foo": []byte("old"),
+		},
+	}).Build()
+
+	data := map[string][]byte{"foo": []byte("bar")}
+	require.NoError(t, SyncSecret(ctx, c, o, data))
+
+	var s corev1.Secret
+	require.NoError(t, c.Get(ctx, objKey, &s))
+	assert.Equal(t, data, s.Data)
+	assert.True(t, HasOwnerLabels(&s))
+	// the owner labels take precedence over the retained labels.
+	assert.Equal(t, OwnerLabels["app.kubernetes.io/managed-by"], s.Labels["app.kubernetes.io/managed-by"])
+	assert.Equal(t, "qux", s.Labels["team"])
+	assert.Equal(t, map[string]string{
+		"meta.helm.sh/release-name":  "app",
+		"note":                       "synced",
+		annotationAdoptedLabels:      "app.kubernetes.io/managed-by",
+		annotationAdoptedAnnotations: "meta.helm.sh/release-name",
+	}, s.Annotations)
+	// the owner references of other tools are removed.
+	require.Len(t, s.OwnerReferences, 1)
+	assert.Equal(t, o.UID, s.OwnerReferences[0].UID)
+
+	// the adopted Secret is owned by o from now on.
+	require.NoError(t, SyncSecret(ctx, c, o, data))
+
+	// the labels and annotations that are removed from the destination are
+	// removed from the adopted Secret, while the ones it had before its adoption
+	// are retained.
+	o.Spec.Destination.Labels = nil
+	o.Spec.Destination.Annotations = map[string]string{
+		"meta.helm.sh/release-name": "other",
+	}
+	require.NoError(t, SyncSecret(ctx, c, o, data))
+	require.NoError(t, c.Get(ctx, objKey, &s))
+	assert.NotContains(t, s.Labels, "team")
+	assert.True(t, HasOwnerLabels(&s))
+	assert.Equal(t, map[string]string{
+		"meta.helm.sh/release-name":  "other",
+		annotationAdoptedLabels:      "app.kubernetes.io/managed-by",
+		annotationAdoptedAnnotations: "meta.helm.sh/release-name",
+	}, s.Annotations)
+
+	// a Secret of a different type cannot be adopted.
+	o.Spec.Destination.Name = "tls"
+	require.NoError(t, c.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.Namespace,
+			Name:      "tls",
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}))
+	assert.ErrorContains(t, SyncSecret(ctx, c, o, data),
+		"cannot adopt the destination secret foo/tls")
+}
+
+func TestSyncSecret_immutable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:                  "dest",
+				Create:                true,
+				Immutable:             true,
+				ImmutableHistoryLimit: ptr.To(1),
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	objKey := ctrlclient.ObjectKey{Namespace: o.Namespace, Name: o.Spec.Destination.Name}
+	assertSynced := func(data map[string][]byte, wantCount int) {
+		t.Helper()
+
+		var dest corev1.Secret
+		require.NoError(t, c.Get(ctx, objKey, &dest))
+		assert.Empty(t, dest.Data)
+		assert.Equal(t, corev1.SecretTypeOpaque, dest.Type)
+		name := immutableSecretName(objKey.Name, data)
+		assert.Equal(t, name, dest.Annotations[AnnotationImmutableSecret])
+
+		s, ok, err := GetSyncableSecret(ctx, c, o)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, name, s.Name)
+		assert.Equal(t, data, s.Data)
+		assert.Equal(t, ptr.To(true), s.Immutable)
+		assert.Equal(t, objKey.Name, destinationSecretName(s))
+
+		secrets := &corev1.SecretList{}
+		require.NoError(t, c.List(ctx, secrets, ctrlclient.InNamespace(o.Namespace)))
+		assert.Len(t, secrets.Items, wantCount)
+	}
+
+	data1 := map[string][]byte{"foo": []byte("bar")}
+	require.NoError(t, SyncSecret(ctx, c, o, data1))
+	assertSynced(data1, 2)
+
+	// the immutable Secret is only replaced when the data changes.
+	require.NoError(t, SyncSecret(ctx, c, o, data1))
+	assertSynced(data1, 2)
+
+	data2 := map[string][]byte{"foo": []byte("baz")}
+	require.NoError(t, SyncSecret(ctx, c, o, data2))
+	assertSynced(data2, 3)
+
+	// the previous immutable Secrets are pruned past the history limit.
+	data3 := map[string][]byte{"foo": []byte("qux")}
+	require.NoError(t, SyncSecret(ctx, c, o, data3))
+	assertSynced(data3, 3)
+
+	// a previous immutable Secret that is current again is the newest one once it
+	// is replaced.
+	require.NoError(t, SyncSecret(ctx, c, o, data2))
+	assertSynced(data2, 3)
+	data4 := map[string][]byte{"foo": []byte("quux")}
+	require.NoError(t, SyncSecret(ctx, c, o, data4))
+	assertSynced(data4, 3)
+	var previous corev1.Secret
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{
+		Namespace: o.Namespace,
+		Name:      immutableSecretName(objKey.Name, data2),
+	}, &previous))
+	assert.Equal(t, "4", previous.Annotations[annotationImmutableGeneration])
+
+	// all immutable Secrets are pruned once the destination is no longer
+	// immutable.
+	o.Spec.Destination.Immutable = false
+	require.NoError(t, SyncSecret(ctx, c, o, data3))
+	var dest corev1.Secret
+	require.NoError(t, c.Get(ctx, objKey, &dest))
+	assert.Equal(t, data3, dest.Data)
+	assert.NotContains(t, dest.Annotations, AnnotationImmutableSecret)
+	secrets := &corev1.SecretList{}
+	require.NoError(t, c.List(ctx, secrets, ctrlclient.InNamespace(o.Namespace)))
+	assert.Len(t, secrets.Items, 1)
+}
+
+func TestSyncSecret_immutableHistoryLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		limit *int
+		// wantCount includes the destination and the current immutable Secret.
+		wantCount int
+	}{
+		{
+			name:      "default",
+			wantCount: 2 + defaultImmutableHistoryLimit,
+		},
+		{
+			name:      "zero",
+			limit:     ptr.To(0),
+			wantCount: 2,
+		},
+		{
+			name:      "two",
+			limit:     ptr.To(2),
+			wantCount: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			o := &secretsv1beta1.VaultStaticSecret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "VaultStaticSecret",
+					APIVersion: "secrets.hashicorp.com/v1beta1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "foo",
+					UID:       types.UID("buzz"),
+				},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Destination: secretsv1beta1.Destination{
+						Name:                  "dest",
+						Create:                true,
+						Immutable:             true,
+						ImmutableHistoryLimit: tt.limit,
+					},
+				},
+			}
+
+			c := testutils.NewFakeClientBuilder().Build()
+			for i := 0; i < 6; i++ {
+				data := map[string][]byte{"foo": []byte(fmt.Sprintf("bar-%d", i))}
+				require.NoError(t, SyncSecret(ctx, c, o, data))
+			}
+
+			secrets := &corev1.SecretList{}
+			require.NoError(t, c.List(ctx, secrets, ctrlclient.InNamespace(o.Namespace)))
+			assert.Len(t, secrets.Items, tt.wantCount)
+
+			s, ok, err := GetSyncableSecret(ctx, c, o)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, map[string][]byte{"foo": []byte("bar-5")}, s.Data)
+		})
+	}
+}
+
+func Test_pruneImmutableSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:                  "dest",
+				Create:                true,
+				Immutable:             true,
+				ImmutableHistoryLimit: ptr.To(2),
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	ownerLabels, err := OwnerLabelsForObj(o)
+	require.NoError(t, err)
+	ownerRef, err := utils.GetOwnerRefFromObj(o, c.Scheme())
+	require.NoError(t, err)
+
+	// all Secrets are created within the same second, and the oldest ones sort
+	// first by name.
+	created := metav1.NewTime(time.Now().Truncate(time.Second))
+	generations := map[string]string{
+		"dest-a": "1",
+		"dest-b": "2",
+		"dest-c": "3",
+		"dest-d": "4",
+		"dest-e": "5",
+	}
+	for name, generation := range generations {
+		require.NoError(t, c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         o.Namespace,
+				CreationTimestamp: created,
+				Labels:            ownerLabels,
+				Annotations: map[string]string{
+					annotationImmutableDestination: o.Spec.Destination.Name,
+					annotationImmutableGeneration:  generation,
+				},
+				OwnerReferences: []metav1.OwnerReference{ownerRef},
+			},
+			Immutable: ptr.To(true),
+		}))
+	}
+
+	require.NoError(t, pruneImmutableSecrets(ctx, c, o, &o.Spec.Destination, "dest-e"))
+
+	secrets := &corev1.SecretList{}
+	require.NoError(t, c.List(ctx, secrets, ctrlclient.InNamespace(o.Namespace)))
+	var got []string
+	for _, s := range secrets.Items {
+		got = append(got, s.Name)
+	}
+	assert.ElementsMatch(t, []string{"dest-c", "dest-d", "dest-e"}, got)
+}
+
+func Test_renderDestinationMetadata(t *testing.T) {
+	t.Parallel()
+
+	obj := &secretsv1beta1.VaultStaticSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			Labels: map[string]string{
+				"team": "qux",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		m        map[string]string
+		metadata map[string]any
+		want     map[string]string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "nil",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "no-templates",
+			m: map[string]string{
+				"foo": "bar",
+			},
+			want: map[string]string{
+				"foo": "bar",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "templates",
+			m: map[string]string{
+				"foo":     "bar",
+				"version": `{{ .Metadata.version }}`,
+				"team":    `{{ get .Labels "team" }}-{{ .Metadata.lease_id | trunc 3 }}`,
+			},
+			metadata: map[string]any{
+				"version":  2,
+				"lease_id": "abcdef",
+			},
+			want: map[string]string{
+				"foo":     "bar",
+				"version": "2",
+				"team":    "qux-abc",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "no-secret-data",
+			m: map[string]string{
+				"foo": `{{ .Secrets }}`,
+			},
+			want: map[string]string{
+				"foo": "map[]",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid-template",
+			m: map[string]string{
+				"foo": `{{ .Metadata.version `,
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderDestinationMetadata(obj, tt.m, tt.metadata)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSyncSecret_metadataTemplates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:   "dest",
+				Create: true,
+				Labels: map[string]string{
+					"version": `{{ .Metadata.version }}`,
+				},
+				Annotations: map[string]string{
+					"created": `{{ .Metadata.created_time }}`,
+				},
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	data := map[string][]byte{"foo": []byte("bar")}
+	opts := DefaultSyncOptions()
+	opts.Metadata = map[string]any{
+		"version":      1,
+		"created_time": "2024-01-01T00:00:00Z",
+	}
+	require.NoError(t, SyncSecret(ctx, c, o, data, opts))
+
+	var s corev1.Secret
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Namespace: "foo", Name: "dest"}, &s))
+	assert.Equal(t, "1", s.Labels["version"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", s.Annotations["created"])
+	// the templates are not rendered in place.
+	assert.Equal(t, `{{ .Metadata.version }}`, o.Spec.Destination.Labels["version"])
+
+	// the rendered label values must be valid.
+	opts.Metadata["version"] = "not a valid label value"
+	assert.ErrorContains(t, SyncSecret(ctx, c, o, data, opts),
+		`invalid value for the destination label "version"`)
+}
+
+func TestSyncSecret_contentMAC(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "foo",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:       "dest",
+				Create:     true,
+				ContentMAC: true,
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	hmacSecret, err := CreateHMACKeySecret(ctx, c, defaultHMACObjKey)
+	require.NoError(t, err)
+	opts := DefaultSyncOptions()
+	opts.HMACValidator = NewHMACValidator(defaultHMACObjKey)
+
+	objKey := ctrlclient.ObjectKey{Namespace: o.Namespace, Name: o.Spec.Destination.Name}
+	getAnnotations := func() map[string]string {
+		t.Helper()
+
+		var dest corev1.Secret
+		require.NoError(t, c.Get(ctx, objKey, &dest))
+		return dest.Annotations
+	}
+	wantMAC := func(data map[string][]byte) string {
+		t.Helper()
+
+		mac, err := MACMessage(hmacSecret.Data[HMACKeyName], contentMessage(data))
+		require.NoError(t, err)
+		return hex.EncodeToString(mac)
+	}
+
+	data := map[string][]byte{"foo": []byte("bar")}
+	require.NoError(t, SyncSecret(ctx, c, o, data, opts))
+	assert.Equal(t, wantMAC(data), getAnnotations()[AnnotationContentMAC])
+	// the MAC is keyed, it is not a plain digest of the data.
+	assert.NotEqual(t, contentSHA256(data), getAnnotations()[AnnotationContentMAC])
+
+	data = map[string][]byte{"foo": []byte("qux")}
+	require.NoError(t, SyncSecret(ctx, c, o, data, opts))
+	assert.Equal(t, wantMAC(data), getAnnotations()[AnnotationContentMAC])
+
+	assert.ErrorContains(t, SyncSecret(ctx, c, o, data),
+		"the content MAC requires an HMACValidator")
+
+	// the annotation is removed once the destination opts out.
+	o.Spec.Destination.ContentMAC = false
+	require.NoError(t, SyncSecret(ctx, c, o, data, opts))
+	assert.NotContains(t, getAnnotations(), AnnotationContentMAC)
+}
+
+func Test_contentSHA256(t *testing.T) {
+	t.Parallel()
+
+	data := map[string][]byte{
+		"foo": []byte("bar"),
+		"baz": []byte("qux"),
+	}
+	want := contentSHA256(data)
+	assert.Len(t, want, 64)
+
+	// the digest is stable.
+	assert.Equal(t, want, contentSHA256(map[string][]byte{
+		"baz": []byte("qux"),
+		"foo": []byte("bar"),
+	}))
+
+	// and changes along with the content.
+	assert.NotEqual(t, want, contentSHA256(map[string][]byte{
+		"foo": []byte("bar"),
+		"baz": []byte("quux"),
+	}))
+	assert.NotEqual(t, contentSHA256(map[string][]byte{"ab": []byte("c")}),
+		contentSHA256(map[string][]byte{"a": []byte("bc")}))
+	assert.Equal(t, contentSHA256(nil), contentSHA256(map[string][]byte{}))
+}
+
+func TestFinalizeDestinationSecrets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		deletionPolicy string
+		wantExists     bool
+		wantReleased   bool
+	}{
+		{
+			name:       "unset",
+			wantExists: true,
+		},
+		{
+			name:           "retain",
+			deletionPolicy: DeletionPolicyRetain,
+			wantExists:     true,
+			wantReleased:   true,
+		},
+		{
+			name:           "delete",
+			deletionPolicy: DeletionPolicyDelete,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			o := &secretsv1beta1.VaultStaticSecret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "VaultStaticSecret",
+					APIVersion: "secrets.hashicorp.com/v1beta1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "foo",
+					UID:       types.UID("buzz"),
+				},
+				Spec: secretsv1beta1.VaultStaticSecretSpec{
+					Destination: secretsv1beta1.Destination{
+						Name:           "dest",
+						Create:         true,
+						DeletionPolicy: tt.deletionPolicy,
+						Labels: map[string]string{
+							"team": "qux",
+						},
+					},
+				},
+			}
+
+			c := testutils.NewFakeClientBuilder().Build()
+			require.NoError(t, SyncSecret(ctx, c, o, map[string][]byte{"foo": []byte("bar")}))
+			require.NoError(t, FinalizeDestinationSecrets(ctx, c, o))
+
+			var s corev1.Secret
+			err := c.Get(ctx, ctrlclient.ObjectKey{Namespace: o.Namespace, Name: "dest"}, &s)
+			if !tt.wantExists {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantReleased {
+				assert.False(t, HasOwnerLabels(&s))
+				assert.NotContains(t, s.Labels, labelOwnerRefUID)
+				assert.Equal(t, "qux", s.Labels["team"])
+				assert.Empty(t, s.OwnerReferences)
+			} else {
+				assert.True(t, HasOwnerLabels(&s))
+				assert.Len(t, s.OwnerReferences, 1)
+			}
+		})
+	}
+}
+
+func TestSyncSecret_syncedVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	o := &secretsv1beta1.VaultStaticSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VaultStaticSecret",
+			APIVersion: "secrets.hashicorp.com/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "synced-version",
+			UID:       types.UID("buzz"),
+		},
+		Spec: secretsv1beta1.VaultStaticSecretSpec{
+			Destination: secretsv1beta1.Destination{
+				Name:   "dest",
+				Create: true,
+			},
+		},
+	}
+
+	c := testutils.NewFakeClientBuilder().Build()
+	objKey := ctrlclient.ObjectKey{Namespace: o.Namespace, Name: o.Spec.Destination.Name}
+	require.NoError(t, SyncSecret(ctx, c, o, map[string][]byte{"foo": []byte("bar")}))
+
+	var s corev1.Secret
+	require.NoError(t, c.Get(ctx, objKey, &s))
+	assert.True(t, IsSyncedSecretVersion(&s))
+
+	// an out-of-band change is not a synced version.
+	s.Data["foo"] = []byte("qux")
+	require.NoError(t, c.Update(ctx, &s))
+	assert.False(t, IsSyncedSecretVersion(&s))
+
+	require.NoError(t, SyncSecret(ctx, c, o, map[string][]byte{"foo": []byte("bar")}))
+	require.NoError(t, c.Get(ctx, objKey, &s))
+	assert.True(t, IsSyncedSecretVersion(&s))
+
+	ForgetSyncedSecretVersion(objKey)
+	assert.False(t, IsSyncedSecretVersion(&s))
+}
+
 func TestSecretDataBuilder_WithVaultData(t *testing.T) {
 	t.Parallel()
 
@@ -619,6 +1443,113 @@ func TestSecretDataBuilder_WithVaultData(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "key-map",
+			data: map[string]interface{}{
+				"username": "admin",
+				"password": "secret",
+				"DB_USER":  "other",
+				"ttl":      30,
+			},
+			opt: &SecretTransformationOption{
+				ExcludeRaw: true,
+				Excludes:   []string{"^ttl$"},
+				KeyMap: map[string]string{
+					"username": "DB_USER",
+					"password": "DB_PASSWORD",
+					"ttl":      "TTL",
+					"missing":  "MISSING",
+				},
+			},
+			want: map[string][]byte{
+				"DB_USER":     []byte(`admin`),
+				"DB_PASSWORD": []byte(`secret`),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "key-map-template-precedence",
+			data: map[string]interface{}{
+				"username": "admin",
+			},
+			opt: &SecretTransformationOption{
+				ExcludeRaw: true,
+				KeyMap: map[string]string{
+					"username": "DB_USER",
+				},
+				KeyedTemplates: []*KeyedTemplate{
+					{
+						Key: "DB_USER",
+						Template: secretsv1beta1.Template{
+							Name: "user",
+							Text: `{{ get .Secrets "username" | upper }}`,
+						},
+					},
+				},
+			},
+			want: map[string][]byte{
+				"DB_USER": []byte(`ADMIN`),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "encodings",
+			data: map[string]interface{}{
+				"cert":  "AAEC",
+				"name":  "foo",
+				"count": float64(1e21),
+				"port":  float64(8200),
+			},
+			opt: &SecretTransformationOption{
+				ExcludeRaw: true,
+				KeyMap: map[string]string{
+					"count": "COUNT",
+				},
+				Encodings: map[string]string{
+					"cert":  KeyEncodingBase64Decode,
+					"name":  KeyEncodingJSON,
+					"COUNT": KeyEncodingIntString,
+				},
+			},
+			want: map[string][]byte{
+				"cert":  {0, 1, 2},
+				"name":  []byte(`"foo"`),
+				"COUNT": []byte(`1000000000000000000000`),
+				"port":  []byte(`8200`),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid-encoding-base64decode",
+			data: map[string]interface{}{
+				"cert": "not base64",
+			},
+			opt: &SecretTransformationOption{
+				Encodings: map[string]string{
+					"cert": KeyEncodingBase64Decode,
+				},
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`failed to base64 decode the value of key "cert": illegal base64 data at input byte 3`, i...)
+			},
+		},
+		{
+			name: "invalid-key-map-raw",
+			data: map[string]interface{}{
+				"username": "admin",
+			},
+			opt: &SecretTransformationOption{
+				KeyMap: map[string]string{
+					"username": SecretDataKeyRaw,
+				},
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, SecretDataErrorContainsRaw, i...)
+			},
+		},
 		{
 			name:    "nil-data-nil-raw",
 			data:    nil,
@@ -1256,6 +2187,48 @@ META_QUX=biff
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "isolated-template-errors",
+			opt: &SecretTransformationOption{
+				ExcludeRaw:            true,
+				IsolateTemplateErrors: true,
+				KeyedTemplates: []*KeyedTemplate{
+					{
+						Key: "good",
+						Template: secretsv1beta1.Template{
+							Name: "good",
+							Text: `{{- get .Secrets "baz" -}}`,
+						},
+					},
+					{
+						Key: "fab",
+						Template: secretsv1beta1.Template{
+							Name: "fab",
+							Text: `{{- template "missing" . -}}`,
+						},
+					},
+				},
+			},
+			data: map[string]interface{}{
+				"baz": "qux",
+				"fab": "biff",
+			},
+			raw: map[string]interface{}{
+				"baz": "qux",
+				"fab": "biff",
+			},
+			want: map[string][]byte{
+				"baz":  []byte("qux"),
+				"good": []byte("qux"),
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				var renderErr *TemplateRenderError
+				if !assert.ErrorAs(t, err, &renderErr, i...) {
+					return false
+				}
+				return assert.Equal(t, []string{"fab"}, renderErr.Keys(), i...)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1675,6 +2648,120 @@ func TestSecretDataBuilder_WithHVSAppSecrets(t *testing.T) {
 	}
 }
 
+func TestValidateSecretData(t *testing.T) {
+	t.Parallel()
+
+	cert, key := testTLSKeyPair(t)
+	_, otherKey := testTLSKeyPair(t)
+
+	tests := []struct {
+		name       string
+		secretType corev1.SecretType
+		data       map[string][]byte
+		wantErr    string
+	}{
+		{
+			name:       "opaque",
+			secretType: corev1.SecretTypeOpaque,
+		},
+		{
+			name:       "dockerconfigjson",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`),
+			},
+		},
+		{
+			name:       "dockerconfigjson-missing-key",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data: map[string][]byte{
+				"foo": []byte(`{"auths":{}}`),
+			},
+			wantErr: `secret type kubernetes.io/dockerconfigjson requires the key ".dockerconfigjson"`,
+		},
+		{
+			name:       "dockerconfigjson-invalid-json",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`[]`),
+			},
+			wantErr: `secret type kubernetes.io/dockerconfigjson requires valid JSON for the key ".dockerconfigjson": ` +
+				`json: cannot unmarshal array into Go value of type map[string]interface {}`,
+		},
+		{
+			name:       "dockerconfigjson-missing-auths",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"registry.example.com":{"auth":"Zm9vOmJhcg=="}}`),
+			},
+			wantErr: `secret type kubernetes.io/dockerconfigjson requires an "auths" object in the JSON of the key ".dockerconfigjson"`,
+		},
+		{
+			name:       "tls",
+			secretType: corev1.SecretTypeTLS,
+			data: map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: key,
+			},
+		},
+		{
+			name:       "tls-not-pem",
+			secretType: corev1.SecretTypeTLS,
+			data: map[string][]byte{
+				corev1.TLSCertKey:       []byte(`cert`),
+				corev1.TLSPrivateKeyKey: []byte(`key`),
+			},
+			wantErr: `secret type kubernetes.io/tls requires a PEM encoded certificate and its private key ` +
+				`for the keys "tls.crt" and "tls.key": tls: failed to find any PEM data in certificate input`,
+		},
+		{
+			name:       "tls-mismatched-key",
+			secretType: corev1.SecretTypeTLS,
+			data: map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: otherKey,
+			},
+			wantErr: `secret type kubernetes.io/tls requires a PEM encoded certificate and its private key ` +
+				`for the keys "tls.crt" and "tls.key": tls: private key does not match public key`,
+		},
+		{
+			name:       "tls-missing-keys",
+			secretType: corev1.SecretTypeTLS,
+			wantErr: `secret type kubernetes.io/tls requires the key "tls.crt"` + "\n" +
+				`secret type kubernetes.io/tls requires the key "tls.key"`,
+		},
+		{
+			name:       "ssh-auth-missing-key",
+			secretType: corev1.SecretTypeSSHAuth,
+			wantErr:    `secret type kubernetes.io/ssh-auth requires the key "ssh-privatekey"`,
+		},
+		{
+			name:       "basic-auth",
+			secretType: corev1.SecretTypeBasicAuth,
+			data: map[string][]byte{
+				corev1.BasicAuthPasswordKey: []byte(`bar`),
+			},
+		},
+		{
+			name:       "basic-auth-missing-keys",
+			secretType: corev1.SecretTypeBasicAuth,
+			wantErr:    `secret type kubernetes.io/basic-auth requires one of the keys "username" or "password"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateSecretData(tt.secretType, tt.data)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestHasOwnerLabels(t *testing.T) {
 	t.Parallel()
 
@@ -1786,3 +2873,99 @@ func checkDynamicOpenSecretEqual(t *testing.T, want, got *models.Secrets20231128
 	assert.Equal(t, want.DynamicInstance.TTL, got.DynamicInstance.TTL)
 	assert.Equal(t, want.DynamicInstance.Values, got.DynamicInstance.Values)
 }
+
+func Test_encodeValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    any
+		encoding string
+		want     []byte
+		wantErr  string
+	}{
+		{
+			name:  "default-string",
+			value: "foo",
+			want:  []byte(`foo`),
+		},
+		{
+			name:  "default-number",
+			value: float64(1e21),
+			want:  []byte(`1e+21`),
+		},
+		{
+			name:     "json-string",
+			value:    "foo",
+			encoding: KeyEncodingJSON,
+			want:     []byte(`"foo"`),
+		},
+		{
+			name:     "json-map",
+			value:    map[string]any{"foo": "bar"},
+			encoding: KeyEncodingJSON,
+			want:     []byte(`{"foo":"bar"}`),
+		},
+		{
+			name:     "base64decode",
+			value:    "aGVsbG8=",
+			encoding: KeyEncodingBase64Decode,
+			want:     []byte(`hello`),
+		},
+		{
+			name:     "base64decode-not-string",
+			value:    float64(1),
+			encoding: KeyEncodingBase64Decode,
+			wantErr:  `value of key "foo" is not a base64 encoded string`,
+		},
+		{
+			name:     "int-string-float",
+			value:    float64(1e21),
+			encoding: KeyEncodingIntString,
+			want:     []byte(`1000000000000000000000`),
+		},
+		{
+			name:     "int-string-json-number",
+			value:    json.Number("42"),
+			encoding: KeyEncodingIntString,
+			want:     []byte(`42`),
+		},
+		{
+			name:     "int-string-string",
+			value:    "1e3",
+			encoding: KeyEncodingIntString,
+			want:     []byte(`1000`),
+		},
+		{
+			name:     "int-string-fraction",
+			value:    float64(1.5),
+			encoding: KeyEncodingIntString,
+			wantErr:  `value of key "foo" is not an integer`,
+		},
+		{
+			name:     "int-string-not-number",
+			value:    "bar",
+			encoding: KeyEncodingIntString,
+			wantErr:  `value of key "foo" is not a number`,
+		},
+		{
+			name:     "unsupported",
+			value:    "bar",
+			encoding: "yaml",
+			wantErr:  `unsupported encoding "yaml" for key "foo"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := encodeValue("foo", tt.value, tt.encoding)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"maps"
 	"regexp"
 	"slices"
+	"strings"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -71,6 +73,36 @@ func (e *TemplateNotFoundError) Error() string {
 		"template %q not found in object %s, %s", e.name, e.objKey, e.gvk)
 }
 
+type ConfigMapKeyNotFoundError struct {
+	key    string
+	objKey ctrlclient.ObjectKey
+}
+
+func (e *ConfigMapKeyNotFoundError) Error() string {
+	return fmt.Sprintf("key %q not found in ConfigMap %s", e.key, e.objKey)
+}
+
+// TemplateRenderError is returned when SecretTransformationOption's
+// IsolateTemplateErrors is set, and some of the KeyedTemplates failed to
+// render. The data of all other keys is returned along with the error.
+type TemplateRenderError struct {
+	// Errs maps a K8s Secret data key to its template's rendering error.
+	Errs map[string]error
+}
+
+func (e *TemplateRenderError) Error() string {
+	var msgs []string
+	for _, k := range e.Keys() {
+		msgs = append(msgs, fmt.Sprintf("%q: %s", k, e.Errs[k]))
+	}
+	return fmt.Sprintf("failed to render templates for keys %s", strings.Join(msgs, ", "))
+}
+
+// Keys returns the sorted K8s Secret data keys that failed to render.
+func (e *TemplateRenderError) Keys() []string {
+	return slices.Sorted(maps.Keys(e.Errs))
+}
+
 // SecretTransformationOption provides the configuration necessary when
 // performing source secret data transformations.
 type SecretTransformationOption struct {
@@ -89,6 +121,15 @@ type SecretTransformationOption struct {
 	KeyedTemplates []*KeyedTemplate
 	// ExcludeRaw data from the resulting K8s Secret data.
 	ExcludeRaw bool
+	// IsolateTemplateErrors renders each KeyedTemplate independently, see
+	// TemplateRenderError.
+	IsolateTemplateErrors bool
+	// KeyMap renames the secret data keys to their K8s Secret data keys, it is
+	// applied after the Includes and Excludes.
+	KeyMap map[string]string
+	// Encodings maps the K8s Secret data keys to the encoding of their values,
+	// see encodeValue.
+	Encodings map[string]string
 }
 
 // KeyedTemplate maps a secret data key to its secretsv1beta1.Template
@@ -116,10 +157,16 @@ func (k *KeyedTemplate) Cmp(other *KeyedTemplate) int {
 }
 
 type GlobalTransformationOptions struct {
-	// RenderOptionExcludeRaw sets the global sync option for controlling the exclusion
-	// of _raw from the destination secret.
+	// RenderOptionExcludeRaw sets the global default for controlling the exclusion
+	// of _raw from the destination secret. It is overridden by the destination's
+	// Transformation.ExcludeRaw, when set.
 	// This is usually set from main via the command line arg --global-transformation-options
 	ExcludeRaw bool
+	// TransformationRef to a SecretTransformation that is merged into every
+	// syncable secret's transformation. The syncable secret's own transformation
+	// always takes precedence over the global one. The Namespace must be set.
+	// This is usually set from main via the command line arg --global-transformation-ref
+	TransformationRef *secretsv1beta1.TransformationRef
 }
 
 func NewSecretTransformationOption(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object, globalOpt *GlobalTransformationOptions) (*SecretTransformationOption, error) {
@@ -128,7 +175,24 @@ func NewSecretTransformationOption(ctx context.Context, client ctrlclient.Client
 		return nil, err
 	}
 
-	keyedTemplates, ff, err := gatherTemplates(ctx, client, meta)
+	return newSecretTransformationOption(ctx, client, obj, meta, globalOpt)
+}
+
+// NewDestinationTransformationOption returns the SecretTransformationOption
+// for dest, one of obj's additional destinations, rather than for obj's
+// Spec.Destination.
+func NewDestinationTransformationOption(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object, dest *secretsv1beta1.Destination, globalOpt *GlobalTransformationOptions) (*SecretTransformationOption, error) {
+	meta, err := common.NewSyncableSecretMetaData(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	meta.Destination = dest.DeepCopy()
+	return newSecretTransformationOption(ctx, client, obj, meta, globalOpt)
+}
+
+func newSecretTransformationOption(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object, meta *common.SyncableSecretMetaData, globalOpt *GlobalTransformationOptions) (*SecretTransformationOption, error) {
+	keyedTemplates, ff, err := gatherTemplates(ctx, client, meta, globalOpt)
 	if err != nil {
 		return nil, err
 	}
@@ -141,20 +205,62 @@ func NewSecretTransformationOption(ctx context.Context, client ctrlclient.Client
 		Labels:         obj.GetLabels(),
 	}
 
+	// the global option is only the default, the destination's configuration
+	// always takes precedence over it.
 	if globalOpt != nil {
 		opt.ExcludeRaw = globalOpt.ExcludeRaw
 	}
 
-	if meta.Destination.Transformation.ExcludeRaw {
-		opt.ExcludeRaw = meta.Destination.Transformation.ExcludeRaw
+	if meta.Destination.Transformation.ExcludeRaw != nil {
+		opt.ExcludeRaw = *meta.Destination.Transformation.ExcludeRaw
 	}
 
+	opt.IsolateTemplateErrors = meta.Destination.Transformation.IsolateTemplateErrors
+
+	if err := validateKeyMap(meta.Destination.KeyMap); err != nil {
+		return nil, err
+	}
+	opt.KeyMap = meta.Destination.KeyMap
+
+	if err := validateEncodings(meta.Destination.Transformation.Encodings); err != nil {
+		return nil, err
+	}
+	opt.Encodings = meta.Destination.Transformation.Encodings
+
 	return opt, nil
 }
 
+// validateEncodings ensures that every encoding is supported.
+func validateEncodings(encodings map[string]string) error {
+	for _, k := range slices.Sorted(maps.Keys(encodings)) {
+		if !slices.Contains(supportedKeyEncodings, encodings[k]) {
+			return fmt.Errorf("unsupported encoding %q for key %q, must be one of %v",
+				encodings[k], k, supportedKeyEncodings)
+		}
+	}
+	return nil
+}
+
+// validateKeyMap ensures that every key of the destination's KeyMap is renamed
+// to a distinct and valid K8s Secret data key.
+func validateKeyMap(keyMap map[string]string) error {
+	seen := make(map[string]string, len(keyMap))
+	for _, from := range slices.Sorted(maps.Keys(keyMap)) {
+		to := keyMap[from]
+		if errs := validation.IsConfigMapKey(to); len(errs) > 0 {
+			return fmt.Errorf("invalid keyMap key %q for %q: %s", to, from, strings.Join(errs, "; "))
+		}
+		if other, ok := seen[to]; ok {
+			return fmt.Errorf("duplicate keyMap key %q for %q and %q", to, other, from)
+		}
+		seen[to] = from
+	}
+	return nil
+}
+
 // gatherTemplates attempts to collect all v1beta1.Template(s) for the
 // syncable secret object.
-func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common.SyncableSecretMetaData) ([]*KeyedTemplate, *fieldFilters, error) {
+func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common.SyncableSecretMetaData, globalOpt *GlobalTransformationOptions) ([]*KeyedTemplate, *fieldFilters, error) {
 	var errs error
 	var keyedTemplates []*KeyedTemplate
 
@@ -194,8 +300,7 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 	}
 
 	seenRefs := make(map[ctrlclient.ObjectKey]bool)
-	// get the remote ref template templates
-	for _, ref := range transformation.TransformationRefs {
+	addTransformationRef := func(ref secretsv1beta1.TransformationRef, global bool) {
 		ns := meta.Namespace
 		if ref.Namespace != "" {
 			ns = ref.Namespace
@@ -203,12 +308,14 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 
 		objKey := ctrlclient.ObjectKey{Namespace: ns, Name: ref.Name}
 		if _, ok := seenRefs[objKey]; ok {
-			errs = errors.Join(errs,
-				&DuplicateTransformationRefError{
-					objKey: objKey,
-				},
-			)
-			continue
+			if !global {
+				errs = errors.Join(errs,
+					&DuplicateTransformationRefError{
+						objKey: objKey,
+					},
+				)
+			}
+			return
 		}
 
 		seenRefs[objKey] = true
@@ -216,7 +323,7 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 		obj, err := common.GetSecretTransformation(ctx, client, objKey)
 		if err != nil {
 			errs = errors.Join(errs, err)
-			continue
+			return
 		}
 
 		if !ptr.Deref(obj.Status.Valid, false) {
@@ -225,7 +332,7 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 					objKey: objKey,
 					gvk:    obj.GetObjectKind().GroupVersionKind(),
 				})
-			continue
+			return
 		}
 
 		if !ref.IgnoreExcludes {
@@ -235,8 +342,30 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 			ff.addIncludes(obj.Spec.Includes...)
 		}
 
+		// the global templates never override the object's own templates.
+		skipGlobal := func(name, key string) bool {
+			if !global {
+				return false
+			}
+			if _, ok := seenTemplates[name]; ok {
+				return true
+			}
+			return slices.ContainsFunc(keyedTemplates, func(k *KeyedTemplate) bool {
+				return k.Key == key
+			})
+		}
+
 		// add all configured templates for the Destination
 		for _, tmplRef := range ref.TemplateRefs {
+			key := tmplRef.KeyOverride
+			if key == "" {
+				key = tmplRef.Name
+			}
+
+			if skipGlobal(tmplRef.Name, key) {
+				continue
+			}
+
 			if _, ok := seenTemplates[tmplRef.Name]; ok {
 				errs = errors.Join(errs,
 					&DuplicateTemplateNameError{name: tmplRef.Name})
@@ -254,11 +383,6 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 				continue
 			}
 
-			key := tmplRef.KeyOverride
-			if key == "" {
-				key = tmplRef.Name
-			}
-
 			addTemplate(tmpl, key)
 		}
 
@@ -269,10 +393,20 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 				name = fmt.Sprintf("%s/%d", objKey, idx)
 			}
 
+			if skipGlobal(name, "") {
+				continue
+			}
+
+			text, err := GetSourceTemplateText(ctx, client, objKey.Namespace, tmpl)
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+
 			addTemplate(
 				secretsv1beta1.Template{
 					Name: name,
-					Text: tmpl.Text,
+					Text: text,
 				}, "",
 			)
 		}
@@ -285,12 +419,27 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 						tmpl.Name = fmt.Sprintf("%s/%s", objKey, key)
 					}
 
+					if skipGlobal(tmpl.Name, key) {
+						continue
+					}
+
 					addTemplate(tmpl, key)
 				}
 			}
 		}
 	}
 
+	// get the remote ref template templates
+	for _, ref := range transformation.TransformationRefs {
+		addTransformationRef(ref, false)
+	}
+
+	// the global ref is merged last, so that the object's own transformation
+	// always takes precedence.
+	if globalOpt != nil && globalOpt.TransformationRef != nil {
+		addTransformationRef(*globalOpt.TransformationRef, true)
+	}
+
 	if errs != nil {
 		return nil, nil, errs
 	}
@@ -302,6 +451,33 @@ func gatherTemplates(ctx context.Context, client ctrlclient.Client, meta *common
 	return keyedTemplates, ff, nil
 }
 
+// GetSourceTemplateText returns the text of the SourceTemplate tmpl of a
+// SecretTransformation in namespace. The text of a template with a
+// ConfigMapRef is read from the referenced ConfigMap.
+func GetSourceTemplateText(ctx context.Context, client ctrlclient.Client,
+	namespace string, tmpl secretsv1beta1.SourceTemplate,
+) (string, error) {
+	if tmpl.ConfigMapRef == nil {
+		return tmpl.Text, nil
+	}
+
+	objKey := ctrlclient.ObjectKey{Namespace: namespace, Name: tmpl.ConfigMapRef.Name}
+	cm, err := GetConfigMap(ctx, client, objKey)
+	if err != nil {
+		return "", err
+	}
+
+	text, ok := cm.Data[tmpl.ConfigMapRef.Key]
+	if !ok {
+		return "", &ConfigMapKeyNotFoundError{
+			key:    tmpl.ConfigMapRef.Key,
+			objKey: objKey,
+		}
+	}
+
+	return text, nil
+}
+
 // loadTemplates parses all v1beta1.Template(s) into a single
 // template.SecretTemplate. It should normally be called before rendering any
 // templates
@@ -329,6 +505,10 @@ func renderTemplates(opt *SecretTransformationOption,
 		return nil, fmt.Errorf("no templates configured")
 	}
 
+	if opt.IsolateTemplateErrors {
+		return renderTemplatesIsolated(opt, input)
+	}
+
 	data := make(map[string][]byte)
 	tmpl, err := loadTemplates(opt)
 	if err != nil {
@@ -356,6 +536,56 @@ func renderTemplates(opt *SecretTransformationOption,
 	return data, nil
 }
 
+// renderTemplatesIsolated renders each of the KeyedTemplates independently. A
+// template that fails to parse, validate, or execute only affects its own key.
+// Returns a TemplateRenderError along with the successfully rendered data, if
+// any template failed.
+func renderTemplatesIsolated(opt *SecretTransformationOption,
+	input *SecretInput,
+) (map[string][]byte, error) {
+	errs := make(map[string]error)
+	tmpl := template.NewSecretTemplate("")
+	for _, spec := range opt.KeyedTemplates {
+		// parse each template on its own first, so that a template that does
+		// not parse cannot leave the shared template in a partial state.
+		err := template.NewSecretTemplate("").Parse(spec.Template.Name, spec.Template.Text)
+		if err == nil {
+			err = tmpl.Parse(spec.Template.Name, spec.Template.Text)
+		}
+		if err != nil && !spec.IsSource() {
+			errs[spec.Key] = err
+		}
+	}
+
+	data := make(map[string][]byte)
+	for _, spec := range opt.KeyedTemplates {
+		if spec.IsSource() {
+			continue
+		}
+		if _, ok := errs[spec.Key]; ok {
+			continue
+		}
+
+		if err := validateTemplate(spec.Template); err != nil {
+			errs[spec.Key] = err
+			continue
+		}
+
+		b, err := tmpl.ExecuteTemplate(spec.Template.Name, input)
+		if err != nil {
+			errs[spec.Key] = err
+			continue
+		}
+		data[spec.Key] = b
+	}
+
+	if len(errs) > 0 {
+		return data, &TemplateRenderError{Errs: errs}
+	}
+
+	return data, nil
+}
+
 func matchField(pat, f string) (bool, error) {
 	var err error
 	re, ok := regexCache.Get(pat)
@@ -532,14 +762,24 @@ func newFieldFilters() *fieldFilters {
 	}
 }
 
-func GetTransformationRefObjKeys(t secretsv1beta1.Transformation, defaultNS string) []ctrlclient.ObjectKey {
+// GetTransformationRefObjKeys returns the object keys of all SecretTransformation
+// references, including the global one from globalOpt.
+func GetTransformationRefObjKeys(t secretsv1beta1.Transformation, defaultNS string, globalOpt *GlobalTransformationOptions) []ctrlclient.ObjectKey {
+	refs := t.TransformationRefs
+	if globalOpt != nil && globalOpt.TransformationRef != nil {
+		refs = append(slices.Clip(refs), *globalOpt.TransformationRef)
+	}
+
 	var result []ctrlclient.ObjectKey
-	for _, ref := range t.TransformationRefs {
+	for _, ref := range refs {
 		ns := defaultNS
 		if ref.Namespace != "" {
 			ns = ref.Namespace
 		}
-		result = append(result, ctrlclient.ObjectKey{Namespace: ns, Name: ref.Name})
+		objKey := ctrlclient.ObjectKey{Namespace: ns, Name: ref.Name}
+		if !slices.Contains(result, objKey) {
+			result = append(result, objKey)
+		}
 	}
 
 	return result
@@ -289,6 +289,94 @@ db.username=alice
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name:  "isolated-all-rendered",
+			input: NewSecretInput[any, any](secrets, nil, nil, nil),
+			opt: &SecretTransformationOption{
+				IsolateTemplateErrors: true,
+				KeyedTemplates: []*KeyedTemplate{
+					{
+						Template: secretsv1beta1.Template{
+							Name: "helper",
+							Text: `{{define "helper"}}{{- . | b64dec -}}{{end}}`,
+						},
+					},
+					{
+						Key: "t1r",
+						Template: secretsv1beta1.Template{
+							Name: "t1r",
+							Text: `{{- template "helper" get .Secrets "baz" -}}`,
+						},
+					},
+					{
+						Key: "t2r",
+						Template: secretsv1beta1.Template{
+							Name: "t2r",
+							Text: `{{- get .Secrets "foo" -}}`,
+						},
+					},
+				},
+			},
+			want: map[string][]byte{
+				"t1r": []byte(`foo`),
+				"t2r": marshalRaw(t, 1),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "isolated-partial-failure",
+			input: NewSecretInput[any, any](secrets, nil, nil, nil),
+			opt: &SecretTransformationOption{
+				IsolateTemplateErrors: true,
+				KeyedTemplates: []*KeyedTemplate{
+					{
+						Template: secretsv1beta1.Template{
+							Name: "helper",
+							Text: `{{define "helper"}}{{- . | b64dec -}}{{end}}`,
+						},
+					},
+					{
+						Key: "t1r",
+						Template: secretsv1beta1.Template{
+							Name: "t1r",
+							Text: `{{- template "helper" get .Secrets "baz" -}}`,
+						},
+					},
+					{
+						Key: "parse",
+						Template: secretsv1beta1.Template{
+							Name: "parse",
+							Text: `{{- get .Secrets "bar" | unknown -}}`,
+						},
+					},
+					{
+						Key: "exec",
+						Template: secretsv1beta1.Template{
+							Name: "exec",
+							Text: `{{- template "missing" . -}}`,
+						},
+					},
+					{
+						Key: "t2r",
+						Template: secretsv1beta1.Template{
+							Name: "t2r",
+							Text: `{{- template "helper" get .Secrets "bar" -}}`,
+						},
+					},
+				},
+			},
+			want: map[string][]byte{
+				"t1r": []byte(`foo`),
+				"t2r": []byte(`buz`),
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				var renderErr *TemplateRenderError
+				if !assert.ErrorAs(t, err, &renderErr, i...) {
+					return false
+				}
+				return assert.Equal(t, []string{"exec", "parse"}, renderErr.Keys(), i...)
+			},
+		},
 		{
 			name:  "no-specs-error",
 			input: NewSecretInput[string, string](nil, nil, nil, nil),
@@ -1235,11 +1323,156 @@ func TestNewSecretTransformationOption(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "global-transformation-ref",
+			globalOpt: &GlobalTransformationOptions{
+				TransformationRef: &secretsv1beta1.TransformationRef{
+					Namespace: "vso",
+					Name:      "global",
+				},
+			},
+			obj: newSecretObj(t,
+				secretsv1beta1.Transformation{
+					Templates: map[string]secretsv1beta1.Template{
+						"foo": {
+							Text: "{{- foo -}}",
+						},
+					},
+					Excludes: []string{`^bad.+`},
+				},
+			),
+			secretTransObjs: []*secretsv1beta1.SecretTransformation{
+				newTransObj(t,
+					metav1.ObjectMeta{
+						Name:      "global",
+						Namespace: "vso",
+					},
+					secretsv1beta1.SecretTransformationSpec{
+						Templates: map[string]secretsv1beta1.Template{
+							"foo": {
+								Text: "{{- global-foo -}}",
+							},
+							".env": {
+								Text: "{{- env -}}",
+							},
+						},
+						Excludes: []string{`^ugly.+`},
+					}, nil),
+			},
+			want: &SecretTransformationOption{
+				Excludes: defaultExcludes,
+				KeyedTemplates: []*KeyedTemplate{
+					{
+						Key: ".env",
+						Template: secretsv1beta1.Template{
+							Name: "vso/global/.env",
+							Text: "{{- env -}}",
+						},
+					},
+					{
+						Key: "foo",
+						Template: secretsv1beta1.Template{
+							Name: "default/basic/foo",
+							Text: "{{- foo -}}",
+						},
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "global-transformation-ref-also-referenced-by-obj",
+			globalOpt: &GlobalTransformationOptions{
+				TransformationRef: &secretsv1beta1.TransformationRef{
+					Namespace: "default",
+					Name:      "templates",
+				},
+			},
+			obj: newSecretObj(t,
+				secretsv1beta1.Transformation{
+					TransformationRefs: []secretsv1beta1.TransformationRef{
+						{
+							Name: "templates",
+						},
+					},
+				},
+			),
+			secretTransObjs: []*secretsv1beta1.SecretTransformation{
+				newTransObj(t,
+					defaultTransObjMeta,
+					secretsv1beta1.SecretTransformationSpec{
+						Templates: defaultTemplates1,
+					}, nil),
+			},
+			want: &SecretTransformationOption{
+				KeyedTemplates: defaultKeyedTemplates,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "global-transformation-ref-invalid",
+			globalOpt: &GlobalTransformationOptions{
+				TransformationRef: &secretsv1beta1.TransformationRef{
+					Namespace: "vso",
+					Name:      "global",
+				},
+			},
+			obj: newSecretObj(t,
+				secretsv1beta1.Transformation{},
+			),
+			secretTransObjs: []*secretsv1beta1.SecretTransformation{
+				newTransObj(t,
+					metav1.ObjectMeta{
+						Name:      "global",
+						Namespace: "vso",
+					},
+					secretsv1beta1.SecretTransformationSpec{
+						Templates: defaultTemplates1,
+					},
+					&secretsv1beta1.SecretTransformationStatus{
+						Valid: ptr.To(false),
+					}),
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorContains(t, err,
+					`vso/global is in an invalid state`)
+			},
+		},
 		{
 			name: "exclude-raw-from-obj",
 			obj: newSecretObj(t,
 				secretsv1beta1.Transformation{
-					ExcludeRaw: true,
+					ExcludeRaw: ptr.To(true),
+				},
+			),
+			want: &SecretTransformationOption{
+				ExcludeRaw: true,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "exclude-raw-from-obj-overrides-global-opt",
+			globalOpt: &GlobalTransformationOptions{
+				ExcludeRaw: true,
+			},
+			obj: newSecretObj(t,
+				secretsv1beta1.Transformation{
+					ExcludeRaw: ptr.To(false),
+				},
+			),
+			want: &SecretTransformationOption{
+				ExcludeRaw: false,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "exclude-raw-inherits-global-opt",
+			globalOpt: &GlobalTransformationOptions{
+				ExcludeRaw: true,
+			},
+			obj: newSecretObj(t,
+				secretsv1beta1.Transformation{
+					ExcludeRaw: nil,
 				},
 			),
 			want: &SecretTransformationOption{
@@ -1247,6 +1480,21 @@ func TestNewSecretTransformationOption(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "include-raw-inherits-global-opt",
+			globalOpt: &GlobalTransformationOptions{
+				ExcludeRaw: false,
+			},
+			obj: newSecretObj(t,
+				secretsv1beta1.Transformation{
+					ExcludeRaw: nil,
+				},
+			),
+			want: &SecretTransformationOption{
+				ExcludeRaw: false,
+			},
+			wantErr: assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1351,3 +1599,215 @@ func Test_validateTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTransformationRefObjKeys(t *testing.T) {
+	t.Parallel()
+
+	transformation := secretsv1beta1.Transformation{
+		TransformationRefs: []secretsv1beta1.TransformationRef{
+			{
+				Name: "foo",
+			},
+			{
+				Namespace: "vso",
+				Name:      "global",
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		globalOpt *GlobalTransformationOptions
+		want      []ctrlclient.ObjectKey
+	}{
+		{
+			name: "without-global-opt",
+			want: []ctrlclient.ObjectKey{
+				{Namespace: "default", Name: "foo"},
+				{Namespace: "vso", Name: "global"},
+			},
+		},
+		{
+			name: "with-global-ref",
+			globalOpt: &GlobalTransformationOptions{
+				TransformationRef: &secretsv1beta1.TransformationRef{
+					Namespace: "vso",
+					Name:      "other",
+				},
+			},
+			want: []ctrlclient.ObjectKey{
+				{Namespace: "default", Name: "foo"},
+				{Namespace: "vso", Name: "global"},
+				{Namespace: "vso", Name: "other"},
+			},
+		},
+		{
+			name: "with-global-ref-already-referenced",
+			globalOpt: &GlobalTransformationOptions{
+				TransformationRef: &secretsv1beta1.TransformationRef{
+					Namespace: "vso",
+					Name:      "global",
+				},
+			},
+			want: []ctrlclient.ObjectKey{
+				{Namespace: "default", Name: "foo"},
+				{Namespace: "vso", Name: "global"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want,
+				GetTransformationRefObjKeys(transformation, "default", tt.globalOpt))
+		})
+	}
+}
+
+func TestGetSourceTemplateText(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "templates",
+		},
+		Data: map[string]string{
+			"helpers.tmpl": `{{- define "helper" -}}foo{{- end -}}`,
+		},
+	}
+	client := testutils.NewFakeClientBuilder().WithObjects(cm).Build()
+
+	tests := []struct {
+		name      string
+		namespace string
+		tmpl      secretsv1beta1.SourceTemplate
+		want      string
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "inline",
+			namespace: "default",
+			tmpl: secretsv1beta1.SourceTemplate{
+				Text: "{{- foo -}}",
+			},
+			want:    "{{- foo -}}",
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "configmap",
+			namespace: "default",
+			tmpl: secretsv1beta1.SourceTemplate{
+				ConfigMapRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "templates",
+					Key:  "helpers.tmpl",
+				},
+			},
+			want:    `{{- define "helper" -}}foo{{- end -}}`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "configmap-key-not-found",
+			namespace: "default",
+			tmpl: secretsv1beta1.SourceTemplate{
+				ConfigMapRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "templates",
+					Key:  "other.tmpl",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`key "other.tmpl" not found in ConfigMap default/templates`, i...)
+			},
+		},
+		{
+			name:      "configmap-not-found",
+			namespace: "other",
+			tmpl: secretsv1beta1.SourceTemplate{
+				ConfigMapRef: &secretsv1beta1.ConfigMapKeyRef{
+					Name: "templates",
+					Key:  "helpers.tmpl",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t, apierrors.IsNotFound(err), i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := GetSourceTemplateText(ctx, client, tt.namespace, tt.tmpl)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_validateKeyMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keyMap  map[string]string
+		wantErr string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "valid",
+			keyMap: map[string]string{
+				"username": "DB_USER",
+				"password": "DB_PASSWORD",
+			},
+		},
+		{
+			name: "duplicate",
+			keyMap: map[string]string{
+				"username": "DB_USER",
+				"user":     "DB_USER",
+			},
+			wantErr: `duplicate keyMap key "DB_USER" for "user" and "username"`,
+		},
+		{
+			name: "invalid",
+			keyMap: map[string]string{
+				"username": "DB USER",
+			},
+			wantErr: `invalid keyMap key "DB USER" for "username": ` +
+				`a valid config key must consist of alphanumeric characters, '-', '_' or '.' ` +
+				`(e.g. 'key.name',  or 'KEY_NAME',  or 'key-name', regex used for validation is '[-._a-zA-Z0-9]+')`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateKeyMap(tt.keyMap)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_validateEncodings(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateEncodings(nil))
+	assert.NoError(t, validateEncodings(map[string]string{
+		"cert":  KeyEncodingBase64Decode,
+		"name":  KeyEncodingJSON,
+		"count": KeyEncodingIntString,
+	}))
+	assert.EqualError(t, validateEncodings(map[string]string{
+		"name": "yaml",
+	}), `unsupported encoding "yaml" for key "name", must be one of [base64decode json int-string]`)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package cron provides a parser for standard cron expressions.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tzPrefix can prefix an expression to set the time zone of its schedule, e.g.
+// "CRON_TZ=Europe/Berlin 0 22 * * 5".
+const tzPrefix = "CRON_TZ="
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// 7 is an alias for Sunday.
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression. The zero value never matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day of month or day of week field is
+	// unrestricted, see Matches.
+	domStar, dowStar bool
+	location         *time.Location
+	expr             string
+}
+
+// Parse parses a standard cron expression with the five fields: minute, hour,
+// day of month, month, and day of week. Each field supports "*", single values,
+// ranges "a-b", steps "*/n" and "a-b/n", and comma separated lists thereof. The
+// expression is evaluated in UTC, unless it is prefixed by CRON_TZ=<zone>.
+func Parse(expr string) (*Schedule, error) {
+	s := &Schedule{
+		location: time.UTC,
+		expr:     expr,
+	}
+
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, tzPrefix) {
+		zone, rest, _ := strings.Cut(strings.TrimPrefix(expr, tzPrefix), " ")
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", zone, err)
+		}
+		s.location = loc
+		expr = rest
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("expected %d fields, got %d in %q", len(fields), len(parts), expr)
+	}
+
+	bits := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, part := range parts {
+		v, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		*bits[i] = v
+	}
+
+	s.domStar = parts[2] == "*"
+	s.dowStar = parts[4] == "*"
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, term := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(term, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepStr, f.name)
+			}
+		}
+
+		var lo, hi int
+		if rng == "*" {
+			lo, hi = f.min, f.max
+		} else {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseValue(loStr, f); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(hiStr, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field", rng, f.name)
+			}
+		}
+
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << i
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, must be within [%d, %d]",
+			s, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Matches returns true if t, truncated to the minute, is matched by the
+// schedule. Like the standard cron, if both the day of month and the day of
+// week fields are restricted, t matches when either field matches.
+func (s *Schedule) Matches(t time.Time) bool {
+	if s == nil || s.location == nil {
+		return false
+	}
+
+	t = t.In(s.location)
+	if s.minute&(1<<t.Minute()) == 0 ||
+		s.hour&(1<<t.Hour()) == 0 ||
+		s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Prev returns the latest time, truncated to the minute, that is at or before
+// t, not earlier than t minus within, and that is matched by the schedule.
+// Returns false if there is no such time.
+func (s *Schedule) Prev(t time.Time, within time.Duration) (time.Time, bool) {
+	start := t.Truncate(time.Minute)
+	for cur := start; t.Sub(cur) <= within; cur = cur.Add(-time.Minute) {
+		if s.Matches(cur) {
+			return cur, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Next returns the earliest time, truncated to the minute, that is at or after
+// t, not later than t plus within, and that is matched by the schedule. Returns
+// false if there is no such time.
+func (s *Schedule) Next(t time.Time, within time.Duration) (time.Time, bool) {
+	start := t.Truncate(time.Minute)
+	if start.Before(t) {
+		start = start.Add(time.Minute)
+	}
+	for cur := start; cur.Sub(t) <= within; cur = cur.Add(time.Minute) {
+		if s.Matches(cur) {
+			return cur, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.expr
+}
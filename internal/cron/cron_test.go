@@ -0,0 +1,251 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		expr    string
+		match   []time.Time
+		noMatch []time.Time
+		wantErr string
+	}{
+		{
+			name: "every-minute",
+			expr: "* * * * *",
+			match: []time.Time{
+				time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 3, 22, 1, 30, 0, time.UTC),
+			},
+		},
+		{
+			name: "friday-night",
+			expr: "0 22 * * 5",
+			match: []time.Time{
+				time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 3, 22, 0, 59, 0, time.UTC),
+			},
+			noMatch: []time.Time{
+				time.Date(2024, 5, 3, 22, 1, 0, 0, time.UTC),
+				time.Date(2024, 5, 4, 22, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "sunday-alias",
+			expr: "0 0 * * 7",
+			match: []time.Time{
+				time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+			noMatch: []time.Time{
+				time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "lists-ranges-and-steps",
+			expr: "*/15 8-17/3 1,15 * *",
+			match: []time.Time{
+				time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 15, 17, 45, 0, 0, time.UTC),
+				time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC),
+			},
+			noMatch: []time.Time{
+				time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 1, 8, 10, 0, 0, time.UTC),
+				time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day-of-month-or-day-of-week",
+			expr: "0 0 13 * 5",
+			match: []time.Time{
+				// Monday the 13th
+				time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+				// Friday the 3rd
+				time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC),
+			},
+			noMatch: []time.Time{
+				time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "time-zone",
+			expr: "CRON_TZ=America/New_York 0 22 * * *",
+			match: []time.Time{
+				time.Date(2024, 5, 4, 2, 0, 0, 0, time.UTC),
+			},
+			noMatch: []time.Time{
+				time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "too-few-fields",
+			expr:    "0 22 * *",
+			wantErr: `expected 5 fields, got 4 in "0 22 * *"`,
+		},
+		{
+			name:    "out-of-range",
+			expr:    "0 24 * * *",
+			wantErr: `invalid value "24" in hour field, must be within [0, 23]`,
+		},
+		{
+			name:    "invalid-step",
+			expr:    "*/0 * * * *",
+			wantErr: `invalid step "0" in minute field`,
+		},
+		{
+			name:    "invalid-range",
+			expr:    "* * * 6-1 *",
+			wantErr: `invalid range "6-1" in month field`,
+		},
+		{
+			name:    "invalid-time-zone",
+			expr:    "CRON_TZ=Nowhere/Else * * * * *",
+			wantErr: `invalid time zone "Nowhere/Else"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := Parse(tt.expr)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expr, s.String())
+			for _, ts := range tt.match {
+				assert.True(t, s.Matches(ts), "expected %s to match", ts)
+			}
+			for _, ts := range tt.noMatch {
+				assert.False(t, s.Matches(ts), "expected %s not to match", ts)
+			}
+		})
+	}
+}
+
+func TestSchedule_Prev(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("0 22 * * 5")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		t      time.Time
+		within time.Duration
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "at-start",
+			t:      start,
+			within: time.Hour,
+			want:   start,
+			wantOK: true,
+		},
+		{
+			name:   "within",
+			t:      start.Add(59*time.Minute + 30*time.Second),
+			within: time.Hour,
+			want:   start,
+			wantOK: true,
+		},
+		{
+			name:   "outside",
+			t:      start.Add(61 * time.Minute),
+			within: time.Hour,
+		},
+		{
+			name:   "before",
+			t:      start.Add(-time.Minute),
+			within: 72 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := s.Prev(tt.t, tt.within)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "Prev() = %s, want %s", got, tt.want)
+		})
+	}
+
+	var nilSchedule *Schedule
+	_, ok := nilSchedule.Prev(start, time.Hour)
+	assert.False(t, ok)
+}
+
+func TestSchedule_Next(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse("0 22 * * 5")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		t      time.Time
+		within time.Duration
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "at-start",
+			t:      start,
+			within: time.Hour,
+			want:   start,
+			wantOK: true,
+		},
+		{
+			name:   "within",
+			t:      start.Add(-59*time.Minute - 30*time.Second),
+			within: time.Hour,
+			want:   start,
+			wantOK: true,
+		},
+		{
+			name:   "outside",
+			t:      start.Add(-61 * time.Minute),
+			within: time.Hour,
+		},
+		{
+			name:   "after",
+			t:      start.Add(time.Minute),
+			within: 72 * time.Hour,
+		},
+		{
+			name:   "next-week",
+			t:      start.Add(time.Second),
+			within: 7 * 24 * time.Hour,
+			want:   start.Add(7 * 24 * time.Hour),
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := s.Next(tt.t, tt.within)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "Next() = %s, want %s", got, tt.want)
+		})
+	}
+
+	var nilSchedule *Schedule
+	_, ok := nilSchedule.Next(start, time.Hour)
+	assert.False(t, ok)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package kms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+var _ Wrapper = (*awsWrapper)(nil)
+
+type awsWrapper struct {
+	client *awskms.Client
+	keyID  string
+}
+
+func newAWSWrapper(ctx context.Context, keyID string) (*awsWrapper, error) {
+	var opts []func(*config.LoadOptions) error
+	if region := awsKeyRegion(keyID); region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the AWS config, err=%w", err)
+	}
+
+	return &awsWrapper{
+		client: awskms.NewFromConfig(cfg),
+		keyID:  keyID,
+	}, nil
+}
+
+func (w *awsWrapper) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	out, err := w.client.Encrypt(ctx, &awskms.EncryptInput{
+		KeyId:     aws.String(w.keyID),
+		Plaintext: plaintext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with AWS KMS key %s, err=%w", w.keyID, err)
+	}
+
+	return out.CiphertextBlob, nil
+}
+
+func (w *awsWrapper) Unwrap(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	out, err := w.client.Decrypt(ctx, &awskms.DecryptInput{
+		KeyId:          aws.String(w.keyID),
+		CiphertextBlob: ciphertext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with AWS KMS key %s, err=%w", w.keyID, err)
+	}
+
+	return out.Plaintext, nil
+}
+
+func (w *awsWrapper) Provider() string {
+	return ProviderAWS
+}
+
+func (w *awsWrapper) KeyID() string {
+	return w.keyID
+}
+
+// awsKeyRegion returns the region of a key or alias ARN, it is empty for any
+// other key ID, in which case the region is taken from the AWS config.
+func awsKeyRegion(keyID string) string {
+	// arn:partition:kms:region:account:key/id
+	parts := strings.SplitN(keyID, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "kms" {
+		return ""
+	}
+
+	return parts[3]
+}
@@ -0,0 +1,227 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	azureKeyVaultAPIVersion = "7.4"
+	azureKeyVaultResource   = "https://vault.azure.net"
+	azureWrapAlgorithm      = "RSA-OAEP-256"
+	azureIMDSTokenEndpoint  = "http://169.254.169.254/metadata/identity/oauth2/token"
+	azureDefaultAuthority   = "https://login.microsoftonline.com/"
+)
+
+var _ Wrapper = (*azureWrapper)(nil)
+
+// azureWrapper wraps keys with an Azure Key Vault key using the Key Vault REST
+// API. The access token is obtained from Azure Workload Identity if it is
+// configured for the Pod, otherwise from the Managed Identity of the node.
+type azureWrapper struct {
+	httpClient *http.Client
+	keyID      string
+	token      func(context.Context) (string, error)
+}
+
+// azureKeyOperation is the request and response body of the Key Vault wrapkey
+// and unwrapkey operations.
+type azureKeyOperation struct {
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Value     string `json:"value"`
+}
+
+// azureWrappedKey is the result of azureWrapper.Wrap. It includes the versioned
+// key identifier, since the key may be rotated after it was wrapped.
+type azureWrappedKey struct {
+	KeyID string `json:"kid"`
+	Value string `json:"value"`
+}
+
+func newAzureWrapper(keyID string) (*azureWrapper, error) {
+	u, err := url.Parse(keyID)
+	if err != nil || u.Scheme != "https" || !strings.HasPrefix(u.Path, "/keys/") {
+		return nil, fmt.Errorf(
+			"invalid Azure Key Vault key ID %q, expected https://<vault>.vault.azure.net/keys/<name>", keyID)
+	}
+
+	w := &azureWrapper{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		keyID:      strings.TrimSuffix(keyID, "/"),
+	}
+	w.token = func(ctx context.Context) (string, error) {
+		if tokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tokenFile != "" {
+			authority := os.Getenv("AZURE_AUTHORITY_HOST")
+			if authority == "" {
+				authority = azureDefaultAuthority
+			}
+			return azureWorkloadIdentityToken(ctx, w.httpClient, authority,
+				os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), tokenFile)
+		}
+		return azureManagedIdentityToken(ctx, w.httpClient, azureIMDSTokenEndpoint, os.Getenv("AZURE_CLIENT_ID"))
+	}
+
+	return w, nil
+}
+
+func (w *azureWrapper) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var resp azureKeyOperation
+	if err := w.keyOperation(ctx, w.keyID+"/wrapkey", azureKeyOperation{
+		Algorithm: azureWrapAlgorithm,
+		Value:     base64.RawURLEncoding.EncodeToString(plaintext),
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to wrap with Azure Key Vault key %s, err=%w", w.keyID, err)
+	}
+
+	return json.Marshal(azureWrappedKey{
+		KeyID: resp.KeyID,
+		Value: resp.Value,
+	})
+}
+
+func (w *azureWrapper) Unwrap(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var wrapped azureWrappedKey
+	if err := json.Unmarshal(ciphertext, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid wrapped key, err=%w", err)
+	}
+	// the wrapped key must belong to the configured key, regardless of its version.
+	if wrapped.KeyID != w.keyID && !strings.HasPrefix(wrapped.KeyID, w.keyID+"/") {
+		return nil, fmt.Errorf("wrapped key belongs to %s, not %s", wrapped.KeyID, w.keyID)
+	}
+
+	var resp azureKeyOperation
+	if err := w.keyOperation(ctx, wrapped.KeyID+"/unwrapkey", azureKeyOperation{
+		Algorithm: azureWrapAlgorithm,
+		Value:     wrapped.Value,
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unwrap with Azure Key Vault key %s, err=%w", wrapped.KeyID, err)
+	}
+
+	return base64.RawURLEncoding.DecodeString(resp.Value)
+}
+
+func (w *azureWrapper) Provider() string {
+	return ProviderAzure
+}
+
+func (w *azureWrapper) KeyID() string {
+	return w.keyID
+}
+
+func (w *azureWrapper) keyOperation(ctx context.Context, endpoint string, op azureKeyOperation, out *azureKeyOperation) error {
+	token, err := w.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		endpoint+"?api-version="+azureKeyVaultAPIVersion, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return doAzureRequest(w.httpClient, req, out)
+}
+
+// azureWorkloadIdentityToken exchanges the federated service account token in
+// tokenFile for a Key Vault access token.
+func azureWorkloadIdentityToken(ctx context.Context, httpClient *http.Client, authority, tenantID, clientID, tokenFile string) (string, error) {
+	if tenantID == "" || clientID == "" {
+		return "", fmt.Errorf("AZURE_TENANT_ID and AZURE_CLIENT_ID are required for Azure Workload Identity")
+	}
+
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the federated token, err=%w", err)
+	}
+
+	form := url.Values{
+		"client_id":             {clientID},
+		"scope":                 {azureKeyVaultResource + "/.default"},
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	endpoint := strings.TrimSuffix(authority, "/") + "/" + tenantID + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return azureAccessToken(httpClient, req)
+}
+
+// azureManagedIdentityToken requests a Key Vault access token from the Azure
+// Instance Metadata Service. The clientID selects a user-assigned identity, it
+// is optional.
+func azureManagedIdentityToken(ctx context.Context, httpClient *http.Client, endpoint, clientID string) (string, error) {
+	params := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {azureKeyVaultResource},
+	}
+	if clientID != "" {
+		params.Set("client_id", clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+
+	return azureAccessToken(httpClient, req)
+}
+
+func azureAccessToken(httpClient *http.Client, req *http.Request) (string, error) {
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doAzureRequest(httpClient, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to get an Azure access token, err=%w", err)
+	}
+	if resp.AccessToken == "" {
+		return "", fmt.Errorf("empty Azure access token")
+	}
+
+	return resp.AccessToken, nil
+}
+
+func doAzureRequest(httpClient *http.Client, req *http.Request, out any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s: %s",
+			resp.StatusCode, req.URL.Redacted(), bytes.TrimSpace(b))
+	}
+
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+var _ Wrapper = (*gcpWrapper)(nil)
+
+type gcpWrapper struct {
+	keys  *cloudkms.ProjectsLocationsKeyRingsCryptoKeysService
+	keyID string
+}
+
+func newGCPWrapper(ctx context.Context, keyID string) (*gcpWrapper, error) {
+	svc, err := cloudkms.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the GCP KMS client, err=%w", err)
+	}
+
+	return &gcpWrapper{
+		keys:  svc.Projects.Locations.KeyRings.CryptoKeys,
+		keyID: keyID,
+	}, nil
+}
+
+func (w *gcpWrapper) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	resp, err := w.keys.Encrypt(w.keyID, &cloudkms.EncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(plaintext),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with GCP KMS key %s, err=%w", w.keyID, err)
+	}
+
+	return base64.StdEncoding.DecodeString(resp.Ciphertext)
+}
+
+func (w *gcpWrapper) Unwrap(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	resp, err := w.keys.Decrypt(w.keyID, &cloudkms.DecryptRequest{
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with GCP KMS key %s, err=%w", w.keyID, err)
+	}
+
+	return base64.StdEncoding.DecodeString(resp.Plaintext)
+}
+
+func (w *gcpWrapper) Provider() string {
+	return ProviderGCP
+}
+
+func (w *gcpWrapper) KeyID() string {
+	return w.keyID
+}
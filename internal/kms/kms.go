@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package kms
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+	ProviderAzure = "azure"
+)
+
+// Providers returns all supported KMS providers.
+func Providers() []string {
+	return []string{ProviderAWS, ProviderGCP, ProviderAzure}
+}
+
+// Wrapper wraps and unwraps an encryption key with a cloud KMS key, for the
+// envelope encryption of data that is stored outside the KMS.
+type Wrapper interface {
+	// Wrap encrypts the plaintext key with the KMS key.
+	Wrap(context.Context, []byte) ([]byte, error)
+	// Unwrap decrypts a key that was encrypted by Wrap.
+	Unwrap(context.Context, []byte) ([]byte, error)
+	// Provider of the KMS key.
+	Provider() string
+	// KeyID of the KMS key.
+	KeyID() string
+}
+
+// NewWrapper returns the Wrapper for the provider's KMS key identified by
+// keyID. The credentials are obtained from the provider's default credential
+// chain, e.g. IRSA on EKS, Workload Identity on GKE, and Workload Identity or a
+// Managed Identity on AKS.
+//
+// The format of keyID depends on the provider:
+//   - aws: the key ID, key ARN, or alias ARN,
+//     e.g. 'arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab'
+//   - gcp: the CryptoKey resource name,
+//     e.g. 'projects/p/locations/global/keyRings/r/cryptoKeys/k'
+//   - azure: the Key Vault key identifier, optionally including its version,
+//     e.g. 'https://my-vault.vault.azure.net/keys/my-key'
+func NewWrapper(ctx context.Context, provider, keyID string) (Wrapper, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("a KMS key ID is required")
+	}
+
+	switch provider {
+	case ProviderAWS:
+		return newAWSWrapper(ctx, keyID)
+	case ProviderGCP:
+		return newGCPWrapper(ctx, keyID)
+	case ProviderAzure:
+		return newAzureWrapper(keyID)
+	default:
+		return nil, fmt.Errorf("unsupported KMS provider %q, choices=%v", provider, Providers())
+	}
+}
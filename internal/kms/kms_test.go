@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWrapper(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		provider string
+		keyID    string
+		wantErr  string
+	}{
+		{
+			name:     "empty-key-id",
+			provider: ProviderAWS,
+			wantErr:  "a KMS key ID is required",
+		},
+		{
+			name:     "unsupported-provider",
+			provider: "foo",
+			keyID:    "bar",
+			wantErr:  `unsupported KMS provider "foo"`,
+		},
+		{
+			name:     "invalid-azure-key-id",
+			provider: ProviderAzure,
+			keyID:    "http://my-vault.vault.azure.net/secrets/foo",
+			wantErr:  "invalid Azure Key Vault key ID",
+		},
+		{
+			name:     "azure",
+			provider: ProviderAzure,
+			keyID:    "https://my-vault.vault.azure.net/keys/my-key/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewWrapper(ctx, tt.provider, tt.keyID)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.provider, w.Provider())
+			assert.Equal(t, strings.TrimSuffix(tt.keyID, "/"), w.KeyID())
+		})
+	}
+}
+
+func Test_awsKeyRegion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "us-east-1",
+		awsKeyRegion("arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"))
+	assert.Equal(t, "eu-west-1", awsKeyRegion("arn:aws:kms:eu-west-1:111122223333:alias/vso"))
+	assert.Equal(t, "", awsKeyRegion("1234abcd-12ab-34cd-56ef-1234567890ab"))
+	assert.Equal(t, "", awsKeyRegion("alias/vso"))
+}
+
+func TestAzureWrapper(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// the fake Key Vault "wraps" a key by reversing it.
+	reverse := func(s string) string {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		assert.NoError(t, err)
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, azureKeyVaultAPIVersion, r.URL.Query().Get("api-version"))
+
+		var op azureKeyOperation
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&op))
+		assert.Equal(t, azureWrapAlgorithm, op.Algorithm)
+		switch r.URL.Path {
+		case "/keys/vso/wrapkey":
+			assert.NoError(t, json.NewEncoder(w).Encode(azureKeyOperation{
+				KeyID: server.URL + "/keys/vso/v1",
+				Value: reverse(op.Value),
+			}))
+		case "/keys/vso/v1/unwrapkey":
+			assert.NoError(t, json.NewEncoder(w).Encode(azureKeyOperation{
+				KeyID: server.URL + "/keys/vso/v1",
+				Value: reverse(op.Value),
+			}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	w, err := newAzureWrapper(server.URL + "/keys/vso")
+	require.NoError(t, err)
+	w.httpClient = server.Client()
+	w.token = func(context.Context) (string, error) {
+		return "token", nil
+	}
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := w.Wrap(ctx, key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(wrapped), base64.RawURLEncoding.EncodeToString(key))
+
+	unwrapped, err := w.Unwrap(ctx, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	other, err := newAzureWrapper(server.URL + "/keys/other")
+	require.NoError(t, err)
+	_, err = other.Unwrap(ctx, wrapped)
+	assert.ErrorContains(t, err, "wrapped key belongs to")
+}
+
+func Test_azureWorkloadIdentityToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tenant/oauth2/v2.0/token", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "federated", r.PostForm.Get("client_assertion"))
+		assert.Equal(t, azureKeyVaultResource+"/.default", r.PostForm.Get("scope"))
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3599}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated\n"), 0o600))
+
+	token, err := azureWorkloadIdentityToken(ctx, server.Client(), server.URL+"/", "tenant", "client", tokenFile)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	_, err = azureWorkloadIdentityToken(ctx, server.Client(), server.URL, "", "client", tokenFile)
+	assert.ErrorContains(t, err, "AZURE_TENANT_ID and AZURE_CLIENT_ID are required")
+}
+
+func Test_azureManagedIdentityToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, azureKeyVaultResource, r.URL.Query().Get("resource"))
+		if r.URL.Query().Get("client_id") == "unknown" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":"3599"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	token, err := azureManagedIdentityToken(ctx, server.Client(), server.URL, "")
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	_, err = azureManagedIdentityToken(ctx, server.Client(), server.URL, "unknown")
+	assert.ErrorContains(t, err, "unexpected status code 400")
+}
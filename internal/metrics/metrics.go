@@ -4,6 +4,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +32,10 @@ const (
 	OperationRenew   = "renew"
 	OperationRead    = "read"
 	OperationWrite   = "write"
+	OperationConnect = "connect"
+	OperationPing    = "ping"
+	OperationPrewarm = "prewarm"
+	OperationRewrap  = "rewrap"
 
 	NameConfig                = "config"
 	NameLength                = "length"
@@ -39,6 +45,61 @@ const (
 	NameRequestsTotal         = "requests_total"
 	NameRequestsErrorsTotal   = "requests_errors_total"
 	NameTaintedClients        = "tainted_clients"
+	NameConnections           = "connections"
+
+	subsystemSecret        = "secret"
+	subsystemSourceChannel = "source_channel"
+	subsystemReconcile     = "reconcile"
+	subsystemFreezeWindow  = "freeze_window"
+	subsystemKVReadBatch   = "kv_read_batch"
+	subsystemReadCache     = "read_cache"
+	subsystemLoginQueue    = "login_queue"
+	subsystemProfile       = "profile"
+	subsystemEventWatcher  = "event_watcher"
+	subsystemConnection    = "vault_connection"
+
+	// SourceChannelDropReasonClosed denotes an event dropped because the source
+	// channel was closed, e.g. on shutdown.
+	SourceChannelDropReasonClosed = "closed"
+	// SourceChannelDropReasonFull denotes an event dropped because the source
+	// channel's buffer was full.
+	SourceChannelDropReasonFull = "full"
+	// SourceChannelDropReasonCanceled denotes an event dropped because its
+	// sender's context was done before the source channel had room for it.
+	SourceChannelDropReasonCanceled = "canceled"
+
+	// ReconcileShedReasonKind denotes a reconcile request that was shed because
+	// its resource kind is low priority.
+	ReconcileShedReasonKind = "kind"
+	// ReconcileShedReasonNamespace denotes a reconcile request that was shed
+	// because its namespace is low priority.
+	ReconcileShedReasonNamespace = "namespace"
+
+	// FreezeWindowActionRotation denotes a secret rotation that was deferred by
+	// the freeze window.
+	FreezeWindowActionRotation = "rotation"
+	// FreezeWindowActionRolloutRestart denotes a rollout-restart that was
+	// deferred by the freeze window.
+	FreezeWindowActionRolloutRestart = "rollout_restart"
+
+	// ProfileSubsystemClientCache denotes the Vault client cache, including the
+	// Vault clients it holds.
+	ProfileSubsystemClientCache = "client_cache"
+	// ProfileSubsystemTemplateRendering denotes the rendering of secret data
+	// templates.
+	ProfileSubsystemTemplateRendering = "template_rendering"
+	// ProfileSubsystemEventWatchers denotes the Vault event watchers.
+	ProfileSubsystemEventWatchers = "event_watchers"
+	// ProfileSubsystemInformerCache denotes the K8s informer caches.
+	ProfileSubsystemInformerCache = "informer_cache"
+	// ProfileSubsystemReconcile denotes reconciliation work not attributed to
+	// any other subsystem.
+	ProfileSubsystemReconcile = "reconcile"
+	// ProfileSubsystemRuntimeGC denotes the Go runtime's garbage collector.
+	ProfileSubsystemRuntimeGC = "runtime_gc"
+	// ProfileSubsystemOther denotes everything not attributed to any other
+	// subsystem.
+	ProfileSubsystemOther = "other"
 )
 
 var ResourceStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -50,12 +111,521 @@ var ResourceStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 	"namespace",
 })
 
+var secretLabels = []string{
+	"controller",
+	"name",
+	"namespace",
+}
+
+// SecretLastSyncTimestamp is the time of the last successful sync of a
+// syncable secret resource.
+var SecretLastSyncTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSecret,
+	Name:      "last_sync_timestamp",
+	Help:      "Unix timestamp of the last successful sync of a syncable secret",
+}, secretLabels)
+
+// SecretNextRotationTimestamp is the time of the next scheduled sync of a
+// syncable secret resource.
+var SecretNextRotationTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSecret,
+	Name:      "next_rotation_timestamp",
+	Help:      "Unix timestamp of the next scheduled sync of a syncable secret",
+}, secretLabels)
+
+// SecretSyncDuration is the duration of each reconciliation of a syncable
+// secret resource.
+var SecretSyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSecret,
+	Name:      "sync_duration_seconds",
+	Help:      "Length of time per syncable secret reconciliation",
+	Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+}, secretLabels)
+
+// SecretSyncErrors is the total number of sync errors of a syncable secret
+// resource.
+var SecretSyncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSecret,
+	Name:      "sync_errors_total",
+	Help:      "Total number of syncable secret sync errors",
+}, secretLabels)
+
+// SecretDriftDetected is the total number of out-of-band changes detected in
+// the enforced destination Secret of a syncable secret resource.
+var SecretDriftDetected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSecret,
+	Name:      "drift_detected_total",
+	Help:      "Total number of out-of-band changes detected in an enforced destination Secret",
+}, secretLabels)
+
+// OperatorUp is the composite health of the operator, as reported in the
+// OperatorStatus resource. It is only set by the leader.
+var OperatorUp = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Name:      "up",
+	Help:      "Composite health of the operator; a value other than 1 denotes an unhealthy operator",
+})
+
+// SourceChannelEventsReceived is the total number of GenericEvents received
+// from a controller's source channel.
+var SourceChannelEventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSourceChannel,
+	Name:      "events_received_total",
+	Help:      "Total number of events received from a controller's source channel",
+}, []string{"controller"})
+
+// SourceChannelEventsDeduplicated is the total number of GenericEvents for
+// objects that already had a delayed enqueue pending.
+var SourceChannelEventsDeduplicated = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSourceChannel,
+	Name:      "events_deduplicated_total",
+	Help:      "Total number of source channel events for objects that were already pending enqueue",
+}, []string{"controller"})
+
+// SourceChannelEventsDropped is the total number of GenericEvents that were
+// never sent to a controller's source channel.
+var SourceChannelEventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSourceChannel,
+	Name:      "events_dropped_total",
+	Help:      "Total number of events dropped before reaching a controller's source channel",
+}, []string{"controller", "reason"})
+
+// SourceChannelEnqueueDelay is the jitter delay applied when enqueueing a
+// GenericEvent's object.
+var SourceChannelEnqueueDelay = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSourceChannel,
+	Name:      "enqueue_delay_seconds",
+	Help:      "Jitter delay applied when enqueueing an object from a controller's source channel",
+	Buckets:   []float64{0.1, 0.25, 0.5, 1, 1.5, 2, 5},
+}, []string{"controller"})
+
+// SourceChannelLength is the number of GenericEvents buffered in a
+// controller's source channel.
+var SourceChannelLength = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemSourceChannel,
+	Name:      NameLength,
+	Help:      "Number of events buffered in a controller's source channel",
+}, []string{"controller"})
+
+// ReconcileShed is the total number of reconcile requests that were deferred
+// by the load shedding policy.
+var ReconcileShed = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReconcile,
+	Name:      "shed_total",
+	Help:      "Total number of low priority reconcile requests deferred by load shedding",
+}, []string{"controller", "reason"})
+
+// ReconcileShedding denotes whether the load shedding policy is active for a
+// controller.
+var ReconcileShedding = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReconcile,
+	Name:      "shedding",
+	Help:      "Whether load shedding is active for a controller; a value of 1 denotes active shedding",
+}, []string{"controller"})
+
+// ReconcileStartupDeferred is the total number of initial reconcile requests
+// that were deferred to spread them over the startup sync window.
+var ReconcileStartupDeferred = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReconcile,
+	Name:      "startup_deferred_total",
+	Help:      "Total number of initial reconcile requests deferred by the startup sync window",
+}, []string{"controller"})
+
+// ReconcileQueueExpedited is the total number of reconcile requests that were
+// served ahead of the routine requests by a controller's priority queue.
+var ReconcileQueueExpedited = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReconcile,
+	Name:      "queue_expedited_total",
+	Help:      "Total number of reconcile requests expedited by the priority queue",
+}, []string{"controller"})
+
+// ReconcileQueueStarved is the total number of routine reconcile requests that
+// waited for longer than the priority queue's maximum wait, because of a
+// backlog of expedited requests.
+var ReconcileQueueStarved = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReconcile,
+	Name:      "queue_starved_total",
+	Help:      "Total number of routine reconcile requests starved by expedited requests in the priority queue",
+}, []string{"controller"})
+
+// KVReadBatchReads is the total number of KV reads sent to Vault by the KV
+// read batcher.
+var KVReadBatchReads = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemKVReadBatch,
+	Name:      "reads_total",
+	Help:      "Total number of KV reads sent to Vault by the KV read batcher",
+})
+
+// KVReadBatchCoalesced is the total number of KV reads that were served by an
+// identical read of the same batch.
+var KVReadBatchCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemKVReadBatch,
+	Name:      "coalesced_total",
+	Help:      "Total number of KV reads coalesced with an identical read by the KV read batcher",
+})
+
+// ReadCacheHits is the total number of Vault reads that were served by the
+// Vault read cache.
+var ReadCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReadCache,
+	Name:      "hits_total",
+	Help:      "Total number of Vault reads served by the Vault read cache",
+})
+
+// ReadCacheMisses is the total number of cacheable Vault reads that were not
+// found in the Vault read cache, and were sent to Vault.
+var ReadCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemReadCache,
+	Name:      "misses_total",
+	Help:      "Total number of cacheable Vault reads not found in the Vault read cache",
+})
+
+// LoginQueueDepth is the number of Vault logins waiting for a slot of their
+// auth mount's login concurrency limit.
+var LoginQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemLoginQueue,
+	Name:      "depth",
+	Help:      "Number of Vault logins waiting for a slot of their auth mount's login concurrency limit",
+}, []string{"auth_method", "auth_mount"})
+
+// LoginQueueWaitSeconds is the time Vault logins waited for a slot of their
+// auth mount's login concurrency limit.
+var LoginQueueWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemLoginQueue,
+	Name:      "wait_seconds",
+	Help:      "Time Vault logins waited for a slot of their auth mount's login concurrency limit",
+	Buckets:   []float64{0, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+}, []string{"auth_method", "auth_mount"})
+
+// FreezeWindowActive denotes whether the freeze window is active.
+var FreezeWindowActive = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemFreezeWindow,
+	Name:      "active",
+	Help:      "Whether the freeze window is active; a value of 1 denotes an active window",
+})
+
+// FreezeWindowDeferred is the total number of secret rotations and
+// rollout-restarts deferred by the freeze window.
+var FreezeWindowDeferred = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemFreezeWindow,
+	Name:      "deferred_total",
+	Help:      "Total number of secret rotations and rollout-restarts deferred until the end of the freeze window",
+}, []string{"controller", "action"})
+
+// EventWatchersActive is the number of Vault event watchers that are connected
+// to Vault.
+var EventWatchersActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemEventWatcher,
+	Name:      "active",
+	Help:      "Number of Vault event watchers connected to Vault",
+}, []string{"controller"})
+
+// EventWatcherReconnects is the total number of times that the Vault event
+// watchers reconnected to Vault.
+var EventWatcherReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemEventWatcher,
+	Name:      "reconnects_total",
+	Help:      "Total number of Vault event watcher reconnects",
+}, []string{"controller"})
+
+// VaultConnectionHealthy is the health of the Vault server of each
+// VaultConnection, as of the last Vault connection health check.
+var VaultConnectionHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemConnection,
+	Name:      "healthy",
+	Help:      "Health of the Vault server of a VaultConnection; a value other than 1 denotes an unreachable, or sealed, Vault server",
+}, []string{"name", "namespace"})
+
+// ProfileHeapInUseBytes is the estimated in-use heap memory attributed to each
+// of the operator's major subsystems.
+var ProfileHeapInUseBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemProfile,
+	Name:      "heap_inuse_bytes",
+	Help:      "Estimated in-use heap memory attributed to an operator subsystem",
+}, []string{"subsystem"})
+
+// ProfileCPUCores is the CPU usage attributed to each of the operator's major
+// subsystems during the last CPU profile.
+var ProfileCPUCores = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: Namespace,
+	Subsystem: subsystemProfile,
+	Name:      "cpu_cores",
+	Help:      "CPU cores used by an operator subsystem during the last CPU profile",
+}, []string{"subsystem"})
+
 func init() {
 	metrics.Registry.MustRegister(
 		ResourceStatus,
+		OperatorUp,
+		SecretLastSyncTimestamp,
+		SecretNextRotationTimestamp,
+		SecretSyncDuration,
+		SecretSyncErrors,
+		SecretDriftDetected,
+		SourceChannelEventsReceived,
+		SourceChannelEventsDeduplicated,
+		SourceChannelEventsDropped,
+		SourceChannelEnqueueDelay,
+		SourceChannelLength,
+		ReconcileShed,
+		ReconcileShedding,
+		ReconcileStartupDeferred,
+		ReconcileQueueExpedited,
+		ReconcileQueueStarved,
+		KVReadBatchReads,
+		KVReadBatchCoalesced,
+		ReadCacheHits,
+		ReadCacheMisses,
+		LoginQueueDepth,
+		LoginQueueWaitSeconds,
+		FreezeWindowActive,
+		FreezeWindowDeferred,
+		EventWatchersActive,
+		EventWatcherReconnects,
+		VaultConnectionHealthy,
+		ProfileHeapInUseBytes,
+		ProfileCPUCores,
 	)
 }
 
+// SetSecretLastSyncTimestamp sets the time of the last successful sync for the
+// syncable secret objKey.
+func SetSecretLastSyncTimestamp(controller string, objKey client.ObjectKey, ts time.Time) {
+	SecretLastSyncTimestamp.WithLabelValues(controller, objKey.Name, objKey.Namespace).Set(
+		float64(ts.Unix()))
+}
+
+// SetSecretNextRotationTimestamp sets the time of the next scheduled sync for
+// the syncable secret objKey.
+func SetSecretNextRotationTimestamp(controller string, objKey client.ObjectKey, ts time.Time) {
+	SecretNextRotationTimestamp.WithLabelValues(controller, objKey.Name, objKey.Namespace).Set(
+		float64(ts.Unix()))
+}
+
+// DeleteSecretNextRotationTimestamp deletes the time of the next scheduled sync
+// for the syncable secret objKey. Should be called when no sync is scheduled.
+func DeleteSecretNextRotationTimestamp(controller string, objKey client.ObjectKey) {
+	SecretNextRotationTimestamp.DeleteLabelValues(controller, objKey.Name, objKey.Namespace)
+}
+
+// ObserveSecretSyncDuration records the duration of a single reconciliation of
+// the syncable secret objKey.
+func ObserveSecretSyncDuration(controller string, objKey client.ObjectKey, d time.Duration) {
+	SecretSyncDuration.WithLabelValues(controller, objKey.Name, objKey.Namespace).Observe(
+		d.Seconds())
+}
+
+// IncSecretSyncErrors increments the sync error counter for the syncable secret
+// objKey.
+func IncSecretSyncErrors(controller string, objKey client.ObjectKey) {
+	SecretSyncErrors.WithLabelValues(controller, objKey.Name, objKey.Namespace).Inc()
+}
+
+// IncSecretDriftDetected increments the drift counter for the syncable secret
+// objKey.
+func IncSecretDriftDetected(controller string, objKey client.ObjectKey) {
+	SecretDriftDetected.WithLabelValues(controller, objKey.Name, objKey.Namespace).Inc()
+}
+
+// DeleteSecretMetrics deletes all syncable secret metrics for objKey. Should be
+// called whenever the resource has been deleted.
+func DeleteSecretMetrics(controller string, objKey client.ObjectKey) {
+	SecretLastSyncTimestamp.DeleteLabelValues(controller, objKey.Name, objKey.Namespace)
+	SecretNextRotationTimestamp.DeleteLabelValues(controller, objKey.Name, objKey.Namespace)
+	SecretSyncDuration.DeleteLabelValues(controller, objKey.Name, objKey.Namespace)
+	SecretSyncErrors.DeleteLabelValues(controller, objKey.Name, objKey.Namespace)
+	SecretDriftDetected.DeleteLabelValues(controller, objKey.Name, objKey.Namespace)
+}
+
+// ObserveSourceChannelEvent records a GenericEvent received from the source
+// channel of controller, along with the jitter delay applied when enqueueing
+// it. If deduplicated is true, the event's object already had an enqueue
+// pending.
+func ObserveSourceChannelEvent(controller string, delay time.Duration, deduplicated bool) {
+	SourceChannelEventsReceived.WithLabelValues(controller).Inc()
+	SourceChannelEnqueueDelay.WithLabelValues(controller).Observe(delay.Seconds())
+	if deduplicated {
+		SourceChannelEventsDeduplicated.WithLabelValues(controller).Inc()
+	}
+}
+
+// IncSourceChannelEventsDropped increments the dropped event counter of the
+// source channel of controller.
+func IncSourceChannelEventsDropped(controller, reason string) {
+	SourceChannelEventsDropped.WithLabelValues(controller, reason).Inc()
+}
+
+// SetSourceChannelLength sets the number of events buffered in the source
+// channel of controller.
+func SetSourceChannelLength(controller string, length int) {
+	SourceChannelLength.WithLabelValues(controller).Set(float64(length))
+}
+
+// IncReconcileShed increments the shed reconcile request counter of
+// controller.
+func IncReconcileShed(controller, reason string) {
+	ReconcileShed.WithLabelValues(controller, reason).Inc()
+}
+
+// SetReconcileShedding sets whether load shedding is active for controller.
+func SetReconcileShedding(controller string, active bool) {
+	g := ReconcileShedding.WithLabelValues(controller)
+	if active {
+		g.Set(float64(1))
+	} else {
+		g.Set(float64(0))
+	}
+}
+
+// IncReconcileStartupDeferred increments the counter of controller's initial
+// reconcile requests that were deferred by the startup sync window.
+func IncReconcileStartupDeferred(controller string) {
+	ReconcileStartupDeferred.WithLabelValues(controller).Inc()
+}
+
+// IncReconcileQueueExpedited increments the expedited request counter of
+// controller's priority queue.
+func IncReconcileQueueExpedited(controller string) {
+	ReconcileQueueExpedited.WithLabelValues(controller).Inc()
+}
+
+// IncReconcileQueueStarved increments the starved request counter of
+// controller's priority queue.
+func IncReconcileQueueStarved(controller string) {
+	ReconcileQueueStarved.WithLabelValues(controller).Inc()
+}
+
+// IncKVReadBatchReads increments the counter of KV reads sent by the KV read
+// batcher.
+func IncKVReadBatchReads() {
+	KVReadBatchReads.Inc()
+}
+
+// IncKVReadBatchCoalesced increments the counter of KV reads coalesced by the KV
+// read batcher.
+func IncKVReadBatchCoalesced() {
+	KVReadBatchCoalesced.Inc()
+}
+
+// IncReadCacheHits increments the counter of Vault reads served by the Vault
+// read cache.
+func IncReadCacheHits() {
+	ReadCacheHits.Inc()
+}
+
+// IncReadCacheMisses increments the counter of cacheable Vault reads not found in
+// the Vault read cache.
+func IncReadCacheMisses() {
+	ReadCacheMisses.Inc()
+}
+
+// IncLoginQueueDepth increments the number of Vault logins waiting for a slot
+// of the auth mount's login concurrency limit.
+func IncLoginQueueDepth(method, mount string) {
+	LoginQueueDepth.WithLabelValues(method, mount).Inc()
+}
+
+// DecLoginQueueDepth decrements the number of Vault logins waiting for a slot
+// of the auth mount's login concurrency limit.
+func DecLoginQueueDepth(method, mount string) {
+	LoginQueueDepth.WithLabelValues(method, mount).Dec()
+}
+
+// ObserveLoginQueueWait records the time a Vault login waited for a slot of the
+// auth mount's login concurrency limit.
+func ObserveLoginQueueWait(method, mount string, d time.Duration) {
+	LoginQueueWaitSeconds.WithLabelValues(method, mount).Observe(d.Seconds())
+}
+
+// SetFreezeWindowActive sets whether the freeze window is active.
+func SetFreezeWindowActive(active bool) {
+	if active {
+		FreezeWindowActive.Set(float64(1))
+	} else {
+		FreezeWindowActive.Set(float64(0))
+	}
+}
+
+// IncFreezeWindowDeferred increments the counter of the action deferred by the
+// freeze window for controller.
+func IncFreezeWindowDeferred(controller, action string) {
+	FreezeWindowDeferred.WithLabelValues(controller, action).Inc()
+}
+
+// IncEventWatchersActive increments the number of connected event watchers of
+// controller.
+func IncEventWatchersActive(controller string) {
+	EventWatchersActive.WithLabelValues(controller).Inc()
+}
+
+// DecEventWatchersActive decrements the number of connected event watchers of
+// controller.
+func DecEventWatchersActive(controller string) {
+	EventWatchersActive.WithLabelValues(controller).Dec()
+}
+
+// IncEventWatcherReconnects increments the event watcher reconnect counter of
+// controller.
+func IncEventWatcherReconnects(controller string) {
+	EventWatcherReconnects.WithLabelValues(controller).Inc()
+}
+
+// SetProfileHeapInUseBytes sets the estimated in-use heap memory attributed to
+// subsystem.
+func SetProfileHeapInUseBytes(subsystem string, bytes int64) {
+	ProfileHeapInUseBytes.WithLabelValues(subsystem).Set(float64(bytes))
+}
+
+// SetProfileCPUCores sets the CPU cores used by subsystem during the last CPU
+// profile.
+func SetProfileCPUCores(subsystem string, cores float64) {
+	ProfileCPUCores.WithLabelValues(subsystem).Set(cores)
+}
+
+// SetVaultConnectionHealthy sets the VaultConnectionHealthy gauge of the
+// VaultConnection objKey to 1 if healthy is true, else 0.
+func SetVaultConnectionHealthy(objKey client.ObjectKey, healthy bool) {
+	g := VaultConnectionHealthy.WithLabelValues(objKey.Name, objKey.Namespace)
+	if healthy {
+		g.Set(float64(1))
+	} else {
+		g.Set(float64(0))
+	}
+}
+
+// DeleteVaultConnectionHealthy deletes the VaultConnectionHealthy gauge of the
+// VaultConnection objKey.
+func DeleteVaultConnectionHealthy(objKey client.ObjectKey) {
+	VaultConnectionHealthy.DeleteLabelValues(objKey.Name, objKey.Namespace)
+}
+
 // SetResourceStatus for the given client.Object. If valid is true, then the
 // ResourceStatus gauge will be set 1, else 0.
 func SetResourceStatus(controller string, o client.Object, valid bool) {
@@ -73,6 +643,15 @@ func DeleteResourceStatus(controller string, o client.Object) {
 	ResourceStatus.DeleteLabelValues(controller, o.GetName(), o.GetNamespace())
 }
 
+// SetOperatorUp sets the OperatorUp gauge to 1 if healthy is true, else 0.
+func SetOperatorUp(healthy bool) {
+	if healthy {
+		OperatorUp.Set(float64(1))
+	} else {
+		OperatorUp.Set(float64(0))
+	}
+}
+
 // NewBuildInfoGauge provides the Operator's build info as a Prometheus metric.
 func NewBuildInfoGauge(info apimachineryversion.Info) prometheus.Gauge {
 	metric := prometheus.NewGauge(
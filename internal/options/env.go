@@ -19,16 +19,39 @@ type VSOEnvOptions struct {
 	// ClientCacheSize is the VSO_CLIENT_CACHE_SIZE environment variable option
 	ClientCacheSize *int `split_words:"true"`
 
+	// ClientCacheRevokeTokensOnEviction is the VSO_CLIENT_CACHE_REVOKE_TOKENS_ON_EVICTION
+	// environment variable option
+	ClientCacheRevokeTokensOnEviction *bool `split_words:"true"`
+
+	// ClientCachePrewarm is the VSO_CLIENT_CACHE_PREWARM environment variable
+	// option
+	ClientCachePrewarm *bool `split_words:"true"`
+
+	// ClientCacheStorageRewrapInterval is the
+	// VSO_CLIENT_CACHE_STORAGE_REWRAP_INTERVAL environment variable option
+	ClientCacheStorageRewrapInterval *time.Duration `split_words:"true"`
+
 	// ClientCachePersistenceModel is the VSO_CLIENT_CACHE_PERSISTENCE_MODEL
 	// environment variable option
 	ClientCachePersistenceModel string `split_words:"true"`
 
+	// ClientCacheStorageKMSProvider is the VSO_CLIENT_CACHE_STORAGE_KMS_PROVIDER
+	// environment variable option
+	ClientCacheStorageKMSProvider string `split_words:"true"`
+
+	// ClientCacheStorageKMSKeyID is the VSO_CLIENT_CACHE_STORAGE_KMS_KEY_ID
+	// environment variable option
+	ClientCacheStorageKMSKeyID string `split_words:"true"`
+
 	// MaxConcurrentReconciles is the VSO_MAX_CONCURRENT_RECONCILES environment variable option
 	MaxConcurrentReconciles *int `split_words:"true"`
 
 	// GlobalTransformationOptions is VSO_GLOBAL_TRANSFORMATION_OPTIONS environment variable option
 	GlobalTransformationOptions []string `split_words:"true"`
 
+	// GlobalTransformationRef is VSO_GLOBAL_TRANSFORMATION_REF environment variable option
+	GlobalTransformationRef string `split_words:"true"`
+
 	// BackoffInitialInterval is VSO_BACKOFF_INITIAL_INTERVAL environment variable option
 	BackoffInitialInterval time.Duration `split_words:"true"`
 
@@ -49,6 +72,111 @@ type VSOEnvOptions struct {
 
 	// ClientCacheNumLocks is VSO_CLIENT_CACHE_NUM_LOCKS environment variable option
 	ClientCacheNumLocks *int `split_words:"true"`
+
+	// VaultNamespaceRemap is VSO_VAULT_NAMESPACE_REMAP environment variable option
+	VaultNamespaceRemap []string `split_words:"true"`
+
+	// VaultLoginMaxConcurrency is VSO_VAULT_LOGIN_MAX_CONCURRENCY environment variable option
+	VaultLoginMaxConcurrency []string `split_words:"true"`
+
+	// AllowedVaultNamespaces is VSO_ALLOWED_VAULT_NAMESPACES environment variable option
+	AllowedVaultNamespaces []string `split_words:"true"`
+
+	// AllowedVaultPaths is VSO_ALLOWED_VAULT_PATHS environment variable option
+	AllowedVaultPaths []string `split_words:"true"`
+
+	// DeniedVaultPaths is VSO_DENIED_VAULT_PATHS environment variable option
+	DeniedVaultPaths []string `split_words:"true"`
+
+	// WatchNamespaces is VSO_WATCH_NAMESPACES environment variable option
+	WatchNamespaces []string `split_words:"true"`
+
+	// EnableConversionWebhook is VSO_ENABLE_CONVERSION_WEBHOOK environment variable option
+	EnableConversionWebhook *bool `split_words:"true"`
+
+	// OperatorStatusInterval is VSO_OPERATOR_STATUS_INTERVAL environment variable option
+	OperatorStatusInterval *time.Duration `split_words:"true"`
+
+	// FollowerMode is VSO_FOLLOWER_MODE environment variable option
+	FollowerMode *bool `split_words:"true"`
+
+	// HVSWebhookBindAddress is VSO_HVS_WEBHOOK_BIND_ADDRESS environment variable option
+	HVSWebhookBindAddress string `split_words:"true"`
+
+	// HVSWebhookHMACKey is VSO_HVS_WEBHOOK_HMAC_KEY environment variable option
+	HVSWebhookHMACKey string `split_words:"true"`
+
+	// ACMEHTTP01BindAddress is VSO_ACME_HTTP01_BIND_ADDRESS environment variable option
+	ACMEHTTP01BindAddress string `envconfig:"acme_http01_bind_address"`
+
+	// ReconcileSheddingThreshold is VSO_RECONCILE_SHEDDING_THRESHOLD environment variable option
+	ReconcileSheddingThreshold *int `split_words:"true"`
+
+	// ReconcileSheddingKinds is VSO_RECONCILE_SHEDDING_KINDS environment variable option
+	ReconcileSheddingKinds []string `split_words:"true"`
+
+	// ReconcileSheddingNamespaces is VSO_RECONCILE_SHEDDING_NAMESPACES environment variable option
+	ReconcileSheddingNamespaces []string `split_words:"true"`
+
+	// ReconcileSheddingDeferAfter is VSO_RECONCILE_SHEDDING_DEFER_AFTER environment variable option
+	ReconcileSheddingDeferAfter *time.Duration `split_words:"true"`
+
+	// FreezeWindowSchedule is VSO_FREEZE_WINDOW_SCHEDULE environment variable option
+	FreezeWindowSchedule string `split_words:"true"`
+
+	// FreezeWindowDuration is VSO_FREEZE_WINDOW_DURATION environment variable option
+	FreezeWindowDuration *time.Duration `split_words:"true"`
+
+	// FreezeWindowExemptSelector is VSO_FREEZE_WINDOW_EXEMPT_SELECTOR environment variable option
+	FreezeWindowExemptSelector string `split_words:"true"`
+
+	// ExternalSecretsCompat is VSO_EXTERNAL_SECRETS_COMPAT environment variable option
+	ExternalSecretsCompat *bool `split_words:"true"`
+
+	// ProfileInterval is VSO_PROFILE_INTERVAL environment variable option
+	ProfileInterval *time.Duration `split_words:"true"`
+
+	// ProfileCPUDuration is VSO_PROFILE_CPU_DURATION environment variable option
+	ProfileCPUDuration *time.Duration `split_words:"true"`
+
+	// ShardCount is VSO_SHARD_COUNT environment variable option
+	ShardCount *int `split_words:"true"`
+
+	// ShardIndex is VSO_SHARD_INDEX environment variable option
+	ShardIndex *int `split_words:"true"`
+
+	// StartupSyncWindow is VSO_STARTUP_SYNC_WINDOW environment variable option
+	StartupSyncWindow *time.Duration `split_words:"true"`
+
+	// StartupSyncWindowKinds is VSO_STARTUP_SYNC_WINDOW_KINDS environment variable option
+	StartupSyncWindowKinds []string `split_words:"true"`
+
+	// KVReadBatchWindow is VSO_KV_READ_BATCH_WINDOW environment variable option
+	KVReadBatchWindow *time.Duration `split_words:"true"`
+
+	// HMACKeyRotationInterval is VSO_HMAC_KEY_ROTATION_INTERVAL environment variable option
+	HMACKeyRotationInterval *time.Duration `envconfig:"hmac_key_rotation_interval"`
+
+	// VaultReadCacheTTL is VSO_VAULT_READ_CACHE_TTL environment variable option
+	VaultReadCacheTTL *time.Duration `split_words:"true"`
+
+	// SyncFailureThreshold is VSO_SYNC_FAILURE_THRESHOLD environment variable option
+	SyncFailureThreshold *int `split_words:"true"`
+
+	// SyncFailureWebhookURL is VSO_SYNC_FAILURE_WEBHOOK_URL environment variable option
+	SyncFailureWebhookURL string `envconfig:"sync_failure_webhook_url"`
+
+	// SyncFailureWebhookFormat is VSO_SYNC_FAILURE_WEBHOOK_FORMAT environment variable option
+	SyncFailureWebhookFormat string `split_words:"true"`
+
+	// LeaseRenewalBatchWindow is VSO_LEASE_RENEWAL_BATCH_WINDOW environment variable option
+	LeaseRenewalBatchWindow *time.Duration `split_words:"true"`
+
+	// VaultConnectionHealthInterval is VSO_VAULT_CONNECTION_HEALTH_INTERVAL environment variable option
+	VaultConnectionHealthInterval *time.Duration `split_words:"true"`
+
+	// VaultConnectionHealthChecks is VSO_VAULT_CONNECTION_HEALTH_CHECKS environment variable option
+	VaultConnectionHealthChecks []string `split_words:"true"`
 }
 
 // Parse environment variable options, prefixed with "VSO_"
@@ -23,32 +23,114 @@ func TestParse(t *testing.T) {
 		},
 		"set all": {
 			envs: map[string]string{
-				"VSO_OUTPUT_FORMAT":                  "json",
-				"VSO_CLIENT_CACHE_SIZE":              "100",
-				"VSO_CLIENT_CACHE_PERSISTENCE_MODEL": "memory",
-				"VSO_MAX_CONCURRENT_RECONCILES":      "10",
-				"VSO_BACKOFF_INITIAL_INTERVAL":       "1s",
-				"VSO_BACKOFF_MAX_INTERVAL":           "60s",
-				"VSO_BACKOFF_MAX_ELAPSED_TIME":       "24h",
-				"VSO_BACKOFF_RANDOMIZATION_FACTOR":   "0.5",
-				"VSO_BACKOFF_MULTIPLIER":             "2.5",
-				"VSO_GLOBAL_TRANSFORMATION_OPTIONS":  "gOpt1,gOpt2",
-				"VSO_GLOBAL_VAULT_AUTH_OPTIONS":      "vOpt1,vOpt2",
-				"VSO_CLIENT_CACHE_NUM_LOCKS":         "10",
+				"VSO_OUTPUT_FORMAT":                          "json",
+				"VSO_CLIENT_CACHE_SIZE":                      "100",
+				"VSO_CLIENT_CACHE_PERSISTENCE_MODEL":         "memory",
+				"VSO_CLIENT_CACHE_STORAGE_KMS_PROVIDER":      "aws",
+				"VSO_CLIENT_CACHE_STORAGE_KMS_KEY_ID":        "alias/vso",
+				"VSO_MAX_CONCURRENT_RECONCILES":              "10",
+				"VSO_BACKOFF_INITIAL_INTERVAL":               "1s",
+				"VSO_BACKOFF_MAX_INTERVAL":                   "60s",
+				"VSO_BACKOFF_MAX_ELAPSED_TIME":               "24h",
+				"VSO_BACKOFF_RANDOMIZATION_FACTOR":           "0.5",
+				"VSO_BACKOFF_MULTIPLIER":                     "2.5",
+				"VSO_GLOBAL_TRANSFORMATION_OPTIONS":          "gOpt1,gOpt2",
+				"VSO_GLOBAL_VAULT_AUTH_OPTIONS":              "vOpt1,vOpt2",
+				"VSO_CLIENT_CACHE_NUM_LOCKS":                 "10",
+				"VSO_CLIENT_CACHE_REVOKE_TOKENS_ON_EVICTION": "true",
+				"VSO_CLIENT_CACHE_PREWARM":                   "true",
+				"VSO_VAULT_NAMESPACE_REMAP":                  "ns1=ns2,ns3=ns4",
+				"VSO_OPERATOR_STATUS_INTERVAL":               "1m",
+				"VSO_VAULT_LOGIN_MAX_CONCURRENCY":            "kubernetes=10,default=20",
+				"VSO_ALLOWED_VAULT_NAMESPACES":               "team-a=org/team-a,*=shared",
+				"VSO_ALLOWED_VAULT_PATHS":                    "kv/apps/*,db/creds/*",
+				"VSO_DENIED_VAULT_PATHS":                     "sys/*",
+				"VSO_WATCH_NAMESPACES":                       "team-a,team-b",
+				"VSO_ENABLE_CONVERSION_WEBHOOK":              "true",
+				"VSO_FOLLOWER_MODE":                          "true",
+				"VSO_HVS_WEBHOOK_BIND_ADDRESS":               ":9444",
+				"VSO_HVS_WEBHOOK_HMAC_KEY":                   "hmac-key",
+				"VSO_ACME_HTTP01_BIND_ADDRESS":               ":8089",
+				"VSO_GLOBAL_TRANSFORMATION_REF":              "vso/global",
+				"VSO_RECONCILE_SHEDDING_THRESHOLD":           "500",
+				"VSO_RECONCILE_SHEDDING_KINDS":               "VaultStaticSecret,HCPVaultSecretsApp",
+				"VSO_RECONCILE_SHEDDING_NAMESPACES":          "dev,test",
+				"VSO_RECONCILE_SHEDDING_DEFER_AFTER":         "2m",
+				"VSO_FREEZE_WINDOW_SCHEDULE":                 "0 22 * * 5",
+				"VSO_FREEZE_WINDOW_DURATION":                 "60h",
+				"VSO_FREEZE_WINDOW_EXEMPT_SELECTOR":          "tier=critical",
+				"VSO_EXTERNAL_SECRETS_COMPAT":                "true",
+				"VSO_PROFILE_INTERVAL":                       "5m",
+				"VSO_PROFILE_CPU_DURATION":                   "15s",
+				"VSO_SHARD_COUNT":                            "4",
+				"VSO_SHARD_INDEX":                            "2",
+				"VSO_KV_READ_BATCH_WINDOW":                   "500ms",
+				"VSO_HMAC_KEY_ROTATION_INTERVAL":             "720h",
+				"VSO_VAULT_READ_CACHE_TTL":                   "30s",
+				"VSO_STARTUP_SYNC_WINDOW":                    "5m",
+				"VSO_STARTUP_SYNC_WINDOW_KINDS":              "VaultDynamicSecret=10m,VaultPKISecret=0s",
+				"VSO_SYNC_FAILURE_THRESHOLD":                 "5",
+				"VSO_SYNC_FAILURE_WEBHOOK_URL":               "https://hooks.example.com/vso",
+				"VSO_SYNC_FAILURE_WEBHOOK_FORMAT":            "slack",
+				"VSO_LEASE_RENEWAL_BATCH_WINDOW":             "30s",
+				"VSO_CLIENT_CACHE_STORAGE_REWRAP_INTERVAL":   "1h",
+				"VSO_VAULT_CONNECTION_HEALTH_INTERVAL":       "30s",
+				"VSO_VAULT_CONNECTION_HEALTH_CHECKS":         "healthz,readyz",
 			},
 			wantOptions: VSOEnvOptions{
-				OutputFormat:                "json",
-				ClientCacheSize:             ptr.To(100),
-				ClientCachePersistenceModel: "memory",
-				MaxConcurrentReconciles:     ptr.To(10),
-				BackoffInitialInterval:      time.Second * 1,
-				BackoffMaxInterval:          time.Second * 60,
-				BackoffMaxElapsedTime:       time.Hour * 24,
-				BackoffRandomizationFactor:  0.5,
-				BackoffMultiplier:           2.5,
-				GlobalTransformationOptions: []string{"gOpt1", "gOpt2"},
-				GlobalVaultAuthOptions:      []string{"vOpt1", "vOpt2"},
-				ClientCacheNumLocks:         ptr.To(10),
+				OutputFormat:                      "json",
+				ClientCacheSize:                   ptr.To(100),
+				ClientCachePersistenceModel:       "memory",
+				ClientCacheStorageKMSProvider:     "aws",
+				ClientCacheStorageKMSKeyID:        "alias/vso",
+				MaxConcurrentReconciles:           ptr.To(10),
+				BackoffInitialInterval:            time.Second * 1,
+				BackoffMaxInterval:                time.Second * 60,
+				BackoffMaxElapsedTime:             time.Hour * 24,
+				BackoffRandomizationFactor:        0.5,
+				BackoffMultiplier:                 2.5,
+				GlobalTransformationOptions:       []string{"gOpt1", "gOpt2"},
+				GlobalVaultAuthOptions:            []string{"vOpt1", "vOpt2"},
+				ClientCacheNumLocks:               ptr.To(10),
+				ClientCacheRevokeTokensOnEviction: ptr.To(true),
+				ClientCachePrewarm:                ptr.To(true),
+				VaultNamespaceRemap:               []string{"ns1=ns2", "ns3=ns4"},
+				OperatorStatusInterval:            ptr.To(time.Minute),
+				VaultLoginMaxConcurrency:          []string{"kubernetes=10", "default=20"},
+				AllowedVaultNamespaces:            []string{"team-a=org/team-a", "*=shared"},
+				AllowedVaultPaths:                 []string{"kv/apps/*", "db/creds/*"},
+				DeniedVaultPaths:                  []string{"sys/*"},
+				WatchNamespaces:                   []string{"team-a", "team-b"},
+				EnableConversionWebhook:           ptr.To(true),
+				FollowerMode:                      ptr.To(true),
+				HVSWebhookBindAddress:             ":9444",
+				HVSWebhookHMACKey:                 "hmac-key",
+				ACMEHTTP01BindAddress:             ":8089",
+				GlobalTransformationRef:           "vso/global",
+				ReconcileSheddingThreshold:        ptr.To(500),
+				ReconcileSheddingKinds:            []string{"VaultStaticSecret", "HCPVaultSecretsApp"},
+				ReconcileSheddingNamespaces:       []string{"dev", "test"},
+				ReconcileSheddingDeferAfter:       ptr.To(time.Minute * 2),
+				FreezeWindowSchedule:              "0 22 * * 5",
+				FreezeWindowDuration:              ptr.To(time.Hour * 60),
+				FreezeWindowExemptSelector:        "tier=critical",
+				ExternalSecretsCompat:             ptr.To(true),
+				ProfileInterval:                   ptr.To(time.Minute * 5),
+				ProfileCPUDuration:                ptr.To(time.Second * 15),
+				ShardCount:                        ptr.To(4),
+				ShardIndex:                        ptr.To(2),
+				KVReadBatchWindow:                 ptr.To(time.Millisecond * 500),
+				HMACKeyRotationInterval:           ptr.To(time.Hour * 720),
+				VaultReadCacheTTL:                 ptr.To(time.Second * 30),
+				StartupSyncWindow:                 ptr.To(time.Minute * 5),
+				StartupSyncWindowKinds:            []string{"VaultDynamicSecret=10m", "VaultPKISecret=0s"},
+				SyncFailureThreshold:              ptr.To(5),
+				SyncFailureWebhookURL:             "https://hooks.example.com/vso",
+				SyncFailureWebhookFormat:          "slack",
+				LeaseRenewalBatchWindow:           ptr.To(time.Second * 30),
+				ClientCacheStorageRewrapInterval:  ptr.To(time.Hour),
+				VaultConnectionHealthInterval:     ptr.To(time.Second * 30),
+				VaultConnectionHealthChecks:       []string{"healthz", "readyz"},
 			},
 		},
 	}
@@ -0,0 +1,258 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// cpuUsage profiles the CPU for duration, or until ctx is done, and returns
+// the CPU time attributed to each subsystem along with the duration of the
+// profile. It fails if a CPU profile is already in progress, e.g. one that was
+// requested from a pprof endpoint.
+func cpuUsage(ctx context.Context, duration time.Duration) (map[string]time.Duration, time.Duration, error) {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(duration)
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	timer.Stop()
+	pprof.StopCPUProfile()
+	elapsed := time.Since(start)
+
+	samples, err := decodeCPUProfile(buf.Bytes())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make(map[string]time.Duration)
+	for _, s := range samples {
+		result[attribute(s.functions)] += s.cpu
+	}
+
+	return result, elapsed, nil
+}
+
+// cpuSample is a single sample of a CPU profile.
+type cpuSample struct {
+	// functions of the sampled stack, ordered from the innermost frame outwards.
+	functions []string
+	// cpu time of the sample.
+	cpu time.Duration
+}
+
+// Field numbers of the profile.proto messages written by runtime/pprof, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	profileSampleType  protowire.Number = 1
+	profileSample      protowire.Number = 2
+	profileLocation    protowire.Number = 4
+	profileFunction    protowire.Number = 5
+	profileStringTable protowire.Number = 6
+
+	valueTypeType protowire.Number = 1
+
+	sampleLocationID protowire.Number = 1
+	sampleValue      protowire.Number = 2
+
+	locationID   protowire.Number = 1
+	locationLine protowire.Number = 4
+
+	lineFunctionID protowire.Number = 1
+
+	functionID   protowire.Number = 1
+	functionName protowire.Number = 2
+)
+
+// decodeCPUProfile decodes the gzipped CPU profile written by runtime/pprof.
+func decodeCPUProfile(data []byte) ([]cpuSample, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CPU profile: %w", err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CPU profile: %w", err)
+	}
+
+	type rawSample struct {
+		locationIDs []uint64
+		values      []uint64
+	}
+	var (
+		strs        []string
+		sampleTypes []uint64
+		rawSamples  []rawSample
+		// location ID to function IDs, ordered from the innermost inlined function.
+		locations = make(map[uint64][]uint64)
+		// function ID to its name's string table index.
+		functions = make(map[uint64]uint64)
+	)
+
+	err = walkFields(b, func(num protowire.Number, _ uint64, v []byte) error {
+		switch num {
+		case profileStringTable:
+			strs = append(strs, string(v))
+		case profileSampleType:
+			return walkFields(v, func(num protowire.Number, x uint64, _ []byte) error {
+				if num == valueTypeType {
+					sampleTypes = append(sampleTypes, x)
+				}
+				return nil
+			})
+		case profileSample:
+			var s rawSample
+			if err := walkFields(v, func(num protowire.Number, x uint64, v []byte) error {
+				var err error
+				switch num {
+				case sampleLocationID:
+					s.locationIDs, err = appendUints(s.locationIDs, x, v)
+				case sampleValue:
+					s.values, err = appendUints(s.values, x, v)
+				}
+				return err
+			}); err != nil {
+				return err
+			}
+			rawSamples = append(rawSamples, s)
+		case profileLocation:
+			var id uint64
+			var functionIDs []uint64
+			if err := walkFields(v, func(num protowire.Number, x uint64, v []byte) error {
+				switch num {
+				case locationID:
+					id = x
+				case locationLine:
+					return walkFields(v, func(num protowire.Number, x uint64, _ []byte) error {
+						if num == lineFunctionID {
+							functionIDs = append(functionIDs, x)
+						}
+						return nil
+					})
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			locations[id] = functionIDs
+		case profileFunction:
+			var id, name uint64
+			if err := walkFields(v, func(num protowire.Number, x uint64, _ []byte) error {
+				switch num {
+				case functionID:
+					id = x
+				case functionName:
+					name = x
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			functions[id] = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CPU profile: %w", err)
+	}
+
+	cpuIndex := -1
+	for i, t := range sampleTypes {
+		if t < uint64(len(strs)) && strs[t] == "cpu" {
+			cpuIndex = i
+			break
+		}
+	}
+	if cpuIndex < 0 {
+		return nil, fmt.Errorf("failed to decode CPU profile: no cpu sample type")
+	}
+
+	samples := make([]cpuSample, 0, len(rawSamples))
+	for _, s := range rawSamples {
+		if cpuIndex >= len(s.values) {
+			continue
+		}
+		sample := cpuSample{
+			cpu: time.Duration(s.values[cpuIndex]),
+		}
+		for _, locID := range s.locationIDs {
+			for _, fnID := range locations[locID] {
+				if idx, ok := functions[fnID]; ok && idx < uint64(len(strs)) {
+					sample.functions = append(sample.functions, strs[idx])
+				}
+			}
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// walkFields calls fn for each field of the protobuf encoded message b. Varint
+// fields are passed as x, length-delimited fields as v. Fields of any other
+// wire type are skipped.
+func walkFields(b []byte, fn func(num protowire.Number, x uint64, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var x uint64
+		var v []byte
+		switch typ {
+		case protowire.VarintType:
+			x, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n >= 0 {
+				b = b[n:]
+				continue
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(num, x, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendUints appends a repeated varint field, which is either the single
+// value x, or the packed values in v.
+func appendUints(s []uint64, x uint64, v []byte) ([]uint64, error) {
+	if v == nil {
+		return append(s, x), nil
+	}
+	for len(v) > 0 {
+		x, n := protowire.ConsumeVarint(v)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		s = append(s, x)
+		v = v[n:]
+	}
+	return s, nil
+}
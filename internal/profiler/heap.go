@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package profiler
+
+import (
+	"math"
+	"runtime"
+)
+
+// heapInUse returns the estimated in-use heap bytes attributed to each
+// subsystem. The estimate is taken from the runtime's sampled memory profile,
+// which reflects the heap as of the most recently completed garbage collection.
+func heapInUse() map[string]int64 {
+	var records []runtime.MemProfileRecord
+	n, _ := runtime.MemProfile(nil, false)
+	for {
+		// allow for some growth between the two calls.
+		records = make([]runtime.MemProfileRecord, n+50)
+		var ok bool
+		n, ok = runtime.MemProfile(records, false)
+		if ok {
+			records = records[:n]
+			break
+		}
+	}
+
+	result := make(map[string]int64)
+	rate := int64(runtime.MemProfileRate)
+	for _, r := range records {
+		bytes := scaleHeapSample(r.InUseObjects(), r.InUseBytes(), rate)
+		if bytes <= 0 {
+			continue
+		}
+		result[attribute(stackFunctions(r.Stack()))] += bytes
+	}
+
+	return result
+}
+
+// scaleHeapSample unbiases the sampled size of count objects, as is done by
+// runtime/pprof when writing a heap profile.
+func scaleHeapSample(count, size, rate int64) int64 {
+	if count <= 0 || size <= 0 {
+		return 0
+	}
+	if rate <= 1 {
+		return size
+	}
+
+	avgSize := float64(size) / float64(count)
+	scale := 1 / (1 - math.Exp(-avgSize/float64(rate)))
+	return int64(float64(size) * scale)
+}
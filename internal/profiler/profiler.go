@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package profiler
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// defaultCPUDuration is the default duration of each CPU profile.
+const defaultCPUDuration = time.Second * 10
+
+var (
+	_ manager.Runnable               = (*Profiler)(nil)
+	_ manager.LeaderElectionRunnable = (*Profiler)(nil)
+)
+
+// Profiler periodically attributes the operator's heap memory and CPU usage to
+// its major subsystems, e.g. the client cache, template rendering, and the
+// event watchers. The results are exposed by the vso_profile_* metrics, and
+// are summarized in the logs, so that the resource requests, limits, and cache
+// sizes of large deployments can be based on data. It is meant to be added to
+// the manager, and runs on every operator instance.
+type Profiler struct {
+	// Interval between profiles.
+	Interval time.Duration
+	// CPUDuration is the duration of each CPU profile, it is capped at the
+	// Interval. The CPU is not profiled if it is negative.
+	CPUDuration time.Duration
+}
+
+// Result of a single profile.
+type Result struct {
+	// HeapInUse is the estimated in-use heap bytes per subsystem.
+	HeapInUse map[string]int64
+	// CPUCores is the CPU cores used per subsystem, it is nil if the CPU was not
+	// profiled.
+	CPUCores map[string]float64
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (p *Profiler) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until ctx is done.
+func (p *Profiler) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("profiler")
+	ctx = log.IntoContext(ctx, logger)
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			result := p.Profile(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+			p.report(ctx, result)
+		}
+	}
+}
+
+// Profile the heap and the CPU once. CPU profiling is skipped if a CPU profile
+// is already in progress.
+func (p *Profiler) Profile(ctx context.Context) *Result {
+	result := &Result{
+		HeapInUse: heapInUse(),
+	}
+
+	if duration := p.cpuDuration(); duration > 0 {
+		usage, elapsed, err := cpuUsage(ctx, duration)
+		if err != nil {
+			log.FromContext(ctx).V(consts.LogLevelDebug).Info(
+				"Skipping CPU profile", "err", err)
+		} else if elapsed > 0 {
+			result.CPUCores = make(map[string]float64, len(usage))
+			for subsystem, cpu := range usage {
+				result.CPUCores[subsystem] = cpu.Seconds() / elapsed.Seconds()
+			}
+		}
+	}
+
+	return result
+}
+
+func (p *Profiler) cpuDuration() time.Duration {
+	duration := p.CPUDuration
+	if duration == 0 {
+		duration = defaultCPUDuration
+	}
+	if duration > p.Interval {
+		duration = p.Interval
+	}
+	return duration
+}
+
+// report sets the profile metrics from result, and logs a summary of it.
+func (p *Profiler) report(ctx context.Context, result *Result) {
+	var heapTotal int64
+	var cpuTotal float64
+	for _, subsystem := range subsystems() {
+		heapTotal += result.HeapInUse[subsystem]
+		metrics.SetProfileHeapInUseBytes(subsystem, result.HeapInUse[subsystem])
+		if result.CPUCores != nil {
+			cpuTotal += result.CPUCores[subsystem]
+			metrics.SetProfileCPUCores(subsystem, result.CPUCores[subsystem])
+		}
+	}
+
+	keysAndValues := []any{
+		"heapInUseBytes", result.HeapInUse,
+		"heapInUseBytesTotal", heapTotal,
+	}
+	if result.CPUCores != nil {
+		keysAndValues = append(keysAndValues,
+			"cpuCores", result.CPUCores,
+			"cpuCoresTotal", cpuTotal,
+		)
+	}
+	log.FromContext(ctx).Info("Resource profile", keysAndValues...)
+}
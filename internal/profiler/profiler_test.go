@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+func Test_attribute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		functions []string
+		want      string
+	}{
+		{
+			name:      "empty",
+			functions: nil,
+			want:      metrics.ProfileSubsystemOther,
+		},
+		{
+			name: "client-cache",
+			functions: []string{
+				"runtime.mallocgc",
+				"github.com/hashicorp/vault/api.NewClient",
+				modulePath + "/vault.(*defaultClient).Init",
+				modulePath + "/vault.(*cachingClientFactory).Get",
+				modulePath + "/controllers.(*VaultStaticSecretReconciler).Reconcile",
+				"sigs.k8s.io/controller-runtime/pkg/internal/controller.(*Controller).reconcileHandler",
+			},
+			want: metrics.ProfileSubsystemClientCache,
+		},
+		{
+			name: "template-rendering",
+			functions: []string{
+				"text/template.(*state).walk",
+				modulePath + "/helpers.(*SecretDataBuilder).WithVaultData",
+				modulePath + "/controllers.(*VaultStaticSecretReconciler).Reconcile",
+				"sigs.k8s.io/controller-runtime/pkg/internal/controller.(*Controller).reconcileHandler",
+			},
+			want: metrics.ProfileSubsystemTemplateRendering,
+		},
+		{
+			name: "event-watchers",
+			functions: []string{
+				"encoding/json.Unmarshal",
+				modulePath + "/controllers.(*VaultStaticSecretReconciler).streamStaticSecretEvents",
+				modulePath + "/controllers.(*VaultStaticSecretReconciler).getEvents",
+			},
+			want: metrics.ProfileSubsystemEventWatchers,
+		},
+		{
+			name: "informer-cache",
+			functions: []string{
+				"k8s.io/client-go/tools/cache.(*threadSafeMap).Add",
+				"k8s.io/client-go/tools/cache.(*Reflector).watch",
+			},
+			want: metrics.ProfileSubsystemInformerCache,
+		},
+		{
+			name: "reconcile",
+			functions: []string{
+				"encoding/json.Marshal",
+				modulePath + "/controllers.(*VaultStaticSecretReconciler).Reconcile",
+				"sigs.k8s.io/controller-runtime/pkg/internal/controller.(*Controller).reconcileHandler",
+			},
+			want: metrics.ProfileSubsystemReconcile,
+		},
+		{
+			name: "runtime-gc",
+			functions: []string{
+				"runtime.scanobject",
+				"runtime.gcDrain",
+				"runtime.gcBgMarkWorker",
+			},
+			want: metrics.ProfileSubsystemRuntimeGC,
+		},
+		{
+			name: "other",
+			functions: []string{
+				"net/http.(*conn).serve",
+			},
+			want: metrics.ProfileSubsystemOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, attribute(tt.functions))
+		})
+	}
+}
+
+func Test_scaleHeapSample(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, int64(0), scaleHeapSample(0, 0, 512*1024))
+	assert.Equal(t, int64(1024), scaleHeapSample(1, 1024, 1))
+	// small objects are less likely to be sampled, so their size is scaled up.
+	assert.Greater(t, scaleHeapSample(1, 1024, 512*1024), int64(1024))
+	// objects much larger than the sampling rate are always sampled.
+	assert.Equal(t, int64(100*1024*1024), scaleHeapSample(1, 100*1024*1024, 512*1024))
+}
+
+//go:noinline
+func burnCPU(d time.Duration) int {
+	var n int
+	for start := time.Now(); time.Since(start) < d; {
+		for i := 0; i < 1000; i++ {
+			n += i % 7
+		}
+	}
+	return n
+}
+
+func Test_decodeCPUProfile(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, pprof.StartCPUProfile(&buf))
+	burnCPU(time.Millisecond * 300)
+	pprof.StopCPUProfile()
+
+	samples, err := decodeCPUProfile(buf.Bytes())
+	require.NoError(t, err)
+	require.NotEmpty(t, samples)
+
+	var total, burned time.Duration
+	for _, s := range samples {
+		total += s.cpu
+		for _, f := range s.functions {
+			if strings.HasSuffix(f, "profiler.burnCPU") {
+				burned += s.cpu
+				break
+			}
+		}
+	}
+	assert.Greater(t, burned, time.Duration(0))
+	assert.LessOrEqual(t, burned, total)
+
+	_, err = decodeCPUProfile([]byte("invalid"))
+	assert.ErrorContains(t, err, "failed to read CPU profile")
+}
+
+func TestProfiler_Profile(t *testing.T) {
+	ctx := context.Background()
+
+	p := &Profiler{
+		Interval:    time.Second,
+		CPUDuration: -1,
+	}
+	// the heap profile is only updated by garbage collection.
+	runtime.GC()
+	result := p.Profile(ctx)
+	assert.Nil(t, result.CPUCores)
+	assert.NotEmpty(t, result.HeapInUse)
+	for subsystem := range result.HeapInUse {
+		assert.Contains(t, subsystems(), subsystem)
+	}
+
+	p.CPUDuration = time.Millisecond * 200
+	result = p.Profile(ctx)
+	require.NotNil(t, result.CPUCores)
+	for subsystem, cores := range result.CPUCores {
+		assert.Contains(t, subsystems(), subsystem)
+		assert.GreaterOrEqual(t, cores, float64(0))
+	}
+
+	// a CPU profile is already in progress.
+	var buf bytes.Buffer
+	require.NoError(t, pprof.StartCPUProfile(&buf))
+	t.Cleanup(pprof.StopCPUProfile)
+	result = p.Profile(ctx)
+	assert.Nil(t, result.CPUCores)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package profiler
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+const modulePath = "github.com/hashicorp/vault-secrets-operator"
+
+// subsystemRule attributes a stack frame to subsystem if the frame's function
+// name starts with any of the prefixes.
+type subsystemRule struct {
+	subsystem string
+	prefixes  []string
+}
+
+// subsystemRules are evaluated in order for each stack frame, starting from the
+// innermost frame. The first matching rule wins, so the work done by the
+// client cache on behalf of a reconciliation is attributed to the client cache
+// rather than to the reconciliation.
+var subsystemRules = []subsystemRule{
+	{
+		subsystem: metrics.ProfileSubsystemClientCache,
+		prefixes: []string{
+			modulePath + "/vault.(*cachingClientFactory)",
+			modulePath + "/vault.(*clientCache)",
+			modulePath + "/vault.(*defaultClientCacheStorage)",
+		},
+	},
+	{
+		subsystem: metrics.ProfileSubsystemTemplateRendering,
+		prefixes: []string{
+			modulePath + "/helpers.(*SecretDataBuilder)",
+			"text/template.",
+		},
+	},
+	{
+		subsystem: metrics.ProfileSubsystemEventWatchers,
+		prefixes: []string{
+			modulePath + "/vault.(*WebsocketClient)",
+			modulePath + "/vault.(*WebsocketConn)",
+			modulePath + "/controllers.(*VaultStaticSecretReconciler).getEvents",
+			modulePath + "/controllers.(*VaultStaticSecretReconciler).streamStaticSecretEvents",
+		},
+	},
+	{
+		subsystem: metrics.ProfileSubsystemInformerCache,
+		prefixes: []string{
+			"k8s.io/client-go/tools/cache.",
+			"sigs.k8s.io/controller-runtime/pkg/cache.",
+		},
+	},
+	{
+		subsystem: metrics.ProfileSubsystemReconcile,
+		prefixes: []string{
+			"sigs.k8s.io/controller-runtime/pkg/internal/controller.(*Controller)",
+		},
+	},
+	{
+		subsystem: metrics.ProfileSubsystemRuntimeGC,
+		prefixes: []string{
+			"runtime.gcBgMarkWorker",
+			"runtime.bgsweep",
+			"runtime.bgscavenge",
+		},
+	},
+}
+
+// subsystems returns all subsystems that work can be attributed to.
+func subsystems() []string {
+	result := make([]string, 0, len(subsystemRules)+1)
+	for _, rule := range subsystemRules {
+		result = append(result, rule.subsystem)
+	}
+	return append(result, metrics.ProfileSubsystemOther)
+}
+
+// attribute returns the subsystem of the first function in functions that
+// matches any of the subsystemRules. The functions must be ordered from the
+// innermost frame outwards.
+func attribute(functions []string) string {
+	for _, f := range functions {
+		for _, rule := range subsystemRules {
+			for _, prefix := range rule.prefixes {
+				if strings.HasPrefix(f, prefix) {
+					return rule.subsystem
+				}
+			}
+		}
+	}
+	return metrics.ProfileSubsystemOther
+}
+
+// stackFunctions returns the function names of stack, ordered from the
+// innermost frame outwards.
+func stackFunctions(stack []uintptr) []string {
+	var functions []string
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			functions = append(functions, frame.Function)
+		}
+		if !more {
+			break
+		}
+	}
+	return functions
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -20,12 +21,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 	"sigs.k8s.io/yaml"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -35,16 +42,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
 	"github.com/hashicorp/vault-secrets-operator/utils"
 	vclient "github.com/hashicorp/vault-secrets-operator/vault"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/controllers"
+	"github.com/hashicorp/vault-secrets-operator/internal/cron"
+	"github.com/hashicorp/vault-secrets-operator/internal/kms"
 	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
 	"github.com/hashicorp/vault-secrets-operator/internal/options"
+	"github.com/hashicorp/vault-secrets-operator/internal/profiler"
 	"github.com/hashicorp/vault-secrets-operator/internal/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -60,6 +72,8 @@ const (
 	defaultVaultDynamicSecretsConcurrency = 100
 	// The default MaxConcurrentReconciles for Syncable Secrets controllers.
 	defaultSyncableSecretsConcurrency = 100
+	// The interval between validations of a follower operator instance.
+	followerValidationInterval = time.Minute
 )
 
 func init() {
@@ -71,8 +85,24 @@ func init() {
 	// +kubebuilder:scaffold:scheme
 }
 
-// upgradeCRDs upgrades the CRDs in the cluster to the latest version.
+// upgradeCRDs upgrades the CRDs in the cluster to the latest version. With
+// --dry-run, the pending changes are reported to stdout and the CRDs are not
+// upgraded.
 func upgradeCRDs() error {
+	fs := flag.NewFlagSet("upgrade-crds", flag.ContinueOnError)
+	var dryRun bool
+	fs.BoolVar(&dryRun, "dry-run", false,
+		"Report the pending changes to the CRDs without upgrading them. "+
+			"Also set from environment variable VSO_UPGRADE_CRDS_DRY_RUN.")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return err
+	}
+	if v := os.Getenv("VSO_UPGRADE_CRDS_DRY_RUN"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			dryRun = b
+		}
+	}
+
 	root, err := filepath.Abs(filepath.Dir(os.Args[0]))
 	if err != nil {
 		return err
@@ -102,9 +132,56 @@ func upgradeCRDs() error {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
+	if dryRun {
+		changes, err := utils.DiffCRDs(ctx, c, filepath.Join(root, "crds"))
+		for _, change := range changes {
+			fmt.Fprintln(os.Stdout, change)
+		}
+		return err
+	}
+
 	return utils.UpgradeCRDs(ctx, c, filepath.Join(root, "crds"))
 }
 
+// importExternalSecrets writes the VSO resources that are equivalent to the
+// external-secrets.io ExternalSecrets in namespace to stdout as YAML, or creates
+// them in the cluster if apply is true.
+func importExternalSecrets(ctx context.Context, c client.Client, namespace string, apply bool) error {
+	result, err := controllers.ImportExternalSecrets(ctx, c, namespace)
+	if err != nil {
+		return err
+	}
+
+	for objKey, err := range result.Skipped {
+		os.Stderr.WriteString(fmt.Sprintf("skipped ExternalSecret %s, err=%s\n", objKey, err))
+	}
+
+	for _, o := range result.Objects {
+		kind := o.GetObjectKind().GroupVersionKind().Kind
+		if apply {
+			if err := c.Create(ctx, o); err != nil {
+				if !apierrors.IsAlreadyExists(err) {
+					return err
+				}
+				os.Stderr.WriteString(fmt.Sprintf("%s %s already exists, skipping\n",
+					kind, client.ObjectKeyFromObject(o)))
+				continue
+			}
+			os.Stdout.WriteString(fmt.Sprintf("%s %s created\n", kind, client.ObjectKeyFromObject(o)))
+			continue
+		}
+
+		b, err := yaml.Marshal(o)
+		if err != nil {
+			return err
+		}
+		os.Stdout.WriteString("---\n")
+		os.Stdout.Write(b)
+	}
+
+	return nil
+}
+
 func main() {
 	if filepath.Base(os.Args[0]) == "upgrade-crds" {
 		// If the binary is named "upgrade-crds" then we are running in a job to upgrade
@@ -133,18 +210,57 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var clientCachePersistenceModel string
+	var clientCacheStorageKMSProvider string
+	var clientCacheStorageKMSKeyID string
 	var printVersion bool
 	var outputFormat string
 	var uninstall bool
 	var preDeleteHookTimeoutSeconds int
+	var uninstallDestinationSecrets string
+	var importExternalSecretsMode bool
+	var importExternalSecretsNamespace string
+	var importExternalSecretsApply bool
 	var minRefreshAfterHVSA time.Duration
 	var globalTransformationOpts string
+	var globalTransformationRef string
 	var globalVaultAuthOpts string
+	var vaultNamespaceRemap string
+	var vaultLoginMaxConcurrency string
+	var allowedVaultNamespaces string
+	var allowedVaultPaths string
+	var deniedVaultPaths string
+	var watchNamespaces string
+	var enableConversionWebhook bool
 	var backoffInitialInterval time.Duration
 	var backoffMaxInterval time.Duration
 	var backoffRandomizationFactor float64
 	var backoffMultiplier float64
 	var backoffMaxElapsedTime time.Duration
+	var operatorStatusInterval time.Duration
+	var followerMode bool
+	var hvsWebhookBindAddress string
+	var acmeHTTP01BindAddress string
+	var reconcileSheddingThreshold int
+	var reconcileSheddingKinds string
+	var reconcileSheddingNamespaces string
+	var reconcileSheddingDeferAfter time.Duration
+	var freezeWindowSchedule string
+	var freezeWindowDuration time.Duration
+	var freezeWindowExemptSelector string
+	var externalSecretsCompat bool
+	var profileInterval time.Duration
+	var profileCPUDuration time.Duration
+	var shardCount int
+	var shardIndex int
+	var kvReadBatchWindow time.Duration
+	var startupSyncWindow time.Duration
+	var startupSyncWindowKinds string
+	var hmacKeyRotationInterval time.Duration
+	var syncFailureThreshold int
+	var syncFailureWebhookFormat string
+	var leaseRenewalBatchWindow time.Duration
+	var vaultConnectionHealthInterval time.Duration
+	var vaultConnectionHealthChecks string
 
 	// command-line args and flags
 	flag.BoolVar(&printVersion, "version", false, "Print the operator version information")
@@ -165,11 +281,39 @@ func main() {
 			"Increasing this value may improve performance during Vault client creation, but requires more memory. "+
 			"When the value is <= 0 the number of locks will be set to the number of logical CPUs of the run host. "+
 			"Also set from environment variable VSO_CLIENT_CACHE_NUM_LOCKS.")
+	flag.BoolVar(&cfc.RevokeTokensOnEviction, "client-cache-revoke-tokens-on-eviction", false,
+		"Revoke the Vault token of any client that is evicted from the client cache, "+
+			"or that remains in the cache when the operator is stopped. "+
+			"Also set from environment variable VSO_CLIENT_CACHE_REVOKE_TOKENS_ON_EVICTION.")
+	flag.BoolVar(&cfc.Prewarm, "client-cache-prewarm", false,
+		"Restore all persisted clients into the client cache, and renew their Vault tokens, "+
+			"before the first reconciliation. This avoids a login to Vault for every cached client on startup. "+
+			"Requires a client cache persistence model other than none. "+
+			"Also set from environment variable VSO_CLIENT_CACHE_PREWARM.")
+	flag.DurationVar(&cfc.StorageRewrapInterval, "client-cache-storage-rewrap-interval", 0,
+		"The interval at which the persisted clients are rewrapped with the latest version of the Vault Transit "+
+			"key used for the client cache storage encryption, so that older key versions can be trimmed "+
+			"without stranding them. Persisted clients that are encrypted with a key version below the key's "+
+			"minimum decryption version are pruned. Only applies to the direct-encrypted persistence model "+
+			"with Vault Transit encryption. Setting this to 0 disables the rewrap. "+
+			"Also set from environment variable VSO_CLIENT_CACHE_STORAGE_REWRAP_INTERVAL.")
 	flag.StringVar(&clientCachePersistenceModel, "client-cache-persistence-model", defaultPersistenceModel,
 		fmt.Sprintf(
 			"The type of client cache persistence model that should be employed. "+
 				"Also set from environment variable VSO_CLIENT_CACHE_PERSISTENCE_MODEL. "+
 				"choices=%v", []string{persistenceModelDirectUnencrypted, persistenceModelDirectEncrypted, persistenceModelNone}))
+	flag.StringVar(&clientCacheStorageKMSProvider, "client-cache-storage-kms-provider", "",
+		fmt.Sprintf(
+			"The cloud KMS provider used to wrap the client cache storage encryption key, "+
+				"instead of encrypting the cached clients with Vault Transit. "+
+				"Only used with the %s persistence model, requires -client-cache-storage-kms-key-id. "+
+				"Also set from environment variable VSO_CLIENT_CACHE_STORAGE_KMS_PROVIDER. "+
+				"choices=%v", persistenceModelDirectEncrypted, kms.Providers()))
+	flag.StringVar(&clientCacheStorageKMSKeyID, "client-cache-storage-kms-key-id", "",
+		"The ID of the cloud KMS key used to wrap the client cache storage encryption key: "+
+			"an AWS KMS key ID, ARN, or alias, a GCP KMS CryptoKey resource name, "+
+			"or an Azure Key Vault key URL. "+
+			"Also set from environment variable VSO_CLIENT_CACHE_STORAGE_KMS_KEY_ID.")
 	flag.IntVar(&vdsOptions.MaxConcurrentReconciles, "max-concurrent-reconciles-vds", defaultVaultDynamicSecretsConcurrency,
 		"Maximum number of concurrent reconciles for the VaultDynamicSecrets controller. Deprecated in favor of -max-concurrent-reconciles.")
 	flag.IntVar(&controllerOptions.MaxConcurrentReconciles, "max-concurrent-reconciles", defaultSyncableSecretsConcurrency,
@@ -178,16 +322,74 @@ func main() {
 	flag.BoolVar(&uninstall, "uninstall", false, "Run in uninstall mode")
 	flag.IntVar(&preDeleteHookTimeoutSeconds, "pre-delete-hook-timeout-seconds", 60,
 		"Pre-delete hook timeout in seconds")
+	flag.StringVar(&uninstallDestinationSecrets, "uninstall-destination-secrets", controllers.DestinationSecretsPolicyRetain,
+		fmt.Sprintf("The policy applied to the destination Secrets created by the operator in uninstall mode, "+
+			"one of %q, %q, or %q. The orphan policy retains the Secrets and labels them with %s=true.",
+			controllers.DestinationSecretsPolicyRetain, controllers.DestinationSecretsPolicyDelete,
+			controllers.DestinationSecretsPolicyOrphan, consts.LabelOrphaned))
+	flag.BoolVar(&importExternalSecretsMode, "import-external-secrets", false,
+		"Generate the VaultConnections, VaultAuths, and VaultStaticSecrets that are equivalent to the "+
+			"external-secrets.io ExternalSecrets whose SecretStore or ClusterSecretStore is backed by Vault, and exit. "+
+			"The resources are written to stdout as YAML, unless -import-external-secrets-apply is set. "+
+			"The ExternalSecrets that cannot be imported are reported on stderr.")
+	flag.StringVar(&importExternalSecretsNamespace, "import-external-secrets-namespace", "",
+		"The namespace of the ExternalSecrets to import, all namespaces if empty.")
+	flag.BoolVar(&importExternalSecretsApply, "import-external-secrets-apply", false,
+		"Create the imported resources in the cluster, rather than writing them to stdout. "+
+			"Existing resources are left unchanged.")
 	flag.DurationVar(&minRefreshAfterHVSA, "min-refresh-after-hvsa", time.Second*30,
 		"Minimum duration between HCPVaultSecretsApp resource reconciliation.")
 	flag.StringVar(&globalTransformationOpts, "global-transformation-options", "",
 		fmt.Sprintf("Set global secret transformation options as a comma delimited string. "+
 			"Also set from environment variable VSO_GLOBAL_TRANSFORMATION_OPTIONS. "+
 			"Valid values are: %v", []string{"exclude-raw"}))
+	flag.StringVar(&globalTransformationRef, "global-transformation-ref", "",
+		"Reference to a SecretTransformation, in the form of <namespace>/<name>, that is merged into "+
+			"every syncable secret's transformation. The namespace defaults to the operator's namespace. "+
+			"Also set from environment variable VSO_GLOBAL_TRANSFORMATION_REF.")
 	flag.StringVar(&globalVaultAuthOpts, "global-vault-auth-options", "allow-default-globals",
 		fmt.Sprintf("Set global vault auth options as a comma delimited string. "+
 			"Also set from environment variable VSO_GLOBAL_VAULT_AUTH_OPTIONS. "+
 			"Valid values are: %v", []string{"allow-default-globals"}))
+	flag.StringVar(&vaultNamespaceRemap, "vault-namespace-remap", "",
+		"Remap renamed Vault namespaces as a comma delimited string of old=new namespace pairs. "+
+			"Resources referencing an old Vault namespace will use the new namespace, "+
+			"without requiring new Vault clients or secret rotations. "+
+			"Also set from environment variable VSO_VAULT_NAMESPACE_REMAP.")
+	flag.StringVar(&vaultLoginMaxConcurrency, "vault-login-max-concurrency", "",
+		"The maximum number of concurrent Vault logins per auth mount as a comma delimited string of "+
+			"method=limit pairs, e.g. kubernetes=10. The default method sets the limit of all other auth methods. "+
+			"Logins in excess of the limit wait in a first-in, first-out queue, so that a mass re-authentication "+
+			"does not overwhelm the auth backend. Auth methods without a limit are not limited. "+
+			"Also set from environment variable VSO_VAULT_LOGIN_MAX_CONCURRENCY.")
+	flag.StringVar(&allowedVaultNamespaces, "allowed-vault-namespaces", "",
+		"Restrict the Vault namespaces that resources in a Kubernetes namespace may target, "+
+			"as a comma delimited string of k8s-namespace=vault-namespace pairs, e.g. team-a=org/team-a. "+
+			"Child namespaces of an allowed Vault namespace are allowed as well. "+
+			"The Kubernetes namespace * applies to all namespaces. Kubernetes namespaces without any pairs are not restricted. "+
+			"Also set from environment variable VSO_ALLOWED_VAULT_NAMESPACES.")
+	flag.StringVar(&allowedVaultPaths, "allowed-vault-paths", "",
+		"Restrict the Vault paths that the secret resources may reference to those matching "+
+			"any of the patterns in this comma delimited string, e.g. kv/apps/*,db/creds/*. "+
+			"The paths are the mount joined with the path, role, or key of a resource, "+
+			"and * matches any sequence of characters, including /. "+
+			"Resources with paths that are not allowed are not synced, and have a VaultPathDenied condition. "+
+			"Also set from environment variable VSO_ALLOWED_VAULT_PATHS.")
+	flag.StringVar(&deniedVaultPaths, "denied-vault-paths", "",
+		"Deny the Vault paths matching any of the patterns in this comma delimited string, e.g. sys/*. "+
+			"Denied paths take precedence over the allowed paths, see --allowed-vault-paths. "+
+			"Also set from environment variable VSO_DENIED_VAULT_PATHS.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Restrict the operator to the resources in the Kubernetes namespaces of this comma delimited string, "+
+			"e.g. team-a,team-b. The operator's own namespace is always watched. "+
+			"Multiple operator instances that watch distinct namespaces may run in the same cluster, "+
+			"each has its own leader election ID. All namespaces are watched if unset. "+
+			"Also set from environment variable VSO_WATCH_NAMESPACES.")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false,
+		"Serve the conversion of the custom resources between their API versions from the webhook server "+
+			"at /convert on port 9443. The webhook server's TLS certificate must be provisioned, "+
+			"and the CRDs must be configured with the Webhook conversion strategy. "+
+			"Also set from environment variable VSO_ENABLE_CONVERSION_WEBHOOK.")
 	flag.DurationVar(&backoffInitialInterval, "backoff-initial-interval", time.Second*5,
 		"Initial interval between retries on secret source errors. "+
 			"All errors are tried using an exponential backoff strategy. "+
@@ -214,6 +416,163 @@ func main() {
 			"All errors are tried using an exponential backoff strategy. "+
 			"The value must be greater than zero. "+
 			"Also set from environment variable VSO_BACKOFF_MULTIPLIER.")
+	flag.DurationVar(&operatorStatusInterval, "operator-status-interval", time.Second*30,
+		"Interval between updates of the OperatorStatus resource, and the vso_up metric, "+
+			"by the leader. Setting this to 0 disables the OperatorStatus resource. "+
+			"Also set from environment variable VSO_OPERATOR_STATUS_INTERVAL.")
+	flag.BoolVar(&followerMode, "follower-mode", false,
+		"Run the operator as a read-only follower of an active operator instance, "+
+			"e.g. in a disaster recovery cluster. A follower never modifies any resources, "+
+			"it periodically validates that it could take over syncing the secret resources "+
+			"and reports the outcome through its readiness probe. Disabling follower mode "+
+			"promotes the instance. "+
+			"Also set from environment variable VSO_FOLLOWER_MODE.")
+	flag.StringVar(&hvsWebhookBindAddress, "hvs-webhook-bind-address", "",
+		"The address the HVS webhook receiver binds to, e.g. :9444. The receiver immediately syncs "+
+			"the HCPVaultSecretsApps with instant updates enabled whenever HCP Vault Secrets notifies it "+
+			"of a change to their App. The HCP webhook's HMAC key must be set from environment "+
+			"variable VSO_HVS_WEBHOOK_HMAC_KEY. Setting this to an empty string disables the receiver. "+
+			"Also set from environment variable VSO_HVS_WEBHOOK_BIND_ADDRESS.")
+	flag.StringVar(&acmeHTTP01BindAddress, "acme-http01-bind-address", "",
+		"The address the ACME HTTP-01 solver binds to, e.g. :8089. The solver serves the "+
+			"HTTP-01 challenges of VaultPKISecrets that obtain their certificate from Vault's ACME server. "+
+			"Requests for http://<domain>/.well-known/acme-challenge/ must be routed to it. "+
+			"Setting this to an empty string disables the solver. "+
+			"Also set from environment variable VSO_ACME_HTTP01_BIND_ADDRESS.")
+	flag.IntVar(&reconcileSheddingThreshold, "reconcile-shedding-threshold", 0,
+		"The workqueue depth at which a syncable secret controller starts deferring the reconcile "+
+			"requests of low priority kinds and namespaces, so that the remaining secrets continue "+
+			"meeting their sync horizons. Setting this to 0 disables load shedding. "+
+			"Also set from environment variable VSO_RECONCILE_SHEDDING_THRESHOLD.")
+	flag.StringVar(&reconcileSheddingKinds, "reconcile-shedding-kinds", "",
+		fmt.Sprintf("Low priority resource kinds whose reconcile requests are deferred by load shedding, "+
+			"as a comma delimited string. "+
+			"Also set from environment variable VSO_RECONCILE_SHEDDING_KINDS. "+
+			"Valid values are: %v", []string{
+			controllers.VaultStaticSecret.String(),
+			controllers.VaultDynamicSecret.String(),
+			controllers.VaultPKISecret.String(),
+			controllers.VaultSSHCertificate.String(),
+			controllers.VaultTransitKey.String(),
+			controllers.HCPVaultSecretsApp.String(),
+		}))
+	flag.StringVar(&reconcileSheddingNamespaces, "reconcile-shedding-namespaces", "",
+		"Low priority namespaces whose reconcile requests are deferred by load shedding, "+
+			"as a comma delimited string. "+
+			"Also set from environment variable VSO_RECONCILE_SHEDDING_NAMESPACES.")
+	flag.DurationVar(&reconcileSheddingDeferAfter, "reconcile-shedding-defer-after", time.Minute,
+		"The duration after which a reconcile request deferred by load shedding is requeued. "+
+			"Also set from environment variable VSO_RECONCILE_SHEDDING_DEFER_AFTER.")
+	flag.StringVar(&freezeWindowSchedule, "freeze-window-schedule", "",
+		"The cron schedule of the start of each freeze window, e.g. \"0 22 * * 5\". "+
+			"During a freeze window all non-critical secret rotations and all rollout-restarts are "+
+			"deferred until the window ends. The schedule is evaluated in UTC, unless it is prefixed "+
+			"with CRON_TZ=<zone>. Setting this to an empty string disables the freeze window. "+
+			"Also set from environment variable VSO_FREEZE_WINDOW_SCHEDULE.")
+	flag.DurationVar(&freezeWindowDuration, "freeze-window-duration", 0,
+		"The duration of each freeze window, it is required when --freeze-window-schedule is set. "+
+			"Also set from environment variable VSO_FREEZE_WINDOW_DURATION.")
+	flag.StringVar(&freezeWindowExemptSelector, "freeze-window-exempt-selector", "",
+		"The label selector of the resources that are exempt from the freeze window, e.g. \"tier=critical\". "+
+			"Also set from environment variable VSO_FREEZE_WINDOW_EXEMPT_SELECTOR.")
+	flag.BoolVar(&externalSecretsCompat, "external-secrets-compat", false,
+		"Service the external-secrets.io ExternalSecrets whose SecretStore or ClusterSecretStore "+
+			"is backed by Vault, easing the migration from the external-secrets operator. "+
+			"The Vault client is set up from the VaultAuth referenced by the "+
+			consts.AnnotationVaultAuthRef+" annotation on the ExternalSecret or its store, "+
+			"or from the default VaultAuth. The option is ignored if the ExternalSecret CRD is not installed. "+
+			"Also set from environment variable VSO_EXTERNAL_SECRETS_COMPAT.")
+	flag.IntVar(&shardCount, "shard-count", 1,
+		"The number of shards the syncable secret resources are partitioned into, by a consistent hash of "+
+			"their namespace/name. Each shard is reconciled by its own active operator instance, which allows "+
+			"the operator to scale horizontally. Setting this to 1 disables sharding. "+
+			"Also set from environment variable VSO_SHARD_COUNT.")
+	flag.IntVar(&shardIndex, "shard-index", -1,
+		"The index of the shard that is reconciled by the operator instance, in the range [0, shard-count). "+
+			"Every shard has its own leader election. When the index is negative, the operator instance is assigned "+
+			"the first available shard by acquiring its lease, which allows all replicas to share the same configuration. "+
+			"Also set from environment variable VSO_SHARD_INDEX.")
+	flag.DurationVar(&kvReadBatchWindow, "kv-read-batch-window", 0,
+		"The window during which the KV reads of VaultStaticSecrets that share a Vault client and KV mount "+
+			"are collected into a batch. Identical reads of a batch are only sent to Vault once, and the distinct "+
+			"reads are sent with a bounded concurrency. This reduces the load on Vault when many VaultStaticSecrets "+
+			"are reconciled at once, e.g. after an operator restart, at the cost of delaying each read by up to the window. "+
+			"Setting this to 0 disables batching. "+
+			"Also set from environment variable VSO_KV_READ_BATCH_WINDOW.")
+	flag.DurationVar(&startupSyncWindow, "startup-sync-window", 0,
+		"The maximum window over which the initial reconciliation of the existing syncable secret resources "+
+			"is spread after the operator starts, rather than reconciling all of them at once. The window of each "+
+			"kind is proportional to its number of resources, and capped at this value. VaultDynamicSecrets that are "+
+			"past their renewal or rotation time are never deferred. Setting this to 0 disables the smearing. "+
+			"Also set from environment variable VSO_STARTUP_SYNC_WINDOW.")
+	flag.StringVar(&startupSyncWindowKinds, "startup-sync-window-kinds", "",
+		fmt.Sprintf("Overrides of the --startup-sync-window for specific resource kinds, "+
+			"as a comma delimited string of kind=duration pairs, e.g. VaultDynamicSecret=10m,VaultPKISecret=0s. "+
+			"Also set from environment variable VSO_STARTUP_SYNC_WINDOW_KINDS. "+
+			"Valid kinds are: %v", []string{
+			controllers.VaultStaticSecret.String(),
+			controllers.VaultDynamicSecret.String(),
+			controllers.VaultPKISecret.String(),
+			controllers.VaultSSHCertificate.String(),
+			controllers.VaultTransitKey.String(),
+			controllers.HCPVaultSecretsApp.String(),
+		}))
+	flag.DurationVar(&profileInterval, "profile-interval", 0,
+		"The interval between the profiles that attribute the operator's heap memory and CPU usage "+
+			"to its major subsystems, e.g. the client cache, template rendering, and the event watchers. "+
+			"The results are exposed by the vso_profile_* metrics and summarized in the logs. "+
+			"Setting this to 0 disables profiling. "+
+			"Also set from environment variable VSO_PROFILE_INTERVAL.")
+	flag.DurationVar(&profileCPUDuration, "profile-cpu-duration", time.Second*10,
+		"The duration of each CPU profile, it is capped at --profile-interval. "+
+			"Setting this to a negative value disables CPU profiling. "+
+			"Also set from environment variable VSO_PROFILE_CPU_DURATION.")
+	flag.DurationVar(&hmacKeyRotationInterval, "hmac-key-rotation-interval", 0,
+		"The interval between rotations of the HMAC key that is used to detect changes to the synced secret data. "+
+			"The last 3 replaced keys are kept for verification, so a rotation does not cause the destination Secrets "+
+			"to be synced again, or their rollout-restart targets to be restarted, as long as they are synced "+
+			"at least once every 3 intervals. "+
+			"Setting this to 0 disables the rotation. "+
+			"Also set from environment variable VSO_HMAC_KEY_ROTATION_INTERVAL.")
+	flag.IntVar(&syncFailureThreshold, "sync-failure-threshold", 0,
+		"The number of consecutive sync failures after which a syncable secret resource is marked degraded. "+
+			"A degraded resource has its SyncDegraded condition set, a SyncDegraded warning event is emitted, "+
+			"and the webhook set from environment variable VSO_SYNC_FAILURE_WEBHOOK_URL is notified, if any. "+
+			"The alert is cleared by the next successful sync. Setting this to 0 disables the alert. "+
+			"Also set from environment variable VSO_SYNC_FAILURE_THRESHOLD.")
+	flag.StringVar(&syncFailureWebhookFormat, "sync-failure-webhook-format", controllers.SyncFailureWebhookFormatGeneric,
+		fmt.Sprintf("The format of the sync failure webhook notifications. "+
+			"Also set from environment variable VSO_SYNC_FAILURE_WEBHOOK_FORMAT. "+
+			"Valid values are: %v", []string{
+			controllers.SyncFailureWebhookFormatGeneric,
+			controllers.SyncFailureWebhookFormatSlack,
+		}))
+	flag.DurationVar(&cfc.ReadCacheTTL, "vault-read-cache-ttl", 0,
+		"The duration for which the responses of identical KV reads are cached by the client factory, "+
+			"keyed by the Vault client, path, and parameters. Resources that read the same KV secret with the "+
+			"same Vault client share a single Vault read per TTL, at the cost of syncing changes to the secret "+
+			"up to the TTL later, even with instant updates. Wrapped reads are never cached. "+
+			"Setting this to 0 disables the cache. "+
+			"Also set from environment variable VSO_VAULT_READ_CACHE_TTL.")
+	flag.DurationVar(&leaseRenewalBatchWindow, "lease-renewal-batch-window", 0,
+		"Enables the renewal of the VaultDynamicSecret leases out of the reconcile loop, by a lease manager per "+
+			"Vault client. The leases of a Vault client that are due within the window of each other are renewed "+
+			"together, and a VaultDynamicSecret is only reconciled again once the renewal of its lease fails. "+
+			"This reduces the number of reconciliations when there are many VaultDynamicSecrets, at the cost of "+
+			"renewing each lease up to the window early. Shared leases are always renewed by the reconciliation. "+
+			"Setting this to 0 disables the lease manager. "+
+			"Also set from environment variable VSO_LEASE_RENEWAL_BATCH_WINDOW.")
+	flag.DurationVar(&vaultConnectionHealthInterval, "vault-connection-health-interval", 0,
+		"The interval at which the Vault server of every VaultConnection is checked for being reachable and "+
+			"unsealed. The outcome is exposed by a health gauge per VaultConnection, and cached until the next "+
+			"check. Setting this to 0 disables the checks. "+
+			"Also set from environment variable VSO_VAULT_CONNECTION_HEALTH_INTERVAL.")
+	flag.StringVar(&vaultConnectionHealthChecks, "vault-connection-health-checks", "",
+		fmt.Sprintf("Comma delimited list of the health endpoints that fail whenever the Vault server of any "+
+			"VaultConnection was unreachable, or sealed, during the last check. "+
+			"Requires --vault-connection-health-interval to be set. "+
+			"Also set from environment variable VSO_VAULT_CONNECTION_HEALTH_CHECKS. "+
+			"Valid values are: %v", controllers.VaultConnectionHealthChecks))
 
 	opts := zap.Options{
 		Development: os.Getenv("VSO_LOGGER_DEVELOPMENT_MODE") != "",
@@ -229,6 +588,12 @@ func main() {
 
 	var globalTransOptsSet []string
 	var globalVaultAuthOptsSet []string
+	var vaultNamespaceRemapSet []string
+	var vaultLoginMaxConcurrencySet []string
+	var allowedVaultNamespacesSet []string
+	var allowedVaultPathsSet []string
+	var deniedVaultPathsSet []string
+	var watchNamespacesSet []string
 	// Set options from env if any are set
 	if vsoEnvOptions.OutputFormat != "" {
 		outputFormat = vsoEnvOptions.OutputFormat
@@ -239,9 +604,27 @@ func main() {
 	if vsoEnvOptions.ClientCacheNumLocks != nil {
 		cfc.ClientCacheNumLocks = *vsoEnvOptions.ClientCacheNumLocks
 	}
+	if vsoEnvOptions.ClientCacheRevokeTokensOnEviction != nil {
+		cfc.RevokeTokensOnEviction = *vsoEnvOptions.ClientCacheRevokeTokensOnEviction
+	}
+	if vsoEnvOptions.ClientCachePrewarm != nil {
+		cfc.Prewarm = *vsoEnvOptions.ClientCachePrewarm
+	}
+	if vsoEnvOptions.ClientCacheStorageRewrapInterval != nil {
+		cfc.StorageRewrapInterval = *vsoEnvOptions.ClientCacheStorageRewrapInterval
+	}
+	if vsoEnvOptions.VaultReadCacheTTL != nil {
+		cfc.ReadCacheTTL = *vsoEnvOptions.VaultReadCacheTTL
+	}
 	if vsoEnvOptions.ClientCachePersistenceModel != "" {
 		clientCachePersistenceModel = vsoEnvOptions.ClientCachePersistenceModel
 	}
+	if vsoEnvOptions.ClientCacheStorageKMSProvider != "" {
+		clientCacheStorageKMSProvider = vsoEnvOptions.ClientCacheStorageKMSProvider
+	}
+	if vsoEnvOptions.ClientCacheStorageKMSKeyID != "" {
+		clientCacheStorageKMSKeyID = vsoEnvOptions.ClientCacheStorageKMSKeyID
+	}
 	if vsoEnvOptions.MaxConcurrentReconciles != nil {
 		controllerOptions.MaxConcurrentReconciles = *vsoEnvOptions.MaxConcurrentReconciles
 	}
@@ -250,6 +633,9 @@ func main() {
 	} else if globalTransformationOpts != "" {
 		globalTransOptsSet = strings.Split(globalTransformationOpts, ",")
 	}
+	if vsoEnvOptions.GlobalTransformationRef != "" {
+		globalTransformationRef = vsoEnvOptions.GlobalTransformationRef
+	}
 	if vsoEnvOptions.BackoffInitialInterval != 0 {
 		backoffInitialInterval = vsoEnvOptions.BackoffInitialInterval
 	}
@@ -267,6 +653,117 @@ func main() {
 	} else if globalVaultAuthOpts != "" {
 		globalVaultAuthOptsSet = strings.Split(globalVaultAuthOpts, ",")
 	}
+	if vsoEnvOptions.OperatorStatusInterval != nil {
+		operatorStatusInterval = *vsoEnvOptions.OperatorStatusInterval
+	}
+	if vsoEnvOptions.FollowerMode != nil {
+		followerMode = *vsoEnvOptions.FollowerMode
+	}
+	if vsoEnvOptions.HVSWebhookBindAddress != "" {
+		hvsWebhookBindAddress = vsoEnvOptions.HVSWebhookBindAddress
+	}
+	if vsoEnvOptions.ACMEHTTP01BindAddress != "" {
+		acmeHTTP01BindAddress = vsoEnvOptions.ACMEHTTP01BindAddress
+	}
+	if vsoEnvOptions.ReconcileSheddingThreshold != nil {
+		reconcileSheddingThreshold = *vsoEnvOptions.ReconcileSheddingThreshold
+	}
+	if len(vsoEnvOptions.ReconcileSheddingKinds) > 0 {
+		reconcileSheddingKinds = strings.Join(vsoEnvOptions.ReconcileSheddingKinds, ",")
+	}
+	if len(vsoEnvOptions.ReconcileSheddingNamespaces) > 0 {
+		reconcileSheddingNamespaces = strings.Join(vsoEnvOptions.ReconcileSheddingNamespaces, ",")
+	}
+	if vsoEnvOptions.ReconcileSheddingDeferAfter != nil {
+		reconcileSheddingDeferAfter = *vsoEnvOptions.ReconcileSheddingDeferAfter
+	}
+	if vsoEnvOptions.ShardCount != nil {
+		shardCount = *vsoEnvOptions.ShardCount
+	}
+	if vsoEnvOptions.ShardIndex != nil {
+		shardIndex = *vsoEnvOptions.ShardIndex
+	}
+	if vsoEnvOptions.StartupSyncWindow != nil {
+		startupSyncWindow = *vsoEnvOptions.StartupSyncWindow
+	}
+	if len(vsoEnvOptions.StartupSyncWindowKinds) > 0 {
+		startupSyncWindowKinds = strings.Join(vsoEnvOptions.StartupSyncWindowKinds, ",")
+	}
+	if vsoEnvOptions.KVReadBatchWindow != nil {
+		kvReadBatchWindow = *vsoEnvOptions.KVReadBatchWindow
+	}
+	if vsoEnvOptions.HMACKeyRotationInterval != nil {
+		hmacKeyRotationInterval = *vsoEnvOptions.HMACKeyRotationInterval
+	}
+	if vsoEnvOptions.SyncFailureThreshold != nil {
+		syncFailureThreshold = *vsoEnvOptions.SyncFailureThreshold
+	}
+	if vsoEnvOptions.SyncFailureWebhookFormat != "" {
+		syncFailureWebhookFormat = vsoEnvOptions.SyncFailureWebhookFormat
+	}
+	if vsoEnvOptions.LeaseRenewalBatchWindow != nil {
+		leaseRenewalBatchWindow = *vsoEnvOptions.LeaseRenewalBatchWindow
+	}
+	if vsoEnvOptions.VaultConnectionHealthInterval != nil {
+		vaultConnectionHealthInterval = *vsoEnvOptions.VaultConnectionHealthInterval
+	}
+	var vaultConnectionHealthChecksSet []string
+	if len(vsoEnvOptions.VaultConnectionHealthChecks) > 0 {
+		vaultConnectionHealthChecksSet = vsoEnvOptions.VaultConnectionHealthChecks
+	} else if vaultConnectionHealthChecks != "" {
+		vaultConnectionHealthChecksSet = strings.Split(vaultConnectionHealthChecks, ",")
+	}
+	if vsoEnvOptions.FreezeWindowSchedule != "" {
+		freezeWindowSchedule = vsoEnvOptions.FreezeWindowSchedule
+	}
+	if vsoEnvOptions.FreezeWindowDuration != nil {
+		freezeWindowDuration = *vsoEnvOptions.FreezeWindowDuration
+	}
+	if vsoEnvOptions.FreezeWindowExemptSelector != "" {
+		freezeWindowExemptSelector = vsoEnvOptions.FreezeWindowExemptSelector
+	}
+	if vsoEnvOptions.ExternalSecretsCompat != nil {
+		externalSecretsCompat = *vsoEnvOptions.ExternalSecretsCompat
+	}
+	if vsoEnvOptions.ProfileInterval != nil {
+		profileInterval = *vsoEnvOptions.ProfileInterval
+	}
+	if vsoEnvOptions.ProfileCPUDuration != nil {
+		profileCPUDuration = *vsoEnvOptions.ProfileCPUDuration
+	}
+	if len(vsoEnvOptions.VaultNamespaceRemap) > 0 {
+		vaultNamespaceRemapSet = vsoEnvOptions.VaultNamespaceRemap
+	} else if vaultNamespaceRemap != "" {
+		vaultNamespaceRemapSet = strings.Split(vaultNamespaceRemap, ",")
+	}
+	if len(vsoEnvOptions.VaultLoginMaxConcurrency) > 0 {
+		vaultLoginMaxConcurrencySet = vsoEnvOptions.VaultLoginMaxConcurrency
+	} else if vaultLoginMaxConcurrency != "" {
+		vaultLoginMaxConcurrencySet = strings.Split(vaultLoginMaxConcurrency, ",")
+	}
+	if len(vsoEnvOptions.AllowedVaultNamespaces) > 0 {
+		allowedVaultNamespacesSet = vsoEnvOptions.AllowedVaultNamespaces
+	} else if allowedVaultNamespaces != "" {
+		allowedVaultNamespacesSet = strings.Split(allowedVaultNamespaces, ",")
+	}
+	if len(vsoEnvOptions.AllowedVaultPaths) > 0 {
+		allowedVaultPathsSet = vsoEnvOptions.AllowedVaultPaths
+	} else if allowedVaultPaths != "" {
+		allowedVaultPathsSet = strings.Split(allowedVaultPaths, ",")
+	}
+	if len(vsoEnvOptions.DeniedVaultPaths) > 0 {
+		deniedVaultPathsSet = vsoEnvOptions.DeniedVaultPaths
+	} else if deniedVaultPaths != "" {
+		deniedVaultPathsSet = strings.Split(deniedVaultPaths, ",")
+	}
+	if len(vsoEnvOptions.WatchNamespaces) > 0 {
+		watchNamespacesSet = vsoEnvOptions.WatchNamespaces
+	} else if watchNamespaces != "" {
+		watchNamespacesSet = strings.Split(watchNamespaces, ",")
+	}
+	if vsoEnvOptions.EnableConversionWebhook != nil {
+		enableConversionWebhook = *vsoEnvOptions.EnableConversionWebhook
+	}
 
 	// versionInfo is used when setting up the buildInfo metric below
 	versionInfo := version.Version()
@@ -325,6 +822,21 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if globalTransformationRef != "" {
+		ns, name := common.OperatorNamespace, globalTransformationRef
+		if parts := strings.Split(globalTransformationRef, "/"); len(parts) == 2 {
+			ns, name = parts[0], parts[1]
+		}
+		if ns == "" || name == "" || strings.Contains(name, "/") {
+			setupLog.Error(fmt.Errorf("invalid SecretTransformation reference %q", globalTransformationRef),
+				"Invalid argument for --global-transformation-ref")
+			os.Exit(1)
+		}
+		globalTransOptions.TransformationRef = &secretsv1beta1.TransformationRef{
+			Namespace: ns,
+			Name:      name,
+		}
+	}
 
 	globalVaultAuthOptions := &common.GlobalVaultAuthOptions{}
 	for _, v := range globalVaultAuthOptsSet {
@@ -339,6 +851,41 @@ func main() {
 	}
 	cfc.GlobalVaultAuthOptions = globalVaultAuthOptions
 
+	namespaceRemap, err := common.ParseNamespaceRemap(vaultNamespaceRemapSet)
+	if err != nil {
+		setupLog.Error(err, "Invalid argument for --vault-namespace-remap")
+		os.Exit(1)
+	}
+	cfc.NamespaceRemap = namespaceRemap
+
+	loginMaxConcurrency, err := vclient.ParseLoginMaxConcurrency(vaultLoginMaxConcurrencySet)
+	if err != nil {
+		setupLog.Error(err, "Invalid argument for --vault-login-max-concurrency")
+		os.Exit(1)
+	}
+	cfc.LoginMaxConcurrency = loginMaxConcurrency
+
+	allowedVaultNamespacesMap, err := common.ParseAllowedVaultNamespaces(allowedVaultNamespacesSet)
+	if err != nil {
+		setupLog.Error(err, "Invalid argument for --allowed-vault-namespaces")
+		os.Exit(1)
+	}
+	cfc.AllowedVaultNamespaces = allowedVaultNamespacesMap
+
+	vaultPathPolicy := common.NewVaultPathPolicy(allowedVaultPathsSet, deniedVaultPathsSet)
+
+	if followerMode {
+		// a follower never modifies any resources, so it must not compete with the
+		// active operator instance for the leader lease.
+		enableLeaderElection = false
+	}
+
+	if hvsWebhookBindAddress != "" && vsoEnvOptions.HVSWebhookHMACKey == "" {
+		setupLog.Error(errors.New("invalid option"),
+			"The VSO_HVS_WEBHOOK_HMAC_KEY environment variable must be set when the HVS webhook receiver is enabled")
+		os.Exit(1)
+	}
+
 	config := ctrl.GetConfigOrDie()
 
 	defaultClient, err := client.NewWithWatch(config, client.Options{
@@ -365,9 +912,75 @@ func main() {
 			os.Exit(1)
 		}
 
+		cleanupLog.Info("cleaning up destination secrets", "policy", uninstallDestinationSecrets)
+		if err = controllers.CleanupDestinationSecrets(preDeleteDeadlineCtx, defaultClient, cleanupLog,
+			uninstallDestinationSecrets); err != nil {
+			cleanupLog.Error(err, "unable to clean up destination secrets")
+			os.Exit(1)
+		}
+
 		os.Exit(0)
 	}
 
+	if importExternalSecretsMode {
+		var exitCode int
+		if err := importExternalSecrets(context.Background(), defaultClient,
+			importExternalSecretsNamespace, importExternalSecretsApply); err != nil {
+			exitCode = 1
+			os.Stderr.WriteString(fmt.Sprintf("failed to import ExternalSecrets, err=%s\n", err))
+		}
+		os.Exit(exitCode)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	watchNamespacesSet = common.WatchNamespaces(common.OperatorNamespace, watchNamespacesSet)
+	// operator instances that watch distinct namespaces must never compete for
+	// the same leader lease.
+	leaderElectionID := common.ScopedLeaderElectionID("b0d477c0.hashicorp.com", watchNamespacesSet)
+	var shard *controllers.Shard
+	if shardCount > 1 && !followerMode {
+		if shardIndex >= 0 {
+			shard = &controllers.Shard{
+				Index: shardIndex,
+				Count: shardCount,
+			}
+			if err := shard.Validate(); err != nil {
+				setupLog.Error(err, "Invalid argument for --shard-index")
+				os.Exit(1)
+			}
+			// every shard has its own leader, so that a shard may have standby replicas.
+			leaderElectionID = fmt.Sprintf("%s-shard-%d", leaderElectionID, shardIndex)
+		} else {
+			identity, err := os.Hostname()
+			if err != nil {
+				setupLog.Error(err, "Unable to get the operator identity")
+				os.Exit(1)
+			}
+			setupLog.Info("Waiting for a shard lease", "shardCount", shardCount)
+			shard, err = (&controllers.ShardAssigner{
+				Config:      config,
+				Namespace:   common.OperatorNamespace,
+				LeasePrefix: leaderElectionID + "-shard",
+				Identity:    identity + "_" + string(uuid.NewUUID()),
+				Count:       shardCount,
+				OnLost: func() {
+					setupLog.Error(errors.New("shard lease lost"), "Exiting", "shard", shard.String())
+					os.Exit(1)
+				},
+			}).Assign(ctx)
+			if err != nil {
+				setupLog.Error(err, "Unable to acquire a shard lease")
+				os.Exit(1)
+			}
+			// the shard lease ensures that there is only one active operator instance per shard.
+			enableLeaderElection = false
+		}
+	} else if shardCount < 1 {
+		setupLog.Error(fmt.Errorf("invalid shard count %d", shardCount), "Invalid argument for --shard-count")
+		os.Exit(1)
+	}
+
 	collectMetrics := metricsAddr != ""
 	if collectMetrics {
 		cfc.MetricsRegistry.MustRegister(
@@ -382,16 +995,19 @@ func main() {
 				Name:      "config",
 				Help:      "Vault Secrets Operator runtime config.",
 				ConstLabels: map[string]string{
-					"backoffInitialInterval":      backoffInitialInterval.String(),
-					"backoffMaxInterval":          backoffMaxInterval.String(),
-					"backoffMaxElapsedTime":       backoffMaxElapsedTime.String(),
-					"backoffMultiplier":           fmt.Sprintf("%.2f", backoffMultiplier),
-					"backoffRandomizationFactor":  fmt.Sprintf("%.2f", backoffRandomizationFactor),
-					"clientCachePersistenceModel": clientCachePersistenceModel,
-					"clientCacheSize":             strconv.Itoa(cfc.ClientCacheSize),
-					"globalTransformationOptions": globalTransformationOpts,
-					"globalVaultAuthOptions":      globalVaultAuthOpts,
-					"maxConcurrentReconciles":     strconv.Itoa(controllerOptions.MaxConcurrentReconciles),
+					"backoffInitialInterval":            backoffInitialInterval.String(),
+					"backoffMaxInterval":                backoffMaxInterval.String(),
+					"backoffMaxElapsedTime":             backoffMaxElapsedTime.String(),
+					"backoffMultiplier":                 fmt.Sprintf("%.2f", backoffMultiplier),
+					"backoffRandomizationFactor":        fmt.Sprintf("%.2f", backoffRandomizationFactor),
+					"clientCachePersistenceModel":       clientCachePersistenceModel,
+					"clientCacheSize":                   strconv.Itoa(cfc.ClientCacheSize),
+					"clientCacheRevokeTokensOnEviction": strconv.FormatBool(cfc.RevokeTokensOnEviction),
+					"globalTransformationOptions":       globalTransformationOpts,
+					"globalTransformationRef":           globalTransformationRef,
+					"globalVaultAuthOptions":            globalVaultAuthOpts,
+					"maxConcurrentReconciles":           strconv.Itoa(controllerOptions.MaxConcurrentReconciles),
+					"watchNamespaces":                   strings.Join(watchNamespacesSet, ","),
 				},
 			},
 		)
@@ -399,8 +1015,18 @@ func main() {
 		cfc.MetricsRegistry.MustRegister(metric)
 	}
 
+	var cacheOpts cache.Options
+	if len(watchNamespacesSet) > 0 {
+		cacheOpts.DefaultNamespaces = make(map[string]cache.Config, len(watchNamespacesSet))
+		for _, ns := range watchNamespacesSet {
+			cacheOpts.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
+	syncStatusRegistry := controllers.NewSyncStatusRegistry()
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOpts,
 		Client: client.Options{
 			Cache: &client.CacheOptions{
 				// disable caching of K8s Secrets to avoid OOM issues. Caching is not needed for
@@ -412,11 +1038,15 @@ func main() {
 		},
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
+			// serve the aggregated sync status of all syncable secret resources.
+			ExtraHandlers: map[string]http.Handler{
+				"/status": syncStatusRegistry,
+			},
 		},
 		WebhookServer:          webhook.NewServer(webhook.Options{Port: 9443}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "b0d477c0.hashicorp.com",
+		LeaderElectionID:       leaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -433,26 +1063,59 @@ func main() {
 		setupLog.Error(err, "Unable to start manager")
 		os.Exit(1)
 	}
-	ctx := ctrl.SetupSignalHandler()
+
+	if syncFailureThreshold > 0 {
+		syncStatusRegistry.FailureAlert = &controllers.SyncFailureAlert{
+			Client:        mgr.GetClient(),
+			Threshold:     syncFailureThreshold,
+			WebhookURL:    vsoEnvOptions.SyncFailureWebhookURL,
+			WebhookFormat: syncFailureWebhookFormat,
+		}
+		if err := syncStatusRegistry.FailureAlert.Validate(); err != nil {
+			setupLog.Error(err, "Invalid argument for --sync-failure-webhook-format")
+			os.Exit(1)
+		}
+	}
+
+	if enableConversionWebhook {
+		// v1beta1 is the conversion hub, see api/v1beta1/conversion.go.
+		mgr.GetWebhookServer().Register("/convert", conversion.NewWebhookHandler(mgr.GetScheme()))
+	}
 
 	var clientFactory vclient.CachingClientFactory
 	{
-		switch clientCachePersistenceModel {
-		case persistenceModelDirectUnencrypted:
-			cfc.Persist = true
-		case persistenceModelDirectEncrypted:
-			cfc.Persist = true
-			cfc.StorageConfig.EnforceEncryption = true
-		case persistenceModelNone:
-			cfc.Persist = false
-		default:
-			setupLog.Error(errors.New("invalid option"),
-				fmt.Sprintf("Invalid cache persistence model %q", clientCachePersistenceModel))
-			os.Exit(1)
+		if followerMode {
+			// the client cache storage belongs to the active operator instance, a
+			// follower must neither persist nor purge any cached clients.
+			cfc.ReadOnly = true
+		} else {
+			switch clientCachePersistenceModel {
+			case persistenceModelDirectUnencrypted:
+				cfc.Persist = true
+			case persistenceModelDirectEncrypted:
+				cfc.Persist = true
+				cfc.StorageConfig.EnforceEncryption = true
+				if clientCacheStorageKMSProvider != "" {
+					wrapper, err := kms.NewWrapper(ctx, clientCacheStorageKMSProvider, clientCacheStorageKMSKeyID)
+					if err != nil {
+						setupLog.Error(err, "Failed to setup the client cache storage KMS")
+						os.Exit(1)
+					}
+					cfc.StorageConfig.KMS = wrapper
+				}
+			case persistenceModelNone:
+				cfc.Persist = false
+			default:
+				setupLog.Error(errors.New("invalid option"),
+					fmt.Sprintf("Invalid cache persistence model %q", clientCachePersistenceModel))
+				os.Exit(1)
+			}
 		}
 
 		cfc.CollectClientCacheMetrics = collectMetrics
-		cfc.Recorder = mgr.GetEventRecorderFor("vaultClientFactory")
+		if !followerMode {
+			cfc.Recorder = mgr.GetEventRecorderFor("vaultClientFactory")
+		}
 		clientFactory, err = vclient.InitCachingClientFactory(ctx, defaultClient, cfc)
 		if err != nil {
 			setupLog.Error(err, "Failed to setup the Vault ClientFactory")
@@ -460,9 +1123,96 @@ func main() {
 		}
 	}
 
+	var shedder *controllers.ReconcileShedder
+	if reconcileSheddingThreshold > 0 {
+		shedder = &controllers.ReconcileShedder{
+			Gatherer:   ctrlmetrics.Registry,
+			Threshold:  reconcileSheddingThreshold,
+			DeferAfter: reconcileSheddingDeferAfter,
+		}
+		for _, v := range strings.Split(reconcileSheddingKinds, ",") {
+			if v == "" {
+				continue
+			}
+			kind, err := controllers.ParseResourceKind(strings.TrimSpace(v))
+			if err != nil {
+				setupLog.Error(err, "Invalid argument for --reconcile-shedding-kinds")
+				os.Exit(1)
+			}
+			shedder.Kinds = append(shedder.Kinds, kind)
+		}
+		for _, v := range strings.Split(reconcileSheddingNamespaces, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				shedder.Namespaces = append(shedder.Namespaces, v)
+			}
+		}
+	}
+
+	var startupSyncSmear *controllers.StartupSyncSmear
+	if startupSyncWindow > 0 || startupSyncWindowKinds != "" {
+		kindWindows, err := controllers.ParseKindWindows(strings.Split(startupSyncWindowKinds, ","))
+		if err != nil {
+			setupLog.Error(err, "Invalid argument for --startup-sync-window-kinds")
+			os.Exit(1)
+		}
+		startupSyncSmear = &controllers.StartupSyncSmear{
+			Reader:      mgr.GetAPIReader(),
+			StartTime:   startTime,
+			Window:      startupSyncWindow,
+			KindWindows: kindWindows,
+		}
+	}
+
+	var freezeWindow *controllers.FreezeWindow
+	if freezeWindowSchedule != "" {
+		schedule, err := cron.Parse(freezeWindowSchedule)
+		if err != nil {
+			setupLog.Error(err, "Invalid argument for --freeze-window-schedule")
+			os.Exit(1)
+		}
+		if freezeWindowDuration <= 0 {
+			setupLog.Error(errors.New("must be greater than 0"),
+				"Invalid argument for --freeze-window-duration")
+			os.Exit(1)
+		}
+		exemptSelector, err := labels.Parse(freezeWindowExemptSelector)
+		if err != nil {
+			setupLog.Error(err, "Invalid argument for --freeze-window-exempt-selector")
+			os.Exit(1)
+		}
+		freezeWindow = &controllers.FreezeWindow{
+			Schedule:       schedule,
+			Duration:       freezeWindowDuration,
+			ExemptSelector: exemptSelector,
+		}
+	}
+
+	readyzCheck := healthz.Ping
+	if followerMode {
+		validator := &controllers.FollowerValidator{
+			Client:        mgr.GetClient(),
+			ClientFactory: clientFactory,
+			Interval:      followerValidationInterval,
+		}
+		if err := mgr.Add(validator); err != nil {
+			setupLog.Error(err, "Unable to set up the follower validator")
+			os.Exit(1)
+		}
+		readyzCheck = validator.Ready
+		// a follower never modifies any resources, so none of the controllers and
+		// runnables that are set up below are added to the manager.
+		mgr = &followerManager{Manager: mgr}
+	}
+
 	hmacValidator := helpers.NewHMACValidator(cfc.StorageConfig.HMACSecretObjKey)
 	secretDataBuilder := helpers.NewSecretsDataBuilder()
-	if err = (&controllers.VaultStaticSecretReconciler{
+	var kvReadBatcher *controllers.KVReadBatcher
+	if kvReadBatchWindow > 0 {
+		kvReadBatcher = &controllers.KVReadBatcher{
+			Window: kvReadBatchWindow,
+		}
+	}
+	vssReconciler := &controllers.VaultStaticSecretReconciler{
 		Client:                      mgr.GetClient(),
 		Scheme:                      mgr.GetScheme(),
 		Recorder:                    mgr.GetEventRecorderFor("VaultStaticSecret"),
@@ -470,11 +1220,31 @@ func main() {
 		HMACValidator:               hmacValidator,
 		ClientFactory:               clientFactory,
 		BackOffRegistry:             controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry:          syncStatusRegistry,
 		GlobalTransformationOptions: globalTransOptions,
-	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		NamespaceRemap:              namespaceRemap,
+		Shedder:                     shedder,
+		FreezeWindow:                freezeWindow,
+		VaultPathPolicy:             vaultPathPolicy,
+		Shard:                       shard,
+		StartupSyncSmear:            startupSyncSmear,
+		KVReadBatcher:               kvReadBatcher,
+		SyncRegistry:                controllers.NewSyncRegistry(),
+	}
+	if err = vssReconciler.SetupWithManager(mgr, controllerOptions); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "VaultStaticSecret")
 		os.Exit(1)
 	}
+	var acmeHTTP01Solver *controllers.ACMEHTTP01Solver
+	if acmeHTTP01BindAddress != "" {
+		acmeHTTP01Solver = &controllers.ACMEHTTP01Solver{
+			BindAddress: acmeHTTP01BindAddress,
+		}
+		if err := mgr.Add(acmeHTTP01Solver); err != nil {
+			setupLog.Error(err, "Unable to set up the ACME HTTP-01 solver")
+			os.Exit(1)
+		}
+	}
 	if err = (&controllers.VaultPKISecretReconciler{
 		Client:                      mgr.GetClient(),
 		Scheme:                      mgr.GetScheme(),
@@ -483,11 +1253,71 @@ func main() {
 		SyncRegistry:                controllers.NewSyncRegistry(),
 		Recorder:                    mgr.GetEventRecorderFor("VaultPKISecret"),
 		BackOffRegistry:             controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry:          syncStatusRegistry,
 		GlobalTransformationOptions: globalTransOptions,
+		ACMEHTTP01Solver:            acmeHTTP01Solver,
+		Shedder:                     shedder,
+		FreezeWindow:                freezeWindow,
+		VaultPathPolicy:             vaultPathPolicy,
+		Shard:                       shard,
+		StartupSyncSmear:            startupSyncSmear,
 	}).SetupWithManager(mgr, controllerOptions); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "VaultPKISecret")
 		os.Exit(1)
 	}
+	if err = (&controllers.VaultSSHCertificateReconciler{
+		Client:                      mgr.GetClient(),
+		Scheme:                      mgr.GetScheme(),
+		ClientFactory:               clientFactory,
+		HMACValidator:               hmacValidator,
+		SyncRegistry:                controllers.NewSyncRegistry(),
+		Recorder:                    mgr.GetEventRecorderFor("VaultSSHCertificate"),
+		BackOffRegistry:             controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry:          syncStatusRegistry,
+		GlobalTransformationOptions: globalTransOptions,
+		Shedder:                     shedder,
+		FreezeWindow:                freezeWindow,
+		VaultPathPolicy:             vaultPathPolicy,
+		Shard:                       shard,
+		StartupSyncSmear:            startupSyncSmear,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "Unable to create controller", "controller", "VaultSSHCertificate")
+		os.Exit(1)
+	}
+	if err = (&controllers.VaultTransitKeyReconciler{
+		Client:                      mgr.GetClient(),
+		Scheme:                      mgr.GetScheme(),
+		ClientFactory:               clientFactory,
+		HMACValidator:               hmacValidator,
+		SyncRegistry:                controllers.NewSyncRegistry(),
+		Recorder:                    mgr.GetEventRecorderFor("VaultTransitKey"),
+		BackOffRegistry:             controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry:          syncStatusRegistry,
+		GlobalTransformationOptions: globalTransOptions,
+		Shedder:                     shedder,
+		FreezeWindow:                freezeWindow,
+		VaultPathPolicy:             vaultPathPolicy,
+		Shard:                       shard,
+		StartupSyncSmear:            startupSyncSmear,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "Unable to create controller", "controller", "VaultTransitKey")
+		os.Exit(1)
+	}
+	if err = (&controllers.VaultSecretExportReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		ClientFactory:      clientFactory,
+		HMACValidator:      hmacValidator,
+		SyncRegistry:       controllers.NewSyncRegistry(),
+		Recorder:           mgr.GetEventRecorderFor("VaultSecretExport"),
+		BackOffRegistry:    controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry: syncStatusRegistry,
+		Shard:              shard,
+		VaultPathPolicy:    vaultPathPolicy,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "Unable to create controller", "controller", "VaultSecretExport")
+		os.Exit(1)
+	}
 	if err = (&controllers.VaultAuthReconciler{
 		Client:                 mgr.GetClient(),
 		Scheme:                 mgr.GetScheme(),
@@ -498,6 +1328,15 @@ func main() {
 		setupLog.Error(err, "Unable to create controller", "controller", "VaultAuth")
 		os.Exit(1)
 	}
+	if err = (&controllers.VaultEventSubscriptionReconciler{
+		Client:                 mgr.GetClient(),
+		Scheme:                 mgr.GetScheme(),
+		Recorder:               mgr.GetEventRecorderFor("VaultEventSubscription"),
+		GlobalVaultAuthOptions: globalVaultAuthOptions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Unable to create controller", "controller", "VaultEventSubscription")
+		os.Exit(1)
+	}
 	if err = (&controllers.VaultConnectionReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
@@ -519,6 +1358,12 @@ func main() {
 		vdsOverrideOpts = controllerOptions
 	}
 
+	var leaseManager *controllers.LeaseManager
+	if leaseRenewalBatchWindow > 0 {
+		leaseManager = &controllers.LeaseManager{
+			BatchWindow: leaseRenewalBatchWindow,
+		}
+	}
 	vdsReconciler := &controllers.VaultDynamicSecretReconciler{
 		Client:                      mgr.GetClient(),
 		Scheme:                      mgr.GetScheme(),
@@ -527,7 +1372,15 @@ func main() {
 		HMACValidator:               hmacValidator,
 		SyncRegistry:                controllers.NewSyncRegistry(),
 		BackOffRegistry:             controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry:          syncStatusRegistry,
 		GlobalTransformationOptions: globalTransOptions,
+		NamespaceRemap:              namespaceRemap,
+		Shedder:                     shedder,
+		FreezeWindow:                freezeWindow,
+		VaultPathPolicy:             vaultPathPolicy,
+		Shard:                       shard,
+		StartupSyncSmear:            startupSyncSmear,
+		LeaseManager:                leaseManager,
 	}
 	if err = vdsReconciler.SetupWithManager(mgr, vdsOverrideOpts); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "VaultDynamicSecret")
@@ -546,7 +1399,7 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "HCPAuth")
 		os.Exit(1)
 	}
-	if err = (&controllers.HCPVaultSecretsAppReconciler{
+	hvsaReconciler := &controllers.HCPVaultSecretsAppReconciler{
 		Client:                      mgr.GetClient(),
 		Scheme:                      mgr.GetScheme(),
 		Recorder:                    mgr.GetEventRecorderFor("HCPVaultSecretsApp"),
@@ -554,11 +1407,41 @@ func main() {
 		HMACValidator:               hmacValidator,
 		MinRefreshAfter:             minRefreshAfterHVSA,
 		BackOffRegistry:             controllers.NewBackOffRegistry(backoffOpts...),
+		SyncStatusRegistry:          syncStatusRegistry,
 		GlobalTransformationOptions: globalTransOptions,
-	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		Shedder:                     shedder,
+		FreezeWindow:                freezeWindow,
+		Shard:                       shard,
+		StartupSyncSmear:            startupSyncSmear,
+		SyncRegistry:                controllers.NewSyncRegistry(),
+	}
+	if err = hvsaReconciler.SetupWithManager(mgr, controllerOptions); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HCPVaultSecretsApp")
 		os.Exit(1)
 	}
+	if hvsWebhookBindAddress != "" {
+		if err := mgr.Add(&controllers.HVSWebhookReceiver{
+			Client:      mgr.GetClient(),
+			BindAddress: hvsWebhookBindAddress,
+			HMACKey:     []byte(vsoEnvOptions.HVSWebhookHMACKey),
+			SourceCh:    hvsaReconciler.SourceCh,
+			Elected:     mgr.Elected(),
+		}); err != nil {
+			setupLog.Error(err, "Unable to set up the HVS webhook receiver")
+			os.Exit(1)
+		}
+	}
+	if err = (&controllers.HCPVaultSecretsProjectReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("HCPVaultSecretsProject"),
+		MinRefreshAfter: minRefreshAfterHVSA,
+		BackOffRegistry: controllers.NewBackOffRegistry(backoffOpts...),
+		Shard:           shard,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HCPVaultSecretsProject")
+		os.Exit(1)
+	}
 	if err = (&controllers.SecretTransformationReconciler{
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
@@ -574,17 +1457,132 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "VaultAuthGlobal")
 		os.Exit(1)
 	}
+	if externalSecretsCompat {
+		installed, err := controllers.ExternalSecretCRDInstalled(mgr.GetRESTMapper())
+		if err != nil {
+			setupLog.Error(err, "Unable to check for the ExternalSecret CRD")
+			os.Exit(1)
+		}
+		if installed {
+			if err = (&controllers.ExternalSecretReconciler{
+				Client:          mgr.GetClient(),
+				Scheme:          mgr.GetScheme(),
+				Recorder:        mgr.GetEventRecorderFor("ExternalSecret"),
+				ClientFactory:   clientFactory,
+				BackOffRegistry: controllers.NewBackOffRegistry(backoffOpts...),
+				Shard:           shard,
+			}).SetupWithManager(mgr, controllerOptions); err != nil {
+				setupLog.Error(err, "Unable to create controller", "controller", "ExternalSecret")
+				os.Exit(1)
+			}
+		} else {
+			setupLog.Info("The ExternalSecret CRD is not installed, ignoring --external-secrets-compat")
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if shedder != nil {
+		if err := mgr.Add(shedder); err != nil {
+			setupLog.Error(err, "Unable to set up the reconcile shedder")
+			os.Exit(1)
+		}
+	}
+
+	if freezeWindow != nil {
+		if err := mgr.Add(freezeWindow); err != nil {
+			setupLog.Error(err, "Unable to set up the freeze window")
+			os.Exit(1)
+		}
+	}
+
+	// the HMAC key is shared by all shards, so it is only rotated by the first shard.
+	if hmacKeyRotationInterval > 0 && (shard == nil || shard.Index == 0) {
+		if err := mgr.Add(&controllers.HMACKeyRotator{
+			Client:   defaultClient,
+			ObjKey:   cfc.StorageConfig.HMACSecretObjKey,
+			Interval: hmacKeyRotationInterval,
+		}); err != nil {
+			setupLog.Error(err, "Unable to set up the HMAC key rotator")
+			os.Exit(1)
+		}
+	}
+
+	// the OperatorStatus is only reported by the first shard, since it is a single resource.
+	if operatorStatusInterval > 0 && (shard == nil || shard.Index == 0) {
+		identity, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "Unable to get the operator identity")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controllers.OperatorStatusReporter{
+			Client:                mgr.GetClient(),
+			ClientFactory:         clientFactory,
+			SyncStatusRegistry:    syncStatusRegistry,
+			Gatherer:              ctrlmetrics.Registry,
+			EventWatcherCountFunc: vssReconciler.EventWatcherCount,
+			Identity:              identity,
+			Interval:              operatorStatusInterval,
+		}); err != nil {
+			setupLog.Error(err, "Unable to set up the operator status reporter")
+			os.Exit(1)
+		}
+	}
+
+	if m, ok := mgr.(*followerManager); ok {
+		mgr = m.Manager
+	}
+
+	if profileInterval > 0 {
+		if err := mgr.Add(&profiler.Profiler{
+			Interval:    profileInterval,
+			CPUDuration: profileCPUDuration,
+		}); err != nil {
+			setupLog.Error(err, "Unable to set up the profiler")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "Unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	if err := mgr.AddReadyzCheck("readyz", readyzCheck); err != nil {
 		setupLog.Error(err, "Unable to set up ready check")
 		os.Exit(1)
 	}
 
+	if err := controllers.ValidateVaultConnectionHealthChecks(vaultConnectionHealthChecksSet); err != nil {
+		setupLog.Error(err, "Invalid argument for --vault-connection-health-checks")
+		os.Exit(1)
+	}
+	if vaultConnectionHealthInterval > 0 {
+		checker := &controllers.VaultConnectionHealthChecker{
+			Client:   mgr.GetClient(),
+			Interval: vaultConnectionHealthInterval,
+		}
+		if err := mgr.Add(checker); err != nil {
+			setupLog.Error(err, "Unable to set up the Vault connection health checker")
+			os.Exit(1)
+		}
+		for _, check := range vaultConnectionHealthChecksSet {
+			var err error
+			switch check {
+			case controllers.VaultConnectionHealthCheckHealthz:
+				err = mgr.AddHealthzCheck("vault-connections", checker.Check)
+			case controllers.VaultConnectionHealthCheckReadyz:
+				err = mgr.AddReadyzCheck("vault-connections", checker.Check)
+			}
+			if err != nil {
+				setupLog.Error(err, "Unable to set up the Vault connection health check", "check", check)
+				os.Exit(1)
+			}
+		}
+	} else if len(vaultConnectionHealthChecksSet) > 0 {
+		setupLog.Error(errors.New("--vault-connection-health-interval is not set"),
+			"Invalid argument for --vault-connection-health-checks")
+		os.Exit(1)
+	}
+
 	setupLog.Info("Starting manager",
 		"gitVersion", versionInfo.GitVersion,
 		"gitCommit", versionInfo.GitCommit,
@@ -593,14 +1591,52 @@ func main() {
 		"goVersion", versionInfo.GoVersion,
 		"platform", versionInfo.Platform,
 		"clientCachePersistenceModel", clientCachePersistenceModel,
+		"clientCacheStorageKMSProvider", clientCacheStorageKMSProvider,
+		"clientCacheStorageKMSKeyID", clientCacheStorageKMSKeyID,
 		"clientCacheSize", cfc.ClientCacheSize,
+		"clientCacheRevokeTokensOnEviction", cfc.RevokeTokensOnEviction,
+		"clientCachePrewarm", cfc.Prewarm,
+		"clientCacheStorageRewrapInterval", cfc.StorageRewrapInterval,
+		"vaultReadCacheTTL", cfc.ReadCacheTTL,
 		"backoffMultiplier", backoffMultiplier,
 		"backoffMaxInterval", backoffMaxInterval,
 		"backoffMaxElapsedTime", backoffMaxElapsedTime,
 		"backoffInitialInterval", backoffInitialInterval,
 		"backoffRandomizationFactor", backoffRandomizationFactor,
 		"globalTransformationOptions", globalTransformationOpts,
+		"globalTransformationRef", globalTransformationRef,
 		"globalVaultAuthOptions", globalVaultAuthOpts,
+		"vaultNamespaceRemap", vaultNamespaceRemap,
+		"vaultLoginMaxConcurrency", vaultLoginMaxConcurrency,
+		"allowedVaultNamespaces", allowedVaultNamespaces,
+		"allowedVaultPaths", allowedVaultPaths,
+		"deniedVaultPaths", deniedVaultPaths,
+		"watchNamespaces", watchNamespacesSet,
+		"enableConversionWebhook", enableConversionWebhook,
+		"operatorStatusInterval", operatorStatusInterval,
+		"followerMode", followerMode,
+		"hvsWebhookBindAddress", hvsWebhookBindAddress,
+		"acmeHTTP01BindAddress", acmeHTTP01BindAddress,
+		"reconcileSheddingThreshold", reconcileSheddingThreshold,
+		"reconcileSheddingKinds", reconcileSheddingKinds,
+		"reconcileSheddingNamespaces", reconcileSheddingNamespaces,
+		"reconcileSheddingDeferAfter", reconcileSheddingDeferAfter,
+		"freezeWindowSchedule", freezeWindowSchedule,
+		"freezeWindowDuration", freezeWindowDuration,
+		"freezeWindowExemptSelector", freezeWindowExemptSelector,
+		"profileInterval", profileInterval,
+		"profileCPUDuration", profileCPUDuration,
+		"shard", shard.String(),
+		"kvReadBatchWindow", kvReadBatchWindow,
+		"startupSyncWindow", startupSyncWindow,
+		"startupSyncWindowKinds", startupSyncWindowKinds,
+		"hmacKeyRotationInterval", hmacKeyRotationInterval,
+		"syncFailureThreshold", syncFailureThreshold,
+		"syncFailureWebhookFormat", syncFailureWebhookFormat,
+		"syncFailureWebhookEnabled", vsoEnvOptions.SyncFailureWebhookURL != "",
+		"leaseRenewalBatchWindow", leaseRenewalBatchWindow,
+		"vaultConnectionHealthInterval", vaultConnectionHealthInterval,
+		"vaultConnectionHealthChecks", vaultConnectionHealthChecksSet,
 	)
 
 	mgr.GetCache()
@@ -609,6 +1645,24 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	if cfc.RevokeTokensOnEviction {
+		// purge the client cache so that all remaining Vault tokens are revoked
+		// before the operator exits.
+		setupLog.Info("Revoking all cached Vault client tokens")
+		clientFactory.ShutDown(vclient.CachingClientFactoryShutDownRequest{Revoke: true})
+	}
+}
+
+// followerManager is the manager.Manager of a follower. It drops all of the
+// runnables that are added to it, e.g. the controllers.
+type followerManager struct {
+	manager.Manager
+}
+
+// Add implements manager.Manager.
+func (m *followerManager) Add(manager.Runnable) error {
+	return nil
 }
 
 func shutDownOperator(ctx context.Context, c client.Client, mode vclient.ShutDownMode) error {
@@ -0,0 +1,187 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	awsDefaultProfile = "default"
+	kubeconfigDefault = "default"
+)
+
+// dockerConfigJSON is the format of the kubernetes.io/dockerconfigjson Secret's
+// .dockerconfigjson key.
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// dockerconfigjson returns a Docker config JSON holding the credentials for
+// registry. The result is suitable for the .dockerconfigjson key of a
+// kubernetes.io/dockerconfigjson Secret.
+//
+// Example:
+//
+//	{{- dockerconfigjson "ghcr.io" .Secrets.username .Secrets.password -}}
+func dockerconfigjson(registry, username, password string) (string, error) {
+	if registry == "" {
+		return "", fmt.Errorf("dockerconfigjson: registry is required")
+	}
+	if username == "" && password == "" {
+		return "", fmt.Errorf("dockerconfigjson: username or password is required")
+	}
+
+	b, err := json.Marshal(dockerConfigJSON{
+		Auths: map[string]dockerConfigAuth{
+			registry: {
+				Username: username,
+				Password: password,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("dockerconfigjson: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// awsCredentialsFile returns an AWS shared credentials file. The sessionToken is
+// optional, it is omitted when empty, e.g. for the static credentials of an IAM
+// user. The profile defaults to "default".
+//
+// Example:
+//
+//	{{- awsCredentialsFile .Secrets.access_key .Secrets.secret_key .Secrets.security_token -}}
+func awsCredentialsFile(accessKeyID, secretAccessKey, sessionToken any, profile ...string) (string, error) {
+	name := awsDefaultProfile
+	switch len(profile) {
+	case 0:
+	case 1:
+		name = profile[0]
+	default:
+		return "", fmt.Errorf("awsCredentialsFile: expected a single profile, got %d", len(profile))
+	}
+	if name == "" || strings.ContainsAny(name, "[]\r\n") {
+		return "", fmt.Errorf("awsCredentialsFile: invalid profile %q", name)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s]\n", name))
+	for _, e := range []struct {
+		key      string
+		value    any
+		required bool
+	}{
+		{key: "aws_access_key_id", value: accessKeyID, required: true},
+		{key: "aws_secret_access_key", value: secretAccessKey, required: true},
+		{key: "aws_session_token", value: sessionToken},
+	} {
+		k := e.key
+		v, err := stringArg(e.value)
+		if err != nil {
+			return "", fmt.Errorf("awsCredentialsFile: %s: %w", k, err)
+		}
+		if v == "" {
+			if !e.required {
+				continue
+			}
+			return "", fmt.Errorf("awsCredentialsFile: %s is required", k)
+		}
+		if strings.ContainsAny(v, "\r\n") {
+			return "", fmt.Errorf("awsCredentialsFile: %s contains a line break", k)
+		}
+		sb.WriteString(fmt.Sprintf("%s = %s\n", k, v))
+	}
+
+	return sb.String(), nil
+}
+
+// kubeconfig returns a kubeconfig file for accessing the Kubernetes API server
+// with a bearer token, e.g. one issued by Vault's Kubernetes secrets engine. The
+// caCert is the PEM encoded CA certificate of the server, the system's roots
+// are used when empty. The namespace is optional.
+//
+// Example:
+//
+//	{{- kubeconfig "https://10.0.0.1:6443" .Secrets.ca_cert .Secrets.service_account_token .Secrets.service_account_namespace -}}
+func kubeconfig(server string, caCert, token any, namespace ...string) (string, error) {
+	if server == "" {
+		return "", fmt.Errorf("kubeconfig: server is required")
+	}
+
+	t, err := stringArg(token)
+	if err != nil {
+		return "", fmt.Errorf("kubeconfig: token: %w", err)
+	}
+	if t == "" {
+		return "", fmt.Errorf("kubeconfig: token is required")
+	}
+
+	cluster := clientcmdapi.NewCluster()
+	cluster.Server = server
+	if _, err := parseCertificates(caCert); err != nil {
+		return "", fmt.Errorf("kubeconfig: caCert: %w", err)
+	}
+	if bundle, err := pemBundle(caCert); err != nil {
+		return "", fmt.Errorf("kubeconfig: caCert: %w", err)
+	} else if bundle != "" {
+		cluster.CertificateAuthorityData = []byte(bundle)
+	}
+
+	authInfo := clientcmdapi.NewAuthInfo()
+	authInfo.Token = t
+
+	kubeCtx := clientcmdapi.NewContext()
+	kubeCtx.Cluster = kubeconfigDefault
+	kubeCtx.AuthInfo = kubeconfigDefault
+	switch len(namespace) {
+	case 0:
+	case 1:
+		kubeCtx.Namespace = namespace[0]
+	default:
+		return "", fmt.Errorf("kubeconfig: expected a single namespace, got %d", len(namespace))
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[kubeconfigDefault] = cluster
+	config.AuthInfos[kubeconfigDefault] = authInfo
+	config.Contexts[kubeconfigDefault] = kubeCtx
+	config.CurrentContext = kubeconfigDefault
+
+	b, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("kubeconfig: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// stringArg returns v as a string, nil values are returned as an empty string.
+func stringArg(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+}
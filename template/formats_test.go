@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func Test_dockerconfigjson(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		registry string
+		username string
+		password string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "valid",
+			registry: "ghcr.io",
+			username: "foo",
+			password: "bar",
+			want:     `{"auths":{"ghcr.io":{"username":"foo","password":"bar","auth":"Zm9vOmJhcg=="}}}`,
+		},
+		{
+			name:     "escaped",
+			registry: "https://index.docker.io/v1/",
+			username: "foo",
+			password: `b"a\r`,
+			want: `{"auths":{"https://index.docker.io/v1/":{"username":"foo","password":"b\"a\\r","auth":"` +
+				base64.StdEncoding.EncodeToString([]byte(`foo:b"a\r`)) + `"}}}`,
+		},
+		{
+			name:     "no-registry",
+			username: "foo",
+			password: "bar",
+			wantErr:  "dockerconfigjson: registry is required",
+		},
+		{
+			name:     "no-credentials",
+			registry: "ghcr.io",
+			wantErr:  "dockerconfigjson: username or password is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := dockerconfigjson(tt.registry, tt.username, tt.password)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_awsCredentialsFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		accessKeyID     any
+		secretAccessKey any
+		sessionToken    any
+		profile         []string
+		want            string
+		wantErr         string
+	}{
+		{
+			name:            "session-token",
+			accessKeyID:     "AKIA",
+			secretAccessKey: "secret",
+			sessionToken:    "token",
+			want: `[default]
+aws_access_key_id = AKIA
+aws_secret_access_key = secret
+aws_session_token = token
+`,
+		},
+		{
+			name:            "no-session-token",
+			accessKeyID:     "AKIA",
+			secretAccessKey: []byte("secret"),
+			sessionToken:    nil,
+			profile:         []string{"prod"},
+			want: `[prod]
+aws_access_key_id = AKIA
+aws_secret_access_key = secret
+`,
+		},
+		{
+			name:            "missing-secret-access-key",
+			accessKeyID:     "AKIA",
+			secretAccessKey: "",
+			wantErr:         "awsCredentialsFile: aws_secret_access_key is required",
+		},
+		{
+			name:            "line-break",
+			accessKeyID:     "AKIA\n[other]",
+			secretAccessKey: "secret",
+			wantErr:         "awsCredentialsFile: aws_access_key_id contains a line break",
+		},
+		{
+			name:            "unsupported-type",
+			accessKeyID:     1,
+			secretAccessKey: "secret",
+			wantErr:         "awsCredentialsFile: aws_access_key_id: unsupported type int",
+		},
+		{
+			name:            "invalid-profile",
+			accessKeyID:     "AKIA",
+			secretAccessKey: "secret",
+			profile:         []string{"foo]"},
+			wantErr:         `awsCredentialsFile: invalid profile "foo]"`,
+		},
+		{
+			name:            "multiple-profiles",
+			accessKeyID:     "AKIA",
+			secretAccessKey: "secret",
+			profile:         []string{"foo", "bar"},
+			wantErr:         "awsCredentialsFile: expected a single profile, got 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := awsCredentialsFile(tt.accessKeyID, tt.secretAccessKey, tt.sessionToken, tt.profile...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_kubeconfig(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCertChain(t)
+	tests := []struct {
+		name      string
+		server    string
+		caCert    any
+		token     any
+		namespace []string
+		wantErr   string
+	}{
+		{
+			name:      "valid",
+			server:    "https://10.0.0.1:6443",
+			caCert:    c.caPEM,
+			token:     "token",
+			namespace: []string{"tenant-1"},
+		},
+		{
+			name:   "no-ca-cert",
+			server: "https://10.0.0.1:6443",
+			caCert: "",
+			token:  []byte("token"),
+		},
+		{
+			name:    "no-server",
+			token:   "token",
+			wantErr: "kubeconfig: server is required",
+		},
+		{
+			name:    "no-token",
+			server:  "https://10.0.0.1:6443",
+			wantErr: "kubeconfig: token is required",
+		},
+		{
+			name:    "invalid-ca-cert",
+			server:  "https://10.0.0.1:6443",
+			caCert:  c.keyPEM,
+			token:   "token",
+			wantErr: `kubeconfig: caCert: unsupported certificate PEM block type "EC PRIVATE KEY"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := kubeconfig(tt.server, tt.caCert, tt.token, tt.namespace...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			config, err := clientcmd.Load([]byte(got))
+			require.NoError(t, err)
+			assert.Equal(t, "default", config.CurrentContext)
+
+			restConfig, err := clientcmd.NewDefaultClientConfig(*config, nil).ClientConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tt.server, restConfig.Host)
+			assert.Equal(t, "token", restConfig.BearerToken)
+			if s, _ := tt.caCert.(string); s != "" {
+				assert.Equal(t, s, string(restConfig.CAData))
+			} else {
+				assert.Empty(t, restConfig.CAData)
+			}
+
+			ns, _, err := clientcmd.NewDefaultClientConfig(*config, nil).Namespace()
+			require.NoError(t, err)
+			if len(tt.namespace) > 0 {
+				assert.Equal(t, tt.namespace[0], ns)
+			} else {
+				assert.Equal(t, "default", ns)
+			}
+		})
+	}
+}
+
+func Test_formatFuncs_template(t *testing.T) {
+	t.Parallel()
+
+	tmpl := NewSecretTemplate("")
+	require.NoError(t, tmpl.Parse("docker",
+		`{{- dockerconfigjson "ghcr.io" .username .password -}}`))
+	require.NoError(t, tmpl.Parse("aws",
+		`{{- awsCredentialsFile .access_key .secret_key .security_token "prod" -}}`))
+
+	got, err := tmpl.ExecuteTemplate("docker", map[string]any{
+		"username": "foo",
+		"password": "bar",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"auths":{"ghcr.io":{"username":"foo","password":"bar","auth":"Zm9vOmJhcg=="}}}`, string(got))
+
+	got, err = tmpl.ExecuteTemplate("aws", map[string]any{
+		"access_key":     "AKIA",
+		"secret_key":     "secret",
+		"security_token": nil,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "[prod]\naws_access_key_id = AKIA\naws_secret_access_key = secret\n", string(got))
+}
@@ -17,6 +17,20 @@ func init() {
 			funcMap[k] = springFuncs[k]
 		} // missing functions are detected in Test_funcMap()
 	}
+	for k, f := range vsoFuncs {
+		funcMap[k] = f
+	}
+}
+
+// vsoFuncs contains the functions provided by VSO in addition to the sprig
+// functions.
+var vsoFuncs = map[string]any{
+	"awsCredentialsFile": awsCredentialsFile,
+	"dockerconfigjson":   dockerconfigjson,
+	"jks":                jksKeystore,
+	"kubeconfig":         kubeconfig,
+	"pemBundle":          pemBundle,
+	"pkcs12":             pkcs12Keystore,
 }
 
 // allowedSprigFuncs contains the set of all sprig functions allowed. it is a
@@ -10,14 +10,19 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// tests to ensure all allowedSprigFuncs are registered in the funcMap
+// tests to ensure all allowedSprigFuncs and vsoFuncs are registered in the
+// funcMap
 func Test_funcMap(t *testing.T) {
-	expected := allowedSprigFuncs
+	expected := slices.Clone(allowedSprigFuncs)
+	for k := range vsoFuncs {
+		expected = append(expected, k)
+	}
 	var actual []string
 	for k := range funcMap {
 		actual = append(actual, k)
 	}
 
+	slices.Sort(expected)
 	slices.Sort(actual)
 	assert.Equal(t, actual, expected)
 }
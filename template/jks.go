@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	jksMagic               uint32 = 0xfeedfeed
+	jksVersion             uint32 = 2
+	jksTagPrivateKey       uint32 = 1
+	jksTagTrustedCert      uint32 = 2
+	jksCertTypeX509               = "X.509"
+	jksPrivateKeyAlias            = "certificate"
+	jksTrustedCertAlias           = "ca"
+	jksKeyProtectorSaltLen        = sha1.Size
+	// jksDigestWhitener is mixed into the JKS integrity digest, as defined by
+	// Java's JavaKeyStore implementation.
+	jksDigestWhitener = "Mighty Aphrodite"
+)
+
+// oidJavaKeyProtector is the algorithm of Java's proprietary private key
+// protection.
+var oidJavaKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+// encodeJKS returns a Java KeyStore. If privateKey, a PKCS#8 DER encoded key, is
+// set, the key store holds the key along with its certificate chain, where
+// certs[0] is the key's certificate. Otherwise, the key store is a trust store
+// holding certs.
+func encodeJKS(rand io.Reader, privateKey []byte, certs []*x509.Certificate, password string, now time.Time) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates provided")
+	}
+
+	w := &jksWriter{}
+	w.writeUint32(jksMagic)
+	w.writeUint32(jksVersion)
+
+	ts := uint64(now.UnixMilli())
+	if privateKey != nil {
+		protected, err := jksProtectKey(rand, privateKey, password)
+		if err != nil {
+			return nil, err
+		}
+
+		w.writeUint32(1)
+		w.writeUint32(jksTagPrivateKey)
+		w.writeUTF(jksPrivateKeyAlias)
+		w.writeUint64(ts)
+		w.writeBytes(protected)
+		w.writeUint32(uint32(len(certs)))
+		for _, cert := range certs {
+			w.writeCert(cert)
+		}
+	} else {
+		w.writeUint32(uint32(len(certs)))
+		for i, cert := range certs {
+			alias := jksTrustedCertAlias
+			if i > 0 {
+				alias = fmt.Sprintf("%s-%d", jksTrustedCertAlias, i)
+			}
+			w.writeUint32(jksTagTrustedCert)
+			w.writeUTF(alias)
+			w.writeUint64(ts)
+			w.writeCert(cert)
+		}
+	}
+
+	digest := sha1.New()
+	digest.Write(bmpString(password))
+	digest.Write([]byte(jksDigestWhitener))
+	digest.Write(w.buf.Bytes())
+	w.buf.Write(digest.Sum(nil))
+
+	return w.buf.Bytes(), nil
+}
+
+// jksProtectKey encrypts the PKCS#8 DER encoded privateKey with Java's
+// proprietary key protection algorithm, and returns the DER encoded
+// EncryptedPrivateKeyInfo.
+func jksProtectKey(rand io.Reader, privateKey []byte, password string) ([]byte, error) {
+	salt := make([]byte, jksKeyProtectorSaltLen)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+
+	passwd := bmpString(password)
+	encrypted := make([]byte, 0, len(salt)+len(privateKey)+sha1.Size)
+	encrypted = append(encrypted, salt...)
+
+	// the key stream is the chained SHA-1 digests of the password and the
+	// previous digest, starting with the salt.
+	digest := salt
+	for i := 0; i < len(privateKey); i += sha1.Size {
+		sum := sha1.Sum(append(passwd[:len(passwd):len(passwd)], digest...))
+		digest = sum[:]
+		for j := 0; j < sha1.Size && i+j < len(privateKey); j++ {
+			encrypted = append(encrypted, privateKey[i+j]^digest[j])
+		}
+	}
+
+	check := sha1.Sum(append(passwd[:len(passwd):len(passwd)], privateKey...))
+	encrypted = append(encrypted, check[:]...)
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		AlgorithmIdentifier: pkixAlgorithmIdentifier{
+			Algorithm:  oidJavaKeyProtector,
+			Parameters: asn1NULL,
+		},
+		EncryptedData: encrypted,
+	})
+}
+
+// jksWriter writes the big-endian encoded JKS fields, like Java's
+// DataOutputStream.
+type jksWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *jksWriter) writeUint32(v uint32) {
+	w.buf.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (w *jksWriter) writeUint64(v uint64) {
+	w.buf.Write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (w *jksWriter) writeBytes(b []byte) {
+	w.writeUint32(uint32(len(b)))
+	w.buf.Write(b)
+}
+
+// writeUTF writes s as a length prefixed string. The aliases are always ASCII,
+// so Java's modified UTF-8 encoding is the same as UTF-8.
+func (w *jksWriter) writeUTF(s string) {
+	w.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(len(s))))
+	w.buf.WriteString(s)
+}
+
+func (w *jksWriter) writeCert(cert *x509.Certificate) {
+	w.writeUTF(jksCertTypeX509)
+	w.writeBytes(cert.Raw)
+}
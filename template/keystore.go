@@ -0,0 +1,217 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// randReader is the source of randomness for the keystore salts and IVs.
+var randReader = rand.Reader
+
+// nowFunc returns the creation time of the JKS entries.
+var nowFunc = time.Now
+
+// pemBundle returns a PEM bundle of all the PEM blocks found in args. Each arg
+// is either a string, or a list of strings, e.g. the ca_chain of a
+// VaultPKISecret. Empty args are ignored.
+//
+// Example:
+//
+//	{{- pemBundle .Secrets.certificate .Secrets.ca_chain -}}
+func pemBundle(args ...any) (string, error) {
+	blocks, err := pemBlocks(args...)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		if err := pem.Encode(&buf, block); err != nil {
+			return "", err
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// pkcs12Keystore returns a binary PKCS#12 archive protected by password. If
+// privateKey is empty, the archive is a trust store holding the certificates
+// from certificate and caChain. Otherwise, it is a key store holding the private
+// key along with its certificate chain. The private key entry's alias is
+// "certificate".
+//
+// Example:
+//
+//	{{- pkcs12 .Secrets.private_key .Secrets.certificate .Secrets.ca_chain "changeit" -}}
+func pkcs12Keystore(privateKey, certificate, caChain any, password string) (string, error) {
+	key, certs, err := keystoreEntries(privateKey, certificate, caChain)
+	if err != nil {
+		return "", fmt.Errorf("pkcs12: %w", err)
+	}
+
+	b, err := encodePKCS12(randReader, key, certs, password)
+	if err != nil {
+		return "", fmt.Errorf("pkcs12: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// jksKeystore returns a binary Java KeyStore protected by password. If
+// privateKey is empty, the keystore is a trust store holding the certificates
+// from certificate and caChain, with the aliases "ca", "ca-1", ... Otherwise, it
+// is a key store holding the private key along with its certificate chain. The
+// private key entry's alias is "certificate".
+//
+// Example:
+//
+//	{{- jks "" "" .Secrets.ca_chain "changeit" -}}
+func jksKeystore(privateKey, certificate, caChain any, password string) (string, error) {
+	key, certs, err := keystoreEntries(privateKey, certificate, caChain)
+	if err != nil {
+		return "", fmt.Errorf("jks: %w", err)
+	}
+
+	b, err := encodeJKS(randReader, key, certs, password, nowFunc())
+	if err != nil {
+		return "", fmt.Errorf("jks: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// keystoreEntries parses the keystore function args. Returns the PKCS#8 DER
+// encoded private key, if any, along with the certificates where the first
+// certificate belongs to the private key.
+func keystoreEntries(privateKey, certificate, caChain any) ([]byte, []*x509.Certificate, error) {
+	certs, err := parseCertificates(certificate, caChain)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("no certificates provided")
+	}
+
+	blocks, err := pemBlocks(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch len(blocks) {
+	case 0:
+		return nil, certs, nil
+	case 1:
+	default:
+		return nil, nil, fmt.Errorf("expected a single private key, got %d PEM blocks", len(blocks))
+	}
+
+	key, err := parsePrivateKey(blocks[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(certs[0].PublicKey) {
+		return nil, nil, fmt.Errorf("private key does not match the certificate")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return der, certs, nil
+}
+
+func parsePrivateKey(block *pem.Block) (any, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key PEM block type %q", block.Type)
+	}
+}
+
+func parseCertificates(args ...any) ([]*x509.Certificate, error) {
+	blocks, err := pemBlocks(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unsupported certificate PEM block type %q", block.Type)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+// pemBlocks returns all PEM blocks found in args, see pemBundle.
+func pemBlocks(args ...any) ([]*pem.Block, error) {
+	var blocks []*pem.Block
+	for _, arg := range args {
+		var values []string
+		switch v := arg.(type) {
+		case nil:
+		case string:
+			values = append(values, v)
+		case []byte:
+			values = append(values, string(v))
+		case []string:
+			values = append(values, v...)
+		case []any:
+			for _, e := range v {
+				switch e := e.(type) {
+				case string:
+					values = append(values, e)
+				case []byte:
+					values = append(values, string(e))
+				default:
+					return nil, fmt.Errorf("unsupported PEM list element type %T", e)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported PEM type %T", arg)
+		}
+
+		for _, value := range values {
+			rest := []byte(strings.TrimSpace(value))
+			for len(rest) > 0 {
+				var block *pem.Block
+				block, rest = pem.Decode(rest)
+				if block == nil {
+					return nil, fmt.Errorf("invalid PEM data")
+				}
+				blocks = append(blocks, block)
+				rest = bytes.TrimSpace(rest)
+			}
+		}
+	}
+
+	return blocks, nil
+}
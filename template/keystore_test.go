@@ -0,0 +1,610 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type testCertChain struct {
+	keyPEM    string
+	keyDER    []byte
+	leafPEM   string
+	leaf      *x509.Certificate
+	caPEM     string
+	ca        *x509.Certificate
+	otherPEM  string
+	otherCert *x509.Certificate
+}
+
+func newTestCertChain(t *testing.T) *testCertChain {
+	t.Helper()
+
+	newCert := func(cn string, parent *x509.Certificate, pub any, signer any) (*x509.Certificate, string) {
+		t.Helper()
+		tmpl := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: cn},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  parent == nil,
+			BasicConstraintsValid: true,
+		}
+		if parent == nil {
+			parent = tmpl
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, signer)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		return cert, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	c := &testCertChain{}
+	c.ca, c.caPEM = newCert("ca", nil, &caKey.PublicKey, caKey)
+	c.leaf, c.leafPEM = newCert("leaf", c.ca, &key.PublicKey, caKey)
+	c.otherCert, c.otherPEM = newCert("other", c.ca, &caKey.PublicKey, caKey)
+
+	ecDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	c.keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+	c.keyDER, err = x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return c
+}
+
+func Test_pemBundle(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCertChain(t)
+	tests := []struct {
+		name    string
+		args    []any
+		want    string
+		wantErr string
+	}{
+		{
+			name: "strings",
+			args: []any{c.leafPEM, c.caPEM},
+			want: c.leafPEM + c.caPEM,
+		},
+		{
+			name: "list",
+			args: []any{c.leafPEM, []any{c.caPEM, []byte(c.otherPEM)}},
+			want: c.leafPEM + c.caPEM + c.otherPEM,
+		},
+		{
+			name: "bundle-with-whitespace",
+			args: []any{"\n" + c.leafPEM + "\n\n" + c.caPEM + "\n", []string{}, nil, ""},
+			want: c.leafPEM + c.caPEM,
+		},
+		{
+			name: "empty",
+			args: []any{"", nil},
+			want: "",
+		},
+		{
+			name:    "invalid-pem",
+			args:    []any{c.leafPEM, "foo"},
+			wantErr: "invalid PEM data",
+		},
+		{
+			name:    "unsupported-type",
+			args:    []any{1},
+			wantErr: "unsupported PEM type int",
+		},
+		{
+			name:    "unsupported-list-element-type",
+			args:    []any{[]any{c.leafPEM, 1}},
+			wantErr: "unsupported PEM list element type int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := pemBundle(tt.args...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_keystoreEntries(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCertChain(t)
+	tests := []struct {
+		name        string
+		privateKey  any
+		certificate any
+		caChain     any
+		wantKey     []byte
+		wantCerts   []*x509.Certificate
+		wantErr     string
+	}{
+		{
+			name:        "key-store",
+			privateKey:  c.keyPEM,
+			certificate: c.leafPEM,
+			caChain:     []any{c.caPEM},
+			wantKey:     c.keyDER,
+			wantCerts:   []*x509.Certificate{c.leaf, c.ca},
+		},
+		{
+			name:        "key-store-pkcs8",
+			privateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: c.keyDER})),
+			certificate: c.leafPEM,
+			wantKey:     c.keyDER,
+			wantCerts:   []*x509.Certificate{c.leaf},
+		},
+		{
+			name:      "trust-store",
+			caChain:   c.caPEM + c.otherPEM,
+			wantCerts: []*x509.Certificate{c.ca, c.otherCert},
+		},
+		{
+			name:    "no-certificates",
+			caChain: []any{},
+			wantErr: "no certificates provided",
+		},
+		{
+			name:        "key-mismatch",
+			privateKey:  c.keyPEM,
+			certificate: c.otherPEM,
+			wantErr:     "private key does not match the certificate",
+		},
+		{
+			name:        "multiple-keys",
+			privateKey:  c.keyPEM + c.keyPEM,
+			certificate: c.leafPEM,
+			wantErr:     "expected a single private key, got 2 PEM blocks",
+		},
+		{
+			name:        "unsupported-key-type",
+			privateKey:  c.caPEM,
+			certificate: c.leafPEM,
+			wantErr:     `unsupported private key PEM block type "CERTIFICATE"`,
+		},
+		{
+			name:        "unsupported-certificate-type",
+			privateKey:  c.keyPEM,
+			certificate: c.keyPEM,
+			wantErr:     `unsupported certificate PEM block type "EC PRIVATE KEY"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, certs, err := keystoreEntries(tt.privateKey, tt.certificate, tt.caChain)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantCerts, certs)
+		})
+	}
+}
+
+func Test_pkcs12Keystore(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCertChain(t)
+	tests := []struct {
+		name        string
+		privateKey  any
+		certificate any
+		caChain     any
+		password    string
+		wantKey     []byte
+		wantCerts   []*x509.Certificate
+		wantErr     string
+	}{
+		{
+			name:        "key-store",
+			privateKey:  c.keyPEM,
+			certificate: c.leafPEM,
+			caChain:     []any{c.caPEM},
+			password:    "changeit",
+			wantKey:     c.keyDER,
+			wantCerts:   []*x509.Certificate{c.leaf, c.ca},
+		},
+		{
+			name:      "trust-store",
+			caChain:   []any{c.caPEM, c.otherPEM},
+			password:  "changeit",
+			wantCerts: []*x509.Certificate{c.ca, c.otherCert},
+		},
+		{
+			name:        "empty-password",
+			privateKey:  c.keyPEM,
+			certificate: c.leafPEM,
+			wantKey:     c.keyDER,
+			wantCerts:   []*x509.Certificate{c.leaf},
+		},
+		{
+			name:        "invalid",
+			privateKey:  c.keyPEM,
+			certificate: c.otherPEM,
+			wantErr:     "pkcs12: private key does not match the certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := pkcs12Keystore(tt.privateKey, tt.certificate, tt.caChain, tt.password)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			key, certs, trusted := decodeTestPKCS12(t, []byte(got), tt.password)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantCerts, certs)
+			assert.Equal(t, tt.wantKey == nil, trusted)
+		})
+	}
+}
+
+func Test_jksKeystore(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCertChain(t)
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name        string
+		privateKey  any
+		certificate any
+		caChain     any
+		password    string
+		wantEntries []testJKSEntry
+		wantErr     string
+	}{
+		{
+			name:        "key-store",
+			privateKey:  c.keyPEM,
+			certificate: c.leafPEM,
+			caChain:     c.caPEM,
+			password:    "changeit",
+			wantEntries: []testJKSEntry{
+				{
+					alias: "certificate",
+					key:   c.keyDER,
+					certs: []*x509.Certificate{c.leaf, c.ca},
+				},
+			},
+		},
+		{
+			name:     "trust-store",
+			caChain:  []string{c.caPEM, c.otherPEM},
+			password: "changeit",
+			wantEntries: []testJKSEntry{
+				{
+					alias: "ca",
+					certs: []*x509.Certificate{c.ca},
+				},
+				{
+					alias: "ca-1",
+					certs: []*x509.Certificate{c.otherCert},
+				},
+			},
+		},
+		{
+			name:    "invalid",
+			caChain: "foo",
+			wantErr: "jks: invalid PEM data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, certs, err := keystoreEntries(tt.privateKey, tt.certificate, tt.caChain)
+			if tt.wantErr != "" {
+				_, err := jksKeystore(tt.privateKey, tt.certificate, tt.caChain, tt.password)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := encodeJKS(rand.Reader, key, certs, tt.password, now)
+			require.NoError(t, err)
+			entries := decodeTestJKS(t, got, tt.password)
+			for i := range entries {
+				assert.Equal(t, now.UnixMilli(), entries[i].ts)
+				entries[i].ts = 0
+			}
+			assert.Equal(t, tt.wantEntries, entries)
+
+			// the integrity check must fail with the wrong password.
+			digest := sha1.New()
+			digest.Write(bmpString(tt.password + "x"))
+			digest.Write([]byte(jksDigestWhitener))
+			digest.Write(got[:len(got)-sha1.Size])
+			assert.NotEqual(t, digest.Sum(nil), got[len(got)-sha1.Size:])
+		})
+	}
+}
+
+func Test_keystoreFuncs_template(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCertChain(t)
+	tmpl := NewSecretTemplate("")
+	require.NoError(t, tmpl.Parse("bundle", `{{- pemBundle .certificate .ca_chain -}}`))
+	require.NoError(t, tmpl.Parse("p12", `{{- pkcs12 .private_key .certificate .ca_chain "changeit" -}}`))
+	require.NoError(t, tmpl.Parse("jks", `{{- jks "" "" .ca_chain "changeit" -}}`))
+
+	input := map[string]any{
+		"private_key": c.keyPEM,
+		"certificate": c.leafPEM,
+		"ca_chain":    []any{c.caPEM},
+	}
+
+	got, err := tmpl.ExecuteTemplate("bundle", input)
+	require.NoError(t, err)
+	assert.Equal(t, c.leafPEM+c.caPEM, string(got))
+
+	got, err = tmpl.ExecuteTemplate("p12", input)
+	require.NoError(t, err)
+	key, certs, _ := decodeTestPKCS12(t, got, "changeit")
+	assert.Equal(t, c.keyDER, key)
+	assert.Equal(t, []*x509.Certificate{c.leaf, c.ca}, certs)
+
+	got, err = tmpl.ExecuteTemplate("jks", input)
+	require.NoError(t, err)
+	entries := decodeTestJKS(t, got, "changeit")
+	require.Len(t, entries, 1)
+	assert.Equal(t, []*x509.Certificate{c.ca}, entries[0].certs)
+}
+
+// decodeTestPKCS12 verifies and decodes a PKCS#12 archive produced by
+// encodePKCS12. Returns the private key, the certificates, and whether all the
+// certificates are marked as trusted for Java.
+func decodeTestPKCS12(t *testing.T, b []byte, password string) ([]byte, []*x509.Certificate, bool) {
+	t.Helper()
+
+	var pfx pfxPDU
+	rest, err := asn1.Unmarshal(b, &pfx)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	require.Equal(t, 3, pfx.Version)
+	require.Equal(t, oidDataContentType, pfx.AuthSafe.ContentType)
+
+	var authSafeBytes []byte
+	_, err = asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafeBytes)
+	require.NoError(t, err)
+
+	macKey := pkcs12KDF(sha256.New, 3, bmpStringZeroTerminated(password), pfx.MacData.MacSalt,
+		pfx.MacData.Iterations, sha256.Size)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(authSafeBytes)
+	require.Equal(t, mac.Sum(nil), pfx.MacData.Mac.Digest, "MAC verification failed")
+
+	var authSafe []contentInfo
+	_, err = asn1.Unmarshal(authSafeBytes, &authSafe)
+	require.NoError(t, err)
+
+	var bags []safeBag
+	for _, ci := range authSafe {
+		var data []byte
+		switch {
+		case ci.ContentType.Equal(oidDataContentType):
+			_, err = asn1.Unmarshal(ci.Content.Bytes, &data)
+			require.NoError(t, err)
+		case ci.ContentType.Equal(oidEncryptedDataContentType):
+			var ed encryptedData
+			_, err = asn1.Unmarshal(ci.Content.Bytes, &ed)
+			require.NoError(t, err)
+			data = decryptTestPBES2(t, ed.EncryptedContentInfo.ContentEncryptionAlgorithm,
+				ed.EncryptedContentInfo.EncryptedContent, password)
+		default:
+			require.Fail(t, "unexpected content type", ci.ContentType)
+		}
+
+		var contentBags []safeBag
+		_, err = asn1.Unmarshal(data, &contentBags)
+		require.NoError(t, err)
+		bags = append(bags, contentBags...)
+	}
+
+	var key []byte
+	var certs []*x509.Certificate
+	trusted := true
+	for _, bag := range bags {
+		switch {
+		case bag.ID.Equal(oidCertBag):
+			var cb certBag
+			_, err = asn1.Unmarshal(bag.Value.Bytes, &cb)
+			require.NoError(t, err)
+			cert, err := x509.ParseCertificate(cb.Data)
+			require.NoError(t, err)
+			certs = append(certs, cert)
+
+			var isTrusted bool
+			for _, attr := range bag.Attributes {
+				isTrusted = isTrusted || attr.ID.Equal(oidJavaTrustStore)
+			}
+			trusted = trusted && isTrusted
+		case bag.ID.Equal(oidPKCS8ShroudedKeyBag):
+			var info encryptedPrivateKeyInfo
+			_, err = asn1.Unmarshal(bag.Value.Bytes, &info)
+			require.NoError(t, err)
+			key = decryptTestPBES2(t, info.AlgorithmIdentifier, info.EncryptedData, password)
+		default:
+			require.Fail(t, "unexpected bag type", bag.ID)
+		}
+	}
+
+	return key, certs, trusted
+}
+
+func decryptTestPBES2(t *testing.T, algo pkixAlgorithmIdentifier, data []byte, password string) []byte {
+	t.Helper()
+
+	require.Equal(t, oidPBES2, algo.Algorithm)
+	var params pbes2Params
+	_, err := asn1.Unmarshal(algo.Parameters.FullBytes, &params)
+	require.NoError(t, err)
+	require.Equal(t, oidPBKDF2, params.KeyDerivationFunc.Algorithm)
+	require.Equal(t, oidAES256CBC, params.EncryptionScheme.Algorithm)
+
+	var kdfParams pbkdf2Params
+	_, err = asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams)
+	require.NoError(t, err)
+	require.Equal(t, oidHMACWithSHA256, kdfParams.PRF.Algorithm)
+
+	var iv []byte
+	_, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv)
+	require.NoError(t, err)
+
+	key := pbkdf2.Key([]byte(password), kdfParams.Salt.Bytes, kdfParams.Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	padLen := int(out[len(out)-1])
+	require.True(t, padLen > 0 && padLen <= aes.BlockSize, "invalid padding")
+
+	return out[:len(out)-padLen]
+}
+
+type testJKSEntry struct {
+	alias string
+	ts    int64
+	key   []byte
+	certs []*x509.Certificate
+}
+
+// decodeTestJKS verifies and decodes a Java KeyStore produced by encodeJKS.
+func decodeTestJKS(t *testing.T, b []byte, password string) []testJKSEntry {
+	t.Helper()
+
+	require.Greater(t, len(b), sha1.Size)
+	data, sum := b[:len(b)-sha1.Size], b[len(b)-sha1.Size:]
+	digest := sha1.New()
+	digest.Write(bmpString(password))
+	digest.Write([]byte(jksDigestWhitener))
+	digest.Write(data)
+	require.Equal(t, digest.Sum(nil), sum, "integrity check failed")
+
+	r := bytes.NewReader(data)
+	readUint32 := func() uint32 {
+		var v uint32
+		require.NoError(t, binary.Read(r, binary.BigEndian, &v))
+		return v
+	}
+	readBytes := func(n int) []byte {
+		v := make([]byte, n)
+		_, err := io.ReadFull(r, v)
+		require.NoError(t, err)
+		return v
+	}
+	readUTF := func() string {
+		var n uint16
+		require.NoError(t, binary.Read(r, binary.BigEndian, &n))
+		return string(readBytes(int(n)))
+	}
+	readCert := func() *x509.Certificate {
+		require.Equal(t, jksCertTypeX509, readUTF())
+		cert, err := x509.ParseCertificate(readBytes(int(readUint32())))
+		require.NoError(t, err)
+		return cert
+	}
+
+	require.Equal(t, jksMagic, readUint32())
+	require.Equal(t, jksVersion, readUint32())
+
+	var entries []testJKSEntry
+	count := int(readUint32())
+	for i := 0; i < count; i++ {
+		tag := readUint32()
+		entry := testJKSEntry{alias: readUTF()}
+		var ts int64
+		require.NoError(t, binary.Read(r, binary.BigEndian, &ts))
+		entry.ts = ts
+		switch tag {
+		case jksTagPrivateKey:
+			var info encryptedPrivateKeyInfo
+			_, err := asn1.Unmarshal(readBytes(int(readUint32())), &info)
+			require.NoError(t, err)
+			require.Equal(t, oidJavaKeyProtector, info.AlgorithmIdentifier.Algorithm)
+			entry.key = recoverTestJKSKey(t, info.EncryptedData, password)
+			n := int(readUint32())
+			for j := 0; j < n; j++ {
+				entry.certs = append(entry.certs, readCert())
+			}
+		case jksTagTrustedCert:
+			entry.certs = []*x509.Certificate{readCert()}
+		default:
+			require.Fail(t, "unexpected tag", tag)
+		}
+		entries = append(entries, entry)
+	}
+	require.Zero(t, r.Len())
+
+	return entries
+}
+
+// recoverTestJKSKey reverses jksProtectKey.
+func recoverTestJKSKey(t *testing.T, protected []byte, password string) []byte {
+	t.Helper()
+
+	passwd := bmpString(password)
+	salt := protected[:jksKeyProtectorSaltLen]
+	encrypted := protected[jksKeyProtectorSaltLen : len(protected)-sha1.Size]
+	check := protected[len(protected)-sha1.Size:]
+
+	key := make([]byte, len(encrypted))
+	digest := salt
+	for i := 0; i < len(encrypted); i += sha1.Size {
+		sum := sha1.Sum(append(bytes.Clone(passwd), digest...))
+		digest = sum[:]
+		for j := 0; j < sha1.Size && i+j < len(encrypted); j++ {
+			key[i+j] = encrypted[i+j] ^ digest[j]
+		}
+	}
+
+	sum := sha1.Sum(append(bytes.Clone(passwd), key...))
+	require.Equal(t, check, sum[:], "key integrity check failed")
+
+	return key
+}
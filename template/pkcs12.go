@@ -0,0 +1,516 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// The PKCS#12 archives are encoded with the same algorithms as OpenSSL 3's
+// defaults: PBES2 with PBKDF2-HMAC-SHA256 and AES-256-CBC for the encryption of
+// the key and certificate bags, and an HMAC-SHA256 MAC for the archive's
+// integrity.
+const (
+	pkcs12Iterations = 2048
+	pkcs12SaltLen    = 16
+)
+
+// pkcs12FriendlyName is the alias of the private key entry.
+const pkcs12FriendlyName = "certificate"
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidPKCS8ShroudedKeyBag      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidCertTypeX509             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	// oidJavaTrustStore marks a certificate as trusted for Java, it is required
+	// for Java to load the certificates of a PKCS#12 trust store.
+	oidJavaTrustStore      = asn1.ObjectIdentifier{2, 16, 840, 1, 113894, 746875, 1, 1}
+	oidAnyExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37, 0}
+	oidPBES2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSHA256              = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+	asn1NULL = asn1.RawValue{Tag: asn1.TagNull}
+)
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkixAlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type pkixAlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkixAlgorithmIdentifier
+	Digest    []byte
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	AlgorithmIdentifier pkixAlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkixAlgorithmIdentifier
+	EncryptionScheme  pkixAlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       asn1.RawValue
+	Iterations int
+	KeyLength  int `asn1:"optional"`
+	PRF        pkixAlgorithmIdentifier
+}
+
+// encodePKCS12 returns a PKCS#12 archive. If privateKey, a PKCS#8 DER encoded
+// key, is set, the archive is a key store holding the key along with its
+// certificate chain, where certs[0] is the key's certificate. Otherwise, the
+// archive is a trust store holding certs.
+func encodePKCS12(rand io.Reader, privateKey []byte, certs []*x509.Certificate, password string) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates provided")
+	}
+
+	var localKeyID []byte
+	if privateKey != nil {
+		sum := sha1.Sum(certs[0].Raw)
+		localKeyID = sum[:]
+	}
+
+	var certBags []safeBag
+	for i, cert := range certs {
+		bag, err := newCertSafeBag(cert, i == 0 && localKeyID != nil, localKeyID)
+		if err != nil {
+			return nil, err
+		}
+		certBags = append(certBags, bag)
+	}
+
+	certsContent, err := newEncryptedContentInfo(rand, certBags, password)
+	if err != nil {
+		return nil, err
+	}
+	authSafe := []contentInfo{certsContent}
+
+	if privateKey != nil {
+		keyBag, err := newKeySafeBag(rand, privateKey, localKeyID, password)
+		if err != nil {
+			return nil, err
+		}
+
+		keyContent, err := newDataContentInfo([]safeBag{keyBag})
+		if err != nil {
+			return nil, err
+		}
+		authSafe = append(authSafe, keyContent)
+	}
+
+	authSafeBytes, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	macSalt := make([]byte, pkcs12SaltLen)
+	if _, err := io.ReadFull(rand, macSalt); err != nil {
+		return nil, err
+	}
+
+	macKey := pkcs12KDF(sha256.New, 3, bmpStringZeroTerminated(password), macSalt,
+		pkcs12Iterations, sha256.Size)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(authSafeBytes)
+
+	authSafeContent, err := asn1.Marshal(authSafeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(pfxPDU{
+		Version: 3,
+		AuthSafe: contentInfo{
+			ContentType: oidDataContentType,
+			Content: asn1.RawValue{
+				Class:      asn1.ClassContextSpecific,
+				Tag:        0,
+				IsCompound: true,
+				Bytes:      authSafeContent,
+			},
+		},
+		MacData: macData{
+			Mac: digestInfo{
+				Algorithm: pkixAlgorithmIdentifier{
+					Algorithm:  oidSHA256,
+					Parameters: asn1NULL,
+				},
+				Digest: mac.Sum(nil),
+			},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+func newCertSafeBag(cert *x509.Certificate, withKey bool, localKeyID []byte) (safeBag, error) {
+	b, err := asn1.Marshal(certBag{
+		ID:   oidCertTypeX509,
+		Data: cert.Raw,
+	})
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	var attrs []pkcs12Attribute
+	if withKey {
+		attrs, err = newKeyAttributes(localKeyID)
+		if err != nil {
+			return safeBag{}, err
+		}
+	} else if localKeyID == nil {
+		v, err := asn1.Marshal(oidAnyExtendedKeyUsage)
+		if err != nil {
+			return safeBag{}, err
+		}
+		attrs = append(attrs, pkcs12Attribute{
+			ID: oidJavaTrustStore,
+			Value: asn1.RawValue{
+				Tag:        asn1.TagSet,
+				IsCompound: true,
+				Bytes:      v,
+			},
+		})
+	}
+
+	return safeBag{
+		ID: oidCertBag,
+		Value: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      b,
+		},
+		Attributes: attrs,
+	}, nil
+}
+
+func newKeySafeBag(rand io.Reader, privateKey, localKeyID []byte, password string) (safeBag, error) {
+	algo, encrypted, err := pbes2Encrypt(rand, privateKey, password)
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	b, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		AlgorithmIdentifier: algo,
+		EncryptedData:       encrypted,
+	})
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	attrs, err := newKeyAttributes(localKeyID)
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	return safeBag{
+		ID: oidPKCS8ShroudedKeyBag,
+		Value: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      b,
+		},
+		Attributes: attrs,
+	}, nil
+}
+
+// newKeyAttributes returns the attributes that link the private key to its
+// certificate.
+func newKeyAttributes(localKeyID []byte) ([]pkcs12Attribute, error) {
+	name, err := asn1.Marshal(asn1.RawValue{
+		Tag:   asn1.TagBMPString,
+		Bytes: bmpString(pkcs12FriendlyName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := asn1.Marshal(localKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []pkcs12Attribute{
+		{
+			ID: oidFriendlyName,
+			Value: asn1.RawValue{
+				Tag:        asn1.TagSet,
+				IsCompound: true,
+				Bytes:      name,
+			},
+		},
+		{
+			ID: oidLocalKeyID,
+			Value: asn1.RawValue{
+				Tag:        asn1.TagSet,
+				IsCompound: true,
+				Bytes:      id,
+			},
+		},
+	}, nil
+}
+
+func newDataContentInfo(bags []safeBag) (contentInfo, error) {
+	b, err := asn1.Marshal(bags)
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	data, err := asn1.Marshal(b)
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	return contentInfo{
+		ContentType: oidDataContentType,
+		Content: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      data,
+		},
+	}, nil
+}
+
+func newEncryptedContentInfo(rand io.Reader, bags []safeBag, password string) (contentInfo, error) {
+	b, err := asn1.Marshal(bags)
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	algo, encrypted, err := pbes2Encrypt(rand, b, password)
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	data, err := asn1.Marshal(encryptedData{
+		Version: 0,
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType:                oidDataContentType,
+			ContentEncryptionAlgorithm: algo,
+			EncryptedContent:           encrypted,
+		},
+	})
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	return contentInfo{
+		ContentType: oidEncryptedDataContentType,
+		Content: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      data,
+		},
+	}, nil
+}
+
+// pbes2Encrypt encrypts data with PBES2, using PBKDF2-HMAC-SHA256 and
+// AES-256-CBC. Returns the algorithm identifier along with the encrypted data.
+func pbes2Encrypt(rand io.Reader, data []byte, password string) (pkixAlgorithmIdentifier, []byte, error) {
+	salt := make([]byte, pkcs12SaltLen)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return pkixAlgorithmIdentifier{}, nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return pkixAlgorithmIdentifier{}, nil, err
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, pkcs12Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return pkixAlgorithmIdentifier{}, nil, err
+	}
+
+	padLen := aes.BlockSize - len(data)%aes.BlockSize
+	encrypted := make([]byte, len(data), len(data)+padLen)
+	copy(encrypted, data)
+	for i := 0; i < padLen; i++ {
+		encrypted = append(encrypted, byte(padLen))
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt: asn1.RawValue{
+			Tag:   asn1.TagOctetString,
+			Bytes: salt,
+		},
+		Iterations: pkcs12Iterations,
+		PRF: pkixAlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1NULL,
+		},
+	})
+	if err != nil {
+		return pkixAlgorithmIdentifier{}, nil, err
+	}
+
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return pkixAlgorithmIdentifier{}, nil, err
+	}
+
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkixAlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkixAlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return pkixAlgorithmIdentifier{}, nil, err
+	}
+
+	return pkixAlgorithmIdentifier{
+		Algorithm:  oidPBES2,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}, encrypted, nil
+}
+
+// pkcs12KDF derives a key of size bytes, as described in RFC 7292, appendix
+// B.2. The password must be a zero terminated BMPString.
+func pkcs12KDF(h func() hash.Hash, id byte, password, salt []byte, iterations, size int) []byte {
+	u := h().Size()
+	v := h().BlockSize()
+
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+
+	d := make([]byte, v)
+	for i := range d {
+		d[i] = id
+	}
+	I := append(fill(salt), fill(password)...)
+
+	one := big.NewInt(1)
+	var out []byte
+	for len(out) < size {
+		hh := h()
+		hh.Write(d)
+		hh.Write(I)
+		a := hh.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			hh.Reset()
+			hh.Write(a)
+			a = hh.Sum(a[:0])
+		}
+		out = append(out, a...)
+
+		if len(out) >= size {
+			break
+		}
+
+		b := new(big.Int).SetBytes(fill(a[:u])[:v])
+		b.Add(b, one)
+		for j := 0; j < len(I); j += v {
+			ij := new(big.Int).SetBytes(I[j : j+v])
+			ij.Add(ij, b)
+			ijBytes := ij.Bytes()
+			// keep the v least significant bytes.
+			if len(ijBytes) > v {
+				ijBytes = ijBytes[len(ijBytes)-v:]
+			}
+			clear(I[j : j+v])
+			copy(I[j+v-len(ijBytes):j+v], ijBytes)
+		}
+	}
+
+	return out[:size]
+}
+
+// bmpString returns s encoded as a BMPString, i.e. UTF-16 big-endian.
+func bmpString(s string) []byte {
+	var b []byte
+	for _, r := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return b
+}
+
+func bmpStringZeroTerminated(s string) []byte {
+	return append(bmpString(s), 0, 0)
+}
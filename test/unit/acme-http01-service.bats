@@ -0,0 +1,44 @@
+#!/usr/bin/env bats
+
+load _helpers
+
+#--------------------------------------------------------------------
+# enabled/disabled
+
+@test "acmeHTTP01Solver/Service: disabled by default" {
+  cd `chart_dir`
+  local actual=$(helm template \
+      -s templates/acme-http01-service.yaml  \
+      . | tee /dev/stderr |
+      yq 'length > 0' | tee /dev/stderr)
+  [ "${actual}" = "false" ]
+}
+
+@test "acmeHTTP01Solver/Service: enabled" {
+  cd `chart_dir`
+  local object=$(helm template \
+      -s templates/acme-http01-service.yaml  \
+      --set 'controller.manager.acmeHTTP01Solver.enabled=true' \
+      . | tee /dev/stderr)
+
+  local actual=$(echo "$object" | yq '.spec.type' | tee /dev/stderr)
+  [ "${actual}" = "ClusterIP" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].name' | tee /dev/stderr)
+  [ "${actual}" = "acme-http01" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].port' | tee /dev/stderr)
+  [ "${actual}" = "80" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].targetPort' | tee /dev/stderr)
+  [ "${actual}" = "acme-http01" ]
+}
+
+@test "acmeHTTP01Solver/Service: service type can be set" {
+  cd `chart_dir`
+  local object=$(helm template \
+      -s templates/acme-http01-service.yaml  \
+      --set 'controller.manager.acmeHTTP01Solver.enabled=true' \
+      --set 'controller.manager.acmeHTTP01Solver.serviceType=NodePort' \
+      . | tee /dev/stderr)
+
+  local actual=$(echo "$object" | yq '.spec.type' | tee /dev/stderr)
+  [ "${actual}" = "NodePort" ]
+}
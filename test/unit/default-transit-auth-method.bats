@@ -24,6 +24,18 @@ load _helpers
   [ "${actual}" = "true" ]
 }
 
+@test "defaultTransitAuthMethod/CR: disabled when a KMS provider is set" {
+    cd `chart_dir`
+    local actual=$(helm template \
+        -s templates/default-transit-auth-method.yaml  \
+        --set 'controller.manager.clientCache.persistenceModel=direct-encrypted' \
+        --set 'controller.manager.clientCache.kms.provider=aws' \
+        --set 'controller.manager.clientCache.kms.keyID=alias/vso' \
+        . | tee /dev/stderr |
+    yq 'length > 0' | tee /dev/stderr)
+  [ "${actual}" = "false" ]
+}
+
 #--------------------------------------------------------------------
 # settings
 
@@ -233,6 +233,121 @@ load _helpers
   [ "${actual}" = "true" ]
 }
 
+@test "controller/Deployment: clientCache.revokeTokensOnEviction unset" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'contains(["--client-cache-revoke-tokens-on-eviction"])' | tee /dev/stderr)
+  [ "${actual}" = "false" ]
+}
+
+@test "controller/Deployment: clientCache.revokeTokensOnEviction can be set" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.clientCache.revokeTokensOnEviction=true' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'contains(["--client-cache-revoke-tokens-on-eviction"])' | tee /dev/stderr)
+  [ "${actual}" = "true" ]
+}
+
+@test "controller/Deployment: clientCache.prewarm unset" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'contains(["--client-cache-prewarm"])' | tee /dev/stderr)
+  [ "${actual}" = "false" ]
+}
+
+@test "controller/Deployment: clientCache.prewarm can be set" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.clientCache.prewarm=true' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'contains(["--client-cache-prewarm"])' | tee /dev/stderr)
+  [ "${actual}" = "true" ]
+}
+
+@test "controller/Deployment: clientCache.storageRewrapInterval unset" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'map(select(. == "--client-cache-storage-rewrap-interval*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: clientCache.storageRewrapInterval can be set" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.clientCache.storageRewrapInterval=1h' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'contains(["--client-cache-storage-rewrap-interval=1h"])' | tee /dev/stderr)
+  [ "${actual}" = "true" ]
+}
+
+@test "controller/Deployment: clientCache.kms unset" {
+  cd `chart_dir`
+  local object
+  object=$(helm template   -s templates/deployment.yaml    . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'map(select(test("^--client-cache-storage-kms-"))) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: clientCache.kms can be set" {
+  cd `chart_dir`
+  local object
+  object=$(helm template   -s templates/deployment.yaml    --set 'controller.manager.clientCache.kms.provider=aws'   --set 'controller.manager.clientCache.kms.keyID=alias/vso'   . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq 'contains(["--client-cache-storage-kms-provider=aws"])' | tee /dev/stderr)
+  [ "${actual}" = "true" ]
+  actual=$(echo "$object" | yq 'contains(["--client-cache-storage-kms-key-id=alias/vso"])' | tee /dev/stderr)
+  [ "${actual}" = "true" ]
+}
+
+@test "controller/Deployment: clientCache.kms.keyID is required" {
+  cd `chart_dir`
+  run helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.clientCache.kms.provider=aws' \
+  .
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "controller.manager.clientCache.kms.keyID is required" ]]
+}
+
 #--------------------------------------------------------------------
 # maxConcurrentReconciles
 
@@ -939,9 +1054,9 @@ load _helpers
 }
 
 #--------------------------------------------------------------------
-# globalVaultAuthOptions
+# globalTransformationRef
 
-@test "controller/Deployment: globalVaultAuthOptions defaults" {
+@test "controller/Deployment: globalTransformationRef not set by default" {
   cd `chart_dir`
   local object
   object=$(helm template \
@@ -952,260 +1067,1153 @@ load _helpers
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
   [ "${actual}" = "12" ]
-  actual=$(echo "$object" | yq '.[3]' | tee /dev/stderr)
-  [ "${actual}" = "--global-vault-auth-options=allow-default-globals" ]
 }
 
-@test "controller/Deployment: with globalVaultAuthOptions.allowDefaultGlobals=false" {
+@test "controller/Deployment: with globalTransformationRef.name" {
   cd `chart_dir`
   local object
   object=$(helm template \
   -s templates/deployment.yaml \
-  --set 'controller.manager.globalVaultAuthOptions.allowDefaultGlobals=false' \
+    --namespace vso \
+    --set 'controller.manager.globalTransformationRef.name=global' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "11" ]
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[3]' | tee /dev/stderr)
+  [ "${actual}" = "--global-transformation-ref=vso/global" ]
 }
 
-@test "controller/Deployment: with backoffOnSecretSourceError defaults" {
+@test "controller/Deployment: with globalTransformationRef.name and namespace" {
   cd `chart_dir`
   local object
   object=$(helm template \
   -s templates/deployment.yaml \
+    --set 'controller.manager.globalTransformationOptions.excludeRaw=true' \
+    --set 'controller.manager.globalTransformationRef.name=global' \
+    --set 'controller.manager.globalTransformationRef.namespace=platform' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "12" ]
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.[3]' | tee /dev/stderr)
+  [ "${actual}" = "--global-transformation-options=exclude-raw" ]
   actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-initial-interval=5s" ]
-  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-max-interval=60s" ]
-  actual=$(echo "$object" | yq '.[6]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-max-elapsed-time=0s" ]
-  actual=$(echo "$object" | yq '.[7]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-multiplier=1.50" ]
-  actual=$(echo "$object" | yq '.[8]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-randomization-factor=0.50" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--global-transformation-ref=platform/global" ]
 }
 
-@test "controller/Deployment: with backoffOnSecretSourceError set" {
+#--------------------------------------------------------------------
+# globalVaultAuthOptions
+
+@test "controller/Deployment: globalVaultAuthOptions defaults" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.backoffOnSecretSourceError.initialInterval=30s' \
-  --set 'controller.manager.backoffOnSecretSourceError.maxInterval=300s' \
-  --set 'controller.manager.backoffOnSecretSourceError.maxElapsedTime=24h' \
-  --set 'controller.manager.backoffOnSecretSourceError.multiplier=2.5' \
-  --set 'controller.manager.backoffOnSecretSourceError.randomizationFactor=3.7361' \
+  -s templates/deployment.yaml  \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
   [ "${actual}" = "12" ]
-  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-initial-interval=30s" ]
-  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-max-interval=300s" ]
-  actual=$(echo "$object" | yq '.[6]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-max-elapsed-time=24h" ]
-  actual=$(echo "$object" | yq '.[7]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-multiplier=2.50" ]
-  actual=$(echo "$object" | yq '.[8]' | tee /dev/stderr)
-  [ "${actual}" = "--backoff-randomization-factor=3.74" ]
+  actual=$(echo "$object" | yq '.[3]' | tee /dev/stderr)
+  [ "${actual}" = "--global-vault-auth-options=allow-default-globals" ]
 }
 
-
-#--------------------------------------------------------------------
-# manager logging args
-@test "controller/Deployment: manager logging defaults" {
+@test "controller/Deployment: with globalVaultAuthOptions.allowDefaultGlobals=false" {
   cd `chart_dir`
   local object
   object=$(helm template \
   -s templates/deployment.yaml \
+  --set 'controller.manager.globalVaultAuthOptions.allowDefaultGlobals=false' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "12" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=info" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+  [ "${actual}" = "11" ]
 }
 
-@test "controller/Deployment: manager logging set all" {
+#--------------------------------------------------------------------
+# vaultNamespaceRemap
+
+@test "controller/Deployment: vaultNamespaceRemap defaults" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.logging.level=debug' \
-  --set 'controller.manager.logging.timeEncoding=nanos' \
-  --set 'controller.manager.logging.stacktraceLevel=error' \
+  -s templates/deployment.yaml  \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
   [ "${actual}" = "12" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=debug" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=nanos" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=error" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--vault-namespace-remap*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
 }
 
-@test "controller/Deployment: manager logging set custom log level debug-extended" {
+@test "controller/Deployment: with vaultNamespaceRemap" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.logging.level=debug-extended' \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.vaultNamespaceRemap.tenant-b=org/tenant-b' \
+  --set 'controller.manager.vaultNamespaceRemap.tenant-a=org/tenant-a' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "12" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=5" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--vault-namespace-remap=tenant-a=org/tenant-a,tenant-b=org/tenant-b" ]
 }
 
-@test "controller/Deployment: manager logging set custom log level trace" {
+#--------------------------------------------------------------------
+# vaultLoginMaxConcurrency
+
+@test "controller/Deployment: vaultLoginMaxConcurrency defaults" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.logging.level=trace' \
+  -s templates/deployment.yaml  \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
   [ "${actual}" = "12" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=6" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--vault-login-max-concurrency*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
 }
 
-@test "controller/Deployment: manager logging with intersecting log level extraArgs" {
+@test "controller/Deployment: with vaultLoginMaxConcurrency" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.extraArgs={--zap-log-level=extra,--bar=qux}' \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.vaultLoginMaxConcurrency.kubernetes=10' \
+  --set 'controller.manager.vaultLoginMaxConcurrency.default=20' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
   [ "${actual}" = "13" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=panic" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=extra" ]
-  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
-  [ "${actual}" = "--bar=qux" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--vault-login-max-concurrency=default=20,kubernetes=10" ]
 }
 
-@test "controller/Deployment: manager logging with intersecting time encoding extraArgs" {
+#--------------------------------------------------------------------
+# vaultConnectionHealth
+
+@test "controller/Deployment: vaultConnectionHealth defaults" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.extraArgs={--zap-time-encoding=extra,--bar=qux}' \
+  -s templates/deployment.yaml  \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "13" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=info" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=panic" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=extra" ]
-  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
-  [ "${actual}" = "--bar=qux" ]
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--vault-connection-health*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
 }
 
-@test "controller/Deployment: manager logging with intersecting stacktrace level extraArgs" {
+@test "controller/Deployment: checks without vaultConnectionHealth.interval" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.extraArgs={--zap-stacktrace-level=extra,--bar=qux}' \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.vaultConnectionHealth.checks={readyz}' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
-  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "13" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=info" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-stacktrace-level=extra" ]
-  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
-  [ "${actual}" = "--bar=qux" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--vault-connection-health*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
 }
 
-@test "controller/Deployment: manager logging with all intersecting extraArgs" {
+@test "controller/Deployment: with vaultConnectionHealth" {
   cd `chart_dir`
   local object
   object=$(helm template \
-  --debug \
-  -s templates/deployment.yaml \
-  --set 'controller.manager.extraArgs={--zap-log-level=extra,-zap-time-encoding=extra,-zap-stacktrace-level=extra,--bar=qux}' \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.vaultConnectionHealth.interval=30s' \
+  --set 'controller.manager.vaultConnectionHealth.checks={healthz,readyz}' \
   . | tee /dev/stderr |
   yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
   actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
-  [ "${actual}" = "13" ]
-  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
-  [ "${actual}" = "--zap-log-level=extra" ]
-  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
-  [ "${actual}" = "-zap-time-encoding=extra" ]
-  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
-  [ "${actual}" = "-zap-stacktrace-level=extra" ]
-  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
-  [ "${actual}" = "--bar=qux" ]
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--vault-connection-health-interval=30s" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--vault-connection-health-checks=healthz,readyz" ]
 }
 
 #--------------------------------------------------------------------
-# image.pullPolicy
-@test "controller/Deployment: imagePullPolicy default" {
+# allowedVaultNamespaces
+
+@test "controller/Deployment: allowedVaultNamespaces defaults" {
   cd `chart_dir`
-  local deployment=$(helm template \
+  local object
+  object=$(helm template \
   -s templates/deployment.yaml  \
   . | tee /dev/stderr |
-  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager")' | tee /dev/stderr)
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
 
   local actual
-  actual=$(echo "${deployment}" | yq '.spec.template.spec.containers[] | select(.name == "manager") | .imagePullPolicy' | tee /dev/stderr)
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--allowed-vault-namespaces*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with allowedVaultNamespaces" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.allowedVaultNamespaces.team-b={org/team-b}' \
+  --set 'controller.manager.allowedVaultNamespaces.team-a={org/team-a,org/shared}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--allowed-vault-namespaces=team-a=org/team-a,team-a=org/shared,team-b=org/team-b" ]
+}
+
+#--------------------------------------------------------------------
+# vaultPaths
+
+@test "controller/Deployment: vaultPaths defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--*-vault-paths*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with vaultPaths" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.vaultPaths.allowed={kv/apps/*,db/creds/*}' \
+  --set 'controller.manager.vaultPaths.denied={sys/*}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--allowed-vault-paths=kv/apps/*,db/creds/*" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--denied-vault-paths=sys/*" ]
+}
+
+#--------------------------------------------------------------------
+# watchNamespaces
+
+@test "controller/Deployment: watchNamespaces defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--watch-namespaces*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with watchNamespaces" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.watchNamespaces={team-a,team-b}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--watch-namespaces=team-a,team-b" ]
+}
+
+#--------------------------------------------------------------------
+# followerMode
+
+@test "controller/Deployment: followerMode defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--follower-mode")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with followerMode" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.followerMode=true' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--follower-mode" ]
+}
+
+#--------------------------------------------------------------------
+# externalSecretsCompat
+
+@test "controller/Deployment: externalSecretsCompat defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--external-secrets-compat")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with externalSecretsCompat" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.externalSecretsCompat=true' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--external-secrets-compat" ]
+}
+
+#--------------------------------------------------------------------
+# profiling
+
+@test "controller/Deployment: profiling defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.profiling.cpuDuration=15s' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--profile*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with all profiling options" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.profiling.interval=5m' \
+  --set 'controller.manager.profiling.cpuDuration=15s' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--profile-interval=5m" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--profile-cpu-duration=15s" ]
+}
+
+#--------------------------------------------------------------------
+# sharding
+
+@test "controller/Deployment: sharding defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--shard*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with sharding count" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.sharding.count=3' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--shard-count=3" ]
+}
+
+#--------------------------------------------------------------------
+# kvReadBatchWindow
+
+@test "controller/Deployment: kvReadBatchWindow defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--kv-read-batch*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with kvReadBatchWindow" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.kvReadBatchWindow=500ms' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--kv-read-batch-window=500ms" ]
+}
+
+#--------------------------------------------------------------------
+# leaseRenewalBatchWindow
+
+@test "controller/Deployment: leaseRenewalBatchWindow defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--lease-renewal-batch*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with leaseRenewalBatchWindow" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.leaseRenewalBatchWindow=30s' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--lease-renewal-batch-window=30s" ]
+}
+
+#--------------------------------------------------------------------
+# hmacKeyRotationInterval
+
+@test "controller/Deployment: hmacKeyRotationInterval defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--hmac-key-rotation*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with hmacKeyRotationInterval" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.hmacKeyRotationInterval=720h' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--hmac-key-rotation-interval=720h" ]
+}
+
+#--------------------------------------------------------------------
+# vaultReadCacheTTL
+
+@test "controller/Deployment: vaultReadCacheTTL defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--vault-read-cache*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with vaultReadCacheTTL" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.vaultReadCacheTTL=30s' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--vault-read-cache-ttl=30s" ]
+}
+
+#--------------------------------------------------------------------
+# startupSync
+
+@test "controller/Deployment: startupSync defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--startup-sync*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with all startupSync options" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.startupSync.window=5m' \
+  --set 'controller.manager.startupSync.kindWindows.VaultPKISecret=0s' \
+  --set 'controller.manager.startupSync.kindWindows.VaultDynamicSecret=10m' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--startup-sync-window=5m" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--startup-sync-window-kinds=VaultDynamicSecret=10m,VaultPKISecret=0s" ]
+}
+
+#--------------------------------------------------------------------
+# hvsWebhook
+
+@test "controller/Deployment: hvsWebhook defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.args | map(select(. == "--hvs-webhook-bind-address*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+  actual=$(echo "$object" | yq '.env | map(select(.name == "VSO_HVS_WEBHOOK_HMAC_KEY")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+  actual=$(echo "$object" | yq '.ports' | tee /dev/stderr)
+  [ "${actual}" = "null" ]
+}
+
+@test "controller/Deployment: with hvsWebhook" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.hvsWebhook.enabled=true' \
+  --set 'controller.manager.hvsWebhook.port=9555' \
+  --set 'controller.manager.hvsWebhook.hmacKeySecretRef.name=hvs-webhook' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.args[4]' | tee /dev/stderr)
+  [ "${actual}" = "--hvs-webhook-bind-address=:9555" ]
+  actual=$(echo "$object" | yq '.env[] | select(.name == "VSO_HVS_WEBHOOK_HMAC_KEY") | .valueFrom.secretKeyRef.name' | tee /dev/stderr)
+  [ "${actual}" = "hvs-webhook" ]
+  actual=$(echo "$object" | yq '.env[] | select(.name == "VSO_HVS_WEBHOOK_HMAC_KEY") | .valueFrom.secretKeyRef.key' | tee /dev/stderr)
+  [ "${actual}" = "hmacKey" ]
+  actual=$(echo "$object" | yq '.ports[0].containerPort' | tee /dev/stderr)
+  [ "${actual}" = "9555" ]
+  actual=$(echo "$object" | yq '.ports[0].name' | tee /dev/stderr)
+  [ "${actual}" = "hvs-webhook" ]
+}
+
+@test "controller/Deployment: hvsWebhook requires hmacKeySecretRef.name" {
+  cd `chart_dir`
+  run helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.hvsWebhook.enabled=true' \
+  .
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "controller.manager.hvsWebhook.hmacKeySecretRef.name is required" ]]
+}
+
+#--------------------------------------------------------------------
+# acmeHTTP01Solver
+
+@test "controller/Deployment: acmeHTTP01Solver defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | map(select(. == "--acme-http01-bind-address*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+  actual=$(echo "$object" | yq '.ports' | tee /dev/stderr)
+  [ "${actual}" = "null" ]
+}
+
+@test "controller/Deployment: with acmeHTTP01Solver" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.acmeHTTP01Solver.enabled=true' \
+  --set 'controller.manager.acmeHTTP01Solver.port=8090' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.args[4]' | tee /dev/stderr)
+  [ "${actual}" = "--acme-http01-bind-address=:8090" ]
+  actual=$(echo "$object" | yq '.ports | length' | tee /dev/stderr)
+  [ "${actual}" = "1" ]
+  actual=$(echo "$object" | yq '.ports[0].containerPort' | tee /dev/stderr)
+  [ "${actual}" = "8090" ]
+  actual=$(echo "$object" | yq '.ports[0].name' | tee /dev/stderr)
+  [ "${actual}" = "acme-http01" ]
+}
+
+@test "controller/Deployment: with acmeHTTP01Solver and hvsWebhook" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.acmeHTTP01Solver.enabled=true' \
+  --set 'controller.manager.hvsWebhook.enabled=true' \
+  --set 'controller.manager.hvsWebhook.hmacKeySecretRef.name=hvs-webhook' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.args[5]' | tee /dev/stderr)
+  [ "${actual}" = "--acme-http01-bind-address=:8089" ]
+  actual=$(echo "$object" | yq '.ports | length' | tee /dev/stderr)
+  [ "${actual}" = "2" ]
+  actual=$(echo "$object" | yq '.ports[0].name' | tee /dev/stderr)
+  [ "${actual}" = "hvs-webhook" ]
+  actual=$(echo "$object" | yq '.ports[1].name' | tee /dev/stderr)
+  [ "${actual}" = "acme-http01" ]
+}
+
+#--------------------------------------------------------------------
+# reconcileShedding
+
+@test "controller/Deployment: reconcileShedding defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.reconcileShedding.kinds={VaultStaticSecret}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--reconcile-shedding*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with reconcileShedding.threshold" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.reconcileShedding.threshold=500' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--reconcile-shedding-threshold=500" ]
+}
+
+@test "controller/Deployment: with all reconcileShedding options" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.reconcileShedding.threshold=500' \
+  --set 'controller.manager.reconcileShedding.kinds={VaultStaticSecret,HCPVaultSecretsApp}' \
+  --set 'controller.manager.reconcileShedding.namespaces={dev,test}' \
+  --set 'controller.manager.reconcileShedding.deferAfter=2m' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "16" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--reconcile-shedding-threshold=500" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--reconcile-shedding-kinds=VaultStaticSecret,HCPVaultSecretsApp" ]
+  actual=$(echo "$object" | yq '.[6]' | tee /dev/stderr)
+  [ "${actual}" = "--reconcile-shedding-namespaces=dev,test" ]
+  actual=$(echo "$object" | yq '.[7]' | tee /dev/stderr)
+  [ "${actual}" = "--reconcile-shedding-defer-after=2m" ]
+}
+
+# freezeWindow
+
+@test "controller/Deployment: freezeWindow defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.freezeWindow.duration=60h' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq 'map(select(. == "--freeze-window*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with all freezeWindow options" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.freezeWindow.schedule=0 22 * * 5' \
+  --set 'controller.manager.freezeWindow.duration=60h' \
+  --set 'controller.manager.freezeWindow.exemptSelector=tier=critical' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "15" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--freeze-window-schedule=0 22 * * 5" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--freeze-window-duration=60h" ]
+  actual=$(echo "$object" | yq '.[6]' | tee /dev/stderr)
+  [ "${actual}" = "--freeze-window-exempt-selector=tier=critical" ]
+}
+
+@test "controller/Deployment: freezeWindow.schedule requires duration" {
+  cd `chart_dir`
+  run helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.freezeWindow.schedule=0 22 * * 5' \
+  .
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "controller.manager.freezeWindow.duration is required" ]]
+}
+
+#--------------------------------------------------------------------
+# syncFailureAlert
+
+@test "controller/Deployment: syncFailureAlert defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.syncFailureAlert.webhook.urlSecretRef.name=vso-alerts' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.args | map(select(. == "--sync-failure*")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+  actual=$(echo "$object" | yq '.env | map(select(.name == "VSO_SYNC_FAILURE_WEBHOOK_URL")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with syncFailureAlert" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.syncFailureAlert.threshold=5' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.args[4]' | tee /dev/stderr)
+  [ "${actual}" = "--sync-failure-threshold=5" ]
+  actual=$(echo "$object" | yq '.args[5]' | tee /dev/stderr)
+  [ "${actual}" = "--sync-failure-webhook-format=generic" ]
+  actual=$(echo "$object" | yq '.env | map(select(.name == "VSO_SYNC_FAILURE_WEBHOOK_URL")) | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
+
+@test "controller/Deployment: with syncFailureAlert webhook" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml  \
+  --set 'controller.manager.syncFailureAlert.threshold=3' \
+  --set 'controller.manager.syncFailureAlert.webhook.urlSecretRef.name=vso-alerts' \
+  --set 'controller.manager.syncFailureAlert.webhook.urlSecretRef.key=slack' \
+  --set 'controller.manager.syncFailureAlert.webhook.format=slack' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '.args | length' | tee /dev/stderr)
+  [ "${actual}" = "14" ]
+  actual=$(echo "$object" | yq '.args[4]' | tee /dev/stderr)
+  [ "${actual}" = "--sync-failure-threshold=3" ]
+  actual=$(echo "$object" | yq '.args[5]' | tee /dev/stderr)
+  [ "${actual}" = "--sync-failure-webhook-format=slack" ]
+  actual=$(echo "$object" | yq '.env[] | select(.name == "VSO_SYNC_FAILURE_WEBHOOK_URL") | .valueFrom.secretKeyRef.name' | tee /dev/stderr)
+  [ "${actual}" = "vso-alerts" ]
+  actual=$(echo "$object" | yq '.env[] | select(.name == "VSO_SYNC_FAILURE_WEBHOOK_URL") | .valueFrom.secretKeyRef.key' | tee /dev/stderr)
+  [ "${actual}" = "slack" ]
+}
+
+@test "controller/Deployment: with backoffOnSecretSourceError defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-initial-interval=5s" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-max-interval=60s" ]
+  actual=$(echo "$object" | yq '.[6]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-max-elapsed-time=0s" ]
+  actual=$(echo "$object" | yq '.[7]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-multiplier=1.50" ]
+  actual=$(echo "$object" | yq '.[8]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-randomization-factor=0.50" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+}
+
+@test "controller/Deployment: with backoffOnSecretSourceError set" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.backoffOnSecretSourceError.initialInterval=30s' \
+  --set 'controller.manager.backoffOnSecretSourceError.maxInterval=300s' \
+  --set 'controller.manager.backoffOnSecretSourceError.maxElapsedTime=24h' \
+  --set 'controller.manager.backoffOnSecretSourceError.multiplier=2.5' \
+  --set 'controller.manager.backoffOnSecretSourceError.randomizationFactor=3.7361' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.[4]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-initial-interval=30s" ]
+  actual=$(echo "$object" | yq '.[5]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-max-interval=300s" ]
+  actual=$(echo "$object" | yq '.[6]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-max-elapsed-time=24h" ]
+  actual=$(echo "$object" | yq '.[7]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-multiplier=2.50" ]
+  actual=$(echo "$object" | yq '.[8]' | tee /dev/stderr)
+  [ "${actual}" = "--backoff-randomization-factor=3.74" ]
+}
+
+
+#--------------------------------------------------------------------
+# manager logging args
+@test "controller/Deployment: manager logging defaults" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=info" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+}
+
+@test "controller/Deployment: manager logging set all" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.logging.level=debug' \
+  --set 'controller.manager.logging.timeEncoding=nanos' \
+  --set 'controller.manager.logging.stacktraceLevel=error' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=debug" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=nanos" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=error" ]
+}
+
+@test "controller/Deployment: manager logging set custom log level debug-extended" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.logging.level=debug-extended' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=5" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+}
+
+@test "controller/Deployment: manager logging set custom log level trace" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.logging.level=trace' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "12" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=6" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+}
+
+@test "controller/Deployment: manager logging with intersecting log level extraArgs" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.extraArgs={--zap-log-level=extra,--bar=qux}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=extra" ]
+  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
+  [ "${actual}" = "--bar=qux" ]
+}
+
+@test "controller/Deployment: manager logging with intersecting time encoding extraArgs" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.extraArgs={--zap-time-encoding=extra,--bar=qux}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=info" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=panic" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=extra" ]
+  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
+  [ "${actual}" = "--bar=qux" ]
+}
+
+@test "controller/Deployment: manager logging with intersecting stacktrace level extraArgs" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.extraArgs={--zap-stacktrace-level=extra,--bar=qux}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=info" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-time-encoding=rfc3339" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-stacktrace-level=extra" ]
+  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
+  [ "${actual}" = "--bar=qux" ]
+}
+
+@test "controller/Deployment: manager logging with all intersecting extraArgs" {
+  cd `chart_dir`
+  local object
+  object=$(helm template \
+  --debug \
+  -s templates/deployment.yaml \
+  --set 'controller.manager.extraArgs={--zap-log-level=extra,-zap-time-encoding=extra,-zap-stacktrace-level=extra,--bar=qux}' \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager") | .spec.template.spec.containers[] | select(.name == "manager") | .args' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "$object" | yq '. | length' | tee /dev/stderr)
+  [ "${actual}" = "13" ]
+  actual=$(echo "$object" | yq '.[9]' | tee /dev/stderr)
+  [ "${actual}" = "--zap-log-level=extra" ]
+  actual=$(echo "$object" | yq '.[10]' | tee /dev/stderr)
+  [ "${actual}" = "-zap-time-encoding=extra" ]
+  actual=$(echo "$object" | yq '.[11]' | tee /dev/stderr)
+  [ "${actual}" = "-zap-stacktrace-level=extra" ]
+  actual=$(echo "$object" | yq '.[12]' | tee /dev/stderr)
+  [ "${actual}" = "--bar=qux" ]
+}
+
+#--------------------------------------------------------------------
+# image.pullPolicy
+@test "controller/Deployment: imagePullPolicy default" {
+  cd `chart_dir`
+  local deployment=$(helm template \
+  -s templates/deployment.yaml  \
+  . | tee /dev/stderr |
+  yq 'select(.kind == "Deployment" and .metadata.labels."control-plane" == "controller-manager")' | tee /dev/stderr)
+
+  local actual
+  actual=$(echo "${deployment}" | yq '.spec.template.spec.containers[] | select(.name == "manager") | .imagePullPolicy' | tee /dev/stderr)
   [ "${actual}" = "IfNotPresent" ]
 
   actual=$(echo "${deployment}" | yq '.spec.template.spec.containers[] | select(.name == "kube-rbac-proxy") | .imagePullPolicy' | tee /dev/stderr)
@@ -1284,11 +2292,25 @@ load _helpers
   [ "$(echo "${job}" | \
   yq '.spec.template.spec.containers[0].command[0] == "/vault-secrets-operator"')" = "true" ]
   [ "$(echo "${job}" | \
-  yq '(.spec.template.spec.containers[0].args | length) == "2"')" = "true" ]
+  yq '(.spec.template.spec.containers[0].args | length) == "3"')" = "true" ]
   [ "$(echo "${job}" | \
   yq '.spec.template.spec.containers[0].args[0]  == "--uninstall"')" = "true" ]
   [ "$(echo "${job}" | \
   yq '.spec.template.spec.containers[0].args[1]  == "--pre-delete-hook-timeout-seconds=120"')" = "true" ]
   [ "$(echo "${job}" | \
+  yq '.spec.template.spec.containers[0].args[2]  == "--uninstall-destination-secrets=retain"')" = "true" ]
+  [ "$(echo "${job}" | \
   yq '(.spec.template.spec.containers[0].imagePullPolicy == "IfNotPresent")')" = "true" ]
 }
+
+@test "controller/Deployment: hookPreDelete Job with uninstallDestinationSecrets" {
+  cd "$(chart_dir)"
+  local job
+  job=$(helm template \
+    -s templates/deployment.yaml  \
+    --set 'controller.uninstallDestinationSecrets=orphan' \
+    . | tee /dev/stderr |
+  yq 'select(.kind == "Job")' | tee /dev/stderr)
+  [ "$(echo "${job}" | \
+  yq '.spec.template.spec.containers[0].args[2]  == "--uninstall-destination-secrets=orphan"')" = "true" ]
+}
@@ -95,7 +95,7 @@ load _helpers
     local cr
     cr="$(echo "${object}" | yq 'select(di == 1)')"
     [ "$(echo "${cr}" | yq '.kind == "ClusterRole"')" = "true" ]
-    [ "$(echo "${cr}" | yq '(.rules | length) == 1')" = "true" ]
+    [ "$(echo "${cr}" | yq '(.rules | length) == 3')" = "true" ]
     [ "$(echo "${cr}" | yq '(.rules[0] | length) == 3')" = "true" ]
     [ "$(echo "${cr}" | yq '.rules[0].apiGroups[0] == "apiextensions.k8s.io"')" = "true" ]
     [ "$(echo "${cr}" | yq '(.rules[0].resources | length) == 1')" = "true" ]
@@ -107,6 +107,16 @@ load _helpers
     [ "$(echo "${cr}" | yq '.rules[0].verbs[3] == "list"')" = "true" ]
     [ "$(echo "${cr}" | yq '.rules[0].verbs[4] == "patch"')" = "true" ]
     [ "$(echo "${cr}" | yq '.rules[0].verbs[5] == "update"')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[1].apiGroups[0] == "apiextensions.k8s.io"')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[1].resources[0] == "customresourcedefinitions/status"')" = "true" ]
+    [ "$(echo "${cr}" | yq '(.rules[1].verbs | length) == 1')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[1].verbs[0] == "patch"')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[2].apiGroups[0] == "secrets.hashicorp.com"')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[2].resources[0] == "*"')" = "true" ]
+    [ "$(echo "${cr}" | yq '(.rules[2].verbs | length) == 3')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[2].verbs[0] == "get"')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[2].verbs[1] == "list"')" = "true" ]
+    [ "$(echo "${cr}" | yq '.rules[2].verbs[2] == "update"')" = "true" ]
 }
 
 @test "hookUpgradeCRDs: Job extended" {
@@ -204,6 +214,25 @@ load _helpers
       yq '.spec.template.spec.containers[0].env[0].value == "64s"')" = "true" ]
 }
 
+@test "hookUpgradeCRDs: Job extended with dryRun" {
+    pushd "$(chart_dir)" > /dev/stderr
+    local object
+    object=$(helm template \
+        -s templates/hook-upgrade-crds.yaml \
+        --set hooks.upgradeCRDs.dryRun=true \
+        . )
+
+    local job
+    job="$(echo "${object}" | yq 'select(di == 3)' | tee /dev/stderr)"
+    [ "$(echo "${job}" | yq '.kind == "Job"')" = "true" ]
+    [ "$(echo "${job}" | \
+      yq '(.spec.template.spec.containers[0].env | length == 2)')" = "true" ]
+    [ "$(echo "${job}" | \
+      yq '.spec.template.spec.containers[0].env[1].name == "VSO_UPGRADE_CRDS_DRY_RUN"')" = "true" ]
+    [ "$(echo "${job}" | \
+      yq '.spec.template.spec.containers[0].env[1].value == "true"')" = "true" ]
+}
+
 @test "hookUpgradeCRDs: Job extended with imagePullPolicy" {
     pushd "$(chart_dir)" > /dev/stderr
     local object
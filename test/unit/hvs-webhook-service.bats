@@ -0,0 +1,49 @@
+#!/usr/bin/env bats
+
+load _helpers
+
+#--------------------------------------------------------------------
+# enabled/disabled
+
+@test "hvsWebhook/Service: disabled by default" {
+  cd `chart_dir`
+  local actual=$(helm template \
+      -s templates/hvs-webhook-service.yaml  \
+      . | tee /dev/stderr |
+      yq 'length > 0' | tee /dev/stderr)
+  [ "${actual}" = "false" ]
+}
+
+@test "hvsWebhook/Service: enabled" {
+  cd `chart_dir`
+  local object=$(helm template \
+      -s templates/hvs-webhook-service.yaml  \
+      --set 'controller.manager.hvsWebhook.enabled=true' \
+      --set 'controller.manager.hvsWebhook.hmacKeySecretRef.name=hvs-webhook' \
+      . | tee /dev/stderr)
+
+  local actual=$(echo "$object" | yq '.spec.type' | tee /dev/stderr)
+  [ "${actual}" = "ClusterIP" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].name' | tee /dev/stderr)
+  [ "${actual}" = "hvs-webhook" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].port' | tee /dev/stderr)
+  [ "${actual}" = "9444" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].targetPort' | tee /dev/stderr)
+  [ "${actual}" = "hvs-webhook" ]
+}
+
+@test "hvsWebhook/Service: port and service type can be set" {
+  cd `chart_dir`
+  local object=$(helm template \
+      -s templates/hvs-webhook-service.yaml  \
+      --set 'controller.manager.hvsWebhook.enabled=true' \
+      --set 'controller.manager.hvsWebhook.hmacKeySecretRef.name=hvs-webhook' \
+      --set 'controller.manager.hvsWebhook.port=9555' \
+      --set 'controller.manager.hvsWebhook.serviceType=LoadBalancer' \
+      . | tee /dev/stderr)
+
+  local actual=$(echo "$object" | yq '.spec.type' | tee /dev/stderr)
+  [ "${actual}" = "LoadBalancer" ]
+  actual=$(echo "$object" | yq '.spec.ports[0].port' | tee /dev/stderr)
+  [ "${actual}" = "9555" ]
+}
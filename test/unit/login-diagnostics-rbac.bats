@@ -0,0 +1,42 @@
+#!/usr/bin/env bats
+
+load _helpers
+
+#--------------------------------------------------------------------
+# enabled/disabled
+
+@test "loginDiagnostics/RBAC: disabled by default" {
+  cd `chart_dir`
+  local actual=$(helm template \
+      -s templates/login-diagnostics-rbac.yaml  \
+      . | tee /dev/stderr |
+      yq 'length > 0' | tee /dev/stderr)
+  [ "${actual}" = "false" ]
+}
+
+@test "loginDiagnostics/RBAC: enabled" {
+  cd `chart_dir`
+  local object=$(helm template \
+      -s templates/login-diagnostics-rbac.yaml  \
+      --set 'controller.manager.loginDiagnostics.enabled=true' \
+      . | tee /dev/stderr)
+
+  local actual=$(echo "$object" | yq 'select(.kind == "ClusterRole") | .rules[0].resources[0]' | tee /dev/stderr)
+  [ "${actual}" = "tokenreviews" ]
+  actual=$(echo "$object" | yq 'select(.kind == "ClusterRole") | .rules[1].resources[0]' | tee /dev/stderr)
+  [ "${actual}" = "subjectaccessreviews" ]
+  actual=$(echo "$object" | yq 'select(.kind == "ClusterRoleBinding") | .roleRef.name' | tee /dev/stderr)
+  [ "${actual}" = "release-name-vault-secrets-operator-login-diagnostics-role" ]
+  actual=$(echo "$object" | yq 'select(.kind == "ClusterRoleBinding") | .subjects[0].name' | tee /dev/stderr)
+  [ "${actual}" = "release-name-vault-secrets-operator-controller-manager" ]
+}
+
+@test "loginDiagnostics/RBAC: not granted by the manager role" {
+  cd `chart_dir`
+  local actual=$(helm template \
+      -s templates/role.yaml  \
+      --set 'controller.manager.loginDiagnostics.enabled=true' \
+      . | tee /dev/stderr |
+      yq '[.rules[].resources[] | select(. == "tokenreviews" or . == "subjectaccessreviews")] | length' | tee /dev/stderr)
+  [ "${actual}" = "0" ]
+}
@@ -11,13 +11,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	yamlv3 "gopkg.in/yaml.v3"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/json"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -147,50 +151,356 @@ func DecodeCRDs(input io.Reader) ([]apiextensionsv1.CustomResourceDefinition, er
 	return crds, nil
 }
 
+// CRDChange describes the changes to a CRD in the cluster that are pending
+// from its manifest.
+type CRDChange struct {
+	// Name of the CRD.
+	Name string
+	// Create is true if the CRD does not exist in the cluster.
+	Create bool
+	// Changed is true if the CRD in the cluster differs from its manifest.
+	Changed bool
+	// AddedVersions are the versions that are only in the manifest.
+	AddedVersions []string
+	// RemovedVersions are the versions that are no longer in the manifest, they
+	// are pruned from the CRD.
+	RemovedVersions []string
+	// ChangedVersions are the versions whose definition differs from the
+	// manifest.
+	ChangedVersions []string
+	// MigratedVersions are the stored versions whose custom resources are
+	// migrated to the CRD's storage version.
+	MigratedVersions []string
+}
+
+// Pending returns true if the CRD must be upgraded.
+func (c CRDChange) Pending() bool {
+	return c.Create || c.Changed || len(c.MigratedVersions) > 0
+}
+
+func (c CRDChange) String() string {
+	switch {
+	case c.Create:
+		return c.Name + ": create"
+	case !c.Pending():
+		return c.Name + ": unchanged"
+	}
+
+	parts := []string{c.Name + ": update"}
+	for _, v := range []struct {
+		name     string
+		versions []string
+	}{
+		{name: "added", versions: c.AddedVersions},
+		{name: "removed", versions: c.RemovedVersions},
+		{name: "changed", versions: c.ChangedVersions},
+		{name: "migrated", versions: c.MigratedVersions},
+	} {
+		if len(v.versions) > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%s", v.name, strings.Join(v.versions, ",")))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// crdUpgrade holds the CRD in the cluster, the CRD that it is upgraded to,
+// and the changes between them. The current CRD is nil if it does not exist in
+// the cluster.
+type crdUpgrade struct {
+	cur     *apiextensionsv1.CustomResourceDefinition
+	desired *apiextensionsv1.CustomResourceDefinition
+	change  CRDChange
+}
+
+// planCRDUpgrade returns the crdUpgrade of the CRD in the cluster to crd.
+func planCRDUpgrade(ctx context.Context, c ctrlclient.Client, crd *apiextensionsv1.CustomResourceDefinition) (*crdUpgrade, error) {
+	var cur apiextensionsv1.CustomResourceDefinition
+	if err := c.Get(ctx, ctrlclient.ObjectKey{Name: crd.Name}, &cur); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &crdUpgrade{
+				desired: crd,
+				change: CRDChange{
+					Name:   crd.Name,
+					Create: true,
+				},
+			}, nil
+		}
+		return nil, err
+	}
+
+	desired := cur.DeepCopy()
+	caBundle := conversionCABundle(&cur)
+	desired.Spec = *crd.Spec.DeepCopy()
+	desired.ObjectMeta.Annotations = crd.ObjectMeta.Annotations
+	desired.ObjectMeta.Labels = crd.ObjectMeta.Labels
+	// the conversion webhook's CA bundle is typically injected in the cluster,
+	// so it is retained unless the manifest sets one.
+	if conv := desired.Spec.Conversion; len(caBundle) > 0 && conversionCABundle(desired) == nil &&
+		conv != nil && conv.Webhook != nil && conv.Webhook.ClientConfig != nil {
+		conv.Webhook.ClientConfig.CABundle = caBundle
+	}
+
+	change := CRDChange{
+		Name: crd.Name,
+		Changed: !equality.Semantic.DeepEqual(cur.Spec, desired.Spec) ||
+			!equality.Semantic.DeepEqual(cur.Annotations, desired.Annotations) ||
+			!equality.Semantic.DeepEqual(cur.Labels, desired.Labels),
+	}
+
+	curVersions := make(map[string]apiextensionsv1.CustomResourceDefinitionVersion)
+	for _, v := range cur.Spec.Versions {
+		curVersions[v.Name] = v
+	}
+	desiredVersions := make(map[string]apiextensionsv1.CustomResourceDefinitionVersion)
+	var storageVersion string
+	for _, v := range desired.Spec.Versions {
+		desiredVersions[v.Name] = v
+		if v.Storage {
+			storageVersion = v.Name
+		}
+		if curVersion, ok := curVersions[v.Name]; !ok {
+			change.AddedVersions = append(change.AddedVersions, v.Name)
+		} else if !equality.Semantic.DeepEqual(curVersion, v) {
+			change.ChangedVersions = append(change.ChangedVersions, v.Name)
+		}
+	}
+	for _, v := range cur.Spec.Versions {
+		if _, ok := desiredVersions[v.Name]; !ok {
+			change.RemovedVersions = append(change.RemovedVersions, v.Name)
+		}
+	}
+	for _, v := range cur.Status.StoredVersions {
+		if v != storageVersion {
+			change.MigratedVersions = append(change.MigratedVersions, v)
+		}
+	}
+
+	return &crdUpgrade{
+		cur:     &cur,
+		desired: desired,
+		change:  change,
+	}, nil
+}
+
+// loadCRDs loads the CRDs from dir, an error is returned if there are none.
+func loadCRDs(dir string) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	crds, err := LoadCRDsFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(crds) == 0 {
+		return nil, fmt.Errorf("no CRDs found in directory %q", dir)
+	}
+
+	return crds, nil
+}
+
+// DiffCRDs returns the changes that UpgradeCRDs would make to the CRDs in the
+// cluster from the CRD YAML manifest files in dir, without making any changes.
+// The Client must have the apiextensionsv1.Scheme registered.
+func DiffCRDs(ctx context.Context, c ctrlclient.Client, dir string) ([]CRDChange, error) {
+	crds, err := loadCRDs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	var changes []CRDChange
+	for _, crd := range crds {
+		u, err := planCRDUpgrade(ctx, c, &crd)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to diff CRD %q: %w", crd.Name, err))
+			continue
+		}
+		changes = append(changes, u.change)
+	}
+
+	return changes, errs
+}
+
 // UpgradeCRDs upgrades custom resource definitions a directory containing CRD
 // YAML manifest files. It only supports
 // apiextensionsv1.CustomResourceDefinition. If the CRD exists in the cluster, it
 // will be patched from the contents from the corresponding manifest file. If the
 // CRD does not exist, it will be created. The Client must have the
 // apiextensionsv1.Scheme registered.
+//
+// The custom resources of the CRD are migrated to its storage version, see
+// MigrateStoredVersions. The versions that are no longer in the manifest are
+// pruned from the CRD, a version is only pruned once none of the custom
+// resources are stored in it.
 func UpgradeCRDs(ctx context.Context, c ctrlclient.Client, dir string) error {
 	logger := zap.New().WithName("UpgradeCRDs").WithValues(
 		"version", version.Version(), "dir", dir)
 
-	crds, err := LoadCRDsFromDir(dir)
+	crds, err := loadCRDs(dir)
 	if err != nil {
 		return err
 	}
 
-	if len(crds) == 0 {
-		return fmt.Errorf("no CRDs found in directory %q", dir)
-	}
-
 	// TODO(future): add support for optionally deleting obsolete CRDs
 	var errs error
 	for _, crd := range crds {
-		var cur apiextensionsv1.CustomResourceDefinition
-		if err := c.Get(ctx, ctrlclient.ObjectKey{Name: crd.Name}, &cur); err != nil {
-			if apierrors.IsNotFound(err) {
-				logger.Info("Creating", "name", crd.Name, "gvk", crd.GroupVersionKind())
-				if err := c.Create(ctx, &crd); err != nil {
-					return err
-				}
-			} else {
-				errs = errors.Join(errs, err)
+		u, err := planCRDUpgrade(ctx, c, &crd)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to get CRD %q: %w", crd.Name, err))
+			continue
+		}
+
+		if u.change.Create {
+			logger.Info("Creating", "name", crd.Name, "gvk", crd.GroupVersionKind())
+			if err := c.Create(ctx, &crd); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to create CRD %q: %w", crd.Name, err))
 			}
-		} else {
-			logger.Info("Patching",
-				"name", crd.Name, "gvk", crd.GroupVersionKind(),
-				"uid", cur.GetUID(), "resourceVersion", cur.GetResourceVersion(),
-			)
-			patch := ctrlclient.MergeFrom(cur.DeepCopy())
-			cur.Spec = crd.Spec
-			cur.ObjectMeta.Annotations = crd.ObjectMeta.Annotations
-			cur.ObjectMeta.Labels = crd.ObjectMeta.Labels
-			errors.Join(errs, c.Patch(ctx, &cur, patch))
+			continue
+		}
+
+		if !u.change.Pending() {
+			logger.Info("Unchanged", "name", crd.Name, "gvk", crd.GroupVersionKind())
+			continue
+		}
+
+		logger.Info("Patching",
+			"name", crd.Name, "gvk", crd.GroupVersionKind(),
+			"uid", u.cur.GetUID(), "resourceVersion", u.cur.GetResourceVersion(),
+			"change", u.change.String(),
+		)
+		if err := upgradeCRD(ctx, c, u); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to upgrade CRD %q: %w", crd.Name, err))
 		}
 	}
 
 	return errs
 }
+
+// upgradeCRD patches the CRD in the cluster to the desired CRD. The removed
+// versions that are stored are retained, as non-storage versions, until the
+// custom resources have been migrated to the storage version, after which they
+// are pruned.
+func upgradeCRD(ctx context.Context, c ctrlclient.Client, u *crdUpgrade) error {
+	cur := u.cur
+	var retained []string
+	versions := u.desired.DeepCopy().Spec.Versions
+	for _, v := range cur.Spec.Versions {
+		if slices.Contains(u.change.RemovedVersions, v.Name) &&
+			slices.Contains(cur.Status.StoredVersions, v.Name) {
+			v.Storage = false
+			versions = append(versions, v)
+			retained = append(retained, v.Name)
+		}
+	}
+
+	patch := ctrlclient.MergeFrom(cur.DeepCopy())
+	cur.Spec = *u.desired.Spec.DeepCopy()
+	cur.Spec.Versions = versions
+	cur.ObjectMeta.Annotations = u.desired.ObjectMeta.Annotations
+	cur.ObjectMeta.Labels = u.desired.ObjectMeta.Labels
+	if err := c.Patch(ctx, cur, patch); err != nil {
+		return err
+	}
+
+	if err := MigrateStoredVersions(ctx, c, cur); err != nil {
+		return err
+	}
+
+	if len(retained) == 0 {
+		return nil
+	}
+
+	// never prune a version that may still hold stored resources, the API server
+	// would reject it.
+	for _, v := range retained {
+		if slices.Contains(cur.Status.StoredVersions, v) {
+			return fmt.Errorf("cannot prune version %q, it is still stored", v)
+		}
+	}
+
+	patch = ctrlclient.MergeFrom(cur.DeepCopy())
+	cur.Spec.Versions = u.desired.DeepCopy().Spec.Versions
+	return c.Patch(ctx, cur, patch)
+}
+
+// conversionCABundle returns the CA bundle of the CRD's conversion webhook, if
+// any.
+func conversionCABundle(crd *apiextensionsv1.CustomResourceDefinition) []byte {
+	conv := crd.Spec.Conversion
+	if conv == nil || conv.Strategy != apiextensionsv1.WebhookConverter ||
+		conv.Webhook == nil || conv.Webhook.ClientConfig == nil || len(conv.Webhook.ClientConfig.CABundle) == 0 {
+		return nil
+	}
+	return conv.Webhook.ClientConfig.CABundle
+}
+
+// MigrateStoredVersions migrates the custom resources of the CRD to its
+// storage version, after which all other versions are removed from the CRD's
+// status.storedVersions. This allows obsolete versions to be removed from the
+// CRD without any manual rewrites of the custom resources. Each resource is
+// migrated by updating it without any changes, which has the API server
+// store it in the storage version, converting it with the CRD's conversion
+// webhook if needed. The Client must have the apiextensionsv1.Scheme
+// registered.
+func MigrateStoredVersions(ctx context.Context, c ctrlclient.Client, crd *apiextensionsv1.CustomResourceDefinition) error {
+	if len(crd.Status.StoredVersions) == 0 {
+		return nil
+	}
+
+	var storageVersion string
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storageVersion = v.Name
+			break
+		}
+	}
+	if storageVersion == "" {
+		return fmt.Errorf("no storage version found for CRD %q", crd.Name)
+	}
+
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		return nil
+	}
+
+	logger := zap.New().WithName("MigrateStoredVersions").WithValues(
+		"name", crd.Name, "storageVersion", storageVersion,
+		"storedVersions", crd.Status.StoredVersions)
+	logger.Info("Migrating")
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: storageVersion,
+		Kind:    crd.Spec.Names.ListKind,
+	})
+	var count int
+	for {
+		if err := c.List(ctx, list, ctrlclient.Limit(500), ctrlclient.Continue(list.GetContinue())); err != nil {
+			return err
+		}
+
+		for _, item := range list.Items {
+			// a resource that was deleted or updated since it was listed no
+			// longer needs to be migrated.
+			if err := c.Update(ctx, &item); err != nil &&
+				!apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+				return fmt.Errorf("failed to migrate %s %s: %w",
+					crd.Spec.Names.Kind, ctrlclient.ObjectKeyFromObject(&item), err)
+			}
+			count++
+		}
+
+		if list.GetContinue() == "" {
+			break
+		}
+	}
+
+	patch := ctrlclient.MergeFrom(crd.DeepCopy())
+	crd.Status.StoredVersions = []string{storageVersion}
+	if err := c.Status().Patch(ctx, crd, patch); err != nil {
+		return err
+	}
+
+	logger.Info("Migrated", "count", count)
+
+	return nil
+}
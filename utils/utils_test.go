@@ -14,9 +14,12 @@ import (
 	"github.com/stretchr/testify/require"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 func TestGetCurrentNamespace(t *testing.T) {
@@ -296,3 +299,268 @@ metadata:
 		})
 	}
 }
+
+func TestMigrateStoredVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gvk := schema.GroupVersionKind{
+		Group:   "secrets.hashicorp.com",
+		Version: "v1beta1",
+		Kind:    "HCPAuth",
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind("HCPAuthList"), &unstructured.UnstructuredList{})
+
+	newCRD := func(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "hcpauths.secrets.hashicorp.com",
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: gvk.Group,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Kind:     gvk.Kind,
+					ListKind: "HCPAuthList",
+					Plural:   "hcpauths",
+					Singular: "hcpauth",
+				},
+				Scope: "Namespaced",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{
+						Name: "v1alpha1",
+					},
+					{
+						Name:    gvk.Version,
+						Storage: true,
+					},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{
+				StoredVersions: storedVersions,
+			},
+		}
+	}
+
+	newObj := func(name string) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{}
+		o.SetGroupVersionKind(gvk)
+		o.SetNamespace("default")
+		o.SetName(name)
+		return o
+	}
+
+	tests := []struct {
+		name               string
+		crd                *apiextensionsv1.CustomResourceDefinition
+		wantStoredVersions []string
+		wantMigrated       bool
+		wantErr            assert.ErrorAssertionFunc
+	}{
+		{
+			name:               "migrated",
+			crd:                newCRD("v1alpha1", "v1beta1"),
+			wantStoredVersions: []string{"v1beta1"},
+			wantMigrated:       true,
+			wantErr:            assert.NoError,
+		},
+		{
+			name:               "up-to-date",
+			crd:                newCRD("v1beta1"),
+			wantStoredVersions: []string{"v1beta1"},
+			wantErr:            assert.NoError,
+		},
+		{
+			name:    "no-stored-versions",
+			crd:     newCRD(),
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid-no-storage-version",
+			crd: func() *apiextensionsv1.CustomResourceDefinition {
+				crd := newCRD("v1alpha1")
+				crd.Spec.Versions[1].Storage = false
+				return crd
+			}(),
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`no storage version found for CRD "hcpauths.secrets.hashicorp.com"`, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []*unstructured.Unstructured{newObj("foo"), newObj("bar")}
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(tt.crd, objs[0], objs[1]).
+				WithStatusSubresource(tt.crd).
+				Build()
+
+			var crd apiextensionsv1.CustomResourceDefinition
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt.crd), &crd))
+			err := MigrateStoredVersions(ctx, c, &crd)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt.crd), &crd))
+			assert.Equal(t, tt.wantStoredVersions, crd.Status.StoredVersions)
+
+			for _, o := range objs {
+				got := &unstructured.Unstructured{}
+				got.SetGroupVersionKind(gvk)
+				require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(o), got))
+				// every migrated resource is updated.
+				assert.Equal(t, tt.wantMigrated, got.GetResourceVersion() != "999")
+			}
+		})
+	}
+}
+
+func TestUpgradeCRDs_pruneVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gvk := schema.GroupVersionKind{
+		Group:   "secrets.hashicorp.com",
+		Version: "v1beta1",
+		Kind:    "HCPAuth",
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind("HCPAuthList"), &unstructured.UnstructuredList{})
+
+	manifest := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: hcpauths.secrets.hashicorp.com
+spec:
+  group: secrets.hashicorp.com
+  names:
+    kind: HCPAuth
+    listKind: HCPAuthList
+    plural: hcpauths
+    singular: hcpauth
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+`
+
+	newCRD := func() *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "hcpauths.secrets.hashicorp.com",
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: gvk.Group,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Kind:     gvk.Kind,
+					ListKind: "HCPAuthList",
+					Plural:   "hcpauths",
+					Singular: "hcpauth",
+				},
+				Scope: "Namespaced",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{
+						Name:    "v1alpha1",
+						Served:  true,
+						Storage: true,
+					},
+					{
+						Name:   gvk.Version,
+						Served: true,
+					},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{
+				StoredVersions: []string{"v1alpha1"},
+			},
+		}
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace("default")
+	obj.SetName("foo")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crd.yaml"), []byte(manifest), 0o600))
+
+	tests := []struct {
+		name              string
+		funcs             interceptor.Funcs
+		wantVersions      []string
+		wantStoredVersion []string
+		wantErr           assert.ErrorAssertionFunc
+	}{
+		{
+			name:              "pruned",
+			wantVersions:      []string{"v1beta1"},
+			wantStoredVersion: []string{"v1beta1"},
+			wantErr:           assert.NoError,
+		},
+		{
+			name: "retained-migration-failed",
+			funcs: interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					return fmt.Errorf("status patch failed")
+				},
+			},
+			wantVersions:      []string{"v1beta1", "v1alpha1"},
+			wantStoredVersion: []string{"v1alpha1"},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`failed to upgrade CRD "hcpauths.secrets.hashicorp.com": status patch failed`, i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			crd := newCRD()
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(crd, obj.DeepCopy()).
+				WithStatusSubresource(crd).
+				WithInterceptorFuncs(tt.funcs).
+				Build()
+
+			changes, err := DiffCRDs(ctx, c, dir)
+			require.NoError(t, err)
+			assert.Equal(t, []CRDChange{
+				{
+					Name:             crd.Name,
+					Changed:          true,
+					RemovedVersions:  []string{"v1alpha1"},
+					ChangedVersions:  []string{"v1beta1"},
+					MigratedVersions: []string{"v1alpha1"},
+				},
+			}, changes)
+			assert.Equal(t,
+				"hcpauths.secrets.hashicorp.com: update removed=v1alpha1 changed=v1beta1 migrated=v1alpha1",
+				changes[0].String())
+
+			if !tt.wantErr(t, UpgradeCRDs(ctx, c, dir)) {
+				return
+			}
+
+			var got apiextensionsv1.CustomResourceDefinition
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(crd), &got))
+			var versions []string
+			for _, v := range got.Spec.Versions {
+				versions = append(versions, v.Name)
+			}
+			assert.Equal(t, tt.wantVersions, versions)
+			assert.Equal(t, tt.wantStoredVersion, got.Status.StoredVersions)
+		})
+	}
+}
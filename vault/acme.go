@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto"
+	"fmt"
+	"net/url"
+	"path"
+
+	"golang.org/x/crypto/acme"
+)
+
+// ACMEClient returns an ACME client for the Vault ACME server at directoryPath,
+// signing its requests with the ACME account key. The ACME endpoints are
+// unauthenticated, so the Vault token is never sent. The client's namespace is
+// prepended to directoryPath.
+func (c *defaultClient) ACMEClient(directoryPath string, key crypto.Signer) (*acme.Client, error) {
+	if key == nil {
+		return nil, fmt.Errorf("ACME account key was nil")
+	}
+
+	vaultURL, err := url.Parse(c.client.Address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vault URL: %w", err)
+	}
+
+	vaultURL.Path = path.Join("/v1", c.client.Namespace(), directoryPath)
+
+	return &acme.Client{
+		Key:          key,
+		HTTPClient:   c.client.CloneConfig().HttpClient,
+		DirectoryURL: vaultURL.String(),
+		UserAgent:    "vault-secrets-operator",
+	}, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACMEClient(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		address       string
+		namespace     string
+		directoryPath string
+		expectedURL   string
+	}{
+		"no-namespace": {
+			address:       "http://some-vault:1234",
+			directoryPath: "pki/roles/web/acme/directory",
+			expectedURL:   "http://some-vault:1234/v1/pki/roles/web/acme/directory",
+		},
+		"namespace": {
+			address:       "https://some-vault:8200",
+			namespace:     "foo/bar",
+			directoryPath: "pki/acme/directory",
+			expectedURL:   "https://some-vault:8200/v1/foo/bar/pki/acme/directory",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := api.NewClient(nil)
+			require.NoError(t, err)
+			client.SetToken("foo")
+			require.NoError(t, client.SetAddress(tc.address))
+			client.SetNamespace(tc.namespace)
+			c := &defaultClient{client: client}
+
+			ac, err := c.ACMEClient(tc.directoryPath, key)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedURL, ac.DirectoryURL)
+			assert.Equal(t, key, ac.Key)
+			assert.NotNil(t, ac.HTTPClient)
+		})
+	}
+
+	_, err = (&defaultClient{}).ACMEClient("pki/acme/directory", nil)
+	assert.EqualError(t, err, "ACME account key was nil")
+}
@@ -24,12 +24,24 @@ type ClientCache interface {
 	Prune(filterFunc ClientCachePruneFilterFunc) []Client
 	Contains(key ClientCacheKey) bool
 	Purge() []ClientCacheKey
+	Stats() ClientCacheStats
+}
+
+// ClientCacheStats summarizes the state of a ClientCache.
+type ClientCacheStats struct {
+	// Length is the number of cached Clients.
+	Length int
+	// Size is the maximum number of cached Clients.
+	Size int
+	// Tainted is the number of cached Clients that are tainted.
+	Tainted int
 }
 
 var _ ClientCache = (*clientCache)(nil)
 
 // clientCache implements ClientCache with an underlying LRU cache. The cache size is fixed.
 type clientCache struct {
+	size               int
 	cache              *lru.Cache[ClientCacheKey, Client]
 	cloneCache         *lru.Cache[ClientCacheKey, Client]
 	evictionGauge      prometheus.Gauge
@@ -67,6 +79,21 @@ func (c *clientCache) Len() int {
 	return c.cache.Len()
 }
 
+// Stats returns the ClientCacheStats for the cache. Clones are not included.
+func (c *clientCache) Stats() ClientCacheStats {
+	stats := ClientCacheStats{
+		Size: c.size,
+	}
+	for _, client := range c.cache.Values() {
+		stats.Length++
+		if client.Tainted() {
+			stats.Tainted++
+		}
+	}
+
+	return stats
+}
+
 // Get a Client for key, returning the Client, and a boolean if the key
 // was found in the cache.
 func (c *clientCache) Get(key ClientCacheKey) (Client, bool) {
@@ -196,6 +223,7 @@ func onEvictPruneClonesFunc(cache *clientCache) onEvictCallbackFunc {
 // An error will be returned if the cache could not be initialized.
 func NewClientCache(size int, callbackFunc onEvictCallbackFunc, metricsRegistry prometheus.Registerer) (ClientCache, error) {
 	cache := &clientCache{
+		size: size,
 		evictionGauge: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: metricsFQNClientCacheEvictions,
 			Help: "Number of cache evictions.",
@@ -174,3 +174,30 @@ func ClientCacheKeyClone(key ClientCacheKey, namespace string) (ClientCacheKey,
 
 	return ClientCacheKey(fmt.Sprintf("%s-%s", key, namespace)), nil
 }
+
+// RemapClientCacheKey returns the clone key with its Vault namespace suffix
+// remapped by remap. Keys that are not clones, or whose namespace has not been
+// renamed, are returned unchanged.
+func RemapClientCacheKey(key ClientCacheKey, remap common.NamespaceRemap) ClientCacheKey {
+	if len(remap) == 0 || !key.IsClone() {
+		return key
+	}
+
+	parent, err := key.Parent()
+	if err != nil {
+		return key
+	}
+
+	ns := strings.TrimPrefix(key.String(), parent.String()+"-")
+	newNS := remap.Remap(ns)
+	if newNS == ns {
+		return key
+	}
+
+	newKey, err := ClientCacheKeyClone(parent, newNS)
+	if err != nil {
+		return key
+	}
+
+	return newKey
+}
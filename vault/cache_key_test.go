@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/credentials/vault"
 	"github.com/hashicorp/vault-secrets-operator/credentials/vault/consts"
 )
@@ -459,3 +460,64 @@ func TestClientCacheKey_SameParent(t *testing.T) {
 		})
 	}
 }
+
+func TestRemapClientCacheKey(t *testing.T) {
+	t.Parallel()
+
+	parent := ClientCacheKey(fmt.Sprintf("%s-%s",
+		consts.ProviderMethodKubernetes,
+		computedHash))
+	remap := common.NamespaceRemap{
+		"ns1": "ns2",
+	}
+
+	tests := []struct {
+		name  string
+		key   ClientCacheKey
+		remap common.NamespaceRemap
+		want  ClientCacheKey
+	}{
+		{
+			name:  "clone-renamed",
+			key:   ClientCacheKey(fmt.Sprintf("%s-ns1", parent)),
+			remap: remap,
+			want:  ClientCacheKey(fmt.Sprintf("%s-ns2", parent)),
+		},
+		{
+			name:  "clone-child-of-renamed",
+			key:   ClientCacheKey(fmt.Sprintf("%s-ns1/child", parent)),
+			remap: remap,
+			want:  ClientCacheKey(fmt.Sprintf("%s-ns2/child", parent)),
+		},
+		{
+			name:  "clone-not-renamed",
+			key:   ClientCacheKey(fmt.Sprintf("%s-ns3", parent)),
+			remap: remap,
+			want:  ClientCacheKey(fmt.Sprintf("%s-ns3", parent)),
+		},
+		{
+			name:  "parent",
+			key:   parent,
+			remap: remap,
+			want:  parent,
+		},
+		{
+			name:  "nil-remap",
+			key:   ClientCacheKey(fmt.Sprintf("%s-ns1", parent)),
+			remap: nil,
+			want:  ClientCacheKey(fmt.Sprintf("%s-ns1", parent)),
+		},
+		{
+			name:  "empty-key",
+			key:   "",
+			remap: remap,
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, RemapClientCacheKey(tt.key, tt.remap),
+				"RemapClientCacheKey(%v, %v)", tt.key, tt.remap)
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/hashicorp/vault-secrets-operator/common"
 	"github.com/hashicorp/vault-secrets-operator/consts"
 	"github.com/hashicorp/vault-secrets-operator/helpers"
+	"github.com/hashicorp/vault-secrets-operator/internal/kms"
 	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
 )
 
@@ -78,6 +79,38 @@ type ClientCacheStoragePruneRequest struct {
 	Filter         PruneFilterFunc
 }
 
+// ClientCacheStorageRewrapRequest is the request to rewrap the stored Clients
+// with the latest version of the Vault Transit key of EncryptionVaultAuth.
+type ClientCacheStorageRewrapRequest struct {
+	EncryptionClient    Client
+	EncryptionVaultAuth *secretsv1beta1.VaultAuth
+}
+
+func (c ClientCacheStorageRewrapRequest) Validate() error {
+	var err error
+	if c.EncryptionClient == nil {
+		err = errors.Join(err, fmt.Errorf("an EncryptionClient must be set"))
+	}
+	if c.EncryptionVaultAuth == nil || c.EncryptionVaultAuth.Spec.StorageEncryption == nil {
+		err = errors.Join(err, fmt.Errorf("an EncryptionVaultAuth with StorageEncryption must be set"))
+	}
+
+	return err
+}
+
+// ClientCacheStorageRewrapResult is the result of a rewrap of the stored
+// Clients.
+type ClientCacheStorageRewrapResult struct {
+	// KeyVersions of the Vault Transit key at the time of the rewrap.
+	KeyVersions TransitKeyVersions
+	// Rewrapped is the number of entries rewrapped to the latest key version.
+	Rewrapped int
+	// Stranded is the number of entries that were encrypted with a key version
+	// below the key's minimum decryption version. They can never be decrypted
+	// again, so they are pruned.
+	Stranded int
+}
+
 type CachingClientFactoryShutDownRequest struct {
 	Revoke bool
 }
@@ -147,11 +180,17 @@ type ClientCacheStorage interface {
 	Prune(context.Context, ctrlclient.Client, ClientCacheStoragePruneRequest) (int, error)
 	Purge(context.Context, ctrlclient.Client) error
 	Len(context.Context, ctrlclient.Client) (int, error)
+	CacheKeys(context.Context, ctrlclient.Client) ([]ClientCacheKey, error)
+	Rewrap(context.Context, ctrlclient.Client, ClientCacheStorageRewrapRequest) (*ClientCacheStorageRewrapResult, error)
 }
 
 type defaultClientCacheStorage struct {
-	hmacKey                  []byte
+	// hmacSecretObjKey of the Secret that holds the HMAC key, the keys are
+	// read on every use, so that a rotation is picked up without a restart.
+	hmacSecretObjKey         ctrlclient.ObjectKey
 	enforceEncryption        bool
+	kms                      kms.Wrapper
+	encryptionKey            []byte
 	logger                   logr.Logger
 	requestCounterVec        *prometheus.CounterVec
 	requestErrorCounterVec   *prometheus.CounterVec
@@ -193,7 +232,7 @@ func (c *defaultClientCacheStorage) Store(ctx context.Context, client ctrlclient
 		return nil, err
 	}
 
-	if c.enforceEncryption && (req.EncryptionClient == nil || req.EncryptionVaultAuth == nil) {
+	if c.enforceEncryption && c.encryptionKey == nil && (req.EncryptionClient == nil || req.EncryptionVaultAuth == nil) {
 		err = fmt.Errorf("request is invalid for when enforcing encryption")
 		return nil, err
 	}
@@ -202,6 +241,7 @@ func (c *defaultClientCacheStorage) Store(ctx context.Context, client ctrlclient
 	defer c.mu.Unlock()
 	logger.Info("Storing client",
 		"enforceEncryption", c.enforceEncryption,
+		"kmsProvider", c.kmsProvider(),
 		"cacheKey", cacheKey)
 
 	labels := ctrlclient.MatchingLabels{
@@ -248,7 +288,18 @@ func (c *defaultClientCacheStorage) Store(ctx context.Context, client ctrlclient
 		return nil, err
 	}
 
-	if c.enforceEncryption {
+	if c.enforceEncryption && c.encryptionKey != nil {
+		// needed for restoration
+		s.ObjectMeta.Labels[labelEncrypted] = "true"
+		s.ObjectMeta.Labels[labelKMSProvider] = c.kmsProvider()
+
+		var encBytes []byte
+		encBytes, err = encryptWithKey(c.encryptionKey, b)
+		if err != nil {
+			return nil, err
+		}
+		b = encBytes
+	} else if c.enforceEncryption {
 		// needed for restoration
 		s.ObjectMeta.Labels[labelEncrypted] = "true"
 		s.ObjectMeta.Labels[labelVaultTransitRef] = req.EncryptionVaultAuth.Name
@@ -272,8 +323,14 @@ func (c *defaultClientCacheStorage) Store(ctx context.Context, client ctrlclient
 		return nil, err
 	}
 
+	var hmacKey []byte
+	hmacKey, err = c.hmacKey(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
 	var messageMAC []byte
-	messageMAC, err = helpers.MACMessage(c.hmacKey, message)
+	messageMAC, err = helpers.MACMessage(hmacKey, message)
 	if err != nil {
 		return nil, err
 	}
@@ -282,17 +339,7 @@ func (c *defaultClientCacheStorage) Store(ctx context.Context, client ctrlclient
 	if e := client.Create(ctx, s); e != nil {
 		if apierrors.IsAlreadyExists(e) {
 			// since the Secret is immutable we need to always recreate it
-			err = c.delete(ctx, client, s)
-			if err != nil {
-				return nil, err
-			}
-
-			// we want to retry create since the previous Delete() call is eventually consistent.
-			bo := backoff.NewExponentialBackOff()
-			bo.MaxInterval = 2 * time.Second
-			err = backoff.Retry(func() error {
-				return client.Create(ctx, s)
-			}, backoff.WithMaxRetries(bo, 5))
+			err = c.recreate(ctx, client, s)
 			if err != nil {
 				return nil, err
 			}
@@ -346,6 +393,23 @@ func (c *defaultClientCacheStorage) Len(ctx context.Context, client ctrlclient.C
 	return len(found), nil
 }
 
+// CacheKeys returns the ClientCacheKey of every stored Client.
+func (c *defaultClientCacheStorage) CacheKeys(ctx context.Context, client ctrlclient.Client) ([]ClientCacheKey, error) {
+	found, err := c.listSecrets(ctx, client, c.listOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []ClientCacheKey
+	for _, s := range found {
+		if v := s.Labels[labelCacheKey]; v != "" {
+			keys = append(keys, ClientCacheKey(v))
+		}
+	}
+
+	return keys, nil
+}
+
 func (c *defaultClientCacheStorage) restore(ctx context.Context, client ctrlclient.Client,
 	req ClientCacheStorageRestoreRequest, s *corev1.Secret,
 ) (*clientCacheStorageEntry, error) {
@@ -363,7 +427,7 @@ func (c *defaultClientCacheStorage) restore(ctx context.Context, client ctrlclie
 	}()
 
 	var secret *api.Secret
-	err = c.validateSecretMAC(req, s)
+	err = c.validateSecretMAC(ctx, client, req, s)
 	if err != nil {
 		return nil, err
 	}
@@ -389,6 +453,19 @@ func (c *defaultClientCacheStorage) restore(ctx context.Context, client ctrlclie
 				return nil, err
 			}
 
+			b = decBytes
+		} else if provider := s.Labels[labelKMSProvider]; provider != "" {
+			if c.encryptionKey == nil || provider != c.kmsProvider() {
+				err = fmt.Errorf("invalid %s, need %s, have %q", labelKMSProvider, provider, c.kmsProvider())
+				return nil, err
+			}
+
+			var decBytes []byte
+			decBytes, err = decryptWithKey(c.encryptionKey, b)
+			if err != nil {
+				return nil, err
+			}
+
 			b = decBytes
 		}
 
@@ -468,6 +545,174 @@ func (c *defaultClientCacheStorage) Prune(ctx context.Context, client ctrlclient
 	return count, errs
 }
 
+// Rewrap all stored Clients that are encrypted with an older version of the
+// Vault Transit key of the request's EncryptionVaultAuth to the key's latest
+// version, so that operators can safely raise the key's minimum decryption
+// version, and trim the older key versions. Entries that are encrypted with a
+// key version below the minimum decryption version can never be decrypted
+// again, they are pruned. Rewrapping continues on error.
+func (c *defaultClientCacheStorage) Rewrap(ctx context.Context, client ctrlclient.Client, req ClientCacheStorageRewrapRequest) (*ClientCacheStorageRewrapResult, error) {
+	var errs error
+	defer func() {
+		c.incrementRequestCounter(metrics.OperationRewrap, errs)
+	}()
+
+	if err := req.Validate(); err != nil {
+		errs = err
+		return nil, errs
+	}
+
+	mount := req.EncryptionVaultAuth.Spec.StorageEncryption.Mount
+	keyName := req.EncryptionVaultAuth.Spec.StorageEncryption.KeyName
+	versions, err := ReadTransitKeyVersions(ctx, req.EncryptionClient, mount, keyName)
+	if err != nil {
+		errs = err
+		return nil, errs
+	}
+
+	secrets, err := c.listSecrets(ctx, client,
+		c.commonMatchingLabels(),
+		ctrlclient.MatchingLabels{labelVaultTransitRef: req.EncryptionVaultAuth.Name},
+		ctrlclient.InNamespace(common.OperatorNamespace),
+	)
+	if err != nil {
+		errs = err
+		return nil, errs
+	}
+
+	result := &ClientCacheStorageRewrapResult{
+		KeyVersions: *versions,
+	}
+	for _, item := range secrets {
+		rewrapped, stranded, err := c.rewrap(ctx, client, req, versions, &item)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if rewrapped {
+			result.Rewrapped++
+		}
+		if stranded {
+			result.Stranded++
+		}
+	}
+
+	c.logger.V(consts.LogLevelDebug).Info("Rewrapped storage cache",
+		"rewrapped", result.Rewrapped, "stranded", result.Stranded, "total", len(secrets),
+		"latestVersion", versions.LatestVersion, "minDecryptionVersion", versions.MinDecryptionVersion)
+
+	return result, errs
+}
+
+// rewrap a single stored Client with the latest key version, it returns
+// whether the entry was rewrapped, or pruned because it is stranded.
+func (c *defaultClientCacheStorage) rewrap(ctx context.Context, client ctrlclient.Client,
+	req ClientCacheStorageRewrapRequest, versions *TransitKeyVersions, s *corev1.Secret,
+) (bool, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+	defer func() {
+		c.incrementOperationCounter(metrics.OperationRewrap, err)
+	}()
+
+	// the entry may have been replaced since it was listed.
+	var cur *corev1.Secret
+	cur, err = c.getSecret(ctx, client, ctrlclient.ObjectKeyFromObject(s))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+		return false, false, err
+	}
+
+	var v encryptResponse
+	if err = json.Unmarshal(cur.Data[fieldCachedSecret], &v); err != nil {
+		return false, false, err
+	}
+
+	var version int
+	version, err = TransitCiphertextVersion(v.Ciphertext)
+	if err != nil {
+		return false, false, err
+	}
+
+	if version >= versions.LatestVersion {
+		return false, false, nil
+	}
+
+	if version < versions.MinDecryptionVersion {
+		c.logger.Info("Warning: pruning stored client encrypted with a key version below the minimum decryption version",
+			"secret", ctrlclient.ObjectKeyFromObject(cur), "keyVersion", version,
+			"minDecryptionVersion", versions.MinDecryptionVersion)
+		err = c.delete(ctx, client, cur)
+		return false, err == nil, err
+	}
+
+	mount := req.EncryptionVaultAuth.Spec.StorageEncryption.Mount
+	keyName := req.EncryptionVaultAuth.Spec.StorageEncryption.KeyName
+	v.Ciphertext, err = RewrapWithTransit(ctx, req.EncryptionClient, mount, keyName, v.Ciphertext)
+	if err != nil {
+		return false, false, err
+	}
+
+	var b []byte
+	b, err = json.Marshal(v)
+	if err != nil {
+		return false, false, err
+	}
+
+	var message []byte
+	message, err = c.message(cur.Name, cur.Labels[labelCacheKey], b)
+	if err != nil {
+		return false, false, err
+	}
+
+	var hmacKey []byte
+	hmacKey, err = c.hmacKey(ctx, client)
+	if err != nil {
+		return false, false, err
+	}
+
+	var messageMAC []byte
+	messageMAC, err = helpers.MACMessage(hmacKey, message)
+	if err != nil {
+		return false, false, err
+	}
+
+	s = &corev1.Secret{
+		Immutable: ptr.To(true),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            cur.Name,
+			Namespace:       cur.Namespace,
+			OwnerReferences: cur.OwnerReferences,
+			Labels:          cur.Labels,
+		},
+		Data: map[string][]byte{
+			fieldCachedSecret: b,
+			fieldMACMessage:   messageMAC,
+		},
+	}
+
+	err = c.recreate(ctx, client, s)
+	return err == nil, false, err
+}
+
+// recreate the immutable Secret s, by deleting it before creating it again.
+func (c *defaultClientCacheStorage) recreate(ctx context.Context, client ctrlclient.Client, s *corev1.Secret) error {
+	if err := c.delete(ctx, client, s); err != nil {
+		return err
+	}
+
+	// we want to retry create since the previous Delete() call is eventually consistent.
+	bo := backoff.NewExponentialBackOff()
+	bo.MaxInterval = 2 * time.Second
+	return backoff.Retry(func() error {
+		return client.Create(ctx, s)
+	}, backoff.WithMaxRetries(bo, 5))
+}
+
 func (c *defaultClientCacheStorage) delete(ctx context.Context, client ctrlclient.Client, secret *corev1.Secret) error {
 	var errs error
 	defer func() {
@@ -510,7 +755,9 @@ func (c *defaultClientCacheStorage) Purge(ctx context.Context, client ctrlclient
 	return err
 }
 
-func (c *defaultClientCacheStorage) validateSecretMAC(req ClientCacheStorageRestoreRequest, s *corev1.Secret) error {
+func (c *defaultClientCacheStorage) validateSecretMAC(ctx context.Context, client ctrlclient.Client,
+	req ClientCacheStorageRestoreRequest, s *corev1.Secret,
+) error {
 	if s == nil {
 		return fmt.Errorf("secret is nil")
 	}
@@ -536,7 +783,12 @@ func (c *defaultClientCacheStorage) validateSecretMAC(req ClientCacheStorageRest
 		return err
 	}
 
-	ok, _, err = helpers.ValidateMAC(message, messageMAC, c.hmacKey)
+	keys, err := helpers.GetHMACKeys(ctx, client, c.hmacSecretObjKey)
+	if err != nil {
+		return err
+	}
+
+	ok, _, err = helpers.ValidateMACWithKeys(message, messageMAC, keys...)
 	if err != nil {
 		return err
 	}
@@ -549,6 +801,15 @@ func (c *defaultClientCacheStorage) validateSecretMAC(req ClientCacheStorageRest
 	return nil
 }
 
+// hmacKey returns the current HMAC key.
+func (c *defaultClientCacheStorage) hmacKey(ctx context.Context, client ctrlclient.Client) ([]byte, error) {
+	s, err := helpers.GetCurrentHMACKeySecret(ctx, client, c.hmacSecretObjKey)
+	if err != nil {
+		return nil, err
+	}
+	return s.Data[helpers.HMACKeyName], nil
+}
+
 func (c *defaultClientCacheStorage) message(name, cacheKey string, secretData []byte) ([]byte, error) {
 	if name == "" || cacheKey == "" {
 		return nil, fmt.Errorf("invalid empty name or cacheKey")
@@ -606,8 +867,13 @@ type ClientCacheStorageConfig struct {
 	// EnforceEncryption for persisting Clients i.e. the controller must have VaultTransitRef
 	// configured before it will persist the Client to storage. This option requires Persist to be true.
 	EnforceEncryption bool
-	HMACSecretObjKey  ctrlclient.ObjectKey
-	OwnerRefs         []metav1.OwnerReference
+	// KMS wraps the key that encrypts the persisted Clients, it is used instead
+	// of Vault Transit. This option requires EnforceEncryption to be true.
+	KMS kms.Wrapper
+	// EncryptionKeySecretObjKey is the Secret holding the KMS wrapped encryption key.
+	EncryptionKeySecretObjKey ctrlclient.ObjectKey
+	HMACSecretObjKey          ctrlclient.ObjectKey
+	OwnerRefs                 []metav1.OwnerReference
 	// skipHMACSecret is used for unit tests, which need to control various aspects
 	// of HMAC secret creation.
 	skipHMACSecret bool
@@ -620,6 +886,10 @@ func DefaultClientCacheStorageConfig() *ClientCacheStorageConfig {
 			Name:      NamePrefixVCC + "storage-hmac-key",
 			Namespace: common.OperatorNamespace,
 		},
+		EncryptionKeySecretObjKey: ctrlclient.ObjectKey{
+			Name:      NamePrefixVCC + "storage-encryption-key",
+			Namespace: common.OperatorNamespace,
+		},
 	}
 }
 
@@ -638,6 +908,7 @@ func newDefaultClientCacheStorage(ctx context.Context, client ctrlclient.Client,
 
 	cacheStorage := &defaultClientCacheStorage{
 		enforceEncryption: config.EnforceEncryption,
+		kms:               config.KMS,
 		logger:            zap.New().WithName("ClientCacheStorage"),
 		requestCounterVec: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -686,13 +957,25 @@ func newDefaultClientCacheStorage(ctx context.Context, client ctrlclient.Client,
 		}
 
 		if s == nil {
-			s, err = helpers.GetHMACKeySecret(ctx, client, config.HMACSecretObjKey)
-			if err != nil {
+			if _, err := helpers.GetHMACKeySecret(ctx, client, config.HMACSecretObjKey); err != nil {
 				return nil, err
 			}
 		}
 
-		cacheStorage.hmacKey = s.Data[helpers.HMACKeyName]
+		cacheStorage.hmacSecretObjKey = config.HMACSecretObjKey
+	}
+
+	if config.EnforceEncryption && config.KMS != nil {
+		if err := common.ValidateObjectKey(config.EncryptionKeySecretObjKey); err != nil {
+			return nil, err
+		}
+
+		key, err := cacheStorage.setupEncryptionKey(ctx, client, config)
+		if err != nil {
+			return nil, err
+		}
+
+		cacheStorage.encryptionKey = key
 	}
 
 	if metricsRegistry != nil {
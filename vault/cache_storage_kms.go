@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	labelKMSProvider      = "kmsProvider"
+	annotationKMSKeyID    = "vso.secrets.hashicorp.com/kmsKeyID"
+	fieldWrappedKey       = "wrappedKey"
+	storageEncryptionKeyN = 32
+)
+
+// storageEncryptionKeyLabels are included in the Secret holding the wrapped
+// storage encryption key. They must not match the commonMatchingLabels, since
+// the key must survive a Purge of the client cache storage.
+var storageEncryptionKeyLabels = map[string]string{
+	"app.kubernetes.io/name":       "vault-secrets-operator",
+	"app.kubernetes.io/managed-by": "hashicorp-vso",
+	"app.kubernetes.io/component":  "client-cache-storage-encryption",
+}
+
+// setupEncryptionKey returns the storage encryption key, the data encryption
+// key of the cached Clients. The key is stored in the Secret for
+// config.EncryptionKeySecretObjKey, wrapped by config.KMS, it is generated on
+// first use. If the Secret's key was wrapped by a different KMS key, then the
+// storage is purged, since its Clients can no longer be decrypted, and a new
+// key is generated.
+func (c *defaultClientCacheStorage) setupEncryptionKey(ctx context.Context, client ctrlclient.Client,
+	config *ClientCacheStorageConfig,
+) ([]byte, error) {
+	objKey := config.EncryptionKeySecretObjKey
+	logger := c.logger.WithValues("secret", objKey,
+		"kmsProvider", config.KMS.Provider(), "kmsKeyID", config.KMS.KeyID())
+
+	s, err := c.getSecret(ctx, client, objKey)
+	switch {
+	case err == nil:
+		if s.Labels[labelKMSProvider] == config.KMS.Provider() &&
+			s.Annotations[annotationKMSKeyID] == config.KMS.KeyID() {
+			return config.KMS.Unwrap(ctx, s.Data[fieldWrappedKey])
+		}
+
+		logger.Info("The KMS key has changed, purging the client cache storage",
+			"previousKMSProvider", s.Labels[labelKMSProvider],
+			"previousKMSKeyID", s.Annotations[annotationKMSKeyID])
+		if err := c.Purge(ctx, client); err != nil {
+			return nil, err
+		}
+		if err := c.delete(ctx, client, s); err != nil {
+			return nil, err
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, err
+	}
+
+	key := make([]byte, storageEncryptionKeyN)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := config.KMS.Wrap(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{
+		labelKMSProvider: config.KMS.Provider(),
+	}
+	for k, v := range storageEncryptionKeyLabels {
+		labels[k] = v
+	}
+	s = &corev1.Secret{
+		Immutable: ptr.To(true),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            objKey.Name,
+			Namespace:       objKey.Namespace,
+			OwnerReferences: config.OwnerRefs,
+			Labels:          labels,
+			Annotations: map[string]string{
+				annotationKMSKeyID: config.KMS.KeyID(),
+			},
+		},
+		Data: map[string][]byte{
+			fieldWrappedKey: wrapped,
+		},
+	}
+	if err := client.Create(ctx, s); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+
+		// another operator instance created the key first.
+		if s, err = c.getSecret(ctx, client, objKey); err != nil {
+			return nil, err
+		}
+		return config.KMS.Unwrap(ctx, s.Data[fieldWrappedKey])
+	}
+
+	logger.Info("Created the storage encryption key")
+
+	return key, nil
+}
+
+// encryptWithKey encrypts data with AES-256-GCM, the nonce is prepended to the
+// result.
+func encryptWithKey(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// decryptWithKey decrypts data that was encrypted by encryptWithKey.
+func decryptWithKey(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != storageEncryptionKeyN {
+		return nil, fmt.Errorf("invalid storage encryption key length %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// kmsProvider returns the provider of the KMS key that wraps the storage
+// encryption key, it is empty if the Clients are encrypted with Vault
+// Transit.
+func (c *defaultClientCacheStorage) kmsProvider() string {
+	if c.kms == nil {
+		return ""
+	}
+	return c.kms.Provider()
+}
@@ -5,18 +5,29 @@ package vault
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/common"
+	"github.com/hashicorp/vault-secrets-operator/credentials/vault"
+	"github.com/hashicorp/vault-secrets-operator/helpers"
 )
 
 func Test_defaultClientCacheStorage_Purge(t *testing.T) {
@@ -103,6 +114,177 @@ func Test_defaultClientCacheStorage_Purge(t *testing.T) {
 	}
 }
 
+func Test_defaultClientCacheStorage_CacheKeys(t *testing.T) {
+	ctx := context.Background()
+
+	client := fake.NewClientBuilder().Build()
+	c, err := newDefaultClientCacheStorage(ctx, client, DefaultClientCacheStorageConfig(), nil)
+	require.NoError(t, err)
+
+	keys, err := c.CacheKeys(ctx, client)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	want := []ClientCacheKey{"kubernetes-0123456789abcdef012345", "jwt-0123456789abcdef012345"}
+	for _, cacheKey := range want {
+		labels := ctrlclient.MatchingLabels{
+			labelCacheKey: cacheKey.String(),
+		}
+		for k, v := range commonMatchingLabels {
+			labels[k] = v
+		}
+		require.NoError(t, client.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      NamePrefixVCC + cacheKey.String(),
+				Namespace: common.OperatorNamespace,
+				Labels:    labels,
+			},
+		}))
+	}
+	// secrets without the common labels are not included
+	require.NoError(t, client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NamePrefixVCC + "other",
+			Namespace: common.OperatorNamespace,
+			Labels: map[string]string{
+				labelCacheKey: "other",
+			},
+		},
+	}))
+
+	keys, err = c.CacheKeys(ctx, client)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, want, keys)
+}
+
+// fakeKMSWrapper "wraps" a key by reversing it, and prefixing it with its key ID.
+type fakeKMSWrapper struct {
+	keyID string
+}
+
+func (w *fakeKMSWrapper) Wrap(_ context.Context, plaintext []byte) ([]byte, error) {
+	b := slices.Clone(plaintext)
+	slices.Reverse(b)
+	return append([]byte(w.keyID+":"), b...), nil
+}
+
+func (w *fakeKMSWrapper) Unwrap(_ context.Context, ciphertext []byte) ([]byte, error) {
+	prefix := []byte(w.keyID + ":")
+	if len(ciphertext) < len(prefix) || string(ciphertext[:len(prefix)]) != string(prefix) {
+		return nil, fmt.Errorf("ciphertext was not wrapped by %s", w.keyID)
+	}
+
+	b := slices.Clone(ciphertext[len(prefix):])
+	slices.Reverse(b)
+	return b, nil
+}
+
+func (w *fakeKMSWrapper) Provider() string {
+	return "fake"
+}
+
+func (w *fakeKMSWrapper) KeyID() string {
+	return w.keyID
+}
+
+func Test_defaultClientCacheStorage_KMS(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientBuilder().Build()
+
+	newConfig := func(keyID string) *ClientCacheStorageConfig {
+		config := DefaultClientCacheStorageConfig()
+		config.EnforceEncryption = true
+		config.KMS = &fakeKMSWrapper{keyID: keyID}
+		return config
+	}
+
+	c, err := newDefaultClientCacheStorage(ctx, client, newConfig("key-1"), nil)
+	require.NoError(t, err)
+	require.Len(t, c.encryptionKey, storageEncryptionKeyN)
+
+	var keySecret corev1.Secret
+	keyObjKey := DefaultClientCacheStorageConfig().EncryptionKeySecretObjKey
+	require.NoError(t, client.Get(ctx, keyObjKey, &keySecret))
+	assert.Equal(t, "fake", keySecret.Labels[labelKMSProvider])
+	assert.Equal(t, "key-1", keySecret.Annotations[annotationKMSKeyID])
+	assert.NotContains(t, string(keySecret.Data[fieldWrappedKey]), string(c.encryptionKey))
+
+	// no encryption client is required to store a Client.
+	s := storeSecret(t, ctx, client, c, 0)
+	assert.Equal(t, "true", s.Labels[labelEncrypted])
+	assert.Equal(t, "fake", s.Labels[labelKMSProvider])
+	assert.NotContains(t, s.Labels, labelVaultTransitRef)
+	assert.NotEqual(t, "null", string(s.Data[fieldCachedSecret]))
+
+	req := ClientCacheStorageRestoreRequest{
+		SecretObjKey: ctrlclient.ObjectKeyFromObject(s),
+		CacheKey:     ClientCacheKey(s.Labels[labelCacheKey]),
+	}
+
+	// the existing key is reused with the same KMS key.
+	c, err = newDefaultClientCacheStorage(ctx, client, newConfig("key-1"), nil)
+	require.NoError(t, err)
+	_, err = c.Restore(ctx, client, req)
+	require.NoError(t, err)
+
+	// the storage is purged, and a new key created on a KMS key change.
+	previousKey := c.encryptionKey
+	c, err = newDefaultClientCacheStorage(ctx, client, newConfig("key-2"), nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, previousKey, c.encryptionKey)
+	assertCacheSecretLen(t, ctx, client, 0)
+	require.NoError(t, client.Get(ctx, keyObjKey, &keySecret))
+	assert.Equal(t, "key-2", keySecret.Annotations[annotationKMSKeyID])
+
+	// Clients stored without a KMS cannot be restored.
+	s = storeSecret(t, ctx, client, c, 1)
+	c, err = newDefaultClientCacheStorage(ctx, client, &ClientCacheStorageConfig{
+		HMACSecretObjKey: DefaultClientCacheStorageConfig().HMACSecretObjKey,
+	}, nil)
+	require.NoError(t, err)
+	_, err = c.Restore(ctx, client, ClientCacheStorageRestoreRequest{
+		SecretObjKey: ctrlclient.ObjectKeyFromObject(s),
+		CacheKey:     ClientCacheKey(s.Labels[labelCacheKey]),
+	})
+	assert.ErrorContains(t, err, "invalid kmsProvider")
+}
+
+func Test_defaultClientCacheStorage_HMACKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientBuilder().Build()
+	config := DefaultClientCacheStorageConfig()
+
+	c, err := newDefaultClientCacheStorage(ctx, client, config, nil)
+	require.NoError(t, err)
+
+	restore := func(s *corev1.Secret) error {
+		t.Helper()
+		_, err := c.Restore(ctx, client, ClientCacheStorageRestoreRequest{
+			SecretObjKey:   ctrlclient.ObjectKeyFromObject(s),
+			CacheKey:       ClientCacheKey(s.Labels[labelCacheKey]),
+			NoPruneOnError: true,
+		})
+		return err
+	}
+
+	// the entries are stored with the current key, and validated with the
+	// previous keys, without recreating the storage after a rotation.
+	var stored []*corev1.Secret
+	for i := 0; i <= helpers.HMACKeyHistoryLimit; i++ {
+		s := storeSecret(t, ctx, client, c, i)
+		require.NoError(t, restore(s))
+		stored = append(stored, s)
+		_, err := helpers.RotateHMACKeySecret(ctx, client, config.HMACSecretObjKey)
+		require.NoError(t, err)
+	}
+
+	// the entry stored with a pruned key is no longer valid.
+	assert.ErrorContains(t, restore(stored[0]), "invalid message MAC")
+	for _, s := range stored[1:] {
+		assert.NoError(t, restore(s))
+	}
+}
+
 func assertCacheSecretLen(t *testing.T, ctx context.Context, client ctrlclient.Client, length int, i ...any) bool {
 	t.Helper()
 
@@ -115,3 +297,157 @@ func assertCacheSecretLen(t *testing.T, ctx context.Context, client ctrlclient.C
 	require.NoError(t, client.List(ctx, &so, listOptions...))
 	return assert.Len(t, so.Items, length, i...)
 }
+
+func Test_defaultClientCacheStorage_Rewrap(t *testing.T) {
+	ctx := context.Background()
+
+	// the fake Transit engine "encrypts" a plaintext by prefixing it with the
+	// current key version.
+	keyVersion := 1
+	handler := &testHandler{
+		handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+			var data map[string]any
+			var params map[string]any
+			if req.Method == http.MethodPut {
+				params = t.params[len(t.params)-1]
+			}
+			switch req.URL.Path {
+			case "/v1/transit/keys/vso":
+				data = map[string]any{
+					"latest_version":         keyVersion,
+					"min_decryption_version": 2,
+				}
+			case "/v1/transit/encrypt/vso":
+				data = map[string]any{
+					"ciphertext": fmt.Sprintf("vault:v%d:%s", keyVersion, params["plaintext"]),
+				}
+			case "/v1/transit/rewrap/vso":
+				parts := strings.SplitN(params["ciphertext"].(string), ":", 3)
+				data = map[string]any{
+					"ciphertext": fmt.Sprintf("vault:v%d:%s", keyVersion, parts[2]),
+				}
+			case "/v1/transit/decrypt/vso":
+				parts := strings.SplitN(params["ciphertext"].(string), ":", 3)
+				data = map[string]any{
+					"plaintext": parts[2],
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			b, err := json.Marshal(&api.Secret{Data: data})
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(b)
+		},
+	}
+	config, l := NewTestHTTPServer(t, handler.handler())
+	t.Cleanup(func() {
+		l.Close()
+	})
+	apiClient, err := api.NewClient(config)
+	require.NoError(t, err)
+
+	encClient := &defaultClient{client: apiClient}
+	encAuthObj := &secretsv1beta1.VaultAuth{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "vso-transit",
+		},
+		Spec: secretsv1beta1.VaultAuthSpec{
+			StorageEncryption: &secretsv1beta1.StorageEncryption{
+				Mount:   "transit",
+				KeyName: "vso",
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().Build()
+	storageConfig := DefaultClientCacheStorageConfig()
+	storageConfig.EnforceEncryption = true
+	c, err := newDefaultClientCacheStorage(ctx, client, storageConfig, nil)
+	require.NoError(t, err)
+
+	_, err = c.Rewrap(ctx, client, ClientCacheStorageRewrapRequest{})
+	assert.Error(t, err)
+
+	// store a Client for each of the key versions 1 to 3.
+	var secrets []*corev1.Secret
+	for i := 0; i < 3; i++ {
+		keyVersion = i + 1
+		s, err := c.Store(ctx, client, ClientCacheStorageStoreRequest{
+			Client: &defaultClient{
+				authObj: &secretsv1beta1.VaultAuth{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: fmt.Sprintf("auth-%d", i),
+						UID:  types.UID(uuid.New().String()),
+					},
+					Spec: secretsv1beta1.VaultAuthSpec{
+						Method: "kubernetes",
+					},
+				},
+				connObj: &secretsv1beta1.VaultConnection{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: fmt.Sprintf("conn-%d", i),
+						UID:  types.UID(uuid.New().String()),
+					},
+				},
+				credentialProvider: vault.NewKubernetesCredentialProvider(nil, "",
+					types.UID(uuid.New().String())),
+			},
+			EncryptionClient:    encClient,
+			EncryptionVaultAuth: encAuthObj,
+		})
+		require.NoError(t, err)
+		secrets = append(secrets, s)
+	}
+
+	req := ClientCacheStorageRewrapRequest{
+		EncryptionClient:    encClient,
+		EncryptionVaultAuth: encAuthObj,
+	}
+	got, err := c.Rewrap(ctx, client, req)
+	require.NoError(t, err)
+	assert.Equal(t, &ClientCacheStorageRewrapResult{
+		KeyVersions: TransitKeyVersions{
+			LatestVersion:        3,
+			MinDecryptionVersion: 2,
+		},
+		Rewrapped: 1,
+		Stranded:  1,
+	}, got)
+
+	// the entry below the minimum decryption version is pruned.
+	assertCacheSecretLen(t, ctx, client, 2)
+	var stranded corev1.Secret
+	assert.True(t, apierrors.IsNotFound(
+		client.Get(ctx, ctrlclient.ObjectKeyFromObject(secrets[0]), &stranded)))
+
+	// the rewrapped entry is still restorable.
+	for _, s := range secrets[1:] {
+		var v encryptResponse
+		var cur corev1.Secret
+		require.NoError(t, client.Get(ctx, ctrlclient.ObjectKeyFromObject(s), &cur))
+		require.NoError(t, json.Unmarshal(cur.Data[fieldCachedSecret], &v))
+		assert.True(t, strings.HasPrefix(v.Ciphertext, "vault:v3:"), v.Ciphertext)
+		assert.Equal(t, s.Labels, cur.Labels)
+		assert.True(t, *cur.Immutable)
+
+		_, err := c.Restore(ctx, client, ClientCacheStorageRestoreRequest{
+			SecretObjKey:        ctrlclient.ObjectKeyFromObject(s),
+			CacheKey:            ClientCacheKey(s.Labels[labelCacheKey]),
+			DecryptionClient:    encClient,
+			DecryptionVaultAuth: encAuthObj,
+		})
+		assert.NoError(t, err)
+	}
+
+	// nothing is left to rewrap.
+	got, err = c.Rewrap(ctx, client, req)
+	require.NoError(t, err)
+	assert.Zero(t, got.Rewrapped)
+	assert.Zero(t, got.Stranded)
+}
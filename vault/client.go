@@ -5,13 +5,17 @@ package vault
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/acme"
 	"golang.org/x/crypto/blake2b"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -31,6 +35,14 @@ type ClientOptions struct {
 	WatcherDoneCh             chan<- *ClientCallbackHandlerRequest
 	GlobalVaultAuthOptions    *common.GlobalVaultAuthOptions
 	CredentialProviderFactory credentials.CredentialProviderFactory
+	// NamespaceRemap maps renamed Vault namespaces to their new name.
+	NamespaceRemap common.NamespaceRemap
+	// readCache caches the responses of identical KV reads, it is set by the
+	// CachingClientFactory.
+	readCache *readCache
+	// loginLimiter limits the number of concurrent logins per auth mount, it is
+	// set by the CachingClientFactory.
+	loginLimiter *loginLimiter
 }
 
 func defaultClientOptions() *ClientOptions {
@@ -167,11 +179,13 @@ type Client interface {
 	Login(context.Context, ctrlclient.Client) error
 	Restore(context.Context, *api.Secret) error
 	GetTokenSecret() *api.Secret
+	TokenRenewals() int
 	CheckExpiry(int64) (bool, error)
 	Validate(ctx context.Context) error
 	GetVaultAuthObj() *secretsv1beta1.VaultAuth
 	GetVaultConnectionObj() *secretsv1beta1.VaultConnection
 	GetCredentialProvider() provider.CredentialProviderBase
+	ActiveAuthMethod() string
 	GetCacheKey() (ClientCacheKey, error)
 	Close(bool)
 	Clone(string) (Client, error)
@@ -181,29 +195,37 @@ type Client interface {
 	Tainted() bool
 	Untaint() bool
 	WebsocketClient(string) (*WebsocketClient, error)
+	ACMEClient(string, crypto.Signer) (*acme.Client, error)
 }
 
 var _ Client = (*defaultClient)(nil)
 
 type defaultClient struct {
-	client             *api.Client
-	isClone            bool
-	authObj            *secretsv1beta1.VaultAuth
-	connObj            *secretsv1beta1.VaultConnection
-	authSecret         *api.Secret
-	skipRenewal        bool
-	lastRenewal        int64
-	targetNamespace    string
-	credentialProvider provider.CredentialProviderBase
-	watcher            *api.LifetimeWatcher
-	inClosing          bool
-	closed             bool
-	lastWatcherErr     error
-	watcherDoneCh      chan<- *ClientCallbackHandlerRequest
-	tainted            bool
-	once               sync.Once
-	mu                 sync.RWMutex
-	id                 string
+	client              *api.Client
+	websocketHTTPClient *http.Client
+	websocketConfig     *WebsocketConfig
+	isClone             bool
+	authObj             *secretsv1beta1.VaultAuth
+	connObj             *secretsv1beta1.VaultConnection
+	authSecret          *api.Secret
+	skipRenewal         bool
+	lastRenewal         int64
+	renewals            int
+	targetNamespace     string
+	credentialProvider  provider.CredentialProviderBase
+	fallbacks           []*authMethod
+	activeMethod        string
+	watcher             *api.LifetimeWatcher
+	inClosing           bool
+	closed              bool
+	lastWatcherErr      error
+	watcherDoneCh       chan<- *ClientCallbackHandlerRequest
+	readCache           *readCache
+	loginLimiter        *loginLimiter
+	tainted             bool
+	once                sync.Once
+	mu                  sync.RWMutex
+	id                  string
 }
 
 // Untaint the client, marking it as untainted. This should be done after the
@@ -306,15 +328,22 @@ func (c *defaultClient) Clone(namespace string) (Client, error) {
 	}
 
 	client := &defaultClient{
-		client:             clone,
-		isClone:            true,
-		authObj:            c.authObj,
-		connObj:            c.connObj,
-		authSecret:         c.authSecret,
-		skipRenewal:        true,
-		targetNamespace:    c.targetNamespace,
-		credentialProvider: c.credentialProvider,
-		id:                 c.id,
+		client:              clone,
+		websocketHTTPClient: c.websocketHTTPClient,
+		websocketConfig:     c.websocketConfig,
+		isClone:             true,
+		authObj:             c.authObj,
+		connObj:             c.connObj,
+		authSecret:          c.authSecret,
+		renewals:            c.renewals,
+		skipRenewal:         true,
+		targetNamespace:     c.targetNamespace,
+		credentialProvider:  c.credentialProvider,
+		fallbacks:           c.fallbacks,
+		activeMethod:        c.activeMethod,
+		readCache:           c.readCache,
+		loginLimiter:        c.loginLimiter,
+		id:                  c.id,
 	}
 	client.SetNamespace(namespace)
 
@@ -364,6 +393,7 @@ func (c *defaultClient) Restore(ctx context.Context, secret *api.Secret) error {
 	}
 
 	c.authSecret = secret
+	c.renewals = 0
 	c.client.SetToken(secret.Auth.ClientToken)
 
 	id, err := c.hashAccessor()
@@ -435,6 +465,15 @@ func (c *defaultClient) GetTokenSecret() *api.Secret {
 	return c.authSecret
 }
 
+// TokenRenewals returns the number of times the Client's token was renewed
+// since its last login.
+func (c *defaultClient) TokenRenewals() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.renewals
+}
+
 // Close un-initializes this Client, stopping its LifetimeWatcher in the process and optionally revoking the token.
 // It is safe to be called multiple times.
 func (c *defaultClient) Close(revoke bool) {
@@ -539,6 +578,7 @@ func (c *defaultClient) startLifetimeWatcher(ctx context.Context) error {
 
 				c.authSecret = renewal.Secret
 				c.lastRenewal = renewal.RenewedAt.Unix()
+				c.renewals++
 			}
 		}
 	}(ctx, c, watcher)
@@ -567,12 +607,6 @@ func (c *defaultClient) Login(ctx context.Context, client ctrlclient.Client) err
 		c.watcher.Stop()
 	}
 
-	creds, err := c.credentialProvider.GetCreds(ctx, client)
-	if err != nil {
-		errs = err
-		return errs
-	}
-
 	if len(c.authObj.Spec.Headers) > 0 {
 		defer c.client.SetHeaders(c.client.Headers())
 		headers := c.client.Headers()
@@ -582,31 +616,46 @@ func (c *defaultClient) Login(ctx context.Context, client ctrlclient.Client) err
 		c.client.SetHeaders(headers)
 	}
 
-	path := fmt.Sprintf("auth/%s/login", c.authObj.Spec.Mount)
-	resp, err := c.Write(ctx, &defaultWriteRequest{
-		path:   path,
-		params: creds,
-	})
-	if err != nil {
-		errs = err
-		return errs
-	}
+	// every login starts with the primary auth method, the fallbacks are only
+	// tried in order when the previous auth method failed with an auth specific
+	// error.
+	methods := append([]*authMethod{c.primaryAuthMethod()}, c.fallbacks...)
+	var secret *api.Secret
+	var active *authMethod
+	for i, m := range methods {
+		var fallback bool
+		var err error
+		secret, fallback, err = c.loginWith(ctx, client, m)
+		if err == nil {
+			active = m
+			break
+		}
 
-	secret := resp.Secret()
-	if secret == nil {
-		errs = fmt.Errorf("empty response from Vault, path=%q", path)
-		return errs
-	}
+		if len(c.fallbacks) == 0 {
+			errs = err
+			return errs
+		}
 
-	if secret.Auth == nil {
-		errs = fmt.Errorf("auth secret is nil")
-		return errs
+		errs = errors.Join(errs, fmt.Errorf("login with auth method %s failed: %w", m, err))
+		if !fallback || i == len(methods)-1 {
+			return errs
+		}
+
+		log.FromContext(ctx).Info("Login failed, falling back to the next auth method",
+			"authMethod", m.String(), "nextAuthMethod", methods[i+1].String(), "err", err)
+	}
+	// reset the errors of the auth methods that were tried before the
+	// successful one.
+	errs = nil
+	if len(c.fallbacks) > 0 {
+		c.activeMethod = active.String()
 	}
 
 	c.client.SetToken(secret.Auth.ClientToken)
 
 	c.authSecret = secret
 	c.lastRenewal = time.Now().Unix()
+	c.renewals = 0
 
 	id, err := c.hashAccessor()
 	if err != nil {
@@ -615,7 +664,7 @@ func (c *defaultClient) Login(ctx context.Context, client ctrlclient.Client) err
 
 	c.id = id
 
-	if resp.Secret().Auth.Renewable {
+	if secret.Auth.Renewable {
 		if err := c.startLifetimeWatcher(ctx); err != nil {
 			errs = err
 			return errs
@@ -628,6 +677,72 @@ func (c *defaultClient) Login(ctx context.Context, client ctrlclient.Client) err
 	return nil
 }
 
+// loginWith logs in to Vault with the auth method m, returning the auth secret.
+// The returned bool is true if the error is specific to the auth method, in
+// which case the next fallback auth method can be tried.
+func (c *defaultClient) loginWith(ctx context.Context, client ctrlclient.Client, m *authMethod) (*api.Secret, bool, error) {
+	// the credentials are requested within the login slot as well, since they
+	// may be subject to the same backend, e.g. the TokenRequest API.
+	release, err := c.loginLimiter.acquire(ctx, c.client.Address(), c.client.Namespace(), m.method, m.mount)
+	if err != nil {
+		return nil, false, err
+	}
+	defer release()
+
+	creds, err := m.provider.GetCreds(ctx, client)
+	if err != nil {
+		return nil, true, err
+	}
+
+	path := fmt.Sprintf("auth/%s/login", m.mount)
+	resp, err := c.Write(ctx, &defaultWriteRequest{
+		path:   path,
+		params: creds,
+	})
+	if err != nil {
+		if d, ok := m.provider.(provider.LoginDiagnoser); ok && IsForbiddenError(err) {
+			if diagnostics := d.DiagnoseLogin(ctx, client, creds); len(diagnostics) > 0 {
+				err = &LoginDiagnosticsError{
+					Err:         err,
+					Diagnostics: diagnostics,
+				}
+			}
+		}
+		return nil, isAuthFallbackError(err), err
+	}
+
+	secret := resp.Secret()
+	if secret == nil {
+		return nil, true, fmt.Errorf("empty response from Vault, path=%q", path)
+	}
+
+	if secret.Auth == nil {
+		return nil, true, fmt.Errorf("auth secret is nil")
+	}
+
+	return secret, false, nil
+}
+
+// primaryAuthMethod returns the auth method that is configured on the
+// VaultAuth's spec.
+func (c *defaultClient) primaryAuthMethod() *authMethod {
+	return &authMethod{
+		method:   c.authObj.Spec.Method,
+		mount:    c.authObj.Spec.Mount,
+		provider: c.credentialProvider,
+	}
+}
+
+// ActiveAuthMethod returns the auth method that was used for the last
+// successful login, formatted as <method>/<mount>. It is only set when the
+// VaultAuth has Fallbacks configured.
+func (c *defaultClient) ActiveAuthMethod() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.activeMethod
+}
+
 func (c *defaultClient) hashAccessor() (string, error) {
 	accessor, err := c.accessor()
 	if err != nil {
@@ -680,6 +795,20 @@ func (c *defaultClient) GetVaultConnectionObj() *secretsv1beta1.VaultConnection
 }
 
 func (c *defaultClient) Read(ctx context.Context, request ReadRequest) (Response, error) {
+	// responses served by the read cache are not counted as Vault operations.
+	var readKey string
+	var cacheable bool
+	if c.readCache != nil {
+		if cacheKey, err := c.GetCacheKey(); err == nil {
+			readKey, cacheable = readCacheKey(ctx, cacheKey, c.client.Namespace(), request)
+		}
+	}
+	if cacheable && !readCacheBypassed(ctx) {
+		if resp, ok := c.readCache.get(readKey); ok {
+			return resp, nil
+		}
+	}
+
 	var err error
 	startTS := time.Now()
 	defer func() {
@@ -701,16 +830,26 @@ func (c *defaultClient) Read(ctx context.Context, request ReadRequest) (Response
 
 	path := request.Path()
 	var secret *api.Secret
-	secret, err = c.client.Logical().ReadWithDataWithContext(ctx, path, request.Values())
+	var throttle throttleState
+	client, recordState := withReplicationState(ctx, c.client,
+		[]api.ResponseCallback{throttle.record}, wrapTTLCallbacks(ctx)...)
+	secret, err = client.Logical().ReadWithDataWithContext(ctx, path, request.Values())
+	recordState()
+	err = c.throttled(&throttle, err)
 	if err != nil {
 		return nil, err
 	}
 
 	if secret == nil {
-		return nil, fmt.Errorf("empty response from Vault, path=%q", path)
+		return nil, &EmptyResponseError{Path: path}
+	}
+
+	resp := respFunc(secret)
+	if cacheable {
+		c.readCache.set(readKey, resp)
 	}
 
-	return respFunc(secret), nil
+	return resp, nil
 }
 
 func (c *defaultClient) Write(ctx context.Context, req WriteRequest) (Response, error) {
@@ -722,7 +861,12 @@ func (c *defaultClient) Write(ctx context.Context, req WriteRequest) (Response,
 	}()
 
 	var secret *api.Secret
-	secret, err = c.client.Logical().WriteWithContext(ctx, req.Path(), req.Params())
+	var throttle throttleState
+	client, recordState := withReplicationState(ctx, c.client,
+		[]api.ResponseCallback{throttle.record}, wrapTTLCallbacks(ctx)...)
+	secret, err = client.Logical().WriteWithContext(ctx, req.Path(), req.Params())
+	recordState()
+	err = c.throttled(&throttle, err)
 
 	return &defaultResponse{secret: secret}, err
 }
@@ -757,6 +901,7 @@ func (c *defaultClient) renew(ctx context.Context) error {
 	} else {
 		c.authSecret = resp.Secret()
 		c.lastRenewal = time.Now().UTC().Unix()
+		c.renewals++
 	}
 	return nil
 }
@@ -772,7 +917,7 @@ func (c *defaultClient) init(ctx context.Context, client ctrlclient.Client,
 		return errors.New("VaultAuth was nil")
 	}
 
-	cfg, err := NewClientConfigFromConnObj(connObj, authObj.Spec.Namespace)
+	cfg, err := NewClientConfigFromConnObj(connObj, opts.NamespaceRemap.Remap(authObj.Spec.Namespace))
 	if err != nil {
 		return err
 	}
@@ -780,22 +925,110 @@ func (c *defaultClient) init(ctx context.Context, client ctrlclient.Client,
 	if err != nil {
 		return err
 	}
+	wsHTTPClient, err := MakeWebsocketHTTPClient(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
 
 	credentialProvider, err := opts.CredentialProviderFactory.New(ctx, client, authObj, providerNamespace)
 	if err != nil {
 		return err
 	}
 
+	var fallbacks []*authMethod
+	for _, f := range authObj.Spec.Fallbacks {
+		p, err := opts.CredentialProviderFactory.New(ctx, client, fallbackVaultAuth(authObj, f), providerNamespace)
+		if err != nil {
+			return fmt.Errorf("invalid fallback auth method %s/%s: %w", f.Method, f.Mount, err)
+		}
+		fallbacks = append(fallbacks, &authMethod{
+			method:   f.Method,
+			mount:    f.Mount,
+			provider: p,
+		})
+	}
+
 	c.skipRenewal = opts.SkipRenewal
 	c.credentialProvider = credentialProvider
+	c.fallbacks = fallbacks
 	c.client = vc
+	c.websocketHTTPClient = wsHTTPClient
+	c.websocketConfig = cfg.Websocket
 	c.authObj = authObj
 	c.connObj = connObj
 	c.watcherDoneCh = opts.WatcherDoneCh
+	c.readCache = opts.readCache
+	c.loginLimiter = opts.loginLimiter
 
 	return nil
 }
 
+// SetVaultClientMetaToken sets the fields of meta that describe the Vault token
+// of the Client c.
+func SetVaultClientMetaToken(meta *secretsv1beta1.VaultClientMeta, c Client) {
+	meta.Accessor = ""
+	meta.TokenTTL = 0
+	meta.TokenPolicies = nil
+	meta.TokenRenewals = c.TokenRenewals()
+
+	secret := c.GetTokenSecret()
+	if secret == nil {
+		return
+	}
+	if accessor, err := secret.TokenAccessor(); err == nil {
+		meta.Accessor = accessor
+	}
+	if ttl, err := secret.TokenTTL(); err == nil {
+		meta.TokenTTL = int64(ttl.Seconds())
+	}
+	if policies, err := secret.TokenPolicies(); err == nil {
+		meta.TokenPolicies = policies
+	}
+}
+
+// authMethod is an auth method that the Client can log in to Vault with.
+type authMethod struct {
+	method   string
+	mount    string
+	provider provider.CredentialProviderBase
+}
+
+func (m *authMethod) String() string {
+	return fmt.Sprintf("%s/%s", m.method, m.mount)
+}
+
+// fallbackVaultAuth returns a copy of authObj that is configured with the auth
+// method of the fallback f, for setting up its credential provider.
+func fallbackVaultAuth(authObj *secretsv1beta1.VaultAuth, f secretsv1beta1.VaultAuthFallback) *secretsv1beta1.VaultAuth {
+	o := authObj.DeepCopy()
+	o.Spec.Method = f.Method
+	o.Spec.Mount = f.Mount
+	o.Spec.Kubernetes = f.Kubernetes
+	o.Spec.AppRole = f.AppRole
+	o.Spec.JWT = f.JWT
+	o.Spec.AWS = f.AWS
+	o.Spec.GCP = f.GCP
+	o.Spec.Fallbacks = nil
+	return o
+}
+
+// isAuthFallbackError returns true if err is specific to the auth method that
+// was used to log in to Vault. Errors that would also fail the login with any
+// other auth method, like Vault being unreachable, sealed, or rate limiting the
+// requests, are not.
+func isAuthFallbackError(err error) bool {
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) && respErr != nil {
+		switch respErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return false
+		default:
+			return true
+		}
+	}
+	return false
+}
+
 func (c *defaultClient) observeTime(ts time.Time, operation string) {
 	if c.connObj == nil {
 		// should not happen on a properly initialized Client
@@ -820,6 +1053,16 @@ func (c *defaultClient) incrementOperationCounter(operation string, err error) {
 	}
 }
 
+// throttled returns the error of a request whose response was recorded in
+// throttle, converting it to a ThrottledError if Vault throttled the request.
+func (c *defaultClient) throttled(throttle *throttleState, err error) error {
+	err = throttle.err(err)
+	if c.connObj != nil && IsThrottledError(err) {
+		incResponsesThrottled(ctrlclient.ObjectKeyFromObject(c.connObj), throttle.statusCode)
+	}
+	return err
+}
+
 type MockRequest struct {
 	Method string
 	Path   string
@@ -897,13 +1140,26 @@ func NewClientConfigFromConnObj(connObj *secretsv1beta1.VaultConnection, vaultNS
 	}
 
 	cfg := &ClientConfig{
-		Address:         connObj.Spec.Address,
-		SkipTLSVerify:   connObj.Spec.SkipTLSVerify,
-		TLSServerName:   connObj.Spec.TLSServerName,
-		K8sNamespace:    connObj.Namespace,
-		CACertSecretRef: connObj.Spec.CACertSecretRef,
-		Headers:         connObj.Spec.Headers,
-		VaultNamespace:  vaultNS,
+		Address:             connObj.Spec.Address,
+		SkipTLSVerify:       connObj.Spec.SkipTLSVerify,
+		TLSServerName:       connObj.Spec.TLSServerName,
+		K8sNamespace:        connObj.Namespace,
+		CACertSecretRef:     connObj.Spec.CACertSecretRef,
+		ClientCertSecretRef: connObj.Spec.ClientCertSecretRef,
+		Headers:             connObj.Spec.Headers,
+		VaultNamespace:      vaultNS,
+		Connection:          ctrlclient.ObjectKeyFromObject(connObj),
+	}
+
+	if ref := connObj.Spec.CABundleRef; ref != nil {
+		if connObj.Spec.CACertSecretRef != "" {
+			return nil, errors.New("caCertSecretRef and caBundleRef are mutually exclusive")
+		}
+		cfg.CABundleRef = &CABundleRef{
+			Kind: ref.Kind,
+			Name: ref.Name,
+			Key:  ref.Key,
+		}
 	}
 
 	if connObj.Spec.Timeout != "" {
@@ -913,5 +1169,85 @@ func NewClientConfigFromConnObj(connObj *secretsv1beta1.VaultConnection, vaultNS
 		}
 		cfg.Timeout = &d
 	}
+
+	if rl := connObj.Spec.RateLimit; rl != nil {
+		cfg.RateLimit = &RateLimitConfig{
+			RequestsPerSecond: float64(rl.RequestsPerSecond),
+			Burst:             rl.Burst,
+		}
+		if cfg.RateLimit.Burst <= 0 {
+			cfg.RateLimit.Burst = rl.RequestsPerSecond
+		}
+	}
+
+	if cb := connObj.Spec.CircuitBreaker; cb != nil {
+		cfg.CircuitBreaker = &CircuitBreakerConfig{
+			FailureThreshold: cb.FailureThreshold,
+			OpenDuration:     defaultCircuitBreakerOpenDuration,
+		}
+		if cfg.CircuitBreaker.FailureThreshold <= 0 {
+			cfg.CircuitBreaker.FailureThreshold = defaultCircuitBreakerFailureThreshold
+		}
+		if cb.OpenDuration != "" {
+			d, err := time.ParseDuration(cb.OpenDuration)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse circuit breaker open duration: %w", err)
+			}
+			cfg.CircuitBreaker.OpenDuration = d
+		}
+	}
+
+	if connObj.Spec.ProxyURL != "" {
+		u, err := url.Parse(connObj.Spec.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		cfg.ProxyURL = u
+	}
+
+	if connObj.Spec.DialTimeout != "" {
+		d, err := time.ParseDuration(connObj.Spec.DialTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse dial timeout: %w", err)
+		}
+		cfg.DialTimeout = &d
+	}
+
+	if dns := connObj.Spec.DNS; dns != nil {
+		for _, ns := range dns.Nameservers {
+			if _, _, err := net.SplitHostPort(ns); err != nil {
+				return nil, fmt.Errorf("invalid DNS nameserver %q: %w", ns, err)
+			}
+		}
+		cfg.DNSNameservers = dns.Nameservers
+	}
+
+	if ws := connObj.Spec.Websocket; ws != nil {
+		cfg.Websocket = &WebsocketConfig{
+			CACertSecretRef: ws.CACertSecretRef,
+		}
+		if ws.ProxyURL != "" {
+			u, err := url.Parse(ws.ProxyURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse websocket proxy URL: %w", err)
+			}
+			cfg.Websocket.ProxyURL = u
+		}
+		if ws.DialTimeout != "" {
+			d, err := time.ParseDuration(ws.DialTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse websocket dial timeout: %w", err)
+			}
+			cfg.Websocket.DialTimeout = &d
+		}
+		if ws.PingInterval != "" {
+			d, err := time.ParseDuration(ws.PingInterval)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse websocket ping interval: %w", err)
+			}
+			cfg.Websocket.PingInterval = &d
+		}
+	}
+
 	return cfg, nil
 }
@@ -7,12 +7,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -110,6 +112,8 @@ type CachingClientFactory interface {
 	Start(context.Context)
 	Stop()
 	ShutDown(CachingClientFactoryShutDownRequest)
+	Stats() ClientCacheStats
+	Prewarm(context.Context, ctrlclient.Client) (int, error)
 }
 
 var _ CachingClientFactory = (*cachingClientFactory)(nil)
@@ -128,12 +132,19 @@ type cachingClientFactory struct {
 	requestErrorCounterVec *prometheus.CounterVec
 	revokeOnEvict          bool
 	pruneStorageOnEvict    bool
+	// revokeTokensOnEviction enables token revocation for every Client that is
+	// evicted from the ClientCache, rather than only on ShutDown.
+	revokeTokensOnEviction bool
 	ctrlClient             ctrlclient.Client
 	clientCallbacks        []ClientCallbackHandler
 	callbackHandlerCh      chan *ClientCallbackHandlerRequest
 	mu                     sync.RWMutex
 	onceDoWatcher          sync.Once
 	callbackHandlerCancel  context.CancelFunc
+	// storageRewrapInterval is the interval at which the stored Clients are
+	// rewrapped with the latest version of the storage encryption Transit key.
+	storageRewrapInterval time.Duration
+	storageRewrapCancel   context.CancelFunc
 	// clientMutex is a mutex that is used to lock the client factory's cache by ClientCacheKey.
 	clientMutex keymutex.KeyMutex
 	// encClientLock is a lock for the encryption client. It is used to ensure that
@@ -148,6 +159,16 @@ type cachingClientFactory struct {
 	GlobalVaultAuthOptions *common.GlobalVaultAuthOptions
 	// credentialProviderFactory is a function that returns a CredentialProvider.
 	credentialProviderFactory credentials.CredentialProviderFactory
+	// namespaceRemap maps renamed Vault namespaces to their new name.
+	namespaceRemap common.NamespaceRemap
+	// allowedVaultNamespaces restricts the Vault namespaces that each Kubernetes
+	// namespace may target.
+	allowedVaultNamespaces common.AllowedVaultNamespaces
+	// readCache caches the responses of identical KV reads for all Clients.
+	readCache *readCache
+	// loginLimiter limits the number of concurrent logins per auth mount for
+	// all Clients.
+	loginLimiter *loginLimiter
 }
 
 // Start method for cachingClientFactory starts the lifetime watcher handler.
@@ -156,6 +177,7 @@ func (m *cachingClientFactory) Start(ctx context.Context) {
 	defer m.mu.Unlock()
 	m.onceDoWatcher.Do(func() {
 		m.startClientCallbackHandler(ctx)
+		m.startStorageRewrap(ctx)
 	})
 }
 
@@ -166,6 +188,9 @@ func (m *cachingClientFactory) Stop() {
 	if m.callbackHandlerCancel != nil {
 		m.callbackHandlerCancel()
 	}
+	if m.storageRewrapCancel != nil {
+		m.storageRewrapCancel()
+	}
 }
 
 // RegisterClientCallbackHandler registers a ClientCallbackHandler with the
@@ -255,9 +280,12 @@ func (m *cachingClientFactory) pruneStorage(ctx context.Context, client ctrlclie
 func (m *cachingClientFactory) onClientEvict(ctx context.Context, client ctrlclient.Client, cacheKey ClientCacheKey, c Client) {
 	logger := m.logger.WithValues("cacheKey", cacheKey)
 	logger.Info("Handling client cache eviction")
-	c.Close(m.revokeOnEvict)
+	// clones share their parent's token, so they must never revoke it.
+	revoke := m.revokeOnEvict || (m.revokeTokensOnEviction && !c.IsClone())
+	c.Close(revoke)
 
-	if m.storageEnabled() && m.pruneStorageOnEvict {
+	// a revoked Client can never be restored, so its storage entry must be pruned.
+	if m.storageEnabled() && (m.pruneStorageOnEvict || revoke) {
 		if count, err := m.pruneStorage(ctx, client, cacheKey); err != nil {
 			logger.Error(err, "Failed to remove Client from storage")
 		} else {
@@ -295,6 +323,161 @@ func (m *cachingClientFactory) Restore(ctx context.Context, client ctrlclient.Cl
 	return m.restoreClientFromCacheKey(ctx, client, cacheKey)
 }
 
+// startStorageRewrap starts rewrapping the stored Clients in the background,
+// every storageRewrapInterval, it is a no-op unless the storage is encrypted by
+// Vault Transit.
+func (m *cachingClientFactory) startStorageRewrap(ctx context.Context) {
+	if m.storageRewrapInterval <= 0 || !m.storageEnabled() || !m.encryptionRequired {
+		return
+	}
+
+	rewrapCtx, cancel := context.WithCancel(ctx)
+	m.storageRewrapCancel = cancel
+
+	logger := log.FromContext(ctx).WithName("storageRewrap")
+	logger.Info("Starting client cache storage rewrap", "interval", m.storageRewrapInterval)
+	go func() {
+		ticker := time.NewTicker(m.storageRewrapInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-rewrapCtx.Done():
+				logger.Info("Client cache storage rewrap done")
+				return
+			case <-ticker.C:
+				if _, err := m.rewrapStorage(rewrapCtx, m.ctrlClient); err != nil {
+					logger.Error(err, "Failed to rewrap the client cache storage")
+				}
+			}
+		}
+	}()
+}
+
+// rewrapStorage rewraps all stored Clients with the latest version of the
+// storage encryption Transit key. A warning event is recorded on the
+// encryption VaultAuth whenever stored Clients were stranded by the key's
+// minimum decryption version.
+func (m *cachingClientFactory) rewrapStorage(ctx context.Context, client ctrlclient.Client) (*ClientCacheStorageRewrapResult, error) {
+	if m.isDisabled() {
+		return nil, &ClientFactoryDisabledError{}
+	}
+
+	if !m.storageEnabled() || !m.encryptionRequired {
+		return nil, fmt.Errorf("rewrap impossible, storage encryption is not enabled")
+	}
+
+	var errs error
+	defer func() {
+		m.incrementRequestCounter(metrics.OperationRewrap, errs)
+	}()
+
+	c, err := m.storageEncryptionClient(ctx, client)
+	if err != nil {
+		errs = err
+		return nil, errs
+	}
+
+	authObj := c.GetVaultAuthObj()
+	result, err := m.storage.Rewrap(ctx, client, ClientCacheStorageRewrapRequest{
+		EncryptionClient:    c,
+		EncryptionVaultAuth: authObj,
+	})
+	if err != nil {
+		errs = err
+	}
+
+	if result != nil {
+		if result.Stranded > 0 {
+			m.recorder.Eventf(authObj, v1.EventTypeWarning, consts.ReasonTransitKeyVersionStranded,
+				"Pruned %d stored Vault client(s) encrypted with a key version below the "+
+					"minimum decryption version %d of the storage encryption key",
+				result.Stranded, result.KeyVersions.MinDecryptionVersion)
+		}
+		m.logger.V(consts.LogLevelDebug).Info("Rewrapped the client cache storage",
+			"rewrapped", result.Rewrapped, "stranded", result.Stranded,
+			"latestVersion", result.KeyVersions.LatestVersion)
+	}
+
+	return result, errs
+}
+
+// Prewarm restores all Clients from the ClientCacheStorage into the in-memory
+// ClientCache. The token of each restored Client is renewed, and then kept
+// renewed in the background by the Client's LifetimeWatcher, independently of
+// any secret sync. It is meant to be called on startup, before the first
+// reconciliation, so that the operator does not need to log in again for every
+// cached Client. Clients that cannot be restored are pruned from the storage,
+// and are recreated on their next use. It returns the number of restored
+// Clients.
+func (m *cachingClientFactory) Prewarm(ctx context.Context, client ctrlclient.Client) (int, error) {
+	if m.isDisabled() {
+		return 0, &ClientFactoryDisabledError{}
+	}
+
+	if !m.storageEnabled() {
+		return 0, fmt.Errorf("pre-warm impossible, storage is not enabled")
+	}
+
+	var errs error
+	defer func() {
+		m.incrementRequestCounter(metrics.OperationPrewarm, errs)
+	}()
+
+	cacheKeys, err := m.storage.CacheKeys(ctx, client)
+	if err != nil {
+		errs = err
+		return 0, errs
+	}
+
+	logger := log.FromContext(ctx).WithName("cachingClientFactory")
+	var count int
+	for _, cacheKey := range cacheKeys {
+		if m.prewarmClient(ctx, client, cacheKey) {
+			count++
+		}
+	}
+
+	logger.Info("Pre-warmed the client cache", "restored", count, "total", len(cacheKeys))
+	return count, nil
+}
+
+// prewarmClient restores the Client for cacheKey from the ClientCacheStorage,
+// it returns true if the Client was restored.
+func (m *cachingClientFactory) prewarmClient(ctx context.Context, client ctrlclient.Client, cacheKey ClientCacheKey) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	logger := log.FromContext(ctx).WithName("cachingClientFactory").WithValues("cacheKey", cacheKey)
+	cacheKeyForLock := cacheKey.String()
+	m.clientMutex.LockKey(cacheKeyForLock)
+	defer func() {
+		if err := m.clientMutex.UnlockKey(cacheKeyForLock); err != nil {
+			logger.Error(err, "Failed to unlock client mutex")
+		}
+	}()
+
+	if _, ok := m.cache.Get(cacheKey); ok {
+		return false
+	}
+
+	c, err := m.restoreClientFromCacheKey(ctx, client, cacheKey)
+	if err != nil {
+		logger.V(consts.LogLevelDebug).Info("Failed to pre-warm client", "err", err)
+		return false
+	}
+
+	if err := m.recordVaultAuthStatus(ctx, client, c, true); err != nil {
+		logger.Error(err, "Failed to record the Vault client in the VaultAuth status")
+	}
+
+	return true
+}
+
+// Stats returns the ClientCacheStats of the factory's client cache.
+func (m *cachingClientFactory) Stats() ClientCacheStats {
+	return m.cache.Stats()
+}
+
 // ShutDown will attempt to revoke all Client tokens in memory.
 // This should be called upon operator deployment deletion if client cache cleanup is required.
 func (m *cachingClientFactory) ShutDown(req CachingClientFactoryShutDownRequest) {
@@ -377,8 +560,31 @@ func (m *cachingClientFactory) Get(ctx context.Context, client ctrlclient.Client
 	if err != nil {
 		return nil, err
 	}
+	// relative namespaces are resolved against the "root" Client's namespace,
+	// which has already been remapped.
+	relative := common.IsRelativeVaultNamespace(ns)
+	if !relative {
+		ns = m.namespaceRemap.Remap(ns)
+	}
 
 	namespacedClient := func(c Client) (Client, error) {
+		ns := ns
+		if relative {
+			var err error
+			ns, err = common.ResolveVaultNamespace(c.Namespace(), ns)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		effectiveNS := ns
+		if effectiveNS == "" {
+			effectiveNS = c.Namespace()
+		}
+		if err := m.allowedVaultNamespaces.Allowed(obj.GetNamespace(), effectiveNS); err != nil {
+			return nil, err
+		}
+
 		// handle the case where the "root" Client's namespace differs from that of the one specified in obj.Spec.Namespace.
 		// in which case we cache and return the namespaced Clone of the "root" Client.
 		if ns != "" && ns != c.Namespace() {
@@ -424,6 +630,9 @@ func (m *cachingClientFactory) Get(ctx context.Context, client ctrlclient.Client
 			}
 		} else {
 			c.Untaint()
+			if err := m.recordVaultAuthStatus(ctx, client, c, false); err != nil {
+				logger.Error(err, "Failed to record the Vault client in the VaultAuth status")
+			}
 			return namespacedClient(c)
 		}
 	} else {
@@ -432,6 +641,9 @@ func (m *cachingClientFactory) Get(ctx context.Context, client ctrlclient.Client
 			// try and restore from Client storage cache, if properly configured to do so.
 			restored, err := m.restoreClientFromCacheKey(ctx, client, cacheKey)
 			if restored != nil {
+				if err := m.recordVaultAuthStatus(ctx, client, restored, true); err != nil {
+					logger.Error(err, "Failed to record the Vault client in the VaultAuth status")
+				}
 				return namespacedClient(restored)
 			}
 
@@ -445,6 +657,12 @@ func (m *cachingClientFactory) Get(ctx context.Context, client ctrlclient.Client
 	c, err = NewClientWithLogin(ctx, client, obj, m.clientOptions())
 	if err != nil {
 		logger.Error(err, "Failed to get NewClientWithLogin")
+		var diagErr *LoginDiagnosticsError
+		if errors.As(err, &diagErr) {
+			m.recorder.Eventf(obj, v1.EventTypeWarning, consts.ReasonVaultLoginDenied,
+				"Vault denied the login, the most likely causes are: %s",
+				strings.Join(diagErr.Diagnostics, "; "))
+		}
 		errs = errors.Join(err)
 		return nil, errs
 
@@ -452,6 +670,10 @@ func (m *cachingClientFactory) Get(ctx context.Context, client ctrlclient.Client
 
 	logger.V(consts.LogLevelTrace).Info("New client created",
 		"cacheKey", cacheKey, "clientID", c.ID())
+	if err := m.recordVaultAuthStatus(ctx, client, c, true); err != nil {
+		logger.Error(err, "Failed to record the Vault client in the VaultAuth status")
+	}
+
 	// cache the parent Client for future requests.
 	cacheKey, err = m.cacheClient(ctx, c, m.storageEnabled())
 	if err != nil {
@@ -468,6 +690,41 @@ func (m *cachingClientFactory) Get(ctx context.Context, client ctrlclient.Client
 	return c, errs
 }
 
+// recordVaultAuthStatus records the Client in the status of its VaultAuth.
+// loggedIn should be true if the Client has just logged in, or was restored,
+// in which case it replaces the Client that is recorded in the status.
+// Otherwise, the status is only refreshed if it already records the Client, so
+// that the Clients that share a VaultAuth do not overwrite each other. The
+// auth method that the Client logged in with is only recorded for VaultAuths
+// that have fallback auth methods configured.
+func (m *cachingClientFactory) recordVaultAuthStatus(ctx context.Context, client ctrlclient.Client, c Client, loggedIn bool) error {
+	o, err := common.GetVaultAuth(ctx, client, ctrlclient.ObjectKeyFromObject(c.GetVaultAuthObj()))
+	if err != nil {
+		return err
+	}
+
+	status := o.Status.DeepCopy()
+	if active := c.ActiveAuthMethod(); active != "" {
+		status.ActiveMethod = active
+	}
+	if loggedIn || status.VaultClientMeta.ID == c.ID() {
+		cacheKey, err := c.GetCacheKey()
+		if err != nil {
+			return err
+		}
+		status.VaultClientMeta.CacheKey = cacheKey.String()
+		status.VaultClientMeta.ID = c.ID()
+		SetVaultClientMetaToken(&status.VaultClientMeta, c)
+	}
+	if equality.Semantic.DeepEqual(status, &o.Status) {
+		return nil
+	}
+
+	patch := ctrlclient.MergeFrom(o.DeepCopy())
+	o.Status = *status
+	return client.Status().Patch(ctx, o, patch)
+}
+
 func (m *cachingClientFactory) storeClient(ctx context.Context, client ctrlclient.Client, c Client) error {
 	var errs error
 	defer func() {
@@ -568,6 +825,9 @@ func (m *cachingClientFactory) clientOptions() *ClientOptions {
 		WatcherDoneCh:             m.callbackHandlerCh,
 		GlobalVaultAuthOptions:    m.GlobalVaultAuthOptions,
 		CredentialProviderFactory: m.credentialProviderFactory,
+		NamespaceRemap:            m.namespaceRemap,
+		readCache:                 m.readCache,
+		loginLimiter:              m.loginLimiter,
 	}
 }
 
@@ -872,20 +1132,30 @@ func (m *cachingClientFactory) callClientCallbacks(ctx context.Context, c Client
 // The ClientCache's onEvictCallback is registered with the factory's onClientEvict(),
 // to ensure any evictions are handled by the factory (this is very important).
 func NewCachingClientFactory(ctx context.Context, client ctrlclient.Client, cacheStorage ClientCacheStorage, config *CachingClientFactoryConfig) (CachingClientFactory, error) {
+	// Clients are encrypted by the storage itself when a KMS is configured, so
+	// no Vault Transit encryption client is required.
+	encryptionRequired := config.StorageConfig.EnforceEncryption && config.StorageConfig.KMS == nil
 	factory := &cachingClientFactory{
 		storage:                   cacheStorage,
 		recorder:                  config.Recorder,
 		persist:                   config.Persist,
 		ctrlClient:                client,
 		callbackHandlerCh:         make(chan *ClientCallbackHandlerRequest),
-		encryptionRequired:        config.StorageConfig.EnforceEncryption,
+		encryptionRequired:        encryptionRequired,
 		encClientSetupTimeout:     config.SetupEncryptionClientTimeout,
 		clientMutex:               keymutex.NewHashed(config.ClientCacheNumLocks),
 		GlobalVaultAuthOptions:    config.GlobalVaultAuthOptions,
 		credentialProviderFactory: config.CredentialProviderFactory,
+		revokeTokensOnEviction:    config.RevokeTokensOnEviction,
+		namespaceRemap:            config.NamespaceRemap,
+		allowedVaultNamespaces:    config.AllowedVaultNamespaces,
+		readCache:                 newReadCache(config.ReadCacheTTL),
+		loginLimiter:              newLoginLimiter(config.LoginMaxConcurrency),
+		storageRewrapInterval:     config.StorageRewrapInterval,
 		logger: zap.New().WithName("clientCacheFactory").WithValues(
 			"persist", config.Persist,
 			"enforceEncryption", config.StorageConfig.EnforceEncryption,
+			"revokeTokensOnEviction", config.RevokeTokensOnEviction,
 		),
 		requestCounterVec: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -953,6 +1223,40 @@ type CachingClientFactoryConfig struct {
 	// operations. A higher number of locks will reduce contention but increase
 	// memory usage.
 	ClientCacheNumLocks int
+	// RevokeTokensOnEviction will cause the Vault token of any Client evicted from
+	// the ClientCache to be revoked via auth/token/revoke-self. This prevents
+	// orphaned tokens from accumulating in Vault until they expire.
+	RevokeTokensOnEviction bool
+	// NamespaceRemap maps renamed Vault namespaces from their old name to their
+	// new name. All Vault namespaces referenced by the old name are transparently
+	// remapped, so that existing Clients and their cache keys remain valid.
+	NamespaceRemap common.NamespaceRemap
+	// AllowedVaultNamespaces restricts the Vault namespaces that the resources in
+	// a Kubernetes namespace may target.
+	AllowedVaultNamespaces common.AllowedVaultNamespaces
+	// Prewarm restores all persisted Clients into the ClientCache, and renews
+	// their tokens, when the CachingClientFactory is initialized. It requires
+	// Persist to be enabled.
+	Prewarm bool
+	// ReadCacheTTL is the duration for which the responses of identical KV reads
+	// are cached, and shared by all callers of the same Client. A TTL of 0
+	// disables the cache.
+	ReadCacheTTL time.Duration
+	// LoginMaxConcurrency is the maximum number of concurrent logins per auth
+	// mount, keyed by auth method, see ParseLoginMaxConcurrency. Logins in
+	// excess of the limit wait in a FIFO queue. No limit is applied to the auth
+	// methods without one.
+	LoginMaxConcurrency map[string]int
+	// StorageRewrapInterval is the interval at which the persisted Clients are
+	// rewrapped with the latest version of the storage encryption Transit key,
+	// so that older key versions can be trimmed in Vault. It only applies to
+	// Vault Transit encrypted storage. An interval of 0 disables the rewrap.
+	StorageRewrapInterval time.Duration
+	// ReadOnly disables the client cache storage entirely. A read-only factory
+	// never persists, restores, nor purges cached Clients, so that the storage of
+	// another operator instance is left intact, e.g. when running in follower
+	// mode.
+	ReadOnly bool
 }
 
 // DefaultCachingClientFactoryConfig provides the default configuration for a CachingClientFactory instance.
@@ -972,7 +1276,6 @@ func DefaultCachingClientFactoryConfig() *CachingClientFactoryConfig {
 // InitCachingClientFactory initializes a CachingClientFactory along with its ClientCacheStorage.
 // It is meant to be called from main.
 func InitCachingClientFactory(ctx context.Context, client ctrlclient.Client, config *CachingClientFactoryConfig) (CachingClientFactory, error) {
-	// TODO: add support for bulk restoration
 	logger := zap.New().WithName("initCachingClientFactory")
 	logger.Info("Initializing the CachingClientFactory")
 
@@ -981,6 +1284,14 @@ func InitCachingClientFactory(ctx context.Context, client ctrlclient.Client, con
 		// register the ClientCache's metrics with the default registry.
 		metricsRegistry = ctrlmetrics.Registry
 	}
+
+	if config.ReadOnly {
+		if config.Persist {
+			return nil, fmt.Errorf("client cache persistence is not supported in read-only mode")
+		}
+		return NewCachingClientFactory(ctx, client, nil, config)
+	}
+
 	clientCacheStorage, err := NewDefaultClientCacheStorage(ctx, client, config.StorageConfig, metricsRegistry)
 	if err != nil {
 		return nil, err
@@ -1000,6 +1311,15 @@ func InitCachingClientFactory(ctx context.Context, client ctrlclient.Client, con
 		return nil, err
 	}
 
+	if config.Prewarm {
+		if !config.Persist {
+			logger.Info("Client cache pre-warm requires client cache persistence, skipping")
+		} else if _, err := clientCacheFactory.Prewarm(ctx, client); err != nil {
+			// a failed pre-warm is not fatal, the Clients are restored on demand.
+			logger.Error(err, "Failed to pre-warm the client cache")
+		}
+	}
+
 	return clientCacheFactory, nil
 }
 
@@ -12,7 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/hashicorp/vault/api"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -690,3 +692,283 @@ func invalidateClient(t *testing.T, client Client) {
 	require.NotNil(t, secret)
 	secret.Auth.LeaseDuration = 0
 }
+
+func Test_cachingClientFactory_onClientEvict(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name                   string
+		revokeOnEvict          bool
+		revokeTokensOnEviction bool
+		isClone                bool
+		wantRevoke             bool
+	}{
+		{
+			name:       "no-revoke",
+			wantRevoke: false,
+		},
+		{
+			name:          "revoke-on-shutdown",
+			revokeOnEvict: true,
+			wantRevoke:    true,
+		},
+		{
+			name:                   "revoke-tokens-on-eviction",
+			revokeTokensOnEviction: true,
+			wantRevoke:             true,
+		},
+		{
+			name:                   "revoke-tokens-on-eviction-clone",
+			revokeTokensOnEviction: true,
+			isClone:                true,
+			wantRevoke:             false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &testHandler{
+				handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+			}
+			config, l := NewTestHTTPServer(t, handler.handler())
+			t.Cleanup(func() {
+				assert.NoError(t, l.Close())
+			})
+
+			vc, err := api.NewClient(config)
+			require.NoError(t, err)
+
+			c := &defaultClient{
+				client:  vc,
+				isClone: tt.isClone,
+			}
+			m := &cachingClientFactory{
+				revokeOnEvict:          tt.revokeOnEvict,
+				revokeTokensOnEviction: tt.revokeTokensOnEviction,
+				logger:                 logr.Discard(),
+			}
+
+			m.onClientEvict(ctx, nil, "kubernetes-12345", c)
+			assert.True(t, c.closed)
+			if tt.wantRevoke {
+				assert.Equal(t, []string{"/v1/auth/token/revoke-self"}, handler.paths)
+			} else {
+				assert.Empty(t, handler.paths)
+			}
+		})
+	}
+}
+
+func Test_cachingClientFactory_recordVaultAuthStatus(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(id string, renewals int) *defaultClient {
+		return &defaultClient{
+			id:       id,
+			renewals: renewals,
+			authObj: &secretsv1beta1.VaultAuth{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "foo",
+					UID:       "c3b5a4ad-4a17-4e43-8b8a-a1bd4b4a35a2",
+				},
+				Spec: secretsv1beta1.VaultAuthSpec{
+					Method: vconsts.ProviderMethodKubernetes,
+				},
+			},
+			connObj: &secretsv1beta1.VaultConnection{
+				ObjectMeta: metav1.ObjectMeta{
+					UID: "b1e6b2a6-7c2c-4d3e-9a8e-6c5e8a3d2f10",
+				},
+			},
+			credentialProvider: credentials.NewFakeCredentialProvider().WithUID(
+				"f8b1d8a4-3e0c-4a5f-8f3b-2d6e1c9a7b42"),
+			authSecret: &api.Secret{
+				Auth: &api.SecretAuth{
+					Accessor:      "accessor-" + id,
+					Policies:      []string{"default", "foo"},
+					LeaseDuration: 3600,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		status   secretsv1beta1.VaultAuthStatus
+		c        *defaultClient
+		loggedIn bool
+		// wantCacheKey is true if the Client's cache key is expected in the
+		// recorded VaultClientMeta.
+		wantCacheKey bool
+		want         secretsv1beta1.VaultClientMeta
+	}{
+		{
+			name:         "logged-in",
+			c:            newClient("client-1", 0),
+			loggedIn:     true,
+			wantCacheKey: true,
+			want: secretsv1beta1.VaultClientMeta{
+				ID:            "client-1",
+				Accessor:      "accessor-client-1",
+				TokenTTL:      3600,
+				TokenPolicies: []string{"default", "foo"},
+			},
+		},
+		{
+			name: "refresh-recorded-client",
+			status: secretsv1beta1.VaultAuthStatus{
+				VaultClientMeta: secretsv1beta1.VaultClientMeta{
+					ID: "client-1",
+				},
+			},
+			c:            newClient("client-1", 2),
+			wantCacheKey: true,
+			want: secretsv1beta1.VaultClientMeta{
+				ID:            "client-1",
+				Accessor:      "accessor-client-1",
+				TokenTTL:      3600,
+				TokenRenewals: 2,
+				TokenPolicies: []string{"default", "foo"},
+			},
+		},
+		{
+			name: "other-recorded-client",
+			status: secretsv1beta1.VaultAuthStatus{
+				VaultClientMeta: secretsv1beta1.VaultClientMeta{
+					ID: "client-2",
+				},
+			},
+			c: newClient("client-1", 2),
+			want: secretsv1beta1.VaultClientMeta{
+				ID: "client-2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &secretsv1beta1.VaultAuth{
+				ObjectMeta: *tt.c.authObj.ObjectMeta.DeepCopy(),
+				Status:     tt.status,
+			}
+			client := testutils.NewFakeClientBuilder().
+				WithObjects(o).
+				WithStatusSubresource(o).
+				Build()
+
+			m := &cachingClientFactory{}
+			require.NoError(t, m.recordVaultAuthStatus(ctx, client, tt.c, tt.loggedIn))
+
+			got, err := common.GetVaultAuth(ctx, client, ctrlclient.ObjectKeyFromObject(o))
+			require.NoError(t, err)
+			want := tt.want
+			if tt.wantCacheKey {
+				cacheKey, err := tt.c.GetCacheKey()
+				require.NoError(t, err)
+				want.CacheKey = cacheKey.String()
+			}
+			assert.Equal(t, want, got.Status.VaultClientMeta)
+		})
+	}
+}
+
+func Test_cachingClientFactory_Prewarm(t *testing.T) {
+	ctx := context.Background()
+
+	cached := &defaultClient{
+		authObj: &secretsv1beta1.VaultAuth{
+			ObjectMeta: metav1.ObjectMeta{
+				UID: "c3b5a4ad-4a17-4e43-8b8a-a1bd4b4a35a2",
+			},
+			Spec: secretsv1beta1.VaultAuthSpec{
+				Method: vconsts.ProviderMethodKubernetes,
+			},
+		},
+		connObj: &secretsv1beta1.VaultConnection{
+			ObjectMeta: metav1.ObjectMeta{
+				UID: "b1e6b2a6-7c2c-4d3e-9a8e-6c5e8a3d2f10",
+			},
+		},
+		credentialProvider: credentials.NewFakeCredentialProvider().WithUID(
+			"f8b1d8a4-3e0c-4a5f-8f3b-2d6e1c9a7b42"),
+	}
+	cachedKey, err := cached.GetCacheKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		cacheKeys []ClientCacheKey
+		persist   bool
+		want      int
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "storage-disabled",
+			persist: false,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "pre-warm impossible, storage is not enabled", i...)
+			},
+		},
+		{
+			name:    "empty-storage",
+			persist: true,
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "skip-cached",
+			cacheKeys: []ClientCacheKey{cachedKey},
+			persist:   true,
+			wantErr:   assert.NoError,
+		},
+		{
+			name:      "skip-invalid",
+			cacheKeys: []ClientCacheKey{"kubernetes-0123456789abcdef012345"},
+			persist:   true,
+			wantErr:   assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutils.NewFakeClientBuilder().Build()
+			storage, err := newDefaultClientCacheStorage(ctx, client, DefaultClientCacheStorageConfig(), nil)
+			require.NoError(t, err)
+
+			for _, cacheKey := range tt.cacheKeys {
+				labels := ctrlclient.MatchingLabels{
+					labelCacheKey: cacheKey.String(),
+				}
+				for k, v := range commonMatchingLabels {
+					labels[k] = v
+				}
+				require.NoError(t, client.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      NamePrefixVCC + cacheKey.String(),
+						Namespace: common.OperatorNamespace,
+						Labels:    labels,
+					},
+				}))
+			}
+
+			clientCache, err := NewClientCache(5, nil, nil)
+			require.NoError(t, err)
+			_, err = clientCache.Add(cached)
+			require.NoError(t, err)
+
+			m := &cachingClientFactory{
+				cache:                  clientCache,
+				storage:                storage,
+				persist:                tt.persist,
+				clientMutex:            keymutex.NewHashed(1),
+				requestCounterVec:      prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests"}, []string{"operation"}),
+				requestErrorCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "errors"}, []string{"operation"}),
+			}
+
+			got, err := m.Prewarm(ctx, client)
+			if !tt.wantErr(t, err, "Prewarm()") {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, 1, clientCache.Len())
+		})
+	}
+}
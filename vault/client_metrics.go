@@ -4,13 +4,17 @@
 package vault
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
 )
 
 const (
-	subsystemClient = "client"
+	subsystemClient    = "client"
+	subsystemWebsocket = "websocket"
 )
 
 var (
@@ -43,6 +47,55 @@ var (
 		Help:        "Vault Client operation errors",
 		ConstLabels: nil,
 	}, []string{metrics.LabelOperation, metrics.LabelVaultConnection})
+
+	clientRequestsThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemClient,
+		Name:      "requests_throttled_total",
+		Help:      "Vault requests delayed by the VaultConnection's rate limit",
+	}, []string{metrics.LabelVaultConnection})
+
+	clientResponsesThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemClient,
+		Name:      "responses_throttled_total",
+		Help:      "Vault responses that throttled the request, by HTTP status code",
+	}, []string{metrics.LabelVaultConnection, "status"})
+
+	clientRequestsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemClient,
+		Name:      "requests_rejected_total",
+		Help:      "Vault requests rejected by the VaultConnection's open circuit breaker",
+	}, []string{metrics.LabelVaultConnection})
+
+	clientCircuitBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemClient,
+		Name:      "circuit_breaker_state",
+		Help:      "State of the VaultConnection's circuit breaker: 0=Closed, 1=HalfOpen, 2=Open",
+	}, []string{metrics.LabelVaultConnection})
+
+	websocketConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemWebsocket,
+		Name:      metrics.NameConnections,
+		Help:      "Number of open websocket connections to Vault",
+	}, []string{metrics.LabelVaultConnection})
+
+	websocketOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemWebsocket,
+		Name:      metrics.NameOperationsTotal,
+		Help:      "Websocket client operations",
+	}, []string{metrics.LabelOperation, metrics.LabelVaultConnection})
+
+	websocketOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemWebsocket,
+		Name:      metrics.NameOperationsErrorsTotal,
+		Help:      "Websocket client operation errors",
+	}, []string{metrics.LabelOperation, metrics.LabelVaultConnection})
 )
 
 // MustRegisterClientMetrics to register the global Client Prometheus metrics.
@@ -51,5 +104,44 @@ func MustRegisterClientMetrics(registry prometheus.Registerer) {
 		clientOperationTimes,
 		clientOperations,
 		clientOperationErrors,
+		clientRequestsThrottled,
+		clientResponsesThrottled,
+		clientRequestsRejected,
+		clientCircuitBreakerState,
+		websocketConnections,
+		websocketOperations,
+		websocketOperationErrors,
 	)
 }
+
+func incRequestsThrottled(connection ctrlclient.ObjectKey) {
+	clientRequestsThrottled.WithLabelValues(connection.String()).Inc()
+}
+
+func incResponsesThrottled(connection ctrlclient.ObjectKey, statusCode int) {
+	clientResponsesThrottled.WithLabelValues(connection.String(), strconv.Itoa(statusCode)).Inc()
+}
+
+func incRequestsRejected(connection ctrlclient.ObjectKey) {
+	clientRequestsRejected.WithLabelValues(connection.String()).Inc()
+}
+
+func setCircuitBreakerStateMetric(connection ctrlclient.ObjectKey, state CircuitBreakerState) {
+	var v float64
+	switch state {
+	case CircuitBreakerStateHalfOpen:
+		v = 1
+	case CircuitBreakerStateOpen:
+		v = 2
+	}
+	clientCircuitBreakerState.WithLabelValues(connection.String()).Set(v)
+}
+
+func deleteConnectionGuardMetrics(connection ctrlclient.ObjectKey) {
+	clientRequestsThrottled.DeleteLabelValues(connection.String())
+	clientRequestsRejected.DeleteLabelValues(connection.String())
+	clientResponsesThrottled.DeletePartialMatch(prometheus.Labels{
+		metrics.LabelVaultConnection: connection.String(),
+	})
+	clientCircuitBreakerState.DeleteLabelValues(connection.String())
+}
@@ -24,6 +24,7 @@ import (
 
 	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 	"github.com/hashicorp/vault-secrets-operator/consts"
+	"github.com/hashicorp/vault-secrets-operator/credentials"
 	"github.com/hashicorp/vault-secrets-operator/credentials/provider"
 	"github.com/hashicorp/vault-secrets-operator/credentials/vault"
 	vaultcredsconsts "github.com/hashicorp/vault-secrets-operator/credentials/vault/consts"
@@ -972,6 +973,315 @@ func Test_defaultClient_hashAccessor(t *testing.T) {
 	}
 }
 
+// staticCredentialProvider returns the same credentials from every GetCreds
+// call.
+type staticCredentialProvider struct {
+	provider.CredentialProviderBase
+	creds map[string]any
+}
+
+func (p *staticCredentialProvider) GetCreds(_ context.Context, _ ctrlclient.Client) (map[string]any, error) {
+	return p.creds, nil
+}
+
+func Test_defaultClient_Login_params(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method string
+		creds  map[string]any
+	}{
+		{
+			method: vaultcredsconsts.ProviderMethodKubernetes,
+			creds: map[string]any{
+				"role": "role1",
+				"jwt":  "token",
+			},
+		},
+		{
+			method: vaultcredsconsts.ProviderMethodJWT,
+			creds: map[string]any{
+				"role": "role1",
+				"jwt":  "token",
+			},
+		},
+		{
+			method: vaultcredsconsts.ProviderMethodAppRole,
+			creds: map[string]any{
+				"role_id":   "role-id",
+				"secret_id": "secret-id",
+			},
+		},
+		{
+			method: vaultcredsconsts.ProviderMethodAWS,
+			creds: map[string]any{
+				"role":                    "role1",
+				"iam_http_request_method": "POST",
+				"iam_request_url":         "aHR0cHM6Ly9zdHMuYW1hem9uYXdzLmNvbS8=",
+				"iam_request_body":        "QWN0aW9uPUdldENhbGxlcklkZW50aXR5",
+				"iam_request_headers":     "e30=",
+			},
+		},
+		{
+			method: vaultcredsconsts.ProviderMethodGCP,
+			creds: map[string]any{
+				"role": "role1",
+				"jwt":  "token",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &testHandler{
+				handlerFunc: func(_ *testHandler, w http.ResponseWriter, _ *http.Request) {
+					b, err := json.Marshal(&api.Secret{
+						Auth: &api.SecretAuth{
+							ClientToken: "token",
+							Accessor:    "3cb18a45-eb9e-0ed8-149b-ae4f83808925",
+						},
+					})
+					if err != nil {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.Write(b)
+				},
+			}
+			config, l := NewTestHTTPServer(t, handler.handler())
+			t.Cleanup(func() {
+				l.Close()
+			})
+
+			client, err := api.NewClient(config)
+			require.NoError(t, err)
+
+			c := &defaultClient{
+				client: client,
+				authObj: &secretsv1beta1.VaultAuth{
+					Spec: secretsv1beta1.VaultAuthSpec{
+						Method: tt.method,
+						Mount:  "mount",
+					},
+				},
+				// needed for Client Prometheus metrics
+				connObj: &secretsv1beta1.VaultConnection{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "bar",
+					},
+				},
+				credentialProvider: &staticCredentialProvider{creds: tt.creds},
+				skipRenewal:        true,
+			}
+
+			require.NoError(t, c.Login(context.Background(), nil))
+			assert.Equal(t, []string{"/v1/auth/mount/login"}, handler.paths)
+			assert.Equal(t, []map[string]any{tt.creds}, handler.params)
+		})
+	}
+}
+
+func Test_defaultClient_Login_fallbacks(t *testing.T) {
+	t.Parallel()
+
+	respond := func(w http.ResponseWriter, status int, v any) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write(b)
+	}
+	authSecret := &api.Secret{
+		Auth: &api.SecretAuth{
+			ClientToken: "token",
+			Accessor:    "3cb18a45-eb9e-0ed8-149b-ae4f83808925",
+		},
+	}
+
+	tests := []struct {
+		name             string
+		fallbacks        []string
+		statuses         map[string]int
+		expectPaths      []string
+		wantActiveMethod string
+		wantErr          assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "primary-no-fallbacks",
+			expectPaths: []string{"/v1/auth/k8s/login"},
+			wantErr:     assert.NoError,
+		},
+		{
+			name:             "primary",
+			fallbacks:        []string{"approle"},
+			expectPaths:      []string{"/v1/auth/k8s/login"},
+			wantActiveMethod: "kubernetes/k8s",
+			wantErr:          assert.NoError,
+		},
+		{
+			name:      "fallback",
+			fallbacks: []string{"jwt", "approle"},
+			statuses: map[string]int{
+				"/v1/auth/k8s/login": http.StatusForbidden,
+				"/v1/auth/jwt/login": http.StatusBadRequest,
+			},
+			expectPaths: []string{
+				"/v1/auth/k8s/login",
+				"/v1/auth/jwt/login",
+				"/v1/auth/approle/login",
+			},
+			wantActiveMethod: "appRole/approle",
+			wantErr:          assert.NoError,
+		},
+		{
+			name:      "fail-all",
+			fallbacks: []string{"approle"},
+			statuses: map[string]int{
+				"/v1/auth/k8s/login":     http.StatusForbidden,
+				"/v1/auth/approle/login": http.StatusForbidden,
+			},
+			expectPaths: []string{
+				"/v1/auth/k8s/login",
+				"/v1/auth/approle/login",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "login with auth method kubernetes/k8s failed", i...) &&
+					assert.ErrorContains(t, err, "login with auth method appRole/approle failed", i...)
+			},
+		},
+		{
+			name:      "fail-no-fallback-on-unavailable",
+			fallbacks: []string{"approle"},
+			statuses: map[string]int{
+				"/v1/auth/k8s/login": http.StatusServiceUnavailable,
+			},
+			expectPaths: []string{"/v1/auth/k8s/login"},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "login with auth method kubernetes/k8s failed", i...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &testHandler{
+				handlerFunc: func(_ *testHandler, w http.ResponseWriter, req *http.Request) {
+					if status, ok := tt.statuses[req.URL.Path]; ok {
+						respond(w, status, map[string]any{
+							"errors": []string{"permission denied"},
+						})
+						return
+					}
+					respond(w, http.StatusOK, authSecret)
+				},
+			}
+			config, l := NewTestHTTPServer(t, handler.handler())
+			t.Cleanup(func() {
+				l.Close()
+			})
+			// server errors are retried by default
+			config.MaxRetries = 0
+
+			client, err := api.NewClient(config)
+			require.NoError(t, err)
+
+			methods := map[string]string{
+				"jwt":     vaultcredsconsts.ProviderMethodJWT,
+				"approle": vaultcredsconsts.ProviderMethodAppRole,
+			}
+			var fallbacks []*authMethod
+			for _, mount := range tt.fallbacks {
+				fallbacks = append(fallbacks, &authMethod{
+					method:   methods[mount],
+					mount:    mount,
+					provider: credentials.NewFakeCredentialProvider(),
+				})
+			}
+
+			c := &defaultClient{
+				client: client,
+				authObj: &secretsv1beta1.VaultAuth{
+					Spec: secretsv1beta1.VaultAuthSpec{
+						Method: vaultcredsconsts.ProviderMethodKubernetes,
+						Mount:  "k8s",
+					},
+				},
+				// needed for Client Prometheus metrics
+				connObj: &secretsv1beta1.VaultConnection{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "baz",
+						Namespace: "bar",
+					},
+				},
+				credentialProvider: credentials.NewFakeCredentialProvider(),
+				fallbacks:          fallbacks,
+				skipRenewal:        true,
+			}
+
+			err = c.Login(context.Background(), nil)
+			assert.Equal(t, tt.expectPaths, handler.paths)
+			if !tt.wantErr(t, err, "Login()") || err != nil {
+				return
+			}
+			assert.Equal(t, tt.wantActiveMethod, c.ActiveAuthMethod())
+			assert.NotEmpty(t, c.ID())
+		})
+	}
+}
+
+func Test_fallbackVaultAuth(t *testing.T) {
+	t.Parallel()
+
+	authObj := &secretsv1beta1.VaultAuth{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: secretsv1beta1.VaultAuthSpec{
+			Method: vaultcredsconsts.ProviderMethodKubernetes,
+			Mount:  "k8s",
+			Headers: map[string]string{
+				"X-Foo": "bar",
+			},
+			Kubernetes: &secretsv1beta1.VaultAuthConfigKubernetes{
+				Role:           "role",
+				ServiceAccount: "default",
+			},
+			Fallbacks: []secretsv1beta1.VaultAuthFallback{
+				{
+					Method: vaultcredsconsts.ProviderMethodAppRole,
+					Mount:  "approle",
+					AppRole: &secretsv1beta1.VaultAuthConfigAppRole{
+						RoleID:    "role-id",
+						SecretRef: "secret-id",
+					},
+				},
+			},
+		},
+	}
+
+	got := fallbackVaultAuth(authObj, authObj.Spec.Fallbacks[0])
+	assert.Equal(t, &secretsv1beta1.VaultAuth{
+		ObjectMeta: authObj.ObjectMeta,
+		Spec: secretsv1beta1.VaultAuthSpec{
+			Method: vaultcredsconsts.ProviderMethodAppRole,
+			Mount:  "approle",
+			Headers: map[string]string{
+				"X-Foo": "bar",
+			},
+			AppRole: &secretsv1beta1.VaultAuthConfigAppRole{
+				RoleID:    "role-id",
+				SecretRef: "secret-id",
+			},
+		},
+	}, got)
+	assert.Len(t, authObj.Spec.Fallbacks, 1, "fallbackVaultAuth() must not modify authObj")
+	assert.NotNil(t, authObj.Spec.Kubernetes, "fallbackVaultAuth() must not modify authObj")
+}
+
 func Test_defaultClient_Taint(t *testing.T) {
 	t.Parallel()
 
@@ -1176,6 +1486,56 @@ func TestNewClientConfigFromConnObj(t *testing.T) {
 	connObjEmptyTimeout := connObjBase.DeepCopy()
 	connObjEmptyTimeout.Spec.Timeout = ""
 
+	connObjWebsocket := connObjBase.DeepCopy()
+	connObjWebsocket.Spec.Websocket = &secretsv1beta1.VaultConnectionWebsocket{
+		ProxyURL:        "http://proxy.example.com:3128",
+		CACertSecretRef: "ws-ca.crt",
+		DialTimeout:     "5s",
+		PingInterval:    "30s",
+	}
+
+	connObjWebsocketInvalidDialTimeout := connObjBase.DeepCopy()
+	connObjWebsocketInvalidDialTimeout.Spec.Websocket = &secretsv1beta1.VaultConnectionWebsocket{
+		DialTimeout: "5",
+	}
+
+	connObjDialer := connObjBase.DeepCopy()
+	connObjDialer.Spec.ProxyURL = "http://proxy.example.com:3128"
+	connObjDialer.Spec.DialTimeout = "5s"
+	connObjDialer.Spec.DNS = &secretsv1beta1.VaultConnectionDNS{
+		Nameservers: []string{"10.0.0.10:53", "[fd00::10]:53"},
+	}
+
+	connObjCABundle := connObjBase.DeepCopy()
+	connObjCABundle.Spec.CACertSecretRef = ""
+	connObjCABundle.Spec.CABundleRef = &secretsv1beta1.VaultConnectionCABundleRef{
+		Kind: CABundleRefKindConfigMap,
+		Name: "vault-ca",
+	}
+
+	connObjCABundleExclusive := connObjCABundle.DeepCopy()
+	connObjCABundleExclusive.Spec.CACertSecretRef = "ca.crt"
+
+	connObjInvalidNameserver := connObjBase.DeepCopy()
+	connObjInvalidNameserver.Spec.DNS = &secretsv1beta1.VaultConnectionDNS{
+		Nameservers: []string{"10.0.0.10"},
+	}
+
+	connObjGuarded := connObjBase.DeepCopy()
+	connObjGuarded.Name = "conn"
+	connObjGuarded.Namespace = "ns"
+	connObjGuarded.Spec.RateLimit = &secretsv1beta1.VaultConnectionRateLimit{
+		RequestsPerSecond: 10,
+	}
+	connObjGuarded.Spec.CircuitBreaker = &secretsv1beta1.VaultConnectionCircuitBreaker{
+		OpenDuration: "1m",
+	}
+
+	connObjInvalidOpenDuration := connObjBase.DeepCopy()
+	connObjInvalidOpenDuration.Spec.CircuitBreaker = &secretsv1beta1.VaultConnectionCircuitBreaker{
+		OpenDuration: "1",
+	}
+
 	tests := []struct {
 		name    string
 		connObj *secretsv1beta1.VaultConnection
@@ -1208,6 +1568,109 @@ func TestNewClientConfigFromConnObj(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name:    "websocket",
+			connObj: connObjWebsocket,
+			want: &ClientConfig{
+				Address:         "https://vault.example.com",
+				Headers:         map[string]string{"foo": "bar"},
+				TLSServerName:   "baz.biff",
+				CACertSecretRef: "ca.crt",
+				SkipTLSVerify:   true,
+				Timeout:         ptr.To[time.Duration](10 * time.Second),
+				Websocket: &WebsocketConfig{
+					CACertSecretRef: "ws-ca.crt",
+					ProxyURL: &url.URL{
+						Scheme: "http",
+						Host:   "proxy.example.com:3128",
+					},
+					DialTimeout:  ptr.To[time.Duration](5 * time.Second),
+					PingInterval: ptr.To[time.Duration](30 * time.Second),
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "proxy-and-dialer",
+			connObj: connObjDialer,
+			want: &ClientConfig{
+				Address:         "https://vault.example.com",
+				Headers:         map[string]string{"foo": "bar"},
+				TLSServerName:   "baz.biff",
+				CACertSecretRef: "ca.crt",
+				SkipTLSVerify:   true,
+				Timeout:         ptr.To[time.Duration](10 * time.Second),
+				ProxyURL: &url.URL{
+					Scheme: "http",
+					Host:   "proxy.example.com:3128",
+				},
+				DialTimeout:    ptr.To[time.Duration](5 * time.Second),
+				DNSNameservers: []string{"10.0.0.10:53", "[fd00::10]:53"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid-dns-nameserver",
+			connObj: connObjInvalidNameserver,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "ca-bundle-ref",
+			connObj: connObjCABundle,
+			want: &ClientConfig{
+				Address:       "https://vault.example.com",
+				Headers:       map[string]string{"foo": "bar"},
+				TLSServerName: "baz.biff",
+				CABundleRef: &CABundleRef{
+					Kind: CABundleRefKindConfigMap,
+					Name: "vault-ca",
+				},
+				SkipTLSVerify: true,
+				Timeout:       ptr.To[time.Duration](10 * time.Second),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "ca-bundle-ref-and-ca-cert-secret-ref",
+			connObj: connObjCABundleExclusive,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					"caCertSecretRef and caBundleRef are mutually exclusive", i...)
+			},
+		},
+		{
+			name:    "rate-limit-and-circuit-breaker",
+			connObj: connObjGuarded,
+			want: &ClientConfig{
+				Address:         "https://vault.example.com",
+				Headers:         map[string]string{"foo": "bar"},
+				TLSServerName:   "baz.biff",
+				CACertSecretRef: "ca.crt",
+				SkipTLSVerify:   true,
+				K8sNamespace:    "ns",
+				Timeout:         ptr.To[time.Duration](10 * time.Second),
+				Connection:      ctrlclient.ObjectKey{Namespace: "ns", Name: "conn"},
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 10,
+					Burst:             10,
+				},
+				CircuitBreaker: &CircuitBreakerConfig{
+					FailureThreshold: defaultCircuitBreakerFailureThreshold,
+					OpenDuration:     time.Minute,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "circuit-breaker-invalid-open-duration",
+			connObj: connObjInvalidOpenDuration,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "websocket-invalid-dial-timeout",
+			connObj: connObjWebsocketInvalidDialTimeout,
+			wantErr: assert.Error,
+		},
 		{
 			name:    "nil-connObj",
 			wantErr: assert.Error,
@@ -5,12 +5,18 @@ package vault
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/hashicorp/vault/api"
 	vconsts "github.com/hashicorp/vault/sdk/helper/consts"
+	certificatesv1alpha1 "k8s.io/api/certificates/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -25,7 +31,15 @@ type ClientConfig struct {
 	// "ca.crt" that holds a CA cert that can be used to validate the
 	// certificate presented by the Vault server
 	CACertSecretRef string
-	// K8sNamespace the namespace of the CACertSecretRef secret
+	// CABundleRef references the CA cert chain in a ConfigMap, or a
+	// ClusterTrustBundle, as an alternative to CACertSecretRef.
+	CABundleRef *CABundleRef
+	// ClientCertSecretRef is the name of a k8s TLS secret that contains the
+	// client certificate "tls.crt", and its private key "tls.key", presented to
+	// the Vault server for mutual TLS.
+	ClientCertSecretRef string
+	// K8sNamespace the namespace of the CACertSecretRef and ClientCertSecretRef
+	// secrets
 	K8sNamespace string
 	// Address is the URL of the Vault server
 	Address string
@@ -42,6 +56,69 @@ type ClientConfig struct {
 	// Timeout applied to all Vault requests. If not set, the default timeout from
 	// the Vault API client config is used.
 	Timeout *time.Duration
+	// ProxyURL is the URL of the proxy to use for all Vault requests. If not
+	// set, the proxy is taken from the environment.
+	ProxyURL *url.URL
+	// DialTimeout applied when establishing a connection to Vault. If not set,
+	// the default dial timeout of the Vault API client is used.
+	DialTimeout *time.Duration
+	// DNSNameservers are the DNS servers used for resolving host names, in the
+	// form host:port. If not set, the system's resolver is used.
+	DNSNameservers []string
+	// Websocket contains the configuration for the websocket client used for
+	// streaming events from Vault. If not set, the websocket client uses the same
+	// settings as the HTTP client.
+	Websocket *WebsocketConfig
+	// Connection is the VaultConnection of the ClientConfig. The RateLimit and
+	// the CircuitBreaker are shared by all clients of the same Connection.
+	Connection ctrlclient.ObjectKey
+	// RateLimit applied to all Vault requests of the Connection. If not set,
+	// requests are not rate limited.
+	RateLimit *RateLimitConfig
+	// CircuitBreaker applied to all Vault requests of the Connection. If not
+	// set, requests are always sent to Vault.
+	CircuitBreaker *CircuitBreakerConfig
+}
+
+const (
+	// CABundleRefKindConfigMap is the kind of a CABundleRef to a ConfigMap.
+	CABundleRefKindConfigMap = "ConfigMap"
+	// CABundleRefKindClusterTrustBundle is the kind of a CABundleRef to a
+	// ClusterTrustBundle.
+	CABundleRefKindClusterTrustBundle = "ClusterTrustBundle"
+)
+
+// CABundleRef references a PEM encoded CA cert chain in a ConfigMap, or a
+// ClusterTrustBundle.
+type CABundleRef struct {
+	// Kind of the referenced object, one of CABundleRefKindConfigMap or
+	// CABundleRefKindClusterTrustBundle.
+	Kind string
+	// Name of the ConfigMap, in the ClientConfig's K8sNamespace, or of the
+	// cluster scoped ClusterTrustBundle.
+	Name string
+	// Key of the ConfigMap that holds the CA cert chain, it defaults to
+	// "ca.crt".
+	Key string
+}
+
+// WebsocketConfig contains the configuration for the websocket client used for
+// streaming events from Vault.
+type WebsocketConfig struct {
+	// CACertSecretRef is the name of a k8s secret that contains a data key
+	// "ca.crt" that holds a CA cert that can be used to validate the
+	// certificate presented by the websocket server. If not set, the
+	// ClientConfig's CACertSecretRef is used.
+	CACertSecretRef string
+	// ProxyURL is the URL of the proxy to use for websocket connections. If not
+	// set, the ClientConfig's ProxyURL is used.
+	ProxyURL *url.URL
+	// DialTimeout applied when establishing a websocket connection. If not set,
+	// the ClientConfig's Timeout is used.
+	DialTimeout *time.Duration
+	// PingInterval is the interval at which pings are sent on an open websocket
+	// connection. If not set, no pings are sent.
+	PingInterval *time.Duration
 }
 
 // MakeVaultClient creates a Vault api.Client from a ClientConfig.
@@ -55,30 +132,9 @@ func MakeVaultClient(ctx context.Context, cfg *ClientConfig, client ctrlclient.C
 		return nil, fmt.Errorf("ctrl-runtime Client was nil")
 	}
 
-	var b []byte
-	if cfg.CACertSecretRef != "" {
-		objKey := ctrlclient.ObjectKey{
-			Namespace: cfg.K8sNamespace,
-			Name:      cfg.CACertSecretRef,
-		}
-		s := &v1.Secret{}
-		if err := client.Get(ctx, objKey, s); err != nil {
-			return nil, err
-		}
-
-		var ok bool
-		key := consts.TLSSecretCAKey
-		if b, ok = s.Data[key]; !ok {
-			return nil, fmt.Errorf(`%q not present in the CA secret %q`, key, objKey)
-		}
-
-		if !cfg.SkipTLSVerify {
-			// only validate CA cert chain when SkipTLSVerify is false.
-			certPool := x509.NewCertPool()
-			if ok := certPool.AppendCertsFromPEM(b); !ok {
-				return nil, fmt.Errorf("no valid certificates found for key %q in CA secret %q", key, objKey)
-			}
-		}
+	b, err := getConfigCACertBytes(ctx, client, cfg, cfg.CACertSecretRef)
+	if err != nil {
+		return nil, err
 	}
 
 	config := api.DefaultConfig()
@@ -92,10 +148,18 @@ func MakeVaultClient(ctx context.Context, cfg *ClientConfig, client ctrlclient.C
 		return nil, err
 	}
 
+	if err := configureClientCertificate(ctx, client, config.HttpClient, cfg); err != nil {
+		return nil, err
+	}
+
 	if cfg.Timeout != nil {
 		config.Timeout = *cfg.Timeout
 	}
 
+	if err := configureTransport(config.HttpClient, cfg, cfg.ProxyURL); err != nil {
+		return nil, err
+	}
+
 	config.CloneToken = true
 	config.CloneHeaders = true
 
@@ -104,6 +168,11 @@ func MakeVaultClient(ctx context.Context, cfg *ClientConfig, client ctrlclient.C
 		l.Error(err, "error setting up Vault API client")
 		return nil, err
 	}
+	// the transport is guarded once the client is set up, since the Vault API
+	// client requires an *http.Transport during its setup.
+	if g := connectionGuards.forConfig(cfg); g != nil {
+		config.HttpClient.Transport = g.roundTripper(config.HttpClient.Transport)
+	}
 	if _, exists := cfg.Headers[vconsts.NamespaceHeaderName]; exists {
 		return nil, fmt.Errorf("setting header %q on VaultConnection is not permitted", vconsts.NamespaceHeaderName)
 	}
@@ -116,3 +185,254 @@ func MakeVaultClient(ctx context.Context, cfg *ClientConfig, client ctrlclient.C
 
 	return c, nil
 }
+
+// MakeWebsocketHTTPClient creates the http.Client used for websocket
+// connections from a ClientConfig. It returns nil if the ClientConfig does not
+// specify any websocket specific configuration.
+func MakeWebsocketHTTPClient(ctx context.Context, cfg *ClientConfig, client ctrlclient.Client) (*http.Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("ClientConfig was nil")
+	}
+
+	if cfg.Websocket == nil {
+		return nil, nil
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("ctrl-runtime Client was nil")
+	}
+
+	caCertSecretRef := cfg.Websocket.CACertSecretRef
+	if caCertSecretRef == "" {
+		caCertSecretRef = cfg.CACertSecretRef
+	}
+
+	b, err := getConfigCACertBytes(ctx, client, cfg, caCertSecretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	config := api.DefaultConfig()
+	if err := config.ConfigureTLS(&api.TLSConfig{
+		Insecure:      cfg.SkipTLSVerify,
+		TLSServerName: cfg.TLSServerName,
+		CACertBytes:   b,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := configureClientCertificate(ctx, client, config.HttpClient, cfg); err != nil {
+		return nil, err
+	}
+
+	proxyURL := cfg.Websocket.ProxyURL
+	if proxyURL == nil {
+		proxyURL = cfg.ProxyURL
+	}
+	if err := configureTransport(config.HttpClient, cfg, proxyURL); err != nil {
+		return nil, err
+	}
+
+	// the Vault API client applies its timeout per request, so it must be set on
+	// the http.Client to be honored by the websocket dialer.
+	config.HttpClient.Timeout = config.Timeout
+	if cfg.Timeout != nil {
+		config.HttpClient.Timeout = *cfg.Timeout
+	}
+
+	return config.HttpClient, nil
+}
+
+// configureTransport applies the proxyURL, and the ClientConfig's dialer
+// settings, to the transport of httpClient. The transport is left untouched if
+// none are set.
+func configureTransport(httpClient *http.Client, cfg *ClientConfig, proxyURL *url.URL) error {
+	if proxyURL == nil && cfg.DialTimeout == nil && len(cfg.DNSNameservers) == 0 {
+		return nil
+	}
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport type %T", httpClient.Transport)
+	}
+
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.DialTimeout != nil || len(cfg.DNSNameservers) > 0 {
+		transport.DialContext = newDialer(cfg.DialTimeout, cfg.DNSNameservers).DialContext
+	}
+
+	return nil
+}
+
+// configureClientCertificate sets the client certificate from the
+// ClientConfig's ClientCertSecretRef on the TLS config of httpClient's
+// transport. The transport is left untouched if no ClientCertSecretRef is set.
+func configureClientCertificate(ctx context.Context, client ctrlclient.Client, httpClient *http.Client, cfg *ClientConfig) error {
+	if cfg.ClientCertSecretRef == "" {
+		return nil
+	}
+
+	cert, err := getClientCertificate(ctx, client, cfg.K8sNamespace, cfg.ClientCertSecretRef)
+	if err != nil {
+		return err
+	}
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport type %T", httpClient.Transport)
+	}
+
+	transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+
+	return nil
+}
+
+// newDialer returns a net.Dialer with timeout, that resolves host names with
+// the DNS nameservers. The defaults of the Vault API client's dialer apply to
+// all unset options.
+func newDialer(timeout *time.Duration, nameservers []string) *net.Dialer {
+	d := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if timeout != nil {
+		d.Timeout = *timeout
+	}
+
+	if len(nameservers) > 0 {
+		d.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var errs error
+				var dialer net.Dialer
+				for _, ns := range nameservers {
+					conn, err := dialer.DialContext(ctx, network, ns)
+					if err == nil {
+						return conn, nil
+					}
+					errs = errors.Join(errs, err)
+				}
+				return nil, errs
+			},
+		}
+	}
+
+	return d
+}
+
+// getConfigCACertBytes returns the PEM encoded CA certificate chain from the
+// k8s secret named secretRef, or from the ClientConfig's CABundleRef if
+// secretRef is empty.
+func getConfigCACertBytes(ctx context.Context, client ctrlclient.Client, cfg *ClientConfig, secretRef string) ([]byte, error) {
+	if secretRef == "" && cfg.CABundleRef != nil {
+		return GetCABundleBytes(ctx, client, cfg.K8sNamespace, cfg.CABundleRef, cfg.SkipTLSVerify)
+	}
+
+	return getCACertBytes(ctx, client, cfg.K8sNamespace, secretRef, cfg.SkipTLSVerify)
+}
+
+// GetCABundleBytes returns the PEM encoded CA certificate chain from the
+// ConfigMap, or the ClusterTrustBundle, referenced by ref.
+func GetCABundleBytes(ctx context.Context, client ctrlclient.Client, namespace string, ref *CABundleRef, skipTLSVerify bool) ([]byte, error) {
+	var b []byte
+	switch ref.Kind {
+	case CABundleRefKindConfigMap:
+		objKey := ctrlclient.ObjectKey{
+			Namespace: namespace,
+			Name:      ref.Name,
+		}
+		cm := &v1.ConfigMap{}
+		if err := client.Get(ctx, objKey, cm); err != nil {
+			return nil, err
+		}
+
+		key := ref.Key
+		if key == "" {
+			key = consts.TLSSecretCAKey
+		}
+		v, ok := cm.Data[key]
+		if !ok {
+			return nil, fmt.Errorf(`%q not present in the CA ConfigMap %q`, key, objKey)
+		}
+		b = []byte(v)
+	case CABundleRefKindClusterTrustBundle:
+		ctb := &certificatesv1alpha1.ClusterTrustBundle{}
+		if err := client.Get(ctx, ctrlclient.ObjectKey{Name: ref.Name}, ctb); err != nil {
+			return nil, err
+		}
+		b = []byte(ctb.Spec.TrustBundle)
+	default:
+		return nil, fmt.Errorf("unsupported CA bundle kind %q", ref.Kind)
+	}
+
+	if !skipTLSVerify {
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM(b); !ok {
+			return nil, fmt.Errorf("no valid certificates found in CA %s %q", ref.Kind, ref.Name)
+		}
+	}
+
+	return b, nil
+}
+
+// getCACertBytes returns the PEM encoded CA certificate chain from the k8s
+// secret named secretRef. It returns nil if secretRef is empty.
+func getCACertBytes(ctx context.Context, client ctrlclient.Client, namespace, secretRef string, skipTLSVerify bool) ([]byte, error) {
+	if secretRef == "" {
+		return nil, nil
+	}
+
+	objKey := ctrlclient.ObjectKey{
+		Namespace: namespace,
+		Name:      secretRef,
+	}
+	s := &v1.Secret{}
+	if err := client.Get(ctx, objKey, s); err != nil {
+		return nil, err
+	}
+
+	key := consts.TLSSecretCAKey
+	b, ok := s.Data[key]
+	if !ok {
+		return nil, fmt.Errorf(`%q not present in the CA secret %q`, key, objKey)
+	}
+
+	if !skipTLSVerify {
+		// only validate CA cert chain when SkipTLSVerify is false.
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM(b); !ok {
+			return nil, fmt.Errorf("no valid certificates found for key %q in CA secret %q", key, objKey)
+		}
+	}
+
+	return b, nil
+}
+
+// getClientCertificate returns the client certificate, and its private key,
+// from the k8s TLS secret named secretRef.
+func getClientCertificate(ctx context.Context, client ctrlclient.Client, namespace, secretRef string) (*tls.Certificate, error) {
+	objKey := ctrlclient.ObjectKey{
+		Namespace: namespace,
+		Name:      secretRef,
+	}
+	s := &v1.Secret{}
+	if err := client.Get(ctx, objKey, s); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{v1.TLSCertKey, v1.TLSPrivateKeyKey} {
+		if _, ok := s.Data[key]; !ok {
+			return nil, fmt.Errorf(`%q not present in the client certificate secret %q`, key, objKey)
+		}
+	}
+
+	cert, err := tls.X509KeyPair(s.Data[v1.TLSCertKey], s.Data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate in secret %q: %w", objKey, err)
+	}
+
+	return &cert, nil
+}
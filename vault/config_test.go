@@ -5,8 +5,11 @@ package vault
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	vconsts "github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	certificatesv1alpha1 "k8s.io/api/certificates/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
@@ -98,6 +102,18 @@ func TestMakeVaultClient(t *testing.T) {
 			CACert:        nil,
 			expectedError: nil,
 		},
+		"proxy and dialer": {
+			vaultConfig: &ClientConfig{
+				ProxyURL: &url.URL{
+					Scheme: "http",
+					Host:   "proxy.example.com:3128",
+				},
+				DialTimeout:    ptr.To[time.Duration](5 * time.Second),
+				DNSNameservers: []string{"10.0.0.10:53"},
+			},
+			CACert:        nil,
+			expectedError: nil,
+		},
 		"headers can't override namespace": {
 			vaultConfig: &ClientConfig{
 				Headers: map[string]string{
@@ -170,11 +186,357 @@ func TestMakeVaultClient(t *testing.T) {
 				}
 				assert.Equalf(t, expectedTimeout, vaultConfig.Timeout,
 					"expected timeout %v, got %v", expectedTimeout, vaultConfig.Timeout)
+
+				if tc.vaultConfig.ProxyURL != nil {
+					transport := vaultConfig.HttpClient.Transport.(*http.Transport)
+					got, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "vault:8200"}})
+					require.NoError(t, err)
+					assert.Equal(t, tc.vaultConfig.ProxyURL, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeWebsocketHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	testCABytes, err := generateCA()
+	require.NoError(t, err)
+
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		vaultConfig   *ClientConfig
+		wantNil       bool
+		wantCA        bool
+		wantProxy     *url.URL
+		wantTimeout   time.Duration
+		expectedError error
+	}{
+		"nil config": {
+			expectedError: fmt.Errorf("ClientConfig was nil"),
+		},
+		"no websocket config": {
+			vaultConfig: &ClientConfig{
+				CACertSecretRef: "vault-cert",
+				K8sNamespace:    "vault",
+			},
+			wantNil: true,
+		},
+		"websocket caCert": {
+			vaultConfig: &ClientConfig{
+				CACertSecretRef: "missing",
+				K8sNamespace:    "vault",
+				Websocket: &WebsocketConfig{
+					CACertSecretRef: "vault-cert",
+				},
+			},
+			wantCA:      true,
+			wantTimeout: api.DefaultConfig().Timeout,
+		},
+		"inherited caCert": {
+			vaultConfig: &ClientConfig{
+				CACertSecretRef: "vault-cert",
+				K8sNamespace:    "vault",
+				Websocket:       &WebsocketConfig{},
+			},
+			wantCA:      true,
+			wantTimeout: api.DefaultConfig().Timeout,
+		},
+		"websocket caCert k8s secret doesn't exist": {
+			vaultConfig: &ClientConfig{
+				K8sNamespace: "vault",
+				Websocket: &WebsocketConfig{
+					CACertSecretRef: "missing",
+				},
+			},
+			expectedError: fmt.Errorf(`secrets "missing" not found`),
+		},
+		"inherited proxy": {
+			vaultConfig: &ClientConfig{
+				ProxyURL:  proxyURL,
+				Websocket: &WebsocketConfig{},
+			},
+			wantProxy:   proxyURL,
+			wantTimeout: api.DefaultConfig().Timeout,
+		},
+		"proxy and timeout": {
+			vaultConfig: &ClientConfig{
+				Timeout: ptr.To[time.Duration](10 * time.Second),
+				Websocket: &WebsocketConfig{
+					ProxyURL: proxyURL,
+				},
+			},
+			wantProxy:   proxyURL,
+			wantTimeout: 10 * time.Second,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "vault-cert",
+					Namespace: "vault",
+				},
+				Data: map[string][]byte{consts.TLSSecretCAKey: testCABytes},
+			}).Build()
+
+			httpClient, err := MakeWebsocketHTTPClient(ctx, tc.vaultConfig, fakeClient)
+			if tc.expectedError != nil {
+				assert.EqualError(t, err, tc.expectedError.Error())
+				assert.Nil(t, httpClient)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.wantNil {
+				assert.Nil(t, httpClient)
+				return
+			}
+
+			require.NotNil(t, httpClient)
+			assert.Equal(t, tc.wantTimeout, httpClient.Timeout)
+
+			transport := httpClient.Transport.(*http.Transport)
+			if tc.wantCA {
+				require.NotNil(t, transport.TLSClientConfig.RootCAs)
+				expectedCertPool, err := rootcerts.AppendCertificate(testCABytes)
+				require.NoError(t, err)
+				assert.True(t, transport.TLSClientConfig.RootCAs.Equal(expectedCertPool))
+			}
+
+			if tc.wantProxy != nil {
+				got, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "vault:8200"}})
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantProxy, got)
 			}
 		})
 	}
 }
 
+func TestMakeVaultClient_clientCertificate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cert, key, err := generateClientCert()
+	require.NoError(t, err)
+	otherCert, _, err := generateClientCert()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "valid",
+			data: map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: key,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "missing-key",
+			data: map[string][]byte{
+				corev1.TLSCertKey: cert,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`"tls.key" not present in the client certificate secret "vso/client-cert"`, i...)
+			},
+		},
+		{
+			name: "mismatched-key",
+			data: map[string][]byte{
+				corev1.TLSCertKey:       otherCert,
+				corev1.TLSPrivateKeyKey: key,
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "missing-secret",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientBuilder := fake.NewClientBuilder()
+			if tt.data != nil {
+				clientBuilder = clientBuilder.WithObjects(&corev1.Secret{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "client-cert",
+						Namespace: "vso",
+					},
+					Data: tt.data,
+				})
+			}
+			cfg := &ClientConfig{
+				ClientCertSecretRef: "client-cert",
+				K8sNamespace:        "vso",
+				Websocket:           &WebsocketConfig{},
+			}
+
+			vaultClient, err := MakeVaultClient(ctx, cfg, clientBuilder.Build())
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			wantCert, err := tls.X509KeyPair(cert, key)
+			require.NoError(t, err)
+			transport := vaultClient.CloneConfig().HttpClient.Transport.(*http.Transport)
+			assert.Equal(t, []tls.Certificate{wantCert}, transport.TLSClientConfig.Certificates)
+
+			// the websocket client presents the same client certificate.
+			httpClient, err := MakeWebsocketHTTPClient(ctx, cfg, clientBuilder.Build())
+			require.NoError(t, err)
+			transport = httpClient.Transport.(*http.Transport)
+			assert.Equal(t, []tls.Certificate{wantCert}, transport.TLSClientConfig.Certificates)
+		})
+	}
+}
+
+func TestGetCABundleBytes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	caBytes, err := generateCA()
+	require.NoError(t, err)
+
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: "vault-ca", Namespace: "vso"},
+			Data: map[string]string{
+				consts.TLSSecretCAKey: string(caBytes),
+				"invalid.pem":         "invalid",
+			},
+		},
+		&certificatesv1alpha1.ClusterTrustBundle{
+			ObjectMeta: v1.ObjectMeta{Name: "vault-ca"},
+			Spec: certificatesv1alpha1.ClusterTrustBundleSpec{
+				TrustBundle: string(caBytes),
+			},
+		},
+	).Build()
+
+	tests := []struct {
+		name          string
+		ref           *CABundleRef
+		skipTLSVerify bool
+		want          []byte
+		wantErr       assert.ErrorAssertionFunc
+	}{
+		{
+			name: "configmap",
+			ref: &CABundleRef{
+				Kind: CABundleRefKindConfigMap,
+				Name: "vault-ca",
+			},
+			want:    caBytes,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "configmap-missing-key",
+			ref: &CABundleRef{
+				Kind: CABundleRefKindConfigMap,
+				Name: "vault-ca",
+				Key:  "other.pem",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`"other.pem" not present in the CA ConfigMap "vso/vault-ca"`, i...)
+			},
+		},
+		{
+			name: "configmap-invalid",
+			ref: &CABundleRef{
+				Kind: CABundleRefKindConfigMap,
+				Name: "vault-ca",
+				Key:  "invalid.pem",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					`no valid certificates found in CA ConfigMap "vault-ca"`, i...)
+			},
+		},
+		{
+			name: "configmap-invalid-skip-tls-verify",
+			ref: &CABundleRef{
+				Kind: CABundleRefKindConfigMap,
+				Name: "vault-ca",
+				Key:  "invalid.pem",
+			},
+			skipTLSVerify: true,
+			want:          []byte("invalid"),
+			wantErr:       assert.NoError,
+		},
+		{
+			name: "cluster-trust-bundle",
+			ref: &CABundleRef{
+				Kind: CABundleRefKindClusterTrustBundle,
+				Name: "vault-ca",
+			},
+			want:    caBytes,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "cluster-trust-bundle-not-found",
+			ref: &CABundleRef{
+				Kind: CABundleRefKindClusterTrustBundle,
+				Name: "other",
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "unsupported-kind",
+			ref: &CABundleRef{
+				Kind: "Secret",
+				Name: "vault-ca",
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetCABundleBytes(ctx, c, "vso", tt.ref, tt.skipTLSVerify)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newDialer(t *testing.T) {
+	t.Parallel()
+
+	d := newDialer(nil, nil)
+	assert.Equal(t, 30*time.Second, d.Timeout)
+	assert.Nil(t, d.Resolver)
+
+	// the first reachable nameserver is dialed by the resolver.
+	ns, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ns.Close()
+	})
+
+	d = newDialer(ptr.To[time.Duration](5*time.Second), []string{"invalid", ns.LocalAddr().String()})
+	assert.Equal(t, 5*time.Second, d.Timeout)
+	require.NotNil(t, d.Resolver)
+	conn, err := d.Resolver.Dial(context.Background(), "udp", "8.8.8.8:53")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	assert.Equal(t, ns.LocalAddr().String(), conn.RemoteAddr().String())
+}
+
 func makeVaultHttpHeaders(t *testing.T, namespace string, headers map[string]string) http.Header {
 	t.Helper()
 
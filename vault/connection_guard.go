@@ -0,0 +1,388 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerOpenDuration     = 30 * time.Second
+)
+
+// CircuitBreakerState is the state of a VaultConnection's circuit breaker.
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerStateClosed denotes that all requests are sent to Vault.
+	CircuitBreakerStateClosed CircuitBreakerState = "Closed"
+	// CircuitBreakerStateOpen denotes that all requests are rejected without
+	// being sent to Vault.
+	CircuitBreakerStateOpen CircuitBreakerState = "Open"
+	// CircuitBreakerStateHalfOpen denotes that a single probe request is sent
+	// to Vault, its outcome decides whether the circuit breaker is closed or
+	// opened again.
+	CircuitBreakerStateHalfOpen CircuitBreakerState = "HalfOpen"
+)
+
+// ErrCircuitBreakerOpen is returned for all requests that are rejected by an
+// open circuit breaker.
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+// connectionGuards holds the connectionGuard of every VaultConnection, it is
+// shared by all Clients.
+var connectionGuards = newConnectionGuardRegistry()
+
+// RateLimitConfig configures the client-side rate limit of all requests to
+// Vault for a VaultConnection.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of requests.
+	RequestsPerSecond float64
+	// Burst is the maximum number of requests that may be sent at once.
+	Burst int
+}
+
+// CircuitBreakerConfig configures the circuit breaker of all requests to Vault
+// for a VaultConnection.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed requests that open
+	// the circuit breaker.
+	FailureThreshold int
+	// OpenDuration is the duration the circuit breaker stays open before a
+	// probe request is sent.
+	OpenDuration time.Duration
+}
+
+// CircuitBreakerStatus is the observed state of a VaultConnection's circuit
+// breaker.
+type CircuitBreakerStatus struct {
+	State              CircuitBreakerState
+	LastTransitionTime time.Time
+}
+
+// OnCircuitBreakerStateChange registers f to be called whenever the state of
+// a VaultConnection's circuit breaker changes. f must not block.
+func OnCircuitBreakerStateChange(f func(connection ctrlclient.ObjectKey, state CircuitBreakerState)) {
+	connectionGuards.setOnStateChange(f)
+}
+
+// GetCircuitBreakerStatus returns the status of the VaultConnection's circuit
+// breaker, and false if the VaultConnection has no circuit breaker.
+func GetCircuitBreakerStatus(connection ctrlclient.ObjectKey) (CircuitBreakerStatus, bool) {
+	g, ok := connectionGuards.get(connection)
+	if !ok {
+		return CircuitBreakerStatus{}, false
+	}
+	return g.circuitBreakerStatus()
+}
+
+// DeleteConnectionGuard removes the rate limiter and the circuit breaker of
+// the VaultConnection, it should be called when the VaultConnection is deleted.
+func DeleteConnectionGuard(connection ctrlclient.ObjectKey) {
+	connectionGuards.delete(connection)
+}
+
+type connectionGuardRegistry struct {
+	mu            sync.RWMutex
+	guards        map[ctrlclient.ObjectKey]*connectionGuard
+	onStateChange func(ctrlclient.ObjectKey, CircuitBreakerState)
+}
+
+func newConnectionGuardRegistry() *connectionGuardRegistry {
+	return &connectionGuardRegistry{
+		guards: make(map[ctrlclient.ObjectKey]*connectionGuard),
+	}
+}
+
+func (r *connectionGuardRegistry) setOnStateChange(f func(ctrlclient.ObjectKey, CircuitBreakerState)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onStateChange = f
+}
+
+func (r *connectionGuardRegistry) stateChanged(connection ctrlclient.ObjectKey, state CircuitBreakerState) {
+	r.mu.RLock()
+	f := r.onStateChange
+	r.mu.RUnlock()
+
+	setCircuitBreakerStateMetric(connection, state)
+	if f != nil {
+		f(connection, state)
+	}
+}
+
+func (r *connectionGuardRegistry) get(connection ctrlclient.ObjectKey) (*connectionGuard, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.guards[connection]
+	return g, ok
+}
+
+// forConfig returns the connectionGuard of cfg's VaultConnection, updated to
+// cfg's rate limit and circuit breaker. It returns nil if neither is
+// configured.
+func (r *connectionGuardRegistry) forConfig(cfg *ClientConfig) *connectionGuard {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cfg.RateLimit == nil && cfg.CircuitBreaker == nil {
+		if _, ok := r.guards[cfg.Connection]; ok {
+			delete(r.guards, cfg.Connection)
+			deleteConnectionGuardMetrics(cfg.Connection)
+		}
+		return nil
+	}
+
+	g, ok := r.guards[cfg.Connection]
+	if !ok {
+		g = &connectionGuard{
+			connection:         cfg.Connection,
+			registry:           r,
+			state:              CircuitBreakerStateClosed,
+			lastTransitionTime: time.Now(),
+			now:                time.Now,
+		}
+		r.guards[cfg.Connection] = g
+	}
+	g.configure(cfg.RateLimit, cfg.CircuitBreaker)
+	if status, ok := g.circuitBreakerStatus(); ok {
+		setCircuitBreakerStateMetric(cfg.Connection, status.State)
+	}
+
+	return g
+}
+
+func (r *connectionGuardRegistry) delete(connection ctrlclient.ObjectKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.guards, connection)
+	deleteConnectionGuardMetrics(connection)
+}
+
+// connectionGuard rate limits the requests to Vault for a VaultConnection, and
+// rejects them while its circuit breaker is open. It is shared by all Clients
+// of the VaultConnection, so that the limits apply to the operator as a whole,
+// regardless of the number of Clients.
+type connectionGuard struct {
+	connection ctrlclient.ObjectKey
+	registry   *connectionGuardRegistry
+	now        func() time.Time
+
+	mu                 sync.Mutex
+	limiter            *rate.Limiter
+	breaker            *CircuitBreakerConfig
+	state              CircuitBreakerState
+	lastTransitionTime time.Time
+	failures           int
+	openedAt           time.Time
+	probing            bool
+}
+
+func (g *connectionGuard) configure(rateLimit *RateLimitConfig, breaker *CircuitBreakerConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case rateLimit == nil:
+		g.limiter = nil
+	case g.limiter == nil:
+		g.limiter = rate.NewLimiter(rate.Limit(rateLimit.RequestsPerSecond), rateLimit.Burst)
+	default:
+		g.limiter.SetLimit(rate.Limit(rateLimit.RequestsPerSecond))
+		g.limiter.SetBurst(rateLimit.Burst)
+	}
+
+	g.breaker = breaker
+	if breaker == nil {
+		g.state = CircuitBreakerStateClosed
+		g.failures = 0
+		g.probing = false
+	}
+}
+
+// wait blocks until the request is permitted by the rate limiter.
+func (g *connectionGuard) wait(ctx context.Context) error {
+	g.mu.Lock()
+	limiter := g.limiter
+	g.mu.Unlock()
+
+	if limiter == nil || limiter.Allow() {
+		return nil
+	}
+
+	incRequestsThrottled(g.connection)
+	return limiter.Wait(ctx)
+}
+
+// acquire returns ErrCircuitBreakerOpen if the request must be rejected. It
+// returns true if the request is the probe of a half-open circuit breaker.
+func (g *connectionGuard) acquire() (bool, error) {
+	g.mu.Lock()
+	if g.breaker == nil {
+		g.mu.Unlock()
+		return false, nil
+	}
+
+	var probe, changed bool
+	var err error
+	switch g.state {
+	case CircuitBreakerStateOpen:
+		if g.now().Sub(g.openedAt) < g.breaker.OpenDuration {
+			err = ErrCircuitBreakerOpen
+			break
+		}
+		changed = g.transition(CircuitBreakerStateHalfOpen)
+		fallthrough
+	case CircuitBreakerStateHalfOpen:
+		if g.probing {
+			err = ErrCircuitBreakerOpen
+			break
+		}
+		g.probing = true
+		probe = true
+	}
+	state := g.state
+	g.mu.Unlock()
+
+	if changed {
+		g.registry.stateChanged(g.connection, state)
+	}
+	if err != nil {
+		incRequestsRejected(g.connection)
+	}
+
+	return probe, err
+}
+
+// release records the outcome of a request that was permitted by acquire.
+func (g *connectionGuard) release(probe, failed bool) {
+	g.mu.Lock()
+	if g.breaker == nil {
+		g.mu.Unlock()
+		return
+	}
+
+	var changed bool
+	switch {
+	case probe:
+		g.probing = false
+		if failed {
+			changed = g.open()
+		} else {
+			g.failures = 0
+			changed = g.transition(CircuitBreakerStateClosed)
+		}
+	case g.state != CircuitBreakerStateClosed:
+		// the request was sent before the circuit breaker was opened, its outcome
+		// is no longer relevant.
+	case failed:
+		g.failures++
+		if g.failures >= g.breaker.FailureThreshold {
+			changed = g.open()
+		}
+	default:
+		g.failures = 0
+	}
+	state := g.state
+	g.mu.Unlock()
+
+	if changed {
+		g.registry.stateChanged(g.connection, state)
+	}
+}
+
+// abort releases the probe of a request that was not sent.
+func (g *connectionGuard) abort(probe bool) {
+	if !probe {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.probing = false
+}
+
+func (g *connectionGuard) open() bool {
+	g.failures = 0
+	g.openedAt = g.now()
+	return g.transition(CircuitBreakerStateOpen)
+}
+
+func (g *connectionGuard) transition(state CircuitBreakerState) bool {
+	if g.state == state {
+		return false
+	}
+	g.state = state
+	g.lastTransitionTime = g.now()
+	return true
+}
+
+func (g *connectionGuard) circuitBreakerStatus() (CircuitBreakerStatus, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.breaker == nil {
+		return CircuitBreakerStatus{}, false
+	}
+	return CircuitBreakerStatus{
+		State:              g.state,
+		LastTransitionTime: g.lastTransitionTime,
+	}, true
+}
+
+// roundTripper returns an http.RoundTripper that guards all requests sent
+// by next.
+func (g *connectionGuard) roundTripper(next http.RoundTripper) http.RoundTripper {
+	return &guardedTransport{
+		guard: g,
+		next:  next,
+	}
+}
+
+type guardedTransport struct {
+	guard *connectionGuard
+	next  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := t.guard.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.guard.wait(req.Context()); err != nil {
+		t.guard.abort(probe)
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// the request was canceled by the caller, which says nothing about
+		// Vault's health.
+		t.guard.abort(probe)
+	default:
+		t.guard.release(probe, isFailedResponse(resp, err))
+	}
+
+	return resp, err
+}
+
+// isFailedResponse returns true if the response indicates that the Vault
+// server is unavailable or overloaded. Client errors, like a permission
+// denied, do not count as failures.
+func isFailedResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusTooManyRequests
+}
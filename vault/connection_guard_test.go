@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_connectionGuard_circuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	connection := ctrlclient.ObjectKey{Namespace: "ns", Name: "circuit-breaker"}
+	r := newConnectionGuardRegistry()
+	var states []CircuitBreakerState
+	r.setOnStateChange(func(objKey ctrlclient.ObjectKey, state CircuitBreakerState) {
+		assert.Equal(t, connection, objKey)
+		states = append(states, state)
+	})
+
+	g := r.forConfig(&ClientConfig{
+		Connection: connection,
+		CircuitBreaker: &CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OpenDuration:     time.Minute,
+		},
+	})
+	require.NotNil(t, g)
+	now := time.Unix(1000, 0)
+	g.now = func() time.Time { return now }
+
+	httpClient := &http.Client{Transport: g.roundTripper(http.DefaultTransport)}
+	get := func() (int, error) {
+		resp, err := httpClient.Get(server.URL)
+		if err != nil {
+			return 0, err
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+	assertState := func(want CircuitBreakerState) {
+		t.Helper()
+		got, ok := g.circuitBreakerStatus()
+		require.True(t, ok)
+		assert.Equal(t, want, got.State)
+	}
+
+	code, err := get()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assertState(CircuitBreakerStateClosed)
+
+	// client errors do not count as failures.
+	status.Store(http.StatusForbidden)
+	for i := 0; i < 3; i++ {
+		_, err = get()
+		require.NoError(t, err)
+	}
+	assertState(CircuitBreakerStateClosed)
+
+	status.Store(http.StatusServiceUnavailable)
+	for i := 0; i < 2; i++ {
+		_, err = get()
+		require.NoError(t, err)
+	}
+	assertState(CircuitBreakerStateOpen)
+
+	// requests are rejected while the circuit breaker is open.
+	sent := requests.Load()
+	_, err = get()
+	assert.ErrorIs(t, err, ErrCircuitBreakerOpen)
+	assert.Equal(t, sent, requests.Load())
+
+	// the failed probe opens the circuit breaker again.
+	now = now.Add(time.Minute)
+	_, err = get()
+	require.NoError(t, err)
+	assertState(CircuitBreakerStateOpen)
+	_, err = get()
+	assert.ErrorIs(t, err, ErrCircuitBreakerOpen)
+
+	// the successful probe closes the circuit breaker.
+	now = now.Add(time.Minute)
+	status.Store(http.StatusOK)
+	_, err = get()
+	require.NoError(t, err)
+	assertState(CircuitBreakerStateClosed)
+
+	assert.Equal(t, []CircuitBreakerState{
+		CircuitBreakerStateOpen,
+		CircuitBreakerStateHalfOpen,
+		CircuitBreakerStateOpen,
+		CircuitBreakerStateHalfOpen,
+		CircuitBreakerStateClosed,
+	}, states)
+}
+
+func Test_connectionGuard_acquire_halfOpen(t *testing.T) {
+	t.Parallel()
+
+	r := newConnectionGuardRegistry()
+	g := r.forConfig(&ClientConfig{
+		Connection: ctrlclient.ObjectKey{Namespace: "ns", Name: "half-open"},
+		CircuitBreaker: &CircuitBreakerConfig{
+			FailureThreshold: 1,
+			OpenDuration:     time.Second,
+		},
+	})
+	require.NotNil(t, g)
+	now := time.Unix(1000, 0)
+	g.now = func() time.Time { return now }
+
+	probe, err := g.acquire()
+	require.NoError(t, err)
+	g.release(probe, true)
+
+	now = now.Add(time.Second)
+	probe, err = g.acquire()
+	require.NoError(t, err)
+	assert.True(t, probe)
+
+	// only a single probe request is permitted at once.
+	_, err = g.acquire()
+	assert.ErrorIs(t, err, ErrCircuitBreakerOpen)
+
+	// an aborted probe permits the next request to probe.
+	g.abort(probe)
+	probe, err = g.acquire()
+	require.NoError(t, err)
+	assert.True(t, probe)
+}
+
+func Test_connectionGuard_wait(t *testing.T) {
+	t.Parallel()
+
+	r := newConnectionGuardRegistry()
+	g := r.forConfig(&ClientConfig{
+		Connection: ctrlclient.ObjectKey{Namespace: "ns", Name: "rate-limit"},
+		RateLimit: &RateLimitConfig{
+			RequestsPerSecond: 1,
+			Burst:             2,
+		},
+	})
+	require.NotNil(t, g)
+
+	ctx := context.Background()
+	require.NoError(t, g.wait(ctx))
+	require.NoError(t, g.wait(ctx))
+
+	// the burst is exhausted, the request must wait for longer than the
+	// context's deadline.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, g.wait(ctx))
+
+	_, ok := g.circuitBreakerStatus()
+	assert.False(t, ok)
+}
+
+func Test_connectionGuardRegistry_forConfig(t *testing.T) {
+	t.Parallel()
+
+	connection := ctrlclient.ObjectKey{Namespace: "ns", Name: "registry"}
+	r := newConnectionGuardRegistry()
+	assert.Nil(t, r.forConfig(&ClientConfig{Connection: connection}))
+
+	g := r.forConfig(&ClientConfig{
+		Connection: connection,
+		RateLimit: &RateLimitConfig{
+			RequestsPerSecond: 1,
+			Burst:             1,
+		},
+	})
+	require.NotNil(t, g)
+
+	// the guard is shared by all clients of the connection, and updated to
+	// the latest config.
+	same := r.forConfig(&ClientConfig{
+		Connection: connection,
+		RateLimit: &RateLimitConfig{
+			RequestsPerSecond: 5,
+			Burst:             10,
+		},
+	})
+	assert.Same(t, g, same)
+	assert.Equal(t, 10, g.limiter.Burst())
+
+	assert.Nil(t, r.forConfig(&ClientConfig{Connection: connection}))
+	_, ok := r.get(connection)
+	assert.False(t, ok)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// kvV2CASMismatchError is the error that Vault returns when the check-and-set
+// version of a KV version 2 write does not match the secret's current version.
+const kvV2CASMismatchError = "check-and-set parameter did not match the current version"
+
+// ReadKVV2CurrentVersion returns the current version of the KV version 2 secret
+// at path of mount. It returns 0 if the secret does not exist.
+func ReadKVV2CurrentVersion(ctx context.Context, c Client, mount, path string) (int, error) {
+	resp, err := c.Read(ctx, NewKVReadMetadataRequestV2(mount, path))
+	if err != nil {
+		if IsEmptyResponseError(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	version, ok := KVV2CurrentVersion(resp)
+	if !ok {
+		return 0, fmt.Errorf("current_version not found in the secret's metadata, path=%s",
+			JoinPath(mount, "metadata", path))
+	}
+
+	return version, nil
+}
+
+// WriteKVV2WithCAS writes data to the KV version 2 secret at path of mount. The
+// write only succeeds if cas is the current version of the secret, or 0 if the
+// secret does not exist. It returns the version of the written secret.
+func WriteKVV2WithCAS(ctx context.Context, c Client, mount, path string, data map[string]any, cas int) (int, error) {
+	p := JoinPath(mount, "data", path)
+	resp, err := c.Write(ctx, NewWriteRequest(p, map[string]any{
+		"data": data,
+		"options": map[string]any{
+			"cas": cas,
+		},
+	}))
+	if err != nil {
+		return 0, err
+	}
+	if resp == nil || resp.Secret() == nil {
+		return 0, fmt.Errorf("nil response from Vault, path=%s", p)
+	}
+
+	version, ok := versionFromData(resp.Data(), "version")
+	if !ok {
+		return 0, fmt.Errorf("version not found in the response from Vault, path=%s", p)
+	}
+
+	return version, nil
+}
+
+// IsCheckAndSetError returns true if Vault rejected a KV version 2 write,
+// because its check-and-set version did not match the secret's current
+// version.
+func IsCheckAndSetError(err error) bool {
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) && respErr != nil {
+		if respErr.StatusCode == http.StatusBadRequest {
+			for _, e := range respErr.Errors {
+				if strings.Contains(e, kvV2CASMismatchError) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubKVV2Client is a KV-v2 secret at version, it only accepts writes whose
+// check-and-set version matches the current version.
+type stubKVV2Client struct {
+	Client
+	version int
+	readErr error
+	paths   []string
+}
+
+func (c *stubKVV2Client) Read(_ context.Context, req ReadRequest) (Response, error) {
+	c.paths = append(c.paths, req.Path())
+	if c.readErr != nil {
+		return nil, c.readErr
+	}
+	if c.version == 0 {
+		return nil, &EmptyResponseError{Path: req.Path()}
+	}
+	return NewDefaultResponse(&api.Secret{
+		Data: map[string]any{
+			"current_version": c.version,
+		},
+	}), nil
+}
+
+func (c *stubKVV2Client) Write(_ context.Context, req WriteRequest) (Response, error) {
+	c.paths = append(c.paths, req.Path())
+	if req.Params()["options"].(map[string]any)["cas"] != c.version {
+		return nil, &api.ResponseError{
+			StatusCode: http.StatusBadRequest,
+			Errors:     []string{kvV2CASMismatchError},
+		}
+	}
+	c.version++
+	return NewDefaultResponse(&api.Secret{
+		Data: map[string]any{
+			"version": c.version,
+		},
+	}), nil
+}
+
+func TestReadKVV2CurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		c       *stubKVV2Client
+		want    int
+		wantErr string
+	}{
+		{
+			name: "exists",
+			c:    &stubKVV2Client{version: 3},
+			want: 3,
+		},
+		{
+			name: "not-exists",
+			c:    &stubKVV2Client{},
+			want: 0,
+		},
+		{
+			name:    "error",
+			c:       &stubKVV2Client{readErr: fmt.Errorf("permission denied")},
+			wantErr: "permission denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ReadKVV2CurrentVersion(context.Background(), tt.c, "kv", "app/tls")
+			assert.Equal(t, []string{"kv/metadata/app/tls"}, tt.c.paths)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteKVV2WithCAS(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &stubKVV2Client{version: 2}
+
+	_, err := WriteKVV2WithCAS(ctx, c, "kv", "app/tls", map[string]any{"foo": "bar"}, 1)
+	assert.True(t, IsCheckAndSetError(err))
+
+	got, err := WriteKVV2WithCAS(ctx, c, "kv", "app/tls", map[string]any{"foo": "bar"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got)
+	assert.Equal(t, []string{"kv/data/app/tls", "kv/data/app/tls"}, c.paths)
+}
+
+func TestIsCheckAndSetError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "check-and-set",
+			err: &api.ResponseError{
+				StatusCode: http.StatusBadRequest,
+				Errors:     []string{"check-and-set parameter did not match the current version"},
+			},
+			want: true,
+		},
+		{
+			name: "wrapped-check-and-set",
+			err: fmt.Errorf("write failed: %w", &api.ResponseError{
+				StatusCode: http.StatusBadRequest,
+				Errors:     []string{"check-and-set parameter did not match the current version"},
+			}),
+			want: true,
+		},
+		{
+			name: "wrong-error",
+			err: &api.ResponseError{
+				StatusCode: http.StatusBadRequest,
+				Errors:     []string{"another error"},
+			},
+			want: false,
+		},
+		{
+			name: "wrong-status-code",
+			err: &api.ResponseError{
+				StatusCode: http.StatusForbidden,
+				Errors:     []string{"check-and-set parameter did not match the current version"},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, IsCheckAndSetError(tt.err), "IsCheckAndSetError(%v)", tt.err)
+		})
+	}
+}
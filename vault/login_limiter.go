@@ -0,0 +1,183 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault-secrets-operator/credentials"
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// LoginMaxConcurrencyDefault is the key of the login concurrency limit that
+// applies to all auth methods without a limit of their own.
+const LoginMaxConcurrencyDefault = "default"
+
+// ParseLoginMaxConcurrency parses the maximum number of concurrent Vault logins
+// per auth mount, keyed by auth method, from a slice of "method=limit" pairs.
+// The LoginMaxConcurrencyDefault key sets the limit of all other auth methods.
+func ParseLoginMaxConcurrency(pairs []string) (map[string]int, error) {
+	limits := map[string]int{}
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid login concurrency %q, must be in the form method=limit", pair)
+		}
+
+		if k != LoginMaxConcurrencyDefault && !slices.Contains(credentials.ProviderMethodsSupported, k) {
+			return nil, fmt.Errorf("unsupported auth method %q for login concurrency, must be one of %v or %q",
+				k, credentials.ProviderMethodsSupported, LoginMaxConcurrencyDefault)
+		}
+
+		if _, ok := limits[k]; ok {
+			return nil, fmt.Errorf("duplicate login concurrency for auth method %q", k)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid login concurrency %q, the limit must be a positive integer", pair)
+		}
+
+		limits[k] = limit
+	}
+
+	return limits, nil
+}
+
+// loginLimiter limits the number of concurrent Vault logins per auth mount. It
+// is shared by all Clients of a CachingClientFactory, so that a mass re-auth,
+// e.g. after the revocation of the Clients' tokens or a wipe of the client
+// cache, does not overwhelm the auth backend, or the Kubernetes TokenReview
+// API. Logins in excess of the limit wait in a FIFO queue for their turn.
+type loginLimiter struct {
+	limits map[string]int
+	mu     sync.Mutex
+	queues map[string]*loginQueue
+}
+
+// loginQueue tracks the in-flight and waiting logins of a single auth mount.
+type loginQueue struct {
+	method   string
+	mount    string
+	limit    int
+	inFlight int
+	// waiters holds a channel per waiting login, the channel is closed once the
+	// login is granted a slot.
+	waiters *list.List
+}
+
+// newLoginLimiter returns a loginLimiter with the given limits, keyed by auth
+// method. A nil loginLimiter is returned if there are no limits, disabling it.
+func newLoginLimiter(limits map[string]int) *loginLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	return &loginLimiter{
+		limits: limits,
+		queues: make(map[string]*loginQueue),
+	}
+}
+
+// acquire a login slot for the auth method's mount on the Vault server addr,
+// waiting for one to be released if the mount's limit has been reached. The
+// returned func must be called to release the slot once the login is done. An
+// error is returned if ctx is done before a slot was acquired. It is safe to
+// call on a nil loginLimiter.
+func (l *loginLimiter) acquire(ctx context.Context, addr, namespace, method, mount string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	limit, ok := l.limits[method]
+	if !ok {
+		limit, ok = l.limits[LoginMaxConcurrencyDefault]
+	}
+	if !ok {
+		return func() {}, nil
+	}
+
+	key := strings.Join([]string{addr, namespace, method, mount}, "\x00")
+	l.mu.Lock()
+	q, ok := l.queues[key]
+	if !ok {
+		q = &loginQueue{
+			method:  method,
+			mount:   mount,
+			limit:   limit,
+			waiters: list.New(),
+		}
+		l.queues[key] = q
+	}
+
+	if q.inFlight < q.limit && q.waiters.Len() == 0 {
+		q.inFlight++
+		l.mu.Unlock()
+		metrics.ObserveLoginQueueWait(method, mount, 0)
+		return l.releaseFunc(key, q), nil
+	}
+
+	ch := make(chan struct{})
+	e := q.waiters.PushBack(ch)
+	metrics.IncLoginQueueDepth(method, mount)
+	l.mu.Unlock()
+
+	startTS := time.Now()
+	select {
+	case <-ch:
+		metrics.ObserveLoginQueueWait(method, mount, time.Since(startTS))
+		return l.releaseFunc(key, q), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ch:
+			// the slot was granted concurrently, hand it to the next waiter.
+			l.release(key, q)
+		default:
+			q.waiters.Remove(e)
+			metrics.DecLoginQueueDepth(method, mount)
+		}
+		return nil, fmt.Errorf("timed out waiting for a login slot for auth mount %q: %w", mount, ctx.Err())
+	}
+}
+
+func (l *loginLimiter) releaseFunc(key string, q *loginQueue) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.release(key, q)
+		})
+	}
+}
+
+// release the login slot of q, handing it over to the longest waiting login.
+// It must be called with the lock held.
+func (l *loginLimiter) release(key string, q *loginQueue) {
+	if e := q.waiters.Front(); e != nil {
+		q.waiters.Remove(e)
+		metrics.DecLoginQueueDepth(q.method, q.mount)
+		close(e.Value.(chan struct{}))
+		return
+	}
+
+	q.inFlight--
+	if q.inFlight == 0 {
+		delete(l.queues, key)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLoginMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    map[string]int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "empty",
+			pairs:   nil,
+			want:    map[string]int{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "valid",
+			pairs: []string{"kubernetes=10", " jwt = 5 ", "default=20", ""},
+			want: map[string]int{
+				"kubernetes": 10,
+				"jwt":        5,
+				"default":    20,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "missing-separator",
+			pairs:   []string{"kubernetes"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unsupported-method",
+			pairs:   []string{"userpass=10"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid-limit",
+			pairs:   []string{"kubernetes=ten"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "zero-limit",
+			pairs:   []string{"kubernetes=0"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "duplicate",
+			pairs:   []string{"kubernetes=1", "kubernetes=2"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLoginMaxConcurrency(tt.pairs)
+			if !tt.wantErr(t, err, "ParseLoginMaxConcurrency(%v)", tt.pairs) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_loginLimiter_acquire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	l := newLoginLimiter(map[string]int{"kubernetes": 2})
+
+	// the first logins of the limit are never queued.
+	release1, err := l.acquire(ctx, "https://vault", "", "kubernetes", "k8s")
+	require.NoError(t, err)
+	release2, err := l.acquire(ctx, "https://vault", "", "kubernetes", "k8s")
+	require.NoError(t, err)
+
+	// other mounts, and auth methods without a limit, are not affected.
+	releaseOther, err := l.acquire(ctx, "https://vault", "", "kubernetes", "other")
+	require.NoError(t, err)
+	releaseOther()
+	releaseJWT, err := l.acquire(ctx, "https://vault", "", "jwt", "k8s")
+	require.NoError(t, err)
+	releaseJWT()
+
+	// the excess logins are granted in the order they were queued.
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			release, err := l.acquire(ctx, "https://vault", "", "kubernetes", "k8s")
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			release()
+		}(i)
+		require.Eventually(t, func() bool {
+			return l.waiting("https://vault", "", "kubernetes", "k8s") == i+1
+		}, 10*time.Second, time.Millisecond)
+	}
+
+	// a queued login is abandoned once its context is done.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = l.acquire(canceledCtx, "https://vault", "", "kubernetes", "k8s")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, l.waiting("https://vault", "", "kubernetes", "k8s"))
+
+	// only a single slot is released, so that it is handed over from one queued
+	// login to the next.
+	release1()
+	// releasing a slot more than once has no effect.
+	release1()
+	wg.Wait()
+	release2()
+
+	assert.Equal(t, []int{0, 1, 2}, order)
+	assert.Empty(t, l.queues)
+}
+
+func Test_loginLimiter_nil(t *testing.T) {
+	t.Parallel()
+
+	var l *loginLimiter
+	assert.Nil(t, newLoginLimiter(nil))
+	release, err := l.acquire(context.Background(), "https://vault", "", "kubernetes", "k8s")
+	require.NoError(t, err)
+	release()
+}
+
+// waiting returns the number of logins queued for the auth mount.
+func (l *loginLimiter) waiting(addr, namespace, method, mount string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if q, ok := l.queues[strings.Join([]string{addr, namespace, method, mount}, "\x00")]; ok {
+		return q.waiters.Len()
+	}
+	return 0
+}
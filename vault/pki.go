@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
+const (
+	PKIKeyTypeRSA     = "rsa"
+	PKIKeyTypeEC      = "ec"
+	PKIKeyTypeEd25519 = "ed25519"
+)
+
+// PKIPrivateKey is a private key that is generated locally, for use with the
+// Vault PKI sign endpoints. Only the CSR is ever sent to Vault.
+type PKIPrivateKey struct {
+	signer  crypto.Signer
+	keyType string
+}
+
+// Type returns the key type, as returned by Vault in private_key_type.
+func (k *PKIPrivateKey) Type() string {
+	return k.keyType
+}
+
+// CSR returns the PEM encoded certificate signing request for the private key,
+// created from template.
+func (k *PKIPrivateKey) CSR(template *x509.CertificateRequest) (string, error) {
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, k.signer)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE REQUEST",
+		Bytes: der,
+	})), nil
+}
+
+// Marshal returns the encoded private key, following the same rules as Vault's
+// PKI format and private_key_format parameters. The key is PEM encoded unless
+// format is "der", in which case it is base64 encoded DER. If privateKeyFormat
+// is "pkcs8", the key is marshalled as PKCS #8, otherwise the key type's
+// default encoding is used.
+func (k *PKIPrivateKey) Marshal(format, privateKeyFormat string) (string, error) {
+	var der []byte
+	var blockType string
+	var err error
+	switch key := k.signer.(type) {
+	case *rsa.PrivateKey:
+		if privateKeyFormat != "pkcs8" {
+			der = x509.MarshalPKCS1PrivateKey(key)
+			blockType = "RSA PRIVATE KEY"
+		}
+	case *ecdsa.PrivateKey:
+		if privateKeyFormat != "pkcs8" {
+			der, err = x509.MarshalECPrivateKey(key)
+			blockType = "EC PRIVATE KEY"
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if der == nil {
+		der, err = x509.MarshalPKCS8PrivateKey(k.signer)
+		if err != nil {
+			return "", err
+		}
+		blockType = "PRIVATE KEY"
+	}
+
+	if format == "der" {
+		return base64.StdEncoding.EncodeToString(der), nil
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  blockType,
+		Bytes: der,
+	})), nil
+}
+
+// GeneratePKIPrivateKey generates a new private key of keyType. If keyBits is
+// 0, the Vault PKI default for keyType is used.
+func GeneratePKIPrivateKey(keyType string, keyBits int) (*PKIPrivateKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch keyType {
+	case PKIKeyTypeRSA:
+		if keyBits == 0 {
+			keyBits = 2048
+		}
+		if keyBits < 2048 {
+			return nil, fmt.Errorf("unsupported RSA key bits %d", keyBits)
+		}
+		signer, err = rsa.GenerateKey(rand.Reader, keyBits)
+	case PKIKeyTypeEC, "":
+		keyType = PKIKeyTypeEC
+		var curve elliptic.Curve
+		switch keyBits {
+		case 224:
+			curve = elliptic.P224()
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC key bits %d", keyBits)
+		}
+		signer, err = ecdsa.GenerateKey(curve, rand.Reader)
+	case PKIKeyTypeEd25519:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &PKIPrivateKey{
+		signer:  signer,
+		keyType: keyType,
+	}, nil
+}
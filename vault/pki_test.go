@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePKIPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		keyType          string
+		keyBits          int
+		format           string
+		privateKeyFormat string
+		wantType         string
+		wantBlockType    string
+		wantErr          assert.ErrorAssertionFunc
+	}{
+		{
+			name:          "default",
+			wantType:      PKIKeyTypeEC,
+			wantBlockType: "EC PRIVATE KEY",
+			wantErr:       assert.NoError,
+		},
+		{
+			name:          "ec-384",
+			keyType:       PKIKeyTypeEC,
+			keyBits:       384,
+			wantType:      PKIKeyTypeEC,
+			wantBlockType: "EC PRIVATE KEY",
+			wantErr:       assert.NoError,
+		},
+		{
+			name:          "rsa",
+			keyType:       PKIKeyTypeRSA,
+			wantType:      PKIKeyTypeRSA,
+			wantBlockType: "RSA PRIVATE KEY",
+			wantErr:       assert.NoError,
+		},
+		{
+			name:             "rsa-pkcs8",
+			keyType:          PKIKeyTypeRSA,
+			privateKeyFormat: "pkcs8",
+			wantType:         PKIKeyTypeRSA,
+			wantBlockType:    "PRIVATE KEY",
+			wantErr:          assert.NoError,
+		},
+		{
+			name:          "ed25519",
+			keyType:       PKIKeyTypeEd25519,
+			wantType:      PKIKeyTypeEd25519,
+			wantBlockType: "PRIVATE KEY",
+			wantErr:       assert.NoError,
+		},
+		{
+			name:     "ec-der",
+			keyType:  PKIKeyTypeEC,
+			format:   "der",
+			wantType: PKIKeyTypeEC,
+			wantErr:  assert.NoError,
+		},
+		{
+			name:    "invalid-ec-bits",
+			keyType: PKIKeyTypeEC,
+			keyBits: 1024,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid-rsa-bits",
+			keyType: PKIKeyTypeRSA,
+			keyBits: 1024,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid-type",
+			keyType: "dsa",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GeneratePKIPrivateKey(tt.keyType, tt.keyBits)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantType, got.Type())
+
+			csr, err := got.CSR(&x509.CertificateRequest{})
+			require.NoError(t, err)
+			block, _ := pem.Decode([]byte(csr))
+			require.NotNil(t, block)
+			req, err := x509.ParseCertificateRequest(block.Bytes)
+			require.NoError(t, err)
+			assert.NoError(t, req.CheckSignature())
+
+			pk, err := got.Marshal(tt.format, tt.privateKeyFormat)
+			require.NoError(t, err)
+			if tt.format == "der" {
+				_, err := base64.StdEncoding.DecodeString(pk)
+				assert.NoError(t, err)
+				return
+			}
+
+			block, _ = pem.Decode([]byte(pk))
+			require.NotNil(t, block)
+			assert.Equal(t, tt.wantBlockType, block.Type)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
+)
+
+// readCache caches the responses of identical KV reads for a short TTL. It is
+// shared by all Clients of a CachingClientFactory, so that the resources which
+// read the same KV secret with the same Client, e.g. a shared database config,
+// only result in a single read from Vault per TTL. The cached responses are
+// shared by all callers, and must not be modified.
+type readCache struct {
+	cache *gocache.Cache
+}
+
+// newReadCache returns a readCache with the given ttl. A nil readCache is
+// returned if ttl is not positive, disabling the cache.
+func newReadCache(ttl time.Duration) *readCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &readCache{
+		cache: gocache.New(ttl, 2*ttl),
+	}
+}
+
+// get the cached Response for key. It is safe to call on a nil readCache.
+func (c *readCache) get(key string) (Response, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	if v, ok := c.cache.Get(key); ok {
+		metrics.IncReadCacheHits()
+		return v.(Response), true
+	}
+
+	metrics.IncReadCacheMisses()
+	return nil, false
+}
+
+// set the cached Response for key. It is safe to call on a nil readCache.
+func (c *readCache) set(key string, resp Response) {
+	if c == nil {
+		return
+	}
+
+	c.cache.SetDefault(key, resp)
+}
+
+type readCacheBypassKey struct{}
+
+// WithReadCacheBypass returns a copy of ctx whose reads are always sent to
+// Vault, rather than served by the read cache. Their responses still replace
+// the cached ones, so that subsequent reads get the fresh data.
+func WithReadCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readCacheBypassKey{}, true)
+}
+
+func readCacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(readCacheBypassKey{}).(bool)
+	return bypass
+}
+
+// readCacheKey returns the readCache key of request for the Client, identified
+// by its cacheKey and Vault namespace. Only KV reads that are neither wrapped
+// nor require a Vault replication state are cacheable, false is returned for
+// all others.
+func readCacheKey(ctx context.Context, cacheKey ClientCacheKey, namespace string, request ReadRequest) (string, bool) {
+	switch request.(type) {
+	case *kvReadRequestV1, *kvReadRequestV2:
+	default:
+		return "", false
+	}
+
+	if len(wrapTTLCallbacks(ctx)) > 0 || replicationStateFromContext(ctx) != nil {
+		return "", false
+	}
+
+	return strings.Join([]string{
+		cacheKey.String(), namespace, request.Path(), request.Values().Encode(),
+	}, "\x00"), true
+}
@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
+	"github.com/hashicorp/vault-secrets-operator/credentials"
+	vconsts "github.com/hashicorp/vault-secrets-operator/credentials/vault/consts"
+)
+
+func Test_defaultClient_Read_readCache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		ttl          time.Duration
+		ctx          context.Context
+		requests     []ReadRequest
+		wantRequests int
+	}{
+		{
+			name: "kv-v2",
+			ttl:  time.Minute,
+			ctx:  context.Background(),
+			requests: []ReadRequest{
+				NewKVReadRequestV2("kv", "foo", 0),
+				NewKVReadRequestV2("kv", "foo", 0),
+				NewKVReadRequestV2("kv", "foo", 1),
+				NewKVReadRequestV2("kv", "foo", 1),
+				NewKVReadRequestV2("kv", "bar", 0),
+			},
+			wantRequests: 3,
+		},
+		{
+			name: "kv-v1",
+			ttl:  time.Minute,
+			ctx:  context.Background(),
+			requests: []ReadRequest{
+				NewKVReadRequestV1("kv", "foo"),
+				NewKVReadRequestV1("kv", "foo"),
+			},
+			wantRequests: 1,
+		},
+		{
+			name: "not-kv",
+			ttl:  time.Minute,
+			ctx:  context.Background(),
+			requests: []ReadRequest{
+				NewReadRequest("db/creds/foo", nil),
+				NewReadRequest("db/creds/foo", nil),
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "wrapped",
+			ttl:  time.Minute,
+			ctx:  WithWrapTTL(context.Background(), time.Minute),
+			requests: []ReadRequest{
+				NewKVReadRequestV2("kv", "foo", 0),
+				NewKVReadRequestV2("kv", "foo", 0),
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "replication-state",
+			ttl:  time.Minute,
+			ctx:  WithReplicationState(context.Background()),
+			requests: []ReadRequest{
+				NewKVReadRequestV2("kv", "foo", 0),
+				NewKVReadRequestV2("kv", "foo", 0),
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "bypass",
+			ttl:  time.Minute,
+			ctx:  WithReadCacheBypass(context.Background()),
+			requests: []ReadRequest{
+				NewKVReadRequestV2("kv", "foo", 0),
+				NewKVReadRequestV2("kv", "foo", 0),
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "disabled",
+			ctx:  context.Background(),
+			requests: []ReadRequest{
+				NewKVReadRequestV2("kv", "foo", 0),
+				NewKVReadRequestV2("kv", "foo", 0),
+			},
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &testHandler{
+				handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+					m, err := json.Marshal(&api.Secret{
+						Data: map[string]interface{}{
+							"data": map[string]interface{}{
+								"foo": "bar",
+							},
+						},
+					})
+					if err != nil {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write(m)
+				},
+			}
+
+			config, l := NewTestHTTPServer(t, handler.handler())
+			t.Cleanup(func() {
+				l.Close()
+			})
+
+			client, err := api.NewClient(config)
+			require.NoError(t, err)
+			c := &defaultClient{
+				client: client,
+				authObj: &secretsv1beta1.VaultAuth{
+					ObjectMeta: metav1.ObjectMeta{
+						UID: "c3b5a4ad-4a17-4e43-8b8a-a1bd4b4a35a2",
+					},
+					Spec: secretsv1beta1.VaultAuthSpec{
+						Method: vconsts.ProviderMethodKubernetes,
+					},
+				},
+				connObj: &secretsv1beta1.VaultConnection{
+					ObjectMeta: metav1.ObjectMeta{
+						UID: "b1e6b2a6-7c2c-4d3e-9a8e-6c5e8a3d2f10",
+					},
+				},
+				credentialProvider: credentials.NewFakeCredentialProvider().WithUID(
+					"f8b1d8a4-3e0c-4a5f-8f3b-2d6e1c9a7b42"),
+				readCache: newReadCache(tt.ttl),
+			}
+
+			var responses []Response
+			for _, req := range tt.requests {
+				resp, err := c.Read(tt.ctx, req)
+				require.NoError(t, err)
+				responses = append(responses, resp)
+			}
+			assert.Equal(t, tt.wantRequests, handler.requestCount)
+			for _, resp := range responses {
+				assert.Equal(t, responses[0].Data(), resp.Data())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+)
+
+type replicationStateKey struct{}
+
+// replicationState holds the Vault replication states, as returned in the
+// X-Vault-Index response header, that have been observed within a context.
+type replicationState struct {
+	mu     sync.Mutex
+	states []string
+}
+
+func (s *replicationState) get() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.states)
+}
+
+func (s *replicationState) record(state string) {
+	if state == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = api.MergeReplicationStates(s.states, state)
+}
+
+// WithReplicationState returns a context that tracks the Vault replication
+// state across all Client requests made with it. Every request requires the
+// replication states from all prior responses, providing read-your-writes
+// consistency when Vault performance replication is in use. If ctx is already
+// tracking the replication state, it is returned unchanged.
+func WithReplicationState(ctx context.Context) context.Context {
+	if replicationStateFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, replicationStateKey{}, &replicationState{})
+}
+
+// ReplicationStates returns the Vault replication states observed within ctx.
+func ReplicationStates(ctx context.Context) []string {
+	if s := replicationStateFromContext(ctx); s != nil {
+		return s.get()
+	}
+	return nil
+}
+
+func replicationStateFromContext(ctx context.Context) *replicationState {
+	s, _ := ctx.Value(replicationStateKey{}).(*replicationState)
+	return s
+}
+
+// withReplicationState returns a copy of client that requires all replication
+// states tracked in ctx, along with a function that records the replication
+// state of the response. The function must be called after the request has
+// completed. The additional request and response callbacks are always applied,
+// since they replace any callbacks of client. If ctx is not tracking the
+// replication state, and there are no callbacks, client is returned as is.
+func withReplicationState(ctx context.Context, client *api.Client, respCallbacks []api.ResponseCallback, callbacks ...api.RequestCallback) (*api.Client, func()) {
+	s := replicationStateFromContext(ctx)
+	if s != nil {
+		if states := s.get(); len(states) > 0 {
+			callbacks = append(callbacks, api.RequireState(states...))
+		}
+	}
+
+	if len(callbacks) > 0 {
+		client = client.WithRequestCallbacks(callbacks...)
+	}
+
+	if s == nil {
+		if len(respCallbacks) > 0 {
+			client = client.WithResponseCallbacks(respCallbacks...)
+		}
+		return client, func() {}
+	}
+
+	var state string
+	client = client.WithResponseCallbacks(append(respCallbacks, api.RecordState(&state))...)
+	return client, func() {
+		s.record(state)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultClient_replicationState(t *testing.T) {
+	t.Parallel()
+
+	var gotIndexes [][]string
+	handler := &testHandler{
+		handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+			gotIndexes = append(gotIndexes, req.Header.Values(api.HeaderIndex))
+			if req.Method == http.MethodPut {
+				w.Header().Set(api.HeaderIndex, "write-state")
+			}
+			m, err := json.Marshal(
+				&api.Secret{
+					Data: map[string]interface{}{
+						"foo": "bar",
+					},
+				},
+			)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(m)
+		},
+	}
+
+	config, l := NewTestHTTPServer(t, handler.handler())
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	client, err := api.NewClient(config)
+	require.NoError(t, err)
+	c := &defaultClient{
+		client: client,
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		wantIndexes [][]string
+		wantStates  []string
+	}{
+		{
+			name:        "without-replication-state",
+			ctx:         context.Background(),
+			wantIndexes: [][]string{nil, nil},
+		},
+		{
+			name:        "with-replication-state",
+			ctx:         WithReplicationState(context.Background()),
+			wantIndexes: [][]string{nil, {"write-state"}},
+			wantStates:  []string{"write-state"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIndexes = nil
+			_, err := c.Write(tt.ctx, NewWriteRequest("foo/bar", nil))
+			require.NoError(t, err)
+			_, err = c.Read(tt.ctx, NewReadRequest("foo/bar", nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantIndexes, gotIndexes)
+			assert.Equal(t, tt.wantStates, ReplicationStates(tt.ctx))
+		})
+	}
+}
+
+func TestWithReplicationState(t *testing.T) {
+	t.Parallel()
+
+	ctx := WithReplicationState(context.Background())
+	assert.Equal(t, ctx, WithReplicationState(ctx))
+	assert.Empty(t, ReplicationStates(ctx))
+	assert.Nil(t, ReplicationStates(context.Background()))
+}
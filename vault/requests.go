@@ -104,6 +104,14 @@ func NewKVReadRequestV2(mount, path string, version int) ReadRequest {
 	}
 }
 
+// NewKVReadMetadataRequestV2 returns a ReadRequest for the metadata of a KV
+// version 2 secret, e.g. to get its current version.
+func NewKVReadMetadataRequestV2(mount, path string) ReadRequest {
+	return &defaultReadRequest{
+		path: JoinPath(mount, "metadata", path),
+	}
+}
+
 func NewReadRequest(path string, values url.Values) ReadRequest {
 	return &defaultReadRequest{
 		path:   path,
@@ -4,9 +4,11 @@
 package vault
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp/vault/api"
 
@@ -133,6 +135,102 @@ func NewDefaultResponse(secret *api.Secret) Response {
 	}
 }
 
+// KVV2SecretVersion returns the version of the KV version 2 secret in resp, it
+// returns false if resp does not include the secret's version.
+func KVV2SecretVersion(resp Response) (int, bool) {
+	if resp == nil || resp.Secret() == nil || resp.Secret().Data == nil {
+		return 0, false
+	}
+
+	metadata, ok := resp.Secret().Data["metadata"].(map[string]any)
+	if !ok {
+		return 0, false
+	}
+
+	return versionFromData(metadata, "version")
+}
+
+// IsKVV2SecretDeleted returns true if the version of the KV version 2 secret in
+// resp was deleted or destroyed, in which case resp has the version's metadata
+// but no data.
+func IsKVV2SecretDeleted(resp Response) bool {
+	if resp == nil || resp.Secret() == nil || resp.Secret().Data == nil {
+		return false
+	}
+
+	metadata, ok := resp.Secret().Data["metadata"].(map[string]any)
+	if !ok {
+		return false
+	}
+
+	if destroyed, ok := metadata["destroyed"].(bool); ok && destroyed {
+		return true
+	}
+
+	deletionTime, _ := metadata["deletion_time"].(string)
+	return deletionTime != ""
+}
+
+// KVV2CurrentVersion returns the current version of the KV version 2 secret
+// from resp, the response of a metadata read request. It returns false if resp
+// does not include the current version.
+func KVV2CurrentVersion(resp Response) (int, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	return versionFromData(resp.Data(), "current_version")
+}
+
+func versionFromData(data map[string]any, key string) (int, bool) {
+	switch v := data[key].(type) {
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return int(i), true
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// EmptyResponseError is returned when Vault responds to a read request without
+// a secret, e.g. because it does not exist.
+type EmptyResponseError struct {
+	Path string
+}
+
+func (e *EmptyResponseError) Error() string {
+	return fmt.Sprintf("empty response from Vault, path=%q", e.Path)
+}
+
+// IsEmptyResponseError returns true if Vault responded to a read request
+// without a secret.
+func IsEmptyResponseError(err error) bool {
+	var respErr *EmptyResponseError
+	return errors.As(err, &respErr)
+}
+
+// LoginDiagnosticsError is returned when Vault denied a login, along with the
+// most likely causes as diagnosed by the auth method's credential provider.
+type LoginDiagnosticsError struct {
+	Err         error
+	Diagnostics []string
+}
+
+func (e *LoginDiagnosticsError) Error() string {
+	return fmt.Sprintf("%s, diagnostics: %s", e.Err, strings.Join(e.Diagnostics, "; "))
+}
+
+func (e *LoginDiagnosticsError) Unwrap() error {
+	return e.Err
+}
+
 // IsLeaseNotFoundError returns true if a lease not found error is returned from Vault.
 func IsLeaseNotFoundError(err error) bool {
 	var respErr *api.ResponseError
@@ -4,6 +4,7 @@
 package vault
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -767,6 +768,189 @@ func TestIsForbiddenError(t *testing.T) {
 	}
 }
 
+func TestKVV2SecretVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		resp   Response
+		want   int
+		wantOk bool
+	}{
+		{
+			name:   "nil",
+			resp:   nil,
+			wantOk: false,
+		},
+		{
+			name: "json-number",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"data":     map[string]any{"foo": "bar"},
+					"metadata": map[string]any{"version": json.Number("3")},
+				},
+			}),
+			want:   3,
+			wantOk: true,
+		},
+		{
+			name: "int",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"metadata": map[string]any{"version": 2},
+				},
+			}),
+			want:   2,
+			wantOk: true,
+		},
+		{
+			name: "no-metadata",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"data": map[string]any{"foo": "bar"},
+				},
+			}),
+			wantOk: false,
+		},
+		{
+			name: "invalid-version",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"metadata": map[string]any{"version": "foo"},
+				},
+			}),
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := KVV2SecretVersion(tt.resp)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsKVV2SecretDeleted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		resp Response
+		want bool
+	}{
+		{
+			name: "nil",
+			resp: nil,
+		},
+		{
+			name: "live",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"data": map[string]any{"foo": "bar"},
+					"metadata": map[string]any{
+						"version":       json.Number("3"),
+						"deletion_time": "",
+						"destroyed":     false,
+					},
+				},
+			}),
+		},
+		{
+			name: "deleted",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"data": nil,
+					"metadata": map[string]any{
+						"version":       json.Number("3"),
+						"deletion_time": "2024-01-01T00:00:00.000000Z",
+						"destroyed":     false,
+					},
+				},
+			}),
+			want: true,
+		},
+		{
+			name: "destroyed",
+			resp: NewKVV2Response(&api.Secret{
+				Data: map[string]any{
+					"data": nil,
+					"metadata": map[string]any{
+						"version":       json.Number("3"),
+						"deletion_time": "",
+						"destroyed":     true,
+					},
+				},
+			}),
+			want: true,
+		},
+		{
+			name: "no-metadata",
+			resp: NewKVV1Response(&api.Secret{
+				Data: map[string]any{"foo": "bar"},
+			}),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, IsKVV2SecretDeleted(tt.resp))
+		})
+	}
+}
+
+func TestKVV2CurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		resp   Response
+		want   int
+		wantOk bool
+	}{
+		{
+			name:   "nil",
+			resp:   nil,
+			wantOk: false,
+		},
+		{
+			name: "json-number",
+			resp: NewDefaultResponse(&api.Secret{
+				Data: map[string]any{
+					"current_version": json.Number("5"),
+					"oldest_version":  json.Number("1"),
+				},
+			}),
+			want:   5,
+			wantOk: true,
+		},
+		{
+			name: "missing",
+			resp: NewDefaultResponse(&api.Secret{
+				Data: map[string]any{
+					"oldest_version": json.Number("1"),
+				},
+			}),
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := KVV2CurrentVersion(tt.resp)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func assertResponseData(t *testing.T, tt testResponseData) {
 	t.Helper()
 	resp := tt.respFunc(tt)
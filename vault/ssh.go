@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHCertResponse is the response of Vault's SSH sign endpoint.
+type SSHCertResponse struct {
+	SerialNumber string `json:"serial_number"`
+	SignedKey    string `json:"signed_key"`
+}
+
+// Certificate returns the parsed signed certificate.
+func (r *SSHCertResponse) Certificate() (*ssh.Certificate, error) {
+	return ParseSSHCertificate(r.SignedKey)
+}
+
+// ValidBefore returns the expiry of the signed certificate.
+func (r *SSHCertResponse) ValidBefore() (time.Time, error) {
+	cert, err := r.Certificate()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if cert.ValidBefore == ssh.CertTimeInfinity {
+		return time.Time{}, fmt.Errorf("certificate is valid forever")
+	}
+
+	return time.Unix(int64(cert.ValidBefore), 0), nil
+}
+
+// ParseSSHCertificate parses an SSH certificate in the authorized_keys format.
+func ParseSSHCertificate(s string) (*ssh.Certificate, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("not an SSH certificate, type=%s", key.Type())
+	}
+
+	return cert, nil
+}
+
+func UnmarshalSSHSignResponse(resp *api.Secret) (*SSHCertResponse, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("vault secret response is nil")
+	}
+
+	b, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SSHCertResponse{}
+	if err := json.Unmarshal(b, result); err != nil {
+		return nil, err
+	}
+
+	if result.SignedKey == "" {
+		return nil, fmt.Errorf("no signed_key in response")
+	}
+
+	return result, nil
+}
+
+// SSHPrivateKey is an SSH private key that is generated locally, for use with
+// the Vault SSH sign endpoint. Only its public key is ever sent to Vault.
+type SSHPrivateKey struct {
+	signer crypto.Signer
+}
+
+// PublicKey returns the public key in the authorized_keys format.
+func (k *SSHPrivateKey) PublicKey() (string, error) {
+	pub, err := ssh.NewPublicKey(k.signer.Public())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))), nil
+}
+
+// Marshal returns the private key, PEM encoded in the OpenSSH format.
+func (k *SSHPrivateKey) Marshal() (string, error) {
+	block, err := ssh.MarshalPrivateKey(k.signer, "")
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// GenerateSSHPrivateKey generates a new SSH private key of keyType, one of
+// "rsa", "ec", or "ed25519". If keyBits is 0, the PKI default for keyType is
+// used.
+func GenerateSSHPrivateKey(keyType string, keyBits int) (*SSHPrivateKey, error) {
+	if keyType == PKIKeyTypeEC && keyBits == 224 {
+		// not supported by SSH
+		return nil, fmt.Errorf("unsupported EC key bits %d", keyBits)
+	}
+
+	k, err := GeneratePKIPrivateKey(keyType, keyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHPrivateKey{
+		signer: k.signer,
+	}, nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateSSHPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		keyType  string
+		keyBits  int
+		wantType string
+		wantErr  string
+	}{
+		{
+			name:     "ed25519",
+			keyType:  PKIKeyTypeEd25519,
+			wantType: ssh.KeyAlgoED25519,
+		},
+		{
+			name:     "ec-384",
+			keyType:  PKIKeyTypeEC,
+			keyBits:  384,
+			wantType: ssh.KeyAlgoECDSA384,
+		},
+		{
+			name:     "rsa",
+			keyType:  PKIKeyTypeRSA,
+			wantType: ssh.KeyAlgoRSA,
+		},
+		{
+			name:    "ec-224",
+			keyType: PKIKeyTypeEC,
+			keyBits: 224,
+			wantErr: "unsupported EC key bits 224",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			k, err := GenerateSSHPrivateKey(tt.keyType, tt.keyBits)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			pub, err := k.PublicKey()
+			require.NoError(t, err)
+			key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pub))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, key.Type())
+
+			priv, err := k.Marshal()
+			require.NoError(t, err)
+			block, _ := pem.Decode([]byte(priv))
+			require.NotNil(t, block)
+			assert.Equal(t, "OPENSSH PRIVATE KEY", block.Type)
+			signer, err := ssh.ParsePrivateKey([]byte(priv))
+			require.NoError(t, err)
+			assert.Equal(t, key.Marshal(), signer.PublicKey().Marshal())
+		})
+	}
+}
+
+func TestUnmarshalSSHSignResponse(t *testing.T) {
+	t.Parallel()
+
+	validBefore := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	signedKey := newTestSSHCertificate(t, uint64(validBefore.Unix()))
+
+	tests := []struct {
+		name            string
+		resp            *api.Secret
+		want            *SSHCertResponse
+		wantValidBefore time.Time
+		wantErr         string
+	}{
+		{
+			name: "valid",
+			resp: &api.Secret{
+				Data: map[string]any{
+					"serial_number": "c73f26d2340276aa",
+					"signed_key":    signedKey,
+				},
+			},
+			want: &SSHCertResponse{
+				SerialNumber: "c73f26d2340276aa",
+				SignedKey:    signedKey,
+			},
+			wantValidBefore: validBefore,
+		},
+		{
+			name: "no-signed-key",
+			resp: &api.Secret{
+				Data: map[string]any{
+					"serial_number": "c73f26d2340276aa",
+				},
+			},
+			wantErr: "no signed_key in response",
+		},
+		{
+			name:    "nil-response",
+			wantErr: "vault secret response is nil",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := UnmarshalSSHSignResponse(tt.resp)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			validBefore, err := got.ValidBefore()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantValidBefore, validBefore)
+		})
+	}
+}
+
+func TestSSHCertResponse_ValidBefore(t *testing.T) {
+	t.Parallel()
+
+	r := &SSHCertResponse{
+		SignedKey: newTestSSHCertificate(t, ssh.CertTimeInfinity),
+	}
+	_, err := r.ValidBefore()
+	assert.ErrorContains(t, err, "certificate is valid forever")
+
+	k, err := GenerateSSHPrivateKey(PKIKeyTypeEd25519, 0)
+	require.NoError(t, err)
+	r.SignedKey, err = k.PublicKey()
+	require.NoError(t, err)
+	_, err = r.ValidBefore()
+	assert.ErrorContains(t, err, "not an SSH certificate")
+}
+
+// newTestSSHCertificate returns a user certificate signed by a new CA key, in
+// the authorized_keys format.
+func newTestSSHCertificate(t *testing.T, validBefore uint64) string {
+	t.Helper()
+
+	newSigner := func() ssh.Signer {
+		k, err := GenerateSSHPrivateKey(PKIKeyTypeEd25519, 0)
+		require.NoError(t, err)
+		signer, err := ssh.NewSignerFromSigner(k.signer)
+		require.NoError(t, err)
+		return signer
+	}
+
+	ca, key := newSigner(), newSigner()
+	cert := &ssh.Certificate{
+		Key:             key.PublicKey(),
+		Serial:          1,
+		CertType:        ssh.UserCert,
+		KeyId:           "test",
+		ValidPrincipals: []string{"alice"},
+		ValidBefore:     validBefore,
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+
+	return string(ssh.MarshalAuthorizedKey(cert))
+}
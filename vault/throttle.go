@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ThrottledError is returned when Vault did not serve a request because it is
+// rate limited, or because the Vault server is unavailable, e.g. a sealed node or
+// a standby that redirected the request. The request should be retried after
+// RetryAfter, as requested by Vault in the Retry-After response header. It is
+// zero if Vault did not request a delay.
+type ThrottledError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ThrottledError) Error() string {
+	msg := fmt.Sprintf("request throttled by Vault, status=%d", e.StatusCode)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retryAfter=%s", e.RetryAfter)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %s", e.Err)
+	}
+	return msg
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// IsThrottledError returns true if Vault throttled the request.
+func IsThrottledError(err error) bool {
+	var throttledErr *ThrottledError
+	return errors.As(err, &throttledErr)
+}
+
+// isThrottledStatus returns true if the status code denotes a request that was
+// not served by Vault, and should be retried later.
+func isThrottledStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusTemporaryRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// throttleState records the throttling of a Vault response.
+type throttleState struct {
+	statusCode int
+	retryAfter time.Duration
+}
+
+// record is an api.ResponseCallback, the Vault API client calls it for every
+// response, including error responses.
+func (s *throttleState) record(resp *api.Response) {
+	if resp == nil || resp.Response == nil || !isThrottledStatus(resp.StatusCode) {
+		return
+	}
+
+	s.statusCode = resp.StatusCode
+	s.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// err returns a ThrottledError wrapping err if the response was throttled,
+// otherwise err is returned as is. A standby redirect that was not followed by
+// the Vault API client is not a ResponseError, it is converted regardless.
+func (s *throttleState) err(err error) error {
+	if s.statusCode == 0 {
+		return err
+	}
+
+	if s.statusCode == http.StatusTemporaryRedirect {
+		if err == nil {
+			err = fmt.Errorf("unfollowed redirect from Vault")
+		}
+	} else {
+		var respErr *api.ResponseError
+		if !errors.As(err, &respErr) || respErr.StatusCode != s.statusCode {
+			return err
+		}
+	}
+
+	return &ThrottledError{
+		StatusCode: s.statusCode,
+		RetryAfter: s.retryAfter,
+		Err:        err,
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either in seconds,
+// or as an HTTP date. Returns zero if the value is invalid or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultClient_throttled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		header         http.Header
+		wantThrottled  bool
+		wantStatusCode int
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:           "rate-limited",
+			statusCode:     http.StatusTooManyRequests,
+			header:         http.Header{"Retry-After": {"7"}},
+			wantThrottled:  true,
+			wantStatusCode: http.StatusTooManyRequests,
+			wantRetryAfter: 7 * time.Second,
+		},
+		{
+			name:           "unavailable-without-retry-after",
+			statusCode:     http.StatusServiceUnavailable,
+			wantThrottled:  true,
+			wantStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "forbidden",
+			statusCode: http.StatusForbidden,
+			header:     http.Header{"Retry-After": {"7"}},
+		},
+		{
+			name:       "standby-redirect",
+			statusCode: http.StatusTemporaryRedirect,
+			header: http.Header{
+				"Retry-After": {"3"},
+				// the Vault API client only follows a single redirect.
+				"Location": {"/v1/foo/bar"},
+			},
+			wantThrottled:  true,
+			wantStatusCode: http.StatusTemporaryRedirect,
+			wantRetryAfter: 3 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &testHandler{
+				handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+					for k, v := range tt.header {
+						w.Header()[k] = v
+					}
+					w.WriteHeader(tt.statusCode)
+				},
+			}
+
+			config, l := NewTestHTTPServer(t, handler.handler())
+			t.Cleanup(func() {
+				l.Close()
+			})
+			config.MaxRetries = 0
+
+			client, err := api.NewClient(config)
+			require.NoError(t, err)
+			if loc := tt.header.Get("Location"); loc != "" {
+				tt.header.Set("Location", config.Address+loc)
+			}
+			c := &defaultClient{
+				client: client,
+			}
+
+			for _, err := range []error{
+				func() error {
+					_, err := c.Read(context.Background(), NewReadRequest("foo/bar", nil))
+					return err
+				}(),
+				func() error {
+					_, err := c.Write(context.Background(), NewWriteRequest("foo/bar", nil))
+					return err
+				}(),
+			} {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantThrottled, IsThrottledError(err))
+				var throttledErr *ThrottledError
+				if !tt.wantThrottled || !errors.As(err, &throttledErr) {
+					continue
+				}
+
+				assert.Equal(t, tt.wantStatusCode, throttledErr.StatusCode)
+				assert.Equal(t, tt.wantRetryAfter, throttledErr.RetryAfter)
+				if tt.statusCode != http.StatusTemporaryRedirect {
+					var respErr *api.ResponseError
+					require.ErrorAs(t, err, &respErr)
+					assert.Equal(t, tt.statusCode, respErr.StatusCode)
+				}
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "seconds",
+			value: "30",
+			want:  30 * time.Second,
+		},
+		{
+			name:  "zero-seconds",
+			value: "0",
+		},
+		{
+			name:  "negative-seconds",
+			value: "-1",
+		},
+		{
+			name:  "http-date",
+			value: now.Add(time.Minute).Format(http.TimeFormat),
+			want:  time.Minute,
+		},
+		{
+			name:  "http-date-in-the-past",
+			value: now.Add(-time.Minute).Format(http.TimeFormat),
+		},
+		{
+			name:  "invalid",
+			value: "soon",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, parseRetryAfter(tt.value, now))
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/json"
 )
@@ -76,3 +78,155 @@ func DecryptWithTransit(ctx context.Context, vaultClient Client, mount, key stri
 
 	return base64.StdEncoding.DecodeString(d.Plaintext)
 }
+
+// DecryptCiphertextWithTransit decrypts a Vault Transit ciphertext, e.g.
+// vault:v1:..., using key.
+func DecryptCiphertextWithTransit(ctx context.Context, vaultClient Client, mount, key, ciphertext string) ([]byte, error) {
+	path := fmt.Sprintf("%s/decrypt/%s", mount, key)
+	resp, err := vaultClient.Write(ctx, NewWriteRequest(path, map[string]any{
+		"ciphertext": ciphertext,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("nil response from Vault, path=%s", path)
+	}
+
+	plaintext, ok := resp.Data()["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid plaintext in response from Vault, path=%s", path)
+	}
+
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+// RewrapWithTransit rewraps a Vault Transit ciphertext with the latest version
+// of key.
+func RewrapWithTransit(ctx context.Context, vaultClient Client, mount, key, ciphertext string) (string, error) {
+	path := fmt.Sprintf("%s/rewrap/%s", mount, key)
+	resp, err := vaultClient.Write(ctx, NewWriteRequest(path, map[string]any{
+		"ciphertext": ciphertext,
+	}))
+	if err != nil {
+		return "", err
+	}
+	if resp == nil {
+		return "", fmt.Errorf("nil response from Vault, path=%s", path)
+	}
+
+	v, ok := resp.Data()["ciphertext"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid ciphertext in response from Vault, path=%s", path)
+	}
+
+	return v, nil
+}
+
+// TransitKeyVersions holds the key versions of a Vault Transit key.
+type TransitKeyVersions struct {
+	// LatestVersion of the key, new ciphertexts are encrypted with it.
+	LatestVersion int `json:"latest_version"`
+	// MinDecryptionVersion is the minimum key version that Vault allows for
+	// decryption, older ciphertexts can neither be decrypted nor rewrapped.
+	MinDecryptionVersion int `json:"min_decryption_version"`
+	// MinEncryptionVersion is the minimum key version that Vault allows for
+	// encryption, 0 means the latest version.
+	MinEncryptionVersion int `json:"min_encryption_version"`
+}
+
+// ReadTransitKeyVersions reads the key versions of the Vault Transit key.
+func ReadTransitKeyVersions(ctx context.Context, vaultClient Client, mount, key string) (*TransitKeyVersions, error) {
+	path := fmt.Sprintf("%s/keys/%s", mount, key)
+	resp, err := vaultClient.Read(ctx, NewReadRequest(path, nil))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.Data() == nil {
+		return nil, fmt.Errorf("nil response from Vault, path=%s", path)
+	}
+
+	b, err := json.Marshal(resp.Data())
+	if err != nil {
+		return nil, err
+	}
+
+	var v TransitKeyVersions
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if v.LatestVersion <= 0 {
+		return nil, fmt.Errorf("invalid latest_version in response from Vault, path=%s", path)
+	}
+
+	return &v, nil
+}
+
+// TransitCiphertextVersion returns the key version of a Vault Transit
+// ciphertext, e.g. 2 for vault:v2:...
+func TransitCiphertextVersion(ciphertext string) (int, error) {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+		return 0, fmt.Errorf("invalid transit ciphertext")
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid transit ciphertext key version %q", parts[1])
+	}
+
+	return version, nil
+}
+
+// TransitDataKey is a data key generated by Vault Transit.
+type TransitDataKey struct {
+	// Plaintext of the data key, base64 encoded. It is empty for a wrapped
+	// data key.
+	Plaintext string
+	// Ciphertext of the data key, encrypted by the transit key.
+	Ciphertext string
+	// KeyVersion of the transit key that encrypted the data key.
+	KeyVersion int
+}
+
+// GenerateDataKeyWithTransit generates a new data key of bits, encrypted by
+// key. The keyType is either "plaintext" or "wrapped", the latter only returns
+// the ciphertext of the data key.
+func GenerateDataKeyWithTransit(ctx context.Context, vaultClient Client, mount, key, keyType string, bits int) (*TransitDataKey, error) {
+	path := fmt.Sprintf("%s/datakey/%s/%s", mount, keyType, key)
+	params := map[string]any{}
+	if bits > 0 {
+		params["bits"] = bits
+	}
+
+	resp, err := vaultClient.Write(ctx, NewWriteRequest(path, params))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("nil response from Vault, path=%s", path)
+	}
+
+	b, err := json.Marshal(resp.Data())
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+		KeyVersion int    `json:"key_version"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if v.Ciphertext == "" {
+		return nil, fmt.Errorf("no ciphertext in response from Vault, path=%s", path)
+	}
+
+	return &TransitDataKey{
+		Plaintext:  v.Plaintext,
+		Ciphertext: v.Ciphertext,
+		KeyVersion: v.KeyVersion,
+	}, nil
+}
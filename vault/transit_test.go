@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitCiphertextVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		ciphertext string
+		want       int
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "v1",
+			ciphertext: "vault:v1:Zm9vYmFy",
+			want:       1,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "v12",
+			ciphertext: "vault:v12:Zm9vYmFy",
+			want:       12,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "invalid-prefix",
+			ciphertext: "other:v1:Zm9vYmFy",
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "invalid-version",
+			ciphertext: "vault:vX:Zm9vYmFy",
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "zero-version",
+			ciphertext: "vault:v0:Zm9vYmFy",
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "empty",
+			ciphertext: "",
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransitCiphertextVersion(tt.ciphertext)
+			if !tt.wantErr(t, err, "TransitCiphertextVersion(%q)", tt.ciphertext) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
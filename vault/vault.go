@@ -4,8 +4,12 @@
 package vault
 
 import (
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 )
@@ -20,6 +24,44 @@ type PKICertResponse struct {
 	SerialNumber   string   `json:"serial_number"`
 }
 
+// NotAfter returns the expiry of the issued certificate. The certificate may be
+// PEM encoded, including a "pem_bundle", or base64 encoded DER.
+func (r *PKICertResponse) NotAfter() (time.Time, error) {
+	var der []byte
+	var found bool
+	rest := []byte(r.Certificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		found = true
+		if block.Type == "CERTIFICATE" {
+			der = block.Bytes
+			break
+		}
+	}
+
+	if der == nil {
+		if found {
+			return time.Time{}, fmt.Errorf("no certificate found in PEM data")
+		}
+		b, err := base64.StdEncoding.DecodeString(r.Certificate)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("certificate is neither PEM nor base64 encoded DER")
+		}
+		der = b
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return cert.NotAfter, nil
+}
+
 func UnmarshalPKIIssueResponse(resp *api.Secret) (*PKICertResponse, error) {
 	if resp == nil {
 		return nil, fmt.Errorf("vault secret response is nil")
@@ -4,11 +4,16 @@
 package vault
 
 import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUnmarshalPKIIssueResponse(t *testing.T) {
@@ -73,3 +78,77 @@ func TestUnmarshalPKIIssueResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestPKICertResponse_NotAfter(t *testing.T) {
+	caPEM, err := generateCA()
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(caPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		certificate string
+		want        time.Time
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "pem",
+			certificate: string(caPEM),
+			want:        cert.NotAfter,
+			wantErr:     assert.NoError,
+		},
+		{
+			name: "pem-bundle",
+			certificate: string(pem.EncodeToMemory(&pem.Block{
+				Type:  "EC PRIVATE KEY",
+				Bytes: []byte("key1"),
+			})) + string(caPEM),
+			want:    cert.NotAfter,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "pem-without-certificate",
+			certificate: string(pem.EncodeToMemory(&pem.Block{
+				Type:  "EC PRIVATE KEY",
+				Bytes: []byte("key1"),
+			})),
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "no certificate found in PEM data", i...)
+			},
+		},
+		{
+			name:        "der",
+			certificate: base64.StdEncoding.EncodeToString(block.Bytes),
+			want:        cert.NotAfter,
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "invalid-encoding",
+			certificate: "cert1",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err,
+					"certificate is neither PEM nor base64 encoded DER", i...)
+			},
+		},
+		{
+			name:        "invalid-certificate",
+			certificate: base64.StdEncoding.EncodeToString([]byte("cert1")),
+			wantErr:     assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &PKICertResponse{
+				Certificate: tt.certificate,
+			}
+			got, err := r.NotAfter()
+			if !tt.wantErr(t, err, "NotAfter()") {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -5,19 +5,94 @@ package vault
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 	"nhooyr.io/websocket"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hashicorp/vault-secrets-operator/internal/metrics"
 )
 
 type WebsocketClient struct {
 	URL        string
 	HTTPClient *http.Client
 	Headers    http.Header
+	// DialTimeout applied to each websocket connection attempt. If zero, the
+	// HTTPClient's timeout is used.
+	DialTimeout time.Duration
+	// PingInterval is the interval at which pings are sent on an open
+	// connection. If zero, no pings are sent.
+	PingInterval time.Duration
+	// vaultConn is the VaultConnection's object key, used for metrics.
+	vaultConn string
+}
+
+// ErrWebsocketPingFailed is returned by WebsocketConn.Read after the connection
+// was closed due to a failed ping.
+var ErrWebsocketPingFailed = errors.New("websocket connection unhealthy, ping failed")
+
+// WebsocketConn is an open websocket connection to the Vault server.
+type WebsocketConn struct {
+	*websocket.Conn
+	cancel     context.CancelFunc
+	vaultConn  string
+	once       sync.Once
+	pingFailed atomic.Bool
+}
+
+// Read from the websocket connection. Returns ErrWebsocketPingFailed if the
+// connection was closed due to a failed ping.
+func (c *WebsocketConn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	typ, b, err := c.Conn.Read(ctx)
+	if err != nil && c.pingFailed.Load() {
+		return typ, b, ErrWebsocketPingFailed
+	}
+	return typ, b, err
+}
+
+// Close the websocket connection, and stop any pings.
+func (c *WebsocketConn) Close(code websocket.StatusCode, reason string) error {
+	c.once.Do(func() {
+		c.cancel()
+		websocketConnections.WithLabelValues(c.vaultConn).Dec()
+	})
+	return c.Conn.Close(code, reason)
+}
+
+// ping the connection every interval until ctx is done. The connection is closed
+// if a ping fails, which causes any pending reads to return an error.
+func (c *WebsocketConn) ping(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			err := c.Conn.Ping(pingCtx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+
+			websocketOperations.WithLabelValues(metrics.OperationPing, c.vaultConn).Inc()
+			if err != nil {
+				websocketOperationErrors.WithLabelValues(metrics.OperationPing, c.vaultConn).Inc()
+				c.pingFailed.Store(true)
+				_ = c.Close(websocket.StatusGoingAway, fmt.Sprintf("ping failed: %s", err))
+				return
+			}
+		}
+	}
 }
 
 // WebSocketClient parses the vault client's address and scheme to create a websocket client
@@ -53,22 +128,79 @@ func (c *defaultClient) WebsocketClient(eventPath string) (*WebsocketClient, err
 		Headers:    headers,
 	}
 
+	if c.websocketHTTPClient != nil {
+		w.HTTPClient = c.websocketHTTPClient
+	}
+
+	if cfg := c.websocketConfig; cfg != nil {
+		if cfg.DialTimeout != nil {
+			w.DialTimeout = *cfg.DialTimeout
+		}
+		if cfg.PingInterval != nil {
+			w.PingInterval = *cfg.PingInterval
+		}
+	}
+
+	if c.connObj != nil {
+		w.vaultConn = ctrlclient.ObjectKeyFromObject(c.connObj).String()
+	}
+
 	return w, nil
 }
 
+// SetFilter sets the filter expression that Vault uses to select the events
+// that are sent on the connection, e.g. `event_type == "kv-v2/data-write"`.
+// An empty filter sends all events.
+func (w *WebsocketClient) SetFilter(filter string) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse websocket URL: %w", err)
+	}
+
+	query := u.Query()
+	if filter == "" {
+		query.Del("filter")
+	} else {
+		query.Set("filter", filter)
+	}
+	u.RawQuery = query.Encode()
+	w.URL = u.String()
+
+	return nil
+}
+
 // Connect establishes a websocket connection to the vault server, following
 // redirects if necessary to reach the leader.
-func (w *WebsocketClient) Connect(ctx context.Context) (*websocket.Conn, error) {
+func (w *WebsocketClient) Connect(ctx context.Context) (*WebsocketConn, error) {
+	conn, err := w.connect(ctx)
+	websocketOperations.WithLabelValues(metrics.OperationConnect, w.vaultConn).Inc()
+	if err != nil {
+		websocketOperationErrors.WithLabelValues(metrics.OperationConnect, w.vaultConn).Inc()
+		return nil, err
+	}
+
+	pingCtx, cancel := context.WithCancel(ctx)
+	wsConn := &WebsocketConn{
+		Conn:      conn,
+		cancel:    cancel,
+		vaultConn: w.vaultConn,
+	}
+	websocketConnections.WithLabelValues(w.vaultConn).Inc()
+	if w.PingInterval > 0 {
+		go wsConn.ping(pingCtx, w.PingInterval)
+	}
+
+	return wsConn, nil
+}
+
+func (w *WebsocketClient) connect(ctx context.Context) (*websocket.Conn, error) {
 	// We do ten attempts, to ensure we follow forwarding to the leader.
 	var conn *websocket.Conn
 	var resp *http.Response
 	var err error
 
 	for attempt := 0; attempt < 10; attempt++ {
-		conn, resp, err = websocket.Dial(ctx, w.URL, &websocket.DialOptions{
-			HTTPClient: w.HTTPClient,
-			HTTPHeader: w.Headers,
-		})
+		conn, resp, err = w.dial(ctx)
 		if err == nil {
 			break
 		}
@@ -110,3 +242,24 @@ func (w *WebsocketClient) Connect(ctx context.Context) (*websocket.Conn, error)
 
 	return conn, nil
 }
+
+// dial the websocket server, applying the DialTimeout if one is set.
+func (w *WebsocketClient) dial(ctx context.Context) (*websocket.Conn, *http.Response, error) {
+	httpClient := w.HTTPClient
+	if w.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.DialTimeout)
+		defer cancel()
+		if httpClient != nil && httpClient.Timeout > 0 {
+			// the DialTimeout takes precedence over the HTTP client's timeout.
+			c := *httpClient
+			c.Timeout = 0
+			httpClient = &c
+		}
+	}
+
+	return websocket.Dial(ctx, w.URL, &websocket.DialOptions{
+		HTTPClient: httpClient,
+		HTTPHeader: w.Headers,
+	})
+}
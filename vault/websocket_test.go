@@ -9,11 +9,16 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"nhooyr.io/websocket"
+
+	secretsv1beta1 "github.com/hashicorp/vault-secrets-operator/api/v1beta1"
 )
 
 func TestWebSocketClient(t *testing.T) {
@@ -64,6 +69,32 @@ func TestWebSocketClient(t *testing.T) {
 	}
 }
 
+func TestWebsocketClient_SetFilter(t *testing.T) {
+	tests := map[string]struct {
+		url         string
+		filter      string
+		expectedURL string
+	}{
+		"set": {
+			url:         "wss://127.0.0.1:8200/v1/sys/events/subscribe/%2A?json=true",
+			filter:      `event_type == "kv-v2/data-write"`,
+			expectedURL: "wss://127.0.0.1:8200/v1/sys/events/subscribe/%2A?filter=event_type+%3D%3D+%22kv-v2%2Fdata-write%22&json=true",
+		},
+		"unset": {
+			url:         "wss://127.0.0.1:8200/v1/sys/events/subscribe/%2A?filter=foo&json=true",
+			expectedURL: "wss://127.0.0.1:8200/v1/sys/events/subscribe/%2A?json=true",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ws := &WebsocketClient{URL: tc.url}
+			require.NoError(t, ws.SetFilter(tc.filter))
+			assert.Equal(t, tc.expectedURL, ws.URL)
+		})
+	}
+}
+
 func TestConnect(t *testing.T) {
 	tests := map[string]struct {
 		handler *testHandler
@@ -167,3 +198,68 @@ func TestConnect_redirect(t *testing.T) {
 		conn.Close(websocket.StatusNormalClosure, "test finished")
 	})
 }
+
+func TestWebSocketClient_websocketConfig(t *testing.T) {
+	client, err := api.NewClient(nil)
+	require.NoError(t, err)
+	wsHTTPClient := &http.Client{}
+	c := &defaultClient{
+		client:              client,
+		websocketHTTPClient: wsHTTPClient,
+		websocketConfig: &WebsocketConfig{
+			DialTimeout:  ptr.To[time.Duration](5 * time.Second),
+			PingInterval: ptr.To[time.Duration](30 * time.Second),
+		},
+		connObj: &secretsv1beta1.VaultConnection{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+			},
+		},
+	}
+
+	ws, err := c.WebsocketClient("/subscribe/event/path")
+	require.NoError(t, err)
+	assert.Same(t, wsHTTPClient, ws.HTTPClient)
+	assert.Equal(t, 5*time.Second, ws.DialTimeout)
+	assert.Equal(t, 30*time.Second, ws.PingInterval)
+	assert.Equal(t, "foo/bar", ws.vaultConn)
+}
+
+func TestConnect_pingFailed(t *testing.T) {
+	handler := &testHandler{
+		handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+			conn, err := websocket.Accept(w, req, nil)
+			if err != nil {
+				return
+			}
+			// never read from the connection, so pings are never answered.
+			<-req.Context().Done()
+			conn.CloseNow()
+		},
+	}
+	config, l := NewTestHTTPServer(t, handler.handler())
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	client, err := api.NewClient(config)
+	require.NoError(t, err)
+	c := &defaultClient{client: client}
+	ws, err := c.WebsocketClient("/subscribe/event/path")
+	require.NoError(t, err)
+	ws.DialTimeout = 5 * time.Second
+	ws.PingInterval = 50 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	conn, err := ws.Connect(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, conn)
+	t.Cleanup(func() {
+		conn.Close(websocket.StatusNormalClosure, "test finished")
+	})
+
+	_, _, err = conn.Read(ctx)
+	assert.ErrorIs(t, err, ErrWebsocketPingFailed)
+}
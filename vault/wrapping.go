@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+type wrapTTLKey struct{}
+
+// WithWrapTTL returns a context that requests a wrapped response for all Client
+// requests made with it. The wrapping token expires after ttl, see
+// https://developer.hashicorp.com/vault/docs/concepts/response-wrapping
+func WithWrapTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, wrapTTLKey{}, ttl)
+}
+
+// wrapTTLCallbacks returns the request callbacks that request a wrapped
+// response, if ctx requires it.
+func wrapTTLCallbacks(ctx context.Context) []api.RequestCallback {
+	ttl, _ := ctx.Value(wrapTTLKey{}).(time.Duration)
+	if ttl <= 0 {
+		return nil
+	}
+
+	wrapTTL := strconv.FormatInt(int64(ttl.Seconds()), 10)
+	return []api.RequestCallback{
+		func(r *api.Request) {
+			r.WrapTTL = wrapTTL
+		},
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultClient_wrapTTL(t *testing.T) {
+	t.Parallel()
+
+	var gotWrapTTLs []string
+	var gotIndexes [][]string
+	handler := &testHandler{
+		handlerFunc: func(t *testHandler, w http.ResponseWriter, req *http.Request) {
+			gotWrapTTLs = append(gotWrapTTLs, req.Header.Get("X-Vault-Wrap-TTL"))
+			gotIndexes = append(gotIndexes, req.Header.Values(api.HeaderIndex))
+			w.Header().Set(api.HeaderIndex, "state")
+			secret := &api.Secret{
+				Data: map[string]interface{}{
+					"foo": "bar",
+				},
+			}
+			if req.Header.Get("X-Vault-Wrap-TTL") != "" {
+				secret = &api.Secret{
+					WrapInfo: &api.SecretWrapInfo{
+						Token: "wrapping-token",
+						TTL:   60,
+					},
+				}
+			}
+			m, err := json.Marshal(secret)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(m)
+		},
+	}
+
+	config, l := NewTestHTTPServer(t, handler.handler())
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	client, err := api.NewClient(config)
+	require.NoError(t, err)
+	c := &defaultClient{
+		client: client,
+	}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		wantWrapTTLs []string
+		wantIndexes  [][]string
+		wantWrapInfo *api.SecretWrapInfo
+	}{
+		{
+			name:         "without-wrap-ttl",
+			ctx:          context.Background(),
+			wantWrapTTLs: []string{"", ""},
+			wantIndexes:  [][]string{nil, nil},
+		},
+		{
+			name:         "with-wrap-ttl",
+			ctx:          WithWrapTTL(context.Background(), time.Minute),
+			wantWrapTTLs: []string{"60", "60"},
+			wantIndexes:  [][]string{nil, nil},
+			wantWrapInfo: &api.SecretWrapInfo{
+				Token: "wrapping-token",
+				TTL:   60,
+			},
+		},
+		{
+			name:         "with-wrap-ttl-and-replication-state",
+			ctx:          WithWrapTTL(WithReplicationState(context.Background()), time.Minute),
+			wantWrapTTLs: []string{"60", "60"},
+			wantIndexes:  [][]string{nil, {"state"}},
+			wantWrapInfo: &api.SecretWrapInfo{
+				Token: "wrapping-token",
+				TTL:   60,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotWrapTTLs = nil
+			gotIndexes = nil
+			_, err := c.Write(tt.ctx, NewWriteRequest("foo/bar", nil))
+			require.NoError(t, err)
+			resp, err := c.Read(tt.ctx, NewReadRequest("foo/bar", nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantWrapTTLs, gotWrapTTLs)
+			assert.Equal(t, tt.wantIndexes, gotIndexes)
+			assert.Equal(t, tt.wantWrapInfo, resp.Secret().WrapInfo)
+		})
+	}
+}